@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Analytics events fall into categories, and users can consent to each
+// category separately.
+type Category string
+
+const (
+	// Which commands and features people use.
+	CategoryUsage Category = "usage"
+
+	// How long builds, deploys, and Tiltfile loads take.
+	CategoryPerformance Category = "performance"
+
+	// Errors and crashes.
+	CategoryErrors Category = "errors"
+)
+
+var Categories = []Category{CategoryUsage, CategoryPerformance, CategoryErrors}
+
+func ParseCategory(s string) (Category, error) {
+	for _, c := range Categories {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown analytics category %q. Must be one of: %s", s, categoryNames())
+}
+
+func categoryNames() string {
+	names := []string{}
+	for _, c := range Categories {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Timers measure performance. Counters measure usage, unless the event
+// name says it's an error.
+func CategoryOf(name string, isTimer bool) Category {
+	if isTimer {
+		return CategoryPerformance
+	}
+	for _, part := range strings.Split(name, ".") {
+		switch part {
+		case "error", "errors", "crash", "panic":
+			return CategoryErrors
+		}
+	}
+	return CategoryUsage
+}
+
+// What to do with the events in one category.
+type CategoryOpt string
+
+const (
+	// Fall back to the overall analytics opt-in/opt-out.
+	CategoryOptDefault CategoryOpt = ""
+
+	CategoryOptIn  CategoryOpt = "opt-in"
+	CategoryOptOut CategoryOpt = "opt-out"
+
+	// Record events locally, so that they show up in `tilt dump analytics`,
+	// but never send them.
+	CategoryOptLocal CategoryOpt = "local"
+)
+
+func ParseCategoryOpt(s string) (CategoryOpt, error) {
+	switch s {
+	case "default":
+		return CategoryOptDefault, nil
+	case "in", "opt-in":
+		return CategoryOptIn, nil
+	case "out", "opt-out":
+		return CategoryOptOut, nil
+	case "local", "local-only":
+		return CategoryOptLocal, nil
+	}
+	return CategoryOptDefault, fmt.Errorf("unknown analytics consent %q. Must be one of: opt-in, opt-out, local, default", s)
+}
+
+// Per-category consent. Categories that aren't in the map use CategoryOptDefault.
+type Consent map[Category]CategoryOpt
+
+func LocalOnlyConsent() Consent {
+	c := Consent{}
+	for _, cat := range Categories {
+		c[cat] = CategoryOptLocal
+	}
+	return c
+}
+
+func (c Consent) Get(cat Category) CategoryOpt {
+	return c[cat]
+}
+
+func (c Consent) Empty() bool {
+	for _, opt := range c {
+		if opt != CategoryOptDefault {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Consent) Equal(other Consent) bool {
+	for _, cat := range Categories {
+		if c.Get(cat) != other.Get(cat) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Consent) Copy() Consent {
+	result := Consent{}
+	for k, v := range c {
+		result[k] = v
+	}
+	return result
+}
+
+func (c Consent) String() string {
+	parts := []string{}
+	for _, cat := range Categories {
+		opt := c.Get(cat)
+		if opt == CategoryOptDefault {
+			opt = "default"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", cat, opt))
+	}
+	return strings.Join(parts, " ")
+}
@@ -11,6 +11,7 @@ import (
 type FakeOpter struct {
 	initialOpt analytics.Opt
 	calls      []analytics.Opt
+	consent    Consent
 	mu         sync.Mutex
 }
 
@@ -38,6 +39,19 @@ func (to *FakeOpter) SetUserOpt(opt analytics.Opt) error {
 	return nil
 }
 
+func (to *FakeOpter) ReadUserConsent() (Consent, error) {
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	return to.consent.Copy(), nil
+}
+
+func (to *FakeOpter) SetUserConsent(consent Consent) error {
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	to.consent = consent.Copy()
+	return nil
+}
+
 func (to *FakeOpter) Calls() []analytics.Opt {
 	to.mu.Lock()
 	defer to.mu.Unlock()
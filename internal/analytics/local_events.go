@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+)
+
+// How many events we keep around for `tilt dump analytics`.
+const localEventLimit = 1000
+
+// An analytics event, as recorded by this Tilt process.
+//
+// We record every event locally, whether or not we send it, so that users
+// can see exactly what Tilt would report.
+type LocalEvent struct {
+	Time     time.Time
+	Name     string
+	Category Category
+	Tags     map[string]string `json:",omitempty"`
+	N        int               `json:",omitempty"`
+	Duration time.Duration     `json:",omitempty"`
+
+	// Whether we passed the event on to the analytics server.
+	Sent bool
+}
+
+type localEvents struct {
+	mu     sync.Mutex
+	events []LocalEvent
+}
+
+func (l *localEvents) add(e LocalEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+	if len(l.events) > localEventLimit {
+		l.events = append([]LocalEvent{}, l.events[len(l.events)-localEventLimit:]...)
+	}
+}
+
+func (l *localEvents) list() []LocalEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LocalEvent{}, l.events...)
+}
@@ -17,23 +17,30 @@ type TiltAnalytics struct {
 	a           analytics.Analytics
 	tiltVersion string
 
-	// We make this a constant pointer to a struct.
+	// We make these constant pointers to structs.
 	// That way, the struct returned by WithoutGlobalTags() can
-	// point to the same opt set.
-	opt *optSet
+	// point to the same opt set and the same local event log.
+	opt   *optSet
+	local *localEvents
 }
 
 type optSet struct {
 	env      analytics.Opt
 	user     analytics.Opt
 	tiltfile analytics.Opt
+
+	// Per-category consent. Overrides the opt at the same level.
+	userConsent     Consent
+	tiltfileConsent Consent
 }
 
 // An AnalyticsOpter can record a user's choice (opt-in or opt-out)
-// in re: Tilt recording analytics.
+// in re: Tilt recording analytics, both overall and per-category.
 type AnalyticsOpter interface {
 	SetUserOpt(opt analytics.Opt) error
 	ReadUserOpt() (analytics.Opt, error)
+	SetUserConsent(consent Consent) error
+	ReadUserConsent() (Consent, error)
 }
 
 func NewTiltAnalytics(opter AnalyticsOpter, a analytics.Analytics, tiltVersion string) (*TiltAnalytics, error) {
@@ -41,6 +48,10 @@ func NewTiltAnalytics(opter AnalyticsOpter, a analytics.Analytics, tiltVersion s
 	if err != nil {
 		return nil, err
 	}
+	userConsent, err := opter.ReadUserConsent()
+	if err != nil {
+		return nil, err
+	}
 	envOpt := analytics.OptDefault
 	if ok, _ := IsAnalyticsDisabledFromEnv(); ok {
 		envOpt = analytics.OptOut
@@ -53,7 +64,11 @@ func NewTiltAnalytics(opter AnalyticsOpter, a analytics.Analytics, tiltVersion s
 			env:      envOpt,
 			user:     userOpt,
 			tiltfile: analytics.OptDefault,
+
+			userConsent:     userConsent,
+			tiltfileConsent: Consent{},
 		},
+		local: &localEvents{},
 	}, nil
 }
 
@@ -81,21 +96,46 @@ func (ta *TiltAnalytics) GitRepoHash() string {
 	return id
 }
 func (ta *TiltAnalytics) Count(name string, tags map[string]string, n int) {
-	if ta.EffectiveOpt() != analytics.OptOut {
+	sent := ta.shouldSend(CategoryOf(name, false))
+	if sent {
 		ta.a.Count(name, tags, n)
 	}
+	ta.record(LocalEvent{Name: name, Category: CategoryOf(name, false), Tags: tags, N: n, Sent: sent})
 }
 
 func (ta *TiltAnalytics) Incr(name string, tags map[string]string) {
-	if ta.EffectiveOpt() != analytics.OptOut {
+	sent := ta.shouldSend(CategoryOf(name, false))
+	if sent {
 		ta.a.Incr(name, tags)
 	}
+	ta.record(LocalEvent{Name: name, Category: CategoryOf(name, false), Tags: tags, N: 1, Sent: sent})
 }
 
 func (ta *TiltAnalytics) Timer(name string, dur time.Duration, tags map[string]string) {
-	if ta.EffectiveOpt() != analytics.OptOut {
+	sent := ta.shouldSend(CategoryOf(name, true))
+	if sent {
 		ta.a.Timer(name, dur, tags)
 	}
+	ta.record(LocalEvent{Name: name, Category: CategoryOf(name, true), Tags: tags, Duration: dur, Sent: sent})
+}
+
+func (ta *TiltAnalytics) record(e LocalEvent) {
+	e.Time = time.Now()
+	ta.local.add(e)
+}
+
+// Every event this process has recorded (up to a limit), oldest first,
+// including the ones we didn't send.
+func (ta *TiltAnalytics) LocalEvents() []LocalEvent {
+	return ta.local.list()
+}
+
+func (ta *TiltAnalytics) shouldSend(cat Category) bool {
+	switch ta.EffectiveCategoryOpt(cat) {
+	case CategoryOptOut, CategoryOptLocal:
+		return false
+	}
+	return true
 }
 
 func (ta *TiltAnalytics) Flush(timeout time.Duration) {
@@ -120,6 +160,56 @@ func (ta *TiltAnalytics) EffectiveOpt() analytics.Opt {
 	return ta.opt.user
 }
 
+// How we treat events in the given category.
+//
+// Precedence, highest first: the environment, the Tiltfile's consent for this category,
+// the Tiltfile's overall opt, the user's consent for this category, the user's overall opt.
+func (ta *TiltAnalytics) EffectiveCategoryOpt(cat Category) CategoryOpt {
+	if ta.opt.env != analytics.OptDefault {
+		return categoryOptFromOpt(ta.opt.env)
+	}
+	if opt := ta.opt.tiltfileConsent.Get(cat); opt != CategoryOptDefault {
+		return opt
+	}
+	if ta.opt.tiltfile != analytics.OptDefault {
+		return categoryOptFromOpt(ta.opt.tiltfile)
+	}
+	if opt := ta.opt.userConsent.Get(cat); opt != CategoryOptDefault {
+		return opt
+	}
+	return categoryOptFromOpt(ta.opt.user)
+}
+
+func categoryOptFromOpt(opt analytics.Opt) CategoryOpt {
+	switch opt {
+	case analytics.OptIn:
+		return CategoryOptIn
+	case analytics.OptOut:
+		return CategoryOptOut
+	}
+	return CategoryOptDefault
+}
+
+func (ta *TiltAnalytics) UserConsent() Consent {
+	return ta.opt.userConsent.Copy()
+}
+
+func (ta *TiltAnalytics) TiltfileConsent() Consent {
+	return ta.opt.tiltfileConsent.Copy()
+}
+
+func (ta *TiltAnalytics) SetUserConsent(consent Consent) error {
+	if consent.Equal(ta.opt.userConsent) {
+		return nil
+	}
+	ta.opt.userConsent = consent.Copy()
+	return ta.opter.SetUserConsent(consent)
+}
+
+func (ta *TiltAnalytics) SetTiltfileConsent(consent Consent) {
+	ta.opt.tiltfileConsent = consent.Copy()
+}
+
 func (ta *TiltAnalytics) SetUserOpt(opt analytics.Opt) error {
 	if opt == ta.opt.user {
 		return nil
@@ -138,6 +228,7 @@ func (ta *TiltAnalytics) WithoutGlobalTags() analytics.Analytics {
 		a:           ta.a.WithoutGlobalTags(),
 		tiltVersion: ta.tiltVersion,
 		opt:         ta.opt,
+		local:       ta.local,
 	}
 }
 
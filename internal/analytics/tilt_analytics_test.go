@@ -27,7 +27,8 @@ func testCases(expectedWhenOptedIn, expectedWhenOptedOut, expectedWhenNoOpt bool
 }
 
 type userOptSetting struct {
-	opt analytics.Opt
+	opt     analytics.Opt
+	consent Consent
 }
 
 func (os *userOptSetting) ReadUserOpt() (analytics.Opt, error) {
@@ -39,6 +40,15 @@ func (os *userOptSetting) SetUserOpt(opt analytics.Opt) error {
 	return nil
 }
 
+func (os *userOptSetting) ReadUserConsent() (Consent, error) {
+	return os.consent, nil
+}
+
+func (os *userOptSetting) SetUserConsent(consent Consent) error {
+	os.consent = consent
+	return nil
+}
+
 func TestCount(t *testing.T) {
 	for _, test := range testCases(true, false, true) {
 		t.Run(test.name, func(t *testing.T) {
@@ -189,3 +199,86 @@ func TestOptTransitionIncr(t *testing.T) {
 		})
 	}
 }
+
+func TestCategoryOptPrecedence(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		envOpt          analytics.Opt
+		userOpt         analytics.Opt
+		userConsent     Consent
+		tiltfileOpt     analytics.Opt
+		tiltfileConsent Consent
+		expected        CategoryOpt
+	}{
+		{"env opt overrides all", analytics.OptOut, analytics.OptIn, nil, analytics.OptIn, Consent{CategoryUsage: CategoryOptIn}, CategoryOptOut},
+		{"tiltfile consent overrides tiltfile opt", analytics.OptDefault, analytics.OptDefault, nil, analytics.OptIn, Consent{CategoryUsage: CategoryOptLocal}, CategoryOptLocal},
+		{"tiltfile opt overrides user consent", analytics.OptDefault, analytics.OptDefault, Consent{CategoryUsage: CategoryOptOut}, analytics.OptIn, nil, CategoryOptIn},
+		{"user consent overrides user opt", analytics.OptDefault, analytics.OptIn, Consent{CategoryUsage: CategoryOptLocal}, analytics.OptDefault, nil, CategoryOptLocal},
+		{"other categories don't apply", analytics.OptDefault, analytics.OptIn, Consent{CategoryErrors: CategoryOptOut}, analytics.OptDefault, nil, CategoryOptIn},
+		{"default if none set", analytics.OptDefault, analytics.OptDefault, nil, analytics.OptDefault, nil, CategoryOptDefault},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ma := analytics.NewMemoryAnalytics()
+			os := &userOptSetting{opt: tc.userOpt, consent: tc.userConsent}
+			a, _ := NewTiltAnalytics(os, ma, versionTest)
+			a.opt.env = tc.envOpt
+			a.SetTiltfileOpt(tc.tiltfileOpt)
+			a.SetTiltfileConsent(tc.tiltfileConsent)
+
+			assert.Equal(t, tc.expected, a.EffectiveCategoryOpt(CategoryUsage))
+		})
+	}
+}
+
+func TestLocalOnlyRecordsWithoutSending(t *testing.T) {
+	ma := analytics.NewMemoryAnalytics()
+	os := &userOptSetting{opt: analytics.OptIn, consent: LocalOnlyConsent()}
+	a, _ := NewTiltAnalytics(os, ma, versionTest)
+	a.opt.env = analytics.OptDefault
+
+	a.Incr("foo", testTags)
+	a.Timer("foo", time.Second, testTags)
+
+	assert.Empty(t, ma.Counts)
+	assert.Empty(t, ma.Timers)
+
+	events := a.LocalEvents()
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "foo", events[0].Name)
+		assert.Equal(t, CategoryUsage, events[0].Category)
+		assert.False(t, events[0].Sent)
+		assert.Equal(t, CategoryPerformance, events[1].Category)
+		assert.Equal(t, time.Second, events[1].Duration)
+		assert.False(t, events[1].Sent)
+	}
+}
+
+func TestPerCategoryConsent(t *testing.T) {
+	ma := analytics.NewMemoryAnalytics()
+	os := &userOptSetting{opt: analytics.OptIn}
+	a, _ := NewTiltAnalytics(os, ma, versionTest)
+	a.opt.env = analytics.OptDefault
+
+	err := a.SetUserConsent(Consent{CategoryPerformance: CategoryOptOut})
+	assert.NoError(t, err)
+	assert.Equal(t, Consent{CategoryPerformance: CategoryOptOut}, os.consent)
+
+	a.Incr("foo", testTags)
+	a.Timer("foo", time.Second, testTags)
+
+	assert.Len(t, ma.Counts, 1)
+	assert.Empty(t, ma.Timers)
+
+	events := a.LocalEvents()
+	if assert.Len(t, events, 2) {
+		assert.True(t, events[0].Sent)
+		assert.False(t, events[1].Sent)
+	}
+}
+
+func TestCategoryOf(t *testing.T) {
+	assert.Equal(t, CategoryUsage, CategoryOf("cmd.up", false))
+	assert.Equal(t, CategoryErrors, CategoryOf("ui.error.render", false))
+	assert.Equal(t, CategoryUsage, CategoryOf("ui.errorless", false))
+	assert.Equal(t, CategoryPerformance, CategoryOf("build.image", true))
+}
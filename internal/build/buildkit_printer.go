@@ -10,12 +10,14 @@ import (
 	"github.com/tonistiigi/units"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type buildkitPrinter struct {
-	logger logger.Logger
-	vData  map[digest.Digest]*vertexAndLogs
-	vOrder []digest.Digest
+	logger    logger.Logger
+	verbosity model.BuildOutputVerbosity
+	vData     map[digest.Digest]*vertexAndLogs
+	vOrder    []digest.Digest
 }
 
 type vertex struct {
@@ -107,14 +109,23 @@ func (s vertexStatusSet) combined() vertexStatus {
 	}
 }
 
-func newBuildkitPrinter(l logger.Logger) *buildkitPrinter {
+func newBuildkitPrinter(l logger.Logger, verbosity model.BuildOutputVerbosity) *buildkitPrinter {
 	return &buildkitPrinter{
-		logger: l,
-		vData:  map[digest.Digest]*vertexAndLogs{},
-		vOrder: []digest.Digest{},
+		logger:    l,
+		verbosity: verbosity,
+		vData:     map[digest.Digest]*vertexAndLogs{},
+		vOrder:    []digest.Digest{},
 	}
 }
 
+func (b *buildkitPrinter) stepOutputLogger() logger.Logger {
+	return withBuildDetail(b.logger, !showsFullBuildOutput(b.verbosity))
+}
+
+func (b *buildkitPrinter) statusLogger() logger.Logger {
+	return withBuildDetail(b.logger, b.verbosity == model.BuildOutputQuiet)
+}
+
 func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, statuses []*vertexStatus) error {
 	for _, v := range vertexes {
 		if vl, ok := b.vData[v.digest]; ok {
@@ -135,7 +146,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			b.vData[v.digest] = &vertexAndLogs{
 				vertex: v,
 				logs:   []*vertexLog{},
-				logger: logger.NewPrefixedLogger(logPrefix, b.logger),
+				logger: logger.NewPrefixedLogger(logPrefix, b.stepOutputLogger()),
 			}
 
 			b.vOrder = append(b.vOrder, v.digest)
@@ -169,7 +180,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			if v.cached {
 				cacheSuffix = " [cached]"
 			}
-			b.logger.WithFields(logger.Fields{logger.FieldNameProgressID: v.stageName()}).
+			b.statusLogger().WithFields(logger.Fields{logger.FieldNameProgressID: v.stageName()}).
 				Infof("%s%s", v.name, cacheSuffix)
 			v.startPrinted = true
 		}
@@ -178,6 +189,8 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			// TODO(nick): Should this be logger.Errorf?
 			b.logger.Infof("\nERROR IN: %s", v.name)
 			v.errorPrinted = true
+			b.flushLogs(vl)
+			b.replayHiddenLogs(vl)
 		}
 
 		if v.isError() || !v.isInternal() {
@@ -225,7 +238,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			}
 
 			if shouldPrintCompletion || shouldPrintProgress {
-				b.logger.WithFields(fields).
+				b.statusLogger().WithFields(fields).
 					Infof("%s%s%s", v.name, progressInBytes, doneSuffix)
 
 				vl.lastPrintedStatus = status
@@ -243,3 +256,18 @@ func (b *buildkitPrinter) flushLogs(vl *vertexAndLogs) {
 		vl.logger.Write(logger.InfoLvl, []byte(l.msg))
 	}
 }
+
+// When a step fails, the user needs to see its output, even if
+// we've been hiding it. Print it again for anyone who isn't looking at
+// the full build logs.
+func (b *buildkitPrinter) replayHiddenLogs(vl *vertexAndLogs) {
+	if showsFullBuildOutput(b.verbosity) {
+		return
+	}
+
+	l := logger.NewPrefixedLogger(logPrefix,
+		b.logger.WithFields(logger.Fields{logger.FieldNameBuildDetail: logger.BuildDetailReplay}))
+	for _, log := range vl.logs[:vl.logsPrinted] {
+		l.Write(logger.InfoLvl, log.msg)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // NOTE(dmiller): set at runtime with:
@@ -68,7 +69,7 @@ func TestBuildkitPrinter(t *testing.T) {
 			}
 
 			output := &strings.Builder{}
-			p := newBuildkitPrinter(logger.NewLogger(logger.InfoLvl, output))
+			p := newBuildkitPrinter(logger.NewLogger(logger.InfoLvl, output), model.BuildOutputFull)
 
 			for _, resp := range responses {
 				err := p.parseAndPrint(toVertexes(resp))
@@ -99,3 +100,79 @@ func TestBuildkitPrinter(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildkitPrinterVerbosity(t *testing.T) {
+	cases := []struct {
+		name      string
+		response  string
+		verbosity model.BuildOutputVerbosity
+		visible   string
+	}{
+		{"progress-success", "echo-hi-success.response.txt", model.BuildOutputProgress,
+			"[1/2] FROM docker.io/library/busybox@sha256:c94cf1b87ccb80f2e6414ef913c748b105060debda482058d2b8d0fce39f11b9\n" +
+				"[1/2] FROM docker.io/library/busybox@sha256:c94cf1b87ccb80f2e6414ef913c748b105060debda482058d2b8d0fce39f11b9 2.39kB / 2.39kB\n" +
+				"[1/2] FROM docker.io/library/busybox@sha256:c94cf1b87ccb80f2e6414ef913c748b105060debda482058d2b8d0fce39f11b9 3.89kB / 766.62kB\n" +
+				"[1/2] FROM docker.io/library/busybox@sha256:c94cf1b87ccb80f2e6414ef913c748b105060debda482058d2b8d0fce39f11b9 766.62kB / 766.62kB [done: 702ms]\n" +
+				"[2/2] RUN echo hi\n" +
+				"[2/2] RUN echo hi [done: 1.365s]\n" +
+				"exporting to image\n" +
+				"exporting to image [done: 66ms]\n"},
+		{"quiet-success", "echo-hi-success.response.txt", model.BuildOutputQuiet, ""},
+		{"progress-failure", "echo-hi-failure.response.txt", model.BuildOutputProgress,
+			"[1/2] FROM docker.io/library/busybox@sha256:c94cf1b87ccb80f2e6414ef913c748b105060debda482058d2b8d0fce39f11b9\n" +
+				"[2/2] RUN echo hi && exit 1\n" +
+				"\nERROR IN: [2/2] RUN echo hi && exit 1\n" +
+				"  → hi\n"},
+		{"quiet-failure", "echo-hi-failure.response.txt", model.BuildOutputQuiet,
+			"\nERROR IN: [2/2] RUN echo hi && exit 1\n" +
+				"  → hi\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "TestBuildkitPrinter", c.response))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			responses, err := buildkitTestCase{}.readResponse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			visible := &strings.Builder{}
+			full := &strings.Builder{}
+			l := logger.NewFuncLogger(false, logger.InfoLvl, func(level logger.Level, fields logger.Fields, b []byte) error {
+				switch fields[logger.FieldNameBuildDetail] {
+				case logger.BuildDetailHidden:
+					full.Write(b)
+				case logger.BuildDetailReplay:
+					visible.Write(b)
+				default:
+					full.Write(b)
+					visible.Write(b)
+				}
+				return nil
+			})
+
+			p := newBuildkitPrinter(l, c.verbosity)
+			for _, resp := range responses {
+				err := p.parseAndPrint(toVertexes(resp))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			assert.Equal(t, c.visible, visible.String())
+
+			// The full build log is always the same, no matter what the user sees.
+			expectedFull, err := ioutil.ReadFile(fmt.Sprintf("testdata/TestBuildkitPrinter/%s.master.txt",
+				strings.TrimSuffix(c.response, ".response.txt")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, normalize(string(expectedFull)), normalize(full.String()))
+		})
+	}
+}
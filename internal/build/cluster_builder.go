@@ -0,0 +1,336 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Builds images inside the Kubernetes cluster, with docker_build(build_on='cluster').
+//
+// The cluster builder pushes the image straight to the registry,
+// so the image never touches the local Docker daemon.
+type ClusterBuilder interface {
+	Build(ctx context.Context, ps *PipelineState, refs container.RefSet, db model.DockerBuild, filter model.PathMatcher) (container.TaggedRefs, error)
+}
+
+const KanikoImage = "gcr.io/kaniko-project/executor:v1.3.0"
+
+// A small image with sh and tar, to receive the build context.
+const ClusterBuildContextImage = "busybox:1.32"
+
+const (
+	clusterBuildContextContainer container.Name = "context"
+	clusterBuildKanikoContainer  container.Name = "kaniko"
+
+	clusterBuildWorkspace = "/workspace"
+
+	// The context container waits for this file before it exits,
+	// so that kaniko doesn't start until the whole context is there.
+	clusterBuildReadyFile = clusterBuildWorkspace + "/.tilt-context-ready"
+)
+
+// Label on every build pod, so that users can find (and clean up) stray ones.
+const ClusterBuildLabel = "tilt.dev/cluster-build"
+
+// How long we wait for the build pod to get scheduled and pull its images.
+const clusterBuildStartTimeout = 5 * time.Minute
+
+// Runs each build as a kaniko pod.
+//
+// We stream the build context into the pod by exec'ing tar in an
+// init container, then stream kaniko's output back into the build log.
+//
+// The pod goes in the kubeconfig's default namespace.
+type KanikoClusterBuilder struct {
+	kCli         k8s.Client
+	clock        Clock
+	pollInterval time.Duration
+}
+
+var _ ClusterBuilder = &KanikoClusterBuilder{}
+
+func NewKanikoClusterBuilder(kCli k8s.Client, clock Clock) *KanikoClusterBuilder {
+	return &KanikoClusterBuilder{
+		kCli:         kCli,
+		clock:        clock,
+		pollInterval: 500 * time.Millisecond,
+	}
+}
+
+func (b *KanikoClusterBuilder) Build(ctx context.Context, ps *PipelineState, refs container.RefSet, db model.DockerBuild, filter model.PathMatcher) (container.TaggedRefs, error) {
+	if len(db.SSHSpecs) > 0 || len(db.SecretSpecs) > 0 {
+		return container.TaggedRefs{}, fmt.Errorf("docker_build(build_on='cluster') doesn't support ssh or secret")
+	}
+
+	now := b.clock.Now()
+	tagged, err := refs.AddTagSuffix(fmt.Sprintf("tilt-build-%d", now.Unix()))
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "ClusterBuilder.Build")
+	}
+
+	buildOutputLogger(ctx, db.OutputVerbosity).Infof("Building Dockerfile in cluster:\n%s\n", indent(db.Dockerfile, "  "))
+
+	ps.StartBuildStep(ctx, "Starting build pod")
+	pod := kanikoPod(clusterBuildPodName(refs, now), tagged.ClusterRef.String(), db,
+		tagged.LocalRef.String() != tagged.ClusterRef.String())
+	podID := k8s.PodID(pod.Name)
+	created, err := b.kCli.Upsert(ctx, []k8s.K8sEntity{k8s.NewK8sEntity(pod)}, clusterBuildStartTimeout)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "creating build pod")
+	}
+	ns := k8s.Namespace("")
+	if len(created) == 1 {
+		ns = created[0].Namespace()
+	}
+	defer func() {
+		// Use a fresh context, so that we clean up even if the build was canceled.
+		err := b.kCli.Delete(context.Background(), created)
+		if err != nil {
+			logger.Get(ctx).Debugf("deleting build pod %s: %v", podID, err)
+		}
+	}()
+
+	err = b.waitForPod(ctx, podID, ns, clusterBuildStartTimeout, func(pod *v1.Pod) (bool, error) {
+		state, ok := containerState(pod.Status.InitContainerStatuses, clusterBuildContextContainer)
+		if !ok {
+			return false, nil
+		}
+		if state.Terminated != nil {
+			return false, fmt.Errorf("build context container exited unexpectedly: %s", state.Terminated.Reason)
+		}
+		return state.Running != nil, nil
+	})
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrapf(err, "waiting for build pod %s", podID)
+	}
+
+	ps.StartBuildStep(ctx, "Uploading context")
+	err = b.uploadContext(ctx, podID, ns, db, filter)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "uploading build context")
+	}
+
+	ps.StartBuildStep(ctx, "Building image")
+	err = b.waitForPod(ctx, podID, ns, clusterBuildStartTimeout, func(pod *v1.Pod) (bool, error) {
+		state, ok := containerState(pod.Status.ContainerStatuses, clusterBuildKanikoContainer)
+		return ok && (state.Running != nil || state.Terminated != nil), nil
+	})
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrapf(err, "waiting for build pod %s", podID)
+	}
+
+	logs, err := b.kCli.ContainerLogs(ctx, podID, clusterBuildKanikoContainer, ns, time.Time{})
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "streaming build logs")
+	}
+	_, err = io.Copy(buildOutputLogger(ctx, db.OutputVerbosity).Writer(logger.InfoLvl), logs)
+	_ = logs.Close()
+	if err != nil && ctx.Err() == nil {
+		logger.Get(ctx).Debugf("streaming build logs: %v", err)
+	}
+
+	var exitCode int32
+	err = b.waitForPod(ctx, podID, ns, 0, func(pod *v1.Pod) (bool, error) {
+		state, ok := containerState(pod.Status.ContainerStatuses, clusterBuildKanikoContainer)
+		if !ok || state.Terminated == nil {
+			return false, nil
+		}
+		exitCode = state.Terminated.ExitCode
+		return true, nil
+	})
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrapf(err, "waiting for build pod %s", podID)
+	}
+	if exitCode != 0 {
+		return container.TaggedRefs{}, fmt.Errorf("in-cluster build failed with exit code %d", exitCode)
+	}
+
+	return tagged, nil
+}
+
+// Streams the build context into the pod, then tells the context container to exit.
+func (b *KanikoClusterBuilder) uploadContext(ctx context.Context, podID k8s.PodID, ns k8s.Namespace, db model.DockerBuild, filter model.PathMatcher) error {
+	paths := []PathMapping{
+		{
+			LocalPath:     db.BuildPath,
+			ContainerPath: "/",
+		},
+	}
+
+	// If tarring fails, the exec fails with whatever error the remote tar
+	// made of the broken pipe. Hold onto the original, so we can report that instead.
+	tarErrCh := make(chan error, 1)
+	pr, pw := io.Pipe()
+	go func() {
+		err := tarContextAndUpdateDf(ctx, pw, dockerfile.Dockerfile(db.Dockerfile), paths, filter, db.ContextWarningSize)
+		if err != nil {
+			tarErrCh <- err
+			_ = pw.CloseWithError(err)
+		} else {
+			_ = pw.Close()
+		}
+	}()
+	defer func() {
+		_ = pr.Close()
+	}()
+
+	stderr := &strings.Builder{}
+	err := b.kCli.Exec(ctx, podID, clusterBuildContextContainer, ns,
+		[]string{"tar", "-x", "-f", "-", "-C", clusterBuildWorkspace}, pr, ioutil.Discard, stderr)
+	if err != nil {
+		err = withTarError(err, tarErrCh)
+		if stderr.Len() > 0 {
+			return errors.Wrap(err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+
+	return b.kCli.Exec(ctx, podID, clusterBuildContextContainer, ns,
+		[]string{"touch", clusterBuildReadyFile}, nil, ioutil.Discard, ioutil.Discard)
+}
+
+// Polls the pod until the condition is met. A timeout of 0 means wait until the context is done.
+func (b *KanikoClusterBuilder) waitForPod(ctx context.Context, podID k8s.PodID, ns k8s.Namespace, timeout time.Duration, cond func(pod *v1.Pod) (bool, error)) error {
+	if timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	for {
+		pod, err := b.kCli.PodByID(ctx, podID, ns)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if pod != nil {
+			if pod.Status.Phase == v1.PodFailed && pod.Status.Reason != "" {
+				return fmt.Errorf("pod failed: %s %s", pod.Status.Reason, pod.Status.Message)
+			}
+			done, err := cond(pod)
+			if err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timed out after %s", timeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func containerState(statuses []v1.ContainerStatus, name container.Name) (v1.ContainerState, bool) {
+	for _, s := range statuses {
+		if s.Name == name.String() {
+			return s.State, true
+		}
+	}
+	return v1.ContainerState{}, false
+}
+
+var invalidPodNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func clusterBuildPodName(refs container.RefSet, now time.Time) string {
+	name := container.FamiliarString(refs.ConfigurationRef)
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(invalidPodNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+
+	suffix := fmt.Sprintf("-%d", now.Unix())
+	maxLen := 63 - len("tilt-build-") - len(suffix)
+	if len(name) > maxLen {
+		name = strings.TrimRight(name[:maxLen], "-")
+	}
+	return "tilt-build-" + name + suffix
+}
+
+func kanikoPod(name string, destination string, db model.DockerBuild, insecureRegistry bool) *v1.Pod {
+	args := []string{
+		"--dockerfile=Dockerfile",
+		"--context=dir://" + clusterBuildWorkspace,
+		"--destination=" + destination,
+	}
+	argKeys := make([]string, 0, len(db.BuildArgs))
+	for k := range db.BuildArgs {
+		argKeys = append(argKeys, k)
+	}
+	sort.Strings(argKeys)
+	for _, k := range argKeys {
+		args = append(args, fmt.Sprintf("--build-arg=%s=%s", k, db.BuildArgs[k]))
+	}
+	if db.TargetStage != "" {
+		args = append(args, "--target="+string(db.TargetStage))
+	}
+	for _, cf := range db.CacheFrom {
+		args = append(args, "--cache=true", "--cache-repo="+cf)
+	}
+	if insecureRegistry {
+		// A distinct cluster ref means a local registry, which usually doesn't have TLS.
+		args = append(args, "--insecure")
+	}
+
+	workspace := v1.VolumeMount{Name: "workspace", MountPath: clusterBuildWorkspace}
+	return &v1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				k8s.ManagedByLabel: k8s.ManagedByValue,
+				ClusterBuildLabel:  "true",
+			},
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			InitContainers: []v1.Container{
+				{
+					Name:  clusterBuildContextContainer.String(),
+					Image: ClusterBuildContextImage,
+					Command: []string{"sh", "-c",
+						fmt.Sprintf("until [ -e %s ]; do sleep 0.2; done; rm %s", clusterBuildReadyFile, clusterBuildReadyFile)},
+					VolumeMounts: []v1.VolumeMount{workspace},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:         clusterBuildKanikoContainer.String(),
+					Image:        KanikoImage,
+					Args:         args,
+					WorkingDir:   clusterBuildWorkspace,
+					VolumeMounts: []v1.VolumeMount{workspace},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name:         "workspace",
+					VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+				},
+			},
+		},
+	}
+}
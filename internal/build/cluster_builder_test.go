@@ -0,0 +1,58 @@
+package build
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestKanikoPodArgs(t *testing.T) {
+	db := model.DockerBuild{
+		BuildArgs:   model.DockerBuildArgs{"b": "2", "a": "1"},
+		TargetStage: "prod",
+		CacheFrom:   []string{"gcr.io/foo/cache"},
+	}
+	pod := kanikoPod("tilt-build-foo-1", "gcr.io/foo:tilt-build-1", db, false)
+
+	require.Len(t, pod.Spec.Containers, 1)
+	assert.Equal(t, KanikoImage, pod.Spec.Containers[0].Image)
+	assert.Equal(t, []string{
+		"--dockerfile=Dockerfile",
+		"--context=dir:///workspace",
+		"--destination=gcr.io/foo:tilt-build-1",
+		"--build-arg=a=1",
+		"--build-arg=b=2",
+		"--target=prod",
+		"--cache=true",
+		"--cache-repo=gcr.io/foo/cache",
+	}, pod.Spec.Containers[0].Args)
+	assert.Equal(t, k8s.ManagedByValue, pod.Labels[k8s.ManagedByLabel])
+	assert.Equal(t, "true", pod.Labels[ClusterBuildLabel])
+
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, clusterBuildContextContainer.String(), pod.Spec.InitContainers[0].Name)
+	assert.Contains(t, strings.Join(pod.Spec.InitContainers[0].Command, " "), clusterBuildReadyFile)
+}
+
+func TestKanikoPodInsecureRegistry(t *testing.T) {
+	pod := kanikoPod("tilt-build-foo-1", "registry:5000/foo:tilt-build-1", model.DockerBuild{}, true)
+	assert.Contains(t, pod.Spec.Containers[0].Args, "--insecure")
+}
+
+func TestClusterBuildPodName(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	refs := container.MustSimpleRefSet(container.MustParseSelector("gcr.io/my-project/frontend_app"))
+	assert.Equal(t, "tilt-build-frontend-app-1600000000", clusterBuildPodName(refs, now))
+
+	refs = container.MustSimpleRefSet(container.MustParseSelector("gcr.io/" + strings.Repeat("a", 80)))
+	name := clusterBuildPodName(refs, now)
+	assert.LessOrEqual(t, len(name), 63)
+	assert.True(t, strings.HasSuffix(name, "-1600000000"))
+}
@@ -0,0 +1,74 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Tags images by a hash of their build inputs, so that any machine
+// that builds the same inputs can find the image in the registry.
+const ContentTagPrefix = "content-"
+
+// A tag for the image that db would build, derived from the Dockerfile,
+// the build args, and the files in the build context.
+//
+// The hash only uses paths relative to the build context, and only the
+// executable bit of each file's permissions (the only bit that git tracks),
+// so that checkouts of the same commit on different machines get the same tag.
+//
+// Files that the filter ignores don't count, because they don't go in the context.
+func ContentTag(db model.DockerBuild, filter model.PathMatcher) (string, error) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "dockerfile\x00%s\x00", db.Dockerfile)
+	_, _ = fmt.Fprintf(h, "target\x00%s\x00", db.TargetStage)
+
+	argNames := make([]string, 0, len(db.BuildArgs))
+	for k := range db.BuildArgs {
+		argNames = append(argNames, k)
+	}
+	sort.Strings(argNames)
+	for _, k := range argNames {
+		_, _ = fmt.Fprintf(h, "arg\x00%s\x00%s\x00", k, db.BuildArgs[k])
+	}
+
+	err := filepath.Walk(db.BuildPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			ignored, err := filter.MatchesEntireDir(path)
+			if err != nil {
+				return err
+			}
+			if ignored {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ignored, err := filter.Matches(path)
+		if err != nil || ignored {
+			return err
+		}
+
+		rel, err := filepath.Rel(db.BuildPath, path)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "file\x00%s\x00%t\x00%t\x00",
+			filepath.ToSlash(rel), info.Mode()&os.ModeSymlink != 0, info.Mode()&0111 != 0)
+		return hashFileContents(h, path, info)
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing build context %s: %v", db.BuildPath, err)
+	}
+
+	return fmt.Sprintf("%s%s%s", ImageTagPrefix, ContentTagPrefix, hex.EncodeToString(h.Sum(nil))[:16]), nil
+}
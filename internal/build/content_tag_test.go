@@ -0,0 +1,82 @@
+package build
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestContentTagIgnoresLocation(t *testing.T) {
+	f1 := tempdir.NewTempDirFixture(t)
+	defer f1.TearDown()
+	f2 := tempdir.NewTempDirFixture(t)
+	defer f2.TearDown()
+
+	f1.WriteFile("src/a.txt", "a")
+	f2.WriteFile("src/a.txt", "a")
+
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(f2.JoinPath("src/a.txt"), future, future))
+
+	tag1 := contentTag(t, model.DockerBuild{Dockerfile: "FROM alpine", BuildPath: f1.Path()})
+	tag2 := contentTag(t, model.DockerBuild{Dockerfile: "FROM alpine", BuildPath: f2.Path()})
+	assert.Equal(t, tag1, tag2)
+	assert.True(t, strings.HasPrefix(tag1, ImageTagPrefix+ContentTagPrefix), tag1)
+}
+
+func TestContentTagChanges(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	db := model.DockerBuild{Dockerfile: "FROM alpine", BuildPath: f.Path()}
+	tags := map[string]bool{contentTag(t, db): true}
+
+	f.WriteFile("src/a.txt", "b")
+	tags[contentTag(t, db)] = true
+
+	require.NoError(t, os.Chmod(f.JoinPath("src/a.txt"), 0644))
+	tags[contentTag(t, db)] = true
+
+	db.Dockerfile = "FROM busybox"
+	tags[contentTag(t, db)] = true
+
+	db.BuildArgs = model.DockerBuildArgs{"DEBUG": "1"}
+	tags[contentTag(t, db)] = true
+
+	db.TargetStage = "release"
+	tags[contentTag(t, db)] = true
+
+	assert.Equal(t, 6, len(tags))
+}
+
+func TestContentTagSkipsIgnoredFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	db := model.DockerBuild{Dockerfile: "FROM alpine", BuildPath: f.Path()}
+	filter, err := model.NewSimpleFileMatcher(f.JoinPath("src/ignored.txt"))
+	require.NoError(t, err)
+
+	tag, err := ContentTag(db, filter)
+	require.NoError(t, err)
+
+	f.WriteFile("src/ignored.txt", "ignored")
+	newTag, err := ContentTag(db, filter)
+	require.NoError(t, err)
+	assert.Equal(t, tag, newTag)
+}
+
+func contentTag(t *testing.T, db model.DockerBuild) string {
+	tag, err := ContentTag(db, model.EmptyMatcher)
+	require.NoError(t, err)
+	return tag
+}
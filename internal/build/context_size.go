@@ -0,0 +1,112 @@
+package build
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-units"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How many of the biggest directories and files to list
+// when the build context is too big.
+const contextSizeTopN = 5
+
+type contextSizeItem struct {
+	name string
+	size int64
+}
+
+// A breakdown of where the bytes in a build context come from.
+type contextSizeReport struct {
+	total    int64
+	topDirs  []contextSizeItem
+	topFiles []contextSizeItem
+}
+
+func newContextSizeReport(entries []archiveEntry) contextSizeReport {
+	total := int64(0)
+	dirSizes := make(map[string]int64)
+	files := make([]contextSizeItem, 0, len(entries))
+	for _, entry := range entries {
+		if entry.header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		size := entry.header.Size
+		total += size
+		files = append(files, contextSizeItem{name: entry.header.Name, size: size})
+
+		// Attribute each file to its top-level directory in the context,
+		// because that's usually the granularity you'd want to ignore at.
+		name := strings.TrimPrefix(path.Clean(entry.header.Name), "/")
+		if i := strings.Index(name, "/"); i != -1 {
+			dirSizes[name[:i+1]] += size
+		}
+	}
+
+	dirs := make([]contextSizeItem, 0, len(dirSizes))
+	for name, size := range dirSizes {
+		dirs = append(dirs, contextSizeItem{name: name, size: size})
+	}
+
+	return contextSizeReport{
+		total:    total,
+		topDirs:  topContextSizeItems(dirs),
+		topFiles: topContextSizeItems(files),
+	}
+}
+
+func topContextSizeItems(items []contextSizeItem) []contextSizeItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].size != items[j].size {
+			return items[i].size > items[j].size
+		}
+		return items[i].name < items[j].name
+	})
+	if len(items) > contextSizeTopN {
+		items = items[:contextSizeTopN]
+	}
+	return items
+}
+
+func (r contextSizeReport) String() string {
+	sb := strings.Builder{}
+	if len(r.topDirs) > 0 {
+		sb.WriteString("Largest directories:\n")
+		for _, item := range r.topDirs {
+			sb.WriteString(fmt.Sprintf("  %10s  %s\n", units.HumanSize(float64(item.size)), item.name))
+		}
+	}
+	if len(r.topFiles) > 0 {
+		sb.WriteString("Largest files:\n")
+		for _, item := range r.topFiles {
+			sb.WriteString(fmt.Sprintf("  %10s  %s\n", units.HumanSize(float64(item.size)), item.name))
+		}
+	}
+	return sb.String()
+}
+
+// Warns if the build context is bigger than the threshold, with a breakdown
+// of the biggest offenders, because accidentally sending (e.g.) node_modules
+// to the Docker daemon can add minutes to every build.
+func warnIfContextTooBig(ctx context.Context, entries []archiveEntry, threshold int64) {
+	if threshold <= 0 {
+		return
+	}
+
+	report := newContextSizeReport(entries)
+	if report.total <= threshold {
+		return
+	}
+
+	logger.Get(ctx).Warnf("Build context is %s, which is more than the warning threshold of %s.\n%s"+
+		"If the image doesn't need these, add them to .dockerignore or docker_build(ignore=...).\n"+
+		"To change the threshold, use update_settings(build_context_warning_mb=...).",
+		units.HumanSize(float64(report.total)), units.HumanSize(float64(threshold)), report)
+}
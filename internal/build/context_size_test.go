@@ -0,0 +1,80 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestContextSizeWarning(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("node_modules/left-pad/index.js", strings.Repeat("x", 2000))
+	f.WriteFile("node_modules/right-pad/index.js", strings.Repeat("x", 500))
+	f.WriteFile("src/main.go", strings.Repeat("x", 100))
+	f.WriteFile("README.md", strings.Repeat("x", 10))
+
+	out := f.tarContext(1000)
+	assert.Contains(t, out, "Build context is 2.61kB, which is more than the warning threshold of 1kB")
+	assert.Contains(t, out, "Largest directories:\n       2.5kB  node_modules/\n        100B  src/\n")
+	assert.Contains(t, out, "Largest files:\n         2kB  node_modules/left-pad/index.js\n")
+	assert.Contains(t, out, ".dockerignore")
+}
+
+func TestContextSizeUnderThreshold(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("src/main.go", strings.Repeat("x", 100))
+
+	out := f.tarContext(1000)
+	assert.NotContains(t, out, "Build context is")
+}
+
+func TestContextSizeWarningDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("node_modules/left-pad/index.js", strings.Repeat("x", 2000))
+
+	out := f.tarContext(0)
+	assert.NotContains(t, out, "Build context is")
+}
+
+func TestContextSizeIgnoredFilesDontCount(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.WriteFile("node_modules/left-pad/index.js", strings.Repeat("x", 2000))
+	f.WriteFile("src/main.go", strings.Repeat("x", 100))
+
+	filter, err := dockerignore.NewDockerPatternMatcher(f.Path(), []string{"node_modules"})
+	require.NoError(t, err)
+
+	out := f.tarContextWithFilter(1000, filter)
+	assert.NotContains(t, out, "Build context is")
+}
+
+func (f *fixture) tarContext(sizeWarning int64) string {
+	return f.tarContextWithFilter(sizeWarning, model.EmptyMatcher)
+}
+
+func (f *fixture) tarContextWithFilter(sizeWarning int64, filter model.PathMatcher) string {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	paths := []PathMapping{{LocalPath: f.Path(), ContainerPath: "/"}}
+	err := tarContextAndUpdateDf(ctx, ioutil.Discard, dockerfile.Dockerfile("FROM alpine"), paths, filter, sizeWarning)
+	require.NoError(f.t, err)
+	return out.String()
+}
@@ -2,41 +2,74 @@ package build
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type CustomBuilder interface {
 	Build(ctx context.Context, refs container.RefSet, cb model.CustomBuild) (container.TaggedRefs, error)
+
+	// Runs a custom build that writes an outputs manifest, and returns
+	// the image it built for each output, keyed by the output's ref.
+	BuildOutputs(ctx context.Context, registry container.Registry, cb model.CustomBuild) (map[string]container.TaggedRefs, error)
 }
 
 type ExecCustomBuilder struct {
 	dCli  docker.Client
 	clock Clock
+
+	mu sync.Mutex
+
+	// The custom builds with outputs manifests that are running now, keyed by
+	// the manifest's path. When several resources need images from the same
+	// command at once, they share one run.
+	outputsRuns map[string]*outputsRun
+}
+
+type outputsRun struct {
+	done    chan struct{}
+	outputs map[string]container.TaggedRefs
+	err     error
 }
 
 func NewExecCustomBuilder(dCli docker.Client, clock Clock) *ExecCustomBuilder {
 	return &ExecCustomBuilder{
-		dCli:  dCli,
-		clock: clock,
+		dCli:        dCli,
+		clock:       clock,
+		outputsRuns: make(map[string]*outputsRun),
 	}
 }
 
 func (b *ExecCustomBuilder) Build(ctx context.Context, refs container.RefSet, cb model.CustomBuild) (container.TaggedRefs, error) {
-	workDir := cb.WorkDir
+	if cb.HasOutputsManifest() {
+		outputs, err := b.BuildOutputs(ctx, refs.Registry(), cb)
+		if err != nil {
+			return container.TaggedRefs{}, err
+		}
+		result, ok := outputs[container.FamiliarString(refs.ConfigurationRef)]
+		if !ok {
+			return container.TaggedRefs{}, fmt.Errorf("Custom build outputs manifest %s has no image for %s",
+				cb.OutputsManifest, container.FamiliarString(refs.ConfigurationRef))
+		}
+		return result, nil
+	}
+
 	expectedTag := cb.Tag
-	command := cb.Command
 
 	skipsLocalDocker := cb.SkipsLocalDocker
 	outputsImageRefTo := cb.OutputsImageRefTo
@@ -74,41 +107,19 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, refs container.RefSet, cb
 
 	expectedBuildResult := expectedBuildRefs.LocalRef
 
-	cmd := exec.CommandContext(ctx, command.Argv[0], command.Argv[1:]...)
-	cmd.Dir = workDir
-
-	l := logger.Get(ctx)
-
-	extraEnvVars := []string{}
+	buildEnvVars := []string{}
 	if expectedBuildResult != nil {
-		extraEnvVars = append(extraEnvVars,
+		buildEnvVars = append(buildEnvVars,
 			fmt.Sprintf("EXPECTED_REF=%s", container.FamiliarString(expectedBuildResult)))
 	}
 	if registryHost != "" {
-		extraEnvVars = append(extraEnvVars,
+		buildEnvVars = append(buildEnvVars,
 			fmt.Sprintf("REGISTRY_HOST=%s", registryHost))
 	}
 
-	extraEnvVars = append(extraEnvVars, b.dCli.Env().AsEnviron()...)
-
-	if len(extraEnvVars) == 0 {
-		l.Infof("Custom Build:")
-	} else {
-		l.Infof("Custom Build: Injecting Environment Variables")
-		for _, v := range extraEnvVars {
-			l.Infof("  %s", v)
-		}
-	}
-	cmd.Env = append(os.Environ(), extraEnvVars...)
-
-	w := l.Writer(logger.InfoLvl)
-	cmd.Stdout = w
-	cmd.Stderr = w
-
-	l.Infof("Running custom build cmd %q", command)
-	err = cmd.Run()
+	err = b.runCommand(ctx, cb, buildEnvVars)
 	if err != nil {
-		return container.TaggedRefs{}, errors.Wrap(err, "Custom build command failed")
+		return container.TaggedRefs{}, err
 	}
 
 	if outputsImageRefTo != "" {
@@ -127,10 +138,7 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, refs container.RefSet, cb
 
 	inspect, _, err := b.dCli.ImageInspectWithRaw(ctx, expectedBuildResult.String())
 	if err != nil {
-		return container.TaggedRefs{}, errors.Wrap(err, "Could not find image in Docker\n"+
-			"Did your custom_build script properly tag the image?\n"+
-			"If your custom_build doesn't use Docker, you might need to use skips_local_docker=True, "+
-			"see https://docs.tilt.dev/custom_build.html\n")
+		return container.TaggedRefs{}, imageNotFoundError(err)
 	}
 
 	if outputsImageRefTo != "" {
@@ -159,6 +167,209 @@ func (b *ExecCustomBuilder) Build(ctx context.Context, refs container.RefSet, cb
 	return taggedWithDigest, nil
 }
 
+func (b *ExecCustomBuilder) BuildOutputs(ctx context.Context, registry container.Registry, cb model.CustomBuild) (map[string]container.TaggedRefs, error) {
+	b.mu.Lock()
+	run, ok := b.outputsRuns[cb.OutputsManifest]
+	if ok {
+		b.mu.Unlock()
+		logger.Get(ctx).Infof("Waiting for the custom build command that's already running for %s", cb.OutputsManifest)
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return run.outputs, run.err
+	}
+
+	run = &outputsRun{done: make(chan struct{})}
+	b.outputsRuns[cb.OutputsManifest] = run
+	b.mu.Unlock()
+
+	run.outputs, run.err = b.buildOutputs(ctx, registry, cb)
+
+	b.mu.Lock()
+	delete(b.outputsRuns, cb.OutputsManifest)
+	b.mu.Unlock()
+	close(run.done)
+
+	return run.outputs, run.err
+}
+
+func (b *ExecCustomBuilder) buildOutputs(ctx context.Context, registry container.Registry, cb model.CustomBuild) (map[string]container.TaggedRefs, error) {
+	// The user script MUST write the manifest, so remove the one from the last build.
+	_ = os.Remove(cb.OutputsManifest)
+
+	var buildEnvVars []string
+	if registry.Host != "" {
+		buildEnvVars = append(buildEnvVars, fmt.Sprintf("REGISTRY_HOST=%s", registry.Host))
+	}
+
+	err := b.runCommand(ctx, cb, buildEnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := readOutputsManifest(cb.OutputsManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range cb.OutputRefs {
+		refs, ok := outputs[ref]
+		if !ok {
+			return nil, fmt.Errorf("Custom build outputs manifest %s has no image for %s", cb.OutputsManifest, ref)
+		}
+
+		if cb.SkipsLocalDocker {
+			continue
+		}
+		_, _, err := b.dCli.ImageInspectWithRaw(ctx, refs.LocalRef.String())
+		if err != nil {
+			return nil, imageNotFoundError(err)
+		}
+	}
+	return outputs, nil
+}
+
+// Reads a JSON object that maps the ref of each image to the tagged ref that the command built.
+func readOutputsManifest(path string) (map[string]container.TaggedRefs, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not find outputs manifest. Your custom_build script should have written to %s: %v", path, err)
+	}
+
+	var refStrs map[string]string
+	err = json.Unmarshal(contents, &refStrs)
+	if err != nil {
+		return nil, fmt.Errorf("Outputs manifest %s was invalid: %v", path, err)
+	}
+
+	result := make(map[string]container.TaggedRefs, len(refStrs))
+	for name, refStr := range refStrs {
+		named, err := container.ParseNamed(name)
+		if err != nil {
+			return nil, fmt.Errorf("Outputs manifest %s has an invalid image name %q: %v", path, name, err)
+		}
+		ref, err := container.ParseNamedTagged(refStr)
+		if err != nil {
+			return nil, fmt.Errorf("Outputs manifest %s has an invalid image ref for %s: %v", path, name, err)
+		}
+		result[container.FamiliarString(named)] = container.TaggedRefs{
+			LocalRef:   ref,
+			ClusterRef: ref,
+		}
+	}
+	return result, nil
+}
+
+func (b *ExecCustomBuilder) runCommand(ctx context.Context, cb model.CustomBuild, buildEnvVars []string) error {
+	l := logger.Get(ctx)
+	command := cb.Command
+	argv := command.Argv
+	if cb.Sandbox != "" {
+		var err error
+		argv, err = sandboxArgv(cb, buildEnvVars, b.dCli.Env())
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = cb.WorkDir
+
+	// The Docker env vars point the docker CLI at the right daemon,
+	// which is also the daemon that runs the sandbox.
+	extraEnvVars := append([]string{}, buildEnvVars...)
+	extraEnvVars = append(extraEnvVars, b.dCli.Env().AsEnviron()...)
+
+	if len(extraEnvVars) == 0 {
+		l.Infof("Custom Build:")
+	} else {
+		l.Infof("Custom Build: Injecting Environment Variables")
+		for _, v := range extraEnvVars {
+			l.Infof("  %s", v)
+		}
+	}
+	cmd.Env = append(os.Environ(), extraEnvVars...)
+
+	w := l.Writer(logger.InfoLvl)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if cb.Sandbox != "" {
+		l.Infof("Running custom build cmd %q in sandbox %s", command, cb.Sandbox)
+	} else {
+		l.Infof("Running custom build cmd %q", command)
+	}
+	err := cmd.Run()
+	if err != nil {
+		return errors.Wrap(err, "Custom build command failed")
+	}
+	return nil
+}
+
+func imageNotFoundError(err error) error {
+	return errors.Wrap(err, "Could not find image in Docker\n"+
+		"Did your custom_build script properly tag the image?\n"+
+		"If your custom_build doesn't use Docker, you might need to use skips_local_docker=True, "+
+		"see https://docs.tilt.dev/custom_build.html\n")
+}
+
+// Wraps the custom build command in a `docker run` that runs it in the sandbox image.
+//
+// WorkDir is mounted at the same path, so that paths in the command
+// (and in EXPECTED_REF-style env vars) mean the same thing inside and out.
+//
+// The bind mounts only work if the daemon can see the host's files,
+// so we refuse to sandbox on a remote daemon.
+func sandboxArgv(cb model.CustomBuild, env []string, dEnv docker.Env) ([]string, error) {
+	socket, err := sandboxDockerSocket(dEnv)
+	if err != nil {
+		return nil, err
+	}
+	if !dEnv.CanBindMount(cb.WorkDir) {
+		return nil, fmt.Errorf("custom_build(sandbox=%q): %s isn't shared with the %s VM. "+
+			"Add it to the VM's mounts, or move it under one of: %s",
+			cb.Sandbox, cb.WorkDir, dEnv.VMRuntime, strings.Join(dEnv.VMMounts, ", "))
+	}
+
+	argv := []string{
+		"docker", "run", "--rm",
+		"-v", fmt.Sprintf("%s:%s", cb.WorkDir, cb.WorkDir),
+		// Most custom builds produce an image, so give them the daemon's socket.
+		"-v", fmt.Sprintf("%s:/var/run/docker.sock", socket),
+		"-w", cb.WorkDir,
+	}
+
+	for _, output := range []string{cb.OutputsImageRefTo, cb.OutputsManifest} {
+		if output != "" && !ospath.IsChild(cb.WorkDir, output) {
+			dir := filepath.Dir(output)
+			argv = append(argv, "-v", fmt.Sprintf("%s:%s", dir, dir))
+		}
+	}
+
+	for _, e := range env {
+		argv = append(argv, "-e", e)
+	}
+
+	argv = append(argv, cb.Sandbox)
+	return append(argv, cb.Command.Argv...), nil
+}
+
+// The path of the daemon's socket, as the daemon sees it
+// (since the daemon resolves bind mount sources).
+func sandboxDockerSocket(dEnv docker.Env) (string, error) {
+	if dEnv.Host == "" || dEnv.VMRuntime != docker.VMRuntimeNone {
+		// A VM runtime forwards its socket from the default one inside the VM.
+		return "/var/run/docker.sock", nil
+	}
+	if strings.HasPrefix(dEnv.Host, "unix://") {
+		return strings.TrimPrefix(dEnv.Host, "unix://"), nil
+	}
+	return "", fmt.Errorf("custom_build(sandbox=...) needs a local Docker daemon, so that it can mount your files. "+
+		"DOCKER_HOST is %s", dEnv.Host)
+}
+
 func (b *ExecCustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo string) (container.TaggedRefs, error) {
 	contents, err := ioutil.ReadFile(outputsImageRefTo)
 	if err != nil {
@@ -3,8 +3,11 @@ package build
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -209,6 +212,122 @@ func TestCustomBuildOutputsToImageRefSkipsLocalDocker(t *testing.T) {
 	assert.Equal(f.t, container.MustParseNamed(myTag), refs.ClusterRef)
 }
 
+func TestCustomBuildOutputsManifest(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+	defer f.teardown()
+
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	f.dCli.Images["gcr.io/foo/api:dev"] = types.ImageInspect{ID: string(sha)}
+	f.dCli.Images["gcr.io/foo/worker:dev"] = types.ImageInspect{ID: string(sha)}
+	f.tdf.WriteFile("images.json", `{"gcr.io/foo/api": "gcr.io/foo/api:dev", "gcr.io/foo/worker": "gcr.io/foo/worker:dev"}`)
+	cb := model.CustomBuild{
+		WorkDir:         f.tdf.Path(),
+		Command:         model.ToHostCmd("cp images.json out.json"),
+		OutputsManifest: f.tdf.JoinPath("out.json"),
+		OutputRefs:      []string{"gcr.io/foo/api", "gcr.io/foo/worker"},
+	}
+
+	refs, err := f.cb.Build(f.ctx, refSetFromString("gcr.io/foo/worker"), cb)
+	require.NoError(t, err)
+	assert.Equal(f.t, container.MustParseNamed("gcr.io/foo/worker:dev"), refs.LocalRef)
+	assert.Equal(f.t, container.MustParseNamed("gcr.io/foo/worker:dev"), refs.ClusterRef)
+
+	outputs, err := f.cb.BuildOutputs(f.ctx, container.Registry{}, cb)
+	require.NoError(t, err)
+	assert.Equal(f.t, container.MustParseNamed("gcr.io/foo/api:dev"), outputs["gcr.io/foo/api"].LocalRef)
+}
+
+func TestCustomBuildOutputsManifestMissingOutput(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+	defer f.teardown()
+
+	f.tdf.WriteFile("images.json", `{"gcr.io/foo/api": "gcr.io/foo/api:dev"}`)
+	cb := model.CustomBuild{
+		WorkDir:          f.tdf.Path(),
+		Command:          model.ToHostCmd("cp images.json out.json"),
+		OutputsManifest:  f.tdf.JoinPath("out.json"),
+		OutputRefs:       []string{"gcr.io/foo/api", "gcr.io/foo/worker"},
+		SkipsLocalDocker: true,
+	}
+
+	_, err := f.cb.BuildOutputs(f.ctx, container.Registry{}, cb)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		fmt.Sprintf("Custom build outputs manifest %s has no image for gcr.io/foo/worker", f.tdf.JoinPath("out.json")))
+}
+
+func TestCustomBuildOutputsManifestNotWritten(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+	defer f.teardown()
+
+	// The manifest from the last build shouldn't count.
+	f.tdf.WriteFile("out.json", `{"gcr.io/foo/api": "gcr.io/foo/api:dev"}`)
+	cb := model.CustomBuild{
+		WorkDir:          f.tdf.Path(),
+		Command:          model.ToHostCmd("exit 0"),
+		OutputsManifest:  f.tdf.JoinPath("out.json"),
+		OutputRefs:       []string{"gcr.io/foo/api"},
+		SkipsLocalDocker: true,
+	}
+
+	_, err := f.cb.BuildOutputs(f.ctx, container.Registry{}, cb)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Your custom_build script should have written to")
+}
+
+func TestCustomBuildOutputsManifestSharesRunningCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell loop")
+	}
+	f := newFakeCustomBuildFixture(t)
+	defer f.teardown()
+
+	f.tdf.WriteFile("images.json", `{"gcr.io/foo/api": "gcr.io/foo/api:dev", "gcr.io/foo/worker": "gcr.io/foo/worker:dev"}`)
+	cb := model.CustomBuild{
+		WorkDir: f.tdf.Path(),
+		Command: model.ToHostCmd("touch started; while [ ! -f proceed ]; do sleep 0.01; done; " +
+			"echo run >> runs.txt; cp images.json out.json"),
+		OutputsManifest:  f.tdf.JoinPath("out.json"),
+		OutputRefs:       []string{"gcr.io/foo/api", "gcr.io/foo/worker"},
+		SkipsLocalDocker: true,
+	}
+
+	errs := make(chan error, 2)
+	build := func(ref string) {
+		_, err := f.cb.Build(f.ctx, refSetFromString(ref), cb)
+		errs <- err
+	}
+
+	go build("gcr.io/foo/api")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(f.tdf.JoinPath("started"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	go build("gcr.io/foo/worker")
+	time.Sleep(100 * time.Millisecond)
+	f.tdf.WriteFile("proceed", "")
+
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	runs, err := ioutil.ReadFile(f.tdf.JoinPath("runs.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(runs))
+}
+
+func TestCustomBuildSandboxMountsOutputsManifestOutsideWorkDir(t *testing.T) {
+	cb := model.CustomBuild{
+		WorkDir:         "/src/app",
+		Command:         model.ToUnixCmd("bazel run //:publish"),
+		Sandbox:         "golang:1.21",
+		OutputsManifest: "/tmp/out/images.json",
+	}
+	argv, err := sandboxArgv(cb, nil, docker.Env{})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(argv, " "), "-v /tmp/out:/tmp/out")
+}
+
 type fakeCustomBuildFixture struct {
 	t    *testing.T
 	ctx  context.Context
@@ -217,6 +336,60 @@ type fakeCustomBuildFixture struct {
 	tdf  *tempdir.TempDirFixture
 }
 
+func TestCustomBuildSandboxArgv(t *testing.T) {
+	cb := model.CustomBuild{
+		WorkDir: "/src/app",
+		Command: model.ToUnixCmd("go build ./... && docker build -t $EXPECTED_REF ."),
+		Sandbox: "golang:1.21",
+	}
+	argv, err := sandboxArgv(cb, []string{"EXPECTED_REF=gcr.io/foo/bar:tilt-build-1551202573"}, docker.Env{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker", "run", "--rm",
+		"-v", "/src/app:/src/app",
+		"-v", "/var/run/docker.sock:/var/run/docker.sock",
+		"-w", "/src/app",
+		"-e", "EXPECTED_REF=gcr.io/foo/bar:tilt-build-1551202573",
+		"golang:1.21",
+		"sh", "-c", "go build ./... && docker build -t $EXPECTED_REF .",
+	}, argv)
+}
+
+func TestCustomBuildSandboxMountsDaemonSocket(t *testing.T) {
+	cb := model.CustomBuild{
+		WorkDir: "/src/app",
+		Command: model.ToUnixCmd("docker build ."),
+		Sandbox: "golang:1.21",
+	}
+	argv, err := sandboxArgv(cb, nil, docker.Env{Host: "unix:///run/user/1000/docker.sock"})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(argv, " "), "-v /run/user/1000/docker.sock:/var/run/docker.sock")
+}
+
+func TestCustomBuildSandboxRemoteDaemon(t *testing.T) {
+	cb := model.CustomBuild{
+		WorkDir: "/src/app",
+		Command: model.ToUnixCmd("docker build ."),
+		Sandbox: "golang:1.21",
+	}
+	_, err := sandboxArgv(cb, nil, docker.Env{Host: "tcp://192.168.99.100:2376"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "needs a local Docker daemon")
+	}
+}
+
+func TestCustomBuildSandboxMountsImageRefOutsideWorkDir(t *testing.T) {
+	cb := model.CustomBuild{
+		WorkDir:           "/src/app",
+		Command:           model.ToUnixCmd("ko publish ."),
+		Sandbox:           "golang:1.21",
+		OutputsImageRefTo: "/tmp/out/ref.txt",
+	}
+	argv, err := sandboxArgv(cb, nil, docker.Env{})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(argv, " "), "-v /tmp/out:/tmp/out")
+}
+
 func newFakeCustomBuildFixture(t *testing.T) *fakeCustomBuildFixture {
 	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 	dCli := docker.NewFakeClient()
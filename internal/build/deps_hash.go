@@ -0,0 +1,159 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A hash of the contents of everything a local target depends on.
+//
+// Unlike file events, the hash doesn't change when a file is touched, or when
+// an editor saves it by writing a temp file and renaming it over the original,
+// so it tells us whether re-running the target could do anything different.
+//
+// Ignored files don't count. Missing deps count as empty.
+//
+// Files whose size and mtime haven't changed since we last read them
+// aren't read again.
+func DepsHash(target model.LocalTarget) (string, error) {
+	filter, err := ignore.CreateFileChangeFilter(target)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, dep := range sliceutils.DedupedAndSorted(target.Dependencies()) {
+		err := filepath.Walk(dep, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if info.IsDir() {
+				ignored, err := filter.MatchesEntireDir(path)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ignored, err := filter.Matches(path)
+			if err != nil || ignored {
+				return err
+			}
+			return hashDepFile(h, path, info)
+		})
+		if err != nil {
+			return "", fmt.Errorf("hashing deps of %s: %v", target.ID(), err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashDepFile(h io.Writer, path string, info os.FileInfo) error {
+	_, _ = fmt.Fprintf(h, "%s\x00%o\x00", path, info.Mode())
+	if !info.Mode().IsRegular() {
+		return hashFileContents(h, path, info)
+	}
+
+	digest, err := depFileDigests.get(path, info)
+	if err != nil {
+		return err
+	}
+	_, _ = h.Write(digest)
+	return nil
+}
+
+var depFileDigests = &fileDigestCache{entries: make(map[string]fileDigest)}
+
+type fileDigest struct {
+	size    int64
+	modTime time.Time
+	digest  []byte
+}
+
+// Remembers the digests of file contents, so that we only re-read
+// the files that have changed.
+type fileDigestCache struct {
+	mu      sync.Mutex
+	entries map[string]fileDigest
+}
+
+func (c *fileDigestCache) get(path string, info os.FileInfo) ([]byte, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.digest, nil
+	}
+
+	now := time.Now()
+	d := sha256.New()
+	err := hashFileContents(d, path, info)
+	if err != nil {
+		return nil, err
+	}
+	digest := d.Sum(nil)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Filesystem timestamps are coarse, so a file written in the same tick
+	// as we read it could change again without changing its mtime.
+	// Don't trust the mtime of a file that was modified that recently.
+	if info.ModTime().Before(now.Add(-time.Second)) {
+		c.entries[path] = fileDigest{size: info.Size(), modTime: info.ModTime(), digest: digest}
+	} else {
+		delete(c.entries, path)
+	}
+	return digest, nil
+}
+
+func hashFileContents(h io.Writer, path string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		dest, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00", dest)
+		return nil
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		// The file may have gone away since we walked the dir.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	_, _ = h.Write([]byte{0})
+	return nil
+}
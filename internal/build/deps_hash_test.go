@@ -0,0 +1,93 @@
+package build
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDepsHashIgnoresTouch(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	target := model.NewLocalTarget("foo", model.ToHostCmd("make"), model.Cmd{}, []string{f.JoinPath("src")}, f.Path())
+	before := depsHash(t, target)
+
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(f.JoinPath("src/a.txt"), future, future))
+	assert.Equal(t, before, depsHash(t, target))
+
+	// An atomic save replaces the file with an identical one.
+	f.WriteFile("src/a.txt.tmp", "a")
+	require.NoError(t, os.Rename(f.JoinPath("src/a.txt.tmp"), f.JoinPath("src/a.txt")))
+	assert.Equal(t, before, depsHash(t, target))
+}
+
+func TestDepsHashChanges(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	target := model.NewLocalTarget("foo", model.ToHostCmd("make"), model.Cmd{}, []string{f.JoinPath("src")}, f.Path())
+	hashes := map[string]bool{depsHash(t, target): true}
+
+	f.WriteFile("src/a.txt", "b")
+	hashes[depsHash(t, target)] = true
+
+	f.WriteFile("src/b.txt", "")
+	hashes[depsHash(t, target)] = true
+
+	require.NoError(t, os.Rename(f.JoinPath("src/b.txt"), f.JoinPath("src/c.txt")))
+	hashes[depsHash(t, target)] = true
+
+	f.Rm("src")
+	hashes[depsHash(t, target)] = true
+
+	assert.Equal(t, 5, len(hashes))
+}
+
+func TestDepsHashSkipsIgnoredFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	target := model.NewLocalTarget("foo", model.ToHostCmd("make"), model.Cmd{}, []string{f.JoinPath("src")}, f.Path()).
+		WithIgnores([]model.Dockerignore{{LocalPath: f.JoinPath("src"), Patterns: []string{"*.log"}}})
+	before := depsHash(t, target)
+
+	f.WriteFile("src/debug.log", "lots of output")
+	assert.Equal(t, before, depsHash(t, target))
+}
+
+func TestDepsHashOnlyRereadsChangedFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(f.JoinPath("src/a.txt"), past, past))
+	target := model.NewLocalTarget("foo", model.ToHostCmd("make"), model.Cmd{}, []string{f.JoinPath("src")}, f.Path())
+	before := depsHash(t, target)
+
+	// Same size and mtime, so we trust the digest we already have.
+	f.WriteFile("src/a.txt", "b")
+	require.NoError(t, os.Chtimes(f.JoinPath("src/a.txt"), past, past))
+	assert.Equal(t, before, depsHash(t, target))
+
+	newer := past.Add(time.Minute)
+	require.NoError(t, os.Chtimes(f.JoinPath("src/a.txt"), newer, newer))
+	assert.NotEqual(t, before, depsHash(t, target))
+}
+
+func depsHash(t *testing.T, target model.LocalTarget) string {
+	hash, err := DepsHash(target)
+	require.NoError(t, err)
+	return hash
+}
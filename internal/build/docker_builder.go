@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/docker/distribution/reference"
+	typescontainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
 	controlapi "github.com/moby/buildkit/api/services/control"
@@ -37,9 +38,13 @@ type dockerImageBuilder struct {
 type DockerBuilder interface {
 	BuildImage(ctx context.Context, ps *PipelineState, refs container.RefSet, db model.DockerBuild, filter model.PathMatcher) (container.TaggedRefs, error)
 	DumpImageDeployRef(ctx context.Context, ref string) (reference.NamedTagged, error)
-	PushImage(ctx context.Context, name reference.NamedTagged) error
+	PushImage(ctx context.Context, name reference.NamedTagged) (digest.Digest, error)
 	TagRefs(ctx context.Context, refs container.RefSet, dig digest.Digest) (container.TaggedRefs, error)
+	TagRefsWith(ctx context.Context, refs container.RefSet, src reference.NamedTagged, tag string) (container.TaggedRefs, error)
 	ImageExists(ctx context.Context, ref reference.NamedTagged) (bool, error)
+	ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error)
+	ImageConfig(ctx context.Context, ref reference.NamedTagged) (*typescontainer.Config, error)
+	AnalyzeLayers(ctx context.Context, ref reference.NamedTagged) (model.ImageLayerAnalysis, error)
 }
 
 func DefaultDockerBuilder(b *dockerImageBuilder) DockerBuilder {
@@ -111,17 +116,39 @@ func (d *dockerImageBuilder) TagRefs(ctx context.Context, refs container.RefSet,
 	return tagged, nil
 }
 
+// Tag an image we already built with the given tag.
+func (d *dockerImageBuilder) TagRefsWith(ctx context.Context, refs container.RefSet, src reference.NamedTagged, tag string) (container.TaggedRefs, error) {
+	tagged, err := refs.AddTagSuffix(tag)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "TagImage")
+	}
+
+	err = d.dCli.ImageTag(ctx, src.String(), tagged.LocalRef.String())
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "TagImage#ImageTag")
+	}
+
+	return tagged, nil
+}
+
 // Push the specified ref up to the docker registry specified in the name.
 //
+// Returns the digest that the registry stored the image under, if it told us.
+// Unlike the tag, the digest can only ever refer to the image we just pushed.
+//
 // TODO(nick) In the future, I would like us to be smarter about checking if the kubernetes cluster
 // we're running in has access to the given registry. And if it doesn't, we should either emit an
 // error, or push to a registry that kubernetes does have access to (e.g., a local registry).
-func (d *dockerImageBuilder) PushImage(ctx context.Context, ref reference.NamedTagged) error {
+func (d *dockerImageBuilder) PushImage(ctx context.Context, ref reference.NamedTagged) (digest.Digest, error) {
 	l := logger.Get(ctx)
 
 	imagePushResponse, err := d.dCli.ImagePush(ctx, ref)
 	if err != nil {
-		return errors.Wrap(err, "PushImage#ImagePush")
+		err = errors.Wrap(err, "PushImage#ImagePush")
+		if isRegistryAuthError(err) {
+			return "", model.RegistryAuthError(err)
+		}
+		return "", err
 	}
 
 	defer func() {
@@ -131,12 +158,19 @@ func (d *dockerImageBuilder) PushImage(ctx context.Context, ref reference.NamedT
 		}
 	}()
 
-	_, err = readDockerOutput(ctx, imagePushResponse)
+	output, err := readDockerOutput(ctx, imagePushResponse, model.BuildOutputFull)
 	if err != nil {
-		return errors.Wrapf(err, "pushing image %q", ref.Name())
+		err = errors.Wrapf(err, "pushing image %q", ref.Name())
+		if isRegistryAuthError(err) {
+			return "", model.RegistryAuthError(err)
+		}
+		return "", err
 	}
 
-	return nil
+	if output.aux == nil {
+		return "", nil
+	}
+	return getPushDigestFromAux(*output.aux), nil
 }
 
 func (d *dockerImageBuilder) ImageExists(ctx context.Context, ref reference.NamedTagged) (bool, error) {
@@ -150,8 +184,39 @@ func (d *dockerImageBuilder) ImageExists(ctx context.Context, ref reference.Name
 	return true, nil
 }
 
+func (d *dockerImageBuilder) ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	exists, err := d.dCli.ImageExistsInRegistry(ctx, ref)
+	if err != nil {
+		if isRegistryAuthError(err) {
+			return false, model.RegistryAuthError(err)
+		}
+		return false, errors.Wrapf(err, "error checking if %s is in the registry", ref.String())
+	}
+	return exists, nil
+}
+
+func (d *dockerImageBuilder) ImageConfig(ctx context.Context, ref reference.NamedTagged) (*typescontainer.Config, error) {
+	inspect, _, err := d.dCli.ImageInspectWithRaw(ctx, ref.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error inspecting %s", ref.String())
+	}
+	if inspect.Config == nil {
+		return &typescontainer.Config{}, nil
+	}
+	return inspect.Config, nil
+}
+
+func (d *dockerImageBuilder) AnalyzeLayers(ctx context.Context, ref reference.NamedTagged) (model.ImageLayerAnalysis, error) {
+	r, err := d.dCli.ImageSave(ctx, []string{ref.String()})
+	if err != nil {
+		return model.ImageLayerAnalysis{}, errors.Wrapf(err, "error saving %s", ref.String())
+	}
+	defer func() { _ = r.Close() }()
+	return AnalyzeImageLayers(r, container.FamiliarString(ref))
+}
+
 func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState, db model.DockerBuild, paths []PathMapping, filter model.PathMatcher, refs container.RefSet) (container.TaggedRefs, error) {
-	logger.Get(ctx).Infof("Building Dockerfile:\n%s\n", indent(db.Dockerfile, "  "))
+	buildOutputLogger(ctx, db.OutputVerbosity).Infof("Building Dockerfile:\n%s\n", indent(db.Dockerfile, "  "))
 
 	ps.StartBuildStep(ctx, "Tarring context…")
 
@@ -162,10 +227,14 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		}
 	}
 
+	// If tarring fails, the build fails with whatever error the docker client
+	// made of the broken pipe. Hold onto the original, so we can report that instead.
+	tarErrCh := make(chan error, 1)
 	pr, pw := io.Pipe()
 	go func(ctx context.Context) {
-		err := tarContextAndUpdateDf(ctx, pw, dockerfile.Dockerfile(db.Dockerfile), paths, filter)
+		err := tarContextAndUpdateDf(ctx, pw, dockerfile.Dockerfile(db.Dockerfile), paths, filter, db.ContextWarningSize)
 		if err != nil {
+			tarErrCh <- err
 			_ = pw.CloseWithError(err)
 		} else {
 			_ = pw.Close()
@@ -183,7 +252,7 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		Options(pr, db),
 	)
 	if err != nil {
-		return container.TaggedRefs{}, err
+		return container.TaggedRefs{}, withTarError(err, tarErrCh)
 	}
 
 	defer func() {
@@ -193,9 +262,9 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 		}
 	}()
 
-	digest, err := d.getDigestFromBuildOutput(ps.AttachLogger(ctx), imageBuildResponse.Body)
+	digest, err := d.getDigestFromBuildOutput(ps.AttachLogger(ctx), imageBuildResponse.Body, db.OutputVerbosity)
 	if err != nil {
-		return container.TaggedRefs{}, err
+		return container.TaggedRefs{}, withTarError(err, tarErrCh)
 	}
 
 	tagged, err := d.TagRefs(ctx, refs, digest)
@@ -206,10 +275,14 @@ func (d *dockerImageBuilder) buildFromDf(ctx context.Context, ps *PipelineState,
 	return tagged, nil
 }
 
-func (d *dockerImageBuilder) getDigestFromBuildOutput(ctx context.Context, reader io.Reader) (digest.Digest, error) {
-	result, err := readDockerOutput(ctx, reader)
+func (d *dockerImageBuilder) getDigestFromBuildOutput(ctx context.Context, reader io.Reader, verbosity model.BuildOutputVerbosity) (digest.Digest, error) {
+	result, err := readDockerOutput(ctx, reader, verbosity)
 	if err != nil {
-		return "", errors.Wrap(err, "ImageBuild")
+		err = errors.Wrap(err, "ImageBuild")
+		if isBuildContextError(err) {
+			return "", model.BuildContextError(err)
+		}
+		return "", err
 	}
 
 	digest, err := d.getDigestFromDockerOutput(ctx, result)
@@ -257,12 +330,27 @@ type dockerMessageID string
 // NOTE(nick): I haven't found a good document describing this protocol
 // but you can find it implemented in Docker here:
 // https://github.com/moby/moby/blob/1da7d2eebf0a7a60ce585f89a05cebf7f631019c/pkg/jsonmessage/jsonmessage.go#L139
-func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, error) {
+func readDockerOutput(ctx context.Context, reader io.Reader, verbosity model.BuildOutputVerbosity) (dockerOutput, error) {
 	progressLastPrinted := make(map[dockerMessageID]time.Time)
 
 	result := dockerOutput{}
 	decoder := json.NewDecoder(reader)
-	b := newBuildkitPrinter(logger.Get(ctx))
+	b := newBuildkitPrinter(logger.Get(ctx), verbosity)
+
+	// With the legacy builder, each step's output is just part of the stream.
+	// Keep the output of the current step around, so that we can show it
+	// if the step fails.
+	streamLogger := buildOutputLogger(ctx, verbosity)
+	currentStep := []string{}
+	replayCurrentStep := func() {
+		if showsFullBuildOutput(verbosity) {
+			return
+		}
+		l := logger.Get(ctx).WithFields(logger.Fields{logger.FieldNameBuildDetail: logger.BuildDetailReplay})
+		for _, msg := range currentStep {
+			l.Write(logger.InfoLvl, []byte(msg))
+		}
+	}
 
 	for decoder.More() {
 		message := jsonmessage.JSONMessage{}
@@ -280,14 +368,21 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, erro
 				result.shortDigest = builtDigestMatch[1]
 			}
 
-			logger.Get(ctx).Write(logger.InfoLvl, []byte(msg))
+			if strings.HasPrefix(msg, "Step ") {
+				currentStep = currentStep[:0]
+			}
+			currentStep = append(currentStep, msg)
+
+			streamLogger.Write(logger.InfoLvl, []byte(msg))
 		}
 
 		if message.ErrorMessage != "" {
+			replayCurrentStep()
 			return dockerOutput{}, errors.New(cleanupDockerBuildError(message.ErrorMessage))
 		}
 
 		if message.Error != nil {
+			replayCurrentStep()
 			return dockerOutput{}, errors.New(cleanupDockerBuildError(message.Error.Message))
 		}
 
@@ -305,7 +400,7 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, erro
 				if message.Progress.Current == message.Progress.Total {
 					fields[logger.FieldNameProgressMustPrint] = "1"
 				}
-				logger.Get(ctx).WithFields(fields).
+				buildStatusLogger(ctx, verbosity).WithFields(fields).
 					Infof("%s: %s %s", id, message.Status, message.Progress.String())
 				progressLastPrinted[id] = time.Now()
 			}
@@ -329,6 +424,29 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, erro
 	return result, nil
 }
 
+func showsFullBuildOutput(verbosity model.BuildOutputVerbosity) bool {
+	return verbosity == model.BuildOutputFull || verbosity == model.BuildOutputDefault
+}
+
+// The logger for raw build output. Unless we're showing full output,
+// it's hidden behind `tilt logs --build`.
+func buildOutputLogger(ctx context.Context, verbosity model.BuildOutputVerbosity) logger.Logger {
+	return withBuildDetail(logger.Get(ctx), !showsFullBuildOutput(verbosity))
+}
+
+// The logger for build status lines. In quiet mode,
+// even those are hidden behind `tilt logs --build`.
+func buildStatusLogger(ctx context.Context, verbosity model.BuildOutputVerbosity) logger.Logger {
+	return withBuildDetail(logger.Get(ctx), verbosity == model.BuildOutputQuiet)
+}
+
+func withBuildDetail(l logger.Logger, hidden bool) logger.Logger {
+	if !hidden {
+		return l
+	}
+	return l.WithFields(logger.Fields{logger.FieldNameBuildDetail: logger.BuildDetailHidden})
+}
+
 func toBuildkitStatus(aux *json.RawMessage, b *buildkitPrinter) error {
 	var resp controlapi.StatusResponse
 	var dt []byte
@@ -417,6 +535,22 @@ func getDigestFromAux(aux json.RawMessage) (digest.Digest, error) {
 	return digest.Digest(id), nil
 }
 
+// Registries that don't report a digest leave it empty.
+func getPushDigestFromAux(aux json.RawMessage) digest.Digest {
+	var result struct {
+		Digest string
+	}
+	err := json.Unmarshal(aux, &result)
+	if err != nil {
+		return ""
+	}
+	d, err := digest.Parse(result.Digest)
+	if err != nil {
+		return ""
+	}
+	return d
+}
+
 func digestAsTag(d digest.Digest) (string, error) {
 	str := d.Encoded()
 	if len(str) < 16 {
@@ -16,6 +16,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 const simpleDockerfile = dockerfile.Dockerfile("FROM alpine")
@@ -69,7 +70,7 @@ func TestDigestFromSingleStepOutput(t *testing.T) {
 
 	input := docker.ExampleBuildOutput1
 	expected := digest.Digest("sha256:11cd0b38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
-	actual, err := f.b.getDigestFromBuildOutput(f.ctx, bytes.NewBuffer([]byte(input)))
+	actual, err := f.b.getDigestFromBuildOutput(f.ctx, bytes.NewBuffer([]byte(input)), model.BuildOutputFull)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -85,7 +86,7 @@ func TestDigestFromOutputV1_23(t *testing.T) {
 	input := docker.ExampleBuildOutputV1_23
 	expected := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
 	f.fakeDocker.Images["11cd0b38bc3c"] = types.ImageInspect{ID: string(expected)}
-	actual, err := f.b.getDigestFromBuildOutput(f.ctx, bytes.NewBuffer([]byte(input)))
+	actual, err := f.b.getDigestFromBuildOutput(f.ctx, bytes.NewBuffer([]byte(input)), model.BuildOutputFull)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,6 +95,22 @@ func TestDigestFromOutputV1_23(t *testing.T) {
 	}
 }
 
+func TestPushImageDigest(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	defer f.teardown()
+
+	ref := container.MustParseNamedTagged("localhost:5005/myimage:tilt-11cd0b38bc3ceb95")
+	actual, err := f.b.PushImage(f.ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest("sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1"), actual)
+
+	// Some registries don't report a digest.
+	f.fakeDocker.PushOutput = `{"status":"The push refers to repository [localhost:5005/myimage]"}`
+	actual, err = f.b.PushImage(f.ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, digest.Digest(""), actual)
+}
+
 func TestDumpImageDeployRef(t *testing.T) {
 	f := newFakeDockerBuildFixture(t)
 	defer f.teardown()
@@ -169,7 +186,7 @@ func TestCleanUpBuildKitErrors(t *testing.T) {
 
 			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 			s := makeDockerBuildErrorOutput(tc.buildKitError)
-			_, err := f.b.getDigestFromBuildOutput(ctx, strings.NewReader(s))
+			_, err := f.b.getDigestFromBuildOutput(ctx, strings.NewReader(s), model.BuildOutputFull)
 			require.NotNil(t, err)
 			require.Equal(t, fmt.Sprintf("ImageBuild: %s", tc.expectedTiltError), err.Error())
 		})
@@ -2,6 +2,7 @@ package build
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/util/exec"
@@ -86,3 +87,49 @@ func MaybeRunStepFailure(err error) (RunStepFailure, bool) {
 }
 
 var _ error = RunStepFailure{}
+
+// Messages from the Docker daemon that mean the build context
+// doesn't have the files the Dockerfile asked for.
+var buildContextErrorMessages = []string{
+	"COPY failed",
+	"ADD failed",
+	"failed to compute cache key",
+	"error checking context",
+	"Cannot locate specified Dockerfile",
+}
+
+func isBuildContextError(err error) bool {
+	return containsAny(err.Error(), buildContextErrorMessages)
+}
+
+// Messages from image registries that mean we aren't allowed to push.
+var registryAuthErrorMessages = []string{
+	"unauthorized",
+	"authentication required",
+	"denied:",
+	"no basic auth credentials",
+}
+
+func isRegistryAuthError(err error) bool {
+	return containsAny(err.Error(), registryAuthErrorMessages)
+}
+
+// If building the tarball for the build context failed, that's
+// the real reason the build failed.
+func withTarError(err error, tarErrCh <-chan error) error {
+	select {
+	case tarErr := <-tarErrCh:
+		return model.BuildContextError(errors.Wrap(tarErr, "tarring context"))
+	default:
+		return err
+	}
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package build
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestIsRegistryAuthError(t *testing.T) {
+	assert.True(t, isRegistryAuthError(fmt.Errorf("denied: requested access to the resource is denied")))
+	assert.True(t, isRegistryAuthError(fmt.Errorf("unauthorized: authentication required")))
+	assert.False(t, isRegistryAuthError(fmt.Errorf("manifest unknown")))
+}
+
+func TestIsBuildContextError(t *testing.T) {
+	assert.True(t, isBuildContextError(fmt.Errorf("COPY failed: stat /var/lib/docker/tmp/main.go: no such file or directory")))
+	assert.False(t, isBuildContextError(fmt.Errorf("executor failed running [/bin/sh -c make]: exit code: 2")))
+}
+
+func TestWithTarError(t *testing.T) {
+	buildErr := fmt.Errorf("io: read/write on closed pipe")
+
+	tarErrCh := make(chan error, 1)
+	assert.Equal(t, buildErr, withTarError(buildErr, tarErrCh))
+
+	tarErrCh <- fmt.Errorf("permission denied")
+	err := withTarError(buildErr, tarErrCh)
+	assert.Equal(t, model.ErrorCategoryBuildContext, model.ErrorCategoryOf(err))
+	assert.Contains(t, err.Error(), "permission denied")
+}
@@ -0,0 +1,227 @@
+package build
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Image configs and manifests are tiny. Anything bigger is a layer.
+const maxImageMetadataSize = 1000 * 1000
+
+const whiteoutPrefix = ".wh."
+const whiteoutOpaqueDir = ".wh..wh..opq"
+
+// The manifest.json of a `docker save` tarball.
+type savedImageManifest struct {
+	Config string
+	Layers []string
+}
+
+type savedImageConfig struct {
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+}
+
+// A file or whiteout in a layer tarball.
+type layerEntry struct {
+	path     string
+	size     int64
+	whiteout bool
+	opaque   bool
+}
+
+// Reads an image in the format of `docker save`, and breaks down its size by layer.
+func AnalyzeImageLayers(r io.Reader, image string) (model.ImageLayerAnalysis, error) {
+	var manifests []savedImageManifest
+	metadata := make(map[string][]byte)
+	layers := make(map[string][]layerEntry)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return model.ImageLayerAnalysis{}, errors.Wrap(err, "reading image tarball")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		if name == "manifest.json" {
+			err := json.NewDecoder(tr).Decode(&manifests)
+			if err != nil {
+				return model.ImageLayerAnalysis{}, errors.Wrap(err, "reading manifest.json")
+			}
+			continue
+		}
+
+		if hdr.Size <= maxImageMetadataSize {
+			b, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return model.ImageLayerAnalysis{}, errors.Wrapf(err, "reading %s", name)
+			}
+			metadata[name] = b
+			entries, err := readLayerEntries(bytes.NewReader(b))
+			if err == nil {
+				layers[name] = entries
+			}
+			continue
+		}
+
+		entries, err := readLayerEntries(tr)
+		if err != nil {
+			return model.ImageLayerAnalysis{}, errors.Wrapf(err, "reading layer %s", name)
+		}
+		layers[name] = entries
+	}
+
+	if len(manifests) == 0 {
+		return model.ImageLayerAnalysis{}, fmt.Errorf("image tarball has no manifest.json")
+	}
+	manifest := manifests[0]
+
+	var config savedImageConfig
+	err := json.Unmarshal(metadata[path.Clean(manifest.Config)], &config)
+	if err != nil {
+		return model.ImageLayerAnalysis{}, errors.Wrapf(err, "reading image config %s", manifest.Config)
+	}
+
+	var createdBy []string
+	for _, h := range config.History {
+		if !h.EmptyLayer {
+			createdBy = append(createdBy, cleanCreatedBy(h.CreatedBy))
+		}
+	}
+
+	result := model.ImageLayerAnalysis{Image: image}
+
+	// The size of each file in the image, as of the layers we've seen so far.
+	visible := make(map[string]int64)
+	for i, name := range manifest.Layers {
+		entries, ok := layers[path.Clean(name)]
+		if !ok {
+			return model.ImageLayerAnalysis{}, fmt.Errorf("image tarball is missing layer %s", name)
+		}
+
+		layer := model.ImageLayer{}
+		if i < len(createdBy) {
+			layer.CreatedBy = createdBy[i]
+		}
+
+		// Whiteouts only hide files from lower layers, so apply them first.
+		for _, e := range entries {
+			if e.opaque {
+				result.WastedBytes += removeVisible(visible, e.path, false)
+			} else if e.whiteout {
+				result.WastedBytes += removeVisible(visible, e.path, true)
+			}
+		}
+
+		var files []model.ImageLayerFile
+		for _, e := range entries {
+			if e.whiteout || e.opaque {
+				continue
+			}
+			if prev, ok := visible[e.path]; ok {
+				result.WastedBytes += prev
+			}
+			visible[e.path] = e.size
+			layer.Size += e.size
+			files = append(files, model.ImageLayerFile{Path: e.path, Size: e.size})
+		}
+
+		sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+		if len(files) > model.ImageLayerLargestFilesLimit {
+			files = files[:model.ImageLayerLargestFilesLimit]
+		}
+		layer.LargestFiles = files
+
+		result.Size += layer.Size
+		result.Layers = append(result.Layers, layer)
+	}
+
+	return result, nil
+}
+
+// Lists the regular files and whiteouts in a layer tarball, which may be gzipped.
+func readLayerEntries(r io.Reader) ([]layerEntry, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	var lr io.Reader = br
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gz.Close() }()
+		lr = gz
+	}
+
+	var entries []layerEntry
+	tr := tar.NewReader(lr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p := path.Join("/", hdr.Name)
+		dir, base := path.Split(p)
+		switch {
+		case base == whiteoutOpaqueDir:
+			entries = append(entries, layerEntry{path: path.Clean(dir), opaque: true})
+		case strings.HasPrefix(base, whiteoutPrefix):
+			entries = append(entries, layerEntry{path: path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), whiteout: true})
+		case hdr.Typeflag == tar.TypeReg:
+			entries = append(entries, layerEntry{path: p, size: hdr.Size})
+		}
+	}
+}
+
+// Removes the files under dir (and dir itself, if includeDir) from the
+// visible files, and returns the bytes they took up.
+func removeVisible(visible map[string]int64, dir string, includeDir bool) int64 {
+	removed := int64(0)
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for p, size := range visible {
+		if (includeDir && p == dir) || strings.HasPrefix(p, prefix) {
+			removed += size
+			delete(visible, p)
+		}
+	}
+	return removed
+}
+
+// Turns the shell command that the legacy builder records into something
+// like the Dockerfile instruction.
+func cleanCreatedBy(s string) string {
+	s = strings.TrimSuffix(s, " # buildkit")
+	if strings.HasPrefix(s, "/bin/sh -c #(nop) ") {
+		return strings.TrimSpace(strings.TrimPrefix(s, "/bin/sh -c #(nop) "))
+	}
+	if strings.HasPrefix(s, "/bin/sh -c ") {
+		return "RUN " + strings.TrimPrefix(s, "/bin/sh -c ")
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const layerTestConfig = `{"history": [
+  {"created_by": "/bin/sh -c #(nop) ADD file:abc in / "},
+  {"created_by": "/bin/sh -c #(nop)  CMD [\"sh\"]", "empty_layer": true},
+  {"created_by": "/bin/sh -c apt-get install -y curl"},
+  {"created_by": "RUN /bin/sh -c rm -rf /tmp/cache /var/lib # buildkit"}
+]}`
+
+func TestAnalyzeImageLayers(t *testing.T) {
+	base := layerTarball(t, false, map[string]int{
+		"etc/os-release":     10,
+		"var/lib/apt/lists":  200,
+		"var/lib/dpkg/state": 300,
+	})
+	install := layerTarball(t, false, map[string]int{
+		"usr/bin/curl":      50,
+		"tmp/cache/pkg.deb": 1000,
+		"etc/os-release":    20,
+	})
+	cleanup := layerTarball(t, true, map[string]int{
+		"tmp/cache/.wh..wh..opq": 0,
+		"var/.wh.lib":            0,
+	})
+
+	// The manifest comes last, like in a real `docker save`.
+	image := imageTarball(t, []tarFile{
+		{"1/layer.tar", base},
+		{"2/layer.tar", install},
+		{"3/layer.tar", cleanup},
+		{"config.json", []byte(layerTestConfig)},
+		{"manifest.json", []byte(`[{"Config": "config.json", "Layers": ["1/layer.tar", "2/layer.tar", "3/layer.tar"]}]`)},
+	})
+
+	a, err := AnalyzeImageLayers(bytes.NewReader(image), "myimage:tilt-123")
+	require.NoError(t, err)
+
+	assert.Equal(t, "myimage:tilt-123", a.Image)
+	assert.Equal(t, int64(1580), a.Size)
+
+	// os-release was overwritten, and the rest was deleted in the last layer.
+	assert.Equal(t, int64(10+1000+200+300), a.WastedBytes)
+
+	require.Len(t, a.Layers, 3)
+	assert.Equal(t, "ADD file:abc in /", a.Layers[0].CreatedBy)
+	assert.Equal(t, "RUN apt-get install -y curl", a.Layers[1].CreatedBy)
+	assert.Equal(t, "RUN /bin/sh -c rm -rf /tmp/cache /var/lib", a.Layers[2].CreatedBy)
+
+	assert.Equal(t, []model.ImageLayerFile{
+		{Path: "/tmp/cache/pkg.deb", Size: 1000},
+		{Path: "/usr/bin/curl", Size: 50},
+		{Path: "/etc/os-release", Size: 20},
+	}, a.Layers[1].LargestFiles)
+	assert.Equal(t, int64(0), a.Layers[2].Size)
+}
+
+func TestAnalyzeImageLayersLargestFilesLimit(t *testing.T) {
+	files := make(map[string]int)
+	for i := 1; i <= 10; i++ {
+		files[strings.Repeat("f", i)] = i
+	}
+	image := imageTarball(t, []tarFile{
+		{"manifest.json", []byte(`[{"Config": "config.json", "Layers": ["1/layer.tar"]}]`)},
+		{"config.json", []byte(`{"history": [{"created_by": "COPY . /"}]}`)},
+		{"1/layer.tar", layerTarball(t, false, files)},
+	})
+
+	a, err := AnalyzeImageLayers(bytes.NewReader(image), "myimage")
+	require.NoError(t, err)
+	require.Len(t, a.Layers, 1)
+	require.Len(t, a.Layers[0].LargestFiles, model.ImageLayerLargestFilesLimit)
+	assert.Equal(t, "/ffffffffff", a.Layers[0].LargestFiles[0].Path)
+	assert.Equal(t, 1.0, a.Efficiency())
+}
+
+func TestAnalyzeImageLayersMissingLayer(t *testing.T) {
+	image := imageTarball(t, []tarFile{
+		{"manifest.json", []byte(`[{"Config": "config.json", "Layers": ["1/layer.tar"]}]`)},
+		{"config.json", []byte(`{"history": []}`)},
+	})
+
+	_, err := AnalyzeImageLayers(bytes.NewReader(image), "myimage")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing layer 1/layer.tar")
+}
+
+type tarFile struct {
+	name     string
+	contents []byte
+}
+
+func imageTarball(t *testing.T, files []tarFile) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     f.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(f.contents)),
+		}))
+		_, err := tw.Write(f.contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// A layer with files of the given sizes.
+func layerTarball(t *testing.T, gzipped bool, sizes map[string]int) []byte {
+	var files []tarFile
+	for name, size := range sizes {
+		files = append(files, tarFile{name: name, contents: bytes.Repeat([]byte("x"), size)})
+	}
+	b := imageTarball(t, files)
+	if !gzipped {
+		return b
+	}
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
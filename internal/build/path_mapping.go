@@ -117,7 +117,13 @@ func fileToPathMapping(file string, sync []model.Sync) (pm PathMapping, couldMap
 		relPath, isChild := ospath.Child(s.LocalPath, file)
 		if isChild {
 			localPathIsFile, err := isFile(s.LocalPath)
-			if err != nil {
+			if os.IsNotExist(err) {
+				// The sync root itself was deleted or renamed. If this is a path
+				// under it, the root was a directory. If it's the root, we can't
+				// tell anymore whether it was a file or a directory. Treat it as a
+				// file, so that we never delete the whole destination directory.
+				localPathIsFile = relPath == "."
+			} else if err != nil {
 				return PathMapping{}, false, fmt.Errorf("error stat'ing: %v", err)
 			}
 			var containerPath string
@@ -165,6 +171,10 @@ func SyncsToPathMappings(syncs []model.Sync) []PathMapping {
 }
 
 // Return all the path mappings for local paths that do not exist.
+//
+// When a directory is deleted or renamed, we get a change for the directory
+// and for every file that was in it. Removing the directory removes its
+// contents, so we only return the directory.
 func MissingLocalPaths(ctx context.Context, mappings []PathMapping) (missing, rest []PathMapping, err error) {
 	for _, mapping := range mappings {
 		_, err := os.Stat(mapping.LocalPath)
@@ -179,7 +189,46 @@ func MissingLocalPaths(ctx context.Context, mappings []PathMapping) (missing, re
 			return nil, nil, errors.Wrap(err, "MissingLocalPaths")
 		}
 	}
-	return missing, rest, nil
+	return dedupeNestedContainerPaths(missing), rest, nil
+}
+
+// Drops the path mappings whose container path is inside the container path
+// of another mapping (or the same as an earlier one).
+func dedupeNestedContainerPaths(mappings []PathMapping) []PathMapping {
+	if len(mappings) < 2 {
+		return mappings
+	}
+
+	result := make([]PathMapping, 0, len(mappings))
+	for i, m := range mappings {
+		covered := false
+		for j, other := range mappings {
+			if i == j {
+				continue
+			}
+			if isUnixChild(other.ContainerPath, m.ContainerPath) ||
+				(j < i && path.Clean(other.ContainerPath) == path.Clean(m.ContainerPath)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func isUnixChild(parent, child string) bool {
+	parent = path.Clean(parent)
+	child = path.Clean(child)
+	if parent == child {
+		return false
+	}
+	if parent == "/" {
+		return true
+	}
+	return strings.HasPrefix(child, parent+"/")
 }
 
 func PathMappingsToContainerPaths(mappings []PathMapping) []string {
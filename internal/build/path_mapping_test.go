@@ -1,6 +1,7 @@
 package build
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
@@ -124,3 +125,110 @@ func TestFileNotInSyncYieldsNoMapping(t *testing.T) {
 	assert.Empty(t, actual, "expected no path mapping returned for a file not matching any syncs")
 	assert.Equal(t, files, skipped)
 }
+
+func TestFilesToPathMappingsDeletedSyncRoot(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	files := []string{
+		f.JoinPath("sync1"),
+		f.JoinPath("sync1", "fileA"),
+		f.JoinPath("sync2"),
+	}
+
+	syncs := []model.Sync{
+		model.Sync{
+			LocalPath:     f.JoinPath("sync1"),
+			ContainerPath: "/dest1",
+		},
+		model.Sync{
+			LocalPath:     f.JoinPath("sync2"),
+			ContainerPath: "/dest2/",
+		},
+	}
+
+	actual, skipped, err := FilesToPathMappings(files, syncs)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+
+	expected := []PathMapping{
+		PathMapping{
+			LocalPath:     f.JoinPath("sync1"),
+			ContainerPath: "/dest1",
+		},
+		PathMapping{
+			LocalPath:     f.JoinPath("sync1", "fileA"),
+			ContainerPath: "/dest1/fileA",
+		},
+		// We don't know whether sync2 was a file or a directory, so make
+		// sure we don't delete all of /dest2/.
+		PathMapping{
+			LocalPath:     f.JoinPath("sync2"),
+			ContainerPath: "/dest2/sync2",
+		},
+	}
+
+	assert.ElementsMatch(t, expected, actual)
+	assert.Equal(t, 0, len(skipped))
+}
+
+func TestFilesToPathMappingsDeletedSyncRootChildren(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	files := []string{
+		f.JoinPath("src", "a.py"),
+		f.JoinPath("src", "sub", "b.py"),
+	}
+
+	syncs := []model.Sync{
+		model.Sync{
+			LocalPath:     f.JoinPath("src"),
+			ContainerPath: "/app/",
+		},
+	}
+
+	actual, skipped, err := FilesToPathMappings(files, syncs)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+
+	// src had children, so it was a directory.
+	expected := []PathMapping{
+		PathMapping{
+			LocalPath:     f.JoinPath("src", "a.py"),
+			ContainerPath: "/app/a.py",
+		},
+		PathMapping{
+			LocalPath:     f.JoinPath("src", "sub", "b.py"),
+			ContainerPath: "/app/sub/b.py",
+		},
+	}
+
+	assert.ElementsMatch(t, expected, actual)
+	assert.Equal(t, 0, len(skipped))
+}
+
+func TestMissingLocalPathsDeletedDirectory(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.TouchFiles([]string{filepath.Join("src", "kept")})
+
+	mappings := []PathMapping{
+		PathMapping{LocalPath: f.JoinPath("src", "old", "a"), ContainerPath: "/app/old/a"},
+		PathMapping{LocalPath: f.JoinPath("src", "old"), ContainerPath: "/app/old"},
+		PathMapping{LocalPath: f.JoinPath("src", "old", "sub", "b"), ContainerPath: "/app/old/sub/b"},
+		PathMapping{LocalPath: f.JoinPath("src", "older"), ContainerPath: "/app/older"},
+		PathMapping{LocalPath: f.JoinPath("src", "kept"), ContainerPath: "/app/kept"},
+	}
+
+	missing, rest, err := MissingLocalPaths(context.Background(), mappings)
+	if err != nil {
+		f.T().Fatal(err)
+	}
+
+	assert.Equal(t, []PathMapping{mappings[1], mappings[3]}, missing)
+	assert.Equal(t, []PathMapping{mappings[4]}, rest)
+}
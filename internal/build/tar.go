@@ -22,6 +22,9 @@ type ArchiveBuilder struct {
 	tw     *tar.Writer
 	filter model.PathMatcher
 	paths  []string // local paths archived
+
+	// If non-zero, warn when the archive contents are bigger than this many bytes.
+	sizeWarning int64
 }
 
 func NewArchiveBuilder(writer io.Writer, filter model.PathMatcher) *ArchiveBuilder {
@@ -92,6 +95,8 @@ func (a *ArchiveBuilder) ArchivePathsIfExist(ctx context.Context, paths []PathMa
 	}
 
 	entries = dedupeEntries(entries)
+	warnIfContextTooBig(ctx, entries, a.sizeWarning)
+
 	for _, entry := range entries {
 		err := a.writeEntry(entry)
 		if err != nil {
@@ -241,8 +246,9 @@ func (a *ArchiveBuilder) writeEntry(entry archiveEntry) error {
 	return nil
 }
 
-func tarContextAndUpdateDf(ctx context.Context, writer io.Writer, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher) error {
+func tarContextAndUpdateDf(ctx context.Context, writer io.Writer, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher, sizeWarning int64) error {
 	ab := NewArchiveBuilder(writer, filter)
+	ab.sizeWarning = sizeWarning
 	err := ab.ArchivePathsIfExist(ctx, paths)
 	if err != nil {
 		return errors.Wrap(err, "archivePaths")
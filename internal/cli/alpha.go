@@ -13,6 +13,11 @@ The APIs of these commands may change frequently.
 	}
 
 	addCommand(result, newTiltfileResultCmd())
+	addCommand(result, newTiltfileTestCmd())
+	addCommand(result, newLiveUpdatePreviewCmd())
+	addCommand(result, &convertCmd{})
+	addCommand(result, &watchAgentCmd{})
+	result.AddCommand(newCreateCmd())
 
 	return result
 }
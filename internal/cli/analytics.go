@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"runtime"
 
@@ -10,11 +12,15 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 )
 
 const tiltAppName = "tilt"
 const analyticsURLEnvVar = "TILT_ANALYTICS_URL"
 
+// Lives next to the overall opt-in/opt-out choice file in the windmill dir.
+const analyticsConsentFile = "analytics/user/consent.json"
+
 // Testing analytics locally:
 // (after `npm install http-echo-server -g`)
 // In one window: `PORT=9988 http-echo-server`
@@ -33,6 +39,42 @@ func (ao analyticsOpter) SetUserOpt(opt analytics.Opt) error {
 	return analytics.SetOpt(opt)
 }
 
+func (ao analyticsOpter) ReadUserConsent() (tiltanalytics.Consent, error) {
+	d, err := dirs.UseWindmillDir()
+	if err != nil {
+		return nil, err
+	}
+
+	txt, err := d.ReadFile(analyticsConsentFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tiltanalytics.Consent{}, nil
+		}
+		return nil, err
+	}
+
+	// throw out invalid values, like we do for the choice file
+	consent := tiltanalytics.Consent{}
+	err = json.Unmarshal([]byte(txt), &consent)
+	if err != nil {
+		return tiltanalytics.Consent{}, nil
+	}
+	return consent, nil
+}
+
+func (ao analyticsOpter) SetUserConsent(consent tiltanalytics.Consent) error {
+	d, err := dirs.UseWindmillDir()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(consent)
+	if err != nil {
+		return err
+	}
+	return d.WriteFile(analyticsConsentFile, string(b))
+}
+
 type analyticsLogger struct {
 	logger logger.Logger
 }
@@ -41,8 +83,33 @@ func (al analyticsLogger) Printf(fmt string, v ...interface{}) {
 	al.logger.Debugf(fmt, v...)
 }
 
+// In offline mode, analytics are always opted-out and never leave the process.
+type offlineAnalyticsOpter struct{}
+
+var _ tiltanalytics.AnalyticsOpter = offlineAnalyticsOpter{}
+
+func (offlineAnalyticsOpter) ReadUserOpt() (analytics.Opt, error) {
+	return analytics.OptOut, nil
+}
+
+func (offlineAnalyticsOpter) SetUserOpt(opt analytics.Opt) error {
+	return fmt.Errorf("Tilt is running in offline mode; analytics can't be enabled")
+}
+
+func (offlineAnalyticsOpter) ReadUserConsent() (tiltanalytics.Consent, error) {
+	return tiltanalytics.Consent{}, nil
+}
+
+func (offlineAnalyticsOpter) SetUserConsent(consent tiltanalytics.Consent) error {
+	return fmt.Errorf("Tilt is running in offline mode; analytics consent can't be changed")
+}
+
 func newAnalytics(l logger.Logger, cmdName model.TiltSubcommand, tiltBuild model.TiltBuild,
-	gitRemote git.GitRemote) (*tiltanalytics.TiltAnalytics, error) {
+	gitRemote git.GitRemote, offline model.OfflineMode) (*tiltanalytics.TiltAnalytics, error) {
+	if offline {
+		return tiltanalytics.NewTiltAnalytics(offlineAnalyticsOpter{}, analytics.NewMemoryAnalytics(), tiltBuild.AnalyticsVersion())
+	}
+
 	var err error
 
 	options := []analytics.Option{}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+)
+
+func newAnalyticsConsentCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "consent [CATEGORY=CHOICE ...]",
+		Short: "show or change which categories of analytics Tilt may send",
+		Long: `Shows or changes your analytics consent for each category of events.
+
+Categories:
+  usage        which commands and features you use
+  performance  how long builds, deploys, and Tiltfile loads take
+  errors       errors and crashes
+  all          every category
+
+Choices:
+  opt-in       send events in this category
+  opt-out      don't record or send events in this category
+  local        record events so that 'tilt dump analytics' shows them, but never send them
+  default      use your overall choice from 'tilt analytics opt'
+
+A Tiltfile can override these with analytics_settings().
+`,
+		Example: `tilt analytics consent
+tilt analytics consent performance=opt-out errors=opt-in
+tilt analytics consent all=local`,
+		RunE: analyticsConsent,
+	}
+}
+
+func analyticsConsent(_ *cobra.Command, args []string) error {
+	opter := analyticsOpter{}
+	consent, err := opter.ReadUserConsent()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		printAnalyticsConsent(consent)
+		return nil
+	}
+
+	consent = consent.Copy()
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("expected CATEGORY=CHOICE, got %q", arg)
+		}
+
+		opt, err := tiltanalytics.ParseCategoryOpt(parts[1])
+		if err != nil {
+			return err
+		}
+
+		if parts[0] == "all" {
+			for _, cat := range tiltanalytics.Categories {
+				consent[cat] = opt
+			}
+			continue
+		}
+
+		cat, err := tiltanalytics.ParseCategory(parts[0])
+		if err != nil {
+			return err
+		}
+		consent[cat] = opt
+	}
+
+	err = opter.SetUserConsent(consent)
+	if err != nil {
+		return err
+	}
+	printAnalyticsConsent(consent)
+	return nil
+}
+
+func printAnalyticsConsent(consent tiltanalytics.Consent) {
+	for _, cat := range tiltanalytics.Categories {
+		opt := string(consent.Get(cat))
+		if opt == "" {
+			opt = "default"
+		}
+		fmt.Printf("%-12s %s\n", cat, opt)
+	}
+}
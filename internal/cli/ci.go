@@ -18,9 +18,20 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+// Exit codes for each class of failure. Tiltfile errors share
+// the code that `tilt tiltfile-result` uses.
+var ciExitCodes = map[model.ErrorCategory]int{
+	model.ErrorCategoryTiltfileSyntax: TiltfileErrExitCode,
+	model.ErrorCategoryBuildContext:   6,
+	model.ErrorCategoryRegistryAuth:   7,
+	model.ErrorCategoryClusterConn:    8,
+}
+
 type ciCmd struct {
 	fileName             string
 	outputSnapshotOnExit string
+	outputDir            string
+	rollbackOnFailure    bool
 }
 
 func (c *ciCmd) name() model.TiltSubcommand { return "ci" }
@@ -38,9 +49,22 @@ Exits with failure if any build fails or any server crashes.
 Exits with success if all tasks have completed successfully
 and all servers are healthy.
 
+With --rollback-on-failure, Tilt records each Deployment, StatefulSet, and
+DaemonSet before applying it. If the run fails, Tilt rolls back the ones that
+aren't healthy before exiting, so a shared cluster isn't left running broken
+versions.
+
+Some failures exit with their own code, so that CI systems can route them:
+  %d: the Tiltfile has a syntax error
+  %d: a docker build context is missing files
+  %d: an image registry rejected our credentials
+  %d: Tilt couldn't connect to the Kubernetes cluster
+
 While Tilt is running, you can view the UI at %s:%d
 (configurable with --host and --port).
-`, DefaultWebHost, DefaultWebPort),
+`, ciExitCodes[model.ErrorCategoryTiltfileSyntax], ciExitCodes[model.ErrorCategoryBuildContext],
+			ciExitCodes[model.ErrorCategoryRegistryAuth], ciExitCodes[model.ErrorCategoryClusterConn],
+			DefaultWebHost, DefaultWebPort),
 	}
 
 	addStartServerFlags(cmd)
@@ -51,7 +75,11 @@ While Tilt is running, you can view the UI at %s:%d
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
 	cmd.Flags().Lookup("logactions").Hidden = true
 	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "",
-		"If specified, Tilt will dump a snapshot of its state to the specified path when it exits")
+		"If specified, Tilt will dump a snapshot of its state to the specified path when it exits (gzipped, if the path ends in .gz)")
+	cmd.Flags().StringVar(&c.outputDir, "output-dir", "",
+		"If specified, Tilt will write each resource's logs and status, the applied YAML, and a snapshot to this directory when it exits, for uploading as CI artifacts")
+	cmd.Flags().BoolVar(&c.rollbackOnFailure, "rollback-on-failure", false,
+		"If a Deployment, StatefulSet, or DaemonSet doesn't become healthy, roll it back to the version it was running before Tilt deployed it, then exit")
 
 	return cmd
 }
@@ -66,8 +94,16 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 
 	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
 
+	cleanUpWeb, err := listenWeb(ctx, c.fileName)
+	if err != nil {
+		deferred.SetOutput(deferred.Original())
+		return err
+	}
+	defer cleanUpWeb()
+
 	webHost := provideWebHost()
-	webURL, _ := provideWebURL(webHost, provideWebPort())
+	basePath, _ := provideWebBasePath()
+	webURL, _ := provideWebURL(webHost, provideWebPort(), basePath)
 	startLine := prompt.StartStatusLine(webURL, webHost)
 	log.Print(startLine)
 	log.Print(buildStamp())
@@ -90,15 +126,28 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 	if c.outputSnapshotOnExit != "" {
 		defer cloud.WriteSnapshot(ctx, cmdCIDeps.Store, c.outputSnapshotOnExit)
 	}
+	if c.outputDir != "" {
+		defer writeCIArtifacts(ctx, cmdCIDeps.Store, c.outputDir)
+	}
 
 	engineMode := store.EngineModeCI
 
 	err = upper.Start(ctx, args, cmdCIDeps.TiltBuild, engineMode,
 		c.fileName, store.TerminalModeStream, a.UserOpt(), cmdCIDeps.Token,
-		string(cmdCIDeps.CloudAddress))
+		string(cmdCIDeps.CloudAddress), c.rollbackOnFailure)
 	if err == nil {
 		_, _ = fmt.Fprintln(colorable.NewColorableStdout(),
 			color.GreenString("SUCCESS. All workloads are healthy."))
+	} else if c.rollbackOnFailure {
+		rollbackUnhealthyWorkloads(ctx, cmdCIDeps.Store, cmdCIDeps.K8sClient)
+	}
+	return withCIExitCode(err)
+}
+
+func withCIExitCode(err error) error {
+	code, ok := ciExitCodes[model.ErrorCategoryOf(err)]
+	if !ok {
+		return err
 	}
-	return err
+	return exitCodeError{error: err, code: code}
 }
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/cloud"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The status of one resource when `tilt ci` exited, as written to resources.json.
+type ciResourceStatus struct {
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	BuildStatus    string    `json:"buildStatus"`
+	BuildError     string    `json:"buildError,omitempty"`
+	LastBuildTime  time.Time `json:"lastBuildTime"`
+	RuntimeStatus  string    `json:"runtimeStatus,omitempty"`
+	RuntimeError   string    `json:"runtimeError,omitempty"`
+	LastDeployTime time.Time `json:"lastDeployTime"`
+}
+
+// Writes everything you'd want to look at after a failed CI run to dir:
+//
+//	logs/<resource>.log   the logs of each resource (and the Tiltfile)
+//	tilt.log              all the logs, interleaved
+//	yaml/<resource>.yaml  the YAML we last applied for each Kubernetes resource
+//	resources.json        the status of each resource
+//	snapshot.json         a snapshot that the web UI can open
+func writeCIArtifacts(ctx context.Context, st store.RStore, dir string) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	err := writeCIArtifactsTo(ctx, state, dir)
+	if err != nil {
+		logger.Get(ctx).Errorf("Writing CI artifacts: %v", err)
+		return
+	}
+	logger.Get(ctx).Infof("Wrote CI artifacts to %s", dir)
+}
+
+func writeCIArtifactsTo(ctx context.Context, state store.EngineState, dir string) error {
+	for _, sub := range []string{"logs", "yaml"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	statuses := []ciResourceStatus{ciStatus(model.TiltfileManifestName, "tiltfile", &state.TiltfileState)}
+	names := []model.ManifestName{model.TiltfileManifestName}
+	for _, mt := range state.Targets() {
+		statuses = append(statuses, ciStatus(mt.Manifest.Name, ciResourceType(mt.Manifest), mt.State))
+		names = append(names, mt.Manifest.Name)
+
+		yaml := appliedYAML(mt)
+		if yaml != "" {
+			yaml = string(state.Secrets.Scrub([]byte(yaml)))
+			err := ioutil.WriteFile(filepath.Join(dir, "yaml", artifactFileName(mt.Manifest.Name)+".yaml"), []byte(yaml), 0644)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, mn := range names {
+		err := ioutil.WriteFile(filepath.Join(dir, "logs", artifactFileName(mn)+".log"), []byte(state.LogStore.ManifestLog(mn)), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := ioutil.WriteFile(filepath.Join(dir, "tilt.log"), []byte(state.LogStore.String()), 0644)
+	if err != nil {
+		return err
+	}
+
+	statusJSON, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "resources.json"), append(statusJSON, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "snapshot.json"))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return cloud.WriteSnapshotTo(ctx, state, f)
+}
+
+func ciStatus(name model.ManifestName, resourceType string, ms *store.ManifestState) ciResourceStatus {
+	status := ciResourceStatus{
+		Name:           name.String(),
+		Type:           resourceType,
+		LastDeployTime: ms.LastSuccessfulDeployTime,
+	}
+
+	lastBuild := ms.LastBuild()
+	switch {
+	case ms.IsBuilding():
+		status.BuildStatus = "building"
+	case lastBuild.Empty():
+		status.BuildStatus = "pending"
+	case lastBuild.Error != nil:
+		status.BuildStatus = "error"
+		status.BuildError = lastBuild.Error.Error()
+	default:
+		status.BuildStatus = "ok"
+	}
+	status.LastBuildTime = lastBuild.FinishTime
+
+	if ms.RuntimeState != nil {
+		status.RuntimeStatus = string(ms.RuntimeState.RuntimeStatus())
+		if err := ms.RuntimeState.RuntimeStatusError(); err != nil {
+			status.RuntimeError = err.Error()
+		}
+	}
+	return status
+}
+
+func ciResourceType(m model.Manifest) string {
+	switch {
+	case m.IsK8s():
+		return "k8s"
+	case m.IsDC():
+		return "docker-compose"
+	case m.IsLocal():
+		return "local"
+	}
+	return "unknown"
+}
+
+// The YAML from the most recent successful apply, with verbose fields removed.
+func appliedYAML(mt *store.ManifestTarget) string {
+	if !mt.Manifest.IsK8s() {
+		return ""
+	}
+	result, ok := mt.State.BuildStatus(mt.Manifest.K8sTarget().ID()).LastResult.(store.K8sBuildResult)
+	if !ok {
+		return ""
+	}
+	return result.AppliedEntitiesText
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Resource names can have characters that don't belong in file names, like slashes.
+func artifactFileName(mn model.ManifestName) string {
+	if mn == model.TiltfileManifestName {
+		return "Tiltfile"
+	}
+	return unsafeFileNameChars.ReplaceAllString(mn.String(), "_")
+}
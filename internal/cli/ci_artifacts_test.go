@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestWriteCIArtifacts(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	state := store.NewState()
+	now := time.Now()
+
+	fe := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{Name: "fe"})
+	feTarget := store.NewManifestTarget(fe)
+	feTarget.State.AddCompletedBuild(model.BuildRecord{SpanID: "build:fe", StartTime: now, FinishTime: now})
+	feTarget.State.MutableBuildStatus(fe.K8sTarget().ID()).LastResult = store.K8sBuildResult{
+		AppliedEntitiesText: "kind: Secret\ndata:\n  password: hunter2\n",
+	}
+	state.UpsertManifestTarget(feTarget)
+
+	migrate := model.Manifest{Name: "db/migrate"}.WithDeployTarget(model.LocalTarget{Name: "db/migrate"})
+	migrateTarget := store.NewManifestTarget(migrate)
+	migrateTarget.State.AddCompletedBuild(model.BuildRecord{Error: fmt.Errorf("exit status 1"), StartTime: now, FinishTime: now})
+	state.UpsertManifestTarget(migrateTarget)
+
+	state.Secrets = model.SecretSet{}
+	state.Secrets.AddSecret("db", "password", []byte("hunter2"))
+	state.LogStore.Append(store.NewLogAction("fe", "build:fe", logger.InfoLvl, nil, []byte("building fe\n")), state.Secrets)
+	state.LogStore.Append(store.NewLogAction("db/migrate", "build:migrate", logger.InfoLvl, nil, []byte("no such table\n")), state.Secrets)
+
+	dir := f.JoinPath("artifacts")
+	err := writeCIArtifactsTo(context.Background(), *state, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "building fe\n", readArtifact(t, dir, "logs", "fe.log"))
+	assert.Equal(t, "no such table\n", readArtifact(t, dir, "logs", "db_migrate.log"))
+	assert.Contains(t, readArtifact(t, dir, "tilt.log"), "no such table")
+	assert.Equal(t, "kind: Secret\ndata:\n  password: [redacted secret db:password]\n", readArtifact(t, dir, "yaml", "fe.yaml"))
+	assert.FileExists(t, filepath.Join(dir, "snapshot.json"))
+
+	var statuses []ciResourceStatus
+	err = json.Unmarshal([]byte(readArtifact(t, dir, "resources.json")), &statuses)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "(Tiltfile)", statuses[0].Name)
+	assert.Equal(t, "pending", statuses[0].BuildStatus)
+	assert.Equal(t, "fe", statuses[1].Name)
+	assert.Equal(t, "k8s", statuses[1].Type)
+	assert.Equal(t, "ok", statuses[1].BuildStatus)
+	assert.Equal(t, "db/migrate", statuses[2].Name)
+	assert.Equal(t, "local", statuses[2].Type)
+	assert.Equal(t, "error", statuses[2].BuildStatus)
+	assert.Equal(t, "exit status 1", statuses[2].BuildError)
+}
+
+func readArtifact(t *testing.T, dir string, path ...string) string {
+	contents, err := ioutil.ReadFile(filepath.Join(append([]string{dir}, path...)...))
+	require.NoError(t, err)
+	return string(contents)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Rolls back every workload that isn't healthy to the version it was running
+// before `tilt ci` deployed it.
+func rollbackUnhealthyWorkloads(ctx context.Context, st store.RStore, kCli k8s.Client) {
+	state := st.RLockState()
+	rollbacks := unhealthyRollbackEntities(state)
+	st.RUnlockState()
+
+	l := logger.Get(ctx)
+	for _, previous := range rollbacks {
+		err := rollbackWorkload(ctx, kCli, previous)
+		if err != nil {
+			l.Errorf("Rolling back %s %s: %v", previous.GVK().Kind, previous.Name(), err)
+			continue
+		}
+		l.Infof("Rolled back %s %s to its previous version", previous.GVK().Kind, previous.Name())
+	}
+}
+
+// The recorded versions of workloads in resources that aren't healthy.
+func unhealthyRollbackEntities(state store.EngineState) []k8s.K8sEntity {
+	var result []k8s.K8sEntity
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+
+		rs := mt.State.RuntimeState
+		if rs != nil {
+			status := rs.RuntimeStatus()
+			if status == model.RuntimeStatusOK || status == model.RuntimeStatusNotApplicable {
+				continue
+			}
+		}
+
+		lastResult, ok := mt.State.BuildStatus(mt.Manifest.K8sTarget().ID()).LastResult.(store.K8sBuildResult)
+		if !ok {
+			continue
+		}
+		result = append(result, lastResult.RollbackEntities...)
+	}
+	return result
+}
+
+func rollbackWorkload(ctx context.Context, kCli k8s.Client, previous k8s.K8sEntity) error {
+	ref := previous.ToObjectReference()
+
+	// We may have deleted and re-created the workload, so don't look it up by UID.
+	ref.UID = ""
+	current, err := kCli.GetByReference(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	patch, err := k8s.PodTemplateRollbackPatch(previous, current)
+	if err != nil || patch == nil {
+		return err
+	}
+	return kCli.MergePatch(ctx, ref, patch)
+}
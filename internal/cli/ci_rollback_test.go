@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestRollbackUnhealthyWorkloads(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	st, _ := store.NewStoreWithFakeReducer()
+	state := st.LockMutableStateForTesting()
+
+	fe := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{Name: "fe"})
+	feTarget := store.NewManifestTarget(fe)
+	feTarget.State.MutableBuildStatus(fe.K8sTarget().ID()).LastResult = store.K8sBuildResult{}.
+		WithRollbackEntities([]k8s.K8sEntity{rollbackTestDeployment("fe", "gcr.io/fe:v1")})
+	state.UpsertManifestTarget(feTarget)
+
+	be := model.Manifest{Name: "be"}.WithDeployTarget(model.K8sTarget{Name: "be"})
+	beTarget := store.NewManifestTarget(be)
+	beTarget.State.MutableBuildStatus(be.K8sTarget().ID()).LastResult = store.K8sBuildResult{}.
+		WithRollbackEntities([]k8s.K8sEntity{rollbackTestDeployment("be", "gcr.io/be:v1")})
+	beTarget.State.RuntimeState = store.K8sRuntimeState{
+		HasEverDeployedSuccessfully: true,
+		PodReadinessMode:            model.PodReadinessIgnore,
+	}
+	state.UpsertManifestTarget(beTarget)
+	st.UnlockMutableState()
+
+	kCli := k8s.NewFakeK8sClient()
+	kCli.InjectEntityByName(
+		rollbackTestDeployment("fe", "gcr.io/fe:tilt-123"),
+		rollbackTestDeployment("be", "gcr.io/be:tilt-456"))
+
+	rollbackUnhealthyWorkloads(ctx, st, kCli)
+
+	require.Len(t, kCli.MergePatchCalls, 1)
+	assert.Equal(t, "fe", kCli.MergePatchCalls[0].Ref.Name)
+	assert.JSONEq(t, `{"spec":{"template":{"spec":{"containers":[{"name":"main","image":"gcr.io/fe:v1"}]}}}}`,
+		string(kCli.MergePatchCalls[0].Patch))
+}
+
+func rollbackTestDeployment(name, image string) k8s.K8sEntity {
+	return k8s.NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "main", "image": image},
+					},
+				},
+			},
+		},
+	}})
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCIExitCodes(t *testing.T) {
+	assert.NoError(t, withCIExitCode(nil))
+	assert.Equal(t, 1, exitCode(withCIExitCode(fmt.Errorf("oops"))))
+
+	err := errors.Wrap(model.ClusterConnError(fmt.Errorf("connection refused")), "kubectl apply")
+	assert.Equal(t, 8, exitCode(withCIExitCode(err)))
+
+	err = model.TiltfileSyntaxError(fmt.Errorf("Tiltfile:1:1: got newline"))
+	assert.Equal(t, TiltfileErrExitCode, exitCode(withCIExitCode(err)))
+}
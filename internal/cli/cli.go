@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -52,17 +53,26 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	addCommand(rootCmd, &dockerCmd{})
 	addCommand(rootCmd, &doctorCmd{})
 	addCommand(rootCmd, newDownCmd())
+	addCommand(rootCmd, newInitCmd())
 	addCommand(rootCmd, &versionCmd{})
 	addCommand(rootCmd, &verifyInstallCmd{})
 	addCommand(rootCmd, &dockerPruneCmd{})
 	addCommand(rootCmd, newArgsCmd())
+	addCommand(rootCmd, newEnvCmd())
 	addCommand(rootCmd, &logsCmd{})
+	addCommand(rootCmd, &gcClusterCmd{})
 
-	rootCmd.AddCommand(analytics.NewCommand())
+	analyticsCmd := analytics.NewCommand()
+	analyticsCmd.AddCommand(newAnalyticsConsentCmd())
+	rootCmd.AddCommand(analyticsCmd)
 	rootCmd.AddCommand(newKubectlCmd())
 	rootCmd.AddCommand(newDumpCmd(rootCmd))
 	rootCmd.AddCommand(newTriggerCmd())
+	rootCmd.AddCommand(newGetCmd())
+	rootCmd.AddCommand(newRunNowCmd())
 	rootCmd.AddCommand(newAlphaCmd())
+	rootCmd.AddCommand(newExtCmd())
+	rootCmd.AddCommand(newClusterCmd())
 
 	if len(os.Args) > 2 && os.Args[1] == "kubectl" {
 		// Hack in global flags from kubectl
@@ -73,6 +83,7 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 		globalFlags.BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
 		globalFlags.BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 		globalFlags.IntVar(&klogLevel, "klog", 0, "Enable Kubernetes API logging. Uses klog v-levels (0-4 are debug logs, 5-9 are tracing logs)")
+		addOfflineFlag(globalFlags)
 	}
 
 	if err := rootCmd.Execute(); err != nil {
@@ -152,9 +163,26 @@ func addCommand(parent *cobra.Command, child tiltCmd) {
 			if printErr != nil {
 				panic(printErr)
 			}
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 	}
 
 	parent.AddCommand(cobraChild)
 }
+
+// An error that should make Tilt exit with a specific code,
+// rather than the default of 1.
+type exitCodeError struct {
+	error
+	code int
+}
+
+func (e exitCodeError) Unwrap() error { return e.error }
+
+func exitCode(err error) int {
+	var ece exitCodeError
+	if errors.As(err, &ece) {
+		return ece.code
+	}
+	return 1
+}
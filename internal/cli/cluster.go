@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/cluster"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newClusterCmd() *cobra.Command {
+	result := &cobra.Command{
+		Use:   "cluster",
+		Short: "Create or delete the local cluster that the Tiltfile describes",
+		Long: `Create or delete the local cluster that the Tiltfile describes with cluster().
+
+For example, with this in your Tiltfile:
+
+    cluster('kind', nodes=2, registry=True)
+
+'tilt cluster create' creates a two-node kind cluster with a local image registry,
+and 'tilt cluster delete' deletes them.
+
+Tilt runs the kind, k3d, or minikube CLI to do this, so it must be installed.
+`,
+	}
+
+	addCommand(result, &clusterCmd{action: "create"})
+	addCommand(result, &clusterCmd{action: "delete"})
+
+	return result
+}
+
+type clusterCmd struct {
+	action   string
+	fileName string
+}
+
+func (c *clusterCmd) name() model.TiltSubcommand {
+	return model.TiltSubcommand(fmt.Sprintf("cluster-%s", c.action))
+}
+
+func (c *clusterCmd) register() *cobra.Command {
+	var short string
+	if c.action == "create" {
+		short = "Create the cluster, if it doesn't exist"
+	} else {
+		short = "Delete the cluster"
+	}
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s [-- <Tiltfile args>]", c.action),
+		Short: short,
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	return cmd
+}
+
+func (c *clusterCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	defer a.Flush(time.Second)
+
+	spec, err := c.loadSpec(ctx, args)
+	if err != nil {
+		return err
+	}
+	a.Incr(fmt.Sprintf("cmd.cluster.%s", c.action), map[string]string{"product": string(spec.Product)})
+
+	admin := cluster.NewAdmin()
+	if c.action == "create" {
+		err = admin.Create(ctx, spec)
+		if err != nil {
+			return err
+		}
+		logger.Get(ctx).Infof("Cluster ready. Kubernetes context: %s", spec.KubeContext())
+		return nil
+	}
+
+	return admin.Delete(ctx, spec)
+}
+
+func (c *clusterCmd) loadSpec(ctx context.Context, args []string) (model.ClusterSpec, error) {
+	// Only show the Tiltfile's logs if something goes wrong.
+	l := logger.NewDeferredLogger(ctx)
+	tfCtx := logger.WithLogger(ctx, l)
+
+	deps, err := wireTiltfileResult(tfCtx, analytics.Get(ctx), c.name())
+	if err != nil {
+		l.SetOutput(l.Original())
+		return model.ClusterSpec{}, errors.Wrap(err, "wiring dependencies")
+	}
+
+	tlr := deps.tfl.Load(tfCtx, c.fileName, model.NewUserConfigState(args))
+	if tlr.Error != nil {
+		l.SetOutput(l.Original())
+		return model.ClusterSpec{}, tlr.Error
+	}
+
+	if tlr.Cluster.Empty() {
+		return model.ClusterSpec{}, fmt.Errorf("The Tiltfile doesn't describe a cluster. Add one with cluster(), e.g.: cluster('kind')")
+	}
+	return tlr.Cluster, nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltinit"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type convertCmd struct {
+	fileName string
+	force    bool
+}
+
+func (c *convertCmd) name() model.TiltSubcommand { return "convert" }
+
+func (c *convertCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert [skaffold.yaml|docker-compose.yml]",
+		Short: "Generate a Tiltfile from a skaffold.yaml or docker-compose.yml",
+		Long: `Generate a Tiltfile from a skaffold.yaml or docker-compose.yml.
+
+Translates image builds into docker_build() or custom_build(), deploys into
+k8s_yaml(), kustomize(), helm(), or docker_compose(), port forwards into
+k8s_resource(), and file sync rules into live_update.
+
+Anything that can't be translated is listed in a TODO comment at the top of
+the Tiltfile.
+
+If you don't pass a file, looks for one in the current directory.
+`,
+		Example: "tilt alpha convert\ntilt alpha convert ./deploy/skaffold.yaml --file=./Tiltfile",
+		Args:    cobra.MaximumNArgs(1),
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	cmd.Flags().BoolVar(&c.force, "force", false, "Overwrite the Tiltfile if it already exists")
+	return cmd
+}
+
+func (c *convertCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.convert", nil)
+	defer a.Flush(time.Second)
+
+	srcPath := ""
+	if len(args) > 0 {
+		srcPath = args[0]
+	} else {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		srcPath, err = tiltinit.FindConvertibleFile(wd)
+		if err != nil {
+			return err
+		}
+	}
+
+	absPath, err := filepath.Abs(c.fileName)
+	if err != nil {
+		return err
+	}
+
+	if !c.force {
+		_, err := os.Stat(absPath)
+		if err == nil {
+			return fmt.Errorf("%s already exists. Use --force to overwrite it", absPath)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	plan, err := tiltinit.Convert(srcPath, filepath.Dir(absPath))
+	if err != nil {
+		return err
+	}
+
+	if plan.Empty() {
+		return fmt.Errorf("nothing to convert: %s doesn't build or deploy anything Tilt understands. No Tiltfile written", srcPath)
+	}
+
+	err = ioutil.WriteFile(absPath, []byte(tiltinit.Generate(plan)), 0644)
+	if err != nil {
+		return err
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Wrote %s", absPath)
+	if len(plan.Notes) > 0 {
+		l.Infof("Some things couldn't be translated. See the TODO at the top of the Tiltfile:")
+		for _, note := range plan.Notes {
+			l.Infof("  - %s", note)
+		}
+	}
+	l.Infof("Run 'tilt up' to start!")
+	return nil
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/overlay"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newCreateCmd() *cobra.Command {
+	result := &cobra.Command{
+		Use:   "create",
+		Short: "Adds a resource to a running Tilt, without editing the Tiltfile",
+		Long: `Adds a resource to a running Tilt, without editing the Tiltfile.
+
+Created resources are saved to .tilt-overlay.json, next to the Tiltfile.
+Tilt merges them in every time it loads the Tiltfile, until you delete the file.
+`,
+	}
+
+	addCommand(result, newCreateLocalResourceCmd())
+
+	return result
+}
+
+type createLocalResourceCmd struct {
+	cmd          string
+	serveCmd     string
+	deps         []string
+	resourceDeps []string
+	post         httpPoster
+}
+
+func newCreateLocalResourceCmd() *createLocalResourceCmd {
+	return &createLocalResourceCmd{post: http.Post}
+}
+
+func (c *createLocalResourceCmd) name() model.TiltSubcommand { return "create-local-resource" }
+
+func (c *createLocalResourceCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "local-resource NAME",
+		Short: "Adds a local_resource to a running Tilt",
+		Long: `Adds a local_resource to a running Tilt.
+
+Deps are relative to the Tiltfile's directory. Commands run in the Tiltfile's directory.
+`,
+		Example: "tilt alpha create local-resource proto --cmd='make gen' --deps=./proto",
+		Args:    cobra.ExactArgs(1),
+	}
+
+	addConnectServerFlags(cmd)
+	cmd.Flags().StringVar(&c.cmd, "cmd", "", "Command to run on every update")
+	cmd.Flags().StringVar(&c.serveCmd, "serve-cmd", "", "Long-running command to run after every update")
+	cmd.Flags().StringSliceVar(&c.deps, "deps", nil, "Files or directories that trigger an update when they change")
+	cmd.Flags().StringSliceVar(&c.resourceDeps, "resource-deps", nil, "Resources that must be ready before this one starts")
+
+	return cmd
+}
+
+func (c *createLocalResourceCmd) run(ctx context.Context, args []string) error {
+	r := overlay.LocalResource{
+		Name:         args[0],
+		Cmd:          c.cmd,
+		ServeCmd:     c.serveCmd,
+		Deps:         c.deps,
+		ResourceDeps: c.resourceDeps,
+	}
+	err := r.Validate()
+	if err != nil {
+		return err
+	}
+
+	url := apiURL("overlay/local_resource")
+	body := &bytes.Buffer{}
+	err = json.NewEncoder(body).Encode(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode resource as json")
+	}
+
+	res, err := c.post(url, "application/json", body)
+	if err != nil {
+		fmt.Println("tilt alpha create requires a running Tilt instance")
+		return errors.Wrapf(err, "error making http request to Tilt at %s", url)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		// don't print the response body for 404 since it's full of html and more noise than it's worth on the command line
+		if res.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("http request to Tilt failed: %s", res.Status)
+		}
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("creating %s: %s", r.Name, strings.TrimSpace(string(msg)))
+	}
+
+	fmt.Printf("created local resource %s in Tilt running at %s\n", r.Name, apiHost())
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLocalResource(t *testing.T) {
+	fp := &fakeHttpPoster{}
+	cmd := createLocalResourceCmd{
+		cmd:  "make gen",
+		deps: []string{"./proto"},
+		post: fp.Post,
+	}
+	err := cmd.run(context.Background(), []string{"gen"})
+	require.NoError(t, err)
+	require.Equal(t, "{\"name\":\"gen\",\"cmd\":\"make gen\",\"deps\":[\"./proto\"]}\n", fp.lastRequestBody)
+}
+
+func TestCreateLocalResourceNoCmd(t *testing.T) {
+	fp := &fakeHttpPoster{}
+	cmd := createLocalResourceCmd{post: fp.Post}
+	err := cmd.run(context.Background(), []string{"gen"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must have a cmd and/or a serve_cmd")
+	require.Equal(t, "", fp.lastRequestBody)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
 )
 
@@ -67,7 +68,7 @@ func (c *dockerPruneCmd) run(ctx context.Context, args []string) error {
 
 	imgSelectors := model.LocalRefSelectorsForManifests(tlr.Manifests)
 
-	dp := dockerprune.NewDockerPruner(deps.dCli)
+	dp := dockerprune.NewDockerPruner(deps.dCli, build.ProvideClock())
 
 	// TODO: print the commands being run
 	dp.Prune(ctx, tlr.DockerPruneSettings.MaxAge, tlr.DockerPruneSettings.KeepRecent, imgSelectors)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -11,6 +12,7 @@ import (
 	wmanalytics "github.com/tilt-dev/wmclient/pkg/analytics"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -76,6 +78,8 @@ func (c *doctorCmd) run(ctx context.Context, args []string) error {
 
 		builderVersion := clusterDocker.BuilderVersion()
 		printField("Builder", builderVersion, nil)
+
+		printDockerVM(dockerEnv)
 	}
 
 	if twoDockerClients {
@@ -97,12 +101,23 @@ func (c *doctorCmd) run(ctx context.Context, args []string) error {
 
 			builderVersion := localDocker.BuilderVersion()
 			printField("Builder", builderVersion, nil)
+
+			printDockerVM(dockerEnv)
 		}
 	}
 
 	fmt.Println("---")
 	fmt.Println("Kubernetes")
 
+	// A broken kubeconfig doesn't stop Tilt from starting, so call it out here.
+	kConfig, err := wireKubeConfig(ctx)
+	if err == nil {
+		err = kConfig.Error
+	}
+	if err != nil {
+		printField("Kubeconfig", nil, err)
+	}
+
 	env, err := wireEnv(ctx)
 	printField("Env", env, err)
 
@@ -170,6 +185,16 @@ func clusterLocalRegistryDisplay(ctx context.Context) (string, error) {
 	return fmt.Sprintf("%+v", registry), nil
 }
 
+// Colima, Rancher Desktop, and Lima run the docker daemon in a VM,
+// and bind mounts only work from the host directories that the VM mounts.
+func printDockerVM(env docker.Env) {
+	if env.VMRuntime == docker.VMRuntimeNone {
+		return
+	}
+	printField("VM", env.VMRuntime, nil)
+	printField("VM Mounts", strings.Join(env.VMMounts, ", "), nil)
+}
+
 func printField(name string, v interface{}, err error) {
 	if err != nil {
 		fmt.Printf("- %s: Error: %v\n", name, err)
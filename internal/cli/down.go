@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
@@ -19,11 +25,16 @@ import (
 type downCmd struct {
 	fileName         string
 	deleteNamespaces bool
+	deletePVCs       bool
+	timeout          time.Duration
+	force            bool
+	cascade          string
+	confirm          func(prompt string) bool
 	downDepsProvider func(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (DownDeps, error)
 }
 
 func newDownCmd() *downCmd {
-	return &downCmd{downDepsProvider: wireDownDeps}
+	return &downCmd{downDepsProvider: wireDownDeps, confirm: confirmOnStdin}
 }
 
 func (c *downCmd) name() model.TiltSubcommand { return "down" }
@@ -38,6 +49,15 @@ Deletes resources specified in the Tiltfile
 
 Namespaces are not deleted by default. Use --delete-namespaces to change that.
 
+PersistentVolumeClaims created by StatefulSets are not deleted by default, so that
+you don't lose data. Use --delete-pvcs, or k8s_resource(..., delete_pvcs=True)
+in your Tiltfile, to change that.
+
+Objects with finalizers can get stuck while deleting, if the controller that
+handles their finalizers is gone or broken. 'tilt down' waits up to --timeout for
+them to go away. With --force, it then offers to remove their finalizers, so that
+Kubernetes deletes them without running their cleanup.
+
 There are two types of args:
 1) Tilt flags, listed below, which are handled entirely by Tilt.
 2) Tiltfile args, which can be anything, and are potentially accessed by config.parse in your Tiltfile.
@@ -54,6 +74,10 @@ In that case, see https://tilt.dev/user_config.html and/or comments in your Tilt
 	addTiltfileFlag(cmd, &c.fileName)
 	addKubeContextFlag(cmd)
 	cmd.Flags().BoolVar(&c.deleteNamespaces, "delete-namespaces", false, "delete namespaces defined in the Tiltfile (by default, don't)")
+	cmd.Flags().BoolVar(&c.deletePVCs, "delete-pvcs", false, "delete PersistentVolumeClaims created by StatefulSets (by default, only for resources with delete_pvcs=True)")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", 2*time.Minute, "how long to wait for Kubernetes objects to be deleted (0 waits forever)")
+	cmd.Flags().BoolVar(&c.force, "force", false, "if objects are still stuck after --timeout, ask to remove their finalizers")
+	cmd.Flags().StringVar(&c.cascade, "cascade", "background", "how to delete the objects' dependents: 'background' deletes them after the object, 'foreground' deletes them first")
 
 	return cmd
 }
@@ -71,8 +95,13 @@ func (c *downCmd) run(ctx context.Context, args []string) error {
 }
 
 func (c *downCmd) down(ctx context.Context, downDeps DownDeps, args []string) error {
+	policy, err := c.propagationPolicy()
+	if err != nil {
+		return err
+	}
+
 	tlr := downDeps.tfl.Load(ctx, c.fileName, model.NewUserConfigState(args))
-	err := tlr.Error
+	err = tlr.Error
 	if err != nil {
 		return err
 	}
@@ -100,24 +129,32 @@ func (c *downCmd) down(ctx context.Context, downDeps DownDeps, args []string) er
 		}
 	}
 
+	c.resumeAutoscalers(ctx, downDeps.kClient, tlr.Manifests)
+
+	pvcs, err := c.pvcsToDelete(ctx, downDeps.kClient, downDeps.kNamespace, tlr.Manifests)
+	if err != nil {
+		return errors.Wrap(err, "Finding PersistentVolumeClaims")
+	}
+	entities = append(entities, pvcs...)
+
 	if len(entities) > 0 {
-		err = downDeps.kClient.Delete(ctx, entities)
+		err = c.deleteK8s(ctx, downDeps.kClient, entities, policy)
 		if err != nil {
 			return errors.Wrap(err, "Deleting k8s entities")
 		}
 	}
 
-	var dcConfigPaths []string
+	var dcProject model.DockerComposeProject
 	for _, m := range tlr.Manifests {
 		if m.IsDC() {
-			dcConfigPaths = m.DockerComposeTarget().ConfigPaths
+			dcProject = m.DockerComposeTarget().Project()
 			break
 		}
 	}
 
-	if len(dcConfigPaths) > 0 {
+	if len(dcProject.ConfigPaths) > 0 {
 		dcc := downDeps.dcClient
-		err = dcc.Down(ctx, dcConfigPaths, logger.Get(ctx).Writer(logger.InfoLvl), logger.Get(ctx).Writer(logger.InfoLvl))
+		err = dcc.Down(ctx, dcProject, logger.Get(ctx).Writer(logger.InfoLvl), logger.Get(ctx).Writer(logger.InfoLvl))
 		if err != nil {
 			return errors.Wrap(err, "Running `docker-compose down`")
 		}
@@ -125,3 +162,236 @@ func (c *downCmd) down(ctx context.Context, downDeps DownDeps, args []string) er
 
 	return nil
 }
+
+func (c *downCmd) propagationPolicy() (metav1.DeletionPropagation, error) {
+	switch c.cascade {
+	case "", "background":
+		return metav1.DeletePropagationBackground, nil
+	case "foreground":
+		return metav1.DeletePropagationForeground, nil
+	}
+	return "", fmt.Errorf("Invalid --cascade %q. Must be one of: background, foreground", c.cascade)
+}
+
+// How often we check whether deleted objects are gone.
+var downPollInterval = time.Second
+
+// How often we remind the user what we're still waiting on.
+var downProgressInterval = 10 * time.Second
+
+// An object that we've asked Kubernetes to delete, but that's still around.
+type pendingDelete struct {
+	ref        v1.ObjectReference
+	finalizers []string
+}
+
+func (p pendingDelete) String() string {
+	if len(p.finalizers) == 0 {
+		return refString(p.ref)
+	}
+	return fmt.Sprintf("%s (finalizers: %s)", refString(p.ref), strings.Join(p.finalizers, ", "))
+}
+
+func refString(ref v1.ObjectReference) string {
+	return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+}
+
+func (c *downCmd) deleteK8s(ctx context.Context, kCli k8s.Client, entities []k8s.K8sEntity, policy metav1.DeletionPropagation) error {
+	l := logger.Get(ctx)
+	l.Infof("Deleting Kubernetes objects (%s propagation):", strings.ToLower(string(policy)))
+
+	var pending []pendingDelete
+	for _, e := range entities {
+		ref, err := kCli.DeleteWithPropagation(ctx, e, policy)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				l.Infof("→ %s: already gone", refString(e.ToObjectReference()))
+				continue
+			}
+			return errors.Wrapf(err, "deleting %s", refString(e.ToObjectReference()))
+		}
+		l.Infof("→ %s: deleting", refString(ref))
+		pending = append(pending, pendingDelete{ref: ref})
+	}
+
+	pending, err := c.waitForDeletes(ctx, kCli, pending)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	stuck := pendingList(pending)
+	if !c.force {
+		return fmt.Errorf("Timed out after %s waiting for objects to be deleted:\n%s\n"+
+			"Run with --force to remove their finalizers.", c.timeout, stuck)
+	}
+
+	l.Infof("These objects are still waiting to be deleted:\n%s", stuck)
+	prompt := fmt.Sprintf("Remove the finalizers from %d objects, so that Kubernetes deletes them without running their cleanup?", len(pending))
+	if c.confirm == nil || !c.confirm(prompt) {
+		return fmt.Errorf("Timed out after %s waiting for objects to be deleted, and didn't remove their finalizers:\n%s", c.timeout, stuck)
+	}
+
+	for _, p := range pending {
+		l.Infof("→ %s: removing finalizers", refString(p.ref))
+		err := kCli.MergePatch(ctx, p.ref, k8s.RemoveFinalizersPatch())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "removing finalizers from %s", refString(p.ref))
+		}
+	}
+
+	pending, err = c.waitForDeletes(ctx, kCli, pending)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("Timed out after %s waiting for objects to be deleted, even without their finalizers:\n%s", c.timeout, pendingList(pending))
+	}
+	return nil
+}
+
+// Waits until the objects are gone, or until the timeout.
+//
+// Returns the objects that are still around.
+func (c *downCmd) waitForDeletes(ctx context.Context, kCli k8s.Client, pending []pendingDelete) ([]pendingDelete, error) {
+	l := logger.Get(ctx)
+
+	var deadline <-chan time.Time
+	if c.timeout > 0 {
+		timer := time.NewTimer(c.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	lastProgress := time.Now()
+	for {
+		var remaining []pendingDelete
+		for _, p := range pending {
+			e, err := kCli.GetByReference(ctx, p.ref)
+			if apierrors.IsNotFound(err) {
+				l.Infof("→ %s: deleted", refString(p.ref))
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking on %s", refString(p.ref))
+			}
+			p.finalizers = e.Finalizers()
+			remaining = append(remaining, p)
+		}
+		pending = remaining
+
+		if len(pending) == 0 {
+			return nil, nil
+		}
+
+		if time.Since(lastProgress) >= downProgressInterval {
+			l.Infof("Still waiting for objects to be deleted:\n%s", pendingList(pending))
+			lastProgress = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return pending, nil
+		case <-time.After(downPollInterval):
+		}
+	}
+}
+
+func pendingList(pending []pendingDelete) string {
+	lines := make([]string, 0, len(pending))
+	for _, p := range pending {
+		lines = append(lines, fmt.Sprintf("  %s", p))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func confirmOnStdin(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// Restores the autoscalers that `tilt up` paused for k8s_resource(pause_autoscaling=True),
+// in case it didn't get to restore them when it exited.
+func (c *downCmd) resumeAutoscalers(ctx context.Context, kCli k8s.Client, manifests []model.Manifest) {
+	l := logger.Get(ctx)
+	workloads := make(map[k8s.Namespace]map[string]bool)
+	for _, m := range manifests {
+		if !m.IsK8s() || !m.K8sTarget().PauseAutoscaling {
+			continue
+		}
+
+		entities, err := k8s.ParseYAMLFromString(m.K8sTarget().YAML)
+		if err != nil {
+			l.Warnf("Resuming autoscalers of %s: %v", m.Name, err)
+			continue
+		}
+		for _, e := range entities {
+			if !k8s.IsScalableKind(e.GVK().Kind) {
+				continue
+			}
+			ns := e.Namespace()
+			if workloads[ns] == nil {
+				workloads[ns] = make(map[string]bool)
+			}
+			workloads[ns][fmt.Sprintf("%s/%s", e.GVK().Kind, e.Name())] = true
+		}
+	}
+
+	for ns, names := range workloads {
+		autoscalers, err := kCli.ListAutoscalers(ctx, ns)
+		if err != nil {
+			l.Warnf("Resuming autoscalers in namespace %s: %v", ns, err)
+			continue
+		}
+
+		for _, a := range autoscalers {
+			kind, name, ok := a.AutoscalerTarget()
+			if !ok || !names[fmt.Sprintf("%s/%s", kind, name)] {
+				continue
+			}
+
+			patch, err := k8s.ResumeAutoscalerPatch(a)
+			if err == nil && patch != nil {
+				l.Infof("Resuming %s %s", a.GVK().Kind, a.Name())
+				err = kCli.MergePatch(ctx, a.ToObjectReference(), patch)
+			}
+			if err != nil {
+				l.Warnf("Resuming %s %s: %v", a.GVK().Kind, a.Name(), err)
+			}
+		}
+	}
+}
+
+// Kubernetes doesn't delete the PVCs that a StatefulSet creates, even when the
+// StatefulSet is deleted, so we have to find them ourselves.
+func (c *downCmd) pvcsToDelete(ctx context.Context, kCli k8s.Client, ns k8s.Namespace, manifests []model.Manifest) ([]k8s.K8sEntity, error) {
+	var result []k8s.K8sEntity
+	for _, m := range manifests {
+		if !m.IsK8s() || !(c.deletePVCs || m.K8sTarget().DeletePVCs) {
+			continue
+		}
+
+		entities, err := k8s.ParseYAMLFromString(m.K8sTarget().YAML)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entities {
+			pvcs, err := k8s.StatefulSetPVCs(ctx, kCli, e, ns)
+			if err != nil {
+				return nil, err
+			}
+			for _, pvc := range pvcs {
+				logger.Get(ctx).Infof("Deleting PersistentVolumeClaim %s/%s", pvc.Namespace(), pvc.Name())
+			}
+			result = append(result, pvcs...)
+		}
+	}
+	return result, nil
+}
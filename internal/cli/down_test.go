@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
@@ -60,6 +64,91 @@ func TestDownDeletesNamespacesIfSpecified(t *testing.T) {
 	}
 }
 
+func TestDownPreservesPVCsByDefault(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.kCli.PersistentVolumeClaims = []v1.PersistentVolumeClaim{redisPVC("redis-data-test-redis-master-0")}
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sStatefulSetManifest(false)}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "test-redis-master")
+	assert.NotContains(t, f.kCli.DeletedYaml, "PersistentVolumeClaim")
+}
+
+func TestDownDeletesPVCsIfSpecifiedInTiltfile(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.kCli.PersistentVolumeClaims = []v1.PersistentVolumeClaim{
+		redisPVC("redis-data-test-redis-master-0"),
+		redisPVC("redis-data-some-other-statefulset-0"),
+	}
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sStatefulSetManifest(true)}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "redis-data-test-redis-master-0")
+	assert.NotContains(t, f.kCli.DeletedYaml, "some-other-statefulset")
+}
+
+func TestDownDeletesPVCsIfFlagSpecified(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.kCli.PersistentVolumeClaims = []v1.PersistentVolumeClaim{redisPVC("redis-data-test-redis-master-0")}
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sStatefulSetManifest(false)}
+	f.cmd.deletePVCs = true
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "redis-data-test-redis-master-0")
+}
+
+func TestDownDeletesPVCsInKubeContextNamespace(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	pvc := redisPVC("redis-data-test-redis-master-0")
+	pvc.Namespace = "sandbox"
+	f.kCli.PersistentVolumeClaims = []v1.PersistentVolumeClaim{pvc}
+	f.deps.kNamespace = "sandbox"
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sStatefulSetManifest(true)}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "redis-data-test-redis-master-0")
+}
+
+func TestDownResumesPausedAutoscalers(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	paused := k8s.NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata": map[string]interface{}{
+			"name":        "sancho",
+			"namespace":   "default",
+			"annotations": map[string]interface{}{k8s.PausedAutoscalingAnnotation: `{"minReplicas":2,"maxReplicas":10}`},
+		},
+		"spec": map[string]interface{}{
+			"minReplicas":    int64(1),
+			"maxReplicas":    int64(1),
+			"scaleTargetRef": map[string]interface{}{"kind": "Deployment", "name": "sancho"},
+		},
+	}})
+	f.kCli.Autoscalers = []k8s.K8sEntity{paused}
+
+	kTarget := k8s.MustTarget("sancho", testyaml.SanchoYAML)
+	kTarget.PauseAutoscaling = true
+	m := model.Manifest{Name: "sancho"}.WithDeployTarget(kTarget)
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{m}}
+
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	require.Len(t, f.kCli.MergePatchCalls, 1)
+	assert.Equal(t, "sancho", f.kCli.MergePatchCalls[0].Ref.Name)
+	assert.Contains(t, string(f.kCli.MergePatchCalls[0].Patch), `"maxReplicas":10`)
+}
+
 func TestDownK8sFails(t *testing.T) {
 	f := newDownFixture(t)
 	defer f.TearDown()
@@ -72,6 +161,78 @@ func TestDownK8sFails(t *testing.T) {
 	}
 }
 
+func TestDownCascadeForeground(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sManifest()}
+	f.cmd.cascade = "foreground"
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	require.Len(t, f.kCli.DeleteWithPropagationCalls, 1)
+	assert.Equal(t, metav1.DeletePropagationForeground, f.kCli.DeleteWithPropagationCalls[0].Policy)
+}
+
+func TestDownInvalidCascade(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sManifest()}
+	f.cmd.cascade = "orphan"
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Invalid --cascade "orphan"`)
+	}
+	assert.Empty(t, f.kCli.DeleteWithPropagationCalls)
+}
+
+func TestDownStuckFinalizerTimesOut(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.setUpStuckFinalizer()
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Deployment/stuck (finalizers: example.com/cleanup)")
+		assert.Contains(t, err.Error(), "Run with --force")
+	}
+	assert.Empty(t, f.kCli.MergePatchCalls)
+}
+
+func TestDownForceRemovesFinalizers(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.setUpStuckFinalizer()
+	f.cmd.force = true
+	var prompts []string
+	f.cmd.confirm = func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return true
+	}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+	require.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "Remove the finalizers from 1 objects")
+	require.Len(t, f.kCli.MergePatchCalls, 1)
+	assert.Equal(t, "stuck", f.kCli.MergePatchCalls[0].Ref.Name)
+	assert.Equal(t, string(k8s.RemoveFinalizersPatch()), string(f.kCli.MergePatchCalls[0].Patch))
+}
+
+func TestDownForceDeclined(t *testing.T) {
+	f := newDownFixture(t)
+	defer f.TearDown()
+
+	f.setUpStuckFinalizer()
+	f.cmd.force = true
+	f.cmd.confirm = func(prompt string) bool { return false }
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "didn't remove their finalizers")
+	}
+	assert.Empty(t, f.kCli.MergePatchCalls)
+}
+
 func TestDownDCFails(t *testing.T) {
 	f := newDownFixture(t)
 	defer f.TearDown()
@@ -105,6 +266,44 @@ func newK8sManifest() []model.Manifest {
 	return []model.Manifest{model.Manifest{Name: "fe"}.WithDeployTarget(k8s.MustTarget("fe", testyaml.SanchoYAML))}
 }
 
+func newK8sStatefulSetManifest(deletePVCs bool) []model.Manifest {
+	kTarget := k8s.MustTarget("redis", testyaml.RedisStatefulSetYAML)
+	kTarget.DeletePVCs = deletePVCs
+	return []model.Manifest{model.Manifest{Name: "redis"}.WithDeployTarget(kTarget)}
+}
+
+func redisPVC(name string) v1.PersistentVolumeClaim {
+	return v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "redis", "release": "test", "role": "master"},
+		},
+	}
+}
+
+const stuckFinalizerYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: stuck
+  namespace: default
+  finalizers:
+  - example.com/cleanup
+spec:
+  selector:
+    matchLabels:
+      app: stuck
+  template:
+    metadata:
+      labels:
+        app: stuck
+    spec:
+      containers:
+      - name: stuck
+        image: stuck
+`
+
 func newDCManifest() []model.Manifest {
 	return []model.Manifest{model.Manifest{Name: "fe"}.WithDeployTarget(model.DockerComposeTarget{
 		Name:        "fe",
@@ -140,7 +339,7 @@ func newDownFixture(t *testing.T) downFixture {
 	tfl := tiltfile.NewFakeTiltfileLoader()
 	dcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
 	kCli := k8s.NewFakeK8sClient()
-	downDeps := DownDeps{tfl, dcc, kCli}
+	downDeps := DownDeps{tfl, dcc, kCli, "default"}
 	cmd := &downCmd{downDepsProvider: func(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (deps DownDeps, err error) {
 		return downDeps, nil
 	}}
@@ -156,6 +355,17 @@ func newDownFixture(t *testing.T) downFixture {
 	}
 }
 
+// Sets up a Deployment whose finalizer never finishes, until someone removes it.
+func (f *downFixture) setUpStuckFinalizer() {
+	entities, err := k8s.ParseYAMLFromString(stuckFinalizerYAML)
+	require.NoError(f.t, err)
+	f.kCli.InjectEntityByName(entities...)
+
+	m := model.Manifest{Name: "stuck"}.WithDeployTarget(k8s.MustTarget("stuck", stuckFinalizerYAML))
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{m}}
+	f.cmd.timeout = 10 * time.Millisecond
+}
+
 func (f *downFixture) TearDown() {
 	f.cancel()
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"github.com/spf13/cobra/doc"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -33,8 +35,13 @@ and may change frequently.
 	result.AddCommand(newDumpWebviewCmd())
 	result.AddCommand(newDumpEngineCmd())
 	result.AddCommand(newDumpLogStoreCmd())
+	result.AddCommand(newDumpTiltfileProfileCmd())
+	result.AddCommand(newDumpReloadTriggersCmd())
+	result.AddCommand(newDumpAnalyticsCmd())
+	result.AddCommand(newDumpGoroutinesCmd())
 	result.AddCommand(newDumpCliDocsCmd(rootCmd))
 	result.AddCommand(newDumpImageDeployRefCmd())
+	result.AddCommand(newDumpAPIDocsCmd())
 
 	return result
 }
@@ -97,6 +104,82 @@ and may change frequently.
 	return cmd
 }
 
+func newDumpTiltfileProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tiltfile-profile",
+		Short: "dump where the time went in the last Tiltfile load",
+		Long: `Dumps a breakdown of the last Tiltfile load of a running Tilt to stdout.
+
+Shows the wall time spent executing each file (the Tiltfile, and each file
+it load()s), and the wall time and number of calls of each builtin, slowest first.
+
+Tilt also prints this breakdown to the Tiltfile log when a load is slow.
+`,
+		Run:  dumpTiltfileProfile,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func newDumpReloadTriggersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reload-triggers",
+		Short: "dump the files that will trigger a Tiltfile reload",
+		Long: `Dumps every file and directory that will trigger a Tiltfile reload in a running Tilt.
+
+Tilt reloads the Tiltfile when a file it read changes (with read_file(),
+load(), and friends), or when anything under a path passed to watch_file()
+or listdir(recursive=True) changes. Directories are watched recursively,
+so a directory near the root of your repo is a common cause of spurious reloads.
+
+To read a file without making it a reload trigger, use read_file(path, watch=False).
+`,
+		Run:  dumpReloadTriggers,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func newDumpAnalyticsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analytics",
+		Short: "dump the analytics events recorded by a running Tilt",
+		Long: `Dumps the analytics events that a running Tilt has recorded to stdout.
+
+Includes events that Tilt didn't send, because you opted out of their category
+or chose local-only analytics (see 'tilt analytics consent'). Each event's
+"Sent" field says whether Tilt sent it.
+
+Tilt keeps the most recent 1000 events.
+`,
+		Run:  dumpAnalytics,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func newDumpGoroutinesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "goroutines",
+		Short: "dump the stack of every goroutine in a running Tilt",
+		Long: `Dumps the stack of every goroutine in a running Tilt to stdout.
+
+If Tilt seems stuck (e.g., the (Tilt) resource says it's falling behind),
+attach this dump to your bug report. It works even when the engine is hung.
+
+For CPU and heap profiles, a running Tilt also serves the standard Go pprof
+endpoints under /debug/pprof/.
+`,
+		Run:  dumpGoroutines,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
 type dumpCliDocsCmd struct {
 	rootCmd *cobra.Command
 	dir     string
@@ -139,6 +222,22 @@ func (c *dumpCliDocsCmd) run(cmd *cobra.Command, args []string) {
 	}
 }
 
+func newDumpAPIDocsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "api-docs",
+		Short: "Dumps the OpenAPI schema of the HUD server",
+		Long: `Dumps a swagger 2.0 document describing the JSON API of the HUD server to stdout.
+
+Includes the webview protocol (the JSON used to render the React UX), and the
+payloads that the HUD server accepts. Intended for generating clients.
+
+The same document is served by a running Tilt at /api/schema.
+`,
+		Run:  dumpAPIDocs,
+		Args: cobra.NoArgs,
+	}
+}
+
 func newDumpImageDeployRefCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "image-deploy-ref REF",
@@ -196,6 +295,18 @@ func dumpWebview(cmd *cobra.Command, args []string) {
 	}
 }
 
+func dumpAPIDocs(cmd *cobra.Command, args []string) {
+	doc, err := server.APISchema()
+	if err != nil {
+		cmdFail(fmt.Errorf("dump api-docs: %v", err))
+	}
+
+	err = encodeJSON(doc)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump api-docs: %v", err))
+	}
+}
+
 func dumpEngine(cmd *cobra.Command, args []string) {
 	body := apiGet("dump/engine")
 	defer func() {
@@ -245,6 +356,81 @@ func dumpLogStore(cmd *cobra.Command, args []string) {
 	}
 }
 
+func dumpTiltfileProfile(cmd *cobra.Command, args []string) {
+	body := apiGet("dump/engine")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var result struct {
+		TiltfileProfile *model.TiltfileProfile
+	}
+	err := json.NewDecoder(body).Decode(&result)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump tiltfile-profile: %v", err))
+	}
+
+	if result.TiltfileProfile == nil || result.TiltfileProfile.Empty() {
+		cmdFail(fmt.Errorf("No Tiltfile profile yet. Has the Tiltfile finished loading?"))
+	}
+
+	fmt.Print(result.TiltfileProfile.String())
+}
+
+func dumpReloadTriggers(cmd *cobra.Command, args []string) {
+	body := apiGet("dump/engine")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var result struct {
+		ConfigFiles []string
+	}
+	err := json.NewDecoder(body).Decode(&result)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump reload-triggers: %v", err))
+	}
+
+	printReloadTriggers(os.Stdout, result.ConfigFiles)
+}
+
+func printReloadTriggers(w io.Writer, paths []string) {
+	sorted := append([]string{}, paths...)
+	sort.Strings(sorted)
+	for _, p := range sorted {
+		info, err := os.Stat(p)
+		if err == nil && info.IsDir() {
+			_, _ = fmt.Fprintf(w, "%s (directory, watched recursively)\n", p)
+			continue
+		}
+		_, _ = fmt.Fprintln(w, p)
+	}
+}
+
+func dumpGoroutines(cmd *cobra.Command, args []string) {
+	body := apiGet("dump/goroutines")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	_, err := io.Copy(os.Stdout, body)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump goroutines: %v", err))
+	}
+}
+
+func dumpAnalytics(cmd *cobra.Command, args []string) {
+	body := apiGet("dump/analytics")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	err := dumpJSON(body)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump analytics: %v", err))
+	}
+}
+
 func dumpJSON(reader io.Reader) error {
 	result, err := decodeJSON(reader)
 	if err != nil {
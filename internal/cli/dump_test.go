@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestPrintReloadTriggers(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("Tiltfile", "")
+	f.MkdirAll("config")
+
+	out := &bytes.Buffer{}
+	printReloadTriggers(out, []string{f.JoinPath("config"), f.JoinPath("Tiltfile")})
+	assert.Equal(t, fmt.Sprintf("%s\n%s (directory, watched recursively)\n",
+		f.JoinPath("Tiltfile"), f.JoinPath("config")), out.String())
+}
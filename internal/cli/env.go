@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type envCmd struct {
+	unset bool
+	post  httpPoster
+}
+
+func newEnvCmd() *envCmd {
+	return &envCmd{post: http.Post}
+}
+
+func (c *envCmd) name() model.TiltSubcommand { return "env" }
+
+func (c *envCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "env [<flags>] NAME=VALUE | --unset NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Changes an environment variable of a running Tilt",
+		Long: `Changes an environment variable of a running Tilt.
+
+Tilt records the environment variables that the Tiltfile reads (with os.environ or os.getenv).
+If the Tiltfile read the variable, Tilt reloads the Tiltfile with the new value.
+
+Examples:
+
+tilt env DEBUG=1
+tilt env --unset DEBUG
+`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	addConnectServerFlags(cmd)
+	cmd.Flags().BoolVar(&c.unset, "unset", false, "Unset the variable, instead of setting it")
+
+	return cmd
+}
+
+func (c *envCmd) run(ctx context.Context, args []string) error {
+	payload := map[string]interface{}{}
+	if c.unset {
+		if strings.Contains(args[0], "=") {
+			return fmt.Errorf("--unset takes a variable name, not NAME=VALUE: %s", args[0])
+		}
+		payload["name"] = args[0]
+		payload["unset"] = true
+	} else {
+		pair := strings.SplitN(args[0], "=", 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return fmt.Errorf("expected NAME=VALUE, got: %s", args[0])
+		}
+		payload["name"] = pair[0]
+		payload["value"] = pair[1]
+	}
+
+	url := apiURL("env")
+	body := &bytes.Buffer{}
+	err := json.NewEncoder(body).Encode(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode env as json")
+	}
+
+	res, err := c.post(url, "application/json", body)
+	if err != nil {
+		fmt.Println("tilt env requires a running Tilt instance")
+		return errors.Wrapf(err, "error making http request to Tilt at %s", url)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		if res.StatusCode != http.StatusNotFound {
+			_, err := io.Copy(os.Stderr, res.Body)
+			if err != nil {
+				return errors.Wrapf(err, "http request to Tilt returned non-OK status %s and writing the content of the http response failed", res.Status)
+			}
+		}
+		return fmt.Errorf("http request to Tilt failed: %s", res.Status)
+	}
+
+	if c.unset {
+		fmt.Printf("unset %s for Tilt running at %s\n", payload["name"], apiHost())
+	} else {
+		fmt.Printf("set %s for Tilt running at %s\n", payload["name"], apiHost())
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSet(t *testing.T) {
+	f := newEnvFixture()
+	err := f.cmd.run(context.Background(), []string{"DEBUG=a=b"})
+	require.NoError(t, err)
+	require.Equal(t, "{\"name\":\"DEBUG\",\"value\":\"a=b\"}\n", f.fakeHttpPoster.lastRequestBody)
+}
+
+func TestEnvUnset(t *testing.T) {
+	f := newEnvFixture()
+	f.cmd.unset = true
+	err := f.cmd.run(context.Background(), []string{"DEBUG"})
+	require.NoError(t, err)
+	require.Equal(t, "{\"name\":\"DEBUG\",\"unset\":true}\n", f.fakeHttpPoster.lastRequestBody)
+}
+
+func TestEnvSetNoValue(t *testing.T) {
+	f := newEnvFixture()
+	err := f.cmd.run(context.Background(), []string{"DEBUG"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected NAME=VALUE")
+}
+
+func TestEnvUnsetWithValue(t *testing.T) {
+	f := newEnvFixture()
+	f.cmd.unset = true
+	err := f.cmd.run(context.Background(), []string{"DEBUG=1"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--unset takes a variable name")
+}
+
+type envFixture struct {
+	cmd            envCmd
+	fakeHttpPoster *fakeHttpPoster
+}
+
+func newEnvFixture() *envFixture {
+	fp := &fakeHttpPoster{}
+	return &envFixture{cmd: envCmd{post: fp.Post}, fakeHttpPoster: fp}
+}
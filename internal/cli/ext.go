@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newExtCmd() *cobra.Command {
+	result := &cobra.Command{
+		Use:   "ext",
+		Short: "Manage the Tilt extensions that your Tiltfile loads",
+		Long: `Manage the Tilt extensions that your Tiltfile loads with load('ext://...').
+
+Extensions are fetched from a registry the first time they're loaded, and cached
+in the tilt_modules directory next to your Tiltfile, along with the version
+they were fetched at.
+
+The default registry is ` + tiltextension.DefaultRegistry + `.
+To fetch new extensions from a different registry, set TILT_EXTENSIONS_REGISTRY.
+`,
+	}
+
+	addCommand(result, newExtListCmd())
+	addCommand(result, newExtUpdateCmd())
+	addCommand(result, newExtPinCmd(true))
+	addCommand(result, newExtPinCmd(false))
+
+	return result
+}
+
+type extFetcherFactory func(registry string) (tiltextension.Fetcher, error)
+
+func newGithubFetcher(registry string) (tiltextension.Fetcher, error) {
+	dlr, err := tiltextension.NewTempDirDownloader()
+	if err != nil {
+		return nil, err
+	}
+	return tiltextension.NewGithubFetcher(dlr, registry), nil
+}
+
+// The extension store of the project that the Tiltfile lives in.
+func extStore(fileName string) (*tiltextension.LocalStore, error) {
+	absPath, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return tiltextension.NewLocalStore(filepath.Dir(absPath)), nil
+}
+
+// Re-fetch an extension from the registry it was originally fetched from.
+func fetchExt(ctx context.Context, newFetcher extFetcherFactory, store *tiltextension.LocalStore,
+	metadata tiltextension.Metadata, version string) (tiltextension.Metadata, error) {
+	registry := tiltextension.RegistryImportPath(metadata.ExtensionRegistry)
+	if registry == "" {
+		registry = tiltextension.RegistryFromEnv()
+	}
+
+	fetcher, err := newFetcher(registry)
+	if err != nil {
+		return tiltextension.Metadata{}, err
+	}
+	defer func() {
+		_ = fetcher.CleanUp()
+	}()
+
+	contents, err := fetcher.Fetch(ctx, metadata.Name, version)
+	if err != nil {
+		return tiltextension.Metadata{}, err
+	}
+
+	_, err = store.Write(ctx, contents)
+	if err != nil {
+		return tiltextension.Metadata{}, err
+	}
+	return store.ModuleMetadata(ctx, metadata.Name)
+}
+
+func describeExtVersion(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	if len(version) > 12 {
+		return version[:12]
+	}
+	return version
+}
+
+type extListCmd struct {
+	fileName string
+}
+
+func newExtListCmd() *extListCmd {
+	return &extListCmd{}
+}
+
+func (c *extListCmd) name() model.TiltSubcommand { return "ext-list" }
+
+func (c *extListCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the extensions cached for this Tiltfile, and the versions they're at",
+		Args:  cobra.NoArgs,
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	return cmd
+}
+
+func (c *extListCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.extList", nil)
+	defer a.Flush(time.Second)
+
+	store, err := extStore(c.fileName)
+	if err != nil {
+		return err
+	}
+
+	exts, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(exts) == 0 {
+		logger.Get(ctx).Infof("No extensions found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(logger.Get(ctx).Writer(logger.InfoLvl), 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tVERSION\tPINNED\tFETCHED\tREGISTRY")
+	for _, ext := range exts {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%s\n", ext.Name, describeExtVersion(ext.Version),
+			ext.Pinned, ext.TimeFetched.Format(time.RFC3339), ext.ExtensionRegistry)
+	}
+	return w.Flush()
+}
+
+type extUpdateCmd struct {
+	fileName   string
+	newFetcher extFetcherFactory
+}
+
+func newExtUpdateCmd() *extUpdateCmd {
+	return &extUpdateCmd{newFetcher: newGithubFetcher}
+}
+
+func (c *extUpdateCmd) name() model.TiltSubcommand { return "ext-update" }
+
+func (c *extUpdateCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [<extension>...]",
+		Short: "Fetch the latest version of extensions",
+		Long: `Fetch the latest version of the given extensions, or of all extensions if none are given.
+
+Pinned extensions are skipped. Use 'tilt ext unpin' to let them update again.
+`,
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	return cmd
+}
+
+func (c *extUpdateCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.extUpdate", nil)
+	defer a.Flush(time.Second)
+
+	store, err := extStore(c.fileName)
+	if err != nil {
+		return err
+	}
+	return c.update(ctx, store, args)
+}
+
+func (c *extUpdateCmd) update(ctx context.Context, store *tiltextension.LocalStore, names []string) error {
+	exts, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	toUpdate := exts
+	if len(names) > 0 {
+		toUpdate = nil
+		for _, name := range names {
+			metadata, err := store.ModuleMetadata(ctx, name)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("extension %q not found. Load it from your Tiltfile to fetch it", name)
+			} else if err != nil {
+				return err
+			}
+			toUpdate = append(toUpdate, metadata)
+		}
+	}
+
+	l := logger.Get(ctx)
+	for _, ext := range toUpdate {
+		if ext.Pinned {
+			l.Infof("%s: pinned at %s, skipping", ext.Name, describeExtVersion(ext.Version))
+			continue
+		}
+
+		updated, err := fetchExt(ctx, c.newFetcher, store, ext, "")
+		if err != nil {
+			return errors.Wrapf(err, "updating %s", ext.Name)
+		}
+
+		if updated.Version == ext.Version && updated.Version != "" {
+			l.Infof("%s: already up to date at %s", ext.Name, describeExtVersion(ext.Version))
+		} else {
+			l.Infof("%s: updated %s -> %s", ext.Name, describeExtVersion(ext.Version), describeExtVersion(updated.Version))
+		}
+	}
+	return nil
+}
+
+// Handles both `tilt ext pin` and `tilt ext unpin`.
+type extPinCmd struct {
+	fileName   string
+	pin        bool
+	newFetcher extFetcherFactory
+}
+
+func newExtPinCmd(pin bool) *extPinCmd {
+	return &extPinCmd{pin: pin, newFetcher: newGithubFetcher}
+}
+
+func (c *extPinCmd) name() model.TiltSubcommand {
+	if c.pin {
+		return "ext-pin"
+	}
+	return "ext-unpin"
+}
+
+func (c *extPinCmd) register() *cobra.Command {
+	var cmd *cobra.Command
+	if c.pin {
+		cmd = &cobra.Command{
+			Use:   "pin <extension> [<version>]",
+			Short: "Keep an extension at a specific version",
+			Long: `Keep an extension at a specific version, so that 'tilt ext update' skips it.
+
+If a version (a git commit or tag in the extension registry) is given, fetches
+the extension at that version first. Otherwise, pins the version it's at now.
+
+If you check in tilt_modules/extensions.json, everyone on your team
+will fetch the pinned version.
+`,
+			Args: cobra.RangeArgs(1, 2),
+		}
+	} else {
+		cmd = &cobra.Command{
+			Use:   "unpin <extension>",
+			Short: "Let 'tilt ext update' update a pinned extension again",
+			Args:  cobra.ExactArgs(1),
+		}
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	return cmd
+}
+
+func (c *extPinCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	event := "cmd.extPin"
+	if !c.pin {
+		event = "cmd.extUnpin"
+	}
+	a.Incr(event, nil)
+	defer a.Flush(time.Second)
+
+	store, err := extStore(c.fileName)
+	if err != nil {
+		return err
+	}
+
+	version := ""
+	if len(args) > 1 {
+		version = args[1]
+	}
+	return c.setPinned(ctx, store, args[0], version)
+}
+
+func (c *extPinCmd) setPinned(ctx context.Context, store *tiltextension.LocalStore, name string, version string) error {
+	metadata, err := store.ModuleMetadata(ctx, name)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("extension %q not found. Load it from your Tiltfile to fetch it", name)
+	} else if err != nil {
+		return err
+	}
+
+	l := logger.Get(ctx)
+	if !c.pin {
+		err := store.SetPinned(ctx, name, false)
+		if err != nil {
+			return err
+		}
+		l.Infof("%s: unpinned", name)
+		return nil
+	}
+
+	if version != "" && version != metadata.Version {
+		metadata, err = fetchExt(ctx, c.newFetcher, store, metadata, version)
+		if err != nil {
+			return errors.Wrapf(err, "fetching %s at %s", name, version)
+		}
+	}
+
+	if metadata.Version == "" {
+		return fmt.Errorf("extension %q has no recorded version to pin. Run 'tilt ext update %s' first", name, name)
+	}
+
+	err = store.SetPinned(ctx, name, true)
+	if err != nil {
+		return err
+	}
+	l.Infof("%s: pinned at %s", name, describeExtVersion(metadata.Version))
+	return nil
+}
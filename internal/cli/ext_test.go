@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
+)
+
+func TestExtUpdateSkipsPinned(t *testing.T) {
+	f := newExtFixture(t)
+	defer f.TearDown()
+
+	f.write("foo", "v1")
+	f.write("bar", "v1")
+	require.NoError(t, f.store.SetPinned(f.ctx, "bar", true))
+
+	f.fetcher.latest = "v2"
+	cmd := newExtUpdateCmd()
+	cmd.newFetcher = f.newFetcher
+	require.NoError(t, cmd.update(f.ctx, f.store, nil))
+
+	f.assertVersion("foo", "v2")
+	f.assertVersion("bar", "v1")
+	assert.Equal(t, "github.com/tilt-dev/tilt-extensions", f.fetcher.registry)
+}
+
+func TestExtUpdateUnknownExtension(t *testing.T) {
+	f := newExtFixture(t)
+	defer f.TearDown()
+
+	cmd := newExtUpdateCmd()
+	cmd.newFetcher = f.newFetcher
+	err := cmd.update(f.ctx, f.store, []string{"foo"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `extension "foo" not found`)
+	}
+}
+
+func TestExtPinAtVersion(t *testing.T) {
+	f := newExtFixture(t)
+	defer f.TearDown()
+
+	f.write("foo", "v2")
+
+	cmd := newExtPinCmd(true)
+	cmd.newFetcher = f.newFetcher
+	require.NoError(t, cmd.setPinned(f.ctx, f.store, "foo", "v1"))
+
+	f.assertVersion("foo", "v1")
+	metadata, err := f.store.ModuleMetadata(f.ctx, "foo")
+	require.NoError(t, err)
+	assert.True(t, metadata.Pinned)
+
+	unpin := newExtPinCmd(false)
+	require.NoError(t, unpin.setPinned(f.ctx, f.store, "foo", ""))
+	metadata, err = f.store.ModuleMetadata(f.ctx, "foo")
+	require.NoError(t, err)
+	assert.False(t, metadata.Pinned)
+}
+
+type extFixture struct {
+	*tempdir.TempDirFixture
+	t       *testing.T
+	ctx     context.Context
+	store   *tiltextension.LocalStore
+	fetcher *fakeExtFetcher
+}
+
+func newExtFixture(t *testing.T) *extFixture {
+	f := tempdir.NewTempDirFixture(t)
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	return &extFixture{
+		TempDirFixture: f,
+		t:              t,
+		ctx:            ctx,
+		store:          tiltextension.NewLocalStore(f.Path()),
+		fetcher:        &fakeExtFetcher{tmp: f},
+	}
+}
+
+func (f *extFixture) newFetcher(registry string) (tiltextension.Fetcher, error) {
+	f.fetcher.registry = registry
+	return f.fetcher, nil
+}
+
+func (f *extFixture) write(name, version string) {
+	f.WriteFile(fmt.Sprintf("registry/%s/Tiltfile", name), fmt.Sprintf("print('%s')", version))
+	_, err := f.store.Write(f.ctx, tiltextension.ModuleContents{
+		Name:              name,
+		Dir:               f.JoinPath("registry", name),
+		ExtensionRegistry: "https://github.com/tilt-dev/tilt-extensions",
+		Version:           version,
+		TimeFetched:       time.Now(),
+	})
+	require.NoError(f.t, err)
+}
+
+func (f *extFixture) assertVersion(name, version string) {
+	metadata, err := f.store.ModuleMetadata(f.ctx, name)
+	require.NoError(f.t, err)
+	assert.Equal(f.t, version, metadata.Version)
+	contents, err := ioutil.ReadFile(f.JoinPath("tilt_modules", name, "Tiltfile"))
+	require.NoError(f.t, err)
+	assert.Equal(f.t, fmt.Sprintf("print('%s')", version), string(contents))
+}
+
+// Serves every extension at whatever version is asked for.
+type fakeExtFetcher struct {
+	tmp      *tempdir.TempDirFixture
+	registry string
+	latest   string
+}
+
+func (f *fakeExtFetcher) Fetch(ctx context.Context, moduleName string, version string) (tiltextension.ModuleContents, error) {
+	if version == "" {
+		version = f.latest
+	}
+	dir := fmt.Sprintf("fetched/%s-%s", moduleName, version)
+	f.tmp.WriteFile(dir+"/Tiltfile", fmt.Sprintf("print('%s')", version))
+	return tiltextension.ModuleContents{
+		Name:              moduleName,
+		Dir:               f.tmp.JoinPath(dir),
+		ExtensionRegistry: "https://" + f.registry,
+		Version:           version,
+		TimeFetched:       time.Now(),
+	}, nil
+}
+
+func (f *fakeExtFetcher) CleanUp() error {
+	return nil
+}
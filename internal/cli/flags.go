@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"os"
+	"strconv"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // Common flags used across multiple commands.
@@ -20,14 +25,17 @@ func addKubeContextFlag(cmd *cobra.Command) {
 
 // For commands that talk to the web server.
 func addConnectServerFlags(cmd *cobra.Command) {
-	cmd.Flags().IntVar(&webPort, "port", DefaultWebPort, "Port for the Tilt HTTP server. Only necessary if you started Tilt with --port.")
-	cmd.Flags().StringVar(&webHost, "host", DefaultWebHost, "Host for the Tilt HTTP server. Only necessary if you started Tilt with --host.")
+	cmd.Flags().IntVar(&webPort, "port", DefaultWebPort, "Port for the Tilt HTTP server. Only necessary if you started Tilt with --port and it's not running from this directory.")
+	cmd.Flags().StringVar(&webHost, "host", DefaultWebHost, "Host for the Tilt HTTP server. Only necessary if you started Tilt with --host and it's not running from this directory.")
+	cmd.PreRun = discoverWebServerPreRun
 }
 
 // For commands that start a web server.
 func addStartServerFlags(cmd *cobra.Command) {
-	cmd.Flags().IntVar(&webPort, "port", DefaultWebPort, "Port for the Tilt HTTP server. Set to 0 to disable.")
+	cmd.Flags().IntVar(&webPort, "port", DefaultWebPort, "Port for the Tilt HTTP server. If it's in use, Tilt picks the next free port. Set to 0 to disable.")
 	cmd.Flags().StringVar(&webHost, "host", DefaultWebHost, "Host for the Tilt HTTP server and default host for any port-forwards. Set to 0.0.0.0 to listen on all interfaces.")
+	cmd.Flags().StringVar(&webBasePath, "web-base-path", "", "Path that a reverse proxy serves the Tilt web UI under (e.g., /tilt). Tilt serves the UI at both this path and the root, so the proxy may or may not strip it.")
+	cmd.Flags().StringSliceVar(&webCORSOrigins, "web-cors-origin", nil, "Origins (e.g., https://ide.example.com) that can call the Tilt web API from a browser. Use * to allow any origin.")
 }
 
 func addDevServerFlags(cmd *cobra.Command) {
@@ -40,3 +48,19 @@ var kubeContextOverride string
 func ProvideKubeContextOverride() k8s.KubeContextOverride {
 	return k8s.KubeContextOverride(kubeContextOverride)
 }
+
+const offlineEnvVar = "TILT_OFFLINE"
+
+var offline bool
+
+func addOfflineFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(&offline, "offline", false, "Disable all of Tilt's own network calls (analytics, Tilt Cloud, update checks, telemetry). Can also be set with "+offlineEnvVar+"=1")
+}
+
+func ProvideOfflineMode() model.OfflineMode {
+	if offline {
+		return true
+	}
+	envOffline, _ := strconv.ParseBool(os.Getenv(offlineEnvVar))
+	return model.OfflineMode(envOffline)
+}
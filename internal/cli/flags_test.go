@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tilt-dev/wmclient/pkg/analytics"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestOfflineModeFromEnv(t *testing.T) {
+	defer os.Unsetenv(offlineEnvVar)
+
+	os.Unsetenv(offlineEnvVar)
+	assert.Equal(t, model.OfflineMode(false), ProvideOfflineMode())
+
+	os.Setenv(offlineEnvVar, "1")
+	assert.Equal(t, model.OfflineMode(true), ProvideOfflineMode())
+
+	os.Setenv(offlineEnvVar, "nonsense")
+	assert.Equal(t, model.OfflineMode(false), ProvideOfflineMode())
+}
+
+func TestOfflineAnalyticsNeverOptIn(t *testing.T) {
+	a, err := newAnalytics(logger.NewLogger(logger.InfoLvl, os.Stdout), "up", model.TiltBuild{Version: "0.0.0"}, "", model.OfflineMode(true))
+	if assert.NoError(t, err) {
+		assert.Error(t, a.SetUserOpt(analytics.OptIn))
+	}
+}
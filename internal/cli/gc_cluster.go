@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type gcClusterCmd struct {
+	maxAge      time.Duration
+	dryRun      bool
+	labelPrefix string
+}
+
+func (c *gcClusterCmd) name() model.TiltSubcommand { return "gc-cluster" }
+
+func (c *gcClusterCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc-cluster",
+		Short: "Delete objects left behind on the cluster by Tilt sessions that are no longer running",
+		Long: `
+Every object that Tilt deploys is labeled with the Tilt session that deployed it.
+While 'tilt up' is running, it periodically refreshes a heartbeat on each of those objects.
+
+If a session exits without running 'tilt down' (e.g., a laptop was closed),
+its heartbeats go stale. This command finds every object on the cluster
+whose session heartbeat is older than --max-age and deletes it.
+
+Useful for cleaning up shared dev clusters.
+`,
+	}
+
+	cmd.Flags().DurationVar(&c.maxAge, "max-age", time.Hour,
+		"Delete objects whose session heartbeat is older than this")
+	cmd.Flags().BoolVar(&c.dryRun, "dry-run", false,
+		"Print the objects that would be deleted, without deleting them")
+	cmd.Flags().StringVar(&c.labelPrefix, "label-prefix", k8s.DefaultLabelPrefix,
+		"The prefix of the session labels to look for, if the Tiltfile changed it with k8s_managed_labels()")
+	addKubeContextFlag(cmd)
+
+	return cmd
+}
+
+func (c *gcClusterCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.gcCluster", map[string]string{"dryRun": fmt.Sprintf("%v", c.dryRun)})
+	defer a.Flush(time.Second)
+
+	kCli, err := wireK8sClient(ctx)
+	if err != nil {
+		return err
+	}
+	return c.gc(ctx, kCli, time.Now())
+}
+
+func (c *gcClusterCmd) gc(ctx context.Context, kCli k8s.Client, now time.Time) error {
+	keys := k8s.NewSessionKeys(c.labelPrefix)
+	entities, err := kCli.ListBySelector(ctx, k8s.SessionSelector(keys))
+	if err != nil {
+		return err
+	}
+
+	l := logger.Get(ctx)
+	stale := []k8s.K8sEntity{}
+	for _, e := range entities {
+		// Objects with owners (e.g., the ReplicaSets of a Deployment) inherit our
+		// labels, but get cleaned up by Kubernetes when their owners are deleted.
+		if len(e.OwnerReferences()) > 0 {
+			continue
+		}
+
+		session, heartbeat, ok := k8s.SessionFromEntity(e, keys)
+		if !ok || now.Sub(heartbeat) < c.maxAge {
+			continue
+		}
+
+		l.Infof("%s %s/%s (session %s, last heartbeat %s)",
+			e.GVK().Kind, e.Namespace(), e.Name(), session, describeHeartbeat(heartbeat))
+		stale = append(stale, e)
+	}
+
+	if len(stale) == 0 {
+		l.Infof("No objects from dead Tilt sessions found")
+		return nil
+	}
+
+	if c.dryRun {
+		l.Infof("Dry run: would delete %d objects", len(stale))
+		return nil
+	}
+
+	err = kCli.Delete(ctx, stale)
+	if err != nil {
+		return err
+	}
+	l.Infof("Deleted %d objects", len(stale))
+	return nil
+}
+
+func describeHeartbeat(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Format(time.RFC3339)
+}
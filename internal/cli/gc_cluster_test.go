@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestGCClusterDeletesStaleSessions(t *testing.T) {
+	f := newGCClusterFixture(t)
+
+	f.addEntity(testyaml.SanchoYAML, f.now.Add(-2*time.Hour))
+	f.addEntity(testyaml.DoggosServiceYaml, f.now.Add(-time.Minute))
+
+	err := f.cmd.gc(f.ctx, f.kCli, f.now)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "sancho")
+	assert.NotContains(t, f.kCli.DeletedYaml, "doggos")
+}
+
+func TestGCClusterDryRun(t *testing.T) {
+	f := newGCClusterFixture(t)
+	f.cmd.dryRun = true
+
+	f.addEntity(testyaml.SanchoYAML, f.now.Add(-2*time.Hour))
+
+	err := f.cmd.gc(f.ctx, f.kCli, f.now)
+	require.NoError(t, err)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+}
+
+func TestGCClusterIgnoresUnlabeledObjects(t *testing.T) {
+	f := newGCClusterFixture(t)
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	f.kCli.ListedEntities = append(f.kCli.ListedEntities, entities...)
+
+	err = f.cmd.gc(f.ctx, f.kCli, f.now)
+	require.NoError(t, err)
+	assert.Equal(t, "", f.kCli.DeletedYaml)
+}
+
+func TestGCClusterLabelPrefix(t *testing.T) {
+	f := newGCClusterFixture(t)
+	f.cmd.labelPrefix = "example.com"
+
+	f.addEntity(testyaml.SanchoYAML, f.now.Add(-2*time.Hour))
+
+	err := f.cmd.gc(f.ctx, f.kCli, f.now)
+	require.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "sancho")
+	assert.Contains(t, f.kCli.DeletedYaml, "example.com/session")
+}
+
+type gcClusterFixture struct {
+	t    *testing.T
+	ctx  context.Context
+	now  time.Time
+	cmd  *gcClusterCmd
+	kCli *k8s.FakeK8sClient
+}
+
+func newGCClusterFixture(t *testing.T) *gcClusterFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	return &gcClusterFixture{
+		t:    t,
+		ctx:  ctx,
+		now:  time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		cmd:  &gcClusterCmd{maxAge: time.Hour},
+		kCli: k8s.NewFakeK8sClient(),
+	}
+}
+
+func (f *gcClusterFixture) addEntity(yaml string, heartbeat time.Time) {
+	entities, err := k8s.ParseYAMLFromString(yaml)
+	require.NoError(f.t, err)
+	for _, e := range entities {
+		f.kCli.ListedEntities = append(f.kCli.ListedEntities, k8s.InjectSession(e, k8s.NewSessionKeys(f.cmd.labelPrefix), "deadbeef", heartbeat))
+	}
+}
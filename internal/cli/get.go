@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/listeners"
+)
+
+func newGetCmd() *cobra.Command {
+	result := &cobra.Command{
+		Use:   "get",
+		Short: "Show information from a running Tilt",
+	}
+
+	result.AddCommand(newGetForwardsCmd())
+
+	return result
+}
+
+func newGetForwardsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "forwards",
+		Aliases: []string{"listeners"},
+		Short:   "List every local port that Tilt is listening on",
+		Long: `Lists every local port that a running Tilt is listening on: its web server,
+port-forwards, and the proxies in front of them.
+
+For each one, shows where traffic goes, whether it's listening, and how much
+traffic it has carried. Useful for tracking down port collisions, and for
+finding out which process is listening on a port.
+`,
+		Example: "tilt get forwards",
+		Run:     getForwards,
+		Args:    cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func getForwards(cmd *cobra.Command, args []string) {
+	body := apiGet("listeners")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var statuses []listeners.Status
+	err := json.NewDecoder(body).Decode(&statuses)
+	if err != nil {
+		cmdFail(fmt.Errorf("get forwards: %v", err))
+	}
+
+	err = printForwards(os.Stdout, statuses, time.Now())
+	if err != nil {
+		cmdFail(fmt.Errorf("get forwards: %v", err))
+	}
+}
+
+func printForwards(out io.Writer, statuses []listeners.Status, now time.Time) error {
+	if len(statuses) == 0 {
+		_, err := fmt.Fprintln(out, "Tilt isn't listening on any ports")
+		return err
+	}
+
+	var errs []listeners.Status
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ADDRESS\tPROTOCOL\tRESOURCE\tKIND\tTARGET\tSTATE\tCONNS\tIN\tOUT\tLAST ACTIVITY")
+	for _, s := range statuses {
+		resource := string(s.Resource)
+		if resource == "" {
+			resource = "-"
+		}
+		lastActivity := "-"
+		if !s.LastActivity.IsZero() {
+			lastActivity = units.HumanDuration(now.Sub(s.LastActivity)) + " ago"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			s.Address, s.Protocol, resource, s.Kind, s.Target,
+			fmt.Sprintf("%s (%s)", s.State, units.HumanDuration(now.Sub(s.Since))), s.Connections,
+			units.HumanSize(float64(s.BytesIn)), units.HumanSize(float64(s.BytesOut)), lastActivity)
+		if s.Error != "" {
+			errs = append(errs, s)
+		}
+	}
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	// Errors are too long for the table, so list them after it.
+	if len(errs) > 0 {
+		_, _ = fmt.Fprintln(out, "\nErrors:")
+	}
+	for _, s := range errs {
+		_, err := fmt.Fprintf(out, "  %s (%s): %s\n", s.Address, s.Kind, s.Error)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/listeners"
+)
+
+func TestPrintForwards(t *testing.T) {
+	now := time.Now()
+	out := &bytes.Buffer{}
+	err := printForwards(out, []listeners.Status{
+		{
+			Kind:         listeners.KindWebServer,
+			Protocol:     "tcp",
+			Address:      "localhost:10350",
+			Target:       "Tilt web UI and API",
+			State:        listeners.StateListening,
+			Since:        now.Add(-time.Hour),
+			Connections:  3,
+			BytesIn:      2048,
+			BytesOut:     4096,
+			LastActivity: now.Add(-time.Minute),
+		},
+		{
+			Kind:     listeners.KindPortForward,
+			Resource: "frontend",
+			Protocol: "tcp",
+			Address:  "localhost:8080",
+			Target:   "pod/frontend-abc123:80",
+			State:    listeners.StateError,
+			Error:    "listen tcp 127.0.0.1:8080: bind: address already in use",
+			Since:    now.Add(-10 * time.Second),
+		},
+	}, now)
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 6)
+	assert.Contains(t, string(lines[0]), "ADDRESS")
+	assert.Regexp(t, `localhost:10350\s+tcp\s+-\s+web-server\s+Tilt web UI and API\s+listening \(About an hour\)\s+3\s+2.048kB\s+4.096kB\s+About a minute ago`, string(lines[1]))
+	assert.Regexp(t, `localhost:8080\s+tcp\s+frontend\s+port-forward\s+pod/frontend-abc123:80\s+error \(10 seconds\)\s+0\s+0B\s+0B\s+-`, string(lines[2]))
+	assert.Equal(t, "Errors:", string(lines[4]))
+	assert.Equal(t, "  localhost:8080 (port-forward): listen tcp 127.0.0.1:8080: bind: address already in use", string(lines[5]))
+}
+
+func TestPrintForwardsEmpty(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := printForwards(out, nil, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "Tilt isn't listening on any ports\n", out.String())
+}
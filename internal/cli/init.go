@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/hud/prompt"
+	"github.com/tilt-dev/tilt/internal/tiltinit"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type initCmd struct {
+	fileName  string
+	yes       bool
+	force     bool
+	openInput prompt.OpenInput
+}
+
+func newInitCmd() *initCmd {
+	return &initCmd{openInput: prompt.TTYOpen}
+}
+
+func (c *initCmd) name() model.TiltSubcommand { return "init" }
+
+func (c *initCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate a starter Tiltfile for your project",
+		Long: `Generate a starter Tiltfile for your project.
+
+Looks for Dockerfiles, Kubernetes YAML, Helm charts, and docker-compose files
+under the directory of the Tiltfile, asks a few questions about how they fit
+together, and writes a Tiltfile that builds and deploys them.
+`,
+		Args: cobra.NoArgs,
+	}
+	addTiltfileFlag(cmd, &c.fileName)
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "Don't ask any questions. Accept the default answers")
+	cmd.Flags().BoolVar(&c.force, "force", false, "Overwrite the Tiltfile if it already exists")
+	return cmd
+}
+
+func (c *initCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.init", map[string]string{"yes": fmt.Sprintf("%v", c.yes)})
+	defer a.Flush(time.Second)
+
+	absPath, err := filepath.Abs(c.fileName)
+	if err != nil {
+		return err
+	}
+
+	if !c.force {
+		_, err := os.Stat(absPath)
+		if err == nil {
+			return fmt.Errorf("%s already exists. Use --force to overwrite it", absPath)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	project, err := tiltinit.Scan(filepath.Dir(absPath))
+	if err != nil {
+		return err
+	}
+
+	if project.Empty() {
+		return fmt.Errorf("no Dockerfiles, Kubernetes YAML, Helm charts, or docker-compose files found under %s.\n"+
+			"See https://docs.tilt.dev/tutorial.html to write a Tiltfile from scratch", project.Dir)
+	}
+
+	var asker tiltinit.Asker = tiltinit.DefaultAsker{}
+	if !c.yes {
+		in, err := c.openInput()
+		if err != nil {
+			return fmt.Errorf("opening terminal: %v. Use --yes to accept the default answers", err)
+		}
+		defer func() {
+			_ = in.Close()
+		}()
+		asker = tiltinit.NewTerminalAsker(in, logger.Get(ctx).Writer(logger.InfoLvl))
+	}
+
+	plan, err := tiltinit.BuildPlan(project, asker)
+	if err != nil {
+		return err
+	}
+	if plan.Empty() {
+		return fmt.Errorf("nothing to deploy: tilt init needs Kubernetes YAML, a Helm chart, or a docker-compose file. No Tiltfile written")
+	}
+
+	err = ioutil.WriteFile(absPath, []byte(tiltinit.Generate(plan)), 0644)
+	if err != nil {
+		return err
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Wrote %s", absPath)
+	for _, image := range plan.Images {
+		l.Infof("  Builds %s from %s", image.Ref, image.Dockerfile)
+	}
+	l.Infof("Run 'tilt up' to start!")
+	return nil
+}
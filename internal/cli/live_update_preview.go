@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type liveUpdatePreviewCmd struct {
+	fileName string
+}
+
+var _ tiltCmd = &liveUpdatePreviewCmd{}
+
+func newLiveUpdatePreviewCmd() *liveUpdatePreviewCmd {
+	return &liveUpdatePreviewCmd{}
+}
+
+func (c *liveUpdatePreviewCmd) name() model.TiltSubcommand { return "live-update-preview" }
+
+func (c *liveUpdatePreviewCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "live-update-preview RESOURCE PATH [PATH...]",
+		Short: "Show what live_update would do if the given files changed",
+		Long: `Loads the Tiltfile and shows what Tilt would do with each image of RESOURCE
+if the given files changed: which live_update steps would run, or why Tilt
+would fall back to an image build.
+
+Nothing is built, synced or deployed. Useful when tuning sync(), fall_back_on()
+and fall_back_on_change_count.
+`,
+		Example: "tilt alpha live-update-preview frontend ./web/src/index.js ./web/package.json",
+		Args:    cobra.MinimumNArgs(2),
+	}
+
+	addTiltfileFlag(cmd, &c.fileName)
+	addKubeContextFlag(cmd)
+
+	return cmd
+}
+
+func (c *liveUpdatePreviewCmd) run(ctx context.Context, args []string) error {
+	mn := model.ManifestName(args[0])
+	files := make([]string, 0, len(args)-1)
+	for _, f := range args[1:] {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return err
+		}
+		files = append(files, abs)
+	}
+
+	// Only show the Tiltfile's logs if something goes wrong.
+	l := logger.NewDeferredLogger(ctx)
+	ctx = logger.WithLogger(ctx, l)
+
+	deps, err := wireTiltfileResult(ctx, analytics.Get(ctx), c.name())
+	if err != nil {
+		l.SetOutput(l.Original())
+		return errors.Wrap(err, "wiring dependencies")
+	}
+
+	tlr := deps.tfl.Load(ctx, c.fileName, model.NewUserConfigState(nil))
+	if tlr.Error != nil {
+		l.SetOutput(l.Original())
+		return tlr.Error
+	}
+
+	for _, m := range tlr.Manifests {
+		if m.Name == mn {
+			return printLiveUpdatePreview(os.Stdout, m, files)
+		}
+	}
+	return fmt.Errorf("no resource found with name %q", mn)
+}
+
+func printLiveUpdatePreview(w io.Writer, m model.Manifest, files []string) error {
+	if len(m.ImageTargets) == 0 {
+		return fmt.Errorf("resource %q builds no images, so it has nothing to live-update", m.Name)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "If these files changed in %s:\n", m.Name)
+	for _, f := range ospath.TryAsCwdChildren(files) {
+		fmt.Fprintf(b, "  %s\n", f)
+	}
+
+	for _, iTarget := range planImageOrder(m) {
+		fmt.Fprintf(b, "\nImage %s:\n", container.FamiliarString(iTarget.Refs.ConfigurationRef))
+
+		changed := filesInImage(iTarget, files)
+		if len(changed) == 0 {
+			fmt.Fprintf(b, "  Not affected: none of the files are in its build context\n")
+			continue
+		}
+
+		luInfo := iTarget.LiveUpdateInfo()
+		if luInfo.Empty() {
+			fmt.Fprintf(b, "  Would rebuild the image: it has no live_update steps\n")
+			continue
+		}
+
+		mappings, err := buildcontrol.LiveUpdateChanges(iTarget, changed)
+		if err != nil {
+			if _, ok := err.(buildcontrol.RedirectToNextBuilder); !ok {
+				return err
+			}
+			fmt.Fprintf(b, "  Would fall back to an image build: %v\n", err)
+			continue
+		}
+
+		cmds, err := build.BoilRuns(luInfo.RunSteps(), mappings)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b, "  Would live-update:\n")
+		for _, pm := range mappings {
+			fmt.Fprintf(b, "    sync %s --> %s\n",
+				ospath.TryAsCwdChildren([]string{pm.LocalPath})[0], pm.ContainerPath)
+		}
+		for _, cmd := range cmds {
+			fmt.Fprintf(b, "    run: %s\n", cmd)
+		}
+		if luInfo.ShouldRestart() {
+			fmt.Fprintf(b, "    restart the container\n")
+		}
+		for _, r := range luInfo.RestartResources() {
+			fmt.Fprintf(b, "    restart resource %s\n", r)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// The files that are in the image's build context (or custom_build deps).
+func filesInImage(iTarget model.ImageTarget, files []string) []string {
+	var result []string
+	for _, f := range files {
+		if ospath.IsChildOfOne(iTarget.LocalPaths(), f) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPrintLiveUpdatePreview(t *testing.T) {
+	lu, err := model.NewLiveUpdate([]model.LiveUpdateStep{
+		model.LiveUpdateFallBackOnStep{Files: []string{"/src/app/package.json"}},
+		model.LiveUpdateSyncStep{Source: "/src/app", Dest: "/app"},
+		model.LiveUpdateRunStep{Command: model.ToUnixCmd("make gen"), Triggers: model.NewPathSet([]string{"gen.yaml"}, "/src/app")},
+		model.LiveUpdateRunStep{Command: model.ToUnixCmd("npm install"), Triggers: model.NewPathSet([]string{"package-lock.json"}, "/src/app")},
+		model.LiveUpdateRestartResourceStep{Resource: "cache"},
+	}, "/src/app")
+	require.NoError(t, err)
+
+	base := model.MustNewImageTarget(container.MustParseSelector("gcr.io/base")).
+		WithBuildDetails(model.DockerBuild{BuildPath: "/src/base"})
+	app := model.MustNewImageTarget(container.MustParseSelector("gcr.io/app")).
+		WithBuildDetails(model.DockerBuild{BuildPath: "/src/app", LiveUpdate: lu.WithMaxChangedFiles(2)}).
+		WithDependencyIDs([]model.TargetID{base.ID()})
+	m := model.Manifest{Name: "app"}.WithImageTargets([]model.ImageTarget{app, base})
+
+	preview := func(files ...string) string {
+		out := &bytes.Buffer{}
+		require.NoError(t, printLiveUpdatePreview(out, m, files))
+		return out.String()
+	}
+
+	assert.Equal(t, `If these files changed in app:
+  /src/app/index.js
+  /src/app/gen.yaml
+
+Image gcr.io/base:
+  Not affected: none of the files are in its build context
+
+Image gcr.io/app:
+  Would live-update:
+    sync /src/app/index.js --> /app/index.js
+    sync /src/app/gen.yaml --> /app/gen.yaml
+    run: make gen
+    restart resource cache
+`, preview("/src/app/index.js", "/src/app/gen.yaml"))
+
+	assert.Contains(t, preview("/src/app/package.json"),
+		`Would fall back to an image build: Detected change to fall_back_on file "package.json"`)
+	assert.Contains(t, preview("/src/app/a.js", "/src/app/b.js", "/src/app/c.js"),
+		"Would fall back to an image build: 3 files changed, more than fall_back_on_change_count (2)")
+	assert.Contains(t, preview("/src/base/Dockerfile"),
+		"Image gcr.io/base:\n  Would rebuild the image: it has no live_update steps\n")
+}
@@ -15,6 +15,7 @@ import (
 
 type logsCmd struct {
 	follow bool // if true, follow logs (otherwise print current logs and exit)
+	build  bool // if true, print full build output, even if the Tiltfile hides it
 }
 
 func (c *logsCmd) name() model.TiltSubcommand { return "logs" }
@@ -32,6 +33,7 @@ By default, looks for a running Tilt instance on localhost:10350
 	}
 
 	cmd.Flags().BoolVarP(&c.follow, "follow", "f", false, "If true, stream the requested logs; otherwise, print the requested logs at the current moment in time, then exit.")
+	cmd.Flags().BoolVar(&c.build, "build", false, "If true, include the full output of every image build, even if build_output hides it in the HUD.")
 
 	// TODO: log level flags
 	addConnectServerFlags(cmd)
@@ -53,5 +55,5 @@ func (c *logsCmd) run(ctx context.Context, args []string) error {
 		return err
 	}
 
-	return server.StreamLogs(ctx, c.follow, logDeps.url, args, logDeps.printer)
+	return server.StreamLogs(ctx, c.follow, logDeps.url, args, c.build, logDeps.printer)
 }
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Loads the Tiltfile and prints what `tilt up` would do with it,
+// without building images or touching the cluster.
+func (c *upCmd) runPlan(ctx context.Context, args []string) error {
+	// Only show the Tiltfile's logs if something goes wrong,
+	// so that the plan is easy to read.
+	l := logger.NewDeferredLogger(ctx)
+	ctx = logger.WithLogger(ctx, l)
+
+	deps, err := wireTiltfileResult(ctx, analytics.Get(ctx), c.name())
+	if err != nil {
+		l.SetOutput(l.Original())
+		return errors.Wrap(err, "wiring dependencies")
+	}
+
+	tlr := deps.tfl.Load(ctx, c.fileName, model.NewUserConfigState(args))
+	if tlr.Error != nil {
+		l.SetOutput(l.Original())
+		return tlr.Error
+	}
+
+	return printPlan(os.Stdout, tlr)
+}
+
+func printPlan(w io.Writer, tlr tiltfile.TiltfileLoadResult) error {
+	b := &strings.Builder{}
+	manifests := planOrder(tlr.Manifests)
+	if len(manifests) == 0 {
+		fmt.Fprintf(b, "The Tiltfile defines no resources.\n")
+	} else {
+		fmt.Fprintf(b, "Tilt would start %d resources, in this order:\n", len(manifests))
+	}
+
+	for i, m := range manifests {
+		fmt.Fprintf(b, "\n%d. %s%s\n", i+1, m.Name, planAnnotations(m))
+
+		for _, iTarget := range planImageOrder(m) {
+			fmt.Fprintf(b, "   Build image %s with %s\n",
+				container.FamiliarString(iTarget.Refs.ConfigurationRef), planBuildDescription(iTarget))
+		}
+
+		switch {
+		case m.IsK8s():
+			names := m.K8sTarget().ManagedDisplayNames()
+			if len(names) > 0 {
+				fmt.Fprintf(b, "   Apply to Kubernetes: %s\n", strings.Join(names, ", "))
+			}
+		case m.IsDC():
+			fmt.Fprintf(b, "   Start docker-compose service %s\n", m.Name)
+		case m.IsLocal():
+			lt := m.LocalTarget()
+			if !lt.UpdateCmd.Empty() {
+				fmt.Fprintf(b, "   Run: %s\n", lt.UpdateCmd)
+			}
+			if !lt.ServeCmd.Empty() {
+				fmt.Fprintf(b, "   Serve: %s\n", lt.ServeCmd)
+			}
+		}
+
+		watched := sliceutils.DedupedAndSorted(m.LocalPaths())
+		if len(watched) > 0 {
+			fmt.Fprintf(b, "   Watches: %s\n", strings.Join(ospath.TryAsCwdChildren(watched), ", "))
+		}
+	}
+
+	configFiles := sliceutils.DedupedAndSorted(tlr.ConfigFiles)
+	if len(configFiles) > 0 {
+		fmt.Fprintf(b, "\nTilt would re-run the Tiltfile when any of these change:\n")
+		for _, f := range ospath.TryAsCwdChildren(configFiles) {
+			fmt.Fprintf(b, "  %s\n", f)
+		}
+	}
+
+	fmt.Fprintf(b, "\nNothing was built or deployed.\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// The order in which `tilt up` starts resources: each resource after the
+// resources it depends on, and otherwise in Tiltfile order.
+//
+// Tilt builds independent resources in parallel, so this is one of
+// several orders that Tilt might actually use.
+func planOrder(manifests []model.Manifest) []model.Manifest {
+	byName := make(map[model.ManifestName]model.Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+
+	result := make([]model.Manifest, 0, len(manifests))
+	visited := make(map[model.ManifestName]bool, len(manifests))
+	var visit func(m model.Manifest)
+	visit = func(m model.Manifest) {
+		if visited[m.Name] {
+			return
+		}
+		visited[m.Name] = true
+		for _, dep := range m.ResourceDependencies {
+			depManifest, ok := byName[dep]
+			if ok {
+				visit(depManifest)
+			}
+		}
+		result = append(result, m)
+	}
+
+	for _, m := range manifests {
+		visit(m)
+	}
+	return result
+}
+
+// Images in the order Tilt builds them, base images first.
+func planImageOrder(m model.Manifest) []model.ImageTarget {
+	specs := make([]model.TargetSpec, 0, len(m.ImageTargets))
+	for _, iTarget := range m.ImageTargets {
+		specs = append(specs, iTarget)
+	}
+
+	sorted, err := model.TopologicalSort(specs)
+	if err != nil {
+		// The Tiltfile loader already checked for cycles,
+		// so fall back to the Tiltfile's order.
+		return m.ImageTargets
+	}
+
+	result := make([]model.ImageTarget, 0, len(sorted))
+	for _, spec := range sorted {
+		result = append(result, spec.(model.ImageTarget))
+	}
+	return result
+}
+
+func planAnnotations(m model.Manifest) string {
+	var notes []string
+	if len(m.ResourceDependencies) > 0 {
+		var deps []string
+		for _, dep := range m.ResourceDependencies {
+			deps = append(deps, dep.String())
+		}
+		notes = append(notes, fmt.Sprintf("after %s", strings.Join(deps, ", ")))
+	}
+
+	switch m.TriggerMode {
+	case model.TriggerModeManualIncludingInitial:
+		notes = append(notes, "manual: waits until you trigger it")
+	case model.TriggerModeManualAfterInitial:
+		notes = append(notes, "manual: file changes wait until you trigger an update")
+	}
+
+	if len(notes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(notes, "; "))
+}
+
+func planBuildDescription(iTarget model.ImageTarget) string {
+	switch bd := iTarget.BuildDetails.(type) {
+	case model.DockerBuild:
+		return fmt.Sprintf("docker build %s", ospath.TryAsCwdChildren([]string{bd.BuildPath})[0])
+	case model.CustomBuild:
+		return fmt.Sprintf("custom_build: %s", bd.Command)
+	}
+	return "an unknown builder"
+}
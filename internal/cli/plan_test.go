@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPrintPlan(t *testing.T) {
+	base := model.MustNewImageTarget(container.MustParseSelector("gcr.io/base")).
+		WithBuildDetails(model.DockerBuild{BuildPath: "/src/base"})
+	app := model.MustNewImageTarget(container.MustParseSelector("gcr.io/app")).
+		WithBuildDetails(model.DockerBuild{BuildPath: "/src/app"}).
+		WithDependencyIDs([]model.TargetID{base.ID()})
+
+	// Listed out of build order, the way they might be after assembly.
+	api := model.Manifest{
+		Name:                 "api",
+		ResourceDependencies: []model.ManifestName{"db"},
+	}.WithImageTargets([]model.ImageTarget{app, base}).
+		WithDeployTarget(model.K8sTarget{DisplayNames: []string{"api:deployment", "api:service"}})
+
+	db := model.Manifest{Name: "db"}.WithDeployTarget(model.LocalTarget{
+		Name:     "db",
+		ServeCmd: model.ToUnixCmd("./run-db.sh"),
+		Deps:     []string{"/src/db"},
+	})
+
+	migrate := model.Manifest{
+		Name:        "migrate",
+		TriggerMode: model.TriggerModeManualIncludingInitial,
+	}.WithDeployTarget(model.LocalTarget{
+		Name:      "migrate",
+		UpdateCmd: model.ToUnixCmd("make migrate"),
+	})
+
+	out := &bytes.Buffer{}
+	err := printPlan(out, tiltfile.TiltfileLoadResult{
+		Manifests:   []model.Manifest{api, db, migrate},
+		ConfigFiles: []string{"/src/Tiltfile", "/src/k8s.yaml"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `Tilt would start 3 resources, in this order:
+
+1. db
+   Serve: ./run-db.sh
+   Watches: /src/db
+
+2. api (after db)
+   Build image gcr.io/base with docker build /src/base
+   Build image gcr.io/app with docker build /src/app
+   Apply to Kubernetes: api:deployment, api:service
+   Watches: /src/app, /src/base
+
+3. migrate (manual: waits until you trigger it)
+   Run: make migrate
+
+Tilt would re-run the Tiltfile when any of these change:
+  /src/Tiltfile
+  /src/k8s.yaml
+
+Nothing was built or deployed.
+`, out.String())
+}
+
+func TestPrintPlanNoResources(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := printPlan(out, tiltfile.TiltfileLoadResult{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "The Tiltfile defines no resources.\n\nNothing was built or deployed.\n", out.String())
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newRunNowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run-now [RESOURCE_NAME]",
+		Short: "Run the CronJobs of the specified resource now",
+		Long: `Run the CronJobs of the specified resource now, instead of waiting for their schedule.
+
+Tilt suspends the schedule of each CronJob it deploys, and restores it when Tilt exits.
+This command creates a Job from each CronJob's job template, like
+'kubectl create job --from=cronjob/<name>'. The Job's pods and logs show up under the resource.
+`,
+		Args: cobra.ExactArgs(1),
+		Run:  runNow,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func runNow(cmd *cobra.Command, args []string) {
+	resource := args[0]
+	payload := []byte(fmt.Sprintf(`{"type": "CronJobRunNow", "manifest_name": %q}`, resource))
+
+	body := apiPostJson("action", payload)
+	_ = body.Close()
+
+	fmt.Printf("Successfully asked Tilt to run the CronJobs of resource: %q\n", resource)
+}
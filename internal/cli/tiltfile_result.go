@@ -24,7 +24,10 @@ var tupleRE = regexp.MustCompile(`,\)$`)
 const TiltfileErrExitCode = 5
 
 type tiltfileResultCmd struct {
-	fileName string
+	fileName     string
+	output       string
+	printSchema  bool
+	validateOnly bool
 
 	// for Builtin Timings mode
 	builtinTimings bool
@@ -55,6 +58,12 @@ func (c *tiltfileResultCmd) register() *cobra.Command {
 		Short: "Exec the Tiltfile and print data about execution. By default, prints Tiltfile execution results as JSON (note: the API is unstable and may change); can also print timings of Tiltfile Builtin calls.",
 		Long: `Exec the Tiltfile and print data about execution. By default, prints Tiltfile execution results as JSON (note: the API is unstable and may change); can also print timings of Tiltfile Builtin calls.
 
+With --output=json, prints a versioned, documented view of the manifest graph
+(images, deps, live_update steps, port forwards, trigger modes) that's safe to
+build tooling on. Run with --schema to print the JSON Schema for that format.
+
+With --validate-only, prints nothing on success. Useful in pre-commit hooks and CI.
+
 Exit code 0: successful Tiltfile evaluation (data printed to stdout)
 Exit code 1: some failure in setup, printing results, etc. (any logs printed to stderr)
 Exit code 5: error when evaluating the Tiltfile, such as syntax error, illegal Tiltfile operation, etc. (any logs printed to stderr)
@@ -65,12 +74,24 @@ Run with -v | --verbose to print Tiltfile execution logs on stderr, regardless o
 	addTiltfileFlag(cmd, &c.fileName)
 	addKubeContextFlag(cmd)
 	cmd.Flags().BoolVarP(&c.builtinTimings, "builtin-timings", "b", false, "If true, print timing data for Tiltfile builtin calls instead of Tiltfile result JSON")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "raw", "Output format. One of: raw (Tilt's internal data model, unstable) or json (versioned, see --schema)")
+	cmd.Flags().BoolVar(&c.printSchema, "schema", false, "If true, print the JSON Schema for --output=json and exit without executing the Tiltfile")
+	cmd.Flags().BoolVar(&c.validateOnly, "validate-only", false, "If true, only report whether the Tiltfile executes successfully, via the exit code")
 	cmd.Flags().DurationVar(&c.durThreshold, "dur-threshold", 0, "Only compatible with Builtin Timings mode. Should be a Go duration string. If passed, only print information about builtin calls lasting this duration and longer.")
 
 	return cmd
 }
 
 func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
+	if c.printSchema {
+		_, err := fmt.Fprintln(os.Stdout, tiltfileResultSchemaJSON)
+		return err
+	}
+
+	if c.output != "raw" && c.output != "json" {
+		return fmt.Errorf("invalid --output %q. Must be one of: raw, json", c.output)
+	}
+
 	// HACK(maia): we're overloading the -v|--verbose flags here, which isn't ideal,
 	// but eh, it's fast. Might be cleaner to do --logs=true or something.
 	logLvl := logger.Get(ctx).Level()
@@ -104,6 +125,10 @@ func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
 		os.Exit(TiltfileErrExitCode)
 	}
 
+	if c.validateOnly {
+		return nil
+	}
+
 	// Instead of printing result JSON, print Builtin Timings instead
 	if c.builtinTimings {
 		if len(tlr.BuiltinCalls) == 0 {
@@ -120,7 +145,11 @@ func (c *tiltfileResultCmd) run(ctx context.Context, args []string) error {
 		return nil
 	}
 
-	err = encodeJSON(tlr)
+	if c.output == "json" {
+		err = encodeJSON(newTiltfileResultJSON(tlr))
+	} else {
+		err = encodeJSON(tlr)
+	}
 	if err != nil {
 		maybePrintDeferredLogsToStderr(ctx, showTiltfileLogs)
 		return errors.Wrap(err, "encoding JSON")
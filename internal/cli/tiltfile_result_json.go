@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"sort"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The version of the machine-readable tiltfile-result format.
+//
+// Bump this whenever a field is removed or changes meaning.
+// Adding new fields is backwards-compatible and doesn't need a bump.
+const tiltfileResultVersion = "tilt.dev/tiltfile-result/v1"
+
+// A stable, documented view of the manifest graph.
+//
+// Unlike the raw TiltfileLoadResult (which mirrors Tilt's internal data model and
+// changes whenever the internals do), this is meant for pre-commit hooks and CI
+// checks. If you change it, update tiltfileResultSchemaJSON too.
+type tiltfileResultJSON struct {
+	Version     string         `json:"version"`
+	ConfigFiles []string       `json:"configFiles"`
+	Manifests   []manifestJSON `json:"manifests"`
+}
+
+type manifestJSON struct {
+	Name                 string             `json:"name"`
+	Type                 string             `json:"type"`
+	TriggerMode          string             `json:"triggerMode"`
+	ResourceDependencies []string           `json:"resourceDependencies"`
+	Deps                 []string           `json:"deps"`
+	Images               []imageJSON        `json:"images"`
+	PortForwards         []portForwardJSON  `json:"portForwards"`
+	K8s                  *k8sJSON           `json:"k8s,omitempty"`
+	DockerCompose        *dockerComposeJSON `json:"dockerCompose,omitempty"`
+	Local                *localJSON         `json:"local,omitempty"`
+}
+
+type imageJSON struct {
+	Ref        string               `json:"ref"`
+	BuildType  string               `json:"buildType"`
+	Context    string               `json:"context,omitempty"`
+	BuildArgs  map[string]string    `json:"buildArgs,omitempty"`
+	Target     string               `json:"target,omitempty"`
+	Command    []string             `json:"command,omitempty"`
+	Deps       []string             `json:"deps"`
+	ImageDeps  []string             `json:"imageDeps"`
+	LiveUpdate []liveUpdateStepJSON `json:"liveUpdate"`
+}
+
+type liveUpdateStepJSON struct {
+	Type     string   `json:"type"`
+	Files    []string `json:"files,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Dest     string   `json:"dest,omitempty"`
+	Command  []string `json:"command,omitempty"`
+	Triggers []string `json:"triggers,omitempty"`
+	Resource string   `json:"resource,omitempty"`
+}
+
+type portForwardJSON struct {
+	LocalPort     int    `json:"localPort"`
+	ContainerPort int    `json:"containerPort,omitempty"`
+	Host          string `json:"host,omitempty"`
+	Name          string `json:"name,omitempty"`
+}
+
+type k8sJSON struct {
+	Objects []string `json:"objects"`
+}
+
+type dockerComposeJSON struct {
+	ConfigPaths []string `json:"configPaths"`
+}
+
+type localJSON struct {
+	UpdateCmd []string `json:"updateCmd,omitempty"`
+	ServeCmd  []string `json:"serveCmd,omitempty"`
+	Workdir   string   `json:"workdir,omitempty"`
+}
+
+func newTiltfileResultJSON(tlr tiltfile.TiltfileLoadResult) tiltfileResultJSON {
+	manifests := make([]manifestJSON, 0, len(tlr.Manifests))
+	for _, m := range tlr.Manifests {
+		manifests = append(manifests, newManifestJSON(m))
+	}
+
+	return tiltfileResultJSON{
+		Version:     tiltfileResultVersion,
+		ConfigFiles: sortedStrings(tlr.ConfigFiles),
+		Manifests:   manifests,
+	}
+}
+
+func newManifestJSON(m model.Manifest) manifestJSON {
+	result := manifestJSON{
+		Name:                 m.Name.String(),
+		Type:                 manifestType(m),
+		TriggerMode:          triggerModeString(m.TriggerMode),
+		ResourceDependencies: []string{},
+		Deps:                 sortedStrings(m.LocalPaths()),
+		Images:               []imageJSON{},
+		PortForwards:         []portForwardJSON{},
+	}
+
+	for _, dep := range m.ResourceDependencies {
+		result.ResourceDependencies = append(result.ResourceDependencies, dep.String())
+	}
+
+	for _, iTarget := range m.ImageTargets {
+		result.Images = append(result.Images, newImageJSON(iTarget))
+	}
+
+	switch {
+	case m.IsK8s():
+		kTarget := m.K8sTarget()
+		for _, pf := range kTarget.PortForwards {
+			result.PortForwards = append(result.PortForwards, portForwardJSON{
+				LocalPort:     pf.LocalPort,
+				ContainerPort: pf.ContainerPort,
+				Host:          pf.Host,
+				Name:          pf.Name,
+			})
+		}
+		result.K8s = &k8sJSON{Objects: sortedStrings(kTarget.DisplayNames)}
+	case m.IsDC():
+		dcTarget := m.DockerComposeTarget()
+		for _, port := range dcTarget.PublishedPorts() {
+			result.PortForwards = append(result.PortForwards, portForwardJSON{LocalPort: port})
+		}
+		result.DockerCompose = &dockerComposeJSON{ConfigPaths: sortedStrings(dcTarget.ConfigPaths)}
+	case m.IsLocal():
+		lTarget := m.LocalTarget()
+		result.Local = &localJSON{
+			UpdateCmd: lTarget.UpdateCmd.Argv,
+			ServeCmd:  lTarget.ServeCmd.Argv,
+			Workdir:   lTarget.Workdir,
+		}
+	}
+
+	return result
+}
+
+func newImageJSON(iTarget model.ImageTarget) imageJSON {
+	result := imageJSON{
+		Ref:        container.FamiliarString(iTarget.Refs.ConfigurationRef),
+		Deps:       sortedStrings(iTarget.Dependencies()),
+		ImageDeps:  []string{},
+		LiveUpdate: []liveUpdateStepJSON{},
+	}
+
+	for _, depID := range iTarget.DependencyIDs() {
+		result.ImageDeps = append(result.ImageDeps, depID.Name.String())
+	}
+
+	switch bd := iTarget.BuildDetails.(type) {
+	case model.DockerBuild:
+		result.BuildType = "docker"
+		result.Context = bd.BuildPath
+		result.BuildArgs = bd.BuildArgs
+		result.Target = bd.TargetStage.String()
+	case model.CustomBuild:
+		result.BuildType = "custom"
+		result.Context = bd.WorkDir
+		result.Command = bd.Command.Argv
+	}
+
+	for _, step := range iTarget.LiveUpdateInfo().Steps {
+		result.LiveUpdate = append(result.LiveUpdate, newLiveUpdateStepJSON(step))
+	}
+
+	return result
+}
+
+func newLiveUpdateStepJSON(step model.LiveUpdateStep) liveUpdateStepJSON {
+	switch s := step.(type) {
+	case model.LiveUpdateFallBackOnStep:
+		return liveUpdateStepJSON{Type: "fall_back_on", Files: s.Files}
+	case model.LiveUpdateSyncStep:
+		return liveUpdateStepJSON{Type: "sync", Source: s.Source, Dest: s.Dest}
+	case model.LiveUpdateRunStep:
+		return liveUpdateStepJSON{Type: "run", Command: s.Command.Argv, Triggers: s.Triggers.Paths}
+	case model.LiveUpdateRestartContainerStep:
+		return liveUpdateStepJSON{Type: "restart_container"}
+	case model.LiveUpdateRestartResourceStep:
+		return liveUpdateStepJSON{Type: "restart_resource", Resource: s.Resource.String()}
+	}
+	return liveUpdateStepJSON{Type: "unknown"}
+}
+
+func manifestType(m model.Manifest) string {
+	switch {
+	case m.IsK8s():
+		return "k8s"
+	case m.IsDC():
+		return "docker_compose"
+	case m.IsLocal():
+		return "local"
+	}
+	return "unknown"
+}
+
+func triggerModeString(mode model.TriggerMode) string {
+	switch mode {
+	case model.TriggerModeAuto:
+		return "auto"
+	case model.TriggerModeManualAfterInitial:
+		return "manual_after_initial"
+	case model.TriggerModeManualIncludingInitial:
+		return "manual_including_initial"
+	}
+	return "unknown"
+}
+
+// JSON consumers are much happier with [] than null,
+// and sorting keeps the output stable for diffing.
+func sortedStrings(s []string) []string {
+	result := append([]string{}, s...)
+	sort.Strings(result)
+	return result
+}
+
+// A JSON Schema (draft-07) describing tiltfileResultJSON.
+const tiltfileResultSchemaJSON = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "tilt.dev/tiltfile-result/v1",
+  "title": "Tiltfile result",
+  "type": "object",
+  "required": ["version", "configFiles", "manifests"],
+  "properties": {
+    "version": {"type": "string", "const": "tilt.dev/tiltfile-result/v1"},
+    "configFiles": {"type": "array", "items": {"type": "string"}, "description": "Every file the Tiltfile read. Changing any of them re-executes the Tiltfile."},
+    "manifests": {"type": "array", "items": {"$ref": "#/definitions/manifest"}}
+  },
+  "definitions": {
+    "manifest": {
+      "type": "object",
+      "required": ["name", "type", "triggerMode", "resourceDependencies", "deps", "images", "portForwards"],
+      "properties": {
+        "name": {"type": "string"},
+        "type": {"type": "string", "enum": ["k8s", "docker_compose", "local", "unknown"]},
+        "triggerMode": {"type": "string", "enum": ["auto", "manual_after_initial", "manual_including_initial"]},
+        "resourceDependencies": {"type": "array", "items": {"type": "string"}, "description": "Names of resources that must be ready before this one is built."},
+        "deps": {"type": "array", "items": {"type": "string"}, "description": "Absolute paths that trigger an update of this resource."},
+        "images": {"type": "array", "items": {"$ref": "#/definitions/image"}},
+        "portForwards": {"type": "array", "items": {"$ref": "#/definitions/portForward"}},
+        "k8s": {"$ref": "#/definitions/k8s"},
+        "dockerCompose": {"$ref": "#/definitions/dockerCompose"},
+        "local": {"$ref": "#/definitions/local"}
+      }
+    },
+    "image": {
+      "type": "object",
+      "required": ["ref", "buildType", "deps", "imageDeps", "liveUpdate"],
+      "properties": {
+        "ref": {"type": "string"},
+        "buildType": {"type": "string", "enum": ["docker", "custom"]},
+        "context": {"type": "string"},
+        "buildArgs": {"type": "object", "additionalProperties": {"type": "string"}},
+        "target": {"type": "string"},
+        "command": {"type": "array", "items": {"type": "string"}},
+        "deps": {"type": "array", "items": {"type": "string"}},
+        "imageDeps": {"type": "array", "items": {"type": "string"}, "description": "Refs of other images this image is built from."},
+        "liveUpdate": {"type": "array", "items": {"$ref": "#/definitions/liveUpdateStep"}}
+      }
+    },
+    "liveUpdateStep": {
+      "type": "object",
+      "required": ["type"],
+      "properties": {
+        "type": {"type": "string", "enum": ["fall_back_on", "sync", "run", "restart_container", "restart_resource"]},
+        "files": {"type": "array", "items": {"type": "string"}},
+        "source": {"type": "string"},
+        "dest": {"type": "string"},
+        "command": {"type": "array", "items": {"type": "string"}},
+        "triggers": {"type": "array", "items": {"type": "string"}},
+        "resource": {"type": "string", "description": "For restart_resource, the resource whose containers are restarted."}
+      }
+    },
+    "portForward": {
+      "type": "object",
+      "required": ["localPort"],
+      "properties": {
+        "localPort": {"type": "integer"},
+        "containerPort": {"type": "integer"},
+        "host": {"type": "string"},
+        "name": {"type": "string"}
+      }
+    },
+    "k8s": {
+      "type": "object",
+      "required": ["objects"],
+      "properties": {
+        "objects": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "dockerCompose": {
+      "type": "object",
+      "required": ["configPaths"],
+      "properties": {
+        "configPaths": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "local": {
+      "type": "object",
+      "properties": {
+        "updateCmd": {"type": "array", "items": {"type": "string"}},
+        "serveCmd": {"type": "array", "items": {"type": "string"}},
+        "workdir": {"type": "string"}
+      }
+    }
+  }
+}`
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestTiltfileResultJSON(t *testing.T) {
+	lu, err := model.NewLiveUpdate([]model.LiveUpdateStep{
+		model.LiveUpdateFallBackOnStep{Files: []string{"/src/package.json"}},
+		model.LiveUpdateSyncStep{Source: "/src", Dest: "/app"},
+		model.LiveUpdateRunStep{Command: model.ToUnixCmd("npm install"), Triggers: model.NewPathSet([]string{"package.json"}, "/src")},
+	}, "/src")
+	require.NoError(t, err)
+
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo")).
+		WithBuildDetails(model.DockerBuild{
+			BuildPath:  "/src",
+			BuildArgs:  model.DockerBuildArgs{"FOO": "bar"},
+			LiveUpdate: lu,
+		})
+	kTarget := model.K8sTarget{
+		PortForwards: []model.PortForward{{LocalPort: 8000, ContainerPort: 80}},
+		DisplayNames: []string{"foo:deployment"},
+	}
+	m := model.Manifest{
+		Name:                 "foo",
+		TriggerMode:          model.TriggerModeManualAfterInitial,
+		ResourceDependencies: []model.ManifestName{"db"},
+	}.WithImageTarget(iTarget).WithDeployTarget(kTarget)
+
+	local := model.Manifest{Name: "db"}.WithDeployTarget(model.LocalTarget{
+		Name:     "db",
+		ServeCmd: model.ToUnixCmd("./run-db.sh"),
+		Workdir:  "/src",
+	})
+
+	result := newTiltfileResultJSON(tiltfile.TiltfileLoadResult{
+		Manifests:   []model.Manifest{local, m},
+		ConfigFiles: []string{"/src/Tiltfile"},
+	})
+
+	assert.Equal(t, tiltfileResultVersion, result.Version)
+	require.Len(t, result.Manifests, 2)
+
+	db := result.Manifests[0]
+	assert.Equal(t, "local", db.Type)
+	assert.Equal(t, "auto", db.TriggerMode)
+	assert.Equal(t, []string{"sh", "-c", "./run-db.sh"}, db.Local.ServeCmd)
+	assert.Equal(t, []imageJSON{}, db.Images)
+
+	foo := result.Manifests[1]
+	assert.Equal(t, "k8s", foo.Type)
+	assert.Equal(t, "manual_after_initial", foo.TriggerMode)
+	assert.Equal(t, []string{"db"}, foo.ResourceDependencies)
+	assert.Equal(t, []portForwardJSON{{LocalPort: 8000, ContainerPort: 80}}, foo.PortForwards)
+	assert.Equal(t, []string{"foo:deployment"}, foo.K8s.Objects)
+	require.Len(t, foo.Images, 1)
+
+	img := foo.Images[0]
+	assert.Equal(t, "gcr.io/foo", img.Ref)
+	assert.Equal(t, "docker", img.BuildType)
+	assert.Equal(t, "/src", img.Context)
+	assert.Equal(t, map[string]string{"FOO": "bar"}, img.BuildArgs)
+	assert.Equal(t, []liveUpdateStepJSON{
+		{Type: "fall_back_on", Files: []string{"/src/package.json"}},
+		{Type: "sync", Source: "/src", Dest: "/app"},
+		{Type: "run", Command: []string{"sh", "-c", "npm install"}, Triggers: []string{"package.json"}},
+	}, img.LiveUpdate)
+}
+
+// Make sure every field we emit is documented in the schema.
+func TestTiltfileResultSchemaCoversOutput(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(tiltfileResultSchemaJSON), &schema))
+	assert.Equal(t, tiltfileResultVersion, schema["$id"])
+
+	definitions := schema["definitions"].(map[string]interface{})
+	assertPropsInSchema := func(def map[string]interface{}, v interface{}) {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &fields))
+
+		props := def["properties"].(map[string]interface{})
+		for k := range fields {
+			assert.Contains(t, props, k)
+		}
+	}
+
+	assertPropsInSchema(schema, tiltfileResultJSON{})
+	assertPropsInSchema(definitions["manifest"].(map[string]interface{}), manifestJSON{
+		K8s:           &k8sJSON{},
+		DockerCompose: &dockerComposeJSON{},
+		Local:         &localJSON{},
+	})
+	assertPropsInSchema(definitions["image"].(map[string]interface{}), imageJSON{
+		Context:   "x",
+		BuildArgs: map[string]string{"x": "y"},
+		Target:    "x",
+		Command:   []string{"x"},
+	})
+	assertPropsInSchema(definitions["liveUpdateStep"].(map[string]interface{}), liveUpdateStepJSON{
+		Files:    []string{"x"},
+		Source:   "x",
+		Dest:     "x",
+		Command:  []string{"x"},
+		Triggers: []string{"x"},
+	})
+	assertPropsInSchema(definitions["portForward"].(map[string]interface{}), portForwardJSON{
+		ContainerPort: 1,
+		Host:          "x",
+		Name:          "x",
+	})
+	assertPropsInSchema(definitions["k8s"].(map[string]interface{}), k8sJSON{})
+	assertPropsInSchema(definitions["dockerCompose"].(map[string]interface{}), dockerComposeJSON{})
+	assertPropsInSchema(definitions["local"].(map[string]interface{}), localJSON{
+		UpdateCmd: []string{"x"},
+		ServeCmd:  []string{"x"},
+		Workdir:   "x",
+	})
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tiltfiletest"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type tiltfileTestCmd struct {
+	fileName string
+	filter   string
+}
+
+var _ tiltCmd = &tiltfileTestCmd{}
+
+func newTiltfileTestCmd() *tiltfileTestCmd {
+	return &tiltfileTestCmd{}
+}
+
+func (c *tiltfileTestCmd) name() model.TiltSubcommand { return "tiltfile-test" }
+
+func (c *tiltfileTestCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tiltfile-test [PATH...]",
+		Short: "Run the test_* functions in Tiltfile_test files",
+		Long: `Run the test_* functions in Tiltfile_test files.
+
+A Tiltfile_test can load your Tiltfile with different args and k8s contexts,
+then make assertions about the resources it creates:
+
+  fake_local("kubectl config current-context", stdout="staging")
+
+  def test_prod_is_manual():
+    tf = load_tiltfile(args=["--prod"], k8s_context="gke_prod")
+    assert.equals(None, tf.error)
+    assert.equals("manual", tf.resources["api"].trigger_mode)
+
+Available builtins: assert.equals, assert.true, assert.false, assert.contains,
+assert.fails, fake_local, and load_tiltfile.
+
+Tests never talk to a cluster. Any kubectl, docker, docker-compose, helm, or
+kustomize command the Tiltfile runs must be faked with fake_local().
+
+PATH may be a Tiltfile_test file, or a directory to search for them.
+By default, runs the Tiltfile_test next to the Tiltfile.
+
+Exit code 0: all tests passed
+Exit code 1: a test failed, or a Tiltfile_test couldn't be executed`,
+	}
+
+	addTiltfileFlag(cmd, &c.fileName)
+	cmd.Flags().StringVar(&c.filter, "run", "", "Only run tests whose names match this regular expression")
+
+	return cmd
+}
+
+func (c *tiltfileTestCmd) run(ctx context.Context, args []string) error {
+	var filter *regexp.Regexp
+	if c.filter != "" {
+		var err error
+		filter, err = regexp.Compile(c.filter)
+		if err != nil {
+			return errors.Wrap(err, "invalid --run")
+		}
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{filepath.Join(filepath.Dir(c.fileName), tiltfiletest.FileName)}
+	}
+	files, err := findTiltfileTests(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no %s files found in: %s", tiltfiletest.FileName, strings.Join(paths, ", "))
+	}
+
+	runner := tiltfiletest.NewRunner(analytics.Get(ctx), provideTiltInfo(), filter)
+	passed, failed := 0, 0
+	start := time.Now()
+	for _, file := range files {
+		results, err := runner.RunFile(ctx, file)
+		if err != nil {
+			fmt.Printf("--- FAIL: %s\n%s", file, indentTestOutput(err.Error()))
+			failed++
+			continue
+		}
+
+		for _, r := range results {
+			if r.Passed() {
+				fmt.Printf("--- PASS: %s (%s)\n", r.Name, formatTestDuration(r.Duration))
+				passed++
+				continue
+			}
+
+			fmt.Printf("--- FAIL: %s (%s)\n", r.Name, formatTestDuration(r.Duration))
+			if r.Output != "" {
+				fmt.Print(indentTestOutput(r.Output))
+			}
+			fmt.Print(indentTestOutput(r.Err.Error()))
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed (%s)\n", passed, failed, formatTestDuration(time.Since(start)))
+	if failed > 0 {
+		return fmt.Errorf("%d Tiltfile tests failed", failed)
+	}
+	return nil
+}
+
+// Expand directories into the Tiltfile_test files underneath them.
+func findTiltfileTests(paths []string) ([]string, error) {
+	var result []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			result = append(result, p)
+			continue
+		}
+
+		err = filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				name := info.Name()
+				if path != p && (strings.HasPrefix(name, ".") || name == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.Name() == tiltfiletest.FileName {
+				result = append(result, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func indentTestOutput(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = "    " + l
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatTestDuration(d time.Duration) string {
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -17,23 +18,46 @@ func newTriggerCmd() *cobra.Command {
 If the resource has Trigger Mode: Manual and has pending changes, this command will cause those pending changes to be applied.
 
 Otherwise, this command will force a full rebuild.
+
+With --if-changed, a local_resource is only triggered if the contents of its deps
+have changed since its last successful run. This is handy for scripts that want
+to make sure a resource is up to date without re-running it needlessly.
 `,
 		Args: cobra.ExactArgs(1),
 		Run:  triggerUpdate,
 	}
 	addConnectServerFlags(cmd)
+	cmd.Flags().Bool("if-changed", false, "Skip the update if the resource's deps haven't changed since its last successful run")
 	return cmd
 }
 
 func triggerUpdate(cmd *cobra.Command, args []string) {
 	resource := args[0]
+	ifChanged, _ := cmd.Flags().GetBool("if-changed")
 
 	// TODO(maia): this should probably be the triggerPayload struct, but seems
 	//   like a lot of code to move over (to avoid import cycles) for one call.
-	payload := []byte(fmt.Sprintf(`{"manifest_names":[%q], "build_reason": %d}`, resource, model.BuildReasonFlagTriggerCLI))
+	payload := []byte(fmt.Sprintf(`{"manifest_names":[%q], "build_reason": %d, "if_changed": %t}`,
+		resource, model.BuildReasonFlagTriggerCLI, ifChanged))
 
 	body := apiPostJson("trigger", payload)
-	_ = body.Close()
+	defer func() {
+		_ = body.Close()
+	}()
+
+	if ifChanged {
+		var response struct {
+			Skipped bool `json:"skipped"`
+		}
+		err := json.NewDecoder(body).Decode(&response)
+		if err != nil {
+			cmdFail(fmt.Errorf("Error reading response from Tilt: %v", err))
+		}
+		if response.Skipped {
+			fmt.Printf("Skipped resource %q: deps unchanged since its last successful run\n", resource)
+			return
+		}
+	}
 
 	fmt.Printf("Successfully triggered update for resource: %q\n", resource)
 }
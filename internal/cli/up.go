@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"runtime"
 	"strconv"
 	"time"
 
@@ -35,16 +36,20 @@ var webModeFlag model.WebMode = model.DefaultWebMode
 var webPort = 0
 var webHost = DefaultWebHost
 var webDevPort = 0
+var webBasePath = ""
+var webCORSOrigins []string
 var logActionsFlag bool = false
 
 type upCmd struct {
 	watch                bool
 	fileName             string
 	outputSnapshotOnExit string
+	plan                 bool
 
 	hud    bool
 	legacy bool
 	stream bool
+	output string
 	// whether hud/legacy/stream flags were explicitly set or just got the default value
 	hudFlagExplicitlySet bool
 
@@ -76,6 +81,13 @@ In that case, see https://tilt.dev/user_config.html and/or comments in your Tilt
 When you exit Tilt (using Ctrl+C), Kubernetes resources and Docker Compose resources continue running;
 you can use tilt down (https://docs.tilt.dev/cli/tilt_down.html) to delete these resources. Any long-running
 local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
+
+With --output=json, Tilt writes one JSON object per line to stdout instead of text, for programs that
+read Tilt's output. Each object has a "type": "log" (with "resource", "level", and "text"), "build_started",
+"build_finished" (with "error", if the build failed), or "runtime_status".
+
+With --plan, Tilt loads the Tiltfile and prints what it would build, in what order, which files it would
+watch, and which Kubernetes objects it would apply, then exits without building or deploying anything.
 `,
 	}
 
@@ -85,13 +97,15 @@ local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
 	cmd.Flags().BoolVar(&c.hud, "hud", true, "If true, tilt will open in HUD mode.")
 	cmd.Flags().BoolVar(&c.legacy, "legacy", false, "If true, tilt will open in legacy terminal mode.")
 	cmd.Flags().BoolVar(&c.stream, "stream", false, "If true, tilt will stream logs in the terminal.")
+	cmd.Flags().StringVar(&c.output, "output", "", "If 'json', tilt will write logs, builds, and resource status changes to stdout as line-delimited JSON.")
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
 	addStartServerFlags(cmd)
 	addDevServerFlags(cmd)
 	addTiltfileFlag(cmd, &c.fileName)
 	addKubeContextFlag(cmd)
 	cmd.Flags().Lookup("logactions").Hidden = true
-	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "", "If specified, Tilt will dump a snapshot of its state to the specified path when it exits")
+	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "", "If specified, Tilt will dump a snapshot of its state to the specified path when it exits (gzipped, if the path ends in .gz)")
+	cmd.Flags().BoolVar(&c.plan, "plan", false, "If true, print what Tilt would build, watch, and deploy, then exit without doing it")
 
 	cmd.PreRun = func(cmd *cobra.Command, args []string) {
 		c.hudFlagExplicitlySet = cmd.Flag("hud").Changed
@@ -102,6 +116,10 @@ local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
 }
 
 func (c *upCmd) initialTermMode(isTerminal bool) store.TerminalMode {
+	if c.output == "json" {
+		return store.TerminalModeJSON
+	}
+
 	if !isTerminal {
 		return store.TerminalModeStream
 	}
@@ -124,9 +142,18 @@ func (c *upCmd) initialTermMode(isTerminal bool) store.TerminalMode {
 }
 
 func (c *upCmd) run(ctx context.Context, args []string) error {
+	if c.plan {
+		return c.runPlan(ctx, args)
+	}
+
+	if c.output != "" && c.output != "json" {
+		return fmt.Errorf("invalid --output %q. Must be: json", c.output)
+	}
+
 	a := analytics.Get(ctx)
 
-	termMode := c.initialTermMode(isatty.IsTerminal(os.Stdout.Fd()))
+	requestedTermMode := c.initialTermMode(isatty.IsTerminal(os.Stdout.Fd()))
+	termMode := store.TerminalModeForOS(requestedTermMode, runtime.GOOS)
 
 	cmdUpTags := engineanalytics.CmdTags(map[string]string{
 		"update_mode": updateModeFlag, // before 7/8/20 this was just called "mode"
@@ -140,12 +167,24 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 
 	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
 
+	cleanUpWeb, err := listenWeb(ctx, c.fileName)
+	if err != nil {
+		deferred.SetOutput(deferred.Original())
+		return err
+	}
+	defer cleanUpWeb()
+
 	webHost := provideWebHost()
-	webURL, _ := provideWebURL(webHost, provideWebPort())
+	basePath, _ := provideWebBasePath()
+	webURL, _ := provideWebURL(webHost, provideWebPort(), basePath)
 	startLine := prompt.StartStatusLine(webURL, webHost)
 	log.Print(startLine)
 	log.Print(buildStamp())
 
+	if termMode != requestedTermMode {
+		log.Print("Terminal mode isn't supported on this platform; streaming logs with a resource summary instead")
+	}
+
 	//if --watch was set, warn user about deprecation
 	if c.watchFlagExplicitlySet {
 		logger.Get(ctx).Warnf("Flag --watch has been deprecated, it will be removed in future releases.")
@@ -161,6 +200,13 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Exec credential plugins may need to prompt for a login (e.g., an MFA code),
+	// so fetch credentials while the terminal is still ours.
+	err = cmdUpDeps.ExecCredentials.Warm(ctx)
+	if err != nil {
+		logger.Get(ctx).Warnf("%v", err)
+	}
+
 	upper := cmdUpDeps.Upper
 	if termMode == store.TerminalModePrompt {
 		// Any logs that showed up during initialization, make sure they're
@@ -171,6 +217,11 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	l := store.NewLogActionLogger(ctx, upper.Dispatch)
 	deferred.SetOutput(l)
 	ctx = redirectLogs(ctx, l)
+	if cmdUpDeps.ExecCredentials != nil {
+		// Once the credentials have been rejected, we've already told the user how to log in
+		// again, so don't let every watch log its own 401.
+		klog.SetOutput(newFilteredWriter(l.Writer(logger.InfoLvl), cmdUpDeps.ExecCredentials.IsRejectionLog))
+	}
 	if c.outputSnapshotOnExit != "" {
 		defer cloud.WriteSnapshot(ctx, cmdUpDeps.Store, c.outputSnapshotOnExit)
 	}
@@ -184,7 +235,7 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	}
 
 	err = upper.Start(ctx, args, cmdUpDeps.TiltBuild, engineMode,
-		c.fileName, termMode, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
+		c.fileName, termMode, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress), false)
 	if err != context.Canceled {
 		return err
 	} else {
@@ -233,7 +284,15 @@ func provideWebPort() model.WebPort {
 	return model.WebPort(webPort)
 }
 
-func provideWebURL(webHost model.WebHost, webPort model.WebPort) (model.WebURL, error) {
+func provideWebBasePath() (model.WebBasePath, error) {
+	return model.NewWebBasePath(webBasePath)
+}
+
+func provideWebCORSOrigins() model.WebCORSOrigins {
+	return model.WebCORSOrigins(webCORSOrigins)
+}
+
+func provideWebURL(webHost model.WebHost, webPort model.WebPort, basePath model.WebBasePath) (model.WebURL, error) {
 	if webPort == 0 {
 		return model.WebURL{}, nil
 	}
@@ -244,7 +303,7 @@ func provideWebURL(webHost model.WebHost, webPort model.WebPort) (model.WebURL,
 		webHost = "127.0.0.1"
 	}
 
-	u, err := url.Parse(fmt.Sprintf("http://%s:%d/", webHost, webPort))
+	u, err := url.Parse(fmt.Sprintf("http://%s:%d%s/", webHost, webPort, basePath))
 	if err != nil {
 		return model.WebURL{}, err
 	}
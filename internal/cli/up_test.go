@@ -18,6 +18,8 @@ func TestHudEnabled(t *testing.T) {
 		{"old behavior: no --hud", "", store.TerminalModePrompt},
 		{"old behavior: --hud", "--hud", store.TerminalModeHUD},
 		{"old behavior: --stream=true", "--stream=true", store.TerminalModeStream},
+		{"--output=json", "--output=json", store.TerminalModeJSON},
+		{"--output=json wins over --hud", "--hud --output=json", store.TerminalModeJSON},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			cmd := upCmd{}
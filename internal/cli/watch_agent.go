@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type watchAgentCmd struct{}
+
+func (c *watchAgentCmd) name() model.TiltSubcommand { return "watch-agent" }
+
+func (c *watchAgentCmd) register() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch-agent PATH...",
+		Short: "Watch files on this machine for a Tilt running on another machine",
+		Long: `Watch files on this machine for a Tilt running on another machine.
+
+You don't usually run this yourself. When Tilt runs on a cloud workstation
+and your sources live on your laptop (e.g., mounted with SSHFS), point Tilt at
+them with:
+
+  TILT_WATCH_REMOTE=/mnt/src=me@laptop:/Users/me/src tilt up
+
+and it runs this command on your laptop over ssh. Reports file changes on stdout
+until stdin closes.
+`,
+		Args: cobra.MinimumNArgs(1),
+	}
+}
+
+func (c *watchAgentCmd) run(ctx context.Context, args []string) error {
+	// stdout is for the watcher on the other end, so log to stderr.
+	l := logger.NewLogger(logLevel(verbose, debug), os.Stderr)
+	return watch.ServeRemoteAgent(ctx, args, os.Stdin, os.Stdout, l)
+}
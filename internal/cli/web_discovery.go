@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Each running Tilt session writes a discovery file to this directory
+// (under ~/.windmill), so that commands like `tilt logs` and `tilt trigger`
+// can find its web server even if it had to pick a different port.
+const webSessionsDir = "sessions"
+
+// The listener opened by `tilt up` or `tilt ci`, before we wire up the server.
+var webListener server.WebListener
+
+func provideWebListener() server.WebListener {
+	return webListener
+}
+
+type webSession struct {
+	Tiltfile string `json:"tiltfile"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	PID      int    `json:"pid"`
+}
+
+func (s webSession) fileName() string {
+	return strconv.Itoa(s.PID) + ".json"
+}
+
+// Open the web server's listener, and record where we ended up listening.
+//
+// Returns a function that removes the discovery file on exit.
+func listenWeb(ctx context.Context, tiltfile string) (func(), error) {
+	if webPort == 0 {
+		return func() {}, nil
+	}
+
+	l, err := server.ListenWeb(provideWebHost(), provideWebPort())
+	if err != nil {
+		return nil, fmt.Errorf("Cannot start Tilt: %v. Use --port to set a custom port", err)
+	}
+
+	addr, ok := l.Addr().(*net.TCPAddr)
+	if ok && addr.Port != webPort {
+		logger.Get(ctx).Warnf("Port %d is in use, so Tilt is listening on port %d instead.\n"+
+			"Commands like `tilt logs` will find it automatically when run from this project.",
+			webPort, addr.Port)
+		webPort = addr.Port
+	}
+	webListener = l
+
+	absTiltfile, err := filepath.Abs(tiltfile)
+	if err != nil {
+		return func() {}, nil
+	}
+	path, err := writeWebSession(webSession{Tiltfile: absTiltfile, Host: webHost, Port: webPort, PID: os.Getpid()})
+	if err != nil {
+		// Not fatal: you can still pass --port to find this session.
+		logger.Get(ctx).Debugf("Writing web server discovery file: %v", err)
+		return func() {}, nil
+	}
+	return func() {
+		_ = os.Remove(path)
+	}, nil
+}
+
+func writeWebSession(session webSession) (string, error) {
+	dir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(webSessionsDir, session.fileName())
+	err = dir.WriteFile(p, string(contents))
+	if err != nil {
+		return "", err
+	}
+	return dir.Abs(p)
+}
+
+// For commands that connect to a running Tilt, use the discovery files to find
+// the session for the current project, unless the user passed --host or --port.
+func discoverWebServerPreRun(cmd *cobra.Command, args []string) {
+	if cmd.Flags().Changed("port") || cmd.Flags().Changed("host") {
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+
+	dir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return
+	}
+
+	session, ok := findWebSession(filepath.Join(dir.Root(), webSessionsDir), cwd)
+	if ok {
+		webHost = session.Host
+		webPort = session.Port
+	}
+}
+
+// Find the running session for the project containing cwd, preferring the
+// most deeply nested project. If there's no match, but only one Tilt session
+// is running, use that.
+func findWebSession(sessionsDir string, cwd string) (webSession, bool) {
+	files, err := ioutil.ReadDir(sessionsDir)
+	if err != nil {
+		return webSession{}, false
+	}
+
+	var live []webSession
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(filepath.Join(sessionsDir, f.Name()))
+		if err != nil {
+			continue
+		}
+
+		var session webSession
+		err = json.Unmarshal(contents, &session)
+		if err != nil || session.Port == 0 || !isWebSessionLive(session) {
+			continue
+		}
+		live = append(live, session)
+	}
+
+	var matches []webSession
+	for _, session := range live {
+		if isWithinDir(cwd, filepath.Dir(session.Tiltfile)) {
+			matches = append(matches, session)
+		}
+	}
+	if len(matches) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return len(matches[i].Tiltfile) > len(matches[j].Tiltfile)
+		})
+		return matches[0], true
+	}
+
+	if len(live) == 1 {
+		return live[0], true
+	}
+	return webSession{}, false
+}
+
+// If Tilt crashed, it may have left its discovery file behind.
+func isWebSessionLive(session webSession) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(dialableHost(session.Host), strconv.Itoa(session.Port)), time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+func dialableHost(host string) string {
+	if host == "0.0.0.0" || host == "" {
+		return "127.0.0.1"
+	}
+	return host
+}
+
+func isWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
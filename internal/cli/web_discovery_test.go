@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestFindWebSession(t *testing.T) {
+	f := newWebSessionFixture(t)
+	defer f.TearDown()
+
+	outer := f.writeLiveSession(1, f.JoinPath("projects", "Tiltfile"))
+	inner := f.writeLiveSession(2, f.JoinPath("projects", "app", "Tiltfile"))
+	f.writeLiveSession(3, f.JoinPath("other", "Tiltfile"))
+
+	session, ok := findWebSession(f.JoinPath("sessions"), f.JoinPath("projects", "app", "src"))
+	require.True(t, ok)
+	assert.Equal(t, inner, session)
+
+	session, ok = findWebSession(f.JoinPath("sessions"), f.JoinPath("projects", "lib"))
+	require.True(t, ok)
+	assert.Equal(t, outer, session)
+
+	// Several sessions running, none for this dir.
+	_, ok = findWebSession(f.JoinPath("sessions"), f.JoinPath("elsewhere"))
+	assert.False(t, ok)
+}
+
+func TestFindWebSessionSkipsDeadSessions(t *testing.T) {
+	f := newWebSessionFixture(t)
+	defer f.TearDown()
+
+	live := f.writeLiveSession(1, f.JoinPath("a", "Tiltfile"))
+
+	// A session that crashed without cleaning up.
+	dead := f.writeLiveSession(2, f.JoinPath("b", "Tiltfile"))
+	_ = f.listeners[len(f.listeners)-1].Close()
+
+	session, ok := findWebSession(f.JoinPath("sessions"), f.JoinPath("b"))
+	require.True(t, ok)
+	assert.Equal(t, live, session, "expected the only live session, not %v", dead)
+}
+
+type webSessionFixture struct {
+	*tempdir.TempDirFixture
+	listeners []net.Listener
+}
+
+func newWebSessionFixture(t *testing.T) *webSessionFixture {
+	return &webSessionFixture{TempDirFixture: tempdir.NewTempDirFixture(t)}
+}
+
+func (f *webSessionFixture) writeLiveSession(pid int, tiltfile string) webSession {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(f.T(), err)
+	f.listeners = append(f.listeners, l)
+
+	session := webSession{Tiltfile: tiltfile, Host: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port, PID: pid}
+	contents, err := json.Marshal(session)
+	require.NoError(f.T(), err)
+	f.WriteFile(filepath.Join("sessions", session.fileName()), string(contents))
+	return session
+}
+
+func (f *webSessionFixture) TearDown() {
+	for _, l := range f.listeners {
+		_ = l.Close()
+	}
+	f.TempDirFixture.TearDown()
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/tilt-dev/wmclient/pkg/dirs"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"k8s.io/apimachinery/pkg/version"
-	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
@@ -24,24 +23,39 @@ import (
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/engine"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/autoscale"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/cronjob"
 	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/exit"
 	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/hibernate"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
+	"github.com/tilt-dev/tilt/internal/engine/k8sheartbeat"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/localdns"
 	"github.com/tilt-dev/tilt/internal/engine/metrics"
 	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/git"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
 	"github.com/tilt-dev/tilt/internal/token"
@@ -58,6 +72,7 @@ var K8sWireSet = wire.NewSet(
 	k8s.ProvideClientConfig,
 	k8s.ProvideClientset,
 	k8s.ProvideRESTConfig,
+	k8s.ProvideExecCredentials,
 	k8s.ProvidePortForwardClient,
 	k8s.ProvideConfigNamespace,
 	k8s.ProvideKubectlRunner,
@@ -77,6 +92,18 @@ var BaseWireSet = wire.NewSet(
 
 	ProvideDeferredExporter,
 	metrics.NewController,
+	k8sheartbeat.NewController,
+	k8scredentials.NewController,
+	localdns.ProvideListenPacket,
+	localdns.NewController,
+	hibernate.NewController, endpointhealth.NewController, baseimage.NewController, linkdiscovery.NewController,
+	buildlogs.NewArchiver,
+	cronjob.NewController,
+	autoscale.NewController,
+	workloadstatus.NewController,
+	seed.NewController,
+	watchdog.NewWatchdog,
+	wire.Bind(new(watchdog.WebsocketBacklogger), new(*server.HeadsUpServer)),
 	dockercompose.NewDockerComposeClient,
 
 	clockwork.NewRealClock,
@@ -91,6 +118,7 @@ var BaseWireSet = wire.NewSet(
 	k8swatch.NewEventWatchManager,
 	configs.NewConfigsController,
 	telemetry.NewController,
+	ProvideOfflineMode,
 	dcwatch.NewEventWatcher,
 	runtimelog.NewDockerComposeLogManager,
 	engine.NewProfilerManager,
@@ -109,14 +137,17 @@ var BaseWireSet = wire.NewSet(
 	wire.Bind(new(store.RStore), new(*store.Store)),
 
 	dockerprune.NewDockerPruner,
+	dockerprune.NewDiskGovernor,
 
 	provideTiltInfo,
 	engine.ProvideSubscribers,
 	engine.NewUpper,
 	engineanalytics.NewAnalyticsUpdater,
 	engineanalytics.ProvideAnalyticsReporter,
+	scheduler.NewScheduler,
 	provideUpdateModeFlag,
 	fswatch.NewGitManager,
+	fswatch.NewLimitsChecker,
 	fswatch.NewWatchManager,
 	fswatch.ProvideFsWatcherMaker,
 	fswatch.ProvideTimerMaker,
@@ -125,10 +156,14 @@ var BaseWireSet = wire.NewSet(
 	provideWebMode,
 	provideWebURL,
 	provideWebPort,
+	provideWebListener,
 	provideWebHost,
+	provideWebBasePath,
+	provideWebCORSOrigins,
 	server.ProvideHeadsUpServer,
 	provideAssetServer,
 	server.ProvideHeadsUpServerController,
+	listeners.NewRegistry,
 
 	tracer.NewSpanCollector,
 	wire.Bind(new(sdktrace.SpanProcessor), new(*tracer.SpanCollector)),
@@ -138,6 +173,7 @@ var BaseWireSet = wire.NewSet(
 	token.GetOrCreateToken,
 
 	engine.NewKINDLoader,
+	engine.NewCRIOLoader,
 
 	wire.Value(feature.MainDefaults),
 )
@@ -166,6 +202,9 @@ type CmdUpDeps struct {
 	CloudAddress cloudurl.Address
 	Store        *store.Store
 	Prompt       *prompt.TerminalPrompt
+
+	// Nil unless the kubeconfig context authenticates with an exec credential plugin.
+	ExecCredentials *k8s.ExecCredentials
 }
 
 func wireCmdCI(ctx context.Context, analytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (CmdCIDeps, error) {
@@ -183,6 +222,7 @@ type CmdCIDeps struct {
 	Token        token.Token
 	CloudAddress cloudurl.Address
 	Store        *store.Store
+	K8sClient    k8s.Client
 }
 
 func wireKubeContext(ctx context.Context) (k8s.KubeContext, error) {
@@ -190,9 +230,9 @@ func wireKubeContext(ctx context.Context) (k8s.KubeContext, error) {
 	return "", nil
 }
 
-func wireKubeConfig(ctx context.Context) (*api.Config, error) {
+func wireKubeConfig(ctx context.Context) (k8s.APIConfigOrError, error) {
 	wire.Build(K8sWireSet)
-	return nil, nil
+	return k8s.APIConfigOrError{}, nil
 }
 
 func wireEnv(ctx context.Context) (k8s.Env, error) {
@@ -249,19 +289,22 @@ func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, s
 }
 
 type DownDeps struct {
-	tfl      tiltfile.TiltfileLoader
-	dcClient dockercompose.DockerComposeClient
-	kClient  k8s.Client
+	tfl        tiltfile.TiltfileLoader
+	dcClient   dockercompose.DockerComposeClient
+	kClient    k8s.Client
+	kNamespace k8s.Namespace
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
-	kClient k8s.Client) DownDeps {
+	kClient k8s.Client,
+	kNamespace k8s.Namespace) DownDeps {
 	return DownDeps{
-		tfl:      tfl,
-		dcClient: dcClient,
-		kClient:  kClient,
+		tfl:        tfl,
+		dcClient:   dcClient,
+		kClient:    kClient,
+		kNamespace: kNamespace,
 	}
 }
 
@@ -14,7 +14,6 @@ import (
 	"github.com/tilt-dev/wmclient/pkg/dirs"
 	"go.opentelemetry.io/otel/sdk/trace"
 	version2 "k8s.io/apimachinery/pkg/version"
-	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
@@ -27,25 +26,40 @@ import (
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/engine"
 	analytics2 "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/autoscale"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/cronjob"
 	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/exit"
 	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/hibernate"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
+	"github.com/tilt-dev/tilt/internal/engine/k8sheartbeat"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/localdns"
 	"github.com/tilt-dev/tilt/internal/engine/metrics"
 	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/git"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/synclet/sidecar"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
@@ -63,23 +77,20 @@ import (
 func wireTiltfileResult(ctx context.Context, analytics2 *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (cmdTiltfileResultDeps, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return cmdTiltfileResultDeps{}, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return cmdTiltfileResultDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	extension := k8scontext.NewExtension(kubeContext, env)
 	tiltBuild := provideTiltInfo()
 	versionExtension := version.NewExtension(tiltBuild)
@@ -102,23 +113,20 @@ var (
 func wireDockerPrune(ctx context.Context, analytics2 *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (dpDeps, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return dpDeps{}, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return dpDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -148,45 +156,58 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 	renderer := hud.NewRenderer(v)
 	modelWebHost := provideWebHost()
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebHost, modelWebPort)
+	modelWebBasePath, err := provideWebBasePath()
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
-	headsUpDisplay := hud.NewHud(renderer, webURL, analytics3)
+	webURL, err := provideWebURL(modelWebHost, modelWebPort, modelWebBasePath)
+	if err != nil {
+		return CmdUpDeps{}, err
+	}
+	windmillDir, err := dirs.UseWindmillDir()
+	if err != nil {
+		return CmdUpDeps{}, err
+	}
+	pinStore := hud.NewPinStore(windmillDir)
+	headsUpDisplay := hud.NewHud(renderer, webURL, analytics3, pinStore)
 	stdout := hud.ProvideStdout()
 	incrementalPrinter := hud.NewIncrementalPrinter(stdout)
 	terminalStream := hud.NewTerminalStream(incrementalPrinter, storeStore)
+	jsonStream := hud.NewJSONStream(stdout, storeStore)
 	openInput := _wireOpenInputValue
 	openURL := _wireOpenURLValue
-	terminalPrompt := prompt.NewTerminalPrompt(analytics3, openInput, openURL, stdout, modelWebHost, webURL)
-	k8sKubeContextOverride := ProvideKubeContextOverride()
-	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	keyBindings, err := prompt.ProvideKeyBindings()
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	terminalPrompt := prompt.NewTerminalPrompt(analytics3, openInput, openURL, stdout, modelWebHost, webURL, keyBindings)
+	k8sKubeContextOverride := ProvideKubeContextOverride()
+	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	ownerFetcher := k8s.ProvideOwnerFetcher(client)
 	podWatcher := k8swatch.NewPodWatcher(client, ownerFetcher, namespace)
 	serviceWatcher := k8swatch.NewServiceWatcher(client, ownerFetcher, namespace)
 	podLogManager := runtimelog.NewPodLogManager(client)
-	controller := portforward.NewController(client)
+	registry := listeners.NewRegistry()
+	controller := portforward.NewController(client, namespace, registry)
 	fsWatcherMaker := fswatch.ProvideFsWatcherMaker()
 	timerMaker := fswatch.ProvideTimerMaker()
-	watchManager := fswatch.NewWatchManager(fsWatcherMaker, timerMaker)
-	gitManager := fswatch.NewGitManager(fsWatcherMaker)
+	clock := build.ProvideClock()
+	watchManager := fswatch.NewWatchManager(fsWatcherMaker, timerMaker, clock)
+	gitManager := fswatch.NewGitManager(fsWatcherMaker, timerMaker, clock)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -209,18 +230,23 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
-	clock := build.ProvideClock()
-	liveUpdateBuildAndDeployer := engine.NewLiveUpdateBuildAndDeployer(dockerUpdater, syncletUpdater, execUpdater, updateMode, env, runtime, clock)
+	liveUpdateBuildAndDeployer := engine.NewLiveUpdateBuildAndDeployer(dockerUpdater, syncletUpdater, execUpdater, client, updateMode, env, runtime, clock)
 	labels := _wireLabelsValue
 	dockerImageBuilder := build.NewDockerImageBuilder(switchCli, labels)
 	dockerBuilder := build.DefaultDockerBuilder(dockerImageBuilder)
 	execCustomBuilder := build.NewExecCustomBuilder(switchCli, clock)
-	clusterName := k8s.ProvideClusterName(ctx, apiConfig)
+	clusterName := k8s.ProvideClusterName(ctx, apiConfigOrError)
 	kindLoader := engine.NewKINDLoader(env, clusterName)
+	crioLoader := engine.NewCRIOLoader(env)
 	syncletContainer := sidecar.ProvideSyncletContainer(syncletImageRef)
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(dockerBuilder, execCustomBuilder, client, env, analytics3, updateMode, clock, runtime, kindLoader, syncletContainer)
+	sessionID, err := k8s.ProvideSessionID()
+	if err != nil {
+		return CmdUpDeps{}, err
+	}
+	kanikoClusterBuilder := build.NewKanikoClusterBuilder(client, clock)
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(dockerBuilder, execCustomBuilder, kanikoClusterBuilder, client, env, analytics3, updateMode, clock, runtime, kindLoader, crioLoader, syncletContainer, sessionID)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(localEnv)
-	imageBuilder := engine.NewImageBuilder(dockerBuilder, execCustomBuilder, updateMode)
+	imageBuilder := engine.NewImageBuilder(dockerBuilder, execCustomBuilder, kanikoClusterBuilder, updateMode)
 	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, switchCli, imageBuilder, clock)
 	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
 	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, updateMode, env, runtime)
@@ -230,7 +256,7 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 		return CmdUpDeps{}, err
 	}
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder, traceTracer)
-	buildController := engine.NewBuildController(compositeBuildAndDeployer)
+	buildController := engine.NewBuildController(compositeBuildAndDeployer, clock)
 	extension := k8scontext.NewExtension(kubeContext, env)
 	tiltBuild := provideTiltInfo()
 	versionExtension := version.NewExtension(tiltBuild)
@@ -241,7 +267,9 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 	eventWatcher := dcwatch.NewEventWatcher(dockerComposeClient, localClient)
 	dockerComposeLogManager := runtimelog.NewDockerComposeLogManager(dockerComposeClient)
 	profilerManager := engine.NewProfilerManager()
-	analyticsReporter := analytics2.ProvideAnalyticsReporter(analytics3, storeStore, client, env)
+	clockworkClock := clockwork.NewRealClock()
+	schedulerScheduler := scheduler.NewScheduler(clockworkClock)
+	analyticsReporter := analytics2.ProvideAnalyticsReporter(analytics3, storeStore, client, env, schedulerScheduler)
 	webMode, err := provideWebMode(tiltBuild)
 	if err != nil {
 		return CmdUpDeps{}, err
@@ -251,20 +279,22 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
-	httpClient := cloud.ProvideHttpClient()
+	offlineMode := ProvideOfflineMode()
+	httpClient := cloud.ProvideHttpClient(offlineMode)
 	address := cloudurl.ProvideAddress()
 	snapshotUploader := cloud.NewSnapshotUploader(httpClient, address)
-	headsUpServer, err := server.ProvideHeadsUpServer(ctx, storeStore, assetsServer, analytics3, snapshotUploader)
+	modelWebCORSOrigins := provideWebCORSOrigins()
+	headsUpServer, err := server.ProvideHeadsUpServer(ctx, storeStore, assetsServer, analytics3, snapshotUploader, modelWebBasePath, modelWebCORSOrigins, registry, client)
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
-	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, headsUpServer, assetsServer, webURL)
+	serverWebListener := provideWebListener()
+	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, serverWebListener, headsUpServer, assetsServer, webURL, registry)
 	analyticsUpdater := analytics2.NewAnalyticsUpdater(analytics3, cmdTags)
 	eventWatchManager := k8swatch.NewEventWatchManager(client, ownerFetcher, namespace)
-	clockworkClock := clockwork.NewRealClock()
 	cloudStatusManager := cloud.NewStatusManager(httpClient, clockworkClock)
-	dockerPruner := dockerprune.NewDockerPruner(switchCli)
-	telemetryController := telemetry.NewController(clock, spanCollector)
+	dockerPruner := dockerprune.NewDockerPruner(switchCli, clock)
+	telemetryController := telemetry.NewController(clock, spanCollector, offlineMode, windmillDir)
 	execer := local.ProvideExecer()
 	localController := local.NewController(execer)
 	podMonitor := k8srollout.NewPodMonitor()
@@ -272,23 +302,37 @@ func wireCmdUp(ctx context.Context, analytics3 *analytics.TiltAnalytics, cmdTags
 	deferredExporter := ProvideDeferredExporter()
 	gitRemote := git.ProvideGitRemote()
 	metricsController := metrics.NewController(deferredExporter, tiltBuild, gitRemote)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, terminalStream, terminalPrompt, podWatcher, serviceWatcher, podLogManager, controller, watchManager, gitManager, buildController, configsController, eventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, analyticsUpdater, eventWatchManager, cloudStatusManager, dockerPruner, telemetryController, localController, podMonitor, exitController, metricsController)
+	k8sheartbeatController := k8sheartbeat.NewController(client, schedulerScheduler, clock)
+	execCredentials := k8s.ProvideExecCredentials(restConfigOrError)
+	k8scredentialsController := k8scredentials.NewController(execCredentials, storeStore, schedulerScheduler)
+	listenPacket := localdns.ProvideListenPacket()
+	localdnsController := localdns.NewController(listenPacket)
+	hibernateController := hibernate.NewController(client, schedulerScheduler, clock, registry)
+	endpointhealthController := endpointhealth.NewController(schedulerScheduler, clock)
+	baseimageController := baseimage.NewController(schedulerScheduler, switchCli)
+	linkdiscoveryController := linkdiscovery.NewController()
+	archiver := buildlogs.NewArchiver()
+	cronjobController := cronjob.NewController(client, clock)
+	autoscaleController := autoscale.NewController(client)
+	workloadstatusController := workloadstatus.NewController(client, schedulerScheduler)
+	seedController := seed.NewController(client, clock)
+	watchdogWatchdog := watchdog.NewWatchdog(storeStore, headsUpServer, schedulerScheduler, clock)
+	diskGovernor := dockerprune.NewDiskGovernor(switchCli, dockerPruner, schedulerScheduler, clock)
+	limitsChecker := fswatch.NewLimitsChecker()
+	v2 := engine.ProvideSubscribers(headsUpDisplay, terminalStream, jsonStream, terminalPrompt, podWatcher, serviceWatcher, podLogManager, controller, watchManager, gitManager, limitsChecker, buildController, configsController, eventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, analyticsUpdater, eventWatchManager, cloudStatusManager, dockerPruner, diskGovernor, telemetryController, localController, podMonitor, exitController, metricsController, k8sheartbeatController, k8scredentialsController, localdnsController, hibernateController, endpointhealthController, baseimageController, linkdiscoveryController, archiver, cronjobController, autoscaleController, workloadstatusController, seedController, watchdogWatchdog, schedulerScheduler)
 	upper := engine.NewUpper(ctx, storeStore, v2)
-	windmillDir, err := dirs.UseWindmillDir()
-	if err != nil {
-		return CmdUpDeps{}, err
-	}
 	tokenToken, err := token.GetOrCreateToken(windmillDir)
 	if err != nil {
 		return CmdUpDeps{}, err
 	}
 	cmdUpDeps := CmdUpDeps{
-		Upper:        upper,
-		TiltBuild:    tiltBuild,
-		Token:        tokenToken,
-		CloudAddress: address,
-		Store:        storeStore,
-		Prompt:       terminalPrompt,
+		Upper:           upper,
+		TiltBuild:       tiltBuild,
+		Token:           tokenToken,
+		CloudAddress:    address,
+		Store:           storeStore,
+		Prompt:          terminalPrompt,
+		ExecCredentials: execCredentials,
 	}
 	return cmdUpDeps, nil
 }
@@ -308,45 +352,58 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 	renderer := hud.NewRenderer(v)
 	modelWebHost := provideWebHost()
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebHost, modelWebPort)
+	modelWebBasePath, err := provideWebBasePath()
+	if err != nil {
+		return CmdCIDeps{}, err
+	}
+	webURL, err := provideWebURL(modelWebHost, modelWebPort, modelWebBasePath)
+	if err != nil {
+		return CmdCIDeps{}, err
+	}
+	windmillDir, err := dirs.UseWindmillDir()
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
-	headsUpDisplay := hud.NewHud(renderer, webURL, analytics3)
+	pinStore := hud.NewPinStore(windmillDir)
+	headsUpDisplay := hud.NewHud(renderer, webURL, analytics3, pinStore)
 	stdout := hud.ProvideStdout()
 	incrementalPrinter := hud.NewIncrementalPrinter(stdout)
 	terminalStream := hud.NewTerminalStream(incrementalPrinter, storeStore)
+	jsonStream := hud.NewJSONStream(stdout, storeStore)
 	openInput := _wireOpenInputValue
 	openURL := _wireOpenURLValue
-	terminalPrompt := prompt.NewTerminalPrompt(analytics3, openInput, openURL, stdout, modelWebHost, webURL)
-	k8sKubeContextOverride := ProvideKubeContextOverride()
-	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	keyBindings, err := prompt.ProvideKeyBindings()
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	terminalPrompt := prompt.NewTerminalPrompt(analytics3, openInput, openURL, stdout, modelWebHost, webURL, keyBindings)
+	k8sKubeContextOverride := ProvideKubeContextOverride()
+	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	ownerFetcher := k8s.ProvideOwnerFetcher(client)
 	podWatcher := k8swatch.NewPodWatcher(client, ownerFetcher, namespace)
 	serviceWatcher := k8swatch.NewServiceWatcher(client, ownerFetcher, namespace)
 	podLogManager := runtimelog.NewPodLogManager(client)
-	controller := portforward.NewController(client)
+	registry := listeners.NewRegistry()
+	controller := portforward.NewController(client, namespace, registry)
 	fsWatcherMaker := fswatch.ProvideFsWatcherMaker()
 	timerMaker := fswatch.ProvideTimerMaker()
-	watchManager := fswatch.NewWatchManager(fsWatcherMaker, timerMaker)
-	gitManager := fswatch.NewGitManager(fsWatcherMaker)
+	clock := build.ProvideClock()
+	watchManager := fswatch.NewWatchManager(fsWatcherMaker, timerMaker, clock)
+	gitManager := fswatch.NewGitManager(fsWatcherMaker, timerMaker, clock)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -369,18 +426,23 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
-	clock := build.ProvideClock()
-	liveUpdateBuildAndDeployer := engine.NewLiveUpdateBuildAndDeployer(dockerUpdater, syncletUpdater, execUpdater, updateMode, env, runtime, clock)
+	liveUpdateBuildAndDeployer := engine.NewLiveUpdateBuildAndDeployer(dockerUpdater, syncletUpdater, execUpdater, client, updateMode, env, runtime, clock)
 	labels := _wireLabelsValue
 	dockerImageBuilder := build.NewDockerImageBuilder(switchCli, labels)
 	dockerBuilder := build.DefaultDockerBuilder(dockerImageBuilder)
 	execCustomBuilder := build.NewExecCustomBuilder(switchCli, clock)
-	clusterName := k8s.ProvideClusterName(ctx, apiConfig)
+	clusterName := k8s.ProvideClusterName(ctx, apiConfigOrError)
 	kindLoader := engine.NewKINDLoader(env, clusterName)
+	crioLoader := engine.NewCRIOLoader(env)
 	syncletContainer := sidecar.ProvideSyncletContainer(syncletImageRef)
-	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(dockerBuilder, execCustomBuilder, client, env, analytics3, updateMode, clock, runtime, kindLoader, syncletContainer)
+	sessionID, err := k8s.ProvideSessionID()
+	if err != nil {
+		return CmdCIDeps{}, err
+	}
+	kanikoClusterBuilder := build.NewKanikoClusterBuilder(client, clock)
+	imageBuildAndDeployer := engine.NewImageBuildAndDeployer(dockerBuilder, execCustomBuilder, kanikoClusterBuilder, client, env, analytics3, updateMode, clock, runtime, kindLoader, crioLoader, syncletContainer, sessionID)
 	dockerComposeClient := dockercompose.NewDockerComposeClient(localEnv)
-	imageBuilder := engine.NewImageBuilder(dockerBuilder, execCustomBuilder, updateMode)
+	imageBuilder := engine.NewImageBuilder(dockerBuilder, execCustomBuilder, kanikoClusterBuilder, updateMode)
 	dockerComposeBuildAndDeployer := engine.NewDockerComposeBuildAndDeployer(dockerComposeClient, switchCli, imageBuilder, clock)
 	localTargetBuildAndDeployer := engine.NewLocalTargetBuildAndDeployer(clock)
 	buildOrder := engine.DefaultBuildOrder(liveUpdateBuildAndDeployer, imageBuildAndDeployer, dockerComposeBuildAndDeployer, localTargetBuildAndDeployer, updateMode, env, runtime)
@@ -390,7 +452,7 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 		return CmdCIDeps{}, err
 	}
 	compositeBuildAndDeployer := engine.NewCompositeBuildAndDeployer(buildOrder, traceTracer)
-	buildController := engine.NewBuildController(compositeBuildAndDeployer)
+	buildController := engine.NewBuildController(compositeBuildAndDeployer, clock)
 	extension := k8scontext.NewExtension(kubeContext, env)
 	tiltBuild := provideTiltInfo()
 	versionExtension := version.NewExtension(tiltBuild)
@@ -401,7 +463,9 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 	eventWatcher := dcwatch.NewEventWatcher(dockerComposeClient, localClient)
 	dockerComposeLogManager := runtimelog.NewDockerComposeLogManager(dockerComposeClient)
 	profilerManager := engine.NewProfilerManager()
-	analyticsReporter := analytics2.ProvideAnalyticsReporter(analytics3, storeStore, client, env)
+	clockworkClock := clockwork.NewRealClock()
+	schedulerScheduler := scheduler.NewScheduler(clockworkClock)
+	analyticsReporter := analytics2.ProvideAnalyticsReporter(analytics3, storeStore, client, env, schedulerScheduler)
 	webMode, err := provideWebMode(tiltBuild)
 	if err != nil {
 		return CmdCIDeps{}, err
@@ -411,21 +475,23 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
-	httpClient := cloud.ProvideHttpClient()
+	offlineMode := ProvideOfflineMode()
+	httpClient := cloud.ProvideHttpClient(offlineMode)
 	address := cloudurl.ProvideAddress()
 	snapshotUploader := cloud.NewSnapshotUploader(httpClient, address)
-	headsUpServer, err := server.ProvideHeadsUpServer(ctx, storeStore, assetsServer, analytics3, snapshotUploader)
+	modelWebCORSOrigins := provideWebCORSOrigins()
+	headsUpServer, err := server.ProvideHeadsUpServer(ctx, storeStore, assetsServer, analytics3, snapshotUploader, modelWebBasePath, modelWebCORSOrigins, registry, client)
 	if err != nil {
 		return CmdCIDeps{}, err
 	}
-	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, headsUpServer, assetsServer, webURL)
+	serverWebListener := provideWebListener()
+	headsUpServerController := server.ProvideHeadsUpServerController(modelWebHost, modelWebPort, serverWebListener, headsUpServer, assetsServer, webURL, registry)
 	cmdTags := _wireCmdTagsValue
 	analyticsUpdater := analytics2.NewAnalyticsUpdater(analytics3, cmdTags)
 	eventWatchManager := k8swatch.NewEventWatchManager(client, ownerFetcher, namespace)
-	clockworkClock := clockwork.NewRealClock()
 	cloudStatusManager := cloud.NewStatusManager(httpClient, clockworkClock)
-	dockerPruner := dockerprune.NewDockerPruner(switchCli)
-	telemetryController := telemetry.NewController(clock, spanCollector)
+	dockerPruner := dockerprune.NewDockerPruner(switchCli, clock)
+	telemetryController := telemetry.NewController(clock, spanCollector, offlineMode, windmillDir)
 	execer := local.ProvideExecer()
 	localController := local.NewController(execer)
 	podMonitor := k8srollout.NewPodMonitor()
@@ -433,12 +499,25 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 	deferredExporter := ProvideDeferredExporter()
 	gitRemote := git.ProvideGitRemote()
 	metricsController := metrics.NewController(deferredExporter, tiltBuild, gitRemote)
-	v2 := engine.ProvideSubscribers(headsUpDisplay, terminalStream, terminalPrompt, podWatcher, serviceWatcher, podLogManager, controller, watchManager, gitManager, buildController, configsController, eventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, analyticsUpdater, eventWatchManager, cloudStatusManager, dockerPruner, telemetryController, localController, podMonitor, exitController, metricsController)
+	k8sheartbeatController := k8sheartbeat.NewController(client, schedulerScheduler, clock)
+	execCredentials := k8s.ProvideExecCredentials(restConfigOrError)
+	k8scredentialsController := k8scredentials.NewController(execCredentials, storeStore, schedulerScheduler)
+	listenPacket := localdns.ProvideListenPacket()
+	localdnsController := localdns.NewController(listenPacket)
+	hibernateController := hibernate.NewController(client, schedulerScheduler, clock, registry)
+	endpointhealthController := endpointhealth.NewController(schedulerScheduler, clock)
+	baseimageController := baseimage.NewController(schedulerScheduler, switchCli)
+	linkdiscoveryController := linkdiscovery.NewController()
+	archiver := buildlogs.NewArchiver()
+	cronjobController := cronjob.NewController(client, clock)
+	autoscaleController := autoscale.NewController(client)
+	workloadstatusController := workloadstatus.NewController(client, schedulerScheduler)
+	seedController := seed.NewController(client, clock)
+	watchdogWatchdog := watchdog.NewWatchdog(storeStore, headsUpServer, schedulerScheduler, clock)
+	diskGovernor := dockerprune.NewDiskGovernor(switchCli, dockerPruner, schedulerScheduler, clock)
+	limitsChecker := fswatch.NewLimitsChecker()
+	v2 := engine.ProvideSubscribers(headsUpDisplay, terminalStream, jsonStream, terminalPrompt, podWatcher, serviceWatcher, podLogManager, controller, watchManager, gitManager, limitsChecker, buildController, configsController, eventWatcher, dockerComposeLogManager, profilerManager, syncletManager, analyticsReporter, headsUpServerController, analyticsUpdater, eventWatchManager, cloudStatusManager, dockerPruner, diskGovernor, telemetryController, localController, podMonitor, exitController, metricsController, k8sheartbeatController, k8scredentialsController, localdnsController, hibernateController, endpointhealthController, baseimageController, linkdiscoveryController, archiver, cronjobController, autoscaleController, workloadstatusController, seedController, watchdogWatchdog, schedulerScheduler)
 	upper := engine.NewUpper(ctx, storeStore, v2)
-	windmillDir, err := dirs.UseWindmillDir()
-	if err != nil {
-		return CmdCIDeps{}, err
-	}
 	tokenToken, err := token.GetOrCreateToken(windmillDir)
 	if err != nil {
 		return CmdCIDeps{}, err
@@ -449,6 +528,7 @@ func wireCmdCI(ctx context.Context, analytics3 *analytics.TiltAnalytics, subcomm
 		Token:        tokenToken,
 		CloudAddress: address,
 		Store:        storeStore,
+		K8sClient:    client,
 	}
 	return cmdCIDeps, nil
 }
@@ -460,35 +540,26 @@ var (
 func wireKubeContext(ctx context.Context) (k8s.KubeContext, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return "", err
-	}
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return "", err
 	}
 	return kubeContext, nil
 }
 
-func wireKubeConfig(ctx context.Context) (*api.Config, error) {
+func wireKubeConfig(ctx context.Context) (k8s.APIConfigOrError, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return nil, err
-	}
-	return apiConfig, nil
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	return apiConfigOrError, nil
 }
 
 func wireEnv(ctx context.Context) (k8s.Env, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return "", err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
 	return env, nil
 }
 
@@ -502,34 +573,28 @@ func wireNamespace(ctx context.Context) (k8s.Namespace, error) {
 func wireClusterName(ctx context.Context) (k8s.ClusterName, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return "", err
-	}
-	clusterName := k8s.ProvideClusterName(ctx, apiConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	clusterName := k8s.ProvideClusterName(ctx, apiConfigOrError)
 	return clusterName, nil
 }
 
 func wireRuntime(ctx context.Context) (container.Runtime, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return "", err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return "", err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	return runtime, nil
 }
@@ -537,30 +602,27 @@ func wireRuntime(ctx context.Context) (container.Runtime, error) {
 func wireK8sClient(ctx context.Context) (k8s.Client, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return nil, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return nil, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	return client, nil
 }
 
 func wireK8sVersion(ctx context.Context) (*version2.Info, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	info, err := k8s.ProvideServerVersion(clientsetOrError)
 	if err != nil {
@@ -572,23 +634,20 @@ func wireK8sVersion(ctx context.Context) (*version2.Info, error) {
 func wireDockerClusterClient(ctx context.Context) (docker.ClusterClient, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return nil, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return nil, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -603,23 +662,20 @@ func wireDockerClusterClient(ctx context.Context) (docker.ClusterClient, error)
 func wireDockerLocalClient(ctx context.Context) (docker.LocalClient, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return nil, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return nil, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -630,23 +686,20 @@ func wireDockerLocalClient(ctx context.Context) (docker.LocalClient, error) {
 func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (DownDeps, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return DownDeps{}, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return DownDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	extension := k8scontext.NewExtension(kubeContext, env)
 	tiltBuild := provideTiltInfo()
 	versionExtension := version.NewExtension(tiltBuild)
@@ -658,14 +711,18 @@ func wireDownDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, s
 	modelWebHost := provideWebHost()
 	defaults := _wireDefaultsValue
 	tiltfileLoader := tiltfile.ProvideTiltfileLoader(tiltAnalytics, client, extension, versionExtension, configExtension, dockerComposeClient, modelWebHost, defaults, env)
-	downDeps := ProvideDownDeps(tiltfileLoader, dockerComposeClient, client)
+	downDeps := ProvideDownDeps(tiltfileLoader, dockerComposeClient, client, namespace)
 	return downDeps, nil
 }
 
 func wireLogsDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (LogsDeps, error) {
 	modelWebHost := provideWebHost()
 	modelWebPort := provideWebPort()
-	webURL, err := provideWebURL(modelWebHost, modelWebPort)
+	modelWebBasePath, err := provideWebBasePath()
+	if err != nil {
+		return LogsDeps{}, err
+	}
+	webURL, err := provideWebURL(modelWebHost, modelWebPort, modelWebBasePath)
 	if err != nil {
 		return LogsDeps{}, err
 	}
@@ -678,23 +735,20 @@ func wireLogsDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, s
 func wireDumpImageDeployRefDeps(ctx context.Context) (DumpImageDeployRefDeps, error) {
 	k8sKubeContextOverride := ProvideKubeContextOverride()
 	clientConfig := k8s.ProvideClientConfig(k8sKubeContextOverride)
-	apiConfig, err := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
-	if err != nil {
-		return DumpImageDeployRefDeps{}, err
-	}
-	env := k8s.ProvideEnv(ctx, apiConfig)
-	restConfigOrError := k8s.ProvideRESTConfig(clientConfig)
+	apiConfigOrError := k8s.ProvideKubeConfig(clientConfig, k8sKubeContextOverride)
+	env := k8s.ProvideEnv(ctx, apiConfigOrError)
+	restConfigOrError := k8s.ProvideRESTConfig(clientConfig, k8sKubeContextOverride)
 	clientsetOrError := k8s.ProvideClientset(restConfigOrError)
 	portForwardClient := k8s.ProvidePortForwardClient(restConfigOrError, clientsetOrError)
 	namespace := k8s.ProvideConfigNamespace(clientConfig)
-	kubeContext, err := k8s.ProvideKubeContext(apiConfig)
+	kubeContext, err := k8s.ProvideKubeContext(apiConfigOrError)
 	if err != nil {
 		return DumpImageDeployRefDeps{}, err
 	}
 	int2 := provideKubectlLogLevel()
 	kubectlRunner := k8s.ProvideKubectlRunner(kubeContext, int2)
 	minikubeClient := k8s.ProvideMinikubeClient(kubeContext)
-	client := k8s.ProvideK8sClient(ctx, env, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
+	client := k8s.ProvideK8sClient(ctx, env, apiConfigOrError, restConfigOrError, clientsetOrError, portForwardClient, namespace, kubectlRunner, minikubeClient, clientConfig)
 	runtime := k8s.ProvideContainerRuntime(ctx, client)
 	clusterEnv := docker.ProvideClusterEnv(ctx, env, runtime, minikubeClient)
 	localEnv := docker.ProvideLocalEnv(ctx, clusterEnv)
@@ -717,7 +771,8 @@ func wireDumpImageDeployRefDeps(ctx context.Context) (DumpImageDeployRefDeps, er
 func wireAnalytics(l logger.Logger, cmdName model.TiltSubcommand) (*analytics.TiltAnalytics, error) {
 	tiltBuild := provideTiltInfo()
 	gitRemote := git.ProvideGitRemote()
-	tiltAnalytics, err := newAnalytics(l, cmdName, tiltBuild, gitRemote)
+	offlineMode := ProvideOfflineMode()
+	tiltAnalytics, err := newAnalytics(l, cmdName, tiltBuild, gitRemote, offlineMode)
 	if err != nil {
 		return nil, err
 	}
@@ -726,14 +781,17 @@ func wireAnalytics(l logger.Logger, cmdName model.TiltSubcommand) (*analytics.Ti
 
 // wire.go:
 
-var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.ProvideClusterName, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientset, k8s.ProvideRESTConfig, k8s.ProvidePortForwardClient, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient, k8s.ProvideOwnerFetcher, ProvideKubeContextOverride)
+var K8sWireSet = wire.NewSet(k8s.ProvideEnv, k8s.ProvideClusterName, k8s.ProvideKubeContext, k8s.ProvideKubeConfig, k8s.ProvideClientConfig, k8s.ProvideClientset, k8s.ProvideRESTConfig, k8s.ProvideExecCredentials, k8s.ProvidePortForwardClient, k8s.ProvideConfigNamespace, k8s.ProvideKubectlRunner, k8s.ProvideContainerRuntime, k8s.ProvideServerVersion, k8s.ProvideK8sClient, k8s.ProvideOwnerFetcher, ProvideKubeContextOverride)
 
 var BaseWireSet = wire.NewSet(
-	K8sWireSet, tiltfile.WireSet, provideKubectlLogLevel, git.ProvideGitRemote, docker.SwitchWireSet, ProvideDeferredExporter, metrics.NewController, dockercompose.NewDockerComposeClient, clockwork.NewRealClock, engine.DeployerWireSet, runtimelog.NewPodLogManager, portforward.NewController, engine.NewBuildController, local.ProvideExecer, local.NewController, k8swatch.NewPodWatcher, k8swatch.NewServiceWatcher, k8swatch.NewEventWatchManager, configs.NewConfigsController, telemetry.NewController, dcwatch.NewEventWatcher, runtimelog.NewDockerComposeLogManager, engine.NewProfilerManager, cloud.WireSet, cloudurl.ProvideAddress, k8srollout.NewPodMonitor, telemetry.NewStartTracker, exit.NewController, provideClock, hud.WireSet, prompt.WireSet, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(*store.Store)), dockerprune.NewDockerPruner, provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, analytics2.NewAnalyticsUpdater, analytics2.ProvideAnalyticsReporter, provideUpdateModeFlag, fswatch.NewGitManager, fswatch.NewWatchManager, fswatch.ProvideFsWatcherMaker, fswatch.ProvideTimerMaker, provideWebVersion,
+	K8sWireSet, tiltfile.WireSet, provideKubectlLogLevel, git.ProvideGitRemote, docker.SwitchWireSet, ProvideDeferredExporter, metrics.NewController, k8sheartbeat.NewController, k8scredentials.NewController, localdns.ProvideListenPacket, localdns.NewController, hibernate.NewController, endpointhealth.NewController, baseimage.NewController, linkdiscovery.NewController, buildlogs.NewArchiver, cronjob.NewController, autoscale.NewController, workloadstatus.NewController, seed.NewController, watchdog.NewWatchdog, wire.Bind(new(watchdog.WebsocketBacklogger), new(*server.HeadsUpServer)), dockercompose.NewDockerComposeClient, clockwork.NewRealClock, engine.DeployerWireSet, runtimelog.NewPodLogManager, portforward.NewController, engine.NewBuildController, local.ProvideExecer, local.NewController, k8swatch.NewPodWatcher, k8swatch.NewServiceWatcher, k8swatch.NewEventWatchManager, configs.NewConfigsController, telemetry.NewController, ProvideOfflineMode, dcwatch.NewEventWatcher, runtimelog.NewDockerComposeLogManager, engine.NewProfilerManager, cloud.WireSet, cloudurl.ProvideAddress, k8srollout.NewPodMonitor, telemetry.NewStartTracker, exit.NewController, provideClock, hud.WireSet, prompt.WireSet, provideLogActions, store.NewStore, wire.Bind(new(store.RStore), new(*store.Store)), dockerprune.NewDockerPruner, dockerprune.NewDiskGovernor, provideTiltInfo, engine.ProvideSubscribers, engine.NewUpper, analytics2.NewAnalyticsUpdater, analytics2.ProvideAnalyticsReporter, provideUpdateModeFlag, fswatch.NewGitManager, fswatch.NewLimitsChecker, fswatch.NewWatchManager, fswatch.ProvideFsWatcherMaker, fswatch.ProvideTimerMaker, provideWebVersion,
 	provideWebMode,
 	provideWebURL,
 	provideWebPort,
-	provideWebHost, server.ProvideHeadsUpServer, provideAssetServer, server.ProvideHeadsUpServerController, tracer.NewSpanCollector, wire.Bind(new(trace.SpanProcessor), new(*tracer.SpanCollector)), wire.Bind(new(tracer.SpanSource), new(*tracer.SpanCollector)), dirs.UseWindmillDir, token.GetOrCreateToken, engine.NewKINDLoader, wire.Value(feature.MainDefaults),
+	provideWebListener,
+	provideWebHost,
+	provideWebBasePath,
+	provideWebCORSOrigins, server.ProvideHeadsUpServer, provideAssetServer, server.ProvideHeadsUpServerController, listeners.NewRegistry, tracer.NewSpanCollector, wire.Bind(new(trace.SpanProcessor), new(*tracer.SpanCollector)), wire.Bind(new(tracer.SpanSource), new(*tracer.SpanCollector)), dirs.UseWindmillDir, token.GetOrCreateToken, engine.NewKINDLoader, engine.NewCRIOLoader, wire.Value(feature.MainDefaults),
 )
 
 type CmdUpDeps struct {
@@ -743,6 +801,9 @@ type CmdUpDeps struct {
 	CloudAddress cloudurl.Address
 	Store        *store.Store
 	Prompt       *prompt.TerminalPrompt
+
+	// Nil unless the kubeconfig context authenticates with an exec credential plugin.
+	ExecCredentials *k8s.ExecCredentials
 }
 
 type CmdCIDeps struct {
@@ -751,22 +812,26 @@ type CmdCIDeps struct {
 	Token        token.Token
 	CloudAddress cloudurl.Address
 	Store        *store.Store
+	K8sClient    k8s.Client
 }
 
 type DownDeps struct {
-	tfl      tiltfile.TiltfileLoader
-	dcClient dockercompose.DockerComposeClient
-	kClient  k8s.Client
+	tfl        tiltfile.TiltfileLoader
+	dcClient   dockercompose.DockerComposeClient
+	kClient    k8s.Client
+	kNamespace k8s.Namespace
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
-	kClient k8s.Client) DownDeps {
+	kClient k8s.Client,
+	kNamespace k8s.Namespace) DownDeps {
 	return DownDeps{
-		tfl:      tfl,
-		dcClient: dcClient,
-		kClient:  kClient,
+		tfl:        tfl,
+		dcClient:   dcClient,
+		kClient:    kClient,
+		kNamespace: kNamespace,
 	}
 }
 
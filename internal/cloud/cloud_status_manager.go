@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,6 +40,16 @@ type statusRequestKey struct {
 	tiltToken token.Token
 	teamID    string
 	version   model.TiltBuild
+
+	// Comma-separated, so that the key stays comparable.
+	additionalTeamIDsCSV string
+}
+
+func (k statusRequestKey) additionalTeamIDs() []string {
+	if k.additionalTeamIDsCSV == "" {
+		return nil
+	}
+	return strings.Split(k.additionalTeamIDsCSV, ",")
 }
 
 type CloudStatusManager struct {
@@ -52,7 +65,10 @@ type CloudStatusManager struct {
 	lastSuccessfulLookup time.Time
 }
 
-func ProvideHttpClient() HttpClient {
+func ProvideHttpClient(offline model.OfflineMode) HttpClient {
+	if offline {
+		return offlineHttpClient{}
+	}
 	return http.DefaultClient
 }
 
@@ -77,17 +93,10 @@ type whoAmIRequest struct {
 	TiltVersion string `json:"tilt_version"`
 }
 
-func (c *CloudStatusManager) CheckStatus(ctx context.Context, st store.RStore, cloudAddress string, requestKey statusRequestKey, blocking bool) {
-	c.mu.Lock()
-	c.currentlyMakingRequest = true
-	c.mu.Unlock()
-
-	defer func() {
-		c.mu.Lock()
-		c.currentlyMakingRequest = false
-		c.mu.Unlock()
-	}()
+// Tilt Cloud responds with this status when the token's registration has expired.
+var errTokenExpired = errors.New("tilt cloud token expired")
 
+func (c *CloudStatusManager) whoAmI(cloudAddress string, requestKey statusRequestKey, teamID string, blocking bool) (whoAmIResponse, error) {
 	u := cloudurl.URL(cloudAddress)
 	u.Path = "/api/whoami"
 
@@ -100,70 +109,117 @@ func (c *CloudStatusManager) CheckStatus(ctx context.Context, st store.RStore, c
 	body := &bytes.Buffer{}
 	err := json.NewEncoder(body).Encode(whoAmIRequest{TiltVersion: requestKey.version.Version})
 	if err != nil {
-		logger.Get(ctx).Debugf("error serializing whoami request: %v\n", err)
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error serializing whoami request: %v", err)
 	}
 
 	req, err := http.NewRequest("POST", u.String(), body)
 	if err != nil {
-		logger.Get(ctx).Debugf("error making whoami request: %v", err)
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error making whoami request: %v", err)
 	}
 	req.Header.Set(TiltTokenHeaderName, string(requestKey.tiltToken))
-	if requestKey.teamID != "" {
-		req.Header.Set(TiltTeamIDNameHeaderName, requestKey.teamID)
+	if teamID != "" {
+		req.Header.Set(TiltTeamIDNameHeaderName, teamID)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	resp, err := c.client.Do(req)
 	if err != nil {
-		logger.Get(ctx).Debugf("error checking tilt cloud status: %v", err)
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error checking tilt cloud status: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return whoAmIResponse{}, errTokenExpired
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			logger.Get(ctx).Debugf("tilt cloud status request failed with status %d. error reading response body: %v", resp.StatusCode, err)
-			c.error()
-			return
+			return whoAmIResponse{}, fmt.Errorf("tilt cloud status request failed with status %d. error reading response body: %v", resp.StatusCode, err)
 		}
-		logger.Get(ctx).Debugf("error checking tilt cloud status: code: %d, message: %s", resp.StatusCode, string(body))
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error checking tilt cloud status: code: %d, message: %s", resp.StatusCode, string(body))
 	}
 
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		logger.Get(ctx).Debugf("error reading response body: %v", err)
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error reading response body: %v", err)
 	}
 	r := whoAmIResponse{}
 	err = json.NewDecoder(bytes.NewReader(responseBody)).Decode(&r)
 	if err != nil {
-		logger.Get(ctx).Debugf("error decoding tilt whoami response '%s': %v", string(responseBody), err)
-		c.error()
-		return
+		return whoAmIResponse{}, fmt.Errorf("error decoding tilt whoami response '%s': %v", string(responseBody), err)
 	}
+	return r, nil
+}
 
+func (c *CloudStatusManager) CheckStatus(ctx context.Context, st store.RStore, cloudAddress string, requestKey statusRequestKey, blocking bool) {
 	c.mu.Lock()
-	c.lastRequestKey = requestKey
-	c.lastSuccessfulLookup = c.clock.Now()
-	c.lastErrorTime = time.Time{}
+	c.currentlyMakingRequest = true
 	c.mu.Unlock()
 
+	defer func() {
+		c.mu.Lock()
+		c.currentlyMakingRequest = false
+		c.mu.Unlock()
+	}()
+
+	r, err := c.whoAmI(cloudAddress, requestKey, requestKey.teamID, blocking)
+	if err == errTokenExpired {
+		c.succeeded(requestKey)
+		st.Dispatch(store.TiltCloudStatusReceivedAction{
+			TokenExpired:             true,
+			IsPostRegistrationLookup: blocking,
+		})
+		return
+	} else if err != nil {
+		logger.Get(ctx).Debugf("%v", err)
+		c.error()
+		return
+	}
+
+	var teams []store.CloudTeamStatus
+	if requestKey.teamID != "" {
+		teams = append(teams, store.CloudTeamStatus{
+			ID:         requestKey.teamID,
+			Name:       r.TeamName,
+			Associated: r.Found && r.TeamName != "",
+		})
+	}
+
+	// The whoami endpoint only knows about one team at a time,
+	// so look up each of the other teams separately.
+	for _, teamID := range requestKey.additionalTeamIDs() {
+		teamResp, err := c.whoAmI(cloudAddress, requestKey, teamID, false)
+		if err != nil {
+			logger.Get(ctx).Debugf("looking up team %s: %v", teamID, err)
+			c.error()
+			return
+		}
+		teams = append(teams, store.CloudTeamStatus{
+			ID:         teamID,
+			Name:       teamResp.TeamName,
+			Associated: teamResp.Found && teamResp.TeamName != "",
+		})
+	}
+
+	c.succeeded(requestKey)
+
 	st.Dispatch(store.TiltCloudStatusReceivedAction{
 		Found:                    r.Found,
 		Username:                 r.Username,
 		TeamName:                 r.TeamName,
+		Teams:                    teams,
 		IsPostRegistrationLookup: blocking,
 		SuggestedTiltVersion:     r.SuggestedTiltVersion,
 	})
 }
 
+func (c *CloudStatusManager) succeeded(requestKey statusRequestKey) {
+	c.mu.Lock()
+	c.lastRequestKey = requestKey
+	c.lastSuccessfulLookup = c.clock.Now()
+	c.lastErrorTime = time.Time{}
+	c.mu.Unlock()
+}
+
 func (c *CloudStatusManager) needsLookup(requestKey statusRequestKey) bool {
 	return c.lastSuccessfulLookup.IsZero() ||
 		c.lastSuccessfulLookup.Add(refreshPeriod).Before(c.clock.Now()) ||
@@ -177,7 +233,12 @@ func (c *CloudStatusManager) OnChange(ctx context.Context, st store.RStore) {
 	c.mu.Lock()
 	lastErrorTime := c.lastErrorTime
 	currentlyMakingRequest := c.currentlyMakingRequest
-	requestKey := statusRequestKey{teamID: state.TeamID, tiltToken: state.Token, version: state.TiltBuildInfo}
+	requestKey := statusRequestKey{
+		teamID:               state.TeamID,
+		tiltToken:            state.Token,
+		version:              state.TiltBuildInfo,
+		additionalTeamIDsCSV: strings.Join(state.AdditionalTeamIDs, ","),
+	}
 	needsLookup := c.needsLookup(requestKey)
 	c.mu.Unlock()
 
@@ -85,6 +85,9 @@ func TestWhoAmI(t *testing.T) {
 
 			if tc.teamID != "" {
 				expectedAction.TeamName = "test team name"
+				expectedAction.Teams = []store.CloudTeamStatus{
+					{ID: "test team id", Name: "test team name", Associated: true},
+				}
 			}
 
 			a := store.WaitForAction(t, reflect.TypeOf(store.TiltCloudStatusReceivedAction{}), f.st.Actions).(store.TiltCloudStatusReceivedAction)
@@ -122,7 +125,12 @@ func TestStatusRefresh(t *testing.T) {
 	req = f.waitForRequest(fmt.Sprintf("https://%s/api/whoami", testCloudAddress))
 	require.Equal(t, "test token", req.Header.Get(TiltTokenHeaderName))
 
-	expected = store.TiltCloudStatusReceivedAction{Username: "user2", Found: true, IsPostRegistrationLookup: false}
+	expected = store.TiltCloudStatusReceivedAction{
+		Username:                 "user2",
+		Found:                    true,
+		Teams:                    []store.CloudTeamStatus{{ID: "test team id"}},
+		IsPostRegistrationLookup: false,
+	}
 	a = store.WaitForAction(t, reflect.TypeOf(store.TiltCloudStatusReceivedAction{}), f.st.Actions)
 	require.Equal(t, expected, a)
 
@@ -139,6 +147,41 @@ func TestStatusRefresh(t *testing.T) {
 	require.Equal(t, expected, a)
 }
 
+func TestWhoAmIAdditionalTeams(t *testing.T) {
+	f := newCloudStatusManagerTestFixture(t)
+
+	f.httpClient.SetResponse(`{"Username": "user1", "Found": true, "TeamName": "Sharks"}`)
+	f.Run(func(state *store.EngineState) {
+		state.Token = "test token"
+		state.TeamID = "sharks-id"
+		state.AdditionalTeamIDs = []string{"jets-id"}
+	})
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.TiltCloudStatusReceivedAction{}), f.st.Actions).(store.TiltCloudStatusReceivedAction)
+	require.Equal(t, []store.CloudTeamStatus{
+		{ID: "sharks-id", Name: "Sharks", Associated: true},
+		{ID: "jets-id", Name: "Sharks", Associated: true},
+	}, a.Teams)
+
+	reqs := f.httpClient.Requests()
+	require.Len(t, reqs, 2)
+	require.Equal(t, "sharks-id", reqs[0].Header.Get(TiltTeamIDNameHeaderName))
+	require.Equal(t, "jets-id", reqs[1].Header.Get(TiltTeamIDNameHeaderName))
+}
+
+func TestWhoAmITokenExpired(t *testing.T) {
+	f := newCloudStatusManagerTestFixture(t)
+
+	f.httpClient.SetResponseWithCode(http.StatusUnauthorized, "token expired")
+	f.Run(func(state *store.EngineState) {
+		state.Token = "test token"
+	})
+
+	f.waitForRequest(fmt.Sprintf("https://%s/api/whoami", testCloudAddress))
+	a := store.WaitForAction(t, reflect.TypeOf(store.TiltCloudStatusReceivedAction{}), f.st.Actions)
+	require.Equal(t, store.TiltCloudStatusReceivedAction{TokenExpired: true}, a)
+}
+
 type cloudStatusManagerTestFixture struct {
 	um         *CloudStatusManager
 	httpClient *httptest.FakeClient
@@ -1,9 +1,11 @@
 package cloud
 
 import (
+	"compress/gzip"
 	"context"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 
@@ -17,10 +19,19 @@ func WriteSnapshot(ctx context.Context, store *store.Store, path string) {
 		logger.Get(ctx).Errorf("Writing snapshot to file: %v", err)
 	}
 
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(f)
+		defer func() {
+			_ = gz.Close()
+		}()
+		w = gz
+	}
+
 	state := store.RLockState()
 	defer store.RUnlockState()
 
-	err = WriteSnapshotTo(ctx, state, f)
+	err = WriteSnapshotTo(ctx, state, w)
 	if err != nil {
 		logger.Get(ctx).Errorf("Writing snapshot to file: %v", err)
 	}
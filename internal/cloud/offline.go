@@ -0,0 +1,16 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+)
+
+var ErrOffline = fmt.Errorf("Tilt is running in offline mode")
+
+// An HttpClient that refuses every request, so that nothing
+// talks to Tilt Cloud when the user asked us to stay offline.
+type offlineHttpClient struct{}
+
+func (offlineHttpClient) Do(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, ErrOffline)
+}
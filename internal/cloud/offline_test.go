@@ -0,0 +1,26 @@
+package cloud
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestOfflineHttpClient(t *testing.T) {
+	client := ProvideHttpClient(model.OfflineMode(true))
+
+	req, err := http.NewRequest("POST", "https://cloud.tilt.dev/api/whoami", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, ErrOffline))
+}
+
+func TestOnlineHttpClient(t *testing.T) {
+	assert.Equal(t, http.DefaultClient, ProvideHttpClient(model.OfflineMode(false)))
+}
@@ -2,6 +2,7 @@ package cloud
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -76,17 +77,13 @@ func (s snapshotUploader) Upload(token token.Token, teamID string, snapshot *pro
 	if err != nil {
 		return "", errors.Wrap(err, "encoding snapshot")
 	}
-	request, err := http.NewRequest(http.MethodPost, s.newSnapshotURL(), b)
-	if err != nil {
-		return "", errors.Wrap(err, "Upload NewRequest")
-	}
 
-	request.Header.Set(TiltTokenHeaderName, token.String())
-	if teamID != "" {
-		request.Header.Set(TiltTeamIDNameHeaderName, teamID)
+	response, err := s.post(token, teamID, b.Bytes(), true)
+	if err == nil && response.StatusCode == http.StatusUnsupportedMediaType {
+		// Older servers don't accept compressed snapshots.
+		_ = response.Body.Close()
+		response, err = s.post(token, teamID, b.Bytes(), false)
 	}
-
-	response, err := s.client.Do(request)
 	if err != nil {
 		return "", errors.Wrap(err, "Upload")
 	}
@@ -112,3 +109,33 @@ func (s snapshotUploader) Upload(token token.Token, teamID string, snapshot *pro
 
 	return SnapshotID(resp.ID), nil
 }
+
+func (s snapshotUploader) post(token token.Token, teamID string, body []byte, compress bool) (*http.Response, error) {
+	if compress {
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		_, err := gz.Write(body)
+		if err == nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "compressing snapshot")
+		}
+		body = buf.Bytes()
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.newSnapshotURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "Upload NewRequest")
+	}
+
+	request.Header.Set(TiltTokenHeaderName, token.String())
+	if teamID != "" {
+		request.Header.Set(TiltTeamIDNameHeaderName, teamID)
+	}
+	if compress {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+
+	return s.client.Do(request)
+}
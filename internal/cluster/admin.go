@@ -0,0 +1,220 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Creates and deletes local clusters, by running the CLI of the
+// cluster's product (kind, k3d, or minikube).
+//
+// Creating a cluster that already exists is a no-op, so it's
+// safe to run on every fresh checkout.
+type Admin struct {
+	runner runner
+}
+
+func NewAdmin() Admin {
+	return Admin{runner: execRunner{}}
+}
+
+func (a Admin) Create(ctx context.Context, spec model.ClusterSpec) error {
+	switch spec.Product {
+	case model.ClusterProductKIND:
+		return a.createKIND(ctx, spec)
+	case model.ClusterProductK3D:
+		return a.createK3D(ctx, spec)
+	case model.ClusterProductMinikube:
+		return a.createMinikube(ctx, spec)
+	}
+	return fmt.Errorf("unknown cluster product %q", spec.Product)
+}
+
+func (a Admin) Delete(ctx context.Context, spec model.ClusterSpec) error {
+	switch spec.Product {
+	case model.ClusterProductKIND:
+		return a.deleteKIND(ctx, spec)
+	case model.ClusterProductK3D:
+		return a.runner.run(ctx, newCommand("k3d", "cluster", "delete", spec.Name))
+	case model.ClusterProductMinikube:
+		return a.runner.run(ctx, newCommand("minikube", "delete", "-p", spec.Name))
+	}
+	return fmt.Errorf("unknown cluster product %q", spec.Product)
+}
+
+// The registry runs on port 5000 inside its container. Other containers
+// on the kind network reach it by the container name.
+func kindRegistryName(spec model.ClusterSpec) string {
+	return fmt.Sprintf("%s-registry", spec.Name)
+}
+
+func (a Admin) createKIND(ctx context.Context, spec model.ClusterSpec) error {
+	out, err := a.runner.output(ctx, newCommand("kind", "get", "clusters"))
+	if err != nil {
+		return err
+	}
+	exists := false
+	for _, name := range strings.Fields(out) {
+		if name == spec.Name {
+			exists = true
+		}
+	}
+
+	if spec.Registry {
+		err := a.ensureKINDRegistry(ctx, spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	if exists {
+		logger.Get(ctx).Infof("kind cluster %q already exists", spec.Name)
+	} else {
+		args := []string{"create", "cluster", "--name", spec.Name, "--config", "-"}
+		if spec.KubernetesVersion != "" {
+			args = append(args, "--image", fmt.Sprintf("kindest/node:%s", spec.KubernetesVersion))
+		}
+		err := a.runner.run(ctx, newCommand("kind", args...).withStdin(kindConfig(spec)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if spec.Registry {
+		return a.connectKINDRegistry(ctx, spec)
+	}
+	return nil
+}
+
+func (a Admin) ensureKINDRegistry(ctx context.Context, spec model.ClusterSpec) error {
+	name := kindRegistryName(spec)
+	_, err := a.runner.output(ctx, newCommand("docker", "inspect", name))
+	if err == nil {
+		logger.Get(ctx).Infof("Registry %q already exists", name)
+		return nil
+	}
+
+	return a.runner.run(ctx, newCommand("docker", "run", "-d", "--restart=always",
+		"-p", fmt.Sprintf("127.0.0.1:%d:5000", spec.RegistryPort), "--name", name, "registry:2"))
+}
+
+// Puts the registry on the kind network, and tells Tilt (and other tools)
+// where to push images, with the ConfigMap from KEP-1755.
+func (a Admin) connectKINDRegistry(ctx context.Context, spec model.ClusterSpec) error {
+	name := kindRegistryName(spec)
+	out, err := a.runner.output(ctx, newCommand("docker", "inspect", "-f", "{{json .NetworkSettings.Networks.kind}}", name))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "null" {
+		err := a.runner.run(ctx, newCommand("docker", "network", "connect", "kind", name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.runner.run(ctx, newCommand("kubectl", "--context", spec.KubeContext(), "apply", "-f", "-").
+		withStdin(kindRegistryConfigMap(spec)))
+}
+
+func (a Admin) deleteKIND(ctx context.Context, spec model.ClusterSpec) error {
+	err := a.runner.run(ctx, newCommand("kind", "delete", "cluster", "--name", spec.Name))
+	if err != nil {
+		return err
+	}
+
+	if spec.Registry {
+		name := kindRegistryName(spec)
+		_, err := a.runner.output(ctx, newCommand("docker", "inspect", name))
+		if err != nil {
+			return nil
+		}
+		return a.runner.run(ctx, newCommand("docker", "rm", "-f", name))
+	}
+	return nil
+}
+
+func kindConfig(spec model.ClusterSpec) string {
+	sb := &strings.Builder{}
+	sb.WriteString("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n")
+	for i := 1; i < spec.Nodes; i++ {
+		sb.WriteString("- role: worker\n")
+	}
+	if spec.Registry {
+		fmt.Fprintf(sb, `containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."localhost:%d"]
+    endpoint = ["http://%s:5000"]
+`, spec.RegistryPort, kindRegistryName(spec))
+	}
+	return sb.String()
+}
+
+func kindRegistryConfigMap(spec model.ClusterSpec) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local-registry-hosting
+  namespace: kube-public
+data:
+  localRegistryHosting.v1: |
+    host: "localhost:%d"
+    help: "https://kind.sigs.k8s.io/docs/user/local-registry/"
+`, spec.RegistryPort)
+}
+
+func (a Admin) createK3D(ctx context.Context, spec model.ClusterSpec) error {
+	out, err := a.runner.output(ctx, newCommand("k3d", "cluster", "list", "-o", "json"))
+	if err != nil {
+		return err
+	}
+	var clusters []struct {
+		Name string `json:"name"`
+	}
+	err = json.Unmarshal([]byte(out), &clusters)
+	if err != nil {
+		return fmt.Errorf("reading k3d clusters: %v", err)
+	}
+	for _, c := range clusters {
+		if c.Name == spec.Name {
+			logger.Get(ctx).Infof("k3d cluster %q already exists", spec.Name)
+			return nil
+		}
+	}
+
+	// k3d counts the server separately from the agents.
+	args := []string{"cluster", "create", spec.Name, "--agents", fmt.Sprintf("%d", spec.Nodes-1)}
+	if spec.KubernetesVersion != "" {
+		version := spec.KubernetesVersion
+		if !strings.Contains(version, "-k3s") {
+			version += "-k3s1"
+		}
+		args = append(args, "--image", fmt.Sprintf("rancher/k3s:%s", version))
+	}
+	if spec.Registry {
+		// k3d publishes the registry ConfigMap itself.
+		args = append(args, "--registry-create",
+			fmt.Sprintf("%s-registry:127.0.0.1:%d", spec.Name, spec.RegistryPort))
+	}
+	return a.runner.run(ctx, newCommand("k3d", args...))
+}
+
+// minikube start is a no-op if the cluster is already running.
+func (a Admin) createMinikube(ctx context.Context, spec model.ClusterSpec) error {
+	args := []string{"start", "-p", spec.Name, "--nodes", fmt.Sprintf("%d", spec.Nodes)}
+	if spec.KubernetesVersion != "" {
+		args = append(args, "--kubernetes-version", spec.KubernetesVersion)
+	}
+	if spec.Registry {
+		// Tilt builds images directly in minikube's Docker, so the
+		// registry addon only matters to other tools. It always
+		// listens on port 5000 inside the cluster.
+		args = append(args, "--addons", "registry")
+	}
+	return a.runner.run(ctx, newCommand("minikube", args...))
+}
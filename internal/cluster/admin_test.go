@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCreateKIND(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["kind get clusters"] = "other\n"
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{
+		Product:           model.ClusterProductKIND,
+		Name:              "tilt",
+		Nodes:             2,
+		KubernetesVersion: "v1.21.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"kind create cluster --name tilt --config - --image kindest/node:v1.21.1",
+	}, f.runner.runs)
+	assert.Equal(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+`, f.runner.stdins[0])
+}
+
+func TestCreateKINDWithRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["kind get clusters"] = ""
+	f.runner.outputs["docker inspect -f {{json .NetworkSettings.Networks.kind}} tilt-registry"] = "null\n"
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{
+		Product:      model.ClusterProductKIND,
+		Name:         "tilt",
+		Nodes:        1,
+		Registry:     true,
+		RegistryPort: 5001,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker run -d --restart=always -p 127.0.0.1:5001:5000 --name tilt-registry registry:2",
+		"kind create cluster --name tilt --config -",
+		"docker network connect kind tilt-registry",
+		"kubectl --context kind-tilt apply -f -",
+	}, f.runner.runs)
+	assert.Contains(t, f.runner.stdins[1], `registry.mirrors."localhost:5001"]
+    endpoint = ["http://tilt-registry:5000"]`)
+	assert.Contains(t, f.runner.stdins[3], `host: "localhost:5001"`)
+}
+
+func TestCreateKINDAlreadyExists(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["kind get clusters"] = "tilt\n"
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{Product: model.ClusterProductKIND, Name: "tilt", Nodes: 1})
+	require.NoError(t, err)
+	assert.Empty(t, f.runner.runs)
+	assert.Contains(t, f.out.String(), `kind cluster "tilt" already exists`)
+}
+
+func TestDeleteKINDWithRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["docker inspect tilt-registry"] = "[]"
+
+	err := f.admin.Delete(f.ctx, model.ClusterSpec{Product: model.ClusterProductKIND, Name: "tilt", Registry: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"kind delete cluster --name tilt",
+		"docker rm -f tilt-registry",
+	}, f.runner.runs)
+}
+
+func TestCreateK3D(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["k3d cluster list -o json"] = `[{"name": "other"}]`
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{
+		Product:           model.ClusterProductK3D,
+		Name:              "dev",
+		Nodes:             3,
+		Registry:          true,
+		RegistryPort:      5000,
+		KubernetesVersion: "v1.21.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"k3d cluster create dev --agents 2 --image rancher/k3s:v1.21.1-k3s1 --registry-create dev-registry:127.0.0.1:5000",
+	}, f.runner.runs)
+}
+
+func TestCreateK3DAlreadyExists(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["k3d cluster list -o json"] = `[{"name": "dev"}]`
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{Product: model.ClusterProductK3D, Name: "dev", Nodes: 1})
+	require.NoError(t, err)
+	assert.Empty(t, f.runner.runs)
+}
+
+func TestCreateMinikube(t *testing.T) {
+	f := newFixture(t)
+
+	err := f.admin.Create(f.ctx, model.ClusterSpec{
+		Product:           model.ClusterProductMinikube,
+		Name:              "tilt",
+		Nodes:             1,
+		Registry:          true,
+		KubernetesVersion: "v1.21.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"minikube start -p tilt --nodes 1 --kubernetes-version v1.21.1 --addons registry",
+	}, f.runner.runs)
+}
+
+func TestDeleteMinikube(t *testing.T) {
+	f := newFixture(t)
+
+	err := f.admin.Delete(f.ctx, model.ClusterSpec{Product: model.ClusterProductMinikube, Name: "tilt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"minikube delete -p tilt"}, f.runner.runs)
+}
+
+type fakeRunner struct {
+	runs   []string
+	stdins []string
+
+	// Commands not in this map fail.
+	outputs map[string]string
+}
+
+func (r *fakeRunner) run(ctx context.Context, c command) error {
+	r.runs = append(r.runs, c.String())
+	r.stdins = append(r.stdins, c.stdin)
+	return nil
+}
+
+func (r *fakeRunner) output(ctx context.Context, c command) (string, error) {
+	out, ok := r.outputs[c.String()]
+	if !ok {
+		return "", fmt.Errorf("%s: exit status 1", c)
+	}
+	return out, nil
+}
+
+type fixture struct {
+	ctx    context.Context
+	out    *bytes.Buffer
+	runner *fakeRunner
+	admin  Admin
+}
+
+func newFixture(t *testing.T) *fixture {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	r := &fakeRunner{outputs: make(map[string]string)}
+	return &fixture{
+		ctx:    ctx,
+		out:    out,
+		runner: r,
+		admin:  Admin{runner: r},
+	}
+}
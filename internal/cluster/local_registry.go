@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Runs the registry container from local_registry(), and points the cluster
+// at it, so that pushes go over the loopback interface instead of the internet.
+//
+// Returns the registry that Tilt should push to. If we don't know how to
+// make this cluster pull from the registry, returns an empty registry,
+// and Tilt pushes wherever it would have otherwise.
+func (a Admin) EnsureLocalRegistry(ctx context.Context, spec model.LocalRegistrySpec, env k8s.Env, kubeContext k8s.KubeContext) (container.Registry, error) {
+	l := logger.Get(ctx)
+	switch {
+	case env.UsesLocalDockerRegistry():
+		l.Infof("local_registry: skipping, because Tilt builds images straight into %s's container runtime", env)
+		return container.Registry{}, nil
+
+	case env == k8s.EnvKIND6:
+		err := a.ensureLocalRegistryContainer(ctx, spec)
+		if err != nil {
+			return container.Registry{}, err
+		}
+		err = a.mirrorLocalRegistryToKIND(ctx, spec, strings.TrimPrefix(string(kubeContext), "kind-"))
+		if err != nil {
+			return container.Registry{}, err
+		}
+		return container.NewRegistry(spec.Host())
+
+	case env == k8s.EnvK3D:
+		// k3s only reads its registry config at startup.
+		l.Warnf("local_registry: can't point an existing k3d cluster at a new registry. "+
+			"To create one that pulls from %s, use cluster(product='k3d', registry=True) and `tilt cluster create`", spec.Host())
+		return container.Registry{}, nil
+	}
+
+	l.Warnf("local_registry: don't know how to make cluster %q (%s) pull from a local registry", kubeContext, env)
+	return container.Registry{}, nil
+}
+
+func (a Admin) ensureLocalRegistryContainer(ctx context.Context, spec model.LocalRegistrySpec) error {
+	out, err := a.runner.output(ctx, newCommand("docker", "inspect", "-f", "{{.State.Running}}", spec.Name))
+	if err == nil {
+		if strings.TrimSpace(out) == "true" {
+			return nil
+		}
+		return a.runner.run(ctx, newCommand("docker", "start", spec.Name))
+	}
+
+	return a.runner.run(ctx, newCommand("docker", "run", "-d", "--restart=always",
+		"-p", fmt.Sprintf("127.0.0.1:%d:5000", spec.Port), "--name", spec.Name, "registry:2"))
+}
+
+// Puts the registry on the kind network, and tells containerd on each node
+// to pull images for localhost:PORT from it.
+//
+// kind only supports a registry mirror config directory on new clusters,
+// so this needs kind v0.20+ (or a cluster created with config_path set).
+func (a Admin) mirrorLocalRegistryToKIND(ctx context.Context, spec model.LocalRegistrySpec, clusterName string) error {
+	out, err := a.runner.output(ctx, newCommand("docker", "inspect", "-f", "{{json .NetworkSettings.Networks.kind}}", spec.Name))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "null" {
+		err := a.runner.run(ctx, newCommand("docker", "network", "connect", "kind", spec.Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	out, err = a.runner.output(ctx, newCommand("kind", "get", "nodes", "--name", clusterName))
+	if err != nil {
+		return err
+	}
+
+	dir := fmt.Sprintf("/etc/containerd/certs.d/%s", spec.Host())
+	hostsToml := fmt.Sprintf("[host.\"http://%s:5000\"]\n", spec.Name)
+	for _, node := range strings.Fields(out) {
+		err := a.runner.run(ctx, newCommand("docker", "exec", "-i", node, "sh", "-c",
+			fmt.Sprintf("mkdir -p %s && cat > %s/hosts.toml", dir, dir)).withStdin(hostsToml))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+var testLocalRegistry = model.LocalRegistrySpec{Name: "tilt-registry", Port: 5001}
+
+func TestEnsureLocalRegistryKIND(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["docker inspect -f {{json .NetworkSettings.Networks.kind}} tilt-registry"] = "null\n"
+	f.runner.outputs["kind get nodes --name dev"] = "dev-control-plane\ndev-worker\n"
+
+	reg, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvKIND6, "kind-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:5001", reg.Host)
+	assert.Equal(t, []string{
+		"docker run -d --restart=always -p 127.0.0.1:5001:5000 --name tilt-registry registry:2",
+		"docker network connect kind tilt-registry",
+		"docker exec -i dev-control-plane sh -c mkdir -p /etc/containerd/certs.d/localhost:5001 && cat > /etc/containerd/certs.d/localhost:5001/hosts.toml",
+		"docker exec -i dev-worker sh -c mkdir -p /etc/containerd/certs.d/localhost:5001 && cat > /etc/containerd/certs.d/localhost:5001/hosts.toml",
+	}, f.runner.runs)
+	assert.Equal(t, "[host.\"http://tilt-registry:5000\"]\n", f.runner.stdins[2])
+}
+
+func TestEnsureLocalRegistryKINDAlreadyRunning(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["docker inspect -f {{.State.Running}} tilt-registry"] = "true\n"
+	f.runner.outputs["docker inspect -f {{json .NetworkSettings.Networks.kind}} tilt-registry"] = "{}\n"
+	f.runner.outputs["kind get nodes --name dev"] = ""
+
+	reg, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvKIND6, "kind-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:5001", reg.Host)
+	assert.Empty(t, f.runner.runs)
+}
+
+func TestEnsureLocalRegistryStopped(t *testing.T) {
+	f := newFixture(t)
+	f.runner.outputs["docker inspect -f {{.State.Running}} tilt-registry"] = "false\n"
+	f.runner.outputs["docker inspect -f {{json .NetworkSettings.Networks.kind}} tilt-registry"] = "{}\n"
+	f.runner.outputs["kind get nodes --name dev"] = ""
+
+	_, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvKIND6, "kind-dev")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker start tilt-registry"}, f.runner.runs)
+}
+
+func TestEnsureLocalRegistryMinikube(t *testing.T) {
+	f := newFixture(t)
+
+	reg, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvMinikube, "minikube")
+	require.NoError(t, err)
+	assert.True(t, reg.Empty())
+	assert.Empty(t, f.runner.runs)
+	assert.Contains(t, f.out.String(), "Tilt builds images straight into minikube's container runtime")
+}
+
+func TestEnsureLocalRegistryK3D(t *testing.T) {
+	f := newFixture(t)
+
+	reg, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvK3D, "k3d-dev")
+	require.NoError(t, err)
+	assert.True(t, reg.Empty())
+	assert.Empty(t, f.runner.runs)
+	assert.Contains(t, f.out.String(), "cluster(product='k3d', registry=True)")
+}
+
+func TestEnsureLocalRegistryRemoteCluster(t *testing.T) {
+	f := newFixture(t)
+
+	reg, err := f.admin.EnsureLocalRegistry(f.ctx, testLocalRegistry, k8s.EnvGKE, "gke_proj_us-central1_dev")
+	require.NoError(t, err)
+	assert.True(t, reg.Empty())
+	assert.Contains(t, f.out.String(), `don't know how to make cluster "gke_proj_us-central1_dev" (gke) pull from a local registry`)
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+var installURLs = map[string]string{
+	"kind":     "https://kind.sigs.k8s.io/docs/user/quick-start/#installation",
+	"k3d":      "https://k3d.io/#installation",
+	"minikube": "https://minikube.sigs.k8s.io/docs/start/",
+	"docker":   "https://docs.docker.com/get-docker/",
+	"kubectl":  "https://kubernetes.io/docs/tasks/tools/",
+}
+
+type command struct {
+	name  string
+	args  []string
+	stdin string
+}
+
+func newCommand(name string, args ...string) command {
+	return command{name: name, args: args}
+}
+
+func (c command) withStdin(stdin string) command {
+	c.stdin = stdin
+	return c
+}
+
+func (c command) String() string {
+	return strings.Join(append([]string{c.name}, c.args...), " ")
+}
+
+type runner interface {
+	// Runs the command, and sends its output to the logger.
+	run(ctx context.Context, c command) error
+
+	// Runs the command, and returns its stdout.
+	output(ctx context.Context, c command) (string, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) run(ctx context.Context, c command) error {
+	l := logger.Get(ctx)
+	l.Infof("Running: %s", c)
+
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Stdout = l.Writer(logger.InfoLvl)
+	cmd.Stderr = l.Writer(logger.InfoLvl)
+	if c.stdin != "" {
+		cmd.Stdin = strings.NewReader(c.stdin)
+	}
+	return wrapExecError(c, cmd.Run())
+}
+
+func (execRunner) output(ctx context.Context, c command) (string, error) {
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	if c.stdin != "" {
+		cmd.Stdin = strings.NewReader(c.stdin)
+	}
+	err := cmd.Run()
+	return stdout.String(), wrapExecError(c, err)
+}
+
+func wrapExecError(c command, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		url, ok := installURLs[c.name]
+		if ok {
+			return fmt.Errorf("%s not found on PATH. To install it, see: %s", c.name, url)
+		}
+		return fmt.Errorf("%s not found on PATH", c.name)
+	}
+	return fmt.Errorf("%s: %v", c, err)
+}
+
+var _ runner = execRunner{}
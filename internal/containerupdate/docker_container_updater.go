@@ -68,6 +68,16 @@ func (cu *DockerUpdater) UpdateContainer(ctx context.Context, cInfo store.Contai
 	return nil
 }
 
+// RestartContainer restarts a container without updating it, e.g., because
+// another container that it depends on was updated.
+func (cu *DockerUpdater) RestartContainer(ctx context.Context, cID container.ID) error {
+	err := cu.dCli.ContainerRestartNoWait(ctx, cID.String())
+	if err != nil {
+		return errors.Wrap(err, "ContainerRestart")
+	}
+	return nil
+}
+
 func (cu *DockerUpdater) rmPathsFromContainer(ctx context.Context, cID container.ID, paths []string) error {
 	if len(paths) == 0 {
 		return nil
@@ -0,0 +1,104 @@
+package containerupdate
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/exec"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The image for the ephemeral container that copies files into containers
+// that don't have tar. It only needs tar, rm, and something to keep it running.
+const EphemeralUpdaterImage = "busybox:1.32"
+
+// The ephemeral container shares the target container's process namespace,
+// where the target's entrypoint is PID 1, so this is the target's filesystem.
+const targetRootInEphemeralContainer = "/proc/1/root"
+
+// Distroless images don't have tar (or a shell), so we can't exec into them
+// to copy files. Instead, attach an ephemeral container with those tools,
+// and write to the target's filesystem through the shared process namespace.
+//
+// Commands still run in the target container, so they need to be binaries
+// that exist there.
+func (cu *ExecUpdater) updateViaEphemeralContainer(ctx context.Context, cInfo store.ContainerInfo,
+	archiveToCopy io.Reader, filesToDelete []string, cmds []model.Cmd) error {
+	l := logger.Get(ctx)
+	w := l.Writer(logger.InfoLvl)
+
+	ec := ephemeralUpdaterContainer(cInfo.ContainerName)
+	l.Debugf("Container %s has no tar; copying files through ephemeral container %s",
+		cInfo.ContainerName, ec.Name)
+
+	err := cu.kCli.EnsureEphemeralContainer(ctx, cInfo.PodID, cInfo.Namespace, ec)
+	if err != nil {
+		return errors.Wrapf(err, "container %s has no tar, so Live Update needs an ephemeral container", cInfo.ContainerName)
+	}
+
+	ecName := container.Name(ec.Name)
+	if len(filesToDelete) > 0 {
+		toDelete := make([]string, len(filesToDelete))
+		for i, f := range filesToDelete {
+			toDelete[i] = path.Join(targetRootInEphemeralContainer, f)
+		}
+
+		err := cu.kCli.Exec(ctx, cInfo.PodID, ecName, cInfo.Namespace,
+			append([]string{"rm", "-rf"}, toDelete...), nil, w, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cu.kCli.Exec(ctx, cInfo.PodID, ecName, cInfo.Namespace,
+		[]string{"tar", "-C", targetRootInEphemeralContainer, "-x", "-f", "-"}, archiveToCopy, w, w)
+	if err != nil {
+		return err
+	}
+
+	return cu.runCmds(ctx, cInfo, cmds)
+}
+
+func ephemeralUpdaterContainer(target container.Name) v1.EphemeralContainer {
+	return v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:    "tilt-live-update-" + target.String(),
+			Image:   EphemeralUpdaterImage,
+			Command: []string{"tail", "-f", "/dev/null"},
+			SecurityContext: &v1.SecurityContext{
+				// Needed to reach the target's filesystem through /proc
+				// when the target runs as a different user.
+				Capabilities: &v1.Capabilities{
+					Add: []v1.Capability{"SYS_PTRACE"},
+				},
+			},
+		},
+		TargetContainerName: target.String(),
+	}
+}
+
+// Whether the exec failed because the command doesn't exist in the container,
+// rather than because the command itself failed.
+func isExecutableNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if exitErr, ok := err.(exec.CodeExitError); ok {
+		// The shell conventions for "not executable" and "not found".
+		return exitErr.Code == 126 || exitErr.Code == 127
+	}
+
+	// The container runtime's error when it can't start the command, e.g.,
+	// OCI runtime exec failed: exec failed: ...: exec: "tar": executable file not found in $PATH
+	msg := err.Error()
+	return strings.Contains(msg, "executable file not found") ||
+		(strings.Contains(msg, "exec failed") && strings.Contains(msg, "no such file or directory"))
+}
@@ -1,14 +1,17 @@
 package containerupdate
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/opentracing/opentracing-go"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -17,6 +20,11 @@ import (
 
 type ExecUpdater struct {
 	kCli k8s.Client
+
+	// Containers that we've found don't have `tar` or `rm`, so need their
+	// files copied in through an ephemeral container.
+	mu             sync.Mutex
+	needsEphemeral map[container.ID]bool
 }
 
 var _ ContainerUpdater = &ExecUpdater{}
@@ -36,7 +44,10 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo store.Containe
 			"see https://github.com/tilt-dev/rerun-process-wrapper for a workaround")
 	}
 
-	l := logger.Get(ctx)
+	if cu.isMissingTools(cInfo.ContainerID) {
+		return cu.updateViaEphemeralContainer(ctx, cInfo, archiveToCopy, filesToDelete, cmds)
+	}
+
 	w := logger.Get(ctx).Writer(logger.InfoLvl)
 
 	// delete files (if any)
@@ -44,19 +55,36 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo store.Containe
 		err := cu.kCli.Exec(ctx,
 			cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
 			append([]string{"rm", "-rf"}, filesToDelete...), nil, w, w)
+		if isExecutableNotFound(err) {
+			cu.setMissingTools(cInfo.ContainerID)
+			return cu.updateViaEphemeralContainer(ctx, cInfo, archiveToCopy, filesToDelete, cmds)
+		}
 		if err != nil {
 			return err
 		}
 	}
 
 	// copy files to container
+	//
+	// Keep what we've sent, so that if the container doesn't have tar,
+	// we can send the whole archive again through an ephemeral container.
+	sent := bytes.NewBuffer(nil)
 	err := cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
-		[]string{"tar", "-C", "/", "-x", "-f", "-"}, archiveToCopy, w, w)
+		[]string{"tar", "-C", "/", "-x", "-f", "-"}, io.TeeReader(archiveToCopy, sent), w, w)
+	if isExecutableNotFound(err) {
+		cu.setMissingTools(cInfo.ContainerID)
+		return cu.updateViaEphemeralContainer(ctx, cInfo, io.MultiReader(sent, archiveToCopy), nil, cmds)
+	}
 	if err != nil {
 		return err
 	}
 
-	// run commands
+	return cu.runCmds(ctx, cInfo, cmds)
+}
+
+func (cu *ExecUpdater) runCmds(ctx context.Context, cInfo store.ContainerInfo, cmds []model.Cmd) error {
+	l := logger.Get(ctx)
+	w := l.Writer(logger.InfoLvl)
 	for i, c := range cmds {
 		l.Infof("[CMD %d/%d] %s", i+1, len(cmds), strings.Join(c.Argv, " "))
 		err := cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
@@ -69,3 +97,18 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo store.Containe
 
 	return nil
 }
+
+func (cu *ExecUpdater) isMissingTools(id container.ID) bool {
+	cu.mu.Lock()
+	defer cu.mu.Unlock()
+	return cu.needsEphemeral[id]
+}
+
+func (cu *ExecUpdater) setMissingTools(id container.ID) {
+	cu.mu.Lock()
+	defer cu.mu.Unlock()
+	if cu.needsEphemeral == nil {
+		cu.needsEphemeral = make(map[container.ID]bool)
+	}
+	cu.needsEphemeral[id] = true
+}
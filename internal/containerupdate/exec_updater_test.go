@@ -117,6 +117,75 @@ func TestUpdateContainerRunsFailure(t *testing.T) {
 	assert.Equal(t, 2, len(f.kCli.ExecCalls))
 }
 
+func TestUpdateContainerWithoutTarUsesEphemeralContainer(t *testing.T) {
+	f := newExecFixture(t)
+
+	f.kCli.ExecErrors = []error{fmt.Errorf(`OCI runtime exec failed: exec: "tar": executable file not found in $PATH`)}
+
+	err := f.ecu.UpdateContainer(f.ctx, TestContainerInfo, newReader("hello world"), nil, cmds, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, f.kCli.EphemeralContainerCalls, 1) {
+		ec := f.kCli.EphemeralContainerCalls[0].Container
+		assert.Equal(t, TestContainerInfo.ContainerName.String(), ec.TargetContainerName)
+		assert.Equal(t, EphemeralUpdaterImage, ec.Image)
+	}
+
+	if assert.Len(t, f.kCli.ExecCalls, 4) {
+		call := f.kCli.ExecCalls[1]
+		assert.Equal(t, "tilt-live-update-"+TestContainerInfo.ContainerName.String(), call.CName.String())
+		assert.Equal(t, []string{"tar", "-C", "/proc/1/root", "-x", "-f", "-"}, call.Cmd)
+		assert.Equal(t, []byte("hello world"), call.Stdin)
+
+		// Commands still run in the target container.
+		assert.Equal(t, TestContainerInfo.ContainerName, f.kCli.ExecCalls[2].CName)
+		assert.Equal(t, cmdA.Argv, f.kCli.ExecCalls[2].Cmd)
+	}
+
+	// Later updates go straight to the ephemeral container.
+	f.kCli.ExecCalls = nil
+	err = f.ecu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), toDelete, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, f.kCli.ExecCalls, 2) {
+		assert.Equal(t, []string{"rm", "-rf", "/proc/1/root/foo/delete_me", "/proc/1/root/bar/me_too"}, f.kCli.ExecCalls[0].Cmd)
+		assert.Equal(t, []byte("boop"), f.kCli.ExecCalls[1].Stdin)
+	}
+}
+
+func TestUpdateContainerWithoutTarEphemeralContainerFails(t *testing.T) {
+	f := newExecFixture(t)
+
+	f.kCli.ExecErrors = []error{exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 127"), Code: 127}}
+	f.kCli.EphemeralContainerError = fmt.Errorf("the server could not find the requested resource")
+
+	err := f.ecu.UpdateContainer(f.ctx, TestContainerInfo, newReader("hello world"), nil, nil, true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has no tar, so Live Update needs an ephemeral container")
+	}
+}
+
+func TestIsExecutableNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 127"), Code: 127}, true},
+		{exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 126"), Code: 126}, true},
+		{exec.CodeExitError{Err: fmt.Errorf("tar: /app: Cannot open: No such file or directory"), Code: 2}, false},
+		{fmt.Errorf(`OCI runtime exec failed: exec failed: container_linux.go:349: starting container process caused "exec: \"tar\": executable file not found in $PATH": unknown`), true},
+		{fmt.Errorf(`OCI runtime exec failed: exec failed: unable to start container process: exec: "/bin/tar": stat /bin/tar: no such file or directory: unknown`), true},
+		{fmt.Errorf("open /home/user/app/main.go: no such file or directory"), false},
+	} {
+		assert.Equal(t, tc.expected, isExecutableNotFound(tc.err), "%v", tc.err)
+	}
+}
+
 type execUpdaterFixture struct {
 	t    testing.TB
 	ctx  context.Context
@@ -26,6 +26,7 @@ import (
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/opencontainers/go-digest"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
@@ -84,6 +85,9 @@ type Client interface {
 
 	ServerVersion() types.Version
 
+	// System-wide information about the daemon, like where it stores its data.
+	Info(ctx context.Context) (types.Info, error)
+
 	// Set the orchestrator we're talking to. This is only relevant to switchClient,
 	// which can talk to either the Local or in-cluster docker daemon.
 	SetOrchestrator(orc model.Orchestrator)
@@ -98,12 +102,21 @@ type Client interface {
 	ExecInContainer(ctx context.Context, cID container.ID, cmd model.Cmd, out io.Writer) error
 
 	ImagePush(ctx context.Context, image reference.NamedTagged) (io.ReadCloser, error)
+
+	// Asks the registry whether it has a manifest for the image, without pulling it.
+	ImageExistsInRegistry(ctx context.Context, image reference.NamedTagged) (bool, error)
+
+	// Asks the registry for the digest of the manifest that the image points to, without pulling it.
+	ImageRegistryDigest(ctx context.Context, image reference.Named) (digest.Digest, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error)
 	ImageTag(ctx context.Context, source, target string) error
 	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
 	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
 
+	// Streams the images as a tarball, in the format of `docker save`.
+	ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+
 	NewVersionError(APIrequired, feature string) error
 	BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error)
 	ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error)
@@ -421,18 +434,9 @@ func (c *Cli) ImagePush(ctx context.Context, ref reference.NamedTagged) (io.Read
 	}
 
 	logger.Get(ctx).Infof("Authenticating to image repo: %s", repoInfo.Index.Name)
-	infoWriter := logger.Get(ctx).Writer(logger.InfoLvl)
-	cli, err := command.NewDockerCli(
-		command.WithCombinedStreams(infoWriter),
-		command.WithContentTrust(true),
-	)
+	cli, err := newAuthCli(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "ImagePush#NewDockerCli")
-	}
-
-	err = cli.Initialize(cliflags.NewClientOptions())
-	if err != nil {
-		return nil, errors.Wrap(err, "ImagePush#InitializeCLI")
+		return nil, errors.Wrap(err, "ImagePush")
 	}
 	authConfig := command.ResolveAuthConfig(ctx, cli, repoInfo.Index)
 	requestPrivilege := command.RegistryAuthenticationPrivilegedFunc(cli, repoInfo.Index, "push")
@@ -454,6 +458,89 @@ func (c *Cli) ImagePush(ctx context.Context, ref reference.NamedTagged) (io.Read
 	return c.Client.ImagePush(ctx, ref.String(), options)
 }
 
+// The daemon looks up the manifest in the registry, so this respects
+// the daemon's registry config (e.g., insecure registries).
+func (c *Cli) ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	<-c.initDone
+
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return false, errors.Wrap(err, "ImageExistsInRegistry#ParseRepositoryInfo")
+	}
+
+	cli, err := newAuthCli(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "ImageExistsInRegistry")
+	}
+	authConfig := command.ResolveAuthConfig(ctx, cli, repoInfo.Index)
+	encodedAuth, err := command.EncodeAuthToBase64(authConfig)
+	if err != nil {
+		return false, errors.Wrap(err, "ImageExistsInRegistry#EncodeAuthToBase64")
+	}
+
+	_, err = c.Client.DistributionInspect(ctx, ref.String(), encodedAuth)
+	if err != nil {
+		if isManifestNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "ImageExistsInRegistry")
+	}
+	return true, nil
+}
+
+func (c *Cli) ImageRegistryDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	<-c.initDone
+
+	repoInfo, err := registry.ParseRepositoryInfo(ref)
+	if err != nil {
+		return "", errors.Wrap(err, "ImageRegistryDigest#ParseRepositoryInfo")
+	}
+
+	cli, err := newAuthCli(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "ImageRegistryDigest")
+	}
+	authConfig := command.ResolveAuthConfig(ctx, cli, repoInfo.Index)
+	encodedAuth, err := command.EncodeAuthToBase64(authConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "ImageRegistryDigest#EncodeAuthToBase64")
+	}
+
+	inspect, err := c.Client.DistributionInspect(ctx, reference.TagNameOnly(ref).String(), encodedAuth)
+	if err != nil {
+		return "", errors.Wrap(err, "ImageRegistryDigest")
+	}
+	return inspect.Descriptor.Digest, nil
+}
+
+// Registries don't agree on how to say that a tag doesn't exist.
+func isManifestNotFound(err error) bool {
+	if client.IsErrNotFound(err) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "manifest unknown") ||
+		strings.Contains(msg, "not found")
+}
+
+// A docker CLI, for reading the user's registry credentials.
+func newAuthCli(ctx context.Context) (*command.DockerCli, error) {
+	infoWriter := logger.Get(ctx).Writer(logger.InfoLvl)
+	cli, err := command.NewDockerCli(
+		command.WithCombinedStreams(infoWriter),
+		command.WithContentTrust(true),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewDockerCli")
+	}
+
+	err = cli.Initialize(cliflags.NewClientOptions())
+	if err != nil {
+		return nil, errors.Wrap(err, "InitializeCLI")
+	}
+	return cli, nil
+}
+
 func (c *Cli) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	<-c.initDone
 
@@ -283,3 +283,9 @@ func TestProvideEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryAuthKey(t *testing.T) {
+	assert.Equal(t, "gcr.io", registryAuthKey("gcr.io/windmill-public-containers"))
+	assert.Equal(t, "localhost:32000", registryAuthKey("localhost:32000"))
+	assert.Equal(t, dockerHubAuthKey, registryAuthKey("docker.io/tilt"))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/docker/cli/opts"
+	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/internal/container"
@@ -33,6 +34,11 @@ type Env struct {
 	// https://github.com/kubernetes/minikube/issues/4143
 	IsOldMinikube bool
 
+	// Set if the docker daemon runs in a Colima, Rancher Desktop, or Lima VM,
+	// with the host directories that the VM mounts.
+	VMRuntime VMRuntime
+	VMMounts  []string
+
 	// If the env failed to load for some reason, propagate that error
 	// so that we can report it when the user tries to do a docker_build.
 	Error error
@@ -61,7 +67,7 @@ type ClusterEnv Env
 type LocalEnv Env
 
 func ProvideLocalEnv(ctx context.Context, cEnv ClusterEnv) LocalEnv {
-	result := overlayOSEnvVars(Env{})
+	result := withVMRuntime(overlayOSEnvVars(Env{}), homeDir(ctx), defaultDockerSocket)
 
 	// The user may have already configured their local docker client
 	// to use Minikube's docker server. We check for that by comparing
@@ -111,7 +117,20 @@ func ProvideClusterEnv(ctx context.Context, env k8s.Env, runtime container.Runti
 		}
 	}
 
-	return ClusterEnv(overlayOSEnvVars(Env(result)))
+	result = overlayOSEnvVars(result)
+	if env != k8s.EnvMinikube && env != k8s.EnvMicroK8s {
+		result = withVMRuntime(result, homeDir(ctx), defaultDockerSocket)
+	}
+	return ClusterEnv(result)
+}
+
+func homeDir(ctx context.Context) string {
+	dir, err := homedir.Dir()
+	if err != nil {
+		logger.Get(ctx).Debugf("Error loading homedir: %v", err)
+		return ""
+	}
+	return dir
 }
 
 func isOldMinikube(ctx context.Context, minikubeClient k8s.MinikubeClient) bool {
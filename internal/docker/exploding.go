@@ -7,6 +7,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -56,6 +57,12 @@ func (c explodingClient) ExecInContainer(ctx context.Context, cID container.ID,
 func (c explodingClient) ImagePush(ctx context.Context, ref reference.NamedTagged) (io.ReadCloser, error) {
 	return nil, c.err
 }
+func (c explodingClient) ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	return false, c.err
+}
+func (c explodingClient) ImageRegistryDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	return "", c.err
+}
 func (c explodingClient) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	return types.ImageBuildResponse{}, c.err
 }
@@ -68,6 +75,9 @@ func (c explodingClient) ImageInspectWithRaw(ctx context.Context, imageID string
 func (c explodingClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
 	return nil, c.err
 }
+func (c explodingClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	return nil, c.err
+}
 func (c explodingClient) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	return nil, c.err
 }
@@ -77,6 +87,9 @@ func (c explodingClient) NewVersionError(APIrequired, feature string) error {
 func (c explodingClient) BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
 	return nil, c.err
 }
+func (c explodingClient) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, c.err
+}
 func (c explodingClient) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
 	return types.ContainersPruneReport{}, c.err
 }
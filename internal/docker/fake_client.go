@@ -15,6 +15,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -87,6 +88,13 @@ type FakeClient struct {
 	PushOptions types.ImagePushOptions
 	PushOutput  string
 
+	// Images that ImageExistsInRegistry reports as pushed.
+	RegistryImages      map[string]bool
+	RegistryLookupCount int
+
+	// Digests that ImageRegistryDigest reports, keyed by image ref.
+	RegistryDigests map[string]digest.Digest
+
 	BuildCount        int
 	BuildOptions      BuildOptions
 	BuildContext      *bytes.Buffer
@@ -119,9 +127,15 @@ type FakeClient struct {
 	// even if one hasn't been explicitly pre-loaded.
 	ImageAlwaysExists bool
 
+	// Tarballs returned by ImageSave, keyed by image ref.
+	SavedImages map[string][]byte
+
 	Orchestrator      model.Orchestrator
 	CheckConnectedErr error
 
+	// Returned by Env.
+	FakeEnv Env
+
 	ThrowNewVersionError   bool
 	BuildCachePruneErr     error
 	BuildCachePruneOpts    types.BuildCachePruneOptions
@@ -129,6 +143,9 @@ type FakeClient struct {
 	ContainersPruneErr     error
 	ContainersPruneFilters filters.Args
 	ContainersPruned       []string
+
+	DaemonInfo types.Info
+	InfoErr    error
 }
 
 func NewFakeClient() *FakeClient {
@@ -148,7 +165,7 @@ func (c *FakeClient) CheckConnected() error {
 	return c.CheckConnectedErr
 }
 func (c *FakeClient) Env() Env {
-	return Env{}
+	return c.FakeEnv
 }
 func (c *FakeClient) BuilderVersion() types.BuilderVersion {
 	return types.BuilderV1
@@ -232,6 +249,20 @@ func (c *FakeClient) ImagePush(ctx context.Context, ref reference.NamedTagged) (
 	return NewFakeDockerResponse(c.PushOutput), nil
 }
 
+func (c *FakeClient) ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	c.RegistryLookupCount++
+	return c.RegistryImages[ref.String()], nil
+}
+
+func (c *FakeClient) ImageRegistryDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	c.RegistryLookupCount++
+	d, ok := c.RegistryDigests[ref.String()]
+	if !ok {
+		return "", newNotFoundErrorf("fakeClient.RegistryDigests key: %s", ref.String())
+	}
+	return d, nil
+}
+
 func (c *FakeClient) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	c.BuildCount++
 	c.BuildOptions = options
@@ -285,6 +316,17 @@ func (c *FakeClient) ImageList(ctx context.Context, options types.ImageListOptio
 	return summaries, nil
 }
 
+func (c *FakeClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	if len(imageIDs) != 1 {
+		return nil, fmt.Errorf("fakeClient.ImageSave only saves one image at a time")
+	}
+	b, ok := c.SavedImages[imageIDs[0]]
+	if !ok {
+		return nil, newNotFoundErrorf("fakeClient.SavedImages key: %s", imageIDs[0])
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
 func (c *FakeClient) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	c.RemovedImageIDs = append(c.RemovedImageIDs, imageID)
 	sort.Strings(c.RemovedImageIDs)
@@ -324,6 +366,10 @@ func (c *FakeClient) BuildCachePrune(ctx context.Context, opts types.BuildCacheP
 	return report, nil
 }
 
+func (c *FakeClient) Info(ctx context.Context) (types.Info, error) {
+	return c.DaemonInfo, c.InfoErr
+}
+
 func (c *FakeClient) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
 	if err := c.ContainersPruneErr; err != nil {
 		c.ContainersPruneErr = nil
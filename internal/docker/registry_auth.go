@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/cli/cli/config"
+)
+
+// The key that the docker config uses for Docker Hub credentials.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// Reads the username and password for a registry from the user's docker config
+// (including credential helpers, like gcloud's), the same way `docker push` does.
+func ReadRegistryAuth(host string) (username string, password string, err error) {
+	key := registryAuthKey(host)
+	configFile := config.LoadDefaultConfigFile(ioutil.Discard)
+	auth, err := configFile.GetAuthConfig(key)
+	if err != nil {
+		return "", "", fmt.Errorf("reading credentials for %s: %v", key, err)
+	}
+	if auth.Username == "" && auth.Password == "" {
+		return "", "", fmt.Errorf("no credentials for %s in your docker config. Run `docker login %s`", key, key)
+	}
+	return auth.Username, auth.Password, nil
+}
+
+// Docker stores credentials by registry hostname, without the repository path.
+func registryAuthKey(host string) string {
+	hostname := strings.SplitN(host, "/", 2)[0]
+	if hostname == "docker.io" || hostname == "index.docker.io" {
+		return dockerHubAuthKey
+	}
+	return hostname
+}
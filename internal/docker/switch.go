@@ -8,6 +8,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -78,6 +79,12 @@ func (c *switchCli) ImagePush(ctx context.Context, ref reference.NamedTagged) (i
 func (c *switchCli) ImageBuild(ctx context.Context, buildContext io.Reader, options BuildOptions) (types.ImageBuildResponse, error) {
 	return c.client().ImageBuild(ctx, buildContext, options)
 }
+func (c *switchCli) ImageExistsInRegistry(ctx context.Context, ref reference.NamedTagged) (bool, error) {
+	return c.client().ImageExistsInRegistry(ctx, ref)
+}
+func (c *switchCli) ImageRegistryDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	return c.client().ImageRegistryDigest(ctx, ref)
+}
 func (c *switchCli) ImageTag(ctx context.Context, source, target string) error {
 	return c.client().ImageTag(ctx, source, target)
 }
@@ -87,6 +94,9 @@ func (c *switchCli) ImageInspectWithRaw(ctx context.Context, imageID string) (ty
 func (c *switchCli) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
 	return c.client().ImageList(ctx, options)
 }
+func (c *switchCli) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	return c.client().ImageSave(ctx, imageIDs)
+}
 func (c *switchCli) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	return c.client().ImageRemove(ctx, imageID, options)
 }
@@ -96,6 +106,9 @@ func (c *switchCli) NewVersionError(APIrequired, feature string) error {
 func (c *switchCli) BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
 	return c.client().BuildCachePrune(ctx, opts)
 }
+func (c *switchCli) Info(ctx context.Context) (types.Info, error) {
+	return c.client().Info(ctx)
+}
 func (c *switchCli) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
 	return c.client().ContainersPrune(ctx, pruneFilters)
 }
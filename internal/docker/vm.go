@@ -0,0 +1,168 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+)
+
+// Docker Desktop replacements that run the docker daemon in a Lima VM.
+//
+// The VM only sees the host directories that it mounts (at the same paths),
+// so a bind mount from anywhere else comes up empty in the container.
+type VMRuntime string
+
+const (
+	VMRuntimeNone           VMRuntime = ""
+	VMRuntimeColima         VMRuntime = "colima"
+	VMRuntimeRancherDesktop VMRuntime = "rancher-desktop"
+	VMRuntimeLima           VMRuntime = "lima"
+)
+
+// Where the docker CLI looks for the daemon when DOCKER_HOST isn't set.
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// A VM runtime's docker socket on the host.
+type vmSocket struct {
+	runtime VMRuntime
+	path    string
+}
+
+// The sockets we try, in order, when DOCKER_HOST isn't set and there's no
+// default socket. (These runtimes point the docker CLI at their sockets
+// with a docker context, which Tilt doesn't read.)
+func vmSockets(homeDir string) []vmSocket {
+	return []vmSocket{
+		{VMRuntimeColima, filepath.Join(colimaHome(homeDir), "default", "docker.sock")},
+		// Colima before v0.4 didn't have profile directories.
+		{VMRuntimeColima, filepath.Join(colimaHome(homeDir), "docker.sock")},
+		{VMRuntimeRancherDesktop, filepath.Join(homeDir, ".rd", "docker.sock")},
+		{VMRuntimeLima, filepath.Join(limaHome(homeDir), "docker", "sock", "docker.sock")},
+	}
+}
+
+func colimaHome(homeDir string) string {
+	if dir := os.Getenv("COLIMA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir, ".colima")
+}
+
+func limaHome(homeDir string) string {
+	if dir := os.Getenv("LIMA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir, ".lima")
+}
+
+// Detects whether the docker daemon runs in a Colima, Rancher Desktop, or Lima VM,
+// and which host directories the VM mounts.
+//
+// If DOCKER_HOST isn't set and there's no default socket, points the env at
+// the first VM socket we find.
+func withVMRuntime(e Env, homeDir string, defaultSocket string) Env {
+	if e.Error != nil || homeDir == "" {
+		return e
+	}
+
+	socket := ""
+	if e.Host == "" {
+		if _, err := os.Stat(defaultSocket); err == nil {
+			// Colima and Rancher Desktop can symlink the default socket to theirs.
+			socket, _ = filepath.EvalSymlinks(defaultSocket)
+		} else {
+			for _, s := range vmSockets(homeDir) {
+				if _, err := os.Stat(s.path); err == nil {
+					e.Host = "unix://" + s.path
+					socket = s.path
+					break
+				}
+			}
+		}
+	} else if strings.HasPrefix(e.Host, "unix://") {
+		socket = strings.TrimPrefix(e.Host, "unix://")
+	}
+
+	if socket == "" {
+		return e
+	}
+	e.VMRuntime, e.VMMounts = vmRuntimeForSocket(socket, homeDir)
+	return e
+}
+
+func vmRuntimeForSocket(socket string, homeDir string) (VMRuntime, []string) {
+	if dir, ok := ospath.Child(colimaHome(homeDir), socket); ok {
+		// ~/.colima/<profile>/docker.sock, or ~/.colima/docker.sock
+		profile := "default"
+		if parts := strings.Split(filepath.ToSlash(dir), "/"); len(parts) > 1 {
+			profile = parts[0]
+		}
+		configPath := filepath.Join(colimaHome(homeDir), profile, "colima.yaml")
+		return VMRuntimeColima, vmMounts(configPath, homeDir, []string{homeDir, "/tmp/colima"})
+	}
+
+	if ospath.IsChild(filepath.Join(homeDir, ".rd"), socket) {
+		// Rancher Desktop's default mounts on macOS.
+		return VMRuntimeRancherDesktop, []string{
+			homeDir, "/Volumes", "/var/folders", "/private/var/folders", "/tmp/rancher-desktop",
+		}
+	}
+
+	if dir, ok := ospath.Child(limaHome(homeDir), socket); ok {
+		// ~/.lima/<instance>/sock/docker.sock
+		instance := strings.Split(filepath.ToSlash(dir), "/")[0]
+		configPath := filepath.Join(limaHome(homeDir), instance, "lima.yaml")
+		return VMRuntimeLima, vmMounts(configPath, homeDir, []string{homeDir, "/tmp/lima"})
+	}
+
+	return VMRuntimeNone, nil
+}
+
+// Reads the mounts from a Colima or Lima config file,
+// which both list them as `mounts: [{location: ...}]`.
+//
+// Falls back to the runtime's default mounts if the config doesn't list any.
+func vmMounts(configPath string, homeDir string, defaults []string) []string {
+	contents, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return defaults
+	}
+
+	var config struct {
+		Mounts []struct {
+			Location string `yaml:"location"`
+		} `yaml:"mounts"`
+	}
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil || len(config.Mounts) == 0 {
+		return defaults
+	}
+
+	var result []string
+	for _, m := range config.Mounts {
+		loc := m.Location
+		if loc == "~" {
+			loc = homeDir
+		} else if strings.HasPrefix(loc, "~/") {
+			loc = filepath.Join(homeDir, loc[2:])
+		}
+		if loc != "" {
+			result = append(result, loc)
+		}
+	}
+	return result
+}
+
+// Returns true if a bind mount from this host path will show up in the container:
+// either the daemon isn't in a VM, or the VM mounts the path.
+func (e Env) CanBindMount(path string) bool {
+	if e.VMRuntime == VMRuntimeNone || !filepath.IsAbs(path) {
+		return true
+	}
+	return ospath.IsChildOfOne(e.VMMounts, path)
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestVMRuntimeFallsBackToColimaSocket(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	socket := f.WriteFile(".colima/default/docker.sock", "")
+	env := withVMRuntime(Env{}, f.Path(), f.JoinPath("var/run/docker.sock"))
+	assert.Equal(t, "unix://"+socket, env.Host)
+	assert.Equal(t, VMRuntimeColima, env.VMRuntime)
+	assert.Equal(t, []string{f.Path(), "/tmp/colima"}, env.VMMounts)
+}
+
+func TestVMRuntimeReadsColimaProfileMounts(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	socket := f.WriteFile(".colima/work/docker.sock", "")
+	f.WriteFile(".colima/work/colima.yaml", `
+mounts:
+  - location: ~/src
+    writable: true
+  - location: /opt/data
+`)
+	env := withVMRuntime(Env{Host: "unix://" + socket}, f.Path(), f.JoinPath("var/run/docker.sock"))
+	assert.Equal(t, "unix://"+socket, env.Host)
+	assert.Equal(t, VMRuntimeColima, env.VMRuntime)
+	assert.Equal(t, []string{f.JoinPath("src"), "/opt/data"}, env.VMMounts)
+	assert.True(t, env.CanBindMount(f.JoinPath("src", "app")))
+	assert.False(t, env.CanBindMount(f.JoinPath("other")))
+}
+
+func TestVMRuntimeFollowsDefaultSocketSymlink(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	socket := f.WriteFile(".rd/docker.sock", "")
+	f.MkdirAll("var/run")
+	f.WriteSymlink(socket, "var/run/docker.sock")
+	env := withVMRuntime(Env{}, f.Path(), f.JoinPath("var/run/docker.sock"))
+	assert.Equal(t, "", env.Host)
+	assert.Equal(t, VMRuntimeRancherDesktop, env.VMRuntime)
+	assert.Contains(t, env.VMMounts, "/Volumes")
+}
+
+func TestVMRuntimeNone(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	defaultSocket := f.WriteFile("var/run/docker.sock", "")
+	env := withVMRuntime(Env{}, f.Path(), defaultSocket)
+	assert.Equal(t, "", env.Host)
+	assert.Equal(t, VMRuntimeNone, env.VMRuntime)
+	assert.True(t, env.CanBindMount("/anywhere"))
+
+	env = withVMRuntime(Env{Host: "tcp://192.168.99.100:2376"}, f.Path(), defaultSocket)
+	assert.Equal(t, VMRuntimeNone, env.VMRuntime)
+}
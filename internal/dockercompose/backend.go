@@ -0,0 +1,147 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A compose CLI that Tilt can run docker-compose projects with.
+//
+// They all read the same docker-compose.yml files and accept the same basic
+// commands, but differ in how they're invoked and in which flags they support.
+type Backend interface {
+	Name() model.DockerComposeBackend
+
+	// The command that runs compose, before any compose arguments,
+	// e.g., ["docker", "compose"].
+	Argv() []string
+
+	// Whether the CLI accepts --verbose before the subcommand.
+	SupportsVerbose() bool
+
+	// Whether `up` accepts --no-deps. Tilt starts dependencies itself,
+	// so without it, `up` may restart services that Tilt already started.
+	SupportsNoDeps() bool
+
+	// Whether the CLI implements `events --json`, which Tilt uses
+	// to follow container status.
+	SupportsEvents() bool
+
+	// What goes between the project and service names when the CLI names an
+	// image that it builds, e.g., "_" for myproject_web.
+	ImageNameSeparator() string
+}
+
+type cliBackend struct {
+	name     model.DockerComposeBackend
+	argv     []string
+	verbose  bool
+	noDeps   bool
+	events   bool
+	imageSep string
+}
+
+func (b cliBackend) Name() model.DockerComposeBackend { return b.name }
+func (b cliBackend) Argv() []string                   { return append([]string{}, b.argv...) }
+func (b cliBackend) SupportsVerbose() bool            { return b.verbose }
+func (b cliBackend) SupportsNoDeps() bool             { return b.noDeps }
+func (b cliBackend) SupportsEvents() bool             { return b.events }
+func (b cliBackend) ImageNameSeparator() string       { return b.imageSep }
+
+var (
+	BackendV1 Backend = cliBackend{
+		name:     model.DockerComposeBackendV1,
+		argv:     []string{"docker-compose"},
+		verbose:  true,
+		noDeps:   true,
+		events:   true,
+		imageSep: "_",
+	}
+	BackendV2 Backend = cliBackend{
+		name:     model.DockerComposeBackendV2,
+		argv:     []string{"docker", "compose"},
+		noDeps:   true,
+		events:   true,
+		imageSep: "-",
+	}
+	BackendPodman Backend = cliBackend{
+		name:     model.DockerComposeBackendPodman,
+		argv:     []string{"podman-compose"},
+		noDeps:   true,
+		imageSep: "_",
+	}
+	BackendNerdctl Backend = cliBackend{
+		name:     model.DockerComposeBackendNerdctl,
+		argv:     []string{"nerdctl", "compose"},
+		imageSep: "-",
+	}
+)
+
+// In the order we try them when detecting.
+//
+// docker-compose v1 comes first because it's what Tilt has always used.
+var allBackends = []Backend{BackendV1, BackendV2, BackendPodman, BackendNerdctl}
+
+// Looks up the backend with the given name, as written in the Tiltfile.
+//
+// "auto" (or empty) means Tilt should detect one, and returns nil.
+func BackendForName(name string) (Backend, error) {
+	if name == "" || name == "auto" {
+		return nil, nil
+	}
+	for _, b := range allBackends {
+		if string(b.Name()) == name {
+			return b, nil
+		}
+	}
+
+	var names []string
+	for _, b := range allBackends {
+		names = append(names, fmt.Sprintf("%q", b.Name()))
+	}
+	return nil, fmt.Errorf("unknown docker-compose backend %q. Must be \"auto\" or one of: %s",
+		name, strings.Join(names, ", "))
+}
+
+// Finds the first compose CLI that's installed.
+type backendDetector struct {
+	// Swapped out in tests.
+	lookPath func(file string) (string, error)
+	run      func(ctx context.Context, argv []string) error
+}
+
+func newBackendDetector() backendDetector {
+	return backendDetector{
+		lookPath: exec.LookPath,
+		run: func(ctx context.Context, argv []string) error {
+			return exec.CommandContext(ctx, argv[0], argv[1:]...).Run()
+		},
+	}
+}
+
+// If we can't find any of them, falls back to docker-compose v1,
+// so that the user gets the same "not found" error they always have.
+func (d backendDetector) detect(ctx context.Context) Backend {
+	for _, b := range allBackends {
+		argv := b.Argv()
+		_, err := d.lookPath(argv[0])
+		if err != nil {
+			continue
+		}
+
+		// For compose CLIs that are plugins of a bigger CLI,
+		// make sure the plugin is actually installed.
+		if len(argv) > 1 {
+			err := d.run(ctx, append(argv, "version"))
+			if err != nil {
+				continue
+			}
+		}
+		return b
+	}
+	return BackendV1
+}
@@ -0,0 +1,128 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBackendForName(t *testing.T) {
+	for _, name := range []string{"", "auto"} {
+		b, err := BackendForName(name)
+		require.NoError(t, err)
+		assert.Nil(t, b)
+	}
+
+	b, err := BackendForName("podman-compose")
+	require.NoError(t, err)
+	assert.Equal(t, BackendPodman, b)
+
+	b, err = BackendForName("docker compose")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker", "compose"}, b.Argv())
+
+	_, err = BackendForName("podman")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown docker-compose backend "podman"`)
+		assert.Contains(t, err.Error(), `"nerdctl compose"`)
+	}
+}
+
+func TestDetectBackend(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		plugins   []string
+		expected  Backend
+	}{
+		{"nothing installed", nil, nil, BackendV1},
+		{"v1 preferred", []string{"docker-compose", "docker"}, []string{"docker compose"}, BackendV1},
+		{"v2 plugin", []string{"docker"}, []string{"docker compose"}, BackendV2},
+		{"docker without compose plugin", []string{"docker", "podman-compose"}, nil, BackendPodman},
+		{"nerdctl", []string{"nerdctl"}, []string{"nerdctl compose"}, BackendNerdctl},
+		{"nerdctl without compose", []string{"nerdctl"}, nil, BackendV1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := fakeDetector(tt.installed, tt.plugins)
+			assert.Equal(t, tt.expected, d.detect(context.Background()))
+		})
+	}
+}
+
+func TestDetectBackendOnce(t *testing.T) {
+	c := NewDockerComposeClient(docker.LocalEnv{}).(*cmdDCClient)
+	calls := 0
+	c.detector = fakeDetector([]string{"podman-compose"}, nil)
+	lookPath := c.detector.lookPath
+	c.detector.lookPath = func(file string) (string, error) {
+		calls++
+		return lookPath(file)
+	}
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	proj := model.DockerComposeProject{ConfigPaths: []string{"docker-compose.yml"}}
+	assert.Equal(t, BackendPodman, c.Backend(ctx, proj))
+	detectCalls := calls
+	assert.Equal(t, BackendPodman, c.Backend(ctx, proj))
+	assert.Equal(t, detectCalls, calls)
+
+	proj.Backend = model.DockerComposeBackendNerdctl
+	assert.Equal(t, BackendNerdctl, c.Backend(ctx, proj))
+}
+
+func TestBackendCommands(t *testing.T) {
+	c := NewDockerComposeClient(docker.LocalEnv{}).(*cmdDCClient)
+	ctx := context.Background()
+
+	args := append(configArgs(model.DockerComposeProject{ConfigPaths: []string{"a.yml", "b.yml"}}), "ps", "-q")
+	cmd := c.dcCommand(ctx, BackendV2, args)
+	assert.Equal(t, "docker compose -f a.yml -f b.yml ps -q", strings.Join(cmd.Args, " "))
+
+	cmd = c.dcCommand(ctx, BackendPodman, args)
+	assert.Equal(t, "podman-compose -f a.yml -f b.yml ps -q", strings.Join(cmd.Args, " "))
+}
+
+func TestStreamEventsUnsupported(t *testing.T) {
+	c := NewDockerComposeClient(docker.LocalEnv{})
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ch, err := c.StreamEvents(ctx, model.DockerComposeProject{
+		ConfigPaths: []string{"docker-compose.yml"},
+		Backend:     model.DockerComposeBackendNerdctl,
+	})
+	require.NoError(t, err)
+
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func fakeDetector(installed []string, plugins []string) backendDetector {
+	return backendDetector{
+		lookPath: func(file string) (string, error) {
+			for _, f := range installed {
+				if f == file {
+					return "/usr/bin/" + f, nil
+				}
+			}
+			return "", fmt.Errorf("%s: executable file not found in $PATH", file)
+		},
+		run: func(ctx context.Context, argv []string) error {
+			cmd := strings.Join(argv[:len(argv)-1], " ")
+			for _, p := range plugins {
+				if p == cmd {
+					return nil
+				}
+			}
+			return fmt.Errorf("unknown command: %s", cmd)
+		},
+	}
+}
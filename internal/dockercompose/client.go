@@ -20,43 +20,50 @@ import (
 )
 
 type DockerComposeClient interface {
-	Up(ctx context.Context, configPaths []string, serviceName model.TargetName, shouldBuild bool, stdout, stderr io.Writer) error
-	Down(ctx context.Context, configPaths []string, stdout, stderr io.Writer) error
-	StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error)
-	StreamEvents(ctx context.Context, configPaths []string) (<-chan string, error)
-	Config(ctx context.Context, configPaths []string) (string, error)
-	Services(ctx context.Context, configPaths []string) (string, error)
-	ContainerID(ctx context.Context, configPaths []string, serviceName model.TargetName) (container.ID, error)
+	Up(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName, shouldBuild bool, stdout, stderr io.Writer) error
+	Down(ctx context.Context, proj model.DockerComposeProject, stdout, stderr io.Writer) error
+	StreamLogs(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (io.ReadCloser, error)
+	StreamEvents(ctx context.Context, proj model.DockerComposeProject) (<-chan string, error)
+	Config(ctx context.Context, proj model.DockerComposeProject) (string, error)
+	Services(ctx context.Context, proj model.DockerComposeProject) (string, error)
+	ContainerID(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (container.ID, error)
+
+	// The compose CLI that runs the project.
+	Backend(ctx context.Context, proj model.DockerComposeProject) Backend
 }
 
 type cmdDCClient struct {
 	env docker.Env
 	mu  *sync.Mutex
+
+	detector   backendDetector
+	detectOnce sync.Once
+	detected   Backend
 }
 
 // TODO(dmiller): we might want to make this take a path to the docker-compose config so we don't
 // have to keep passing it in.
 func NewDockerComposeClient(env docker.LocalEnv) DockerComposeClient {
 	return &cmdDCClient{
-		env: docker.Env(env),
-		mu:  &sync.Mutex{},
+		env:      docker.Env(env),
+		mu:       &sync.Mutex{},
+		detector: newBackendDetector(),
 	}
 }
 
-func (c *cmdDCClient) Up(ctx context.Context, configPaths []string, serviceName model.TargetName, shouldBuild bool, stdout, stderr io.Writer) error {
+func (c *cmdDCClient) Up(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName, shouldBuild bool, stdout, stderr io.Writer) error {
+	b := c.Backend(ctx, proj)
 	var genArgs []string
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
+	if b.SupportsVerbose() && logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
 		genArgs = []string{"--verbose"}
 	}
 
-	for _, config := range configPaths {
-		genArgs = append(genArgs, "-f", config)
-	}
+	genArgs = append(genArgs, configArgs(proj)...)
 
 	if shouldBuild {
 		var buildArgs = append([]string{}, genArgs...)
 		buildArgs = append(buildArgs, "build", serviceName.String())
-		cmd := c.dcCommand(ctx, buildArgs)
+		cmd := c.dcCommand(ctx, b, buildArgs)
 		cmd.Stdout = stdout
 		cmd.Stderr = stderr
 		err := cmd.Run()
@@ -75,7 +82,11 @@ func (c *cmdDCClient) Up(ctx context.Context, configPaths []string, serviceName
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	runArgs := append([]string{}, genArgs...)
-	runArgs = append(runArgs, "up", "--no-deps", "--no-build", "-d")
+	runArgs = append(runArgs, "up")
+	if b.SupportsNoDeps() {
+		runArgs = append(runArgs, "--no-deps")
+	}
+	runArgs = append(runArgs, "--no-build", "-d")
 
 	if !shouldBuild {
 		// !shouldBuild implies that Tilt will take care of building, which implies that
@@ -86,29 +97,29 @@ func (c *cmdDCClient) Up(ctx context.Context, configPaths []string, serviceName
 	}
 
 	runArgs = append(runArgs, serviceName.String())
-	cmd := c.dcCommand(ctx, runArgs)
+	cmd := c.dcCommand(ctx, b, runArgs)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
 	return FormatError(cmd, nil, cmd.Run())
 }
 
-func (c *cmdDCClient) Down(ctx context.Context, configPaths []string, stdout, stderr io.Writer) error {
+func (c *cmdDCClient) Down(ctx context.Context, proj model.DockerComposeProject, stdout, stderr io.Writer) error {
+	b := c.Backend(ctx, proj)
+
 	// To be safe, we try not to run two docker-compose downs in parallel,
 	// because we know docker-compose up is not thread-safe.
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	var args []string
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
+	if b.SupportsVerbose() && logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
 		args = []string{"--verbose"}
 	}
-	for _, config := range configPaths {
-		args = append(args, "-f", config)
-	}
+	args = append(args, configArgs(proj)...)
 
 	args = append(args, "down")
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, b, args)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
@@ -120,18 +131,16 @@ func (c *cmdDCClient) Down(ctx context.Context, configPaths []string, stdout, st
 	return nil
 }
 
-func (c *cmdDCClient) StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error) {
+func (c *cmdDCClient) StreamLogs(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (io.ReadCloser, error) {
 	// TODO(maia): --since time
 	// (may need to implement with `docker log <cID>` instead since `d-c log` doesn't support `--since`
-	var args []string
-	for _, config := range configPaths {
-		args = append(args, "-f", config)
-	}
+	b := c.Backend(ctx, proj)
+	args := configArgs(proj)
 	args = append(args, "logs", "--no-color", "-f", serviceName.String())
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, b, args)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, errors.Wrap(err, "making stdout pipe for `docker-compose logs`")
+		return nil, errors.Wrapf(err, "making stdout pipe for `%s logs`", b.Name())
 	}
 
 	errBuf := bytes.Buffer{}
@@ -139,38 +148,43 @@ func (c *cmdDCClient) StreamLogs(ctx context.Context, configPaths []string, serv
 
 	err = cmd.Start()
 	if err != nil {
-		return nil, errors.Wrapf(err, "`docker-compose %s`",
-			strings.Join(args, " "))
+		return nil, errors.Wrapf(err, "`%s %s`",
+			b.Name(), strings.Join(args, " "))
 	}
 
 	go func() {
 		err = cmd.Wait()
 		if err != nil {
-			logger.Get(ctx).Debugf("cmd `docker-compose %s` exited with error: \"%v\" (stderr: %s)",
-				strings.Join(args, " "), err, errBuf.String())
+			logger.Get(ctx).Debugf("cmd `%s %s` exited with error: \"%v\" (stderr: %s)",
+				b.Name(), strings.Join(args, " "), err, errBuf.String())
 		}
 	}()
 	return stdout, nil
 }
 
-func (c *cmdDCClient) StreamEvents(ctx context.Context, configPaths []string) (<-chan string, error) {
+func (c *cmdDCClient) StreamEvents(ctx context.Context, proj model.DockerComposeProject) (<-chan string, error) {
 	ch := make(chan string)
 
-	var args []string
-	for _, config := range configPaths {
-		args = append(args, "-f", config)
+	b := c.Backend(ctx, proj)
+	if !b.SupportsEvents() {
+		logger.Get(ctx).Infof("[DOCKER-COMPOSE WATCHER] %s doesn't support `events`, "+
+			"so container status won't update after the container starts", b.Name())
+		close(ch)
+		return ch, nil
 	}
+
+	args := configArgs(proj)
 	args = append(args, "events", "--json")
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, b, args)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return ch, errors.Wrap(err, "making stdout pipe for `docker-compose events`")
+		return ch, errors.Wrapf(err, "making stdout pipe for `%s events`", b.Name())
 	}
 
 	err = cmd.Start()
 	if err != nil {
-		return ch, errors.Wrapf(err, "`docker-compose %s`",
-			strings.Join(args, " "))
+		return ch, errors.Wrapf(err, "`%s %s`",
+			b.Name(), strings.Join(args, " "))
 	}
 	go func() {
 		scanner := bufio.NewScanner(stdout)
@@ -191,16 +205,16 @@ func (c *cmdDCClient) StreamEvents(ctx context.Context, configPaths []string) (<
 	return ch, nil
 }
 
-func (c *cmdDCClient) Config(ctx context.Context, configPaths []string) (string, error) {
-	return c.dcOutput(ctx, configPaths, "config")
+func (c *cmdDCClient) Config(ctx context.Context, proj model.DockerComposeProject) (string, error) {
+	return c.dcOutput(ctx, proj, "config")
 }
 
-func (c *cmdDCClient) Services(ctx context.Context, configPaths []string) (string, error) {
-	return c.dcOutput(ctx, configPaths, "config", "--services")
+func (c *cmdDCClient) Services(ctx context.Context, proj model.DockerComposeProject) (string, error) {
+	return c.dcOutput(ctx, proj, "config", "--services")
 }
 
-func (c *cmdDCClient) ContainerID(ctx context.Context, configPaths []string, serviceName model.TargetName) (container.ID, error) {
-	id, err := c.dcOutput(ctx, configPaths, "ps", "-q", serviceName.String())
+func (c *cmdDCClient) ContainerID(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (container.ID, error) {
+	id, err := c.dcOutput(ctx, proj, "ps", "-q", serviceName.String())
 	if err != nil {
 		return container.ID(""), err
 	}
@@ -208,20 +222,33 @@ func (c *cmdDCClient) ContainerID(ctx context.Context, configPaths []string, ser
 	return container.ID(id), nil
 }
 
-func (c *cmdDCClient) dcCommand(ctx context.Context, args []string) *exec.Cmd {
-	cmd := exec.CommandContext(ctx, "docker-compose", args...)
+// The backend the project asked for, or the one we detected if it didn't ask.
+func (c *cmdDCClient) Backend(ctx context.Context, proj model.DockerComposeProject) Backend {
+	if proj.Backend != model.DockerComposeBackendAuto {
+		b, err := BackendForName(string(proj.Backend))
+		if err == nil {
+			return b
+		}
+		logger.Get(ctx).Debugf("%v", err)
+	}
+
+	c.detectOnce.Do(func() {
+		c.detected = c.detector.detect(ctx)
+		logger.Get(ctx).Debugf("Using %s to run docker-compose projects", c.detected.Name())
+	})
+	return c.detected
+}
+
+func (c *cmdDCClient) dcCommand(ctx context.Context, b Backend, args []string) *exec.Cmd {
+	argv := append(b.Argv(), args...)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
 	cmd.Env = append(os.Environ(), c.env.AsEnviron()...)
 	return cmd
 }
 
-func (c *cmdDCClient) dcOutput(ctx context.Context, configPaths []string, args ...string) (string, error) {
-
-	var tempArgs []string
-	for _, config := range configPaths {
-		tempArgs = append(tempArgs, "-f", config)
-	}
-	args = append(tempArgs, args...)
-	cmd := c.dcCommand(ctx, args)
+func (c *cmdDCClient) dcOutput(ctx context.Context, proj model.DockerComposeProject, args ...string) (string, error) {
+	args = append(configArgs(proj), args...)
+	cmd := c.dcCommand(ctx, c.Backend(ctx, proj), args)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -234,6 +261,14 @@ func (c *cmdDCClient) dcOutput(ctx context.Context, configPaths []string, args .
 	return strings.TrimSpace(string(output)), err
 }
 
+func configArgs(proj model.DockerComposeProject) []string {
+	var args []string
+	for _, config := range proj.ConfigPaths {
+		args = append(args, "-f", config)
+	}
+	return args
+}
+
 func FormatError(cmd *exec.Cmd, stdout []byte, err error) error {
 	if err == nil {
 		return nil
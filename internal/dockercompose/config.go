@@ -2,6 +2,9 @@ package dockercompose
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -57,14 +60,78 @@ type ServiceConfig struct {
 }
 
 type BuildConfig struct {
-	Context    string `yaml:"context"`
-	Dockerfile string `yaml:"dockerfile"`
+	Context    string    `yaml:"context"`
+	Dockerfile string    `yaml:"dockerfile"`
+	Args       BuildArgs `yaml:"args"`
+	Target     string    `yaml:"target"`
+	CacheFrom  []string  `yaml:"cache_from"`
+	Network    string    `yaml:"network"`
+}
+
+// The build config can be a map, or just the path to the build context.
+// https://docs.docker.com/compose/compose-file/#build
+func (b *BuildConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var context string
+	err := unmarshal(&context)
+	if err == nil {
+		*b = BuildConfig{Context: context}
+		return nil
+	}
+
+	type buildConfig BuildConfig
+	var aux buildConfig
+	err = unmarshal(&aux)
+	if err != nil {
+		return err
+	}
+	*b = BuildConfig(aux)
+	return nil
+}
+
+// Build args can be specified as a map or as a list of KEY=VALUE strings.
+// https://docs.docker.com/compose/compose-file/#args
+type BuildArgs map[string]string
+
+func (a *BuildArgs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var mapType map[string]*string
+	err := unmarshal(&mapType)
+	if err == nil {
+		*a = make(BuildArgs, len(mapType))
+		for k, v := range mapType {
+			if v == nil {
+				// An arg with no value is taken from the environment at build time.
+				// We don't support that yet, so let the Dockerfile default apply.
+				continue
+			}
+			(*a)[k] = *v
+		}
+		return nil
+	}
+
+	var sliceType []string
+	err = unmarshal(&sliceType)
+	if err != nil {
+		return errors.Wrap(err, "unmarshalling build args")
+	}
+
+	*a = make(BuildArgs, len(sliceType))
+	for _, arg := range sliceType {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		(*a)[parts[0]] = parts[1]
+	}
+	return nil
 }
 
 type Volumes []Volume
 
 type Volume struct {
 	Source string
+
+	// The path in the container. Empty for volumes that aren't mounted from the host.
+	Target string
 }
 
 func (v *Volumes) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -84,13 +151,20 @@ func (v *Volumes) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		case string:
 			parts := strings.Split(a, ":")
 			source := parts[0]
+			rest := parts[1:]
 
 			// docker-compose uses : as a separator, but also normalizes
 			// windows paths to absolute (C:\foo\bar), so special-case this.
 			if len(parts) >= 2 && strings.HasPrefix(parts[1], "\\") {
 				source = fmt.Sprintf("%s:%s", parts[0], parts[1])
+				rest = parts[2:]
+			}
+
+			target := ""
+			if len(rest) > 0 {
+				target = rest[0]
 			}
-			*v = append(*v, Volume{Source: source})
+			*v = append(*v, Volume{Source: source, Target: target})
 		}
 	}
 
@@ -148,3 +222,25 @@ func (p *Ports) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 	return nil
 }
+
+const ProjectNameEnvVar = "COMPOSE_PROJECT_NAME"
+
+var invalidProjectNameChars = regexp.MustCompile("[^-_a-z0-9]")
+
+// The name docker-compose uses to namespace containers, networks, and
+// built images. Defaults to the name of the directory containing the
+// first config file.
+// https://docs.docker.com/compose/reference/envvars/#compose_project_name
+func ProjectName(configPaths []string) string {
+	name := os.Getenv(ProjectNameEnvVar)
+	if name == "" && len(configPaths) > 0 {
+		name = filepath.Base(filepath.Dir(configPaths[0]))
+	}
+	return invalidProjectNameChars.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// The name the compose CLI gives to an image it builds for a service
+// without an explicit `image` field.
+func DefaultImageName(b Backend, configPaths []string, serviceName string) string {
+	return fmt.Sprintf("%s%s%s", ProjectName(configPaths), b.ImageNameSeparator(), serviceName)
+}
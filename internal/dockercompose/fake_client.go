@@ -23,6 +23,9 @@ type FakeDCClient struct {
 
 	UpCalls   []UpCall
 	DownError error
+
+	// The backend for projects that don't pick one. Defaults to docker-compose v1.
+	DetectedBackend Backend
 }
 
 // Represents a single call to Up
@@ -41,13 +44,13 @@ func NewFakeDockerComposeClient(t *testing.T, ctx context.Context) *FakeDCClient
 	}
 }
 
-func (c *FakeDCClient) Up(ctx context.Context, configPaths []string, serviceName model.TargetName,
+func (c *FakeDCClient) Up(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName,
 	shouldBuild bool, stdout, stderr io.Writer) error {
-	c.UpCalls = append(c.UpCalls, UpCall{configPaths, serviceName, shouldBuild})
+	c.UpCalls = append(c.UpCalls, UpCall{proj.ConfigPaths, serviceName, shouldBuild})
 	return nil
 }
 
-func (c *FakeDCClient) Down(ctx context.Context, configPaths []string, stdout, stderr io.Writer) error {
+func (c *FakeDCClient) Down(ctx context.Context, proj model.DockerComposeProject, stdout, stderr io.Writer) error {
 	if c.DownError != nil {
 		err := c.DownError
 		c.DownError = err
@@ -56,7 +59,7 @@ func (c *FakeDCClient) Down(ctx context.Context, configPaths []string, stdout, s
 	return nil
 }
 
-func (c *FakeDCClient) StreamLogs(ctx context.Context, configPaths []string, serviceName model.TargetName) (io.ReadCloser, error) {
+func (c *FakeDCClient) StreamLogs(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (io.ReadCloser, error) {
 	output := c.RunLogOutput[serviceName]
 	reader, writer := io.Pipe()
 	go func() {
@@ -78,7 +81,7 @@ func (c *FakeDCClient) StreamLogs(ctx context.Context, configPaths []string, ser
 	return reader, nil
 }
 
-func (c *FakeDCClient) StreamEvents(ctx context.Context, configPaths []string) (<-chan string, error) {
+func (c *FakeDCClient) StreamEvents(ctx context.Context, proj model.DockerComposeProject) (<-chan string, error) {
 	events := make(chan string, 10)
 	go func() {
 		for {
@@ -108,14 +111,25 @@ func (c *FakeDCClient) SendEvent(evt Event) error {
 	return nil
 }
 
-func (c *FakeDCClient) Config(ctx context.Context, configPaths []string) (string, error) {
+func (c *FakeDCClient) Config(ctx context.Context, proj model.DockerComposeProject) (string, error) {
 	return c.ConfigOutput, nil
 }
 
-func (c *FakeDCClient) Services(ctx context.Context, configPaths []string) (string, error) {
+func (c *FakeDCClient) Services(ctx context.Context, proj model.DockerComposeProject) (string, error) {
 	return c.ServicesOutput, nil
 }
 
-func (c *FakeDCClient) ContainerID(ctx context.Context, configPaths []string, serviceName model.TargetName) (container.ID, error) {
+func (c *FakeDCClient) Backend(ctx context.Context, proj model.DockerComposeProject) Backend {
+	b, err := BackendForName(string(proj.Backend))
+	if err == nil && b != nil {
+		return b
+	}
+	if c.DetectedBackend != nil {
+		return c.DetectedBackend
+	}
+	return BackendV1
+}
+
+func (c *FakeDCClient) ContainerID(ctx context.Context, proj model.DockerComposeProject, serviceName model.TargetName) (container.ID, error) {
 	return c.ContainerIdOutput, nil
 }
@@ -6,17 +6,19 @@ import (
 
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
+
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func ReadConfigAndServiceNames(ctx context.Context, dcc DockerComposeClient,
-	configPaths []string) (conf Config, svcNames []string, err error) {
+	proj model.DockerComposeProject) (conf Config, svcNames []string, err error) {
 	// calls to `docker-compose config` take a bit, and we need two,
 	// so do them in parallel to make things faster
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
 
-		configOut, err := dcc.Config(ctx, configPaths)
+		configOut, err := dcc.Config(ctx, proj)
 		if err != nil {
 			return err
 		}
@@ -30,7 +32,7 @@ func ReadConfigAndServiceNames(ctx context.Context, dcc DockerComposeClient,
 
 	g.Go(func() error {
 		var err error
-		svcNames, err = serviceNames(ctx, dcc, configPaths)
+		svcNames, err = serviceNames(ctx, dcc, proj)
 		if err != nil {
 			return err
 		}
@@ -41,8 +43,8 @@ func ReadConfigAndServiceNames(ctx context.Context, dcc DockerComposeClient,
 	return conf, svcNames, err
 }
 
-func serviceNames(ctx context.Context, dcc DockerComposeClient, configPaths []string) ([]string, error) {
-	servicesText, err := dcc.Services(ctx, configPaths)
+func serviceNames(ctx context.Context, dcc DockerComposeClient, proj model.DockerComposeProject) ([]string, error) {
+	servicesText, err := dcc.Services(ctx, proj)
 	if err != nil {
 		return nil, err
 	}
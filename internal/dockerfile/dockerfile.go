@@ -148,6 +148,37 @@ func (d Dockerfile) FindImages() ([]reference.Named, error) {
 	return result, nil
 }
 
+// Find the images in the FROM lines of this dockerfile,
+// skipping the ones that refer to an earlier build stage.
+func (d Dockerfile) FindBaseImages() ([]reference.Named, error) {
+	result := []reference.Named{}
+	ast, err := ParseAST(d)
+	if err != nil {
+		return nil, err
+	}
+
+	stages := make(map[string]bool)
+	err = ast.traverseImageRefs(func(node *parser.Node, ref reference.Named) reference.Named {
+		if node.Value != command.From {
+			return nil
+		}
+
+		if !stages[strings.ToLower(node.Next.Value)] {
+			result = append(result, ref)
+		}
+
+		as := node.Next.Next
+		if as != nil && strings.EqualFold(as.Value, "as") && as.Next != nil {
+			stages[strings.ToLower(as.Next.Value)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (d Dockerfile) String() string {
 	return string(d)
 }
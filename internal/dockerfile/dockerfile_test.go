@@ -180,3 +180,19 @@ RUN --mount=type=cache,id=pip,target=/root/.cache/pip pip install python-dateuti
 		assert.Equal(t, "docker.io/library/python2-base", images[0].String())
 	}
 }
+
+func TestFindBaseImagesSkipsStages(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.15 AS builder
+FROM builder AS test
+FROM gcr.io/image-a
+COPY --from=builder /app /app
+COPY --from=gcr.io/image-b /bin/tool /bin/tool
+`)
+	images, err := df.FindBaseImages()
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(images)) {
+		assert.Equal(t, "docker.io/library/golang:1.15", images[0].String())
+		assert.Equal(t, "gcr.io/image-a", images[1].String())
+	}
+}
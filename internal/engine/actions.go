@@ -31,6 +31,8 @@ type InitAction struct {
 	CloudAddress string
 	Token        token.Token
 	TerminalMode store.TerminalMode
+
+	RollbackOnFailure bool
 }
 
 func (InitAction) Action() {}
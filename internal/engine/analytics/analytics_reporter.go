@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 )
@@ -14,11 +15,12 @@ import (
 const analyticsReportingInterval = time.Minute * 15
 
 type AnalyticsReporter struct {
-	a       *analytics.TiltAnalytics
-	store   store.RStore
-	kClient k8s.Client
-	env     k8s.Env
-	started bool
+	a         *analytics.TiltAnalytics
+	store     store.RStore
+	kClient   k8s.Client
+	env       k8s.Env
+	scheduler *scheduler.Scheduler
+	started   bool
 }
 
 func (ar *AnalyticsReporter) OnChange(ctx context.Context, st store.RStore) {
@@ -32,24 +34,9 @@ func (ar *AnalyticsReporter) OnChange(ctx context.Context, st store.RStore) {
 	// wait until state has been kinda initialized
 	if !state.TiltStartTime.IsZero() && state.LastTiltfileError() == nil {
 		ar.started = true
-		go func() {
-			select {
-			case <-time.After(10 * time.Second):
-				ar.report(ctx) // report once pretty soon after startup...
-			case <-ctx.Done():
-				return
-			}
 
-			for {
-				select {
-				case <-time.After(analyticsReportingInterval):
-					// and once every <interval> thereafter
-					ar.report(ctx)
-				case <-ctx.Done():
-					return
-				}
-			}
-		}()
+		// report once pretty soon after startup, and once every <interval> thereafter
+		ar.scheduler.Every(ctx, "analytics-report", 10*time.Second, analyticsReportingInterval, ar.report)
 	}
 }
 
@@ -59,13 +46,15 @@ func ProvideAnalyticsReporter(
 	a *analytics.TiltAnalytics,
 	st store.RStore,
 	kClient k8s.Client,
-	env k8s.Env) *AnalyticsReporter {
+	env k8s.Env,
+	scheduler *scheduler.Scheduler) *AnalyticsReporter {
 	return &AnalyticsReporter{
-		a:       a,
-		store:   st,
-		kClient: kClient,
-		env:     env,
-		started: false,
+		a:         a,
+		store:     st,
+		kClient:   kClient,
+		env:       env,
+		scheduler: scheduler,
+		started:   false,
 	}
 }
 
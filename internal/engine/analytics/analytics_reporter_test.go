@@ -7,10 +7,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
 	"github.com/tilt-dev/tilt/internal/k8s"
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
@@ -180,7 +182,7 @@ func newAnalyticsReporterTestFixture() *analyticsReporterTestFixture {
 	opter := tiltanalytics.NewFakeOpter(analytics.OptIn)
 	ma, a := tiltanalytics.NewMemoryTiltAnalyticsForTest(opter)
 	kClient := k8s.NewFakeK8sClient()
-	ar := ProvideAnalyticsReporter(a, st, kClient, k8s.EnvDockerDesktop)
+	ar := ProvideAnalyticsReporter(a, st, kClient, k8s.EnvDockerDesktop, scheduler.NewScheduler(clockwork.NewFakeClock()))
 	return &analyticsReporterTestFixture{
 		manifestCount: 0,
 		ar:            ar,
@@ -35,6 +35,7 @@ func (sub *AnalyticsUpdater) OnChange(ctx context.Context, st store.RStore) {
 	defer st.RUnlockState()
 
 	sub.ta.SetTiltfileOpt(state.AnalyticsTiltfileOpt)
+	sub.ta.SetTiltfileConsent(state.AnalyticsTiltfileConsent)
 	err := sub.ta.SetUserOpt(state.AnalyticsUserOpt)
 	if err != nil {
 		logger.Get(ctx).Infof("error saving analytics opt (tried to record opt: '%s')", state.AnalyticsUserOpt)
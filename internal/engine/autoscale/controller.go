@@ -0,0 +1,243 @@
+package autoscale
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// Pauses the HorizontalPodAutoscalers and KEDA ScaledObjects that scale the
+// workloads of resources with k8s_resource(pause_autoscaling=True), so that
+// autoscaling doesn't churn the pods that Tilt live-updates and streams logs from.
+//
+// Each autoscaler keeps the settings we overwrote in an annotation
+// (see k8s.PauseAutoscalerPatch). We restore them when Tilt exits, and
+// `tilt down` restores any that we missed.
+type Controller struct {
+	kCli k8s.Client
+
+	// TearDown doesn't get a context with a logger.
+	logger logger.Logger
+
+	mu        sync.Mutex
+	resources map[model.ManifestName]*resource
+}
+
+// The workloads a resource deployed, and the autoscalers we paused for them.
+type resource struct {
+	lastDeploy time.Time
+	workloads  []v1.ObjectReference
+	paused     []v1.ObjectReference
+}
+
+var _ store.SubscriberLifecycle = &Controller{}
+
+func NewController(kCli k8s.Client) *Controller {
+	return &Controller{
+		kCli:      kCli,
+		resources: make(map[model.ManifestName]*resource),
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	c.logger = logger.Get(ctx)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	toPause := make(map[model.ManifestName][]v1.ObjectReference)
+	var toResume []v1.ObjectReference
+
+	state := st.RLockState()
+	c.mu.Lock()
+	current := make(map[model.ManifestName]bool)
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() || !mt.Manifest.K8sTarget().PauseAutoscaling {
+			continue
+		}
+		mn := mt.Manifest.Name
+		current[mn] = true
+
+		r, ok := c.resources[mn]
+		if !ok {
+			r = &resource{}
+			c.resources[mn] = r
+		}
+
+		// Every deploy re-applies the workload, and maybe the autoscaler
+		// too, so check the autoscalers again.
+		lastBuild := mt.State.LastBuild()
+		if lastBuild.Error != nil || !lastBuild.FinishTime.After(r.lastDeploy) {
+			continue
+		}
+		r.lastDeploy = lastBuild.FinishTime
+		r.workloads = deployedWorkloads(mt)
+		if len(r.workloads) > 0 {
+			toPause[mn] = append([]v1.ObjectReference{}, r.workloads...)
+		}
+	}
+
+	// Resume the autoscalers of resources that don't want them paused anymore.
+	for mn, r := range c.resources {
+		if !current[mn] {
+			toResume = append(toResume, r.paused...)
+			delete(c.resources, mn)
+		}
+	}
+	c.mu.Unlock()
+	st.RUnlockState()
+
+	for mn, workloads := range toPause {
+		c.pause(ctx, st, mn, workloads)
+	}
+	for _, ref := range toResume {
+		c.resume(ctx, ref)
+	}
+}
+
+func deployedWorkloads(mt *store.ManifestTarget) []v1.ObjectReference {
+	var result []v1.ObjectReference
+	for _, bs := range mt.State.BuildStatuses {
+		lastResult, ok := bs.LastResult.(store.K8sBuildResult)
+		if !ok {
+			continue
+		}
+		for _, ref := range lastResult.DeployedRefs {
+			if k8s.IsScalableKind(ref.Kind) {
+				result = append(result, ref)
+			}
+		}
+	}
+	return result
+}
+
+// Pauses every autoscaler that scales one of the workloads.
+func (c *Controller) pause(ctx context.Context, st store.RStore, mn model.ManifestName, workloads []v1.ObjectReference) {
+	var paused []v1.ObjectReference
+	for ns, refs := range workloadsByNamespace(workloads) {
+		autoscalers, err := c.kCli.ListAutoscalers(ctx, k8s.Namespace(ns))
+		if err != nil {
+			c.notify(st, mn, logger.WarnLvl, fmt.Sprintf("Pausing autoscalers of %s: %v\n", mn, err))
+			continue
+		}
+
+		for _, a := range autoscalers {
+			kind, name, ok := a.AutoscalerTarget()
+			if !ok || !refs[workloadKey{kind: kind, name: name}] {
+				continue
+			}
+
+			patch, err := k8s.PauseAutoscalerPatch(a)
+			if err != nil {
+				c.notify(st, mn, logger.WarnLvl, fmt.Sprintf("Pausing %s %s: %v\n", a.GVK().Kind, a.Name(), err))
+				continue
+			}
+
+			ref := a.ToObjectReference()
+			if patch != nil {
+				err = c.kCli.MergePatch(ctx, ref, patch)
+				if err != nil {
+					c.notify(st, mn, logger.WarnLvl, fmt.Sprintf("Pausing %s %s: %v\n", a.GVK().Kind, a.Name(), err))
+					continue
+				}
+				c.notify(st, mn, logger.InfoLvl, fmt.Sprintf(
+					"Paused %s %s at 1 replica while Tilt is running (k8s_resource(pause_autoscaling=True))\n",
+					a.GVK().Kind, a.Name()))
+			}
+			paused = append(paused, ref)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.resources[mn]
+	if !ok {
+		return
+	}
+	for _, ref := range paused {
+		if !containsRef(r.paused, ref) {
+			r.paused = append(r.paused, ref)
+		}
+	}
+}
+
+func (c *Controller) resume(ctx context.Context, ref v1.ObjectReference) {
+	l := logger.Get(ctx)
+	a, err := c.kCli.GetByReference(ctx, ref)
+	if err != nil {
+		l.Debugf("Resuming %s %s: %v", ref.Kind, ref.Name, err)
+		return
+	}
+
+	patch, err := k8s.ResumeAutoscalerPatch(a)
+	if err != nil || patch == nil {
+		if err != nil {
+			l.Debugf("Resuming %s %s: %v", ref.Kind, ref.Name, err)
+		}
+		return
+	}
+
+	err = c.kCli.MergePatch(ctx, ref, patch)
+	if err != nil {
+		l.Debugf("Resuming %s %s: %v", ref.Kind, ref.Name, err)
+	}
+}
+
+// Puts the autoscalers back the way they were.
+func (c *Controller) TearDown(ctx context.Context) {
+	if c.logger != nil {
+		ctx = logger.WithLogger(ctx, c.logger)
+	}
+
+	c.mu.Lock()
+	resources := c.resources
+	c.resources = make(map[model.ManifestName]*resource)
+	c.mu.Unlock()
+
+	for _, r := range resources {
+		for _, ref := range r.paused {
+			c.resume(ctx, ref)
+		}
+	}
+}
+
+type workloadKey struct {
+	kind string
+	name string
+}
+
+func workloadsByNamespace(workloads []v1.ObjectReference) map[string]map[workloadKey]bool {
+	result := make(map[string]map[workloadKey]bool)
+	for _, ref := range workloads {
+		if result[ref.Namespace] == nil {
+			result[ref.Namespace] = make(map[workloadKey]bool)
+		}
+		result[ref.Namespace][workloadKey{kind: ref.Kind, name: ref.Name}] = true
+	}
+	return result
+}
+
+func (c *Controller) notify(st store.RStore, mn model.ManifestName, level logger.Level, msg string) {
+	st.Dispatch(store.NewLogAction(mn, spanIDForManifest(mn), level, nil, []byte(msg)))
+}
+
+func spanIDForManifest(mn model.ManifestName) logstore.SpanID {
+	return logstore.SpanID(fmt.Sprintf("autoscale:%s", mn))
+}
+
+func containsRef(refs []v1.ObjectReference, ref v1.ObjectReference) bool {
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Namespace == ref.Namespace && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
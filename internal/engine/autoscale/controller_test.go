@@ -0,0 +1,144 @@
+package autoscale
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPauseAutoscalerOfDeployedWorkload(t *testing.T) {
+	f := newFixture(t)
+	f.kCli.Autoscalers = []k8s.K8sEntity{
+		newHPA("fe-hpa", "fe"),
+		newHPA("be-hpa", "be"),
+	}
+
+	f.deploy(true, time.Unix(1600000000, 0))
+	f.onChange()
+
+	require.Len(t, f.kCli.MergePatchCalls, 1)
+	assert.Equal(t, "fe-hpa", f.kCli.MergePatchCalls[0].Ref.Name)
+	assert.Contains(t, string(f.kCli.MergePatchCalls[0].Patch), `"maxReplicas":1`)
+	f.assertLog("Paused HorizontalPodAutoscaler fe-hpa at 1 replica")
+
+	// Nothing to do until the next deploy.
+	f.onChange()
+	assert.Len(t, f.kCli.MergePatchCalls, 1)
+
+	f.deploy(true, time.Unix(1600000100, 0))
+	f.onChange()
+	assert.Len(t, f.kCli.MergePatchCalls, 2)
+}
+
+func TestDontPauseWithoutOption(t *testing.T) {
+	f := newFixture(t)
+	f.kCli.Autoscalers = []k8s.K8sEntity{newHPA("fe-hpa", "fe")}
+
+	f.deploy(false, time.Unix(1600000000, 0))
+	f.onChange()
+
+	assert.Empty(t, f.kCli.MergePatchCalls)
+}
+
+func TestResumeAutoscalerOnTearDown(t *testing.T) {
+	f := newFixture(t)
+	f.kCli.Autoscalers = []k8s.K8sEntity{newHPA("fe-hpa", "fe")}
+
+	f.deploy(true, time.Unix(1600000000, 0))
+	f.onChange()
+
+	paused := newHPA("fe-hpa", "fe")
+	paused.Obj.(*unstructured.Unstructured).SetAnnotations(map[string]string{
+		k8s.PausedAutoscalingAnnotation: `{"minReplicas":2,"maxReplicas":10}`,
+	})
+	f.kCli.InjectEntityByName(paused)
+
+	f.c.TearDown(context.Background())
+
+	require.Len(t, f.kCli.MergePatchCalls, 2)
+	assert.JSONEq(t, `{
+"metadata":{"annotations":{"tilt.dev/paused-autoscaling":null}},
+"spec":{"minReplicas":2,"maxReplicas":10}}`, string(f.kCli.MergePatchCalls[1].Patch))
+}
+
+type fixture struct {
+	t    *testing.T
+	ctx  context.Context
+	kCli *k8s.FakeK8sClient
+	st   *store.TestingStore
+	c    *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	kCli := k8s.NewFakeK8sClient()
+	c := NewController(kCli)
+	c.SetUp(ctx)
+	return &fixture{
+		t:    t,
+		ctx:  ctx,
+		kCli: kCli,
+		st:   store.NewTestingStore(),
+		c:    c,
+	}
+}
+
+func (f *fixture) deploy(pauseAutoscaling bool, finishTime time.Time) {
+	f.st.WithState(func(state *store.EngineState) {
+		kTarget := model.K8sTarget{Name: "fe", PauseAutoscaling: pauseAutoscaling}
+		m := model.Manifest{Name: "fe"}.WithDeployTarget(kTarget)
+		mt := store.NewManifestTarget(m)
+		mt.State.MutableBuildStatus(kTarget.ID()).LastResult = store.K8sBuildResult{
+			DeployedRefs: []v1.ObjectReference{
+				{Kind: "Deployment", APIVersion: "apps/v1", Name: "fe", Namespace: "default"},
+				{Kind: "Service", APIVersion: "v1", Name: "fe", Namespace: "default"},
+			},
+		}
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: finishTime, FinishTime: finishTime})
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) assertLog(expected string) {
+	var logs []string
+	for _, action := range f.st.Actions() {
+		la, ok := action.(store.LogAction)
+		if !ok {
+			continue
+		}
+		if strings.Contains(string(la.Message()), expected) {
+			assert.Equal(f.t, model.ManifestName("fe"), la.ManifestName())
+			return
+		}
+		logs = append(logs, string(la.Message()))
+	}
+	f.t.Errorf("Expected log %q. Actual: %v", expected, logs)
+}
+
+func newHPA(name, target string) k8s.K8sEntity {
+	return k8s.NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{
+			"minReplicas":    int64(2),
+			"maxReplicas":    int64(10),
+			"scaleTargetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+		},
+	}})
+}
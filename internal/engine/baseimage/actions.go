@@ -0,0 +1,14 @@
+package baseimage
+
+import (
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The results of checking every image's base images against the registry.
+type CheckAction struct {
+	// The base images that have a newer version in the registry,
+	// keyed by the image target that uses them.
+	Stale map[model.TargetID][]string
+}
+
+func (CheckAction) Action() {}
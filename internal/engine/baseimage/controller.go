@@ -0,0 +1,271 @@
+package baseimage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Wait a bit before the first check, so that we don't
+// slow down the first builds with registry requests.
+const initialDelay = time.Minute
+
+// Periodically asks the registry whether the images in each Dockerfile's
+// FROM lines have changed, so that a session that runs for weeks doesn't
+// keep building on a stale base image.
+//
+// When a base image is updated, we raise an alert on each resource that
+// builds on it. Triggering the resource rebuilds its images and pulls the
+// new base image.
+type Controller struct {
+	sched *scheduler.Scheduler
+	dCli  docker.Client
+
+	mu       sync.Mutex
+	st       store.RStore
+	settings model.BaseImageCheckSettings
+	targets  []imageTarget
+
+	// The alerts we've raised, so that we can resolve them.
+	alerts map[string]bool
+
+	// The base images that were stale at the last update, and the registry
+	// digest of each the last time we checked it.
+	stale  map[string]bool
+	latest map[string]digest.Digest
+
+	// The registry digest that we last rebuilt each base image on. The builds
+	// pull the new base image, but the local tag doesn't always move with it
+	// (e.g., with BuildKit), so we can't rely on the local image alone.
+	builtOn map[string]digest.Digest
+
+	// Stops the periodic check job, if it's running.
+	cancel func()
+}
+
+var _ store.Subscriber = &Controller{}
+
+type imageTarget struct {
+	id         model.TargetID
+	baseImages []reference.Named
+}
+
+func NewController(sched *scheduler.Scheduler, dCli docker.Client) *Controller {
+	return &Controller{
+		sched:   sched,
+		dCli:    dCli,
+		alerts:  make(map[string]bool),
+		stale:   make(map[string]bool),
+		latest:  make(map[string]digest.Digest),
+		builtOn: make(map[string]digest.Digest),
+	}
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	settings := state.BaseImageCheckSettings
+	var targets []imageTarget
+	seen := make(map[model.TargetID]bool)
+	alerts := make(map[string]model.Alert)
+	stale := make(map[string]bool)
+	for _, mt := range state.Targets() {
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			for _, ref := range mt.State.StaleBaseImages[iTarget.ID()] {
+				stale[ref] = true
+				alert := staleAlert(mt.Manifest.Name, iTarget, ref)
+				alerts[alert.ID] = alert
+			}
+
+			if seen[iTarget.ID()] {
+				continue
+			}
+			seen[iTarget.ID()] = true
+			baseImages := findBaseImages(iTarget, mt.Manifest.ImageTargets)
+			if len(baseImages) > 0 {
+				targets = append(targets, imageTarget{id: iTarget.ID(), baseImages: baseImages})
+			}
+		}
+	}
+	st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.st = st
+	c.targets = targets
+
+	for ref := range c.stale {
+		if !stale[ref] && c.latest[ref] != "" {
+			c.builtOn[ref] = c.latest[ref]
+		}
+	}
+	c.stale = stale
+
+	for id := range c.alerts {
+		if _, ok := alerts[id]; !ok {
+			delete(c.alerts, id)
+			st.Dispatch(store.AlertResolvedAction{ID: id})
+		}
+	}
+	for id, alert := range alerts {
+		if !c.alerts[id] {
+			c.alerts[id] = true
+			st.Dispatch(store.AlertAction{Alert: alert})
+		}
+	}
+
+	old := c.settings
+	c.settings = settings
+	if old.Enabled == settings.Enabled && old.Interval == settings.Interval {
+		return
+	}
+
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	if !settings.Enabled {
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.sched.Every(jobCtx, "base-image-check", initialDelay, settings.Interval, c.check)
+}
+
+// Checks every base image against the registry, and reports the stale ones.
+func (c *Controller) check(ctx context.Context) {
+	c.mu.Lock()
+	st := c.st
+	targets := append([]imageTarget{}, c.targets...)
+	c.mu.Unlock()
+
+	if st == nil {
+		return
+	}
+
+	// Several images often build on the same base image, so only check each one once.
+	results := make(map[string]bool)
+	result := make(map[model.TargetID][]string)
+	for _, target := range targets {
+		for _, ref := range target.baseImages {
+			name := container.FamiliarString(ref)
+			isStale, ok := results[name]
+			if !ok {
+				isStale = c.isStale(ctx, ref)
+				results[name] = isStale
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if isStale {
+				result[target.id] = append(result[target.id], name)
+			}
+		}
+	}
+
+	st.Dispatch(CheckAction{Stale: result})
+}
+
+// Whether the registry has a different version of the base image than the one we build on.
+//
+// If we can't reach the registry, or haven't pulled the image yet, we assume it's fine.
+func (c *Controller) isStale(ctx context.Context, ref reference.Named) bool {
+	name := container.FamiliarString(ref)
+	latest, err := c.dCli.ImageRegistryDigest(ctx, ref)
+	if err != nil {
+		logger.Get(ctx).Debugf("Checking base image %s: %v", name, err)
+		return false
+	}
+
+	c.mu.Lock()
+	c.latest[name] = latest
+	builtOn := c.builtOn[name]
+	c.mu.Unlock()
+	if builtOn == latest {
+		return false
+	}
+
+	inspect, _, err := c.dCli.ImageInspectWithRaw(ctx, reference.TagNameOnly(ref).String())
+	if err != nil {
+		return false
+	}
+
+	for _, repoDigest := range inspect.RepoDigests {
+		if strings.HasSuffix(repoDigest, "@"+latest.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// The images in the Dockerfile's FROM lines that come from a registry.
+//
+// FROM lines that refer to an earlier build stage aren't base images.
+//
+// Skips images pinned to a digest (which can't change) and
+// images that the Tiltfile builds itself.
+func findBaseImages(iTarget model.ImageTarget, siblings []model.ImageTarget) []reference.Named {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	if !ok {
+		return nil
+	}
+
+	refs, err := dockerfile.Dockerfile(db.Dockerfile).FindBaseImages()
+	if err != nil {
+		return nil
+	}
+
+	var result []reference.Named
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if _, ok := ref.(reference.Canonical); ok {
+			continue
+		}
+		if container.FamiliarString(ref) == "scratch" || isBuiltByTilt(ref, siblings) {
+			continue
+		}
+		name := container.FamiliarString(ref)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, ref)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result
+}
+
+func isBuiltByTilt(ref reference.Named, iTargets []model.ImageTarget) bool {
+	for _, iTarget := range iTargets {
+		if iTarget.Refs.ConfigurationRef.Matches(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func staleAlert(mn model.ManifestName, iTarget model.ImageTarget, ref string) model.Alert {
+	image := container.FamiliarString(iTarget.Refs.ConfigurationRef)
+	return model.Alert{
+		ID:           fmt.Sprintf("base-image:%s:%s:%s", mn, image, ref),
+		Source:       model.AlertSourceBaseImage,
+		Severity:     model.AlertSeverityWarning,
+		ManifestName: mn,
+		Message: fmt.Sprintf("Base image %s was updated in the registry. Trigger an update of %s to rebuild %s on it.",
+			ref, mn, image),
+	}
+}
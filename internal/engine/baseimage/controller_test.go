@@ -0,0 +1,231 @@
+package baseimage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jonboulle/clockwork"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	oldDigest = digest.Digest("sha256:1111111111111111111111111111111111111111111111111111111111111111")
+	newDigest = digest.Digest("sha256:2222222222222222222222222222222222222222222222222222222222222222")
+)
+
+func TestBaseImageUpToDate(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", "FROM golang:1.15")
+	f.setLocal("golang:1.15", oldDigest)
+	f.setRegistry("golang:1.15", oldDigest)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	assert.Empty(t, f.lastCheck().Stale)
+}
+
+func TestBaseImageUpdated(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", "FROM golang:1.15")
+	f.setLocal("golang:1.15", oldDigest)
+	f.setRegistry("golang:1.15", newDigest)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	assert.Equal(t, map[model.TargetID][]string{
+		f.imageID("gcr.io/fe"): {"golang:1.15"},
+	}, f.lastCheck().Stale)
+}
+
+func TestBaseImageNotPulledYet(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", "FROM golang:1.15")
+	f.setRegistry("golang:1.15", newDigest)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	assert.Empty(t, f.lastCheck().Stale)
+}
+
+func TestBaseImageSkipsStagesAndPinnedImages(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", `
+FROM golang:1.15 AS builder
+FROM builder
+FROM alpine@`+oldDigest.String()+`
+`)
+	f.setLocal("golang:1.15", oldDigest)
+	f.setRegistry("golang:1.15", oldDigest)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	assert.Empty(t, f.lastCheck().Stale)
+	assert.Equal(t, 1, f.dCli.RegistryLookupCount)
+}
+
+func TestBaseImageAlertRaisedAndResolved(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", "FROM golang:1.15")
+	f.setStale("fe", "gcr.io/fe", "golang:1.15")
+	f.onChange()
+
+	alert := f.st.WaitForAction(t, reflectAlertAction).(store.AlertAction).Alert
+	assert.Equal(t, model.AlertSourceBaseImage, alert.Source)
+	assert.Equal(t, model.ManifestName("fe"), alert.ManifestName)
+	assert.Contains(t, alert.Message, "Base image golang:1.15 was updated in the registry")
+	assert.Contains(t, alert.Message, "Trigger an update of fe")
+
+	// Raising the same alert again would count it as a repeat.
+	f.st.ClearActions()
+	f.onChange()
+	assert.Empty(t, f.st.Actions())
+
+	f.setStale("fe", "gcr.io/fe")
+	f.onChange()
+	resolved := f.st.WaitForAction(t, reflectAlertResolvedAction).(store.AlertResolvedAction)
+	assert.Equal(t, alert.ID, resolved.ID)
+}
+
+// BuildKit pulls the new base image without moving the local tag,
+// so after the rebuild, we trust the digest we rebuilt on.
+func TestBaseImageRebuiltOnNewDigest(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", "gcr.io/fe", "FROM golang:1.15")
+	f.setLocal("golang:1.15", oldDigest)
+	f.setRegistry("golang:1.15", newDigest)
+	f.onChange()
+	f.c.check(f.ctx)
+	require.NotEmpty(t, f.lastCheck().Stale)
+
+	f.setStale("fe", "gcr.io/fe", "golang:1.15")
+	f.onChange()
+	f.setStale("fe", "gcr.io/fe")
+	f.onChange()
+
+	f.st.ClearActions()
+	f.c.check(f.ctx)
+	assert.Empty(t, f.lastCheck().Stale)
+}
+
+func TestBaseImageScheduledOnlyWhenEnabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.BaseImageCheckSettings.Enabled = false
+	})
+	f.onChange()
+	assert.Empty(t, f.sched.JobNames())
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.BaseImageCheckSettings.Enabled = true
+	})
+	f.onChange()
+	assert.Equal(t, []string{"base-image-check"}, f.sched.JobNames())
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	st     *store.TestingStore
+	sched  *scheduler.Scheduler
+	dCli   *docker.FakeClient
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	sched := scheduler.NewScheduler(clockwork.NewFakeClock())
+	dCli := docker.NewFakeClient()
+	dCli.RegistryDigests = make(map[string]digest.Digest)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		st:     store.NewTestingStore(),
+		sched:  sched,
+		dCli:   dCli,
+		c:      NewController(sched, dCli),
+	}
+}
+
+func (f *fixture) TearDown() {
+	f.cancel()
+}
+
+func (f *fixture) imageID(ref string) model.TargetID {
+	return model.ImageID(container.MustParseSelector(ref))
+}
+
+func (f *fixture) addResource(name model.ManifestName, ref string, df string) {
+	iTarget := model.MustNewImageTarget(container.MustParseSelector(ref)).
+		WithBuildDetails(model.DockerBuild{Dockerfile: df})
+	m := model.Manifest{Name: name}.WithImageTarget(iTarget)
+	f.st.WithState(func(state *store.EngineState) {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	})
+}
+
+func (f *fixture) setStale(name model.ManifestName, ref string, baseImages ...string) {
+	f.st.WithState(func(state *store.EngineState) {
+		ms, ok := state.ManifestState(name)
+		require.True(f.t, ok)
+		ms.StaleBaseImages = nil
+		if len(baseImages) > 0 {
+			ms.StaleBaseImages = map[model.TargetID][]string{f.imageID(ref): baseImages}
+		}
+	})
+}
+
+func (f *fixture) setLocal(ref string, d digest.Digest) {
+	named := container.MustParseNamed(ref)
+	f.dCli.Images[named.String()] = types.ImageInspect{
+		RepoDigests: []string{named.Name() + "@" + d.String()},
+	}
+}
+
+func (f *fixture) setRegistry(ref string, d digest.Digest) {
+	f.dCli.RegistryDigests[container.MustParseNamed(ref).String()] = d
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) lastCheck() CheckAction {
+	actions := f.st.Actions()
+	for i := len(actions) - 1; i >= 0; i-- {
+		a, ok := actions[i].(CheckAction)
+		if ok {
+			return a
+		}
+	}
+	f.t.Fatalf("No base image check")
+	return CheckAction{}
+}
+
+var reflectAlertAction = reflect.TypeOf(store.AlertAction{})
+var reflectAlertResolvedAction = reflect.TypeOf(store.AlertResolvedAction{})
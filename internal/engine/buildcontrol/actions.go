@@ -37,3 +37,12 @@ func NewBuildCompleteAction(mn model.ManifestName, spanID logstore.SpanID, resul
 		Error:        err,
 	}
 }
+
+// A build leaked credentials. Scrub them from the logs, starting at the
+// checkpoint where the build began.
+type CredentialsDetectedAction struct {
+	Secrets    model.SecretSet
+	Checkpoint logstore.Checkpoint
+}
+
+func (CredentialsDetectedAction) Action() {}
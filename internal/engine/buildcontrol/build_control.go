@@ -3,7 +3,6 @@ package buildcontrol
 import (
 	"time"
 
-	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -30,6 +29,9 @@ func NextTargetToBuild(state store.EngineState) (*store.ManifestTarget, HoldSet)
 		return nil, holds
 	}
 
+	// Resources whose config didn't load don't build until the Tiltfile fixes them.
+	HoldTargetsWithConfigErrors(targets, holds)
+
 	if IsBuildingAnything(state) {
 		// If we're building a target already, remove anything that's not parallelizable
 		// with what's currently building.
@@ -47,9 +49,18 @@ func NextTargetToBuild(state store.EngineState) (*store.ManifestTarget, HoldSet)
 
 	HoldTargetsWithBuildingComponents(targets, holds)
 	HoldTargetsWaitingOnDependencies(state, targets, holds)
+	HoldTargetsInGitCheckout(state, targets, holds)
 
-	// If any of the manifest targets haven't been built yet, build them now.
 	targets = holds.RemoveIneligibleTargets(targets)
+
+	// Builds that the user triggered manually go first, so that the resource
+	// they're working on doesn't wait behind automatic builds.
+	triggered := NextTriggeredTarget(state.TriggerQueue, targets)
+	if triggered != nil {
+		return triggered, holds
+	}
+
+	// If any of the manifest targets haven't been built yet, build them now.
 	unbuilt := FindTargetsNeedingInitialBuild(targets)
 
 	if len(unbuilt) > 0 {
@@ -57,19 +68,14 @@ func NextTargetToBuild(state store.EngineState) (*store.ManifestTarget, HoldSet)
 	}
 
 	// Next prioritize builds that crashed and need a rebuilt to have up-to-date code.
+	var crashed *store.ManifestTarget
 	for _, mt := range targets {
-		if mt.State.NeedsRebuildFromCrash {
-			return mt, holds
+		if mt.State.NeedsRebuildFromCrash && isHigherPriority(mt, crashed) {
+			crashed = mt
 		}
 	}
-
-	// Next prioritize builds that have been manually triggered.
-	if len(state.TriggerQueue) > 0 {
-		mn := state.TriggerQueue[0]
-		mt, ok := state.ManifestTargets[mn]
-		if ok {
-			return mt, holds
-		}
+	if crashed != nil {
+		return crashed, holds
 	}
 
 	// Check to see if any targets
@@ -88,6 +94,32 @@ func NextTargetToBuild(state store.EngineState) (*store.ManifestTarget, HoldSet)
 	return EarliestPendingAutoTriggerTarget(targets), holds
 }
 
+// The triggered target with the highest priority. Among targets with the same
+// priority, the one that was triggered first.
+//
+// Only considers targets that are eligible to build.
+func NextTriggeredTarget(queue []model.ManifestName, targets []*store.ManifestTarget) *store.ManifestTarget {
+	eligible := make(map[model.ManifestName]*store.ManifestTarget, len(targets))
+	for _, mt := range targets {
+		eligible[mt.Manifest.Name] = mt
+	}
+
+	var choice *store.ManifestTarget
+	for _, mn := range queue {
+		mt, ok := eligible[mn]
+		if ok && isHigherPriority(mt, choice) {
+			choice = mt
+		}
+	}
+	return choice
+}
+
+// Whether mt should build before the current choice.
+// On a tie, we keep the current choice.
+func isHigherPriority(mt *store.ManifestTarget, choice *store.ManifestTarget) bool {
+	return choice == nil || mt.Manifest.BuildPriority > choice.Manifest.BuildPriority
+}
+
 func NextManifestNameToBuild(state store.EngineState) model.ManifestName {
 	mt, _ := NextTargetToBuild(state)
 	if mt == nil {
@@ -191,6 +223,24 @@ func HoldTargetsWithBuildingComponents(mts []*store.ManifestTarget, holds HoldSe
 	}
 }
 
+// While git is rewriting files in a repo, building anything
+// from the repo would build a mix of the old and new branch.
+func HoldTargetsInGitCheckout(state store.EngineState, mts []*store.ManifestTarget, holds HoldSet) {
+	for _, mt := range mts {
+		if state.IsInGitCheckout(mt.Manifest) {
+			holds.AddHold(mt, store.HoldGitCheckout)
+		}
+	}
+}
+
+func HoldTargetsWithConfigErrors(mts []*store.ManifestTarget, holds HoldSet) {
+	for _, mt := range mts {
+		if mt.Manifest.ConfigError != "" {
+			holds.AddHold(mt, store.HoldConfigError)
+		}
+	}
+}
+
 func HoldTargetsWaitingOnDependencies(state store.EngineState, mts []*store.ManifestTarget, holds HoldSet) {
 	for _, mt := range mts {
 		if isWaitingOnDependencies(state, mt) {
@@ -306,24 +356,29 @@ func IsBuildingUncategorizedYAML(state store.EngineState) bool {
 // 1) all pending file changes
 // 2) all pending dependency changes (where an image has been rebuilt by another manifest), and
 // 3) all pending manifest changes
-// The earliest one is the one we want.
+// Of the targets with the highest priority, the earliest one is the one we want.
 //
 // If no targets are pending, return nil
 func EarliestPendingAutoTriggerTarget(targets []*store.ManifestTarget) *store.ManifestTarget {
 	var choice *store.ManifestTarget
-	earliest := time.Now()
+	var earliest time.Time
 
 	for _, mt := range targets {
-		ok, newTime := mt.State.HasPendingChangesBeforeOrEqual(earliest)
+		ok, newTime := mt.State.HasPendingChanges()
 		if ok {
 			if !mt.Manifest.TriggerMode.AutoOnChange() {
 				// Don't trigger update of a manual manifest just b/c if has
 				// pending changes; must come through the TriggerQueue, above.
 				continue
 			}
-			if choice != nil && newTime.Equal(earliest) {
-				// If two choices are equal, use the first one in target order.
-				continue
+			if choice != nil {
+				if mt.Manifest.BuildPriority < choice.Manifest.BuildPriority {
+					continue
+				}
+				if mt.Manifest.BuildPriority == choice.Manifest.BuildPriority && !newTime.Before(earliest) {
+					// If two choices are equal, use the first one in target order.
+					continue
+				}
 			}
 			choice = mt
 			earliest = newTime
@@ -374,22 +429,15 @@ func IsLiveUpdateTargetWaitingOnDeploy(state store.EngineState, mt *store.Manife
 		}
 
 		// We have an image target with changes!
-		// First, make sure that all the changes match a sync.
+		// First, make sure that all the changes can be live-updated.
 		files := make([]string, 0, len(status.PendingFileChanges))
 		for f := range status.PendingFileChanges {
 			files = append(files, f)
 		}
 
 		iTarget := mt.Manifest.ImageTargetWithID(id)
-		luInfo := iTarget.LiveUpdateInfo()
-		_, pathsMatchingNoSync, err := build.FilesToPathMappings(files, luInfo.SyncSteps())
-		if err != nil || len(pathsMatchingNoSync) > 0 {
-			return false
-		}
-
-		// If any changed files match a FallBackOn file, fall back to next BuildAndDeployer
-		anyMatch, _, err := luInfo.FallBackOnFiles().AnyMatch(files)
-		if err != nil || anyMatch {
+		_, err := LiveUpdateChanges(iTarget, files)
+		if err != nil {
 			return false
 		}
 
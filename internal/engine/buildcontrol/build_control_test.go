@@ -265,6 +265,105 @@ func TestHoldForDeploy(t *testing.T) {
 	f.assertNextTargetToBuild("sancho")
 }
 
+func TestHoldGitCheckout(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	repo := model.LocalGitRepo{LocalPath: f.Path()}
+	sanchoImage := model.MustNewImageTarget(container.MustParseSelector("sancho")).
+		WithRepos([]model.LocalGitRepo{repo})
+	sancho := f.upsertManifest(manifestbuilder.New(f, "sancho").
+		WithImageTargets(sanchoImage).
+		WithK8sYAML(testyaml.SanchoYAML).
+		Build())
+	sancho.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+	})
+
+	status := sancho.State.MutableBuildStatus(sanchoImage.ID())
+	status.PendingFileChanges[f.JoinPath("main.go")] = time.Now()
+	f.st.GitCheckouts[repo.LocalPath] = store.GitCheckout{Repo: repo, StartTime: time.Now()}
+	f.assertNoTargetNextToBuild()
+	f.assertHold("sancho", store.HoldGitCheckout)
+
+	delete(f.st.GitCheckouts, repo.LocalPath)
+	f.assertNextTargetToBuild("sancho")
+}
+
+func TestHoldConfigError(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	broken := f.upsertLocalManifest("broken")
+	broken.Manifest = broken.Manifest.WithConfigError("resource broken specified a dependency on itself")
+	f.upsertLocalManifest("fine")
+
+	f.assertNextTargetToBuild("fine")
+	f.assertHold("broken", store.HoldConfigError)
+}
+
+func TestTriggeredTargetPreemptsQueuedBuilds(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	f.upsertK8sManifest("unbuilt")
+	f.st.UpsertManifestTarget(f.manifestNeedingCrashRebuild())
+	triggered := f.upsertK8sManifest("triggered")
+	triggered.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+	})
+	triggered.State.TriggerReason = model.BuildReasonFlagTriggerWeb
+	f.st.TriggerQueue = append(f.st.TriggerQueue, "triggered")
+
+	f.assertNextTargetToBuild("triggered")
+}
+
+func TestTriggeredTargetsByPriority(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	for _, name := range []model.ManifestName{"low", "normal1", "high", "normal2"} {
+		mt := f.upsertK8sManifest(name)
+		mt.State.AddCompletedBuild(model.BuildRecord{
+			StartTime:  time.Now(),
+			FinishTime: time.Now(),
+		})
+		mt.State.TriggerReason = model.BuildReasonFlagTriggerWeb
+		f.st.TriggerQueue = append(f.st.TriggerQueue, name)
+	}
+	f.st.ManifestTargets["low"].Manifest.BuildPriority = model.BuildPriorityLow
+	f.st.ManifestTargets["high"].Manifest.BuildPriority = model.BuildPriorityHigh
+
+	f.assertNextTargetToBuild("high")
+
+	f.st.TriggerQueue = []model.ManifestName{"low", "normal1", "normal2"}
+	f.assertNextTargetToBuild("normal1")
+
+	f.st.TriggerQueue = []model.ManifestName{"low"}
+	f.assertNextTargetToBuild("low")
+}
+
+func TestPendingChangesByPriority(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	earlier := f.upsertLocalManifest("earlier", withLocalAllowParallel)
+	later := f.upsertLocalManifest("later", withLocalAllowParallel)
+	for i, mt := range []*store.ManifestTarget{earlier, later} {
+		mt.State.AddCompletedBuild(model.BuildRecord{
+			StartTime:  time.Now(),
+			FinishTime: time.Now(),
+		})
+		mt.State.PendingManifestChange = time.Now().Add(time.Duration(i) * time.Second)
+	}
+	f.assertNextTargetToBuild("earlier")
+
+	later.Manifest.BuildPriority = model.BuildPriorityHigh
+	f.assertNextTargetToBuild("later")
+}
+
 func successPod(podID k8s.PodID, ref reference.Named) *store.Pod {
 	return &store.Pod{
 		PodID:  podID,
@@ -363,6 +462,9 @@ func withResourceDeps(deps ...string) manifestOption {
 		return m.WithResourceDeps(deps...)
 	})
 }
+func withLocalAllowParallel(m manifestbuilder.ManifestBuilder) manifestbuilder.ManifestBuilder {
+	return m.WithLocalAllowParallel(true)
+}
 func withK8sPodReadiness(pr model.PodReadinessMode) manifestOption {
 	return manifestOption(func(m manifestbuilder.ManifestBuilder) manifestbuilder.ManifestBuilder {
 		return m.WithK8sPodReadiness(pr)
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // Nothing is on fire, this is an expected case like a container builder being
@@ -37,6 +38,8 @@ func RedirectToNextBuilderInfof(msg string, a ...interface{}) RedirectToNextBuil
 	return RedirectToNextBuilder{fmt.Errorf(msg, a...), logger.InfoLvl}
 }
 
+func (redir RedirectToNextBuilder) Unwrap() error { return redir.error }
+
 var _ error = RedirectToNextBuilder{}
 
 // Something is wrong enough that we shouldn't bother falling back to other
@@ -61,8 +64,34 @@ func IsDontFallBackError(err error) bool {
 	return ok
 }
 
+func (e DontFallBackError) Unwrap() error { return e.error }
+
 var _ error = DontFallBackError{}
 
+// One of a resource's images failed to build, so none of them were deployed.
+//
+// The images that didn't get a new build have results from before this one,
+// so they need a rebuild next time, rather than getting deployed next to newer
+// builds of their siblings.
+type ImageBuildError struct {
+	error
+
+	// The image that failed.
+	TargetID model.TargetID
+
+	// The images that needed a build but didn't get one, including the one that failed.
+	Unbuilt []model.TargetID
+}
+
+func (e ImageBuildError) Error() string {
+	return fmt.Sprintf("image %s: %v", e.TargetID.Name, e.error)
+}
+
+func (e ImageBuildError) Unwrap() error { return e.error }
+func (e ImageBuildError) Cause() error  { return e.error }
+
+var _ error = ImageBuildError{}
+
 // A permanent error indicates that the whole build pipeline needs to stop.
 // It will never recover, even on subsequent rebuilds.
 func IsFatalError(err error) bool {
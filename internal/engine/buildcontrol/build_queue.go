@@ -0,0 +1,147 @@
+package buildcontrol
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A resource that's waiting to build.
+type QueuedBuild struct {
+	Name model.ManifestName
+
+	// Why it needs to build.
+	Reason model.BuildReason
+
+	// Why it can't build yet, if anything.
+	Hold store.Hold
+
+	// When the earliest change that it hasn't built yet happened.
+	// Zero for initial builds and triggers.
+	PendingSince time.Time
+
+	// Where it is in line, starting at 1. It's only an estimate: holds come
+	// and go, and new changes can jump the line.
+	Position int
+}
+
+// The resources waiting to build, in the order that NextTargetToBuild
+// would pick them if nothing else changed.
+func BuildQueue(state store.EngineState) []QueuedBuild {
+	_, holds := NextTargetToBuild(state)
+
+	targets := state.Targets()
+	order := make(map[model.ManifestName]int, len(targets))
+	for i, mt := range targets {
+		order[mt.Manifest.Name] = i
+	}
+	triggerOrder := make(map[model.ManifestName]int, len(state.TriggerQueue))
+	for i, mn := range state.TriggerQueue {
+		triggerOrder[mn] = i
+	}
+
+	var queued []*store.ManifestTarget
+	for _, mt := range targets {
+		if mt.State.IsBuilding() || mt.NextBuildReason() == model.BuildReasonNone {
+			continue
+		}
+		if queueTier(state, mt) == tierNotQueued {
+			continue
+		}
+		queued = append(queued, mt)
+	}
+
+	sort.SliceStable(queued, func(i, j int) bool {
+		a, b := queued[i], queued[j]
+		aTier, bTier := queueTier(state, a), queueTier(state, b)
+		if aTier != bTier {
+			return aTier < bTier
+		}
+
+		switch aTier {
+		case tierTriggered:
+			if a.Manifest.BuildPriority != b.Manifest.BuildPriority {
+				return a.Manifest.BuildPriority > b.Manifest.BuildPriority
+			}
+			return triggerOrder[a.Manifest.Name] < triggerOrder[b.Manifest.Name]
+		case tierInitial:
+			aRank, bRank := initialBuildRank(a), initialBuildRank(b)
+			if aRank != bRank {
+				return aRank < bRank
+			}
+		case tierCrashed:
+			if a.Manifest.BuildPriority != b.Manifest.BuildPriority {
+				return a.Manifest.BuildPriority > b.Manifest.BuildPriority
+			}
+		case tierChanged:
+			if a.Manifest.BuildPriority != b.Manifest.BuildPriority {
+				return a.Manifest.BuildPriority > b.Manifest.BuildPriority
+			}
+			_, aTime := a.State.HasPendingChanges()
+			_, bTime := b.State.HasPendingChanges()
+			if !aTime.Equal(bTime) {
+				return aTime.Before(bTime)
+			}
+		}
+		return order[a.Manifest.Name] < order[b.Manifest.Name]
+	})
+
+	result := make([]QueuedBuild, 0, len(queued))
+	for i, mt := range queued {
+		_, pendingSince := mt.State.HasPendingChanges()
+		result = append(result, QueuedBuild{
+			Name:         mt.Manifest.Name,
+			Reason:       mt.NextBuildReason(),
+			Hold:         holds[mt.Manifest.Name],
+			PendingSince: pendingSince,
+			Position:     i + 1,
+		})
+	}
+	return result
+}
+
+// The groups that NextTargetToBuild picks from, in order.
+type buildQueueTier int
+
+const (
+	tierTriggered buildQueueTier = iota
+	tierInitial
+	tierCrashed
+	tierChanged
+
+	// Resources with manual trigger mode wait for a trigger, not in the queue.
+	tierNotQueued
+)
+
+func queueTier(state store.EngineState, mt *store.ManifestTarget) buildQueueTier {
+	if state.ManifestInTriggerQueue(mt.Manifest.Name) {
+		return tierTriggered
+	}
+	if !mt.State.StartedFirstBuild() && mt.Manifest.TriggerMode.AutoInitial() {
+		return tierInitial
+	}
+	if mt.State.NeedsRebuildFromCrash {
+		return tierCrashed
+	}
+	if ok, _ := mt.State.HasPendingChanges(); ok && mt.Manifest.TriggerMode.AutoOnChange() {
+		return tierChanged
+	}
+	return tierNotQueued
+}
+
+// Mirrors NextUnbuiltTargetToBuild: local resources, then unresourced YAML,
+// then k8s resources without images, then everything else.
+func initialBuildRank(mt *store.ManifestTarget) int {
+	switch {
+	case mt.Manifest.IsLocal():
+		return 0
+	case mt.Manifest.ManifestName() == model.UnresourcedYAMLManifestName:
+		return 1
+	case mt.Manifest.IsK8s() && len(mt.Manifest.ImageTargets) == 0:
+		return 2
+	default:
+		return 3
+	}
+}
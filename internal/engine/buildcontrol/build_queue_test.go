@@ -0,0 +1,108 @@
+package buildcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBuildQueueInitialBuilds(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	f.upsertK8sManifest("k8s1")
+	f.upsertLocalManifest("local1")
+	f.upsertK8sManifest("k8s2")
+
+	f.assertBuildQueue("local1", "k8s1", "k8s2")
+}
+
+func TestBuildQueueTriggeredFirst(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	f.upsertK8sManifest("unbuilt")
+	f.st.UpsertManifestTarget(f.manifestNeedingCrashRebuild())
+	triggered := f.upsertK8sManifest("triggered")
+	triggered.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+	})
+	triggered.State.TriggerReason = model.BuildReasonFlagTriggerWeb
+	f.st.TriggerQueue = append(f.st.TriggerQueue, "triggered")
+
+	f.assertBuildQueue("triggered", "unbuilt", "needs-crash-rebuild")
+
+	queue := BuildQueue(*f.st)
+	assert.Equal(t, model.BuildReasonFlagTriggerWeb, queue[0].Reason)
+	assert.Equal(t, 1, queue[0].Position)
+	assert.Equal(t, 3, queue[2].Position)
+}
+
+func TestBuildQueuePendingChanges(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	for i, name := range []model.ManifestName{"earlier", "later", "high"} {
+		mt := f.upsertLocalManifest(name, withLocalAllowParallel)
+		mt.State.AddCompletedBuild(model.BuildRecord{
+			StartTime:  now,
+			FinishTime: now,
+		})
+		mt.State.PendingManifestChange = now.Add(time.Duration(i) * time.Second)
+	}
+	f.st.ManifestTargets["high"].Manifest.BuildPriority = model.BuildPriorityHigh
+
+	f.assertBuildQueue("high", "earlier", "later")
+
+	queue := BuildQueue(*f.st)
+	assert.True(t, queue[1].PendingSince.Equal(now))
+}
+
+func TestBuildQueueSkipsBuildingAndManualResources(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	building := f.upsertK8sManifest("building")
+	building.State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+
+	manual := f.upsertLocalManifest("manual")
+	manual.Manifest.TriggerMode = model.TriggerModeManualAfterInitial
+	manual.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+	})
+	manual.State.PendingManifestChange = time.Now()
+
+	f.upsertK8sManifest("waiting")
+
+	f.assertBuildQueue("waiting")
+}
+
+func TestBuildQueueHolds(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	f.upsertK8sManifest("k8s1", withResourceDeps("local1"))
+	f.upsertLocalManifest("local1")
+
+	queue := BuildQueue(*f.st)
+	if assert.Len(t, queue, 2) {
+		assert.Equal(t, store.HoldNone, queue[0].Hold)
+		assert.Equal(t, store.HoldWaitingForDep, queue[1].Hold)
+	}
+}
+
+func (f *testFixture) assertBuildQueue(expected ...model.ManifestName) {
+	f.T().Helper()
+	var actual []model.ManifestName
+	for _, qb := range BuildQueue(*f.st) {
+		actual = append(actual, qb.Name)
+	}
+	assert.Equal(f.t, expected, actual)
+}
@@ -0,0 +1,49 @@
+package buildcontrol
+
+import (
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Checks whether changes to these files can be live-updated into the
+// containers built from iTarget.
+//
+// Returns the files to sync, or a RedirectToNextBuilder error that explains
+// why we have to fall back to an image build.
+func LiveUpdateChanges(iTarget model.ImageTarget, files []string) ([]build.PathMapping, error) {
+	luInfo := iTarget.LiveUpdateInfo()
+
+	// Check this first, so that we don't print thousands of files
+	// in the messages below.
+	max := luInfo.MaxChangedFiles()
+	if max > 0 && len(files) > max {
+		return nil, RedirectToNextBuilderInfof(
+			"%d files changed, more than fall_back_on_change_count (%d)", len(files), max)
+	}
+
+	mappings, pathsMatchingNoSync, err := build.FilesToPathMappings(files, luInfo.SyncSteps())
+	if err != nil {
+		return nil, err
+	}
+	if len(pathsMatchingNoSync) > 0 {
+		prettyPaths := ospath.FileListDisplayNames(iTarget.LocalPaths(), pathsMatchingNoSync)
+		return nil, RedirectToNextBuilderInfof(
+			"Found file(s) not matching any sync for %s (files: %s)", iTarget.ID(), strings.Join(prettyPaths, ", "))
+	}
+
+	// If any changed files match a FallBackOn file, fall back to next BuildAndDeployer
+	anyMatch, file, err := luInfo.FallBackOnFiles().AnyMatch(files)
+	if err != nil {
+		return nil, err
+	}
+	if anyMatch {
+		prettyFile := ospath.FileListDisplayNames(iTarget.LocalPaths(), []string{file})[0]
+		return nil, RedirectToNextBuilderInfof(
+			"Detected change to fall_back_on file %q", prettyFile)
+	}
+
+	return mappings, nil
+}
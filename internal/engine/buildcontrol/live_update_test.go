@@ -0,0 +1,43 @@
+package buildcontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLiveUpdateChanges(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	lu, err := model.NewLiveUpdate([]model.LiveUpdateStep{
+		model.LiveUpdateFallBackOnStep{Files: []string{f.JoinPath("src", "package.json")}},
+		model.LiveUpdateSyncStep{Source: f.JoinPath("src"), Dest: "/src"},
+	}, f.Path())
+	require.NoError(t, err)
+
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("sancho")).
+		WithBuildDetails(model.DockerBuild{BuildPath: f.Path(), LiveUpdate: lu.WithMaxChangedFiles(2)})
+
+	mappings, err := LiveUpdateChanges(iTarget, []string{f.JoinPath("src", "a.txt")})
+	require.NoError(t, err)
+	require.Len(t, mappings, 1)
+	assert.Equal(t, "/src/a.txt", mappings[0].ContainerPath)
+
+	_, err = LiveUpdateChanges(iTarget, []string{f.JoinPath("obj", "a.out")})
+	assert.Contains(t, err.Error(), "Found file(s) not matching any sync")
+
+	_, err = LiveUpdateChanges(iTarget, []string{f.JoinPath("src", "package.json")})
+	assert.Contains(t, err.Error(), `Detected change to fall_back_on file "src/package.json"`)
+
+	tooMany := []string{f.JoinPath("src", "a.txt"), f.JoinPath("src", "b.txt"), f.JoinPath("src", "c.txt")}
+	_, err = LiveUpdateChanges(iTarget, tooMany)
+	assert.EqualError(t, err, "3 files changed, more than fall_back_on_change_count (2)")
+	_, ok := err.(RedirectToNextBuilder)
+	assert.True(t, ok)
+}
@@ -139,9 +139,14 @@ func (q *TargetQueue) isBuilding(id model.TargetID) bool {
 }
 
 func (q *TargetQueue) CountBuilds() int {
+	return q.CountBuildsMatching(func(target model.TargetSpec) bool { return true })
+}
+
+// The number of targets we're going to build that match the predicate.
+func (q *TargetQueue) CountBuildsMatching(match func(target model.TargetSpec) bool) int {
 	result := 0
 	for _, target := range q.sortedTargets {
-		if q.isBuilding(target.ID()) {
+		if q.isBuilding(target.ID()) && match(target) {
 			result++
 		}
 	}
@@ -170,7 +175,7 @@ func (q *TargetQueue) RunBuilds(handler BuildHandler) error {
 		if q.isBuilding(id) {
 			result, err := handler(target, q.dependencyResults(target))
 			if err != nil {
-				return err
+				return q.wrapBuildError(id, err)
 			}
 			q.results[id] = result
 		}
@@ -178,6 +183,23 @@ func (q *TargetQueue) RunBuilds(handler BuildHandler) error {
 	return nil
 }
 
+// When a resource has several images, say which one failed,
+// and which ones didn't get built because of it.
+func (q *TargetQueue) wrapBuildError(failed model.TargetID, err error) error {
+	if len(q.sortedTargets) < 2 {
+		return err
+	}
+
+	var unbuilt []model.TargetID
+	for _, target := range q.sortedTargets {
+		id := target.ID()
+		if _, ok := q.results[id]; !ok && q.isBuilding(id) {
+			unbuilt = append(unbuilt, id)
+		}
+	}
+	return ImageBuildError{error: err, TargetID: failed, Unbuilt: unbuilt}
+}
+
 func (q *TargetQueue) dependencyResults(target model.TargetSpec) []store.BuildResult {
 	depIDs := target.DependencyIDs()
 	results := make([]store.BuildResult, 0, len(depIDs))
@@ -2,11 +2,13 @@ package buildcontrol
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/docker/distribution/reference"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/testutils"
 
@@ -167,6 +169,56 @@ func TestTargetQueue_DepsBuiltButReaped(t *testing.T) {
 	assert.Equal(t, expectedCalls, f.handler.calls)
 }
 
+func TestTargetQueue_SiblingFails(t *testing.T) {
+	f := newTargetQueueFixture(t)
+
+	fooTarget := model.MustNewImageTarget(container.MustParseSelector("foo"))
+	barTarget := model.MustNewImageTarget(container.MustParseSelector("bar"))
+	bazTarget := model.MustNewImageTarget(container.MustParseSelector("baz"))
+	buildStateSet := store.BuildStateSet{
+		fooTarget.ID(): store.BuildState{},
+		barTarget.ID(): store.BuildState{},
+		bazTarget.ID(): store.BuildState{},
+	}
+
+	tq, err := NewImageTargetQueue(f.ctx, []model.ImageTarget{fooTarget, barTarget, bazTarget}, buildStateSet, f.imageExists)
+	require.NoError(t, err)
+
+	err = tq.RunBuilds(func(target model.TargetSpec, depResults []store.BuildResult) (store.BuildResult, error) {
+		if target.ID() == barTarget.ID() {
+			return nil, fmt.Errorf("compile error")
+		}
+		return f.handler.handle(target, depResults)
+	})
+
+	var imageErr ImageBuildError
+	require.True(t, errors.As(err, &imageErr))
+	assert.Equal(t, "image bar: compile error", err.Error())
+	assert.Equal(t, barTarget.ID(), imageErr.TargetID)
+	assert.Equal(t, []model.TargetID{barTarget.ID(), bazTarget.ID()}, imageErr.Unbuilt)
+	newResults := tq.NewResults()
+	assert.Len(t, newResults, 1)
+	assert.NotNil(t, newResults[fooTarget.ID()])
+}
+
+func TestTargetQueue_NeedsRebuild(t *testing.T) {
+	f := newTargetQueueFixture(t)
+
+	fooTarget := model.MustNewImageTarget(container.MustParseSelector("foo"))
+	s1 := store.BuildState{
+		LastResult:   store.NewImageBuildResultSingleRef(fooTarget.ID(), container.MustParseNamedTagged("foo:1234")),
+		NeedsRebuild: true,
+	}
+
+	f.run([]model.ImageTarget{fooTarget}, store.BuildStateSet{fooTarget.ID(): s1})
+
+	// foo's last result is clean, but its last build failed before it got a new one.
+	expectedCalls := map[model.TargetID]fakeBuildHandlerCall{
+		fooTarget.ID(): newFakeBuildHandlerCall(fooTarget, 1, []store.BuildResult{}),
+	}
+	assert.Equal(t, expectedCalls, f.handler.calls)
+}
+
 func newFakeBuildHandlerCall(target model.ImageTarget, num int, depResults []store.BuildResult) fakeBuildHandlerCall {
 	return fakeBuildHandlerCall{
 		target: target,
@@ -52,6 +52,12 @@ func ProvideUpdateMode(flag UpdateModeFlag, env k8s.Env, runtime container.Runti
 
 	mode := UpdateMode(flag)
 	if mode == UpdateModeContainer {
+		// CRI-O clusters like CRC and MicroShift don't run containers in a Docker
+		// daemon we can talk to, so they can only update containers with exec.
+		if runtime == container.RuntimeCrio {
+			return "", fmt.Errorf("update mode %q is not supported on clusters that use the %s container runtime. Valid Values: %v",
+				flag, runtime, []UpdateMode{UpdateModeAuto, UpdateModeImage, UpdateModeKubectlExec})
+		}
 		if !env.UsesLocalDockerRegistry() || runtime != container.RuntimeDocker {
 			return "", fmt.Errorf("update mode %q is only valid with local Docker clusters like Docker For Mac, Minikube, and MicroK8s", flag)
 		}
@@ -0,0 +1,30 @@
+package buildcontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+func TestUpdateModeContainerOnDockerDesktop(t *testing.T) {
+	mode, err := ProvideUpdateMode(UpdateModeFlag(UpdateModeContainer), k8s.EnvDockerDesktop, container.RuntimeDocker)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateModeContainer, mode)
+}
+
+func TestUpdateModeContainerOnCRIO(t *testing.T) {
+	_, err := ProvideUpdateMode(UpdateModeFlag(UpdateModeContainer), k8s.EnvCRC, container.RuntimeCrio)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `update mode "container" is not supported on clusters that use the cri-o container runtime`)
+	}
+}
+
+func TestUpdateModeExecOnCRIO(t *testing.T) {
+	mode, err := ProvideUpdateMode(UpdateModeFlag(UpdateModeKubectlExec), k8s.EnvMicroShift, container.RuntimeCrio)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateModeKubectlExec, mode)
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"time"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -15,6 +15,7 @@ import (
 
 type BuildController struct {
 	b                  BuildAndDeployer
+	clock              build.Clock
 	buildsStartedCount int // used to synchronize with state
 	disabledForTesting bool
 }
@@ -32,9 +33,10 @@ func (e buildEntry) Name() model.ManifestName       { return e.name }
 func (e buildEntry) FilesChanged() []string         { return e.filesChanged }
 func (e buildEntry) BuildReason() model.BuildReason { return e.buildReason }
 
-func NewBuildController(b BuildAndDeployer) *BuildController {
+func NewBuildController(b BuildAndDeployer, clock build.Clock) *BuildController {
 	return &BuildController{
-		b: b,
+		b:     b,
+		clock: clock,
 	}
 }
 
@@ -91,7 +93,7 @@ func (c *BuildController) OnChange(ctx context.Context, st store.RStore) {
 
 	st.Dispatch(buildcontrol.BuildStartedAction{
 		ManifestName: entry.name,
-		StartTime:    time.Now(),
+		StartTime:    c.clock.Now(),
 		FilesChanged: entry.filesChanged,
 		Reason:       entry.buildReason,
 		SpanID:       entry.spanID,
@@ -109,7 +111,9 @@ func (c *BuildController) OnChange(ctx context.Context, st store.RStore) {
 		buildcontrol.LogBuildEntry(ctx, entry)
 
 		result, err := c.buildAndDeploy(ctx, st, entry)
-		st.Dispatch(buildcontrol.NewBuildCompleteAction(entry.name, entry.spanID, result, err))
+		action := buildcontrol.NewBuildCompleteAction(entry.name, entry.spanID, result, err)
+		action.FinishTime = c.clock.Now()
+		st.Dispatch(action)
 	}()
 }
 
@@ -177,7 +181,14 @@ func buildStateSet(ctx context.Context, manifest model.Manifest, specs []model.T
 			depsChanged = append(depsChanged, dep)
 		}
 
-		buildState := store.NewBuildState(status.LastResult, filesChanged, depsChanged)
+		buildState := store.NewBuildState(status.LastResult, filesChanged, depsChanged).
+			WithNeedsRebuild(status.NeedsRebuild)
+		if len(ms.StaleBaseImages[id]) > 0 {
+			buildState = buildState.WithPullBaseImages(true)
+		}
+		if _, ok := spec.(model.K8sTarget); ok {
+			buildState = buildState.WithLastAppliedK8sResult(ms.LastAppliedK8sResult)
+		}
 
 		// Pass along the container when we can update containers in-place.
 		//
@@ -208,9 +219,12 @@ func buildStateSet(ctx context.Context, manifest model.Manifest, specs []model.T
 		result[id] = buildState
 	}
 
+	// When a base image was updated, the trigger is how the user asks
+	// to rebuild on it, so we can't satisfy it with a live update.
 	isLiveUpdateEligibleTrigger := reason.HasTrigger() &&
 		reason.Has(model.BuildReasonFlagChangedFiles) &&
-		manifest.TriggerMode != model.TriggerModeAuto
+		manifest.TriggerMode != model.TriggerModeAuto &&
+		!ms.HasStaleBaseImages()
 	isFullBuildTrigger := reason.HasTrigger() && !isLiveUpdateEligibleTrigger
 	if isFullBuildTrigger {
 		for k, v := range result {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
@@ -16,6 +17,7 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
@@ -573,6 +575,100 @@ func TestBuildControllerImageBuildTrigger(t *testing.T) {
 	}
 }
 
+func TestBuildControllerStaleBaseImageTrigger(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	mName := model.ManifestName("foobar")
+
+	manifest := f.newManifest(mName.String()).WithTriggerMode(model.TriggerModeManualAfterInitial)
+	f.Start([]model.Manifest{manifest})
+	f.nextCallComplete()
+
+	id := manifest.ImageTargetAt(0).ID()
+	f.store.Dispatch(baseimage.CheckAction{Stale: map[model.TargetID][]string{id: {"golang:1.15"}}})
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(id).PendingFileChanges) > 0
+	})
+
+	// Even with changed files, the trigger rebuilds the image on the new base image.
+	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: mName})
+	call := f.nextCallComplete()
+	state := call.oneImageState()
+	assert.True(t, state.FullBuildTriggered)
+	assert.True(t, state.PullBaseImages)
+
+	f.withManifestState(mName, func(ms store.ManifestState) {
+		assert.Empty(t, ms.StaleBaseImages)
+	})
+}
+
+func TestBuildStateSetPullsStaleBaseImages(t *testing.T) {
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/api")).
+		WithBuildDetails(model.DockerBuild{Dockerfile: "FROM golang:1.15"})
+	manifest := model.Manifest{Name: "api"}.WithImageTarget(iTarget).
+		WithDeployTarget(model.LocalTarget{Name: "api"}).
+		WithTriggerMode(model.TriggerModeManualAfterInitial)
+
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(manifest))
+	handleBaseImageCheckAction(state, baseimage.CheckAction{
+		Stale: map[model.TargetID][]string{iTarget.ID(): {"golang:1.15"}},
+	})
+
+	ms, _ := state.ManifestState("api")
+	reason := model.BuildReasonFlagTriggerWeb.With(model.BuildReasonFlagChangedFiles)
+	set := buildStateSet(context.Background(), manifest, buildTargets(manifest), ms, reason)
+	assert.True(t, set[iTarget.ID()].PullBaseImages)
+	assert.True(t, set[iTarget.ID()].FullBuildTriggered)
+
+	ref := container.MustParseNamedTagged("gcr.io/foo/api:dev")
+	handleBuildResults(state, state.ManifestTargets["api"], model.BuildRecord{}, store.BuildResultSet{
+		iTarget.ID(): store.NewImageBuildResultSingleRef(iTarget.ID(), ref),
+	})
+	assert.Empty(t, ms.StaleBaseImages)
+
+	set = buildStateSet(context.Background(), manifest, buildTargets(manifest), ms, reason)
+	assert.False(t, set[iTarget.ID()].PullBaseImages)
+	assert.False(t, set[iTarget.ID()].FullBuildTriggered)
+}
+
+func TestBuildStateSetRebuildsImagesUnbuiltBySiblingFailure(t *testing.T) {
+	apiTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/api"))
+	workerTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/worker"))
+	manifest := model.Manifest{Name: "api"}.WithImageTargets([]model.ImageTarget{apiTarget, workerTarget}).
+		WithDeployTarget(model.LocalTarget{Name: "api"})
+
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(manifest))
+	ms, _ := state.ManifestState("api")
+
+	// api built, but worker failed, so nothing was deployed.
+	apiRef := container.MustParseNamedTagged("gcr.io/foo/api:dev")
+	handleBuildResults(state, state.ManifestTargets["api"], model.BuildRecord{
+		Error: buildcontrol.ImageBuildError{
+			TargetID: workerTarget.ID(),
+			Unbuilt:  []model.TargetID{workerTarget.ID()},
+		},
+	}, store.BuildResultSet{
+		apiTarget.ID(): store.NewImageBuildResultSingleRef(apiTarget.ID(), apiRef),
+	})
+
+	set := buildStateSet(context.Background(), manifest, buildTargets(manifest), ms, model.BuildReasonFlagChangedFiles)
+	assert.False(t, set[apiTarget.ID()].NeedsImageBuild())
+	assert.True(t, set[workerTarget.ID()].NeedsRebuild)
+	assert.True(t, set[workerTarget.ID()].NeedsImageBuild())
+
+	workerRef := container.MustParseNamedTagged("gcr.io/foo/worker:dev")
+	handleBuildResults(state, state.ManifestTargets["api"], model.BuildRecord{}, store.BuildResultSet{
+		workerTarget.ID(): store.NewImageBuildResultSingleRef(workerTarget.ID(), workerRef),
+	})
+
+	set = buildStateSet(context.Background(), manifest, buildTargets(manifest), ms, model.BuildReasonFlagChangedFiles)
+	assert.False(t, set[workerTarget.ID()].NeedsRebuild)
+	assert.False(t, set[workerTarget.ID()].NeedsImageBuild())
+}
+
 // it should be a force update if there have been no file changes since the last build
 // make sure file changes prior to the last build are ignored for this purpose
 func TestBuildControllerManualTriggerWithFileChangesSinceLastSuccessfulBuildButBeforeLastBuild(t *testing.T) {
@@ -0,0 +1,14 @@
+package buildlogs
+
+import (
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A completed build's logs were saved to disk.
+type BuildLogArchivedAction struct {
+	ManifestName model.ManifestName
+	SpanID       model.LogSpanID
+	Path         string
+}
+
+func (BuildLogArchivedAction) Action() {}
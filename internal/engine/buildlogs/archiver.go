@@ -0,0 +1,127 @@
+package buildlogs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Saves the logs of each completed build to disk.
+//
+// The logstore drops the oldest logs when it gets too big, and a resource can
+// keep many builds in its history (see UpdateSettings.BuildHistoryLimit), so
+// this is how we keep older builds' logs around without keeping them in memory.
+//
+// Once a build falls out of its resource's history, we delete its logs.
+type Archiver struct {
+	dir    string
+	nextID int
+
+	// TearDown doesn't get a context with a logger.
+	logger logger.Logger
+
+	// The spans we've saved, and where we saved them.
+	archived map[model.LogSpanID]string
+}
+
+var _ store.SubscriberLifecycle = &Archiver{}
+
+func NewArchiver() *Archiver {
+	return &Archiver{
+		archived: make(map[model.LogSpanID]string),
+	}
+}
+
+type pendingLog struct {
+	name   model.ManifestName
+	spanID model.LogSpanID
+	log    string
+}
+
+func (a *Archiver) SetUp(ctx context.Context) {
+	a.logger = logger.Get(ctx)
+	dir, err := ioutil.TempDir("", "tilt-build-logs-")
+	if err != nil {
+		logger.Get(ctx).Debugf("Error creating directory for build logs: %v", err)
+		return
+	}
+	a.dir = dir
+}
+
+func (a *Archiver) TearDown(ctx context.Context) {
+	if a.dir == "" {
+		return
+	}
+	err := os.RemoveAll(a.dir)
+	if err != nil {
+		a.logger.Debugf("Error removing build logs: %v", err)
+	}
+}
+
+func (a *Archiver) OnChange(ctx context.Context, st store.RStore) {
+	if a.dir == "" {
+		return
+	}
+
+	var pending []pendingLog
+	live := make(map[model.LogSpanID]bool)
+
+	state := st.RLockState()
+	visit := func(name model.ManifestName, ms *store.ManifestState) {
+		for _, br := range ms.BuildHistory {
+			if br.SpanID == "" {
+				continue
+			}
+			live[br.SpanID] = true
+
+			_, ok := a.archived[br.SpanID]
+			if ok || br.LogPath != "" {
+				continue
+			}
+			pending = append(pending, pendingLog{
+				name:   name,
+				spanID: br.SpanID,
+				log:    state.LogStore.SpanLog(br.SpanID),
+			})
+		}
+	}
+	visit(model.TiltfileManifestName, &state.TiltfileState)
+	for _, mt := range state.Targets() {
+		visit(mt.Manifest.Name, mt.State)
+	}
+	st.RUnlockState()
+
+	for _, p := range pending {
+		a.nextID++
+		path := filepath.Join(a.dir, fmt.Sprintf("%d.log", a.nextID))
+		err := ioutil.WriteFile(path, []byte(p.log), 0600)
+		if err != nil {
+			logger.Get(ctx).Debugf("Error saving build log for %s: %v", p.name, err)
+			continue
+		}
+
+		a.archived[p.spanID] = path
+		st.Dispatch(BuildLogArchivedAction{
+			ManifestName: p.name,
+			SpanID:       p.spanID,
+			Path:         path,
+		})
+	}
+
+	for spanID, path := range a.archived {
+		if live[spanID] {
+			continue
+		}
+		delete(a.archived, spanID)
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Get(ctx).Debugf("Error removing build log: %v", err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package buildlogs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestArchiveCompletedBuilds(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addBuild("fe", "build:1", "hello from build 1\n")
+	f.a.OnChange(f.ctx, f.st)
+
+	action := f.st.WaitForAction(t, reflect.TypeOf(BuildLogArchivedAction{})).(BuildLogArchivedAction)
+	assert.Equal(t, model.ManifestName("fe"), action.ManifestName)
+	assert.Equal(t, model.LogSpanID("build:1"), action.SpanID)
+	contents, err := ioutil.ReadFile(action.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello from build 1\n", string(contents))
+
+	// Don't save the same build twice.
+	f.st.ClearActions()
+	f.a.OnChange(f.ctx, f.st)
+	assert.Empty(t, f.st.Actions())
+}
+
+func TestDeleteBuildsOutOfHistory(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addBuild("fe", "build:1", "hello from build 1\n")
+	f.a.OnChange(f.ctx, f.st)
+	action := f.st.WaitForAction(t, reflect.TypeOf(BuildLogArchivedAction{})).(BuildLogArchivedAction)
+
+	f.st.WithState(func(state *store.EngineState) {
+		ms, _ := state.ManifestState("fe")
+		ms.BuildHistory = nil
+	})
+	f.a.OnChange(f.ctx, f.st)
+
+	_, err := os.Stat(action.Path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+type fixture struct {
+	ctx context.Context
+	st  *store.TestingStore
+	a   *Archiver
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	a := NewArchiver()
+	a.SetUp(ctx)
+	return &fixture{
+		ctx: ctx,
+		st:  store.NewTestingStore(),
+		a:   a,
+	}
+}
+
+func (f *fixture) addBuild(name model.ManifestName, spanID model.LogSpanID, log string) {
+	f.st.WithState(func(state *store.EngineState) {
+		ms, ok := state.ManifestState(name)
+		if !ok {
+			state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: name}))
+			ms, _ = state.ManifestState(name)
+		}
+		state.LogStore.Append(store.NewLogAction(name, spanID, logger.InfoLvl, nil, []byte(log)), nil)
+		ms.AddCompletedBuild(model.BuildRecord{
+			StartTime:  time.Now(),
+			FinishTime: time.Now(),
+			SpanID:     spanID,
+		})
+	})
+}
+
+func (f *fixture) TearDown() {
+	f.a.TearDown(f.ctx)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
+	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
@@ -29,14 +30,25 @@ type ConfigsReloadedAction struct {
 	Warnings             []string
 	Features             map[string]bool
 	TeamID               string
+	AdditionalTeamIDs    []string
 	TelemetrySettings    model.TelemetrySettings
 	MetricsSettings      model.MetricsSettings
 	Secrets              model.SecretSet
 	DockerPruneSettings  model.DockerPruneSettings
 	AnalyticsTiltfileOpt analytics.Opt
+	AnalyticsConsent     tiltanalytics.Consent
 	VersionSettings      model.VersionSettings
 	UpdateSettings       model.UpdateSettings
 	WatchSettings        model.WatchSettings
+	LocalDNSSettings     model.LocalDNSSettings
+	HibernateSettings    model.HibernateSettings
+	EndpointHealth       model.EndpointHealthSettings
+	BaseImageChecks      model.BaseImageCheckSettings
+	LogSettings          model.LogSettings
+	SecretSettings       model.SecretSettings
+	TiltfileProfile      model.TiltfileProfile
+	Alerts               []model.Alert
+	TiltfileEnv          model.TiltfileEnv
 
 	// A checkpoint into the logstore when Tiltfile execution started.
 	// Useful for knowing how far back in time we have to scrub secrets.
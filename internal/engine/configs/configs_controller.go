@@ -37,6 +37,10 @@ func (cc *ConfigsController) SetTiltfileLoaderForTesting(tfl tiltfile.TiltfileLo
 	cc.tfl = tfl
 }
 
+func (cc *ConfigsController) SetClockForTesting(clock func() time.Time) {
+	cc.clock = clock
+}
+
 func (cc *ConfigsController) DisableForTesting(disabled bool) {
 	cc.disabledForTesting = disabled
 }
@@ -59,6 +63,7 @@ func (e buildEntry) BuildReason() model.BuildReason { return e.buildReason }
 // 3) Those files have changed since the last Tiltfile build
 //    (so that we don't keep re-running a failed build)
 // 4) OR the command-line args have changed since the last Tiltfile build
+// 5) OR an environment variable that the Tiltfile read has changed
 func (cc *ConfigsController) needsBuild(ctx context.Context, st store.RStore) (buildEntry, bool) {
 	state := st.RLockState()
 	defer st.RUnlockState()
@@ -92,6 +97,10 @@ func (cc *ConfigsController) needsBuild(ctx context.Context, st store.RStore) (b
 		reason = reason.With(model.BuildReasonFlagTiltfileArgs)
 	}
 
+	if state.TiltfileEnvChangeTime.After(lastStartTime) {
+		reason = reason.With(model.BuildReasonFlagTiltfileEnv)
+	}
+
 	if reason == model.BuildReasonNone {
 		return buildEntry{}, false
 	}
@@ -155,6 +164,9 @@ func (cc *ConfigsController) loadTiltfile(ctx context.Context, st store.RStore,
 
 	if tlr.Error != nil {
 		logger.Get(ctx).Infof("%s", tlr.Error.Error())
+		if hint := model.ErrorRemediation(tlr.Error); hint != "" {
+			logger.Get(ctx).Infof("%s", hint)
+		}
 	}
 
 	st.Dispatch(ConfigsReloadedAction{
@@ -165,15 +177,26 @@ func (cc *ConfigsController) loadTiltfile(ctx context.Context, st store.RStore,
 		Err:                   tlr.Error,
 		Features:              tlr.FeatureFlags,
 		TeamID:                tlr.TeamID,
+		AdditionalTeamIDs:     tlr.AdditionalTeamIDs,
 		TelemetrySettings:     tlr.TelemetrySettings,
 		MetricsSettings:       tlr.MetricsSettings,
 		Secrets:               tlr.Secrets,
 		AnalyticsTiltfileOpt:  tlr.AnalyticsOpt,
+		AnalyticsConsent:      tlr.AnalyticsConsent,
 		DockerPruneSettings:   tlr.DockerPruneSettings,
 		CheckpointAtExecStart: entry.checkpointAtExecStart,
 		VersionSettings:       tlr.VersionSettings,
 		UpdateSettings:        tlr.UpdateSettings,
 		WatchSettings:         tlr.WatchSettings,
+		LocalDNSSettings:      tlr.LocalDNSSettings,
+		HibernateSettings:     tlr.HibernateSettings,
+		EndpointHealth:        tlr.EndpointHealth,
+		BaseImageChecks:       tlr.BaseImageChecks,
+		LogSettings:           tlr.LogSettings,
+		SecretSettings:        tlr.SecretSettings,
+		TiltfileProfile:       tlr.Profile,
+		Alerts:                tlr.Alerts,
+		TiltfileEnv:           tlr.Env,
 	})
 }
 
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/secretscan"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// Watches an image build for leaked credentials, when the Tiltfile
+// asks for it with secret_settings(scan_builds=True).
+//
+// We check the build output and the config of the built images
+// after the images are built, but before we deploy them.
+type credentialScanner struct {
+	policy model.SecretScanPolicy
+
+	// Where to start scrubbing logs if we find something.
+	checkpoint logstore.Checkpoint
+
+	mu     sync.Mutex
+	output bytes.Buffer
+}
+
+// Returns nil if scanning is turned off.
+func newCredentialScanner(st store.RStore) *credentialScanner {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	if !state.SecretSettings.ScanBuilds {
+		return nil
+	}
+	return &credentialScanner{
+		policy:     state.SecretSettings.ScanPolicy,
+		checkpoint: state.LogStore.Checkpoint(),
+	}
+}
+
+// Forks the build's log output to the scanner.
+func (s *credentialScanner) attach(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return logger.CtxWithForkedOutput(ctx, s)
+}
+
+func (s *credentialScanner) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.output.Write(b)
+}
+
+// Returns an error if the build leaked credentials and the policy says
+// we shouldn't deploy it.
+func (s *credentialScanner) check(ctx context.Context, st store.RStore, db build.DockerBuilder, results store.BuildResultSet) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	findings := secretscan.Scan("build output", s.output.Bytes())
+	s.mu.Unlock()
+
+	ids := make([]model.TargetID, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	for _, id := range ids {
+		result, ok := results[id].(store.ImageBuildResult)
+		if !ok {
+			continue
+		}
+		ref := result.ImageLocalRef
+		config, err := db.ImageConfig(ctx, ref)
+		if err != nil {
+			// Images built in-cluster may not be visible to our Docker client.
+			logger.Get(ctx).Debugf("Scanning %s for credentials: %v", container.FamiliarString(ref), err)
+			continue
+		}
+		findings = append(findings, secretscan.ScanImage(container.FamiliarString(ref), config.Env, config.Labels)...)
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+
+	st.Dispatch(buildcontrol.CredentialsDetectedAction{
+		Secrets:    findings.Secrets(),
+		Checkpoint: s.checkpoint,
+	})
+
+	msg := fmt.Sprintf("Build leaked credentials: %s", findings.Summary())
+	if s.policy == model.SecretScanPolicyWarn {
+		logger.Get(ctx).Warnf("%s\nTheir values have been scrubbed from the logs.", msg)
+		return nil
+	}
+	return fmt.Errorf("%s\nRemove them from the build, or use secret_settings(scan_policy='warn') to deploy anyway", msg)
+}
@@ -0,0 +1,178 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// Runs CronJobs when the user asks, and restores their schedules when Tilt exits.
+//
+// Tilt suspends the CronJobs it deploys (see k8s.InjectCronJobSuspend), so
+// while Tilt manages them, they only run on demand. Each run is a Job owned by
+// the CronJob, so its pods and logs show up under the CronJob's resource.
+type Controller struct {
+	kCli  k8s.Client
+	clock build.Clock
+
+	// TearDown doesn't get a context with a logger.
+	logger logger.Logger
+
+	mu        sync.Mutex
+	resources map[model.ManifestName]*resource
+}
+
+// The CronJobs a resource deployed, and the run requests we've handled.
+type resource struct {
+	refs    []v1.ObjectReference
+	yaml    string
+	handled int
+}
+
+var _ store.SubscriberLifecycle = &Controller{}
+
+func NewController(kCli k8s.Client, clock build.Clock) *Controller {
+	return &Controller{
+		kCli:      kCli,
+		clock:     clock,
+		resources: make(map[model.ManifestName]*resource),
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	c.logger = logger.Get(ctx)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	var toRun []model.ManifestName
+	state := st.RLockState()
+	timeout := state.UpdateSettings.K8sUpsertTimeout()
+	c.mu.Lock()
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+		mn := mt.Manifest.Name
+		r, ok := c.resources[mn]
+		if !ok {
+			r = &resource{}
+			c.resources[mn] = r
+		}
+
+		// Keep the CronJobs from earlier deploys, so that we restore them all.
+		for _, ref := range mt.State.DeployedCronJobs() {
+			if !containsRef(r.refs, ref) {
+				r.refs = append(r.refs, ref)
+			}
+		}
+		r.yaml = mt.Manifest.K8sTarget().YAML
+
+		if mt.State.CronJobRunRequests > r.handled {
+			r.handled = mt.State.CronJobRunRequests
+			toRun = append(toRun, mn)
+		}
+	}
+	refsByName := make(map[model.ManifestName][]v1.ObjectReference, len(toRun))
+	for _, mn := range toRun {
+		refsByName[mn] = append([]v1.ObjectReference{}, c.resources[mn].refs...)
+	}
+	c.mu.Unlock()
+	st.RUnlockState()
+
+	for _, mn := range toRun {
+		c.run(ctx, st, mn, refsByName[mn], timeout)
+	}
+}
+
+// Creates a Job from each of the resource's CronJobs.
+func (c *Controller) run(ctx context.Context, st store.RStore, mn model.ManifestName, refs []v1.ObjectReference, timeout time.Duration) {
+	if len(refs) == 0 {
+		c.notify(st, mn, logger.WarnLvl, fmt.Sprintf("Can't run %s now: it has no deployed CronJobs\n", mn))
+		return
+	}
+
+	suffix := fmt.Sprintf("tilt-%d", c.clock.Now().Unix())
+	for _, ref := range refs {
+		cronJob, err := c.kCli.GetByReference(ctx, ref)
+		if err != nil {
+			c.notify(st, mn, logger.ErrorLvl, fmt.Sprintf("Running CronJob %s: %v\n", ref.Name, err))
+			continue
+		}
+
+		job, err := k8s.JobFromCronJob(cronJob, suffix)
+		if err != nil {
+			c.notify(st, mn, logger.ErrorLvl, fmt.Sprintf("Running CronJob %s: %v\n", ref.Name, err))
+			continue
+		}
+
+		_, err = c.kCli.Upsert(ctx, []k8s.K8sEntity{job}, timeout)
+		if err != nil {
+			c.notify(st, mn, logger.ErrorLvl, fmt.Sprintf("Running CronJob %s: creating Job: %v\n", ref.Name, err))
+			continue
+		}
+		c.notify(st, mn, logger.InfoLvl, fmt.Sprintf("Running CronJob %s now: created Job %s\n", ref.Name, job.Name()))
+	}
+}
+
+// Puts the CronJobs' schedules back the way their YAML has them.
+func (c *Controller) TearDown(ctx context.Context) {
+	if c.logger != nil {
+		ctx = logger.WithLogger(ctx, c.logger)
+	}
+
+	c.mu.Lock()
+	resources := c.resources
+	c.resources = make(map[model.ManifestName]*resource)
+	c.mu.Unlock()
+
+	for mn, r := range resources {
+		if len(r.refs) == 0 {
+			continue
+		}
+
+		suspendedInYAML := make(map[string]bool)
+		entities, err := k8s.ParseYAMLFromString(r.yaml)
+		if err == nil {
+			for _, e := range entities {
+				suspended, ok := e.CronJobSuspended()
+				if ok {
+					suspendedInYAML[e.Name()] = suspended
+				}
+			}
+		}
+
+		for _, ref := range r.refs {
+			err := c.kCli.MergePatch(ctx, ref, k8s.CronJobSuspendPatch(suspendedInYAML[ref.Name]))
+			if err != nil && c.logger != nil {
+				c.logger.Debugf("Restoring the schedule of %s CronJob %s: %v", mn, ref.Name, err)
+			}
+		}
+	}
+}
+
+func (c *Controller) notify(st store.RStore, mn model.ManifestName, level logger.Level, msg string) {
+	st.Dispatch(store.NewLogAction(mn, spanIDForManifest(mn), level, nil, []byte(msg)))
+}
+
+func spanIDForManifest(mn model.ManifestName) logstore.SpanID {
+	return logstore.SpanID(fmt.Sprintf("cronjob:%s", mn))
+}
+
+func containsRef(refs []v1.ObjectReference, ref v1.ObjectReference) bool {
+	for _, r := range refs {
+		if r.Kind == ref.Kind && r.Namespace == ref.Namespace && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package cronjob
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const reportYAML = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  schedule: "*/5 * * * *"
+  suspend: true
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+          - name: report
+            image: report
+`
+
+func TestRunNow(t *testing.T) {
+	f := newFixture(t)
+
+	f.deploy("")
+	f.onChange()
+	assert.Nil(t, f.kCli.LastUpsertResult)
+
+	f.requestRun()
+	f.onChange()
+
+	if assert.Len(t, f.kCli.LastUpsertResult, 1) {
+		job := f.kCli.LastUpsertResult[0]
+		assert.Equal(t, "Job", job.GVK().Kind)
+		assert.Equal(t, "report-tilt-1600000000", job.Name())
+		if assert.Len(t, job.OwnerReferences(), 1) {
+			assert.Equal(t, "cron-uid", string(job.OwnerReferences()[0].UID))
+		}
+	}
+	f.assertLog("Running CronJob report now: created Job report-tilt-1600000000")
+
+	// We only run once per request.
+	f.kCli.LastUpsertResult = nil
+	f.onChange()
+	assert.Nil(t, f.kCli.LastUpsertResult)
+}
+
+func TestRunNowBeforeDeploy(t *testing.T) {
+	f := newFixture(t)
+
+	f.st.WithState(func(state *store.EngineState) {
+		m := model.Manifest{Name: "report"}.WithDeployTarget(model.K8sTarget{Name: "report"})
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	})
+	f.requestRun()
+	f.onChange()
+
+	assert.Nil(t, f.kCli.LastUpsertResult)
+	f.assertLog("it has no deployed CronJobs")
+}
+
+func TestRestoreScheduleOnTearDown(t *testing.T) {
+	f := newFixture(t)
+
+	f.deploy("")
+	f.onChange()
+	f.c.TearDown(context.Background())
+
+	if assert.Len(t, f.kCli.MergePatchCalls, 1) {
+		assert.Equal(t, "report", f.kCli.MergePatchCalls[0].Ref.Name)
+		assert.Equal(t, `{"spec":{"suspend":false}}`, string(f.kCli.MergePatchCalls[0].Patch))
+	}
+}
+
+func TestRestoreScheduleSuspendedInYAML(t *testing.T) {
+	f := newFixture(t)
+
+	f.deploy(reportYAML)
+	f.onChange()
+	f.c.TearDown(context.Background())
+
+	if assert.Len(t, f.kCli.MergePatchCalls, 1) {
+		assert.Equal(t, `{"spec":{"suspend":true}}`, string(f.kCli.MergePatchCalls[0].Patch))
+	}
+}
+
+type fixture struct {
+	t    *testing.T
+	ctx  context.Context
+	kCli *k8s.FakeK8sClient
+	st   *store.TestingStore
+	c    *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	kCli := k8s.NewFakeK8sClient()
+	c := NewController(kCli, fakeClock{now: time.Unix(1600000000, 0)})
+	c.SetUp(ctx)
+	return &fixture{
+		t:    t,
+		ctx:  ctx,
+		kCli: kCli,
+		st:   store.NewTestingStore(),
+		c:    c,
+	}
+}
+
+func (f *fixture) deploy(yaml string) {
+	cronJob := k8s.NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1beta1",
+		"kind":       "CronJob",
+		"metadata": map[string]interface{}{
+			"name": "report",
+			"uid":  "cron-uid",
+		},
+		"spec": map[string]interface{}{
+			"schedule": "*/5 * * * *",
+			"suspend":  true,
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{},
+			},
+		},
+	}})
+	f.kCli.InjectEntityByName(cronJob)
+
+	f.st.WithState(func(state *store.EngineState) {
+		m := model.Manifest{Name: "report"}.WithDeployTarget(model.K8sTarget{Name: "report", YAML: yaml})
+		mt := store.NewManifestTarget(m)
+		mt.State.MutableBuildStatus(m.K8sTarget().ID()).LastResult = store.K8sBuildResult{
+			DeployedRefs: []v1.ObjectReference{cronJob.ToObjectReference()},
+		}
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) requestRun() {
+	f.st.WithState(func(state *store.EngineState) {
+		ms, ok := state.ManifestState("report")
+		require.True(f.t, ok)
+		ms.CronJobRunRequests++
+	})
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) assertLog(expected string) {
+	var logs []string
+	for _, action := range f.st.Actions() {
+		la, ok := action.(store.LogAction)
+		if !ok {
+			continue
+		}
+		if strings.Contains(string(la.Message()), expected) {
+			assert.Equal(f.t, model.ManifestName("report"), la.ManifestName())
+			return
+		}
+		logs = append(logs, string(la.Message()))
+	}
+	f.t.Errorf("Expected log %q. Actual: %v", expected, logs)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time { return c.now }
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A custom_build with an outputs manifest builds several images with one run
+// of its command. During a build, we keep the images from each run here,
+// keyed by manifest path, so that we only run each command once.
+type customBuildOutputs map[string]map[string]container.TaggedRefs
+
+// Whether the target is an image from a custom_build with an outputs manifest,
+// and the same command builds images that this build doesn't include.
+//
+// We push those images too, and report them, so that the resources that use
+// them can deploy them without running the command again.
+func hasOtherCustomBuildOutputs(iTargetMap map[model.TargetID]model.ImageTarget) func(target model.TargetSpec) bool {
+	seen := make(map[string]bool)
+	return func(target model.TargetSpec) bool {
+		iTarget, ok := target.(model.ImageTarget)
+		if !ok {
+			return false
+		}
+		cb := iTarget.CustomBuildInfo()
+		if !cb.HasOutputsManifest() || seen[cb.OutputsManifest] {
+			return false
+		}
+		seen[cb.OutputsManifest] = true
+		return len(otherCustomBuildOutputs(cb, iTargetMap)) > 0
+	}
+}
+
+// The refs of the images the command builds that aren't in this build.
+func otherCustomBuildOutputs(cb model.CustomBuild, iTargetMap map[model.TargetID]model.ImageTarget) []string {
+	var result []string
+	for _, ref := range cb.OutputRefs {
+		id, err := customBuildOutputID(ref)
+		if err != nil {
+			continue
+		}
+		if _, ok := iTargetMap[id]; !ok {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+func customBuildOutputID(ref string) (model.TargetID, error) {
+	named, err := container.ParseNamed(ref)
+	if err != nil {
+		return model.TargetID{}, err
+	}
+	return model.ImageID(container.NewRefSelector(named)), nil
+}
+
+// Builds an image from a custom_build with an outputs manifest.
+//
+// If another image in this build already ran the same command, reuses its outputs.
+// Otherwise, runs the command, and pushes the images it built for other resources.
+func (ibd *ImageBuildAndDeployer) buildCustomOutput(ctx context.Context, iTarget model.ImageTarget, ps *build.PipelineState,
+	iTargetMap map[model.TargetID]model.ImageTarget, outputs customBuildOutputs) (container.TaggedRefs, error) {
+	cb := iTarget.CustomBuildInfo()
+	name := container.FamiliarString(iTarget.Refs.ConfigurationRef)
+
+	built, ok := outputs[cb.OutputsManifest]
+	if ok {
+		ps.StartPipelineStep(ctx, "Building Custom Build: [%s]", name)
+		ps.Printf(ctx, "Skipping build: the same command already built it")
+		ps.EndPipelineStep(ctx)
+	} else {
+		var err error
+		built, err = ibd.ib.BuildCustomOutputs(ctx, iTarget, ps)
+		if err != nil {
+			return container.TaggedRefs{}, err
+		}
+		outputs[cb.OutputsManifest] = built
+
+		err = ibd.pushOtherCustomBuildOutputs(ctx, ps, cb, iTargetMap, built)
+		if err != nil {
+			return container.TaggedRefs{}, err
+		}
+	}
+
+	refs, ok := built[name]
+	if !ok {
+		return container.TaggedRefs{}, fmt.Errorf("Custom build outputs manifest %s has no image for %s", cb.OutputsManifest, name)
+	}
+	return refs, nil
+}
+
+func (ibd *ImageBuildAndDeployer) pushOtherCustomBuildOutputs(ctx context.Context, ps *build.PipelineState, cb model.CustomBuild,
+	iTargetMap map[model.TargetID]model.ImageTarget, built map[string]container.TaggedRefs) error {
+	others := otherCustomBuildOutputs(cb, iTargetMap)
+	if len(others) == 0 {
+		return nil
+	}
+
+	ps.StartPipelineStep(ctx, "Pushing images for other resources")
+	defer ps.EndPipelineStep(ctx)
+
+	if ibd.canAlwaysSkipPush() || cb.SkipsPush() {
+		ps.Printf(ctx, "Skipping push")
+		return nil
+	}
+
+	for _, ref := range others {
+		refs, ok := built[ref]
+		if !ok {
+			continue
+		}
+		ps.Printf(ctx, "- %s", container.FamiliarString(refs.LocalRef))
+		target := model.MustNewImageTarget(container.MustParseSelector(ref)).WithBuildDetails(cb)
+		_, err := ibd.pushImage(ctx, refs.LocalRef, ps, target)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The results for the images that the commands built for other resources.
+//
+// When the build finishes, the engine hands these to the other resources
+// that are waiting to rebuild the same images.
+func (outputs customBuildOutputs) otherResults(iTargetMap map[model.TargetID]model.ImageTarget) store.BuildResultSet {
+	result := store.BuildResultSet{}
+	for _, built := range outputs {
+		for ref, refs := range built {
+			id, err := customBuildOutputID(ref)
+			if err != nil {
+				continue
+			}
+			if _, ok := iTargetMap[id]; ok {
+				continue
+			}
+			result[id] = store.NewImageBuildResult(id, refs.LocalRef, refs.ClusterRef)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestOtherCustomBuildOutputs(t *testing.T) {
+	cb := model.CustomBuild{
+		Command:         model.ToHostCmd("bazel run //:images"),
+		OutputsManifest: "/src/images.json",
+		OutputRefs:      []string{"gcr.io/foo/api", "gcr.io/foo/worker", "gcr.io/foo/web"},
+	}
+	api := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/api")).WithBuildDetails(cb)
+	worker := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/worker")).WithBuildDetails(cb)
+	iTargetMap := model.ImageTargetsByID([]model.ImageTarget{api, worker})
+
+	assert.Equal(t, []string{"gcr.io/foo/web"}, otherCustomBuildOutputs(cb, iTargetMap))
+
+	// Only one extra pipeline step per command.
+	match := hasOtherCustomBuildOutputs(iTargetMap)
+	assert.True(t, match(api))
+	assert.False(t, match(worker))
+}
+
+func TestCustomBuildOtherResults(t *testing.T) {
+	cb := model.CustomBuild{
+		OutputsManifest: "/src/images.json",
+		OutputRefs:      []string{"gcr.io/foo/api", "gcr.io/foo/web"},
+	}
+	api := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/api")).WithBuildDetails(cb)
+	iTargetMap := model.ImageTargetsByID([]model.ImageTarget{api})
+
+	webRef := container.MustParseNamedTagged("gcr.io/foo/web:dev")
+	outputs := customBuildOutputs{
+		"/src/images.json": {
+			"gcr.io/foo/api": {LocalRef: container.MustParseNamedTagged("gcr.io/foo/api:dev")},
+			"gcr.io/foo/web": {LocalRef: webRef, ClusterRef: webRef},
+		},
+	}
+
+	webID := model.ImageID(container.MustParseSelector("gcr.io/foo/web"))
+	assert.Equal(t, store.BuildResultSet{
+		webID: store.NewImageBuildResult(webID, webRef, webRef),
+	}, outputs.otherResults(iTargetMap))
+}
+
+func TestCustomBuildOtherResultsGoToOtherManifests(t *testing.T) {
+	apiID := model.ImageID(container.MustParseSelector("gcr.io/foo/api"))
+	webID := model.ImageID(container.MustParseSelector("gcr.io/foo/web"))
+	api := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/api"))
+	web := model.MustNewImageTarget(container.MustParseSelector("gcr.io/foo/web"))
+
+	apiManifest := model.Manifest{Name: "api"}.WithImageTarget(api).
+		WithDeployTarget(model.K8sTarget{Name: "api"}.WithDependencyIDs([]model.TargetID{apiID}))
+	webManifest := model.Manifest{Name: "web"}.WithImageTarget(web).
+		WithDeployTarget(model.K8sTarget{Name: "web"}.WithDependencyIDs([]model.TargetID{webID}))
+
+	state := store.NewState()
+	state.UpsertManifestTarget(store.NewManifestTarget(apiManifest))
+	state.UpsertManifestTarget(store.NewManifestTarget(webManifest))
+
+	apiRef := container.MustParseNamedTagged("gcr.io/foo/api:dev")
+	webRef := container.MustParseNamedTagged("gcr.io/foo/web:dev")
+	results := store.BuildResultSet{
+		apiID: store.NewImageBuildResult(apiID, apiRef, apiRef),
+		webID: store.NewImageBuildResult(webID, webRef, webRef),
+	}
+	handleBuildResults(state, state.ManifestTargets["api"], model.BuildRecord{}, results)
+
+	apiState := state.ManifestTargets["api"].State
+	assert.Equal(t, results[apiID], apiState.BuildStatus(apiID).LastResult)
+	_, ok := apiState.BuildStatuses[webID]
+	assert.False(t, ok)
+
+	webMT := state.ManifestTargets["web"]
+	assert.Equal(t, results[webID], webMT.State.BuildStatus(webID).LastResult)
+	assert.Contains(t, webMT.State.BuildStatus(webMT.Manifest.K8sTarget().ID()).PendingDependencyChanges, webID)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type EventWatcher struct {
@@ -39,16 +40,16 @@ func (w *EventWatcher) OnChange(ctx context.Context, st store.RStore) {
 
 	// TODO(nick): This should respond dynamically if the path changes.
 	state := st.RLockState()
-	configPaths := state.DockerComposeConfigPath()
+	proj := state.DockerComposeProject()
 	st.RUnlockState()
 
-	if len(configPaths) == 0 {
+	if len(proj.ConfigPaths) == 0 {
 		// No DC manifests to watch
 		return
 	}
 
 	w.watching = true
-	ch, err := w.startWatch(ctx, configPaths)
+	ch, err := w.startWatch(ctx, proj)
 	if err != nil {
 		err = errors.Wrap(err, "Subscribing to docker-compose events")
 		st.Dispatch(store.NewErrorAction(err))
@@ -58,8 +59,8 @@ func (w *EventWatcher) OnChange(ctx context.Context, st store.RStore) {
 	go w.dispatchEventLoop(ctx, ch, st)
 }
 
-func (w *EventWatcher) startWatch(ctx context.Context, configPath []string) (<-chan string, error) {
-	return w.dcc.StreamEvents(ctx, configPath)
+func (w *EventWatcher) startWatch(ctx context.Context, proj model.DockerComposeProject) (<-chan string, error) {
+	return w.dcc.StreamEvents(ctx, proj)
 }
 
 func (w *EventWatcher) dispatchEventLoop(ctx context.Context, ch <-chan string, st store.RStore) {
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/docker/distribution/reference"
@@ -78,12 +79,15 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		})
 	}()
 
+	scanner := newCredentialScanner(st)
+	ctx = scanner.attach(ctx)
+
 	q, err := buildcontrol.NewImageTargetQueue(ctx, iTargets, currentState, bd.ib.CanReuseRef)
 	if err != nil {
 		return store.BuildResultSet{}, err
 	}
 
-	numStages := q.CountBuilds()
+	numStages := q.CountBuilds() + q.CountBuildsMatching(analyzesLayers)
 
 	reused := q.ReusedResults()
 	hasReusedStep := len(reused) > 0
@@ -116,6 +120,7 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		if err != nil {
 			return nil, err
 		}
+		iTarget = withPullBaseImages(iTarget, currentState[iTarget.ID()].PullBaseImages)
 
 		expectedRef := iTarget.Refs.ConfigurationRef
 
@@ -132,7 +137,8 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 			return nil, err
 		}
 
-		return store.NewImageBuildResultSingleRef(iTarget.ID(), ref), nil
+		layerAnalysis := bd.ib.AnalyzeLayers(ctx, iTarget, ps, ref, currentState[iTarget.ID()].LastResult)
+		return store.NewImageBuildResultSingleRef(iTarget.ID(), ref).WithLayerAnalysis(layerAnalysis), nil
 	})
 
 	newResults := q.NewResults()
@@ -140,16 +146,23 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		return newResults, err
 	}
 
+	err = scanner.check(ctx, st, bd.ib.db, newResults)
+	if err != nil {
+		return newResults, err
+	}
+
+	bd.warnUnmountedBindMounts(ctx, dcTarget)
+
 	stdout := logger.Get(ctx).Writer(logger.InfoLvl)
 	stderr := logger.Get(ctx).Writer(logger.InfoLvl)
-	err = bd.dcc.Up(ctx, dcTarget.ConfigPaths, dcTarget.Name, !haveImage, stdout, stderr)
+	err = bd.dcc.Up(ctx, dcTarget.Project(), dcTarget.Name, !haveImage, stdout, stderr)
 	if err != nil {
 		return newResults, err
 	}
 
 	// NOTE(dmiller): right now we only need this the first time. In the future
 	// it might be worth it to move this somewhere else
-	cid, err := bd.dcc.ContainerID(ctx, dcTarget.ConfigPaths, dcTarget.Name)
+	cid, err := bd.dcc.ContainerID(ctx, dcTarget.Project(), dcTarget.Name)
 	if err != nil {
 		return newResults, err
 	}
@@ -169,6 +182,21 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 	return newResults, nil
 }
 
+// Colima, Rancher Desktop, and Lima run the docker daemon in a VM that only
+// mounts some of the host's directories. A bind mount from anywhere else
+// silently comes up empty, so warn about it.
+func (bd *DockerComposeBuildAndDeployer) warnUnmountedBindMounts(ctx context.Context, dcTarget model.DockerComposeTarget) {
+	env := bd.dc.Env()
+	for _, path := range dcTarget.BindMounts() {
+		if env.CanBindMount(path) {
+			continue
+		}
+		logger.Get(ctx).Warnf("Bind mount %s isn't shared with the %s VM, so the container will see an empty directory.\n"+
+			"Add it to the VM's mounts, or move it under one of: %s",
+			path, env.VMRuntime, strings.Join(env.VMMounts, ", "))
+	}
+}
+
 // tagWithExpected tags the given ref as whatever Docker Compose expects, i.e. as
 // the `image` value given in docker-compose.yaml. (If DC yaml specifies an image
 // with a tag, use that name + tag; otherwise, tag as latest.)
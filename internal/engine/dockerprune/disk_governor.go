@@ -0,0 +1,251 @@
+package dockerprune
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How often we check how much space Docker has left.
+const diskCheckInterval = 30 * time.Second
+
+// When a build is about to start, we check again if it's been at least this long.
+const diskPreBuildCheckInterval = 5 * time.Second
+
+// Don't prune for space more often than this. If a prune didn't free up
+// enough, pruning again right away won't either.
+const diskPruneCooldown = 10 * time.Minute
+
+const diskAlertID = "docker-disk"
+
+// Watches the free space on the disk where Docker stores its data.
+//
+// Warns in the alert center when space runs low, and prunes Tilt's
+// own old images, containers, and build cache before builds start failing
+// with "no space left on device".
+//
+// We can only see the disk of a Docker daemon on this machine. Daemons
+// in a VM (like Docker Desktop or Minikube) or on another host are skipped.
+type DiskGovernor struct {
+	dCli   docker.Client
+	pruner *DockerPruner
+	sched  *scheduler.Scheduler
+	clock  build.Clock
+
+	// Swapped out in tests.
+	statDiskSpace func(path string) (DiskSpace, error)
+
+	// Held while checking, so that scheduled checks and
+	// pre-build checks don't prune at the same time.
+	checkMu sync.Mutex
+
+	mu           sync.Mutex
+	st           store.RStore
+	settings     model.DockerPruneSettings
+	imgSelectors []container.RefSelector
+	building     bool
+	dataRoot     string
+	disabled     bool
+	alerted      bool
+	lastCheck    time.Time
+	lastPrune    time.Time
+}
+
+var _ store.Subscriber = &DiskGovernor{}
+var _ store.SetUpper = &DiskGovernor{}
+
+func NewDiskGovernor(dCli docker.Client, pruner *DockerPruner, sched *scheduler.Scheduler, clock build.Clock) *DiskGovernor {
+	return &DiskGovernor{
+		dCli:          dCli,
+		pruner:        pruner,
+		sched:         sched,
+		clock:         clock,
+		statDiskSpace: statDiskSpace,
+	}
+}
+
+func (g *DiskGovernor) SetUp(ctx context.Context) {
+	g.sched.Every(ctx, "docker-disk", diskCheckInterval, diskCheckInterval, g.check)
+}
+
+func (g *DiskGovernor) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	settings := state.DockerPruneSettings
+	building := len(state.CurrentlyBuilding) > 0
+	buildQueued := buildcontrol.NextManifestNameToBuild(state) != ""
+	imgSelectors := model.LocalRefSelectorsForManifests(state.Manifests())
+	st.RUnlockState()
+
+	g.mu.Lock()
+	g.st = st
+	g.settings = settings
+	g.building = building
+	g.imgSelectors = imgSelectors
+	checkNow := buildQueued && !building && g.clock.Now().Sub(g.lastCheck) >= diskPreBuildCheckInterval
+	g.mu.Unlock()
+
+	// Make sure there's room before the next build starts.
+	if checkNow {
+		g.check(ctx)
+	}
+}
+
+func (g *DiskGovernor) check(ctx context.Context) {
+	g.checkMu.Lock()
+	defer g.checkMu.Unlock()
+
+	g.mu.Lock()
+	g.lastCheck = g.clock.Now()
+	settings := g.settings
+	st := g.st
+	disabled := g.disabled
+	g.mu.Unlock()
+
+	if disabled || st == nil || (settings.WarnFreeDiskPct == 0 && settings.MinFreeDiskPct == 0) {
+		return
+	}
+
+	dataRoot, ok := g.resolveDataRoot(ctx)
+	if !ok {
+		return
+	}
+
+	space, err := g.statDiskSpace(dataRoot)
+	if err != nil {
+		logger.Get(ctx).Debugf("[Docker Prune] checking free space in %s: %v", dataRoot, err)
+		return
+	}
+
+	if g.shouldPrune(settings, space) {
+		logger.Get(ctx).Infof("[Docker Prune] Docker is low on disk space (%s). Pruning Tilt's old images, containers, and build cache",
+			describeDiskSpace(dataRoot, space))
+
+		g.mu.Lock()
+		g.lastPrune = g.clock.Now()
+		imgSelectors := g.imgSelectors
+		g.mu.Unlock()
+
+		g.pruner.Prune(ctx, 0, settings.KeepRecent, imgSelectors)
+
+		space, err = g.statDiskSpace(dataRoot)
+		if err != nil {
+			logger.Get(ctx).Debugf("[Docker Prune] checking free space in %s: %v", dataRoot, err)
+			return
+		}
+	}
+
+	g.updateAlert(st, settings, dataRoot, space)
+}
+
+// Finds where the Docker daemon keeps its data, and makes sure it's on this machine.
+func (g *DiskGovernor) resolveDataRoot(ctx context.Context) (string, bool) {
+	g.mu.Lock()
+	dataRoot := g.dataRoot
+	g.mu.Unlock()
+	if dataRoot != "" {
+		return dataRoot, true
+	}
+
+	disable := func(format string, args ...interface{}) (string, bool) {
+		logger.Get(ctx).Debugf("[Docker Prune] not watching Docker's disk space: "+format, args...)
+		g.mu.Lock()
+		g.disabled = true
+		g.mu.Unlock()
+		return "", false
+	}
+
+	if !isLocalDaemon(g.dCli.Env()) {
+		return disable("Docker daemon at %s is not on this machine", g.dCli.Env().Host)
+	}
+
+	info, err := g.dCli.Info(ctx)
+	if err != nil {
+		// Docker might not be up yet. Try again on the next check.
+		logger.Get(ctx).Debugf("[Docker Prune] reading Docker info: %v", err)
+		return "", false
+	}
+	if info.DockerRootDir == "" {
+		return disable("Docker didn't report its data root")
+	}
+
+	// Docker Desktop reports a data root inside its VM, which won't exist here.
+	_, err = g.statDiskSpace(info.DockerRootDir)
+	if err != nil {
+		return disable("can't read %s: %v", info.DockerRootDir, err)
+	}
+
+	g.mu.Lock()
+	g.dataRoot = info.DockerRootDir
+	g.mu.Unlock()
+	return info.DockerRootDir, true
+}
+
+func (g *DiskGovernor) shouldPrune(settings model.DockerPruneSettings, space DiskSpace) bool {
+	if !settings.Enabled || settings.MinFreeDiskPct == 0 || space.FreePct() >= float64(settings.MinFreeDiskPct) {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Pruning while we build could delete an image out from under the build.
+	if g.building {
+		return false
+	}
+	return g.lastPrune.IsZero() || g.clock.Now().Sub(g.lastPrune) >= diskPruneCooldown
+}
+
+func (g *DiskGovernor) updateAlert(st store.RStore, settings model.DockerPruneSettings, dataRoot string, space DiskSpace) {
+	threshold := settings.WarnFreeDiskPct
+	if threshold == 0 {
+		threshold = settings.MinFreeDiskPct
+	}
+	low := space.FreePct() < float64(threshold)
+
+	g.mu.Lock()
+	wasAlerted := g.alerted
+	g.alerted = low
+	g.mu.Unlock()
+
+	if !low {
+		if wasAlerted {
+			st.Dispatch(store.AlertResolvedAction{ID: diskAlertID})
+		}
+		return
+	}
+
+	severity := model.AlertSeverityWarning
+	if settings.MinFreeDiskPct != 0 && space.FreePct() < float64(settings.MinFreeDiskPct) {
+		severity = model.AlertSeverityError
+	}
+	st.Dispatch(store.AlertAction{Alert: model.Alert{
+		ID:       diskAlertID,
+		Source:   model.AlertSourceDockerDisk,
+		Severity: severity,
+		Message: fmt.Sprintf("Docker is low on disk space (%s). Builds may fail with \"no space left on device\". "+
+			"Try `docker system prune`, or free up space on that disk.", describeDiskSpace(dataRoot, space)),
+	}})
+}
+
+func describeDiskSpace(dataRoot string, space DiskSpace) string {
+	return fmt.Sprintf("%s free of %s in %s", units.HumanSize(float64(space.Free)), units.HumanSize(float64(space.Total)), dataRoot)
+}
+
+// Docker daemons that we talk to over a local socket run on this machine.
+func isLocalDaemon(env docker.Env) bool {
+	host := env.Host
+	return host == "" || strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
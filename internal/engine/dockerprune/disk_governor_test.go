@@ -0,0 +1,237 @@
+package dockerprune
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const gb = 1000 * 1000 * 1000
+
+func TestDiskGovernorPlentyOfSpace(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(50 * gb)
+
+	f.onChangeAndCheck()
+
+	assert.False(t, f.pruneCalled())
+	assert.Empty(t, f.alerts())
+}
+
+func TestDiskGovernorWarnsWhenLow(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(8 * gb)
+
+	f.onChangeAndCheck()
+
+	assert.False(t, f.pruneCalled())
+	alerts := f.alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, model.AlertSourceDockerDisk, alerts[0].Source)
+	assert.Equal(t, model.AlertSeverityWarning, alerts[0].Severity)
+	assert.Contains(t, alerts[0].Message, "Docker is low on disk space (8GB free of 100GB in /var/lib/docker)")
+}
+
+func TestDiskGovernorPrunesWhenVeryLow(t *testing.T) {
+	f := newDiskFixture(t)
+	// The first stat is when we resolve Docker's data root.
+	f.setFree(3*gb, 3*gb, 20*gb)
+
+	f.onChangeAndCheck()
+
+	assert.True(t, f.pruneCalled())
+	assert.Contains(t, f.logs.String(), "Docker is low on disk space (3GB free of 100GB in /var/lib/docker). Pruning")
+	assert.Empty(t, f.alerts())
+}
+
+func TestDiskGovernorAlertsWhenPruneDoesNotHelp(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(3 * gb)
+
+	f.onChangeAndCheck()
+
+	assert.True(t, f.pruneCalled())
+	alerts := f.alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, model.AlertSeverityError, alerts[0].Severity)
+}
+
+func TestDiskGovernorNoPruneWhileBuilding(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(3 * gb)
+	f.withCurrentlyBuilding("fe")
+
+	f.onChangeAndCheck()
+
+	assert.False(t, f.pruneCalled())
+	require.Len(t, f.alerts(), 1)
+}
+
+func TestDiskGovernorNoPruneWhenPruningDisabled(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(3 * gb)
+	f.settings.Enabled = false
+
+	f.onChangeAndCheck()
+
+	assert.False(t, f.pruneCalled())
+	require.Len(t, f.alerts(), 1)
+}
+
+func TestDiskGovernorPruneCooldown(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(3 * gb)
+
+	f.onChangeAndCheck()
+	assert.True(t, f.pruneCalled())
+
+	f.resetPruneCalls()
+	f.clock.Advance(time.Minute)
+	f.dg.check(f.ctx)
+	assert.False(t, f.pruneCalled())
+
+	f.clock.Advance(diskPruneCooldown)
+	f.dg.check(f.ctx)
+	assert.True(t, f.pruneCalled())
+}
+
+func TestDiskGovernorResolvesAlert(t *testing.T) {
+	f := newDiskFixture(t)
+	f.setFree(8 * gb)
+	f.onChangeAndCheck()
+	require.Len(t, f.alerts(), 1)
+
+	f.setFree(30 * gb)
+	f.dg.check(f.ctx)
+
+	var resolved []string
+	for _, a := range f.st.Actions() {
+		if ra, ok := a.(store.AlertResolvedAction); ok {
+			resolved = append(resolved, ra.ID)
+		}
+	}
+	assert.Equal(t, []string{diskAlertID}, resolved)
+}
+
+func TestDiskGovernorDataRootNotOnThisMachine(t *testing.T) {
+	f := newDiskFixture(t)
+	f.statErr = fmt.Errorf("no such file or directory")
+
+	f.onChangeAndCheck()
+
+	assert.True(t, f.dg.disabled)
+	assert.False(t, f.pruneCalled())
+	assert.Empty(t, f.alerts())
+}
+
+func TestIsLocalDaemon(t *testing.T) {
+	assert.True(t, isLocalDaemon(docker.Env{}))
+	assert.True(t, isLocalDaemon(docker.Env{Host: "unix:///var/run/docker.sock"}))
+	assert.True(t, isLocalDaemon(docker.Env{Host: "npipe:////./pipe/docker_engine"}))
+	assert.False(t, isLocalDaemon(docker.Env{Host: "tcp://192.168.99.100:2376"}))
+}
+
+type diskFixture struct {
+	t        *testing.T
+	ctx      context.Context
+	logs     *bytes.Buffer
+	st       *store.TestingStore
+	dCli     *docker.FakeClient
+	clock    clockwork.FakeClock
+	dg       *DiskGovernor
+	settings model.DockerPruneSettings
+
+	free    []uint64
+	statErr error
+}
+
+func newDiskFixture(t *testing.T) *diskFixture {
+	logs := new(bytes.Buffer)
+	ctx, _, _ := testutils.ForkedCtxAndAnalyticsForTest(logs)
+	dCli := docker.NewFakeClient()
+	dCli.DaemonInfo = types.Info{DockerRootDir: "/var/lib/docker"}
+	clock := clockwork.NewFakeClockAt(time.Now())
+	dg := NewDiskGovernor(dCli, NewDockerPruner(dCli, clock), scheduler.NewScheduler(clock), clock)
+
+	f := &diskFixture{
+		t:     t,
+		ctx:   ctx,
+		logs:  logs,
+		st:    store.NewTestingStore(),
+		dCli:  dCli,
+		clock: clock,
+		dg:    dg,
+		settings: model.DockerPruneSettings{
+			Enabled:         true,
+			KeepRecent:      model.DockerPruneDefaultKeepRecent,
+			WarnFreeDiskPct: 10,
+			MinFreeDiskPct:  5,
+		},
+	}
+	dg.statDiskSpace = f.statDiskSpace
+	return f
+}
+
+// Each stat returns the next free value, and the last one forever after.
+func (f *diskFixture) setFree(free ...uint64) {
+	f.free = free
+}
+
+func (f *diskFixture) statDiskSpace(path string) (DiskSpace, error) {
+	assert.Equal(f.t, "/var/lib/docker", path)
+	if f.statErr != nil {
+		return DiskSpace{}, f.statErr
+	}
+	free := f.free[0]
+	if len(f.free) > 1 {
+		f.free = f.free[1:]
+	}
+	return DiskSpace{Free: free, Total: 100 * gb}, nil
+}
+
+func (f *diskFixture) withCurrentlyBuilding(mn model.ManifestName) {
+	state := f.st.LockMutableStateForTesting()
+	state.CurrentlyBuilding[mn] = true
+	f.st.UnlockMutableState()
+}
+
+func (f *diskFixture) onChangeAndCheck() {
+	state := f.st.LockMutableStateForTesting()
+	state.DockerPruneSettings = f.settings
+	f.st.UnlockMutableState()
+
+	f.dg.OnChange(f.ctx, f.st)
+	f.dg.check(f.ctx)
+}
+
+func (f *diskFixture) pruneCalled() bool {
+	return f.dCli.ContainersPruneFilters.Len() > 0
+}
+
+func (f *diskFixture) resetPruneCalls() {
+	f.dCli.ContainersPruneFilters = filters.NewArgs()
+}
+
+func (f *diskFixture) alerts() []model.Alert {
+	var result []model.Alert
+	for _, a := range f.st.Actions() {
+		if aa, ok := a.(store.AlertAction); ok {
+			result = append(result, aa.Alert)
+		}
+	}
+	return result
+}
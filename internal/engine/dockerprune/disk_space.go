@@ -0,0 +1,14 @@
+package dockerprune
+
+// How much room is left on the filesystem that holds a directory.
+type DiskSpace struct {
+	Free  uint64 // Bytes available to unprivileged users
+	Total uint64
+}
+
+func (s DiskSpace) FreePct() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return float64(s.Free) / float64(s.Total) * 100
+}
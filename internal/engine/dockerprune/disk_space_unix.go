@@ -0,0 +1,20 @@
+// +build !windows
+
+package dockerprune
+
+import (
+	"syscall"
+)
+
+func statDiskSpace(path string) (DiskSpace, error) {
+	var buf syscall.Statfs_t
+	err := syscall.Statfs(path, &buf)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	bsize := uint64(buf.Bsize)
+	return DiskSpace{
+		Free:  uint64(buf.Bavail) * bsize,
+		Total: uint64(buf.Blocks) * bsize,
+	}, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -25,7 +26,8 @@ import (
 )
 
 type DockerPruner struct {
-	dCli docker.Client
+	dCli  docker.Client
+	clock build.Clock
 
 	disabledForTesting bool
 	disabledOnSetup    bool
@@ -37,8 +39,8 @@ type DockerPruner struct {
 var _ store.Subscriber = &DockerPruner{}
 var _ store.SetUpper = &DockerPruner{}
 
-func NewDockerPruner(dCli docker.Client) *DockerPruner {
-	return &DockerPruner{dCli: dCli}
+func NewDockerPruner(dCli docker.Client, clock build.Clock) *DockerPruner {
+	return &DockerPruner{dCli: dCli, clock: clock}
 }
 
 func (dp *DockerPruner) DisabledForTesting(disabled bool) {
@@ -110,14 +112,14 @@ func (dp *DockerPruner) OnChange(ctx context.Context, st store.RStore) {
 		interval = model.DockerPruneDefaultInterval
 	}
 
-	if time.Since(dp.lastPruneTime) >= interval {
+	if dp.clock.Now().Sub(dp.lastPruneTime) >= interval {
 		dp.PruneAndRecordState(ctx, settings.MaxAge, settings.KeepRecent, imgSelectors, curBuildCount)
 	}
 }
 
 func (dp *DockerPruner) PruneAndRecordState(ctx context.Context, maxAge time.Duration, keepRecent int, imgSelectors []container.RefSelector, curBuildCount int) {
 	dp.Prune(ctx, maxAge, keepRecent, imgSelectors)
-	dp.lastPruneTime = time.Now()
+	dp.lastPruneTime = dp.clock.Now()
 	dp.lastPruneBuildCount = curBuildCount
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -256,6 +257,21 @@ func TestDockerPrunerSinceInterval(t *testing.T) {
 	f.assertPrune()
 }
 
+func TestDockerPrunerIntervalFollowsClock(t *testing.T) {
+	f := newFixture(t)
+	f.withDockerManifestAlreadyBuilt()
+	f.withDockerPruneSettings(true, 0, 0, 30*time.Minute)
+	f.dp.lastPruneTime = f.clock.Now()
+
+	f.clock.Advance(29 * time.Minute)
+	f.dp.OnChange(f.ctx, f.st)
+	f.assertNoPrune()
+
+	f.clock.Advance(time.Minute)
+	f.dp.OnChange(f.ctx, f.st)
+	f.assertPrune()
+}
+
 func TestDockerPrunerSinceDefaultInterval(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
@@ -376,8 +392,9 @@ type dockerPruneFixture struct {
 	logs *bytes.Buffer
 	st   *store.TestingStore
 
-	dCli *docker.FakeClient
-	dp   *DockerPruner
+	dCli  *docker.FakeClient
+	clock clockwork.FakeClock
+	dp    *DockerPruner
 }
 
 func newFixture(t *testing.T) *dockerPruneFixture {
@@ -386,15 +403,17 @@ func newFixture(t *testing.T) *dockerPruneFixture {
 	st := store.NewTestingStore()
 
 	dCli := docker.NewFakeClient()
-	dp := NewDockerPruner(dCli)
+	clock := clockwork.NewFakeClockAt(time.Now())
+	dp := NewDockerPruner(dCli, clock)
 
 	return &dockerPruneFixture{
-		t:    t,
-		ctx:  ctx,
-		logs: logs,
-		st:   st,
-		dCli: dCli,
-		dp:   dp,
+		t:     t,
+		ctx:   ctx,
+		logs:  logs,
+		st:    st,
+		dCli:  dCli,
+		clock: clock,
+		dp:    dp,
 	}
 }
 
@@ -492,7 +511,7 @@ func (dpf *dockerPruneFixture) assertPrune() {
 		dpf.t.Errorf("expected Prune() to be called, but it was not")
 		dpf.t.FailNow()
 	}
-	if time.Since(dpf.dp.lastPruneTime) > time.Second {
+	if !dpf.dp.lastPruneTime.Equal(dpf.clock.Now()) {
 		dpf.t.Errorf("Prune() was called, but dp.lastPruneTime was not updated/" +
 			"not updated recently")
 		dpf.t.FailNow()
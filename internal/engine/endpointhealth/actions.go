@@ -0,0 +1,14 @@
+package endpointhealth
+
+import (
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The results of checking all of a resource's endpoints.
+type CheckAction struct {
+	ManifestName model.ManifestName
+	Results      []store.EndpointHealth
+}
+
+func (CheckAction) Action() {}
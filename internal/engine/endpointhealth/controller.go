@@ -0,0 +1,164 @@
+package endpointhealth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// We only care about the status code, so don't read much of the body.
+const maxBodyBytes = 64 * 1024
+
+// Periodically sends an HTTP GET to each endpoint link of the resources
+// with health checks enabled, so that the UI can show when a port-forward
+// is up but the app behind it is failing.
+type Controller struct {
+	sched  *scheduler.Scheduler
+	clock  build.Clock
+	client *http.Client
+
+	mu       sync.Mutex
+	st       store.RStore
+	settings model.EndpointHealthSettings
+	targets  map[model.ManifestName][]string
+
+	// Stops the periodic check job, if it's running.
+	cancel func()
+}
+
+var _ store.Subscriber = &Controller{}
+
+func NewController(sched *scheduler.Scheduler, clock build.Clock) *Controller {
+	return &Controller{
+		sched:  sched,
+		clock:  clock,
+		client: &http.Client{},
+	}
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	settings := state.EndpointHealthSettings
+	targets := make(map[model.ManifestName][]string)
+	if settings.Enabled {
+		for _, mt := range state.Targets() {
+			if !settings.Includes(mt.Manifest.Name) {
+				continue
+			}
+			var urls []string
+			for _, link := range store.ManifestTargetServiceEndpoints(mt) {
+				urls = append(urls, link.URL)
+			}
+			if len(urls) > 0 {
+				targets[mt.Manifest.Name] = urls
+			}
+		}
+	}
+	st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.st = st
+	c.targets = targets
+
+	old := c.settings
+	c.settings = settings
+	if old.Enabled == settings.Enabled && old.Interval == settings.Interval {
+		return
+	}
+
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	if !settings.Enabled {
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.sched.Every(jobCtx, "endpoint-health", 0, settings.Interval, c.check)
+}
+
+// Checks every endpoint, and reports the results for each resource.
+func (c *Controller) check(ctx context.Context) {
+	c.mu.Lock()
+	st := c.st
+	timeout := c.settings.Timeout
+	targets := make(map[model.ManifestName][]string, len(c.targets))
+	for mn, urls := range c.targets {
+		targets[mn] = urls
+	}
+	c.mu.Unlock()
+
+	if st == nil {
+		return
+	}
+
+	for mn, urls := range targets {
+		results := make([]store.EndpointHealth, len(urls))
+		var wg sync.WaitGroup
+		for i, u := range urls {
+			wg.Add(1)
+			go func(i int, u string) {
+				defer wg.Done()
+				results[i] = c.checkURL(ctx, u, timeout)
+			}(i, u)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return
+		}
+		st.Dispatch(CheckAction{ManifestName: mn, Results: results})
+	}
+}
+
+func (c *Controller) checkURL(ctx context.Context, u string, timeout time.Duration) store.EndpointHealth {
+	result := store.EndpointHealth{URL: u}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		result.Error = err.Error()
+		result.CheckedAt = c.clock.Now()
+		return result
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	result.Latency = time.Since(start)
+	result.CheckedAt = c.clock.Now()
+	if err != nil {
+		result.Error = requestError(ctx, err, timeout)
+		return result
+	}
+
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxBodyBytes))
+	_ = resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+	return result
+}
+
+// The URL is already displayed next to the result, so strip it out of the error.
+func requestError(ctx context.Context, err error, timeout time.Duration) string {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Sprintf("timed out after %s", timeout)
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
@@ -0,0 +1,181 @@
+package endpointhealth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCheckHealthyEndpoint(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	port := f.serve(http.StatusOK)
+	f.enable()
+	f.addResource("fe", port)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	results := f.results("fe")
+	require.Len(t, results, 1)
+	assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	assert.True(t, results[0].Healthy())
+	assert.Equal(t, f.clock.Now(), results[0].CheckedAt)
+}
+
+func TestCheckFailingEndpoint(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	port := f.serve(http.StatusInternalServerError)
+	f.enable()
+	f.addResource("fe", port)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	results := f.results("fe")
+	require.Len(t, results, 1)
+	assert.Equal(t, http.StatusInternalServerError, results[0].StatusCode)
+	assert.False(t, results[0].Healthy())
+}
+
+func TestCheckUnreachableEndpoint(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.addResource("fe", closedPort(t))
+	f.onChange()
+	f.c.check(f.ctx)
+
+	results := f.results("fe")
+	require.Len(t, results, 1)
+	assert.Equal(t, 0, results[0].StatusCode)
+	assert.Contains(t, results[0].Error, "connection refused")
+	assert.NotContains(t, results[0].Error, "http://")
+	assert.False(t, results[0].Healthy())
+}
+
+func TestCheckOnlySelectedResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	port := f.serve(http.StatusOK)
+	f.enable("be")
+	f.addResource("fe", port)
+	f.onChange()
+	f.c.check(f.ctx)
+
+	assert.Empty(t, f.st.Actions())
+}
+
+func TestScheduledOnlyWhenEnabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.onChange()
+	assert.Empty(t, f.sched.JobNames())
+
+	f.enable()
+	f.onChange()
+	assert.Equal(t, []string{"endpoint-health"}, f.sched.JobNames())
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	st     *store.TestingStore
+	sched  *scheduler.Scheduler
+	clock  clockwork.FakeClock
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	clock := clockwork.NewFakeClock()
+	sched := scheduler.NewScheduler(clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		st:     store.NewTestingStore(),
+		sched:  sched,
+		clock:  clock,
+		c:      NewController(sched, clock),
+	}
+}
+
+func (f *fixture) TearDown() {
+	f.cancel()
+}
+
+// Starts a server that responds with the given status code, and returns its port.
+func (f *fixture) serve(status int) int {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	f.t.Cleanup(server.Close)
+
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(f.t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(f.t, err)
+	return port
+}
+
+func (f *fixture) enable(resources ...model.ManifestName) {
+	f.st.WithState(func(state *store.EngineState) {
+		state.EndpointHealthSettings = model.EndpointHealthSettings{
+			Enabled:   true,
+			Interval:  15 * time.Second,
+			Timeout:   3 * time.Second,
+			Resources: resources,
+		}
+	})
+}
+
+func (f *fixture) addResource(name model.ManifestName, port int) {
+	m := model.Manifest{Name: name}.WithDeployTarget(model.K8sTarget{
+		Name:         model.TargetName(name),
+		PortForwards: []model.PortForward{{LocalPort: port, ContainerPort: 8080, Host: "127.0.0.1"}},
+	})
+	f.st.WithState(func(state *store.EngineState) {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	})
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) results(name model.ManifestName) []store.EndpointHealth {
+	for _, action := range f.st.Actions() {
+		a, ok := action.(CheckAction)
+		if ok && a.ManifestName == name {
+			return a.Results
+		}
+	}
+	f.t.Fatalf("No health check results for %s", name)
+	return nil
+}
+
+func closedPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	_ = l.Close()
+	return port
+}
@@ -2,6 +2,7 @@ package exit
 
 import (
 	"context"
+	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 
@@ -34,6 +35,16 @@ func (c *Controller) shouldExit(store store.RStore) Action {
 			return Action{ExitSignal: true, ExitError: err}
 		}
 
+		// If any of the resources' configs failed to load, they'll never build.
+		for _, mt := range state.Targets() {
+			if mt.Manifest.ConfigError != "" {
+				return Action{
+					ExitSignal: true,
+					ExitError:  fmt.Errorf("resource %s has a config error: %s", mt.Manifest.Name, mt.Manifest.ConfigError),
+				}
+			}
+		}
+
 		// If any of the individual builds failed, exit immediately.
 		for _, mt := range state.ManifestTargets {
 			err := mt.State.LastBuild().Error
@@ -52,6 +52,27 @@ func TestExitControlAllSuccess(t *testing.T) {
 	assert.Nil(t, f.store.exitError)
 }
 
+func TestExitControlConfigError(t *testing.T) {
+	f := newFixture(t, store.EngineModeCI)
+	defer f.TearDown()
+
+	f.store.WithState(func(state *store.EngineState) {
+		m := manifestbuilder.New(f, "fe").WithLocalResource("echo hi", nil).Build()
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+
+		m2 := manifestbuilder.New(f, "fe2").WithLocalResource("echo hi", nil).Build().
+			WithConfigError("resource fe2 specified a dependency on unknown resource be")
+		state.UpsertManifestTarget(store.NewManifestTarget(m2))
+	})
+
+	f.c.OnChange(f.ctx, f.store)
+	assert.True(t, f.store.exitSignal)
+	if assert.Error(t, f.store.exitError) {
+		assert.Equal(t, "resource fe2 has a config error: resource fe2 specified a dependency on unknown resource be",
+			f.store.exitError.Error())
+	}
+}
+
 func TestExitControlFirstFailure(t *testing.T) {
 	f := newFixture(t, store.EngineModeApply)
 	defer f.TearDown()
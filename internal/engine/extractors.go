@@ -39,6 +39,14 @@ func extractImageTargetsForLiveUpdates(specs []model.TargetSpec, stateSet store.
 		return nil, fmt.Errorf("Cannot extract live updates on this build graph structure")
 	}
 
+	// Don't live-update some images while another one in the resource
+	// still needs to be built and deployed.
+	for _, spec := range specs {
+		if state := stateSet[spec.ID()]; state.NeedsRebuild {
+			return nil, buildcontrol.RedirectToNextBuilderInfof("Image %s failed to build last time", spec.ID().Name)
+		}
+	}
+
 	result := make([]liveUpdateStateTree, 0)
 
 	deployedImages := g.DeployedImages()
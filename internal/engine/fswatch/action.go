@@ -29,3 +29,20 @@ type GitBranchStatusAction struct {
 }
 
 func (GitBranchStatusAction) Action() {}
+
+// Git changed HEAD or ORIG_HEAD, so a checkout (or merge, or reset) is
+// probably rewriting files in the repo.
+type GitCheckoutStartedAction struct {
+	Time time.Time
+	Repo model.LocalGitRepo
+}
+
+func (GitCheckoutStartedAction) Action() {}
+
+// The files in the repo have stopped changing since the checkout started.
+type GitCheckoutSettledAction struct {
+	Time time.Time
+	Repo model.LocalGitRepo
+}
+
+func (GitCheckoutSettledAction) Action() {}
@@ -7,9 +7,10 @@ import (
 )
 
 type FakeTimerMaker struct {
-	RestTimerLock *sync.Mutex
-	MaxTimerLock  *sync.Mutex
-	t             *testing.T
+	RestTimerLock   *sync.Mutex
+	MaxTimerLock    *sync.Mutex
+	SettleTimerLock *sync.Mutex
+	t               *testing.T
 }
 
 func (f FakeTimerMaker) Maker() TimerMaker {
@@ -21,6 +22,8 @@ func (f FakeTimerMaker) Maker() TimerMaker {
 			lock = f.RestTimerLock
 		case BufferMaxDuration:
 			lock = f.MaxTimerLock
+		case GitCheckoutSettleDuration:
+			lock = f.SettleTimerLock
 		default:
 			// if you hit this, someone (you!?) might have added a new timer with a new duration, and you probably
 			// want to add a case above
@@ -40,6 +43,7 @@ func (f FakeTimerMaker) Maker() TimerMaker {
 func MakeFakeTimerMaker(t *testing.T) FakeTimerMaker {
 	restTimerLock := new(sync.Mutex)
 	maxTimerLock := new(sync.Mutex)
+	settleTimerLock := new(sync.Mutex)
 
-	return FakeTimerMaker{restTimerLock, maxTimerLock, t}
+	return FakeTimerMaker{restTimerLock, maxTimerLock, settleTimerLock, t}
 }
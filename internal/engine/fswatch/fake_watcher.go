@@ -13,6 +13,9 @@ type FakeMultiWatcher struct {
 	Events chan watch.FileEvent
 	Errors chan error
 
+	// When set, new watchers report that they're degraded for this reason.
+	DegradedReason string
+
 	mu         sync.Mutex
 	watchers   []*FakeWatcher
 	subs       []chan watch.FileEvent
@@ -32,6 +35,7 @@ func (w *FakeMultiWatcher) NewSub(paths []string, ignore watch.PathMatcher, _ lo
 	defer w.mu.Unlock()
 
 	watcher := NewFakeWatcher(subCh, errorCh, paths, ignore)
+	watcher.degradedReason = w.DegradedReason
 	w.watchers = append(w.watchers, watcher)
 	w.subs = append(w.subs, subCh)
 	w.subsErrors = append(w.subsErrors, errorCh)
@@ -90,8 +94,9 @@ type FakeWatcher struct {
 	outboundCh chan watch.FileEvent
 	errorCh    chan error
 
-	paths  []string
-	ignore watch.PathMatcher
+	paths          []string
+	ignore         watch.PathMatcher
+	degradedReason string
 }
 
 func NewFakeWatcher(inboundCh chan watch.FileEvent, errorCh chan error, paths []string, ignore watch.PathMatcher) *FakeWatcher {
@@ -139,6 +144,10 @@ func (w *FakeWatcher) Events() chan watch.FileEvent {
 	return w.outboundCh
 }
 
+func (w *FakeWatcher) DegradedReason() string {
+	return w.degradedReason
+}
+
 func (w *FakeWatcher) loop() {
 	var q []watch.FileEvent
 	for {
@@ -157,4 +166,4 @@ func (w *FakeWatcher) loop() {
 	}
 }
 
-var _ watch.Notify = &FakeWatcher{}
+var _ watch.DegradedNotify = &FakeWatcher{}
@@ -6,12 +6,18 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+// After a git checkout starts, wait until the files in the repo
+// haven't changed for this long before rebuilding anything.
+var GitCheckoutSettleDuration = 2 * time.Second
+
 type repoNotifyCancel struct {
 	repo   model.LocalGitRepo
 	notify watch.Notify
@@ -19,14 +25,22 @@ type repoNotifyCancel struct {
 }
 
 // Watches git for branch switches and fires events.
+//
+// A branch switch (or a merge, or a reset) rewrites lots of files at once.
+// We tell the engine when one starts, so that it can hold builds until the
+// files stop changing, and then rebuild each resource once.
 type GitManager struct {
 	fsWatcherMaker FsWatcherMaker
+	timerMaker     TimerMaker
+	clock          build.Clock
 	repoWatches    map[model.LocalGitRepo]repoNotifyCancel
 }
 
-func NewGitManager(fsWatcherMaker FsWatcherMaker) *GitManager {
+func NewGitManager(fsWatcherMaker FsWatcherMaker, timerMaker TimerMaker, clock build.Clock) *GitManager {
 	return &GitManager{
 		fsWatcherMaker: fsWatcherMaker,
+		timerMaker:     timerMaker,
+		clock:          clock,
 		repoWatches:    make(map[model.LocalGitRepo]repoNotifyCancel),
 	}
 }
@@ -83,9 +97,12 @@ func (m *GitManager) OnChange(ctx context.Context, st store.RStore) {
 		// which is where the current branch is stored.
 		// https://git-scm.com/book/en/v2/Git-Internals-Plumbing-and-Porcelain
 		//
+		// Also watch .git/ORIG_HEAD, which git writes before a merge, pull,
+		// or reset moves the current branch.
+		//
 		// Whenever we see a change, we will re-check the current git branch.
 		watcher, err := m.fsWatcherMaker(
-			[]string{gitHeadPath(repo)},
+			[]string{gitHeadPath(repo), gitPath(repo, "ORIG_HEAD")},
 			watch.EmptyMatcher{},
 			logger.Get(ctx))
 		if err != nil {
@@ -107,7 +124,12 @@ func (m *GitManager) OnChange(ctx context.Context, st store.RStore) {
 
 func (m *GitManager) dispatchBranchChangesLoop(ctx context.Context, repo model.LocalGitRepo,
 	watcher watch.Notify, st store.RStore) {
-	m.dispatchGitBranchStatus(st, repo)
+	l := store.NewLogActionLogger(ctx, st.Dispatch)
+	head := m.dispatchGitBranchStatus(st, repo)
+	origHead := readGitFile(repo, "ORIG_HEAD")
+
+	// Non-nil while a checkout is in progress.
+	var settle <-chan time.Time
 
 	for {
 		select {
@@ -119,26 +141,111 @@ func (m *GitManager) dispatchBranchChangesLoop(ctx context.Context, repo model.L
 				return
 			}
 
-			m.dispatchGitBranchStatus(st, repo)
+			newHead := m.dispatchGitBranchStatus(st, repo)
+			newOrigHead := readGitFile(repo, "ORIG_HEAD")
+
+			// Git may be in the middle of rewriting one of these files,
+			// so an empty read doesn't tell us anything.
+			headChanged := newHead != "" && newHead != head
+			origHeadChanged := newOrigHead != "" && newOrigHead != origHead
+			if newHead != "" {
+				head = newHead
+			}
+			if newOrigHead != "" {
+				origHead = newOrigHead
+			}
+			if !headChanged && !origHeadChanged {
+				continue
+			}
+
+			if settle == nil {
+				l.Infof("Detected a git checkout in %s. Waiting for files to stop changing before rebuilding", repo.LocalPath)
+				st.Dispatch(GitCheckoutStartedAction{
+					Time: m.clock.Now(),
+					Repo: repo,
+				})
+			}
+			settle = m.timerMaker(GitCheckoutSettleDuration)
+
+		case <-settle:
+			if !m.repoIsQuiet(st, repo) {
+				settle = m.timerMaker(GitCheckoutSettleDuration)
+				continue
+			}
+
+			settle = nil
+			st.Dispatch(GitCheckoutSettledAction{
+				Time: m.clock.Now(),
+				Repo: repo,
+			})
 		}
 	}
 
 }
 
-func (m *GitManager) dispatchGitBranchStatus(st store.RStore, repo model.LocalGitRepo) {
-	b, err := ioutil.ReadFile(gitHeadPath(repo))
-	if err != nil {
+// Whether git is done with the repo, and nothing we watch in the repo
+// has changed for GitCheckoutSettleDuration.
+func (m *GitManager) repoIsQuiet(st store.RStore, repo model.LocalGitRepo) bool {
+	if ospath.IsRegularFile(gitPath(repo, "index.lock")) {
+		return false
+	}
+
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	cutoff := m.clock.Now().Add(-GitCheckoutSettleDuration)
+	for _, mt := range state.Targets() {
+		if !manifestInRepo(mt.Manifest, repo) {
+			continue
+		}
+		for _, status := range mt.State.BuildStatuses {
+			for _, t := range status.PendingFileChanges {
+				if t.After(cutoff) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func manifestInRepo(m model.Manifest, repo model.LocalGitRepo) bool {
+	for _, r := range store.ManifestGitRepos(m) {
+		if r == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the current HEAD, or the empty string if we couldn't read it.
+func (m *GitManager) dispatchGitBranchStatus(st store.RStore, repo model.LocalGitRepo) string {
+	head := readGitFile(repo, "HEAD")
+	if head == "" {
 		// ignore errors reading the file
-		return
+		return ""
 	}
 
 	st.Dispatch(GitBranchStatusAction{
 		Time: time.Now(),
 		Repo: repo,
-		Head: string(b),
+		Head: head,
 	})
+	return head
+}
+
+func readGitFile(repo model.LocalGitRepo, name string) string {
+	b, err := ioutil.ReadFile(gitPath(repo, name))
+	if err != nil {
+		return ""
+	}
+	return string(b)
 }
 
 func gitHeadPath(repo model.LocalGitRepo) string {
-	return filepath.Join(repo.LocalPath, ".git", "HEAD")
+	return gitPath(repo, "HEAD")
+}
+
+func gitPath(repo model.LocalGitRepo, name string) string {
+	return filepath.Join(repo.LocalPath, ".git", name)
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tilt-dev/tilt/internal/testutils"
@@ -63,19 +65,74 @@ func TestGitManagerRemove(t *testing.T) {
 		reflect.TypeOf(GitBranchStatusAction{}), f.store.Actions)
 }
 
+func TestGitManagerCheckout(t *testing.T) {
+	f := newGMFixture(t)
+	defer f.TearDown()
+
+	repo := model.LocalGitRepo{LocalPath: f.Path()}
+	head := f.JoinPath(".git", "HEAD")
+	f.WriteFile(head, "ref: refs/heads/nicks/branch")
+	f.UpsertManifestTarget("fe", repo)
+	f.gm.OnChange(f.ctx, f.store)
+	f.NextGitBranchStatusAction()
+	f.store.ClearActions()
+
+	// Hold the settle timer until the checkout is done writing files.
+	f.timerMaker.SettleTimerLock.Lock()
+	f.WriteFile(head, "ref: refs/heads/nicks/branch2")
+	f.fakeMultiWatcher.Events <- watch.NewFileEvent(head)
+	started := f.store.WaitForAction(f.T(), reflect.TypeOf(GitCheckoutStartedAction{})).(GitCheckoutStartedAction)
+	assert.Equal(t, repo, started.Repo)
+
+	f.store.WithState(func(state *store.EngineState) {
+		for _, status := range state.ManifestTargets["fe"].State.BuildStatuses {
+			status.PendingFileChanges[f.JoinPath("main.go")] = f.clock.Now()
+		}
+	})
+
+	f.clock.Advance(3 * time.Second)
+	f.timerMaker.SettleTimerLock.Unlock()
+	settled := f.store.WaitForAction(f.T(), reflect.TypeOf(GitCheckoutSettledAction{})).(GitCheckoutSettledAction)
+	assert.Equal(t, repo, settled.Repo)
+}
+
+func TestGitManagerCheckoutOrigHead(t *testing.T) {
+	f := newGMFixture(t)
+	defer f.TearDown()
+
+	repo := model.LocalGitRepo{LocalPath: f.Path()}
+	f.WriteFile(f.JoinPath(".git", "HEAD"), "ref: refs/heads/nicks/branch")
+	f.UpsertManifestTarget("fe", repo)
+	f.gm.OnChange(f.ctx, f.store)
+	f.NextGitBranchStatusAction()
+	f.store.ClearActions()
+
+	// A pull moves the branch without changing HEAD.
+	origHead := f.JoinPath(".git", "ORIG_HEAD")
+	f.WriteFile(origHead, "0123456789abcdef")
+	f.fakeMultiWatcher.Events <- watch.NewFileEvent(origHead)
+	started := f.store.WaitForAction(f.T(), reflect.TypeOf(GitCheckoutStartedAction{})).(GitCheckoutStartedAction)
+	assert.Equal(t, repo, started.Repo)
+	f.store.WaitForAction(f.T(), reflect.TypeOf(GitCheckoutSettledAction{}))
+}
+
 type gmFixture struct {
 	ctx              context.Context
 	cancel           func()
 	store            *store.TestingStore
 	gm               *GitManager
 	fakeMultiWatcher *FakeMultiWatcher
+	timerMaker       FakeTimerMaker
+	clock            clockwork.FakeClock
 	*tempdir.TempDirFixture
 }
 
 func newGMFixture(t *testing.T) *gmFixture {
 	st := store.NewTestingStore()
 	fakeMultiWatcher := NewFakeMultiWatcher()
-	gm := NewGitManager(fakeMultiWatcher.NewSub)
+	timerMaker := MakeFakeTimerMaker(t)
+	clock := clockwork.NewFakeClock()
+	gm := NewGitManager(fakeMultiWatcher.NewSub, timerMaker.Maker(), clock)
 
 	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 	ctx, cancel := context.WithCancel(ctx)
@@ -89,6 +146,8 @@ func newGMFixture(t *testing.T) *gmFixture {
 		store:            st,
 		gm:               gm,
 		fakeMultiWatcher: fakeMultiWatcher,
+		timerMaker:       timerMaker,
+		clock:            clock,
 		TempDirFixture:   f,
 	}
 }
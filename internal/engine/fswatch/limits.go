@@ -0,0 +1,142 @@
+package fswatch
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const limitsAlertID = "watch-limits"
+
+// Checks the operating system's limits on open files and file watches
+// against what we're about to watch.
+//
+// Users with low limits otherwise find out an hour into a session, when
+// something fails with a cryptic "too many open files". We raise our own soft
+// limit on open files where we can, and warn with instructions for the rest.
+type LimitsChecker struct {
+	// Swapped out in tests.
+	readLimits     func() watch.Limits
+	raiseOpenFiles func(want uint64) (uint64, error)
+	goos           string
+
+	targets       map[model.TargetID]WatchableTarget
+	globalIgnores []model.Dockerignore
+	warnings      []string
+}
+
+var _ store.Subscriber = &LimitsChecker{}
+
+func NewLimitsChecker() *LimitsChecker {
+	return &LimitsChecker{
+		readLimits:     watch.ReadLimits,
+		raiseOpenFiles: watch.RaiseOpenFilesLimit,
+		goos:           runtime.GOOS,
+	}
+}
+
+func (c *LimitsChecker) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	watchesFiles := state.EngineMode.WatchesFiles()
+	targets := watchableTargetsForState(state)
+	globalIgnores := globalIgnores(state)
+	st.RUnlockState()
+
+	if !watchesFiles || len(targets) == 0 || !c.targetsChanged(targets, globalIgnores) {
+		return
+	}
+	c.targets = targets
+	c.globalIgnores = globalIgnores
+
+	usage, err := estimateUsage(targets, globalIgnores)
+	if err != nil {
+		logger.Get(ctx).Debugf("Estimating file watch usage: %v", err)
+		return
+	}
+
+	limits := c.readLimits()
+	if watch.DesiredRaiseLimits() && limits.OpenFiles != 0 && limits.OpenFiles < usage.SuggestedOpenFiles() {
+		raised, err := c.raiseOpenFiles(usage.SuggestedOpenFiles())
+		if err != nil {
+			logger.Get(ctx).Debugf("Raising open files limit: %v", err)
+		} else if raised > limits.OpenFiles {
+			logger.Get(ctx).Debugf("Raised open files limit from %d to %d", limits.OpenFiles, raised)
+			limits.OpenFiles = raised
+		}
+	}
+
+	c.updateWarnings(ctx, st, watch.CheckLimits(limits, usage, c.goos))
+}
+
+func (c *LimitsChecker) targetsChanged(targets map[model.TargetID]WatchableTarget, globalIgnores []model.Dockerignore) bool {
+	if c.targets == nil || len(targets) != len(c.targets) {
+		return true
+	}
+	if !cmp.Equal(globalIgnores, c.globalIgnores, cmpopts.EquateEmpty()) {
+		return true
+	}
+	for id, t := range targets {
+		old, ok := c.targets[id]
+		if !ok || !watchRulesMatch(t, old) {
+			return true
+		}
+	}
+	return false
+}
+
+// Each target gets its own watcher, and so does each git repo.
+func estimateUsage(targets map[model.TargetID]WatchableTarget, globalIgnores []model.Dockerignore) (watch.Usage, error) {
+	globalIgnore, err := dockerignoresToMatcher(globalIgnores)
+	if err != nil {
+		return watch.Usage{}, err
+	}
+
+	usage := watch.Usage{}
+	repos := make(map[model.LocalGitRepo]bool)
+	for _, t := range targets {
+		ignore, err := createIgnoreMatcher(t, globalIgnore)
+		if err != nil {
+			return watch.Usage{}, err
+		}
+		usage.Watchers++
+		usage.Dirs += watch.CountWatchedDirs(t.Dependencies(), ignore, 0)
+		for _, repo := range t.LocalRepos() {
+			repos[repo] = true
+		}
+	}
+	usage.Watchers += len(repos)
+	return usage, nil
+}
+
+func (c *LimitsChecker) updateWarnings(ctx context.Context, st store.RStore, warnings []string) {
+	if cmp.Equal(warnings, c.warnings, cmpopts.EquateEmpty()) {
+		return
+	}
+	hadWarnings := len(c.warnings) > 0
+	c.warnings = warnings
+
+	if len(warnings) == 0 {
+		if hadWarnings {
+			st.Dispatch(store.AlertResolvedAction{ID: limitsAlertID})
+		}
+		return
+	}
+
+	for _, w := range warnings {
+		logger.Get(ctx).Warnf("%s", w)
+	}
+	st.Dispatch(store.AlertAction{Alert: model.Alert{
+		ID:       limitsAlertID,
+		Source:   model.AlertSourceWatchLimits,
+		Severity: model.AlertSeverityWarning,
+		Message:  strings.Join(warnings, "\n\n"),
+	}})
+}
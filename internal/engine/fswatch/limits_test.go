@@ -0,0 +1,154 @@
+package fswatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLimitsCheckerOK(t *testing.T) {
+	f := newLimitsFixture(t)
+	defer f.TearDown()
+
+	f.SetManifestTarget(f.Path())
+	assert.Empty(t, f.alerts())
+	assert.Equal(t, 0, f.raisedTo)
+}
+
+func TestLimitsCheckerWatchLimit(t *testing.T) {
+	f := newLimitsFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("a/a.txt", "")
+	f.WriteFile("b/b.txt", "")
+	f.limits.InotifyWatches = 3
+
+	f.SetManifestTarget(f.Path())
+	alerts := f.alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, model.AlertSourceWatchLimits, alerts[0].Source)
+	assert.Contains(t, alerts[0].Message, "about 3 directories")
+	assert.Contains(t, alerts[0].Message, "fs.inotify.max_user_watches")
+
+	// Watching fewer directories resolves the alert.
+	f.SetManifestTarget(f.JoinPath("a"))
+	assert.Equal(t, []string{limitsAlertID}, f.resolved())
+}
+
+func TestLimitsCheckerChecksOnlyOnChange(t *testing.T) {
+	f := newLimitsFixture(t)
+	defer f.TearDown()
+
+	f.limits.InotifyWatches = 1
+	f.SetManifestTarget(f.Path())
+	f.lc.OnChange(f.ctx, f.store)
+	assert.Len(t, f.alerts(), 1)
+}
+
+func TestLimitsCheckerRaisesOpenFiles(t *testing.T) {
+	f := newLimitsFixture(t)
+	defer f.TearDown()
+
+	f.limits.OpenFiles = 256
+	f.SetManifestTarget(f.Path())
+	assert.Equal(t, 10240, f.raisedTo)
+	assert.Empty(t, f.alerts())
+}
+
+func TestLimitsCheckerCantRaiseOpenFiles(t *testing.T) {
+	f := newLimitsFixture(t)
+	defer f.TearDown()
+
+	f.limits.OpenFiles = 256
+	f.limits.OpenFilesMax = 256
+	f.maxOpenFiles = 256
+	f.SetManifestTarget(f.Path())
+
+	alerts := f.alerts()
+	require.Len(t, alerts, 1)
+	assert.Contains(t, alerts[0].Message, "/etc/security/limits.conf")
+}
+
+type limitsFixture struct {
+	*tempdir.TempDirFixture
+	ctx          context.Context
+	cancel       func()
+	store        *store.TestingStore
+	lc           *LimitsChecker
+	limits       watch.Limits
+	maxOpenFiles uint64
+	raisedTo     int
+}
+
+func newLimitsFixture(t *testing.T) *limitsFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ctx, cancel := context.WithCancel(ctx)
+
+	f := &limitsFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            ctx,
+		cancel:         cancel,
+		store:          store.NewTestingStore(),
+		limits: watch.Limits{
+			OpenFiles:        65536,
+			OpenFilesMax:     65536,
+			InotifyWatches:   524288,
+			InotifyInstances: 512,
+		},
+		maxOpenFiles: 65536,
+	}
+	f.lc = &LimitsChecker{
+		readLimits: func() watch.Limits { return f.limits },
+		raiseOpenFiles: func(want uint64) (uint64, error) {
+			if want > f.maxOpenFiles {
+				want = f.maxOpenFiles
+			}
+			f.raisedTo = int(want)
+			return want, nil
+		},
+		goos: "linux",
+	}
+	return f
+}
+
+func (f *limitsFixture) SetManifestTarget(dir string) {
+	target := model.DockerComposeTarget{Name: "foo"}.WithBuildPath(dir)
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(target)
+	state := f.store.LockMutableStateForTesting()
+	state.UpsertManifestTarget(&store.ManifestTarget{Manifest: m})
+	f.store.UnlockMutableState()
+	f.lc.OnChange(f.ctx, f.store)
+}
+
+func (f *limitsFixture) alerts() []model.Alert {
+	var alerts []model.Alert
+	for _, a := range f.store.Actions() {
+		if a, ok := a.(store.AlertAction); ok {
+			alerts = append(alerts, a.Alert)
+		}
+	}
+	return alerts
+}
+
+func (f *limitsFixture) resolved() []string {
+	var ids []string
+	for _, a := range f.store.Actions() {
+		if a, ok := a.(store.AlertResolvedAction); ok {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+func (f *limitsFixture) TearDown() {
+	f.cancel()
+	f.TempDirFixture.TearDown()
+}
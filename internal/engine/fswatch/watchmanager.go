@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tilt-dev/fsnotify"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/dockerignore"
 	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -103,17 +104,19 @@ type WatchManager struct {
 	targetWatches      map[model.TargetID]targetNotifyCancel
 	fsWatcherMaker     FsWatcherMaker
 	timerMaker         TimerMaker
+	clock              build.Clock
 	globalIgnores      []model.Dockerignore
 	globalIgnore       model.PathMatcher
 	disabledForTesting bool
 	mu                 sync.Mutex
 }
 
-func NewWatchManager(watcherMaker FsWatcherMaker, timerMaker TimerMaker) *WatchManager {
+func NewWatchManager(watcherMaker FsWatcherMaker, timerMaker TimerMaker, clock build.Clock) *WatchManager {
 	return &WatchManager{
 		targetWatches:  make(map[model.TargetID]targetNotifyCancel),
 		fsWatcherMaker: watcherMaker,
 		timerMaker:     timerMaker,
+		clock:          clock,
 		globalIgnore:   model.EmptyMatcher,
 	}
 }
@@ -133,15 +136,7 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 	setup = []WatchableTarget{}
 	teardown = []model.TargetID{}
 
-	watchable := WatchableTargetsForManifests(state.Manifests())
-	targetsToProcess := make(map[model.TargetID]WatchableTarget)
-	for _, w := range watchable {
-		targetsToProcess[w.ID()] = w
-	}
-
-	if len(state.ConfigFiles) > 0 {
-		targetsToProcess[ConfigsTargetID] = &configsTarget{dependencies: append([]string(nil), state.ConfigFiles...)}
-	}
+	targetsToProcess := watchableTargetsForState(state)
 
 	newGlobalIgnores := globalIgnores(state)
 	globalIgnoreChanged := !cmp.Equal(newGlobalIgnores, w.globalIgnores, cmpopts.EquateEmpty())
@@ -179,6 +174,19 @@ func (w *WatchManager) diff(ctx context.Context, st store.RStore) (setup []Watch
 	return setup, teardown
 }
 
+// All the targets we should be watching, including the config files.
+func watchableTargetsForState(state store.EngineState) map[model.TargetID]WatchableTarget {
+	targets := make(map[model.TargetID]WatchableTarget)
+	for _, w := range WatchableTargetsForManifests(state.Manifests()) {
+		targets[w.ID()] = w
+	}
+
+	if len(state.ConfigFiles) > 0 {
+		targets[ConfigsTargetID] = &configsTarget{dependencies: append([]string(nil), state.ConfigFiles...)}
+	}
+	return targets
+}
+
 // Return a list of global ignore patterns.
 func globalIgnores(es store.EngineState) []model.Dockerignore {
 	ignores := []model.Dockerignore{}
@@ -194,13 +202,16 @@ func globalIgnores(es store.EngineState) []model.Dockerignore {
 			if customBuild.OutputsImageRefTo != "" {
 				outputs = append(outputs, customBuild.OutputsImageRefTo)
 			}
+			if customBuild.OutputsManifest != "" {
+				outputs = append(outputs, customBuild.OutputsManifest)
+			}
 		}
 	}
 
 	if len(outputs) > 0 {
 		ignores = append(ignores, model.Dockerignore{
 			LocalPath: filepath.Dir(es.TiltfilePath),
-			Source:    "outputs_image_ref_to",
+			Source:    "custom_build outputs",
 			Patterns:  outputs,
 		})
 	}
@@ -262,6 +273,15 @@ func (w *WatchManager) OnChange(ctx context.Context, st store.RStore) {
 			continue
 		}
 
+		if reason := degradedReason(watcher); reason != "" {
+			st.Dispatch(store.AlertAction{Alert: model.Alert{
+				ID:       degradedAlertID(target.ID()),
+				Source:   model.AlertSourceWatchDegraded,
+				Severity: model.AlertSeverityWarning,
+				Message:  reason,
+			}})
+		}
+
 		ctx, cancel := context.WithCancel(ctx)
 		go w.dispatchFileChangesLoop(ctx, target, watcher, st)
 		newWatches[target.ID()] = targetNotifyCancel{target, watcher, cancel}
@@ -278,6 +298,12 @@ func (w *WatchManager) OnChange(ctx context.Context, st store.RStore) {
 		}
 		p.cancel()
 		delete(w.targetWatches, name)
+
+		// If we replaced a degraded watch with another degraded watch, the alert stays.
+		replacement, replaced := newWatches[name]
+		if degradedReason(p.notify) != "" && (!replaced || degradedReason(replacement.notify) == "") {
+			st.Dispatch(store.AlertResolvedAction{ID: degradedAlertID(name)})
+		}
 	}
 
 	for k, v := range newWatches {
@@ -285,12 +311,28 @@ func (w *WatchManager) OnChange(ctx context.Context, st store.RStore) {
 	}
 }
 
+func degradedReason(n watch.Notify) string {
+	d, ok := n.(watch.DegradedNotify)
+	if !ok {
+		return ""
+	}
+	return d.DegradedReason()
+}
+
+func degradedAlertID(id model.TargetID) string {
+	return fmt.Sprintf("watch-degraded:%s", id)
+}
+
 func (w *WatchManager) createIgnoreMatcher(target WatchableTarget) (watch.PathMatcher, error) {
+	return createIgnoreMatcher(target, w.globalIgnore)
+}
+
+func createIgnoreMatcher(target WatchableTarget, globalIgnore model.PathMatcher) (watch.PathMatcher, error) {
 	filter, err := ignore.CreateFileChangeFilter(target)
 	if err != nil {
 		return nil, err
 	}
-	return model.NewCompositeMatcher([]model.PathMatcher{filter, w.globalIgnore}), nil
+	return model.NewCompositeMatcher([]model.PathMatcher{filter, globalIgnore}), nil
 }
 
 func (w *WatchManager) dispatchFileChangesLoop(
@@ -330,6 +372,7 @@ func (w *WatchManager) dispatchFileChangesLoop(
 				return
 			}
 			watchEvent := NewTargetFilesChangedAction(target.ID())
+			watchEvent.Time = w.clock.Now()
 			for _, e := range fsEvents {
 				watchEvent.Files = append(watchEvent.Files, e.Path())
 			}
@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/builder/dockerignore"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/testutils"
 
@@ -220,6 +221,31 @@ func TestWatchManagerShortRead(t *testing.T) {
 	f.store.ClearActions()
 }
 
+func TestWatchManagerDegradedAlert(t *testing.T) {
+	f := newWMFixture(t)
+	defer f.TearDown()
+
+	f.fakeMultiWatcher.DegradedReason = "/src is on a nfs filesystem"
+	target := model.DockerComposeTarget{Name: "foo"}.
+		WithBuildPath(".")
+	f.SetManifestTarget(target)
+
+	action := f.store.WaitForAction(t, reflect.TypeOf(store.AlertAction{}))
+	alert := action.(store.AlertAction).Alert
+	assert.Equal(t, model.AlertSourceWatchDegraded, alert.Source)
+	assert.Equal(t, model.AlertSeverityWarning, alert.Severity)
+	assert.Equal(t, "/src is on a nfs filesystem", alert.Message)
+
+	state := f.store.LockMutableStateForTesting()
+	delete(state.ManifestTargets, "foo")
+	state.ManifestDefinitionOrder = nil
+	f.store.UnlockMutableState()
+	f.wm.OnChange(f.ctx, f.store)
+
+	action = f.store.WaitForAction(t, reflect.TypeOf(store.AlertResolvedAction{}))
+	assert.Equal(t, alert.ID, action.(store.AlertResolvedAction).ID)
+}
+
 type wmFixture struct {
 	ctx              context.Context
 	cancel           func()
@@ -234,7 +260,7 @@ func newWMFixture(t *testing.T) *wmFixture {
 	st := store.NewTestingStore()
 	timerMaker := MakeFakeTimerMaker(t)
 	fakeMultiWatcher := NewFakeMultiWatcher()
-	wm := NewWatchManager(fakeMultiWatcher.NewSub, timerMaker.Maker())
+	wm := NewWatchManager(fakeMultiWatcher.NewSub, timerMaker.Maker(), build.ProvideClock())
 
 	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 	ctx, cancel := context.WithCancel(ctx)
@@ -0,0 +1,344 @@
+package hibernate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// How often we look for idle resources.
+const checkInterval = 30 * time.Second
+
+// How long to wait between attempts to listen on a port-forward's local port.
+// The port-forward may take a moment to let go of it after its pod goes away.
+const listenRetryInterval = time.Second
+
+// Scales idle Kubernetes resources to zero, and scales them back up on the
+// next file change, build, or connection to one of their port-forwards.
+type Controller struct {
+	kCli      k8s.Client
+	sched     *scheduler.Scheduler
+	clock     build.Clock
+	listeners *listeners.Registry
+
+	// Serializes the Kubernetes calls that hibernate and wake resources.
+	opMu sync.Mutex
+
+	mu        sync.Mutex
+	st        store.RStore
+	settings  model.HibernateSettings
+	enabledAt time.Time
+	resources map[model.ManifestName]*resource
+
+	// Kept apart from resources, which OnChange rebuilds on every change.
+	hibernations map[model.ManifestName]*hibernation
+}
+
+// What we know about a resource that might hibernate.
+type resource struct {
+	name         model.ManifestName
+	refs         []v1.ObjectReference
+	portForwards []model.PortForward
+	lastActivity time.Time
+	lastDeploy   time.Time
+	busy         bool
+}
+
+// The state we need to wake a resource back up.
+type hibernation struct {
+	at       time.Time
+	replicas map[v1.ObjectReference]int32
+
+	// Stops listening on the resource's port-forwards.
+	cancel func()
+}
+
+var _ store.SetUpper = &Controller{}
+var _ store.Subscriber = &Controller{}
+
+func NewController(kCli k8s.Client, sched *scheduler.Scheduler, clock build.Clock, listeners *listeners.Registry) *Controller {
+	return &Controller{
+		kCli:         kCli,
+		sched:        sched,
+		clock:        clock,
+		listeners:    listeners,
+		resources:    make(map[model.ManifestName]*resource),
+		hibernations: make(map[model.ManifestName]*hibernation),
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	c.sched.Every(ctx, "hibernate", checkInterval, checkInterval, c.check)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	settings := state.HibernateSettings
+	current := make(map[model.ManifestName]*resource)
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() || !settings.Includes(mt.Manifest.Name) {
+			continue
+		}
+		current[mt.Manifest.Name] = newResource(mt)
+	}
+	st.RUnlockState()
+
+	var toWake []model.ManifestName
+	c.mu.Lock()
+	c.st = st
+	if settings.Enabled && !c.settings.Enabled {
+		c.enabledAt = c.clock.Now()
+	}
+	c.settings = settings
+
+	for mn, h := range c.hibernations {
+		r, ok := current[mn]
+		if !ok {
+			// The resource is gone, so there's nothing left to wake.
+			h.cancel()
+			delete(c.hibernations, mn)
+			continue
+		}
+		if !settings.Enabled || r.busy || r.lastActivity.After(h.at) {
+			toWake = append(toWake, mn)
+		}
+	}
+	c.resources = current
+	c.mu.Unlock()
+
+	for _, mn := range toWake {
+		c.wake(ctx, mn, "it's needed again")
+	}
+}
+
+func newResource(mt *store.ManifestTarget) *resource {
+	ms := mt.State
+	r := &resource{
+		name:         mt.Manifest.Name,
+		lastActivity: ms.LastPortForwardActivity,
+		busy:         ms.IsBuilding(),
+	}
+
+	for _, bs := range ms.BuildStatuses {
+		result, ok := bs.LastResult.(store.K8sBuildResult)
+		if !ok {
+			continue
+		}
+		for _, ref := range result.DeployedRefs {
+			if k8s.IsScalableKind(ref.Kind) {
+				r.refs = append(r.refs, ref)
+			}
+		}
+	}
+
+	for _, pf := range mt.Manifest.K8sTarget().PortForwards {
+		if pf.Service == "" && pf.LocalPort != 0 {
+			r.portForwards = append(r.portForwards, pf)
+		}
+	}
+
+	if ok, earliest := ms.HasPendingChanges(); ok {
+		r.busy = true
+		r.lastActivity = maxTime(r.lastActivity, earliest)
+	}
+
+	lastBuild := ms.LastBuild()
+	r.lastDeploy = lastBuild.FinishTime
+	r.lastActivity = maxTime(r.lastActivity, lastBuild.FinishTime)
+	r.lastActivity = maxTime(r.lastActivity, ms.CurrentBuild.StartTime)
+	return r
+}
+
+// Hibernates every resource that's been idle for longer than the idle timeout.
+func (c *Controller) check(ctx context.Context) {
+	now := c.clock.Now()
+
+	var toHibernate []model.ManifestName
+	c.mu.Lock()
+	if c.settings.Enabled {
+		for mn, r := range c.resources {
+			if c.hibernations[mn] != nil || r.busy || len(r.refs) == 0 {
+				continue
+			}
+			lastActivity := maxTime(r.lastActivity, c.enabledAt)
+			if now.Sub(lastActivity) >= c.settings.IdleTimeout {
+				toHibernate = append(toHibernate, mn)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	for _, mn := range toHibernate {
+		c.hibernate(ctx, mn)
+	}
+}
+
+func (c *Controller) hibernate(ctx context.Context, mn model.ManifestName) {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	r, ok := c.resources[mn]
+	if !ok || c.hibernations[mn] != nil || r.busy {
+		c.mu.Unlock()
+		return
+	}
+	refs := append([]v1.ObjectReference{}, r.refs...)
+	portForwards := append([]model.PortForward{}, r.portForwards...)
+	idleTimeout := c.settings.IdleTimeout
+	c.mu.Unlock()
+
+	replicas := make(map[v1.ObjectReference]int32)
+	for _, ref := range refs {
+		entity, err := c.kCli.GetByReference(ctx, ref)
+		if err != nil {
+			logger.Get(ctx).Debugf("Hibernating %s: getting %s %s: %v", mn, ref.Kind, ref.Name, err)
+			continue
+		}
+		n, ok := entity.Replicas()
+		if !ok || n == 0 {
+			continue
+		}
+
+		err = c.kCli.MergePatch(ctx, ref, k8s.ReplicasPatch(0))
+		if err != nil {
+			logger.Get(ctx).Debugf("Hibernating %s: scaling %s %s: %v", mn, ref.Kind, ref.Name, err)
+			continue
+		}
+		replicas[ref] = n
+	}
+
+	if len(replicas) == 0 {
+		return
+	}
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	st := c.st
+	c.hibernations[mn] = &hibernation{
+		at:       c.clock.Now(),
+		replicas: replicas,
+		cancel:   cancel,
+	}
+	c.mu.Unlock()
+
+	for _, pf := range portForwards {
+		go c.listen(ctx, listenCtx, mn, pf)
+	}
+
+	c.notify(st, mn, fmt.Sprintf(
+		"Hibernating %s: idle for %s. Scaled to 0 replicas; it will wake on the next file change or port-forward request.\n",
+		mn, idleTimeout))
+}
+
+func (c *Controller) wake(ctx context.Context, mn model.ManifestName, reason string) {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	h, ok := c.hibernations[mn]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.hibernations, mn)
+
+	// If we've deployed since hibernating, the deploy already restored
+	// the replica counts from the resource's YAML.
+	r, ok := c.resources[mn]
+	redeployed := ok && r.lastDeploy.After(h.at)
+	st := c.st
+	c.mu.Unlock()
+
+	h.cancel()
+
+	if !redeployed {
+		for ref, n := range h.replicas {
+			err := c.kCli.MergePatch(ctx, ref, k8s.ReplicasPatch(n))
+			if err != nil {
+				logger.Get(ctx).Infof("Waking %s: scaling %s %s back to %d: %v", mn, ref.Kind, ref.Name, n, err)
+			}
+		}
+	}
+
+	c.notify(st, mn, fmt.Sprintf("Waking %s from hibernation: %s\n", mn, reason))
+}
+
+// Listens on a hibernated resource's port-forward, and wakes the resource
+// when someone connects to it.
+//
+// We can't hold the connection open until the pod is up, because the real
+// port-forward needs the port back. So we drop it, and the user retries.
+func (c *Controller) listen(ctx, listenCtx context.Context, mn model.ManifestName, pf model.PortForward) {
+	host := pf.Host
+	if host == "" {
+		host = "localhost"
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(pf.LocalPort))
+
+	l := c.listeners.Add(listeners.KindWakeProxy, mn, "tcp", addr, fmt.Sprintf("wakes %s", mn))
+	defer l.Remove()
+
+	var listener net.Listener
+	for {
+		var err error
+		listener, err = net.Listen("tcp", addr)
+		if err == nil {
+			break
+		}
+		l.SetError(err)
+		select {
+		case <-listenCtx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+	}
+
+	go func() {
+		<-listenCtx.Done()
+		_ = listener.Close()
+	}()
+	l.SetListening()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	l.AddConnection()
+	_ = conn.Close()
+	_ = listener.Close()
+
+	go c.wake(ctx, mn, fmt.Sprintf("request on port %d. Retry it once the pod is running.", pf.LocalPort))
+}
+
+func (c *Controller) notify(st store.RStore, mn model.ManifestName, msg string) {
+	if st == nil {
+		return
+	}
+	st.Dispatch(store.NewLogAction(mn, spanIDForManifest(mn), logger.InfoLvl, nil, []byte(msg)))
+}
+
+func spanIDForManifest(mn model.ManifestName) logstore.SpanID {
+	return logstore.SpanID(fmt.Sprintf("hibernate:%s", mn))
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,318 @@
+package hibernate
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/listeners"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHibernateIdleResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(25 * time.Minute)
+	f.c.check(f.ctx)
+
+	f.assertPatches(`{"spec":{"replicas":0}}`)
+	f.assertLog("Hibernating sancho: idle for 20m0s")
+}
+
+func TestHibernateNotIdleYet(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(10 * time.Minute)
+	f.c.check(f.ctx)
+
+	f.assertPatches()
+}
+
+func TestHibernateDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(time.Hour)
+	f.c.check(f.ctx)
+
+	f.assertPatches()
+}
+
+func TestHibernateOnlySelectedResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable("frontend")
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(time.Hour)
+	f.c.check(f.ctx)
+
+	f.assertPatches()
+}
+
+func TestHibernatePortForwardActivityKeepsAwake(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(15 * time.Minute)
+	f.st.WithState(func(state *store.EngineState) {
+		state.ManifestTargets["sancho"].State.LastPortForwardActivity = f.clock.Now()
+	})
+	f.onChange()
+	f.clock.Advance(10 * time.Minute)
+	f.c.check(f.ctx)
+
+	f.assertPatches()
+}
+
+func TestWakeOnFileChange(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(25 * time.Minute)
+	f.c.check(f.ctx)
+
+	f.st.WithState(func(state *store.EngineState) {
+		mt := state.ManifestTargets["sancho"]
+		mt.State.MutableBuildStatus(mt.Manifest.K8sTarget().ID()).PendingFileChanges["main.go"] = time.Now()
+	})
+	f.onChange()
+
+	f.assertPatches(`{"spec":{"replicas":0}}`, `{"spec":{"replicas":1}}`)
+	f.assertLog("Waking sancho from hibernation")
+}
+
+func TestWakeAfterChangesWhileHibernating(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	// Scaling down deletes pods, which changes the store while we're still
+	// hibernating the resource.
+	f.c.kCli = onPatchClient{FakeK8sClient: f.kCli, onPatch: f.onChange}
+
+	f.enable()
+	f.deploy(nil)
+	f.onChange()
+	f.clock.Advance(25 * time.Minute)
+	f.c.check(f.ctx)
+	f.onChange()
+
+	f.st.WithState(func(state *store.EngineState) {
+		mt := state.ManifestTargets["sancho"]
+		mt.State.MutableBuildStatus(mt.Manifest.K8sTarget().ID()).PendingFileChanges["main.go"] = time.Now()
+	})
+	f.onChange()
+
+	f.assertPatches(`{"spec":{"replicas":0}}`, `{"spec":{"replicas":1}}`)
+	f.assertLog("Waking sancho from hibernation")
+}
+
+func TestWakeOnPortForwardRequest(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	port := freePort(t)
+	f.enable()
+	f.deploy([]model.PortForward{{LocalPort: port, ContainerPort: 8080}})
+	f.onChange()
+	f.clock.Advance(25 * time.Minute)
+	f.c.check(f.ctx)
+	f.assertPatches(`{"spec":{"replicas":0}}`)
+
+	// The listener binds asynchronously, so retry until it's up.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out connecting to port %d: %v", port, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	f.waitForPatches(2)
+	f.assertPatches(`{"spec":{"replicas":0}}`, `{"spec":{"replicas":1}}`)
+	f.assertLog("request on port")
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	kCli   *k8s.FakeK8sClient
+	st     *store.TestingStore
+	sched  *scheduler.Scheduler
+	clock  *fakeClock
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	kCli := k8s.NewFakeK8sClient()
+	sched := scheduler.NewScheduler(clockwork.NewFakeClock())
+	clock := &fakeClock{now: time.Now()}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		kCli:   kCli,
+		st:     store.NewTestingStore(),
+		sched:  sched,
+		clock:  clock,
+		c:      NewController(kCli, sched, clock, listeners.NewRegistry()),
+	}
+}
+
+func (f *fixture) TearDown() {
+	f.cancel()
+}
+
+func (f *fixture) enable(resources ...model.ManifestName) {
+	f.st.WithState(func(state *store.EngineState) {
+		state.HibernateSettings = model.HibernateSettings{
+			Enabled:     true,
+			IdleTimeout: 20 * time.Minute,
+			Resources:   resources,
+		}
+	})
+}
+
+func (f *fixture) deploy(portForwards []model.PortForward) {
+	m := model.Manifest{Name: "sancho"}.WithDeployTarget(model.K8sTarget{
+		Name:         "sancho",
+		PortForwards: portForwards,
+	})
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(f.t, err)
+	f.kCli.InjectEntityByName(entities...)
+
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+
+	mt := store.NewManifestTarget(m)
+	refs := []v1.ObjectReference{}
+	for _, e := range entities {
+		refs = append(refs, e.ToObjectReference())
+	}
+	mt.State.MutableBuildStatus(m.K8sTarget().ID()).LastResult = store.K8sBuildResult{DeployedRefs: refs}
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  f.clock.Now().Add(-time.Minute),
+		FinishTime: f.clock.Now(),
+	})
+	state.UpsertManifestTarget(mt)
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) patches() []string {
+	f.c.opMu.Lock()
+	defer f.c.opMu.Unlock()
+	var result []string
+	for _, call := range f.kCli.MergePatchCalls {
+		assert.Equal(f.t, "sancho", call.Ref.Name)
+		result = append(result, string(call.Patch))
+	}
+	return result
+}
+
+func (f *fixture) waitForPatches(n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(f.patches()) < n {
+		if time.Now().After(deadline) {
+			f.t.Fatalf("Timed out waiting for %d patches. Actual: %v", n, f.patches())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (f *fixture) assertPatches(expected ...string) {
+	assert.Equal(f.t, expected, f.patches())
+}
+
+func (f *fixture) assertLog(expected string) {
+	var logs []string
+	for _, action := range f.st.Actions() {
+		la, ok := action.(store.LogAction)
+		if !ok {
+			continue
+		}
+		if strings.Contains(string(la.Message()), expected) {
+			assert.Equal(f.t, model.ManifestName("sancho"), la.ManifestName())
+			return
+		}
+		logs = append(logs, string(la.Message()))
+	}
+	f.t.Errorf("Expected log %q. Actual: %v", expected, logs)
+}
+
+// Calls onPatch after every patch.
+type onPatchClient struct {
+	*k8s.FakeK8sClient
+	onPatch func()
+}
+
+func (c onPatchClient) MergePatch(ctx context.Context, ref v1.ObjectReference, patch []byte) error {
+	err := c.FakeK8sClient.MergePatch(ctx, ref, patch)
+	c.onPatch()
+	return err
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	return l.Addr().(*net.TCPAddr).Port
+}
@@ -8,14 +8,17 @@ import (
 	"time"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -59,6 +62,60 @@ func NewKINDLoader(env k8s.Env, clusterName k8s.ClusterName) KINDLoader {
 	}
 }
 
+type CRIOLoader interface {
+	LoadToCRIO(ctx context.Context, ref reference.NamedTagged) error
+}
+
+type cmdCRIOLoader struct {
+	env k8s.Env
+}
+
+// Copies an image from the local Docker daemon into the image store
+// of a CRI-O node, so that clusters without a registry can run it.
+func (cl *cmdCRIOLoader) LoadToCRIO(ctx context.Context, ref reference.NamedTagged) error {
+	w := logger.NewMutexWriter(logger.Get(ctx).Writer(logger.InfoLvl))
+
+	// MicroShift runs CRI-O on this machine, so we can copy
+	// straight into its containers-storage.
+	if cl.env == k8s.EnvMicroShift {
+		cmd := exec.CommandContext(ctx, "skopeo", "copy",
+			fmt.Sprintf("docker-daemon:%s", ref.String()),
+			fmt.Sprintf("containers-storage:%s", ref.String()))
+		cmd.Stdout = w
+		cmd.Stderr = w
+		return cmd.Run()
+	}
+
+	// CRC runs CRI-O in a VM. The podman service in the VM shares
+	// CRI-O's image store, so we stream the image to it.
+	save := exec.CommandContext(ctx, "docker", "save", ref.String())
+	load := exec.CommandContext(ctx, "podman", "--remote", "load")
+	save.Stderr = w
+	load.Stdout = w
+	load.Stderr = w
+
+	pipe, err := save.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	load.Stdin = pipe
+
+	err = load.Start()
+	if err != nil {
+		return errors.Wrap(err, "podman load")
+	}
+	err = save.Run()
+	if err != nil {
+		_ = load.Wait()
+		return errors.Wrap(err, "docker save")
+	}
+	return errors.Wrap(load.Wait(), "podman load")
+}
+
+func NewCRIOLoader(env k8s.Env) CRIOLoader {
+	return &cmdCRIOLoader{env: env}
+}
+
 type ImageBuildAndDeployer struct {
 	db               build.DockerBuilder
 	ib               *imageBuilder
@@ -69,12 +126,18 @@ type ImageBuildAndDeployer struct {
 	injectSynclet    bool
 	clock            build.Clock
 	kl               KINDLoader
+	cl               CRIOLoader
 	syncletContainer sidecar.SyncletContainer
+	sessionID        k8s.SessionID
+
+	// Reads the credentials for the registry of an image pull secret.
+	registryAuth func(host string) (username string, password string, err error)
 }
 
 func NewImageBuildAndDeployer(
 	db build.DockerBuilder,
 	customBuilder build.CustomBuilder,
+	clusterBuilder build.ClusterBuilder,
 	k8sClient k8s.Client,
 	env k8s.Env,
 	analytics *analytics.TiltAnalytics,
@@ -82,18 +145,23 @@ func NewImageBuildAndDeployer(
 	c build.Clock,
 	runtime container.Runtime,
 	kl KINDLoader,
+	cl CRIOLoader,
 	syncletContainer sidecar.SyncletContainer,
+	sessionID k8s.SessionID,
 ) *ImageBuildAndDeployer {
 	return &ImageBuildAndDeployer{
 		db:               db,
-		ib:               NewImageBuilder(db, customBuilder, updMode),
+		ib:               NewImageBuilder(db, customBuilder, clusterBuilder, updMode),
 		k8sClient:        k8sClient,
 		env:              env,
 		analytics:        analytics,
 		clock:            c,
 		runtime:          runtime,
 		kl:               kl,
+		cl:               cl,
 		syncletContainer: syncletContainer,
+		sessionID:        sessionID,
+		registryAuth:     docker.ReadRegistryAuth,
 	}
 }
 
@@ -120,13 +188,21 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		})
 	}()
 
+	scanner := newCredentialScanner(st)
+	ctx = scanner.attach(ctx)
+
 	q, err := buildcontrol.NewImageTargetQueue(ctx, iTargets, stateSet, ibd.ib.CanReuseRef)
 	if err != nil {
 		return store.BuildResultSet{}, err
 	}
 
-	// each image target has two stages: one for build, and one for push
-	numStages := q.CountBuilds()*2 + 1
+	iTargetMap := model.ImageTargetsByID(iTargets)
+
+	// each image target has two stages: one for build, and one for push,
+	// plus one to analyze its layers if the Tiltfile asks for it,
+	// plus one per custom_build command that builds images for other resources
+	numStages := q.CountBuilds()*2 + q.CountBuildsMatching(analyzesLayers) +
+		q.CountBuildsMatching(hasOtherCustomBuildOutputs(iTargetMap)) + 1
 
 	reused := q.ReusedResults()
 	hasReusedStep := len(reused) > 0
@@ -162,7 +238,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 
 	var anyLiveUpdate bool
 
-	iTargetMap := model.ImageTargetsByID(iTargets)
+	customOutputs := customBuildOutputs{}
 	err = q.RunBuilds(func(target model.TargetSpec, depResults []store.BuildResult) (store.BuildResult, error) {
 		iTarget, ok := target.(model.ImageTarget)
 		if !ok {
@@ -173,29 +249,61 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		if err != nil {
 			return nil, err
 		}
-
-		refs, err := ibd.ib.Build(ctx, iTarget, ps)
+		pullBaseImages := stateSet[iTarget.ID()].PullBaseImages
+		iTarget = withPullBaseImages(iTarget, pullBaseImages)
+
+		var refs container.TaggedRefs
+		var fromRegistry bool
+		if iTarget.CustomBuildInfo().HasOutputsManifest() {
+			refs, err = ibd.buildCustomOutput(ctx, iTarget, ps, iTargetMap, customOutputs)
+		} else if !pullBaseImages && ibd.canUseRegistryCache(ctx, iTarget, kTarget) {
+			refs, fromRegistry, err = ibd.ib.BuildOrFindInRegistry(ctx, iTarget, ps)
+		} else {
+			refs, err = ibd.ib.Build(ctx, iTarget, ps)
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		err = ibd.push(ctx, refs.LocalRef, ps, iTarget, kTarget)
-		if err != nil {
-			return nil, err
+		var layerAnalysis *model.ImageLayerAnalysis
+		if !fromRegistry {
+			layerAnalysis = ibd.ib.AnalyzeLayers(ctx, iTarget, ps, refs.LocalRef, stateSet[iTarget.ID()].LastResult)
+		}
+
+		var pushedDigest digest.Digest
+		if fromRegistry {
+			ps.StartPipelineStep(ctx, "Pushing %s", container.FamiliarString(refs.LocalRef))
+			ps.Printf(ctx, "Skipping push: the registry already has it")
+			ps.EndPipelineStep(ctx)
+		} else {
+			pushedDigest, err = ibd.push(ctx, refs.LocalRef, ps, iTarget, kTarget)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		anyLiveUpdate = anyLiveUpdate || !iTarget.LiveUpdateInfo().Empty()
-		return store.NewImageBuildResult(iTarget.ID(), refs.LocalRef, refs.ClusterRef), nil
+		return store.NewImageBuildResult(iTarget.ID(), refs.LocalRef, refs.ClusterRef).
+			WithPushedDigest(pushedDigest).
+			WithLayerAnalysis(layerAnalysis), nil
 	})
 
 	newResults := q.NewResults()
 	if err != nil {
 		return newResults, buildcontrol.WrapDontFallBackError(err)
 	}
+	for id, result := range customOutputs.otherResults(iTargetMap) {
+		newResults[id] = result
+	}
+
+	err = scanner.check(ctx, st, ibd.db, newResults)
+	if err != nil {
+		return newResults, buildcontrol.WrapDontFallBackError(err)
+	}
 
 	// (If we pass an empty list of refs here (as we will do if only deploying
 	// yaml), we just don't inject any image refs into the yaml, nbd.
-	k8sResult, err := ibd.deploy(ctx, st, ps, iTargetMap, kTarget, q.AllResults(), anyLiveUpdate)
+	k8sResult, err := ibd.deploy(ctx, st, ps, iTargetMap, kTarget, q.AllResults(), anyLiveUpdate, stateSet[kTarget.ID()])
 	if err != nil {
 		return newResults, buildcontrol.WrapDontFallBackError(err)
 	}
@@ -203,7 +311,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 	return newResults, nil
 }
 
-func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget, kTarget model.K8sTarget) error {
+func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget, kTarget model.K8sTarget) (digest.Digest, error) {
 	ps.StartPipelineStep(ctx, "Pushing %s", container.FamiliarString(ref))
 	defer ps.EndPipelineStep(ctx)
 
@@ -212,29 +320,58 @@ func (ibd *ImageBuildAndDeployer) push(ctx context.Context, ref reference.NamedT
 		cbSkip = iTarget.CustomBuildInfo().SkipsPush()
 	}
 
+	// In-cluster builds push straight from the cluster.
+	if iTarget.IsDockerBuild() && iTarget.DockerBuildInfo().BuildsOnCluster() {
+		ps.Printf(ctx, "Skipping push: the in-cluster build already pushed it")
+		return "", nil
+	}
+
 	// We can also skip the push of the image if it isn't used
 	// in any k8s resources! (e.g., it's consumed by another image).
 	if ibd.canAlwaysSkipPush() || !isImageDeployedToK8s(iTarget, kTarget) || cbSkip {
 		ps.Printf(ctx, "Skipping push")
-		return nil
+		return "", nil
 	}
 
-	var err error
+	return ibd.pushImage(ctx, ref, ps, iTarget)
+}
+
+// Makes the image available to the cluster, by loading it onto the nodes or pushing it to the registry.
+func (ibd *ImageBuildAndDeployer) pushImage(ctx context.Context, ref reference.NamedTagged, ps *build.PipelineState, iTarget model.ImageTarget) (digest.Digest, error) {
 	if ibd.shouldUseKINDLoad(ctx, iTarget) {
 		ps.Printf(ctx, "Loading image to KIND")
 		err := ibd.kl.LoadToKIND(ps.AttachLogger(ctx), ref)
 		if err != nil {
-			return fmt.Errorf("Error loading image to KIND: %v", err)
+			return "", fmt.Errorf("Error loading image to KIND: %v", err)
 		}
-	} else {
-		ps.Printf(ctx, "Pushing with Docker client")
-		err = ibd.db.PushImage(ps.AttachLogger(ctx), ref)
+	} else if ibd.shouldUseCRIOLoad(ctx, iTarget) {
+		ps.Printf(ctx, "Loading image to CRI-O node")
+		err := ibd.cl.LoadToCRIO(ps.AttachLogger(ctx), ref)
 		if err != nil {
-			return err
+			return "", fmt.Errorf("Error loading image to CRI-O node: %v", err)
 		}
+	} else {
+		ps.Printf(ctx, "Pushing with Docker client")
+		return ibd.db.PushImage(ps.AttachLogger(ctx), ref)
 	}
 
-	return nil
+	return "", nil
+}
+
+// The registry cache only helps if we push the image to a registry,
+// where other machines can find it.
+func (ibd *ImageBuildAndDeployer) canUseRegistryCache(ctx context.Context, iTarget model.ImageTarget, kTarget model.K8sTarget) bool {
+	if !iTarget.IsDockerBuild() {
+		return false
+	}
+	db := iTarget.DockerBuildInfo()
+	if !db.RegistryCache || db.BuildsOnCluster() {
+		return false
+	}
+	if ibd.canAlwaysSkipPush() || !isImageDeployedToK8s(iTarget, kTarget) {
+		return false
+	}
+	return !ibd.shouldUseKINDLoad(ctx, iTarget) && !ibd.shouldUseCRIOLoad(ctx, iTarget)
 }
 
 func (ibd *ImageBuildAndDeployer) shouldUseKINDLoad(ctx context.Context, iTarg model.ImageTarget) bool {
@@ -258,9 +395,25 @@ func (ibd *ImageBuildAndDeployer) shouldUseKINDLoad(ctx context.Context, iTarg m
 	return true
 }
 
+// Local CRI-O clusters (CRC, MicroShift) can't see the Docker daemon's images.
+// When they don't have a registry, we load the image onto the node instead.
+func (ibd *ImageBuildAndDeployer) shouldUseCRIOLoad(ctx context.Context, iTarg model.ImageTarget) bool {
+	if !ibd.env.UsesCRIONodeLoad() || ibd.runtime != container.RuntimeCrio {
+		return false
+	}
+
+	if iTarg.HasDistinctClusterRef() {
+		return false
+	}
+
+	registry := ibd.k8sClient.LocalRegistry(ctx)
+	return registry.Empty()
+}
+
 // Returns: the entities deployed and the namespace of the pod with the given image name/tag.
 func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, ps *build.PipelineState,
-	iTargetMap map[model.TargetID]model.ImageTarget, kTarget model.K8sTarget, results store.BuildResultSet, needsSynclet bool) (store.BuildResult, error) {
+	iTargetMap map[model.TargetID]model.ImageTarget, kTarget model.K8sTarget, results store.BuildResultSet, needsSynclet bool,
+	kState store.BuildState) (store.BuildResult, error) {
 	ps.StartPipelineStep(ctx, "Deploying")
 	defer ps.EndPipelineStep(ctx)
 
@@ -273,16 +426,43 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 
 	ctx = ibd.indentLogger(ctx)
 	l := logger.Get(ctx)
+	lastResult := kState.LastResult
+
+	// Hash the entities before applying them, because the apply fills in
+	// server-side fields.
+	entitiesHash, err := k8s.HashEntities(newK8sEntities, k8s.NewSessionKeys(kTarget.ObjectLabels.Prefix))
+	if err != nil {
+		// We just can't tell whether the entities changed, so apply anyway.
+		l.Debugf("%v", err)
+	}
+	if entitiesHash != "" && entitiesUnchanged(kState, entitiesHash) {
+		l.Infof("Deploy skipped (no changes): the rendered YAML is the same as the last deploy")
+		return kState.LastAppliedK8sResult.WithDeploySkipped(true), nil
+	}
 
 	l.Infof("Applying via kubectl:")
-	for _, displayName := range kTarget.DisplayNames {
+	for _, displayName := range kTarget.ManagedDisplayNames() {
 		l.Infof("→ %s", displayName)
 	}
+	if len(kTarget.ObservedObjectRefs) > 0 {
+		l.Infof("Not applying %d observed object(s) (k8s_yaml(manage=False))", len(kTarget.ObservedObjectRefs))
+	}
 
 	state := st.RLockState()
 	us := state.UpdateSettings
+	rollbackOnFailure := state.RollbackOnFailure
 	st.RUnlockState()
 
+	var rollbackEntities []k8s.K8sEntity
+	if rollbackOnFailure {
+		rollbackEntities = ibd.recordRollback(ctx, newK8sEntities, lastResult)
+	}
+
+	if !kTarget.ImagePullSecret.Empty() {
+		ibd.refreshImagePullSecret(ctx, kTarget.ImagePullSecret, newK8sEntities, us.K8sUpsertTimeout())
+	}
+
+	ctx = k8s.WithEntitySources(ctx, kTarget.ObjectSources)
 	deployed, err := ibd.k8sClient.Upsert(ctx, newK8sEntities, us.K8sUpsertTimeout())
 	if err != nil {
 		return nil, err
@@ -297,14 +477,121 @@ func (ibd *ImageBuildAndDeployer) deploy(ctx context.Context, st store.RStore, p
 			return nil, fmt.Errorf("Entity not deployed correctly: %v", entity)
 		}
 		uids = append(uids, entity.UID())
-		hs, err := k8s.ReadPodTemplateSpecHashes(entity)
+		hs, err := k8s.ReadPodTemplateSpecHashes(entity, k8s.ToImageLocators(kTarget.ImageLocators))
 		if err != nil {
 			return nil, errors.Wrap(err, "reading pod template spec hashes")
 		}
 		podTemplateSpecHashes = append(podTemplateSpecHashes, hs...)
 	}
 
-	return store.NewK8sDeployResult(kTarget.ID(), uids, podTemplateSpecHashes, deployed), nil
+	return store.NewK8sDeployResult(kTarget.ID(), uids, podTemplateSpecHashes, deployed).
+		WithRollbackEntities(rollbackEntities).
+		WithAppliedEntitiesHash(entitiesHash), nil
+}
+
+// Returns true if the rendered entities are the same as the ones we applied
+// in the last successful deploy. A trigger always re-applies, in case someone
+// changed or deleted the objects in the cluster.
+func entitiesUnchanged(state store.BuildState, entitiesHash string) bool {
+	if state.FullBuildTriggered {
+		return false
+	}
+	return state.LastAppliedK8sResult.AppliedEntitiesHash == entitiesHash
+}
+
+// Fetches the live version of each workload that we're about to apply, so that
+// we can roll it back if the new version doesn't become healthy.
+//
+// If we already deployed a workload, keeps the version from before our first deploy.
+func (ibd *ImageBuildAndDeployer) recordRollback(ctx context.Context, entities []k8s.K8sEntity, lastResult store.BuildResult) []k8s.K8sEntity {
+	recorded := make(map[string]k8s.K8sEntity)
+	deployedBefore := make(map[string]bool)
+	if lr, ok := lastResult.(store.K8sBuildResult); ok {
+		for _, e := range lr.RollbackEntities {
+			recorded[rollbackKey(e.ToObjectReference())] = e
+		}
+		for _, ref := range lr.DeployedRefs {
+			deployedBefore[rollbackKey(ref)] = true
+		}
+	}
+
+	var result []k8s.K8sEntity
+	for _, e := range entities {
+		if !k8s.IsRollbackable(e) {
+			continue
+		}
+
+		ref := e.ToObjectReference()
+		ref.Namespace = string(e.Namespace())
+		key := rollbackKey(ref)
+		if previous, ok := recorded[key]; ok {
+			result = append(result, previous)
+			continue
+		}
+		if deployedBefore[key] {
+			// We created it, so there's nothing to roll back to.
+			continue
+		}
+
+		live, err := ibd.k8sClient.GetByReference(ctx, ref)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Get(ctx).Warnf("Can't roll back %s if it fails: %v", e.Name(), err)
+			}
+			continue
+		}
+		result = append(result, live)
+	}
+	return result
+}
+
+func rollbackKey(ref v1.ObjectReference) string {
+	return fmt.Sprintf("%s/%s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+// Creates the image pull secret in each namespace that we're deploying pods to.
+// Registry credentials can expire (e.g., gcloud access tokens), so we refresh
+// it on every deploy.
+//
+// If we can't, the pods might still be able to pull (e.g., if someone created
+// the secret by hand), so we warn instead of failing the deploy.
+func (ibd *ImageBuildAndDeployer) refreshImagePullSecret(ctx context.Context, secret model.ImagePullSecret, entities []k8s.K8sEntity, timeout time.Duration) {
+	l := logger.Get(ctx)
+	username, password, err := ibd.registryAuth(secret.Host)
+	if err != nil {
+		l.Warnf("Image pull secret %s: %v", secret.Name, err)
+		return
+	}
+
+	var secrets []k8s.K8sEntity
+	seen := make(map[string]bool)
+	for _, e := range entities {
+		pods, err := k8s.ExtractPods(&e)
+		if err != nil || len(pods) == 0 {
+			continue
+		}
+		ns := e.NamespaceOrDefault("")
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+
+		s, err := k8s.NewImagePullSecret(secret.Name, ns, secret.Host, username, password)
+		if err != nil {
+			l.Warnf("Image pull secret %s: %v", secret.Name, err)
+			return
+		}
+		l.Infof("Refreshing image pull secret %s in namespace %s", secret.Name, e.Namespace())
+		secrets = append(secrets, s)
+	}
+	if len(secrets) == 0 {
+		return
+	}
+
+	_, err = ibd.k8sClient.Upsert(ctx, secrets, timeout)
+	if err != nil {
+		l.Warnf("Image pull secret %s: %v", secret.Name, err)
+	}
 }
 
 func (ibd *ImageBuildAndDeployer) indentLogger(ctx context.Context) context.Context {
@@ -339,82 +626,138 @@ func (ibd *ImageBuildAndDeployer) createEntitiesToDeploy(ctx context.Context,
 	injectedDepIDs := map[model.TargetID]bool{}
 	for _, e := range entities {
 		injectedSynclet := false
-		e, err = k8s.InjectLabels(e, []model.LabelPair{
-			k8s.TiltManagedByLabel(),
-		})
+		objectLabels := k8sTarget.ObjectLabels
+		injectLabels := func(e k8s.K8sEntity) (k8s.K8sEntity, error) {
+			return k8s.InjectLabels(e, append([]model.LabelPair{
+				k8s.TiltManagedByLabel(),
+			}, objectLabels.Labels...))
+		}
+		e, err = injectLabels(e)
 		if err != nil {
 			return nil, errors.Wrap(err, "deploy")
 		}
+		e, err = k8s.InjectIntoPodTemplates(e, locators, injectLabels)
+		if err != nil {
+			return nil, errors.Wrap(err, "deploy")
+		}
+		e = k8s.InjectAnnotations(e, objectLabels.Annotations)
+
+		e = k8s.InjectSession(e, k8s.NewSessionKeys(objectLabels.Prefix), ibd.sessionID, ibd.clock.Now())
+
+		// Everything that goes into a Deployment's pod template also goes into
+		// the pod templates of workloads like Argo Rollouts (k8s_kind(pod_template_json_path=)).
+		injectPods := func(e k8s.K8sEntity) (k8s.K8sEntity, error) {
+			var err error
+
+			// If we're redeploying these workloads in response to image
+			// changes, we make sure image pull policy isn't set to "Always".
+			// Frequent applies don't work well with this setting, and makes things
+			// slower. See discussion:
+			// https://github.com/tilt-dev/tilt/issues/3209
+			if len(iTargetMap) > 0 {
+				e, err = k8s.InjectImagePullPolicy(e, v1.PullIfNotPresent)
+				if err != nil {
+					return k8s.K8sEntity{}, err
+				}
+			}
 
-		// If we're redeploying these workloads in response to image
-		// changes, we make sure image pull policy isn't set to "Always".
-		// Frequent applies don't work well with this setting, and makes things
-		// slower. See discussion:
-		// https://github.com/tilt-dev/tilt/issues/3209
-		if len(iTargetMap) > 0 {
-			e, err = k8s.InjectImagePullPolicy(e, v1.PullIfNotPresent)
-			if err != nil {
-				return nil, err
+			// Shrink prod-sized pods to fit on the dev cluster. We do this at deploy
+			// time, so that the YAML in the Tiltfile stays prod-accurate.
+			if k8sTarget.DevResourceProfile.AppliesTo(ibd.env.IsDevCluster()) {
+				e, err = k8s.InjectDevResourceProfile(e, k8sTarget.DevResourceProfile)
+				if err != nil {
+					return k8s.K8sEntity{}, err
+				}
 			}
-		}
 
-		// StatefulSet pods should be managed in parallel. See discussion:
-		// https://github.com/tilt-dev/tilt/issues/1962
-		e = k8s.InjectParallelPodManagementPolicy(e)
+			e, err = k8s.InjectPodReplacement(e, k8sTarget.PodReplacement)
+			if err != nil {
+				return k8s.K8sEntity{}, err
+			}
 
-		// When working with a local k8s cluster, we set the pull policy to Never,
-		// to ensure that k8s fails hard if the image is missing from docker.
-		policy := v1.PullIfNotPresent
-		if ibd.canAlwaysSkipPush() {
-			policy = v1.PullNever
-		}
+			// StatefulSet pods should be managed in parallel, unless the user
+			// asked for ordered pod management. See discussion:
+			// https://github.com/tilt-dev/tilt/issues/1962
+			if !k8sTarget.OrderedPodManagement {
+				e = k8s.InjectParallelPodManagementPolicy(e)
+			}
 
-		for _, depID := range depIDs {
-			ref := store.ClusterImageRefFromBuildResult(results[depID])
-			if ref == nil {
-				return nil, fmt.Errorf("Internal error: missing image build result for dependency ID: %s", depID)
+			// CronJobs run when the user asks Tilt to run them now, rather than on
+			// their schedule. The cronjob controller restores the schedule when Tilt exits.
+			e, err = k8s.InjectCronJobSuspend(e)
+			if err != nil {
+				return k8s.K8sEntity{}, err
 			}
 
-			iTarget := iTargetMap[depID]
-			selector := iTarget.Refs.ConfigurationRef
-			matchInEnvVars := iTarget.MatchInEnvVars
+			// The autoscale controller pauses the autoscalers, so that they don't
+			// scale the workload back up.
+			if k8sTarget.PauseAutoscaling {
+				e, err = k8s.InjectSingleReplica(e)
+				if err != nil {
+					return k8s.K8sEntity{}, err
+				}
+			}
 
-			var replaced bool
-			e, replaced, err = k8s.InjectImageDigest(e, selector, ref, locators, matchInEnvVars, policy)
-			if err != nil {
-				return nil, err
+			// When working with a local k8s cluster, we set the pull policy to Never,
+			// to ensure that k8s fails hard if the image is missing from docker.
+			policy := v1.PullIfNotPresent
+			if ibd.canAlwaysSkipPush() {
+				policy = v1.PullNever
 			}
-			if replaced {
-				injectedDepIDs[depID] = true
 
-				if !iTarget.OverrideCmd.Empty() || iTarget.OverrideArgs.ShouldOverride {
-					e, err = k8s.InjectCommandAndArgs(e, ref, iTarget.OverrideCmd, iTarget.OverrideArgs)
-					if err != nil {
-						return nil, err
-					}
+			for _, depID := range depIDs {
+				ref, err := store.PinnedClusterImageRefFromBuildResult(results[depID])
+				if err != nil {
+					return k8s.K8sEntity{}, err
+				}
+				if ref == nil {
+					return k8s.K8sEntity{}, fmt.Errorf("Internal error: missing image build result for dependency ID: %s", depID)
 				}
 
-				if ibd.injectSynclet && needsSynclet && !injectedSynclet {
-					injectedRefSelector := container.NewRefSelector(ref).WithExactMatch()
+				iTarget := iTargetMap[depID]
+				selector := iTarget.Refs.ConfigurationRef
+				matchInEnvVars := iTarget.MatchInEnvVars
 
-					var sidecarInjected bool
-					e, sidecarInjected, err = sidecar.InjectSyncletSidecar(e, injectedRefSelector, ibd.syncletContainer)
-					if err != nil {
-						return nil, err
+				var replaced bool
+				e, replaced, err = k8s.InjectImageDigest(e, selector, ref, locators, matchInEnvVars, policy)
+				if err != nil {
+					return k8s.K8sEntity{}, err
+				}
+				if replaced {
+					injectedDepIDs[depID] = true
+
+					if !iTarget.OverrideCmd.Empty() || iTarget.OverrideArgs.ShouldOverride {
+						e, err = k8s.InjectCommandAndArgs(e, ref, iTarget.OverrideCmd, iTarget.OverrideArgs)
+						if err != nil {
+							return k8s.K8sEntity{}, err
+						}
 					}
-					if !sidecarInjected {
-						return nil, fmt.Errorf("Could not inject synclet: %v", e)
+
+					if ibd.injectSynclet && needsSynclet && !injectedSynclet {
+						injectedRefSelector := container.NewRefSelector(ref).WithExactMatch()
+
+						var sidecarInjected bool
+						e, sidecarInjected, err = sidecar.InjectSyncletSidecar(e, injectedRefSelector, ibd.syncletContainer)
+						if err != nil {
+							return k8s.K8sEntity{}, err
+						}
+						if !sidecarInjected {
+							return k8s.K8sEntity{}, fmt.Errorf("Could not inject synclet: %v", e)
+						}
+						injectedSynclet = true
 					}
-					injectedSynclet = true
 				}
 			}
-		}
 
-		// This needs to be after all the other injections, to ensure the hash includes the Tilt-generated
-		// image tag, etc
-		e, err := k8s.InjectPodTemplateSpecHashes(e)
+			return e, nil
+		}
+		e, err = injectPods(e)
 		if err != nil {
-			return nil, errors.Wrap(err, "injecting pod template hash")
+			return nil, err
+		}
+		e, err = k8s.InjectIntoPodTemplates(e, locators, injectPods)
+		if err != nil {
+			return nil, err
 		}
 
 		newK8sEntities = append(newK8sEntities, e)
@@ -426,6 +769,43 @@ func (ibd *ImageBuildAndDeployer) createEntitiesToDeploy(ctx context.Context,
 		}
 	}
 
+	// The user's transform sees the objects as we're about to deploy them,
+	// with the images injected.
+	newK8sEntities, err = k8s.ApplyTransform(ctx, k8sTarget.Transform, newK8sEntities)
+	if err != nil {
+		return nil, errors.Wrapf(err, "k8s_resource %q", k8sTarget.Name)
+	}
+
+	// Pods reference the pull secret for the private registry that we pushed to.
+	injectImagePullSecret := func(e k8s.K8sEntity) (k8s.K8sEntity, error) {
+		return k8s.InjectImagePullSecret(e, k8sTarget.ImagePullSecret.Name)
+	}
+	for i, e := range newK8sEntities {
+		e, err = injectImagePullSecret(e)
+		if err != nil {
+			return nil, err
+		}
+		e, err = k8s.InjectIntoPodTemplates(e, locators, injectImagePullSecret)
+		if err != nil {
+			return nil, err
+		}
+		newK8sEntities[i] = e
+	}
+
+	for i, e := range newK8sEntities {
+		// This needs to be after all the other injections (and the transform), to ensure the hash
+		// includes the Tilt-generated image tag, etc
+		e, err = k8s.InjectPodTemplateSpecHashes(e)
+		if err != nil {
+			return nil, errors.Wrap(err, "injecting pod template hash")
+		}
+		e, err = k8s.InjectIntoPodTemplates(e, locators, k8s.InjectPodTemplateSpecHashes)
+		if err != nil {
+			return nil, errors.Wrap(err, "injecting pod template hash")
+		}
+		newK8sEntities[i] = e
+	}
+
 	return newK8sEntities, nil
 }
 
@@ -438,6 +818,20 @@ func (ibd *ImageBuildAndDeployer) canAlwaysSkipPush() bool {
 }
 
 // Create a new ImageTarget with the Dockerfiles rewritten with the injected images.
+// A newer version of the image's base image is in the registry,
+// so have Docker pull it instead of building on the local copy.
+//
+// The registry cache doesn't know which base image an image was built on,
+// so callers shouldn't use it for these builds.
+func withPullBaseImages(iTarget model.ImageTarget, pull bool) model.ImageTarget {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	if !ok || !pull {
+		return iTarget
+	}
+	db.PullParent = true
+	return iTarget.WithBuildDetails(db)
+}
+
 func injectImageDependencies(iTarget model.ImageTarget, iTargetMap map[model.TargetID]model.ImageTarget, deps []store.BuildResult) (model.ImageTarget, error) {
 	if len(deps) == 0 {
 		return iTarget, nil
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
@@ -18,8 +19,10 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -32,6 +35,9 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+// The digest that the fake registry reports when we push.
+const pushedDigest = "sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1"
+
 func TestDeployTwinImages(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvGKE)
 	defer f.TearDown()
@@ -49,8 +55,136 @@ func TestDeployTwinImages(t *testing.T) {
 	expectedImage := "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95"
 	image := store.ClusterImageRefFromBuildResult(result[id])
 	assert.Equal(t, expectedImage, image.String())
-	assert.Equalf(t, 2, strings.Count(f.k8s.Yaml, expectedImage),
-		"Expected image to update twice in YAML: %s", f.k8s.Yaml)
+	assert.Equalf(t, 2, strings.Count(f.k8s.Yaml, expectedImage+"@"+pushedDigest),
+		"Expected pinned image to update twice in YAML: %s", f.k8s.Yaml)
+}
+
+func TestDeployInjectsSessionHeartbeat(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	// The session label only goes on the top-level object, so that
+	// a new session doesn't change the pod template.
+	assert.Equal(t, 1, strings.Count(f.k8s.Yaml, k8s.SessionLabel))
+	assert.Contains(t, f.k8s.Yaml, k8s.HeartbeatAnnotation)
+	assert.Contains(t, f.k8s.Yaml, "2019-01-01T01:01:01Z")
+
+	k8sResult := result[manifest.DeployTarget().ID()].(store.K8sBuildResult)
+	require.Equal(t, 1, len(k8sResult.DeployedRefs))
+	assert.Equal(t, "sancho", k8sResult.DeployedRefs[0].Name)
+}
+
+const sanchoWithResourcesYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sancho
+spec:
+  selector:
+    matchLabels:
+      app: sancho
+  template:
+    metadata:
+      labels:
+        app: sancho
+    spec:
+      containers:
+      - name: sancho
+        image: gcr.io/some-project-162817/sancho
+        resources:
+          requests:
+            cpu: "2"
+`
+
+func TestDeployScalesResourcesOnDevCluster(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND6)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifestWithYaml(f, sanchoWithResourcesYAML)
+	kTarget := manifest.K8sTarget().WithDevResourceProfile(model.DevResourceProfile{Scale: 0.25, LocalOnly: true})
+	manifest = manifest.WithDeployTarget(kTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.Contains(t, f.k8s.Yaml, "cpu: 500m")
+}
+
+func TestDeployRefreshesImagePullSecret(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+	f.ibd.registryAuth = func(host string) (string, string, error) {
+		assert.Equal(t, "gcr.io/some-project", host)
+		return "oauth2accesstoken", "s3cret", nil
+	}
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	kTarget := manifest.K8sTarget()
+	kTarget.ImagePullSecret = model.ImagePullSecret{Name: "tilt-registry", Host: "gcr.io/some-project"}
+	manifest = manifest.WithDeployTarget(kTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.Contains(t, f.out.String(), "Refreshing image pull secret tilt-registry in namespace default")
+	assert.Contains(t, f.k8s.Yaml, "imagePullSecrets:\n      - name: tilt-registry")
+}
+
+func TestDeployWarnsWithoutRegistryCredentials(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+	f.ibd.registryAuth = func(host string) (string, string, error) {
+		return "", "", fmt.Errorf("no credentials for gcr.io in your docker config")
+	}
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	kTarget := manifest.K8sTarget()
+	kTarget.ImagePullSecret = model.ImagePullSecret{Name: "tilt-registry", Host: "gcr.io"}
+	manifest = manifest.WithDeployTarget(kTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.Contains(t, f.out.String(), "Image pull secret tilt-registry: no credentials for gcr.io")
+	assert.Contains(t, f.k8s.Yaml, "- name: tilt-registry")
+}
+
+func TestRecordRollbackKeepsVersionBeforeFirstDeploy(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	deployment := entities[0]
+	live := deployment.WithNamespace("default")
+	f.k8s.InjectEntityByName(live)
+
+	recorded := f.ibd.recordRollback(f.ctx, []k8s.K8sEntity{deployment}, nil)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, live, recorded[0])
+
+	// After we deploy, the live object is ours, so keep the one from before.
+	f.k8s.InjectEntityByName(deployment.DeepCopy())
+	lastResult := store.K8sBuildResult{}.WithRollbackEntities(recorded)
+	assert.Equal(t, recorded, f.ibd.recordRollback(f.ctx, []k8s.K8sEntity{deployment}, lastResult))
+
+	// A workload we created has nothing to roll back to.
+	lastResult = store.K8sBuildResult{DeployedRefs: []corev1.ObjectReference{live.ToObjectReference()}}
+	assert.Empty(t, f.ibd.recordRollback(f.ctx, []k8s.K8sEntity{deployment}, lastResult))
+}
+
+func TestDeployDoesntScaleResourcesOnRemoteCluster(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifestWithYaml(f, sanchoWithResourcesYAML)
+	kTarget := manifest.K8sTarget().WithDevResourceProfile(model.DevResourceProfile{Scale: 0.25, LocalOnly: true})
+	manifest = manifest.WithDeployTarget(kTarget)
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.Contains(t, f.k8s.Yaml, `cpu: "2"`)
 }
 
 func TestForceUpdate(t *testing.T) {
@@ -70,6 +204,23 @@ func TestForceUpdate(t *testing.T) {
 	assert.Equal(t, 1, strings.Count(f.k8s.DeletedYaml, "Deployment"))
 }
 
+func TestPullBaseImages(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	m := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(m), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.False(t, f.docker.BuildOptions.PullParent)
+
+	stateSet := store.BuildStateSet{
+		m.ImageTargets[0].ID(): store.BuildState{}.WithPullBaseImages(true),
+	}
+	_, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(m), stateSet)
+	require.NoError(t, err)
+	assert.True(t, f.docker.BuildOptions.PullParent)
+}
+
 func TestDeployPodWithMultipleImages(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvGKE)
 	defer f.TearDown()
@@ -162,6 +313,27 @@ func TestNoImageTargets(t *testing.T) {
 	assert.Contains(t, f.k8s.Yaml, "imagePullPolicy: Always")
 }
 
+func TestDeployInjectsObjectLabels(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	manifest = manifest.WithDeployTarget(manifest.K8sTarget().WithObjectLabels(model.K8sObjectLabels{
+		Prefix:      "example.com",
+		Labels:      []model.LabelPair{{Key: "team", Value: "payments"}},
+		Annotations: []model.LabelPair{{Key: "example.com/owner", Value: "alice"}},
+	}))
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	// On the Deployment and its pod template.
+	assert.Equal(t, 2, strings.Count(f.k8s.Yaml, "team: payments"))
+	assert.Equal(t, 1, strings.Count(f.k8s.Yaml, "example.com/owner: alice"))
+	assert.Contains(t, f.k8s.Yaml, "example.com/session:")
+	assert.Contains(t, f.k8s.Yaml, "example.com/heartbeat:")
+	assert.NotContains(t, f.k8s.Yaml, k8s.SessionLabel)
+}
+
 func TestImageIsClean(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvGKE)
 	defer f.TearDown()
@@ -385,6 +557,43 @@ func TestKINDLoad(t *testing.T) {
 	assert.Equal(t, 1, f.docker.BuildCount)
 	assert.Equal(t, 1, f.kl.loadCount)
 	assert.Equal(t, 0, f.docker.PushCount)
+
+	// Nothing was pushed, so there's no digest to pin to.
+	assert.NotContains(t, f.k8s.Yaml, "@sha256:")
+}
+
+func TestCRIOLoad(t *testing.T) {
+	for _, env := range []k8s.Env{k8s.EnvCRC, k8s.EnvMicroShift} {
+		t.Run(string(env), func(t *testing.T) {
+			f := newIBDFixtureWithRuntime(t, env, container.RuntimeCrio)
+			defer f.TearDown()
+
+			manifest := NewSanchoDockerBuildManifest(f)
+			_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, f.docker.BuildCount)
+			assert.Equal(t, 1, f.cl.loadCount)
+			assert.Equal(t, 0, f.docker.PushCount)
+		})
+	}
+}
+
+func TestDockerPushIfCRIOAndRegistry(t *testing.T) {
+	f := newIBDFixtureWithRuntime(t, k8s.EnvMicroShift, container.RuntimeCrio)
+	defer f.TearDown()
+
+	f.k8s.Registry = container.MustNewRegistry("localhost:5000")
+	manifest := NewSanchoDockerBuildManifest(f)
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 0, f.cl.loadCount)
+	assert.Equal(t, 1, f.docker.PushCount)
 }
 
 func TestDockerPushIfKINDAndClusterRef(t *testing.T) {
@@ -411,6 +620,124 @@ func TestDockerPushIfKINDAndClusterRef(t *testing.T) {
 	assert.NotContains(t, yaml, iTarg.Refs.LocalRef().String(), "LocalRef was NOT injected into applied YAML")
 }
 
+func TestImageLayerAnalysis(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := f.withLayerAnalysis(NewSanchoDockerBuildManifest(f))
+	f.docker.SavedImages = map[string][]byte{
+		"gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95": savedImageTarball(t, "app/sancho", 3*1000*1000),
+	}
+
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	analyses := result.ImageLayerAnalyses()
+	require.Len(t, analyses, 1)
+	assert.Equal(t, int64(3*1000*1000), analyses[0].Size)
+	assert.Equal(t, "COPY . /app", analyses[0].Layers[0].CreatedBy)
+	assert.Contains(t, f.out.String(), "Analyzing image layers")
+	assert.Contains(t, f.out.String(), "/app/sancho (3MB)")
+
+	// The next build compares against this one. Trigger it, so that it
+	// rebuilds instead of reusing the image from the last build.
+	f.docker.SavedImages["gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95"] =
+		savedImageTarball(t, "app/sancho", 303*1000*1000)
+	stateSet := f.resultsToNextState(result)
+	iID := manifest.ImageTargetAt(0).ID()
+	stateSet[iID] = stateSet[iID].WithFullBuildTriggered(true)
+	_, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.docker.BuildCount)
+	assert.Contains(t, f.out.String(), "Grew by 300MB since the last build")
+}
+
+func TestDeploySkippedWhenEntitiesUnchanged(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.False(t, result.DeploySkipped())
+
+	// The next deploy renders the same YAML, so we don't apply it.
+	f.k8s.Yaml = ""
+	result, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), f.resultsToNextState(result))
+	require.NoError(t, err)
+	assert.True(t, result.DeploySkipped())
+	assert.Equal(t, "", f.k8s.Yaml)
+	assert.Contains(t, f.out.String(), "Deploy skipped (no changes)")
+
+	// A trigger always applies.
+	stateSet := f.resultsToNextState(result)
+	kID := manifest.K8sTarget().ID()
+	stateSet[kID] = stateSet[kID].WithFullBuildTriggered(true)
+	result, err = f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), stateSet)
+	require.NoError(t, err)
+	assert.False(t, result.DeploySkipped())
+	assert.Contains(t, f.k8s.Yaml, "sancho")
+}
+
+func TestImageLayerAnalysisFailureDoesntFailBuild(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := f.withLayerAnalysis(NewSanchoDockerBuildManifest(f))
+	result, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+	assert.Empty(t, result.ImageLayerAnalyses())
+	assert.Contains(t, f.out.String(), "Skipping: error saving")
+}
+
+func TestRegistryCacheHit(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := f.withRegistryCache(NewSanchoDockerBuildManifest(f))
+	cachedRef := f.contentTaggedRef(manifest.ImageTargetAt(0))
+	f.docker.RegistryImages = map[string]bool{cachedRef: true}
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, f.docker.RegistryLookupCount)
+	assert.Equal(t, 0, f.docker.BuildCount)
+	assert.Equal(t, 0, f.docker.PushCount)
+	assert.Contains(t, f.k8s.Yaml, cachedRef)
+	assert.Contains(t, f.out.String(), "Skipping build: found")
+}
+
+func TestRegistryCacheMiss(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvGKE)
+	defer f.TearDown()
+
+	manifest := f.withRegistryCache(NewSanchoDockerBuildManifest(f))
+	cachedRef := f.contentTaggedRef(manifest.ImageTargetAt(0))
+
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, f.docker.RegistryLookupCount)
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, cachedRef, f.docker.TagTarget)
+	assert.Equal(t, cachedRef, f.docker.PushImage)
+	assert.Contains(t, f.k8s.Yaml, cachedRef)
+}
+
+func TestRegistryCacheSkippedForKINDLoad(t *testing.T) {
+	f := newIBDFixture(t, k8s.EnvKIND6)
+	defer f.TearDown()
+
+	manifest := f.withRegistryCache(NewSanchoDockerBuildManifest(f))
+	_, err := f.ibd.BuildAndDeploy(f.ctx, f.st, buildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, f.docker.RegistryLookupCount)
+	assert.Equal(t, 1, f.docker.BuildCount)
+	assert.Equal(t, 1, f.kl.loadCount)
+}
+
 func TestCustomBuildDisablePush(t *testing.T) {
 	f := newIBDFixture(t, k8s.EnvKIND6)
 	defer f.TearDown()
@@ -540,7 +867,7 @@ func TestDeployInjectImageEnvVar(t *testing.T) {
 
 	c := d.Spec.Template.Spec.Containers[0]
 	// container image always gets injected
-	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", c.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+pushedDigest, c.Image)
 	expectedEnv := []corev1.EnvVar{
 		// sancho2 gets injected here because it sets match_in_env_vars in docker_build
 		{Name: "foo", Value: "gcr.io/some-project-162817/sancho2:tilt-11cd0b38bc3ceb95"},
@@ -581,7 +908,7 @@ func TestDeployInjectsOverrideCommand(t *testing.T) {
 	c := d.Spec.Template.Spec.Containers[0]
 
 	// Make sure container ref injection worked as expected
-	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", c.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+pushedDigest, c.Image)
 
 	assert.Equal(t, cmd.Argv, c.Command)
 	assert.Empty(t, c.Args)
@@ -771,8 +1098,8 @@ func TestInjectOverrideCommandsMultipleImages(t *testing.T) {
 	sidecarContainer := d.Spec.Template.Spec.Containers[1]
 
 	// Make sure container ref injection worked as expected
-	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", sanchoContainer.Image)
-	assert.Equal(t, "gcr.io/some-project-162817/sancho-sidecar:tilt-11cd0b38bc3ceb95", sidecarContainer.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+pushedDigest, sanchoContainer.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho-sidecar:tilt-11cd0b38bc3ceb95@"+pushedDigest, sidecarContainer.Image)
 
 	assert.Equal(t, cmd1.Argv, sanchoContainer.Command)
 	assert.Equal(t, cmd2.Argv, sidecarContainer.Command)
@@ -914,9 +1241,14 @@ type ibdFixture struct {
 	ibd    *ImageBuildAndDeployer
 	st     *store.TestingStore
 	kl     *fakeKINDLoader
+	cl     *fakeCRIOLoader
 }
 
 func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
+	return newIBDFixtureWithRuntime(t, env, container.RuntimeDocker)
+}
+
+func newIBDFixtureWithRuntime(t *testing.T, env k8s.Env, runtime container.Runtime) *ibdFixture {
 	f := tempdir.NewTempDirFixture(t)
 	dir := dirs.NewWindmillDirAt(f.Path())
 
@@ -931,9 +1263,11 @@ func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
 	ctx, _, ta := testutils.CtxAndAnalyticsForTest()
 	ctx = logger.WithLogger(ctx, l)
 	kClient := k8s.NewFakeK8sClient()
+	kClient.Runtime = runtime
 	kl := &fakeKINDLoader{}
+	cl := &fakeCRIOLoader{}
 	clock := fakeClock{time.Date(2019, 1, 1, 1, 1, 1, 1, time.UTC)}
-	ibd, err := provideImageBuildAndDeployer(ctx, docker, kClient, env, dir, clock, kl, ta)
+	ibd, err := provideImageBuildAndDeployer(ctx, docker, kClient, env, dir, clock, kl, cl, ta)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -946,6 +1280,7 @@ func newIBDFixture(t *testing.T, env k8s.Env) *ibdFixture {
 		ibd:            ibd,
 		st:             store.NewTestingStore(),
 		kl:             kl,
+		cl:             cl,
 	}
 }
 
@@ -954,10 +1289,36 @@ func (f *ibdFixture) TearDown() {
 	f.TempDirFixture.TearDown()
 }
 
+func (f *ibdFixture) withRegistryCache(m model.Manifest) model.Manifest {
+	iTarget := m.ImageTargetAt(0)
+	db := iTarget.DockerBuildInfo()
+	db.RegistryCache = true
+	return m.WithImageTarget(iTarget.WithBuildDetails(db))
+}
+
+func (f *ibdFixture) withLayerAnalysis(m model.Manifest) model.Manifest {
+	iTarget := m.ImageTargetAt(0)
+	db := iTarget.DockerBuildInfo()
+	db.AnalyzeLayers = true
+	return m.WithImageTarget(iTarget.WithBuildDetails(db))
+}
+
+func (f *ibdFixture) contentTaggedRef(iTarget model.ImageTarget) string {
+	tag, err := build.ContentTag(iTarget.DockerBuildInfo(), ignore.CreateBuildContextFilter(iTarget))
+	require.NoError(f.T(), err)
+	refs, err := iTarget.Refs.AddTagSuffix(tag)
+	require.NoError(f.T(), err)
+	return refs.LocalRef.String()
+}
+
 func (f *ibdFixture) resultsToNextState(results store.BuildResultSet) store.BuildStateSet {
 	stateSet := store.BuildStateSet{}
 	for id, result := range results {
-		stateSet[id] = store.NewBuildState(result, nil, nil)
+		state := store.NewBuildState(result, nil, nil)
+		if kResult, ok := result.(store.K8sBuildResult); ok {
+			state = state.WithLastAppliedK8sResult(kResult)
+		}
+		stateSet[id] = state
 	}
 	return stateSet
 }
@@ -979,3 +1340,39 @@ func (kl *fakeKINDLoader) LoadToKIND(ctx context.Context, ref reference.NamedTag
 	kl.loadCount++
 	return nil
 }
+
+type fakeCRIOLoader struct {
+	loadCount int
+}
+
+func (cl *fakeCRIOLoader) LoadToCRIO(ctx context.Context, ref reference.NamedTagged) error {
+	cl.loadCount++
+	return nil
+}
+
+// An image in the format of `docker save`, with one layer that has one file.
+func savedImageTarball(t *testing.T, path string, size int) []byte {
+	layer := &bytes.Buffer{}
+	lw := tar.NewWriter(layer)
+	require.NoError(t, lw.WriteHeader(&tar.Header{Name: path, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(size)}))
+	_, err := lw.Write(make([]byte, size))
+	require.NoError(t, err)
+	require.NoError(t, lw.Close())
+
+	image := &bytes.Buffer{}
+	iw := tar.NewWriter(image)
+	for _, file := range []struct {
+		name     string
+		contents []byte
+	}{
+		{"manifest.json", []byte(`[{"Config": "config.json", "Layers": ["1/layer.tar"]}]`)},
+		{"config.json", []byte(`{"history": [{"created_by": "COPY . /app # buildkit"}]}`)},
+		{"1/layer.tar", layer.Bytes()},
+	} {
+		require.NoError(t, iw.WriteHeader(&tar.Header{Name: file.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(file.contents))}))
+		_, err := iw.Write(file.contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, iw.Close())
+	return image.Bytes()
+}
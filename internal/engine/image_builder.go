@@ -56,20 +56,27 @@ var ImageBuildCount = &view.View{
 type imageBuilder struct {
 	db         build.DockerBuilder
 	custb      build.CustomBuilder
+	clusterb   build.ClusterBuilder
 	updateMode buildcontrol.UpdateMode
 }
 
-func NewImageBuilder(db build.DockerBuilder, custb build.CustomBuilder, updateMode buildcontrol.UpdateMode) *imageBuilder {
+func NewImageBuilder(db build.DockerBuilder, custb build.CustomBuilder, clusterb build.ClusterBuilder, updateMode buildcontrol.UpdateMode) *imageBuilder {
 	return &imageBuilder{
 		db:         db,
 		custb:      custb,
+		clusterb:   clusterb,
 		updateMode: updateMode,
 	}
 }
 
 func (icb *imageBuilder) CanReuseRef(ctx context.Context, iTarget model.ImageTarget, ref reference.NamedTagged) (bool, error) {
-	switch iTarget.BuildDetails.(type) {
+	switch bd := iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
+		if bd.BuildsOnCluster() {
+			// The image only exists in the registry, and we don't want to
+			// pull it just to check. So rebuild to be safe.
+			return false, nil
+		}
 		return icb.db.ImageExists(ctx, ref)
 	case model.CustomBuild:
 		// Custom build doesn't have a good way to check if the ref still exists in the image
@@ -81,12 +88,28 @@ func (icb *imageBuilder) CanReuseRef(ctx context.Context, iTarget model.ImageTar
 }
 
 func (icb *imageBuilder) Build(ctx context.Context, iTarget model.ImageTarget,
-	ps *build.PipelineState) (refs container.TaggedRefs, err error) {
+	ps *build.PipelineState) (container.TaggedRefs, error) {
+	refs, _, err := icb.build(ctx, iTarget, ps, false)
+	return refs, err
+}
+
+// Like Build, but for a docker_build(registry_cache=True), first checks whether the
+// registry already has an image built from the same inputs.
+//
+// Returns true if the image came from the registry, and so wasn't built.
+// Only use this if the image is going to be pushed to the registry.
+func (icb *imageBuilder) BuildOrFindInRegistry(ctx context.Context, iTarget model.ImageTarget,
+	ps *build.PipelineState) (container.TaggedRefs, bool, error) {
+	return icb.build(ctx, iTarget, ps, true)
+}
+
+func (icb *imageBuilder) build(ctx context.Context, iTarget model.ImageTarget,
+	ps *build.PipelineState, useRegistryCache bool) (refs container.TaggedRefs, fromRegistry bool, err error) {
 	userFacingRefName := container.FamiliarString(iTarget.Refs.ConfigurationRef)
 	startTime := time.Now()
 	ctx, err = tag.New(ctx, tag.Upsert(KeyImageRef, userFacingRefName))
 	if err != nil {
-		return container.TaggedRefs{}, err
+		return container.TaggedRefs{}, false, err
 	}
 
 	defer func() {
@@ -105,28 +128,89 @@ func (icb *imageBuilder) Build(ctx context.Context, iTarget model.ImageTarget,
 
 	switch bd := iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
+		if bd.BuildsOnCluster() {
+			ps.StartPipelineStep(ctx, "Building Dockerfile in cluster: [%s]", userFacingRefName)
+			defer ps.EndPipelineStep(ctx)
+
+			refs, err = icb.clusterb.Build(ctx, ps, iTarget.Refs, bd,
+				ignore.CreateBuildContextFilter(iTarget))
+			if err != nil {
+				return container.TaggedRefs{}, false, err
+			}
+			break
+		}
+
 		ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
 		defer ps.EndPipelineStep(ctx)
 
+		if useRegistryCache && bd.RegistryCache {
+			return icb.buildWithRegistryCache(ctx, iTarget, bd, ps)
+		}
+
 		refs, err = icb.db.BuildImage(ctx, ps, iTarget.Refs, bd,
 			ignore.CreateBuildContextFilter(iTarget))
 
 		if err != nil {
-			return container.TaggedRefs{}, err
+			return container.TaggedRefs{}, false, err
 		}
 	case model.CustomBuild:
 		ps.StartPipelineStep(ctx, "Building Custom Build: [%s]", userFacingRefName)
 		defer ps.EndPipelineStep(ctx)
 		refs, err = icb.custb.Build(ctx, iTarget.Refs, bd)
 		if err != nil {
-			return container.TaggedRefs{}, err
+			return container.TaggedRefs{}, false, err
 		}
 	default:
 		// Theoretically this should never trip b/c we `validate` the manifest beforehand...?
 		// If we get here, something is very wrong.
-		return container.TaggedRefs{}, fmt.Errorf("image %q has no valid buildDetails (neither "+
+		return container.TaggedRefs{}, false, fmt.Errorf("image %q has no valid buildDetails (neither "+
 			"DockerBuild nor CustomBuild)", iTarget.Refs.ConfigurationRef)
 	}
 
-	return refs, nil
+	return refs, false, nil
+}
+
+// Runs a custom_build that writes an outputs manifest, and returns
+// every image it built, keyed by ref.
+func (icb *imageBuilder) BuildCustomOutputs(ctx context.Context, iTarget model.ImageTarget,
+	ps *build.PipelineState) (map[string]container.TaggedRefs, error) {
+	ps.StartPipelineStep(ctx, "Building Custom Build: [%s]", container.FamiliarString(iTarget.Refs.ConfigurationRef))
+	defer ps.EndPipelineStep(ctx)
+	return icb.custb.BuildOutputs(ctx, iTarget.Refs.Registry(), iTarget.CustomBuildInfo())
+}
+
+// Tags the image with a hash of its inputs. If the registry already has that tag,
+// use it. Otherwise, build the image and tag it, so that the push shares it.
+func (icb *imageBuilder) buildWithRegistryCache(ctx context.Context, iTarget model.ImageTarget,
+	bd model.DockerBuild, ps *build.PipelineState) (container.TaggedRefs, bool, error) {
+	filter := ignore.CreateBuildContextFilter(iTarget)
+	contentTag, err := build.ContentTag(bd, filter)
+	if err != nil {
+		return container.TaggedRefs{}, false, err
+	}
+
+	cachedRefs, err := iTarget.Refs.AddTagSuffix(contentTag)
+	if err != nil {
+		return container.TaggedRefs{}, false, err
+	}
+
+	exists, err := icb.db.ImageExistsInRegistry(ctx, cachedRefs.LocalRef)
+	if err != nil {
+		// The cache is only an optimization, so fall back to building.
+		ps.Printf(ctx, "Couldn't check registry for %s: %v", container.FamiliarString(cachedRefs.LocalRef), err)
+	} else if exists {
+		ps.Printf(ctx, "Skipping build: found %s in registry", container.FamiliarString(cachedRefs.LocalRef))
+		return cachedRefs, true, nil
+	}
+
+	refs, err := icb.db.BuildImage(ctx, ps, iTarget.Refs, bd, filter)
+	if err != nil {
+		return container.TaggedRefs{}, false, err
+	}
+
+	refs, err = icb.db.TagRefsWith(ctx, iTarget.Refs, refs.LocalRef, contentTag)
+	if err != nil {
+		return container.TaggedRefs{}, false, err
+	}
+	return refs, false, nil
 }
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/go-units"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Layers smaller than this don't get a list of their largest files.
+const layerFilesThreshold = 1000 * 1000
+
+// Size changes smaller than this are noise.
+const layerGrowthThreshold = 1000 * 1000
+
+// Whether we analyze the target's layers after building it,
+// with update_settings(image_layer_analysis=True).
+func analyzesLayers(target model.TargetSpec) bool {
+	iTarget, ok := target.(model.ImageTarget)
+	if !ok {
+		return false
+	}
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	// Images built in the cluster aren't in the local Docker daemon.
+	return ok && db.AnalyzeLayers && !db.BuildsOnCluster()
+}
+
+// Breaks down the size of the image we just built by layer, and prints a summary.
+//
+// The analysis is just for information, so if it fails, the build doesn't.
+// Returns nil if the target doesn't ask for analysis, or it failed.
+func (icb *imageBuilder) AnalyzeLayers(ctx context.Context, iTarget model.ImageTarget, ps *build.PipelineState,
+	ref reference.NamedTagged, last store.BuildResult) *model.ImageLayerAnalysis {
+	if !analyzesLayers(iTarget) {
+		return nil
+	}
+
+	ps.StartPipelineStep(ctx, "Analyzing image layers: [%s]", container.FamiliarString(ref))
+	defer ps.EndPipelineStep(ctx)
+
+	analysis, err := icb.db.AnalyzeLayers(ctx, ref)
+	if err != nil {
+		ps.Printf(ctx, "Skipping: %v", err)
+		return nil
+	}
+
+	var lastAnalysis *model.ImageLayerAnalysis
+	if lastImage, ok := last.(store.ImageBuildResult); ok {
+		lastAnalysis = lastImage.LayerAnalysis
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(layerAnalysisSummary(analysis, lastAnalysis), "\n"), "\n") {
+		ps.Printf(ctx, "%s", line)
+	}
+	return &analysis
+}
+
+// Prints the image size, how it changed since the last build, and the layers biggest first.
+func layerAnalysisSummary(a model.ImageLayerAnalysis, last *model.ImageLayerAnalysis) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Size: %s, efficiency: %.1f%% (%s wasted)\n",
+		units.HumanSize(float64(a.Size)), a.Efficiency()*100, units.HumanSize(float64(a.WastedBytes))))
+
+	if last != nil {
+		growth := a.Size - last.Size
+		if growth >= layerGrowthThreshold {
+			sb.WriteString(fmt.Sprintf("Grew by %s since the last build\n", units.HumanSize(float64(growth))))
+		} else if -growth >= layerGrowthThreshold {
+			sb.WriteString(fmt.Sprintf("Shrank by %s since the last build\n", units.HumanSize(float64(-growth))))
+		}
+	}
+
+	sb.WriteString("Layers:\n")
+	for _, layer := range a.Layers {
+		if layer.Size == 0 {
+			continue
+		}
+		createdBy := layer.CreatedBy
+		if createdBy == "" {
+			createdBy = "(unknown)"
+		}
+		sb.WriteString(fmt.Sprintf("  %10s  %s\n", units.HumanSize(float64(layer.Size)), createdBy))
+		if layer.Size < layerFilesThreshold {
+			continue
+		}
+		for _, f := range layer.LargestFiles {
+			sb.WriteString(fmt.Sprintf("  %10s    %s (%s)\n", "", f.Path, units.HumanSize(float64(f.Size))))
+		}
+	}
+	return sb.String()
+}
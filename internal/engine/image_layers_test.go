@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLayerAnalysisSummary(t *testing.T) {
+	a := model.ImageLayerAnalysis{
+		Size:        310 * 1000 * 1000,
+		WastedBytes: 31 * 1000 * 1000,
+		Layers: []model.ImageLayer{
+			{CreatedBy: "ADD file:abc in /", Size: 10 * 1000 * 1000},
+			{CreatedBy: "EXPOSE 8000"},
+			{
+				CreatedBy: "RUN npm install",
+				Size:      300 * 1000 * 1000,
+				LargestFiles: []model.ImageLayerFile{
+					{Path: "/app/node_modules/.cache/big.pack", Size: 250 * 1000 * 1000},
+				},
+			},
+		},
+	}
+	last := &model.ImageLayerAnalysis{Size: 10 * 1000 * 1000}
+
+	assert.Equal(t, `Size: 310MB, efficiency: 90.0% (31MB wasted)
+Grew by 300MB since the last build
+Layers:
+        10MB  ADD file:abc in /
+       300MB  RUN npm install
+                /app/node_modules/.cache/big.pack (250MB)
+`, layerAnalysisSummary(a, last))
+}
+
+func TestLayerAnalysisSummaryNoChange(t *testing.T) {
+	a := model.ImageLayerAnalysis{Size: 1000}
+	assert.NotContains(t, layerAnalysisSummary(a, &model.ImageLayerAnalysis{Size: 2000}), "since the last build")
+}
@@ -0,0 +1,12 @@
+package k8scredentials
+
+// Dispatched when the cluster starts or stops rejecting the credentials
+// from our exec credential plugin.
+type RejectedAction struct {
+	Rejected bool
+
+	// A command the user can run in their own terminal to log in again.
+	ReauthCommand string
+}
+
+func (RejectedAction) Action() {}
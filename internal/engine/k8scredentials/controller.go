@@ -0,0 +1,71 @@
+package k8scredentials
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+const checkInterval = 5 * time.Second
+
+// Watches for the cluster to reject the credentials from our exec credential
+// plugin (aws-iam-authenticator, gcloud, kubelogin, etc), e.g., when an MFA
+// session expires mid-session.
+//
+// The plugin can't prompt for a new login while the HUD owns the terminal,
+// so we tell the user how to log in again from another terminal.
+type Controller struct {
+	creds *k8s.ExecCredentials
+	st    store.RStore
+	sched *scheduler.Scheduler
+
+	mu       sync.Mutex
+	rejected bool
+}
+
+var _ store.SetUpper = &Controller{}
+var _ store.Subscriber = &Controller{}
+
+func NewController(creds *k8s.ExecCredentials, st store.RStore, sched *scheduler.Scheduler) *Controller {
+	return &Controller{
+		creds: creds,
+		st:    st,
+		sched: sched,
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	if c.creds == nil {
+		return
+	}
+	c.sched.Every(ctx, "k8s-credentials", checkInterval, checkInterval, c.check)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {}
+
+func (c *Controller) check(ctx context.Context) {
+	rejected := c.creds.Rejected()
+
+	c.mu.Lock()
+	changed := rejected != c.rejected
+	c.rejected = rejected
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if rejected {
+		logger.Get(ctx).Warnf("Kubernetes rejected the credentials for context %q from %q.\n"+
+			"To log in again, run this in another terminal:\n  %s",
+			c.creds.KubeContext(), c.creds.Command(), c.creds.ReauthCommand())
+	} else {
+		logger.Get(ctx).Infof("Kubernetes accepted the new credentials for context %q", c.creds.KubeContext())
+	}
+	c.st.Dispatch(RejectedAction{Rejected: rejected, ReauthCommand: c.creds.ReauthCommand()})
+}
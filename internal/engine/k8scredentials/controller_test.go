@@ -0,0 +1,104 @@
+package k8scredentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestNoExecPlugin(t *testing.T) {
+	f := newFixture(t, nil)
+
+	f.c.SetUp(f.ctx)
+	assert.Equal(t, 0, len(f.sched.JobNames()))
+}
+
+func TestRejectedOnce(t *testing.T) {
+	f := newFixture(t, &clientcmdapi.ExecConfig{
+		Command:    "true",
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+	})
+
+	f.c.SetUp(f.ctx)
+	assert.Equal(t, []string{"k8s-credentials"}, f.sched.JobNames())
+
+	f.c.check(f.ctx)
+	assert.Equal(t, 0, len(f.st.Actions()))
+
+	f.request(http.StatusUnauthorized)
+	f.c.check(f.ctx)
+	f.c.check(f.ctx)
+	require.Equal(t, 1, len(f.st.Actions()))
+	assert.Equal(t, RejectedAction{
+		Rejected:      true,
+		ReauthCommand: "kubectl --context eks-dev get namespaces",
+	}, f.st.Actions()[0])
+
+	f.request(http.StatusOK)
+	f.c.check(f.ctx)
+	require.Equal(t, 2, len(f.st.Actions()))
+	assert.Equal(t, false, f.st.Actions()[1].(RejectedAction).Rejected)
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	st     *store.TestingStore
+	sched  *scheduler.Scheduler
+	config *rest.Config
+	c      *Controller
+	server *httptest.Server
+	status int
+}
+
+func newFixture(t *testing.T, exec *clientcmdapi.ExecConfig) *fixture {
+	f := &fixture{t: t}
+	// client-go only reads auth info for TLS clusters.
+	f.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(f.status)
+	}))
+	t.Cleanup(f.server.Close)
+
+	apiConfig := clientcmdapi.NewConfig()
+	apiConfig.Clusters["eks"] = &clientcmdapi.Cluster{Server: f.server.URL, InsecureSkipTLSVerify: true}
+	apiConfig.AuthInfos["eks-user"] = &clientcmdapi.AuthInfo{Exec: exec}
+	apiConfig.Contexts["eks-dev"] = &clientcmdapi.Context{Cluster: "eks", AuthInfo: "eks-user"}
+	apiConfig.CurrentContext = "eks-dev"
+	loader := clientcmd.NewDefaultClientConfig(*apiConfig, &clientcmd.ConfigOverrides{})
+
+	restConfig := k8s.ProvideRESTConfig(loader, "")
+	require.NoError(t, restConfig.Error)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f.ctx = ctx
+	f.st = store.NewTestingStore()
+	f.sched = scheduler.NewScheduler(clockwork.NewFakeClock())
+	f.config = restConfig.Config
+	f.c = NewController(k8s.ProvideExecCredentials(restConfig), f.st, f.sched)
+	return f
+}
+
+// Send a request through the client-go transport wrappers,
+// without running the exec plugin.
+func (f *fixture) request(status int) {
+	f.status = status
+	rt := f.config.WrapTransport(f.server.Client().Transport)
+	req, err := http.NewRequest("GET", f.server.URL, nil)
+	require.NoError(f.t, err)
+	res, err := rt.RoundTrip(req)
+	require.NoError(f.t, err)
+	_ = res.Body.Close()
+}
@@ -0,0 +1,89 @@
+package k8sheartbeat
+
+import (
+	"context"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Periodically refreshes the heartbeat annotation on every object
+// this session has deployed, so that `tilt gc-cluster` knows we're still alive.
+type Controller struct {
+	kCli  k8s.Client
+	sched *scheduler.Scheduler
+	clock build.Clock
+
+	mu   sync.Mutex
+	refs []heartbeatRef
+}
+
+// An object to refresh, and the keys its session uses.
+type heartbeatRef struct {
+	ref  v1.ObjectReference
+	keys k8s.SessionKeys
+}
+
+var _ store.SetUpper = &Controller{}
+var _ store.Subscriber = &Controller{}
+
+func NewController(kCli k8s.Client, sched *scheduler.Scheduler, clock build.Clock) *Controller {
+	return &Controller{
+		kCli:  kCli,
+		sched: sched,
+		clock: clock,
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	c.sched.Every(ctx, "k8s-heartbeat", k8s.HeartbeatInterval, k8s.HeartbeatInterval, c.beat)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	refs := []heartbeatRef{}
+	for _, mt := range state.Targets() {
+		keys := k8s.NewSessionKeys(mt.Manifest.K8sTarget().ObjectLabels.Prefix)
+		for _, bs := range mt.State.BuildStatuses {
+			result, ok := bs.LastResult.(store.K8sBuildResult)
+			if !ok {
+				continue
+			}
+			for _, ref := range result.DeployedRefs {
+				refs = append(refs, heartbeatRef{ref: ref, keys: keys})
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.refs = refs
+	c.mu.Unlock()
+}
+
+func (c *Controller) beat(ctx context.Context) {
+	c.mu.Lock()
+	refs := append([]heartbeatRef{}, c.refs...)
+	c.mu.Unlock()
+
+	if len(refs) == 0 {
+		return
+	}
+
+	now := c.clock.Now()
+	for _, hr := range refs {
+		err := c.kCli.MergePatch(ctx, hr.ref, k8s.HeartbeatPatch(hr.keys, now))
+		if err != nil {
+			// The object may have been deleted out from under us. That's fine;
+			// it'll get a fresh heartbeat the next time we deploy it.
+			logger.Get(ctx).Debugf("Refreshing heartbeat on %s %s: %v", hr.ref.Kind, hr.ref.Name, err)
+		}
+	}
+}
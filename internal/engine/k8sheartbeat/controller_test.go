@@ -0,0 +1,114 @@
+package k8sheartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils/manifestbuilder"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHeartbeatDeployedObjects(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.deploy(testyaml.SanchoYAML)
+	f.c.OnChange(f.ctx, f.st)
+	f.c.beat(f.ctx)
+
+	require.Equal(t, 1, len(f.kCli.MergePatchCalls))
+	call := f.kCli.MergePatchCalls[0]
+	assert.Equal(t, "Deployment", call.Ref.Kind)
+	assert.Equal(t, "sancho", call.Ref.Name)
+	assert.Equal(t, string(k8s.HeartbeatPatch(k8s.DefaultSessionKeys, f.clock.Now())), string(call.Patch))
+}
+
+func TestHeartbeatLabelPrefix(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.deployWithObjectLabels(testyaml.SanchoYAML, model.K8sObjectLabels{Prefix: "example.com"})
+	f.c.OnChange(f.ctx, f.st)
+	f.c.beat(f.ctx)
+
+	require.Equal(t, 1, len(f.kCli.MergePatchCalls))
+	assert.Contains(t, string(f.kCli.MergePatchCalls[0].Patch), "example.com/heartbeat")
+}
+
+func TestHeartbeatNothingDeployed(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.c.OnChange(f.ctx, f.st)
+	f.c.beat(f.ctx)
+
+	assert.Equal(t, 0, len(f.kCli.MergePatchCalls))
+}
+
+func TestHeartbeatScheduled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.c.SetUp(f.ctx)
+	assert.Equal(t, []string{"k8s-heartbeat"}, f.sched.JobNames())
+}
+
+type fixture struct {
+	*tempdir.TempDirFixture
+	ctx   context.Context
+	kCli  *k8s.FakeK8sClient
+	st    *store.TestingStore
+	sched *scheduler.Scheduler
+	clock fakeClock
+	c     *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	kCli := k8s.NewFakeK8sClient()
+	sched := scheduler.NewScheduler(clockwork.NewFakeClock())
+	clock := fakeClock{now: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)}
+	return &fixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            context.Background(),
+		kCli:           kCli,
+		st:             store.NewTestingStore(),
+		sched:          sched,
+		clock:          clock,
+		c:              NewController(kCli, sched, clock),
+	}
+}
+
+func (f *fixture) deploy(yaml string) {
+	f.deployWithObjectLabels(yaml, model.K8sObjectLabels{})
+}
+
+func (f *fixture) deployWithObjectLabels(yaml string, objectLabels model.K8sObjectLabels) {
+	m := manifestbuilder.New(f, "sancho").WithK8sYAML(yaml).Build()
+	m = m.WithDeployTarget(m.K8sTarget().WithObjectLabels(objectLabels))
+	entities, err := k8s.ParseYAMLFromString(yaml)
+	require.NoError(f.T(), err)
+
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+
+	mt := store.NewManifestTarget(m)
+	mt.State.MutableBuildStatus(m.K8sTarget().ID()).LastResult =
+		store.NewK8sDeployResult(m.K8sTarget().ID(), nil, nil, entities)
+	state.UpsertManifestTarget(mt)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time { return c.now }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -107,6 +108,26 @@ func TestPodWatchChangeEventBeforeUID(t *testing.T) {
 	f.assertObservedPods(p)
 }
 
+// Short-lived Job pods often outlive their Job, e.g., with ttlSecondsAfterFinished.
+func TestPodWatchOwnerAlreadyDeleted(t *testing.T) {
+	f := newPWFixture(t)
+	defer f.TearDown()
+
+	manifest := f.addManifestWithSelectors("migrate")
+
+	f.pw.OnChange(f.ctx, f.store)
+
+	p := podbuilder.New(t, manifest).Build()
+	p.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "batch/v1", Kind: "Job", Name: "migrate", UID: "migrate-job-uid"},
+	}
+
+	f.addDeployedUID(manifest, "migrate-job-uid")
+	f.kClient.EmitPod(labels.Everything(), p)
+
+	f.assertObservedPods(p)
+}
+
 // We had a bug where if newPod.resourceVersion < oldPod.resourceVersion (using string comparison!)
 // then we'd ignore the new pod. This meant, e.g., once we got an update for resourceVersion "9", we'd
 // ignore updates for resourceVersions "10" through "89" and "100" through "899"
@@ -0,0 +1,13 @@
+package linkdiscovery
+
+import (
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The well-known paths that answered on a resource's endpoints.
+type DiscoveredAction struct {
+	ManifestName model.ManifestName
+	Links        []model.Link
+}
+
+func (DiscoveredAction) Action() {}
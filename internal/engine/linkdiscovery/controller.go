@@ -0,0 +1,156 @@
+package linkdiscovery
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Paths that many services serve, and the names we show for them in the UI.
+var wellKnownPaths = []struct {
+	path string
+	name string
+}{
+	{"/metrics", "metrics"},
+	{"/healthz", "healthz"},
+	{"/swagger", "API docs"},
+}
+
+const probeTimeout = 3 * time.Second
+
+// We only care about the status code, so don't read much of the body.
+const maxBodyBytes = 64 * 1024
+
+// Once a resource is ready, sends an HTTP GET to a few well-known paths
+// on each of its endpoints, and adds the ones that answer as links,
+// so that the web UI can take you straight to a service's metrics or API docs.
+type Controller struct {
+	client *http.Client
+
+	mu sync.Mutex
+
+	// The endpoints we last probed for each ready resource, so that we only
+	// probe again when they change, or when the resource becomes ready again.
+	probed map[model.ManifestName]string
+}
+
+var _ store.Subscriber = &Controller{}
+
+func NewController() *Controller {
+	return &Controller{
+		client: &http.Client{},
+		probed: make(map[model.ManifestName]string),
+	}
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	toProbe := c.targetsToProbe(st)
+	for mn, urls := range toProbe {
+		go c.discover(ctx, st, mn, urls)
+	}
+}
+
+// Finds the ready resources whose endpoints we haven't probed yet.
+func (c *Controller) targetsToProbe(st store.RStore) map[model.ManifestName][]string {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[model.ManifestName][]string)
+	if !state.UpdateSettings.LinkDiscovery() {
+		return result
+	}
+
+	ready := make(map[model.ManifestName]bool)
+	for _, mt := range state.Targets() {
+		mn := mt.Manifest.Name
+		rs := mt.State.RuntimeState
+		if rs == nil || rs.RuntimeStatus() != model.RuntimeStatusOK {
+			continue
+		}
+
+		urls := model.LinksToURLs(store.ManifestTargetServiceEndpoints(mt))
+		if len(urls) == 0 {
+			continue
+		}
+		ready[mn] = true
+
+		key := strings.Join(urls, " ")
+		if c.probed[mn] == key {
+			continue
+		}
+		c.probed[mn] = key
+		result[mn] = urls
+	}
+
+	for mn := range c.probed {
+		if !ready[mn] {
+			delete(c.probed, mn)
+		}
+	}
+	return result
+}
+
+// Probes the well-known paths on each endpoint, and reports the ones that answered.
+func (c *Controller) discover(ctx context.Context, st store.RStore, mn model.ManifestName, urls []string) {
+	var candidates []model.Link
+	for _, u := range urls {
+		base := strings.TrimSuffix(u, "/")
+		for _, p := range wellKnownPaths {
+			candidates = append(candidates, model.Link{URL: base + p.path, Name: p.name})
+		}
+	}
+
+	found := make([]bool, len(candidates))
+	var wg sync.WaitGroup
+	for i, link := range candidates {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			found[i] = c.exists(ctx, u)
+		}(i, link.URL)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	var links []model.Link
+	for i, link := range candidates {
+		if found[i] {
+			links = append(links, link)
+		}
+	}
+	sort.Sort(model.ByURL(links))
+	st.Dispatch(DiscoveredAction{ManifestName: mn, Links: links})
+}
+
+// A path exists if it answers a GET with a 2xx.
+func (c *Controller) exists(ctx context.Context, u string) bool {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxBodyBytes))
+	_ = resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
@@ -0,0 +1,153 @@
+package linkdiscovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDiscoverWellKnownPaths(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	port := f.serve("/metrics", "/swagger")
+	f.c.discover(f.ctx, f.st, "fe", []string{fmt.Sprintf("http://127.0.0.1:%d/", port)})
+
+	assert.Equal(t, []model.Link{
+		{URL: fmt.Sprintf("http://127.0.0.1:%d/metrics", port), Name: "metrics"},
+		{URL: fmt.Sprintf("http://127.0.0.1:%d/swagger", port), Name: "API docs"},
+	}, f.links("fe"))
+}
+
+func TestProbeOnlyReadyResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", 8000, model.RuntimeStatusPending)
+	assert.Empty(t, f.c.targetsToProbe(f.st))
+
+	f.addResource("fe", 8000, model.RuntimeStatusOK)
+	assert.Equal(t, map[model.ManifestName][]string{
+		"fe": {"http://127.0.0.1:8000/"},
+	}, f.c.targetsToProbe(f.st))
+}
+
+func TestProbeAgainWhenEndpointsChange(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", 8000, model.RuntimeStatusOK)
+	assert.Len(t, f.c.targetsToProbe(f.st), 1)
+	assert.Empty(t, f.c.targetsToProbe(f.st))
+
+	f.addResource("fe", 8001, model.RuntimeStatusOK)
+	assert.Len(t, f.c.targetsToProbe(f.st), 1)
+}
+
+func TestProbeAgainWhenReadyAgain(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("fe", 8000, model.RuntimeStatusOK)
+	assert.Len(t, f.c.targetsToProbe(f.st), 1)
+
+	f.addResource("fe", 8000, model.RuntimeStatusError)
+	assert.Empty(t, f.c.targetsToProbe(f.st))
+
+	f.addResource("fe", 8000, model.RuntimeStatusOK)
+	assert.Len(t, f.c.targetsToProbe(f.st), 1)
+}
+
+func TestNoProbeWhenDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.UpdateSettings = state.UpdateSettings.WithLinkDiscovery(false)
+	})
+	f.addResource("fe", 8000, model.RuntimeStatusOK)
+	assert.Empty(t, f.c.targetsToProbe(f.st))
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	st     *store.TestingStore
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		st:     store.NewTestingStore(),
+		c:      NewController(),
+	}
+}
+
+func (f *fixture) TearDown() {
+	f.cancel()
+}
+
+// Starts a server that only responds to the given paths, and returns its port.
+func (f *fixture) serve(paths ...string) int {
+	mux := http.NewServeMux()
+	for _, p := range paths {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	server := httptest.NewServer(mux)
+	f.t.Cleanup(server.Close)
+
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(f.t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(f.t, err)
+	return port
+}
+
+func (f *fixture) addResource(name model.ManifestName, port int, status model.RuntimeStatus) {
+	m := model.Manifest{Name: name}.WithDeployTarget(model.K8sTarget{
+		Name:         model.TargetName(name),
+		PortForwards: []model.PortForward{{LocalPort: port, ContainerPort: 8080, Host: "127.0.0.1"}},
+	})
+	f.st.WithState(func(state *store.EngineState) {
+		mt := store.NewManifestTarget(m)
+		mt.State.RuntimeState = fakeRuntimeState{status: status}
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) links(name model.ManifestName) []model.Link {
+	for _, action := range f.st.Actions() {
+		a, ok := action.(DiscoveredAction)
+		if ok && a.ManifestName == name {
+			return a.Links
+		}
+	}
+	f.t.Fatalf("No discovered links for %s", name)
+	return nil
+}
+
+type fakeRuntimeState struct {
+	status model.RuntimeStatus
+}
+
+func (fakeRuntimeState) RuntimeState()                        {}
+func (fakeRuntimeState) HasEverBeenReadyOrSucceeded() bool    { return true }
+func (s fakeRuntimeState) RuntimeStatus() model.RuntimeStatus { return s.status }
+func (fakeRuntimeState) RuntimeStatusError() error            { return nil }
@@ -3,15 +3,12 @@ package engine
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
-	"github.com/tilt-dev/tilt/internal/ospath"
-
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 
@@ -32,6 +29,7 @@ type LiveUpdateBuildAndDeployer struct {
 	dcu     *containerupdate.DockerUpdater
 	scu     *containerupdate.SyncletUpdater
 	ecu     *containerupdate.ExecUpdater
+	kCli    k8s.Client
 	updMode buildcontrol.UpdateMode
 	env     k8s.Env
 	runtime container.Runtime
@@ -39,12 +37,13 @@ type LiveUpdateBuildAndDeployer struct {
 }
 
 func NewLiveUpdateBuildAndDeployer(dcu *containerupdate.DockerUpdater,
-	scu *containerupdate.SyncletUpdater, ecu *containerupdate.ExecUpdater,
+	scu *containerupdate.SyncletUpdater, ecu *containerupdate.ExecUpdater, kCli k8s.Client,
 	updMode buildcontrol.UpdateMode, env k8s.Env, runtime container.Runtime, c build.Clock) *LiveUpdateBuildAndDeployer {
 	return &LiveUpdateBuildAndDeployer{
 		dcu:     dcu,
 		scu:     scu,
 		ecu:     ecu,
+		kCli:    kCli,
 		updMode: updMode,
 		env:     env,
 		runtime: runtime,
@@ -113,6 +112,9 @@ func (lubad *LiveUpdateBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 	}
 
 	err = dontFallBackErr
+	if err == nil {
+		lubad.restartDependentResources(ctx, st, liveUpdInfos)
+	}
 	return createResultSet(liveUpdateStateSet, liveUpdInfos), err
 }
 
@@ -209,27 +211,10 @@ func liveUpdateInfoForStateTree(stateTree liveUpdateStateTree) (liveUpdInfo, err
 	var hotReload bool
 
 	if luInfo := iTarget.LiveUpdateInfo(); !luInfo.Empty() {
-		var pathsMatchingNoSync []string
-		fileMappings, pathsMatchingNoSync, err = build.FilesToPathMappings(filesChanged, luInfo.SyncSteps())
-		if err != nil {
-			return liveUpdInfo{}, err
-		}
-		if len(pathsMatchingNoSync) > 0 {
-			prettyPaths := ospath.FileListDisplayNames(iTarget.LocalPaths(), pathsMatchingNoSync)
-			return liveUpdInfo{}, buildcontrol.RedirectToNextBuilderInfof(
-				"Found file(s) not matching any sync for %s (files: %s)", iTarget.ID(), strings.Join(prettyPaths, ", "))
-		}
-
-		// If any changed files match a FallBackOn file, fall back to next BuildAndDeployer
-		anyMatch, file, err := luInfo.FallBackOnFiles().AnyMatch(filesChanged)
+		fileMappings, err = buildcontrol.LiveUpdateChanges(iTarget, filesChanged)
 		if err != nil {
 			return liveUpdInfo{}, err
 		}
-		if anyMatch {
-			prettyFile := ospath.FileListDisplayNames(iTarget.LocalPaths(), []string{file})[0]
-			return liveUpdInfo{}, buildcontrol.RedirectToNextBuilderInfof(
-				"Detected change to fall_back_on file %q", prettyFile)
-		}
 
 		runs = luInfo.RunSteps()
 		hotReload = !luInfo.ShouldRestart()
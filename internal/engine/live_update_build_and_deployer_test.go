@@ -16,8 +16,10 @@ import (
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/manifestbuilder"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -344,6 +346,31 @@ func TestSkipLiveUpdateIfForceUpdate(t *testing.T) {
 	assert.Contains(t, err.Error(), "Force update", "expected error contents not found")
 }
 
+func TestRestartDependentResourceDockerCompose(t *testing.T) {
+	f := newFixture(t)
+	defer f.teardown()
+
+	dCli := docker.NewFakeClient()
+	f.lubad.dcu = containerupdate.NewDockerUpdater(dCli)
+
+	lu, err := model.NewLiveUpdate([]model.LiveUpdateStep{
+		model.LiveUpdateSyncStep{Source: f.Path(), Dest: "/app"},
+		model.LiveUpdateRestartResourceStep{Resource: "cache"},
+	}, f.Path())
+	require.NoError(t, err)
+	iTarget := imageTargetWithLiveUpdate(NewSanchoDockerBuildImageTarget(f), lu)
+
+	cache := manifestbuilder.New(f, "cache").WithDockerCompose().Build()
+	f.st.WithState(func(state *store.EngineState) {
+		mt := store.NewManifestTarget(cache)
+		mt.State.RuntimeState = dockercompose.State{ContainerID: "cache-container"}
+		state.UpsertManifestTarget(mt)
+	})
+
+	f.lubad.restartDependentResources(f.ctx, f.st, []liveUpdInfo{{iTarget: iTarget}})
+	assert.Equal(t, 1, dCli.RestartsByContainer["cache-container"])
+}
+
 type lcbadFixture struct {
 	*tempdir.TempDirFixture
 	t     testing.TB
@@ -357,7 +384,7 @@ type lcbadFixture struct {
 func newFixture(t testing.TB) *lcbadFixture {
 	// HACK(maia): we don't need any real container updaters on this LiveUpdBaD since we're testing
 	// a func further down the flow that takes a ContainerUpdater as an arg, so just pass nils
-	lubad := NewLiveUpdateBuildAndDeployer(nil, nil, nil, buildcontrol.UpdateModeAuto, k8s.EnvDockerDesktop, container.RuntimeDocker, fakeClock{})
+	lubad := NewLiveUpdateBuildAndDeployer(nil, nil, nil, nil, buildcontrol.UpdateModeAuto, k8s.EnvDockerDesktop, container.RuntimeDocker, fakeClock{})
 	fakeContainerUpdater := &containerupdate.FakeContainerUpdater{}
 	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 	st := store.NewTestingStore()
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A resource to restart because of a restart_resource() live update step.
+type resourceRestart struct {
+	name model.ManifestName
+
+	// If the containers run on a docker daemon we can talk to,
+	// restart the containers directly.
+	dcu        *containerupdate.DockerUpdater
+	containers []container.ID
+
+	// Otherwise, delete the pods and let their controller recreate them.
+	pods []store.Pod
+}
+
+// After a successful live update, restart the containers of any resources
+// named in restart_resource() steps.
+//
+// The files have already been synced at this point, so failures here don't
+// fail the live update. We warn, and the user can restart the resource by hand.
+func (lubad *LiveUpdateBuildAndDeployer) restartDependentResources(ctx context.Context, st store.RStore, infos []liveUpdInfo) {
+	var names []model.ManifestName
+	seen := make(map[model.ManifestName]bool)
+	for _, info := range infos {
+		for _, name := range info.iTarget.LiveUpdateInfo().RestartResources() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	l := logger.Get(ctx)
+	for _, r := range lubad.resourceRestarts(st, names) {
+		if len(r.containers) == 0 && len(r.pods) == 0 {
+			l.Infof("Skipping restart of %s: no running containers", r.name)
+			continue
+		}
+
+		l.Infof("Restarting %s (restart_resource)", r.name)
+		err := lubad.restartResource(ctx, r)
+		if err != nil {
+			l.Warnf("Failed to restart %s: %v", r.name, err)
+		}
+	}
+}
+
+func (lubad *LiveUpdateBuildAndDeployer) resourceRestarts(st store.RStore, names []model.ManifestName) []resourceRestart {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	var result []resourceRestart
+	for _, name := range names {
+		mt, ok := state.ManifestTargets[name]
+		if !ok {
+			continue
+		}
+
+		r := resourceRestart{name: name}
+		if mt.Manifest.IsDC() {
+			r.dcu = lubad.dcu
+			if cID := mt.State.DCRuntimeState().ContainerID; cID != "" {
+				r.containers = append(r.containers, cID)
+			}
+			result = append(result, r)
+			continue
+		}
+
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+
+		cu := lubad.containerUpdaterForSpecs(mt.Manifest.TargetSpecs())
+		if dcu, ok := cu.(*containerupdate.DockerUpdater); ok {
+			r.dcu = dcu
+		}
+
+		for _, pod := range mt.State.K8sRuntimeState().Pods {
+			if pod.Deleting {
+				continue
+			}
+			if r.dcu == nil {
+				r.pods = append(r.pods, *pod)
+				continue
+			}
+			for _, c := range pod.Containers {
+				if c.ID != "" && c.Running {
+					r.containers = append(r.containers, c.ID)
+				}
+			}
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func (lubad *LiveUpdateBuildAndDeployer) restartResource(ctx context.Context, r resourceRestart) error {
+	for _, cID := range r.containers {
+		err := r.dcu.RestartContainer(ctx, cID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range r.pods {
+		pod, err := lubad.kCli.PodByID(ctx, p.PodID, p.Namespace)
+		if err != nil {
+			return err
+		}
+
+		// A bare pod wouldn't come back.
+		if len(pod.OwnerReferences) == 0 {
+			return fmt.Errorf("pod %s has no controller to recreate it", p.PodID)
+		}
+
+		err = lubad.kCli.Delete(ctx, []k8s.K8sEntity{k8s.NewK8sEntity(pod)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
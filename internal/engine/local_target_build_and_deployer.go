@@ -40,13 +40,27 @@ func (bd *LocalTargetBuildAndDeployer) BuildAndDeploy(ctx context.Context, st st
 	}()
 
 	targ := targets[0]
+	state := stateSet[targ.ID()]
+
+	// Hash the deps before running, so that any changes made while
+	// the command runs will trigger another run.
+	depsHash, err := build.DepsHash(targ)
+	if err != nil {
+		// We just can't tell whether the deps changed, so run anyway.
+		logger.Get(ctx).Debugf("%v", err)
+	}
+	if depsHash != "" && depsUnchanged(state, depsHash) {
+		logger.Get(ctx).Infof("Skipping %s: file contents unchanged since the last run", targ.Name)
+		return bd.successfulBuildResult(targ, depsHash), nil
+	}
+
 	err = bd.run(ctx, targ.UpdateCmd, targ.Workdir)
 	if err != nil {
 		// (Never fall back from the LocalTargetBaD, none of our other BaDs can handle this target)
 		return store.BuildResultSet{}, buildcontrol.DontFallBackErrorf("Command %q failed: %v", targ.UpdateCmd.String(), err)
 	}
 
-	if state := stateSet[targ.ID()]; state.IsEmpty() {
+	if state.IsEmpty() {
 		// HACK(maia) If target A generates file X and target B depends on file X, it was common that on Tilt startup,
 		// targets A and B would both be queued for their initial build, A would run, modify X, and then B would start
 		// running before Tilt processed the change to X, so we'd end up with this:
@@ -65,7 +79,18 @@ func (bd *LocalTargetBuildAndDeployer) BuildAndDeploy(ctx context.Context, st st
 		time.Sleep(250 * time.Millisecond)
 	}
 
-	return bd.successfulBuildResult(targ), nil
+	return bd.successfulBuildResult(targ, depsHash), nil
+}
+
+// Returns true if this build was only triggered by file events,
+// and the contents of the deps haven't changed since the last successful run
+// (e.g., the files were touched, or saved without changes).
+func depsUnchanged(state store.BuildState, depsHash string) bool {
+	if state.FullBuildTriggered || len(state.FilesChangedSet) == 0 || len(state.DepsChangedSet) > 0 {
+		return false
+	}
+	last, ok := state.LastResult.(store.LocalBuildResult)
+	return ok && last.DepsHash == depsHash
 }
 
 // Extract the targets we can apply -- i.e. LocalTargets
@@ -106,7 +131,8 @@ func (bd *LocalTargetBuildAndDeployer) run(ctx context.Context, c model.Cmd, wd
 	return nil
 }
 
-func (bd *LocalTargetBuildAndDeployer) successfulBuildResult(t model.LocalTarget) store.BuildResultSet {
+func (bd *LocalTargetBuildAndDeployer) successfulBuildResult(t model.LocalTarget, depsHash string) store.BuildResultSet {
 	br := store.NewLocalBuildResult(t.ID())
+	br.DepsHash = depsHash
 	return store.BuildResultSet{t.ID(): br}
 }
@@ -121,6 +121,44 @@ func TestFailedCommand(t *testing.T) {
 	assert.Contains(t, f.out.String(), "oh no", "expect cmd stdout in logs")
 }
 
+func TestSkipCommandIfDepsUnchanged(t *testing.T) {
+	f := newLTFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a.txt", "a")
+	targ := f.localTarget("echo hello world")
+	targ.Deps = []string{f.JoinPath("src")}
+
+	res, err := f.ltbad.BuildAndDeploy(f.ctx, f.st, []model.TargetSpec{targ}, store.BuildStateSet{})
+	require.NoError(t, err)
+	depsHash := res.DepsHash()
+	require.NotEmpty(t, depsHash)
+
+	// The file was touched, but its contents are the same.
+	f.out.Reset()
+	state := store.NewBuildState(res[targ.ID()], []string{f.JoinPath("src/a.txt")}, nil)
+	res, err = f.ltbad.BuildAndDeploy(f.ctx, f.st, []model.TargetSpec{targ}, store.BuildStateSet{targ.ID(): state})
+	require.NoError(t, err)
+	assert.Equal(t, depsHash, res.DepsHash())
+	assert.NotContains(t, f.out.String(), "hello world")
+	assert.Contains(t, f.out.String(), "file contents unchanged")
+
+	// A trigger always runs the command.
+	f.out.Reset()
+	_, err = f.ltbad.BuildAndDeploy(f.ctx, f.st, []model.TargetSpec{targ},
+		store.BuildStateSet{targ.ID(): state.WithFullBuildTriggered(true)})
+	require.NoError(t, err)
+	assert.Contains(t, f.out.String(), "hello world")
+
+	// So does a real change.
+	f.out.Reset()
+	f.WriteFile("src/a.txt", "b")
+	res, err = f.ltbad.BuildAndDeploy(f.ctx, f.st, []model.TargetSpec{targ}, store.BuildStateSet{targ.ID(): state})
+	require.NoError(t, err)
+	assert.Contains(t, f.out.String(), "hello world")
+	assert.NotEqual(t, depsHash, res.DepsHash())
+}
+
 type ltFixture struct {
 	*tempdir.TempDirFixture
 
@@ -0,0 +1,174 @@
+package localdns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type ListenPacket func(network, address string) (net.PacketConn, error)
+
+func ProvideListenPacket() ListenPacket {
+	return net.ListenPacket
+}
+
+// Runs a DNS server on localhost that maps stable hostnames to the
+// port-forwards of running resources, so that apps can use a name like
+// api.tilt.local instead of remembering which localhost port goes where.
+//
+// Each resource with port-forwards gets <resource>.<domain>, pointing at its
+// first port-forward. A port-forward with a name also gets <name>.<resource>.<domain>.
+// A records give the IP the forward listens on, and SRV records give the port.
+//
+// We never touch /etc/hosts or the system resolver config. The user opts in
+// with local_dns() in the Tiltfile, and points their resolver at the server
+// for the domain.
+type Controller struct {
+	listen ListenPacket
+
+	settings model.LocalDNSSettings
+	server   *server
+	cancel   context.CancelFunc
+	records  map[string]record
+}
+
+var _ store.Subscriber = &Controller{}
+var _ store.TearDowner = &Controller{}
+
+func NewController(listen ListenPacket) *Controller {
+	return &Controller{listen: listen}
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	settings, records := c.snapshot(st)
+
+	if settings != c.settings {
+		c.stop()
+		c.settings = settings
+		c.records = nil
+		if settings.Enabled {
+			c.start(ctx, settings)
+		}
+	}
+
+	if c.server == nil || reflect.DeepEqual(records, c.records) {
+		return
+	}
+
+	c.records = records
+	c.server.setRecords(records)
+}
+
+func (c *Controller) TearDown(ctx context.Context) {
+	c.stop()
+}
+
+func (c *Controller) start(ctx context.Context, settings model.LocalDNSSettings) {
+	addr := fmt.Sprintf("127.0.0.1:%d", settings.Port)
+	conn, err := c.listen("udp", addr)
+	if err != nil {
+		logger.Get(ctx).Warnf("Local DNS: could not listen on %s: %v", addr, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.server = newServer(settings.Domain)
+	go c.server.serve(ctx, conn)
+
+	logger.Get(ctx).Infof("Local DNS: serving *.%s on %s (udp)\n"+
+		"To resolve these names, point your system resolver at it for %s. "+
+		"For example, on macOS, create /etc/resolver/%s containing:\n"+
+		"  nameserver 127.0.0.1\n  port %d",
+		settings.Domain, addr, settings.Domain, settings.Domain, settings.Port)
+}
+
+func (c *Controller) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.cancel = nil
+	c.server = nil
+}
+
+func (c *Controller) snapshot(st store.RStore) (model.LocalDNSSettings, map[string]record) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	settings := state.LocalDNSSettings
+	records := make(map[string]record)
+	if !settings.Enabled {
+		return settings, records
+	}
+
+	for _, mt := range state.Targets() {
+		manifest := mt.Manifest
+		if !manifest.IsK8s() {
+			continue
+		}
+
+		// Only point at port-forwards that are actually running.
+		pod := mt.State.MostRecentPod()
+		if pod.PodID == "" || pod.Phase != v1.PodRunning {
+			continue
+		}
+
+		forwards := manifest.K8sTarget().PortForwards
+		if len(forwards) == 0 {
+			continue
+		}
+
+		resource := hostLabel(manifest.Name.String())
+		if resource == "" {
+			continue
+		}
+		addRecord(records, resource, forwards[0])
+		for _, pf := range forwards {
+			if pf.Name == "" {
+				continue
+			}
+			if label := hostLabel(pf.Name); label != "" {
+				addRecord(records, label+"."+resource, pf)
+			}
+		}
+	}
+	return settings, records
+}
+
+// If two resources map to the same name, the first one wins.
+func addRecord(records map[string]record, host string, pf model.PortForward) {
+	if _, ok := records[host]; ok {
+		return
+	}
+	records[host] = record{IP: forwardIP(pf), Port: pf.LocalPort}
+}
+
+// The IP that the port-forward listens on.
+func forwardIP(pf model.PortForward) net.IP {
+	ip := net.ParseIP(pf.Host)
+	if ip == nil || ip.IsUnspecified() {
+		return net.IPv4(127, 0, 0, 1)
+	}
+	return ip
+}
+
+var invalidHostLabelChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Converts a resource or port-forward name into a valid DNS label.
+func hostLabel(name string) string {
+	label := invalidHostLabelChars.ReplaceAllString(strings.ToLower(name), "-")
+	label = strings.Trim(label, "-")
+	if len(label) > 63 {
+		label = strings.Trim(label[:63], "-")
+	}
+	return label
+}
@@ -0,0 +1,174 @@
+package localdns
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDisabledByDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.addResource("api", v1.PodRunning, model.PortForward{LocalPort: 8080})
+	f.c.OnChange(f.ctx, f.st)
+	assert.Nil(t, f.conn)
+}
+
+func TestResolveRunningResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.addResource("api", v1.PodRunning,
+		model.PortForward{LocalPort: 8080},
+		model.PortForward{LocalPort: 9229, Name: "Debugger"})
+	f.addResource("Web_App", v1.PodRunning, model.PortForward{LocalPort: 3000, Host: "127.0.0.2"})
+	f.addResource("pending", v1.PodPending, model.PortForward{LocalPort: 5000})
+	f.c.OnChange(f.ctx, f.st)
+	require.NotNil(t, f.conn)
+
+	assert.Equal(t, []string{"127.0.0.1"}, f.lookupHost("api.tilt.local"))
+	assert.Equal(t, []string{"127.0.0.1"}, f.lookupHost("API.tilt.local."))
+	assert.Equal(t, []string{"127.0.0.2"}, f.lookupHost("web-app.tilt.local"))
+	assert.Equal(t, 8080, f.lookupSRV("api.tilt.local"))
+	assert.Equal(t, 9229, f.lookupSRV("debugger.api.tilt.local"))
+	assert.Equal(t, 3000, f.lookupSRV("_http._tcp.web-app.tilt.local"))
+
+	_, err := f.resolver().LookupHost(f.ctx, "pending.tilt.local")
+	assert.Error(t, err)
+	_, err = f.resolver().LookupHost(f.ctx, "api.example.com")
+	assert.Error(t, err)
+}
+
+func TestRecordsFollowPods(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.c.OnChange(f.ctx, f.st)
+	_, err := f.resolver().LookupHost(f.ctx, "api.tilt.local")
+	assert.Error(t, err)
+
+	f.addResource("api", v1.PodRunning, model.PortForward{LocalPort: 8080})
+	f.c.OnChange(f.ctx, f.st)
+	assert.Equal(t, []string{"127.0.0.1"}, f.lookupHost("api.tilt.local"))
+}
+
+func TestDisableStopsServer(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.enable()
+	f.c.OnChange(f.ctx, f.st)
+	require.NotNil(t, f.conn)
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.LocalDNSSettings.Enabled = false
+	})
+	f.c.OnChange(f.ctx, f.st)
+	assert.Nil(t, f.c.server)
+
+	// The connection gets closed asynchronously when the server stops.
+	assert.Eventually(t, func() bool {
+		_, err := f.conn.WriteTo([]byte{0}, f.conn.LocalAddr())
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHostLabel(t *testing.T) {
+	assert.Equal(t, "web-app", hostLabel("Web_App"))
+	assert.Equal(t, "my-debugger", hostLabel("  My Debugger! "))
+	assert.Equal(t, "", hostLabel("___"))
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	st     *store.TestingStore
+	c      *Controller
+	conn   net.PacketConn
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ctx, cancel := context.WithCancel(ctx)
+	f := &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		st:     store.NewTestingStore(),
+	}
+
+	// Listen on a random port, rather than the port from the settings.
+	f.c = NewController(func(network, address string) (net.PacketConn, error) {
+		conn, err := net.ListenPacket(network, "127.0.0.1:0")
+		f.conn = conn
+		return conn, err
+	})
+	f.st.WithState(func(state *store.EngineState) {
+		state.LocalDNSSettings = model.DefaultLocalDNSSettings()
+	})
+	return f
+}
+
+func (f *fixture) TearDown() {
+	f.c.TearDown(f.ctx)
+	f.cancel()
+}
+
+func (f *fixture) enable() {
+	f.st.WithState(func(state *store.EngineState) {
+		state.LocalDNSSettings.Enabled = true
+	})
+}
+
+func (f *fixture) addResource(name string, phase v1.PodPhase, forwards ...model.PortForward) {
+	m := model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(model.K8sTarget{
+		Name:         model.TargetName(name),
+		PortForwards: forwards,
+	})
+	f.st.WithState(func(state *store.EngineState) {
+		mt := store.NewManifestTarget(m)
+		mt.State.RuntimeState = store.NewK8sRuntimeStateWithPods(m, store.Pod{
+			PodID: k8s.PodID(name + "-pod"),
+			Phase: phase,
+		})
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) resolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return net.Dial("udp", f.conn.LocalAddr().String())
+		},
+	}
+}
+
+func (f *fixture) lookupHost(host string) []string {
+	addrs, err := f.resolver().LookupHost(f.ctx, host)
+	require.NoError(f.t, err)
+	sort.Strings(addrs)
+	return addrs
+}
+
+func (f *fixture) lookupSRV(name string) int {
+	_, srvs, err := f.resolver().LookupSRV(f.ctx, "", "", name)
+	require.NoError(f.t, err)
+	require.Equal(f.t, 1, len(srvs))
+	return int(srvs[0].Port)
+}
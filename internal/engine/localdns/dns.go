@@ -0,0 +1,187 @@
+package localdns
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Just enough of the DNS wire format (RFC 1035) to answer A and SRV queries
+// for the names we serve. We don't recurse, and we refuse anything outside
+// our domain, so the system resolver falls through to its other servers.
+
+const (
+	typeA   uint16 = 1
+	typeSRV uint16 = 33
+	typeANY uint16 = 255
+	classIN uint16 = 1
+
+	rcodeSuccess  = 0
+	rcodeFormErr  = 1
+	rcodeNXDomain = 3
+	rcodeNotImp   = 4
+	rcodeRefused  = 5
+
+	headerLen = 12
+
+	// Port-forwards come and go, so don't let resolvers cache answers for long.
+	recordTTL uint32 = 5
+)
+
+// Where a hostname points: an IP on this machine, and the port
+// that the port-forward listens on.
+type record struct {
+	IP   net.IP
+	Port int
+}
+
+type question struct {
+	labels []string
+	qtype  uint16
+	qclass uint16
+
+	// The offset of each label in the request, for name compression.
+	offsets []int
+
+	// The offset just past the question in the request.
+	end int
+}
+
+func (q question) name() string {
+	return strings.ToLower(strings.Join(q.labels, "."))
+}
+
+func parseQuestion(req []byte) (question, error) {
+	if len(req) < headerLen {
+		return question{}, fmt.Errorf("message too short")
+	}
+	if qdcount := binary.BigEndian.Uint16(req[4:6]); qdcount != 1 {
+		return question{}, fmt.Errorf("expected 1 question, got %d", qdcount)
+	}
+
+	q := question{}
+	i := headerLen
+	for {
+		if i >= len(req) {
+			return question{}, fmt.Errorf("truncated name")
+		}
+		l := int(req[i])
+		if l == 0 {
+			i++
+			break
+		}
+		if l&0xC0 != 0 {
+			return question{}, fmt.Errorf("unexpected compressed name in question")
+		}
+		if i+1+l > len(req) {
+			return question{}, fmt.Errorf("truncated label")
+		}
+		q.offsets = append(q.offsets, i)
+		q.labels = append(q.labels, string(req[i+1:i+1+l]))
+		i += 1 + l
+	}
+
+	if i+4 > len(req) {
+		return question{}, fmt.Errorf("truncated question")
+	}
+	q.qtype = binary.BigEndian.Uint16(req[i : i+2])
+	q.qclass = binary.BigEndian.Uint16(req[i+2 : i+4])
+	q.end = i + 4
+	return q, nil
+}
+
+// Builds the response to a query. Returns nil if the message
+// isn't a query we should respond to at all.
+func respond(req []byte, domain string, lookup func(host string) (record, bool)) []byte {
+	if len(req) < headerLen || req[2]&0x80 != 0 {
+		// Too short to even have an ID, or a response rather than a query.
+		return nil
+	}
+
+	opcode := (req[2] >> 3) & 0xF
+	q, err := parseQuestion(req)
+	if err != nil {
+		return header(req, opcode, rcodeFormErr, 0, 0)
+	}
+	if opcode != 0 {
+		return header(req, opcode, rcodeNotImp, 0, 0)
+	}
+
+	resp := func(rcode byte, answers ...[]byte) []byte {
+		msg := header(req, opcode, rcode, 1, len(answers))
+		msg = append(msg, req[headerLen:q.end]...)
+		for _, a := range answers {
+			msg = append(msg, a...)
+		}
+		return msg
+	}
+
+	name := q.name()
+	if q.qclass != classIN || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+		return resp(rcodeRefused)
+	}
+
+	// SRV lookups conventionally prefix the name with the service and protocol,
+	// e.g., _http._tcp.api.tilt.local. Strip them off.
+	hostIndex := 0
+	for hostIndex < len(q.labels) && strings.HasPrefix(q.labels[hostIndex], "_") {
+		hostIndex++
+	}
+	host := strings.TrimSuffix(strings.ToLower(strings.Join(q.labels[hostIndex:], ".")), "."+domain)
+	if hostIndex == len(q.labels) {
+		return resp(rcodeNXDomain)
+	}
+	if host == domain {
+		// The domain itself exists, it just doesn't have any records.
+		return resp(rcodeSuccess)
+	}
+
+	r, ok := lookup(host)
+	if !ok {
+		return resp(rcodeNXDomain)
+	}
+
+	answers := [][]byte{}
+	ip4 := r.IP.To4()
+	if (q.qtype == typeA || q.qtype == typeANY) && ip4 != nil && hostIndex == 0 {
+		answers = append(answers, answer(typeA, ip4))
+	}
+	if (q.qtype == typeSRV || q.qtype == typeANY) && r.Port > 0 {
+		// priority, weight, port, then the target host as a pointer into the question.
+		rdata := make([]byte, 8)
+		binary.BigEndian.PutUint16(rdata[4:6], uint16(r.Port))
+		binary.BigEndian.PutUint16(rdata[6:8], 0xC000|uint16(q.offsets[hostIndex]))
+		answers = append(answers, answer(typeSRV, rdata))
+	}
+
+	// For other types (like AAAA), the name exists but has no records of that
+	// type, so we answer with success and no records.
+	return resp(rcodeSuccess, answers...)
+}
+
+func header(req []byte, opcode byte, rcode byte, qdcount int, ancount int) []byte {
+	msg := make([]byte, headerLen)
+	copy(msg[0:2], req[0:2])
+
+	// QR=1 (response), AA=1 (we're the authority for our domain),
+	// and echo back RD (recursion desired).
+	msg[2] = 0x80 | opcode<<3 | 0x04 | (req[2] & 0x01)
+	msg[3] = rcode
+	binary.BigEndian.PutUint16(msg[4:6], uint16(qdcount))
+	binary.BigEndian.PutUint16(msg[6:8], uint16(ancount))
+	return msg
+}
+
+// A resource record for the name in the question.
+func answer(rrtype uint16, rdata []byte) []byte {
+	rr := make([]byte, 12, 12+len(rdata))
+
+	// A pointer to the name in the question, which always starts right after the header.
+	binary.BigEndian.PutUint16(rr[0:2], 0xC000|headerLen)
+	binary.BigEndian.PutUint16(rr[2:4], rrtype)
+	binary.BigEndian.PutUint16(rr[4:6], classIN)
+	binary.BigEndian.PutUint32(rr[6:10], recordTTL)
+	binary.BigEndian.PutUint16(rr[10:12], uint16(len(rdata)))
+	return append(rr, rdata...)
+}
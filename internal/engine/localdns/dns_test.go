@@ -0,0 +1,35 @@
+package localdns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noRecords(host string) (record, bool) { return record{}, false }
+
+func TestRespondIgnoresResponses(t *testing.T) {
+	msg := []byte{0, 1, 0x80, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	assert.Nil(t, respond(msg, "tilt.local", noRecords))
+}
+
+func TestRespondMalformed(t *testing.T) {
+	assert.Nil(t, respond([]byte{0, 1}, "tilt.local", noRecords))
+
+	// A question whose label runs off the end of the message.
+	msg := []byte{0, 1, 0x01, 0, 0, 1, 0, 0, 0, 0, 0, 0, 10, 'a', 'p', 'i'}
+	resp := respond(msg, "tilt.local", noRecords)
+	assert.Equal(t, byte(rcodeFormErr), resp[3]&0xF)
+	assert.Equal(t, []byte{0, 1}, resp[0:2])
+}
+
+func TestRespondRefusesOtherDomains(t *testing.T) {
+	msg := []byte{0, 1, 0x01, 0, 0, 1, 0, 0, 0, 0, 0, 0,
+		3, 'a', 'p', 'i', 3, 'c', 'o', 'm', 0,
+		0, 1, 0, 1}
+	resp := respond(msg, "tilt.local", noRecords)
+	assert.Equal(t, byte(rcodeRefused), resp[3]&0xF)
+
+	// The question is echoed back.
+	assert.Equal(t, msg[12:], resp[12:])
+}
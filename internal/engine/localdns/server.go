@@ -0,0 +1,65 @@
+package localdns
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Serves DNS queries for a single domain over UDP.
+type server struct {
+	domain string
+
+	mu      sync.Mutex
+	records map[string]record
+}
+
+func newServer(domain string) *server {
+	return &server{
+		domain:  domain,
+		records: make(map[string]record),
+	}
+}
+
+func (s *server) setRecords(records map[string]record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = records
+}
+
+func (s *server) lookup(host string) (record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[host]
+	return r, ok
+}
+
+// Answers queries on conn until the context is done.
+func (s *server) serve(ctx context.Context, conn net.PacketConn) {
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Get(ctx).Debugf("Local DNS: %v", err)
+			}
+			return
+		}
+
+		resp := respond(buf[:n], s.domain, s.lookup)
+		if resp == nil {
+			continue
+		}
+		_, err = conn.WriteTo(resp, addr)
+		if err != nil {
+			logger.Get(ctx).Debugf("Local DNS: %v", err)
+		}
+	}
+}
@@ -9,9 +9,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/synclet/sidecar"
@@ -26,6 +30,7 @@ func handlePodDeleteAction(ctx context.Context, state *store.EngineState, action
 		ms := target.State
 		runtime := ms.K8sRuntimeState()
 		delete(runtime.Pods, action.PodID)
+		state.Alerts.Resolve(crashLoopAlertID(ms.Name, action.PodID))
 	}
 }
 
@@ -83,10 +88,20 @@ func handlePodChangeAction(ctx context.Context, state *store.EngineState, action
 
 	fwdsValid := portforward.PortForwardsAreValid(manifest, *podInfo)
 	if !fwdsValid {
-		logger.Get(ctx).Warnf(
-			"Resource %s is using port forwards, but no container ports on pod %s",
+		msg := fmt.Sprintf("Resource %s is using port forwards, but no container ports on pod %s",
 			manifest.Name, podInfo.PodID)
+		logger.Get(ctx).Warnf("%s", msg)
+		state.Alerts.Upsert(model.Alert{
+			ID:           portforward.AlertID(manifest.Name),
+			Source:       model.AlertSourcePortForward,
+			Severity:     model.AlertSeverityWarning,
+			ManifestName: manifest.Name,
+			Message:      msg,
+		}, time.Now())
+	} else {
+		state.Alerts.Resolve(portforward.AlertID(manifest.Name))
 	}
+	updateCrashLoopAlert(state, manifest.Name, pod, podInfo)
 	checkForContainerCrash(ctx, state, mt)
 
 	if oldRestartTotal < podInfo.AllContainerRestarts() {
@@ -99,6 +114,43 @@ func handlePodChangeAction(ctx context.Context, state *store.EngineState, action
 	}
 }
 
+func crashLoopAlertID(mn model.ManifestName, podID k8s.PodID) string {
+	return fmt.Sprintf("%s:%s:%s", model.AlertSourceCrashLoop, mn, podID)
+}
+
+// Add an alert while any container on the pod is in CrashLoopBackOff,
+// and resolve it once the pod recovers or goes away.
+func updateCrashLoopAlert(state *store.EngineState, mn model.ManifestName, pod *v1.Pod, podInfo *store.Pod) {
+	id := crashLoopAlertID(mn, podInfo.PodID)
+	if podInfo.Deleting {
+		state.Alerts.Resolve(id)
+		return
+	}
+
+	for _, cStatus := range pod.Status.ContainerStatuses {
+		waiting := cStatus.State.Waiting
+		if waiting == nil || waiting.Reason != "CrashLoopBackOff" {
+			continue
+		}
+
+		msg := fmt.Sprintf("Container %s on pod %s is crash looping (%d restarts)",
+			cStatus.Name, podInfo.PodID, cStatus.RestartCount)
+		if last := cStatus.LastTerminationState.Terminated; last != nil && last.Reason != "" {
+			msg = fmt.Sprintf("%s. Last stopped: %s (exit code %d)", msg, last.Reason, last.ExitCode)
+		}
+
+		state.Alerts.Upsert(model.Alert{
+			ID:           id,
+			Source:       model.AlertSourceCrashLoop,
+			Severity:     model.AlertSeverityError,
+			ManifestName: mn,
+			Message:      msg,
+		}, time.Now())
+		return
+	}
+	state.Alerts.Resolve(id)
+}
+
 // Find the ManifestTarget for the PodChangeAction,
 // and confirm that it matches what we've deployed.
 func matchPodChangeToManifest(state *store.EngineState, action k8swatch.PodChangeAction) *store.ManifestTarget {
@@ -223,6 +275,19 @@ func containerForStatus(ctx context.Context, pod *v1.Pod, cStatus v1.ContainerSt
 		isTerminated = true
 	}
 
+	// If the container is stopped right now, that's the most recent termination.
+	// Otherwise, it's the one that caused the last restart.
+	lastTermination := cStatus.LastTerminationState.Terminated
+	if cStatus.State.Terminated != nil {
+		lastTermination = cStatus.State.Terminated
+	}
+	lastReason := ""
+	lastExitCode := int32(0)
+	if lastTermination != nil {
+		lastReason = lastTermination.Reason
+		lastExitCode = lastTermination.ExitCode
+	}
+
 	return store.Container{
 		Name:       cName,
 		ID:         cID,
@@ -233,6 +298,9 @@ func containerForStatus(ctx context.Context, pod *v1.Pod, cStatus v1.ContainerSt
 		ImageRef:   cRef,
 		Restarts:   int(cStatus.RestartCount),
 		Status:     k8swatch.ContainerStatusToRuntimeState(cStatus),
+
+		LastTerminationReason:   lastReason,
+		LastTerminationExitCode: lastExitCode,
 	}, nil
 }
 
@@ -323,3 +391,68 @@ func handlePodResetRestartsAction(state *store.EngineState, action store.PodRese
 	delta := podInfo.VisibleContainerRestarts() - action.VisibleRestarts
 	podInfo.BaselineRestarts = podInfo.AllContainerRestarts() - delta
 }
+
+func handleEndpointHealthCheckAction(state *store.EngineState, action endpointhealth.CheckAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	ms.EndpointHealth = make(map[string]store.EndpointHealth, len(action.Results))
+	for _, r := range action.Results {
+		ms.EndpointHealth[r.URL] = r
+	}
+}
+
+func handleWorkloadStatusAction(state *store.EngineState, action workloadstatus.StatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok || !ms.IsK8s() {
+		return
+	}
+	runtime := ms.K8sRuntimeState()
+	runtime.WorkloadStatuses = action.Statuses
+	ms.RuntimeState = runtime
+}
+
+func handleLinksDiscoveredAction(state *store.EngineState, action linkdiscovery.DiscoveredAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+	ms.DiscoveredLinks = action.Links
+}
+
+func handleSeedStatusAction(state *store.EngineState, action seed.StatusAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+	ms.Seed = action.Status
+}
+
+func handleTrafficCaptureAction(state *store.EngineState, action store.TrafficCaptureAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+	ms.TrafficCapture = action.Mode
+}
+
+func handleCronJobRunAction(state *store.EngineState, action store.CronJobRunAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+	ms.CronJobRunRequests++
+}
+
+func handlePortForwardActivityAction(state *store.EngineState, action portforward.ActivityAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if !ok {
+		return
+	}
+
+	if action.Time.After(ms.LastPortForwardActivity) {
+		ms.LastPortForwardActivity = action.Time
+	}
+}
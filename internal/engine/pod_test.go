@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -42,6 +43,78 @@ func TestPodDeleteAction(t *testing.T) {
 	assert.Equal(t, 0, len(ms.K8sRuntimeState().Pods))
 }
 
+func TestPodCrashLoopAlert(t *testing.T) {
+	f := newReducerFixture(t)
+	defer f.TearDown()
+
+	ms, _ := f.state.ManifestState("sancho")
+	m, _ := f.state.Manifest("sancho")
+	hash := k8s.PodTemplateSpecHash("ptsh")
+	ms.K8sRuntimeState().DeployedPodTemplateSpecHashSet.Add(hash)
+
+	pod := podbuilder.New(f.T(), m).WithTemplateSpecHash(hash).WithRestartCount(3).Build()
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{
+		Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+	}
+	handlePodChangeAction(f.ctx, f.state, k8swatch.PodChangeAction{
+		Pod:          pod,
+		ManifestName: m.Name,
+	})
+
+	alerts := f.state.Alerts.Unacknowledged()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, model.AlertSourceCrashLoop, alerts[0].Source)
+		assert.Equal(t, model.AlertSeverityError, alerts[0].Severity)
+		assert.Equal(t, m.Name, alerts[0].ManifestName)
+		assert.Contains(t, alerts[0].Message, "crash looping (3 restarts)")
+	}
+
+	pod = pod.DeepCopy()
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{
+		Running: &v1.ContainerStateRunning{},
+	}
+	handlePodChangeAction(f.ctx, f.state, k8swatch.PodChangeAction{
+		Pod:          pod,
+		ManifestName: m.Name,
+	})
+	assert.Equal(t, 0, len(f.state.Alerts.Alerts))
+}
+
+func TestPodOOMKilled(t *testing.T) {
+	f := newReducerFixture(t)
+	defer f.TearDown()
+
+	ms, _ := f.state.ManifestState("sancho")
+	m, _ := f.state.Manifest("sancho")
+	hash := k8s.PodTemplateSpecHash("ptsh")
+	ms.K8sRuntimeState().DeployedPodTemplateSpecHashSet.Add(hash)
+
+	pod := podbuilder.New(f.T(), m).WithTemplateSpecHash(hash).WithRestartCount(2).Build()
+	pod.Status.ContainerStatuses[0].State = v1.ContainerState{
+		Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+	}
+	pod.Status.ContainerStatuses[0].LastTerminationState = v1.ContainerState{
+		Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+	}
+	handlePodChangeAction(f.ctx, f.state, k8swatch.PodChangeAction{
+		Pod:          pod,
+		ManifestName: m.Name,
+	})
+
+	p := ms.K8sRuntimeState().MostRecentPod()
+	if assert.Equal(t, 1, len(p.Containers)) {
+		c := p.Containers[0]
+		assert.True(t, c.OOMKilled())
+		assert.Equal(t, int32(137), c.LastTerminationExitCode)
+	}
+	assert.Equal(t, "OOMKilled", p.LastTerminationReason())
+
+	alerts := f.state.Alerts.Unacknowledged()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Contains(t, alerts[0].Message, "Last stopped: OOMKilled (exit code 137)")
+	}
+}
+
 // A simple fixture for testing reducers, independently of a store.
 type reducerFixture struct {
 	*tempdir.TempDirFixture
@@ -0,0 +1,15 @@
+package portforward
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Someone connected to one of a resource's port-forwards.
+type ActivityAction struct {
+	ManifestName model.ManifestName
+	Time         time.Time
+}
+
+func (ActivityAction) Action() {}
@@ -0,0 +1,40 @@
+package portforward
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Busy port-forwards can see many connections a second, and nobody needs
+// to know about each one, so we report them at most this often.
+const activityReportInterval = 30 * time.Second
+
+// Reports port-forward connections to the store.
+type activityReporter struct {
+	st   store.RStore
+	name model.ManifestName
+
+	mu           sync.Mutex
+	lastReported time.Time
+}
+
+func newActivityReporter(st store.RStore, name model.ManifestName) *activityReporter {
+	return &activityReporter{st: st, name: name}
+}
+
+func (r *activityReporter) report() {
+	now := time.Now()
+
+	r.mu.Lock()
+	if now.Sub(r.lastReported) < activityReportInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastReported = now
+	r.mu.Unlock()
+
+	r.st.Dispatch(ActivityAction{ManifestName: r.name, Time: now})
+}
@@ -0,0 +1,23 @@
+package portforward
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+func TestActivityReporterThrottles(t *testing.T) {
+	st := store.NewTestingStore()
+	r := newActivityReporter(st, "fe")
+
+	r.report()
+	r.report()
+	r.report()
+
+	actions := st.Actions()
+	if assert.Equal(t, 1, len(actions)) {
+		assert.Equal(t, "fe", string(actions[0].(ActivityAction).ManifestName))
+	}
+}
@@ -0,0 +1,229 @@
+package portforward
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Bodies can be big, and nobody wants to scroll through megabytes of them
+// in the log, so we only log the start.
+const maxCapturedBodySize = 4 * 1024
+
+// An HTTP reverse proxy that logs each request that goes through it.
+//
+// We put this in front of a port forward when the user turns on traffic capture,
+// so that they can see how their frontend talks to their service without
+// reaching for wireshark.
+type captureProxy struct {
+	ctx       context.Context
+	mode      model.TrafficCaptureMode
+	proxy     *httputil.ReverseProxy
+	transport *http.Transport
+}
+
+// dial opens a connection to the upstream server.
+func newCaptureProxy(ctx context.Context, mode model.TrafficCaptureMode, dial func(ctx context.Context) (net.Conn, error)) *captureProxy {
+	p := &captureProxy{ctx: ctx, mode: mode}
+	p.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dial(ctx)
+		},
+		DisableCompression: true,
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			// The Host header stays whatever the client sent,
+			// so the server sees the same request it would without the proxy.
+			req.URL.Scheme = "http"
+			req.URL.Host = req.Host
+		},
+		Transport:     p.transport,
+		FlushInterval: 100 * time.Millisecond,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			if cw, ok := w.(*capturingResponseWriter); ok {
+				cw.err = err
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	return p
+}
+
+func (p *captureProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+
+	var reqBody *cappedBuffer
+	if p.mode == model.TrafficCaptureBodies && req.Body != nil {
+		reqBody = &cappedBuffer{}
+		req.Body = readCloser{Reader: io.TeeReader(req.Body, reqBody), Closer: req.Body}
+	}
+
+	rw := &capturingResponseWriter{ResponseWriter: w}
+	if p.mode == model.TrafficCaptureBodies {
+		rw.body = &cappedBuffer{}
+	}
+	p.proxy.ServeHTTP(rw, req)
+
+	p.log(req, rw, reqBody, time.Since(start))
+}
+
+func (p *captureProxy) log(req *http.Request, rw *capturingResponseWriter, reqBody *cappedBuffer, latency time.Duration) {
+	l := logger.Get(p.ctx)
+	latencyMs := latency.Milliseconds()
+	if rw.err != nil {
+		l.Infof("[traffic] %s %s → error: %v (%dms)", req.Method, req.URL.RequestURI(), rw.err, latencyMs)
+	} else if rw.hijacked {
+		l.Infof("[traffic] %s %s → upgraded to %s (%dms)", req.Method, req.URL.RequestURI(), req.Header.Get("Upgrade"), latencyMs)
+		return
+	} else {
+		status := rw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		l.Infof("[traffic] %s %s → %d %s (%dms, %s)", req.Method, req.URL.RequestURI(),
+			status, http.StatusText(status), latencyMs, formatSize(rw.size))
+	}
+
+	if reqBody != nil && reqBody.total > 0 {
+		l.Infof("[traffic]   request body: %s", reqBody.String())
+	}
+	if rw.body != nil && rw.body.total > 0 {
+		l.Infof("[traffic]   response body: %s", rw.body.String())
+	}
+}
+
+func formatSize(n int64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%dB", n)
+	}
+	return fmt.Sprintf("%.1fkB", float64(n)/1000)
+}
+
+// Serve the proxy on the listener until the context is done.
+//
+// Calls onConnection (if not nil) every time a client connects.
+func (p *captureProxy) serve(listener net.Listener, onConnection func()) error {
+	server := &http.Server{Handler: p}
+	if onConnection != nil {
+		server.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				onConnection()
+			}
+		}
+	}
+	go func() {
+		<-p.ctx.Done()
+		_ = server.Close()
+		p.transport.CloseIdleConnections()
+	}()
+
+	err := server.Serve(listener)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Keeps the first maxCapturedBodySize bytes written to it, and counts the rest.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	total int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	remaining := maxCapturedBodySize - b.buf.Len()
+	if remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	contents := b.buf.Bytes()
+	truncated := b.total > int64(len(contents))
+	if truncated {
+		// We may have cut a multi-byte character in half.
+		for i := 0; i < utf8.UTFMax && len(contents) > 0 && !utf8.Valid(contents); i++ {
+			contents = contents[:len(contents)-1]
+		}
+	}
+	if !utf8.Valid(contents) {
+		return fmt.Sprintf("(%s of binary data)", formatSize(b.total))
+	}
+
+	s := strings.TrimRight(string(contents), "\n")
+	if truncated {
+		s = fmt.Sprintf("%s… (truncated, %s total)", s, formatSize(b.total))
+	}
+	return s
+}
+
+// Records the status, size, and (optionally) body of a response.
+type capturingResponseWriter struct {
+	http.ResponseWriter
+
+	status   int
+	size     int64
+	body     *cappedBuffer
+	hijacked bool
+
+	// The error talking to the upstream server, if any.
+	err error
+}
+
+func (w *capturingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *capturingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	if w.body != nil {
+		_, _ = w.body.Write(p[:n])
+	}
+	return n, err
+}
+
+func (w *capturingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReverseProxy hijacks the connection to proxy websockets and other protocol upgrades.
+func (w *capturingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response doesn't support hijacking")
+	}
+	w.hijacked = true
+	return h.Hijack()
+}
+
+var _ http.Flusher = &capturingResponseWriter{}
+var _ http.Hijacker = &capturingResponseWriter{}
@@ -0,0 +1,145 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCaptureRequests(t *testing.T) {
+	f := newCaptureFixture(t, model.TrafficCaptureRequests)
+	defer f.TearDown()
+
+	resp, err := http.Get(f.url("/hello?name=world"))
+	require.NoError(t, err)
+	body := readBody(t, resp)
+
+	assert.Equal(t, "hello world", body)
+	f.assertLogContains("[traffic] GET /hello?name=world → 200 OK (")
+	f.assertLogContains("ms, 11B)")
+	assert.NotContains(t, f.out.String(), "response body")
+}
+
+func TestCaptureBodies(t *testing.T) {
+	f := newCaptureFixture(t, model.TrafficCaptureBodies)
+	defer f.TearDown()
+
+	resp, err := http.Post(f.url("/echo"), "application/json", strings.NewReader(`{"id": 1}`))
+	require.NoError(t, err)
+	readBody(t, resp)
+
+	f.assertLogContains("[traffic] POST /echo → 201 Created (")
+	f.assertLogContains(`[traffic]   request body: {"id": 1}`)
+	f.assertLogContains(`[traffic]   response body: got {"id": 1}`)
+}
+
+func TestCaptureUpstreamDown(t *testing.T) {
+	f := newCaptureFixture(t, model.TrafficCaptureRequests)
+	defer f.TearDown()
+
+	f.upstream.Close()
+
+	resp, err := http.Get(f.url("/hello"))
+	require.NoError(t, err)
+	readBody(t, resp)
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	f.assertLogContains("[traffic] GET /hello → error: ")
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{}
+	_, _ = b.Write([]byte(strings.Repeat("a", maxCapturedBodySize+10)))
+	assert.Equal(t, strings.Repeat("a", maxCapturedBodySize)+"… (truncated, 4.1kB total)", b.String())
+
+	b = &cappedBuffer{}
+	_, _ = b.Write([]byte{0xff, 0xfe, 0x00})
+	assert.Equal(t, "(3B of binary data)", b.String())
+
+	// Don't mistake a multi-byte character cut off at the end for binary data.
+	b = &cappedBuffer{}
+	_, _ = b.Write([]byte(strings.Repeat("a", maxCapturedBodySize-1) + "é"))
+	assert.Equal(t, strings.Repeat("a", maxCapturedBodySize-1)+"… (truncated, 4.1kB total)", b.String())
+}
+
+type captureFixture struct {
+	t        *testing.T
+	cancel   func()
+	out      *bufsync.ThreadSafeBuffer
+	upstream *httptest.Server
+	addr     string
+}
+
+func newCaptureFixture(t *testing.T, mode model.TrafficCaptureMode) *captureFixture {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hello":
+			_, _ = fmt.Fprintf(w, "hello %s", r.URL.Query().Get("name"))
+		case "/echo":
+			body, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, "got %s", body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	out := bufsync.NewThreadSafeBuffer()
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.DebugLvl, out))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", upstream.Listener.Addr().String())
+	}
+	go func() {
+		_ = newCaptureProxy(ctx, mode, dial).serve(listener, nil)
+	}()
+
+	return &captureFixture{
+		t:        t,
+		cancel:   cancel,
+		out:      out,
+		upstream: upstream,
+		addr:     listener.Addr().String(),
+	}
+}
+
+func (f *captureFixture) url(path string) string {
+	return "http://" + f.addr + path
+}
+
+func (f *captureFixture) assertLogContains(s string) {
+	err := f.out.WaitUntilContains(s, time.Second)
+	assert.NoError(f.t, err)
+}
+
+func (f *captureFixture) TearDown() {
+	f.cancel()
+	f.upstream.Close()
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
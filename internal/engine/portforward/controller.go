@@ -2,40 +2,52 @@ package portforward
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 
-	"github.com/google/go-cmp/cmp"
-
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/portforward"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type Controller struct {
-	kClient k8s.Client
+	kClient   k8s.Client
+	ns        k8s.Namespace
+	listeners *listeners.Registry
 
-	activeForwards map[k8s.PodID]portForwardEntry
+	activeForwards        map[podForwardKey]portForwardEntry
+	activeServiceForwards map[serviceForwardKey]serviceForwardEntry
 }
 
-func NewController(kClient k8s.Client) *Controller {
+func NewController(kClient k8s.Client, ns k8s.Namespace, listeners *listeners.Registry) *Controller {
 	return &Controller{
-		kClient:        kClient,
-		activeForwards: make(map[k8s.PodID]portForwardEntry),
+		kClient:               kClient,
+		ns:                    ns,
+		listeners:             listeners,
+		activeForwards:        make(map[podForwardKey]portForwardEntry),
+		activeServiceForwards: make(map[serviceForwardKey]serviceForwardEntry),
 	}
 }
 
 // Figure out the diff between what's in the data store and
 // what port-forwarding is currently active.
+//
+// Each forward is diffed on its own, so that editing one port_forward in the
+// Tiltfile only restarts that forward, and leaves the others connected.
 func (m *Controller) diff(ctx context.Context, st store.RStore) (toStart []portForwardEntry, toShutdown []portForwardEntry) {
 	state := st.RLockState()
 	defer st.RUnlockState()
 
-	statePods := make(map[k8s.PodID]bool, len(state.ManifestTargets))
+	stateKeys := make(map[podForwardKey]bool)
 
 	// Find all the port-forwards that need to be created.
 	for _, mt := range state.Targets() {
@@ -52,44 +64,39 @@ func (m *Controller) diff(ctx context.Context, st store.RStore) (toStart []portF
 			continue
 		}
 
-		forwards := populatePortForwards(manifest, pod)
-		if len(forwards) == 0 {
-			continue
-		}
-
-		statePods[podID] = true
-
-		oldEntry, isActive := m.activeForwards[podID]
-		if isActive {
-			if cmp.Equal(oldEntry.forwards, forwards) {
+		for _, forward := range populatePortForwards(manifest, pod) {
+			key := podForwardKey{
+				podID:   podID,
+				forward: forward,
+				capture: ms.TrafficCapture,
+			}
+			stateKeys[key] = true
+			if _, isActive := m.activeForwards[key]; isActive {
 				continue
 			}
-			toShutdown = append(toShutdown, oldEntry)
-		}
 
-		ctx, cancel := context.WithCancel(ctx)
-		entry := portForwardEntry{
-			podID:     podID,
-			name:      ms.Name,
-			namespace: pod.Namespace,
-			forwards:  forwards,
-			ctx:       ctx,
-			cancel:    cancel,
-		}
+			ctx, cancel := context.WithCancel(ctx)
+			entry := portForwardEntry{
+				podForwardKey: key,
+				name:          ms.Name,
+				namespace:     pod.Namespace,
+				ctx:           ctx,
+				cancel:        cancel,
+			}
 
-		toStart = append(toStart, entry)
-		m.activeForwards[podID] = entry
+			toStart = append(toStart, entry)
+			m.activeForwards[key] = entry
+		}
 	}
 
 	// Find all the port-forwards that aren't in the manifest anymore
 	// and need to be shutdown.
-	for key, value := range m.activeForwards {
-		_, inState := statePods[key]
-		if inState {
+	for key, entry := range m.activeForwards {
+		if stateKeys[key] {
 			continue
 		}
 
-		toShutdown = append(toShutdown, value)
+		toShutdown = append(toShutdown, entry)
 		delete(m.activeForwards, key)
 	}
 
@@ -97,6 +104,14 @@ func (m *Controller) diff(ctx context.Context, st store.RStore) (toStart []portF
 }
 
 func (m *Controller) OnChange(ctx context.Context, st store.RStore) {
+	svcToStart, svcToShutdown := m.diffServices(ctx, st)
+	for _, entry := range svcToShutdown {
+		entry.cancel()
+	}
+	for _, entry := range svcToStart {
+		go m.runServiceForward(entry)
+	}
+
 	toStart, toShutdown := m.diff(ctx, st)
 	for _, entry := range toShutdown {
 		entry.cancel()
@@ -110,15 +125,60 @@ func (m *Controller) OnChange(ctx context.Context, st store.RStore) {
 			ManifestName: entry.name,
 		})
 
-		for _, forward := range entry.forwards {
-			entry := entry
-			forward := forward
-			go m.startPortForwardLoop(ctx, entry, forward)
+		go m.startPortForwardLoop(ctx, st, entry, entry.forward)
+	}
+}
+
+// The ID of the alert for a resource whose port forwards don't match its pod.
+func AlertID(mn model.ManifestName) string {
+	return fmt.Sprintf("%s:%s", model.AlertSourcePortForward, mn)
+}
+
+func connectAlertID(mn model.ManifestName, localPort int) string {
+	return fmt.Sprintf("%s:%d", AlertID(mn), localPort)
+}
+
+func (m *Controller) startPortForwardLoop(ctx context.Context, st store.RStore, entry portForwardEntry, forward model.PortForward) {
+	alertID := connectAlertID(entry.name, forward.LocalPort)
+	hasAlert := false
+	resolveAlert := func() {
+		if hasAlert {
+			st.Dispatch(store.AlertResolvedAction{ID: alertID})
+			hasAlert = false
 		}
 	}
+	defer resolveAlert()
+
+	l := m.listeners.Add(listeners.KindPortForward, entry.name, forwardProtocol(forward),
+		net.JoinHostPort(forwardHost(forward), strconv.Itoa(forward.LocalPort)),
+		podForwardTarget(entry, forward))
+	defer l.Remove()
+	onConnected := func() {
+		resolveAlert()
+		l.SetListening()
+	}
+
+	ctx = portforward.WithOnConnection(ctx, newActivityReporter(st, entry.name).report)
+	ctx = portforward.WithWrapConn(ctx, l.WrapConn)
+
+	retryWithBackoff(ctx, func() error {
+		return m.onePortForward(ctx, entry, forward, l, onConnected)
+	}, func(err error) {
+		l.SetError(err)
+		logger.Get(ctx).Infof("Reconnecting... Error port-forwarding %s: %v", entry.name, err)
+		st.Dispatch(store.AlertAction{Alert: model.Alert{
+			ID:           alertID,
+			Source:       model.AlertSourcePortForward,
+			Severity:     model.AlertSeverityWarning,
+			ManifestName: entry.name,
+			Message:      fmt.Sprintf("Error port-forwarding %s on port %d: %v", entry.name, forward.LocalPort, err),
+		}})
+		hasAlert = true
+	})
 }
 
-func (m *Controller) startPortForwardLoop(ctx context.Context, entry portForwardEntry, forward model.PortForward) {
+// Runs f until the context is canceled, backing off when f fails quickly.
+func retryWithBackoff(ctx context.Context, f func() error, onError func(err error)) {
 	originalBackoff := wait.Backoff{
 		Steps:    1000,
 		Duration: 50 * time.Millisecond,
@@ -130,7 +190,7 @@ func (m *Controller) startPortForwardLoop(ctx context.Context, entry portForward
 
 	for {
 		start := time.Now()
-		err := m.onePortForward(ctx, entry, forward)
+		err := f()
 		if ctx.Err() != nil {
 			// If the context was canceled, we're satisfied.
 			// Ignore any errors.
@@ -139,7 +199,7 @@ func (m *Controller) startPortForwardLoop(ctx context.Context, entry portForward
 
 		// Otherwise, repeat the loop, maybe logging the error
 		if err != nil {
-			logger.Get(ctx).Infof("Reconnecting... Error port-forwarding %s: %v", entry.name, err)
+			onError(err)
 		}
 
 		// If this failed in less than a second, then we should advance the backoff.
@@ -152,7 +212,17 @@ func (m *Controller) startPortForwardLoop(ctx context.Context, entry portForward
 	}
 }
 
-func (m *Controller) onePortForward(ctx context.Context, entry portForwardEntry, forward model.PortForward) error {
+// Calls onConnected once the port forwarder is up.
+func (m *Controller) onePortForward(ctx context.Context, entry portForwardEntry, forward model.PortForward, l *listeners.Entry, onConnected func()) error {
+	// Traffic capture only understands HTTP, so it doesn't apply to UDP.
+	if forward.IsUDP() {
+		return m.oneUDPPortForward(ctx, entry, forward, l, onConnected)
+	}
+
+	if entry.capture != model.TrafficCaptureOff {
+		return m.oneCapturedPortForward(ctx, entry, forward, l, onConnected)
+	}
+
 	ns := entry.namespace
 	podID := entry.podID
 
@@ -160,6 +230,7 @@ func (m *Controller) onePortForward(ctx context.Context, entry portForwardEntry,
 	if err != nil {
 		return err
 	}
+	onConnected()
 
 	err = pf.ForwardPorts()
 	if err != nil {
@@ -168,23 +239,92 @@ func (m *Controller) onePortForward(ctx context.Context, entry portForwardEntry,
 	return nil
 }
 
+// Forwards an ephemeral port to the pod, and serves a proxy that logs
+// HTTP traffic on the user's port in front of it.
+func (m *Controller) oneCapturedPortForward(ctx context.Context, entry portForwardEntry, forward model.PortForward, l *listeners.Entry, onConnected func()) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(forwardHost(forward), strconv.Itoa(forward.LocalPort)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+	listener = l.WrapListener(listener)
+
+	// The tunnel is only for our own proxy, so always bind it to localhost.
+	// Its connections come from the proxy, so don't count them twice.
+	ctx = portforward.WithWrapConn(ctx, nil)
+	pf, err := m.kClient.CreatePortForwarder(ctx, entry.namespace, entry.podID, 0, forward.ContainerPort, "127.0.0.1")
+	if err != nil {
+		return err
+	}
+	onConnected()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The tunnel reports connections from the proxy as activity, so the proxy doesn't have to.
+	proxy := newCaptureProxy(ctx, entry.capture, dialLocalPort(pf.LocalPort()))
+	go func() {
+		_ = proxy.serve(listener, nil)
+	}()
+
+	return pf.ForwardPorts()
+}
+
+func forwardProtocol(forward model.PortForward) string {
+	if forward.IsUDP() {
+		return "udp"
+	}
+	return "tcp"
+}
+
+func podForwardTarget(entry portForwardEntry, forward model.PortForward) string {
+	target := fmt.Sprintf("pod/%s:%d", entry.podID, forward.ContainerPort)
+	if entry.capture != model.TrafficCaptureOff && !forward.IsUDP() {
+		target += " (via traffic capture proxy)"
+	}
+	return target
+}
+
+func forwardHost(forward model.PortForward) string {
+	if forward.Host == "" {
+		return "localhost"
+	}
+	return forward.Host
+}
+
+func dialLocalPort(port int) func(ctx context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	}
+}
+
 var _ store.Subscriber = &Controller{}
 
+type podForwardKey struct {
+	podID   k8s.PodID
+	forward model.PortForward
+	capture model.TrafficCaptureMode
+}
+
 type portForwardEntry struct {
+	podForwardKey
 	name      model.ManifestName
 	namespace k8s.Namespace
-	podID     k8s.PodID
-	forwards  []model.PortForward
 	ctx       context.Context
 	cancel    func()
 }
 
-// Extract the port-forward specs from the manifest. If any of them
+// Extract the pod port-forward specs from the manifest. If any of them
 // have ContainerPort = 0, populate them with the default port for the pod.
 // Quietly drop forwards that we can't populate.
+//
+// Forwards to a Service are handled separately, and not included.
 func populatePortForwards(m model.Manifest, pod store.Pod) []model.PortForward {
 	cPorts := pod.AllContainerPorts()
-	fwds := m.K8sTarget().PortForwards
+	fwds := podPortForwards(m)
 	forwards := make([]model.PortForward, 0, len(fwds))
 	for _, forward := range fwds {
 		if forward.ContainerPort == 0 {
@@ -206,7 +346,17 @@ func populatePortForwards(m model.Manifest, pod store.Pod) []model.PortForward {
 }
 
 func PortForwardsAreValid(m model.Manifest, pod store.Pod) bool {
-	expectedFwds := m.K8sTarget().PortForwards
+	expectedFwds := podPortForwards(m)
 	actualFwds := populatePortForwards(m, pod)
 	return len(actualFwds) == len(expectedFwds)
 }
+
+func podPortForwards(m model.Manifest) []model.PortForward {
+	var result []model.PortForward
+	for _, fwd := range m.K8sTarget().PortForwards {
+		if fwd.Service == "" {
+			result = append(result, fwd)
+		}
+	}
+	return result
+}
@@ -11,6 +11,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
@@ -73,6 +74,47 @@ func TestPortForward(t *testing.T) {
 		"Expected first port-forward to be canceled")
 }
 
+func TestPortForwardRestartsWhenTrafficCaptureChanges(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	m := model.Manifest{
+		Name: "fe",
+	}
+	m = m.WithDeployTarget(model.K8sTarget{
+		// Let the OS pick the local port, so that the capture proxy can bind it.
+		PortForwards: []model.PortForward{{ContainerPort: 8081}},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	mt := state.ManifestTargets["fe"]
+	mt.State.RuntimeState = store.NewK8sRuntimeStateWithPods(mt.Manifest,
+		store.Pod{PodID: "pod-id", Phase: v1.PodRunning})
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	assert.Equal(t, 1, f.kCli.CreatePortForwardCallCount)
+	assert.Equal(t, "", f.kCli.LastForwardPortHost)
+	directForwardCtx := f.kCli.LastForwardContext
+
+	state = f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.TrafficCapture = model.TrafficCaptureRequests
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	assert.Equal(t, 2, f.kCli.CreatePortForwardCallCount)
+	assert.Equal(t, context.Canceled, directForwardCtx.Err(),
+		"Expected direct port-forward to be canceled")
+
+	// The proxy listens on the user's port, and tunnels to the pod from localhost.
+	assert.Equal(t, "127.0.0.1", f.kCli.LastForwardPortHost)
+	assert.Equal(t, 8081, f.kCli.LastForwardPortRemotePort)
+
+	// Nothing changed, so nothing restarts.
+	f.onChange()
+	assert.Equal(t, 2, f.kCli.CreatePortForwardCallCount)
+}
+
 func TestPortForwardAutoDiscovery(t *testing.T) {
 	f := newPLCFixture(t)
 	defer f.TearDown()
@@ -172,6 +214,41 @@ func TestPortForwardChangePort(t *testing.T) {
 	assert.Equal(t, 8082, f.kCli.LastForwardPortRemotePort)
 }
 
+func TestPortForwardChangeOneOfMany(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{LocalPort: 8080, ContainerPort: 8081},
+			{LocalPort: 9000, ContainerPort: 9001},
+		},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	mt := state.ManifestTargets["fe"]
+	mt.State.RuntimeState = store.NewK8sRuntimeStateWithPods(mt.Manifest, store.Pod{PodID: "pod-id", Phase: v1.PodRunning})
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	assert.Equal(t, 2, len(f.plc.activeForwards))
+	assert.Equal(t, 2, f.kCli.CreatePortForwardCallCount)
+	unchanged := f.activeForwardCtx(8080)
+	changed := f.activeForwardCtx(9000)
+
+	state = f.st.LockMutableStateForTesting()
+	kTarget := state.ManifestTargets["fe"].Manifest.K8sTarget()
+	kTarget.PortForwards[1].ContainerPort = 9002
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	assert.Equal(t, 2, len(f.plc.activeForwards))
+	assert.Equal(t, 3, f.kCli.CreatePortForwardCallCount)
+	assert.Equal(t, 9002, f.kCli.LastForwardPortRemotePort)
+	assert.Equal(t, context.Canceled, changed.Err())
+	assert.NoError(t, unchanged.Err(), "Expected the unchanged port-forward to stay connected")
+}
+
 func TestPortForwardRestart(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("TODO(nick): investigate")
@@ -272,7 +349,7 @@ func newPLCFixture(t *testing.T) *plcFixture {
 	f := tempdir.NewTempDirFixture(t)
 	st := store.NewTestingStore()
 	kCli := k8s.NewFakeK8sClient()
-	plc := NewController(kCli)
+	plc := NewController(kCli, "default", listeners.NewRegistry())
 
 	out := bufsync.NewThreadSafeBuffer()
 	l := logger.NewLogger(logger.DebugLvl, out)
@@ -294,6 +371,16 @@ func (f *plcFixture) onChange() {
 	time.Sleep(10 * time.Millisecond)
 }
 
+func (f *plcFixture) activeForwardCtx(localPort int) context.Context {
+	for key, entry := range f.plc.activeForwards {
+		if key.forward.LocalPort == localPort {
+			return entry.ctx
+		}
+	}
+	f.T().Fatalf("No active port-forward on port %d", localPort)
+	return nil
+}
+
 func (f *plcFixture) TearDown() {
 	f.kCli.TearDown()
 	f.TempDirFixture.TearDown()
@@ -0,0 +1,389 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/portforward"
+	"github.com/tilt-dev/tilt/internal/listeners"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// Kubernetes can only port-forward to a single pod.
+//
+// To forward to a Service, we open a tunnel to each of its ready endpoints
+// on an ephemeral local port, then listen on the user's port and proxy
+// each incoming connection to one of those tunnels, round-robin.
+//
+// We watch the Service's Endpoints, and open and close tunnels as pods
+// become ready or go away.
+type serviceForwardKey struct {
+	name      model.ManifestName
+	namespace k8s.Namespace
+	forward   model.PortForward
+	capture   model.TrafficCaptureMode
+}
+
+type serviceForwardEntry struct {
+	serviceForwardKey
+	st     store.RStore
+	lb     *serviceBalancer
+	ctx    context.Context
+	cancel func()
+}
+
+// A pod and port that a Service is currently routing to.
+type serviceBackend struct {
+	podID k8s.PodID
+	port  int
+}
+
+// Figure out the diff between the Service port-forwards in the data store
+// and the ones that are currently active.
+func (m *Controller) diffServices(ctx context.Context, st store.RStore) (toStart []serviceForwardEntry, toShutdown []serviceForwardEntry) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	stateKeys := make(map[serviceForwardKey]bool)
+	for _, mt := range state.Targets() {
+		manifest := mt.Manifest
+		if !manifest.IsK8s() {
+			continue
+		}
+
+		// Don't bind any ports until we've deployed the Service.
+		if mt.State.LastSuccessfulDeployTime.IsZero() {
+			continue
+		}
+
+		kTarget := manifest.K8sTarget()
+		for _, forward := range kTarget.PortForwards {
+			if forward.Service == "" {
+				continue
+			}
+
+			key := serviceForwardKey{
+				name:      manifest.Name,
+				namespace: m.serviceNamespace(kTarget, forward.Service),
+				forward:   forward,
+				capture:   mt.State.TrafficCapture,
+			}
+			stateKeys[key] = true
+			if _, isActive := m.activeServiceForwards[key]; isActive {
+				continue
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			entry := serviceForwardEntry{
+				serviceForwardKey: key,
+				st:                st,
+				lb:                newServiceBalancer(forward.Service),
+				ctx:               ctx,
+				cancel:            cancel,
+			}
+			toStart = append(toStart, entry)
+			m.activeServiceForwards[key] = entry
+		}
+	}
+
+	for key, entry := range m.activeServiceForwards {
+		if stateKeys[key] {
+			continue
+		}
+		toShutdown = append(toShutdown, entry)
+		delete(m.activeServiceForwards, key)
+	}
+
+	return toStart, toShutdown
+}
+
+// If the Service is deployed by this resource, use its namespace.
+// Otherwise, assume it's in the default namespace.
+func (m *Controller) serviceNamespace(kTarget model.K8sTarget, service string) k8s.Namespace {
+	for _, ref := range kTarget.ObjectRefs {
+		if ref.Kind == "Service" && ref.Name == service && ref.Namespace != "" {
+			return k8s.Namespace(ref.Namespace)
+		}
+	}
+	if m.ns != "" {
+		return m.ns
+	}
+	return k8s.DefaultNamespace
+}
+
+func (m *Controller) runServiceForward(entry serviceForwardEntry) {
+	ctx := logger.CtxWithLogHandler(entry.ctx, serviceLogActionWriter{
+		store:        entry.st,
+		manifestName: entry.name,
+		spanID:       spanIDForService(entry.namespace, entry.forward.Service),
+	})
+
+	target := fmt.Sprintf("service/%s:%d", entry.forward.Service, entry.forward.ContainerPort)
+	if entry.capture != model.TrafficCaptureOff {
+		target += " (via traffic capture proxy)"
+	}
+	l := m.listeners.Add(listeners.KindServiceForward, entry.name, "tcp",
+		net.JoinHostPort(forwardHost(entry.forward), strconv.Itoa(entry.forward.LocalPort)), target)
+	defer l.Remove()
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(forwardHost(entry.forward), strconv.Itoa(entry.forward.LocalPort)))
+	if err != nil {
+		l.SetError(err)
+		logger.Get(ctx).Infof("Error port-forwarding %s to service %s: %v", entry.name, entry.forward.Service, err)
+		<-ctx.Done()
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	listener = l.WrapListener(listener)
+	l.SetListening()
+
+	// The tunnels' connections come from our own balancer, so don't count them twice.
+	ctx = portforward.WithWrapConn(ctx, nil)
+
+	lb := entry.lb
+	onConnection := newActivityReporter(entry.st, entry.name).report
+	if entry.capture != model.TrafficCaptureOff {
+		proxy := newCaptureProxy(ctx, entry.capture, lb.dial)
+		go func() {
+			_ = proxy.serve(listener, onConnection)
+		}()
+	} else {
+		go lb.serve(ctx, listener, onConnection)
+	}
+
+	ch, err := m.kClient.WatchEndpoints(ctx, entry.namespace, labels.Everything())
+	if err != nil {
+		logger.Get(ctx).Infof("Error watching endpoints of service %s: %v", entry.forward.Service, err)
+		return
+	}
+
+	tunnels := make(map[serviceBackend]func())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case endpoints, ok := <-ch:
+			if !ok {
+				return
+			}
+			if endpoints.Name != entry.forward.Service {
+				continue
+			}
+
+			ready := make(map[serviceBackend]bool)
+			for _, backend := range readyBackends(endpoints, entry.forward.ContainerPort) {
+				ready[backend] = true
+				if _, ok := tunnels[backend]; ok {
+					continue
+				}
+
+				tunnelCtx, cancel := context.WithCancel(ctx)
+				tunnels[backend] = cancel
+				go m.startTunnelLoop(tunnelCtx, entry, backend, lb)
+			}
+
+			for backend, cancel := range tunnels {
+				if ready[backend] {
+					continue
+				}
+				cancel()
+				delete(tunnels, backend)
+				lb.remove(backend)
+			}
+		}
+	}
+}
+
+// Keeps a tunnel open to one backend, and registers it with the balancer.
+func (m *Controller) startTunnelLoop(ctx context.Context, entry serviceForwardEntry, backend serviceBackend, lb *serviceBalancer) {
+	retryWithBackoff(ctx, func() error {
+		// Tunnels are only for our own proxy, so always bind them to localhost.
+		pf, err := m.kClient.CreatePortForwarder(ctx, entry.namespace, backend.podID, 0, backend.port, "127.0.0.1")
+		if err != nil {
+			return err
+		}
+
+		lb.add(backend, pf.LocalPort())
+		defer lb.remove(backend)
+		return pf.ForwardPorts()
+	}, func(err error) {
+		logger.Get(ctx).Infof("Reconnecting... Error port-forwarding %s to pod %s: %v",
+			entry.forward.Service, backend.podID, err)
+	})
+}
+
+// The pods that are ready to receive traffic from the Service.
+//
+// If containerPort is 0, we use the first port that the Service routes to.
+func readyBackends(endpoints *v1.Endpoints, containerPort int) []serviceBackend {
+	var result []serviceBackend
+	for _, subset := range endpoints.Subsets {
+		port := containerPort
+		if port == 0 {
+			if len(subset.Ports) == 0 {
+				continue
+			}
+			port = int(subset.Ports[0].Port)
+		}
+
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			result = append(result, serviceBackend{podID: k8s.PodID(addr.TargetRef.Name), port: port})
+		}
+	}
+	return result
+}
+
+type serviceTunnel struct {
+	backend   serviceBackend
+	localPort int
+}
+
+// Round-robins connections across the open tunnels.
+type serviceBalancer struct {
+	service string
+
+	mu      sync.Mutex
+	tunnels []serviceTunnel
+	next    int
+}
+
+func newServiceBalancer(service string) *serviceBalancer {
+	return &serviceBalancer{service: service}
+}
+
+func (b *serviceBalancer) add(backend serviceBackend, localPort int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, t := range b.tunnels {
+		if t.backend == backend {
+			b.tunnels[i].localPort = localPort
+			return
+		}
+	}
+	b.tunnels = append(b.tunnels, serviceTunnel{backend: backend, localPort: localPort})
+}
+
+func (b *serviceBalancer) remove(backend serviceBackend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, t := range b.tunnels {
+		if t.backend == backend {
+			b.tunnels = append(b.tunnels[:i], b.tunnels[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *serviceBalancer) backends() []serviceBackend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make([]serviceBackend, 0, len(b.tunnels))
+	for _, t := range b.tunnels {
+		result = append(result, t.backend)
+	}
+	return result
+}
+
+// The local ports of all the tunnels, starting with the next one in rotation.
+func (b *serviceBalancer) pick() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(b.tunnels)
+	if n == 0 {
+		return nil
+	}
+
+	start := b.next % n
+	b.next = start + 1
+	result := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		result = append(result, b.tunnels[(start+i)%n].localPort)
+	}
+	return result
+}
+
+func (b *serviceBalancer) serve(ctx context.Context, listener net.Listener, onConnection func()) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		onConnection()
+		go b.handle(ctx, conn)
+	}
+}
+
+func (b *serviceBalancer) handle(ctx context.Context, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	upstream, err := b.dial(ctx)
+	if err != nil {
+		logger.Get(ctx).Infof("Dropping connection to service %s: %v", b.service, err)
+		return
+	}
+	proxy(conn, upstream)
+}
+
+// Connects to the next tunnel in rotation.
+func (b *serviceBalancer) dial(ctx context.Context) (net.Conn, error) {
+	// If a tunnel is down (e.g., the pod just went away), fall through to the next one.
+	for _, port := range b.pick() {
+		upstream, err := dialLocalPort(port)(ctx)
+		if err != nil {
+			continue
+		}
+		return upstream, nil
+	}
+	return nil, fmt.Errorf("no ready endpoints")
+}
+
+// Copies data both ways until either side closes.
+func proxy(conn, upstream net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+	_ = upstream.Close()
+	_ = conn.Close()
+	<-done
+}
+
+type serviceLogActionWriter struct {
+	store        store.RStore
+	manifestName model.ManifestName
+	spanID       logstore.SpanID
+}
+
+func (w serviceLogActionWriter) Write(level logger.Level, fields logger.Fields, p []byte) error {
+	w.store.Dispatch(store.NewLogAction(w.manifestName, w.spanID, level, fields, p))
+	return nil
+}
+
+func spanIDForService(ns k8s.Namespace, service string) logstore.SpanID {
+	return logstore.SpanID(fmt.Sprintf("portforward:%s/%s", ns, service))
+}
@@ -0,0 +1,225 @@
+package portforward
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestServiceForwardWaitsForDeploy(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServiceManifest()
+	f.onChange()
+	assert.Equal(t, 0, len(f.plc.activeServiceForwards))
+
+	f.markDeployed()
+	f.onChange()
+	assert.Equal(t, 1, len(f.plc.activeServiceForwards))
+	assert.Equal(t, 0, len(f.plc.activeForwards))
+
+	for key := range f.plc.activeServiceForwards {
+		assert.Equal(t, k8s.Namespace("default"), key.namespace)
+		assert.Equal(t, "fe-svc", key.forward.Service)
+	}
+}
+
+func TestServiceForwardTunnelsToReadyEndpoints(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServiceManifest()
+	f.markDeployed()
+	f.onChange()
+	lb := f.serviceBalancer()
+
+	f.emitEndpoints("fe-svc", []string{"pod-a", "pod-b"}, []string{"pod-c"})
+	assert.Eventually(t, func() bool {
+		return len(lb.backends()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []serviceBackend{{"pod-a", 8000}, {"pod-b", 8000}}, lb.backends())
+
+	f.emitEndpoints("fe-svc", []string{"pod-b"}, nil)
+	assert.Eventually(t, func() bool {
+		backends := lb.backends()
+		return len(backends) == 1 && backends[0].podID == "pod-b"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServiceForwardIgnoresOtherServices(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServiceManifest()
+	f.markDeployed()
+	f.onChange()
+	lb := f.serviceBalancer()
+
+	f.emitEndpoints("other-svc", []string{"pod-a"}, nil)
+	f.emitEndpoints("fe-svc", []string{"pod-b"}, nil)
+	assert.Eventually(t, func() bool {
+		return len(lb.backends()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, k8s.PodID("pod-b"), lb.backends()[0].podID)
+}
+
+func TestServiceForwardShutdown(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	f.upsertServiceManifest()
+	f.markDeployed()
+	f.onChange()
+	require.Equal(t, 1, len(f.plc.activeServiceForwards))
+
+	state := f.st.LockMutableStateForTesting()
+	delete(state.ManifestTargets, "fe")
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	assert.Equal(t, 0, len(f.plc.activeServiceForwards))
+}
+
+func TestServiceBalancerRoundRobin(t *testing.T) {
+	lb := newServiceBalancer("fe-svc")
+	lb.add(serviceBackend{"pod-a", 8000}, startEchoServer(t, "a"))
+	lb.add(serviceBackend{"pod-b", 8000}, startEchoServer(t, "b"))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	f := newPLCFixture(t)
+	defer f.TearDown()
+	go lb.serve(f.ctx, listener, func() {})
+
+	var responses []string
+	for i := 0; i < 4; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		_ = conn.Close()
+		responses = append(responses, line)
+	}
+	assert.Equal(t, []string{"a\n", "b\n", "a\n", "b\n"}, responses)
+}
+
+func TestServiceBalancerSkipsDeadTunnels(t *testing.T) {
+	deadListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadPort := deadListener.Addr().(*net.TCPAddr).Port
+	_ = deadListener.Close()
+
+	lb := newServiceBalancer("fe-svc")
+	lb.add(serviceBackend{"pod-a", 8000}, deadPort)
+	lb.add(serviceBackend{"pod-b", 8000}, startEchoServer(t, "b"))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	f := newPLCFixture(t)
+	defer f.TearDown()
+	go lb.serve(f.ctx, listener, func() {})
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", line)
+}
+
+func (f *plcFixture) upsertServiceManifest() {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+
+	m := model.Manifest{
+		Name: "fe",
+	}
+	m = m.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{
+				// Let the OS pick a port, so that tests don't collide.
+				LocalPort: 0,
+				Service:   "fe-svc",
+			},
+		},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+}
+
+func (f *plcFixture) markDeployed() {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	state.ManifestTargets["fe"].State.LastSuccessfulDeployTime = time.Now()
+}
+
+func (f *plcFixture) serviceBalancer() *serviceBalancer {
+	require.Equal(f.T(), 1, len(f.plc.activeServiceForwards))
+	for _, entry := range f.plc.activeServiceForwards {
+		return entry.lb
+	}
+	return nil
+}
+
+func (f *plcFixture) emitEndpoints(name string, ready []string, notReady []string) {
+	toAddresses := func(pods []string) []v1.EndpointAddress {
+		var result []v1.EndpointAddress
+		for _, pod := range pods {
+			result = append(result, v1.EndpointAddress{
+				TargetRef: &v1.ObjectReference{Kind: "Pod", Name: pod},
+			})
+		}
+		return result
+	}
+
+	endpoints := &v1.Endpoints{}
+	endpoints.Name = name
+	endpoints.Namespace = "default"
+	endpoints.Subsets = []v1.EndpointSubset{
+		{
+			Addresses:         toAddresses(ready),
+			NotReadyAddresses: toAddresses(notReady),
+			Ports:             []v1.EndpointPort{{Port: 8000}},
+		},
+	}
+
+	// The watch is set up asynchronously, so keep trying until someone hears us.
+	assert.Eventually(f.T(), func() bool {
+		return f.kCli.EndpointsWatchCount() > 0
+	}, time.Second, 10*time.Millisecond)
+	f.kCli.EmitEndpoints(labels.Everything(), endpoints)
+}
+
+// Starts a server that writes its name to every connection and hangs up.
+func startEchoServer(t *testing.T, name string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(name + "\n"))
+			_ = conn.Close()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
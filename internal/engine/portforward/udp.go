@@ -0,0 +1,177 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/portforward"
+	"github.com/tilt-dev/tilt/internal/listeners"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Kubernetes port-forwarding only carries TCP streams.
+//
+// To forward a UDP port, we attach an ephemeral container running socat to
+// the pod. It shares the pod's network, so it can accept TCP connections on a
+// relay port and send what it receives as datagrams to the container's UDP port.
+//
+// socat doesn't keep datagram boundaries on the TCP side, so we can't
+// multiplex a stream of datagrams over one connection. Instead, each datagram
+// from a local client gets its own tunnel connection, and we relay the first
+// reply that comes back on it. That covers request/response protocols like
+// DNS, but not protocols that send several replies to one request, or that
+// send datagrams the client didn't ask for.
+const UDPRelayImage = "alpine/socat:1.7.4.1-r1"
+
+// The relay for container port N listens on TCP port
+// udpRelayBasePort + N % udpRelayPortRange, which is unlikely to be taken.
+const udpRelayBasePort = 20000
+const udpRelayPortRange = 20000
+
+// Give up on a datagram's reply after this long.
+const udpReplyTimeout = 30 * time.Second
+
+const maxDatagramSize = 65535
+
+func udpRelayPort(containerPort int) int {
+	return udpRelayBasePort + containerPort%udpRelayPortRange
+}
+
+func udpRelayContainer(containerPort int) v1.EphemeralContainer {
+	return v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:  fmt.Sprintf("tilt-udp-forward-%d", containerPort),
+			Image: UDPRelayImage,
+			Args: []string{
+				fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", udpRelayPort(containerPort)),
+				fmt.Sprintf("UDP:127.0.0.1:%d", containerPort),
+			},
+		},
+	}
+}
+
+// Calls onConnected once the tunnel to the relay is up.
+func (m *Controller) oneUDPPortForward(ctx context.Context, entry portForwardEntry, forward model.PortForward, l *listeners.Entry, onConnected func()) error {
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(forwardHost(forward), strconv.Itoa(forward.LocalPort)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	err = m.kClient.EnsureEphemeralContainer(ctx, entry.podID, entry.namespace, udpRelayContainer(forward.ContainerPort))
+	if err != nil {
+		return err
+	}
+
+	// The tunnel is only for our own relay, so always bind it to localhost.
+	// The relay counts datagrams itself.
+	ctx = portforward.WithWrapConn(ctx, nil)
+	pf, err := m.kClient.CreatePortForwarder(ctx, entry.namespace, entry.podID, 0, udpRelayPort(forward.ContainerPort), "127.0.0.1")
+	if err != nil {
+		return err
+	}
+	onConnected()
+
+	logger.Get(ctx).Warnf("Forwarding UDP port %d: each datagram gets at most one reply", forward.LocalPort)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	relay := newUDPRelay(conn, dialLocalPort(pf.LocalPort()))
+	relay.listener = l
+	go relay.serve(ctx)
+
+	return pf.ForwardPorts()
+}
+
+type udpRelay struct {
+	conn net.PacketConn
+	dial func(ctx context.Context) (net.Conn, error)
+
+	// Counts each datagram as a connection, and the bytes of the datagrams. May be nil.
+	listener *listeners.Entry
+}
+
+func newUDPRelay(conn net.PacketConn, dial func(ctx context.Context) (net.Conn, error)) *udpRelay {
+	return &udpRelay{
+		conn: conn,
+		dial: dial,
+	}
+}
+
+// Relays datagrams until the context is canceled or the local socket closes.
+func (r *udpRelay) serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		_ = r.conn.Close()
+	}()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if r.listener != nil {
+			r.listener.AddBytesIn(n)
+			r.listener.AddConnection()
+		}
+
+		datagram := append([]byte{}, buf[:n]...)
+		go func() {
+			err := r.exchange(ctx, datagram, addr)
+			if err != nil {
+				logger.Get(ctx).Debugf("Error relaying UDP from %s: %v", addr, err)
+			}
+		}()
+	}
+}
+
+// Sends one datagram through its own tunnel connection,
+// and sends the first reply back to the client.
+func (r *udpRelay) exchange(ctx context.Context, datagram []byte, addr net.Addr) error {
+	upstream, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = upstream.Close()
+	}()
+
+	// Don't outlive the relay.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = upstream.Close()
+		case <-done:
+		}
+	}()
+
+	_, err = upstream.Write(datagram)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, maxDatagramSize)
+	_ = upstream.SetReadDeadline(time.Now().Add(udpReplyTimeout))
+	n, err := upstream.Read(buf)
+	if err != nil {
+		return err
+	}
+
+	n, err = r.conn.WriteTo(buf[:n], addr)
+	if r.listener != nil {
+		r.listener.AddBytesOut(n)
+	}
+	return err
+}
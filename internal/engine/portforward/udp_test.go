@@ -0,0 +1,104 @@
+package portforward
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestUDPRelay(t *testing.T) {
+	// Stands in for the relay container: upper-cases whatever it gets.
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = upstream.Close() }()
+	accepted := make(chan bool, 10)
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- true
+			go func() {
+				buf := make([]byte, 1024)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					_, _ = conn.Write([]byte(strings.ToUpper(string(buf[:n]))))
+				}
+			}()
+		}
+	}()
+
+	local, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay := newUDPRelay(local, func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", upstream.Addr().String())
+	})
+	go relay.serve(ctx)
+
+	client, err := net.Dial("udp", local.LocalAddr().String())
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	for _, msg := range []string{"hello", "again"} {
+		_, err = client.Write([]byte(msg))
+		require.NoError(t, err)
+
+		_ = client.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, 1024)
+		n, err := client.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToUpper(msg), string(buf[:n]))
+	}
+
+	// socat doesn't keep datagram boundaries, so each datagram gets its own tunnel connection.
+	assert.Len(t, accepted, 2)
+}
+
+func TestUDPPortForwardUsesRelayContainer(t *testing.T) {
+	f := newPLCFixture(t)
+	defer f.TearDown()
+
+	state := f.st.LockMutableStateForTesting()
+	m := model.Manifest{
+		Name: "dns",
+	}
+	m = m.WithDeployTarget(model.K8sTarget{
+		// Let the OS pick the local port, so that the test can bind it.
+		PortForwards: []model.PortForward{{ContainerPort: 53, Protocol: model.PortForwardUDP}},
+	})
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	mt := state.ManifestTargets["dns"]
+	mt.State.RuntimeState = store.NewK8sRuntimeStateWithPods(mt.Manifest,
+		store.Pod{PodID: "pod-id", Namespace: "default", Phase: v1.PodRunning})
+	f.st.UnlockMutableState()
+
+	f.onChange()
+
+	require.Len(t, f.kCli.EphemeralContainerCalls, 1)
+	call := f.kCli.EphemeralContainerCalls[0]
+	assert.Equal(t, "pod-id", call.PID.String())
+	assert.Equal(t, "tilt-udp-forward-53", call.Container.Name)
+	assert.Equal(t, []string{"TCP-LISTEN:20053,fork,reuseaddr", "UDP:127.0.0.1:53"}, call.Container.Args)
+
+	// The tunnel goes to the relay, from localhost.
+	assert.Equal(t, 1, f.kCli.CreatePortForwardCallCount)
+	assert.Equal(t, 20053, f.kCli.LastForwardPortRemotePort)
+	assert.Equal(t, "127.0.0.1", f.kCli.LastForwardPortHost)
+}
@@ -92,6 +92,10 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 					if existing.ctx.Err() == nil {
 						// The active pod watcher is still tailing the logs,
 						// nothing to do.
+						if c.Terminated && !existing.terminated {
+							existing.terminated = true
+							m.watches[key] = existing
+						}
 						continue
 					}
 
@@ -116,6 +120,8 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 					startWatchTime:  startWatchTime,
 					terminationTime: make(chan time.Time, 1),
 					shouldPrefix:    shouldPrefix,
+					isInit:          isInitContainer,
+					terminated:      c.Terminated,
 				}
 				m.watches[key] = w
 				setup = append(setup, w)
@@ -127,6 +133,13 @@ func (m *PodLogManager) diff(ctx context.Context, st store.RStore) (setup []PodL
 		_, inState := stateWatches[key]
 		if !inState {
 			delete(m.watches, key)
+
+			// Pods from Jobs often finish and get cleaned up (or pruned in favor of
+			// a retry) before we've read all their logs. Once a container has
+			// terminated, its log stream ends on its own, so let it drain.
+			if value.terminated {
+				continue
+			}
 			teardown = append(teardown, value)
 		}
 	}
@@ -180,6 +193,9 @@ func (m *PodLogManager) consumeLogs(watch PodLogWatch, st store.RStore) {
 	})
 	if watch.shouldPrefix {
 		prefix := fmt.Sprintf("[%s] ", watch.cName)
+		if watch.isInit {
+			prefix = fmt.Sprintf("[init:%s] ", watch.cName)
+		}
 		ctx = logger.WithLogger(ctx, logger.NewPrefixedLogger(prefix, logger.Get(ctx)))
 	}
 
@@ -214,6 +230,10 @@ type PodLogWatch struct {
 	terminationTime chan time.Time
 
 	shouldPrefix bool // if true, we'll prefix logs with the container name
+	isInit       bool // if true, the container is an init container
+
+	// Whether the container had terminated the last time we saw it.
+	terminated bool
 }
 
 type podLogKey struct {
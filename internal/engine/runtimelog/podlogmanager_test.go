@@ -256,12 +256,64 @@ func TestInitContainerLogs(t *testing.T) {
 
 	f.plm.OnChange(f.ctx, f.store)
 
-	f.AssertOutputContains(cNameInit.String())
-	f.AssertOutputContains("init world!")
+	f.AssertOutputContains("[init:cNameInit] init world!")
 	f.AssertOutputDoesNotContain(cNameNormal.String())
 	f.AssertOutputContains("hello world!")
 }
 
+func TestTerminatedContainerLogsDrainAfterPodRemoved(t *testing.T) {
+	f := newPLMFixture(t)
+	defer f.TearDown()
+
+	state := f.store.LockMutableStateForTesting()
+	p := store.Pod{
+		PodID: podID,
+		Containers: []store.Container{
+			NewTerminatedContainer("migrate", "cID-migrate"),
+		},
+	}
+	state.UpsertManifestTarget(manifestutils.NewManifestTargetWithPod(
+		model.Manifest{Name: "migrate"}, p))
+	f.store.UnlockMutableState()
+
+	setup, teardown := f.plm.diff(f.ctx, f.store)
+	assert.Len(t, setup, 1)
+	assert.Len(t, teardown, 0)
+
+	// The Job cleans up its pod.
+	state = f.store.LockMutableStateForTesting()
+	state.ManifestTargets["migrate"].State.RuntimeState = store.NewK8sRuntimeState(model.Manifest{})
+	f.store.UnlockMutableState()
+
+	setup, teardown = f.plm.diff(f.ctx, f.store)
+	assert.Len(t, setup, 0)
+	assert.Len(t, teardown, 0, "terminated containers should finish streaming their logs")
+}
+
+func TestRunningContainerLogsStopAfterPodRemoved(t *testing.T) {
+	f := newPLMFixture(t)
+	defer f.TearDown()
+
+	state := f.store.LockMutableStateForTesting()
+	p := store.Pod{
+		PodID:      podID,
+		Containers: []store.Container{NewRunningContainer(cName, cID)},
+	}
+	state.UpsertManifestTarget(manifestutils.NewManifestTargetWithPod(
+		model.Manifest{Name: "server"}, p))
+	f.store.UnlockMutableState()
+
+	setup, _ := f.plm.diff(f.ctx, f.store)
+	assert.Len(t, setup, 1)
+
+	state = f.store.LockMutableStateForTesting()
+	state.ManifestTargets["server"].State.RuntimeState = store.NewK8sRuntimeState(model.Manifest{})
+	f.store.UnlockMutableState()
+
+	_, teardown := f.plm.diff(f.ctx, f.store)
+	assert.Len(t, teardown, 1)
+}
+
 type plmFixture struct {
 	*tempdir.TempDirFixture
 	ctx     context.Context
@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+// Jobs that come due within this window of each other are run
+// in the same wakeup, so that periodic work across subsystems
+// doesn't wake the process up separately.
+const coalesceWindow = 5 * time.Second
+
+// Each interval is randomly stretched or shrunk by up to this fraction,
+// so that jobs with the same interval drift apart rather than
+// firing in lockstep forever.
+const jitterFraction = 0.1
+
+type Job func(ctx context.Context)
+
+// Scheduler is the central place for periodic background work
+// (analytics reports, cloud status refreshes, etc).
+//
+// Rather than each subsystem running its own ticker, jobs register here
+// and share a single timer, which sleeps until the next job is due.
+// When nothing is due, Tilt doesn't wake up at all.
+type Scheduler struct {
+	clock  clockwork.Clock
+	jitter func(d time.Duration) time.Duration
+
+	mu      sync.Mutex
+	entries []*entry
+	wake    chan struct{}
+	started bool
+}
+
+type entry struct {
+	ctx      context.Context
+	name     string
+	interval time.Duration
+	next     time.Time
+	job      Job
+	running  bool
+}
+
+var _ store.SetUpper = &Scheduler{}
+var _ store.Subscriber = &Scheduler{}
+
+func NewScheduler(clock clockwork.Clock) *Scheduler {
+	return &Scheduler{
+		clock:  clock,
+		jitter: randomJitter,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func randomJitter(d time.Duration) time.Duration {
+	maxJitter := int64(float64(d) * jitterFraction)
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(2*maxJitter+1) - maxJitter)
+}
+
+// Every runs the job once after initialDelay, then roughly every interval after that,
+// until the given context is done.
+//
+// If the job is still running when it comes due again, that run is skipped.
+func (s *Scheduler) Every(ctx context.Context, name string, initialDelay, interval time.Duration, job Job) {
+	s.mu.Lock()
+	s.entries = append(s.entries, &entry{
+		ctx:      ctx,
+		name:     name,
+		interval: interval,
+		next:     s.clock.Now().Add(initialDelay),
+		job:      job,
+	})
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// The names of all the jobs that are currently scheduled, sorted.
+func (s *Scheduler) JobNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]string, 0, len(s.entries))
+	for _, e := range s.entries {
+		result = append(result, e.name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Scheduler) SetUp(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go s.loop(ctx)
+}
+
+func (s *Scheduler) OnChange(ctx context.Context, st store.RStore) {}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	// The clock interface doesn't let us stop timers, so we only
+	// make a new one when a job comes due earlier than the current one.
+	var timer <-chan time.Time
+	var timerAt time.Time
+	for {
+		next, ok := s.nextDue()
+		if ok && (timer == nil || next.Before(timerAt)) {
+			timer = s.clock.After(next.Sub(s.clock.Now()))
+			timerAt = next
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer:
+			timer = nil
+			s.runDue()
+		}
+	}
+}
+
+// Returns the earliest time that any job is due, and false if there are no jobs.
+func (s *Scheduler) nextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeDoneLocked()
+
+	var next time.Time
+	for _, e := range s.entries {
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func (s *Scheduler) removeDoneLocked() {
+	live := s.entries[:0]
+	for _, e := range s.entries {
+		if e.ctx.Err() == nil {
+			live = append(live, e)
+		}
+	}
+	for i := len(live); i < len(s.entries); i++ {
+		s.entries[i] = nil
+	}
+	s.entries = live
+}
+
+func (s *Scheduler) runDue() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	horizon := now.Add(coalesceWindow)
+	for _, e := range s.entries {
+		if e.next.After(horizon) || e.ctx.Err() != nil {
+			continue
+		}
+
+		e.next = now.Add(e.interval + s.jitter(e.interval))
+		if e.running {
+			continue
+		}
+
+		e.running = true
+		go func(e *entry) {
+			defer func() {
+				s.mu.Lock()
+				e.running = false
+				s.mu.Unlock()
+			}()
+			e.job(e.ctx)
+		}(e)
+	}
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	runs := make(chan string, 10)
+	f.s.Every(f.ctx, "report", 10*time.Second, time.Minute, func(ctx context.Context) {
+		runs <- "report"
+	})
+	f.start()
+
+	f.advance(9 * time.Second)
+	f.assertNoRuns(runs)
+
+	f.advance(time.Second)
+	f.assertRun(runs, "report")
+
+	f.advance(time.Minute)
+	f.assertRun(runs, "report")
+}
+
+func TestCoalesce(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	runs := make(chan string, 10)
+	f.s.Every(f.ctx, "a", time.Minute, time.Hour, func(ctx context.Context) {
+		runs <- "a"
+	})
+	f.s.Every(f.ctx, "b", time.Minute+time.Second, time.Hour, func(ctx context.Context) {
+		runs <- "b"
+	})
+	f.start()
+
+	// b is due a second after a, so they should share a wakeup.
+	f.advance(time.Minute)
+	f.assertRunsInAnyOrder(runs, "a", "b")
+}
+
+func TestCanceledJobsAreRemoved(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	f.s.Every(ctx, "a", time.Minute, time.Hour, func(ctx context.Context) {})
+	f.s.Every(f.ctx, "b", time.Minute, time.Hour, func(ctx context.Context) {})
+	assert.Equal(t, []string{"a", "b"}, f.s.JobNames())
+
+	cancel()
+	f.start()
+	assert.Eventually(t, func() bool {
+		return len(f.s.JobNames()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"b"}, f.s.JobNames())
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+	clock  clockwork.FakeClock
+	s      *Scheduler
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := clockwork.NewFakeClock()
+	s := NewScheduler(clock)
+	s.jitter = func(d time.Duration) time.Duration { return 0 }
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		cancel: cancel,
+		clock:  clock,
+		s:      s,
+	}
+}
+
+func (f *fixture) start() {
+	f.s.SetUp(f.ctx)
+}
+
+func (f *fixture) advance(d time.Duration) {
+	f.clock.BlockUntil(1)
+	f.clock.Advance(d)
+}
+
+func (f *fixture) assertRun(runs chan string, expected string) {
+	select {
+	case actual := <-runs:
+		assert.Equal(f.t, expected, actual)
+	case <-time.After(time.Second):
+		f.t.Fatalf("Timed out waiting for job %s", expected)
+	}
+}
+
+func (f *fixture) assertRunsInAnyOrder(runs chan string, expected ...string) {
+	actual := []string{}
+	for range expected {
+		select {
+		case name := <-runs:
+			actual = append(actual, name)
+		case <-time.After(time.Second):
+			f.t.Fatalf("Timed out waiting for jobs %v. Got: %v", expected, actual)
+		}
+	}
+	assert.ElementsMatch(f.t, expected, actual)
+}
+
+func (f *fixture) assertNoRuns(runs chan string) {
+	select {
+	case name := <-runs:
+		f.t.Fatalf("Unexpected run of job %s", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func (f *fixture) tearDown() {
+	f.cancel()
+}
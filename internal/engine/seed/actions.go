@@ -0,0 +1,14 @@
+package seed
+
+import (
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A seed data load started or finished.
+type StatusAction struct {
+	ManifestName model.ManifestName
+	Status       store.SeedStatus
+}
+
+func (StatusAction) Action() {}
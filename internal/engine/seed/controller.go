@@ -0,0 +1,141 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// Loads each resource's seed data (see k8s_resource(seed=...)) into its pod,
+// by piping the seed file into a command with kubectl exec, once the pod is ready.
+//
+// With on='first-deploy', Tilt loads the data once per session, so a pod with
+// a persistent volume may see the same data again when Tilt restarts.
+// Seed scripts should be idempotent (e.g., CREATE TABLE IF NOT EXISTS).
+type Controller struct {
+	kCli  k8s.Client
+	clock build.Clock
+
+	// The pod we last loaded each resource's seed data into.
+	lastPod map[model.ManifestName]k8s.PodID
+
+	// Resources whose seed data loaded without error this session.
+	loaded map[model.ManifestName]bool
+}
+
+var _ store.Subscriber = &Controller{}
+
+func NewController(kCli k8s.Client, clock build.Clock) *Controller {
+	return &Controller{
+		kCli:    kCli,
+		clock:   clock,
+		lastPod: make(map[model.ManifestName]k8s.PodID),
+		loaded:  make(map[model.ManifestName]bool),
+	}
+}
+
+// Seed data to load into a ready pod.
+type job struct {
+	mn        model.ManifestName
+	seed      model.K8sSeed
+	pod       store.Pod
+	container container.Name
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	var jobs []job
+	state := st.RLockState()
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+		seed := mt.Manifest.K8sTarget().Seed
+		if seed.Empty() {
+			continue
+		}
+
+		mn := mt.Manifest.Name
+		pod := mt.State.K8sRuntimeState().MostRecentPod()
+		if pod.Empty() || pod.Deleting || !pod.AllContainersReady() {
+			continue
+		}
+		if c.lastPod[mn] == pod.PodID {
+			continue
+		}
+		if seed.On == model.SeedOnFirstDeploy && c.loaded[mn] {
+			continue
+		}
+
+		cName := container.Name(seed.Container)
+		if cName == "" {
+			cName = pod.Containers[0].Name
+		}
+		c.lastPod[mn] = pod.PodID
+		jobs = append(jobs, job{mn: mn, seed: seed, pod: pod, container: cName})
+	}
+	st.RUnlockState()
+
+	for _, j := range jobs {
+		c.load(ctx, st, j)
+	}
+}
+
+func (c *Controller) load(ctx context.Context, st store.RStore, j job) {
+	l := c.logger(ctx, st, j.mn)
+	status := store.SeedStatus{
+		State:     store.SeedStateRunning,
+		PodID:     j.pod.PodID,
+		StartTime: c.clock.Now(),
+	}
+	st.Dispatch(StatusAction{ManifestName: j.mn, Status: status})
+
+	l.Infof("Loading seed data from %s into pod %s", filepath.Base(j.seed.Path), j.pod.PodID)
+	err := c.exec(ctx, l, j)
+
+	status.FinishTime = c.clock.Now()
+	if err != nil {
+		status.State = store.SeedStateError
+		status.Error = err.Error()
+		l.Errorf("Loading seed data: %v", err)
+	} else {
+		status.State = store.SeedStateDone
+		c.loaded[j.mn] = true
+		l.Infof("Loaded seed data in %s", status.FinishTime.Sub(status.StartTime))
+	}
+	st.Dispatch(StatusAction{ManifestName: j.mn, Status: status})
+}
+
+func (c *Controller) exec(ctx context.Context, l logger.Logger, j job) error {
+	// Read the file each time, so that edits show up in the next load.
+	f, err := os.Open(j.seed.Path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	err = c.kCli.Exec(ctx, j.pod.PodID, j.container, j.pod.Namespace, j.seed.Cmd.Argv,
+		f, l.Writer(logger.InfoLvl), l.Writer(logger.InfoLvl))
+	if err != nil {
+		return fmt.Errorf("%s: %v", j.seed.Cmd, err)
+	}
+	return nil
+}
+
+// Sends the seed command's output to the resource's log.
+func (c *Controller) logger(ctx context.Context, st store.RStore, mn model.ManifestName) logger.Logger {
+	spanID := logstore.SpanID(fmt.Sprintf("seed:%s", mn))
+	l := logger.Get(ctx)
+	return logger.NewFuncLogger(l.SupportsColor(), l.Level(), func(level logger.Level, fields logger.Fields, b []byte) error {
+		st.Dispatch(store.NewLogAction(mn, spanID, level, fields, b))
+		return nil
+	})
+}
@@ -0,0 +1,191 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLoadWhenPodReady(t *testing.T) {
+	f := newFixture(t, model.SeedOnFirstDeploy)
+	defer f.TearDown()
+
+	f.setPod("pod-1", false)
+	f.onChange()
+	assert.Empty(t, f.kCli.ExecCalls)
+
+	f.setPod("pod-1", true)
+	f.onChange()
+	require.Len(t, f.kCli.ExecCalls, 1)
+	call := f.kCli.ExecCalls[0]
+	assert.Equal(t, k8s.PodID("pod-1"), call.PID)
+	assert.Equal(t, container.Name("postgres"), call.CName)
+	assert.Equal(t, k8s.Namespace("default"), call.Ns)
+	assert.Equal(t, model.DefaultSeedSQLCmd.Argv, call.Cmd)
+	assert.Equal(t, "INSERT INTO users VALUES (1);\n", string(call.Stdin))
+
+	status := f.lastStatus()
+	assert.Equal(t, store.SeedStateDone, status.State)
+	assert.Equal(t, k8s.PodID("pod-1"), status.PodID)
+	f.assertLog("Loading seed data from seed.sql into pod pod-1")
+
+	// Only load once per pod.
+	f.onChange()
+	assert.Len(t, f.kCli.ExecCalls, 1)
+}
+
+func TestFirstDeployOnlyLoadsOnce(t *testing.T) {
+	f := newFixture(t, model.SeedOnFirstDeploy)
+	defer f.TearDown()
+
+	f.setPod("pod-1", true)
+	f.onChange()
+	f.setPod("pod-2", true)
+	f.onChange()
+	assert.Len(t, f.kCli.ExecCalls, 1)
+}
+
+func TestEveryDeployLoadsIntoEachPod(t *testing.T) {
+	f := newFixture(t, model.SeedOnEveryDeploy)
+	defer f.TearDown()
+
+	f.setPod("pod-1", true)
+	f.onChange()
+	f.setPod("pod-2", true)
+	f.onChange()
+	require.Len(t, f.kCli.ExecCalls, 2)
+	assert.Equal(t, k8s.PodID("pod-2"), f.kCli.ExecCalls[1].PID)
+}
+
+func TestFirstDeployRetriesInNextPodAfterError(t *testing.T) {
+	f := newFixture(t, model.SeedOnFirstDeploy)
+	defer f.TearDown()
+
+	f.kCli.ExecErrors = []error{fmt.Errorf("command terminated with exit code 3")}
+	f.setPod("pod-1", true)
+	f.onChange()
+
+	status := f.lastStatus()
+	assert.Equal(t, store.SeedStateError, status.State)
+	assert.Contains(t, status.Error, "exit code 3")
+	f.assertLog("Loading seed data: ")
+
+	// Don't retry in the same pod.
+	f.onChange()
+	assert.Len(t, f.kCli.ExecCalls, 1)
+
+	f.setPod("pod-2", true)
+	f.onChange()
+	assert.Len(t, f.kCli.ExecCalls, 2)
+	assert.Equal(t, store.SeedStateDone, f.lastStatus().State)
+}
+
+func TestMissingSeedFile(t *testing.T) {
+	f := newFixture(t, model.SeedOnFirstDeploy)
+	defer f.TearDown()
+
+	f.Rm("seed.sql")
+	f.setPod("pod-1", true)
+	f.onChange()
+
+	assert.Empty(t, f.kCli.ExecCalls)
+	assert.Equal(t, store.SeedStateError, f.lastStatus().State)
+}
+
+type fixture struct {
+	*tempdir.TempDirFixture
+	ctx  context.Context
+	kCli *k8s.FakeK8sClient
+	st   *store.TestingStore
+	c    *Controller
+	m    model.Manifest
+}
+
+func newFixture(t *testing.T, on model.SeedTrigger) *fixture {
+	tf := tempdir.NewTempDirFixture(t)
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	kCli := k8s.NewFakeK8sClient()
+	st := store.NewTestingStore()
+
+	path := tf.WriteFile("seed.sql", "INSERT INTO users VALUES (1);\n")
+	m := model.Manifest{Name: "postgres"}.WithDeployTarget(model.K8sTarget{
+		Name: "postgres",
+		Seed: model.K8sSeed{Path: path, Cmd: model.DefaultSeedSQLCmd, On: on},
+	})
+	st.WithState(func(state *store.EngineState) {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	})
+
+	return &fixture{
+		TempDirFixture: tf,
+		ctx:            ctx,
+		kCli:           kCli,
+		st:             st,
+		c:              NewController(kCli, fakeClock{now: time.Unix(1600000000, 0)}),
+		m:              m,
+	}
+}
+
+func (f *fixture) setPod(id k8s.PodID, ready bool) {
+	f.st.WithState(func(state *store.EngineState) {
+		ms, ok := state.ManifestState("postgres")
+		require.True(f.T(), ok)
+		ms.RuntimeState = store.NewK8sRuntimeStateWithPods(f.m, store.Pod{
+			PodID:     id,
+			Namespace: "default",
+			Containers: []store.Container{
+				{Name: "postgres", Ready: ready, Running: true},
+			},
+		})
+	})
+}
+
+func (f *fixture) onChange() {
+	f.c.OnChange(f.ctx, f.st)
+}
+
+func (f *fixture) lastStatus() store.SeedStatus {
+	actions := f.st.Actions()
+	for i := len(actions) - 1; i >= 0; i-- {
+		a, ok := actions[i].(StatusAction)
+		if ok {
+			return a.Status
+		}
+	}
+	f.T().Fatal("No seed status")
+	return store.SeedStatus{}
+}
+
+func (f *fixture) assertLog(expected string) {
+	var logs []string
+	for _, action := range f.st.Actions() {
+		la, ok := action.(store.LogAction)
+		if !ok {
+			continue
+		}
+		if strings.Contains(string(la.Message()), expected) {
+			assert.Equal(f.T(), model.ManifestName("postgres"), la.ManifestName())
+			return
+		}
+		logs = append(logs, string(la.Message()))
+	}
+	f.T().Errorf("Expected log %q. Actual: %v", expected, logs)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time { return c.now }
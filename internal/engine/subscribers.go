@@ -4,18 +4,32 @@ import (
 	"github.com/tilt-dev/tilt/internal/cloud"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
 	"github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/autoscale"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/cronjob"
 	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/exit"
 	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/hibernate"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
+	"github.com/tilt-dev/tilt/internal/engine/k8sheartbeat"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/localdns"
 	"github.com/tilt-dev/tilt/internal/engine/metrics"
 	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
@@ -25,6 +39,7 @@ import (
 func ProvideSubscribers(
 	hud hud.HeadsUpDisplay,
 	ts *hud.TerminalStream,
+	js *hud.JSONStream,
 	tp *prompt.TerminalPrompt,
 	pw *k8swatch.PodWatcher,
 	sw *k8swatch.ServiceWatcher,
@@ -32,6 +47,7 @@ func ProvideSubscribers(
 	pfc *portforward.Controller,
 	fwm *fswatch.WatchManager,
 	gm *fswatch.GitManager,
+	flc *fswatch.LimitsChecker,
 	bc *BuildController,
 	cc *configs.ConfigsController,
 	dcw *dcwatch.EventWatcher,
@@ -44,15 +60,31 @@ func ProvideSubscribers(
 	ewm *k8swatch.EventWatchManager,
 	tcum *cloud.CloudStatusManager,
 	dp *dockerprune.DockerPruner,
+	dg *dockerprune.DiskGovernor,
 	tc *telemetry.Controller,
 	lc *local.Controller,
 	podm *k8srollout.PodMonitor,
 	ec *exit.Controller,
 	mc *metrics.Controller,
+	hbc *k8sheartbeat.Controller,
+	kcc *k8scredentials.Controller,
+	ldc *localdns.Controller,
+	hc *hibernate.Controller,
+	ehc *endpointhealth.Controller,
+	bic *baseimage.Controller,
+	lkc *linkdiscovery.Controller,
+	bla *buildlogs.Archiver,
+	cjc *cronjob.Controller,
+	asc *autoscale.Controller,
+	wsc *workloadstatus.Controller,
+	sdc *seed.Controller,
+	wd *watchdog.Watchdog,
+	sched *scheduler.Scheduler,
 ) []store.Subscriber {
 	return []store.Subscriber{
 		hud,
 		ts,
+		js,
 		tp,
 		pw,
 		sw,
@@ -60,6 +92,7 @@ func ProvideSubscribers(
 		pfc,
 		fwm,
 		gm,
+		flc,
 		bc,
 		cc,
 		dcw,
@@ -72,10 +105,25 @@ func ProvideSubscribers(
 		ewm,
 		tcum,
 		dp,
+		dg,
 		tc,
 		lc,
 		podm,
 		ec,
 		mc,
+		hbc,
+		kcc,
+		ldc,
+		hc,
+		ehc,
+		bic,
+		lkc,
+		bla,
+		cjc,
+		asc,
+		wsc,
+		sdc,
+		wd,
+		sched,
 	}
 }
@@ -1,12 +1,18 @@
 package telemetry
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os/exec"
+	"path/filepath"
 	"time"
 
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/tracer"
@@ -15,22 +21,62 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
+// The most spans we keep on disk while the telemetry cmd is failing.
+const maxSpoolBytes = 10 * 1024 * 1024
+
+// The most spans we send to one run of the telemetry cmd.
+const maxBatchBytes = 1024 * 1024
+
+// How many runs of the telemetry cmd we do in one flush, to catch up after failures.
+const maxBatchesPerFlush = 5
+
+// How long the telemetry cmd can run before we kill it.
+const cmdTimeout = time.Minute
+
+// The longest we wait between retries when the telemetry cmd keeps failing.
+const maxBackoff = 15 * time.Minute
+
 type Controller struct {
 	spans      tracer.SpanSource
 	clock      build.Clock
+	dir        *dirs.WindmillDir
 	runCounter int
 	lastRunAt  time.Time
+	offline    model.OfflineMode
+
+	spool *spool
+
+	// After the telemetry cmd fails, we don't run it again until retryAt,
+	// backing off exponentially with each consecutive failure.
+	failures int
+	retryAt  time.Time
+
+	stats spoolStats
+}
+
+// What happened to the spans, over the life of the controller.
+type spoolStats struct {
+	sent    int
+	dropped int
 }
 
-func NewController(clock build.Clock, spans tracer.SpanSource) *Controller {
+func NewController(clock build.Clock, spans tracer.SpanSource, offline model.OfflineMode, dir *dirs.WindmillDir) *Controller {
 	return &Controller{
 		clock:      clock,
 		spans:      spans,
 		runCounter: 0,
+		offline:    offline,
+		dir:        dir,
 	}
 }
 
 func (t *Controller) OnChange(ctx context.Context, st store.RStore) {
+	// The telemetry cmd exists to ship spans somewhere else,
+	// so in offline mode we never run it.
+	if t.offline {
+		return
+	}
+
 	state := st.RLockState()
 	ts := state.TelemetrySettings
 	tc := ts.Cmd
@@ -52,25 +98,127 @@ func (t *Controller) OnChange(ctx context.Context, st store.RStore) {
 		t.lastRunAt = t.clock.Now()
 	}()
 
-	r, requeueFn, err := t.spans.GetOutgoingSpans()
+	sp, err := t.spoolFor(ts.Workdir)
 	if err != nil {
-		if err != io.EOF {
-			t.logError(st, fmt.Errorf("Error gathering Telemetry data for experimental_telemetry_cmd %v", err))
-		}
+		t.logError(st, fmt.Errorf("Error creating spool for experimental_telemetry_cmd: %v", err))
 		return
 	}
 
-	// run the command with the contents of the spans as jsonlines on stdin
-	cmd := exec.CommandContext(ctx, tc.Argv[0], tc.Argv[1:]...)
+	// Move the spans to disk first, so that they don't pile up in memory
+	// while the telemetry cmd is failing.
+	err = t.spoolOutgoingSpans(st, sp)
+	if err != nil {
+		t.logError(st, err)
+		return
+	}
+
+	if t.clock.Now().Before(t.retryAt) {
+		return
+	}
+
+	for i := 0; i < maxBatchesPerFlush; i++ {
+		paths, data, err := sp.next(maxBatchBytes)
+		if err != nil {
+			t.logError(st, fmt.Errorf("Error reading spool for experimental_telemetry_cmd: %v", err))
+			return
+		}
+		if len(paths) == 0 {
+			return
+		}
+
+		out, err := t.runCmd(ctx, ts, data)
+		if err != nil {
+			t.failures++
+			backoff := t.backoff(period)
+			t.retryAt = t.clock.Now().Add(backoff)
+			t.logError(st, fmt.Errorf("Telemetry command failed: %v\noutput: %s\nRetrying in %s", err, out, backoff))
+			return
+		}
+
+		t.failures = 0
+		t.retryAt = time.Time{}
+		t.stats.sent += countSpans(data)
+		err = sp.remove(paths)
+		if err != nil {
+			t.logError(st, fmt.Errorf("Error removing sent spans from spool: %v", err))
+			return
+		}
+	}
+}
+
+// Each Tiltfile gets its own spool, so that spans left over from one
+// project don't get sent with another project's telemetry cmd.
+func (t *Controller) spoolFor(workdir string) (*spool, error) {
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(workdir)))[:16]
+	dir := filepath.Join(t.dir.Root(), "telemetry-spool", key)
+	if t.spool != nil && t.spool.dir == dir {
+		return t.spool, nil
+	}
+
+	sp, err := newSpool(dir, maxSpoolBytes)
+	if err != nil {
+		return nil, err
+	}
+	t.spool = sp
+	return sp, nil
+}
+
+func (t *Controller) spoolOutgoingSpans(st store.RStore, sp *spool) error {
+	r, _, err := t.spans.GetOutgoingSpans()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("Error gathering Telemetry data for experimental_telemetry_cmd %v", err)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("Error gathering Telemetry data for experimental_telemetry_cmd %v", err)
+	}
+
+	dropped, err := sp.add(data, t.clock.Now())
+	if err != nil {
+		return fmt.Errorf("Error spooling Telemetry data for experimental_telemetry_cmd %v", err)
+	}
+	if dropped > 0 {
+		t.stats.dropped += dropped
+		t.logError(st, fmt.Errorf("Telemetry spool is full; dropped the %d oldest spans (%d dropped, %d sent since Tilt started)",
+			dropped, t.stats.dropped, t.stats.sent))
+	}
+	return nil
+}
+
+// Runs the command with the spans as jsonlines on stdin.
+func (t *Controller) runCmd(ctx context.Context, ts model.TelemetrySettings, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, cmdTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ts.Cmd.Argv[0], ts.Cmd.Argv[1:]...)
 	cmd.Dir = ts.Workdir
-	cmd.Stdin = r
+	cmd.Stdin = bytes.NewReader(data)
 
 	out, err := cmd.CombinedOutput()
-	if err != nil {
-		t.logError(st, fmt.Errorf("Telemetry command failed: %v\noutput: %s", err, out))
-		requeueFn()
-		return
+	if ctx.Err() == context.DeadlineExceeded {
+		return out, fmt.Errorf("timed out after %s", cmdTimeout)
+	}
+	return out, err
+}
+
+// Doubles the wait with each consecutive failure, up to maxBackoff.
+// We never retry more often than the period.
+func (t *Controller) backoff(period time.Duration) time.Duration {
+	d := period
+	for i := 1; i < t.failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	if d < period {
+		d = period
 	}
+	return d
 }
 
 func (t *Controller) logError(st store.RStore, err error) {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 
 	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
 
@@ -86,6 +87,18 @@ func TestTelScriptTimeIsUpShouldClearSpansAndSetTime(t *testing.T) {
 	f.assertNoSpans()
 }
 
+func TestTelOfflineNoInvocation(t *testing.T) {
+	f := newTCFixture(t)
+	defer f.teardown()
+
+	f.offline = true
+	f.workCmd()
+	f.run()
+
+	f.assertNoInvocation()
+	f.assertSpansPresent()
+}
+
 func TestTelScriptFailsTimeIsUpShouldDeleteFileAndSetTime(t *testing.T) {
 	f := newTCFixture(t)
 	defer f.teardown()
@@ -97,10 +110,55 @@ func TestTelScriptFailsTimeIsUpShouldDeleteFileAndSetTime(t *testing.T) {
 
 	f.assertInvocation()
 	f.assertLog("exit status 1")
-	f.assertSpansPresent()
+	f.assertSpooledSpans(1)
 	f.assertTelemetryScriptRanAtIs(t1)
 }
 
+func TestTelScriptFailsBacksOff(t *testing.T) {
+	f := newTCFixture(t)
+	defer f.teardown()
+
+	f.failCmd()
+	f.run()
+	f.assertLog("Retrying in 1m0s")
+
+	f.removeRanFile()
+	f.clock.now = f.clock.now.Add(61 * time.Second)
+	f.runAgain()
+	f.assertInvocation()
+	f.assertLog("Retrying in 2m0s")
+
+	// The period is up, but we're still backing off.
+	f.removeRanFile()
+	f.clock.now = f.clock.now.Add(61 * time.Second)
+	f.runAgain()
+	f.assertNoInvocation()
+	f.assertSpooledSpans(3)
+
+	f.clock.now = f.clock.now.Add(61 * time.Second)
+	f.runAgain()
+	f.assertInvocation()
+	f.assertLog("Retrying in 4m0s")
+}
+
+func TestTelSendsSpooledSpansAfterRecovery(t *testing.T) {
+	f := newTCFixture(t)
+	defer f.teardown()
+
+	f.failCmd()
+	f.run()
+	f.assertSpooledSpans(1)
+
+	f.workCmd()
+	f.clock.now = f.clock.now.Add(2 * model.DefaultTelemetryPeriod)
+	f.runAgain()
+
+	f.assertInvocation()
+	f.assertSpooledSpans(0)
+	assert.Equal(t, 2, strings.Count(f.cmdOutput(), "\n"))
+	assert.Equal(t, 2, f.controller.stats.sent)
+}
+
 type tcFixture struct {
 	t          *testing.T
 	ctx        context.Context
@@ -108,6 +166,7 @@ type tcFixture struct {
 	clock      fakeClock
 	st         *store.TestingStore
 	cmd        string
+	offline    model.OfflineMode
 	lastRun    time.Time
 	spans      []*exporttrace.SpanData
 	sc         *tracer.SpanCollector
@@ -176,6 +235,14 @@ func (tcf *tcFixture) setLastRun(t time.Time) {
 }
 
 func (tcf *tcFixture) run() {
+	tc := NewController(&tcf.clock, tcf.sc, tcf.offline, dirs.NewWindmillDirAt(tcf.temp.JoinPath("windmill")))
+	tc.lastRunAt = tcf.lastRun
+	tcf.controller = tc
+	tcf.runAgain()
+}
+
+// Sends the spans to the existing controller again, with the current cmd.
+func (tcf *tcFixture) runAgain() {
 	for _, sd := range tcf.spans {
 		tcf.sc.OnEnd(sd)
 	}
@@ -188,10 +255,22 @@ func (tcf *tcFixture) run() {
 		TelemetrySettings: ts,
 	})
 
-	tc := NewController(tcf.clock, tcf.sc)
-	tc.lastRunAt = tcf.lastRun
-	tcf.controller = tc
-	tc.OnChange(tcf.ctx, tcf.st)
+	tcf.controller.OnChange(tcf.ctx, tcf.st)
+}
+
+func (tcf *tcFixture) removeRanFile() {
+	err := os.Remove(tcf.temp.JoinPath("ran.txt"))
+	if err != nil {
+		tcf.t.Fatal(err)
+	}
+}
+
+func (tcf *tcFixture) assertSpooledSpans(expected int) {
+	_, data, err := tcf.controller.spool.next(maxSpoolBytes)
+	if err != nil {
+		tcf.t.Fatal(err)
+	}
+	assert.Equal(tcf.t, expected, countSpans(data))
 }
 
 func (tcf *tcFixture) assertNoLogs() {
@@ -221,13 +300,16 @@ func (tcf *tcFixture) assertTelemetryScriptRanAtIs(t time.Time) {
 	assert.Equal(tcf.t, t, tcf.controller.lastRunAt)
 }
 
-func (tcf *tcFixture) assertCmdOutput(expected string) {
+func (tcf *tcFixture) cmdOutput() string {
 	bs, err := ioutil.ReadFile(tcf.temp.JoinPath("scriptstdout"))
 	if err != nil {
 		tcf.t.Fatal(err)
 	}
+	return normalize(string(bs))
+}
 
-	assert.Equal(tcf.t, normalize(expected), normalize(string(bs)))
+func (tcf *tcFixture) assertCmdOutput(expected string) {
+	assert.Equal(tcf.t, normalize(expected), tcf.cmdOutput())
 }
 
 func normalize(s string) string {
@@ -260,4 +342,4 @@ type fakeClock struct {
 	now time.Time
 }
 
-func (c fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Now() time.Time { return c.now }
@@ -0,0 +1,141 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Spans waiting to be sent by the telemetry cmd, saved to disk.
+//
+// Each file is a batch of spans as jsonlines, named so that the oldest sorts first.
+// If the telemetry cmd keeps failing, the spool stays under maxBytes by
+// dropping the oldest batches, so a flaky endpoint can't fill up the disk.
+type spool struct {
+	dir      string
+	maxBytes int64
+	seq      int
+}
+
+func newSpool(dir string, maxBytes int64) (*spool, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &spool{dir: dir, maxBytes: maxBytes}, nil
+}
+
+type spoolFile struct {
+	path string
+	size int64
+}
+
+func (s *spool) files() ([]spoolFile, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []spoolFile
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".jsonl") {
+			continue
+		}
+		result = append(result, spoolFile{path: filepath.Join(s.dir, info.Name()), size: info.Size()})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].path < result[j].path })
+	return result, nil
+}
+
+// Adds a batch of spans to the spool.
+//
+// Returns the number of spans we dropped to stay under the size limit.
+func (s *spool) add(data []byte, now time.Time) (int, error) {
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d.jsonl", now.UnixNano(), s.seq)
+	err := ioutil.WriteFile(filepath.Join(s.dir, name), data, 0644)
+	if err != nil {
+		return 0, err
+	}
+	return s.trim()
+}
+
+// Deletes the oldest batches until the spool fits in maxBytes.
+func (s *spool) trim() (int, error) {
+	files, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+
+	total := int64(0)
+	for _, f := range files {
+		total += f.size
+	}
+
+	dropped := 0
+	for len(files) > 0 && total > s.maxBytes {
+		f := files[0]
+		files = files[1:]
+
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil && !os.IsNotExist(err) {
+			return dropped, err
+		}
+		err = os.Remove(f.path)
+		if err != nil && !os.IsNotExist(err) {
+			return dropped, err
+		}
+		dropped += countSpans(data)
+		total -= f.size
+	}
+	return dropped, nil
+}
+
+// The oldest batches, up to maxBytes of them (but always at least one batch).
+//
+// Returns the files, so that we can remove them once they've been sent,
+// and their contents.
+func (s *spool) next(maxBytes int64) ([]string, []byte, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	var buf bytes.Buffer
+	for _, f := range files {
+		if len(paths) > 0 && int64(buf.Len())+f.size > maxBytes {
+			break
+		}
+
+		data, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		paths = append(paths, f.path)
+		buf.Write(data)
+	}
+	return paths, buf.Bytes(), nil
+}
+
+func (s *spool) remove(paths []string) error {
+	for _, p := range paths {
+		err := os.Remove(p)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func countSpans(data []byte) int {
+	return bytes.Count(data, []byte("\n"))
+}
@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestSpoolBatchesOldestFirst(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	sp, err := newSpool(f.JoinPath("spool"), 1024)
+	require.NoError(t, err)
+
+	now := time.Unix(1551202573, 0)
+	_, err = sp.add([]byte("{\"a\":1}\n"), now)
+	require.NoError(t, err)
+	_, err = sp.add([]byte("{\"b\":2}\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	_, err = sp.add([]byte("{\"c\":3}\n"), now.Add(2*time.Second))
+	require.NoError(t, err)
+
+	paths, data, err := sp.next(16)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(paths))
+	assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n", string(data))
+
+	require.NoError(t, sp.remove(paths))
+	_, data, err = sp.next(16)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"c\":3}\n", string(data))
+}
+
+func TestSpoolDropsOldestWhenFull(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	sp, err := newSpool(f.JoinPath("spool"), 20)
+	require.NoError(t, err)
+
+	now := time.Unix(1551202573, 0)
+	dropped, err := sp.add([]byte("{\"a\":1}\n{\"a\":2}\n"), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	dropped, err = sp.add([]byte("{\"b\":3}\n"), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+
+	_, data, err := sp.next(maxBatchBytes)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"b\":3}\n", string(data))
+}
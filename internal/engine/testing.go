@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/synclet"
+)
+
+// The real build and deploy pipeline, backed by the given (usually fake)
+// clients. For integration tests outside this package; see pkg/testing.
+func ProvideBuildAndDeployerForTests(
+	ctx context.Context,
+	dCli docker.Client,
+	kCli k8s.Client,
+	dcc dockercompose.DockerComposeClient,
+	dir *dirs.WindmillDir,
+	env k8s.Env,
+	clock build.Clock,
+	ta *analytics.TiltAnalytics) (BuildAndDeployer, error) {
+	return provideBuildAndDeployer(ctx, dCli, kCli, dir, env, buildcontrol.UpdateModeFlag(buildcontrol.UpdateModeAuto),
+		synclet.NewTestSyncletClient(dCli), dcc, clock, NewKINDLoader(env, ""), NewCRIOLoader(env), ta)
+}
@@ -5,24 +5,35 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
 	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/exit"
 	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
@@ -32,6 +43,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/token"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 // TODO(nick): maybe this should be called 'BuildEngine' or something?
@@ -69,6 +81,7 @@ func (u Upper) Start(
 	analyticsUserOpt analytics.Opt,
 	token token.Token,
 	cloudAddress string,
+	rollbackOnFailure bool,
 ) error {
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Start")
@@ -94,6 +107,8 @@ func (u Upper) Start(
 		Token:            token,
 		CloudAddress:     cloudAddress,
 		TerminalMode:     initTerminalMode,
+
+		RollbackOnFailure: rollbackOnFailure,
 	})
 }
 
@@ -128,12 +143,36 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handleFSEvent(ctx, state, action)
 	case fswatch.GitBranchStatusAction:
 		handleGitBranchStatus(state, action)
+	case fswatch.GitCheckoutStartedAction:
+		handleGitCheckoutStarted(state, action)
+	case fswatch.GitCheckoutSettledAction:
+		handleGitCheckoutSettled(state, action)
 	case k8swatch.PodChangeAction:
 		handlePodChangeAction(ctx, state, action)
 	case k8swatch.PodDeleteAction:
 		handlePodDeleteAction(ctx, state, action)
 	case store.PodResetRestartsAction:
 		handlePodResetRestartsAction(state, action)
+	case store.TrafficCaptureAction:
+		handleTrafficCaptureAction(state, action)
+	case store.CronJobRunAction:
+		handleCronJobRunAction(state, action)
+	case portforward.ActivityAction:
+		handlePortForwardActivityAction(state, action)
+	case endpointhealth.CheckAction:
+		handleEndpointHealthCheckAction(state, action)
+	case baseimage.CheckAction:
+		handleBaseImageCheckAction(state, action)
+	case linkdiscovery.DiscoveredAction:
+		handleLinksDiscoveredAction(state, action)
+	case workloadstatus.StatusAction:
+		handleWorkloadStatusAction(state, action)
+	case seed.StatusAction:
+		handleSeedStatusAction(state, action)
+	case watchdog.HealthAction:
+		state.EngineHealth = action.Health
+	case buildlogs.BuildLogArchivedAction:
+		handleBuildLogArchived(state, action)
 	case k8swatch.ServiceChangeAction:
 		handleServiceEvent(ctx, state, action)
 	case store.K8sEventAction:
@@ -142,6 +181,8 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handleBuildCompleted(ctx, state, action)
 	case buildcontrol.BuildStartedAction:
 		handleBuildStarted(ctx, state, action)
+	case buildcontrol.CredentialsDetectedAction:
+		handleCredentialsDetected(state, action)
 	case configs.ConfigsReloadStartedAction:
 		handleConfigsReloadStarted(ctx, state, action)
 	case configs.ConfigsReloadedAction:
@@ -164,10 +205,22 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handleTiltCloudStatusReceivedAction(state, action)
 	case store.UserStartedTiltCloudRegistrationAction:
 		handleUserStartedTiltCloudRegistrationAction(state)
+	case k8scredentials.RejectedAction:
+		handleK8sCredentialsRejectedAction(state, action)
 	case store.PanicAction:
 		handlePanicAction(state, action)
+	case store.AlertAction:
+		state.Alerts.Upsert(action.Alert, time.Now())
+	case store.AlertResolvedAction:
+		state.Alerts.Resolve(action.ID)
+	case store.AlertsAcknowledgedAction:
+		state.Alerts.Acknowledge(action.IDs)
+	case store.ClearBuildQueueAction:
+		handleClearBuildQueue(state)
 	case server.SetTiltfileArgsAction:
 		handleSetTiltfileArgsAction(state, action)
+	case server.SetEnvAction:
+		handleSetEnvAction(state, action)
 	case local.LocalServeStatusAction:
 		handleLocalServeStatusAction(ctx, state, action)
 	case store.LogAction:
@@ -206,6 +259,7 @@ func handleBuildStarted(ctx context.Context, state *store.EngineState, action bu
 	}
 	ms.ConfigFilesThatCausedChange = []string{}
 	ms.CurrentBuild = bs
+	ms.PendingGitCheckout = false
 
 	if ms.IsK8s() {
 		for _, pod := range ms.K8sRuntimeState().Pods {
@@ -236,8 +290,31 @@ func handleBuildResults(engineState *store.EngineState,
 
 	ms := mt.State
 	mn := mt.Manifest.Name
+	ownIDs := mt.Manifest.TargetIDSet()
 	for id, result := range results {
-		ms.MutableBuildStatus(id).LastResult = result
+		// A custom_build can build images for other manifests too.
+		// We pass those along below.
+		if !ownIDs[id] {
+			continue
+		}
+		status := ms.MutableBuildStatus(id)
+		status.LastResult = result
+		status.NeedsRebuild = false
+
+		if kResult, ok := result.(store.K8sBuildResult); ok && kResult.AppliedEntitiesHash != "" {
+			ms.LastAppliedK8sResult = kResult
+		}
+	}
+
+	// If one of the manifest's images failed, nothing was deployed. The images
+	// that didn't get built need a build next time, even if nothing changes.
+	var imageErr buildcontrol.ImageBuildError
+	if errors.As(br.Error, &imageErr) {
+		for _, id := range imageErr.Unbuilt {
+			if ownIDs[id] {
+				ms.MutableBuildStatus(id).NeedsRebuild = true
+			}
+		}
 	}
 
 	// Remove pending file changes that were consumed by this build.
@@ -247,6 +324,23 @@ func handleBuildResults(engineState *store.EngineState,
 
 	if isBuildSuccess {
 		ms.LastSuccessfulDeployTime = br.FinishTime
+
+		// The image build pulled the new base images.
+		for id, result := range results {
+			if _, ok := result.(store.ImageBuildResult); ok {
+				delete(ms.StaleBaseImages, id)
+			}
+		}
+	} else {
+		// A failed local command always needs to run again, even if
+		// its deps haven't changed since the last successful run.
+		for _, status := range ms.BuildStatuses {
+			lr, ok := status.LastResult.(store.LocalBuildResult)
+			if ok && lr.DepsHash != "" {
+				lr.DepsHash = ""
+				status.LastResult = lr
+			}
+		}
 	}
 
 	// Update build statuses for duplicated image targets in other manifests.
@@ -322,6 +416,23 @@ func handleBuildResults(engineState *store.EngineState,
 	}
 }
 
+func handleBaseImageCheckAction(state *store.EngineState, action baseimage.CheckAction) {
+	for _, mt := range state.Targets() {
+		ms := mt.State
+		ms.StaleBaseImages = nil
+		for _, iTarget := range mt.Manifest.ImageTargets {
+			stale := action.Stale[iTarget.ID()]
+			if len(stale) == 0 {
+				continue
+			}
+			if ms.StaleBaseImages == nil {
+				ms.StaleBaseImages = make(map[model.TargetID][]string)
+			}
+			ms.StaleBaseImages[iTarget.ID()] = stale
+		}
+	}
+}
+
 func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, cb buildcontrol.BuildCompleteAction) {
 	defer func() {
 		delete(engineState.CurrentlyBuilding, cb.ManifestName)
@@ -337,19 +448,26 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 	err := cb.Error
 	if err != nil {
 		s := fmt.Sprintf("Build Failed: %v", err)
+		if hint := model.ErrorRemediation(err); hint != "" {
+			s = fmt.Sprintf("%s\n%s", s, hint)
+		}
 		handleLogAction(engineState, store.NewLogAction(mt.Manifest.Name, cb.SpanID, logger.ErrorLvl, nil, []byte(s)))
 	}
 
 	ms := mt.State
 	bs := ms.CurrentBuild
 	bs.Error = err
+	bs.ErrorCategory = model.ErrorCategoryOf(err)
 	bs.FinishTime = cb.FinishTime
 	bs.BuildTypes = cb.Result.BuildTypes()
+	bs.DepsHash = cb.Result.DepsHash()
+	bs.DeploySkipped = cb.Result.DeploySkipped()
+	bs.ImageLayers = cb.Result.ImageLayerAnalyses()
 	if bs.SpanID != "" {
 		bs.WarningCount = len(engineState.LogStore.Warnings(bs.SpanID))
 	}
 
-	ms.AddCompletedBuild(bs)
+	ms.AddCompletedBuildWithLimit(bs, engineState.UpdateSettings.BuildHistoryLimit())
 
 	ms.CurrentBuild = model.BuildRecord{}
 	ms.NeedsRebuildFromCrash = false
@@ -398,6 +516,7 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 		deployedUIDSet := cb.Result.DeployedUIDSet()
 		if len(deployedUIDSet) > 0 {
 			state.DeployedUIDSet = deployedUIDSet
+			state.PartitionedStatefulSetUIDSet = cb.Result.PartitionedStatefulSetUIDSet()
 		}
 
 		deployedPodTemplateSpecHashSet := cb.Result.DeployedPodTemplateSpecHashes()
@@ -407,6 +526,13 @@ func handleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 
 		if err == nil {
 			state.HasEverDeployedSuccessfully = true
+
+			// The Tiltfile may have changed the namespaces that the resource fans out to.
+			state.Namespaces = manifest.K8sTarget().Namespaces
+
+			// The workloads' statuses are for the old spec until the
+			// workloadstatus controller checks them again.
+			state.WorkloadStatuses = nil
 		}
 
 		ms.RuntimeState = state
@@ -484,6 +610,21 @@ func removeFromTriggerQueue(state *store.EngineState, mn model.ManifestName) {
 	}
 }
 
+func handleClearBuildQueue(state *store.EngineState) {
+	for _, mt := range state.Targets() {
+		if mt.State.IsBuilding() {
+			continue
+		}
+
+		removeFromTriggerQueue(state, mt.Manifest.Name)
+		mt.State.PendingGitCheckout = false
+		for _, status := range mt.State.BuildStatuses {
+			status.PendingFileChanges = make(map[string]time.Time)
+			status.PendingDependencyChanges = make(map[model.TargetID]time.Time)
+		}
+	}
+}
+
 func handleStopProfilingAction(state *store.EngineState) {
 	state.IsProfiling = false
 }
@@ -497,6 +638,9 @@ func handleFSEvent(
 	state *store.EngineState,
 	event fswatch.TargetFilesChangedAction) {
 
+	now := time.Now()
+	state.LastFileEventLatency = store.FileEventLatency{HandledAt: now, Delay: now.Sub(event.Time)}
+
 	if event.TargetID.Type == model.TargetTypeConfigs {
 		for _, f := range event.Files {
 			state.PendingConfigFileChanges[f] = event.Time
@@ -522,6 +666,47 @@ func handleGitBranchStatus(state *store.EngineState, bsa fswatch.GitBranchStatus
 	// TODO(nick): Do something with this data.
 }
 
+func handleGitCheckoutStarted(state *store.EngineState, action fswatch.GitCheckoutStartedAction) {
+	state.GitCheckouts[action.Repo.LocalPath] = store.GitCheckout{
+		Repo:      action.Repo,
+		StartTime: action.Time,
+	}
+}
+
+// Everything in the repo that changed while we held its builds
+// gets rebuilt once, with the checkout as the reason.
+func handleGitCheckoutSettled(state *store.EngineState, action fswatch.GitCheckoutSettledAction) {
+	delete(state.GitCheckouts, action.Repo.LocalPath)
+
+	for _, mt := range state.Targets() {
+		if !mt.State.HasPendingFileChanges() {
+			continue
+		}
+		for _, repo := range store.ManifestGitRepos(mt.Manifest) {
+			if repo == action.Repo {
+				mt.State.PendingGitCheckout = true
+				break
+			}
+		}
+	}
+}
+
+func handleBuildLogArchived(state *store.EngineState, action buildlogs.BuildLogArchivedAction) {
+	ms, ok := state.ManifestState(action.ManifestName)
+	if action.ManifestName == model.TiltfileManifestName {
+		ms, ok = &state.TiltfileState, true
+	}
+	if !ok {
+		return
+	}
+
+	for i, br := range ms.BuildHistory {
+		if br.SpanID == action.SpanID {
+			ms.BuildHistory[i].LogPath = action.Path
+		}
+	}
+}
+
 func handleConfigsReloadStarted(
 	ctx context.Context,
 	state *store.EngineState,
@@ -575,6 +760,7 @@ func handleConfigsReloaded(
 	// Add team id if it exists, even if execution failed.
 	if event.TeamID != "" || event.Err == nil {
 		state.TeamID = event.TeamID
+		state.AdditionalTeamIDs = event.AdditionalTeamIDs
 	}
 
 	// Add metrics if it exists, even if execution failed.
@@ -582,16 +768,22 @@ func handleConfigsReloaded(
 		state.MetricsSettings = event.MetricsSettings
 	}
 
+	// Keep the profile even if execution failed, to help debug slow failures.
+	if !event.TiltfileProfile.Empty() {
+		state.TiltfileProfile = event.TiltfileProfile
+	}
+
 	// if the ConfigsReloadedAction came from a unit test, there might not be a current build
 	if !b.Empty() {
 		b.FinishTime = event.FinishTime
 		b.Error = event.Err
+		b.ErrorCategory = model.ErrorCategoryOf(event.Err)
 
 		if b.SpanID != "" {
 			b.WarningCount = len(state.LogStore.Warnings(b.SpanID))
 		}
 
-		state.TiltfileState.AddCompletedBuild(b)
+		state.TiltfileState.AddCompletedBuildWithLimit(b, state.UpdateSettings.BuildHistoryLimit())
 	}
 	state.TiltfileState.CurrentBuild = model.BuildRecord{}
 	if event.Err != nil {
@@ -608,6 +800,14 @@ func handleConfigsReloaded(
 		// Watch any new config files in the partial state.
 		state.ConfigFiles = sliceutils.AppendWithoutDupes(state.ConfigFiles, event.ConfigFiles...)
 
+		// Likewise for environment variables.
+		if state.TiltfileEnv == nil {
+			state.TiltfileEnv = make(model.TiltfileEnv)
+		}
+		for name, read := range event.TiltfileEnv {
+			state.TiltfileEnv[name] = read
+		}
+
 		// Enable any new features in the partial state.
 		if len(state.Features) == 0 {
 			state.Features = event.Features
@@ -632,6 +832,12 @@ func handleConfigsReloaded(
 
 		newDefOrder[i] = m.ManifestName()
 
+		if m.ConfigError != "" && ok {
+			// Keep the resource's last good config, so that we keep
+			// watching whatever it deployed.
+			m = mt.Manifest.WithConfigError(m.ConfigError)
+		}
+
 		configFilesThatChanged := state.TiltfileState.LastBuild().Edits
 		old := mt.Manifest
 		mt.Manifest = m
@@ -650,13 +856,22 @@ func handleConfigsReloaded(
 	// TODO(maia): update ConfigsManifest with new ConfigFiles/update watches
 	state.ManifestDefinitionOrder = newDefOrder
 	state.ConfigFiles = event.ConfigFiles
+	state.TiltfileEnv = event.TiltfileEnv
 
 	state.Features = event.Features
 	state.TelemetrySettings = event.TelemetrySettings
 	state.VersionSettings = event.VersionSettings
 	state.AnalyticsTiltfileOpt = event.AnalyticsTiltfileOpt
+	state.AnalyticsTiltfileConsent = event.AnalyticsConsent
+	state.Alerts.ReplaceSources(tiltfileAlertSources, event.Alerts, event.FinishTime)
 
 	state.UpdateSettings = event.UpdateSettings
+	state.LocalDNSSettings = event.LocalDNSSettings
+	state.HibernateSettings = event.HibernateSettings
+	state.EndpointHealthSettings = event.EndpointHealth
+	state.BaseImageCheckSettings = event.BaseImageChecks
+	state.LogSettings = event.LogSettings
+	state.SecretSettings = event.SecretSettings
 
 	// Remove pending file changes that were consumed by this build.
 	for file, modTime := range state.PendingConfigFileChanges {
@@ -666,8 +881,16 @@ func handleConfigsReloaded(
 	}
 }
 
+// The alert sources that the Tiltfile reports all at once, on every load.
+var tiltfileAlertSources = []model.AlertSource{
+	model.AlertSourceImageInjection,
+	model.AlertSourceDeprecatedAPI,
+}
+
 func handleLogAction(state *store.EngineState, action store.LogAction) {
-	state.LogStore.Append(action, state.Secrets)
+	state.LogStore.AppendWithOptions(action, state.Secrets, logstore.AppendOptions{
+		FormatJSON: state.LogSettings.JSON,
+	})
 }
 
 func handleExitAction(state *store.EngineState, action exit.Action) {
@@ -678,7 +901,7 @@ func handleExitAction(state *store.EngineState, action exit.Action) {
 }
 
 func handleSwitchTerminalModeAction(state *store.EngineState, action prompt.SwitchTerminalModeAction) {
-	state.TerminalMode = action.Mode
+	state.TerminalMode = store.TerminalModeForOS(action.Mode, runtime.GOOS)
 }
 
 func handleServiceEvent(ctx context.Context, state *store.EngineState, action k8swatch.ServiceChangeAction) {
@@ -731,6 +954,7 @@ func handleInitAction(ctx context.Context, engineState *store.EngineState, actio
 	engineState.CloudAddress = action.CloudAddress
 	engineState.Token = action.Token
 	engineState.TerminalMode = action.TerminalMode
+	engineState.RollbackOnFailure = action.RollbackOnFailure
 }
 
 func handleHudExitAction(state *store.EngineState, action hud.ExitAction) {
@@ -749,6 +973,12 @@ func handleSetTiltfileArgsAction(state *store.EngineState, action server.SetTilt
 	state.UserConfigState = state.UserConfigState.WithArgs(action.Args)
 }
 
+func handleSetEnvAction(state *store.EngineState, action server.SetEnvAction) {
+	if state.TiltfileEnv.Changes(action.Name, action.Set, action.Digest) {
+		state.TiltfileEnvChangeTime = time.Now()
+	}
+}
+
 func handleLocalServeStatusAction(ctx context.Context, state *store.EngineState, action local.LocalServeStatusAction) {
 	ms, ok := state.ManifestState(action.ManifestName)
 	if !ok {
@@ -802,10 +1032,39 @@ func handleAnalyticsNudgeSurfacedAction(ctx context.Context, state *store.Engine
 	}
 }
 
+func handleCredentialsDetected(state *store.EngineState, action buildcontrol.CredentialsDetectedAction) {
+	newSecrets := model.SecretSet{}
+	for k, v := range action.Secrets {
+		if _, exists := state.Secrets[k]; !exists {
+			newSecrets[k] = v
+		}
+	}
+
+	state.Secrets.AddAll(action.Secrets)
+	state.LogStore.ScrubSecretsStartingAt(newSecrets, action.Checkpoint)
+}
+
+func handleK8sCredentialsRejectedAction(state *store.EngineState, action k8scredentials.RejectedAction) {
+	state.K8sCredentials = store.K8sCredentialsStatus{
+		Rejected:      action.Rejected,
+		ReauthCommand: action.ReauthCommand,
+	}
+}
+
 func handleTiltCloudStatusReceivedAction(state *store.EngineState, action store.TiltCloudStatusReceivedAction) {
 	if action.IsPostRegistrationLookup {
 		state.CloudStatus.WaitingForStatusPostRegistration = false
 	}
+	if action.TokenExpired {
+		state.CloudStatus.TokenExpired = true
+		state.CloudStatus.TokenKnownUnregistered = true
+		state.CloudStatus.Username = ""
+		state.CloudStatus.Teams = nil
+		return
+	}
+	state.CloudStatus.TokenExpired = false
+	state.CloudStatus.Teams = action.Teams
+
 	if !action.Found {
 		state.CloudStatus.TokenKnownUnregistered = true
 		state.CloudStatus.Username = ""
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/cloud"
@@ -37,19 +38,33 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/autoscale"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/cronjob"
 	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/engine/exit"
 	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/hibernate"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
+	"github.com/tilt-dev/tilt/internal/engine/k8sheartbeat"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
 	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/localdns"
 	"github.com/tilt-dev/tilt/internal/engine/metrics"
 	"github.com/tilt-dev/tilt/internal/engine/portforward"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
@@ -57,6 +72,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/synclet"
 	"github.com/tilt-dev/tilt/internal/testutils"
@@ -726,9 +742,10 @@ func TestThreeBuilds(t *testing.T) {
 	f.podEvent(podbuilder.New(f.T(), manifest).Build(), manifest.Name)
 
 	f.withManifestState("fe", func(ms store.ManifestState) {
-		assert.Equal(t, 2, len(ms.BuildHistory))
+		assert.Equal(t, 3, len(ms.BuildHistory))
 		assert.Equal(t, []string{f.JoinPath("b.go")}, ms.BuildHistory[0].Edits)
 		assert.Equal(t, []string{f.JoinPath("a.go")}, ms.BuildHistory[1].Edits)
+		assert.Empty(t, ms.BuildHistory[2].Edits)
 	})
 
 	err := f.Stop()
@@ -2757,7 +2774,7 @@ func TestEmptyTiltfile(t *testing.T) {
 		err := f.upper.Start(f.ctx, []string{}, model.TiltBuild{}, store.EngineModeUp,
 			f.JoinPath("Tiltfile"), store.TerminalModeHUD,
 			analytics.OptIn, token.Token("unit test token"),
-			"nonexistent.example.com")
+			"nonexistent.example.com", false)
 		closeCh <- err
 	}()
 	f.WaitUntil("build is set", func(st store.EngineState) bool {
@@ -2791,7 +2808,7 @@ func TestUpperStart(t *testing.T) {
 	go func() {
 		err := f.upper.Start(f.ctx, []string{"foo", "bar"}, model.TiltBuild{},
 			store.EngineModeUp, f.JoinPath("Tiltfile"), store.TerminalModeHUD,
-			analytics.OptIn, tok, cloudAddress)
+			analytics.OptIn, tok, cloudAddress, false)
 		closeCh <- err
 	}()
 	f.WaitUntil("init action processed", func(state store.EngineState) bool {
@@ -2957,6 +2974,41 @@ func TestFeatureFlagsStoredOnState(t *testing.T) {
 	})
 }
 
+func TestConfigErrorKeepsLastGoodConfig(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+	f.bc.DisableForTesting()
+
+	m := manifestbuilder.New(f, "fe").WithLocalResource("echo hi", nil).Build()
+	f.Start([]model.Manifest{m})
+
+	f.store.Dispatch(configs.ConfigsReloadedAction{
+		FinishTime: f.Now(),
+		Manifests: []model.Manifest{
+			model.Manifest{Name: "fe"}.
+				WithDeployTarget(model.LocalTarget{Name: "fe"}).
+				WithConfigError("resource fe specified a dependency on unknown resource be"),
+		},
+	})
+
+	f.WaitUntilManifest("config error is set", "fe", func(mt store.ManifestTarget) bool {
+		return mt.Manifest.ConfigError != ""
+	})
+
+	state := f.store.RLockState()
+	assert.Equal(t, m.LocalTarget(), state.ManifestTargets["fe"].Manifest.LocalTarget())
+	f.store.RUnlockState()
+
+	f.store.Dispatch(configs.ConfigsReloadedAction{
+		FinishTime: f.Now(),
+		Manifests:  []model.Manifest{m},
+	})
+
+	f.WaitUntilManifest("config error is cleared", "fe", func(mt store.ManifestTarget) bool {
+		return mt.Manifest.ConfigError == ""
+	})
+}
+
 func TestTeamIDStoredOnState(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -3035,6 +3087,25 @@ func TestBuildErrorLoggedOnceByUpper(t *testing.T) {
 	})
 }
 
+func TestBuildErrorCategoryRecorded(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	manifest := f.newManifest("fe")
+	f.b.nextBuildError = model.RegistryAuthError(errors.New("unauthorized"))
+
+	f.Start([]model.Manifest{manifest})
+
+	f.waitForCompletedBuildCount(1)
+
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		assert.Equal(t, model.ErrorCategoryRegistryAuth, ms.LastBuild().ErrorCategory)
+	})
+	f.withState(func(state store.EngineState) {
+		assert.Contains(t, state.LogStore.String(), "docker login")
+	})
+}
+
 func TestTiltfileChangedFilesOnlyLoggedAfterFirstBuild(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -3403,6 +3474,42 @@ print('foo=', cfg['foo'])`)
 	})
 }
 
+func TestEnvChangeCausesTiltfileRerun(t *testing.T) {
+	f := newTestFixture(t)
+	defer f.TearDown()
+
+	os.Setenv("TILT_TEST_GREETING", "hello")
+	defer os.Unsetenv("TILT_TEST_GREETING")
+
+	f.WriteFile("Tiltfile", `
+print('greeting=', os.getenv('TILT_TEST_GREETING'))`)
+
+	f.loadAndStart()
+
+	f.WaitUntil("first tiltfile build finishes", func(state store.EngineState) bool {
+		return len(state.TiltfileState.BuildHistory) == 1
+	})
+
+	// Variables the Tiltfile didn't read don't trigger a reload.
+	f.store.Dispatch(server.SetEnvAction{Name: "TILT_TEST_UNREAD", Set: true, Digest: model.EnvValueDigest("x")})
+	f.withState(func(state store.EngineState) {
+		assert.True(t, state.TiltfileEnvChangeTime.IsZero())
+	})
+
+	os.Setenv("TILT_TEST_GREETING", "goodbye")
+	f.store.Dispatch(server.SetEnvAction{Name: "TILT_TEST_GREETING", Set: true, Digest: model.EnvValueDigest("goodbye")})
+
+	f.WaitUntil("second tiltfile build finishes", func(state store.EngineState) bool {
+		return len(state.TiltfileState.BuildHistory) == 2
+	})
+
+	f.withState(func(state store.EngineState) {
+		spanID := state.TiltfileState.LastBuild().SpanID
+		require.Contains(t, state.LogStore.SpanLog(spanID), `greeting= goodbye`)
+		assert.True(t, state.TiltfileState.LastBuild().Reason.Has(model.BuildReasonFlagTiltfileEnv))
+	})
+}
+
 func TestTelemetryLogAction(t *testing.T) {
 	f := newTestFixture(t)
 	defer f.TearDown()
@@ -3608,7 +3715,6 @@ func newTestFixture(t *testing.T) *testFixture {
 	st.AddSubscriber(ctx, fSub)
 
 	plm := runtimelog.NewPodLogManager(kCli)
-	bc := NewBuildController(b)
 
 	err := os.Mkdir(f.JoinPath(".git"), os.FileMode(0777))
 	if err != nil {
@@ -3616,12 +3722,14 @@ func newTestFixture(t *testing.T) *testFixture {
 	}
 
 	clock := clockwork.NewRealClock()
+	bc := NewBuildController(b, clock)
 	env := k8s.EnvDockerDesktop
-	fwm := fswatch.NewWatchManager(watcher.NewSub, timerMaker.Maker())
-	gm := fswatch.NewGitManager(watcher.NewSub)
-	pfc := portforward.NewController(kCli)
+	fwm := fswatch.NewWatchManager(watcher.NewSub, timerMaker.Maker(), clock)
+	gm := fswatch.NewGitManager(watcher.NewSub, timerMaker.Maker(), clock)
+	pfc := portforward.NewController(kCli, ns, listeners.NewRegistry())
 	au := engineanalytics.NewAnalyticsUpdater(ta, engineanalytics.CmdTags{})
-	ar := engineanalytics.ProvideAnalyticsReporter(ta, st, kCli, env)
+	sched := scheduler.NewScheduler(clock)
+	ar := engineanalytics.ProvideAnalyticsReporter(ta, st, kCli, env, sched)
 	fakeDcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
 	k8sContextExt := k8scontext.NewExtension("fake-context", env)
 	versionExt := version.NewExtension(model.TiltBuild{Version: "0.5.0"})
@@ -3635,17 +3743,18 @@ func newTestFixture(t *testing.T) *testFixture {
 	sGRPCCli, err := synclet.FakeGRPCWrapper(ctx, sCli)
 	assert.NoError(t, err)
 	sm := containerupdate.NewSyncletManagerForTests(kCli, sGRPCCli, sCli)
-	hudsc := server.ProvideHeadsUpServerController("localhost", 0, &server.HeadsUpServer{}, assets.NewFakeServer(), model.WebURL{})
+	hudsc := server.ProvideHeadsUpServerController("localhost", 0, nil, &server.HeadsUpServer{}, assets.NewFakeServer(), model.WebURL{}, listeners.NewRegistry())
 	ewm := k8swatch.NewEventWatchManager(kCli, of, ns)
 	tcum := cloud.NewStatusManager(httptest.NewFakeClientEmptyJSON(), clock)
 	fe := local.NewFakeExecer()
 	lc := local.NewController(fe)
 	ts := hud.NewTerminalStream(hud.NewIncrementalPrinter(log), st)
+	js := hud.NewJSONStream(log, st)
 	tp := prompt.NewTerminalPrompt(ta, prompt.TTYOpen, prompt.BrowserOpen,
-		log, "localhost", model.WebURL{})
+		log, "localhost", model.WebURL{}, prompt.DefaultKeyBindings())
 	h := hud.NewFakeHud()
 
-	dp := dockerprune.NewDockerPruner(dockerClient)
+	dp := dockerprune.NewDockerPruner(dockerClient, clock)
 	dp.DisabledForTesting(true)
 
 	ret := &testFixture{
@@ -3677,14 +3786,29 @@ func newTestFixture(t *testing.T) *testFixture {
 
 	ret.disableEnvAnalyticsOpt()
 
-	tc := telemetry.NewController(clock, tracer.NewSpanCollector(ctx))
+	tc := telemetry.NewController(clock, tracer.NewSpanCollector(ctx), model.OfflineMode(false), dirs.NewWindmillDirAt(f.JoinPath(".windmill")))
 	podm := k8srollout.NewPodMonitor()
 	ec := exit.NewController()
 
 	de := metrics.NewDeferredExporter()
 	mc := metrics.NewController(de, model.TiltBuild{}, "")
 
-	subs := ProvideSubscribers(h, ts, tp, pw, sw, plm, pfc, fwm, gm, bc, cc, dcw, dclm, pm, sm, ar, hudsc, au, ewm, tcum, dp, tc, lc, podm, ec, mc)
+	hbc := k8sheartbeat.NewController(kCli, sched, clock)
+	kcc := k8scredentials.NewController(nil, st, sched)
+	ldc := localdns.NewController(localdns.ProvideListenPacket())
+	hc := hibernate.NewController(kCli, sched, clock, listeners.NewRegistry())
+	ehc := endpointhealth.NewController(sched, clock)
+	bic := baseimage.NewController(sched, dockerClient)
+	lkc := linkdiscovery.NewController()
+	bla := buildlogs.NewArchiver()
+	cjc := cronjob.NewController(kCli, clock)
+	asc := autoscale.NewController(kCli)
+	wsc := workloadstatus.NewController(kCli, sched)
+	sdc := seed.NewController(kCli, clock)
+	wd := watchdog.NewWatchdog(st, &server.HeadsUpServer{}, sched, clock)
+	dg := dockerprune.NewDiskGovernor(dockerClient, dp, sched, clock)
+	flc := fswatch.NewLimitsChecker()
+	subs := ProvideSubscribers(h, ts, js, tp, pw, sw, plm, pfc, fwm, gm, flc, bc, cc, dcw, dclm, pm, sm, ar, hudsc, au, ewm, tcum, dp, dg, tc, lc, podm, ec, mc, hbc, kcc, ldc, hc, ehc, bic, lkc, bla, cjc, asc, wsc, sdc, wd, sched)
 	ret.upper = NewUpper(ctx, st, subs)
 
 	go func() {
@@ -0,0 +1,12 @@
+package watchdog
+
+import (
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+// The engine's health got worse or better.
+type HealthAction struct {
+	Health store.EngineHealth
+}
+
+func (HealthAction) Action() {}
@@ -0,0 +1,170 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How often we check on the engine.
+const checkInterval = 10 * time.Second
+
+// Past these, the engine isn't keeping up.
+const (
+	actionQueueThreshold = 1000
+
+	// Some subscribers do slow work inline (e.g., the Tiltfile loader),
+	// so be generous.
+	subscriberLagThreshold = time.Minute
+
+	// The web server stops sending views to a client this far behind.
+	websocketBacklogThreshold = 3
+
+	fileEventDelayThreshold = 5 * time.Second
+)
+
+// A slow file change stops counting against the engine after this long.
+const fileEventDelayMemory = time.Minute
+
+// The parts of the store that we measure.
+type engineStore interface {
+	Dispatch(action store.Action)
+	ActionQueueDepth() int
+	BusySubscribers() []store.BusySubscriber
+}
+
+type WebsocketBacklogger interface {
+	WebsocketBacklog() int
+}
+
+// Watches Tilt's own goroutines for signs that the engine is falling behind
+// or hung, and reports it as the health of the "(Tilt)" resource.
+//
+// The checks run on the scheduler, not in OnChange, so that they still run
+// when the store is stuck.
+type Watchdog struct {
+	st    engineStore
+	ws    WebsocketBacklogger
+	sched *scheduler.Scheduler
+	clock build.Clock
+
+	mu               sync.Mutex
+	fileEventLatency store.FileEventLatency
+
+	// What was wrong at the last check (e.g., "subscriber-lag"), so that we
+	// only log when it changes.
+	lastProblemKinds []string
+}
+
+var _ store.SetUpper = &Watchdog{}
+var _ store.Subscriber = &Watchdog{}
+
+func NewWatchdog(st *store.Store, ws WebsocketBacklogger, sched *scheduler.Scheduler, clock build.Clock) *Watchdog {
+	return newWatchdog(st, ws, sched, clock)
+}
+
+func newWatchdog(st engineStore, ws WebsocketBacklogger, sched *scheduler.Scheduler, clock build.Clock) *Watchdog {
+	return &Watchdog{
+		st:    st,
+		ws:    ws,
+		sched: sched,
+		clock: clock,
+	}
+}
+
+func (w *Watchdog) SetUp(ctx context.Context) {
+	w.sched.Every(ctx, "watchdog", checkInterval, checkInterval, w.check)
+}
+
+// Copies what we need out of the state, so that the check never waits on the state lock.
+func (w *Watchdog) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	latency := state.LastFileEventLatency
+	st.RUnlockState()
+
+	w.mu.Lock()
+	w.fileEventLatency = latency
+	w.mu.Unlock()
+}
+
+// While the engine is degraded, we report every check, so that the UI
+// shows the latest measurements. Otherwise, only report when it recovers.
+func (w *Watchdog) check(ctx context.Context) {
+	health, kinds := w.measure()
+
+	w.mu.Lock()
+	changed := !reflect.DeepEqual(kinds, w.lastProblemKinds)
+	w.lastProblemKinds = kinds
+	w.mu.Unlock()
+
+	if changed {
+		if health.Degraded() {
+			logger.Get(ctx).Warnf("Tilt is falling behind: %s\nRun `tilt dump goroutines` to see what it's doing.",
+				strings.Join(health.Problems, "; "))
+		} else {
+			logger.Get(ctx).Infof("Tilt has caught up")
+		}
+	}
+
+	if changed || health.Degraded() {
+		w.st.Dispatch(HealthAction{Health: health})
+	}
+}
+
+// Returns the engine's health, and the kinds of problems it has.
+func (w *Watchdog) measure() (store.EngineHealth, []string) {
+	now := w.clock.Now()
+	health := store.EngineHealth{
+		CheckTime:        now,
+		ActionQueueDepth: w.st.ActionQueueDepth(),
+		WebsocketBacklog: w.ws.WebsocketBacklog(),
+	}
+
+	// The watchdog isn't busy with anything slow, so it's never the culprit.
+	for _, b := range w.st.BusySubscribers() {
+		if b.Name == fmt.Sprintf("%T", w) {
+			continue
+		}
+		health.SlowestSubscriber = b.Name
+		health.SlowestSubscriberLag = now.Sub(b.Since)
+		break
+	}
+
+	w.mu.Lock()
+	if now.Sub(w.fileEventLatency.HandledAt) < fileEventDelayMemory {
+		health.FileEventDelay = w.fileEventLatency.Delay
+	}
+	w.mu.Unlock()
+
+	var kinds []string
+	if health.ActionQueueDepth >= actionQueueThreshold {
+		kinds = append(kinds, "action-queue")
+		health.Problems = append(health.Problems,
+			fmt.Sprintf("%d actions are waiting to be processed", health.ActionQueueDepth))
+	}
+	if health.SlowestSubscriberLag >= subscriberLagThreshold {
+		kinds = append(kinds, "subscriber-lag")
+		health.Problems = append(health.Problems,
+			fmt.Sprintf("%s has been handling a change for %s", health.SlowestSubscriber,
+				health.SlowestSubscriberLag.Truncate(time.Second)))
+	}
+	if health.WebsocketBacklog >= websocketBacklogThreshold {
+		kinds = append(kinds, "websocket-backlog")
+		health.Problems = append(health.Problems,
+			fmt.Sprintf("a web UI is %d updates behind", health.WebsocketBacklog))
+	}
+	if health.FileEventDelay >= fileEventDelayThreshold {
+		kinds = append(kinds, "file-event-delay")
+		health.Problems = append(health.Problems,
+			fmt.Sprintf("the last file change waited %s to be processed", health.FileEventDelay.Truncate(100*time.Millisecond)))
+	}
+	return health, kinds
+}
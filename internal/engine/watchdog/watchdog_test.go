@@ -0,0 +1,121 @@
+package watchdog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestHealthyEngineIsQuiet(t *testing.T) {
+	f := newFixture(t)
+
+	f.check()
+	assert.Empty(t, f.st.actions)
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	f := newFixture(t)
+
+	f.st.busy = []store.BusySubscriber{
+		{Name: "*configs.ConfigsController", Since: f.clock.Now().Add(-2 * time.Minute)},
+	}
+	f.check()
+
+	health := f.lastHealth()
+	assert.True(t, health.Degraded())
+	assert.Equal(t, "*configs.ConfigsController", health.SlowestSubscriber)
+	assert.Equal(t, []string{"*configs.ConfigsController has been handling a change for 2m0s"}, health.Problems)
+
+	// Keep the UI up to date while we're degraded.
+	f.clock.Advance(checkInterval)
+	f.check()
+	assert.Len(t, f.st.actions, 2)
+	assert.Equal(t, 2*time.Minute+checkInterval, f.lastHealth().SlowestSubscriberLag)
+
+	// Report once when we recover, then go quiet.
+	f.st.busy = nil
+	f.check()
+	f.check()
+	require.Len(t, f.st.actions, 3)
+	assert.False(t, f.lastHealth().Degraded())
+}
+
+func TestActionQueueAndWebsocketBacklog(t *testing.T) {
+	f := newFixture(t)
+
+	f.st.queueDepth = 5000
+	f.ws.backlog = 3
+	f.check()
+
+	assert.Equal(t, []string{
+		"5000 actions are waiting to be processed",
+		"a web UI is 3 updates behind",
+	}, f.lastHealth().Problems)
+}
+
+func TestFileEventDelay(t *testing.T) {
+	f := newFixture(t)
+
+	f.wd.fileEventLatency = store.FileEventLatency{HandledAt: f.clock.Now(), Delay: 8 * time.Second}
+	f.check()
+	assert.Equal(t, []string{"the last file change waited 8s to be processed"}, f.lastHealth().Problems)
+
+	// A slow file change from a while ago doesn't count anymore.
+	f.clock.Advance(2 * fileEventDelayMemory)
+	f.check()
+	assert.False(t, f.lastHealth().Degraded())
+}
+
+type fixture struct {
+	t     *testing.T
+	st    *fakeStore
+	ws    *fakeWebsockets
+	clock clockwork.FakeClock
+	wd    *Watchdog
+}
+
+func newFixture(t *testing.T) *fixture {
+	st := &fakeStore{}
+	ws := &fakeWebsockets{}
+	clock := clockwork.NewFakeClock()
+	return &fixture{
+		t:     t,
+		st:    st,
+		ws:    ws,
+		clock: clock,
+		wd:    newWatchdog(st, ws, scheduler.NewScheduler(clock), clock),
+	}
+}
+
+func (f *fixture) check() {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f.wd.check(ctx)
+}
+
+func (f *fixture) lastHealth() store.EngineHealth {
+	require.NotEmpty(f.t, f.st.actions, "No health reported")
+	return f.st.actions[len(f.st.actions)-1].(HealthAction).Health
+}
+
+type fakeStore struct {
+	queueDepth int
+	busy       []store.BusySubscriber
+	actions    []store.Action
+}
+
+func (s *fakeStore) Dispatch(action store.Action)            { s.actions = append(s.actions, action) }
+func (s *fakeStore) ActionQueueDepth() int                   { return s.queueDepth }
+func (s *fakeStore) BusySubscribers() []store.BusySubscriber { return s.busy }
+
+type fakeWebsockets struct {
+	backlog int
+}
+
+func (w *fakeWebsockets) WebsocketBacklog() int { return w.backlog }
@@ -31,10 +31,13 @@ var DeployerBaseWireSet = wire.NewSet(
 
 	sidecar.WireSet,
 	k8s.ProvideMinikubeClient,
+	k8s.ProvideSessionID,
 	build.DefaultDockerBuilder,
 	build.NewDockerImageBuilder,
 	build.NewExecCustomBuilder,
 	wire.Bind(new(build.CustomBuilder), new(*build.ExecCustomBuilder)),
+	build.NewKanikoClusterBuilder,
+	wire.Bind(new(build.ClusterBuilder), new(*build.KanikoClusterBuilder)),
 
 	// BuildOrder
 	NewLocalTargetBuildAndDeployer,
@@ -79,6 +82,7 @@ func provideBuildAndDeployer(
 	dcc dockercompose.DockerComposeClient,
 	clock build.Clock,
 	kp KINDLoader,
+	cl CRIOLoader,
 	analytics *analytics.TiltAnalytics) (BuildAndDeployer, error) {
 	wire.Build(
 		DeployerWireSetTest,
@@ -96,6 +100,7 @@ func provideImageBuildAndDeployer(
 	dir *dirs.WindmillDir,
 	clock build.Clock,
 	kp KINDLoader,
+	cl CRIOLoader,
 	analytics *analytics.TiltAnalytics) (*ImageBuildAndDeployer, error) {
 	wire.Build(
 		DeployerWireSetTest,
@@ -0,0 +1,14 @@
+package workloadstatus
+
+import (
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// What a resource's workloads say about their own rollouts.
+type StatusAction struct {
+	ManifestName model.ManifestName
+	Statuses     []store.WorkloadStatus
+}
+
+func (StatusAction) Action() {}
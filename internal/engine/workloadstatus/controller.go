@@ -0,0 +1,138 @@
+package workloadstatus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const checkInterval = 2 * time.Second
+
+// Periodically reads the status of the workloads that carry pod templates
+// (declared with k8s_kind(pod_template_json_path=), like Argo Rollouts), so
+// that a resource isn't ready until its workload says the rollout is done.
+//
+// Deployments don't need this, because Tilt only tracks the pods from the
+// newest pod template. But a Rollout can hold back a canary with every pod ready.
+type Controller struct {
+	kCli  k8s.Client
+	sched *scheduler.Scheduler
+
+	mu        sync.Mutex
+	st        store.RStore
+	workloads map[model.ManifestName][]v1.ObjectReference
+
+	// The statuses in the engine state, so that we only dispatch changes.
+	statuses map[model.ManifestName][]store.WorkloadStatus
+}
+
+var _ store.SetUpper = &Controller{}
+var _ store.Subscriber = &Controller{}
+
+func NewController(kCli k8s.Client, sched *scheduler.Scheduler) *Controller {
+	return &Controller{
+		kCli:  kCli,
+		sched: sched,
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context) {
+	c.sched.Every(ctx, "workload-status", checkInterval, checkInterval, c.check)
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	workloads := make(map[model.ManifestName][]v1.ObjectReference)
+	statuses := make(map[model.ManifestName][]store.WorkloadStatus)
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsK8s() {
+			continue
+		}
+		kTarget := mt.Manifest.K8sTarget()
+		locators := k8s.PodTemplateLocators(k8s.ToImageLocators(kTarget.ImageLocators))
+		if len(locators) == 0 {
+			continue
+		}
+
+		result, ok := mt.State.BuildStatus(kTarget.ID()).LastResult.(store.K8sBuildResult)
+		if !ok {
+			continue
+		}
+		statuses[mt.Manifest.Name] = mt.State.K8sRuntimeState().WorkloadStatuses
+		for _, ref := range result.DeployedRefs {
+			for _, l := range locators {
+				if l.MatchesRef(ref) {
+					workloads[mt.Manifest.Name] = append(workloads[mt.Manifest.Name], ref)
+					break
+				}
+			}
+		}
+	}
+	st.RUnlockState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.st = st
+	c.workloads = workloads
+	c.statuses = statuses
+}
+
+// Reads the status of every workload, and reports any changes for each resource.
+func (c *Controller) check(ctx context.Context) {
+	c.mu.Lock()
+	st := c.st
+	workloads := make(map[model.ManifestName][]v1.ObjectReference, len(c.workloads))
+	for mn, refs := range c.workloads {
+		workloads[mn] = refs
+	}
+	c.mu.Unlock()
+
+	if st == nil {
+		return
+	}
+
+	for mn, refs := range workloads {
+		var statuses []store.WorkloadStatus
+		for _, ref := range refs {
+			e, err := c.kCli.GetByReference(ctx, ref)
+			if err != nil {
+				// It may not exist yet, or have been deleted out from under us.
+				// Either way, its pods will tell us what's going on.
+				logger.Get(ctx).Debugf("Reading status of %s %s: %v", ref.Kind, ref.Name, err)
+				continue
+			}
+
+			status, msg, ok := k8s.WorkloadRuntimeStatus(e)
+			if !ok {
+				continue
+			}
+			statuses = append(statuses, store.WorkloadStatus{Ref: ref, Status: status, Message: msg})
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.mu.Lock()
+		current, ok := c.statuses[mn]
+		changed := ok && !reflect.DeepEqual(current, statuses)
+		if changed {
+			// Don't dispatch the same change twice while the store catches up.
+			c.statuses[mn] = statuses
+		}
+		c.mu.Unlock()
+
+		if changed {
+			st.Dispatch(StatusAction{ManifestName: mn, Statuses: statuses})
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package workloadstatus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestReportRolloutStatus(t *testing.T) {
+	f := newFixture(t)
+	f.kCli.InjectEntityByName(newRollout("Paused", "CanaryPauseStep"))
+	f.deploy()
+
+	f.c.OnChange(f.ctx, f.st)
+	f.c.check(f.ctx)
+
+	actions := f.statusActions()
+	require.Len(t, actions, 1)
+	assert.Equal(t, model.ManifestName("fe"), actions[0].ManifestName)
+	require.Len(t, actions[0].Statuses, 1)
+	assert.Equal(t, "fe", actions[0].Statuses[0].Ref.Name)
+	assert.Equal(t, model.RuntimeStatusPending, actions[0].Statuses[0].Status)
+	assert.Equal(t, "Paused: CanaryPauseStep", actions[0].Statuses[0].Message)
+
+	// Nothing changed, so nothing to report.
+	f.c.check(f.ctx)
+	assert.Len(t, f.statusActions(), 1)
+
+	f.kCli.InjectEntityByName(newRollout("Healthy", ""))
+	f.c.check(f.ctx)
+
+	actions = f.statusActions()
+	require.Len(t, actions, 2)
+	assert.Equal(t, model.RuntimeStatusOK, actions[1].Statuses[0].Status)
+}
+
+func TestIgnoreWorkloadsWithoutPodTemplateLocator(t *testing.T) {
+	f := newFixture(t)
+	f.kCli.InjectEntityByName(newRollout("Paused", "CanaryPauseStep"))
+	f.deployWithLocators(nil)
+
+	f.c.OnChange(f.ctx, f.st)
+	f.c.check(f.ctx)
+
+	assert.Empty(t, f.statusActions())
+}
+
+func TestSetUp(t *testing.T) {
+	f := newFixture(t)
+	f.c.SetUp(f.ctx)
+	assert.Equal(t, []string{"workload-status"}, f.sched.JobNames())
+}
+
+type fixture struct {
+	t     *testing.T
+	ctx   context.Context
+	kCli  *k8s.FakeK8sClient
+	st    *store.TestingStore
+	sched *scheduler.Scheduler
+	c     *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	kCli := k8s.NewFakeK8sClient()
+	sched := scheduler.NewScheduler(clockwork.NewFakeClock())
+	return &fixture{
+		t:     t,
+		ctx:   ctx,
+		kCli:  kCli,
+		st:    store.NewTestingStore(),
+		sched: sched,
+		c:     NewController(kCli, sched),
+	}
+}
+
+func (f *fixture) deploy() {
+	f.deployWithLocators([]model.K8sImageLocator{
+		k8s.MustPodTemplateLocator(k8s.MustKindSelector("Rollout"), "{.spec.template}"),
+	})
+}
+
+func (f *fixture) deployWithLocators(locators []model.K8sImageLocator) {
+	f.st.WithState(func(state *store.EngineState) {
+		kTarget := model.K8sTarget{Name: "fe", ImageLocators: locators}
+		m := model.Manifest{Name: "fe"}.WithDeployTarget(kTarget)
+		mt := store.NewManifestTarget(m)
+		mt.State.MutableBuildStatus(kTarget.ID()).LastResult = store.K8sBuildResult{
+			DeployedRefs: []v1.ObjectReference{
+				{Kind: "Rollout", APIVersion: "argoproj.io/v1alpha1", Name: "fe", Namespace: "default"},
+				{Kind: "Service", APIVersion: "v1", Name: "fe-svc", Namespace: "default"},
+			},
+		}
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) statusActions() []StatusAction {
+	var result []StatusAction
+	for _, action := range f.st.Actions() {
+		if a, ok := action.(StatusAction); ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func newRollout(phase, message string) k8s.K8sEntity {
+	return k8s.NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": "fe", "namespace": "default", "generation": int64(2)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"phase":              phase,
+			"message":            message,
+		},
+	}})
+}
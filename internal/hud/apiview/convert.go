@@ -0,0 +1,306 @@
+package apiview
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// There's only ever one session.
+const SessionName = "Tiltfile"
+
+// The plural names of each kind, as they appear in API paths.
+var Kinds = map[string]string{
+	"sessions":  v1alpha1.KindSession,
+	"resources": v1alpha1.KindResource,
+	"builds":    v1alpha1.KindBuild,
+	"logspans":  v1alpha1.KindLogSpan,
+}
+
+// The objects that the Tilt API serves, built from a snapshot of the engine state.
+type Objects struct {
+	Sessions  []v1alpha1.Session
+	Resources []v1alpha1.Resource
+	Builds    []v1alpha1.Build
+	LogSpans  []v1alpha1.LogSpan
+}
+
+// Returns the objects of the given kind, by plural name, in a stable order.
+func (o Objects) List(plural string) ([]metav1.Object, bool) {
+	var result []metav1.Object
+	switch plural {
+	case "sessions":
+		for i := range o.Sessions {
+			result = append(result, &o.Sessions[i])
+		}
+	case "resources":
+		for i := range o.Resources {
+			result = append(result, &o.Resources[i])
+		}
+	case "builds":
+		for i := range o.Builds {
+			result = append(result, &o.Builds[i])
+		}
+	case "logspans":
+		for i := range o.LogSpans {
+			result = append(result, &o.LogSpans[i])
+		}
+	default:
+		return nil, false
+	}
+	return result, true
+}
+
+// Wraps the objects of the given kind in a List object, e.g., a ResourceList.
+func (o Objects) ListObject(plural string) (interface{}, bool) {
+	listMeta := metav1.ListMeta{ResourceVersion: o.ResourceVersion(plural)}
+	typeMeta := func(kind string) metav1.TypeMeta {
+		return metav1.TypeMeta{Kind: kind + "List", APIVersion: v1alpha1.APIVersion}
+	}
+	switch plural {
+	case "sessions":
+		return v1alpha1.SessionList{TypeMeta: typeMeta(v1alpha1.KindSession), ListMeta: listMeta, Items: o.Sessions}, true
+	case "resources":
+		return v1alpha1.ResourceList{TypeMeta: typeMeta(v1alpha1.KindResource), ListMeta: listMeta, Items: o.Resources}, true
+	case "builds":
+		return v1alpha1.BuildList{TypeMeta: typeMeta(v1alpha1.KindBuild), ListMeta: listMeta, Items: o.Builds}, true
+	case "logspans":
+		return v1alpha1.LogSpanList{TypeMeta: typeMeta(v1alpha1.KindLogSpan), ListMeta: listMeta, Items: o.LogSpans}, true
+	}
+	return nil, false
+}
+
+// A version for the whole list, which changes when any object in it changes.
+func (o Objects) ResourceVersion(plural string) string {
+	objs, _ := o.List(plural)
+	h := fnv.New64a()
+	for _, obj := range objs {
+		_, _ = h.Write([]byte(obj.GetName()))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(obj.GetResourceVersion()))
+		_, _ = h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func StateToObjects(s store.EngineState) Objects {
+	o := Objects{
+		Sessions: []v1alpha1.Session{toSession(s)},
+	}
+
+	o.Resources = append(o.Resources, toResource(model.TiltfileManifestName, "tiltfile",
+		model.TriggerModeAuto, nil, &s.TiltfileState))
+	o.Builds = append(o.Builds, toBuilds(model.TiltfileManifestName, &s.TiltfileState)...)
+
+	for _, mt := range s.Targets() {
+		m := mt.Manifest
+		o.Resources = append(o.Resources, toResource(m.Name, resourceType(m),
+			m.TriggerMode, m.ResourceDependencies, mt.State))
+		o.Builds = append(o.Builds, toBuilds(m.Name, mt.State)...)
+	}
+
+	if s.LogStore != nil {
+		o.LogSpans = toLogSpans(s)
+	}
+
+	for i := range o.Sessions {
+		o.Sessions[i].Default()
+		o.Sessions[i].ResourceVersion = resourceVersion(o.Sessions[i])
+	}
+	for i := range o.Resources {
+		o.Resources[i].Default()
+		o.Resources[i].ResourceVersion = resourceVersion(o.Resources[i])
+	}
+	for i := range o.Builds {
+		o.Builds[i].Default()
+		o.Builds[i].ResourceVersion = resourceVersion(o.Builds[i])
+	}
+	for i := range o.LogSpans {
+		o.LogSpans[i].Default()
+		o.LogSpans[i].ResourceVersion = resourceVersion(o.LogSpans[i])
+	}
+	return o
+}
+
+func toSession(s store.EngineState) v1alpha1.Session {
+	session := v1alpha1.Session{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              SessionName,
+			CreationTimestamp: metav1.NewTime(s.TiltStartTime),
+		},
+		Spec: v1alpha1.SessionSpec{
+			TiltfilePath: s.TiltfilePath,
+		},
+		Status: v1alpha1.SessionStatus{
+			Version:   s.TiltBuildInfo.HumanBuildStamp(),
+			StartTime: metav1.NewTime(s.TiltStartTime),
+		},
+	}
+	if s.FatalError != nil {
+		session.Status.FatalError = s.FatalError.Error()
+	}
+	return session
+}
+
+func resourceType(m model.Manifest) string {
+	switch {
+	case m.IsK8s():
+		return "k8s"
+	case m.IsDC():
+		return "docker-compose"
+	case m.IsLocal():
+		return "local"
+	}
+	return "unknown"
+}
+
+func triggerModeString(tm model.TriggerMode) string {
+	switch tm {
+	case model.TriggerModeManualAfterInitial:
+		return v1alpha1.TriggerModeManualAfterInitial
+	case model.TriggerModeManualIncludingInitial:
+		return v1alpha1.TriggerModeManualIncludingInitial
+	}
+	return v1alpha1.TriggerModeAuto
+}
+
+func toResource(name model.ManifestName, resourceType string, tm model.TriggerMode,
+	deps []model.ManifestName, ms *store.ManifestState) v1alpha1.Resource {
+	hasPendingChanges, _ := ms.HasPendingChanges()
+	r := v1alpha1.Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name.String(),
+		},
+		Spec: v1alpha1.ResourceSpec{
+			Type:        resourceType,
+			TriggerMode: triggerModeString(tm),
+		},
+		Status: v1alpha1.ResourceStatus{
+			UpdateStatus:      updateStatus(tm, ms, hasPendingChanges),
+			CurrentBuild:      string(ms.CurrentBuild.SpanID),
+			LastDeployTime:    metav1.NewTime(ms.LastSuccessfulDeployTime),
+			HasPendingChanges: hasPendingChanges,
+		},
+	}
+	for _, dep := range deps {
+		r.Spec.ResourceDeps = append(r.Spec.ResourceDeps, dep.String())
+	}
+	if ms.RuntimeState != nil {
+		r.Status.RuntimeStatus = string(ms.RuntimeState.RuntimeStatus())
+	}
+	return r
+}
+
+func updateStatus(tm model.TriggerMode, ms *store.ManifestState, hasPendingChanges bool) string {
+	if ms.IsBuilding() {
+		return v1alpha1.UpdateStatusInProgress
+	}
+	if hasPendingChanges {
+		return v1alpha1.UpdateStatusPending
+	}
+
+	lastBuild := ms.LastBuild()
+	if lastBuild.Empty() {
+		if tm.AutoInitial() {
+			return v1alpha1.UpdateStatusPending
+		}
+		return v1alpha1.UpdateStatusNone
+	}
+	if lastBuild.Error != nil {
+		return v1alpha1.UpdateStatusError
+	}
+	return v1alpha1.UpdateStatusOK
+}
+
+func toBuilds(name model.ManifestName, ms *store.ManifestState) []v1alpha1.Build {
+	records := append([]model.BuildRecord{}, ms.BuildHistory...)
+	if !ms.CurrentBuild.Empty() {
+		records = append(records, ms.CurrentBuild)
+	}
+
+	var result []v1alpha1.Build
+	for _, br := range records {
+		// Builds are named after their log span, so a build without one
+		// has no stable name.
+		if br.SpanID == "" {
+			continue
+		}
+		b := v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              string(br.SpanID),
+				CreationTimestamp: metav1.NewTime(br.StartTime),
+			},
+			Spec: v1alpha1.BuildSpec{
+				Resource: name.String(),
+				Reason:   br.Reason.String(),
+				Edits:    br.Edits,
+			},
+			Status: v1alpha1.BuildStatus{
+				StartTime:    metav1.NewTime(br.StartTime),
+				FinishTime:   timePtr(br.FinishTime),
+				WarningCount: int32(br.WarningCount),
+			},
+		}
+		if br.Error != nil {
+			b.Status.Error = br.Error.Error()
+		}
+		result = append(result, b)
+	}
+
+	// Oldest first, so that clients can render them in order.
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Status.StartTime.Before(&result[j].Status.StartTime)
+	})
+	return result
+}
+
+func toLogSpans(s store.EngineState) []v1alpha1.LogSpan {
+	spans := s.LogStore.Spans()
+	result := make([]v1alpha1.LogSpan, 0, len(spans))
+	for id, span := range spans {
+		result = append(result, v1alpha1.LogSpan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: string(id),
+			},
+			Spec: v1alpha1.LogSpanSpec{
+				Resource: span.ManifestName.String(),
+			},
+			Status: v1alpha1.LogSpanStatus{
+				FirstCheckpoint: int64(span.FirstCheckpoint),
+				LastCheckpoint:  int64(span.LastCheckpoint),
+			},
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func timePtr(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// An opaque version that changes whenever the object's contents change.
+// The object must not have a resource version set yet.
+func resourceVersion(obj interface{}) string {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return ""
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,143 @@
+package apiview
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSession(t *testing.T) {
+	state := newState()
+	state.TiltfilePath = "/code/Tiltfile"
+	state.FatalError = fmt.Errorf("oh no")
+
+	o := StateToObjects(*state)
+	require.Len(t, o.Sessions, 1)
+	session := o.Sessions[0]
+	assert.Equal(t, "Session", session.Kind)
+	assert.Equal(t, "tilt.dev/v1alpha1", session.APIVersion)
+	assert.Equal(t, "Tiltfile", session.Name)
+	assert.Equal(t, "/code/Tiltfile", session.Spec.TiltfilePath)
+	assert.Equal(t, "oh no", session.Status.FatalError)
+	assert.NotEmpty(t, session.ResourceVersion)
+}
+
+func TestResources(t *testing.T) {
+	state := newState()
+	m := model.Manifest{Name: "fe"}.
+		WithTriggerMode(model.TriggerModeManualIncludingInitial).
+		WithDeployTarget(model.LocalTarget{UpdateCmd: model.ToHostCmd("echo hi")})
+	m.ResourceDependencies = []model.ManifestName{"be"}
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+
+	o := StateToObjects(*state)
+	require.Len(t, o.Resources, 2)
+	assert.Equal(t, "(Tiltfile)", o.Resources[0].Name)
+	assert.Equal(t, "tiltfile", o.Resources[0].Spec.Type)
+
+	r := o.Resources[1]
+	assert.Equal(t, "Resource", r.Kind)
+	assert.Equal(t, "fe", r.Name)
+	assert.Equal(t, "local", r.Spec.Type)
+	assert.Equal(t, v1alpha1.TriggerModeManualIncludingInitial, r.Spec.TriggerMode)
+	assert.Equal(t, []string{"be"}, r.Spec.ResourceDeps)
+	assert.Equal(t, v1alpha1.UpdateStatusNone, r.Status.UpdateStatus)
+}
+
+func TestBuilds(t *testing.T) {
+	state := newState()
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{UpdateCmd: model.ToHostCmd("echo hi")})
+	mt := store.NewManifestTarget(m)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: start.Add(time.Second),
+		Error:      fmt.Errorf("exit status 1"),
+		Reason:     model.BuildReasonFlagInit,
+		SpanID:     "build:fe:1",
+	})
+	mt.State.CurrentBuild = model.BuildRecord{
+		StartTime: start.Add(time.Minute),
+		Reason:    model.BuildReasonFlagChangedFiles,
+		Edits:     []string{"main.go"},
+		SpanID:    "build:fe:2",
+	}
+	state.UpsertManifestTarget(mt)
+
+	o := StateToObjects(*state)
+	require.Len(t, o.Builds, 2)
+
+	first := o.Builds[0]
+	assert.Equal(t, "Build", first.Kind)
+	assert.Equal(t, "build:fe:1", first.Name)
+	assert.Equal(t, "fe", first.Spec.Resource)
+	assert.Equal(t, "Initial Build", first.Spec.Reason)
+	assert.Equal(t, "exit status 1", first.Status.Error)
+	require.NotNil(t, first.Status.FinishTime)
+
+	second := o.Builds[1]
+	assert.Equal(t, "build:fe:2", second.Name)
+	assert.Equal(t, []string{"main.go"}, second.Spec.Edits)
+	assert.Nil(t, second.Status.FinishTime)
+
+	require.Len(t, o.Resources, 2)
+	assert.Equal(t, v1alpha1.UpdateStatusInProgress, o.Resources[1].Status.UpdateStatus)
+	assert.Equal(t, "build:fe:2", o.Resources[1].Status.CurrentBuild)
+}
+
+func TestLogSpans(t *testing.T) {
+	state := newState()
+	state.LogStore.Append(store.NewLogAction("fe", "build:fe:1", logger.InfoLvl, nil, []byte("hello\n")), nil)
+	state.LogStore.Append(store.NewLogAction("fe", "build:fe:1", logger.InfoLvl, nil, []byte("world\n")), nil)
+
+	o := StateToObjects(*state)
+	require.Len(t, o.LogSpans, 1)
+	span := o.LogSpans[0]
+	assert.Equal(t, "LogSpan", span.Kind)
+	assert.Equal(t, "build:fe:1", span.Name)
+	assert.Equal(t, "fe", span.Spec.Resource)
+	assert.Equal(t, int64(0), span.Status.FirstCheckpoint)
+	assert.Equal(t, int64(1), span.Status.LastCheckpoint)
+}
+
+func TestResourceVersionChangesWithObject(t *testing.T) {
+	state := newState()
+	m := model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{UpdateCmd: model.ToHostCmd("echo hi")})
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	before := StateToObjects(*state)
+	again := StateToObjects(*state)
+	assert.Equal(t, before.Resources[1].ResourceVersion, again.Resources[1].ResourceVersion)
+	assert.Equal(t, before.ResourceVersion("resources"), again.ResourceVersion("resources"))
+
+	mt.State.CurrentBuild = model.BuildRecord{StartTime: time.Now(), SpanID: "build:fe:1"}
+	after := StateToObjects(*state)
+	assert.NotEqual(t, before.Resources[1].ResourceVersion, after.Resources[1].ResourceVersion)
+	assert.NotEqual(t, before.ResourceVersion("resources"), after.ResourceVersion("resources"))
+	assert.Equal(t, before.Sessions[0].ResourceVersion, after.Sessions[0].ResourceVersion)
+}
+
+func TestDefaults(t *testing.T) {
+	r := v1alpha1.Resource{}
+	r.Default()
+	assert.Equal(t, v1alpha1.KindResource, r.Kind)
+	assert.Equal(t, v1alpha1.APIVersion, r.APIVersion)
+	assert.Equal(t, v1alpha1.TriggerModeAuto, r.Spec.TriggerMode)
+	assert.Equal(t, v1alpha1.UpdateStatusNone, r.Status.UpdateStatus)
+	assert.Equal(t, v1alpha1.RuntimeStatusUnknown, r.Status.RuntimeStatus)
+}
+
+func newState() *store.EngineState {
+	state := store.NewState()
+	state.TiltStartTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return state
+}
@@ -39,6 +39,13 @@ func makeBuildStatus(res view.Resource, triggerMode model.TriggerMode) buildStat
 		}
 	}
 
+	if res.ConfigError != "" {
+		return buildStatus{
+			status:     "Config error",
+			deployTime: res.LastDeployTime,
+		}
+	}
+
 	if !res.CurrentBuild.Empty() && !res.CurrentBuild.Reason.IsCrashOnly() {
 		status = "In prog."
 		duration = time.Since(res.CurrentBuild.StartTime)
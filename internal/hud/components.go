@@ -3,10 +3,17 @@ package hud
 import (
 	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/rty"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 const resourcesScollerName = "resources"
 const alertScrollerName = "alert"
+const alertCenterScrollerName = "alert-center"
+const buildQueueScrollerName = "build-queue"
+
+func pinnedScrollerName(mn model.ManifestName) string {
+	return "pinned:" + mn.String()
+}
 
 func (h *Hud) activeScroller() scroller {
 	am := h.activeModal()
@@ -20,6 +27,10 @@ func (h *Hud) activeScroller() scroller {
 func (h *Hud) activeModal() modal {
 	if h.currentViewState.AlertMessage != "" {
 		return makeAlertModal(h.r.rty)
+	} else if h.currentViewState.ShowAlertCenter {
+		return makeAlertCenterModal(h.r.rty)
+	} else if h.currentViewState.ShowBuildQueue {
+		return makeBuildQueueModal(h.r.rty)
 	} else {
 		return nil
 	}
@@ -53,3 +64,31 @@ func makeAlertModal(r rty.RTY) modal {
 func (am alertModal) Close(vs *view.ViewState) {
 	vs.AlertMessage = ""
 }
+
+type alertCenterModal struct {
+	rty.TextScroller
+}
+
+var _ modal = alertCenterModal{}
+
+func makeAlertCenterModal(r rty.RTY) modal {
+	return alertCenterModal{r.TextScroller(alertCenterScrollerName)}
+}
+
+func (am alertCenterModal) Close(vs *view.ViewState) {
+	vs.ShowAlertCenter = false
+}
+
+type buildQueueModal struct {
+	rty.TextScroller
+}
+
+var _ modal = buildQueueModal{}
+
+func makeBuildQueueModal(r rty.RTY) modal {
+	return buildQueueModal{r.TextScroller(buildQueueScrollerName)}
+}
+
+func (m buildQueueModal) Close(vs *view.ViewState) {
+	vs.ShowBuildQueue = false
+}
@@ -17,14 +17,20 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
-// The main loop ensures the HUD updates at least this often
+// The main loop checks whether the HUD needs to update this often
 const DefaultRefreshInterval = 100 * time.Millisecond
 
+// When nothing has changed and nothing is animating, the HUD still re-renders
+// this often, so that durations like "5s ago" stay current.
+const idleRefreshInterval = time.Second
+
 // number of arrows a pgup/dn is equivalent to
 // (we don't currently worry about trying to know how big a page is, and instead just support pgup/dn as "faster arrows"
 const pgUpDownCount = 20
@@ -44,16 +50,23 @@ type Hud struct {
 	mu               sync.RWMutex
 	isStarted        bool
 	isRunning        bool
+	dirty            bool
+	lastRender       time.Time
 	a                *analytics.TiltAnalytics
+
+	// The Tiltfile that the pinned resources belong to.
+	pins         *PinStore
+	tiltfilePath string
 }
 
 var _ HeadsUpDisplay = (*Hud)(nil)
 
-func NewHud(renderer *Renderer, webURL model.WebURL, analytics *analytics.TiltAnalytics) HeadsUpDisplay {
+func NewHud(renderer *Renderer, webURL model.WebURL, analytics *analytics.TiltAnalytics, pins *PinStore) HeadsUpDisplay {
 	return &Hud{
 		r:      renderer,
 		webURL: webURL,
 		a:      analytics,
+		pins:   pins,
 	}
 }
 
@@ -110,7 +123,7 @@ func (h *Hud) Run(ctx context.Context, dispatch func(action store.Action), refre
 				return nil
 			}
 		case <-ticker.C:
-			h.Refresh(ctx)
+			h.refreshIfNeeded(ctx)
 		}
 	}
 }
@@ -129,9 +142,19 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 
 	escape := func() {
 		am := h.activeModal()
-		if am != nil {
-			am.Close(&h.currentViewState)
+		if am == nil {
+			return
+		}
+
+		// Closing the alert center acknowledges the alerts the user just saw.
+		if _, ok := am.(alertCenterModal); ok {
+			ids := make([]string, len(h.currentView.Alerts))
+			for i, a := range h.currentView.Alerts {
+				ids[i] = a.ID
+			}
+			dispatch(store.AlertsAcknowledgedAction{IDs: ids})
 		}
+		am.Close(&h.currentViewState)
 	}
 
 	switch ev := ev.(type) {
@@ -156,6 +179,19 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 				} else {
 					h.currentViewState.AlertMessage = fmt.Sprintf("no urls for resource '%s' ¯\\_(ツ)_/¯", selected.Name)
 				}
+			case r == 'a': // [A]lerts
+				if len(h.currentView.Alerts) > 0 {
+					h.recordInteraction("alert_center")
+					h.currentViewState.ShowAlertCenter = true
+				}
+			case r == 'u': // B[u]ild queue
+				h.recordInteraction("build_queue")
+				h.currentViewState.ShowBuildQueue = true
+			case r == 'c': // [C]lear the build queue
+				if h.currentViewState.ShowBuildQueue && len(h.currentView.BuildQueue) > 0 {
+					h.recordInteraction("clear_build_queue")
+					dispatch(store.ClearBuildQueueAction{})
+				}
 			case r == 'l': // Tilt [L]og
 				if h.webURL.Empty() {
 					break
@@ -176,6 +212,9 @@ func (h *Hud) handleScreenEvent(ctx context.Context, dispatch func(action store.
 			case r == 'x':
 				h.recordInteraction("cycle_view_log_state")
 				h.currentViewState.CycleViewLogState()
+			case r == 'p': // [P]in
+				h.recordInteraction("toggle_pin")
+				h.togglePin()
 			case r == '1':
 				h.recordInteraction("tab_all_log")
 				h.currentViewState.TabState = view.TabAllLog
@@ -282,10 +321,14 @@ func (h *Hud) OnChange(ctx context.Context, st store.RStore) {
 	toPrint := ""
 	state := st.RLockState()
 	view := store.StateToView(state, st.StateMutex())
+	view.BuildQueue = buildQueueView(state)
+	tiltfilePath := state.TiltfilePath
 
 	// if the hud isn't running, make sure new logs are visible on stdout
 	if !h.isRunning {
-		toPrint = state.LogStore.ContinuingString(h.currentViewState.ProcessedLogs)
+		toPrint = state.LogStore.ContinuingStringWithOptions(h.currentViewState.ProcessedLogs, logstore.LineOptions{
+			Settings: state.LogSettings,
+		})
 	}
 	h.currentViewState.ProcessedLogs = state.LogStore.Checkpoint()
 
@@ -293,12 +336,18 @@ func (h *Hud) OnChange(ctx context.Context, st store.RStore) {
 
 	fmt.Print(toPrint)
 
+	if tiltfilePath != "" && tiltfilePath != h.tiltfilePath {
+		h.tiltfilePath = tiltfilePath
+		h.currentViewState.PinnedResources = h.pins.Load(tiltfilePath)
+	}
+
 	// if we're going from 1 resource (i.e., the Tiltfile) to more than 1, reset
 	// the resource selection, so that we're not scrolled to the bottom with the Tiltfile selected
 	if len(h.currentView.Resources) == 1 && len(view.Resources) > 1 {
 		h.resetResourceSelection()
 	}
 	h.currentView = view
+	h.dirty = true
 	h.refreshSelectedIndex()
 }
 
@@ -308,6 +357,30 @@ func (h *Hud) Refresh(ctx context.Context) {
 	h.refresh(ctx)
 }
 
+// Re-renders only if the view has changed or something on screen is animating,
+// so that an idle Tilt isn't redrawing the terminal 10x/second.
+func (h *Hud) refreshIfNeeded(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.needsRefresh(time.Now()) {
+		return
+	}
+	h.refresh(ctx)
+}
+
+// Must hold the lock
+func (h *Hud) needsRefresh(now time.Time) bool {
+	if h.dirty || now.Sub(h.lastRender) >= idleRefreshInterval {
+		return true
+	}
+	for _, res := range h.currentView.Resources {
+		if combinedStatus(res).spinner {
+			return true
+		}
+	}
+	return false
+}
+
 // Must hold the lock
 func (h *Hud) setViewState(ctx context.Context, currentViewState view.ViewState) {
 	h.currentViewState = currentViewState
@@ -325,6 +398,8 @@ func (h *Hud) refresh(ctx context.Context) {
 	vs.Resources = append(vs.Resources, h.currentViewState.Resources...)
 
 	h.r.Render(h.currentView, h.currentViewState)
+	h.dirty = false
+	h.lastRender = time.Now()
 }
 
 func (h *Hud) resetResourceSelection() {
@@ -350,6 +425,39 @@ func (h *Hud) refreshSelectedIndex() {
 	h.currentViewState.SelectedIndex = i
 }
 
+// Pins the selected resource into a split pane, or unpins it if it's already pinned.
+//
+// Must hold the lock
+func (h *Hud) togglePin() {
+	_, selected := h.selectedResource()
+	if selected.Name == "" {
+		return
+	}
+
+	var pinned []model.ManifestName
+	for _, mn := range h.currentViewState.PinnedResources {
+		if mn != selected.Name {
+			pinned = append(pinned, mn)
+		}
+	}
+	if len(pinned) == len(h.currentViewState.PinnedResources) {
+		if len(pinned) >= maxPinnedResources {
+			h.currentViewState.AlertMessage = fmt.Sprintf("You can pin up to %d resources. Unpin one with (p) first.", maxPinnedResources)
+			return
+		}
+		pinned = append(pinned, selected.Name)
+	}
+	h.currentViewState.PinnedResources = pinned
+
+	if h.tiltfilePath == "" {
+		return
+	}
+	err := h.pins.Save(h.tiltfilePath, pinned)
+	if err != nil {
+		h.currentViewState.AlertMessage = fmt.Sprintf("error saving pinned resources: %v", err)
+	}
+}
+
 func (h *Hud) selectedResource() (i int, resource view.Resource) {
 	return selectedResource(h.currentView, h.currentViewState)
 }
@@ -385,3 +493,15 @@ func writeHeapProfile(ctx context.Context) {
 	}
 	logger.Get(ctx).Infof("wrote heap profile to %s", f.Name())
 }
+
+func buildQueueView(state store.EngineState) []view.QueuedBuild {
+	var result []view.QueuedBuild
+	for _, qb := range buildcontrol.BuildQueue(state) {
+		result = append(result, view.QueuedBuild{
+			Name:            qb.Name,
+			Reason:          qb.Reason,
+			HoldDescription: qb.Hold.Description(),
+		})
+	}
+	return result
+}
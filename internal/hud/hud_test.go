@@ -7,8 +7,12 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 
+	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/rty"
+	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -21,6 +25,96 @@ func TestRenderInit(t *testing.T) {
 	r := NewRenderer(clockForTest)
 	r.rty = rty.NewRTY(tcell.NewSimulationScreen(""), t)
 	webURL, _ := url.Parse("http://localhost:10350")
-	hud := NewHud(r, model.WebURL(*webURL), ta)
+	hud := NewHud(r, model.WebURL(*webURL), ta, nil)
 	hud.(*Hud).refresh(ctx) // Ensure we render without error
 }
+
+func TestNeedsRefresh(t *testing.T) {
+	logs := new(bytes.Buffer)
+	ctx, _, ta := testutils.ForkedCtxAndAnalyticsForTest(logs)
+
+	r := NewRenderer(time.Now)
+	r.rty = rty.NewRTY(tcell.NewSimulationScreen(""), t)
+	h := NewHud(r, model.WebURL{}, ta, nil).(*Hud)
+	h.currentView = view.View{Resources: []view.Resource{{Name: "foo"}}}
+	h.refresh(ctx)
+
+	now := h.lastRender.Add(DefaultRefreshInterval)
+	assert.False(t, h.needsRefresh(now))
+
+	// Re-render occasionally even when idle, so relative times stay fresh.
+	assert.True(t, h.needsRefresh(h.lastRender.Add(idleRefreshInterval)))
+
+	h.dirty = true
+	assert.True(t, h.needsRefresh(now))
+	h.refresh(ctx)
+	assert.False(t, h.needsRefresh(h.lastRender.Add(DefaultRefreshInterval)))
+
+	// Spinners need every frame.
+	h.currentView.Resources[0].CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	assert.True(t, h.needsRefresh(h.lastRender.Add(DefaultRefreshInterval)))
+}
+
+func TestAlertCenterAcknowledgesOnClose(t *testing.T) {
+	logs := new(bytes.Buffer)
+	ctx, _, ta := testutils.ForkedCtxAndAnalyticsForTest(logs)
+
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	screen.SetSize(80, 20)
+	r := NewRenderer(time.Now)
+	r.rty = rty.NewRTY(screen, t)
+	h := NewHud(r, model.WebURL{}, ta, nil).(*Hud)
+	h.currentView = view.View{
+		Resources: []view.Resource{{Name: "foo"}},
+		Alerts:    []model.Alert{{ID: "a1"}, {ID: "a2"}},
+	}
+	h.currentViewState = view.ViewState{Resources: []view.ResourceViewState{{}}}
+
+	var actions []store.Action
+	dispatch := func(action store.Action) { actions = append(actions, action) }
+
+	h.handleScreenEvent(ctx, dispatch, tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
+	assert.True(t, h.currentViewState.ShowAlertCenter)
+	assert.Empty(t, actions)
+
+	h.handleScreenEvent(ctx, dispatch, tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	assert.False(t, h.currentViewState.ShowAlertCenter)
+	assert.Equal(t, []store.Action{store.AlertsAcknowledgedAction{IDs: []string{"a1", "a2"}}}, actions)
+}
+
+func TestTogglePin(t *testing.T) {
+	logs := new(bytes.Buffer)
+	ctx, _, ta := testutils.ForkedCtxAndAnalyticsForTest(logs)
+
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	screen.SetSize(80, 20)
+	r := NewRenderer(time.Now)
+	r.rty = rty.NewRTY(screen, t)
+
+	dir := dirs.NewWindmillDirAt(t.TempDir())
+	h := NewHud(r, model.WebURL{}, ta, NewPinStore(dir)).(*Hud)
+	h.tiltfilePath = "/src/Tiltfile"
+	h.currentView = view.View{
+		Resources: []view.Resource{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+	}
+	h.currentViewState = view.ViewState{Resources: make([]view.ResourceViewState, 4)}
+	dispatch := func(action store.Action) {}
+
+	for i := 0; i < 4; i++ {
+		h.currentViewState.SelectedIndex = i
+		h.togglePin()
+	}
+	assert.Equal(t, []model.ManifestName{"a", "b", "c"}, h.currentViewState.PinnedResources)
+	assert.Contains(t, h.currentViewState.AlertMessage, "You can pin up to 3 resources")
+	h.handleScreenEvent(ctx, dispatch, tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+
+	h.currentViewState.SelectedIndex = 1
+	h.togglePin()
+	assert.Equal(t, []model.ManifestName{"a", "c"}, h.currentViewState.PinnedResources)
+
+	// The pins come back when Tilt restarts.
+	assert.Equal(t, []model.ManifestName{"a", "c"}, NewPinStore(dir).Load("/src/Tiltfile"))
+	assert.Empty(t, NewPinStore(dir).Load("/other/Tiltfile"))
+}
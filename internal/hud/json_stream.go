@@ -0,0 +1,185 @@
+package hud
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+const (
+	JSONEventLog           = "log"
+	JSONEventBuildStarted  = "build_started"
+	JSONEventBuildFinished = "build_finished"
+	JSONEventRuntimeStatus = "runtime_status"
+)
+
+// One line of `tilt up --output=json`.
+//
+// Which fields are set depends on the type.
+type JSONEvent struct {
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	Resource string    `json:"resource,omitempty"`
+
+	// For log events. The text is a chunk of output, not always a
+	// whole line. Lines end with a newline.
+	Level string `json:"level,omitempty"`
+	Text  string `json:"text,omitempty"`
+
+	// For build events.
+	Reason          string  `json:"reason,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+
+	// For runtime status events.
+	RuntimeStatus model.RuntimeStatus `json:"runtime_status,omitempty"`
+}
+
+// Writes logs, builds, and runtime status changes to stdout as
+// line-delimited JSON, for wrapper tools and CI log processors.
+type JSONStream struct {
+	ProcessedLogs logstore.Checkpoint
+	encoder       *json.Encoder
+	store         store.RStore
+
+	// What we last reported for each resource.
+	buildStarts   map[model.ManifestName]time.Time
+	buildFinishes map[model.ManifestName]time.Time
+	statuses      map[model.ManifestName]model.RuntimeStatus
+}
+
+func NewJSONStream(stdout Stdout, store store.RStore) *JSONStream {
+	return &JSONStream{
+		encoder:       json.NewEncoder(stdout),
+		store:         store,
+		buildStarts:   make(map[model.ManifestName]time.Time),
+		buildFinishes: make(map[model.ManifestName]time.Time),
+		statuses:      make(map[model.ManifestName]model.RuntimeStatus),
+	}
+}
+
+func (h *JSONStream) TearDown(ctx context.Context) {
+	h.OnChange(ctx, h.store)
+}
+
+func (h *JSONStream) isEnabled(st store.RStore) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+	return state.TerminalMode == store.TerminalModeJSON
+}
+
+func (h *JSONStream) OnChange(ctx context.Context, st store.RStore) {
+	if !h.isEnabled(st) {
+		return
+	}
+
+	state := st.RLockState()
+	events := h.logEvents(state)
+	h.ProcessedLogs = state.LogStore.Checkpoint()
+
+	events = append(events, h.buildEvents(store.TiltfileManifestName, &state.TiltfileState)...)
+	for _, mt := range state.Targets() {
+		events = append(events, h.buildEvents(mt.Manifest.Name, mt.State)...)
+		events = append(events, h.runtimeEvents(mt)...)
+	}
+	st.RUnlockState()
+
+	for _, e := range events {
+		err := h.encoder.Encode(e)
+		if err != nil {
+			// There's nowhere else to report this.
+			return
+		}
+	}
+}
+
+func (h *JSONStream) logEvents(state store.EngineState) []JSONEvent {
+	var result []JSONEvent
+	for _, segment := range state.LogStore.SegmentsSince(h.ProcessedLogs) {
+		if segment.IsHiddenBuildDetail(false) {
+			continue
+		}
+		result = append(result, JSONEvent{
+			Type:     JSONEventLog,
+			Time:     segment.Time,
+			Resource: segment.ManifestName.String(),
+			Level:    jsonLogLevel(segment.Level),
+			Text:     string(segment.Text),
+		})
+	}
+	return result
+}
+
+func (h *JSONStream) buildEvents(mn model.ManifestName, ms *store.ManifestState) []JSONEvent {
+	var result []JSONEvent
+
+	lastBuild := ms.LastBuild()
+	if !lastBuild.Empty() && !lastBuild.FinishTime.Equal(h.buildFinishes[mn]) {
+		h.buildFinishes[mn] = lastBuild.FinishTime
+		e := JSONEvent{
+			Type:            JSONEventBuildFinished,
+			Time:            lastBuild.FinishTime,
+			Resource:        mn.String(),
+			DurationSeconds: lastBuild.Duration().Seconds(),
+		}
+		if lastBuild.Error != nil {
+			e.Error = lastBuild.Error.Error()
+		}
+		result = append(result, e)
+	}
+
+	currentBuild := ms.CurrentBuild
+	if !currentBuild.Empty() && !currentBuild.StartTime.Equal(h.buildStarts[mn]) {
+		h.buildStarts[mn] = currentBuild.StartTime
+		result = append(result, JSONEvent{
+			Type:     JSONEventBuildStarted,
+			Time:     currentBuild.StartTime,
+			Resource: mn.String(),
+			Reason:   currentBuild.Reason.String(),
+		})
+	}
+
+	return result
+}
+
+func (h *JSONStream) runtimeEvents(mt *store.ManifestTarget) []JSONEvent {
+	status := model.RuntimeStatusUnknown
+	if mt.State.RuntimeState != nil {
+		status = mt.State.RuntimeState.RuntimeStatus()
+	}
+
+	mn := mt.Manifest.Name
+	lastStatus, ok := h.statuses[mn]
+	if ok && lastStatus == status {
+		return nil
+	}
+	h.statuses[mn] = status
+	return []JSONEvent{{
+		Type:          JSONEventRuntimeStatus,
+		Time:          time.Now(),
+		Resource:      mn.String(),
+		RuntimeStatus: status,
+	}}
+}
+
+func jsonLogLevel(level logger.Level) string {
+	switch level {
+	case logger.DebugLvl:
+		return "debug"
+	case logger.VerboseLvl:
+		return "verbose"
+	case logger.WarnLvl:
+		return "warn"
+	case logger.ErrorLvl:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+var _ store.TearDowner = &JSONStream{}
@@ -0,0 +1,161 @@
+package hud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestJSONStreamLogs(t *testing.T) {
+	f := newJSONStreamFixture(t, store.TerminalModeJSON)
+
+	f.log("fe", logger.InfoLvl, "hello\n")
+	f.log("", logger.WarnLvl, "careful\n")
+	events := f.onChange()
+
+	logs := f.ofType(events, JSONEventLog)
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, "fe", logs[0].Resource)
+	assert.Equal(t, "info", logs[0].Level)
+	assert.Equal(t, "hello\n", logs[0].Text)
+	assert.Equal(t, "", logs[1].Resource)
+	assert.Equal(t, "warn", logs[1].Level)
+
+	// Logs are only written once.
+	assert.Empty(t, f.ofType(f.onChange(), JSONEventLog))
+}
+
+func TestJSONStreamBuilds(t *testing.T) {
+	f := newJSONStreamFixture(t, store.TerminalModeJSON)
+
+	start := time.Now()
+	f.startBuild("fe", start)
+	events := f.ofType(f.onChange(), JSONEventBuildStarted)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "fe", events[0].Resource)
+
+	f.completeBuild("fe", start, fmt.Errorf("oh no"))
+	events = f.onChange()
+	assert.Empty(t, f.ofType(events, JSONEventBuildStarted))
+	finished := f.ofType(events, JSONEventBuildFinished)
+	require.Equal(t, 1, len(finished))
+	assert.Equal(t, "fe", finished[0].Resource)
+	assert.Equal(t, "oh no", finished[0].Error)
+
+	assert.Empty(t, f.ofType(f.onChange(), JSONEventBuildFinished))
+}
+
+func TestJSONStreamRuntimeStatus(t *testing.T) {
+	f := newJSONStreamFixture(t, store.TerminalModeJSON)
+
+	events := f.ofType(f.onChange(), JSONEventRuntimeStatus)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, model.RuntimeStatusPending, events[0].RuntimeStatus)
+
+	assert.Empty(t, f.ofType(f.onChange(), JSONEventRuntimeStatus))
+
+	f.setRuntimeStatus("fe", model.RuntimeStatusOK)
+	events = f.ofType(f.onChange(), JSONEventRuntimeStatus)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, model.RuntimeStatusOK, events[0].RuntimeStatus)
+}
+
+func TestJSONStreamDisabled(t *testing.T) {
+	f := newJSONStreamFixture(t, store.TerminalModeStream)
+
+	f.log("fe", logger.InfoLvl, "hello\n")
+	assert.Empty(t, f.onChange())
+}
+
+type jsonStreamFixture struct {
+	t   *testing.T
+	out *bytes.Buffer
+	st  *store.TestingStore
+	js  *JSONStream
+}
+
+func newJSONStreamFixture(t *testing.T, mode store.TerminalMode) *jsonStreamFixture {
+	out := &bytes.Buffer{}
+	st := store.NewTestingStore()
+	state := st.LockMutableStateForTesting()
+	state.TerminalMode = mode
+	mt := store.NewManifestTarget(model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{}))
+	mt.State.RuntimeState = store.LocalRuntimeState{Status: model.RuntimeStatusPending}
+	state.UpsertManifestTarget(mt)
+	st.UnlockMutableState()
+
+	return &jsonStreamFixture{
+		t:   t,
+		out: out,
+		st:  st,
+		js:  NewJSONStream(Stdout(out), st),
+	}
+}
+
+func (f *jsonStreamFixture) log(mn model.ManifestName, level logger.Level, msg string) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	spanID := model.LogSpanID(fmt.Sprintf("span:%s", mn))
+	state.LogStore.Append(store.NewLogAction(mn, spanID, level, nil, []byte(msg)), nil)
+}
+
+func (f *jsonStreamFixture) startBuild(mn model.ManifestName, start time.Time) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	ms, _ := state.ManifestState(mn)
+	ms.CurrentBuild = model.BuildRecord{StartTime: start, Reason: model.BuildReasonFlagInit}
+}
+
+func (f *jsonStreamFixture) completeBuild(mn model.ManifestName, start time.Time, err error) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	ms, _ := state.ManifestState(mn)
+	ms.CurrentBuild = model.BuildRecord{}
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: start, FinishTime: time.Now(), Error: err})
+}
+
+func (f *jsonStreamFixture) setRuntimeStatus(mn model.ManifestName, status model.RuntimeStatus) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	ms, _ := state.ManifestState(mn)
+	ms.RuntimeState = store.LocalRuntimeState{Status: status}
+}
+
+// Returns the events written since the last call.
+func (f *jsonStreamFixture) onChange() []JSONEvent {
+	f.js.OnChange(context.Background(), f.st)
+
+	var result []JSONEvent
+	for _, line := range strings.Split(strings.TrimSpace(f.out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e JSONEvent
+		err := json.Unmarshal([]byte(line), &e)
+		require.NoError(f.t, err, line)
+		result = append(result, e)
+	}
+	f.out.Reset()
+	return result
+}
+
+func (f *jsonStreamFixture) ofType(events []JSONEvent, t string) []JSONEvent {
+	var result []JSONEvent
+	for _, e := range events {
+		if e.Type == t {
+			result = append(result, e)
+		}
+	}
+	return result
+}
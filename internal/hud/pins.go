@@ -0,0 +1,79 @@
+package hud
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// More panes than this don't fit side by side in most terminals.
+const maxPinnedResources = 3
+
+const pinsFile = "hud_pins.json"
+
+// Remembers which resources the user pinned in the HUD for each Tiltfile,
+// so that the split panes come back when they restart Tilt.
+//
+// A nil PinStore doesn't remember anything.
+type PinStore struct {
+	dir *dirs.WindmillDir
+}
+
+func NewPinStore(dir *dirs.WindmillDir) *PinStore {
+	return &PinStore{dir: dir}
+}
+
+// The pinned resources for the Tiltfile. Returns nothing if
+// the pins can't be read, since they're only a convenience.
+func (s *PinStore) Load(tiltfilePath string) []model.ManifestName {
+	if s == nil {
+		return nil
+	}
+	pins, err := s.read()
+	if err != nil {
+		return nil
+	}
+	return pins[tiltfilePath]
+}
+
+func (s *PinStore) Save(tiltfilePath string, pinned []model.ManifestName) error {
+	if s == nil {
+		return nil
+	}
+	pins, err := s.read()
+	if err != nil {
+		// Start over rather than fail forever on a corrupt file.
+		pins = make(map[string][]model.ManifestName)
+	}
+
+	if len(pinned) == 0 {
+		delete(pins, tiltfilePath)
+	} else {
+		pins[tiltfilePath] = pinned
+	}
+
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.dir.WriteFile(pinsFile, string(data))
+}
+
+func (s *PinStore) read() (map[string][]model.ManifestName, error) {
+	pins := make(map[string][]model.ManifestName)
+	data, err := s.dir.ReadFile(pinsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pins, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(data), &pins)
+	if err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
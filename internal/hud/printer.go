@@ -28,6 +28,10 @@ func (p *IncrementalPrinter) PrintNewline() {
 	_, _ = io.WriteString(p.stdout, "\n")
 }
 
+func (p *IncrementalPrinter) PrintString(s string) {
+	_, _ = io.WriteString(p.stdout, s)
+}
+
 func (p *IncrementalPrinter) Print(lines []logstore.LogLine) {
 	for _, line := range lines {
 		// Naive progress implementation: skip lines that have already been printed
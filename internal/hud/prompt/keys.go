@@ -0,0 +1,140 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rebinds the prompt's keys, e.g., TILT_PROMPT_KEYS="browser=o,hud=h,quit=q"
+//
+// Useful when a key is already taken by your terminal or IDE.
+const KeysEnvVar = "TILT_PROMPT_KEYS"
+
+type KeyAction string
+
+const (
+	KeyActionBrowser KeyAction = "browser"
+	KeyActionStream  KeyAction = "stream"
+	KeyActionHUD     KeyAction = "hud"
+	KeyActionRelink  KeyAction = "relink"
+	KeyActionQuit    KeyAction = "quit"
+)
+
+var keyActions = []KeyAction{KeyActionBrowser, KeyActionStream, KeyActionHUD, KeyActionRelink, KeyActionQuit}
+
+// The keys for each action in the prompt. The first key is the one we show.
+//
+// An action with no keys is unbound. (ctrl-c always exits.)
+type KeyBindings map[KeyAction][]rune
+
+func DefaultKeyBindings() KeyBindings {
+	return KeyBindings{
+		KeyActionBrowser: {' '},
+		KeyActionStream:  {'s'},
+		KeyActionHUD:     {'t', 'h'},
+		KeyActionRelink:  {'r'},
+	}
+}
+
+func ProvideKeyBindings() (KeyBindings, error) {
+	keys, err := ParseKeyBindings(os.Getenv(KeysEnvVar))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", KeysEnvVar, err)
+	}
+	return keys, nil
+}
+
+// Parses a comma-separated list of action=key pairs on top of the defaults.
+//
+// The key is a single character, or "space". An empty key unbinds the action.
+func ParseKeyBindings(s string) (KeyBindings, error) {
+	keys := DefaultKeyBindings()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected action=key, got %q", pair)
+		}
+
+		action := KeyAction(strings.TrimSpace(parts[0]))
+		if !isKeyAction(action) {
+			return nil, fmt.Errorf("unknown action %q (must be one of: %s)", action, keyActionNames())
+		}
+
+		key := strings.TrimSpace(parts[1])
+		switch {
+		case key == "":
+			keys[action] = nil
+		case key == "space":
+			keys[action] = []rune{' '}
+		case utf8.RuneCountInString(key) == 1:
+			r, _ := utf8.DecodeRuneInString(key)
+			keys[action] = []rune{r}
+		default:
+			return nil, fmt.Errorf("key for %q must be a single character or \"space\", got %q", action, key)
+		}
+	}
+
+	seen := make(map[rune]KeyAction)
+	for _, action := range keyActions {
+		for _, r := range keys[action] {
+			other, ok := seen[r]
+			if ok {
+				return nil, fmt.Errorf("%s and %s are both bound to %s", other, action, keyName(r))
+			}
+			seen[r] = action
+		}
+	}
+	return keys, nil
+}
+
+// The action bound to the key, or "" if there isn't one.
+func (k KeyBindings) ActionFor(r rune) KeyAction {
+	for action, keys := range k {
+		for _, key := range keys {
+			if key == r {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// The key to show for the action, e.g., "(space)". False if the action is unbound.
+func (k KeyBindings) Label(action KeyAction) (string, bool) {
+	keys := k[action]
+	if len(keys) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("(%s)", keyName(keys[0])), true
+}
+
+func keyName(r rune) string {
+	if r == ' ' {
+		return "space"
+	}
+	return string(r)
+}
+
+func isKeyAction(action KeyAction) bool {
+	for _, a := range keyActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func keyActionNames() string {
+	names := make([]string, len(keyActions))
+	for i, a := range keyActions {
+		names[i] = string(a)
+	}
+	return strings.Join(names, ", ")
+}
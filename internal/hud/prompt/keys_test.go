@@ -0,0 +1,51 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyBindingsDefaults(t *testing.T) {
+	keys, err := ParseKeyBindings("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultKeyBindings(), keys)
+	assert.Equal(t, KeyActionHUD, keys.ActionFor('h'))
+	assert.Equal(t, KeyAction(""), keys.ActionFor('q'))
+
+	label, ok := keys.Label(KeyActionBrowser)
+	assert.True(t, ok)
+	assert.Equal(t, "(space)", label)
+}
+
+func TestParseKeyBindingsOverrides(t *testing.T) {
+	keys, err := ParseKeyBindings("browser=o, hud=space, relink=, quit=q")
+	require.NoError(t, err)
+	assert.Equal(t, KeyActionBrowser, keys.ActionFor('o'))
+	assert.Equal(t, KeyActionHUD, keys.ActionFor(' '))
+	assert.Equal(t, KeyAction(""), keys.ActionFor('t'))
+	assert.Equal(t, KeyActionQuit, keys.ActionFor('q'))
+
+	_, ok := keys.Label(KeyActionRelink)
+	assert.False(t, ok)
+}
+
+func TestParseKeyBindingsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{"browser", `expected action=key, got "browser"`},
+		{"open=o", `unknown action "open"`},
+		{"quit=esc", `key for "quit" must be a single character or "space", got "esc"`},
+		{"quit=s", "stream and quit are both bound to s"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := ParseKeyBindings(tc.input)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
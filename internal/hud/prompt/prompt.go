@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 	"sync"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -41,10 +43,17 @@ type TerminalPrompt struct {
 	stdout    hud.Stdout
 	host      model.WebHost
 	url       model.WebURL
+	keys      KeyBindings
 
 	printed bool
 	term    TerminalInput
 
+	// Whether we've told the user that their Tilt Cloud token expired.
+	printedTokenExpired bool
+
+	// Whether we've told the user that the cluster rejected their credentials.
+	printedK8sCredentialsRejected bool
+
 	// Make sure that Close() completes both during the teardown sequence and when
 	// we switch modes.
 	closeOnce sync.Once
@@ -54,7 +63,7 @@ type TerminalPrompt struct {
 
 func NewTerminalPrompt(a *analytics.TiltAnalytics, openInput OpenInput,
 	openURL OpenURL, stdout hud.Stdout,
-	host model.WebHost, url model.WebURL) *TerminalPrompt {
+	host model.WebHost, url model.WebURL, keys KeyBindings) *TerminalPrompt {
 	return &TerminalPrompt{
 		a:         a,
 		openInput: openInput,
@@ -62,6 +71,7 @@ func NewTerminalPrompt(a *analytics.TiltAnalytics, openInput OpenInput,
 		stdout:    stdout,
 		host:      host,
 		url:       url,
+		keys:      keys,
 	}
 }
 
@@ -95,6 +105,51 @@ func (p *TerminalPrompt) isEnabled(st store.RStore) bool {
 	return state.TerminalMode == store.TerminalModePrompt
 }
 
+func (p *TerminalPrompt) isTokenExpired(st store.RStore) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+	return state.CloudStatus.TokenExpired
+}
+
+// The page on the Tilt web server that links the token to Tilt Cloud again.
+func (p *TerminalPrompt) relinkURL() string {
+	u := url.URL(p.url)
+	u.Path = server.RelinkTiltCloudTokenPath
+	return u.String()
+}
+
+// If Tilt Cloud tells us the token expired after we've shown the prompt,
+// tell the user how to fix it.
+func (p *TerminalPrompt) maybePrintTokenExpired(st store.RStore) {
+	if p.url.Empty() {
+		return
+	}
+
+	expired := p.isTokenExpired(st)
+	if expired && !p.printedTokenExpired {
+		_, _ = fmt.Fprintf(p.stdout, "Your Tilt Cloud token has expired.\n")
+		if label, ok := p.keys.Label(KeyActionRelink); ok {
+			_, _ = fmt.Fprintf(p.stdout, "%s to link Tilt to Tilt Cloud again in the browser\n", label)
+		}
+	}
+	p.printedTokenExpired = expired
+}
+
+// If the cluster starts rejecting the credentials from the kubeconfig's exec
+// credential plugin, tell the user how to log in again. The plugin can't prompt
+// for a login while we own the terminal.
+func (p *TerminalPrompt) maybePrintK8sCredentialsRejected(st store.RStore) {
+	state := st.RLockState()
+	creds := state.K8sCredentials
+	st.RUnlockState()
+
+	if creds.Rejected && !p.printedK8sCredentialsRejected {
+		_, _ = fmt.Fprintf(p.stdout, "Kubernetes rejected your credentials. To log in again, run this in another terminal:\n")
+		_, _ = fmt.Fprintf(p.stdout, "  %s\n", creds.ReauthCommand)
+	}
+	p.printedK8sCredentialsRejected = creds.Rejected
+}
+
 func (p *TerminalPrompt) TearDown(ctx context.Context) {
 	if p.term != nil {
 		p.closeOnce.Do(func() {
@@ -109,6 +164,8 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 	}
 
 	if p.printed {
+		p.maybePrintTokenExpired(st)
+		p.maybePrintK8sCredentialsRejected(st)
 		return
 	}
 
@@ -134,19 +191,28 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 	}
 
 	hasBrowserUI := !p.url.Empty()
-	if hasBrowserUI {
-		_, _ = fmt.Fprintf(p.stdout, "(space) to open the browser\n")
+	if label, ok := p.keys.Label(KeyActionBrowser); ok && hasBrowserUI {
+		_, _ = fmt.Fprintf(p.stdout, "%s to open the browser\n", label)
+	}
+	if label, ok := p.keys.Label(KeyActionStream); ok {
+		_, _ = fmt.Fprintf(p.stdout, "%s to stream logs (--stream=true)\n", label)
+	}
+	if label, ok := p.keys.Label(KeyActionHUD); ok {
+		_, _ = fmt.Fprintf(p.stdout, "%s to open legacy terminal mode (--legacy=true)\n", label)
+	}
+	if label, ok := p.keys.Label(KeyActionQuit); ok {
+		_, _ = fmt.Fprintf(p.stdout, "%s or (ctrl-c) to exit\n", label)
+	} else {
+		_, _ = fmt.Fprintf(p.stdout, "(ctrl-c) to exit\n")
 	}
-
-	_, _ = fmt.Fprintf(p.stdout, "(s) to stream logs (--stream=true)\n")
-	_, _ = fmt.Fprintf(p.stdout, "(t) to open legacy terminal mode (--legacy=true)\n")
-	_, _ = fmt.Fprintf(p.stdout, "(ctrl-c) to exit\n")
 
 	p.printed = true
+	p.maybePrintTokenExpired(st)
+	p.maybePrintK8sCredentialsRejected(st)
 
 	t, err := p.openInput()
 	if err != nil {
-		st.Dispatch(store.ErrorAction{Error: err})
+		p.fallBackToStream(st, err)
 		return
 	}
 	p.term = t
@@ -158,8 +224,10 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 		for ctx.Err() == nil {
 			r, err := t.ReadRune()
 			if err != nil {
-				st.Dispatch(store.ErrorAction{Error: err})
-				return
+				if ctx.Err() == nil {
+					p.fallBackToStream(st, err)
+				}
+				break
 			}
 
 			msg := runeMessage{
@@ -195,20 +263,24 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 					return
 				}
 
-				r := msg.rune
-				switch r {
-				case 's':
+				switch p.keys.ActionFor(msg.rune) {
+				case KeyActionStream:
 					p.a.Incr("ui.prompt.switch", map[string]string{"type": "stream"})
 					st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeStream})
 					msg.stopCh <- true
 
-				case 't', 'h':
+				case KeyActionHUD:
 					p.a.Incr("ui.prompt.switch", map[string]string{"type": "hud"})
 					st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeHUD})
 
 					msg.stopCh <- true
 
-				case ' ':
+				case KeyActionQuit:
+					p.a.Incr("ui.prompt.quit", map[string]string{})
+					st.Dispatch(hud.NewExitAction(nil))
+					msg.stopCh <- true
+
+				case KeyActionBrowser:
 					p.a.Incr("ui.prompt.browser", map[string]string{})
 					_, _ = fmt.Fprintf(p.stdout, "Opening browser: %s\n", p.url.String())
 					err := p.openURL(p.url.String())
@@ -216,6 +288,20 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
 					}
 					msg.stopCh <- false
+				case KeyActionRelink:
+					if !hasBrowserUI || !p.isTokenExpired(st) {
+						msg.stopCh <- false
+						continue
+					}
+
+					p.a.Incr("ui.prompt.relink", map[string]string{})
+					relinkURL := p.relinkURL()
+					_, _ = fmt.Fprintf(p.stdout, "Opening browser: %s\n", relinkURL)
+					err := p.openURL(relinkURL)
+					if err != nil {
+						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
+					}
+					msg.stopCh <- false
 				default:
 					msg.stopCh <- false
 
@@ -225,6 +311,16 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 	}()
 }
 
+// Some terminals look like TTYs but won't give us keyboard input
+// (e.g., some IDE terminals, or tmux panes fed by a pipe).
+// The prompt is useless there, so stream logs instead, and say why.
+func (p *TerminalPrompt) fallBackToStream(st store.RStore, err error) {
+	p.a.Incr("ui.prompt.fallback", map[string]string{})
+	_, _ = fmt.Fprintf(p.stdout, "\nCan't read keyboard input from this terminal (%v)\n", err)
+	_, _ = fmt.Fprintf(p.stdout, "Streaming logs instead. To skip the prompt, run 'tilt up --stream'\n\n")
+	st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeStream})
+}
+
 type runeMessage struct {
 	rune rune
 
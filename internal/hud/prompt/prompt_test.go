@@ -3,13 +3,16 @@ package prompt
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
@@ -70,6 +73,76 @@ func TestInitOutput(t *testing.T) {
 (space) to open the browser`)
 }
 
+func TestRelinkExpiredToken(t *testing.T) {
+	f := newFixture()
+	defer f.TearDown()
+
+	f.prompt.OnChange(f.ctx, f.st)
+	assert.NotContains(t, f.out.String(), "(r)")
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.CloudStatus.TokenExpired = true
+	})
+	f.prompt.OnChange(f.ctx, f.st)
+	assert.Contains(t, f.out.String(), "Your Tilt Cloud token has expired.\n(r) to link Tilt")
+
+	f.input.nextRune <- 'r'
+	assert.Equal(t, "http://localhost:10350/api/tilt_cloud/relink", f.b.WaitForURL(t))
+}
+
+func TestK8sCredentialsRejected(t *testing.T) {
+	f := newFixture()
+	defer f.TearDown()
+
+	f.prompt.OnChange(f.ctx, f.st)
+	assert.NotContains(t, f.out.String(), "Kubernetes rejected")
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.K8sCredentials = store.K8sCredentialsStatus{
+			Rejected:      true,
+			ReauthCommand: "kubectl --context eks get namespaces",
+		}
+	})
+	f.prompt.OnChange(f.ctx, f.st)
+	f.prompt.OnChange(f.ctx, f.st)
+	assert.Equal(t, 1, strings.Count(f.out.String(), "Kubernetes rejected your credentials"))
+	assert.Contains(t, f.out.String(), "  kubectl --context eks get namespaces\n")
+}
+
+func TestCustomKeyBindings(t *testing.T) {
+	keys, err := ParseKeyBindings("stream=l,quit=q")
+	assert.NoError(t, err)
+	f := newFixtureWithKeys(keys)
+	defer f.TearDown()
+
+	f.prompt.OnChange(f.ctx, f.st)
+
+	assert.Contains(t, f.out.String(), "(l) to stream logs")
+	assert.Contains(t, f.out.String(), "(q) or (ctrl-c) to exit")
+
+	f.input.nextRune <- 's'
+	f.input.nextRune <- 'q'
+
+	action := f.st.WaitForAction(t, reflect.TypeOf(hud.ExitAction{}))
+	assert.Equal(t, hud.NewExitAction(nil), action)
+	assert.Empty(t, f.st.Actions()[:len(f.st.Actions())-1])
+}
+
+func TestFallBackToStreamWithoutTTY(t *testing.T) {
+	f := newFixture()
+	defer f.TearDown()
+
+	f.prompt.openInput = func() (TerminalInput, error) {
+		return nil, fmt.Errorf("open /dev/tty: no such device or address")
+	}
+	f.prompt.OnChange(f.ctx, f.st)
+
+	action := f.st.WaitForAction(t, reflect.TypeOf(SwitchTerminalModeAction{}))
+	assert.Equal(t, SwitchTerminalModeAction{Mode: store.TerminalModeStream}, action)
+	assert.Contains(t, f.out.String(),
+		"Can't read keyboard input from this terminal (open /dev/tty: no such device or address)\nStreaming logs instead")
+}
+
 type fixture struct {
 	ctx    context.Context
 	cancel func()
@@ -81,6 +154,10 @@ type fixture struct {
 }
 
 func newFixture() *fixture {
+	return newFixtureWithKeys(DefaultKeyBindings())
+}
+
+func newFixtureWithKeys(keys KeyBindings) *fixture {
 	ctx, _, ta := testutils.CtxAndAnalyticsForTest()
 	ctx, cancel := context.WithCancel(ctx)
 	out := bufsync.NewThreadSafeBuffer()
@@ -94,7 +171,7 @@ func newFixture() *fixture {
 
 	url, _ := url.Parse(FakeURL)
 
-	prompt := NewTerminalPrompt(ta, openInput, b.OpenURL, out, "localhost", model.WebURL(*url))
+	prompt := NewTerminalPrompt(ta, openInput, b.OpenURL, out, "localhost", model.WebURL(*url), keys)
 	return &fixture{
 		ctx:    ctx,
 		cancel: cancel,
@@ -6,5 +6,6 @@ import (
 
 var WireSet = wire.NewSet(
 	NewTerminalPrompt,
+	ProvideKeyBindings,
 	wire.Value(OpenInput(TTYOpen)),
 	wire.Value(OpenURL(BrowserOpen)))
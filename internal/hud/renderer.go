@@ -54,18 +54,85 @@ func (r *Renderer) layout(v view.View, vs view.ViewState) rty.Component {
 
 	l.Add(r.renderResourceHeader(v))
 	l.Add(r.renderResources(v, vs))
-	l.Add(r.renderLogPane(v, vs))
+	if len(pinnedResources(v, vs)) > 0 {
+		l.Add(r.renderPinnedPanes(v, vs))
+	} else {
+		l.Add(r.renderLogPane(v, vs))
+	}
 	l.Add(r.renderFooter(v, keyLegend(v, vs)))
 
 	var ret rty.Component = l
 
 	ret = r.maybeAddFullScreenLog(v, vs, ret)
 
+	ret = r.maybeAddAlertCenter(v, vs, ret)
+
+	ret = r.maybeAddBuildQueue(v, vs, ret)
+
 	ret = r.maybeAddAlertModal(v, vs, ret)
 
 	return ret
 }
 
+func (r *Renderer) maybeAddAlertCenter(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
+	if !vs.ShowAlertCenter {
+		return layout
+	}
+
+	sl := rty.NewTextScrollLayout(alertCenterScrollerName)
+	if len(v.Alerts) == 0 {
+		sl.Add(rty.TextString("No alerts"))
+	}
+	for _, a := range v.Alerts {
+		sb := rty.NewStringBuilder()
+		if a.Severity == model.AlertSeverityError {
+			sb.Fg(cBad).Text(xMark())
+		} else {
+			sb.Fg(cPending).Text("⚠")
+		}
+		sb.Fg(tcell.ColorDefault).Text(" ")
+		if a.ManifestName != "" {
+			sb.Textf("%s: ", a.ManifestName)
+		}
+		sb.Text(a.Message)
+		if a.Count > 1 {
+			sb.Fg(cLightText).Textf(" (×%d, last seen %s ago)", a.Count, formatDeployAge(r.clock().Sub(a.LastSeen)))
+		}
+		sl.Add(sb.Build())
+	}
+
+	w := rty.NewWindow(sl)
+	w.SetTitle(fmt.Sprintf(" Alerts (%d) ", len(v.Alerts)))
+	return r.renderModal(w, layout, false)
+}
+
+// Lists the resources waiting to build, and why, so that you can tell
+// why your change hasn't built yet.
+func (r *Renderer) maybeAddBuildQueue(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
+	if !vs.ShowBuildQueue {
+		return layout
+	}
+
+	sl := rty.NewTextScrollLayout(buildQueueScrollerName)
+	if len(v.BuildQueue) == 0 {
+		sl.Add(rty.TextString("Nothing waiting to build"))
+	}
+	for i, qb := range v.BuildQueue {
+		sb := rty.NewStringBuilder()
+		sb.Fg(cLightText).Textf("%d. ", i+1)
+		sb.Fg(tcell.ColorDefault).Textf("%s ", qb.Name)
+		sb.Fg(cLightText).Textf("(%s)", qb.Reason)
+		if qb.HoldDescription != "" {
+			sb.Fg(cPending).Textf(" — %s", qb.HoldDescription)
+		}
+		sl.Add(sb.Build())
+	}
+
+	w := rty.NewWindow(sl)
+	w.SetTitle(fmt.Sprintf(" Build Queue (%d) ", len(v.BuildQueue)))
+	return r.renderModal(w, layout, false)
+}
+
 func (r *Renderer) maybeAddFullScreenLog(v view.View, vs view.ViewState, layout rty.Component) rty.Component {
 	if vs.TiltLogState == view.TiltLogFullScreen {
 		tabView := NewTabView(v, vs)
@@ -107,18 +174,50 @@ func (r *Renderer) maybeAddAlertModal(v view.View, vs view.ViewState, layout rty
 
 func (r *Renderer) renderLogPane(v view.View, vs view.ViewState) rty.Component {
 	tabView := NewTabView(v, vs)
-	var height int
+	return rty.NewFixedSize(tabView.Build(), rty.GROW, logPaneHeight(vs))
+}
+
+func logPaneHeight(vs view.ViewState) int {
 	switch vs.TiltLogState {
-	case view.TiltLogShort:
-		height = defaultLogPaneHeight
 	case view.TiltLogHalfScreen:
-		height = rty.GROW
+		return rty.GROW
 	case view.TiltLogFullScreen:
-		height = 1
 		// FullScreen is handled elsewhere, since it's no longer a pane
 		// but we have to set height to something non-0 or rty will blow up
+		return 1
+	default:
+		return defaultLogPaneHeight
+	}
+}
+
+// The pinned resources that are still in the Tiltfile.
+func pinnedResources(v view.View, vs view.ViewState) []model.ManifestName {
+	var result []model.ManifestName
+	for _, mn := range vs.PinnedResources {
+		if _, ok := v.Resource(mn); ok {
+			result = append(result, mn)
+		}
+	}
+	return result
+}
+
+// Shows the logs of each pinned resource side by side, in place of the log tabs.
+func (r *Renderer) renderPinnedPanes(v view.View, vs view.ViewState) rty.Component {
+	l := rty.NewFlexLayout(rty.DirHor)
+	for _, mn := range pinnedResources(v, vs) {
+		log := v.LogReader.TailManifestWithSettings(logLineCount, mn, v.LogSettings)
+		if log == "" {
+			log = "(no logs received)"
+		}
+		sl := rty.NewTextScrollLayout(pinnedScrollerName(mn))
+		sl.Add(rty.TextString(log))
+
+		box := rty.NewGrowingBox()
+		box.SetInner(sl)
+		box.SetTitle(fmt.Sprintf(" %s ", mn))
+		l.Add(box)
 	}
-	return rty.NewFixedSize(tabView.Build(), rty.GROW, height)
+	return rty.NewFixedSize(l, rty.GROW, logPaneHeight(vs))
 }
 
 func renderPaneHeader(isMax bool) rty.Component {
@@ -168,6 +267,9 @@ func (r *Renderer) renderStatusBar(v view.View) rty.Component {
 	l.Add(rty.TextString(" "))
 	l.Add(r.renderStatusMessage(v))
 	l.Add(rty.TextString(" "))
+	if len(v.Alerts) > 0 {
+		l.Add(renderAlertBadge(v.Alerts))
+	}
 	l.AddDynamic(rty.NewFillerString(' '))
 
 	msg := " To explore, open web view (enter) • terminal is limited "
@@ -175,6 +277,21 @@ func (r *Renderer) renderStatusBar(v view.View) rty.Component {
 	return rty.Bg(rty.OneLine(l), tcell.ColorWhiteSmoke)
 }
 
+func renderAlertBadge(alerts []model.Alert) rty.Component {
+	color := cPending
+	for _, a := range alerts {
+		if a.Severity == model.AlertSeverityError {
+			color = cBad
+		}
+	}
+
+	s := "alert"
+	if len(alerts) > 1 {
+		s = "alerts"
+	}
+	return rty.NewStringBuilder().Fg(color).Text("⚠").Fg(cText).Textf(" %d %s (a) ", len(alerts), s).Build()
+}
+
 func (r *Renderer) renderFooter(v view.View, keys string) rty.Component {
 	footer := rty.NewConcatLayout(rty.DirVert)
 	footer.Add(r.renderStatusBar(v))
@@ -188,10 +305,29 @@ func (r *Renderer) renderFooter(v view.View, keys string) rty.Component {
 }
 
 func keyLegend(v view.View, vs view.ViewState) string {
-	defaultKeys := "Browse (↓ ↑), Expand (→) ┊ (enter) log ┊ (ctrl-C) quit  "
+	pin := "(p) pin"
+	if _, selected := selectedResource(v, vs); vs.IsPinned(selected.Name) {
+		pin = "(p) unpin"
+	}
+	defaultKeys := "Browse (↓ ↑), Expand (→) ┊ (enter) log ┊ " + pin + " ┊ (ctrl-C) quit  "
 	if vs.AlertMessage != "" {
 		return "Tilt (l)og ┊ (esc) close alert "
 	}
+	if vs.ShowAlertCenter {
+		return "Browse (↓ ↑) ┊ (esc) acknowledge and close "
+	}
+	if vs.ShowBuildQueue {
+		if len(v.BuildQueue) > 0 {
+			return "Browse (↓ ↑) ┊ (c) clear queue ┊ (esc) close "
+		}
+		return "(esc) close "
+	}
+	if len(v.BuildQueue) > 0 {
+		defaultKeys = "b(u)ild queue ┊ " + defaultKeys
+	}
+	if len(v.Alerts) > 0 {
+		return "(a) alerts ┊ " + defaultKeys
+	}
 	return defaultKeys
 }
 
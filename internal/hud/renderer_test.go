@@ -286,6 +286,28 @@ func TestRenderTiltLog(t *testing.T) {
 	rtf.run("tilt log full screen", 70, 20, v, vs)
 }
 
+func TestRenderPinnedResources(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := newView(
+		view.Resource{Name: "frontend", ResourceInfo: view.K8sResourceInfo{}},
+		view.Resource{Name: "backend", ResourceInfo: view.K8sResourceInfo{}},
+		view.Resource{Name: "worker", ResourceInfo: view.K8sResourceInfo{}},
+	)
+	logStore := logstore.NewLogStore()
+	appendSpanLog(logStore, "frontend", "frontend:1", "compiled successfully\n")
+	appendSpanLog(logStore, "backend", "backend:1", "listening on :8080\nGET /api/users 200\n")
+	v.LogReader = logstore.NewReader(&sync.RWMutex{}, logStore)
+
+	vs := fakeViewState(3, view.CollapseAuto)
+	vs.PinnedResources = []model.ManifestName{"frontend", "backend"}
+	rtf.run("pinned resources", 80, 20, v, vs)
+
+	vs.PinnedResources = []model.ManifestName{"frontend", "backend", "worker"}
+	vs.TiltLogState = view.TiltLogHalfScreen
+	rtf.run("pinned resources half screen", 80, 20, v, vs)
+}
+
 func TestRenderNarrationMessage(t *testing.T) {
 	rtf := newRendererTestFixture(t)
 
@@ -502,6 +524,37 @@ func TestCompletedPod(t *testing.T) {
 	rtf.run("Completed is a good status", 70, 20, v, vs)
 }
 
+func TestPodFannedOutToNamespaces(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+	ts := time.Now().Add(-30 * time.Second)
+
+	v := newView(view.Resource{
+		Name: "vigoda",
+		BuildHistory: []model.BuildRecord{{
+			SpanID:     "vigoda:1",
+			StartTime:  ts,
+			FinishTime: ts,
+		}},
+		ResourceInfo: view.K8sResourceInfo{
+			PodName:            "vigoda-pod",
+			PodStatus:          "Running",
+			RunStatus:          model.RuntimeStatusError,
+			PodUpdateStartTime: ts,
+			PodCreationTime:    ts.Add(-time.Minute),
+			Namespaces: []view.NamespaceStatus{
+				{Namespace: "tenant-a", PodName: "vigoda-pod", RunStatus: model.RuntimeStatusOK},
+				{Namespace: "tenant-b", PodName: "vigoda-pod", RunStatus: model.RuntimeStatusError},
+				{Namespace: "tenant-c", RunStatus: model.RuntimeStatusPending},
+			},
+		},
+		LastDeployTime: ts,
+	})
+	v.LogReader = newSpanLogReader("vigoda", "vigoda:1",
+		"Building (1/2)\nBuilding (2/2)\n")
+	vs := fakeViewState(1, view.CollapseNo)
+	rtf.run("pod fanned out to namespaces", 70, 20, v, vs)
+}
+
 func TestBrackets(t *testing.T) {
 	rtf := newRendererTestFixture(t)
 	ts := time.Now().Add(-30 * time.Second)
@@ -877,6 +930,64 @@ func TestLocalResourceErroredServe(t *testing.T) {
 	rtf.run("local resource errored serve", 80, 20, v, vs)
 }
 
+func TestAlertCenter(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := newView(view.Resource{
+		Name:         "vigoda",
+		ResourceInfo: view.K8sResourceInfo{},
+	})
+	v.Alerts = []model.Alert{
+		{
+			ID:           "crash-loop:vigoda:vigoda-pod",
+			Source:       model.AlertSourceCrashLoop,
+			Severity:     model.AlertSeverityError,
+			ManifestName: "vigoda",
+			Message:      "Container main on pod vigoda-pod is crash looping (3 restarts)",
+			LastSeen:     clockForTest().Add(-time.Minute),
+			Count:        3,
+		},
+		{
+			ID:       "image-injection",
+			Source:   model.AlertSourceImageInjection,
+			Severity: model.AlertSeverityWarning,
+			Message:  "Image not used in any deploy config: gcr.io/foo",
+			LastSeen: clockForTest(),
+			Count:    1,
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseAuto)
+	rtf.run("alert badge", 80, 20, v, vs)
+
+	vs.ShowAlertCenter = true
+	rtf.run("alert center", 80, 20, v, vs)
+}
+
+func TestBuildQueue(t *testing.T) {
+	rtf := newRendererTestFixture(t)
+
+	v := newView(view.Resource{
+		Name:         "vigoda",
+		ResourceInfo: view.K8sResourceInfo{},
+	})
+	v.BuildQueue = []view.QueuedBuild{
+		{Name: "vigoda", Reason: model.BuildReasonFlagChangedFiles},
+		{
+			Name:            "snack",
+			Reason:          model.BuildReasonFlagInit,
+			HoldDescription: store.HoldWaitingForDep.Description(),
+		},
+	}
+
+	vs := fakeViewState(1, view.CollapseAuto)
+	vs.ShowBuildQueue = true
+	rtf.run("build queue", 80, 20, v, vs)
+
+	v.BuildQueue = nil
+	rtf.run("build queue empty", 80, 20, v, vs)
+}
+
 type rendererTestFixture struct {
 	i rty.InteractiveTester
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/rty"
+	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
@@ -86,6 +87,10 @@ func combinedStatus(res view.Resource) statusDisplay {
 	lastBuild := res.LastBuild()
 	lastBuildError := lastBuild.Error != nil
 
+	if res.ConfigError != "" {
+		return statusDisplay{color: cBad}
+	}
+
 	if hasCurrentBuild {
 		return statusDisplay{color: cPending, spinner: true}
 	} else if hasPendingBuild {
@@ -287,6 +292,18 @@ func (v *ResourceView) endpointsNeedSecondLine() bool {
 }
 
 func (v *ResourceView) resourceExpandedK8s() rty.Component {
+	k8sInfo := v.res.K8sInfo()
+	if len(k8sInfo.Namespaces) == 0 {
+		return v.resourceExpandedK8sPod()
+	}
+
+	l := rty.NewConcatLayout(rty.DirVert)
+	l.Add(v.resourceExpandedK8sPod())
+	l.Add(resourceTextNamespaces(k8sInfo))
+	return l
+}
+
+func (v *ResourceView) resourceExpandedK8sPod() rty.Component {
 	k8sInfo := v.res.K8sInfo()
 	if k8sInfo.PodName == "" {
 		return rty.EmptyLayout
@@ -319,15 +336,36 @@ func resourceTextPodName(k8sInfo view.K8sResourceInfo) rty.Component {
 	return sb.Build()
 }
 
+// Shows the rollout in each namespace that the resource is fanned out to.
+func resourceTextNamespaces(k8sInfo view.K8sResourceInfo) rty.Component {
+	sb := rty.NewStringBuilder()
+	sb.Fg(cLightText).Text("NAMESPACES:")
+	for _, ns := range k8sInfo.Namespaces {
+		switch ns.RunStatus {
+		case model.RuntimeStatusOK:
+			sb.Fg(cGood).Text(" ●")
+		case model.RuntimeStatusError:
+			sb.Fg(cBad).Textf(" %s", xMark())
+		default:
+			sb.Fg(cPending).Text(" ○")
+		}
+		sb.Fg(tcell.ColorDefault).Textf(" %s", ns.Namespace)
+	}
+	return sb.Build()
+}
+
 func resourceTextPodRestarts(k8sInfo view.K8sResourceInfo) rty.Component {
 	s := "restarts"
 	if k8sInfo.PodRestarts == 1 {
 		s = "restart"
 	}
-	return rty.NewStringBuilder().
+	sb := rty.NewStringBuilder().
 		Fg(cPending).
-		Textf("%d %s", k8sInfo.PodRestarts, s).
-		Build()
+		Textf("%d %s", k8sInfo.PodRestarts, s)
+	if k8sInfo.LastTerminationReason == store.ContainerReasonOOMKilled {
+		sb.Fg(cBad).Textf(" (%s)", k8sInfo.LastTerminationReason)
+	}
+	return sb.Build()
 }
 
 func resourceTextAge(t time.Time) rty.Component {
@@ -415,8 +453,11 @@ func (v *ResourceView) resourceExpandedHistory() rty.Component {
 }
 
 func (v *ResourceView) resourceExpandedError() rty.Component {
-	errPane, ok := v.resourceExpandedBuildError()
+	errPane, ok := v.resourceExpandedConfigError()
 	isWarnings := false
+	if !ok {
+		errPane, ok = v.resourceExpandedBuildError()
+	}
 	if !ok {
 		errPane, ok = v.resourceExpandedRuntimeError()
 	}
@@ -449,6 +490,18 @@ func (v *ResourceView) resourceExpandedError() rty.Component {
 	return l
 }
 
+func (v *ResourceView) resourceExpandedConfigError() (rty.Component, bool) {
+	pane := rty.NewConcatLayout(rty.DirVert)
+	if v.res.ConfigError == "" {
+		return pane, false
+	}
+
+	for _, line := range abbreviateLog(fmt.Sprintf("Config error: %s", v.res.ConfigError)) {
+		pane.Add(rty.TextString(line))
+	}
+	return pane, true
+}
+
 func (v *ResourceView) resourceExpandedRuntimeError() (rty.Component, bool) {
 	pane := rty.NewConcatLayout(rty.DirVert)
 	ok := false
@@ -14,3 +14,14 @@ type SetTiltfileArgsAction struct {
 }
 
 func (SetTiltfileArgsAction) Action() {}
+
+// An external process called `tilt env` to change an environment variable.
+type SetEnvAction struct {
+	Name string
+
+	// Whether the variable is set, and a digest of its new value.
+	Set    bool
+	Digest string
+}
+
+func (SetEnvAction) Action() {}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/hud/apiview"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func (s *HeadsUpServer) apiObjects() apiview.Objects {
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+	return apiview.StateToObjects(state)
+}
+
+// Lists every object of a kind, or streams changes to them if ?watch=true.
+func (s *HeadsUpServer) ListAPIObjects(w http.ResponseWriter, req *http.Request) {
+	plural := mux.Vars(req)["kind"]
+	if _, ok := apiview.Kinds[plural]; !ok {
+		http.Error(w, fmt.Sprintf("unknown kind: %s", plural), http.StatusNotFound)
+		return
+	}
+
+	if req.URL.Query().Get("watch") == "true" {
+		s.watchAPIObjects(w, req, plural)
+		return
+	}
+
+	list, _ := s.apiObjects().ListObject(plural)
+	writeAPIObject(w, list)
+}
+
+func (s *HeadsUpServer) GetAPIObject(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	plural := vars["kind"]
+	name, err := url.PathUnescape(vars["name"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid name: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	objs, ok := s.apiObjects().List(plural)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown kind: %s", plural), http.StatusNotFound)
+		return
+	}
+	for _, obj := range objs {
+		if obj.GetName() == name {
+			writeAPIObject(w, obj)
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("%s %q not found", apiview.Kinds[plural], name), http.StatusNotFound)
+}
+
+func writeAPIObject(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(obj)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering object: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Streams a WatchEvent for each change to objects of the given kind,
+// one JSON object per line. Starts with an ADDED event for every object
+// that already exists.
+func (s *HeadsUpServer) watchAPIObjects(w http.ResponseWriter, req *http.Request, plural string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	watcher := newAPIWatcher()
+	s.store.AddSubscriber(ctx, watcher)
+	defer func() {
+		_ = s.store.RemoveSubscriber(context.Background(), watcher)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	sent := make(map[string]metav1.Object)
+	for {
+		objs, _ := s.apiObjects().List(plural)
+		events := diffAPIObjects(sent, objs)
+		for _, e := range events {
+			err := encoder.Encode(e)
+			if err != nil {
+				return
+			}
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-watcher.changed:
+		}
+	}
+}
+
+// Compares the objects against the ones we've already sent,
+// and updates the sent map to match.
+func diffAPIObjects(sent map[string]metav1.Object, objs []metav1.Object) []v1alpha1.WatchEvent {
+	var events []v1alpha1.WatchEvent
+	seen := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		name := obj.GetName()
+		seen[name] = true
+
+		old, ok := sent[name]
+		if ok && old.GetResourceVersion() == obj.GetResourceVersion() {
+			continue
+		}
+
+		eventType := v1alpha1.Modified
+		if !ok {
+			eventType = v1alpha1.Added
+		}
+		sent[name] = obj
+		events = append(events, v1alpha1.WatchEvent{Type: eventType, Object: obj})
+	}
+
+	for name, old := range sent {
+		if seen[name] {
+			continue
+		}
+		delete(sent, name)
+		events = append(events, v1alpha1.WatchEvent{Type: v1alpha1.Deleted, Object: old})
+	}
+	return events
+}
+
+// Wakes up a watch whenever the engine state changes.
+type apiWatcher struct {
+	changed chan struct{}
+}
+
+var _ store.Subscriber = &apiWatcher{}
+
+func newAPIWatcher() *apiWatcher {
+	return &apiWatcher{changed: make(chan struct{}, 1)}
+}
+
+func (w *apiWatcher) OnChange(ctx context.Context, st store.RStore) {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestListAPIObjects(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertLocalResource("fe")
+
+	rr := f.getAPI("/api/v1alpha1/resources")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var list v1alpha1.ResourceList
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Equal(t, "ResourceList", list.Kind)
+	assert.Equal(t, "tilt.dev/v1alpha1", list.APIVersion)
+	assert.NotEmpty(t, list.ResourceVersion)
+	require.Len(t, list.Items, 2)
+	assert.Equal(t, "(Tiltfile)", list.Items[0].Name)
+	assert.Equal(t, "fe", list.Items[1].Name)
+	assert.Equal(t, "local", list.Items[1].Spec.Type)
+}
+
+func TestListAPIObjectsUnknownKind(t *testing.T) {
+	f := newTestFixture(t)
+
+	rr := f.getAPI("/api/v1alpha1/widgets")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetAPIObject(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertLocalResource("fe")
+
+	rr := f.getAPI("/api/v1alpha1/resources/fe")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var r v1alpha1.Resource
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &r))
+	assert.Equal(t, "Resource", r.Kind)
+	assert.Equal(t, "fe", r.Name)
+}
+
+func TestGetAPIObjectEscapedName(t *testing.T) {
+	f := newTestFixture(t)
+
+	rr := f.getAPI("/api/v1alpha1/resources/%28Tiltfile%29")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var r v1alpha1.Resource
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &r))
+	assert.Equal(t, "(Tiltfile)", r.Name)
+}
+
+func TestGetAPIObjectNotFound(t *testing.T) {
+	f := newTestFixture(t)
+
+	rr := f.getAPI("/api/v1alpha1/resources/fe")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestWatchAPIObjects(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertLocalResource("fe")
+
+	server := httptest.NewServer(f.serv.Router())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1alpha1/resources?watch=true", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() (v1alpha1.WatchEventType, string) {
+		require.True(t, scanner.Scan(), "expected another watch event: %v", scanner.Err())
+		var event struct {
+			Type   v1alpha1.WatchEventType
+			Object v1alpha1.Resource
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		return event.Type, event.Object.Name
+	}
+
+	eventType, name := next()
+	assert.Equal(t, v1alpha1.Added, eventType)
+	assert.Equal(t, "(Tiltfile)", name)
+	eventType, name = next()
+	assert.Equal(t, v1alpha1.Added, eventType)
+	assert.Equal(t, "fe", name)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.CurrentBuild = model.BuildRecord{StartTime: time.Now(), SpanID: "build:fe:1"}
+	f.st.UnlockMutableState()
+	f.st.NotifySubscribers(ctx)
+
+	eventType, name = next()
+	assert.Equal(t, v1alpha1.Modified, eventType)
+	assert.Equal(t, "fe", name)
+
+	state = f.st.LockMutableStateForTesting()
+	delete(state.ManifestTargets, "fe")
+	f.st.UnlockMutableState()
+	f.st.NotifySubscribers(ctx)
+
+	eventType, name = next()
+	assert.Equal(t, v1alpha1.Deleted, eventType)
+	assert.Equal(t, "fe", name)
+}
+
+func (f *serverFixture) upsertLocalResource(name model.ManifestName) {
+	m := model.Manifest{Name: name}.WithDeployTarget(model.LocalTarget{UpdateCmd: model.ToHostCmd("echo hi")})
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+}
+
+func (f *serverFixture) getAPI(path string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	require.NoError(f.t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr
+}
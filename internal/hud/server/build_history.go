@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const defaultBuildHistoryPageSize = 10
+const maxBuildHistoryPageSize = 100
+
+type buildHistoryPage struct {
+	Name string `json:"name"`
+
+	// The number of builds Tilt has for this resource, across all pages.
+	Total  int                 `json:"total"`
+	Offset int                 `json:"offset"`
+	Builds []buildHistoryEntry `json:"builds"`
+}
+
+type buildHistoryEntry struct {
+	SpanID       model.LogSpanID `json:"spanId"`
+	StartTime    time.Time       `json:"startTime"`
+	FinishTime   time.Time       `json:"finishTime"`
+	Reason       string          `json:"reason"`
+	Edits        []string        `json:"edits,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	WarningCount int             `json:"warningCount"`
+	Log          string          `json:"log"`
+
+	// True if the rendered YAML was unchanged, so Tilt didn't apply it.
+	DeploySkipped bool `json:"deploySkipped,omitempty"`
+
+	// Only for images built with update_settings(image_layer_analysis=True).
+	ImageLayers []imageLayersEntry `json:"imageLayers,omitempty"`
+
+	// Where to read the log from, if it's not in the logstore anymore.
+	logPath string
+}
+
+type imageLayersEntry struct {
+	Image       string            `json:"image"`
+	Size        int64             `json:"size"`
+	WastedBytes int64             `json:"wastedBytes"`
+	Efficiency  float64           `json:"efficiency"`
+	Layers      []imageLayerEntry `json:"layers"`
+}
+
+type imageLayerEntry struct {
+	CreatedBy    string                `json:"createdBy"`
+	Size         int64                 `json:"size"`
+	LargestFiles []imageLayerFileEntry `json:"largestFiles,omitempty"`
+}
+
+type imageLayerFileEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Serves a resource's completed builds, most recent first, with their logs.
+//
+// Takes `offset` and `limit` query params to page through them.
+func (s *HeadsUpServer) BuildHistoryJSON(w http.ResponseWriter, req *http.Request) {
+	name, err := url.PathUnescape(mux.Vars(req)["name"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid name: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	offset, err := queryInt(req, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(req, "limit", defaultBuildHistoryPageSize)
+	if err != nil || limit < 1 {
+		http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if limit > maxBuildHistoryPageSize {
+		limit = maxBuildHistoryPageSize
+	}
+
+	state := s.store.RLockState()
+	page, ok := buildHistoryPageFromState(state, model.ManifestName(name), offset, limit)
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	// Read the saved logs after we've released the state lock.
+	for i, entry := range page.Builds {
+		if entry.Log != "" {
+			continue
+		}
+		page.Builds[i].Log = readBuildLog(entry.logPath)
+	}
+
+	writeAPIObject(w, page)
+}
+
+func buildHistoryPageFromState(state store.EngineState, name model.ManifestName, offset, limit int) (buildHistoryPage, bool) {
+	ms, ok := state.ManifestState(name)
+	if name == model.TiltfileManifestName {
+		ms, ok = &state.TiltfileState, true
+	}
+	if !ok {
+		return buildHistoryPage{}, false
+	}
+
+	history := ms.BuildHistory
+	page := buildHistoryPage{
+		Name:   name.String(),
+		Total:  len(history),
+		Offset: offset,
+		Builds: []buildHistoryEntry{},
+	}
+
+	for i := offset; i < len(history) && i < offset+limit; i++ {
+		br := history[i]
+		entry := buildHistoryEntry{
+			SpanID:        br.SpanID,
+			StartTime:     br.StartTime,
+			FinishTime:    br.FinishTime,
+			Reason:        br.Reason.String(),
+			Edits:         br.Edits,
+			WarningCount:  br.WarningCount,
+			DeploySkipped: br.DeploySkipped,
+			logPath:       br.LogPath,
+		}
+		if br.Error != nil {
+			entry.Error = br.Error.Error()
+		}
+		for _, a := range br.ImageLayers {
+			entry.ImageLayers = append(entry.ImageLayers, toImageLayersEntry(a))
+		}
+
+		// Builds whose logs haven't been saved yet are still in the logstore.
+		if br.LogPath == "" && br.SpanID != "" {
+			entry.Log = state.LogStore.SpanLog(br.SpanID)
+		}
+
+		page.Builds = append(page.Builds, entry)
+	}
+	return page, true
+}
+
+func toImageLayersEntry(a model.ImageLayerAnalysis) imageLayersEntry {
+	entry := imageLayersEntry{
+		Image:       a.Image,
+		Size:        a.Size,
+		WastedBytes: a.WastedBytes,
+		Efficiency:  a.Efficiency(),
+		Layers:      []imageLayerEntry{},
+	}
+	for _, l := range a.Layers {
+		layer := imageLayerEntry{CreatedBy: l.CreatedBy, Size: l.Size}
+		for _, f := range l.LargestFiles {
+			layer.LargestFiles = append(layer.LargestFiles, imageLayerFileEntry{Path: f.Path, Size: f.Size})
+		}
+		entry.Layers = append(entry.Layers, layer)
+	}
+	return entry
+}
+
+func readBuildLog(path string) string {
+	if path == "" {
+		return ""
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		// The build fell out of the history, and its logs were deleted.
+		return ""
+	}
+	return string(b)
+}
+
+func queryInt(req *http.Request, key string, defaultValue int) (int, error) {
+	v := req.URL.Query().Get(key)
+	if v == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,163 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type buildHistoryPage struct {
+	Name   string `json:"name"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Builds []struct {
+		SpanID        string `json:"spanId"`
+		Error         string `json:"error"`
+		Log           string `json:"log"`
+		DeploySkipped bool   `json:"deploySkipped"`
+
+		ImageLayers []struct {
+			Image      string  `json:"image"`
+			Size       int64   `json:"size"`
+			Efficiency float64 `json:"efficiency"`
+			Layers     []struct {
+				CreatedBy    string `json:"createdBy"`
+				LargestFiles []struct {
+					Path string `json:"path"`
+				} `json:"largestFiles"`
+			} `json:"layers"`
+		} `json:"imageLayers"`
+	} `json:"builds"`
+}
+
+func TestBuildHistoryPagination(t *testing.T) {
+	f := newTestFixture(t)
+	tmp := tempdir.NewTempDirFixture(t)
+	defer tmp.TearDown()
+
+	f.upsertLocalResource("fe")
+	state := f.st.LockMutableStateForTesting()
+	ms, _ := state.ManifestState("fe")
+	for i := 0; i < 5; i++ {
+		spanID := model.LogSpanID(fmt.Sprintf("build:%d", i))
+		br := model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now(), SpanID: spanID}
+		if i < 3 {
+			// The oldest builds' logs have been saved to disk.
+			br.LogPath = tmp.JoinPath(fmt.Sprintf("%d.log", i))
+			tmp.WriteFile(br.LogPath, fmt.Sprintf("saved log %d\n", i))
+		} else {
+			state.LogStore.Append(store.NewLogAction("fe", spanID, logger.InfoLvl, nil,
+				[]byte(fmt.Sprintf("live log %d\n", i))), nil)
+		}
+		ms.AddCompletedBuildWithLimit(br, 10)
+	}
+	f.st.UnlockMutableState()
+
+	rr := f.getAPI("/api/build_history/fe?limit=2")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var page buildHistoryPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, 5, page.Total)
+	if assert.Len(t, page.Builds, 2) {
+		assert.Equal(t, "build:4", page.Builds[0].SpanID)
+		assert.Equal(t, "live log 4\n", page.Builds[0].Log)
+		assert.Equal(t, "build:3", page.Builds[1].SpanID)
+	}
+
+	rr = f.getAPI("/api/build_history/fe?offset=2&limit=2")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	page = buildHistoryPage{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Offset)
+	if assert.Len(t, page.Builds, 2) {
+		assert.Equal(t, "build:2", page.Builds[0].SpanID)
+		assert.Equal(t, "saved log 2\n", page.Builds[0].Log)
+		assert.Equal(t, "build:1", page.Builds[1].SpanID)
+	}
+
+	rr = f.getAPI("/api/build_history/fe?offset=10")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	page = buildHistoryPage{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, 5, page.Total)
+	assert.Len(t, page.Builds, 0)
+}
+
+func TestBuildHistoryImageLayers(t *testing.T) {
+	f := newTestFixture(t)
+
+	f.upsertLocalResource("fe")
+	state := f.st.LockMutableStateForTesting()
+	ms, _ := state.ManifestState("fe")
+	ms.AddCompletedBuildWithLimit(model.BuildRecord{
+		StartTime:  time.Now(),
+		FinishTime: time.Now(),
+		ImageLayers: []model.ImageLayerAnalysis{{
+			Image:       "fe:tilt-123",
+			Size:        400,
+			WastedBytes: 100,
+			Layers: []model.ImageLayer{{
+				CreatedBy:    "RUN npm install",
+				Size:         400,
+				LargestFiles: []model.ImageLayerFile{{Path: "/app/node_modules/big.js", Size: 300}},
+			}},
+		}},
+	}, 10)
+	f.st.UnlockMutableState()
+
+	rr := f.getAPI("/api/build_history/fe")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var page buildHistoryPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	require.Len(t, page.Builds, 1)
+	require.Len(t, page.Builds[0].ImageLayers, 1)
+	analysis := page.Builds[0].ImageLayers[0]
+	assert.Equal(t, "fe:tilt-123", analysis.Image)
+	assert.Equal(t, 0.75, analysis.Efficiency)
+	require.Len(t, analysis.Layers, 1)
+	assert.Equal(t, "RUN npm install", analysis.Layers[0].CreatedBy)
+	assert.Equal(t, "/app/node_modules/big.js", analysis.Layers[0].LargestFiles[0].Path)
+}
+
+func TestBuildHistoryErrors(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertLocalResource("fe")
+
+	rr := f.getAPI("/api/build_history/nope")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = f.getAPI("/api/build_history/fe?limit=0")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = f.getAPI("/api/build_history/fe?offset=-1")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestBuildHistoryDeploySkipped(t *testing.T) {
+	f := newTestFixture(t)
+
+	f.upsertLocalResource("fe")
+	state := f.st.LockMutableStateForTesting()
+	ms, _ := state.ManifestState("fe")
+	ms.AddCompletedBuildWithLimit(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()}, 10)
+	ms.AddCompletedBuildWithLimit(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now(), DeploySkipped: true}, 10)
+	f.st.UnlockMutableState()
+
+	rr := f.getAPI("/api/build_history/fe")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var page buildHistoryPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	require.Len(t, page.Builds, 2)
+	assert.True(t, page.Builds[0].DeploySkipped)
+	assert.False(t, page.Builds[1].DeploySkipped)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+type buildQueuePage struct {
+	Builds []buildQueueEntry `json:"builds"`
+}
+
+type buildQueueEntry struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+
+	// Why it needs to build, e.g. "Changed Files" or "Web Trigger".
+	Reason string `json:"reason"`
+
+	// Why it can't build yet, if anything.
+	Hold            string `json:"hold,omitempty"`
+	HoldDescription string `json:"holdDescription,omitempty"`
+
+	PendingSince *time.Time `json:"pendingSince,omitempty"`
+}
+
+// Serves the resources waiting to build, in the order we expect to build them,
+// so that you can tell why your change hasn't built yet.
+func (s *HeadsUpServer) BuildQueueJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	queue := buildcontrol.BuildQueue(state)
+	s.store.RUnlockState()
+
+	page := buildQueuePage{Builds: []buildQueueEntry{}}
+	for _, qb := range queue {
+		entry := buildQueueEntry{
+			Name:            qb.Name.String(),
+			Position:        qb.Position,
+			Reason:          qb.Reason.String(),
+			Hold:            string(qb.Hold),
+			HoldDescription: qb.Hold.Description(),
+		}
+		if !qb.PendingSince.IsZero() {
+			t := qb.PendingSince
+			entry.PendingSince = &t
+		}
+		page.Builds = append(page.Builds, entry)
+	}
+	writeAPIObject(w, page)
+}
+
+// Skips the builds waiting in the queue. See store.ClearBuildQueueAction.
+func (s *HeadsUpServer) HandleClearBuildQueue(w http.ResponseWriter, req *http.Request) {
+	s.store.Dispatch(store.ClearBuildQueueAction{})
+}
@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type buildQueuePage struct {
+	Builds []struct {
+		Name            string     `json:"name"`
+		Position        int        `json:"position"`
+		Reason          string     `json:"reason"`
+		Hold            string     `json:"hold"`
+		HoldDescription string     `json:"holdDescription"`
+		PendingSince    *time.Time `json:"pendingSince"`
+	} `json:"builds"`
+}
+
+func TestBuildQueueJSON(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	for _, name := range []model.ManifestName{"lint", "test", "vet"} {
+		m := model.Manifest{Name: name}.WithDeployTarget(model.LocalTarget{Name: model.TargetName(name)})
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	}
+	state.ManifestTargets["lint"].State.CurrentBuild = model.BuildRecord{StartTime: time.Now()}
+	f.st.UnlockMutableState()
+
+	rr := f.getAPI("/api/build_queue")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var page buildQueuePage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+
+	// lint is building, so it's not in the queue, and local resources
+	// build one at a time.
+	require.Len(t, page.Builds, 2)
+	assert.Equal(t, "test", page.Builds[0].Name)
+	assert.Equal(t, 1, page.Builds[0].Position)
+	assert.Equal(t, "Initial Build", page.Builds[0].Reason)
+	assert.Equal(t, string(store.HoldWaitingForUnparallelizableTarget), page.Builds[0].Hold)
+	assert.Equal(t, store.HoldWaitingForUnparallelizableTarget.Description(), page.Builds[0].HoldDescription)
+	assert.Nil(t, page.Builds[0].PendingSince)
+	assert.Equal(t, "vet", page.Builds[1].Name)
+	assert.Equal(t, 2, page.Builds[1].Position)
+}
+
+func TestBuildQueueJSONEmpty(t *testing.T) {
+	f := newTestFixture(t)
+
+	rr := f.getAPI("/api/build_queue")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"builds": []}`, rr.Body.String())
+}
+
+func TestClearBuildQueue(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/build_queue/clear", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.ClearBuildQueueAction{}), f.getActions)
+	assert.Equal(t, store.ClearBuildQueueAction{}, a)
+}
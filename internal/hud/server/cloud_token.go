@@ -0,0 +1,52 @@
+package server
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+// The path that re-links this Tilt's token to a Tilt Cloud account.
+//
+// The terminal prompt can only open URLs, and Tilt Cloud expects
+// the token in a POST, so we serve a page that submits it.
+const RelinkTiltCloudTokenPath = "/api/tilt_cloud/relink"
+
+var relinkTemplate = template.Must(template.New("relink").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Linking Tilt to Tilt Cloud</title></head>
+<body onload="document.forms[0].submit()">
+<form action="{{.Action}}" method="POST">
+<input name="token" type="hidden" value="{{.Token}}">
+<noscript><input type="submit" value="Link Tilt to Tilt Cloud"></noscript>
+</form>
+</body>
+</html>
+`))
+
+func (s *HeadsUpServer) relinkTiltCloudToken(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	token := state.Token
+	u := cloudurl.URL(state.CloudAddress)
+	s.store.RUnlockState()
+
+	u.Path = "/start_register_token"
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := relinkTemplate.Execute(w, struct {
+		Action string
+		Token  string
+	}{
+		Action: u.String(),
+		Token:  token.String(),
+	})
+	if err != nil {
+		log.Printf("Error rendering relink page: %v", err)
+		return
+	}
+
+	s.store.Dispatch(store.UserStartedTiltCloudRegistrationAction{})
+}
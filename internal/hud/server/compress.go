@@ -0,0 +1,89 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// View and snapshot payloads are multi-MB JSON, which is slow to load
+// over a remote connection. They compress well.
+//
+// We only speak gzip. Clients that only accept other encodings (like zstd)
+// get the payload uncompressed.
+//
+// (Websocket frames are compressed separately, with the permessage-deflate
+// extension that the browser negotiates.)
+func gzipHandler(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(req) {
+			h(w, req)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer func() {
+			_ = gz.Close()
+		}()
+		h(gzipResponseWriter{ResponseWriter: w, w: gz}, req)
+	}
+}
+
+func acceptsGzip(req *http.Request) bool {
+	accepts := false
+	for _, header := range req.Header.Values("Accept-Encoding") {
+		for _, encoding := range strings.Split(header, ",") {
+			params := strings.Split(encoding, ";")
+			name := strings.TrimSpace(params[0])
+			if name != "gzip" && name != "*" {
+				continue
+			}
+
+			// Any quality value other than 0 (e.g., "gzip;q=0.8") means we can use it.
+			// An explicit "gzip;q=0" wins over "*".
+			refused := false
+			for _, p := range params[1:] {
+				q := strings.TrimSpace(p)
+				if strings.HasPrefix(q, "q=") {
+					v, err := strconv.ParseFloat(strings.TrimPrefix(q, "q="), 64)
+					refused = err == nil && v == 0
+				}
+			}
+			if name == "gzip" && refused {
+				return false
+			}
+			if !refused {
+				accepts = true
+			}
+		}
+	}
+	return accepts
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (w gzipResponseWriter) WriteHeader(code int) {
+	// The handler doesn't know the body will be compressed.
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+// Decodes a request body that the client compressed,
+// e.g., a snapshot that the web UI is sharing.
+func requestBody(req *http.Request) (io.ReadCloser, error) {
+	if req.Header.Get("Content-Encoding") != "gzip" {
+		return req.Body, nil
+	}
+	return gzip.NewReader(req.Body)
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The biggest file that the file browser will fetch from a container.
+const maxContainerFileSize = 1024 * 1024
+
+// Lists a directory with nothing but a POSIX shell, because we can't count
+// on the container having any particular `ls` (or having `stat` at all).
+//
+// Prints one line per entry: its type (d, l, or f), a tab, and its name.
+const listContainerDirScript = `cd -- "$1" || exit 1
+for f in * .*; do
+  case "$f" in .|..) continue;; esac
+  if [ -L "$f" ]; then t=l
+  elif [ -d "$f" ]; then t=d
+  elif [ -e "$f" ]; then t=f
+  else continue
+  fi
+  printf '%s\t%s\n' "$t" "$f"
+done`
+
+var errContainerFileTooLarge = errors.New("file too large")
+
+type containerFilesPage struct {
+	Name      string               `json:"name"`
+	Pod       k8s.PodID            `json:"pod"`
+	Container container.Name       `json:"container"`
+	Path      string               `json:"path"`
+	Entries   []containerFileEntry `json:"entries"`
+}
+
+type containerFileEntry struct {
+	Name string `json:"name"`
+
+	// One of "dir", "file", or "link".
+	Type string `json:"type"`
+}
+
+// The container that a file browser request reads from.
+type containerFilesTarget struct {
+	pod       k8s.PodID
+	namespace k8s.Namespace
+	container container.Name
+	path      string
+}
+
+// Lists a directory in a resource's running container, for the web UI's
+// file browser, so that you can check where live_update put your files.
+//
+// Takes a `path` query param (default: /), and a `container` query param
+// (default: the pod's first container).
+func (s *HeadsUpServer) ContainerFilesJSON(w http.ResponseWriter, req *http.Request) {
+	target, name, status, err := s.containerFilesTarget(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err = s.kClient.Exec(req.Context(), target.pod, target.container, target.namespace,
+		[]string{"sh", "-c", listContainerDirScript, "sh", target.path}, nil, stdout, stderr)
+	if err != nil {
+		http.Error(w, execErrorMessage("listing", target, err, stderr), http.StatusBadGateway)
+		return
+	}
+
+	writeAPIObject(w, containerFilesPage{
+		Name:      name.String(),
+		Pod:       target.pod,
+		Container: target.container,
+		Path:      target.path,
+		Entries:   parseContainerDirListing(stdout.String()),
+	})
+}
+
+// Serves the contents of a file in a resource's running container.
+//
+// Takes the same query params as ContainerFilesJSON. Refuses files bigger than 1MB.
+func (s *HeadsUpServer) ContainerFileContent(w http.ResponseWriter, req *http.Request) {
+	target, _, status, err := s.containerFilesTarget(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	stdout := &cappedBuffer{max: maxContainerFileSize}
+	stderr := &bytes.Buffer{}
+	err = s.kClient.Exec(req.Context(), target.pod, target.container, target.namespace,
+		[]string{"cat", "--", target.path}, nil, stdout, stderr)
+	if stdout.exceeded {
+		http.Error(w, fmt.Sprintf("%s is larger than %d bytes", target.path, maxContainerFileSize),
+			http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, execErrorMessage("reading", target, err, stderr), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(stdout.buf.Bytes())
+}
+
+// Finds the container and path that a file browser request is asking about.
+//
+// Returns the HTTP status to respond with if the request is no good.
+func (s *HeadsUpServer) containerFilesTarget(req *http.Request) (containerFilesTarget, model.ManifestName, int, error) {
+	rawName, err := url.PathUnescape(mux.Vars(req)["name"])
+	if err != nil {
+		return containerFilesTarget{}, "", http.StatusBadRequest, fmt.Errorf("invalid name: %v", err)
+	}
+	name := model.ManifestName(rawName)
+
+	p := req.URL.Query().Get("path")
+	if p == "" {
+		p = "/"
+	}
+	if !path.IsAbs(p) {
+		return containerFilesTarget{}, name, http.StatusBadRequest, fmt.Errorf("path must be absolute: %q", p)
+	}
+	p = path.Clean(p)
+
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+
+	mt, ok := state.ManifestTargets[name]
+	if !ok {
+		return containerFilesTarget{}, name, http.StatusNotFound, fmt.Errorf("resource %q not found", name)
+	}
+	if !mt.Manifest.IsK8s() {
+		return containerFilesTarget{}, name, http.StatusBadRequest,
+			fmt.Errorf("resource %q doesn't run in Kubernetes", name)
+	}
+
+	pod := mt.State.K8sRuntimeState().MostRecentPod()
+	if pod.PodID == "" || len(pod.Containers) == 0 {
+		return containerFilesTarget{}, name, http.StatusNotFound,
+			fmt.Errorf("resource %q has no running containers", name)
+	}
+
+	cName := container.Name(req.URL.Query().Get("container"))
+	if cName == "" {
+		cName = pod.Containers[0].Name
+	} else if !podHasContainer(pod.Containers, cName) {
+		return containerFilesTarget{}, name, http.StatusNotFound,
+			fmt.Errorf("pod %s has no container %q", pod.PodID, cName)
+	}
+
+	return containerFilesTarget{
+		pod:       pod.PodID,
+		namespace: pod.Namespace,
+		container: cName,
+		path:      p,
+	}, name, http.StatusOK, nil
+}
+
+func podHasContainer(containers []store.Container, name container.Name) bool {
+	for _, c := range containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseContainerDirListing(out string) []containerFileEntry {
+	entries := []containerFileEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+
+		t := "file"
+		switch parts[0] {
+		case "d":
+			t = "dir"
+		case "l":
+			t = "link"
+		}
+		entries = append(entries, containerFileEntry{Name: parts[1], Type: t})
+	}
+	return entries
+}
+
+func execErrorMessage(verb string, target containerFilesTarget, err error, stderr *bytes.Buffer) string {
+	msg := fmt.Sprintf("%s %s in %s/%s: %v", verb, target.path, target.pod, target.container, err)
+	if s := strings.TrimSpace(stderr.String()); s != "" {
+		msg = fmt.Sprintf("%s\n%s", msg, s)
+	}
+	return msg
+}
+
+// Buffers up to max bytes, then fails the write so that the exec stops
+// streaming the rest of the file.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		b.exceeded = true
+		return 0, errContainerFileTooLarge
+	}
+	return b.buf.Write(p)
+}
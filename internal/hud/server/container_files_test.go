@@ -0,0 +1,114 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type containerFilesPage struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Entries   []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"entries"`
+}
+
+func TestContainerFilesList(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertK8sResourceWithPod("fe", "app", "sidecar")
+	f.kCli.ExecOutputs = []string{"d\tsrc\nf\tmain.go\nl\tcurrent\n"}
+
+	rr := f.getAPI("/api/container_files/fe?path=/app/")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var page containerFilesPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, "pod-fe", page.Pod)
+	assert.Equal(t, "app", page.Container)
+	assert.Equal(t, "/app", page.Path)
+	require.Len(t, page.Entries, 3)
+	assert.Equal(t, "src", page.Entries[0].Name)
+	assert.Equal(t, "dir", page.Entries[0].Type)
+	assert.Equal(t, "file", page.Entries[1].Type)
+	assert.Equal(t, "link", page.Entries[2].Type)
+
+	require.Len(t, f.kCli.ExecCalls, 1)
+	call := f.kCli.ExecCalls[0]
+	assert.Equal(t, k8s.PodID("pod-fe"), call.PID)
+	assert.Equal(t, k8s.Namespace("default"), call.Ns)
+	assert.Equal(t, "/app", call.Cmd[len(call.Cmd)-1])
+}
+
+func TestContainerFilesPicksContainer(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertK8sResourceWithPod("fe", "app", "sidecar")
+
+	rr := f.getAPI("/api/container_files/fe?container=sidecar")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, container.Name("sidecar"), f.kCli.ExecCalls[0].CName)
+	assert.Equal(t, "/", f.kCli.ExecCalls[0].Cmd[len(f.kCli.ExecCalls[0].Cmd)-1])
+
+	rr = f.getAPI("/api/container_files/fe?container=nope")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestContainerFilesErrors(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertLocalResource("local")
+	f.upsertK8sResourceWithPod("fe", "app")
+
+	assert.Equal(t, http.StatusNotFound, f.getAPI("/api/container_files/nope").Code)
+	assert.Equal(t, http.StatusBadRequest, f.getAPI("/api/container_files/local").Code)
+	assert.Equal(t, http.StatusBadRequest, f.getAPI("/api/container_files/fe?path=app").Code)
+
+	f.kCli.ExecErrors = []error{fmt.Errorf("command terminated with exit code 1")}
+	rr := f.getAPI("/api/container_files/fe?path=/nope")
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "listing /nope in pod-fe/app")
+}
+
+func TestContainerFileContent(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertK8sResourceWithPod("fe", "app")
+	f.kCli.ExecOutputs = []string{"package main\n"}
+
+	rr := f.getAPI("/api/container_files/fe/content?path=/app/main.go")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "package main\n", rr.Body.String())
+	assert.Equal(t, []string{"cat", "--", "/app/main.go"}, f.kCli.ExecCalls[0].Cmd)
+}
+
+func TestContainerFileContentTooLarge(t *testing.T) {
+	f := newTestFixture(t)
+	f.upsertK8sResourceWithPod("fe", "app")
+	f.kCli.ExecOutputs = []string{strings.Repeat("x", 2*1024*1024)}
+
+	rr := f.getAPI("/api/container_files/fe/content?path=/app/big.bin")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func (f *serverFixture) upsertK8sResourceWithPod(name model.ManifestName, containers ...container.Name) {
+	m := model.Manifest{Name: name}.WithDeployTarget(model.K8sTarget{Name: model.TargetName(name)})
+	pod := store.Pod{PodID: k8s.PodID(fmt.Sprintf("pod-%s", name)), Namespace: "default"}
+	for _, c := range containers {
+		pod.Containers = append(pod.Containers, store.Container{Name: c, Running: true})
+	}
+
+	mt := store.NewManifestTarget(m)
+	mt.State.RuntimeState = store.NewK8sRuntimeStateWithPods(m, pod)
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(mt)
+	f.st.UnlockMutableState()
+}
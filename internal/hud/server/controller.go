@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -22,19 +23,23 @@ const reconnectDur = 2 * time.Second
 type HeadsUpServerController struct {
 	host        model.WebHost
 	port        model.WebPort
+	listener    WebListener
 	hudServer   *HeadsUpServer
 	assetServer assets.Server
 	webURL      model.WebURL
+	listeners   *listeners.Registry
 	initDone    bool
 }
 
-func ProvideHeadsUpServerController(host model.WebHost, port model.WebPort, hudServer *HeadsUpServer, assetServer assets.Server, webURL model.WebURL) *HeadsUpServerController {
+func ProvideHeadsUpServerController(host model.WebHost, port model.WebPort, listener WebListener, hudServer *HeadsUpServer, assetServer assets.Server, webURL model.WebURL, listeners *listeners.Registry) *HeadsUpServerController {
 	return &HeadsUpServerController{
 		host:        host,
 		port:        port,
+		listener:    listener,
 		hudServer:   hudServer,
 		assetServer: assetServer,
 		webURL:      webURL,
+		listeners:   listeners,
 	}
 }
 
@@ -52,18 +57,26 @@ func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore)
 		s.initDone = true
 	}()
 
-	if s.initDone || s.port == 0 {
+	if s.initDone || (s.port == 0 && s.listener == nil) {
 		return
 	}
 
-	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", string(s.host), int(s.port)))
-	if err != nil {
-		st.Dispatch(
-			store.NewErrorAction(
-				errors.Wrapf(err, "Cannot start Tilt. Maybe another process is already running on port %d? Use --port to set a custom port", s.port)))
-		return
+	var l net.Listener = s.listener
+	if l == nil {
+		var err error
+		l, err = net.Listen("tcp", fmt.Sprintf("%s:%d", string(s.host), int(s.port)))
+		if err != nil {
+			st.Dispatch(
+				store.NewErrorAction(
+					errors.Wrapf(err, "Cannot start Tilt. Maybe another process is already running on port %d? Use --port to set a custom port", s.port)))
+			return
+		}
 	}
 
+	entry := s.listeners.Add(listeners.KindWebServer, "", "tcp", l.Addr().String(), "Tilt web UI and API")
+	entry.SetListening()
+	l = entry.WrapListener(l)
+
 	httpServer := &http.Server{
 		Handler: http.DefaultServeMux,
 	}
@@ -72,6 +85,7 @@ func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore)
 	go func() {
 		<-ctx.Done()
 		_ = httpServer.Shutdown(context.Background())
+		entry.Remove()
 	}()
 
 	go func() {
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How many ports after the configured one we try before giving up.
+const webPortAttempts = 20
+
+// With systemd-style socket activation, passed sockets start at fd 3.
+// http://0pointer.de/public/systemd-man/sd_listen_fds.html
+const listenFdsStart = 3
+
+// A listener for the web server that was opened before the engine started.
+//
+// If nil, the server controller listens on the configured host and port itself.
+type WebListener net.Listener
+
+// Open the listener for the web server.
+//
+// If Tilt was started with socket activation (e.g., by a systemd socket unit),
+// we use the socket we were handed. Otherwise, we listen on the configured port,
+// moving on to the next one if it's taken, so that you can run several Tilt
+// sessions side by side.
+func ListenWeb(host model.WebHost, port model.WebPort) (net.Listener, error) {
+	l, err := activatedListener()
+	if err != nil || l != nil {
+		return l, err
+	}
+
+	var firstErr error
+	for i := 0; i < webPortAttempts; i++ {
+		l, err := net.Listen("tcp", net.JoinHostPort(string(host), strconv.Itoa(int(port)+i)))
+		if err == nil {
+			return l, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, fmt.Errorf("no free port in %d-%d: %v", port, int(port)+webPortAttempts-1, firstErr)
+}
+
+func activatedListener() (net.Listener, error) {
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds < 1 {
+		return nil, nil
+	}
+
+	// The sockets are meant for a specific process. If it's not us,
+	// we inherited the env from a parent that was socket-activated.
+	pid := os.Getenv("LISTEN_PID")
+	if pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	// Don't pass the sockets on to the commands that we run.
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(listenFdsStart), "LISTEN_FD_3")
+	defer func() {
+		_ = f.Close()
+	}()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("using socket from socket activation: %v", err)
+	}
+	return l, nil
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestListenWebPicksNextFreePort(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = taken.Close()
+	}()
+	port := taken.Addr().(*net.TCPAddr).Port
+
+	l, err := ListenWeb("127.0.0.1", model.WebPort(port))
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+
+	actual := l.Addr().(*net.TCPAddr).Port
+	assert.True(t, actual > port && actual < port+webPortAttempts,
+		"expected a port after %d, got %d", port, actual)
+}
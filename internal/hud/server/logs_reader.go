@@ -32,8 +32,9 @@ type WebsocketReader struct {
 	handler      ViewHandler
 }
 
-func newWebsocketReaderForLogs(conn WebsocketConn, persistent bool, resources []string, p *hud.IncrementalPrinter) *WebsocketReader {
+func newWebsocketReaderForLogs(conn WebsocketConn, persistent bool, resources []string, includeBuildDetail bool, p *hud.IncrementalPrinter) *WebsocketReader {
 	ls := NewLogStreamer(resources, p)
+	ls.includeBuildDetail = includeBuildDetail
 	return newWebsocketReader(conn, persistent, ls)
 }
 
@@ -56,6 +57,9 @@ type LogStreamer struct {
 	checkpoint logstore.Checkpoint
 	resources  model.ManifestNameSet // if present, resource(s) to stream logs for
 	printer    *hud.IncrementalPrinter
+
+	// if true, print the full build output, even parts hidden by the user's build_output settings
+	includeBuildDetail bool
 }
 
 func NewLogStreamer(resources []string, p *hud.IncrementalPrinter) *LogStreamer {
@@ -95,8 +99,9 @@ func (ls *LogStreamer) Handle(v proto_webview.View) error {
 	}
 
 	ls.printer.Print(ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, logstore.LineOptions{
-		ManifestNames:  ls.resources,
-		SuppressPrefix: suppressPrefix,
+		ManifestNames:      ls.resources,
+		SuppressPrefix:     suppressPrefix,
+		IncludeBuildDetail: ls.includeBuildDetail,
 	}))
 
 	if toCheckpoint > ls.checkpoint {
@@ -105,18 +110,20 @@ func (ls *LogStreamer) Handle(v proto_webview.View) error {
 
 	return nil
 }
-func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []string, printer *hud.IncrementalPrinter) error {
+func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []string, includeBuildDetail bool, printer *hud.IncrementalPrinter) error {
 	url.Scheme = "ws"
 	url.Path = "/ws/view"
 	logger.Get(ctx).Debugf("connecting to %s", url.String())
 
-	conn, _, err := websocket.DefaultDialer.Dial(url.String(), nil)
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = true
+	conn, _, err := dialer.Dial(url.String(), nil)
 	if err != nil {
 		return errors.Wrapf(err, "dialing websocket %s", url.String())
 	}
 	defer conn.Close()
 
-	wsr := newWebsocketReaderForLogs(conn, follow, resources, printer)
+	wsr := newWebsocketReaderForLogs(conn, follow, resources, includeBuildDetail, printer)
 	return wsr.Listen(ctx)
 }
 
@@ -170,8 +177,8 @@ func (wsr *WebsocketReader) handleTextMessage(ctx context.Context, reader io.Rea
 		return errors.Wrap(err, "Handling Tilt state from websocket")
 	}
 
-	// If server is using the incremental logs protocol, send back an ACK
-	if v.LogList != nil && v.LogList.ToCheckpoint > 0 {
+	// If server is using the incremental logs protocol or sequence numbers, send back an ACK
+	if v.Seq > 0 || v.LogList.GetToCheckpoint() > 0 {
 		err = wsr.sendIncrementalLogResp(ctx, &v)
 		if err != nil {
 			return errors.Wrap(err, "sending websocket ack")
@@ -184,8 +191,11 @@ func (wsr *WebsocketReader) handleTextMessage(ctx context.Context, reader io.Rea
 // sends logs from here on forward
 func (wsr *WebsocketReader) sendIncrementalLogResp(ctx context.Context, v *proto_webview.View) error {
 	resp := proto_webview.AckWebsocketRequest{
-		ToCheckpoint:  v.LogList.ToCheckpoint,
 		TiltStartTime: v.TiltStartTime,
+		Seq:           v.Seq,
+	}
+	if v.LogList.GetToCheckpoint() > 0 {
+		resp.ToCheckpoint = v.LogList.GetToCheckpoint()
 	}
 
 	w, err := wsr.conn.NextWriter(websocket.TextMessage)
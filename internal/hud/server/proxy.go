@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/assets"
+)
+
+// Serves the UI under --web-base-path, for when it lives behind a reverse proxy.
+//
+// Some proxies strip the base path before they forward the request, and some
+// don't, so we serve both. Either way, the public URL includes the base path,
+// so that's what we use when we rewrite asset URLs.
+func (s *HeadsUpServer) basePathHandler(h http.Handler) http.Handler {
+	base := string(s.basePath)
+	if base == "" {
+		return h
+	}
+
+	stripped := assets.StripPrefix(base, h)
+	unstripped := assets.WithPublicPathPrefix(base, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == base {
+			u := *r.URL
+			u.Path = base + "/"
+			http.Redirect(w, r, u.RequestURI(), http.StatusMovedPermanently)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, base+"/") {
+			stripped.ServeHTTP(w, r)
+			return
+		}
+		unstripped.ServeHTTP(w, r)
+	})
+}
+
+// Lets pages on other origins (e.g., a cloud IDE that embeds the dashboard)
+// call the JSON API, if the user allowed them with --web-cors-origin.
+func (s *HeadsUpServer) corsHandler(h http.Handler) http.Handler {
+	if len(s.corsOrigins) == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !strings.HasPrefix(r.URL.Path, "/api/") || !s.corsOrigins.Allows(origin) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Add("Vary", "Origin")
+
+		// Answer the preflight request here, because most of the API
+		// routes only accept GET or POST.
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type")
+			header.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// By default, the websocket library only accepts connections where the
+// Origin matches the Host. A reverse proxy usually rewrites the Host, so
+// also accept the host that the proxy says it forwarded, and any allowed origin.
+func (s *HeadsUpServer) checkWebsocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	forwardedHost := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Host"), ",")[0])
+	if forwardedHost != "" && strings.EqualFold(u.Host, forwardedHost) {
+		return true
+	}
+
+	return s.corsOrigins.Allows(origin)
+}
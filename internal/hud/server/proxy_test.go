@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestCheckWebsocketOrigin(t *testing.T) {
+	s := &HeadsUpServer{corsOrigins: model.WebCORSOrigins{"https://allowed.example.com"}}
+
+	for _, tc := range []struct {
+		name          string
+		origin        string
+		forwardedHost string
+		expected      bool
+	}{
+		{"no origin", "", "", true},
+		{"same host", "http://localhost:10350", "", true},
+		{"proxy host", "https://ide.example.com", "ide.example.com", true},
+		{"allowed origin", "https://allowed.example.com", "", true},
+		{"other origin", "https://evil.example.com", "", false},
+		{"other origin through proxy", "https://evil.example.com", "ide.example.com", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:10350/ws/view", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.forwardedHost != "" {
+				req.Header.Set("X-Forwarded-Host", tc.forwardedHost)
+			}
+			assert.Equal(t, tc.expected, s.checkWebsocketOrigin(req))
+		})
+	}
+}
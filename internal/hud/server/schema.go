@@ -0,0 +1,635 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+// The webview swagger doc is generated from view.proto, but many of the HUD server's
+// endpoints take hand-written JSON payloads. This describes those endpoints, so that
+// clients can codegen against the whole API.
+//
+// If you change any of the payload structs in server.go, update this too.
+const hudServerSchemaJSON = `{
+  "paths": {
+    "/api/schema": {
+      "get": {
+        "operationId": "GetSchema",
+        "description": "This document.",
+        "responses": {
+          "200": {
+            "description": "A swagger 2.0 document.",
+            "schema": {"type": "object"}
+          }
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/ws/view": {
+      "get": {
+        "operationId": "ViewWebsocket",
+        "description": "Upgrades to a websocket. The server sends a webviewView message every time the state changes. After the first message, views only contain log segments that the client hasn't seen yet. The client acks each view by sending a webviewAckWebsocketRequest with the view's seq; the server stops sending views to a client with too many unacked views until it catches up.",
+        "parameters": [
+          {"name": "checkpoint", "in": "query", "required": false, "type": "integer", "format": "int32", "description": "When reconnecting, the to_checkpoint of the last log list the client received. The server resumes sending logs from there."},
+          {"name": "tiltStartTime", "in": "query", "required": false, "type": "string", "format": "date-time", "description": "When reconnecting, the tilt_start_time of the last view the client received. If Tilt has restarted since, checkpoint is ignored."},
+          {"name": "resources", "in": "query", "required": false, "type": "string", "description": "A comma-separated list of resources. Views only contain these resources, and only their logs."},
+          {"name": "only", "in": "query", "required": false, "type": "string", "enum": ["status", "logs"], "description": "status: views only contain the name and status of each resource, and the server only sends a view when a status changes. logs: views only contain the log list."},
+          {"name": "logLevel", "in": "query", "required": false, "type": "string", "enum": ["info", "warn", "error"], "description": "Views only contain log lines at this level or more severe."}
+        ],
+        "responses": {
+          "101": {
+            "description": "Switching protocols.",
+            "schema": {"$ref": "#/definitions/webviewView"}
+          }
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/trigger": {
+      "post": {
+        "operationId": "Trigger",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/serverTriggerPayload"}}],
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/action": {
+      "post": {
+        "operationId": "DispatchAction",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/serverActionPayload"}}],
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/analytics": {
+      "post": {
+        "operationId": "Analytics",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"type": "array", "items": {"$ref": "#/definitions/serverAnalyticsPayload"}}}],
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/analytics_opt": {
+      "post": {
+        "operationId": "AnalyticsOpt",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/serverAnalyticsOptPayload"}}],
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/set_tiltfile_args": {
+      "post": {
+        "operationId": "SetTiltfileArgs",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"type": "array", "items": {"type": "string"}}}],
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/env": {
+      "post": {
+        "operationId": "SetEnv",
+        "description": "Sets or unsets an environment variable of the running Tilt. If the Tiltfile read the variable, Tilt reloads the Tiltfile.",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/serverSetEnvPayload"}}],
+        "responses": {"200": {"description": "A successful response."}, "400": {"description": "Invalid variable name."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/tilt_cloud/relink": {
+      "get": {
+        "operationId": "RelinkTiltCloudToken",
+        "description": "Serves an HTML page that posts this Tilt's token to Tilt Cloud, so that the user can link it to their account again. The terminal prompt opens this page when the token has expired.",
+        "produces": ["text/html"],
+        "responses": {"200": {"description": "An HTML page that submits itself."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/dump/analytics": {
+      "get": {
+        "operationId": "DumpAnalytics",
+        "description": "Lists the analytics events that Tilt recorded this session, including the ones it didn't send because of the user's consent settings. Used by tilt dump analytics.",
+        "responses": {
+          "200": {
+            "description": "The events, oldest first.",
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/analyticsLocalEvent"}}
+          }
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/alerts/ack": {
+      "post": {
+        "operationId": "AckAlerts",
+        "description": "Acknowledges alerts in the alert center, so that they stop nagging the user. An alert comes back if its message changes.",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/serverAckAlertsPayload"}}],
+        "responses": {"200": {"description": "A successful response."}, "400": {"description": "Invalid payload."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/overlay/local_resource": {
+      "post": {
+        "operationId": "CreateLocalResource",
+        "description": "Adds a local resource to the session overlay next to the Tiltfile, and reloads the Tiltfile to create it. Used by tilt alpha create local-resource.",
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/overlayLocalResource"}}],
+        "responses": {
+          "200": {"description": "A successful response."},
+          "400": {"description": "Invalid payload, or no Tiltfile loaded."},
+          "409": {"description": "A resource with that name already exists."}
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/build_history/{name}": {
+      "get": {
+        "operationId": "GetBuildHistory",
+        "description": "Lists a resource's completed builds, most recent first, with their logs. Tilt keeps update_settings(build_history_limit=) builds per resource.",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "type": "string", "description": "The resource name, or (Tiltfile)."},
+          {"name": "offset", "in": "query", "required": false, "type": "integer", "format": "int32", "default": 0},
+          {"name": "limit", "in": "query", "required": false, "type": "integer", "format": "int32", "default": 10, "maximum": 100}
+        ],
+        "responses": {
+          "200": {"description": "A page of builds.", "schema": {"$ref": "#/definitions/serverBuildHistoryPage"}},
+          "400": {"description": "Invalid offset or limit."},
+          "404": {"description": "Unknown resource."}
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/dump/goroutines": {
+      "get": {
+        "operationId": "DumpGoroutines",
+        "description": "Writes the stack of every goroutine in Tilt, for reporting hangs. Used by tilt dump goroutines.",
+        "produces": ["text/plain"],
+        "responses": {"200": {"description": "The stacks, in the format of a Go panic."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/listeners": {
+      "get": {
+        "operationId": "ListListeners",
+        "description": "Lists every local port that Tilt listens on, like the web server, port-forwards, and wake proxies. Used by tilt get forwards.",
+        "responses": {
+          "200": {
+            "description": "The listeners, sorted by address.",
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/listenersStatus"}}
+          }
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/container_files/{name}": {
+      "get": {
+        "operationId": "ListContainerFiles",
+        "description": "Lists a directory in a resource's running container, for the web UI's file browser.",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "type": "string"},
+          {"name": "path", "in": "query", "required": false, "type": "string", "default": "/", "description": "An absolute path in the container."},
+          {"name": "container", "in": "query", "required": false, "type": "string", "description": "Defaults to the pod's first container."}
+        ],
+        "responses": {
+          "200": {"description": "The directory's entries.", "schema": {"$ref": "#/definitions/serverContainerFilesPage"}},
+          "400": {"description": "Invalid path, or the resource doesn't run in Kubernetes."},
+          "404": {"description": "Unknown resource, or no running pod or container."},
+          "502": {"description": "Listing the directory in the container failed."}
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/container_files/{name}/content": {
+      "get": {
+        "operationId": "GetContainerFileContent",
+        "description": "Serves the contents of a file in a resource's running container. Refuses files bigger than 1MB.",
+        "produces": ["application/octet-stream"],
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "type": "string"},
+          {"name": "path", "in": "query", "required": true, "type": "string", "description": "An absolute path in the container."},
+          {"name": "container", "in": "query", "required": false, "type": "string", "description": "Defaults to the pod's first container."}
+        ],
+        "responses": {
+          "200": {"description": "The file's contents.", "schema": {"type": "file"}},
+          "400": {"description": "Invalid path, or the resource doesn't run in Kubernetes."},
+          "404": {"description": "Unknown resource, or no running pod or container."},
+          "413": {"description": "The file is larger than 1MB."},
+          "502": {"description": "Reading the file in the container failed."}
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/build_queue": {
+      "get": {
+        "operationId": "GetBuildQueue",
+        "description": "Lists the resources waiting to build, in the order Tilt expects to build them, and why each one can't build yet.",
+        "responses": {
+          "200": {"description": "The queue.", "schema": {"$ref": "#/definitions/serverBuildQueuePage"}}
+        },
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/build_queue/clear": {
+      "post": {
+        "operationId": "ClearBuildQueue",
+        "description": "Skips the builds waiting in the queue. Builds that are already running finish.",
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/dump/engine": {
+      "get": {
+        "operationId": "DumpEngine",
+        "description": "Dumps Tilt's internal engine state, for debugging. The format isn't stable. Used by tilt dump engine.",
+        "responses": {"200": {"description": "The engine state.", "schema": {"type": "object"}}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/snapshot/{snapshot_id}": {
+      "get": {
+        "operationId": "GetLocalSnapshot",
+        "description": "Serves a snapshot of the current view, whatever the snapshot_id. Only used for testing snapshots in development.",
+        "parameters": [{"name": "snapshot_id", "in": "path", "required": true, "type": "string"}],
+        "responses": {"200": {"description": "A snapshot.", "schema": {"$ref": "#/definitions/webviewSnapshot"}}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/user_started_tilt_cloud_registration": {
+      "post": {
+        "operationId": "UserStartedTiltCloudRegistration",
+        "description": "Tells Tilt that the user opened the Tilt Cloud sign-up page, so that it checks whether the token is registered more often.",
+        "responses": {"200": {"description": "A successful response."}},
+        "tags": ["HeadsUpServer"]
+      }
+    },
+    "/api/v1alpha1/{kind}": {
+      "get": {
+        "operationId": "ListObjects",
+        "description": "Lists every object of a kind, as a SessionList, ResourceList, BuildList, or LogSpanList. With watch=true, instead streams a v1alpha1WatchEvent per line for each change, starting with an ADDED event for every existing object.",
+        "parameters": [
+          {"name": "kind", "in": "path", "required": true, "type": "string", "enum": ["sessions", "resources", "builds", "logspans"]},
+          {"name": "watch", "in": "query", "required": false, "type": "boolean"}
+        ],
+        "responses": {
+          "200": {"description": "A list of objects, or a stream of v1alpha1WatchEvents.", "schema": {"type": "object"}},
+          "404": {"description": "Unknown kind."}
+        },
+        "tags": ["TiltAPI"]
+      }
+    },
+    "/api/v1alpha1/{kind}/{name}": {
+      "get": {
+        "operationId": "GetObject",
+        "description": "Gets one object, as a v1alpha1Session, v1alpha1Resource, v1alpha1Build, or v1alpha1LogSpan.",
+        "parameters": [
+          {"name": "kind", "in": "path", "required": true, "type": "string", "enum": ["sessions", "resources", "builds", "logspans"]},
+          {"name": "name", "in": "path", "required": true, "type": "string"}
+        ],
+        "responses": {
+          "200": {"description": "The object.", "schema": {"type": "object"}},
+          "404": {"description": "Unknown kind or name."}
+        },
+        "tags": ["TiltAPI"]
+      }
+    }
+  },
+  "definitions": {
+    "serverTriggerPayload": {
+      "type": "object",
+      "properties": {
+        "manifest_names": {"type": "array", "items": {"type": "string"}},
+        "build_reason": {"type": "integer", "format": "int32", "description": "A bitmask of model.BuildReason flags."}
+      }
+    },
+    "serverActionPayload": {
+      "type": "object",
+      "properties": {
+        "type": {"type": "string", "enum": ["PodResetRestarts", "SetTrafficCapture", "CronJobRunNow"]},
+        "manifest_name": {"type": "string"},
+        "pod_id": {"type": "string"},
+        "visible_restarts": {"type": "integer", "format": "int32"},
+        "traffic_capture": {"type": "string", "enum": ["off", "requests", "bodies"], "description": "For SetTrafficCapture: what to log about the HTTP requests through the resource's port forwards."}
+      }
+    },
+    "serverAnalyticsPayload": {
+      "type": "object",
+      "properties": {
+        "verb": {"type": "string", "enum": ["incr"]},
+        "name": {"type": "string"},
+        "tags": {"type": "object", "additionalProperties": {"type": "string"}}
+      }
+    },
+    "serverAnalyticsOptPayload": {
+      "type": "object",
+      "properties": {
+        "opt": {"type": "string", "enum": ["opt-in", "opt-out"]}
+      }
+    },
+    "serverSetEnvPayload": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "value": {"type": "string"},
+        "unset": {"type": "boolean"}
+      }
+    },
+    "analyticsLocalEvent": {
+      "type": "object",
+      "properties": {
+        "Time": {"type": "string", "format": "date-time"},
+        "Name": {"type": "string"},
+        "Category": {"type": "string", "enum": ["usage", "performance", "errors"]},
+        "Tags": {"type": "object", "additionalProperties": {"type": "string"}},
+        "N": {"type": "integer", "format": "int64"},
+        "Duration": {"type": "integer", "format": "int64", "description": "In nanoseconds."},
+        "Sent": {"type": "boolean", "description": "Whether Tilt passed the event on to the analytics server."}
+      }
+    },
+    "serverAckAlertsPayload": {
+      "type": "object",
+      "properties": {
+        "ids": {"type": "array", "items": {"type": "string"}, "description": "The alerts to acknowledge. If empty, acknowledges every alert."}
+      }
+    },
+    "overlayLocalResource": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string"},
+        "cmd": {"type": "string"},
+        "serve_cmd": {"type": "string", "description": "Either cmd or serve_cmd is required."},
+        "deps": {"type": "array", "items": {"type": "string"}, "description": "Relative to the Tiltfile's directory, unless absolute."},
+        "resource_deps": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "serverBuildHistoryPage": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "total": {"type": "integer", "format": "int32", "description": "The number of builds Tilt has for this resource, across all pages."},
+        "offset": {"type": "integer", "format": "int32"},
+        "builds": {"type": "array", "items": {"$ref": "#/definitions/serverBuildHistoryEntry"}}
+      }
+    },
+    "serverBuildHistoryEntry": {
+      "type": "object",
+      "properties": {
+        "spanId": {"type": "string"},
+        "startTime": {"type": "string", "format": "date-time"},
+        "finishTime": {"type": "string", "format": "date-time"},
+        "reason": {"type": "string"},
+        "edits": {"type": "array", "items": {"type": "string"}},
+        "error": {"type": "string"},
+        "warningCount": {"type": "integer", "format": "int32"},
+        "log": {"type": "string"},
+        "deploySkipped": {"type": "boolean", "description": "True if the rendered YAML was unchanged since the last deploy, so Tilt didn't apply it."},
+        "imageLayers": {"type": "array", "items": {"$ref": "#/definitions/serverImageLayers"}, "description": "Only for images built with update_settings(image_layer_analysis=True)."}
+      }
+    },
+    "serverImageLayers": {
+      "type": "object",
+      "properties": {
+        "image": {"type": "string"},
+        "size": {"type": "integer", "format": "int64"},
+        "wastedBytes": {"type": "integer", "format": "int64"},
+        "efficiency": {"type": "number", "format": "double"},
+        "layers": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "createdBy": {"type": "string"},
+              "size": {"type": "integer", "format": "int64"},
+              "largestFiles": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "properties": {
+                    "path": {"type": "string"},
+                    "size": {"type": "integer", "format": "int64"}
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "listenersStatus": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string", "enum": ["web-server", "port-forward", "service-forward", "wake-proxy"]},
+        "resource": {"type": "string"},
+        "protocol": {"type": "string"},
+        "address": {"type": "string"},
+        "target": {"type": "string", "description": "Where traffic goes, e.g., pod/frontend-abc123:8080."},
+        "state": {"type": "string", "enum": ["starting", "listening", "error"]},
+        "error": {"type": "string"},
+        "since": {"type": "string", "format": "date-time", "description": "When the listener last changed state."},
+        "connections": {"type": "integer", "format": "int64"},
+        "bytesIn": {"type": "integer", "format": "int64"},
+        "bytesOut": {"type": "integer", "format": "int64"},
+        "lastActivity": {"type": "string", "format": "date-time"}
+      }
+    },
+    "serverContainerFilesPage": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "pod": {"type": "string"},
+        "container": {"type": "string"},
+        "path": {"type": "string"},
+        "entries": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string"},
+              "type": {"type": "string", "enum": ["dir", "file", "link"]}
+            }
+          }
+        }
+      }
+    },
+    "serverBuildQueuePage": {
+      "type": "object",
+      "properties": {
+        "builds": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string"},
+              "position": {"type": "integer", "format": "int32"},
+              "reason": {"type": "string", "description": "Why it needs to build, e.g. Changed Files or Web Trigger."},
+              "hold": {"type": "string", "description": "Why it can't build yet, if anything, e.g. waiting-for-dep."},
+              "holdDescription": {"type": "string"},
+              "pendingSince": {"type": "string", "format": "date-time"}
+            }
+          }
+        }
+      }
+    },
+    "v1alpha1ObjectMeta": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "resourceVersion": {"type": "string", "description": "Opaque. Changes whenever the object changes."},
+        "creationTimestamp": {"type": "string", "format": "date-time"}
+      }
+    },
+    "v1alpha1Session": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string"},
+        "apiVersion": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/v1alpha1ObjectMeta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "tiltfilePath": {"type": "string"}
+          }
+        },
+        "status": {
+          "type": "object",
+          "properties": {
+            "version": {"type": "string"},
+            "startTime": {"type": "string", "format": "date-time"},
+            "fatalError": {"type": "string"}
+          }
+        }
+      }
+    },
+    "v1alpha1Resource": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string"},
+        "apiVersion": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/v1alpha1ObjectMeta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "type": {"type": "string", "enum": ["tiltfile", "k8s", "docker-compose", "local", "unknown"]},
+            "triggerMode": {"type": "string", "enum": ["auto", "manual_after_initial", "manual_including_initial"], "default": "auto"},
+            "resourceDeps": {"type": "array", "items": {"type": "string"}}
+          }
+        },
+        "status": {
+          "type": "object",
+          "properties": {
+            "updateStatus": {"type": "string", "enum": ["none", "pending", "in_progress", "ok", "error"], "default": "none"},
+            "runtimeStatus": {"type": "string", "enum": ["pending", "ok", "error", "not_applicable", "unknown"], "default": "unknown"},
+            "currentBuild": {"type": "string"},
+            "lastDeployTime": {"type": "string", "format": "date-time"},
+            "hasPendingChanges": {"type": "boolean"}
+          }
+        }
+      }
+    },
+    "v1alpha1Build": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string"},
+        "apiVersion": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/v1alpha1ObjectMeta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "resource": {"type": "string"},
+            "reason": {"type": "string"},
+            "edits": {"type": "array", "items": {"type": "string"}}
+          }
+        },
+        "status": {
+          "type": "object",
+          "properties": {
+            "startTime": {"type": "string", "format": "date-time"},
+            "finishTime": {"type": "string", "format": "date-time"},
+            "error": {"type": "string"},
+            "warningCount": {"type": "integer", "format": "int32"}
+          }
+        }
+      }
+    },
+    "v1alpha1LogSpan": {
+      "type": "object",
+      "properties": {
+        "kind": {"type": "string"},
+        "apiVersion": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/v1alpha1ObjectMeta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "resource": {"type": "string"}
+          }
+        },
+        "status": {
+          "type": "object",
+          "properties": {
+            "firstCheckpoint": {"type": "integer", "format": "int64"},
+            "lastCheckpoint": {"type": "integer", "format": "int64"}
+          }
+        }
+      }
+    },
+    "v1alpha1WatchEvent": {
+      "type": "object",
+      "properties": {
+        "type": {"type": "string", "enum": ["ADDED", "MODIFIED", "DELETED"]},
+        "object": {"type": "object", "description": "A v1alpha1Session, v1alpha1Resource, v1alpha1Build, or v1alpha1LogSpan. For DELETED events, the last version of the object."}
+      }
+    }
+  }
+}`
+
+// APISchema returns a swagger 2.0 document describing every JSON type
+// served by the HUD server, including the webview protocol.
+func APISchema() (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	err := json.Unmarshal([]byte(proto_webview.SwaggerJSON), &doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding webview schema")
+	}
+
+	var extra map[string]interface{}
+	err = json.Unmarshal([]byte(hudServerSchemaJSON), &extra)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding server schema")
+	}
+
+	for _, key := range []string{"paths", "definitions"} {
+		dst, ok := doc[key].(map[string]interface{})
+		if !ok {
+			dst = make(map[string]interface{})
+			doc[key] = dst
+		}
+		src, _ := extra[key].(map[string]interface{})
+		for k, v := range src {
+			if _, exists := dst[k]; exists {
+				return nil, fmt.Errorf("duplicate %s entry in schema: %s", key, k)
+			}
+			dst[k] = v
+		}
+	}
+
+	doc["info"] = map[string]interface{}{
+		"title":   "Tilt HUD server",
+		"version": "v1alpha1",
+	}
+	return doc, nil
+}
+
+func (s *HeadsUpServer) SchemaJSON(w http.ResponseWriter, req *http.Request) {
+	doc, err := APISchema()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error building schema: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(doc)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering schema: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/assets"
+)
+
+// Every route that the server handles should be in the schema, so that
+// clients that codegen against it can call the whole API.
+func TestSchemaCoversRoutes(t *testing.T) {
+	s, err := ProvideHeadsUpServer(context.Background(), nil, assets.NewFakeServer(), nil, nil, "", nil, nil, nil)
+	require.NoError(t, err)
+
+	doc, err := APISchema()
+	require.NoError(t, err)
+	paths := doc["paths"].(map[string]interface{})
+
+	count := 0
+	err = s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		// The web UI's assets.
+		if tmpl == "/" {
+			return nil
+		}
+		count++
+
+		entry, ok := paths[tmpl].(map[string]interface{})
+		if !assert.True(t, ok, "route %s is missing from the schema", tmpl) {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// The route takes any method, so the schema can document whichever it expects.
+			assert.NotEmpty(t, entry, "route %s has no operations in the schema", tmpl)
+			return nil
+		}
+		for _, m := range methods {
+			assert.Contains(t, entry, strings.ToLower(m), "route %s %s is missing from the schema", m, tmpl)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, count)
+}
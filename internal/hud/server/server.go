@@ -9,6 +9,10 @@ import (
 	"log"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"runtime/pprof"
+	"strings"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -20,10 +24,13 @@ import (
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/cloud"
 	"github.com/tilt-dev/tilt/internal/hud/webview"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/tiltfile/overlay"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -32,6 +39,10 @@ import (
 const httpTimeOut = 5 * time.Second
 const TiltTokenCookieName = "Tilt-Token"
 
+// Tells the web UI what path it lives under, so that it can build
+// URLs that go through the reverse proxy.
+const TiltBasePathCookieName = "Tilt-Base-Path"
+
 type analyticsPayload struct {
 	Verb string            `json:"verb"`
 	Name string            `json:"name"`
@@ -45,6 +56,26 @@ type analyticsOptPayload struct {
 type triggerPayload struct {
 	ManifestNames []string          `json:"manifest_names"`
 	BuildReason   model.BuildReason `json:"build_reason"`
+
+	// Only trigger the resource if its deps changed since the last successful run.
+	IfChanged bool `json:"if_changed"`
+}
+
+type setEnvPayload struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+
+	// Unset the variable, instead of setting it to Value.
+	Unset bool `json:"unset"`
+}
+
+type ackAlertsPayload struct {
+	// The alerts to acknowledge. If empty, acknowledges every alert.
+	IDs []string `json:"ids"`
+}
+
+type triggerResponse struct {
+	Skipped bool `json:"skipped"`
 }
 
 type actionPayload struct {
@@ -52,15 +83,27 @@ type actionPayload struct {
 	ManifestName    model.ManifestName `json:"manifest_name"`
 	PodID           k8s.PodID          `json:"pod_id"`
 	VisibleRestarts int                `json:"visible_restarts"`
+	TrafficCapture  string             `json:"traffic_capture"`
 }
 
 type HeadsUpServer struct {
 	ctx               context.Context
 	store             *store.Store
 	router            *mux.Router
+	handler           http.Handler
 	a                 *tiltanalytics.TiltAnalytics
 	uploader          cloud.SnapshotUploader
+	basePath          model.WebBasePath
+	corsOrigins       model.WebCORSOrigins
+	listeners         *listeners.Registry
+	kClient           k8s.Client
 	numWebsocketConns int32
+
+	websocketsMu sync.Mutex
+	websockets   map[*WebsocketSubscriber]bool
+
+	// Serializes read-modify-writes of the session overlay.
+	overlayMu sync.Mutex
 }
 
 func ProvideHeadsUpServer(
@@ -68,31 +111,57 @@ func ProvideHeadsUpServer(
 	store *store.Store,
 	assetServer assets.Server,
 	analytics *tiltanalytics.TiltAnalytics,
-	uploader cloud.SnapshotUploader) (*HeadsUpServer, error) {
+	uploader cloud.SnapshotUploader,
+	basePath model.WebBasePath,
+	corsOrigins model.WebCORSOrigins,
+	listeners *listeners.Registry,
+	kClient k8s.Client) (*HeadsUpServer, error) {
 	r := mux.NewRouter().UseEncodedPath()
 	s := &HeadsUpServer{
-		ctx:      ctx,
-		store:    store,
-		router:   r,
-		a:        analytics,
-		uploader: uploader,
+		ctx:         ctx,
+		store:       store,
+		router:      r,
+		a:           analytics,
+		uploader:    uploader,
+		basePath:    basePath,
+		corsOrigins: corsOrigins,
+		listeners:   listeners,
+		kClient:     kClient,
+		websockets:  make(map[*WebsocketSubscriber]bool),
 	}
 
-	r.HandleFunc("/api/view", s.ViewJSON)
-	r.HandleFunc("/api/dump/engine", s.DumpEngineJSON)
+	r.HandleFunc("/api/view", gzipHandler(s.ViewJSON))
+	r.HandleFunc("/api/schema", s.SchemaJSON)
+	r.HandleFunc("/api/dump/engine", gzipHandler(s.DumpEngineJSON))
+	r.HandleFunc("/api/dump/analytics", s.DumpAnalyticsJSON)
+	r.HandleFunc("/api/dump/goroutines", s.DumpGoroutines)
 	r.HandleFunc("/api/analytics", s.HandleAnalytics)
 	r.HandleFunc("/api/analytics_opt", s.HandleAnalyticsOpt)
 	r.HandleFunc("/api/trigger", s.HandleTrigger)
+	r.HandleFunc("/api/listeners", s.ListenersJSON).Methods("GET")
 	r.HandleFunc("/api/action", s.DispatchAction).Methods("POST")
 	r.HandleFunc("/api/snapshot/new", s.HandleNewSnapshot).Methods("POST")
 	// this endpoint is only used for testing snapshots in development
-	r.HandleFunc("/api/snapshot/{snapshot_id}", s.SnapshotJSON)
+	r.HandleFunc("/api/snapshot/{snapshot_id}", gzipHandler(s.SnapshotJSON))
 	r.HandleFunc("/ws/view", s.ViewWebsocket)
 	r.HandleFunc("/api/user_started_tilt_cloud_registration", s.userStartedTiltCloudRegistration)
+	r.HandleFunc(RelinkTiltCloudTokenPath, s.relinkTiltCloudToken).Methods("GET")
 	r.HandleFunc("/api/set_tiltfile_args", s.HandleSetTiltfileArgs).Methods("POST")
+	r.HandleFunc("/api/env", s.HandleSetEnv).Methods("POST")
+	r.HandleFunc("/api/alerts/ack", s.HandleAckAlerts).Methods("POST")
+	r.HandleFunc("/api/build_history/{name}", gzipHandler(s.BuildHistoryJSON)).Methods("GET")
+	r.HandleFunc("/api/build_queue", s.BuildQueueJSON).Methods("GET")
+	r.HandleFunc("/api/build_queue/clear", s.HandleClearBuildQueue).Methods("POST")
+	r.HandleFunc("/api/container_files/{name}", s.ContainerFilesJSON).Methods("GET")
+	r.HandleFunc("/api/container_files/{name}/content", s.ContainerFileContent).Methods("GET")
+	r.HandleFunc("/api/overlay/local_resource", s.HandleCreateLocalResource).Methods("POST")
+	r.HandleFunc("/api/v1alpha1/{kind}", s.ListAPIObjects).Methods("GET")
+	r.HandleFunc("/api/v1alpha1/{kind}/{name}", s.GetAPIObject).Methods("GET")
 
 	r.PathPrefix("/").Handler(s.cookieWrapper(assetServer))
 
+	s.handler = s.basePathHandler(s.corsHandler(r))
+
 	return s, nil
 }
 
@@ -109,12 +178,13 @@ func (s *HeadsUpServer) cookieWrapper(handler http.Handler) http.Handler {
 		state := s.store.RLockState()
 		http.SetCookie(w, &http.Cookie{Name: TiltTokenCookieName, Value: string(state.Token), Path: "/"})
 		s.store.RUnlockState()
+		http.SetCookie(w, &http.Cookie{Name: TiltBasePathCookieName, Value: string(s.basePath), Path: "/"})
 		handler.ServeHTTP(w, r)
 	}}
 }
 
 func (s *HeadsUpServer) Router() http.Handler {
-	return s.router
+	return s.handler
 }
 
 func (s *HeadsUpServer) ViewJSON(w http.ResponseWriter, req *http.Request) {
@@ -147,6 +217,34 @@ func (s *HeadsUpServer) DumpEngineJSON(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+func (s *HeadsUpServer) DumpAnalyticsJSON(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(s.a.LocalEvents())
+	if err != nil {
+		log.Printf("Error encoding: %v", err)
+	}
+}
+
+// Lists every local port that Tilt listens on. Used by 'tilt get forwards'.
+func (s *HeadsUpServer) ListenersJSON(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(s.listeners.List())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering listeners: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Writes the stack of every goroutine, for reporting hangs.
+//
+// For CPU and heap profiles, use the standard pprof endpoints under /debug/pprof/.
+func (s *HeadsUpServer) DumpGoroutines(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	err := pprof.Lookup("goroutine").WriteTo(w, 2)
+	if err != nil {
+		log.Printf("Error writing goroutines: %v", err)
+	}
+}
+
 func (s *HeadsUpServer) SnapshotJSON(w http.ResponseWriter, req *http.Request) {
 	state := s.store.RLockState()
 	view, err := webview.StateToProtoView(state, 0)
@@ -228,6 +326,102 @@ func (s *HeadsUpServer) HandleSetTiltfileArgs(w http.ResponseWriter, req *http.R
 	s.store.Dispatch(SetTiltfileArgsAction{args})
 }
 
+// Changes an environment variable of the running Tilt. The Tiltfile runs in
+// this process, so if it read the variable, it reloads with the new value.
+func (s *HeadsUpServer) HandleSetEnv(w http.ResponseWriter, req *http.Request) {
+	var payload setEnvPayload
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if payload.Name == "" || strings.Contains(payload.Name, "=") {
+		http.Error(w, fmt.Sprintf("invalid environment variable name: %q", payload.Name), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Unset {
+		err = os.Unsetenv(payload.Name)
+	} else {
+		err = os.Setenv(payload.Name, payload.Value)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error setting %s: %v", payload.Name, err), http.StatusBadRequest)
+		return
+	}
+
+	// Only pass along a digest, so that the value never enters the store.
+	action := SetEnvAction{Name: payload.Name, Set: !payload.Unset}
+	if action.Set {
+		action.Digest = model.EnvValueDigest(payload.Value)
+	}
+	s.store.Dispatch(action)
+}
+
+func (s *HeadsUpServer) HandleAckAlerts(w http.ResponseWriter, req *http.Request) {
+	var payload ackAlertsPayload
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Dispatch(store.AlertsAcknowledgedAction{IDs: payload.IDs})
+}
+
+// Adds a local resource to the session overlay. The Tiltfile loader watches
+// the overlay, so writing it kicks off a reload that creates the resource.
+func (s *HeadsUpServer) HandleCreateLocalResource(w http.ResponseWriter, req *http.Request) {
+	var payload overlay.LocalResource
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = payload.Validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state := s.store.RLockState()
+	tiltfilePath := state.TiltfilePath
+	_, exists := state.Manifest(model.ManifestName(payload.Name))
+	s.store.RUnlockState()
+
+	if tiltfilePath == "" {
+		http.Error(w, "no Tiltfile loaded", http.StatusBadRequest)
+		return
+	}
+	if exists {
+		http.Error(w, fmt.Sprintf("resource %s already exists", payload.Name), http.StatusConflict)
+		return
+	}
+
+	s.overlayMu.Lock()
+	defer s.overlayMu.Unlock()
+
+	path := overlay.Path(tiltfilePath)
+	o, err := overlay.Read(path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading overlay: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	o, err = o.WithLocalResource(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	err = overlay.Write(path, o)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error writing overlay: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (s *HeadsUpServer) DispatchAction(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "must be POST request", http.StatusBadRequest)
@@ -246,6 +440,26 @@ func (s *HeadsUpServer) DispatchAction(w http.ResponseWriter, req *http.Request)
 	case "PodResetRestarts":
 		s.store.Dispatch(
 			store.NewPodResetRestartsAction(payload.PodID, payload.ManifestName, payload.VisibleRestarts))
+	case "SetTrafficCapture":
+		mode, err := model.ParseTrafficCaptureMode(payload.TrafficCapture)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.store.Dispatch(store.TrafficCaptureAction{ManifestName: payload.ManifestName, Mode: mode})
+	case "CronJobRunNow":
+		state := s.store.RLockState()
+		m, ok := state.Manifest(payload.ManifestName)
+		s.store.RUnlockState()
+		if !ok {
+			http.Error(w, fmt.Sprintf("no manifest found with name '%s'", payload.ManifestName), http.StatusNotFound)
+			return
+		}
+		if !m.IsK8s() {
+			http.Error(w, fmt.Sprintf("resource %s doesn't deploy to Kubernetes", payload.ManifestName), http.StatusBadRequest)
+			return
+		}
+		s.store.Dispatch(store.CronJobRunAction{ManifestName: payload.ManifestName})
 	default:
 		http.Error(w, fmt.Sprintf("Unknown action type: %s", payload.Type), http.StatusBadRequest)
 	}
@@ -272,11 +486,62 @@ func (s *HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if payload.IfChanged {
+		unchanged, err := depsUnchangedSinceLastRun(s.store, payload.ManifestNames[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if unchanged {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(triggerResponse{Skipped: true})
+			return
+		}
+	}
+
 	err = SendToTriggerQueue(s.store, payload.ManifestNames[0], payload.BuildReason)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+
+	if payload.IfChanged {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(triggerResponse{})
+	}
+}
+
+// Returns true if the resource is a local resource whose last run succeeded,
+// and the contents of its deps haven't changed since.
+//
+// For other kinds of resources, we can't tell, so always return false.
+func depsUnchangedSinceLastRun(st store.RStore, name string) (bool, error) {
+	mName := model.ManifestName(name)
+
+	state := st.RLockState()
+	mt, ok := state.ManifestTargets[mName]
+	isLocal := false
+	var lastBuild model.BuildRecord
+	var lt model.LocalTarget
+	if ok {
+		isLocal = mt.Manifest.IsLocal()
+		lastBuild = mt.State.LastBuild()
+		lt = mt.Manifest.LocalTarget()
+	}
+	st.RUnlockState()
+
+	if !ok {
+		return false, fmt.Errorf("no manifest found with name '%s'", mName)
+	}
+	if !isLocal || lastBuild.Error != nil || lastBuild.DepsHash == "" {
+		return false, nil
+	}
+
+	depsHash, err := build.DepsHash(lt)
+	if err != nil {
+		return false, err
+	}
+	return depsHash == lastBuild.DepsHash, nil
 }
 
 func SendToTriggerQueue(st store.RStore, name string, buildReason model.BuildReason) error {
@@ -323,7 +588,15 @@ func (s *HeadsUpServer) HandleNewSnapshot(w http.ResponseWriter, req *http.Reque
 	teamID := st.TeamID
 	s.store.RUnlockState()
 
-	b, err := ioutil.ReadAll(req.Body)
+	body, err := requestBody(req)
+	if err != nil {
+		msg := fmt.Sprintf("error decompressing body: %v", err)
+		log.Println(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	b, err := ioutil.ReadAll(body)
 	if err != nil {
 		msg := fmt.Sprintf("error reading body: %v", err)
 		log.Println(msg)
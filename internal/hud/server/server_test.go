@@ -2,10 +2,13 @@ package server_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -15,16 +18,22 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/testutils"
 
+	"github.com/gorilla/websocket"
 	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/cloud"
 	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
 	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/tiltfile/overlay"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -74,6 +83,68 @@ func TestHandleAnalyticsRecordsIncr(t *testing.T) {
 	f.assertIncrement("foo", 1)
 }
 
+func TestDumpAnalytics(t *testing.T) {
+	f := newTestFixture(t)
+	f.ta.Incr("foo", map[string]string{"bar": "baz"})
+
+	req, err := http.NewRequest(http.MethodGet, "/api/dump/analytics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(f.serv.DumpAnalyticsJSON)
+
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var events []tiltanalytics.LocalEvent
+	err = json.Unmarshal(rr.Body.Bytes(), &events)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "foo", events[0].Name)
+	assert.Equal(t, tiltanalytics.CategoryUsage, events[0].Category)
+	assert.Equal(t, map[string]string{"bar": "baz"}, events[0].Tags)
+	assert.True(t, events[0].Sent)
+}
+
+func TestDumpGoroutines(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/dump/goroutines", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(f.serv.DumpGoroutines).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "TestDumpGoroutines")
+}
+
+func TestListeners(t *testing.T) {
+	f := newTestFixture(t)
+	e := f.listeners.Add(listeners.KindPortForward, "fe", "tcp", "localhost:8080", "pod/fe-abc123:80")
+	e.SetListening()
+	e.AddConnection()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/listeners", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var statuses []listeners.Status
+	err = json.Unmarshal(rr.Body.Bytes(), &statuses)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.ManifestName("fe"), statuses[0].Resource)
+	assert.Equal(t, "pod/fe-abc123:80", statuses[0].Target)
+	assert.Equal(t, listeners.StateListening, statuses[0].State)
+	assert.Equal(t, int64(1), statuses[0].Connections)
+}
+
 func TestHandleAnalyticsNonPost(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -302,6 +373,39 @@ func TestHandleTriggerMalformedPayload(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), "error parsing JSON")
 }
 
+func TestHandleTriggerIfChanged(t *testing.T) {
+	f := newTestFixture(t)
+	tmp := tempdir.NewTempDirFixture(t)
+	defer tmp.TearDown()
+
+	tmp.WriteFile("src/a.txt", "a")
+	lt := model.NewLocalTarget("foobar", model.ToHostCmd("make"), model.Cmd{}, []string{tmp.JoinPath("src")}, tmp.Path())
+	depsHash, err := build.DepsHash(lt)
+	require.NoError(t, err)
+
+	mt := store.NewManifestTarget(model.Manifest{Name: "foobar"}.WithDeployTarget(lt))
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now(), DepsHash: depsHash})
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(mt)
+	f.st.UnlockMutableState()
+
+	trigger := func() string {
+		var jsonStr = []byte(`{"manifest_names":["foobar"], "if_changed": true}`)
+		req, err := http.NewRequest(http.MethodPost, "/api/trigger", bytes.NewBuffer(jsonStr))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(f.serv.HandleTrigger).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		return rr.Body.String()
+	}
+
+	assert.Contains(t, trigger(), `"skipped":true`)
+
+	tmp.WriteFile("src/a.txt", "b")
+	assert.Contains(t, trigger(), `"skipped":false`)
+	store.WaitForAction(t, reflect.TypeOf(server.AppendToTriggerQueueAction{}), f.getActions)
+}
+
 func TestSendToTriggerQueue_manualManifest(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("TODO(nick): fix this")
@@ -375,10 +479,11 @@ func TestHandleNewSnapshot(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	req, err := http.NewRequest(http.MethodPost, "/api/snapshot/new", bytes.NewBuffer(snap))
+	req, err := http.NewRequest(http.MethodPost, "/api/snapshot/new", bytes.NewBuffer(gzipBytes(t, snap)))
 	if err != nil {
 		t.Fatal(err)
 	}
+	req.Header.Set("Content-Encoding", "gzip")
 
 	rr := httptest.NewRecorder()
 	handler := http.HandlerFunc(f.serv.HandleNewSnapshot)
@@ -391,16 +496,195 @@ func TestHandleNewSnapshot(t *testing.T) {
 
 	lastReq := f.snapshotHTTP.lastReq
 	if assert.NotNil(t, lastReq) {
+		require.Equal(t, "gzip", lastReq.Header.Get("Content-Encoding"))
+		body, err := gzip.NewReader(lastReq.Body)
+		require.NoError(t, err)
+
 		var snapshot proto_webview.Snapshot
 		jspb := &grpcRuntime.JSONPb{OrigName: false, EmitDefaults: true}
-		decoder := jspb.NewDecoder(lastReq.Body)
-		err := decoder.Decode(&snapshot)
+		decoder := jspb.NewDecoder(body)
+		err = decoder.Decode(&snapshot)
 		require.NoError(t, err)
 		assert.Equal(t, "0.10.13", snapshot.View.RunningTiltBuild.Version)
 		assert.Equal(t, "43", snapshot.SnapshotHighlight.BeginningLogID)
 	}
 }
 
+func TestHandleNewSnapshotServerWithoutCompression(t *testing.T) {
+	f := newTestFixture(t)
+	f.snapshotHTTP.statusCodes = []int{http.StatusUnsupportedMediaType}
+
+	sp := filepath.Join("..", "webview", "testdata", "snapshot.json")
+	snap, err := ioutil.ReadFile(sp)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/api/snapshot/new", bytes.NewBuffer(snap))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(f.serv.HandleNewSnapshot).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	lastReq := f.snapshotHTTP.lastReq
+	if assert.NotNil(t, lastReq) {
+		assert.Equal(t, "", lastReq.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestViewJSONCompressed(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/view", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	body, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	var view proto_webview.View
+	jspb := &grpcRuntime.JSONPb{OrigName: false, EmitDefaults: true}
+	require.NoError(t, jspb.NewDecoder(body).Decode(&view))
+}
+
+func TestViewJSONGzipRefused(t *testing.T) {
+	f := newTestFixture(t)
+
+	for _, accept := range []string{"zstd", "gzip;q=0", "*, gzip;q=0"} {
+		req, err := http.NewRequest(http.MethodGet, "/api/view", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", accept)
+
+		rr := httptest.NewRecorder()
+		f.serv.Router().ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "", rr.Header().Get("Content-Encoding"), accept)
+	}
+}
+
+func TestViewWebsocketNegotiatesCompression(t *testing.T) {
+	f := newTestFixture(t)
+
+	server := httptest.NewServer(f.serv.Router())
+	defer server.Close()
+
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = true
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/view", nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+	assert.Contains(t, resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+}
+
+func TestViewJSONUncompressed(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/view", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "", rr.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(rr.Body.String(), "{"))
+}
+
+func TestBasePathStrippedByProxy(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "/tilt", nil)
+
+	rr := f.get("/api/view", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestBasePathNotStrippedByProxy(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "/tilt", nil)
+
+	rr := f.get("/tilt/api/view", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestBasePathRedirectsToSlash(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "/tilt", nil)
+
+	rr := f.get("/tilt?foo=bar", nil)
+	require.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/tilt/?foo=bar", rr.Header().Get("Location"))
+}
+
+func TestBasePathCookie(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "/tilt", nil)
+
+	rr := f.get("/tilt/", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var basePath string
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == server.TiltBasePathCookieName {
+			basePath = c.Value
+		}
+	}
+	assert.Equal(t, "/tilt", basePath)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "", model.WebCORSOrigins{"https://ide.example.com"})
+
+	req, err := http.NewRequest(http.MethodOptions, "/api/trigger", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://ide.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "https://ide.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Allow-Methods"), "POST")
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "", model.WebCORSOrigins{"https://ide.example.com"})
+
+	rr := f.get("/api/view", map[string]string{"Origin": "https://ide.example.com"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://ide.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	f := newTestFixtureWithWebOptions(t, "", model.WebCORSOrigins{"https://ide.example.com"})
+
+	rr := f.get("/api/view", map[string]string{"Origin": "https://evil.example.com"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "", rr.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRelinkTiltCloudToken(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.Token = "my-token"
+	state.CloudAddress = "cloud.example.com"
+	f.st.UnlockMutableState()
+
+	req, err := http.NewRequest(http.MethodGet, server.RelinkTiltCloudTokenPath, nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `action="https://cloud.example.com/start_register_token"`)
+	assert.Contains(t, rr.Body.String(), `value="my-token"`)
+	store.WaitForAction(t, reflect.TypeOf(store.UserStartedTiltCloudRegistrationAction{}), f.getActions)
+}
+
 func TestSetTiltfileArgs(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -424,17 +708,269 @@ func TestSetTiltfileArgs(t *testing.T) {
 	assert.Equal(t, []string{"--foo", "bar", "as df"}, action.Args)
 }
 
+func TestSetEnv(t *testing.T) {
+	f := newTestFixture(t)
+	defer os.Unsetenv("TILT_TEST_SET_ENV")
+
+	req, err := http.NewRequest("POST", "/api/env", strings.NewReader(`{"name": "TILT_TEST_SET_ENV", "value": "hello"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(f.serv.HandleSetEnv).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "hello", os.Getenv("TILT_TEST_SET_ENV"))
+
+	a := store.WaitForAction(t, reflect.TypeOf(server.SetEnvAction{}), f.getActions)
+	assert.Equal(t, server.SetEnvAction{
+		Name:   "TILT_TEST_SET_ENV",
+		Set:    true,
+		Digest: model.EnvValueDigest("hello"),
+	}, a)
+}
+
+func TestUnsetEnv(t *testing.T) {
+	f := newTestFixture(t)
+	os.Setenv("TILT_TEST_SET_ENV", "hello")
+	defer os.Unsetenv("TILT_TEST_SET_ENV")
+
+	req, err := http.NewRequest("POST", "/api/env", strings.NewReader(`{"name": "TILT_TEST_SET_ENV", "unset": true}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(f.serv.HandleSetEnv).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	_, found := os.LookupEnv("TILT_TEST_SET_ENV")
+	assert.False(t, found)
+
+	a := store.WaitForAction(t, reflect.TypeOf(server.SetEnvAction{}), f.getActions)
+	assert.Equal(t, server.SetEnvAction{Name: "TILT_TEST_SET_ENV"}, a)
+}
+
+func TestSetEnvInvalidName(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/env", strings.NewReader(`{"name": "A=B", "value": "hello"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(f.serv.HandleSetEnv).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAckAlerts(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/alerts/ack", strings.NewReader(`{"ids": ["image-injection"]}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.AlertsAcknowledgedAction{}), f.getActions)
+	assert.Equal(t, store.AlertsAcknowledgedAction{IDs: []string{"image-injection"}}, a)
+}
+
+func TestSetTrafficCapture(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/action",
+		strings.NewReader(`{"type": "SetTrafficCapture", "manifest_name": "fe", "traffic_capture": "bodies"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.TrafficCaptureAction{}), f.getActions)
+	assert.Equal(t, store.TrafficCaptureAction{ManifestName: "fe", Mode: model.TrafficCaptureBodies}, a)
+}
+
+func TestSetTrafficCaptureInvalidMode(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/action",
+		strings.NewReader(`{"type": "SetTrafficCapture", "manifest_name": "fe", "traffic_capture": "everything"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `invalid traffic capture mode "everything"`)
+}
+
+func TestCronJobRunNow(t *testing.T) {
+	f := newTestFixture(t)
+	m := model.Manifest{Name: "report"}.WithDeployTarget(model.K8sTarget{Name: "report"})
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	f.st.UnlockMutableState()
+
+	req, err := http.NewRequest("POST", "/api/action",
+		strings.NewReader(`{"type": "CronJobRunNow", "manifest_name": "report"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.CronJobRunAction{}), f.getActions)
+	assert.Equal(t, store.CronJobRunAction{ManifestName: "report"}, a)
+}
+
+func TestCronJobRunNowNotFound(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/action",
+		strings.NewReader(`{"type": "CronJobRunNow", "manifest_name": "report"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAckAlertsMalformedPayload(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest("POST", "/api/alerts/ack", strings.NewReader(`{"ids": `))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "error parsing JSON payload")
+}
+
+func TestCreateLocalResource(t *testing.T) {
+	f := newTestFixture(t)
+	tmp := tempdir.NewTempDirFixture(t)
+	defer tmp.TearDown()
+	f.setTiltfilePath(tmp.JoinPath("Tiltfile"))
+
+	rr := f.createLocalResource(`{"name": "gen", "cmd": "make gen", "deps": ["./proto"]}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	o, err := overlay.Read(tmp.JoinPath(".tilt-overlay.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []overlay.LocalResource{{Name: "gen", Cmd: "make gen", Deps: []string{"./proto"}}}, o.LocalResources)
+
+	rr = f.createLocalResource(`{"name": "gen", "cmd": "make other"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "resource gen already exists")
+}
+
+func TestCreateLocalResourceExistingManifest(t *testing.T) {
+	f := newTestFixture(t)
+	tmp := tempdir.NewTempDirFixture(t)
+	defer tmp.TearDown()
+	f.setTiltfilePath(tmp.JoinPath("Tiltfile"))
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "gen"}))
+	f.st.UnlockMutableState()
+
+	rr := f.createLocalResource(`{"name": "gen", "cmd": "make gen"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "resource gen already exists")
+	assert.NoFileExists(t, tmp.JoinPath(".tilt-overlay.json"))
+}
+
+func TestCreateLocalResourceNoCmd(t *testing.T) {
+	f := newTestFixture(t)
+	tmp := tempdir.NewTempDirFixture(t)
+	defer tmp.TearDown()
+	f.setTiltfilePath(tmp.JoinPath("Tiltfile"))
+
+	rr := f.createLocalResource(`{"name": "gen"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "must have a cmd and/or a serve_cmd")
+}
+
+func TestSchemaJSON(t *testing.T) {
+	f := newTestFixture(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/schema", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "2.0", doc["swagger"])
+
+	paths := doc["paths"].(map[string]interface{})
+	assert.Contains(t, paths, "/api/view")
+	assert.Contains(t, paths, "/api/trigger")
+
+	defs := doc["definitions"].(map[string]interface{})
+	assert.Contains(t, defs, "webviewView")
+	assert.Contains(t, defs, "serverTriggerPayload")
+}
+
+// Make sure the Go copy of the swagger doc doesn't drift from the generated JSON.
+func TestSwaggerJSONUpToDate(t *testing.T) {
+	contents, err := ioutil.ReadFile(filepath.Join("..", "..", "..", "pkg", "webview", "view.swagger.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(contents), proto_webview.SwaggerJSON,
+		"pkg/webview/view.swagger.go is out of date. Run 'make proto'")
+}
+
 type serverFixture struct {
 	t            *testing.T
 	serv         *server.HeadsUpServer
 	a            *analytics.MemoryAnalytics
 	ta           *tiltanalytics.TiltAnalytics
+	listeners    *listeners.Registry
+	kCli         *k8s.FakeK8sClient
 	st           *store.Store
 	getActions   func() []store.Action
 	snapshotHTTP *fakeHTTPClient
 }
 
+func (f *serverFixture) setTiltfilePath(path string) {
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfilePath = path
+	f.st.UnlockMutableState()
+}
+
+func (f *serverFixture) createLocalResource(payload string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodPost, "/api/overlay/local_resource", strings.NewReader(payload))
+	require.NoError(f.t, err)
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *serverFixture) get(path string, headers map[string]string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	require.NoError(f.t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr
+}
+
 func newTestFixture(t *testing.T) *serverFixture {
+	return newTestFixtureWithWebOptions(t, "", nil)
+}
+
+func newTestFixtureWithWebOptions(t *testing.T, basePath model.WebBasePath, corsOrigins model.WebCORSOrigins) *serverFixture {
 	st, getActions := store.NewStoreWithFakeReducer()
 	go func() {
 		err := st.Loop(context.Background())
@@ -445,7 +981,9 @@ func newTestFixture(t *testing.T) *serverFixture {
 	snapshotHTTP := &fakeHTTPClient{}
 	addr := cloudurl.Address("nonexistent.example.com")
 	uploader := cloud.NewSnapshotUploader(snapshotHTTP, addr)
-	serv, err := server.ProvideHeadsUpServer(context.Background(), st, assets.NewFakeServer(), ta, uploader)
+	reg := listeners.NewRegistry()
+	kCli := k8s.NewFakeK8sClient()
+	serv, err := server.ProvideHeadsUpServer(context.Background(), st, assets.NewFakeServer(), ta, uploader, basePath, corsOrigins, reg, kCli)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -455,6 +993,8 @@ func newTestFixture(t *testing.T) *serverFixture {
 		serv:         serv,
 		a:            a,
 		ta:           ta,
+		listeners:    reg,
+		kCli:         kCli,
 		st:           st,
 		getActions:   getActions,
 		snapshotHTTP: snapshotHTTP,
@@ -463,13 +1003,22 @@ func newTestFixture(t *testing.T) *serverFixture {
 
 type fakeHTTPClient struct {
 	lastReq *http.Request
+
+	// Status codes to respond with before succeeding.
+	statusCodes []int
 }
 
 func (f *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	f.lastReq = req
 
+	statusCode := http.StatusOK
+	if len(f.statusCodes) > 0 {
+		statusCode = f.statusCodes[0]
+		f.statusCodes = f.statusCodes[1:]
+	}
+
 	return &http.Response{
-		StatusCode: http.StatusOK,
+		StatusCode: statusCode,
 		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"ID":"aaaaa"}`))),
 	}, nil
 }
@@ -484,3 +1033,12 @@ func (f *serverFixture) assertIncrement(name string, count int) {
 
 	assert.Equalf(f.t, count, runningCount, "Expected the total count to be %d, got %d", count, runningCount)
 }
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	buf := bytes.NewBuffer(nil)
+	gz := gzip.NewWriter(buf)
+	_, err := gz.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
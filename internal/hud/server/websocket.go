@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +22,14 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// If the client has this many views that it hasn't acknowledged yet,
+// we stop sending it new views until it catches up.
+//
+// Every view contains the complete resource state, so when the client
+// acks, we only need to send it the latest one. This bounds how much we
+// buffer per client, no matter how slow it is.
+const maxUnackedViews = 3
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:    1024,
 	WriteBufferSize:   1024,
@@ -32,9 +41,28 @@ type WebsocketSubscriber struct {
 	conn       WebsocketConn
 	streamDone chan bool
 
+	// Held while sending a view, so that views triggered by
+	// store changes and by client acks don't interleave.
+	sendMu sync.Mutex
+
 	mu               sync.Mutex
 	tiltStartTime    time.Time
 	clientCheckpoint logstore.Checkpoint
+
+	// Sequence numbers of the last view sent, and the last view the client acknowledged.
+	// Older clients don't send seq numbers, so we don't apply any backpressure
+	// until we've seen at least one.
+	sentSeq    int32
+	ackedSeq   int32
+	clientAcks bool
+
+	// True if we skipped sending a view because the client was too far behind.
+	pending bool
+
+	// The part of the view the client subscribed to, and the last one we sent,
+	// so that we don't send filtered views that haven't changed.
+	filter   viewFilter
+	lastSent *proto_webview.View
 }
 
 type WebsocketConn interface {
@@ -54,6 +82,42 @@ func NewWebsocketSubscriber(ctx context.Context, conn WebsocketConn) *WebsocketS
 	}
 }
 
+// Resume a connection from a previous websocket, so that the client
+// only gets the logs it missed, rather than all of them.
+//
+// If the client was talking to a different Tilt process, we ignore
+// the checkpoint and send everything.
+func (ws *WebsocketSubscriber) Resume(tiltStartTime time.Time, checkpoint logstore.Checkpoint) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.tiltStartTime = tiltStartTime
+	ws.clientCheckpoint = checkpoint
+}
+
+// Only send the client the part of the view that it subscribed to.
+func (ws *WebsocketSubscriber) SetFilter(f viewFilter) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.filter = f
+}
+
+// Filters the view. Returns nil if the client has already seen everything in it.
+func (ws *WebsocketSubscriber) filterView(view *proto_webview.View) *proto_webview.View {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.filter.Empty() {
+		return view
+	}
+
+	view = ws.filter.Apply(view)
+	unchanged, normalized := viewUnchanged(ws.lastSent, view)
+	if unchanged {
+		return nil
+	}
+	ws.lastSent = normalized
+	return view
+}
+
 func (ws *WebsocketSubscriber) TearDown(ctx context.Context) {
 	_ = ws.conn.Close()
 }
@@ -72,10 +136,15 @@ func (ws *WebsocketSubscriber) Stream(ctx context.Context, store *store.Store) {
 			}
 
 			if messageType == websocket.TextMessage {
-				err := ws.handleClientMessage(reader)
+				caughtUp, err := ws.handleClientMessage(reader)
 				if err != nil {
 					logger.Get(ctx).Infof("Error parsing webclient message: %v", err)
 				}
+
+				// If we held back a view while the client was behind, send it now.
+				if caughtUp {
+					go ws.OnChange(ctx, store)
+				}
 			}
 		}
 	}()
@@ -87,67 +156,123 @@ func (ws *WebsocketSubscriber) Stream(ctx context.Context, store *store.Store) {
 	_ = store.RemoveSubscriber(context.Background(), ws)
 }
 
-func (ws *WebsocketSubscriber) handleClientMessage(reader io.Reader) error {
+// Returns true if a view was held back and the client has now caught up enough to receive it.
+func (ws *WebsocketSubscriber) handleClientMessage(reader io.Reader) (bool, error) {
 	decoder := (&runtime.JSONPb{OrigName: false}).NewDecoder(reader)
 	msg := &proto_webview.AckWebsocketRequest{}
 	err := decoder.Decode(msg)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	return ws.updateClientCheckpoint(msg)
 }
 
-func (ws *WebsocketSubscriber) readClientCheckpoint() logstore.Checkpoint {
+// Returns the checkpoint to send logs from, and resets it if
+// the client's logs came from a different Tilt process.
+func (ws *WebsocketSubscriber) clientCheckpointFor(tiltStartTime time.Time) logstore.Checkpoint {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
+	if !tiltStartTime.Equal(ws.tiltStartTime) {
+		ws.tiltStartTime = tiltStartTime
+		ws.clientCheckpoint = 0
+	}
 	return ws.clientCheckpoint
 }
 
-func (ws *WebsocketSubscriber) setTiltStartTime(t time.Time) {
+func (ws *WebsocketSubscriber) updateClientCheckpoint(msg *proto_webview.AckWebsocketRequest) (bool, error) {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
-	ws.tiltStartTime = t
-}
 
-func (ws *WebsocketSubscriber) updateClientCheckpoint(msg *proto_webview.AckWebsocketRequest) error {
+	if msg.Seq > 0 {
+		ws.clientAcks = true
+		if msg.Seq > ws.ackedSeq {
+			ws.ackedSeq = msg.Seq
+		}
+	}
+
+	caughtUp := ws.pending && !ws.isTooFarBehindLocked()
+	if caughtUp {
+		ws.pending = false
+	}
+
 	t, err := ptypes.Timestamp(msg.TiltStartTime)
 	if err != nil {
-		return err
+		return caughtUp, err
 	}
 
+	if !t.Equal(ws.tiltStartTime) {
+		ws.clientCheckpoint = 0
+		return caughtUp, nil
+	}
+
+	if msg.ToCheckpoint > 0 {
+		ws.clientCheckpoint = logstore.Checkpoint(msg.ToCheckpoint)
+	}
+	return caughtUp, nil
+}
+
+func (ws *WebsocketSubscriber) isTooFarBehindLocked() bool {
+	return ws.clientAcks && ws.sentSeq-ws.ackedSeq >= maxUnackedViews
+}
+
+// Returns true if the client is too far behind to send it anything right now.
+func (ws *WebsocketSubscriber) holdBack() bool {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
+	if ws.isTooFarBehindLocked() {
+		ws.pending = true
+		return true
+	}
+	return false
+}
 
-	if !t.Equal(ws.tiltStartTime) {
-		ws.clientCheckpoint = 0
-		return nil
+// The number of views we've sent that the client hasn't acknowledged.
+func (ws *WebsocketSubscriber) Backlog() int {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if !ws.clientAcks {
+		return 0
 	}
+	return int(ws.sentSeq - ws.ackedSeq)
+}
 
-	ws.clientCheckpoint = logstore.Checkpoint(msg.ToCheckpoint)
-	return nil
+func (ws *WebsocketSubscriber) nextSeq() int32 {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.sentSeq++
+	return ws.sentSeq
 }
 
 func (ws *WebsocketSubscriber) OnChange(ctx context.Context, s store.RStore) {
-	checkpoint := ws.readClientCheckpoint()
+	ws.sendMu.Lock()
+	defer ws.sendMu.Unlock()
+
+	if ws.holdBack() {
+		return
+	}
 
 	state := s.RLockState()
+	checkpoint := ws.clientCheckpointFor(state.TiltStartTime)
 	view, err := webview.StateToProtoView(state, checkpoint)
-	tiltStartTime := state.TiltStartTime
 	s.RUnlockState()
 	if err != nil {
 		logger.Get(ctx).Infof("error converting view to proto for websocket: %v", err)
 		return
 	}
 
-	ws.setTiltStartTime(tiltStartTime)
-
 	if view.NeedsAnalyticsNudge && !state.AnalyticsNudgeSurfaced {
 		// If we're showing the nudge and no one's told the engine
 		// state about it yet... tell the engine state.
 		s.Dispatch(store.AnalyticsNudgeSurfacedAction{})
 	}
 
+	view = ws.filterView(view)
+	if view == nil {
+		return
+	}
+	view.Seq = ws.nextSeq()
+
 	jsEncoder := &runtime.JSONPb{OrigName: false, EmitDefaults: true}
 	w, err := ws.conn.NextWriter(websocket.TextMessage)
 	if err != nil {
@@ -179,7 +304,15 @@ func (ws *WebsocketSubscriber) OnChange(ctx context.Context, s store.RStore) {
 }
 
 func (s *HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request) {
-	conn, err := upgrader.Upgrade(w, req, nil)
+	filter, err := viewFilterParams(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u := upgrader
+	u.CheckOrigin = s.checkWebsocketOrigin
+	conn, err := u.Upgrade(w, req, nil)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error upgrading websocket: %v", err), http.StatusInternalServerError)
 		return
@@ -187,6 +320,16 @@ func (s *HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request)
 
 	atomic.AddInt32(&s.numWebsocketConns, 1)
 	ws := NewWebsocketSubscriber(s.ctx, conn)
+	s.addWebsocket(ws)
+	defer s.removeWebsocket(ws)
+
+	// A client that's reconnecting tells us what it already has,
+	// so we can pick up where the last connection left off.
+	tiltStartTime, checkpoint, ok := resumeParams(req)
+	if ok {
+		ws.Resume(tiltStartTime, checkpoint)
+	}
+	ws.SetFilter(filter)
 
 	// Fire a fake OnChange event to initialize the connection.
 	ws.OnChange(s.ctx, s.store)
@@ -196,4 +339,42 @@ func (s *HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request)
 	atomic.AddInt32(&s.numWebsocketConns, -1)
 }
 
+func (s *HeadsUpServer) addWebsocket(ws *WebsocketSubscriber) {
+	s.websocketsMu.Lock()
+	defer s.websocketsMu.Unlock()
+	s.websockets[ws] = true
+}
+
+func (s *HeadsUpServer) removeWebsocket(ws *WebsocketSubscriber) {
+	s.websocketsMu.Lock()
+	defer s.websocketsMu.Unlock()
+	delete(s.websockets, ws)
+}
+
+// The most views that any connected web UI hasn't acknowledged yet.
+func (s *HeadsUpServer) WebsocketBacklog() int {
+	s.websocketsMu.Lock()
+	defer s.websocketsMu.Unlock()
+	max := 0
+	for ws := range s.websockets {
+		if b := ws.Backlog(); b > max {
+			max = b
+		}
+	}
+	return max
+}
+
+func resumeParams(req *http.Request) (time.Time, logstore.Checkpoint, bool) {
+	q := req.URL.Query()
+	checkpoint, err := strconv.Atoi(q.Get("checkpoint"))
+	if err != nil || checkpoint <= 0 {
+		return time.Time{}, 0, false
+	}
+	tiltStartTime, err := time.Parse(time.RFC3339Nano, q.Get("tiltStartTime"))
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return tiltStartTime, logstore.Checkpoint(checkpoint), true
+}
+
 var _ store.TearDowner = &WebsocketSubscriber{}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+// Which part of the view a websocket client wants.
+const (
+	viewOnlyStatus = "status"
+	viewOnlyLogs   = "logs"
+)
+
+// A websocket client can subscribe to a subset of the view, so that lightweight
+// clients (like status bar widgets and IDE decorations) don't get multi-MB views
+// that they throw away.
+type viewFilter struct {
+	// If non-empty, only these resources, and only their logs.
+	resources map[string]bool
+
+	// "status" for only the status of each resource, "logs" for only the logs,
+	// or "" for everything.
+	only string
+
+	// If non-NONE, only log lines at this level or more severe.
+	minLogLevel proto_webview.LogLevel
+}
+
+func (f viewFilter) Empty() bool {
+	return len(f.resources) == 0 && f.only == "" && f.minLogLevel == proto_webview.LogLevel_NONE
+}
+
+// Reads the filter from the websocket URL, e.g.,
+// /ws/view?resources=frontend,backend&only=status
+func viewFilterParams(req *http.Request) (viewFilter, error) {
+	q := req.URL.Query()
+	f := viewFilter{}
+
+	for _, r := range strings.Split(q.Get("resources"), ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if f.resources == nil {
+			f.resources = make(map[string]bool)
+		}
+		f.resources[r] = true
+	}
+
+	only := q.Get("only")
+	switch only {
+	case "", viewOnlyStatus, viewOnlyLogs:
+		f.only = only
+	default:
+		return viewFilter{}, fmt.Errorf("invalid only=%q. Allowed: {%s, %s}", only, viewOnlyStatus, viewOnlyLogs)
+	}
+
+	level := q.Get("logLevel")
+	switch strings.ToLower(level) {
+	case "":
+	case "info":
+		f.minLogLevel = proto_webview.LogLevel_INFO
+	case "warn":
+		f.minLogLevel = proto_webview.LogLevel_WARN
+	case "error":
+		f.minLogLevel = proto_webview.LogLevel_ERROR
+	default:
+		return viewFilter{}, fmt.Errorf("invalid logLevel=%q. Allowed: {info, warn, error}", level)
+	}
+
+	return f, nil
+}
+
+// Returns a copy of the view with only what the client subscribed to.
+func (f viewFilter) Apply(view *proto_webview.View) *proto_webview.View {
+	if f.Empty() {
+		return view
+	}
+
+	switch f.only {
+	case viewOnlyStatus:
+		return &proto_webview.View{
+			Resources:     f.statusResources(view.Resources),
+			FatalError:    view.FatalError,
+			TiltStartTime: view.TiltStartTime,
+			Alerts:        view.Alerts,
+		}
+	case viewOnlyLogs:
+		return &proto_webview.View{
+			LogList:       f.logList(view.LogList),
+			FatalError:    view.FatalError,
+			TiltStartTime: view.TiltStartTime,
+		}
+	}
+
+	// Views are built fresh for every change, so it's OK to share their fields.
+	result := *view
+	result.Resources = f.filterResources(view.Resources)
+	result.LogList = f.logList(view.LogList)
+	return &result
+}
+
+func (f viewFilter) includesResource(name string) bool {
+	return len(f.resources) == 0 || f.resources[name]
+}
+
+func (f viewFilter) filterResources(resources []*proto_webview.Resource) []*proto_webview.Resource {
+	if len(f.resources) == 0 {
+		return resources
+	}
+	var result []*proto_webview.Resource
+	for _, r := range resources {
+		if f.includesResource(r.Name) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Just enough of each resource to tell whether it's building, healthy, or broken.
+func (f viewFilter) statusResources(resources []*proto_webview.Resource) []*proto_webview.Resource {
+	var result []*proto_webview.Resource
+	for _, r := range f.filterResources(resources) {
+		s := &proto_webview.Resource{
+			Name:              r.Name,
+			IsTiltfile:        r.IsTiltfile,
+			RuntimeStatus:     r.RuntimeStatus,
+			TriggerMode:       r.TriggerMode,
+			Queued:            r.Queued,
+			HasPendingChanges: r.HasPendingChanges,
+			PendingBuildSince: r.PendingBuildSince,
+		}
+		if r.CurrentBuild != nil {
+			s.CurrentBuild = &proto_webview.BuildRecord{StartTime: r.CurrentBuild.StartTime}
+		}
+		if len(r.BuildHistory) > 0 {
+			last := r.BuildHistory[0]
+			s.BuildHistory = []*proto_webview.BuildRecord{{
+				Error:      last.Error,
+				Warnings:   last.Warnings,
+				StartTime:  last.StartTime,
+				FinishTime: last.FinishTime,
+			}}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func (f viewFilter) logList(logList *proto_webview.LogList) *proto_webview.LogList {
+	if logList == nil || (len(f.resources) == 0 && f.minLogLevel == proto_webview.LogLevel_NONE) {
+		return logList
+	}
+
+	result := &proto_webview.LogList{
+		Spans:          make(map[string]*proto_webview.LogSpan),
+		FromCheckpoint: logList.FromCheckpoint,
+		ToCheckpoint:   logList.ToCheckpoint,
+	}
+	for _, seg := range logList.Segments {
+		span, ok := logList.Spans[seg.SpanId]
+		if !ok || !f.includesResource(span.ManifestName) {
+			continue
+		}
+		if logLevelSeverity(seg.Level) < logLevelSeverity(f.minLogLevel) {
+			continue
+		}
+		result.Segments = append(result.Segments, seg)
+		result.Spans[seg.SpanId] = span
+	}
+	return result
+}
+
+// The LogLevel enum values don't say anything about relative severity.
+func logLevelSeverity(level proto_webview.LogLevel) int {
+	switch level {
+	case proto_webview.LogLevel_ERROR:
+		return 3
+	case proto_webview.LogLevel_WARN:
+		return 2
+	case proto_webview.LogLevel_INFO, proto_webview.LogLevel_NONE:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Returns true if the filtered view has nothing the client hasn't already seen,
+// so that subscribers to a slice of the state only hear about changes to that slice.
+// Returns the view to compare the next one against.
+func viewUnchanged(last, next *proto_webview.View) (bool, *proto_webview.View) {
+	hasLogs := next.LogList != nil && len(next.LogList.Segments) > 0
+
+	normalized := *next
+	normalized.Seq = 0
+	normalized.LogList = nil
+	return !hasLogs && last != nil && proto.Equal(last, &normalized), &normalized
+}
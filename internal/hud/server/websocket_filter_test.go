@@ -0,0 +1,135 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+func TestViewFilterParams(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ws/view?resources=fe,%20be&only=status&logLevel=WARN", nil)
+	f, err := viewFilterParams(req)
+	require.NoError(t, err)
+	assert.Equal(t, viewFilter{
+		resources:   map[string]bool{"fe": true, "be": true},
+		only:        viewOnlyStatus,
+		minLogLevel: proto_webview.LogLevel_WARN,
+	}, f)
+
+	f, err = viewFilterParams(httptest.NewRequest("GET", "/ws/view", nil))
+	require.NoError(t, err)
+	assert.True(t, f.Empty())
+
+	_, err = viewFilterParams(httptest.NewRequest("GET", "/ws/view?only=everything", nil))
+	assert.EqualError(t, err, `invalid only="everything". Allowed: {status, logs}`)
+
+	_, err = viewFilterParams(httptest.NewRequest("GET", "/ws/view?logLevel=debug", nil))
+	assert.EqualError(t, err, `invalid logLevel="debug". Allowed: {info, warn, error}`)
+}
+
+func TestViewFilterStatusOnly(t *testing.T) {
+	f := viewFilter{resources: map[string]bool{"fe": true}, only: viewOnlyStatus}
+	view := f.Apply(newFilterTestView())
+
+	assert.Nil(t, view.LogList)
+	require.Len(t, view.Resources, 1)
+	r := view.Resources[0]
+	assert.Equal(t, "fe", r.Name)
+	assert.Equal(t, "ok", r.RuntimeStatus)
+	require.Len(t, r.BuildHistory, 1)
+	assert.Equal(t, "build failed", r.BuildHistory[0].Error)
+	assert.Empty(t, r.BuildHistory[0].Edits)
+	assert.Nil(t, r.K8SResourceInfo)
+}
+
+func TestViewFilterLogs(t *testing.T) {
+	f := viewFilter{resources: map[string]bool{"fe": true}, minLogLevel: proto_webview.LogLevel_WARN}
+	view := f.Apply(newFilterTestView())
+
+	require.Len(t, view.Resources, 1)
+	assert.NotNil(t, view.Resources[0].K8SResourceInfo)
+
+	var texts []string
+	for _, seg := range view.LogList.Segments {
+		texts = append(texts, seg.Text)
+	}
+	assert.Equal(t, []string{"fe warning\n", "fe error\n"}, texts)
+	assert.Equal(t, []string{"fe:1"}, spanIDs(view.LogList))
+	assert.Equal(t, int32(7), view.LogList.ToCheckpoint)
+}
+
+func TestViewFilterLogsOnly(t *testing.T) {
+	f := viewFilter{only: viewOnlyLogs}
+	view := f.Apply(newFilterTestView())
+
+	assert.Empty(t, view.Resources)
+	assert.Len(t, view.LogList.Segments, 4)
+}
+
+func TestWebsocketFilterSkipsUnchangedViews(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ws := NewWebsocketSubscriber(ctx, newFakeConn())
+	ws.SetFilter(viewFilter{only: viewOnlyStatus})
+
+	assert.NotNil(t, ws.filterView(newFilterTestView()))
+
+	// Only the logs changed.
+	assert.Nil(t, ws.filterView(newFilterTestView()))
+
+	view := newFilterTestView()
+	view.Resources[0].RuntimeStatus = "error"
+	assert.NotNil(t, ws.filterView(view))
+}
+
+func TestWebsocketNoFilterSendsEveryView(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ws := NewWebsocketSubscriber(ctx, newFakeConn())
+
+	view := newFilterTestView()
+	assert.Equal(t, view, ws.filterView(view))
+	assert.Equal(t, view, ws.filterView(view))
+}
+
+func newFilterTestView() *proto_webview.View {
+	return &proto_webview.View{
+		Resources: []*proto_webview.Resource{
+			{
+				Name:          "fe",
+				RuntimeStatus: "ok",
+				BuildHistory: []*proto_webview.BuildRecord{
+					{Error: "build failed", Edits: []string{"main.go"}},
+					{},
+				},
+				K8SResourceInfo: &proto_webview.K8SResourceInfo{PodName: "fe-pod"},
+			},
+			{Name: "be", RuntimeStatus: "pending"},
+		},
+		LogList: &proto_webview.LogList{
+			Spans: map[string]*proto_webview.LogSpan{
+				"fe:1": {ManifestName: "fe"},
+				"be:1": {ManifestName: "be"},
+			},
+			Segments: []*proto_webview.LogSegment{
+				{SpanId: "fe:1", Text: "fe info\n", Level: proto_webview.LogLevel_INFO},
+				{SpanId: "fe:1", Text: "fe warning\n", Level: proto_webview.LogLevel_WARN},
+				{SpanId: "be:1", Text: "be error\n", Level: proto_webview.LogLevel_ERROR},
+				{SpanId: "fe:1", Text: "fe error\n", Level: proto_webview.LogLevel_ERROR},
+			},
+			FromCheckpoint: 3,
+			ToCheckpoint:   7,
+		},
+	}
+}
+
+func spanIDs(logList *proto_webview.LogList) []string {
+	var result []string
+	for id := range logList.Spans {
+		result = append(result, id)
+	}
+	return result
+}
@@ -48,6 +48,22 @@ func TestIncrementalLogAck(t *testing.T) {
 	f.tearDown()
 }
 
+func TestSeqAck(t *testing.T) {
+	f := newWebsocketReaderFixture(t)
+	f.start()
+
+	v := &proto_webview.View{Log: "hello world", Seq: 1}
+	f.sendView(v)
+
+	f.assertHandlerCallCount(1)
+
+	// Even with no new logs, the client needs to tell the server
+	// which views it's seen, so the server keeps sending them.
+	f.assertMessageWritten()
+
+	f.tearDown()
+}
+
 func TestHandlerErrorDoesntStopLoop(t *testing.T) {
 	f := newWebsocketReaderFixture(t)
 	f.start()
@@ -3,13 +3,17 @@ package server
 import (
 	"fmt"
 	"io"
+	"net/http/httptest"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 
 	"github.com/tilt-dev/tilt/internal/store"
 )
@@ -97,6 +101,79 @@ func TestWebsocketNextWriterError(t *testing.T) {
 	conn.AssertClose(t, done)
 }
 
+func TestWebsocketHoldsBackViewsUntilAck(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ws := NewWebsocketSubscriber(ctx, newFakeConn())
+
+	// Clients that don't ack seq numbers never get held back.
+	for i := 0; i < maxUnackedViews+1; i++ {
+		assert.False(t, ws.holdBack())
+		ws.nextSeq()
+	}
+
+	caughtUp, err := ws.handleClientMessage(strings.NewReader(`{"seq": 2, "tiltStartTime": "2020-01-01T00:00:00Z"}`))
+	require.NoError(t, err)
+	assert.False(t, caughtUp)
+
+	// sent 4, acked 2
+	assert.False(t, ws.holdBack())
+	assert.Equal(t, int32(5), ws.nextSeq())
+
+	// sent 5, acked 2
+	assert.True(t, ws.holdBack())
+
+	caughtUp, err = ws.handleClientMessage(strings.NewReader(`{"seq": 5, "tiltStartTime": "2020-01-01T00:00:00Z"}`))
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+	assert.False(t, ws.holdBack())
+}
+
+func TestWebsocketBacklog(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ws := NewWebsocketSubscriber(ctx, newFakeConn())
+	s := &HeadsUpServer{websockets: make(map[*WebsocketSubscriber]bool)}
+	s.addWebsocket(ws)
+
+	for i := 0; i < 4; i++ {
+		ws.nextSeq()
+	}
+	// Clients that don't ack never have a backlog.
+	assert.Equal(t, 0, s.WebsocketBacklog())
+
+	_, err := ws.handleClientMessage(strings.NewReader(`{"seq": 1, "tiltStartTime": "2020-01-01T00:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.WebsocketBacklog())
+
+	s.removeWebsocket(ws)
+	assert.Equal(t, 0, s.WebsocketBacklog())
+}
+
+func TestWebsocketResume(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ws := NewWebsocketSubscriber(ctx, newFakeConn())
+
+	req := httptest.NewRequest("GET", "/ws/view?checkpoint=42&tiltStartTime=2020-01-01T00%3A00%3A00.5Z", nil)
+	startTime, checkpoint, ok := resumeParams(req)
+	require.True(t, ok)
+	assert.Equal(t, logstore.Checkpoint(42), checkpoint)
+
+	ws.Resume(startTime, checkpoint)
+	assert.Equal(t, logstore.Checkpoint(42), ws.clientCheckpointFor(startTime))
+
+	// If Tilt restarted, the client needs all the logs.
+	assert.Equal(t, logstore.Checkpoint(0), ws.clientCheckpointFor(startTime.Add(time.Hour)))
+}
+
+func TestWebsocketResumeParamsMissing(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ws/view", nil)
+	_, _, ok := resumeParams(req)
+	assert.False(t, ok)
+
+	req = httptest.NewRequest("GET", "/ws/view?checkpoint=42", nil)
+	_, _, ok = resumeParams(req)
+	assert.False(t, ok)
+}
+
 type readerOrErr struct {
 	reader io.Reader
 	err    error
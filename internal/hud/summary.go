@@ -0,0 +1,41 @@
+package hud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/tilt-dev/tilt/internal/hud/view"
+)
+
+// A one-line summary of resource status, for terminals
+// that can't show the full-screen HUD.
+func resourceSummary(v view.View) string {
+	ok, pending := 0, 0
+	var errors []string
+	for _, res := range v.Resources {
+		switch combinedStatus(res).color {
+		case cGood:
+			ok++
+		case cBad:
+			errors = append(errors, res.Name.String())
+		default:
+			pending++
+		}
+	}
+
+	parts := []string{color.GreenString("%d ok", ok)}
+	if pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", pending))
+	}
+	if len(errors) > 0 {
+		s := "error"
+		if len(errors) > 1 {
+			s = "errors"
+		}
+		parts = append(parts, color.RedString("%d %s (%s)", len(errors), s, strings.Join(errors, ", ")))
+	}
+
+	return fmt.Sprintf("-- Resources: %s --", strings.Join(parts, ", "))
+}
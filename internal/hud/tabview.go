@@ -60,9 +60,9 @@ func (v *TabView) log() string {
 	reader := v.view.LogReader
 	result := ""
 	if v.tabState == view.TabAllLog {
-		result = reader.Tail(numLinesNeeded)
+		result = reader.TailWithSettings(numLinesNeeded, v.view.LogSettings)
 	} else if spanID != "" {
-		result = reader.TailSpan(numLinesNeeded, spanID)
+		result = reader.TailSpanWithSettings(numLinesNeeded, spanID, v.view.LogSettings)
 	}
 
 	if result == "" {
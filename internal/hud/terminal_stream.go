@@ -2,6 +2,7 @@ package hud
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
@@ -11,6 +12,9 @@ type TerminalStream struct {
 	ProcessedLogs logstore.Checkpoint
 	printer       *IncrementalPrinter
 	store         store.RStore
+
+	// The last resource summary we printed, in TerminalModeRichStream.
+	lastSummary string
 }
 
 func NewTerminalStream(printer *IncrementalPrinter, store store.RStore) *TerminalStream {
@@ -38,7 +42,8 @@ func (h *TerminalStream) TearDown(ctx context.Context) {
 func (h *TerminalStream) isEnabled(st store.RStore) bool {
 	state := st.RLockState()
 	defer st.RUnlockState()
-	return state.TerminalMode == store.TerminalModeStream
+	return state.TerminalMode == store.TerminalModeStream ||
+		state.TerminalMode == store.TerminalModeRichStream
 }
 
 func (h *TerminalStream) OnChange(ctx context.Context, st store.RStore) {
@@ -47,12 +52,26 @@ func (h *TerminalStream) OnChange(ctx context.Context, st store.RStore) {
 	}
 
 	state := st.RLockState()
-	lines := state.LogStore.ContinuingLines(h.ProcessedLogs)
+	lines := state.LogStore.ContinuingLinesWithOptions(h.ProcessedLogs, logstore.LineOptions{
+		Settings: state.LogSettings,
+	})
 	checkpoint := state.LogStore.Checkpoint()
+	summary := ""
+	if state.TerminalMode == store.TerminalModeRichStream &&
+		!state.LogStore.IsLastSegmentUncompleted() {
+		summary = resourceSummary(store.StateToView(state, st.StateMutex()))
+	}
 	st.RUnlockState()
 
 	h.printer.Print(lines)
 	h.ProcessedLogs = checkpoint
+
+	// Don't print the summary in the middle of a log line.
+	// We'll catch up once the line is done.
+	if summary != "" && summary != h.lastSummary {
+		h.printer.PrintString(fmt.Sprintf("%s\n", summary))
+		h.lastSummary = summary
+	}
 }
 
 var _ store.TearDowner = &TerminalStream{}
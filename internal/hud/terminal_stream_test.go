@@ -0,0 +1,103 @@
+package hud
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestTerminalStreamPrintsLogs(t *testing.T) {
+	f := newTerminalStreamFixture(t, store.TerminalModeStream)
+
+	f.log("hello\n")
+	f.onChange()
+
+	assert.Equal(t, "hello\n", f.out.String())
+}
+
+func TestRichStreamPrintsSummary(t *testing.T) {
+	f := newTerminalStreamFixture(t, store.TerminalModeRichStream)
+
+	f.log("hello\n")
+	f.onChange()
+	assert.Equal(t, "hello\n-- Resources: 0 ok, 2 pending --\n", f.out.String())
+
+	// Nothing changed, so no new summary.
+	f.onChange()
+	assert.Equal(t, "hello\n-- Resources: 0 ok, 2 pending --\n", f.out.String())
+
+	f.completeBuild("fe", nil)
+	f.onChange()
+	assert.Equal(t, "hello\n-- Resources: 0 ok, 2 pending --\n"+
+		"-- Resources: 1 ok, 1 pending --\n", f.out.String())
+}
+
+func TestRichStreamSummaryErrors(t *testing.T) {
+	f := newTerminalStreamFixture(t, store.TerminalModeRichStream)
+
+	f.completeBuild("fe", fmt.Errorf("oh no"))
+	f.onChange()
+	assert.Equal(t, "-- Resources: 0 ok, 1 pending, 1 error (fe) --\n", f.out.String())
+}
+
+func TestRichStreamWaitsForCompleteLine(t *testing.T) {
+	f := newTerminalStreamFixture(t, store.TerminalModeRichStream)
+
+	f.log("building...")
+	f.onChange()
+	assert.Equal(t, "building...", f.out.String())
+
+	f.log(" done\n")
+	f.onChange()
+	assert.Equal(t, "building... done\n-- Resources: 0 ok, 2 pending --\n", f.out.String())
+}
+
+type terminalStreamFixture struct {
+	t   *testing.T
+	out *bytes.Buffer
+	st  *store.TestingStore
+	ts  *TerminalStream
+}
+
+func newTerminalStreamFixture(t *testing.T, mode store.TerminalMode) *terminalStreamFixture {
+	out := &bytes.Buffer{}
+	st := store.NewTestingStore()
+	state := st.LockMutableStateForTesting()
+	state.TerminalMode = mode
+	mt := store.NewManifestTarget(model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{}))
+	mt.State.RuntimeState = store.LocalRuntimeState{Status: model.RuntimeStatusNotApplicable}
+	state.UpsertManifestTarget(mt)
+	st.UnlockMutableState()
+
+	return &terminalStreamFixture{
+		t:   t,
+		out: out,
+		st:  st,
+		ts:  NewTerminalStream(NewIncrementalPrinter(Stdout(out)), st),
+	}
+}
+
+func (f *terminalStreamFixture) log(msg string) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	state.LogStore.Append(store.NewGlobalLogAction(logger.InfoLvl, []byte(msg)), nil)
+}
+
+func (f *terminalStreamFixture) completeBuild(mn model.ManifestName, err error) {
+	state := f.st.LockMutableStateForTesting()
+	defer f.st.UnlockMutableState()
+	ms, _ := state.ManifestState(mn)
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now(), Error: err})
+}
+
+func (f *terminalStreamFixture) onChange() {
+	f.ts.OnChange(context.Background(), f.st)
+}
@@ -56,8 +56,20 @@ type K8sResourceInfo struct {
 	PodStatus          string
 	PodRestarts        int
 	SpanID             logstore.SpanID
-	RunStatus          model.RuntimeStatus
-	DisplayNames       []string
+
+	// Why a container in the pod last stopped, e.g., "OOMKilled".
+	LastTerminationReason string
+	RunStatus             model.RuntimeStatus
+	DisplayNames          []string
+
+	// If the resource is deployed to several namespaces, the rollout in each one.
+	Namespaces []NamespaceStatus
+}
+
+type NamespaceStatus struct {
+	Namespace string
+	PodName   string
+	RunStatus model.RuntimeStatus
 }
 
 var _ ResourceInfoView = K8sResourceInfo{}
@@ -130,6 +142,9 @@ type Resource struct {
 	// for a little while.
 	CrashLog model.Log
 
+	// Why the Tiltfile couldn't load this resource's config, if it couldn't.
+	ConfigError string
+
 	IsTiltfile bool
 }
 
@@ -212,9 +227,25 @@ func (r Resource) IsCollapsed(rv ResourceViewState) bool {
 // whenever they need to mutate something.
 type View struct {
 	LogReader   logstore.Reader
+	LogSettings model.LogSettings
 	Resources   []Resource
 	IsProfiling bool
 	FatalError  error
+
+	// Alerts the user hasn't acknowledged yet.
+	Alerts []model.Alert
+
+	// The resources waiting to build, in the order we expect to build them.
+	BuildQueue []QueuedBuild
+}
+
+// A resource that's waiting to build.
+type QueuedBuild struct {
+	Name   model.ManifestName
+	Reason model.BuildReason
+
+	// Why it can't build yet, if anything.
+	HoldDescription string
 }
 
 func (v View) TiltfileErrorMessage() string {
@@ -245,9 +276,24 @@ type ViewState struct {
 	Resources        []ResourceViewState
 	ProcessedLogs    logstore.Checkpoint
 	AlertMessage     string
+	ShowAlertCenter  bool
+	ShowBuildQueue   bool
 	TabState         TabState
 	SelectedIndex    int
 	TiltLogState     TiltLogState
+
+	// Resources whose logs we show side by side, in split panes,
+	// instead of the log tabs.
+	PinnedResources []model.ManifestName
+}
+
+func (vs ViewState) IsPinned(mn model.ManifestName) bool {
+	for _, p := range vs.PinnedResources {
+		if p == mn {
+			return true
+		}
+	}
+	return false
 }
 
 type TabState int
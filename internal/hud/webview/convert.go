@@ -1,10 +1,12 @@
 package webview
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
 	"github.com/tilt-dev/tilt/internal/ospath"
@@ -25,6 +27,11 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 	}
 	ret.Resources = append(ret.Resources, rpv)
 
+	// Tilt's own health only shows up once the watchdog has something to say.
+	if !s.EngineHealth.CheckTime.IsZero() {
+		ret.Resources = append(ret.Resources, engineHealthProtoView(s.EngineHealth))
+	}
+
 	for _, name := range s.ManifestDefinitionOrder {
 		mt, ok := s.ManifestTargets[name]
 		if !ok {
@@ -56,7 +63,7 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := append([]model.BuildRecord{}, ms.RecentBuildHistory()...)
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -102,6 +109,14 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 			return nil, err
 		}
 
+		links := ToProtoLinks(endpoints)
+		if s.EndpointHealthSettings.Enabled && s.EndpointHealthSettings.Includes(name) {
+			err = populateLinkHealth(links, ms.EndpointHealth)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		r := &proto_webview.Resource{
 			Name:               name.String(),
 			LastDeployTime:     lastDeploy,
@@ -110,7 +125,7 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 			PendingBuildSince:  pbs,
 			PendingBuildReason: int32(mt.NextBuildReason()),
 			CurrentBuild:       cb,
-			EndpointLinks:      ToProtoLinks(endpoints),
+			EndpointLinks:      links,
 			PodID:              podID.String(),
 			Specs:              specs,
 			ShowBuildStatus:    len(mt.Manifest.ImageTargets) > 0 || mt.Manifest.IsDC(),
@@ -119,6 +134,10 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 			HasPendingChanges:  hasPendingChanges,
 			Facets:             model.FacetsToProto(facets),
 			Queued:             s.ManifestInTriggerQueue(name),
+			TrafficCapture:     string(ms.TrafficCapture),
+			LogColor:           s.LogSettings.ColorFor(name),
+			CronJob:            len(ms.DeployedCronJobs()) > 0,
+			ConfigError:        mt.Manifest.ConfigError,
 		}
 
 		err = protoPopulateResourceInfoView(mt, r)
@@ -135,6 +154,8 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 	}
 
 	ret.LogList = logList
+	ret.LogTimestamps = s.LogSettings.Timestamps
+	ret.LogPrefixWidth = int32(s.LogSettings.PrefixWidth)
 	ret.NeedsAnalyticsNudge = NeedsNudge(s)
 	ret.RunningTiltBuild = &proto_webview.TiltBuild{
 		Version:   s.TiltBuildInfo.Version,
@@ -151,6 +172,21 @@ func StateToProtoView(s store.EngineState, logCheckpoint logstore.Checkpoint) (*
 	ret.TiltCloudTeamName = s.CloudStatus.TeamName
 	ret.TiltCloudSchemeHost = cloudurl.URL(s.CloudAddress).String()
 	ret.TiltCloudTeamID = s.TeamID
+	ret.TiltCloudTokenExpired = s.CloudStatus.TokenExpired
+	for _, t := range s.CloudStatus.Teams {
+		ret.TiltCloudTeams = append(ret.TiltCloudTeams, &proto_webview.TiltCloudTeam{
+			Id:         t.ID,
+			Name:       t.Name,
+			Associated: t.Associated,
+		})
+	}
+	for _, a := range s.Alerts.Unacknowledged() {
+		alert, err := ToProtoAlert(a)
+		if err != nil {
+			return nil, err
+		}
+		ret.Alerts = append(ret.Alerts, alert)
+	}
 	if s.FatalError != nil {
 		ret.FatalError = s.FatalError.Error()
 	}
@@ -207,6 +243,34 @@ func tiltfileResourceProtoView(s store.EngineState) (*proto_webview.Resource, er
 	return tr, nil
 }
 
+func engineHealthProtoView(h store.EngineHealth) *proto_webview.Resource {
+	status := model.RuntimeStatusOK
+	if h.Degraded() {
+		status = model.RuntimeStatusError
+	}
+
+	facets := []model.Facet{
+		{Name: "Action queue", Value: fmt.Sprintf("%d", h.ActionQueueDepth)},
+		{Name: "Web UI backlog", Value: fmt.Sprintf("%d", h.WebsocketBacklog)},
+		{Name: "File change delay", Value: h.FileEventDelay.String()},
+	}
+	if h.SlowestSubscriber != "" {
+		facets = append(facets, model.Facet{
+			Name:  "Busiest subscriber",
+			Value: fmt.Sprintf("%s (%s)", h.SlowestSubscriber, h.SlowestSubscriberLag.Truncate(time.Millisecond)),
+		})
+	}
+	if h.Degraded() {
+		facets = append(facets, model.Facet{Name: "Problems", Value: strings.Join(h.Problems, "\n")})
+	}
+
+	return &proto_webview.Resource{
+		Name:          store.EngineHealthManifestName.String(),
+		RuntimeStatus: string(status),
+		Facets:        model.FacetsToProto(facets),
+	}
+}
+
 func protoPopulateResourceInfoView(mt *store.ManifestTarget, r *proto_webview.Resource) error {
 	r.RuntimeStatus = string(model.RuntimeStatusNotApplicable)
 
@@ -246,6 +310,8 @@ func protoPopulateResourceInfoView(mt *store.ManifestTarget, r *proto_webview.Re
 			AllContainersReady: pod.AllContainersReady(),
 			PodRestarts:        int32(pod.VisibleContainerRestarts()),
 			DisplayNames:       mt.Manifest.K8sTarget().DisplayNames,
+			Containers:         toProtoContainerStatuses(pod.Containers),
+			Namespaces:         toProtoNamespaceStatuses(kState.NamespaceStatuses()),
 		}
 
 		r.RuntimeStatus = string(kState.RuntimeStatus())
@@ -255,6 +321,33 @@ func protoPopulateResourceInfoView(mt *store.ManifestTarget, r *proto_webview.Re
 	panic("Unrecognized manifest type (not one of: k8s, DC, local)")
 }
 
+func toProtoContainerStatuses(containers []store.Container) []*proto_webview.ContainerStatus {
+	var result []*proto_webview.ContainerStatus
+	for _, c := range containers {
+		result = append(result, &proto_webview.ContainerStatus{
+			Name:                    string(c.Name),
+			Restarts:                int32(c.Restarts),
+			LastTerminationReason:   c.LastTerminationReason,
+			LastTerminationExitCode: c.LastTerminationExitCode,
+			OomKilled:               c.OOMKilled(),
+		})
+	}
+	return result
+}
+
+func toProtoNamespaceStatuses(statuses []store.NamespaceRuntimeStatus) []*proto_webview.NamespaceStatus {
+	var result []*proto_webview.NamespaceStatus
+	for _, ns := range statuses {
+		result = append(result, &proto_webview.NamespaceStatus{
+			Namespace:     ns.Namespace.String(),
+			PodName:       ns.Pod.PodID.String(),
+			PodStatus:     ns.Pod.Status,
+			RuntimeStatus: string(ns.Status),
+		})
+	}
+	return result
+}
+
 func LogSegmentToEvent(seg *proto_webview.LogSegment, spans map[string]*proto_webview.LogSpan) store.LogAction {
 	span, ok := spans[seg.SpanId]
 	if !ok {
@@ -266,3 +359,46 @@ func LogSegmentToEvent(seg *proto_webview.LogSegment, spans map[string]*proto_we
 	spoofedLevel := logger.InfoLvl
 	return store.NewLogAction(model.ManifestName(span.ManifestName), logstore.SpanID(seg.SpanId), spoofedLevel, seg.Fields, []byte(seg.Text))
 }
+
+func ToProtoAlert(a model.Alert) (*proto_webview.Alert, error) {
+	firstSeen, err := timeToProto(a.FirstSeen)
+	if err != nil {
+		return nil, err
+	}
+	lastSeen, err := timeToProto(a.LastSeen)
+	if err != nil {
+		return nil, err
+	}
+	return &proto_webview.Alert{
+		Id:           a.ID,
+		Source:       string(a.Source),
+		Severity:     string(a.Severity),
+		ManifestName: a.ManifestName.String(),
+		Message:      a.Message,
+		FirstSeen:    firstSeen,
+		LastSeen:     lastSeen,
+		Count:        int32(a.Count),
+	}, nil
+}
+
+// Attaches the most recent health check to each link that has one.
+func populateLinkHealth(links []*proto_webview.Link, health map[string]store.EndpointHealth) error {
+	for _, link := range links {
+		h, ok := health[link.Url]
+		if !ok {
+			continue
+		}
+		checkedAt, err := timeToProto(h.CheckedAt)
+		if err != nil {
+			return err
+		}
+		link.Health = &proto_webview.LinkHealth{
+			StatusCode: int32(h.StatusCode),
+			LatencyMs:  h.Latency.Milliseconds(),
+			Error:      h.Error,
+			CheckedAt:  checkedAt,
+			Healthy:    h.Healthy(),
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package webview
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,6 +11,7 @@ import (
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/engine/configs"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -96,6 +98,134 @@ func TestStateToWebViewPortForwards(t *testing.T) {
 	assert.Equal(t, expected, res.EndpointLinks)
 }
 
+func TestStateToWebViewEndpointHealth(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{LocalPort: 8000, ContainerPort: 5000},
+			{LocalPort: 7000, ContainerPort: 5001},
+		},
+	})
+	state := newState([]model.Manifest{m})
+	state.EndpointHealthSettings.Enabled = true
+	checkedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	state.ManifestTargets["foo"].State.EndpointHealth = map[string]store.EndpointHealth{
+		"http://localhost:8000/": {
+			URL:        "http://localhost:8000/",
+			StatusCode: 500,
+			Latency:    25 * time.Millisecond,
+			CheckedAt:  checkedAt,
+		},
+	}
+	v := stateToProtoView(t, *state)
+
+	res, _ := findResource(m.Name, v)
+	require.Len(t, res.EndpointLinks, 2)
+	assert.Nil(t, res.EndpointLinks[0].Health)
+
+	health := res.EndpointLinks[1].Health
+	require.NotNil(t, health)
+	assert.Equal(t, int32(500), health.StatusCode)
+	assert.Equal(t, int64(25), health.LatencyMs)
+	assert.False(t, health.Healthy)
+	assert.Equal(t, checkedAt.Unix(), health.CheckedAt.Seconds)
+}
+
+func TestStateToWebViewEndpointHealthDisabled(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{{LocalPort: 8000, ContainerPort: 5000}},
+	})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets["foo"].State.EndpointHealth = map[string]store.EndpointHealth{
+		"http://localhost:8000/": {URL: "http://localhost:8000/", StatusCode: 200},
+	}
+	v := stateToProtoView(t, *state)
+
+	res, _ := findResource(m.Name, v)
+	require.Len(t, res.EndpointLinks, 1)
+	assert.Nil(t, res.EndpointLinks[0].Health)
+}
+
+func TestStateToWebViewTrafficCapture(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{{LocalPort: 8000, ContainerPort: 5000}},
+	})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets["foo"].State.TrafficCapture = model.TrafficCaptureBodies
+	v := stateToProtoView(t, *state)
+
+	res, _ := findResource(m.Name, v)
+	assert.Equal(t, "bodies", res.TrafficCapture)
+}
+
+func TestStateToWebViewCronJob(t *testing.T) {
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{Name: "foo"})
+	state := newState([]model.Manifest{m})
+	v := stateToProtoView(t, *state)
+	res, _ := findResource(m.Name, v)
+	assert.False(t, res.CronJob)
+
+	state.ManifestTargets["foo"].State.MutableBuildStatus(m.K8sTarget().ID()).LastResult = store.K8sBuildResult{
+		DeployedRefs: []v1.ObjectReference{{Kind: "CronJob", Name: "foo"}},
+	}
+	v = stateToProtoView(t, *state)
+	res, _ = findResource(m.Name, v)
+	assert.True(t, res.CronJob)
+}
+
+func TestStateToWebViewLogSettings(t *testing.T) {
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.LocalTarget{})
+	state := newState([]model.Manifest{m})
+	state.LogSettings = model.LogSettings{Colors: true, PrefixWidth: 20, Timestamps: true}
+	v := stateToProtoView(t, *state)
+
+	assert.True(t, v.LogTimestamps)
+	assert.Equal(t, int32(20), v.LogPrefixWidth)
+	res, _ := findResource(m.Name, v)
+	assert.Equal(t, state.LogSettings.ColorFor("foo"), res.LogColor)
+	assert.NotEqual(t, "", res.LogColor)
+
+	tf, _ := findResource(model.TiltfileManifestName, v)
+	assert.Equal(t, "", tf.LogColor)
+}
+
+func TestStateToWebViewEngineHealth(t *testing.T) {
+	state := newState([]model.Manifest{fooManifest})
+	v := stateToProtoView(t, *state)
+	_, ok := findResource(store.EngineHealthManifestName, v)
+	assert.False(t, ok, "Health resource should be hidden until the watchdog reports")
+
+	state.EngineHealth = store.EngineHealth{
+		CheckTime:            time.Now(),
+		ActionQueueDepth:     5000,
+		SlowestSubscriber:    "*engine.BuildController",
+		SlowestSubscriberLag: 2 * time.Second,
+		Problems:             []string{"5000 actions are waiting to be processed"},
+	}
+	v = stateToProtoView(t, *state)
+	res, ok := findResource(store.EngineHealthManifestName, v)
+	require.True(t, ok)
+	assert.Equal(t, string(model.RuntimeStatusError), res.RuntimeStatus)
+
+	facets := make(map[string]string)
+	for _, f := range res.Facets {
+		facets[f.Name] = f.Value
+	}
+	assert.Equal(t, "5000", facets["Action queue"])
+	assert.Equal(t, "*engine.BuildController (2s)", facets["Busiest subscriber"])
+	assert.Equal(t, "5000 actions are waiting to be processed", facets["Problems"])
+
+	state.EngineHealth.Problems = nil
+	v = stateToProtoView(t, *state)
+	res, _ = findResource(store.EngineHealthManifestName, v)
+	assert.Equal(t, string(model.RuntimeStatusOK), res.RuntimeStatus)
+}
+
 func TestStateToViewUnresourcedYAMLManifest(t *testing.T) {
 	m, err := k8s.NewK8sOnlyManifestFromYAML(testyaml.SanchoYAML)
 	assert.NoError(t, err)
@@ -220,6 +350,85 @@ func TestReadinessCheckFailing(t *testing.T) {
 	require.Equal(t, model.RuntimeStatusPending, model.RuntimeStatus(rv.RuntimeStatus))
 }
 
+func TestContainerStatuses(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets[m.Name].State.RuntimeState = store.K8sRuntimeState{
+		Pods: map[k8s.PodID]*store.Pod{
+			"pod id": {
+				Status: "CrashLoopBackOff",
+				Phase:  "Running",
+				Containers: []store.Container{
+					{
+						Name:                    "main",
+						Restarts:                3,
+						LastTerminationReason:   "OOMKilled",
+						LastTerminationExitCode: 137,
+					},
+					{
+						Name: "sidecar",
+					},
+				},
+			},
+		},
+	}
+
+	v := stateToProtoView(t, *state)
+	rv, ok := findResource(m.Name, v)
+	require.True(t, ok)
+	assert.Equal(t, []*proto_webview.ContainerStatus{
+		{
+			Name:                    "main",
+			Restarts:                3,
+			LastTerminationReason:   "OOMKilled",
+			LastTerminationExitCode: 137,
+			OomKilled:               true,
+		},
+		{
+			Name: "sidecar",
+		},
+	}, rv.K8SResourceInfo.Containers)
+}
+
+func TestNamespaceStatuses(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+	}.WithDeployTarget(model.K8sTarget{Namespaces: []string{"tenant-a", "tenant-b"}})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets[m.Name].State.RuntimeState = store.K8sRuntimeState{
+		Namespaces:                  []string{"tenant-a", "tenant-b"},
+		HasEverDeployedSuccessfully: true,
+		Pods: map[k8s.PodID]*store.Pod{
+			"pod-a": {
+				PodID:      "pod-a",
+				Namespace:  "tenant-a",
+				Status:     "Running",
+				Phase:      "Running",
+				Containers: []store.Container{{Ready: true}},
+			},
+		},
+	}
+
+	v := stateToProtoView(t, *state)
+	rv, ok := findResource(m.Name, v)
+	require.True(t, ok)
+	assert.Equal(t, []*proto_webview.NamespaceStatus{
+		{
+			Namespace:     "tenant-a",
+			PodName:       "pod-a",
+			PodStatus:     "Running",
+			RuntimeStatus: string(model.RuntimeStatusOK),
+		},
+		{
+			Namespace:     "tenant-b",
+			RuntimeStatus: string(model.RuntimeStatusPending),
+		},
+	}, rv.K8SResourceInfo.Namespaces)
+	assert.Equal(t, model.RuntimeStatusPending, model.RuntimeStatus(rv.RuntimeStatus))
+}
+
 func TestLocalResource(t *testing.T) {
 	cmd := model.Cmd{
 		Argv: []string{"make", "test"},
@@ -265,11 +474,11 @@ func TestBuildHistory(t *testing.T) {
 		Reason:     model.BuildReasonFlagCrash,
 		BuildTypes: []model.BuildType{model.BuildTypeImage, model.BuildTypeK8s},
 	}
-	buildRecords := []model.BuildRecord{br1, br2, br3}
+	// Most recent first.
+	buildRecords := []model.BuildRecord{br3, br2, br1}
 	expectedUpdateTypes := [][]proto_webview.UpdateType{
 		[]proto_webview.UpdateType{proto_webview.UpdateType_UPDATE_TYPE_IMAGE, proto_webview.UpdateType_UPDATE_TYPE_K8S},
 		[]proto_webview.UpdateType{proto_webview.UpdateType_UPDATE_TYPE_LIVE_UPDATE},
-		[]proto_webview.UpdateType{proto_webview.UpdateType_UPDATE_TYPE_IMAGE, proto_webview.UpdateType_UPDATE_TYPE_K8S},
 	}
 
 	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{})
@@ -280,18 +489,38 @@ func TestBuildHistory(t *testing.T) {
 	require.Equal(t, 2, len(v.Resources))
 	r := v.Resources[1]
 	require.Equal(t, "foo", r.Name)
-	require.Len(t, r.BuildHistory, 3)
+	// The view only has the most recent builds; the rest are at /api/build_history.
+	require.Len(t, r.BuildHistory, model.BuildHistoryViewLimit)
 
 	for i, actual := range r.BuildHistory {
 		expected := buildRecords[i]
 		require.Equal(t, expected.Edits, actual.Edits)
 		require.Equal(t, mustTimeToProto(expected.StartTime), actual.StartTime)
 		require.Equal(t, mustTimeToProto(expected.FinishTime), actual.FinishTime)
-		require.Equal(t, i == 2, actual.IsCrashRebuild)
+		require.Equal(t, i == 0, actual.IsCrashRebuild)
 		require.ElementsMatch(t, expectedUpdateTypes[i], actual.UpdateTypes)
 	}
 }
 
+func TestBuildRecordErrorCategory(t *testing.T) {
+	br := model.BuildRecord{
+		StartTime:     time.Now().Add(-time.Minute),
+		FinishTime:    time.Now(),
+		Error:         model.RegistryAuthError(fmt.Errorf("unauthorized")),
+		ErrorCategory: model.ErrorCategoryRegistryAuth,
+	}
+
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets[m.Name].State.BuildHistory = []model.BuildRecord{br}
+
+	v := stateToProtoView(t, *state)
+	b := lastBuild(v.Resources[1])
+	assert.Equal(t, "unauthorized", b.Error)
+	assert.Equal(t, "registry-auth", b.ErrorCategory)
+	assert.Contains(t, b.ErrorRemediation, "docker login")
+}
+
 func TestSpecs(t *testing.T) {
 	lu, err := model.NewLiveUpdate(
 		[]model.LiveUpdateStep{model.LiveUpdateSyncStep{Source: "foo", Dest: "bar"}}, ".")
@@ -358,3 +587,26 @@ func lastBuild(r *proto_webview.Resource) *proto_webview.BuildRecord {
 
 	return r.BuildHistory[0]
 }
+
+func TestAlertsOnlyIncludeUnacknowledged(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+	state := newState([]model.Manifest{fooManifest})
+	state.Alerts.Upsert(model.Alert{
+		ID:           "crash-loop:foo:pod-a",
+		Source:       model.AlertSourceCrashLoop,
+		Severity:     model.AlertSeverityError,
+		ManifestName: "foo",
+		Message:      "crashing",
+	}, now)
+	state.Alerts.Upsert(model.Alert{ID: "image-injection", Source: model.AlertSourceImageInjection}, now)
+	state.Alerts.Acknowledge([]string{"image-injection"})
+
+	v := stateToProtoView(t, *state)
+	require.Len(t, v.Alerts, 1)
+	assert.Equal(t, "crash-loop:foo:pod-a", v.Alerts[0].Id)
+	assert.Equal(t, "crash-loop", v.Alerts[0].Source)
+	assert.Equal(t, "error", v.Alerts[0].Severity)
+	assert.Equal(t, "foo", v.Alerts[0].ManifestName)
+	assert.Equal(t, int32(1), v.Alerts[0].Count)
+	assert.Equal(t, now.Unix(), v.Alerts[0].LastSeen.Seconds)
+}
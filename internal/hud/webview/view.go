@@ -141,6 +141,9 @@ func ToProtoBuildRecord(br model.BuildRecord, logStore *logstore.LogStore) (*pro
 		UpdateTypes:    updateTypes,
 		IsCrashRebuild: br.Reason.IsCrashOnly(),
 		SpanId:         string(br.SpanID),
+
+		ErrorCategory:    string(br.ErrorCategory),
+		ErrorRemediation: br.ErrorCategory.Remediation(),
 	}, nil
 }
 
@@ -8,7 +8,9 @@ import (
 var WireSet = wire.NewSet(
 	NewRenderer,
 	NewHud,
+	NewPinStore,
 	NewTerminalStream,
+	NewJSONStream,
 	ProvideStdout,
 	NewIncrementalPrinter)
 
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Kinds that other objects in the same resource may depend on, and the order
+// we apply them in. Objects live in Namespaces, custom resources need their
+// CustomResourceDefinition, and pods need their ServiceAccount and its roles.
+var foundationKindOrder = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 1,
+	"ServiceAccount":           2,
+	"Role":                     3,
+	"ClusterRole":              4,
+	"RoleBinding":              5,
+	"ClusterRoleBinding":       6,
+}
+
+// How long to wait for the API server to start serving the CRDs we just applied.
+var crdEstablishedTimeout = 30 * time.Second
+
+// Even once a CRD is established, kubectl's discovery cache may not know about
+// it yet, so we keep retrying objects of kinds the server doesn't recognize
+// for a little while.
+var noKindMatchRetryTimeout = 10 * time.Second
+var noKindMatchRetryInterval = time.Second
+
+// Splits the entities into the ones that others depend on (in the order we
+// apply them) and everything else (in input order).
+func foundationEntities(entities []K8sEntity) (foundation, rest []K8sEntity) {
+	for _, e := range entities {
+		if _, ok := foundationKindOrder[e.GVK().Kind]; ok {
+			foundation = append(foundation, e)
+			continue
+		}
+		rest = append(rest, e)
+	}
+
+	sort.SliceStable(foundation, func(i, j int) bool {
+		return foundationKindOrder[foundation[i].GVK().Kind] < foundationKindOrder[foundation[j].GVK().Kind]
+	})
+	return foundation, rest
+}
+
+// Waits until the API server serves the given CRDs.
+//
+// If it doesn't happen in time, we go ahead anyway: applying the custom
+// resources will retry for a bit, then report a more specific error.
+func (k K8sClient) waitForCRDsEstablished(ctx context.Context, entities []K8sEntity) {
+	args := []string{"wait", "--for=condition=established", fmt.Sprintf("--timeout=%s", crdEstablishedTimeout)}
+	for _, e := range entities {
+		if e.GVK().Kind == "CustomResourceDefinition" {
+			args = append(args, fmt.Sprintf("crd/%s", e.Name()))
+		}
+	}
+	if len(args) == 3 {
+		return
+	}
+
+	_, stderr, err := k.kubectlRunner.exec(ctx, args)
+	if err != nil {
+		logger.Get(ctx).Warnf("CustomResourceDefinitions not established yet: %v", strings.TrimSpace(stderr))
+	}
+}
+
+// We're using kubectl, so we only get stderr, not structured errors.
+//
+// Guess if the apply failed because the server doesn't know about the kind yet,
+// e.g., because we applied its CRD a moment ago.
+func isNoKindMatchError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "no matches for kind") ||
+		strings.Contains(msg, "ensure CRDs are installed first")
+}
+
+// Calls apply, retrying for a bit while it fails because of an unknown kind.
+func retryNoKindMatch(ctx context.Context, apply func() error) error {
+	deadline := time.Now().Add(noKindMatchRetryTimeout)
+	for {
+		err := apply()
+		if !isNoKindMatchError(err) || time.Now().After(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(noKindMatchRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestFoundationEntities(t *testing.T) {
+	crd := MustParseYAMLFromString(t, testyaml.CRDYAML)
+	eDeploy := MustParseYAMLFromString(t, testyaml.SanchoYAML)[0]
+	eNamespace := MustParseYAMLFromString(t, testyaml.MyNamespaceYAML)[0]
+
+	foundation, rest := foundationEntities([]K8sEntity{crd[1], eDeploy, crd[0], eNamespace})
+	assert.Equal(t, []K8sEntity{eNamespace, crd[0]}, foundation)
+	assert.Equal(t, []K8sEntity{crd[1], eDeploy}, rest)
+}
+
+func TestWaitForCRDsEstablished(t *testing.T) {
+	f := newClientTestFixture(t)
+	crd := MustParseYAMLFromString(t, testyaml.CRDYAML)
+
+	f.client.waitForCRDsEstablished(f.ctx, crd)
+	require.Len(t, f.runner.calls, 1)
+	assert.Equal(t,
+		[]string{"wait", "--for=condition=established", "--timeout=30s", "crd/projects.example.martin-helmich.de"},
+		f.runner.calls[0].argv)
+}
+
+func TestWaitForCRDsEstablishedNoCRDs(t *testing.T) {
+	f := newClientTestFixture(t)
+	eNamespace := MustParseYAMLFromString(t, testyaml.MyNamespaceYAML)[0]
+
+	f.client.waitForCRDsEstablished(f.ctx, []K8sEntity{eNamespace})
+	assert.Len(t, f.runner.calls, 0)
+}
+
+func TestRetryNoKindMatch(t *testing.T) {
+	defer shortNoKindMatchRetry()()
+
+	tries := 0
+	err := retryNoKindMatch(context.Background(), func() error {
+		tries++
+		if tries < 3 {
+			return fmt.Errorf(`kubectl apply:
+stderr: error: unable to recognize "STDIN": no matches for kind "Project" in version "example.martin-helmich.de/v1alpha1"`)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tries)
+}
+
+func TestRetryNoKindMatchOtherError(t *testing.T) {
+	defer shortNoKindMatchRetry()()
+
+	tries := 0
+	err := retryNoKindMatch(context.Background(), func() error {
+		tries++
+		return fmt.Errorf("kubectl apply:\nstderr: the server rejected our request")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, tries)
+}
+
+func TestRetryNoKindMatchGivesUp(t *testing.T) {
+	defer shortNoKindMatchRetry()()
+
+	err := retryNoKindMatch(context.Background(), func() error {
+		return fmt.Errorf(`resource mapping not found for name: "example-project": ensure CRDs are installed first`)
+	})
+	assert.True(t, isNoKindMatchError(err))
+}
+
+func shortNoKindMatchRetry() func() {
+	oldTimeout, oldInterval := noKindMatchRetryTimeout, noKindMatchRetryInterval
+	noKindMatchRetryTimeout, noKindMatchRetryInterval = 50*time.Millisecond, time.Millisecond
+	return func() {
+		noKindMatchRetryTimeout, noKindMatchRetryInterval = oldTimeout, oldInterval
+	}
+}
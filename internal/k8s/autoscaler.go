@@ -0,0 +1,178 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The annotation where Tilt keeps an autoscaler's settings while it's paused,
+// so that Tilt (or `tilt down`) can restore them.
+const PausedAutoscalingAnnotation = "tilt.dev/paused-autoscaling"
+
+// KEDA stops scaling a ScaledObject and holds its target at this many
+// replicas while the annotation is set.
+const kedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+var hpaGroupKind = schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}
+var scaledObjectGroupKind = schema.GroupKind{Group: "keda.sh", Kind: "ScaledObject"}
+
+// The autoscaler APIs that Tilt knows how to pause.
+var autoscalerResources = []schema.GroupVersionResource{
+	{Group: "autoscaling", Version: "v1", Resource: "horizontalpodautoscalers"},
+	{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"},
+}
+
+// The settings we overwrite to pause an autoscaler.
+type pausedAutoscaling struct {
+	MinReplicas    interface{} `json:"minReplicas,omitempty"`
+	MaxReplicas    interface{} `json:"maxReplicas,omitempty"`
+	PausedReplicas *string     `json:"pausedReplicas,omitempty"`
+}
+
+func (e K8sEntity) IsAutoscaler() bool {
+	gk := e.GVK().GroupKind()
+	return gk == hpaGroupKind || gk == scaledObjectGroupKind
+}
+
+// The kind and name of the workload that an autoscaler scales.
+//
+// Returns false if the entity isn't an autoscaler.
+func (e K8sEntity) AutoscalerTarget() (kind string, name string, ok bool) {
+	if !e.IsAutoscaler() {
+		return "", "", false
+	}
+	content, err := toUnstructuredContent(e)
+	if err != nil {
+		return "", "", false
+	}
+	kind, _, _ = unstructured.NestedString(content, "spec", "scaleTargetRef", "kind")
+	name, _, _ = unstructured.NestedString(content, "spec", "scaleTargetRef", "name")
+	if kind == "" && e.GVK().GroupKind() == scaledObjectGroupKind {
+		// KEDA defaults to scaling a Deployment.
+		kind = "Deployment"
+	}
+	return kind, name, name != ""
+}
+
+// A JSON merge patch that pauses an autoscaler at 1 replica, and records the
+// settings it overwrites.
+//
+// Returns nil if the autoscaler is already paused.
+func PauseAutoscalerPatch(e K8sEntity) ([]byte, error) {
+	content, err := toUnstructuredContent(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved pausedAutoscaling
+	patch := map[string]interface{}{}
+	annotations := map[string]interface{}{}
+	switch e.GVK().GroupKind() {
+	case hpaGroupKind:
+		min, hasMin, _ := unstructured.NestedFieldNoCopy(content, "spec", "minReplicas")
+		max, _, _ := unstructured.NestedFieldNoCopy(content, "spec", "maxReplicas")
+		if (!hasMin || isOneReplica(min)) && isOneReplica(max) {
+			return nil, nil
+		}
+		if hasMin {
+			saved.MinReplicas = min
+		}
+		saved.MaxReplicas = max
+		patch["spec"] = map[string]interface{}{"minReplicas": 1, "maxReplicas": 1}
+
+	case scaledObjectGroupKind:
+		current, ok := e.Annotations()[kedaPausedReplicasAnnotation]
+		if ok && current == "1" {
+			return nil, nil
+		}
+		if ok {
+			saved.PausedReplicas = &current
+		}
+		annotations[kedaPausedReplicasAnnotation] = "1"
+
+	default:
+		return nil, fmt.Errorf("%s %s is not an autoscaler", e.GVK().Kind, e.Name())
+	}
+
+	savedJSON, err := json.Marshal(saved)
+	if err != nil {
+		return nil, err
+	}
+	annotations[PausedAutoscalingAnnotation] = string(savedJSON)
+	patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	return json.Marshal(patch)
+}
+
+// A JSON merge patch that restores the settings that PauseAutoscalerPatch recorded.
+//
+// Returns nil if Tilt didn't pause the autoscaler.
+func ResumeAutoscalerPatch(e K8sEntity) ([]byte, error) {
+	savedJSON, ok := e.Annotations()[PausedAutoscalingAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var saved pausedAutoscaling
+	err := json.Unmarshal([]byte(savedJSON), &saved)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: reading annotation %s: %v", e.GVK().Kind, e.Name(), PausedAutoscalingAnnotation, err)
+	}
+
+	patch := map[string]interface{}{}
+	annotations := map[string]interface{}{PausedAutoscalingAnnotation: nil}
+	switch e.GVK().GroupKind() {
+	case hpaGroupKind:
+		// A null minReplicas goes back to the default.
+		patch["spec"] = map[string]interface{}{"minReplicas": saved.MinReplicas, "maxReplicas": saved.MaxReplicas}
+	case scaledObjectGroupKind:
+		if saved.PausedReplicas != nil {
+			annotations[kedaPausedReplicasAnnotation] = *saved.PausedReplicas
+		} else {
+			annotations[kedaPausedReplicasAnnotation] = nil
+		}
+	default:
+		return nil, fmt.Errorf("%s %s is not an autoscaler", e.GVK().Kind, e.Name())
+	}
+	patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	return json.Marshal(patch)
+}
+
+func isOneReplica(v interface{}) bool {
+	switch n := v.(type) {
+	case int64:
+		return n == 1
+	case float64:
+		return n == 1
+	}
+	return false
+}
+
+// Pins a workload to 1 replica, so that it doesn't fight the autoscalers we pause.
+func InjectSingleReplica(entity K8sEntity) (K8sEntity, error) {
+	one := int32(1)
+	switch obj := entity.Obj.(type) {
+	case *appsv1.Deployment:
+		entity = entity.DeepCopy()
+		entity.Obj.(*appsv1.Deployment).Spec.Replicas = &one
+	case *appsv1.StatefulSet:
+		entity = entity.DeepCopy()
+		entity.Obj.(*appsv1.StatefulSet).Spec.Replicas = &one
+	case *appsv1.ReplicaSet:
+		entity = entity.DeepCopy()
+		entity.Obj.(*appsv1.ReplicaSet).Spec.Replicas = &one
+	case *unstructured.Unstructured:
+		if !IsScalableKind(obj.GetKind()) {
+			return entity, nil
+		}
+		entity = entity.DeepCopy()
+		err := unstructured.SetNestedField(entity.Obj.(*unstructured.Unstructured).Object, int64(1), "spec", "replicas")
+		if err != nil {
+			return K8sEntity{}, fmt.Errorf("%s %s: setting replicas: %v", obj.GetKind(), entity.Name(), err)
+		}
+	}
+	return entity, nil
+}
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestAutoscalerTarget(t *testing.T) {
+	kind, name, ok := newTestHPA(map[string]interface{}{"maxReplicas": int64(5)}).AutoscalerTarget()
+	assert.True(t, ok)
+	assert.Equal(t, "Deployment", kind)
+	assert.Equal(t, "fe", name)
+
+	kind, name, ok = newTestScaledObject(nil).AutoscalerTarget()
+	assert.True(t, ok)
+	assert.Equal(t, "Deployment", kind)
+	assert.Equal(t, "fe", name)
+
+	_, _, ok = NewK8sEntity(&appsv1.Deployment{}).AutoscalerTarget()
+	assert.False(t, ok)
+}
+
+func TestPauseAndResumeHPA(t *testing.T) {
+	hpa := newTestHPA(map[string]interface{}{"minReplicas": int64(2), "maxReplicas": int64(10)})
+
+	patch, err := PauseAutoscalerPatch(hpa)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+"metadata":{"annotations":{"tilt.dev/paused-autoscaling":"{\"minReplicas\":2,\"maxReplicas\":10}"}},
+"spec":{"minReplicas":1,"maxReplicas":1}}`, string(patch))
+
+	paused := newTestHPA(map[string]interface{}{"minReplicas": int64(1), "maxReplicas": int64(1)})
+	paused.Obj.(*unstructured.Unstructured).SetAnnotations(map[string]string{
+		PausedAutoscalingAnnotation: `{"minReplicas":2,"maxReplicas":10}`,
+	})
+	patch, err = PauseAutoscalerPatch(paused)
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+
+	patch, err = ResumeAutoscalerPatch(paused)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+"metadata":{"annotations":{"tilt.dev/paused-autoscaling":null}},
+"spec":{"minReplicas":2,"maxReplicas":10}}`, string(patch))
+}
+
+func TestResumeHPAWithDefaultMinReplicas(t *testing.T) {
+	patch, err := PauseAutoscalerPatch(newTestHPA(map[string]interface{}{"maxReplicas": int64(10)}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+"metadata":{"annotations":{"tilt.dev/paused-autoscaling":"{\"maxReplicas\":10}"}},
+"spec":{"minReplicas":1,"maxReplicas":1}}`, string(patch))
+
+	paused := newTestHPA(map[string]interface{}{"minReplicas": int64(1), "maxReplicas": int64(1)})
+	paused.Obj.(*unstructured.Unstructured).SetAnnotations(map[string]string{
+		PausedAutoscalingAnnotation: `{"maxReplicas":10}`,
+	})
+	patch, err = ResumeAutoscalerPatch(paused)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+"metadata":{"annotations":{"tilt.dev/paused-autoscaling":null}},
+"spec":{"minReplicas":null,"maxReplicas":10}}`, string(patch))
+}
+
+func TestPauseAndResumeScaledObject(t *testing.T) {
+	patch, err := PauseAutoscalerPatch(newTestScaledObject(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"annotations":{
+"tilt.dev/paused-autoscaling":"{}",
+"autoscaling.keda.sh/paused-replicas":"1"}}}`, string(patch))
+
+	patch, err = ResumeAutoscalerPatch(newTestScaledObject(map[string]string{
+		PausedAutoscalingAnnotation:  `{}`,
+		kedaPausedReplicasAnnotation: "1",
+	}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"annotations":{
+"tilt.dev/paused-autoscaling":null,
+"autoscaling.keda.sh/paused-replicas":null}}}`, string(patch))
+
+	// Restore a pause that the user set themselves.
+	patch, err = ResumeAutoscalerPatch(newTestScaledObject(map[string]string{
+		PausedAutoscalingAnnotation:  `{"pausedReplicas":"0"}`,
+		kedaPausedReplicasAnnotation: "1",
+	}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"annotations":{
+"tilt.dev/paused-autoscaling":null,
+"autoscaling.keda.sh/paused-replicas":"0"}}}`, string(patch))
+}
+
+func TestResumeAutoscalerNotPausedByTilt(t *testing.T) {
+	patch, err := ResumeAutoscalerPatch(newTestScaledObject(map[string]string{kedaPausedReplicasAnnotation: "1"}))
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestInjectSingleReplica(t *testing.T) {
+	three := int32(3)
+	deployment := NewK8sEntity(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &three},
+	})
+	result, err := InjectSingleReplica(deployment)
+	require.NoError(t, err)
+	replicas, _ := result.Replicas()
+	assert.Equal(t, int32(1), replicas)
+
+	original, _ := deployment.Replicas()
+	assert.Equal(t, int32(3), original)
+
+	statefulSet := NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata":   map[string]interface{}{"name": "db"},
+		"spec":       map[string]interface{}{"replicas": int64(3)},
+	}})
+	result, err = InjectSingleReplica(statefulSet)
+	require.NoError(t, err)
+	replicas, _ = result.Replicas()
+	assert.Equal(t, int32(1), replicas)
+}
+
+func newTestHPA(spec map[string]interface{}) K8sEntity {
+	spec["scaleTargetRef"] = map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "fe"}
+	return NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "fe"},
+		"spec":       spec,
+	}})
+}
+
+func newTestScaledObject(annotations map[string]string) K8sEntity {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata":   map[string]interface{}{"name": "fe"},
+		"spec": map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"name": "fe"},
+		},
+	}}
+	if annotations != nil {
+		obj.SetAnnotations(annotations)
+	}
+	return NewK8sEntity(obj)
+}
@@ -13,10 +13,12 @@ import (
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -33,6 +35,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type Namespace string
@@ -104,6 +107,8 @@ type Client interface {
 
 	WatchServices(ctx context.Context, ns Namespace, lps labels.Selector) (<-chan *v1.Service, error)
 
+	WatchEndpoints(ctx context.Context, ns Namespace, lps labels.Selector) (<-chan *v1.Endpoints, error)
+
 	WatchEvents(ctx context.Context, ns Namespace) (<-chan *v1.Event, error)
 
 	ConnectedToCluster(ctx context.Context) error
@@ -117,6 +122,35 @@ type Client interface {
 	NodeIP(ctx context.Context) NodeIP
 
 	Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+
+	// Deletes the entity through the API server with the given propagation
+	// policy, without waiting for it to go away.
+	//
+	// Returns a reference to the object being deleted (with its namespace and UID
+	// filled in), so that callers can wait for it. Returns a NotFound error if
+	// the object doesn't exist.
+	DeleteWithPropagation(ctx context.Context, entity K8sEntity, policy metav1.DeletionPropagation) (v1.ObjectReference, error)
+
+	// Applies a JSON merge patch to the referenced object.
+	MergePatch(ctx context.Context, ref v1.ObjectReference, patch []byte) error
+
+	// Lists objects of every type in every namespace that match the selector.
+	//
+	// Skips any types that we're not allowed to list, so this may be incomplete
+	// on clusters with restrictive RBAC.
+	ListBySelector(ctx context.Context, selector labels.Selector) ([]K8sEntity, error)
+
+	// Lists the PersistentVolumeClaims in a namespace that match the selector.
+	ListPersistentVolumeClaims(ctx context.Context, ns Namespace, selector labels.Selector) ([]v1.PersistentVolumeClaim, error)
+
+	// Lists the HorizontalPodAutoscalers and KEDA ScaledObjects in a namespace.
+	//
+	// Skips KEDA if it isn't installed.
+	ListAutoscalers(ctx context.Context, ns Namespace) ([]K8sEntity, error)
+
+	// Attaches an ephemeral container to a running pod, and waits for it to start.
+	// Does nothing if the pod already has a running ephemeral container with that name.
+	EnsureEphemeralContainer(ctx context.Context, pID PodID, n Namespace, c v1.EphemeralContainer) error
 }
 
 type K8sClient struct {
@@ -139,6 +173,7 @@ var _ Client = K8sClient{}
 func ProvideK8sClient(
 	ctx context.Context,
 	env Env,
+	maybeAPIConfig APIConfigOrError,
 	maybeRESTConfig RESTConfigOrError,
 	maybeClientset ClientsetOrError,
 	pfClient PortForwardClient,
@@ -148,6 +183,9 @@ func ProvideK8sClient(
 	clientLoader clientcmd.ClientConfig) Client {
 	if env == EnvNone {
 		// No k8s, so no need to get any further configs
+		if maybeAPIConfig.Error != nil {
+			return &explodingClient{err: maybeAPIConfig.Error}
+		}
 		return &explodingClient{err: fmt.Errorf("Kubernetes context not set in %s", clientLoader.ConfigAccess().GetLoadingPrecedence())}
 	}
 
@@ -254,60 +292,100 @@ func timeoutError(timeout time.Duration) error {
 	return errors.New(fmt.Sprintf("Killed kubectl. Hit timeout of %v.", timeout))
 }
 
+// Applies the entities in an order where they're likely to work on the first pass:
+// Namespaces, CRDs, and RBAC before the objects that depend on them,
+// and immutable objects last.
 func (k K8sClient) Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
 	result := make([]K8sEntity, 0, len(entities))
 
 	mutable, immutable := MutableAndImmutableEntities(entities)
+	foundation, rest := foundationEntities(mutable)
+	serverVersion := k.serverGitVersion()
 
-	for _, e := range mutable {
+	upsert := func(e K8sEntity, act func(ctx context.Context, e K8sEntity) ([]K8sEntity, []string, error)) error {
 		innerCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		newEntity, err := k.applyEntityAndMaybeForce(innerCtx, e)
+		var newEntities []K8sEntity
+		var warnings []string
+		err := retryNoKindMatch(innerCtx, func() error {
+			var err error
+			newEntities, warnings, err = act(innerCtx, e)
+			return err
+		})
 		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
-				return nil, timeoutError(timeout)
+				return timeoutError(timeout)
 			}
+			return err
+		}
+		logApplyWarnings(ctx, e, removedAPIWarning(e, serverVersion), warnings)
+		result = append(result, newEntities...)
+		return nil
+	}
+
+	for _, e := range foundation {
+		err := upsert(e, k.applyEntityAndMaybeForce)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, newEntity...)
 	}
 
-	for _, e := range immutable {
-		innerCtx, cancel := context.WithTimeout(ctx, timeout)
-		defer cancel()
+	if len(rest) > 0 || len(immutable) > 0 {
+		k.waitForCRDsEstablished(ctx, foundation)
+	}
 
-		newEntities, err := k.forceReplaceEntity(innerCtx, e)
+	for _, e := range rest {
+		err := upsert(e, k.applyEntityAndMaybeForce)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, e := range immutable {
+		err := upsert(e, k.forceReplaceEntity)
 		if err != nil {
-			if ctx.Err() == context.DeadlineExceeded {
-				return nil, timeoutError(timeout)
-			}
 			return nil, err
 		}
-		result = append(result, newEntities...)
 	}
 
 	return result, nil
 }
 
-func (k K8sClient) forceReplaceEntity(ctx context.Context, entity K8sEntity) ([]K8sEntity, error) {
+// The version of the API server (e.g., "v1.22.3"), or "" if we can't tell.
+func (k K8sClient) serverGitVersion() string {
+	if k.clientset == nil {
+		return ""
+	}
+	info, err := k.clientset.Discovery().ServerVersion()
+	if err != nil || info == nil {
+		return ""
+	}
+	return info.GitVersion
+}
+
+// Returns the replaced entities, and any warnings from the API server.
+func (k K8sClient) forceReplaceEntity(ctx context.Context, entity K8sEntity) ([]K8sEntity, []string, error) {
 	stdout, stderr, err := k.actOnEntity(ctx, []string{"replace", "-o", "yaml", "--force"}, entity)
 	if err != nil {
-		return nil, errors.Wrapf(err, "kubectl replace:\nstderr: %s", stderr)
+		return nil, nil, maybeClusterConnError(errors.Wrapf(err, "kubectl replace:\nstderr: %s", stderr), stderr)
 	}
 
-	return parseYAMLFromStringWithDeletedResources(stdout)
+	entities, err := parseYAMLFromStringWithDeletedResources(stdout)
+	return entities, parseKubectlWarnings(stderr), err
 }
 
 // applyEntityAndMaybeForce `kubectl apply`'s the given entity, and if the call fails with
 // an immutible field error, attempts to `replace --force` it.
-func (k K8sClient) applyEntityAndMaybeForce(ctx context.Context, entity K8sEntity) ([]K8sEntity, error) {
+//
+// Returns the applied entities, and any warnings from the API server.
+func (k K8sClient) applyEntityAndMaybeForce(ctx context.Context, entity K8sEntity) ([]K8sEntity, []string, error) {
 	stdout, stderr, err := k.actOnEntity(ctx, []string{"apply", "-o", "yaml"}, entity)
 	if err != nil {
 		reason, shouldTryReplace := maybeShouldTryReplaceReason(stderr)
 
 		if !shouldTryReplace {
-			return nil, errors.Wrapf(err, "kubectl apply:\nstderr: %s", stderr)
+			return nil, nil, maybeClusterConnError(errors.Wrapf(err, "kubectl apply:\nstderr: %s", stderr), stderr)
 		}
 
 		// NOTE(maia): we don't use `kubecutl replace --force`, because we want to ensure that all
@@ -317,22 +395,23 @@ func (k K8sClient) applyEntityAndMaybeForce(ctx context.Context, entity K8sEntit
 		// --ignore-not-found because, e.g., if we fell back due to large metadata.annotations, the object might not exist
 		_, stderr, err = k.actOnEntity(ctx, []string{"delete", "--ignore-not-found=true"}, entity)
 		if err != nil {
-			return nil, errors.Wrapf(err, "kubectl delete (as part of delete && create):\nstderr: %s", stderr)
+			return nil, nil, errors.Wrapf(err, "kubectl delete (as part of delete && create):\nstderr: %s", stderr)
 		}
 		stdout, stderr, err = k.actOnEntity(ctx, []string{"create", "-o", "yaml"}, entity)
 		if err != nil {
-			return nil, errors.Wrapf(err, "kubectl create (as part of delete && create):\nstderr: %s", stderr)
+			return nil, nil, errors.Wrapf(err, "kubectl create (as part of delete && create):\nstderr: %s", stderr)
 		}
 		logger.Get(ctx).Infof("Succeeded!")
 	}
 
-	return ParseYAMLFromString(stdout)
+	entities, err := ParseYAMLFromString(stdout)
+	return entities, parseKubectlWarnings(stderr), err
 }
 
 func (k K8sClient) ConnectedToCluster(ctx context.Context) error {
 	stdout, stderr, err := k.kubectlRunner.exec(ctx, []string{"cluster-info"})
 	if err != nil {
-		return errors.Wrapf(err, "Unable to connect to cluster via `kubectl cluster-info`:\nstdout: %s\nstderr: %s", stdout, stderr)
+		return model.ClusterConnError(errors.Wrapf(err, "Unable to connect to cluster via `kubectl cluster-info`:\nstdout: %s\nstderr: %s", stdout, stderr))
 	}
 
 	return nil
@@ -364,6 +443,26 @@ func maybeAnnotationsTooLong(stderr string) (string, bool) {
 	return "", false
 }
 
+// Messages kubectl prints when it can't reach the API server at all.
+var clusterConnStderrMessages = []string{
+	"Unable to connect to the server",
+	"The connection to the server",
+	"connection refused",
+	"no such host",
+	"i/o timeout",
+}
+
+// Likewise, guess if kubectl failed because it couldn't talk to the cluster,
+// rather than because the cluster rejected what we sent.
+func maybeClusterConnError(err error, stderr string) error {
+	for _, msg := range clusterConnStderrMessages {
+		if strings.Contains(stderr, msg) {
+			return model.ClusterConnError(err)
+		}
+	}
+	return err
+}
+
 func maybeShouldTryReplaceReason(stderr string) (string, bool) {
 	if maybeImmutableFieldStderr(stderr) {
 		return "immutable field error", true
@@ -386,7 +485,7 @@ func (k K8sClient) Delete(ctx context.Context, entities []K8sEntity) error {
 
 		_, stderr, err := k.actOnEntity(ctx, []string{"delete", "--ignore-not-found"}, e)
 		if err != nil {
-			return errors.Wrapf(err, "kubectl delete:\nstderr: %s", stderr)
+			return maybeClusterConnError(errors.Wrapf(err, "kubectl delete:\nstderr: %s", stderr), stderr)
 		}
 	}
 	return nil
@@ -405,12 +504,151 @@ func (k K8sClient) actOnEntity(ctx context.Context, cmdArgs []string, entity K8s
 }
 
 func (k K8sClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error) {
-	group := getGroup(ref)
 	kind := ref.Kind
 	namespace := ref.Namespace
 	name := ref.Name
 	resourceVersion := ref.ResourceVersion
 	uid := ref.UID
+	rm, err := k.restMappingForReference(ref)
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	result, err := k.dynamic.Resource(rm.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{
+		ResourceVersion: resourceVersion,
+	})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+	if uid != "" && result.GetUID() != uid {
+		return K8sEntity{}, apierrors.NewNotFound(v1.Resource(kind), name)
+	}
+	return NewK8sEntity(result), nil
+}
+
+func (k K8sClient) DeleteWithPropagation(ctx context.Context, entity K8sEntity, policy metav1.DeletionPropagation) (v1.ObjectReference, error) {
+	ref := entity.ToObjectReference()
+	rm, err := k.restMappingForReference(ref)
+	if err != nil {
+		return v1.ObjectReference{}, err
+	}
+
+	if rm.Scope.Name() == meta.RESTScopeNameRoot {
+		ref.Namespace = ""
+	} else if ref.Namespace == "" {
+		ref.Namespace = k.configNamespace.String()
+	}
+
+	ri := k.dynamic.Resource(rm.Resource).Namespace(ref.Namespace)
+	obj, err := ri.Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return v1.ObjectReference{}, err
+	}
+	ref.UID = obj.GetUID()
+
+	// The UID precondition makes sure we don't delete an object that
+	// someone re-created after our Get.
+	err = ri.Delete(ctx, ref.Name, metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+		Preconditions:     &metav1.Preconditions{UID: &ref.UID},
+	})
+	if err != nil {
+		return v1.ObjectReference{}, err
+	}
+	return ref, nil
+}
+
+func (k K8sClient) MergePatch(ctx context.Context, ref v1.ObjectReference, patch []byte) error {
+	rm, err := k.restMappingForReference(ref)
+	if err != nil {
+		return err
+	}
+
+	_, err = k.dynamic.Resource(rm.Resource).Namespace(ref.Namespace).Patch(ctx, ref.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (k K8sClient) ListPersistentVolumeClaims(ctx context.Context, ns Namespace, selector labels.Selector) ([]v1.PersistentVolumeClaim, error) {
+	list, err := k.core.PersistentVolumeClaims(ns.String()).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (k K8sClient) ListAutoscalers(ctx context.Context, ns Namespace) ([]K8sEntity, error) {
+	result := []K8sEntity{}
+	for _, gvr := range autoscalerResources {
+		list, err := k.dynamic.Resource(gvr).Namespace(ns.String()).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) {
+				logger.Get(ctx).Debugf("Skipping %s: %v", gvr, err)
+				continue
+			}
+			return nil, errors.Wrapf(err, "listing %s", gvr)
+		}
+
+		for i := range list.Items {
+			result = append(result, NewK8sEntity(&list.Items[i]))
+		}
+	}
+	return result, nil
+}
+
+func (k K8sClient) ListBySelector(ctx context.Context, selector labels.Selector) ([]K8sEntity, error) {
+	// Discovery often partially fails (e.g., when an aggregated API server is down),
+	// so only bail if we got nothing at all.
+	resourceLists, err := k.clientset.Discovery().ServerPreferredResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, errors.Wrap(err, "discovering resource types")
+	}
+
+	result := []K8sEntity{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, r := range resourceList.APIResources {
+			if strings.Contains(r.Name, "/") || !hasVerb(r.Verbs, "list") {
+				// Skip subresources and anything we can't list.
+				continue
+			}
+
+			list, err := k.dynamic.Resource(gv.WithResource(r.Name)).List(ctx, metav1.ListOptions{
+				LabelSelector: selector.String(),
+			})
+			if err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					logger.Get(ctx).Debugf("Skipping %s: %v", gv.WithResource(r.Name), err)
+					continue
+				}
+				return nil, errors.Wrapf(err, "listing %s", gv.WithResource(r.Name))
+			}
+
+			for i := range list.Items {
+				result = append(result, NewK8sEntity(&list.Items[i]))
+			}
+		}
+	}
+	return result, nil
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+func (k K8sClient) restMappingForReference(ref v1.ObjectReference) (*meta.RESTMapping, error) {
+	group := getGroup(ref)
+	kind := ref.Kind
 	rm, err := k.drm.RESTMapping(schema.GroupKind{Group: group, Kind: kind})
 	if err != nil {
 		// The REST mapper doesn't have any sort of internal invalidation
@@ -426,20 +664,10 @@ func (k K8sClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (
 
 		rm, err = k.drm.RESTMapping(schema.GroupKind{Group: group, Kind: kind})
 		if err != nil {
-			return K8sEntity{}, errors.Wrapf(err, "error mapping %s/%s", group, kind)
+			return nil, errors.Wrapf(err, "error mapping %s/%s", group, kind)
 		}
 	}
-
-	result, err := k.dynamic.Resource(rm.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{
-		ResourceVersion: resourceVersion,
-	})
-	if err != nil {
-		return K8sEntity{}, err
-	}
-	if uid != "" && result.GetUID() != uid {
-		return K8sEntity{}, apierrors.NewNotFound(v1.Resource(kind), name)
-	}
-	return NewK8sEntity(result), nil
+	return rm, nil
 }
 
 // Tests whether a string is a valid version for a k8s resource type.
@@ -520,9 +748,23 @@ func ProvideConfigNamespace(clientLoader clientcmd.ClientConfig) Namespace {
 type RESTConfigOrError struct {
 	Config *rest.Config
 	Error  error
+
+	// Nil unless the context authenticates with an exec credential plugin.
+	ExecCredentials *ExecCredentials
 }
 
-func ProvideRESTConfig(clientLoader clientcmd.ClientConfig) RESTConfigOrError {
+func ProvideRESTConfig(clientLoader clientcmd.ClientConfig, contextOverride KubeContextOverride) RESTConfigOrError {
 	config, err := clientLoader.ClientConfig()
-	return RESTConfigOrError{Config: config, Error: err}
+	if err != nil {
+		return RESTConfigOrError{Error: err}
+	}
+
+	kubeContext := KubeContext(contextOverride)
+	if kubeContext == "" {
+		rawConfig, err := clientLoader.RawConfig()
+		if err == nil {
+			kubeContext = KubeContext(rawConfig.CurrentContext)
+		}
+	}
+	return RESTConfigOrError{Config: config, ExecCredentials: newExecCredentials(kubeContext, config)}
 }
@@ -72,9 +72,9 @@ func TestUpsertMutableAndImmutable(t *testing.T) {
 	call0Entity := mustParseYAML(t, call0.stdin)[0]
 	call1Entity := mustParseYAML(t, call1.stdin)[0]
 
-	// `apply` should preserve input order of entities (we sort them further upstream)
-	require.Equal(t, eDeploy, call0Entity, "expect call 0 to have applied deployment first (preserve input order)")
-	require.Equal(t, eNamespace, call1Entity, "expect call 0 to have applied namespace second (preserve input order)")
+	// the namespace goes first, because the deployment might live in it
+	require.Equal(t, eNamespace, call0Entity, "expect call 0 to have applied namespace first")
+	require.Equal(t, eDeploy, call1Entity, "expect call 1 to have applied deployment second")
 
 	call2 := f.runner.calls[2]
 	require.Equal(t, []string{"replace", "-o", "yaml", "--force", "-f", "-"}, call2.argv, "expected args for call 1")
@@ -0,0 +1,154 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/batch/v1beta1"
+	"k8s.io/api/batch/v2alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const CronJobKind = "CronJob"
+
+// The annotation Kubernetes puts on Jobs that were created from a CronJob
+// by hand, rather than on its schedule.
+const CronJobInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// Job names are used as label values, so they're limited to 63 characters.
+const maxJobNameLength = 63
+
+func (e K8sEntity) IsCronJob() bool {
+	return e.GVK().Kind == CronJobKind
+}
+
+// Tilt runs CronJobs on demand, so we don't want them firing on their own
+// schedule while Tilt manages them.
+func InjectCronJobSuspend(entity K8sEntity) (K8sEntity, error) {
+	suspend := true
+	switch obj := entity.Obj.(type) {
+	case *v1beta1.CronJob:
+		entity = entity.DeepCopy()
+		entity.Obj.(*v1beta1.CronJob).Spec.Suspend = &suspend
+	case *v2alpha1.CronJob:
+		entity = entity.DeepCopy()
+		entity.Obj.(*v2alpha1.CronJob).Spec.Suspend = &suspend
+	case *unstructured.Unstructured:
+		if obj.GetKind() != CronJobKind {
+			return entity, nil
+		}
+		entity = entity.DeepCopy()
+		err := unstructured.SetNestedField(entity.Obj.(*unstructured.Unstructured).Object, suspend, "spec", "suspend")
+		if err != nil {
+			return K8sEntity{}, errors.Wrapf(err, "CronJob %s: suspending", entity.Name())
+		}
+	}
+	return entity, nil
+}
+
+// Whether the CronJob's YAML suspends its schedule.
+//
+// Returns false if the entity isn't a CronJob.
+func (e K8sEntity) CronJobSuspended() (bool, bool) {
+	if !e.IsCronJob() {
+		return false, false
+	}
+	content, err := toUnstructuredContent(e)
+	if err != nil {
+		return false, false
+	}
+	suspend, _, err := unstructured.NestedBool(content, "spec", "suspend")
+	if err != nil {
+		return false, false
+	}
+	return suspend, true
+}
+
+// A JSON merge patch that suspends or resumes a CronJob's schedule.
+func CronJobSuspendPatch(suspend bool) []byte {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"suspend": suspend,
+		},
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Creates a Job from a CronJob's job template, like
+// `kubectl create job --from=cronjob/<name>`.
+//
+// The Job is owned by the CronJob, so that its pods show up under the same
+// resource, and Kubernetes deletes it along with the CronJob.
+//
+// The cronJob must come from the cluster, so that it has a UID.
+func JobFromCronJob(cronJob K8sEntity, suffix string) (K8sEntity, error) {
+	if !cronJob.IsCronJob() {
+		return K8sEntity{}, fmt.Errorf("%s %s is not a CronJob", cronJob.GVK().Kind, cronJob.Name())
+	}
+	if cronJob.UID() == "" {
+		return K8sEntity{}, fmt.Errorf("CronJob %s: missing UID", cronJob.Name())
+	}
+
+	content, err := toUnstructuredContent(cronJob)
+	if err != nil {
+		return K8sEntity{}, errors.Wrapf(err, "CronJob %s", cronJob.Name())
+	}
+	templateContent, ok, err := unstructured.NestedMap(content, "spec", "jobTemplate")
+	if err != nil || !ok {
+		return K8sEntity{}, fmt.Errorf("CronJob %s: missing spec.jobTemplate", cronJob.Name())
+	}
+
+	// The job template is the same across CronJob API versions.
+	var template v1beta1.JobTemplateSpec
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(templateContent, &template)
+	if err != nil {
+		return K8sEntity{}, errors.Wrapf(err, "CronJob %s: spec.jobTemplate", cronJob.Name())
+	}
+
+	name := cronJob.Name()
+	if len(name)+len(suffix)+1 > maxJobNameLength {
+		name = name[:maxJobNameLength-len(suffix)-1]
+	}
+
+	annotations := template.Annotations
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[CronJobInstantiateAnnotation] = "manual"
+
+	controller := true
+	apiVersion, kind := cronJob.GVK().ToAPIVersionAndKind()
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%s", name, suffix),
+			Namespace:   cronJob.Namespace().String(),
+			Labels:      template.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Name:       cronJob.Name(),
+				UID:        cronJob.UID(),
+				Controller: &controller,
+			}},
+		},
+		Spec: template.Spec,
+	}
+	return NewK8sEntity(job), nil
+}
+
+func toUnstructuredContent(e K8sEntity) (map[string]interface{}, error) {
+	if obj, ok := e.Obj.(*unstructured.Unstructured); ok {
+		return obj.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newUnstructuredCronJob(name string) K8sEntity {
+	return NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "jobs",
+			"uid":       "cron-uid",
+		},
+		"spec": map[string]interface{}{
+			"schedule": "*/5 * * * *",
+			"jobTemplate": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "report"},
+				},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"restartPolicy": "Never",
+						},
+					},
+				},
+			},
+		},
+	}})
+}
+
+func TestInjectCronJobSuspendUnstructured(t *testing.T) {
+	cronJob := newUnstructuredCronJob("report")
+	suspended, ok := cronJob.CronJobSuspended()
+	assert.True(t, ok)
+	assert.False(t, suspended)
+
+	injected, err := InjectCronJobSuspend(cronJob)
+	require.NoError(t, err)
+	suspended, ok = injected.CronJobSuspended()
+	assert.True(t, ok)
+	assert.True(t, suspended)
+
+	// The original is untouched.
+	suspended, _ = cronJob.CronJobSuspended()
+	assert.False(t, suspended)
+}
+
+func TestInjectCronJobSuspendTyped(t *testing.T) {
+	cronJob := NewK8sEntity(&v1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report"},
+	})
+
+	injected, err := InjectCronJobSuspend(cronJob)
+	require.NoError(t, err)
+	suspended, ok := injected.CronJobSuspended()
+	assert.True(t, ok)
+	assert.True(t, suspended)
+}
+
+func TestInjectCronJobSuspendIgnoresOtherKinds(t *testing.T) {
+	deployment := NewK8sEntity(&unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{},
+	}})
+
+	injected, err := InjectCronJobSuspend(deployment)
+	require.NoError(t, err)
+	_, found, _ := unstructured.NestedBool(injected.Obj.(*unstructured.Unstructured).Object, "spec", "suspend")
+	assert.False(t, found)
+
+	_, ok := deployment.CronJobSuspended()
+	assert.False(t, ok)
+}
+
+func TestCronJobSuspendPatch(t *testing.T) {
+	assert.Equal(t, `{"spec":{"suspend":false}}`, string(CronJobSuspendPatch(false)))
+}
+
+func TestJobFromCronJob(t *testing.T) {
+	job, err := JobFromCronJob(newUnstructuredCronJob("report"), "tilt-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Job", job.GVK().Kind)
+	assert.Equal(t, "report-tilt-1", job.Name())
+	assert.Equal(t, Namespace("jobs"), job.Namespace())
+	assert.Equal(t, map[string]string{"app": "report"}, job.Labels())
+	assert.Equal(t, "manual", job.Obj.(*batchv1.Job).Annotations[CronJobInstantiateAnnotation])
+
+	owners := job.OwnerReferences()
+	if assert.Len(t, owners, 1) {
+		assert.Equal(t, "CronJob", owners[0].Kind)
+		assert.Equal(t, "report", owners[0].Name)
+		assert.Equal(t, "cron-uid", string(owners[0].UID))
+	}
+
+	assert.Equal(t, v1.RestartPolicyNever, job.Obj.(*batchv1.Job).Spec.Template.Spec.RestartPolicy)
+}
+
+func TestJobFromCronJobLongName(t *testing.T) {
+	name := "a-cronjob-with-a-very-long-name-that-goes-on-and-on-and-on-and-on"
+	job, err := JobFromCronJob(newUnstructuredCronJob(name), "tilt-1600000000")
+	require.NoError(t, err)
+	assert.Len(t, job.Name(), maxJobNameLength)
+	assert.Contains(t, job.Name(), "-tilt-1600000000")
+}
+
+func TestJobFromCronJobNeedsUID(t *testing.T) {
+	cronJob := newUnstructuredCronJob("report")
+	cronJob.Obj.(*unstructured.Unstructured).SetUID("")
+	_, err := JobFromCronJob(cronJob, "tilt-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// API versions that Kubernetes has deprecated, mapped to the version that replaces them.
+//
+// Clusters keep serving these for a few releases after the deprecation,
+// so YAML that uses them deploys fine right up until the cluster upgrade that removes them.
+var deprecatedAPIs = map[schema.GroupVersionKind]string{
+	{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}: "apps/v1",
+	{Group: "extensions", Version: "v1beta1", Kind: "DaemonSet"}:  "apps/v1",
+	{Group: "extensions", Version: "v1beta1", Kind: "ReplicaSet"}: "apps/v1",
+	{Group: "apps", Version: "v1beta1", Kind: "Deployment"}:       "apps/v1",
+	{Group: "apps", Version: "v1beta1", Kind: "StatefulSet"}:      "apps/v1",
+	{Group: "apps", Version: "v1beta2", Kind: "Deployment"}:       "apps/v1",
+	{Group: "apps", Version: "v1beta2", Kind: "StatefulSet"}:      "apps/v1",
+	{Group: "apps", Version: "v1beta2", Kind: "DaemonSet"}:        "apps/v1",
+	{Group: "apps", Version: "v1beta2", Kind: "ReplicaSet"}:       "apps/v1",
+
+	{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}:           "networking.k8s.io/v1",
+	{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}:    "networking.k8s.io/v1",
+	{Group: "extensions", Version: "v1beta1", Kind: "NetworkPolicy"}:     "networking.k8s.io/v1",
+	{Group: "extensions", Version: "v1beta1", Kind: "PodSecurityPolicy"}: "policy/v1beta1",
+
+	{Group: "batch", Version: "v1beta1", Kind: "CronJob"}: "batch/v1",
+
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "Role"}:               "rbac.authorization.k8s.io/v1",
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "RoleBinding"}:        "rbac.authorization.k8s.io/v1",
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "ClusterRole"}:        "rbac.authorization.k8s.io/v1",
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "ClusterRoleBinding"}: "rbac.authorization.k8s.io/v1",
+
+	{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}: "apiextensions.k8s.io/v1",
+
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "MutatingWebhookConfiguration"}:   "admissionregistration.k8s.io/v1",
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingWebhookConfiguration"}: "admissionregistration.k8s.io/v1",
+
+	{Group: "scheduling.k8s.io", Version: "v1beta1", Kind: "PriorityClass"}: "scheduling.k8s.io/v1",
+	{Group: "storage.k8s.io", Version: "v1beta1", Kind: "StorageClass"}:     "storage.k8s.io/v1",
+}
+
+// If the entity uses a deprecated API version, returns a message telling the user what to use instead.
+func DeprecatedAPIMessage(e K8sEntity) (string, bool) {
+	gvk := e.GVK()
+	replacement, ok := deprecatedAPIs[gvk]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s %q uses deprecated API version %s. Use %s instead.",
+		gvk.Kind, e.Name(), gvk.GroupVersion().String(), replacement), true
+}
@@ -0,0 +1,31 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecatedAPIMessage(t *testing.T) {
+	entities, err := ParseYAMLFromString(`
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: frontend
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+`)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+
+	msg, ok := DeprecatedAPIMessage(entities[0])
+	assert.True(t, ok)
+	assert.Equal(t, `Ingress "frontend" uses deprecated API version extensions/v1beta1. Use networking.k8s.io/v1 instead.`, msg)
+
+	_, ok = DeprecatedAPIMessage(entities[1])
+	assert.False(t, ok)
+}
@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// An API version that Kubernetes stopped serving.
+//
+// Removing an API version is a breaking change, so they're announced well in advance:
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+type removedAPI struct {
+	apiVersion string
+	kind       string
+
+	// The minor version of Kubernetes 1.x that stopped serving it.
+	removedIn int
+
+	// What to use instead, if anything.
+	replacement string
+}
+
+var removedAPIs = []removedAPI{
+	{"extensions/v1beta1", "DaemonSet", 16, "apps/v1"},
+	{"extensions/v1beta1", "Deployment", 16, "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", 16, "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", 16, "policy/v1beta1"},
+	{"extensions/v1beta1", "ReplicaSet", 16, "apps/v1"},
+	{"apps/v1beta1", "Deployment", 16, "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", 16, "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", 16, "apps/v1"},
+	{"apps/v1beta2", "Deployment", 16, "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", 16, "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", 16, "apps/v1"},
+
+	{"extensions/v1beta1", "Ingress", 22, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", 22, "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", 22, "networking.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", 22, "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", 22, "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", 22, "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", 22, "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", 22, "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", 22, "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", 22, "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", 22, "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", 22, "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", 22, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", 22, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", 22, "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", 22, "storage.k8s.io/v1"},
+
+	{"batch/v1beta1", "CronJob", 25, "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", 25, "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", 25, "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", 25, "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", 25, "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", 25, ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", 25, "node.k8s.io/v1"},
+
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", 26, "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", 26, "flowcontrol.apiserver.k8s.io/v1beta3"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", 26, "flowcontrol.apiserver.k8s.io/v1beta3"},
+
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", 27, "storage.k8s.io/v1"},
+
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", 29, "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", 29, "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+var serverMinorVersionRe = regexp.MustCompile(`^v?1\.(\d+)`)
+
+// Parses the minor version out of a Kubernetes 1.x version, like "v1.22.3-gke.100".
+// Returns false if it's not a 1.x version.
+func serverMinorVersion(gitVersion string) (int, bool) {
+	match := serverMinorVersionRe.FindStringSubmatch(gitVersion)
+	if match == nil {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return minor, true
+}
+
+// If the entity uses an API version that the cluster doesn't serve anymore,
+// or will stop serving after the next minor upgrade, explains what to use instead.
+//
+// Returns "" if the API version is fine, or we don't know the server version.
+func removedAPIWarning(e K8sEntity, serverVersion string) string {
+	minor, ok := serverMinorVersion(serverVersion)
+	if !ok {
+		return ""
+	}
+
+	gvk := e.GVK()
+	apiVersion, kind := gvk.GroupVersion().String(), gvk.Kind
+	for _, api := range removedAPIs {
+		if api.apiVersion != apiVersion || api.kind != kind || minor+1 < api.removedIn {
+			continue
+		}
+
+		var msg string
+		if minor >= api.removedIn {
+			msg = fmt.Sprintf("%s %s is no longer served as of Kubernetes v1.%d, and the cluster is %s",
+				apiVersion, kind, api.removedIn, serverVersion)
+		} else {
+			msg = fmt.Sprintf("%s %s will stop being served in Kubernetes v1.%d, the next version after the cluster's (%s)",
+				apiVersion, kind, api.removedIn, serverVersion)
+		}
+		if api.replacement != "" {
+			msg += fmt.Sprintf("; use %s %s", api.replacement, kind)
+		}
+		return msg
+	}
+	return ""
+}
+
+// Extracts the warnings that the API server sent back (e.g., about deprecated
+// API versions) from kubectl's stderr.
+func parseKubectlWarnings(stderr string) []string {
+	var warnings []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Warning: ") {
+			warnings = append(warnings, strings.TrimPrefix(line, "Warning: "))
+		}
+	}
+	return warnings
+}
+
+type entitySourcesKey struct{}
+
+// Tells Upsert where the entities came from, so that it can point
+// warnings about them at the offending YAML. See ObjectSourceKey.
+func WithEntitySources(ctx context.Context, sources map[string]string) context.Context {
+	return context.WithValue(ctx, entitySourcesKey{}, sources)
+}
+
+// How we look up where an object came from in K8sTarget.ObjectSources.
+//
+// We deliberately leave out the namespace, because Tilt may fill it in
+// after the YAML has been read.
+func ObjectSourceKey(e K8sEntity) string {
+	return fmt.Sprintf("%s/%s", e.GVK().Kind, e.Name())
+}
+
+// Records where each entity came from on the target, e.g., "k8s/deploy.yaml:12".
+func WithObjectSources(target model.K8sTarget, entities []K8sEntity, sourceOf func(e K8sEntity) string) model.K8sTarget {
+	sources := make(map[string]string)
+	for _, e := range entities {
+		source := sourceOf(e)
+		if source != "" {
+			sources[ObjectSourceKey(e)] = source
+		}
+	}
+	if len(sources) > 0 {
+		target.ObjectSources = sources
+	}
+	return target
+}
+
+// Describes the entity for a warning, with where it came from if we know.
+func entityWarningPrefix(ctx context.Context, e K8sEntity) string {
+	name := fmt.Sprintf("%s %s", e.GVK().Kind, e.Name())
+	sources, _ := ctx.Value(entitySourcesKey{}).(map[string]string)
+	if source, ok := sources[ObjectSourceKey(e)]; ok {
+		return fmt.Sprintf("%s (%s)", name, source)
+	}
+	return name
+}
+
+// Logs the warnings for an entity we just applied.
+//
+// If we already warned that its API version is going away, skip the server's
+// warnings about the same API version, which would only repeat it.
+func logApplyWarnings(ctx context.Context, e K8sEntity, removedWarning string, serverWarnings []string) {
+	prefix := entityWarningPrefix(ctx, e)
+	l := logger.Get(ctx)
+	if removedWarning != "" {
+		l.Warnf("%s: %s", prefix, removedWarning)
+	}
+
+	apiVersion := e.GVK().GroupVersion().String()
+	for _, w := range serverWarnings {
+		if removedWarning != "" && strings.Contains(w, apiVersion) {
+			continue
+		}
+		l.Warnf("%s: %s", prefix, w)
+	}
+}
@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const betaCronJobYAML = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: busybox
+          restartPolicy: OnFailure
+`
+
+func TestRemovedAPIWarning(t *testing.T) {
+	cronJob := MustParseYAMLFromString(t, betaCronJobYAML)[0]
+
+	assert.Equal(t, "", removedAPIWarning(cronJob, ""))
+	assert.Equal(t, "", removedAPIWarning(cronJob, "v1.22.3"))
+	assert.Equal(t,
+		"batch/v1beta1 CronJob will stop being served in Kubernetes v1.25, the next version after the cluster's (v1.24.1); use batch/v1 CronJob",
+		removedAPIWarning(cronJob, "v1.24.1"))
+	assert.Equal(t,
+		"batch/v1beta1 CronJob is no longer served as of Kubernetes v1.25, and the cluster is v1.26.0-gke.100; use batch/v1 CronJob",
+		removedAPIWarning(cronJob, "v1.26.0-gke.100"))
+
+	deployment := MustParseYAMLFromString(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`)[0]
+	assert.Equal(t, "", removedAPIWarning(deployment, "v1.26.0"))
+}
+
+func TestParseKubectlWarnings(t *testing.T) {
+	stderr := "Warning: batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob\n" +
+		"some other output\n"
+	assert.Equal(t,
+		[]string{"batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob"},
+		parseKubectlWarnings(stderr))
+}
+
+func TestLogApplyWarningsSkipsRedundantServerWarnings(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx, _, _ := testutils.ForkedCtxAndAnalyticsForTest(out)
+	ctx = WithEntitySources(ctx, map[string]string{"CronJob/cleanup": "k8s/cron.yaml:3"})
+	cronJob := MustParseYAMLFromString(t, betaCronJobYAML)[0]
+
+	logApplyWarnings(ctx, cronJob, removedAPIWarning(cronJob, "v1.24.0"),
+		[]string{"batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob"})
+
+	assert.Contains(t, out.String(), "CronJob cleanup (k8s/cron.yaml:3): batch/v1beta1 CronJob will stop being served in Kubernetes v1.25")
+	assert.NotContains(t, out.String(), "is deprecated in v1.21+")
+}
+
+func TestLogApplyWarningsFromServer(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx, _, _ := testutils.ForkedCtxAndAnalyticsForTest(out)
+	cronJob := MustParseYAMLFromString(t, betaCronJobYAML)[0]
+
+	logApplyWarnings(ctx, cronJob, removedAPIWarning(cronJob, "v1.21.0"),
+		[]string{"batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+; use batch/v1 CronJob"})
+
+	assert.Contains(t, out.String(), "CronJob cleanup: batch/v1beta1 CronJob is deprecated in v1.21+")
+}
+
+func TestServerGitVersion(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	cs.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.24.0"}
+	assert.Equal(t, "v1.24.0", K8sClient{clientset: cs}.serverGitVersion())
+	assert.Equal(t, "", K8sClient{}.serverGitVersion())
+}
+
+func TestWithObjectSources(t *testing.T) {
+	entities := MustParseYAMLFromString(t, betaCronJobYAML)
+	target := WithObjectSources(model.K8sTarget{}, entities, func(e K8sEntity) string {
+		return "cron.yaml:1"
+	})
+	assert.Equal(t, map[string]string{"CronJob/cleanup": "cron.yaml:1"}, target.ObjectSources)
+
+	target = WithObjectSources(model.K8sTarget{}, entities, func(e K8sEntity) string { return "" })
+	assert.Nil(t, target.ObjectSources)
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Iterate through the pods of a k8s entity and shrink them to fit on a dev
+// cluster: scale or strip container resource requirements, and remove
+// pod anti-affinity rules.
+func InjectDevResourceProfile(entity K8sEntity, profile model.DevResourceProfile) (K8sEntity, error) {
+	if profile.Empty() {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+	if profile.StripResources || profile.Scale != 0 {
+		containers, err := extractContainers(&entity)
+		if err != nil {
+			return K8sEntity{}, err
+		}
+
+		for _, c := range containers {
+			if profile.StripResources {
+				c.Resources.Requests = nil
+				c.Resources.Limits = nil
+				continue
+			}
+			scaleResourceList(c.Resources.Requests, profile.Scale)
+			scaleResourceList(c.Resources.Limits, profile.Scale)
+		}
+	}
+
+	if profile.RemoveAntiAffinity {
+		pods, err := ExtractPods(&entity)
+		if err != nil {
+			return K8sEntity{}, err
+		}
+
+		for _, pod := range pods {
+			if pod.Affinity == nil {
+				continue
+			}
+			pod.Affinity.PodAntiAffinity = nil
+			if pod.Affinity.NodeAffinity == nil && pod.Affinity.PodAffinity == nil {
+				pod.Affinity = nil
+			}
+		}
+	}
+	return entity, nil
+}
+
+// Only scale the resources that can be fractional.
+// Extended resources (e.g., GPUs) must be whole numbers, so we leave them alone.
+func scaleResourceList(list v1.ResourceList, scale float64) {
+	for name, q := range list {
+		switch name {
+		case v1.ResourceCPU:
+			// Never scale a request down to 0, because a 0 request means "unset".
+			milli := int64(math.Max(1, math.Round(float64(q.MilliValue())*scale)))
+			list[name] = *resource.NewMilliQuantity(milli, q.Format)
+		case v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			value := int64(math.Max(1, math.Round(float64(q.Value())*scale)))
+			list[name] = *resource.NewQuantity(value, q.Format)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const devResourcesYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+          - labelSelector:
+              matchLabels:
+                app: web
+            topologyKey: kubernetes.io/hostname
+      containers:
+      - name: web
+        image: gcr.io/web
+        resources:
+          requests:
+            cpu: "2"
+            memory: 4Gi
+            nvidia.com/gpu: "1"
+          limits:
+            cpu: "4"
+            memory: 8Gi
+`
+
+func TestInjectDevResourceProfileScale(t *testing.T) {
+	deployment := injectDevResourceProfile(t, model.DevResourceProfile{Scale: 0.25})
+
+	resources := deployment.Spec.Template.Spec.Containers[0].Resources
+	assert.Equal(t, "500m", resources.Requests.Cpu().String())
+	assert.Equal(t, "1Gi", resources.Requests.Memory().String())
+	gpu := resources.Requests["nvidia.com/gpu"]
+	assert.Equal(t, "1", gpu.String())
+	assert.Equal(t, "1", resources.Limits.Cpu().String())
+	assert.Equal(t, "2Gi", resources.Limits.Memory().String())
+
+	// Affinity is untouched unless asked for.
+	assert.NotNil(t, deployment.Spec.Template.Spec.Affinity)
+}
+
+func TestInjectDevResourceProfileStrip(t *testing.T) {
+	deployment := injectDevResourceProfile(t, model.DevResourceProfile{StripResources: true, Scale: 0.5})
+
+	resources := deployment.Spec.Template.Spec.Containers[0].Resources
+	assert.Empty(t, resources.Requests)
+	assert.Empty(t, resources.Limits)
+}
+
+func TestInjectDevResourceProfileRemoveAntiAffinity(t *testing.T) {
+	deployment := injectDevResourceProfile(t, model.DevResourceProfile{RemoveAntiAffinity: true})
+
+	assert.Nil(t, deployment.Spec.Template.Spec.Affinity)
+	resources := deployment.Spec.Template.Spec.Containers[0].Resources
+	assert.Equal(t, "2", resources.Requests.Cpu().String())
+}
+
+func injectDevResourceProfile(t *testing.T, profile model.DevResourceProfile) *appsv1.Deployment {
+	entities, err := ParseYAMLFromString(devResourcesYAML)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+
+	e, err := InjectDevResourceProfile(entities[0], profile)
+	require.NoError(t, err)
+
+	deployment, ok := e.Obj.(*appsv1.Deployment)
+	require.True(t, ok)
+	return deployment
+}
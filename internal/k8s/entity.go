@@ -38,7 +38,10 @@ type k8sMeta interface {
 	GetLabels() map[string]string
 	GetOwnerReferences() []metav1.OwnerReference
 	GetAnnotations() map[string]string
+	GetFinalizers() []string
 	SetNamespace(ns string)
+	SetLabels(labels map[string]string)
+	SetAnnotations(annotations map[string]string)
 	SetManagedFields(managedFields []metav1.ManagedFieldsEntry)
 }
 
@@ -50,7 +53,10 @@ func (emptyMeta) GetUID() types.UID                               { return "" }
 func (emptyMeta) GetAnnotations() map[string]string               { return make(map[string]string) }
 func (emptyMeta) GetLabels() map[string]string                    { return make(map[string]string) }
 func (emptyMeta) GetOwnerReferences() []metav1.OwnerReference     { return nil }
+func (emptyMeta) GetFinalizers() []string                         { return nil }
 func (emptyMeta) SetNamespace(ns string)                          {}
+func (emptyMeta) SetLabels(labels map[string]string)              {}
+func (emptyMeta) SetAnnotations(annotations map[string]string)    {}
 func (emptyMeta) SetManagedFields(mf []metav1.ManagedFieldsEntry) {}
 
 var _ k8sMeta = emptyMeta{}
@@ -95,6 +101,34 @@ func (e K8sEntity) WithNamespace(ns string) K8sEntity {
 	return newE
 }
 
+// Built-in kinds that don't live in a namespace.
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CSIDriver":                      true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// Whether the entity lives outside of any namespace, e.g., a ClusterRole.
+//
+// We can't ask the cluster when we load the Tiltfile, so this only knows about
+// built-in kinds. Custom resources are assumed to be namespaced.
+func (e K8sEntity) IsClusterScoped() bool {
+	return clusterScopedKinds[e.GVK().Kind]
+}
+
 func (e K8sEntity) GVK() schema.GroupVersionKind {
 	gvk := e.Obj.GetObjectKind().GroupVersionKind()
 	if gvk.Empty() {
@@ -237,6 +271,18 @@ func (e K8sEntity) Labels() map[string]string {
 	return e.meta().GetLabels()
 }
 
+func (e K8sEntity) Annotations() map[string]string {
+	return e.meta().GetAnnotations()
+}
+
+func (e K8sEntity) OwnerReferences() []metav1.OwnerReference {
+	return e.meta().GetOwnerReferences()
+}
+
+func (e K8sEntity) Finalizers() []string {
+	return e.meta().GetFinalizers()
+}
+
 // Most entities can be updated once running, but a few cannot.
 func (e K8sEntity) ImmutableOnceCreated() bool {
 	return e.GVK().Kind == "Job" || e.GVK().Kind == "Pod"
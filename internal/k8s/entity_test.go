@@ -50,6 +50,17 @@ func TestNamespace(t *testing.T) {
 	assert.Equal(t, "kube-system", string(entities[0].Namespace()))
 }
 
+func TestIsClusterScoped(t *testing.T) {
+	entities, err := ParseYAMLFromString(fmt.Sprintf("%s\n---\n%s", testyaml.SanchoYAML, testyaml.MyNamespaceYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(entities))
+	assert.False(t, entities[0].IsClusterScoped())
+	assert.True(t, entities[1].IsClusterScoped())
+}
+
 func TestImmutableFilter(t *testing.T) {
 	yaml := fmt.Sprintf("%s\n---\n%s\n---\n%s", testyaml.JobYAML, testyaml.SanchoYAML, testyaml.PodYAML)
 	entities, err := ParseYAMLFromString(yaml)
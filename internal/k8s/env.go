@@ -24,6 +24,7 @@ const (
 	EnvDockerDesktop Env = "docker-for-desktop"
 	EnvMicroK8s      Env = "microk8s"
 	EnvCRC           Env = "crc"
+	EnvMicroShift    Env = "microshift"
 	EnvKrucible      Env = "krucible"
 
 	// Kind v0.6 substantially changed the protocol for detecting and pulling,
@@ -38,18 +39,37 @@ func (e Env) UsesLocalDockerRegistry() bool {
 	return e == EnvMinikube || e == EnvDockerDesktop || e == EnvMicroK8s
 }
 
+// Local clusters where we can copy images straight onto a CRI-O node
+// when there's no registry to push to.
+func (e Env) UsesCRIONodeLoad() bool {
+	return e == EnvCRC || e == EnvMicroShift
+}
+
 func (e Env) IsDevCluster() bool {
-	return e == EnvMinikube || e == EnvDockerDesktop || e == EnvMicroK8s || e == EnvCRC || e == EnvKIND5 || e == EnvKIND6 || e == EnvK3D || e == EnvKrucible
+	return e == EnvMinikube || e == EnvDockerDesktop || e == EnvMicroK8s || e == EnvCRC || e == EnvMicroShift || e == EnvKIND5 || e == EnvKIND6 || e == EnvK3D || e == EnvKrucible
+}
+
+// The kubeconfig, or the error we got loading it.
+//
+// Lots of Tiltfiles don't use Kubernetes at all, so a missing or broken
+// kubeconfig shouldn't stop Tilt from starting. Instead, we treat the cluster
+// as not configured, and only report the error when something needs the cluster.
+type APIConfigOrError struct {
+	Config *api.Config
+	Error  error
 }
 
-func ProvideKubeContext(config *api.Config) (KubeContext, error) {
-	return KubeContext(config.CurrentContext), nil
+func ProvideKubeContext(maybeConfig APIConfigOrError) (KubeContext, error) {
+	if maybeConfig.Error != nil {
+		return "", nil
+	}
+	return KubeContext(maybeConfig.Config.CurrentContext), nil
 }
 
-func ProvideKubeConfig(clientLoader clientcmd.ClientConfig, contextOverride KubeContextOverride) (*api.Config, error) {
+func ProvideKubeConfig(clientLoader clientcmd.ClientConfig, contextOverride KubeContextOverride) APIConfigOrError {
 	config, err := clientLoader.RawConfig()
 	if err != nil {
-		return nil, errors.Wrap(err, "Loading Kubernetes current-context")
+		return APIConfigOrError{Error: errors.Wrap(err, "Loading Kubernetes current-context")}
 	}
 
 	// NOTE(nick): The RawConfig() accessor doesn't handle overrides.
@@ -61,14 +81,18 @@ func ProvideKubeConfig(clientLoader clientcmd.ClientConfig, contextOverride Kube
 		// If the user explicitly passed an override, validate it.
 		err := clientcmd.ConfirmUsable(config, string(contextOverride))
 		if err != nil {
-			return nil, errors.Wrap(err, "Overriding Kubernetes context")
+			return APIConfigOrError{Error: errors.Wrap(err, "Overriding Kubernetes context")}
 		}
 	}
 
-	return &config, nil
+	return APIConfigOrError{Config: &config}
 }
 
-func ProvideClusterName(ctx context.Context, config *api.Config) ClusterName {
+func ProvideClusterName(ctx context.Context, maybeConfig APIConfigOrError) ClusterName {
+	if maybeConfig.Error != nil {
+		return ""
+	}
+	config := maybeConfig.Config
 	n := config.CurrentContext
 	c, ok := config.Contexts[n]
 	if !ok {
@@ -77,7 +101,11 @@ func ProvideClusterName(ctx context.Context, config *api.Config) ClusterName {
 	return ClusterName(c.Cluster)
 }
 
-func ProvideEnv(ctx context.Context, config *api.Config) Env {
+func ProvideEnv(ctx context.Context, maybeConfig APIConfigOrError) Env {
+	if maybeConfig.Error != nil {
+		return EnvNone
+	}
+	config := maybeConfig.Config
 	n := config.CurrentContext
 
 	c, ok := config.Contexts[n]
@@ -107,6 +135,8 @@ func ProvideEnv(ctx context.Context, config *api.Config) Env {
 		return EnvMicroK8s
 	} else if strings.HasPrefix(cn, "api-crc-testing") {
 		return EnvCRC
+	} else if strings.HasPrefix(cn, string(EnvMicroShift)) {
+		return EnvMicroShift
 	} else if strings.HasPrefix(cn, "krucible-") {
 		return EnvKrucible
 	}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"testing"
 
@@ -81,6 +82,11 @@ func TestProvideEnv(t *testing.T) {
 			Cluster: "api-crc-testing:6443",
 		},
 	}
+	microShiftContexts := map[string]*api.Context{
+		"microshift": &api.Context{
+			Cluster: "microshift",
+		},
+	}
 
 	homedir, err := homedir.Dir()
 	assert.NoError(t, err)
@@ -116,6 +122,7 @@ func TestProvideEnv(t *testing.T) {
 		{EnvMicroK8s, &api.Config{CurrentContext: "microk8s-dev-cluster-1", Contexts: microK8sPrefixContexts}},
 		{EnvCRC, &api.Config{CurrentContext: "api-crc-testing", Contexts: crcContexts}},
 		{EnvCRC, &api.Config{CurrentContext: "api-crc-testing:6443", Contexts: crcPrefixContexts}},
+		{EnvMicroShift, &api.Config{CurrentContext: "microshift", Contexts: microShiftContexts}},
 		{EnvKrucible, &api.Config{CurrentContext: "krucible-c-74701fe1a05596b3", Contexts: krucibleContexts}},
 		{EnvK3D, &api.Config{CurrentContext: "default", Contexts: k3dContexts}},
 		{EnvKIND5, &api.Config{CurrentContext: "default", Contexts: kind5NamedClusterContexts}},
@@ -124,10 +131,21 @@ func TestProvideEnv(t *testing.T) {
 
 	for _, tt := range table {
 		t.Run(tt.input.CurrentContext, func(t *testing.T) {
-			actual := ProvideEnv(context.Background(), tt.input)
+			actual := ProvideEnv(context.Background(), APIConfigOrError{Config: tt.input})
 			if actual != tt.expected {
 				t.Errorf("Expected %s, actual %s", tt.expected, actual)
 			}
 		})
 	}
 }
+
+func TestProvideEnvBrokenKubeConfig(t *testing.T) {
+	maybeConfig := APIConfigOrError{Error: fmt.Errorf("yaml: line 3: mapping values are not allowed")}
+
+	assert.Equal(t, EnvNone, ProvideEnv(context.Background(), maybeConfig))
+	assert.Equal(t, ClusterName(""), ProvideClusterName(context.Background(), maybeConfig))
+
+	kubeContext, err := ProvideKubeContext(maybeConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, KubeContext(""), kubeContext)
+}
@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ephemeralContainerStartTimeout = 30 * time.Second
+
+// Adds the ephemeral container to the pod, unless it's already there,
+// and waits for it to start.
+//
+// Ephemeral containers can't be removed or restarted, so if one with
+// this name has already exited, we return an error.
+func (k K8sClient) EnsureEphemeralContainer(ctx context.Context, pID PodID, n Namespace, c v1.EphemeralContainer) error {
+	podAPI := k.core.Pods(n.String())
+	ecs, err := podAPI.GetEphemeralContainers(ctx, pID.String(), metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting ephemeral containers of pod %s "+
+			"(ephemeral containers need the EphemeralContainers feature gate)", pID)
+	}
+
+	exists := false
+	for _, existing := range ecs.EphemeralContainers {
+		if existing.Name == c.Name {
+			exists = true
+			break
+		}
+	}
+
+	if !exists {
+		ecs.EphemeralContainers = append(ecs.EphemeralContainers, c)
+		_, err = podAPI.UpdateEphemeralContainers(ctx, pID.String(), ecs, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "adding ephemeral container %s to pod %s", c.Name, pID)
+		}
+	}
+
+	return k.waitForEphemeralContainer(ctx, pID, n, c.Name)
+}
+
+func (k K8sClient) waitForEphemeralContainer(ctx context.Context, pID PodID, n Namespace, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, ephemeralContainerStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		pod, err := k.core.Pods(n.String()).Get(ctx, pID.String(), metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "waiting for ephemeral container %s", name)
+		}
+
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != name {
+				continue
+			}
+			if status.State.Running != nil {
+				return nil
+			}
+			if terminated := status.State.Terminated; terminated != nil {
+				return fmt.Errorf("ephemeral container %s in pod %s exited (%s). "+
+					"Ephemeral containers can't be restarted; delete the pod to get a new one",
+					name, pID, terminated.Reason)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for ephemeral container %s in pod %s to start",
+				ephemeralContainerStartTimeout, name, pID)
+		case <-ticker.C:
+		}
+	}
+}
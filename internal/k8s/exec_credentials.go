@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// We set this on the environment of exec credential plugins.
+//
+// client-go caches plugin credentials by the plugin's config. Adding the
+// context name to the config means that each kubeconfig context gets its own
+// cached token, even if two contexts run the same plugin command.
+const ExecCredentialsContextEnv = "TILT_KUBE_CONTEXT"
+
+// Tracks the exec credential plugin (aws-iam-authenticator, gcloud, kubelogin, etc)
+// that the current kubeconfig context authenticates with.
+//
+// Plugins often need a terminal, e.g., to prompt for an MFA code. Once the HUD
+// starts, it owns the terminal. So we fetch credentials once at startup with
+// Warm(), while the plugin can still prompt, and client-go caches them
+// until they expire.
+//
+// When the cluster starts rejecting our credentials, we record it here, so that
+// we can tell the user how to log in again once, rather than logging every 401.
+//
+// A nil *ExecCredentials means the context doesn't use an exec plugin.
+type ExecCredentials struct {
+	kubeContext KubeContext
+	command     string
+	config      *rest.Config
+
+	mu       sync.Mutex
+	rejected bool
+}
+
+func newExecCredentials(kubeContext KubeContext, config *rest.Config) *ExecCredentials {
+	if config == nil || config.ExecProvider == nil {
+		return nil
+	}
+
+	// Copy the exec config, because client-go shares it with the loaded kubeconfig.
+	exec := *config.ExecProvider
+	exec.Env = append([]clientcmdapi.ExecEnvVar{}, exec.Env...)
+	if kubeContext != "" {
+		exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{
+			Name:  ExecCredentialsContextEnv,
+			Value: string(kubeContext),
+		})
+	}
+	config.ExecProvider = &exec
+
+	c := &ExecCredentials{
+		kubeContext: kubeContext,
+		command:     strings.Join(append([]string{exec.Command}, exec.Args...), " "),
+		config:      config,
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return execCredentialsRoundTripper{creds: c, base: rt}
+	})
+	return c
+}
+
+func ProvideExecCredentials(maybeRESTConfig RESTConfigOrError) *ExecCredentials {
+	return maybeRESTConfig.ExecCredentials
+}
+
+// The plugin command line, e.g., "aws eks get-token --cluster-name my-cluster"
+func (c *ExecCredentials) Command() string {
+	if c == nil {
+		return ""
+	}
+	return c.command
+}
+
+func (c *ExecCredentials) KubeContext() KubeContext {
+	if c == nil {
+		return ""
+	}
+	return c.kubeContext
+}
+
+// A command the user can run in their own terminal to log in again.
+//
+// Most plugins keep their own credential cache (e.g., ~/.kube/cache or the aws sso cache),
+// so once the user has logged in interactively, Tilt's next (non-interactive)
+// run of the plugin picks up the new credentials.
+func (c *ExecCredentials) ReauthCommand() string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("kubectl --context %s get namespaces", c.kubeContext)
+}
+
+// Fetch credentials from the plugin now, while it can still prompt on the terminal.
+//
+// Must be called before the HUD takes over the terminal.
+func (c *ExecCredentials) Warm(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(c.config)
+	if err != nil {
+		return errors.Wrap(err, "exec credential plugin")
+	}
+	_, err = clientset.Discovery().ServerVersion()
+	if err != nil {
+		return errors.Wrapf(err, "fetching credentials with %q", c.command)
+	}
+	return nil
+}
+
+// Whether the cluster rejected the last request we made.
+func (c *ExecCredentials) Rejected() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rejected
+}
+
+func (c *ExecCredentials) observe(statusCode int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		c.rejected = true
+	case statusCode < 400:
+		c.rejected = false
+	}
+}
+
+type execCredentialsRoundTripper struct {
+	creds *ExecCredentials
+	base  http.RoundTripper
+}
+
+func (rt execCredentialsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	rt.creds.observe(res.StatusCode)
+	return res, nil
+}
+
+// Whether a client-go log line is just noise about rejected credentials.
+//
+// Once we've told the user to log in again, every watch and list
+// failing with the same 401 doesn't tell them anything new.
+func (c *ExecCredentials) IsRejectionLog(line string) bool {
+	return c.Rejected() && strings.Contains(line, "Unauthorized")
+}
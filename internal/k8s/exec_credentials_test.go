@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestExecCredentialsNoPlugin(t *testing.T) {
+	creds := newExecCredentials("minikube", &rest.Config{Host: "https://localhost"})
+	assert.Nil(t, creds)
+	assert.False(t, creds.Rejected())
+	assert.NoError(t, creds.Warm(nil))
+}
+
+func TestExecCredentialsContextEnv(t *testing.T) {
+	exec := &clientcmdapi.ExecConfig{
+		Command:    "aws",
+		Args:       []string{"eks", "get-token"},
+		APIVersion: "client.authentication.k8s.io/v1beta1",
+		Env:        []clientcmdapi.ExecEnvVar{{Name: "AWS_PROFILE", Value: "dev"}},
+	}
+	config := &rest.Config{Host: "https://localhost", ExecProvider: exec}
+	creds := newExecCredentials("eks-dev", config)
+
+	assert.Equal(t, "aws eks get-token", creds.Command())
+	assert.Equal(t, "kubectl --context eks-dev get namespaces", creds.ReauthCommand())
+	assert.Equal(t, []clientcmdapi.ExecEnvVar{
+		{Name: "AWS_PROFILE", Value: "dev"},
+		{Name: ExecCredentialsContextEnv, Value: "eks-dev"},
+	}, config.ExecProvider.Env)
+
+	// Make sure we didn't modify the exec config from the kubeconfig.
+	assert.Equal(t, 1, len(exec.Env))
+}
+
+func TestExecCredentialsRejected(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	config.ExecProvider = &clientcmdapi.ExecConfig{Command: "true"}
+	creds := newExecCredentials("eks-dev", config)
+
+	// Exercise the wrapped transport directly, so we don't run the plugin.
+	rt := config.WrapTransport(http.DefaultTransport)
+	get := func() {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		require.NoError(t, err)
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	get()
+	assert.True(t, creds.Rejected())
+	assert.True(t, creds.IsRejectionLog(`reflector.go:178: Failed to list *v1.Pod: Unauthorized`))
+	assert.False(t, creds.IsRejectionLog(`reflector.go:178: watch of *v1.Pod ended`))
+
+	status = http.StatusOK
+	get()
+	assert.False(t, creds.Rejected())
+	assert.False(t, creds.IsRejectionLog(`reflector.go:178: Failed to list *v1.Pod: Unauthorized`))
+}
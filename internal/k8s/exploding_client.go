@@ -8,6 +8,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 
@@ -17,60 +18,70 @@ import (
 
 var _ Client = &explodingClient{}
 
+const ClusterNotConfiguredMsg = "Kubernetes cluster not configured"
+
+// A client for when we couldn't set up a connection to the cluster.
+//
+// We don't fail at startup, because the Tiltfile might not need the cluster.
+// Instead, every call that needs the cluster returns the setup error.
 type explodingClient struct {
 	err error
 }
 
+func (ec *explodingClient) clusterErr() error {
+	return model.ClusterConnError(errors.Wrap(ec.err, ClusterNotConfiguredMsg))
+}
+
 func (ec *explodingClient) Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) Delete(ctx context.Context, entities []K8sEntity) error {
-	return errors.Wrap(ec.err, "could not set up k8s client")
+	return ec.clusterErr()
 }
 
 func (ec *explodingClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error) {
-	return K8sEntity{}, errors.Wrap(ec.err, "could not set up k8s client")
+	return K8sEntity{}, ec.clusterErr()
 }
 
 func (ec *explodingClient) PodsWithImage(ctx context.Context, image reference.NamedTagged, n Namespace, lp []model.LabelPair) ([]v1.Pod, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) PollForPodsWithImage(ctx context.Context, image reference.NamedTagged, n Namespace, lp []model.LabelPair, timeout time.Duration) ([]v1.Pod, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) PodByID(ctx context.Context, podID PodID, n Namespace) (*v1.Pod, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) WatchPod(ctx context.Context, pod *v1.Pod) (watch.Interface, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) ContainerLogs(ctx context.Context, podID PodID, cName container.Name, n Namespace, startTime time.Time) (io.ReadCloser, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) CreatePortForwarder(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int, host string) (PortForwarder, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) WatchPods(ctx context.Context, ns Namespace, lps labels.Selector) (<-chan ObjectUpdate, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) WatchServices(ctx context.Context, ns Namespace, lps labels.Selector) (<-chan *v1.Service, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) WatchEvents(ctx context.Context, ns Namespace) (<-chan *v1.Event, error) {
-	return nil, errors.Wrap(ec.err, "could not set up k8s client")
+	return nil, ec.clusterErr()
 }
 
 func (ec *explodingClient) ConnectedToCluster(ctx context.Context) error {
-	return errors.Wrap(ec.err, "could not set up k8s client")
+	return ec.clusterErr()
 }
 
 func (ec *explodingClient) ContainerRuntime(ctx context.Context) container.Runtime {
@@ -86,5 +97,33 @@ func (ec *explodingClient) NodeIP(ctx context.Context) NodeIP {
 }
 
 func (ec *explodingClient) Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
-	return errors.Wrap(ec.err, "could not set up k8s client")
+	return ec.clusterErr()
+}
+
+func (ec *explodingClient) DeleteWithPropagation(ctx context.Context, entity K8sEntity, policy metav1.DeletionPropagation) (v1.ObjectReference, error) {
+	return v1.ObjectReference{}, ec.clusterErr()
+}
+
+func (ec *explodingClient) MergePatch(ctx context.Context, ref v1.ObjectReference, patch []byte) error {
+	return ec.clusterErr()
+}
+
+func (ec *explodingClient) ListBySelector(ctx context.Context, selector labels.Selector) ([]K8sEntity, error) {
+	return nil, ec.clusterErr()
+}
+
+func (ec *explodingClient) ListPersistentVolumeClaims(ctx context.Context, ns Namespace, selector labels.Selector) ([]v1.PersistentVolumeClaim, error) {
+	return nil, ec.clusterErr()
+}
+
+func (ec *explodingClient) ListAutoscalers(ctx context.Context, ns Namespace) ([]K8sEntity, error) {
+	return nil, ec.clusterErr()
+}
+
+func (ec *explodingClient) WatchEndpoints(ctx context.Context, ns Namespace, lps labels.Selector) (<-chan *v1.Endpoints, error) {
+	return nil, ec.clusterErr()
+}
+
+func (ec *explodingClient) EnsureEphemeralContainer(ctx context.Context, pID PodID, n Namespace, c v1.EphemeralContainer) error {
+	return ec.clusterErr()
 }
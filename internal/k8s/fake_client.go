@@ -13,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 
@@ -50,9 +51,10 @@ type FakeK8sClient struct {
 	LastPodLogStartTime      time.Time
 	ContainerLogsError       error
 
-	podWatches     []fakePodWatch
-	serviceWatches []fakeServiceWatch
-	eventWatches   []fakeEventWatch
+	podWatches       []fakePodWatch
+	serviceWatches   []fakeServiceWatch
+	endpointsWatches []fakeEndpointsWatch
+	eventWatches     []fakeEventWatch
 
 	EventsWatchErr error
 
@@ -69,6 +71,42 @@ type FakeK8sClient struct {
 
 	ExecCalls  []ExecCall
 	ExecErrors []error
+
+	// Written to the stdout of successive Exec calls.
+	ExecOutputs []string
+
+	DeleteWithPropagationCalls []DeleteWithPropagationCall
+
+	MergePatchCalls []MergePatchCall
+	MergePatchError error
+
+	// Entities returned by ListBySelector, filtered by their labels.
+	ListedEntities []K8sEntity
+
+	// Returned by ListPersistentVolumeClaims, filtered by namespace and labels.
+	PersistentVolumeClaims []v1.PersistentVolumeClaim
+
+	// Returned by ListAutoscalers, filtered by namespace.
+	Autoscalers []K8sEntity
+
+	EphemeralContainerCalls []EphemeralContainerCall
+	EphemeralContainerError error
+}
+
+type EphemeralContainerCall struct {
+	PID       PodID
+	Ns        Namespace
+	Container v1.EphemeralContainer
+}
+
+type DeleteWithPropagationCall struct {
+	Ref    v1.ObjectReference
+	Policy metav1.DeletionPropagation
+}
+
+type MergePatchCall struct {
+	Ref   v1.ObjectReference
+	Patch []byte
 }
 
 type ExecCall struct {
@@ -85,6 +123,12 @@ type fakeServiceWatch struct {
 	ch chan *v1.Service
 }
 
+type fakeEndpointsWatch struct {
+	ns Namespace
+	ls labels.Selector
+	ch chan *v1.Endpoints
+}
+
 type fakePodWatch struct {
 	ns Namespace
 	ls labels.Selector
@@ -134,6 +178,50 @@ func (c *FakeK8sClient) WatchServices(ctx context.Context, ns Namespace, ls labe
 	return ch, nil
 }
 
+func (c *FakeK8sClient) EmitEndpoints(ls labels.Selector, e *v1.Endpoints) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.endpointsWatches {
+		if !SelectorEqual(ls, w.ls) {
+			continue
+		}
+
+		if w.ns != "" && w.ns != Namespace(e.Namespace) {
+			continue
+		}
+
+		w.ch <- e
+	}
+}
+
+func (c *FakeK8sClient) EndpointsWatchCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.endpointsWatches)
+}
+
+func (c *FakeK8sClient) WatchEndpoints(ctx context.Context, ns Namespace, ls labels.Selector) (<-chan *v1.Endpoints, error) {
+	c.mu.Lock()
+	ch := make(chan *v1.Endpoints, 20)
+	c.endpointsWatches = append(c.endpointsWatches, fakeEndpointsWatch{ns, ls, ch})
+	c.mu.Unlock()
+
+	go func() {
+		// when ctx is canceled, remove the label selector from the list of watched label selectors
+		<-ctx.Done()
+		c.mu.Lock()
+		var newWatches []fakeEndpointsWatch
+		for _, e := range c.endpointsWatches {
+			if e.ns != ns || !SelectorEqual(e.ls, ls) {
+				newWatches = append(newWatches, e)
+			}
+		}
+		c.endpointsWatches = newWatches
+		c.mu.Unlock()
+	}()
+	return ch, nil
+}
+
 func (c *FakeK8sClient) WatchEvents(ctx context.Context, ns Namespace) (<-chan *v1.Event, error) {
 	if c.EventsWatchErr != nil {
 		err := c.EventsWatchErr
@@ -432,6 +520,17 @@ func (c *FakeK8sClient) Exec(ctx context.Context, podID PodID, cName container.N
 		Stdin: stdinBytes,
 	})
 
+	if len(c.ExecOutputs) > 0 {
+		out := c.ExecOutputs[0]
+		c.ExecOutputs = c.ExecOutputs[1:]
+		if stdout != nil {
+			_, err = stdout.Write([]byte(out))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if len(c.ExecErrors) > 0 {
 		err = c.ExecErrors[0]
 		c.ExecErrors = c.ExecErrors[1:]
@@ -440,6 +539,95 @@ func (c *FakeK8sClient) Exec(ctx context.Context, podID PodID, cName container.N
 	return nil
 }
 
+// Records the deleted entity in DeletedYaml. Entities injected with
+// InjectEntityByName stay around if they have finalizers, until
+// their finalizers are patched away.
+func (c *FakeK8sClient) DeleteWithPropagation(ctx context.Context, entity K8sEntity, policy metav1.DeletionPropagation) (v1.ObjectReference, error) {
+	if c.DeleteError != nil {
+		err := c.DeleteError
+		c.DeleteError = nil
+		return v1.ObjectReference{}, err
+	}
+
+	yaml, err := SerializeSpecYAML([]K8sEntity{entity})
+	if err != nil {
+		return v1.ObjectReference{}, errors.Wrap(err, "delete")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ref := entity.ToObjectReference()
+	c.DeleteWithPropagationCalls = append(c.DeleteWithPropagationCalls, DeleteWithPropagationCall{Ref: ref, Policy: policy})
+	c.DeletedYaml += yaml
+	if e, ok := c.entityByName[ref.Name]; ok && len(e.Finalizers()) == 0 {
+		delete(c.entityByName, ref.Name)
+	}
+	return ref, nil
+}
+
+func (c *FakeK8sClient) MergePatch(ctx context.Context, ref v1.ObjectReference, patch []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.MergePatchCalls = append(c.MergePatchCalls, MergePatchCall{Ref: ref, Patch: patch})
+	if c.MergePatchError == nil && string(patch) == string(RemoveFinalizersPatch()) {
+		delete(c.entityByName, ref.Name)
+	}
+	return c.MergePatchError
+}
+
+func (c *FakeK8sClient) ListBySelector(ctx context.Context, selector labels.Selector) ([]K8sEntity, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := []K8sEntity{}
+	for _, e := range c.ListedEntities {
+		if selector.Matches(labels.Set(e.Labels())) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) ListPersistentVolumeClaims(ctx context.Context, ns Namespace, selector labels.Selector) ([]v1.PersistentVolumeClaim, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := []v1.PersistentVolumeClaim{}
+	for _, pvc := range c.PersistentVolumeClaims {
+		if Namespace(pvc.Namespace) == ns && selector.Matches(labels.Set(pvc.Labels)) {
+			result = append(result, pvc)
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) ListAutoscalers(ctx context.Context, ns Namespace) ([]K8sEntity, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := []K8sEntity{}
+	for _, e := range c.Autoscalers {
+		if e.Namespace() == ns {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) EnsureEphemeralContainer(ctx context.Context, pID PodID, n Namespace, ec v1.EphemeralContainer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.EphemeralContainerCalls = append(c.EphemeralContainerCalls, EphemeralContainerCall{
+		PID:       pID,
+		Ns:        n,
+		Container: ec,
+	})
+	return c.EphemeralContainerError
+}
+
 type BufferCloser struct {
 	*bytes.Buffer
 }
@@ -470,6 +658,8 @@ func (pf FakePortForwarder) ForwardPorts() error {
 }
 
 type FakePortForwardClient struct {
+	mu sync.Mutex
+
 	CreatePortForwardCallCount int
 	LastForwardPortPodID       PodID
 	LastForwardPortRemotePort  int
@@ -479,6 +669,9 @@ type FakePortForwardClient struct {
 }
 
 func (c *FakePortForwardClient) CreatePortForwarder(ctx context.Context, namespace Namespace, podID PodID, optionalLocalPort, remotePort int, host string) (PortForwarder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.CreatePortForwardCallCount++
 	c.LastForwardContext = ctx
 	c.LastForwardPortPodID = podID
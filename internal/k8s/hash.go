@@ -0,0 +1,34 @@
+package k8s
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// A hash of the fully-rendered entities we're about to apply.
+//
+// If it matches the hash of the last apply, re-applying them can't change
+// anything in the cluster, except to bump resourceVersions and emit events.
+//
+// The session heartbeat doesn't count, because it changes on every apply.
+func HashEntities(entities []K8sEntity, keys SessionKeys) (string, error) {
+	h := sha256.New()
+	for _, e := range entities {
+		if _, ok := e.Annotations()[keys.HeartbeatAnnotation]; ok {
+			e = e.DeepCopy()
+			a := e.meta().GetAnnotations()
+			delete(a, keys.HeartbeatAnnotation)
+			e.meta().SetAnnotations(a)
+		}
+
+		b, err := json.Marshal(e.Obj)
+		if err != nil {
+			return "", fmt.Errorf("hashing %s: %v", e.Name(), err)
+		}
+		_, _ = h.Write(b)
+		_, _ = h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
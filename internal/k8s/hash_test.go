@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestHashEntities(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	same, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+
+	hash, err := HashEntities(entities, DefaultSessionKeys)
+	require.NoError(t, err)
+	sameHash, err := HashEntities(same, DefaultSessionKeys)
+	require.NoError(t, err)
+	assert.Equal(t, hash, sameHash)
+
+	// A new heartbeat doesn't change anything.
+	stamped := InjectSession(same[0], DefaultSessionKeys, "session", time.Unix(1, 0))
+	restamped := InjectSession(same[0], DefaultSessionKeys, "session", time.Unix(2, 0))
+	stampedHash, err := HashEntities([]K8sEntity{stamped}, DefaultSessionKeys)
+	require.NoError(t, err)
+	restampedHash, err := HashEntities([]K8sEntity{restamped}, DefaultSessionKeys)
+	require.NoError(t, err)
+	assert.Equal(t, stampedHash, restampedHash)
+	assert.Contains(t, restamped.Annotations(), HeartbeatAnnotation)
+
+	other, err := ParseYAMLFromString(testyaml.SanchoSidecarYAML)
+	require.NoError(t, err)
+	otherHash, err := HashEntities(other, DefaultSessionKeys)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+}
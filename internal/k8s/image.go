@@ -35,6 +35,11 @@ func InjectImagePullPolicy(entity K8sEntity, policy v1.PullPolicy) (K8sEntity, e
 //   When working with a local k8s cluster, we want to set this to Never,
 //   to ensure that k8s fails hard if the image is missing from docker.
 //
+// If injectRef has both a tag and a digest, containers get both, so that
+// the kubelet pulls exactly that image. Env vars and custom image locators
+// only get the tag, because the controllers that read them often expect
+// name:tag and choke on digests.
+//
 // Returns: the new entity, whether the image was replaced, and an error.
 func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectRef reference.Named, locators []ImageLocator, matchInEnvVars bool, policy v1.PullPolicy) (K8sEntity, bool, error) {
 	entity = entity.DeepCopy()
@@ -64,8 +69,9 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 		replaced = true
 	}
 
+	tagRef := tagOnlyRef(injectRef)
 	if matchInEnvVars {
-		entity, r, err = injectImageDigestInEnvVars(entity, selector, injectRef)
+		entity, r, err = injectImageDigestInEnvVars(entity, selector, tagRef)
 		if err != nil {
 			return K8sEntity{}, false, err
 		}
@@ -75,7 +81,7 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 	}
 
 	for _, locator := range locators {
-		entity, r, err = locator.Inject(entity, selector, injectRef)
+		entity, r, err = locator.Inject(entity, selector, tagRef)
 		if err != nil {
 			return K8sEntity{}, false, err
 		}
@@ -87,6 +93,21 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 	return entity, replaced, nil
 }
 
+// Drops the digest from a ref that also has a tag.
+func tagOnlyRef(ref reference.Named) reference.Named {
+	tagged, hasTag := ref.(reference.NamedTagged)
+	_, hasDigest := ref.(reference.Digested)
+	if !hasTag || !hasDigest {
+		return ref
+	}
+
+	result, err := reference.WithTag(reference.TrimNamed(ref), tagged.Tag())
+	if err != nil {
+		return ref
+	}
+	return result
+}
+
 func injectImageDigestInContainers(entity K8sEntity, selector container.RefSelector, injectRef reference.Named, policy v1.PullPolicy) (K8sEntity, bool, error) {
 	containers, err := extractContainers(&entity)
 	if err != nil {
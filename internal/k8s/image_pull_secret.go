@@ -0,0 +1,76 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Creates a kubernetes.io/dockerconfigjson Secret that lets the cluster pull
+// from a private registry, the same as `kubectl create secret docker-registry`.
+//
+// If the namespace is empty, the Secret goes in the namespace that kubectl defaults to.
+func NewImagePullSecret(name string, namespace string, host string, username string, password string) (K8sEntity, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	config, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			host: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	return NewK8sEntity(&v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: config,
+		},
+	}), nil
+}
+
+// Adds the pull secret to the imagePullSecrets of the entity's pods
+// (or of the ServiceAccount, if the entity is one).
+func InjectImagePullSecret(entity K8sEntity, name string) (K8sEntity, error) {
+	if name == "" {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+	if sa, ok := entity.Obj.(*v1.ServiceAccount); ok {
+		sa.ImagePullSecrets = appendImagePullSecret(sa.ImagePullSecrets, name)
+		return entity, nil
+	}
+
+	pods, err := ExtractPods(&entity)
+	if err != nil {
+		return K8sEntity{}, err
+	}
+	for _, pod := range pods {
+		pod.ImagePullSecrets = appendImagePullSecret(pod.ImagePullSecrets, name)
+	}
+	return entity, nil
+}
+
+func appendImagePullSecret(refs []v1.LocalObjectReference, name string) []v1.LocalObjectReference {
+	for _, ref := range refs {
+		if ref.Name == name {
+			return refs
+		}
+	}
+	return append(refs, v1.LocalObjectReference{Name: name})
+}
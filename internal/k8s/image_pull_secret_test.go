@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const imagePullSecretYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      imagePullSecrets:
+      - name: team-secret
+      containers:
+      - name: web
+        image: gcr.io/web
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+`
+
+func TestNewImagePullSecret(t *testing.T) {
+	e, err := NewImagePullSecret("tilt-registry", "tenant-a", "gcr.io", "oauth2accesstoken", "s3cret")
+	require.NoError(t, err)
+
+	secret, ok := e.Obj.(*v1.Secret)
+	require.True(t, ok)
+	assert.Equal(t, "tilt-registry", secret.Name)
+	assert.Equal(t, "tenant-a", secret.Namespace)
+	assert.Equal(t, v1.SecretTypeDockerConfigJson, secret.Type)
+
+	var config map[string]map[string]map[string]string
+	err = json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"username": "oauth2accesstoken",
+		"password": "s3cret",
+		"auth":     "b2F1dGgyYWNjZXNzdG9rZW46czNjcmV0",
+	}, config["auths"]["gcr.io"])
+}
+
+func TestInjectImagePullSecret(t *testing.T) {
+	entities, err := ParseYAMLFromString(imagePullSecretYAML)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+
+	e, err := InjectImagePullSecret(entities[0], "tilt-registry")
+	require.NoError(t, err)
+	deployment := e.Obj.(*appsv1.Deployment)
+	assert.Equal(t, []v1.LocalObjectReference{{Name: "team-secret"}, {Name: "tilt-registry"}},
+		deployment.Spec.Template.Spec.ImagePullSecrets)
+
+	// Injecting twice doesn't add it twice.
+	e, err = InjectImagePullSecret(e, "tilt-registry")
+	require.NoError(t, err)
+	assert.Len(t, e.Obj.(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets, 2)
+
+	e, err = InjectImagePullSecret(entities[1], "tilt-registry")
+	require.NoError(t, err)
+	assert.Equal(t, []v1.LocalObjectReference{{Name: "tilt-registry"}},
+		e.Obj.(*v1.ServiceAccount).ImagePullSecrets)
+
+	// The original is untouched.
+	assert.Empty(t, entities[1].Obj.(*v1.ServiceAccount).ImagePullSecrets)
+}
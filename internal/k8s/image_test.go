@@ -188,6 +188,29 @@ func TestInjectDigestBlorgBackendYAML(t *testing.T) {
 	}
 }
 
+func TestInjectPinnedDigestOnlyInContainers(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoImageInEnvYAML)
+	require.NoError(t, err)
+
+	name := "gcr.io/some-project-162817/sancho"
+	tagged := container.MustParseNamedTagged(name + ":tilt-deadbeef")
+	pinned, err := reference.WithDigest(tagged, digest.Digest("sha256:2baf1f40105d9501fe319a8ec463fdf4325a2a5df445adf3f572f626253678c9"))
+	require.NoError(t, err)
+
+	newEntity, replaced, err := InjectImageDigest(entities[0], container.NameSelector(tagged), pinned, nil, true, v1.PullIfNotPresent)
+	require.NoError(t, err)
+	assert.True(t, replaced)
+
+	containers, err := extractContainers(&newEntity)
+	require.NoError(t, err)
+	assert.Equal(t, pinned.String(), containers[0].Image)
+
+	envVars, err := extractEnvVars(&newEntity)
+	require.NoError(t, err)
+	assert.Equal(t, name+"2", envVars[0].Value)
+	assert.Equal(t, tagged.String(), envVars[1].Value)
+}
+
 // the same as InjectImageDigestInjectRefWithStrings, but with original == inject (the normal case with no default_registry)
 func InjectImageDigestWithStrings(entity K8sEntity, original string, newDigest string, locators []ImageLocator, policy v1.PullPolicy) (K8sEntity, bool, error) {
 	return InjectImageDigestInjectRefWithStrings(entity, original, original, newDigest, locators, policy)
@@ -34,6 +34,28 @@ func OverwriteLabels(entity K8sEntity, labels []model.LabelPair) (K8sEntity, err
 	return injectLabels(entity, labels, true)
 }
 
+// Adds the annotations to the top-level object.
+//
+// Unlike labels, we don't copy annotations into pod templates, because
+// tools often read annotations on pods as instructions (e.g., sidecar injection).
+func InjectAnnotations(entity K8sEntity, annotations []model.LabelPair) K8sEntity {
+	if len(annotations) == 0 {
+		return entity
+	}
+
+	entity = entity.DeepCopy()
+	meta := entity.meta()
+	a := meta.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	for _, pair := range annotations {
+		a[pair.Key] = pair.Value
+	}
+	meta.SetAnnotations(a)
+	return entity
+}
+
 // labels: labels to be added to `dest`
 // overwrite: if true, merge `labels` into `dest`. if false, replace `dest` with `labels`
 // addNew: if true, add all `labels` to `dest`. if false, only add `labels` whose keys are already in `dest`
@@ -274,6 +274,19 @@ func TestInjectService(t *testing.T) {
 	})
 }
 
+func TestInjectAnnotationsTopLevelOnly(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoYAML)
+	newEntity := InjectAnnotations(entity, []model.LabelPair{{Key: "example.com/owner", Value: "team-a"}})
+
+	d, ok := newEntity.Obj.(*appsv1.Deployment)
+	require.True(t, ok)
+	assert.Equal(t, "team-a", d.Annotations["example.com/owner"])
+	assert.Empty(t, d.Spec.Template.Annotations)
+
+	// The original entity is unchanged.
+	assert.Empty(t, entity.Obj.(*appsv1.Deployment).Annotations)
+}
+
 func TestSelectorMatchesLabels(t *testing.T) {
 	entities, err := ParseYAMLFromString(testyaml.BlorgBackendYAML)
 	if err != nil {
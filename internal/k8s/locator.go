@@ -6,6 +6,7 @@ import (
 
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/tilt-dev/tilt/internal/container"
@@ -253,3 +254,121 @@ func (l *JSONPathImageObjectLocator) Inject(e K8sEntity, selector container.RefS
 }
 
 var _ ImageLocator = &JSONPathImageObjectLocator{}
+
+// Finds the pod templates in a workload that Kubernetes doesn't know about,
+// like an Argo Rollout, so that Tilt can treat it like a Deployment.
+//
+// Tilt finds the images in the templates' containers. The engine injects the
+// built images into the templates with InjectIntoPodTemplates, along with
+// everything else it injects into a Deployment's pod template.
+type PodTemplateLocator struct {
+	selector ObjectSelector
+	path     JSONPath
+}
+
+func MustPodTemplateLocator(selector ObjectSelector, path string) *PodTemplateLocator {
+	locator, err := NewPodTemplateLocator(selector, path)
+	if err != nil {
+		panic(err)
+	}
+	return locator
+}
+
+func NewPodTemplateLocator(selector ObjectSelector, path string) (*PodTemplateLocator, error) {
+	p, err := NewJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &PodTemplateLocator{
+		selector: selector,
+		path:     p,
+	}, nil
+}
+
+func (l *PodTemplateLocator) EqualsImageLocator(other interface{}) bool {
+	otherL, ok := other.(*PodTemplateLocator)
+	if !ok {
+		return false
+	}
+	return l.path.path == otherL.path.path &&
+		l.selector.EqualsSelector(otherL.selector)
+}
+
+func (l *PodTemplateLocator) MatchesType(e K8sEntity) bool {
+	return l.selector.Matches(e)
+}
+
+// Whether the object this ref points to has pod templates that this locator can find.
+func (l *PodTemplateLocator) MatchesRef(ref v1.ObjectReference) bool {
+	return l.selector.apiVersion.MatchString(ref.APIVersion) &&
+		l.selector.kind.MatchString(ref.Kind) &&
+		l.selector.name.MatchString(ref.Name) &&
+		l.selector.namespace.MatchString(ref.Namespace)
+}
+
+func (l *PodTemplateLocator) Extract(e K8sEntity) ([]reference.Named, error) {
+	var result []reference.Named
+	err := l.visit(e, false, func(template *v1.PodTemplateSpec) error {
+		images, err := NewK8sEntity(&v1.PodTemplate{Template: *template}).FindImages(nil, nil)
+		if err != nil {
+			return err
+		}
+		result = append(result, images...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// A no-op. Images go into pod templates with InjectIntoPodTemplates instead,
+// so that the containers get the pull policy and command overrides too.
+func (l *PodTemplateLocator) Inject(e K8sEntity, selector container.RefSelector, injectRef reference.Named) (K8sEntity, bool, error) {
+	return e, false, nil
+}
+
+// Calls fn on each pod template in the entity. If write is true,
+// replaces each template with whatever fn changed it to.
+func (l *PodTemplateLocator) visit(e K8sEntity, write bool, fn func(template *v1.PodTemplateSpec) error) error {
+	u, ok := e.Obj.(runtime.Unstructured)
+	if !ok || !l.selector.Matches(e) {
+		return nil
+	}
+
+	return l.path.Visit(u.UnstructuredContent(), func(val jsonpath.Value) error {
+		m, ok := val.Interface().(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("May only match pod templates (json_path=%q)\nGot Type: %s\nGot Value: %s",
+				l.path.path, val.Type(), val)
+		}
+
+		var template v1.PodTemplateSpec
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &template)
+		if err != nil {
+			return errors.Wrapf(err, "decoding pod template at json path '%s'", l.path)
+		}
+
+		err = fn(&template)
+		if err != nil {
+			return err
+		}
+		if !write {
+			return nil
+		}
+
+		updated, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+		if err != nil {
+			return errors.Wrapf(err, "encoding pod template at json path '%s'", l.path)
+		}
+		for k := range m {
+			delete(m, k)
+		}
+		for k, v := range updated {
+			m[k] = v
+		}
+		return nil
+	})
+}
+
+var _ ImageLocator = &PodTemplateLocator{}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
@@ -32,3 +34,65 @@ func TestCRDImageObjectInjection(t *testing.T) {
 	require.Equal(t, 1, len(images))
 	assert.Equal(t, "docker.io/library/frontend:tilt-123", images[0].String())
 }
+
+func TestPodTemplateLocator(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.ArgoRolloutYAML)
+	require.NoError(t, err)
+
+	e := entities[0]
+	locator := MustPodTemplateLocator(MustKindSelector("Rollout"), "{.spec.template}")
+	assert.True(t, locator.MatchesType(e))
+	assert.True(t, locator.MatchesRef(e.ToObjectReference()))
+
+	images, err := locator.Extract(e)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(images))
+	assert.Equal(t, "gcr.io/some-project-162817/rollouts-demo", images[0].String())
+
+	// Images go in with InjectIntoPodTemplates instead.
+	_, modified, err := locator.Inject(e, container.MustParseSelector("gcr.io/some-project-162817/rollouts-demo"),
+		container.MustParseNamed("gcr.io/some-project-162817/rollouts-demo:tilt-123"))
+	require.NoError(t, err)
+	assert.False(t, modified)
+}
+
+func TestInjectIntoPodTemplates(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.ArgoRolloutYAML)
+	require.NoError(t, err)
+
+	orig := entities[0]
+	locators := []ImageLocator{MustPodTemplateLocator(MustKindSelector("Rollout"), "{.spec.template}")}
+	e, err := InjectIntoPodTemplates(orig, locators, func(e K8sEntity) (K8sEntity, error) {
+		e, _, err := InjectImageDigest(e, container.MustParseSelector("gcr.io/some-project-162817/rollouts-demo"),
+			container.MustParseNamed("gcr.io/some-project-162817/rollouts-demo:tilt-123"), nil, false, v1.PullNever)
+		if err != nil {
+			return K8sEntity{}, err
+		}
+		return InjectPodTemplateSpecHashes(e)
+	})
+	require.NoError(t, err)
+
+	images, err := locators[0].Extract(e)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(images))
+	assert.Equal(t, "gcr.io/some-project-162817/rollouts-demo:tilt-123", images[0].String())
+
+	policy, _, err := unstructured.NestedSlice(e.Obj.(*unstructured.Unstructured).Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	assert.Equal(t, "Never", policy[0].(map[string]interface{})["imagePullPolicy"])
+
+	hashes, err := ReadPodTemplateSpecHashes(e, locators)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(hashes))
+	assert.Regexp(t, `^[0-9a-f]{10,}$`, string(hashes[0]))
+
+	// The rest of the spec is untouched.
+	strategy, _, err := unstructured.NestedSlice(e.Obj.(*unstructured.Unstructured).Object, "spec", "strategy", "canary", "steps")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(strategy))
+
+	// The original is untouched too.
+	images, err = locators[0].Extract(orig)
+	require.NoError(t, err)
+	assert.Equal(t, "gcr.io/some-project-162817/rollouts-demo", images[0].String())
+}
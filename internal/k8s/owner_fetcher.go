@@ -127,12 +127,11 @@ func (v OwnerFetcher) OwnerTreeOf(ctx context.Context, entity K8sEntity) (result
 
 func (v OwnerFetcher) ownerTreeOfHelper(ctx context.Context, ref v1.ObjectReference, meta k8sMeta) (ObjectRefTree, error) {
 	tree := ObjectRefTree{Ref: ref}
-	owners, err := v.ownersOfMeta(ctx, meta)
-	if err != nil {
-		return ObjectRefTree{}, err
-	}
-	for _, owner := range owners {
-		ownerTree, err := v.OwnerTreeOf(ctx, owner)
+	for _, owner := range meta.GetOwnerReferences() {
+		// If the owner is already gone (e.g., a Job that was cleaned up as soon as
+		// its pod finished), we still know its UID, which is enough to match
+		// the pod to the resource that deployed the owner.
+		ownerTree, err := v.OwnerTreeOfRef(ctx, OwnerRefToObjectRef(owner, meta.GetNamespace()))
 		if err != nil {
 			return ObjectRefTree{}, err
 		}
@@ -141,24 +140,6 @@ func (v OwnerFetcher) ownerTreeOfHelper(ctx context.Context, ref v1.ObjectRefere
 	return tree, nil
 }
 
-func (v OwnerFetcher) ownersOfMeta(ctx context.Context, meta k8sMeta) ([]K8sEntity, error) {
-	owners := meta.GetOwnerReferences()
-	result := make([]K8sEntity, 0, len(owners))
-	for _, owner := range owners {
-		ref := OwnerRefToObjectRef(owner, meta.GetNamespace())
-		owner, err := v.kCli.GetByReference(ctx, ref)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				continue
-			}
-			return nil, err
-		}
-		result = append(result, owner)
-	}
-
-	return result, nil
-}
-
 func OwnerRefToObjectRef(owner metav1.OwnerReference, namespace string) v1.ObjectReference {
 	return v1.ObjectReference{
 		APIVersion: owner.APIVersion,
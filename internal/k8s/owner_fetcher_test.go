@@ -9,6 +9,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
 )
 
 func TestVisitOneParent(t *testing.T) {
@@ -38,6 +41,18 @@ func TestVisitTwoParents(t *testing.T) {
     Deployment:dep-a`, tree.String())
 }
 
+func TestVisitDeletedParent(t *testing.T) {
+	kCli := &FakeK8sClient{}
+	ov := ProvideOwnerFetcher(kCli)
+
+	pod, _ := fakeOneParentChain()
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	tree, err := ov.OwnerTreeOf(ctx, K8sEntity{Obj: pod})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.UID{"pod-a-uid", "rs-a-uid"}, tree.UIDs())
+}
+
 func TestOwnerFetcherParallelism(t *testing.T) {
 	kCli := &FakeK8sClient{}
 	ov := ProvideOwnerFetcher(kCli)
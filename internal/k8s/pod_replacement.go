@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	v1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The kubelet starts the termination grace period when it runs the preStop hook,
+// so a drain period eats into the time the container gets to shut down after SIGTERM.
+// If the user didn't set a grace period, make sure there's still this much left.
+const drainShutdownBuffer = 30 * time.Second
+
+// Rewrite the pods of a k8s entity so that they're replaced the way
+// the user asked for when Tilt redeploys them.
+func InjectPodReplacement(entity K8sEntity, p model.PodReplacement) (K8sEntity, error) {
+	if p.Empty() {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+	if p.Surge {
+		injectSurgeStrategy(entity)
+	}
+
+	if p.DrainPeriod == 0 && p.TerminationGracePeriod == nil {
+		return entity, nil
+	}
+
+	pods, err := ExtractPods(&entity)
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	for _, pod := range pods {
+		if p.DrainPeriod > 0 {
+			for i := range pod.Containers {
+				injectDrainHook(&pod.Containers[i], p.DrainPeriod)
+			}
+		}
+
+		if p.TerminationGracePeriod != nil {
+			pod.TerminationGracePeriodSeconds = durationToSeconds(*p.TerminationGracePeriod)
+		} else if p.DrainPeriod > 0 {
+			grace := p.DrainPeriod + drainShutdownBuffer
+			current := pod.TerminationGracePeriodSeconds
+			if current == nil || time.Duration(*current)*time.Second < grace {
+				pod.TerminationGracePeriodSeconds = durationToSeconds(grace)
+			}
+		}
+	}
+	return entity, nil
+}
+
+// Don't take down any old pods until their replacements are ready.
+func injectSurgeStrategy(entity K8sEntity) {
+	switch d := entity.Obj.(type) {
+	case *appsv1.Deployment:
+		d.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+		if d.Spec.Strategy.RollingUpdate == nil {
+			d.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+		}
+		ru := d.Spec.Strategy.RollingUpdate
+		ru.MaxUnavailable, ru.MaxSurge = surgeRollingUpdate(ru.MaxSurge)
+	case *appsv1beta2.Deployment:
+		d.Spec.Strategy.Type = appsv1beta2.RollingUpdateDeploymentStrategyType
+		if d.Spec.Strategy.RollingUpdate == nil {
+			d.Spec.Strategy.RollingUpdate = &appsv1beta2.RollingUpdateDeployment{}
+		}
+		ru := d.Spec.Strategy.RollingUpdate
+		ru.MaxUnavailable, ru.MaxSurge = surgeRollingUpdate(ru.MaxSurge)
+	case *appsv1beta1.Deployment:
+		d.Spec.Strategy.Type = appsv1beta1.RollingUpdateDeploymentStrategyType
+		if d.Spec.Strategy.RollingUpdate == nil {
+			d.Spec.Strategy.RollingUpdate = &appsv1beta1.RollingUpdateDeployment{}
+		}
+		ru := d.Spec.Strategy.RollingUpdate
+		ru.MaxUnavailable, ru.MaxSurge = surgeRollingUpdate(ru.MaxSurge)
+	case *extv1beta1.Deployment:
+		d.Spec.Strategy.Type = extv1beta1.RollingUpdateDeploymentStrategyType
+		if d.Spec.Strategy.RollingUpdate == nil {
+			d.Spec.Strategy.RollingUpdate = &extv1beta1.RollingUpdateDeployment{}
+		}
+		ru := d.Spec.Strategy.RollingUpdate
+		ru.MaxUnavailable, ru.MaxSurge = surgeRollingUpdate(ru.MaxSurge)
+	}
+}
+
+// Returns the maxUnavailable and maxSurge for a rolling update that
+// never has fewer ready pods than desired. Keeps the user's maxSurge,
+// unless it wouldn't let the rollout make progress.
+func surgeRollingUpdate(maxSurge *intstr.IntOrString) (*intstr.IntOrString, *intstr.IntOrString) {
+	zero := intstr.FromInt(0)
+	if maxSurge == nil || maxSurge.String() == "0" || maxSurge.String() == "0%" {
+		one := intstr.FromInt(1)
+		maxSurge = &one
+	}
+	return &zero, maxSurge
+}
+
+// Sleep before the container gets SIGTERM, so that Services have time to
+// stop routing requests to it. Leaves user-defined preStop hooks alone.
+//
+// The hook runs `sleep` in the container, because the Kubernetes API we build
+// against predates the built-in sleep action. Images without a `sleep` binary
+// (e.g., distroless or scratch) fail the hook, and the kubelet logs a
+// FailedPreStopHook event and sends SIGTERM right away, so those containers
+// don't drain.
+func injectDrainHook(c *v1.Container, drain time.Duration) {
+	if c.Lifecycle != nil && c.Lifecycle.PreStop != nil {
+		return
+	}
+	if c.Lifecycle == nil {
+		c.Lifecycle = &v1.Lifecycle{}
+	}
+	secs := int64(math.Ceil(drain.Seconds()))
+	c.Lifecycle.PreStop = &v1.Handler{
+		Exec: &v1.ExecAction{
+			Command: []string{"sleep", strconv.FormatInt(secs, 10)},
+		},
+	}
+}
+
+func durationToSeconds(d time.Duration) *int64 {
+	secs := int64(math.Ceil(d.Seconds()))
+	return &secs
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const podReplacementYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  strategy:
+    type: Recreate
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: gcr.io/web
+      - name: proxy
+        image: gcr.io/proxy
+        lifecycle:
+          preStop:
+            exec:
+              command: ["/bin/drain"]
+`
+
+func TestInjectPodReplacementSurge(t *testing.T) {
+	deployment := injectPodReplacement(t, model.PodReplacement{Surge: true})
+
+	strategy := deployment.Spec.Strategy
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, strategy.Type)
+	require.NotNil(t, strategy.RollingUpdate)
+	assert.Equal(t, "0", strategy.RollingUpdate.MaxUnavailable.String())
+	assert.Equal(t, "1", strategy.RollingUpdate.MaxSurge.String())
+
+	// Pods are untouched.
+	assert.Nil(t, deployment.Spec.Template.Spec.Containers[0].Lifecycle)
+	assert.Nil(t, deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestInjectPodReplacementDrain(t *testing.T) {
+	deployment := injectPodReplacement(t, model.PodReplacement{DrainPeriod: 5 * time.Second})
+
+	spec := deployment.Spec.Template.Spec
+	assert.Equal(t, v1.Handler{Exec: &v1.ExecAction{Command: []string{"sleep", "5"}}},
+		*spec.Containers[0].Lifecycle.PreStop)
+	assert.Equal(t, []string{"/bin/drain"}, spec.Containers[1].Lifecycle.PreStop.Exec.Command)
+
+	// The grace period leaves time to shut down after the drain.
+	require.NotNil(t, spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, int64(35), *spec.TerminationGracePeriodSeconds)
+
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+}
+
+func TestInjectPodReplacementGracePeriod(t *testing.T) {
+	grace := 90 * time.Second
+	deployment := injectPodReplacement(t, model.PodReplacement{
+		DrainPeriod:            5 * time.Second,
+		TerminationGracePeriod: &grace,
+	})
+
+	spec := deployment.Spec.Template.Spec
+	require.NotNil(t, spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, int64(90), *spec.TerminationGracePeriodSeconds)
+}
+
+func injectPodReplacement(t *testing.T, p model.PodReplacement) *appsv1.Deployment {
+	entities, err := ParseYAMLFromString(podReplacementYAML)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+
+	e, err := InjectPodReplacement(entities[0], p)
+	require.NoError(t, err)
+
+	deployment, ok := e.Obj.(*appsv1.Deployment)
+	require.True(t, ok)
+	return deployment
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const TiltPodTemplateHashLabel = "tilt.dev/pod-template-hash"
@@ -47,8 +48,9 @@ func InjectPodTemplateSpecHashes(entity K8sEntity) (K8sEntity, error) {
 }
 
 // ReadPodTemplateSpecHashes pulls the PodTemplateSpecHash that Tilt injected
-// into this entity's metadata during deploy (if any)
-func ReadPodTemplateSpecHashes(entity K8sEntity) ([]PodTemplateSpecHash, error) {
+// into this entity's metadata during deploy (if any), including the pod
+// templates that any PodTemplateLocators find.
+func ReadPodTemplateSpecHashes(entity K8sEntity, locators []ImageLocator) ([]PodTemplateSpecHash, error) {
 	templateSpecs, err := ExtractPodTemplateSpec(&entity)
 	if err != nil {
 		return nil, err
@@ -59,5 +61,61 @@ func ReadPodTemplateSpecHashes(entity K8sEntity) ([]PodTemplateSpecHash, error)
 		ret = append(ret, PodTemplateSpecHash(ts.Labels[TiltPodTemplateHashLabel]))
 	}
 
+	for _, l := range PodTemplateLocators(locators) {
+		err := l.visit(entity, false, func(ts *v1.PodTemplateSpec) error {
+			ret = append(ret, PodTemplateSpecHash(ts.Labels[TiltPodTemplateHashLabel]))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return ret, nil
 }
+
+// The PodTemplateLocators among the image locators.
+func PodTemplateLocators(locators []ImageLocator) []*PodTemplateLocator {
+	var result []*PodTemplateLocator
+	for _, l := range locators {
+		if ptl, ok := l.(*PodTemplateLocator); ok {
+			result = append(result, ptl)
+		}
+	}
+	return result
+}
+
+// Calls inject on each pod template that the PodTemplateLocators find in
+// the entity, and returns the entity with the changed templates.
+//
+// Each template is wrapped in a v1.PodTemplate, so that the functions that
+// inject into a Deployment's pod template work on it too.
+func InjectIntoPodTemplates(entity K8sEntity, locators []ImageLocator, inject func(e K8sEntity) (K8sEntity, error)) (K8sEntity, error) {
+	ptls := PodTemplateLocators(locators)
+	if len(ptls) == 0 {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+	for _, l := range ptls {
+		err := l.visit(entity, true, func(ts *v1.PodTemplateSpec) error {
+			injected, err := inject(NewK8sEntity(&v1.PodTemplate{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PodTemplate"},
+				Template: *ts,
+			}))
+			if err != nil {
+				return err
+			}
+			pt, ok := injected.Obj.(*v1.PodTemplate)
+			if !ok {
+				return fmt.Errorf("injecting into pod template: expected PodTemplate, actual %T", injected.Obj)
+			}
+			*ts = pt.Template
+			return nil
+		})
+		if err != nil {
+			return K8sEntity{}, err
+		}
+	}
+	return entity, nil
+}
@@ -54,6 +54,24 @@ type PortForwarder struct {
 	requestID     int
 }
 
+type onConnectionKey struct{}
+
+// WithOnConnection returns a context that makes PortForwarders created with it
+// call f every time they accept a local connection.
+func WithOnConnection(ctx context.Context, f func()) context.Context {
+	return context.WithValue(ctx, onConnectionKey{}, f)
+}
+
+type wrapConnKey struct{}
+
+// WithWrapConn returns a context that makes PortForwarders created with it
+// pass every local connection they accept through wrap, e.g., to count its bytes.
+//
+// A nil wrap turns off wrapping that a parent context set up.
+func WithWrapConn(ctx context.Context, wrap func(net.Conn) net.Conn) context.Context {
+	return context.WithValue(ctx, wrapConnKey{}, wrap)
+}
+
 // ForwardedPort contains a Local:Remote port pairing.
 type ForwardedPort struct {
 	Local  uint16
@@ -299,6 +317,12 @@ func (pf *PortForwarder) waitForConnection(listener net.Listener, port Forwarded
 			}
 			return
 		}
+		if onConnection, ok := pf.ctx.Value(onConnectionKey{}).(func()); ok {
+			onConnection()
+		}
+		if wrap, ok := pf.ctx.Value(wrapConnKey{}).(func(net.Conn) net.Conn); ok && wrap != nil {
+			conn = wrap(conn)
+		}
 		go pf.handleConnection(conn, port)
 	}
 }
@@ -0,0 +1,284 @@
+package k8s
+
+import (
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Labels that conventionally name the app an object belongs to.
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+var appLabels = []string{
+	"app.kubernetes.io/instance",
+	"app.kubernetes.io/name",
+	"app",
+}
+
+// Finds the entities that belong with each group of workload entities.
+//
+// An entity belongs to a group if one of the group's workloads owns it,
+// if one of the group's workloads mounts it or reads it into env vars
+// (ConfigMaps, Secrets, PersistentVolumeClaims, ServiceAccounts), if it's an
+// Ingress that routes to one of the group's Services, or if it shares
+// app labels with one of the group's workloads.
+//
+// Entities that could belong to more than one group stay in rest,
+// so that the user can decide where they go.
+func GroupRelatedEntities(groups [][]K8sEntity, entities []K8sEntity) (related [][]K8sEntity, rest []K8sEntity, err error) {
+	refs := make([]map[relatedKey]bool, len(groups))
+	services := make([]map[relatedKey]bool, len(groups))
+	for i, group := range groups {
+		refs[i] = make(map[relatedKey]bool)
+		services[i] = make(map[relatedKey]bool)
+		for _, e := range group {
+			if e.GVK().Kind == "Service" {
+				services[i][newRelatedKey("Service", e.NamespaceOrDefault("default"), e.Name())] = true
+			}
+
+			templates, err := ExtractPodTemplateSpec(&e)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "extracting pod template spec")
+			}
+			for _, template := range templates {
+				addPodSpecRefs(refs[i], e.NamespaceOrDefault("default"), template.Spec)
+			}
+		}
+	}
+
+	related = make([][]K8sEntity, len(groups))
+	for _, e := range entities {
+		i, err := findRelatedGroup(groups, refs, services, e)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i == -1 {
+			rest = append(rest, e)
+			continue
+		}
+		related[i] = append(related[i], e)
+	}
+	return related, rest, nil
+}
+
+type relatedKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func newRelatedKey(kind, namespace, name string) relatedKey {
+	return relatedKey{kind: kind, namespace: namespace, name: name}
+}
+
+func addPodSpecRefs(refs map[relatedKey]bool, ns string, spec v1.PodSpec) {
+	add := func(kind, name string) {
+		if name != "" {
+			refs[newRelatedKey(kind, ns, name)] = true
+		}
+	}
+
+	add("ServiceAccount", spec.ServiceAccountName)
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			add("ConfigMap", v.ConfigMap.Name)
+		}
+		if v.Secret != nil {
+			add("Secret", v.Secret.SecretName)
+		}
+		if v.PersistentVolumeClaim != nil {
+			add("PersistentVolumeClaim", v.PersistentVolumeClaim.ClaimName)
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.ConfigMap != nil {
+					add("ConfigMap", source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					add("Secret", source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append([]v1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, c := range containers {
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add("ConfigMap", envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				add("Secret", envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add("Secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+}
+
+// Returns the index of the only group e belongs to, or -1.
+func findRelatedGroup(groups [][]K8sEntity, refs, services []map[relatedKey]bool, e K8sEntity) (int, error) {
+	ns := e.NamespaceOrDefault("default")
+	kind := e.GVK().Kind
+
+	// Try each rule in order of how sure we are, and stop at the first one
+	// that points to exactly one group.
+	i := onlyMatch(len(groups), func(i int) bool {
+		for _, workload := range groups[i] {
+			if workload.NamespaceOrDefault("default") != ns {
+				continue
+			}
+			for _, owner := range e.OwnerReferences() {
+				if owner.Kind == workload.GVK().Kind && owner.Name == workload.Name() {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	if i != -1 {
+		return i, nil
+	}
+
+	key := newRelatedKey(kind, ns, e.Name())
+	i = onlyMatch(len(groups), func(i int) bool { return refs[i][key] })
+	if i != -1 {
+		return i, nil
+	}
+
+	if kind == "Ingress" {
+		backends, err := ingressServiceNames(e)
+		if err != nil {
+			return -1, err
+		}
+		i = onlyMatch(len(groups), func(i int) bool {
+			for _, name := range backends {
+				if services[i][newRelatedKey("Service", ns, name)] {
+					return true
+				}
+			}
+			return false
+		})
+		if i != -1 {
+			return i, nil
+		}
+	}
+
+	return bestAppLabelMatch(groups, e), nil
+}
+
+// Returns the index of the only group that passes the test, or -1.
+func onlyMatch(n int, test func(i int) bool) int {
+	result := -1
+	for i := 0; i < n; i++ {
+		if !test(i) {
+			continue
+		}
+		if result != -1 {
+			return -1
+		}
+		result = i
+	}
+	return result
+}
+
+// Returns the group whose workloads share the most app labels with e,
+// or -1 if there's a tie or no group shares any.
+func bestAppLabelMatch(groups [][]K8sEntity, e K8sEntity) int {
+	labels := e.Labels()
+	ns := e.NamespaceOrDefault("default")
+
+	best, bestScore, tie := -1, 0, false
+	for i, group := range groups {
+		score := 0
+		for _, workload := range group {
+			if workload.NamespaceOrDefault("default") != ns {
+				continue
+			}
+			templates, err := ExtractPodTemplateSpec(&workload)
+			if err != nil || len(templates) == 0 {
+				continue
+			}
+
+			workloadScore := 0
+			workloadLabels := workload.Labels()
+			for _, key := range appLabels {
+				val, ok := labels[key]
+				if ok && val != "" && workloadLabels[key] == val {
+					workloadScore++
+				}
+			}
+			if workloadScore > score {
+				score = workloadScore
+			}
+		}
+
+		if score == 0 {
+			continue
+		}
+		if score > bestScore {
+			best, bestScore, tie = i, score, false
+		} else if score == bestScore {
+			tie = true
+		}
+	}
+	if tie {
+		return -1
+	}
+	return best
+}
+
+// The names of all the Services an Ingress routes to.
+//
+// Works for both the networking.k8s.io/v1 schema (backend.service.name) and
+// the older v1beta1 schema (backend.serviceName).
+func ingressServiceNames(e K8sEntity) ([]string, error) {
+	var obj map[string]interface{}
+	if u, ok := e.Obj.(runtime.Unstructured); ok {
+		obj = u.UnstructuredContent()
+	} else {
+		var err error
+		obj, err = runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading ingress %s", e.Name())
+		}
+	}
+	spec, ok := obj["spec"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var names []string
+	var visit func(v interface{})
+	visit = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if name, ok := v["serviceName"].(string); ok {
+				names = append(names, name)
+			}
+			if service, ok := v["service"].(map[string]interface{}); ok {
+				if name, ok := service["name"].(string); ok {
+					names = append(names, name)
+				}
+			}
+			for _, child := range v {
+				visit(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				visit(child)
+			}
+		}
+	}
+	visit(spec)
+	return names, nil
+}
@@ -0,0 +1,287 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const relatedDeploymentYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  labels:
+    app: frontend
+    app.kubernetes.io/instance: shop
+spec:
+  selector:
+    matchLabels:
+      app: frontend
+  template:
+    metadata:
+      labels:
+        app: frontend
+    spec:
+      serviceAccountName: frontend-sa
+      containers:
+      - name: frontend
+        image: frontend
+        envFrom:
+        - configMapRef:
+            name: frontend-env
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: frontend-password
+              key: password
+      volumes:
+      - name: config
+        configMap:
+          name: frontend-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  labels:
+    app: backend
+    app.kubernetes.io/instance: shop
+spec:
+  selector:
+    matchLabels:
+      app: backend
+  template:
+    metadata:
+      labels:
+        app: backend
+    spec:
+      containers:
+      - name: backend
+        image: backend
+        envFrom:
+        - secretRef:
+            name: shared-secret
+      volumes:
+      - name: data
+        persistentVolumeClaim:
+          claimName: backend-data
+      - name: shared
+        secret:
+          secretName: shared-secret
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app: worker
+spec:
+  selector:
+    matchLabels:
+      app: worker
+  template:
+    metadata:
+      labels:
+        app: worker
+    spec:
+      containers:
+      - name: worker
+        image: backend
+        envFrom:
+        - secretRef:
+            name: shared-secret
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+spec:
+  selector:
+    app: frontend
+`
+
+func TestGroupRelatedByReference(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: frontend-env
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: frontend-config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: frontend-password
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: frontend-sa
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: backend-data
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+`)
+
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend-env", "frontend-config", "frontend-password", "frontend-sa"}, entityNames(related[0]))
+	assert.Equal(t, []string{"backend-data"}, entityNames(related[1]))
+	assert.Equal(t, []string{"unrelated"}, entityNames(rest))
+}
+
+func TestGroupRelatedSkipsSharedReferences(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: shared-secret
+`)
+
+	// Both backend and worker read it.
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Empty(t, related[1])
+	assert.Empty(t, related[2])
+	assert.Equal(t, []string{"shared-secret"}, entityNames(rest))
+}
+
+func TestGroupRelatedReferenceInOtherNamespace(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: frontend-env
+  namespace: other
+`)
+
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Empty(t, related[0])
+	assert.Equal(t, []string{"frontend-env"}, entityNames(rest))
+}
+
+func TestGroupRelatedByOwner(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: backend-pdb
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Deployment
+    name: backend
+    uid: "1234"
+`)
+
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend-pdb"}, entityNames(related[1]))
+	assert.Empty(t, rest)
+}
+
+func TestGroupRelatedIngress(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: frontend-v1
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: frontend
+            port:
+              number: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: frontend-v1beta1
+spec:
+  backend:
+    serviceName: frontend
+    servicePort: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: elsewhere
+spec:
+  backend:
+    serviceName: elsewhere
+    servicePort: 80
+`)
+
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend-v1", "frontend-v1beta1"}, entityNames(related[0]))
+	assert.Equal(t, []string{"elsewhere"}, entityNames(rest))
+}
+
+func TestGroupRelatedByAppLabels(t *testing.T) {
+	groups, entities := parseRelated(t, `
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: backend-hpa
+  labels:
+    app: backend
+    app.kubernetes.io/instance: shop
+spec:
+  maxReplicas: 3
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: backend
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shop-settings
+  labels:
+    app.kubernetes.io/instance: shop
+`)
+
+	related, rest, err := GroupRelatedEntities(groups, entities)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend-hpa"}, entityNames(related[1]))
+
+	// Both workloads are part of the shop, so we can't tell which one it belongs to.
+	assert.Equal(t, []string{"shop-settings"}, entityNames(rest))
+}
+
+func parseRelated(t *testing.T, yaml string) ([][]K8sEntity, []K8sEntity) {
+	workloads, err := ParseYAMLFromString(relatedDeploymentYAML)
+	require.NoError(t, err)
+	entities, err := ParseYAMLFromString(yaml)
+	require.NoError(t, err)
+
+	// frontend and its service, backend, and worker
+	return [][]K8sEntity{{workloads[0], workloads[3]}, {workloads[1]}, {workloads[2]}}, entities
+}
+
+func entityNames(entities []K8sEntity) []string {
+	names := []string{}
+	for _, e := range entities {
+		names = append(names, e.Name())
+	}
+	return names
+}
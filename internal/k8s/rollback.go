@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Returns true for workloads that we can roll back by restoring their pod template.
+//
+// Jobs are immutable, and bare pods don't have a template, so we can't roll them back.
+func IsRollbackable(e K8sEntity) bool {
+	gk := e.GVK().GroupKind()
+	if gk.Group != "apps" {
+		return false
+	}
+	switch gk.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return true
+	}
+	return false
+}
+
+// A JSON merge patch that restores the pod template of a workload from current
+// to previous, so that the workload rolls back to the images it ran before.
+//
+// Returns nil if the pod templates are the same.
+func PodTemplateRollbackPatch(previous, current K8sEntity) ([]byte, error) {
+	prevTemplate, err := podTemplateMap(previous)
+	if err != nil {
+		return nil, err
+	}
+	curTemplate, err := podTemplateMap(current)
+	if err != nil {
+		return nil, err
+	}
+
+	templatePatch := createMergePatch(curTemplate, prevTemplate)
+	if len(templatePatch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": templatePatch,
+		},
+	})
+}
+
+func podTemplateMap(e K8sEntity) (map[string]interface{}, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+	if err != nil {
+		return nil, err
+	}
+	template, ok, err := unstructured.NestedMap(obj, "spec", "template")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s %s has no pod template", e.GVK().Kind, e.Name())
+	}
+	return template, nil
+}
+
+// Creates a JSON merge patch (RFC 7386) that turns original into modified.
+//
+// Merge patches replace lists wholesale, and delete fields set to null.
+func createMergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, modVal := range modified {
+		origVal, ok := original[key]
+		if !ok {
+			patch[key] = modVal
+			continue
+		}
+
+		origMap, origIsMap := origVal.(map[string]interface{})
+		modMap, modIsMap := modVal.(map[string]interface{})
+		if origIsMap && modIsMap {
+			sub := createMergePatch(origMap, modMap)
+			if len(sub) > 0 {
+				patch[key] = sub
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(origVal, modVal) {
+			patch[key] = modVal
+		}
+	}
+
+	for key := range original {
+		if _, ok := modified[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsRollbackable(t *testing.T) {
+	for kind, expected := range map[string]bool{
+		"Deployment":  true,
+		"StatefulSet": true,
+		"DaemonSet":   true,
+		"ReplicaSet":  false,
+	} {
+		assert.Equal(t, expected, IsRollbackable(rollbackTestEntity("apps/v1", kind, nil)), kind)
+	}
+	assert.False(t, IsRollbackable(rollbackTestEntity("batch/v1", "Job", nil)))
+	assert.False(t, IsRollbackable(rollbackTestEntity("v1", "Pod", nil)))
+}
+
+func TestPodTemplateRollbackPatch(t *testing.T) {
+	previous := rollbackTestEntity("apps/v1", "Deployment", map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "fe"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "fe", "image": "gcr.io/fe:v1"},
+			},
+		},
+	})
+	current := rollbackTestEntity("apps/v1", "Deployment", map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "fe", "app.kubernetes.io/managed-by": "tilt"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "fe", "image": "gcr.io/fe:tilt-123"},
+			},
+		},
+	})
+
+	patch, err := PodTemplateRollbackPatch(previous, current)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spec":{"template":{
+"metadata":{"labels":{"app.kubernetes.io/managed-by":null}},
+"spec":{"containers":[{"name":"fe","image":"gcr.io/fe:v1"}]}}}}`, string(patch))
+
+	patch, err = PodTemplateRollbackPatch(previous, previous)
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestPodTemplateRollbackPatchNoTemplate(t *testing.T) {
+	_, err := PodTemplateRollbackPatch(rollbackTestEntity("v1", "Pod", nil), rollbackTestEntity("v1", "Pod", nil))
+	assert.EqualError(t, err, "Pod fe has no pod template")
+}
+
+func rollbackTestEntity(apiVersion, kind string, template map[string]interface{}) K8sEntity {
+	obj := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "fe"},
+	}
+	if template != nil {
+		obj["spec"] = map[string]interface{}{"template": template}
+	}
+	return NewK8sEntity(&unstructured.Unstructured{Object: obj})
+}
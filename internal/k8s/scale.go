@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"encoding/json"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Kinds with a spec.replicas field that Tilt knows how to scale.
+var scalableKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"ReplicaSet":  true,
+}
+
+func IsScalableKind(kind string) bool {
+	return scalableKinds[kind]
+}
+
+// The number of replicas this entity asks for. Kubernetes defaults
+// spec.replicas to 1 when it's unset.
+//
+// Returns false if the entity doesn't have a replica count.
+func (e K8sEntity) Replicas() (int32, bool) {
+	switch obj := e.Obj.(type) {
+	case *unstructured.Unstructured:
+		if !IsScalableKind(obj.GetKind()) {
+			return 0, false
+		}
+		replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if err != nil {
+			return 0, false
+		}
+		if !found {
+			return 1, true
+		}
+		return int32(replicas), true
+	case *appsv1.Deployment:
+		return replicasOrDefault(obj.Spec.Replicas), true
+	case *appsv1.StatefulSet:
+		return replicasOrDefault(obj.Spec.Replicas), true
+	case *appsv1.ReplicaSet:
+		return replicasOrDefault(obj.Spec.Replicas), true
+	}
+	return 0, false
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// A JSON merge patch that sets the number of replicas on an object.
+func ReplicasPatch(replicas int32) []byte {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// A JSON merge patch that clears an object's finalizers, so that Kubernetes
+// can finish deleting it without waiting on whatever controller owns them.
+func RemoveFinalizersPatch() []byte {
+	return []byte(`{"metadata":{"finalizers":null}}`)
+}
@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReplicasUnstructured(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"replicas": int64(3)},
+	}}
+	replicas, ok := NewK8sEntity(obj).Replicas()
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), replicas)
+}
+
+func TestReplicasUnstructuredDefault(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "StatefulSet",
+		"spec": map[string]interface{}{},
+	}}
+	replicas, ok := NewK8sEntity(obj).Replicas()
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), replicas)
+}
+
+func TestReplicasUnstructuredNotScalable(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Service",
+	}}
+	_, ok := NewK8sEntity(obj).Replicas()
+	assert.False(t, ok)
+}
+
+func TestReplicasTyped(t *testing.T) {
+	two := int32(2)
+	replicas, ok := NewK8sEntity(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &two}}).Replicas()
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), replicas)
+
+	replicas, ok = NewK8sEntity(&appsv1.ReplicaSet{}).Replicas()
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), replicas)
+
+	_, ok = NewK8sEntity(&v1.Pod{}).Replicas()
+	assert.False(t, ok)
+}
+
+func TestReplicasPatch(t *testing.T) {
+	assert.Equal(t, `{"spec":{"replicas":0}}`, string(ReplicasPatch(0)))
+}
@@ -20,6 +20,56 @@ func ParseYAMLFromString(yaml string) ([]K8sEntity, error) {
 	return ParseYAML(buf)
 }
 
+// Like ParseYAMLFromString, but also returns the line (counting from 1)
+// where each entity starts in the YAML, for pointing users at it.
+func ParseYAMLFromStringWithLines(yaml string) ([]K8sEntity, []int, error) {
+	var entities []K8sEntity
+	var lines []int
+	var doc []string
+	docStart := 1
+
+	parseDoc := func() error {
+		parsed, err := ParseYAMLFromString(strings.Join(doc, "\n"))
+		if err != nil {
+			return err
+		}
+		line := docStart + firstObjectLine(doc)
+		for range parsed {
+			lines = append(lines, line)
+		}
+		entities = append(entities, parsed...)
+		return nil
+	}
+
+	// Split documents the same way the YAML decoder does.
+	for i, l := range strings.Split(yaml, "\n") {
+		if strings.HasPrefix(l, "---") {
+			if err := parseDoc(); err != nil {
+				return nil, nil, err
+			}
+			doc = nil
+			docStart = i + 2
+			continue
+		}
+		doc = append(doc, l)
+	}
+	if err := parseDoc(); err != nil {
+		return nil, nil, err
+	}
+	return entities, lines, nil
+}
+
+// The index of the first line of the document that isn't blank or a comment.
+func firstObjectLine(doc []string) int {
+	for i, l := range doc {
+		trimmed := strings.TrimSpace(l)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return i
+		}
+	}
+	return 0
+}
+
 func parseYAMLFromStringWithDeletedResources(yamlWithDeletedResources string) ([]K8sEntity, error) {
 	lines := strings.Split(yamlWithDeletedResources, "\n")
 	for len(lines) > 0 {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 )
@@ -154,3 +155,35 @@ func assertRoundTripYAML(t *testing.T, yaml string) []K8sEntity {
 	assert.Equal(t, expected, result)
 	return entities
 }
+
+func TestParseYAMLFromStringWithLines(t *testing.T) {
+	yaml := `# The app
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`
+	entities, lines, err := ParseYAMLFromStringWithLines(yaml)
+	require.NoError(t, err)
+	require.Len(t, entities, 4)
+	assert.Equal(t, []int{2, 8, 13, 13}, lines)
+	assert.Equal(t, "Deployment", entities[1].GVK().Kind)
+}
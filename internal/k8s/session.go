@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// Every object that Tilt applies is labeled with the Tilt session that applied it,
+// and annotated with a heartbeat that the session refreshes while it's still running.
+//
+// If a session goes away without cleaning up (e.g., someone closed their laptop
+// without running `tilt down`), its heartbeats go stale, and
+// `tilt gc-cluster` can find and delete everything it left behind.
+const SessionLabel = DefaultLabelPrefix + "/session"
+const HeartbeatAnnotation = DefaultLabelPrefix + "/heartbeat"
+
+// The prefix of the session label and heartbeat annotation,
+// unless the Tiltfile picks a different one with k8s_managed_labels().
+const DefaultLabelPrefix = "tilt.dev"
+
+// The keys of the session label and heartbeat annotation.
+type SessionKeys struct {
+	Label               string
+	HeartbeatAnnotation string
+}
+
+var DefaultSessionKeys = SessionKeys{Label: SessionLabel, HeartbeatAnnotation: HeartbeatAnnotation}
+
+// The session keys with the given prefix. An empty prefix means the default.
+func NewSessionKeys(prefix string) SessionKeys {
+	if prefix == "" || prefix == DefaultLabelPrefix {
+		return DefaultSessionKeys
+	}
+	return SessionKeys{
+		Label:               prefix + "/session",
+		HeartbeatAnnotation: prefix + "/heartbeat",
+	}
+}
+
+// How often a running session refreshes the heartbeat on its objects.
+const HeartbeatInterval = 5 * time.Minute
+
+type SessionID string
+
+func (id SessionID) String() string { return string(id) }
+
+func ProvideSessionID() (SessionID, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating session id: %v", err)
+	}
+	return SessionID(hex.EncodeToString(b)), nil
+}
+
+// Selects all objects applied by any Tilt session.
+func SessionSelector(keys SessionKeys) labels.Selector {
+	req, err := labels.NewRequirement(keys.Label, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+	return labels.NewSelector().Add(*req)
+}
+
+// Labels the entity with the session, and stamps it with a heartbeat.
+//
+// Unlike InjectLabels, this only touches the top-level object, not any pod templates.
+// Otherwise, every new session would change the pod template and restart every pod.
+func InjectSession(entity K8sEntity, keys SessionKeys, id SessionID, heartbeat time.Time) K8sEntity {
+	entity = entity.DeepCopy()
+	meta := entity.meta()
+
+	l := meta.GetLabels()
+	if l == nil {
+		l = map[string]string{}
+	}
+	l[keys.Label] = id.String()
+	meta.SetLabels(l)
+
+	a := meta.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[keys.HeartbeatAnnotation] = heartbeat.UTC().Format(time.RFC3339)
+	meta.SetAnnotations(a)
+	return entity
+}
+
+// The session that applied this entity and its last heartbeat.
+//
+// Returns false if the entity wasn't applied by a Tilt session.
+// If the heartbeat is missing or can't be parsed, it's the zero time.
+func SessionFromEntity(entity K8sEntity, keys SessionKeys) (SessionID, time.Time, bool) {
+	meta := entity.meta()
+	id, ok := meta.GetLabels()[keys.Label]
+	if !ok {
+		return "", time.Time{}, false
+	}
+
+	heartbeat, _ := time.Parse(time.RFC3339, meta.GetAnnotations()[keys.HeartbeatAnnotation])
+	return SessionID(id), heartbeat, true
+}
+
+// A JSON merge patch that refreshes the heartbeat on an object.
+func HeartbeatPatch(keys SessionKeys, heartbeat time.Time) []byte {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				keys.HeartbeatAnnotation: heartbeat.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -1,9 +1,17 @@
 package k8s
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
 	v1 "k8s.io/api/apps/v1"
 	"k8s.io/api/apps/v1beta1"
 	"k8s.io/api/apps/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // By default, StatefulSets use OrderedPodManagement.
@@ -31,3 +39,86 @@ func InjectParallelPodManagementPolicy(entity K8sEntity) K8sEntity {
 	}
 	return entity
 }
+
+// The parts of a StatefulSet spec that Tilt cares about, across API versions.
+type statefulSetInfo struct {
+	selector             *metav1.LabelSelector
+	volumeClaimTemplates []corev1.PersistentVolumeClaim
+	partition            *int32
+}
+
+func extractStatefulSetInfo(entity K8sEntity) (statefulSetInfo, bool) {
+	switch ss := entity.Obj.(type) {
+	case *v1.StatefulSet:
+		info := statefulSetInfo{selector: ss.Spec.Selector, volumeClaimTemplates: ss.Spec.VolumeClaimTemplates}
+		if ss.Spec.UpdateStrategy.RollingUpdate != nil {
+			info.partition = ss.Spec.UpdateStrategy.RollingUpdate.Partition
+		}
+		return info, true
+	case *v1beta1.StatefulSet:
+		info := statefulSetInfo{selector: ss.Spec.Selector, volumeClaimTemplates: ss.Spec.VolumeClaimTemplates}
+		if ss.Spec.UpdateStrategy.RollingUpdate != nil {
+			info.partition = ss.Spec.UpdateStrategy.RollingUpdate.Partition
+		}
+		return info, true
+	case *v1beta2.StatefulSet:
+		info := statefulSetInfo{selector: ss.Spec.Selector, volumeClaimTemplates: ss.Spec.VolumeClaimTemplates}
+		if ss.Spec.UpdateStrategy.RollingUpdate != nil {
+			info.partition = ss.Spec.UpdateStrategy.RollingUpdate.Partition
+		}
+		return info, true
+	}
+	return statefulSetInfo{}, false
+}
+
+// A StatefulSet with a partitioned rolling update only updates the pods
+// with an ordinal at or above the partition. The pods below the partition
+// intentionally keep running the old pod template.
+func IsPartitionedStatefulSet(entity K8sEntity) bool {
+	info, ok := extractStatefulSetInfo(entity)
+	return ok && info.partition != nil && *info.partition > 0
+}
+
+// The PersistentVolumeClaims that the StatefulSet controller created from the
+// StatefulSet's volumeClaimTemplates.
+//
+// Kubernetes never deletes these, even when the StatefulSet is deleted,
+// so that you don't lose data by accident.
+//
+// If the StatefulSet doesn't specify a namespace, looks in defaultNs,
+// the namespace that kubectl would have applied it to.
+func StatefulSetPVCs(ctx context.Context, kCli Client, entity K8sEntity, defaultNs Namespace) ([]K8sEntity, error) {
+	info, ok := extractStatefulSetInfo(entity)
+	if !ok || len(info.volumeClaimTemplates) == 0 {
+		return nil, nil
+	}
+
+	selector := labels.Everything()
+	if info.selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(info.selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "StatefulSet %s: selector", entity.Name())
+		}
+	}
+
+	pvcs, err := kCli.ListPersistentVolumeClaims(ctx, Namespace(entity.NamespaceOrDefault(defaultNs.String())), selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each PVC is named <template name>-<statefulset name>-<ordinal>
+	result := []K8sEntity{}
+	for _, pvc := range pvcs {
+		for _, template := range info.volumeClaimTemplates {
+			prefix := fmt.Sprintf("%s-%s-", template.Name, entity.Name())
+			if strings.HasPrefix(pvc.Name, prefix) {
+				pvc := pvc
+				pvc.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"}
+				result = append(result, NewK8sEntity(&pvc))
+				break
+			}
+		}
+	}
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,3 +20,19 @@ func TestStatefulsetPodManagementPolicy(t *testing.T) {
 	}
 	assert.Contains(t, result, "podManagementPolicy: Parallel")
 }
+
+func TestIsPartitionedStatefulSet(t *testing.T) {
+	ss, err := ParseYAMLFromString(testyaml.RedisStatefulSetYAML)
+	assert.Nil(t, err)
+	assert.False(t, IsPartitionedStatefulSet(ss[0]))
+
+	partitioned, err := ParseYAMLFromString(strings.Replace(testyaml.RedisStatefulSetYAML,
+		"  updateStrategy:\n    type: RollingUpdate\n",
+		"  updateStrategy:\n    type: RollingUpdate\n    rollingUpdate:\n      partition: 2\n", 1))
+	assert.Nil(t, err)
+	assert.True(t, IsPartitionedStatefulSet(partitioned[0]))
+
+	deployment, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	assert.Nil(t, err)
+	assert.False(t, IsPartitionedStatefulSet(deployment[0]))
+}
@@ -66,6 +66,50 @@ func NewTarget(
 	}.WithDependencyIDs(dependencyIDs).WithRefInjectCounts(refInjectCounts), nil
 }
 
+// Marks some of the target's entities as observed: Tilt shows their status
+// and pod logs, but never applies or deletes them.
+//
+// Observed entities stay in the target's ObjectRefs and DisplayNames,
+// but are removed from its YAML. Tilt doesn't inject labels into their
+// pods, so we find them by their pod template labels instead.
+func WithObservedEntities(target model.K8sTarget, entities []K8sEntity, isObserved func(e K8sEntity) bool) (model.K8sTarget, error) {
+	var managed []K8sEntity
+	var observedRefs []v1.ObjectReference
+	var podSelectors []labels.Selector
+	for _, e := range SortedEntities(entities) {
+		if !isObserved(e) {
+			managed = append(managed, e)
+			continue
+		}
+
+		observedRefs = append(observedRefs, e.ToObjectReference())
+
+		templateSpecs, err := ExtractPodTemplateSpec(&e)
+		if err != nil {
+			return model.K8sTarget{}, err
+		}
+		for _, template := range templateSpecs {
+			if len(template.Labels) > 0 {
+				podSelectors = append(podSelectors, labels.SelectorFromSet(template.Labels))
+			}
+		}
+	}
+
+	if len(observedRefs) == 0 {
+		return target, nil
+	}
+
+	yaml, err := SerializeSpecYAML(managed)
+	if err != nil {
+		return model.K8sTarget{}, err
+	}
+
+	target.YAML = yaml
+	target.ObservedObjectRefs = observedRefs
+	target.ExtraPodSelectors = append(append([]labels.Selector{}, target.ExtraPodSelectors...), podSelectors...)
+	return target, nil
+}
+
 func NewK8sOnlyManifest(name model.ManifestName, entities []K8sEntity, allLocators []ImageLocator) (model.Manifest, error) {
 	kTarget, err := NewTarget(name.TargetName(), entities, nil, nil, nil, nil, model.PodReadinessIgnore, allLocators)
 	if err != nil {
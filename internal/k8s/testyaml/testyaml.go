@@ -1449,6 +1449,32 @@ spec:
     repo: frontend
 `
 
+const ArgoRolloutYAML = `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: rollouts-demo
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: rollouts-demo
+  template:
+    metadata:
+      labels:
+        app: rollouts-demo
+    spec:
+      containers:
+      - name: rollouts-demo
+        image: gcr.io/some-project-162817/rollouts-demo
+        ports:
+        - containerPort: 8080
+  strategy:
+    canary:
+      steps:
+      - setWeight: 20
+      - pause: {}
+`
+
 const MyNamespaceYAML = `apiVersion: v1
 kind: Namespace
 metadata:
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type EntityTransform interface {
+	// Checks whether two transforms are the same.
+	EqualsK8sTransform(other interface{}) bool
+
+	// Returns the changed entities.
+	Transform(ctx context.Context, entities []K8sEntity) ([]K8sEntity, error)
+}
+
+// Applies the target's transform (if any) to the entities we're about to deploy.
+//
+// The transform may change the entities, but not add, remove, or rename them,
+// because the rest of Tilt identifies the target's objects by name.
+func ApplyTransform(ctx context.Context, transform model.K8sTransform, entities []K8sEntity) ([]K8sEntity, error) {
+	if transform == nil {
+		return entities, nil
+	}
+
+	t, ok := transform.(EntityTransform)
+	if !ok {
+		return nil, fmt.Errorf("Internal error: invalid k8s transform %T", transform)
+	}
+
+	result, err := t.Transform(ctx, entities)
+	if err != nil {
+		return nil, err
+	}
+
+	before := transformRefs(entities)
+	after := transformRefs(result)
+	if len(before) != len(after) {
+		return nil, fmt.Errorf("transform was given %d objects, but returned %d. It may change objects, but not add or remove them",
+			len(before), len(after))
+	}
+	for i := range before {
+		if before[i] != after[i] {
+			return nil, fmt.Errorf("transform changed object %s to %s. It may change objects, but not rename them",
+				before[i], after[i])
+		}
+	}
+	return result, nil
+}
+
+func transformRefs(entities []K8sEntity) []string {
+	result := make([]string, 0, len(entities))
+	for _, e := range entities {
+		ref := e.ToObjectReference()
+		result = append(result, fmt.Sprintf("%s:%s:%s", ref.Kind, ref.Namespace, ref.Name))
+	}
+	sort.Strings(result)
+	return result
+}
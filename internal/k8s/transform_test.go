@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type fakeTransform struct {
+	fn func(entities []K8sEntity) []K8sEntity
+}
+
+func (fakeTransform) EqualsK8sTransform(other interface{}) bool { return false }
+
+func (t fakeTransform) Transform(ctx context.Context, entities []K8sEntity) ([]K8sEntity, error) {
+	return t.fn(entities), nil
+}
+
+func TestApplyTransformNil(t *testing.T) {
+	entities, err := ParseYAMLFromString(podReplacementYAML)
+	require.NoError(t, err)
+
+	result, err := ApplyTransform(context.Background(), nil, entities)
+	require.NoError(t, err)
+	assert.Equal(t, entities, result)
+}
+
+func TestApplyTransformChangesEntities(t *testing.T) {
+	entities, err := ParseYAMLFromString(podReplacementYAML)
+	require.NoError(t, err)
+
+	transform := fakeTransform{fn: func(entities []K8sEntity) []K8sEntity {
+		var result []K8sEntity
+		for _, e := range entities {
+			e, _ = InjectLabels(e, []model.LabelPair{{Key: "dev", Value: "true"}})
+			result = append(result, e)
+		}
+		return result
+	}}
+	result, err := ApplyTransform(context.Background(), transform, entities)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "true", result[0].Labels()["dev"])
+}
+
+func TestApplyTransformRename(t *testing.T) {
+	entities, err := ParseYAMLFromString(podReplacementYAML)
+	require.NoError(t, err)
+
+	transform := fakeTransform{fn: func(entities []K8sEntity) []K8sEntity {
+		return []K8sEntity{entities[0].WithNamespace("other")}
+	}}
+	_, err = ApplyTransform(context.Background(), transform, entities)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not rename them")
+	}
+}
+
+func TestApplyTransformAdd(t *testing.T) {
+	entities, err := ParseYAMLFromString(podReplacementYAML)
+	require.NoError(t, err)
+
+	transform := fakeTransform{fn: func(entities []K8sEntity) []K8sEntity {
+		return append(entities, entities[0].WithNamespace("other"))
+	}}
+	_, err = ApplyTransform(context.Background(), transform, entities)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not add or remove them")
+	}
+}
@@ -23,6 +23,7 @@ import (
 var PodGVR = v1.SchemeGroupVersion.WithResource("pods")
 var ServiceGVR = v1.SchemeGroupVersion.WithResource("services")
 var EventGVR = v1.SchemeGroupVersion.WithResource("events")
+var EndpointsGVR = v1.SchemeGroupVersion.WithResource("endpoints")
 
 // A wrapper object around SharedInformer objects, to make them
 // a bit easier to use correctly.
@@ -269,6 +270,43 @@ func (kCli K8sClient) WatchServices(ctx context.Context, ns Namespace, ls labels
 	return ch, nil
 }
 
+func (kCli K8sClient) WatchEndpoints(ctx context.Context, ns Namespace, ls labels.Selector) (<-chan *v1.Endpoints, error) {
+	gvr := EndpointsGVR
+	informer, err := kCli.makeInformer(ctx, ns, gvr, ls)
+	if err != nil {
+		return nil, errors.Wrap(err, "WatchEndpoints")
+	}
+
+	ch := make(chan *v1.Endpoints)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			mObj, ok := obj.(*v1.Endpoints)
+			if ok {
+				ch <- mObj
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			mObj, ok := obj.(*v1.Endpoints)
+			if ok {
+				// Report a deleted Endpoints object as one with no addresses.
+				mObj = mObj.DeepCopy()
+				mObj.Subsets = nil
+				ch <- mObj
+			}
+		},
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			newEndpoints, ok := newObj.(*v1.Endpoints)
+			if ok {
+				ch <- newEndpoints
+			}
+		},
+	})
+
+	go runInformer(ctx, "endpoints", informer)
+
+	return ch, nil
+}
+
 func runInformer(ctx context.Context, name string, informer cache.SharedInformer) {
 	originalDuration := 3 * time.Second
 	originalBackoff := wait.Backoff{
@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The condition types that workloads commonly use to say they're ready,
+// in the order we check them.
+var workloadReadyConditions = []string{"Ready", "Available"}
+
+// Reads the status that a workload with pod templates, like an Argo Rollout,
+// reports about its own rollout. Pods don't tell the whole story for these
+// workloads: a canary can be paused with every pod ready.
+//
+// We understand two conventions:
+// 1) A status.phase, like the Argo Rollout's Healthy, Progressing, Paused, or Degraded.
+// 2) A Ready or Available condition in status.conditions.
+//
+// Returns false if the workload doesn't report a status we understand,
+// so that its pods decide whether it's ready.
+func WorkloadRuntimeStatus(e K8sEntity) (model.RuntimeStatus, string, bool) {
+	u, ok := e.Obj.(runtime.Unstructured)
+	if !ok {
+		return "", "", false
+	}
+	obj := u.UnstructuredContent()
+
+	// The status is stale until the controller sees the spec we applied.
+	generation, hasGeneration, _ := unstructured.NestedInt64(obj, "metadata", "generation")
+	observed, hasObserved, _ := unstructured.NestedInt64(obj, "status", "observedGeneration")
+	if hasGeneration && hasObserved && observed < generation {
+		return model.RuntimeStatusPending, "Waiting for the controller to observe the latest spec", true
+	}
+
+	phase, _, _ := unstructured.NestedString(obj, "status", "phase")
+	message, _, _ := unstructured.NestedString(obj, "status", "message")
+	switch phase {
+	case "":
+	case "Healthy":
+		return model.RuntimeStatusOK, "", true
+	case "Degraded", "Failed":
+		return model.RuntimeStatusError, workloadStatusMessage(phase, message), true
+	default:
+		return model.RuntimeStatusPending, workloadStatusMessage(phase, message), true
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, conditionType := range workloadReadyConditions {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != conditionType {
+				continue
+			}
+
+			switch condition["status"] {
+			case "True":
+				return model.RuntimeStatusOK, "", true
+			case "False":
+				reason, _ := condition["reason"].(string)
+				message, _ := condition["message"].(string)
+				if reason == "" {
+					reason = fmt.Sprintf("Not %s", conditionType)
+				}
+				return model.RuntimeStatusPending, workloadStatusMessage(reason, message), true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+func workloadStatusMessage(summary, message string) string {
+	if message == "" {
+		return summary
+	}
+	return fmt.Sprintf("%s: %s", summary, message)
+}
@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestWorkloadRuntimeStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		status          map[string]interface{}
+		generation      int64
+		expectedOK      bool
+		expectedStatus  model.RuntimeStatus
+		expectedMessage string
+	}{
+		{
+			name:   "no status",
+			status: nil,
+		},
+		{
+			name:           "healthy phase",
+			status:         map[string]interface{}{"phase": "Healthy"},
+			expectedOK:     true,
+			expectedStatus: model.RuntimeStatusOK,
+		},
+		{
+			name:            "paused phase",
+			status:          map[string]interface{}{"phase": "Paused", "message": "CanaryPauseStep"},
+			expectedOK:      true,
+			expectedStatus:  model.RuntimeStatusPending,
+			expectedMessage: "Paused: CanaryPauseStep",
+		},
+		{
+			name:            "degraded phase",
+			status:          map[string]interface{}{"phase": "Degraded", "message": "ProgressDeadlineExceeded"},
+			expectedOK:      true,
+			expectedStatus:  model.RuntimeStatusError,
+			expectedMessage: "Degraded: ProgressDeadlineExceeded",
+		},
+		{
+			name:            "stale status",
+			status:          map[string]interface{}{"phase": "Healthy", "observedGeneration": int64(1)},
+			generation:      2,
+			expectedOK:      true,
+			expectedStatus:  model.RuntimeStatusPending,
+			expectedMessage: "Waiting for the controller to observe the latest spec",
+		},
+		{
+			name: "available condition",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "True"},
+				map[string]interface{}{"type": "Available", "status": "True"},
+			}},
+			expectedOK:     true,
+			expectedStatus: model.RuntimeStatusOK,
+		},
+		{
+			name: "not ready condition",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "MinimumReplicasUnavailable"},
+			}},
+			expectedOK:      true,
+			expectedStatus:  model.RuntimeStatusPending,
+			expectedMessage: "MinimumReplicasUnavailable",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entities, err := ParseYAMLFromString(testyaml.ArgoRolloutYAML)
+			require.NoError(t, err)
+			u := entities[0].Obj.(*unstructured.Unstructured)
+			if tc.status != nil {
+				u.Object["status"] = tc.status
+			}
+			if tc.generation != 0 {
+				u.SetGeneration(tc.generation)
+			}
+
+			status, msg, ok := WorkloadRuntimeStatus(entities[0])
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedMessage, msg)
+		})
+	}
+}
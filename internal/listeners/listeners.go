@@ -0,0 +1,248 @@
+// Package listeners keeps track of every local port that Tilt listens on
+// (the web server, port-forwards, and the proxies in front of them), so that
+// port collisions and unexpected listeners are easy to debug.
+package listeners
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type Kind string
+
+const (
+	KindWebServer      Kind = "web-server"
+	KindPortForward    Kind = "port-forward"
+	KindServiceForward Kind = "service-forward"
+
+	// Listens on a hibernated resource's port, and wakes it on the first connection.
+	KindWakeProxy Kind = "wake-proxy"
+)
+
+type State string
+
+const (
+	// Waiting to bind the port, e.g., while the pod starts or the port is taken.
+	StateStarting  State = "starting"
+	StateListening State = "listening"
+	StateError     State = "error"
+)
+
+// A snapshot of one listener.
+type Status struct {
+	Kind     Kind               `json:"kind"`
+	Resource model.ManifestName `json:"resource,omitempty"`
+	Protocol string             `json:"protocol"`
+	Address  string             `json:"address"`
+
+	// Where traffic goes, e.g., "pod/frontend-abc123:8080".
+	Target string `json:"target"`
+
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+
+	// When the listener last changed state.
+	Since time.Time `json:"since"`
+
+	Connections  int64     `json:"connections"`
+	BytesIn      int64     `json:"bytesIn"`
+	BytesOut     int64     `json:"bytesOut"`
+	LastActivity time.Time `json:"lastActivity,omitempty"`
+}
+
+type Registry struct {
+	mu      sync.Mutex
+	entries map[*Entry]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[*Entry]bool)}
+}
+
+// Adds a listener in the starting state. Call Remove when it's gone.
+func (r *Registry) Add(kind Kind, resource model.ManifestName, protocol, address, target string) *Entry {
+	e := &Entry{
+		registry: r,
+		status: Status{
+			Kind:     kind,
+			Resource: resource,
+			Protocol: protocol,
+			Address:  address,
+			Target:   target,
+			State:    StateStarting,
+			Since:    time.Now(),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[e] = true
+	return e
+}
+
+// Lists every listener, sorted by address.
+func (r *Registry) List() []Status {
+	r.mu.Lock()
+	entries := make([]*Entry, 0, len(r.entries))
+	for e := range r.entries {
+		entries = append(entries, e)
+	}
+	r.mu.Unlock()
+
+	result := make([]Status, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Status())
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Address != result[j].Address {
+			return result[i].Address < result[j].Address
+		}
+		if result[i].Protocol != result[j].Protocol {
+			return result[i].Protocol < result[j].Protocol
+		}
+		return result[i].Resource < result[j].Resource
+	})
+	return result
+}
+
+type Entry struct {
+	registry *Registry
+
+	connections  int64
+	bytesIn      int64
+	bytesOut     int64
+	lastActivity int64 // unix nanos
+
+	mu     sync.Mutex
+	status Status
+}
+
+func (e *Entry) Status() Status {
+	e.mu.Lock()
+	s := e.status
+	e.mu.Unlock()
+
+	s.Connections = atomic.LoadInt64(&e.connections)
+	s.BytesIn = atomic.LoadInt64(&e.bytesIn)
+	s.BytesOut = atomic.LoadInt64(&e.bytesOut)
+	if last := atomic.LoadInt64(&e.lastActivity); last != 0 {
+		s.LastActivity = time.Unix(0, last)
+	}
+	return s
+}
+
+// Sets the address after binding, e.g., when we asked for port 0.
+func (e *Entry) SetAddress(address string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.Address = address
+}
+
+func (e *Entry) SetTarget(target string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.Target = target
+}
+
+func (e *Entry) SetListening() {
+	e.setState(StateListening, "")
+}
+
+func (e *Entry) SetStarting() {
+	e.setState(StateStarting, "")
+}
+
+func (e *Entry) SetError(err error) {
+	e.setState(StateError, err.Error())
+}
+
+func (e *Entry) setState(state State, msg string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.status.State == state && e.status.Error == msg {
+		return
+	}
+	e.status.State = state
+	e.status.Error = msg
+	e.status.Since = time.Now()
+}
+
+func (e *Entry) Remove() {
+	e.registry.mu.Lock()
+	defer e.registry.mu.Unlock()
+	delete(e.registry.entries, e)
+}
+
+// Counts a new client connection.
+func (e *Entry) AddConnection() {
+	atomic.AddInt64(&e.connections, 1)
+	e.touch()
+}
+
+// Counts bytes received from clients.
+func (e *Entry) AddBytesIn(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddInt64(&e.bytesIn, int64(n))
+	e.touch()
+}
+
+// Counts bytes sent back to clients.
+func (e *Entry) AddBytesOut(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddInt64(&e.bytesOut, int64(n))
+	e.touch()
+}
+
+func (e *Entry) touch() {
+	atomic.StoreInt64(&e.lastActivity, time.Now().UnixNano())
+}
+
+// Counts the connection, and the bytes read from and written to it.
+func (e *Entry) WrapConn(conn net.Conn) net.Conn {
+	e.AddConnection()
+	return countingConn{Conn: conn, entry: e}
+}
+
+// Counts every connection that the listener accepts.
+func (e *Entry) WrapListener(l net.Listener) net.Listener {
+	return countingListener{Listener: l, entry: e}
+}
+
+type countingConn struct {
+	net.Conn
+	entry *Entry
+}
+
+func (c countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.entry.AddBytesIn(n)
+	return n, err
+}
+
+func (c countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.entry.AddBytesOut(n)
+	return n, err
+}
+
+type countingListener struct {
+	net.Listener
+	entry *Entry
+}
+
+func (l countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.entry.WrapConn(conn), nil
+}
@@ -0,0 +1,68 @@
+package listeners
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSortedByAddress(t *testing.T) {
+	r := NewRegistry()
+	r.Add(KindPortForward, "fe", "tcp", "localhost:8080", "pod/fe:80")
+	r.Add(KindWebServer, "", "tcp", "localhost:10350", "Tilt web UI and API")
+	be := r.Add(KindServiceForward, "be", "tcp", "localhost:5000", "service/be:5000")
+
+	var addrs []string
+	for _, s := range r.List() {
+		addrs = append(addrs, s.Address)
+	}
+	assert.Equal(t, []string{"localhost:10350", "localhost:5000", "localhost:8080"}, addrs)
+
+	be.Remove()
+	assert.Len(t, r.List(), 2)
+}
+
+func TestStateTransitions(t *testing.T) {
+	r := NewRegistry()
+	e := r.Add(KindPortForward, "fe", "tcp", "localhost:8080", "pod/fe:80")
+	assert.Equal(t, StateStarting, e.Status().State)
+
+	e.SetError(errors.New("address already in use"))
+	s := e.Status()
+	assert.Equal(t, StateError, s.State)
+	assert.Equal(t, "address already in use", s.Error)
+
+	e.SetListening()
+	s = e.Status()
+	assert.Equal(t, StateListening, s.State)
+	assert.Equal(t, "", s.Error)
+}
+
+func TestWrapConnCountsTraffic(t *testing.T) {
+	r := NewRegistry()
+	e := r.Add(KindPortForward, "fe", "tcp", "localhost:8080", "pod/fe:80")
+
+	server, client := net.Pipe()
+	wrapped := e.WrapConn(server)
+	go func() {
+		_, _ = client.Write([]byte("hello"))
+		_, _ = ioutil.ReadAll(client)
+	}()
+
+	buf := make([]byte, 5)
+	_, err := wrapped.Read(buf)
+	require.NoError(t, err)
+	_, err = wrapped.Write([]byte("hi"))
+	require.NoError(t, err)
+	_ = wrapped.Close()
+
+	s := e.Status()
+	assert.Equal(t, int64(1), s.Connections)
+	assert.Equal(t, int64(5), s.BytesIn)
+	assert.Equal(t, int64(2), s.BytesOut)
+	assert.False(t, s.LastActivity.IsZero())
+}
@@ -0,0 +1,119 @@
+// Package secretscan looks for well-known kinds of credentials in build
+// output and image metadata, so that we can catch them before they leak
+// into a deployed image or a shared log.
+package secretscan
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+
+	// If the credential is only part of the match, the index of the
+	// submatch that holds it. Otherwise 0.
+	group int
+}
+
+var patterns = []pattern{
+	{
+		kind: "AWS access key ID",
+		re:   regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	},
+	{
+		kind:  "AWS secret access key",
+		re:    regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})\b`),
+		group: 1,
+	},
+	{
+		kind: "GitHub token",
+		re:   regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		kind: "private key",
+		re:   regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----`),
+	},
+}
+
+// A credential we found, and where we found it.
+type Finding struct {
+	Kind   string
+	Source string
+	Value  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s in %s", f.Kind, f.Source)
+}
+
+type Findings []Finding
+
+// Scans text for credentials. The source describes where the text came from,
+// so that the user can track the credential down.
+func Scan(source string, text []byte) Findings {
+	var result Findings
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		for _, m := range p.re.FindAllSubmatch(text, -1) {
+			value := string(m[p.group])
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+			result = append(result, Finding{Kind: p.kind, Source: source, Value: value})
+		}
+	}
+	return result
+}
+
+// Scans an image's environment variables and labels for credentials.
+func ScanImage(ref string, env []string, labels map[string]string) Findings {
+	var result Findings
+	for _, e := range env {
+		name := strings.SplitN(e, "=", 2)[0]
+		result = append(result, Scan(fmt.Sprintf("env %s of image %s", name, ref), []byte(e))...)
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, Scan(fmt.Sprintf("label %s of image %s", k, ref), []byte(labels[k]))...)
+	}
+	return result
+}
+
+// The credentials as secrets, so that they can be scrubbed from logs.
+//
+// Logs are scrubbed a line at a time, so we scrub each line of a
+// multi-line credential (like a private key) separately.
+func (fs Findings) Secrets() model.SecretSet {
+	result := model.SecretSet{}
+	for _, f := range fs {
+		for _, line := range strings.Split(f.Value, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "-----") {
+				continue
+			}
+			result.AddSecret("leaked credential", f.Kind, []byte(line))
+		}
+	}
+	return result
+}
+
+// A human-readable list of what we found, without the credentials themselves.
+func (fs Findings) Summary() string {
+	descs := make([]string, 0, len(fs))
+	for _, f := range fs {
+		descs = append(descs, f.String())
+	}
+	return strings.Join(descs, "; ")
+}
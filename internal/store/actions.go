@@ -129,6 +129,8 @@ type TiltCloudStatusReceivedAction struct {
 	Found                    bool
 	Username                 string
 	TeamName                 string
+	Teams                    []CloudTeamStatus
+	TokenExpired             bool
 	IsPostRegistrationLookup bool
 	SuggestedTiltVersion     string
 }
@@ -156,8 +158,53 @@ func NewPodResetRestartsAction(podID k8s.PodID, mn model.ManifestName, visibleRe
 
 func (PodResetRestartsAction) Action() {}
 
+// The user turned traffic capture on a resource's port forwards on or off.
+type TrafficCaptureAction struct {
+	ManifestName model.ManifestName
+	Mode         model.TrafficCaptureMode
+}
+
+func (TrafficCaptureAction) Action() {}
+
+// The user asked to run a resource's CronJobs now, instead of waiting for
+// their schedule.
+type CronJobRunAction struct {
+	ManifestName model.ManifestName
+}
+
+func (CronJobRunAction) Action() {}
+
 type PanicAction struct {
 	Err error
 }
 
 func (PanicAction) Action() {}
+
+// Adds an alert to the alert center, or updates it if it's already there.
+type AlertAction struct {
+	Alert model.Alert
+}
+
+func (AlertAction) Action() {}
+
+// Removes an alert from the alert center, because the problem went away.
+type AlertResolvedAction struct {
+	ID string
+}
+
+func (AlertResolvedAction) Action() {}
+
+// The user has seen these alerts. With no IDs, the user has seen all of them.
+type AlertsAcknowledgedAction struct {
+	IDs []string
+}
+
+func (AlertsAcknowledgedAction) Action() {}
+
+// The user wants to skip the builds that are waiting in the queue.
+//
+// Drops triggers and pending file changes. Initial builds and Tiltfile
+// changes still build, or the resources wouldn't match the Tiltfile.
+type ClearBuildQueueAction struct{}
+
+func (ClearBuildQueueAction) Action() {}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Keep the alert center from growing without bound if something
+// generates a new alert ID on every update.
+const maxAlerts = 100
+
+// The alert center: warnings collected from around the engine,
+// so that they don't scroll past in the logs.
+//
+// Alerts are kept in the order we first saw them.
+type AlertStore struct {
+	Alerts []model.Alert
+}
+
+// Adds the alert, or updates the alert with the same ID.
+func (s *AlertStore) Upsert(a model.Alert, now time.Time) {
+	for i, existing := range s.Alerts {
+		if existing.ID != a.ID {
+			continue
+		}
+
+		a.FirstSeen = existing.FirstSeen
+		a.LastSeen = now
+		a.Count = existing.Count + 1
+
+		// Only keep the acknowledgment if it's still the same problem.
+		a.Acknowledged = existing.Acknowledged && existing.Message == a.Message
+		s.Alerts[i] = a
+		return
+	}
+
+	a.FirstSeen = now
+	a.LastSeen = now
+	a.Count = 1
+	a.Acknowledged = false
+	s.Alerts = append(s.Alerts, a)
+	if len(s.Alerts) > maxAlerts {
+		s.Alerts = append([]model.Alert{}, s.Alerts[len(s.Alerts)-maxAlerts:]...)
+	}
+}
+
+// Removes the alert with the given ID, if any.
+func (s *AlertStore) Resolve(id string) {
+	s.resolveIf(func(a model.Alert) bool { return a.ID == id })
+}
+
+// Replaces all the alerts from the given sources.
+//
+// For sources that report everything they know at once (like the Tiltfile),
+// so that problems that didn't come up this time get resolved.
+func (s *AlertStore) ReplaceSources(sources []model.AlertSource, alerts []model.Alert, now time.Time) {
+	ids := make(map[string]bool, len(alerts))
+	for _, a := range alerts {
+		ids[a.ID] = true
+	}
+
+	s.resolveIf(func(a model.Alert) bool {
+		for _, source := range sources {
+			if a.Source == source && !ids[a.ID] {
+				return true
+			}
+		}
+		return false
+	})
+
+	for _, a := range alerts {
+		s.Upsert(a, now)
+	}
+}
+
+// Acknowledges the alerts with the given IDs. With no IDs, acknowledges every alert.
+func (s *AlertStore) Acknowledge(ids []string) {
+	for i, a := range s.Alerts {
+		if len(ids) == 0 || containsString(ids, a.ID) {
+			s.Alerts[i].Acknowledged = true
+		}
+	}
+}
+
+func (s *AlertStore) Unacknowledged() []model.Alert {
+	result := []model.Alert{}
+	for _, a := range s.Alerts {
+		if !a.Acknowledged {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func (s *AlertStore) resolveIf(pred func(a model.Alert) bool) {
+	result := s.Alerts[:0]
+	for _, a := range s.Alerts {
+		if !pred(a) {
+			result = append(result, a)
+		}
+	}
+	s.Alerts = result
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
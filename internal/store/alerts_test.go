@@ -0,0 +1,77 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestAlertUpsertCountsRepeats(t *testing.T) {
+	s := AlertStore{}
+	t1 := time.Unix(1, 0)
+	t2 := time.Unix(2, 0)
+	s.Upsert(model.Alert{ID: "a", Message: "oops"}, t1)
+	s.Upsert(model.Alert{ID: "a", Message: "oops"}, t2)
+
+	if assert.Len(t, s.Alerts, 1) {
+		assert.Equal(t, 2, s.Alerts[0].Count)
+		assert.Equal(t, t1, s.Alerts[0].FirstSeen)
+		assert.Equal(t, t2, s.Alerts[0].LastSeen)
+	}
+}
+
+func TestAlertAcknowledge(t *testing.T) {
+	s := AlertStore{}
+	now := time.Now()
+	s.Upsert(model.Alert{ID: "a", Message: "oops"}, now)
+	s.Upsert(model.Alert{ID: "b", Message: "uh oh"}, now)
+
+	s.Acknowledge([]string{"a"})
+	assert.Equal(t, []string{"b"}, alertIDs(s.Unacknowledged()))
+
+	// The same problem again stays acknowledged.
+	s.Upsert(model.Alert{ID: "a", Message: "oops"}, now)
+	assert.Equal(t, []string{"b"}, alertIDs(s.Unacknowledged()))
+
+	// A different message for the same alert is a new problem.
+	s.Upsert(model.Alert{ID: "a", Message: "oops again"}, now)
+	assert.Equal(t, []string{"a", "b"}, alertIDs(s.Unacknowledged()))
+
+	s.Acknowledge(nil)
+	assert.Empty(t, s.Unacknowledged())
+}
+
+func TestAlertReplaceSources(t *testing.T) {
+	s := AlertStore{}
+	now := time.Now()
+	s.Upsert(model.Alert{ID: "api:1", Source: model.AlertSourceDeprecatedAPI}, now)
+	s.Upsert(model.Alert{ID: "api:2", Source: model.AlertSourceDeprecatedAPI}, now)
+	s.Upsert(model.Alert{ID: "crash", Source: model.AlertSourceCrashLoop}, now)
+
+	s.ReplaceSources([]model.AlertSource{model.AlertSourceDeprecatedAPI}, []model.Alert{
+		{ID: "api:2", Source: model.AlertSourceDeprecatedAPI},
+		{ID: "api:3", Source: model.AlertSourceDeprecatedAPI},
+	}, now)
+
+	assert.Equal(t, []string{"api:2", "crash", "api:3"}, alertIDs(s.Alerts))
+}
+
+func TestAlertResolve(t *testing.T) {
+	s := AlertStore{}
+	now := time.Now()
+	s.Upsert(model.Alert{ID: "a"}, now)
+	s.Upsert(model.Alert{ID: "b"}, now)
+	s.Resolve("a")
+	assert.Equal(t, []string{"b"}, alertIDs(s.Alerts))
+}
+
+func alertIDs(alerts []model.Alert) []string {
+	result := []string{}
+	for _, a := range alerts {
+		result = append(result, a.ID)
+	}
+	return result
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/docker/distribution/reference"
 	dockertypes "github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/tilt/internal/container"
@@ -35,6 +37,10 @@ type BuildResult interface {
 
 type LocalBuildResult struct {
 	id model.TargetID
+
+	// The hash of the target's dependencies when the command ran,
+	// or empty if the command failed.
+	DepsHash string
 }
 
 func (r LocalBuildResult) TargetID() model.TargetID   { return r.id }
@@ -59,6 +65,14 @@ type ImageBuildResult struct {
 	// Often ImageLocalRef and ImageClusterRef will be the same, but may diverge: e.g.
 	// when using KIND + local registry, localRef is localhost:1234/my-img:tilt-abc,
 	// ClusterRef is http://registry/my-img:tilt-abc
+
+	// The digest the registry stored the image under, when we pushed it.
+	// Empty if we didn't push (e.g., we loaded it straight into the cluster).
+	ImagePushedDigest digest.Digest
+
+	// The size of the image, layer by layer, if the Tiltfile asked for it
+	// with update_settings(image_layer_analysis=True).
+	LayerAnalysis *model.ImageLayerAnalysis
 }
 
 func (r ImageBuildResult) TargetID() model.TargetID   { return r.id }
@@ -79,6 +93,16 @@ func NewImageBuildResultSingleRef(id model.TargetID, ref reference.NamedTagged)
 	return NewImageBuildResult(id, ref, ref)
 }
 
+func (r ImageBuildResult) WithPushedDigest(d digest.Digest) ImageBuildResult {
+	r.ImagePushedDigest = d
+	return r
+}
+
+func (r ImageBuildResult) WithLayerAnalysis(a *model.ImageLayerAnalysis) ImageBuildResult {
+	r.LayerAnalysis = a
+	return r
+}
+
 type LiveUpdateBuildResult struct {
 	id model.TargetID
 
@@ -138,7 +162,40 @@ type K8sBuildResult struct {
 	// Hashes of the pod template specs that we deployed to a Kubernetes cluster.
 	PodTemplateSpecHashes []k8s.PodTemplateSpecHash
 
+	// References to the objects we deployed, for refreshing their session heartbeat.
+	DeployedRefs []v1.ObjectReference
+
+	// The UIDs of the StatefulSets we deployed with a partitioned rolling update.
+	PartitionedStatefulSetUIDs []types.UID
+
 	AppliedEntitiesText string
+
+	// The workloads as they were before we first deployed them, for rolling them
+	// back if they don't become healthy (tilt ci --rollback-on-failure).
+	RollbackEntities []k8s.K8sEntity
+
+	// A hash of the fully-rendered entities we applied, so that we can skip
+	// the apply when they haven't changed.
+	AppliedEntitiesHash string
+
+	// True if the rendered entities were the same as the last deploy,
+	// so we didn't apply them.
+	DeploySkipped bool
+}
+
+func (r K8sBuildResult) WithRollbackEntities(entities []k8s.K8sEntity) K8sBuildResult {
+	r.RollbackEntities = entities
+	return r
+}
+
+func (r K8sBuildResult) WithAppliedEntitiesHash(hash string) K8sBuildResult {
+	r.AppliedEntitiesHash = hash
+	return r
+}
+
+func (r K8sBuildResult) WithDeploySkipped(skipped bool) K8sBuildResult {
+	r.DeploySkipped = skipped
+	return r
 }
 
 func (r K8sBuildResult) TargetID() model.TargetID   { return r.id }
@@ -154,7 +211,16 @@ func (r K8sBuildResult) Facets() []model.Facet {
 }
 
 // For kubernetes deploy targets.
-func NewK8sDeployResult(id model.TargetID, uids []types.UID, hashes []k8s.PodTemplateSpecHash, appliedEntities []k8s.K8sEntity) BuildResult {
+func NewK8sDeployResult(id model.TargetID, uids []types.UID, hashes []k8s.PodTemplateSpecHash, appliedEntities []k8s.K8sEntity) K8sBuildResult {
+	refs := make([]v1.ObjectReference, 0, len(appliedEntities))
+	partitioned := []types.UID{}
+	for _, e := range appliedEntities {
+		refs = append(refs, e.ToObjectReference())
+		if k8s.IsPartitionedStatefulSet(e) {
+			partitioned = append(partitioned, e.UID())
+		}
+	}
+
 	// Remove verbose fields from the YAML.
 	for _, e := range appliedEntities {
 		e.Clean()
@@ -166,10 +232,12 @@ func NewK8sDeployResult(id model.TargetID, uids []types.UID, hashes []k8s.PodTem
 	}
 
 	return K8sBuildResult{
-		id:                    id,
-		DeployedUIDs:          uids,
-		PodTemplateSpecHashes: hashes,
-		AppliedEntitiesText:   appliedEntitiesText,
+		id:                         id,
+		DeployedUIDs:               uids,
+		PodTemplateSpecHashes:      hashes,
+		DeployedRefs:               refs,
+		PartitionedStatefulSetUIDs: partitioned,
+		AppliedEntitiesText:        appliedEntitiesText,
 	}
 }
 
@@ -189,6 +257,24 @@ func ClusterImageRefFromBuildResult(r BuildResult) reference.NamedTagged {
 	return nil
 }
 
+// The cluster ref, pinned to the digest we pushed, if we pushed it.
+//
+// A node that already has an image cached under our tag (e.g., from a
+// previous Tilt session) will run it as-is with imagePullPolicy IfNotPresent.
+// A digest can only ever refer to the image we just built.
+func PinnedClusterImageRefFromBuildResult(r BuildResult) (reference.Named, error) {
+	ref := ClusterImageRefFromBuildResult(r)
+	if ref == nil {
+		return nil, nil
+	}
+
+	ibr, ok := r.(ImageBuildResult)
+	if !ok || ibr.ImagePushedDigest == "" {
+		return ref, nil
+	}
+	return reference.WithDigest(ref, ibr.ImagePushedDigest)
+}
+
 type BuildResultSet map[model.TargetID]BuildResult
 
 func (set BuildResultSet) LiveUpdatedContainerIDs() []container.ID {
@@ -213,6 +299,17 @@ func (set BuildResultSet) DeployedUIDSet() UIDSet {
 	return result
 }
 
+func (set BuildResultSet) PartitionedStatefulSetUIDSet() UIDSet {
+	result := NewUIDSet()
+	for _, r := range set {
+		r, ok := r.(K8sBuildResult)
+		if ok {
+			result.Add(r.PartitionedStatefulSetUIDs...)
+		}
+	}
+	return result
+}
+
 func (set BuildResultSet) DeployedPodTemplateSpecHashes() PodTemplateSpecHashSet {
 	result := NewPodTemplateSpecHashSet()
 	for _, r := range set {
@@ -235,6 +332,46 @@ func MergeBuildResultsSet(a, b BuildResultSet) BuildResultSet {
 	return res
 }
 
+// The deps hash of the local target that was built, if any.
+func (set BuildResultSet) DepsHash() string {
+	for _, r := range set {
+		lr, ok := r.(LocalBuildResult)
+		if ok && lr.DepsHash != "" {
+			return lr.DepsHash
+		}
+	}
+	return ""
+}
+
+// True if a k8s target was up-to-date, so we skipped applying it.
+func (set BuildResultSet) DeploySkipped() bool {
+	for _, r := range set {
+		kr, ok := r.(K8sBuildResult)
+		if ok && kr.DeploySkipped {
+			return true
+		}
+	}
+	return false
+}
+
+// The layer analyses of the images that were built, sorted by target.
+func (set BuildResultSet) ImageLayerAnalyses() []model.ImageLayerAnalysis {
+	ids := make([]model.TargetID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	var result []model.ImageLayerAnalysis
+	for _, id := range ids {
+		ir, ok := set[id].(ImageBuildResult)
+		if ok && ir.LayerAnalysis != nil {
+			result = append(result, *ir.LayerAnalysis)
+		}
+	}
+	return result
+}
+
 func (set BuildResultSet) BuildTypes() []model.BuildType {
 	btMap := make(map[model.BuildType]bool, len(set))
 	for _, br := range set {
@@ -288,6 +425,10 @@ type BuildState struct {
 	// The last result.
 	LastResult BuildResult
 
+	// For a K8sTarget, the result of the last deploy that applied its YAML,
+	// which may be older than LastResult (see ManifestState.LastAppliedK8sResult).
+	LastAppliedK8sResult K8sBuildResult
+
 	// Files changed since the last result was build.
 	// This must be liberal: it's ok if this has too many files, but not ok if it has too few.
 	FilesChangedSet map[string]bool
@@ -311,6 +452,15 @@ type BuildState struct {
 	// live_update, and force an image build (even if there are no changed files)
 	FullBuildTriggered bool
 
+	// A newer version of the image's base image is in the registry,
+	// so the build should pull it instead of using the local copy.
+	PullBaseImages bool
+
+	// The last build of the manifest failed before this image got a new result.
+	// It needs a build, so that we never deploy it alongside newer builds
+	// of its siblings.
+	NeedsRebuild bool
+
 	RunningContainers []ContainerInfo
 
 	// If we had an error retrieving running containers
@@ -348,6 +498,21 @@ func (b BuildState) WithFullBuildTriggered(isImageBuildTrigger bool) BuildState
 	return b
 }
 
+func (b BuildState) WithPullBaseImages(pull bool) BuildState {
+	b.PullBaseImages = pull
+	return b
+}
+
+func (b BuildState) WithLastAppliedK8sResult(result K8sBuildResult) BuildState {
+	b.LastAppliedK8sResult = result
+	return b
+}
+
+func (b BuildState) WithNeedsRebuild(needsRebuild bool) BuildState {
+	b.NeedsRebuild = needsRebuild
+	return b
+}
+
 // NOTE(maia): Interim method to replicate old behavior where every
 // BuildState had a single ContainerInfo
 func (b BuildState) OneContainerInfo() ContainerInfo {
@@ -394,7 +559,8 @@ func (b BuildState) NeedsImageBuild() bool {
 	return !lastBuildWasImgBuild ||
 		len(b.FilesChangedSet) > 0 ||
 		len(b.DepsChangedSet) > 0 ||
-		b.FullBuildTriggered
+		b.FullBuildTriggered ||
+		b.NeedsRebuild
 }
 
 type BuildStateSet map[model.TargetID]BuildState
@@ -3,7 +3,9 @@ package store
 import (
 	"testing"
 
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -30,3 +32,22 @@ func TestOneAndOnlyLiveUpdatedContainerID(t *testing.T) {
 	}
 	assert.Equal(t, "cA", string(set.OneAndOnlyLiveUpdatedContainerID()))
 }
+
+func TestPinnedClusterImageRef(t *testing.T) {
+	localRef := container.MustParseNamedTagged("localhost:5000/my-img:tilt-abc")
+	clusterRef := container.MustParseNamedTagged("registry:5000/my-img:tilt-abc")
+	result := NewImageBuildResult(imageID("a"), localRef, clusterRef)
+
+	ref, err := PinnedClusterImageRefFromBuildResult(result)
+	require.NoError(t, err)
+	assert.Equal(t, "registry:5000/my-img:tilt-abc", ref.String())
+
+	dig := digest.Digest("sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1")
+	ref, err = PinnedClusterImageRefFromBuildResult(result.WithPushedDigest(dig))
+	require.NoError(t, err)
+	assert.Equal(t, "registry:5000/my-img:tilt-abc@"+dig.String(), ref.String())
+
+	ref, err = PinnedClusterImageRefFromBuildResult(NewLocalBuildResult(model.TargetID{}))
+	require.NoError(t, err)
+	assert.Nil(t, ref)
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"time"
+)
+
+// The result of the most recent health check of one of a resource's endpoints.
+type EndpointHealth struct {
+	URL string
+
+	// The HTTP status code of the response, or 0 if there was no response.
+	StatusCode int
+
+	// How long the endpoint took to respond.
+	Latency time.Duration
+
+	// Why the request failed, if it did.
+	Error string
+
+	CheckedAt time.Time
+}
+
+// An endpoint is healthy if it responds with anything other than an error status.
+func (h EndpointHealth) Healthy() bool {
+	return h.Error == "" && h.StatusCode > 0 && h.StatusCode < 400
+}
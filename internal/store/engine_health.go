@@ -0,0 +1,48 @@
+package store
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The name of the resource that shows the health of Tilt itself.
+const EngineHealthManifestName = model.ManifestName("(Tilt)")
+
+// How well the engine is keeping up with its own work, as of the watchdog's last check.
+type EngineHealth struct {
+	CheckTime time.Time
+
+	// Actions that have been dispatched, but not reduced yet.
+	ActionQueueDepth int
+
+	// The subscriber that's been handling a change the longest, and for how long.
+	SlowestSubscriber    string
+	SlowestSubscriberLag time.Duration
+
+	// The most views that a web UI connection hasn't acknowledged.
+	WebsocketBacklog int
+
+	// How long the most recent file change waited before the engine handled it.
+	FileEventDelay time.Duration
+
+	// What's wrong, in words, if anything.
+	Problems []string
+}
+
+func (h EngineHealth) Degraded() bool {
+	return len(h.Problems) > 0
+}
+
+// How long a file change waited before the engine handled it.
+type FileEventLatency struct {
+	HandledAt time.Time
+	Delay     time.Duration
+}
+
+// A subscriber that's in the middle of handling a change.
+type BusySubscriber struct {
+	// The subscriber's type, e.g., "*engine.BuildController".
+	Name  string
+	Since time.Time
+}
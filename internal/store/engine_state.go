@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/k8s"
 
@@ -36,6 +37,10 @@ type EngineState struct {
 	EngineMode        EngineMode
 	TerminalMode      TerminalMode
 
+	// In CI mode, record each workload before we apply it, so that we can
+	// roll it back if it doesn't become healthy.
+	RollbackOnFailure bool
+
 	// For synchronizing BuildController -- wait until engine records all builds started
 	// so far before starting another build
 	StartedBuildCount int
@@ -49,6 +54,16 @@ type EngineState struct {
 
 	UpdateSettings model.UpdateSettings
 
+	LocalDNSSettings model.LocalDNSSettings
+
+	HibernateSettings model.HibernateSettings
+
+	EndpointHealthSettings model.EndpointHealthSettings
+
+	BaseImageCheckSettings model.BaseImageCheckSettings
+
+	LogSettings model.LogSettings
+
 	FatalError error
 
 	// The user has indicated they want to exit
@@ -83,12 +98,24 @@ type EngineState struct {
 
 	PendingConfigFileChanges map[string]time.Time
 
+	// How long the most recent file change waited in the action queue.
+	LastFileEventLatency FileEventLatency
+
+	// Tilt's own health, from the watchdog.
+	EngineHealth EngineHealth
+
+	// Git checkouts that are still changing files, keyed by the repo's local path.
+	GitCheckouts map[string]GitCheckout
+
 	TriggerQueue []model.ManifestName
 
 	IsProfiling bool
 
 	TiltfileState ManifestState
 
+	// Where the time went the last time we executed the Tiltfile.
+	TiltfileProfile model.TiltfileProfile
+
 	SuggestedTiltVersion string
 	VersionSettings      model.VersionSettings
 
@@ -98,16 +125,29 @@ type EngineState struct {
 	AnalyticsTiltfileOpt   analytics.Opt // Set by the Tiltfile. Overrides the UserOpt.
 	AnalyticsNudgeSurfaced bool          // this flag is set the first time we show the analytics nudge to the user.
 
+	// Per-category consent set by the Tiltfile. Overrides the AnalyticsTiltfileOpt for those categories.
+	AnalyticsTiltfileConsent tiltanalytics.Consent
+
+	// Warnings collected from around the engine, for the alert center.
+	Alerts AlertStore
+
 	Features map[string]bool
 
 	Secrets model.SecretSet
 
+	SecretSettings model.SecretSettings
+
 	CloudAddress string
 	Token        token.Token
 	TeamID       string
 
+	// Other teams that this session reports its status to, from `set_team()`.
+	AdditionalTeamIDs []string
+
 	CloudStatus CloudStatus
 
+	K8sCredentials K8sCredentialsStatus
+
 	DockerPruneSettings model.DockerPruneSettings
 
 	TelemetrySettings model.TelemetrySettings
@@ -115,6 +155,20 @@ type EngineState struct {
 	MetricsSettings model.MetricsSettings
 
 	UserConfigState model.UserConfigState
+
+	// The environment variables the Tiltfile read, and the last time
+	// `tilt env` changed one of them.
+	TiltfileEnv           model.TiltfileEnv
+	TiltfileEnvChangeTime time.Time
+}
+
+// The status of the credentials from the kubeconfig's exec credential plugin, if any.
+type K8sCredentialsStatus struct {
+	// Whether the cluster is rejecting our credentials.
+	Rejected bool
+
+	// A command the user can run in their own terminal to log in again.
+	ReauthCommand string
 }
 
 type CloudStatus struct {
@@ -122,6 +176,23 @@ type CloudStatus struct {
 	TeamName                         string
 	TokenKnownUnregistered           bool // to distinguish whether an empty Username means "we haven't checked" or "we checked and the token isn't registered"
 	WaitingForStatusPostRegistration bool
+
+	// The registration of the token has expired. The user needs to link it
+	// to their Tilt Cloud account again.
+	TokenExpired bool
+
+	// Whether the user is on each of the teams from `set_team()`,
+	// starting with the primary team.
+	Teams []CloudTeamStatus
+}
+
+type CloudTeamStatus struct {
+	ID   string
+	Name string
+
+	// False if Tilt Cloud doesn't know the team,
+	// or the user isn't a member of it.
+	Associated bool
 }
 
 // Merge analytics opt-in status from different sources.
@@ -341,6 +412,10 @@ type BuildStatus struct {
 	// dependency-tracking in the short-term, without having to switch over to a
 	// full dependency graph in one swoop.
 	PendingDependencyChanges map[model.TargetID]time.Time
+
+	// True if the manifest's last build failed before this image got a new
+	// result, so LastResult may be older than its siblings' results.
+	NeedsRebuild bool
 }
 
 func newBuildStatus() *BuildStatus {
@@ -382,7 +457,12 @@ type ManifestState struct {
 
 	LastSuccessfulDeployTime time.Time
 
-	// The last `BuildHistoryLimit` builds. The most recent build is first in the slice.
+	// The result of the last deploy that applied its YAML. Unlike BuildStatuses,
+	// this survives Tiltfile reloads, so that a reload that renders the same
+	// YAML doesn't re-apply it.
+	LastAppliedK8sResult K8sBuildResult
+
+	// The last `UpdateSettings.BuildHistoryLimit` builds. The most recent build is first in the slice.
 	BuildHistory []model.BuildRecord
 
 	// The container IDs that we've run a LiveUpdate on, if any. Their contents have
@@ -402,6 +482,68 @@ type ManifestState struct {
 
 	// If the build was manually triggered, record why.
 	TriggerReason model.BuildReason
+
+	// Whether the pending file changes came from a git checkout.
+	PendingGitCheckout bool
+
+	// The last time someone connected to one of this manifest's port-forwards.
+	LastPortForwardActivity time.Time
+
+	// The most recent health check of each endpoint, keyed by URL.
+	EndpointHealth map[string]EndpointHealth
+
+	// Well-known paths (e.g., /metrics) that answered on this manifest's
+	// endpoints after it became ready.
+	DiscoveredLinks []model.Link
+
+	// Whether to log the HTTP traffic through this manifest's port forwards.
+	// Set from the web UI.
+	TrafficCapture model.TrafficCaptureMode
+
+	// The number of times the user has asked to run this manifest's CronJobs now.
+	CronJobRunRequests int
+
+	// The most recent load of this manifest's seed data, if it has any.
+	Seed SeedStatus
+
+	// Base images (from FROM lines) that have a newer version in the registry
+	// than the one we last built on, keyed by the image target that uses them.
+	// The next build of those images pulls the new version.
+	StaleBaseImages map[model.TargetID][]string
+}
+
+// A branch switch or other big checkout in a local git repo.
+//
+// Git rewrites lots of files at once, so we hold builds of the resources
+// in the repo until the files stop changing, then rebuild each resource once.
+type GitCheckout struct {
+	Repo      model.LocalGitRepo
+	StartTime time.Time
+}
+
+// Whether any of the manifest's targets are in a repo with a git checkout in progress.
+func (s EngineState) IsInGitCheckout(m model.Manifest) bool {
+	if len(s.GitCheckouts) == 0 {
+		return false
+	}
+	for _, repo := range ManifestGitRepos(m) {
+		if _, ok := s.GitCheckouts[repo.LocalPath]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// The local git repos that the manifest's targets are in.
+func ManifestGitRepos(m model.Manifest) []model.LocalGitRepo {
+	var result []model.LocalGitRepo
+	for _, spec := range m.TargetSpecs() {
+		t, ok := spec.(interface{ LocalRepos() []model.LocalGitRepo })
+		if ok {
+			result = append(result, t.LocalRepos()...)
+		}
+	}
+	return result
 }
 
 func NewState() *EngineState {
@@ -409,12 +551,19 @@ func NewState() *EngineState {
 	ret.LogStore = logstore.NewLogStore()
 	ret.ManifestTargets = make(map[model.ManifestName]*ManifestTarget)
 	ret.PendingConfigFileChanges = make(map[string]time.Time)
+	ret.GitCheckouts = make(map[string]GitCheckout)
 	ret.Secrets = model.SecretSet{}
+	ret.SecretSettings = model.DefaultSecretSettings()
 	ret.DockerPruneSettings = model.DefaultDockerPruneSettings()
 	ret.VersionSettings = model.VersionSettings{
 		CheckUpdates: true,
 	}
 	ret.UpdateSettings = model.DefaultUpdateSettings()
+	ret.LocalDNSSettings = model.DefaultLocalDNSSettings()
+	ret.HibernateSettings = model.DefaultHibernateSettings()
+	ret.EndpointHealthSettings = model.DefaultEndpointHealthSettings()
+	ret.BaseImageCheckSettings = model.DefaultBaseImageCheckSettings()
+	ret.LogSettings = model.DefaultLogSettings()
 	ret.CurrentlyBuilding = make(map[model.ManifestName]bool)
 
 	if ok, _ := tiltanalytics.IsAnalyticsDisabledFromEnv(); ok {
@@ -496,6 +645,11 @@ func (ms *ManifestState) ActiveBuild() model.BuildRecord {
 	return ms.CurrentBuild
 }
 
+// Whether any of the manifest's images should pull their base images on the next build.
+func (ms *ManifestState) HasStaleBaseImages() bool {
+	return len(ms.StaleBaseImages) > 0
+}
+
 func (ms *ManifestState) IsBuilding() bool {
 	return !ms.CurrentBuild.Empty()
 }
@@ -508,10 +662,23 @@ func (ms *ManifestState) LastBuild() model.BuildRecord {
 }
 
 func (ms *ManifestState) AddCompletedBuild(bs model.BuildRecord) {
+	ms.AddCompletedBuildWithLimit(bs, model.DefaultBuildHistoryLimit)
+}
+
+// Adds a completed build, and drops all but the `limit` most recent builds.
+func (ms *ManifestState) AddCompletedBuildWithLimit(bs model.BuildRecord, limit int) {
 	ms.BuildHistory = append([]model.BuildRecord{bs}, ms.BuildHistory...)
-	if len(ms.BuildHistory) > model.BuildHistoryLimit {
-		ms.BuildHistory = ms.BuildHistory[:model.BuildHistoryLimit]
+	if len(ms.BuildHistory) > limit {
+		ms.BuildHistory = ms.BuildHistory[:limit]
+	}
+}
+
+// The most recent builds, which the HUD and web UI show.
+func (ms *ManifestState) RecentBuildHistory() []model.BuildRecord {
+	if len(ms.BuildHistory) > model.BuildHistoryViewLimit {
+		return ms.BuildHistory[:model.BuildHistoryViewLimit]
 	}
+	return ms.BuildHistory
 }
 
 func (ms *ManifestState) StartedFirstBuild() bool {
@@ -549,11 +716,31 @@ func (ms *ManifestState) HasPendingDependencyChanges() bool {
 	return false
 }
 
+// The CronJobs that Tilt deployed for this manifest.
+func (ms *ManifestState) DeployedCronJobs() []v1.ObjectReference {
+	var result []v1.ObjectReference
+	for _, bs := range ms.BuildStatuses {
+		r, ok := bs.LastResult.(K8sBuildResult)
+		if !ok {
+			continue
+		}
+		for _, ref := range r.DeployedRefs {
+			if ref.Kind == k8s.CronJobKind {
+				result = append(result, ref)
+			}
+		}
+	}
+	return result
+}
+
 func (mt *ManifestTarget) NextBuildReason() model.BuildReason {
 	state := mt.State
 	reason := state.TriggerReason
 	if mt.State.HasPendingFileChanges() {
 		reason = reason.With(model.BuildReasonFlagChangedFiles)
+		if mt.State.PendingGitCheckout {
+			reason = reason.With(model.BuildReasonFlagGitCheckout)
+		}
 	}
 	if mt.State.HasPendingDependencyChanges() {
 		reason = reason.With(model.BuildReasonFlagChangedDeps)
@@ -577,7 +764,27 @@ func (mt *ManifestTarget) NextBuildReason() model.BuildReason {
 // bool: whether changes have been made
 // Time: the time of the earliest change
 func (ms *ManifestState) HasPendingChanges() (bool, time.Time) {
-	return ms.HasPendingChangesBeforeOrEqual(time.Now())
+	// Not bounded by time.Now(): change times may come from an injected
+	// clock that runs ahead of the wall clock.
+	ok := false
+	var earliest time.Time
+	visit := func(t time.Time) {
+		if !t.IsZero() && (!ok || t.Before(earliest)) {
+			ok = true
+			earliest = t
+		}
+	}
+
+	visit(ms.PendingManifestChange)
+	for _, status := range ms.BuildStatuses {
+		for _, t := range status.PendingFileChanges {
+			visit(t)
+		}
+		for _, t := range status.PendingDependencyChanges {
+			visit(t)
+		}
+	}
+	return ok, earliest
 }
 
 // Like HasPendingChanges, but relative to a particular time.
@@ -613,7 +820,29 @@ func (ms *ManifestState) HasPendingChangesBeforeOrEqual(highWaterMark time.Time)
 
 var _ model.TargetStatus = &ManifestState{}
 
+// All the links to show on a resource: the ones from the Tiltfile, the
+// endpoints of its services, and any well-known paths we found on them.
 func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
+	seen := make(map[string]bool)
+	add := func(links []model.Link) {
+		for _, l := range links {
+			if seen[l.URL] {
+				continue
+			}
+			seen[l.URL] = true
+			endpoints = append(endpoints, l)
+		}
+	}
+
+	add(mt.Manifest.Links)
+	add(ManifestTargetServiceEndpoints(mt))
+	add(mt.State.DiscoveredLinks)
+	sort.Sort(model.ByURL(endpoints))
+	return endpoints
+}
+
+// The URLs where the resource's services are exposed on this machine.
+func ManifestTargetServiceEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 	defer func() {
 		sort.Sort(model.ByURL(endpoints))
 	}()
@@ -623,9 +852,15 @@ func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 	portForwards := mt.Manifest.K8sTarget().PortForwards
 	if len(portForwards) > 0 {
 		for _, pf := range portForwards {
+			// There's nothing to open in a browser on a UDP port.
+			if pf.IsUDP() {
+				continue
+			}
 			endpoints = append(endpoints, pf.ToLink())
 		}
-		return endpoints
+		if len(endpoints) > 0 {
+			return endpoints
+		}
 	}
 
 	publishedPorts := mt.Manifest.DockerComposeTarget().PublishedPorts()
@@ -647,6 +882,7 @@ func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 func StateToView(s EngineState, mu *sync.RWMutex) view.View {
 	ret := view.View{
 		IsProfiling: s.IsProfiling,
+		Alerts:      s.Alerts.Unacknowledged(),
 	}
 
 	ret.Resources = append(ret.Resources, tiltfileResourceView(s))
@@ -682,7 +918,7 @@ func StateToView(s EngineState, mu *sync.RWMutex) view.View {
 
 		pendingBuildEdits = ospath.FileListDisplayNames(absWatchDirs, pendingBuildEdits)
 
-		buildHistory := append([]model.BuildRecord{}, ms.BuildHistory...)
+		buildHistory := append([]model.BuildRecord{}, ms.RecentBuildHistory()...)
 		for i, build := range buildHistory {
 			build.Edits = ospath.FileListDisplayNames(absWatchDirs, build.Edits)
 			buildHistory[i] = build
@@ -713,12 +949,14 @@ func StateToView(s EngineState, mu *sync.RWMutex) view.View {
 			CrashLog:           ms.CrashLog,
 			Endpoints:          model.LinksToURLs(endpoints), // hud can't handle link names, just send URLs
 			ResourceInfo:       resourceInfoView(mt),
+			ConfigError:        mt.Manifest.ConfigError,
 		}
 
 		ret.Resources = append(ret.Resources, r)
 	}
 
 	ret.LogReader = logstore.NewReader(mu, s.LogStore)
+	ret.LogSettings = s.LogSettings
 	ret.FatalError = s.FatalError
 
 	return ret
@@ -731,7 +969,7 @@ func tiltfileResourceView(s EngineState) view.Resource {
 		Name:         TiltfileManifestName,
 		IsTiltfile:   true,
 		CurrentBuild: s.TiltfileState.CurrentBuild,
-		BuildHistory: s.TiltfileState.BuildHistory,
+		BuildHistory: s.TiltfileState.RecentBuildHistory(),
 		ResourceInfo: view.TiltfileResourceInfo{},
 	}
 	if !s.TiltfileState.CurrentBuild.Empty() {
@@ -775,6 +1013,9 @@ func resourceInfoView(mt *ManifestTarget) view.ResourceInfoView {
 			SpanID:             pod.SpanID,
 			RunStatus:          runStatus,
 			DisplayNames:       mt.Manifest.K8sTarget().DisplayNames,
+
+			LastTerminationReason: pod.LastTerminationReason(),
+			Namespaces:            namespaceStatusViews(state.NamespaceStatuses()),
 		}
 	case LocalRuntimeState:
 		return view.NewLocalResourceInfo(runStatus, state.PID, state.SpanID)
@@ -784,15 +1025,27 @@ func resourceInfoView(mt *ManifestTarget) view.ResourceInfoView {
 	}
 }
 
-// DockerComposeConfigPath returns the path to the docker-compose yaml file of any
+func namespaceStatusViews(statuses []NamespaceRuntimeStatus) []view.NamespaceStatus {
+	var result []view.NamespaceStatus
+	for _, ns := range statuses {
+		result = append(result, view.NamespaceStatus{
+			Namespace: ns.Namespace.String(),
+			PodName:   ns.Pod.PodID.String(),
+			RunStatus: ns.Status,
+		})
+	}
+	return result
+}
+
+// DockerComposeProject returns the docker-compose project of any
 // docker-compose manifests on this EngineState.
 // NOTE(maia): current assumption is only one d-c.yaml per run, so we take the
-// path from the first d-c manifest we see.
-func (s EngineState) DockerComposeConfigPath() []string {
+// project from the first d-c manifest we see.
+func (s EngineState) DockerComposeProject() model.DockerComposeProject {
 	for _, mt := range s.ManifestTargets {
 		if mt.Manifest.IsDC() {
-			return mt.Manifest.DockerComposeTarget().ConfigPaths
+			return mt.Manifest.DockerComposeTarget().Project()
 		}
 	}
-	return []string{}
+	return model.DockerComposeProject{}
 }
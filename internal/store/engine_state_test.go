@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/hud/view"
@@ -73,6 +74,35 @@ func TestStateToViewPortForwards(t *testing.T) {
 		res.Endpoints)
 }
 
+func TestStateToViewLinks(t *testing.T) {
+	m := model.Manifest{
+		Name: "foo",
+		Links: []model.Link{
+			{URL: "http://localhost:9000/swagger", Name: "API docs"},
+			{URL: "http://localhost:8000/", Name: "dupe"},
+		},
+	}.WithDeployTarget(model.K8sTarget{
+		PortForwards: []model.PortForward{
+			{LocalPort: 8000, ContainerPort: 5000},
+		},
+	})
+	state := newState([]model.Manifest{m})
+	state.ManifestTargets[m.Name].State.DiscoveredLinks = []model.Link{
+		{URL: "http://localhost:8000/metrics", Name: "metrics"},
+	}
+
+	mt := state.ManifestTargets[m.Name]
+	assert.Equal(t,
+		[]model.Link{{URL: "http://localhost:8000/"}},
+		ManifestTargetServiceEndpoints(mt))
+
+	v := StateToView(*state, &sync.RWMutex{})
+	res, _ := v.Resource(m.Name)
+	assert.Equal(t,
+		[]string{"http://localhost:8000/", "http://localhost:8000/metrics", "http://localhost:9000/swagger"},
+		res.Endpoints)
+}
+
 func TestRuntimeStateNonWorkload(t *testing.T) {
 	f := tempdir.NewTempDirFixture(t)
 	defer f.TearDown()
@@ -89,6 +119,72 @@ func TestRuntimeStateNonWorkload(t *testing.T) {
 	assert.Equal(t, model.RuntimeStatusOK, runtimeState.RuntimeStatus())
 }
 
+func TestRuntimeStateFannedOutToNamespaces(t *testing.T) {
+	m := model.Manifest{Name: "sancho"}.WithDeployTarget(model.K8sTarget{
+		Name:       "sancho",
+		Namespaces: []string{"tenant-a", "tenant-b"},
+	})
+
+	readyPod := Pod{
+		PodID:      "sancho-a",
+		Namespace:  "tenant-a",
+		Phase:      v1.PodRunning,
+		Containers: []Container{{Name: "sancho", Ready: true}},
+	}
+	runtimeState := NewK8sRuntimeStateWithPods(m, readyPod)
+
+	// Ready in one namespace isn't enough.
+	assert.Equal(t, model.RuntimeStatusPending, runtimeState.RuntimeStatus())
+	statuses := runtimeState.NamespaceStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, k8s.Namespace("tenant-a"), statuses[0].Namespace)
+	assert.Equal(t, k8s.PodID("sancho-a"), statuses[0].Pod.PodID)
+	assert.Equal(t, model.RuntimeStatusOK, statuses[0].Status)
+	assert.Equal(t, k8s.Namespace("tenant-b"), statuses[1].Namespace)
+	assert.Equal(t, k8s.PodID(""), statuses[1].Pod.PodID)
+	assert.Equal(t, model.RuntimeStatusPending, statuses[1].Status)
+
+	runtimeState.Pods["sancho-b"] = &Pod{
+		PodID:      "sancho-b",
+		Namespace:  "tenant-b",
+		Phase:      v1.PodRunning,
+		Containers: []Container{{Name: "sancho", Ready: true}},
+	}
+	assert.Equal(t, model.RuntimeStatusOK, runtimeState.RuntimeStatus())
+
+	runtimeState.Pods["sancho-b"].Phase = v1.PodFailed
+	runtimeState.Pods["sancho-b"].Status = "Error"
+	assert.Equal(t, model.RuntimeStatusError, runtimeState.RuntimeStatus())
+	assert.EqualError(t, runtimeState.RuntimeStatusError(), "Pod sancho-b in namespace tenant-b in error state: Error")
+}
+
+func TestRuntimeStateWorkloadStatus(t *testing.T) {
+	m := model.Manifest{Name: "sancho"}.WithDeployTarget(model.K8sTarget{Name: "sancho"})
+	readyPod := Pod{
+		PodID:      "sancho-a",
+		Phase:      v1.PodRunning,
+		Containers: []Container{{Name: "sancho", Ready: true}},
+	}
+	runtimeState := NewK8sRuntimeStateWithPods(m, readyPod)
+	assert.Equal(t, model.RuntimeStatusOK, runtimeState.RuntimeStatus())
+
+	// A paused canary isn't done, even with every pod ready.
+	ref := v1.ObjectReference{Kind: "Rollout", Name: "sancho"}
+	runtimeState.WorkloadStatuses = []WorkloadStatus{
+		{Ref: ref, Status: model.RuntimeStatusPending, Message: "Paused: CanaryPauseStep"},
+	}
+	assert.Equal(t, model.RuntimeStatusPending, runtimeState.RuntimeStatus())
+
+	runtimeState.WorkloadStatuses = []WorkloadStatus{
+		{Ref: ref, Status: model.RuntimeStatusError, Message: "Degraded: ProgressDeadlineExceeded"},
+	}
+	assert.Equal(t, model.RuntimeStatusError, runtimeState.RuntimeStatus())
+	assert.EqualError(t, runtimeState.RuntimeStatusError(), "Rollout sancho in error state: Degraded: ProgressDeadlineExceeded")
+
+	runtimeState.WorkloadStatuses = []WorkloadStatus{{Ref: ref, Status: model.RuntimeStatusOK}}
+	assert.Equal(t, model.RuntimeStatusOK, runtimeState.RuntimeStatus())
+}
+
 func TestStateToViewUnresourcedYAMLManifest(t *testing.T) {
 	m, err := k8s.NewK8sOnlyManifestFromYAML(testyaml.SanchoYAML)
 	assert.NoError(t, err)
@@ -173,3 +269,20 @@ func TestNextBuildReason(t *testing.T) {
 	assert.Equal(t, "Changed Files | Dependency Updated",
 		mt.NextBuildReason().String())
 }
+
+func TestBuildHistoryLimit(t *testing.T) {
+	ms := newManifestState(model.Manifest{Name: "fe"})
+	for i := 0; i < 5; i++ {
+		ms.AddCompletedBuildWithLimit(model.BuildRecord{
+			StartTime: time.Unix(int64(i), 0),
+		}, 3)
+	}
+
+	require.Len(t, ms.BuildHistory, 3)
+	assert.Equal(t, time.Unix(4, 0), ms.LastBuild().StartTime)
+	assert.Equal(t, time.Unix(2, 0), ms.BuildHistory[2].StartTime)
+
+	recent := ms.RecentBuildHistory()
+	require.Len(t, recent, model.BuildHistoryViewLimit)
+	assert.Equal(t, time.Unix(4, 0), recent[0].StartTime)
+}
@@ -13,4 +13,31 @@ const (
 	HoldBuildingComponent                Hold = "building-component"
 	HoldWaitingForDep                    Hold = "waiting-for-dep"
 	HoldWaitingForDeploy                 Hold = "waiting-for-deploy"
+	HoldGitCheckout                      Hold = "git-checkout"
+	HoldConfigError                      Hold = "config-error"
 )
+
+// A human-readable explanation of the hold.
+func (h Hold) Description() string {
+	switch h {
+	case HoldTiltfileReload:
+		return "Waiting for the Tiltfile to reload"
+	case HoldWaitingForUnparallelizableTarget:
+		return "Waiting for a local resource to finish"
+	case HoldIsUnparallelizableTarget:
+		return "Waiting for other builds to finish, because it's a local resource that can't run in parallel"
+	case HoldWaitingForUncategorized:
+		return "Waiting for uncategorized YAML to deploy"
+	case HoldBuildingComponent:
+		return "Waiting for another resource to finish building a shared image"
+	case HoldWaitingForDep:
+		return "Waiting for resource_deps to become ready"
+	case HoldWaitingForDeploy:
+		return "Waiting for the last deploy to start running, so live_update has a container"
+	case HoldGitCheckout:
+		return "Waiting for a git checkout to finish"
+	case HoldConfigError:
+		return "Waiting for the Tiltfile to fix the resource's config error"
+	}
+	return ""
+}
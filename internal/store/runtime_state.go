@@ -72,10 +72,39 @@ type K8sRuntimeState struct {
 	DeployedUIDSet                 UIDSet                 // for the most recent successful deploy
 	DeployedPodTemplateSpecHashSet PodTemplateSpecHashSet // for the most recent successful deploy
 
+	// StatefulSets with a partitioned rolling update, for the most recent successful deploy.
+	PartitionedStatefulSetUIDSet UIDSet
+
 	LastReadyOrSucceededTime    time.Time
 	HasEverDeployedSuccessfully bool
 
 	PodReadinessMode model.PodReadinessMode
+
+	// If non-empty, the resource is deployed to each of these namespaces,
+	// and it's only ready once it's ready in all of them.
+	Namespaces []string
+
+	// What workloads with pod templates, like Argo Rollouts, say about their own
+	// rollout since the most recent deploy. Their pods don't tell the whole story.
+	WorkloadStatuses []WorkloadStatus
+}
+
+// The status that a workload with pod templates reports about its own rollout.
+// See k8s.WorkloadRuntimeStatus.
+type WorkloadStatus struct {
+	Ref     v1.ObjectReference
+	Status  model.RuntimeStatus
+	Message string
+}
+
+// The rollout in one namespace of a resource deployed to several.
+type NamespaceRuntimeStatus struct {
+	Namespace k8s.Namespace
+
+	// The most recent pod in the namespace. Empty if there isn't one yet.
+	Pod Pod
+
+	Status model.RuntimeStatus
 }
 
 func (K8sRuntimeState) RuntimeState() {}
@@ -95,10 +124,12 @@ func NewK8sRuntimeStateWithPods(m model.Manifest, pods ...Pod) K8sRuntimeState {
 func NewK8sRuntimeState(m model.Manifest) K8sRuntimeState {
 	return K8sRuntimeState{
 		PodReadinessMode:               m.PodReadinessMode(),
+		Namespaces:                     m.K8sTarget().Namespaces,
 		Pods:                           make(map[k8s.PodID]*Pod),
 		LBs:                            make(map[k8s.ServiceName]*url.URL),
 		DeployedUIDSet:                 NewUIDSet(),
 		DeployedPodTemplateSpecHashSet: NewPodTemplateSpecHashSet(),
+		PartitionedStatefulSetUIDSet:   NewUIDSet(),
 	}
 }
 
@@ -107,6 +138,16 @@ func (s K8sRuntimeState) RuntimeStatusError() error {
 	if status != model.RuntimeStatusError {
 		return nil
 	}
+	if ws, ok := s.unfinishedWorkload(); ok && ws.Status == model.RuntimeStatusError {
+		return fmt.Errorf("%s %s in error state: %s", ws.Ref.Kind, ws.Ref.Name, ws.Message)
+	}
+	if len(s.Namespaces) > 0 {
+		for _, ns := range s.NamespaceStatuses() {
+			if ns.Status == model.RuntimeStatusError {
+				return fmt.Errorf("Pod %s in namespace %s in error state: %s", ns.Pod.PodID, ns.Namespace, ns.Pod.Status)
+			}
+		}
+	}
 	pod := s.MostRecentPod()
 	return fmt.Errorf("Pod %s in error state: %s", pod.PodID, pod.Status)
 }
@@ -120,8 +161,88 @@ func (s K8sRuntimeState) RuntimeStatus() model.RuntimeStatus {
 		return model.RuntimeStatusOK
 	}
 
-	pod := s.MostRecentPod()
+	// A workload that says its rollout isn't done wins over its pods.
+	if ws, ok := s.unfinishedWorkload(); ok {
+		return ws.Status
+	}
+
+	if len(s.Namespaces) > 0 {
+		// Any error wins, then anything still rolling out.
+		result := model.RuntimeStatusOK
+		for _, ns := range s.NamespaceStatuses() {
+			if ns.Status == model.RuntimeStatusError {
+				return model.RuntimeStatusError
+			}
+			if ns.Status != model.RuntimeStatusOK {
+				result = ns.Status
+			}
+		}
+		return result
+	}
 
+	return podRuntimeStatus(s.MostRecentPod())
+}
+
+// The first workload that reports an error, or else the first one
+// that reports its rollout is still in progress.
+func (s K8sRuntimeState) unfinishedWorkload() (WorkloadStatus, bool) {
+	var pending WorkloadStatus
+	hasPending := false
+	for _, ws := range s.WorkloadStatuses {
+		switch ws.Status {
+		case model.RuntimeStatusError:
+			return ws, true
+		case model.RuntimeStatusPending:
+			if !hasPending {
+				pending = ws
+				hasPending = true
+			}
+		}
+	}
+	return pending, hasPending
+}
+
+// The status of the rollout in each namespace, in the order the Tiltfile
+// listed them. Empty if the resource isn't deployed to several namespaces.
+func (s K8sRuntimeState) NamespaceStatuses() []NamespaceRuntimeStatus {
+	result := make([]NamespaceRuntimeStatus, 0, len(s.Namespaces))
+	for _, ns := range s.Namespaces {
+		pod := s.mostRecentPodInNamespace(k8s.Namespace(ns))
+		status := model.RuntimeStatusPending
+		switch {
+		case !s.HasEverDeployedSuccessfully:
+		case s.PodReadinessMode == model.PodReadinessIgnore:
+			status = model.RuntimeStatusOK
+		case pod.PodID != "":
+			status = podRuntimeStatus(pod)
+		}
+		result = append(result, NamespaceRuntimeStatus{
+			Namespace: k8s.Namespace(ns),
+			Pod:       pod,
+			Status:    status,
+		})
+	}
+	return result
+}
+
+func (s K8sRuntimeState) mostRecentPodInNamespace(ns k8s.Namespace) Pod {
+	bestPod := Pod{}
+	found := false
+
+	for _, v := range s.Pods {
+		if v.Namespace != ns {
+			continue
+		}
+		if !found || v.isAfter(bestPod) {
+			bestPod = *v
+			found = true
+		}
+	}
+
+	return bestPod
+}
+
+func podRuntimeStatus(pod Pod) model.RuntimeStatus {
 	switch pod.Phase {
 	case v1.PodRunning:
 		if pod.AllContainersReady() {
@@ -191,6 +312,14 @@ func (s K8sRuntimeState) MostRecentPod() Pod {
 }
 
 func (s K8sRuntimeState) HasOKPodTemplateSpecHash(pod *v1.Pod) bool {
+	// Pods below the partition of a partitioned StatefulSet keep running
+	// the old pod template on purpose, so they're still part of the current deploy.
+	for _, ref := range pod.OwnerReferences {
+		if s.PartitionedStatefulSetUIDSet.Contains(ref.UID) {
+			return true
+		}
+	}
+
 	// if it doesn't have a label, just let it through - maybe it's from a CRD w/ no pod template spec
 	hash, ok := pod.Labels[k8s.TiltPodTemplateHashLabel]
 	if !ok {
@@ -248,12 +377,24 @@ type Container struct {
 	ImageRef   reference.Named
 	Restarts   int
 	Status     model.RuntimeStatus
+
+	// Why the container last stopped (e.g., "OOMKilled" or "Error"), and its
+	// exit code. Empty if it has never stopped.
+	LastTerminationReason   string
+	LastTerminationExitCode int32
 }
 
 func (c Container) Empty() bool {
 	return c.Name == "" && c.ID == ""
 }
 
+// Whether the container last stopped because it ran out of memory.
+func (c Container) OOMKilled() bool {
+	return c.LastTerminationReason == ContainerReasonOOMKilled
+}
+
+const ContainerReasonOOMKilled = "OOMKilled"
+
 func (p Pod) Empty() bool {
 	return p.PodID == ""
 }
@@ -293,6 +434,21 @@ func (p Pod) VisibleContainerRestarts() int {
 	return p.AllContainerRestarts() - p.BaselineRestarts
 }
 
+// Why a container in this pod last stopped. Prefers OOMKilled,
+// because that's the one that's hard to diagnose from the logs.
+func (p Pod) LastTerminationReason() string {
+	result := ""
+	for _, c := range p.Containers {
+		if c.OOMKilled() {
+			return c.LastTerminationReason
+		}
+		if result == "" {
+			result = c.LastTerminationReason
+		}
+	}
+	return result
+}
+
 func (p Pod) AllContainerRestarts() int {
 	result := 0
 	for _, c := range p.Containers {
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+type SeedState string
+
+const (
+	SeedStateRunning SeedState = "running"
+	SeedStateDone    SeedState = "done"
+	SeedStateError   SeedState = "error"
+)
+
+// The most recent load of a resource's seed data (see k8s_resource(seed=...)).
+type SeedStatus struct {
+	State SeedState
+
+	// The pod that Tilt loaded the seed data into.
+	PodID k8s.PodID
+
+	StartTime  time.Time
+	FinishTime time.Time
+
+	// Why the load failed, if it did.
+	Error string
+}
+
+func (s SeedStatus) Empty() bool {
+	return s.State == ""
+}
@@ -98,6 +98,16 @@ func (s *Store) NotifySubscribers(ctx context.Context) {
 	s.subscribers.NotifyAll(ctx, s)
 }
 
+// The number of actions waiting to be reduced.
+func (s *Store) ActionQueueDepth() int {
+	return s.actionQueue.len()
+}
+
+// The subscribers that are handling a change right now, busiest first.
+func (s *Store) BusySubscribers() []BusySubscriber {
+	return s.subscribers.Busy()
+}
+
 // TODO(nick): Clone the state to ensure it's not mutated.
 // For now, we use RW locks to simulate the same behavior, but the
 // onus is on the caller to RUnlockState.
@@ -231,6 +241,12 @@ func (q *actionQueue) add(action Action) {
 	q.actions = append(q.actions, action)
 }
 
+func (q *actionQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.actions)
+}
+
 func (q *actionQueue) drain() []Action {
 	q.mu.Lock()
 	defer q.mu.Unlock()
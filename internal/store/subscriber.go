@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 // A subscriber is notified whenever the state changes.
@@ -91,6 +93,23 @@ func (l *subscriberList) TeardownAll(ctx context.Context) {
 	}
 }
 
+// The subscribers that are handling a change right now, busiest first.
+func (l *subscriberList) Busy() []BusySubscriber {
+	l.mu.Lock()
+	subscribers := append([]*subscriberEntry{}, l.subscribers...)
+	l.mu.Unlock()
+
+	var result []BusySubscriber
+	for _, s := range subscribers {
+		since, ok := s.activeSince()
+		if ok {
+			result = append(result, BusySubscriber{Name: fmt.Sprintf("%T", s.subscriber), Since: since})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Since.Before(result[j].Since) })
+	return result
+}
+
 func (l *subscriberList) NotifyAll(ctx context.Context, store *Store) {
 	l.mu.Lock()
 	subscribers := append([]*subscriberEntry{}, l.subscribers...)
@@ -115,6 +134,9 @@ type subscriberEntry struct {
 	hasPending bool
 	hasActive  bool
 
+	// When the active goroutine started notifying the subscriber.
+	activeStart time.Time
+
 	// The active mutex is held by the goroutine currently notifying the
 	// subscriber. It may be held for a long time if the subscriber
 	// takes a long time.
@@ -144,6 +166,14 @@ func (e *subscriberEntry) movePendingToActive() {
 
 	e.hasPending = false
 	e.hasActive = true
+	e.activeStart = time.Now()
+}
+
+func (e *subscriberEntry) activeSince() (time.Time, bool) {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	return e.activeStart, e.hasActive
 }
 
 func (e *subscriberEntry) clearActive() {
@@ -42,6 +42,25 @@ func TestSubscriberInterleavedCalls(t *testing.T) {
 	}
 }
 
+func TestBusySubscribers(t *testing.T) {
+	st, _ := NewStoreWithFakeReducer()
+	ctx := context.Background()
+	s := newFakeSubscriber()
+	st.AddSubscriber(ctx, s)
+	assert.Empty(t, st.BusySubscribers())
+
+	st.NotifySubscribers(ctx)
+	call := <-s.onChange
+	busy := st.BusySubscribers()
+	if assert.Len(t, busy, 1) {
+		assert.Equal(t, "*store.fakeSubscriber", busy[0].Name)
+		assert.False(t, busy[0].Since.IsZero())
+	}
+
+	close(call.done)
+	assert.Eventually(t, func() bool { return len(st.BusySubscribers()) == 0 }, time.Second, time.Millisecond)
+}
+
 func TestAddSubscriberToAlreadySetUpListCallsSetUp(t *testing.T) {
 	st, _ := NewStoreWithFakeReducer()
 	ctx := context.Background()
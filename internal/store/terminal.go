@@ -19,4 +19,22 @@ const (
 	// Tilt waits on a prompt to decide what mode
 	// to be in.
 	TerminalModePrompt
+
+	// Like TerminalModeStream, but also prints a summary of
+	// resource status whenever it changes. We fall back to this
+	// where the termbox UI doesn't work.
+	TerminalModeRichStream
+
+	// Logs and status changes are written to stdout as
+	// line-delimited JSON, for other programs to read.
+	TerminalModeJSON
 )
+
+// The termbox UI misbehaves on Windows consoles (redraw artifacts,
+// no resize events, broken colors), so we fall back to a rich stream there.
+func TerminalModeForOS(mode TerminalMode, goos string) TerminalMode {
+	if mode == TerminalModeHUD && goos == "windows" {
+		return TerminalModeRichStream
+	}
+	return mode
+}
@@ -0,0 +1,15 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalModeForOS(t *testing.T) {
+	assert.Equal(t, TerminalModeHUD, TerminalModeForOS(TerminalModeHUD, "linux"))
+	assert.Equal(t, TerminalModeHUD, TerminalModeForOS(TerminalModeHUD, "darwin"))
+	assert.Equal(t, TerminalModeRichStream, TerminalModeForOS(TerminalModeHUD, "windows"))
+	assert.Equal(t, TerminalModeStream, TerminalModeForOS(TerminalModeStream, "windows"))
+	assert.Equal(t, TerminalModePrompt, TerminalModeForOS(TerminalModePrompt, "windows"))
+}
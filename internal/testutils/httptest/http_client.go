@@ -34,6 +34,11 @@ func (fc *FakeClient) SetResponse(s string) {
 	fc.responseBody = s
 }
 
+func (fc *FakeClient) SetResponseWithCode(code int, s string) {
+	fc.responseCode = code
+	fc.responseBody = s
+}
+
 func (fc *FakeClient) ClearRequests() {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
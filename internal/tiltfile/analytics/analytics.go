@@ -1,15 +1,19 @@
 package analytics
 
 import (
+	"fmt"
+
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 	"go.starlark.net/starlark"
 
+	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 )
 
 type Settings struct {
 	Opt                analytics.Opt
+	Consent            tiltanalytics.Consent
 	CustomTagsToReport map[string]string
 }
 
@@ -23,6 +27,7 @@ func NewExtension() Extension {
 func (e Extension) NewState() interface{} {
 	return Settings{
 		Opt:                analytics.OptDefault,
+		Consent:            tiltanalytics.Consent{},
 		CustomTagsToReport: make(map[string]string),
 	}
 }
@@ -45,17 +50,51 @@ func (Extension) OnStart(env *starkit.Environment) error {
 }
 
 func setAnalyticsSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var enable bool
+	var enable, usage, performance, errors starlark.Value
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
-		"enable", &enable); err != nil {
+		"enable?", &enable,
+		"usage?", &usage,
+		"performance?", &performance,
+		"errors?", &errors); err != nil {
 		return nil, err
 	}
 
-	err := starkit.SetState(thread, func(settings Settings) Settings {
-		if enable {
+	enableOpt, err := unpackCategoryOpt(fn, "enable", enable)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryValues := map[tiltanalytics.Category]starlark.Value{
+		tiltanalytics.CategoryUsage:       usage,
+		tiltanalytics.CategoryPerformance: performance,
+		tiltanalytics.CategoryErrors:      errors,
+	}
+	consent := tiltanalytics.Consent{}
+	for cat, v := range categoryValues {
+		opt, err := unpackCategoryOpt(fn, string(cat), v)
+		if err != nil {
+			return nil, err
+		}
+		if opt != tiltanalytics.CategoryOptDefault {
+			consent[cat] = opt
+		}
+	}
+
+	err = starkit.SetState(thread, func(settings Settings) Settings {
+		switch enableOpt {
+		case tiltanalytics.CategoryOptIn:
 			settings.Opt = analytics.OptIn
-		} else {
+		case tiltanalytics.CategoryOptOut:
 			settings.Opt = analytics.OptOut
+		case tiltanalytics.CategoryOptLocal:
+			// Local-only analytics applies to every category
+			// that isn't set explicitly.
+			settings.Consent = tiltanalytics.LocalOnlyConsent()
+		}
+
+		settings.Consent = settings.Consent.Copy()
+		for cat, opt := range consent {
+			settings.Consent[cat] = opt
 		}
 		return settings
 	})
@@ -63,6 +102,24 @@ func setAnalyticsSettings(thread *starlark.Thread, fn *starlark.Builtin, args st
 	return starlark.None, err
 }
 
+// Each analytics setting can be True (send), False (don't send), or "local" (record locally, but don't send).
+func unpackCategoryOpt(fn *starlark.Builtin, arg string, v starlark.Value) (tiltanalytics.CategoryOpt, error) {
+	switch v := v.(type) {
+	case nil, starlark.NoneType:
+		return tiltanalytics.CategoryOptDefault, nil
+	case starlark.Bool:
+		if v {
+			return tiltanalytics.CategoryOptIn, nil
+		}
+		return tiltanalytics.CategoryOptOut, nil
+	case starlark.String:
+		if v.GoString() == string(tiltanalytics.CategoryOptLocal) {
+			return tiltanalytics.CategoryOptLocal, nil
+		}
+	}
+	return tiltanalytics.CategoryOptDefault, fmt.Errorf("%s: for parameter %q, expected True, False, or \"local\", got %s", fn.Name(), arg, v.String())
+}
+
 func reportCustomTags(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var tags value.StringStringMap
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "tags", &tags); err != nil {
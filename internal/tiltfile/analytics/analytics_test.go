@@ -7,6 +7,7 @@ import (
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
+	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 )
 
@@ -54,3 +55,53 @@ experimental_analytics_report({'1': '2a', '3': '4'})
 func NewFixture(tb testing.TB) *starkit.Fixture {
 	return starkit.NewFixture(tb, NewExtension())
 }
+
+func TestEnableLocal(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+analytics_settings(enable='local')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.Equal(t, analytics.OptDefault, MustState(result).Opt)
+	assert.Equal(t, tiltanalytics.LocalOnlyConsent(), MustState(result).Consent)
+}
+
+func TestPerCategoryConsent(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+analytics_settings(enable=True, performance=False, errors='local')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.Equal(t, analytics.OptIn, MustState(result).Opt)
+	assert.Equal(t, tiltanalytics.Consent{
+		tiltanalytics.CategoryPerformance: tiltanalytics.CategoryOptOut,
+		tiltanalytics.CategoryErrors:      tiltanalytics.CategoryOptLocal,
+	}, MustState(result).Consent)
+}
+
+func TestLocalWithCategoryOverride(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+analytics_settings(enable='local', errors=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.Equal(t, tiltanalytics.Consent{
+		tiltanalytics.CategoryUsage:       tiltanalytics.CategoryOptLocal,
+		tiltanalytics.CategoryPerformance: tiltanalytics.CategoryOptLocal,
+		tiltanalytics.CategoryErrors:      tiltanalytics.CategoryOptIn,
+	}, MustState(result).Consent)
+}
+
+func TestInvalidConsent(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+analytics_settings(usage='sometimes')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `for parameter "usage", expected True, False, or "local", got "sometimes"`)
+	}
+}
@@ -0,0 +1,69 @@
+package baseimage
+
+import (
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Checks can't run more often than this, so that Tilt doesn't
+// run into registry rate limits.
+const minInterval = time.Minute
+
+// Implements the base_image_checks() builtin, which periodically checks
+// whether the base images in Dockerfile FROM lines have been updated.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.DefaultBaseImageCheckSettings()
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("base_image_checks", setBaseImageCheckSettings)
+}
+
+func setBaseImageCheckSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings model.BaseImageCheckSettings) (model.BaseImageCheckSettings, error) {
+		interval := settings.Interval.String()
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"enabled?", &settings.Enabled,
+			"interval?", &interval)
+		if err != nil {
+			return model.BaseImageCheckSettings{}, err
+		}
+
+		settings.Interval, err = time.ParseDuration(interval)
+		if err != nil {
+			return model.BaseImageCheckSettings{}, fmt.Errorf("%s: invalid interval %q: %v", fn.Name(), interval, err)
+		}
+		if settings.Interval < minInterval {
+			return model.BaseImageCheckSettings{}, fmt.Errorf("%s: interval must be at least %s (got: %s)", fn.Name(), minInterval, settings.Interval)
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.BaseImageCheckSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.BaseImageCheckSettings, error) {
+	var state model.BaseImageCheckSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,58 @@
+package baseimage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBaseImageChecksDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultBaseImageCheckSettings(), MustState(result))
+}
+
+func TestBaseImageChecksDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "base_image_checks(enabled=False)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).Enabled)
+}
+
+func TestBaseImageChecksInterval(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "base_image_checks(interval='6h')")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Enabled)
+	assert.Equal(t, 6*time.Hour, MustState(result).Interval)
+}
+
+func TestBaseImageChecksIntervalTooShort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "base_image_checks(interval='10s')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "interval must be at least 1m0s")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
@@ -67,6 +67,16 @@ func (idx *buildIndex) findBuilderByID(id model.TargetID) *dockerImage {
 	return idx.byTargetID[id]
 }
 
+// Looks up the build for an image, without marking it as consumed.
+func (idx *buildIndex) findBuilderByRef(ref reference.Named) *dockerImage {
+	for _, image := range idx.images {
+		if image.configurationRef.Matches(ref) {
+			return image
+		}
+	}
+	return nil
+}
+
 // Many things can consume image builds:
 // - k8s yaml
 // - docker-compose yaml
@@ -0,0 +1,52 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	priorityLowN    = "PRIORITY_LOW"
+	priorityNormalN = "PRIORITY_NORMAL"
+	priorityHighN   = "PRIORITY_HIGH"
+)
+
+// The starlark values for model.BuildPriority.
+//
+// The zero value is "normal", so resources that don't set a priority
+// keep the default.
+type buildPriority model.BuildPriority
+
+func (p buildPriority) String() string {
+	switch model.BuildPriority(p) {
+	case model.BuildPriorityLow:
+		return priorityLowN
+	case model.BuildPriorityNormal:
+		return priorityNormalN
+	case model.BuildPriorityHigh:
+		return priorityHighN
+	default:
+		return fmt.Sprintf("unknown priority with value %d", p)
+	}
+}
+
+func (p buildPriority) Type() string {
+	return "BuildPriority"
+}
+
+func (p buildPriority) Freeze() {
+	// noop
+}
+
+func (p buildPriority) Truth() starlark.Bool {
+	return starlark.True
+}
+
+func (p buildPriority) Hash() (uint32, error) {
+	return starlark.MakeInt(int(p)).Hash()
+}
+
+var _ starlark.Value = buildPriority(0)
@@ -0,0 +1,102 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements the cluster() builtin, which describes the local cluster
+// that `tilt cluster create` sets up.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.ClusterSpec{}
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("cluster", setClusterSpec)
+}
+
+func setClusterSpec(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var product string
+	spec := model.ClusterSpec{
+		Name:         model.DefaultClusterName,
+		Nodes:        1,
+		RegistryPort: model.DefaultClusterRegistryPort,
+	}
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"product", &product,
+		"name?", &spec.Name,
+		"nodes?", &spec.Nodes,
+		"registry?", &spec.Registry,
+		"registry_port?", &spec.RegistryPort,
+		"k8s_version?", &spec.KubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Product = model.ClusterProduct(strings.ToLower(product))
+	if !isValidProduct(spec.Product) {
+		return nil, fmt.Errorf("%s: unknown product %q. Must be one of: %s",
+			fn.Name(), product, productList())
+	}
+	if spec.Name == "" {
+		return nil, fmt.Errorf("%s: name cannot be empty", fn.Name())
+	}
+	if spec.Nodes < 1 {
+		return nil, fmt.Errorf("%s: nodes must be at least 1 (got: %d)", fn.Name(), spec.Nodes)
+	}
+	if spec.RegistryPort <= 0 || spec.RegistryPort > 65535 {
+		return nil, fmt.Errorf("%s: registry_port must be between 1 and 65535 (got: %d)", fn.Name(), spec.RegistryPort)
+	}
+
+	err = starkit.SetState(thread, func(existing model.ClusterSpec) (model.ClusterSpec, error) {
+		if !existing.Empty() {
+			return existing, fmt.Errorf("%s: can only be called once", fn.Name())
+		}
+		return spec, nil
+	})
+	return starlark.None, err
+}
+
+func isValidProduct(p model.ClusterProduct) bool {
+	for _, valid := range model.AllClusterProducts {
+		if p == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func productList() string {
+	result := make([]string, 0, len(model.AllClusterProducts))
+	for _, p := range model.AllClusterProducts {
+		result = append(result, string(p))
+	}
+	return strings.Join(result, ", ")
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.ClusterSpec {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.ClusterSpec, error) {
+	var state model.ClusterSpec
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestClusterDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Empty())
+}
+
+func TestClusterDefaults(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "cluster('kind')")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.ClusterSpec{
+		Product:      model.ClusterProductKIND,
+		Name:         "tilt",
+		Nodes:        1,
+		RegistryPort: 5000,
+	}, MustState(result))
+	assert.Equal(t, "kind-tilt", MustState(result).KubeContext())
+}
+
+func TestClusterAllArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", `
+cluster('K3D', name='dev', nodes=3, registry=True, registry_port=5001, k8s_version='v1.21.1')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.ClusterSpec{
+		Product:           model.ClusterProductK3D,
+		Name:              "dev",
+		Nodes:             3,
+		Registry:          true,
+		RegistryPort:      5001,
+		KubernetesVersion: "v1.21.1",
+	}, MustState(result))
+}
+
+func TestClusterUnknownProduct(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "cluster('gke')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown product "gke". Must be one of: kind, k3d, minikube`)
+	}
+}
+
+func TestClusterBadNodes(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "cluster('kind', nodes=0)")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "nodes must be at least 1")
+	}
+}
+
+func TestClusterCalledTwice(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", `
+cluster('kind')
+cluster('minikube')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can only be called once")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
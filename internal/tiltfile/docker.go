@@ -8,9 +8,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/builder/dockerignore"
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
@@ -40,6 +42,9 @@ type dockerImage struct {
 	extraTags        []string // Extra tags added at build-time.
 	cacheFrom        []string
 	pullParent       bool
+	buildOutput      model.BuildOutputVerbosity
+	buildOn          model.BuildLocation
+	registryCache    bool
 
 	// Overrides the container args. Used as an escape hatch in case people want the old entrypoint behavior.
 	// See discussion here:
@@ -63,6 +68,9 @@ type dockerImage struct {
 	disablePush       bool
 	skipsLocalDocker  bool
 	outputsImageRefTo string
+	sandbox           string
+	outputsManifest   string
+	outputRefs        []string
 
 	liveUpdate model.LiveUpdate
 }
@@ -92,19 +100,20 @@ func (d *dockerImage) Type() dockerImageBuildType {
 }
 
 func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var dockerRef, targetStage string
+	var dockerRef, targetStage, buildOutputVal, buildOnVal string
 	var contextVal,
 		dockerfilePathVal,
 		dockerfileContentsVal,
 		cacheVal,
 		liveUpdateVal,
+		fallBackOnChangeCountVal,
 		ignoreVal,
 		onlyVal,
 		entrypoint starlark.Value
 	var buildArgs value.StringStringMap
 	var network value.Stringable
 	var ssh, secret, extraTags, cacheFrom value.StringOrStringList
-	var matchInEnvVars, pullParent bool
+	var matchInEnvVars, pullParent, registryCache bool
 	var containerArgsVal starlark.Sequence
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
@@ -114,6 +123,7 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"dockerfile_contents?", &dockerfileContentsVal,
 		"cache?", &cacheVal,
 		"live_update?", &liveUpdateVal,
+		"fall_back_on_change_count?", &fallBackOnChangeCountVal,
 		"match_in_env_vars?", &matchInEnvVars,
 		"ignore?", &ignoreVal,
 		"only?", &onlyVal,
@@ -126,6 +136,9 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"extra_tag?", &extraTags,
 		"cache_from?", &cacheFrom,
 		"pull?", &pullParent,
+		"build_output?", &buildOutputVal,
+		"build_on?", &buildOnVal,
+		"registry_cache?", &registryCache,
 	); err != nil {
 		return nil, err
 	}
@@ -184,6 +197,10 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	if err != nil {
 		return nil, errors.Wrap(err, "live_update")
 	}
+	liveUpdate, err = liveUpdateWithFallBackOnChangeCount(liveUpdate, fallBackOnChangeCountVal)
+	if err != nil {
+		return nil, err
+	}
 
 	ignores, err := parseValuesToStrings(ignoreVal, "ignore")
 	if err != nil {
@@ -216,6 +233,28 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		}
 	}
 
+	var buildOutput model.BuildOutputVerbosity
+	if buildOutputVal != "" {
+		buildOutput, err = model.ParseBuildOutputVerbosity(buildOutputVal)
+		if err != nil {
+			return nil, fmt.Errorf("Argument build_output: %v", err)
+		}
+	}
+
+	var buildOn model.BuildLocation
+	if buildOnVal != "" {
+		buildOn, err = model.ParseBuildLocation(buildOnVal)
+		if err != nil {
+			return nil, fmt.Errorf("Argument build_on: %v", err)
+		}
+	}
+	if buildOn == model.BuildLocationCluster && (len(ssh.Values) > 0 || len(secret.Values) > 0) {
+		return nil, fmt.Errorf("Argument build_on: %q doesn't support ssh or secret", buildOn)
+	}
+	if buildOn == model.BuildLocationCluster && registryCache {
+		return nil, fmt.Errorf("Argument build_on: %q doesn't support registry_cache", buildOn)
+	}
+
 	r := &dockerImage{
 		workDir:          starkit.CurrentExecPath(thread),
 		dbDockerfilePath: dockerfilePath,
@@ -236,6 +275,9 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		extraTags:        extraTags.Values,
 		cacheFrom:        cacheFrom.Values,
 		pullParent:       pullParent,
+		buildOutput:      buildOutput,
+		buildOn:          buildOn,
+		registryCache:    registryCache,
 	}
 	err = s.buildIndex.addImage(r)
 	if err != nil {
@@ -271,12 +313,15 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 	var deps *starlark.List
 	var tag string
 	var disablePush bool
-	var liveUpdateVal, ignoreVal starlark.Value
+	var liveUpdateVal, fallBackOnChangeCountVal, ignoreVal starlark.Value
 	var matchInEnvVars bool
 	var entrypoint starlark.Value
 	var containerArgsVal starlark.Sequence
 	var skipsLocalDocker bool
+	var sandbox string
+	var outputs value.StringOrStringList
 	outputsImageRefTo := value.NewLocalPathUnpacker(thread)
+	outputsManifest := value.NewLocalPathUnpacker(thread)
 
 	err := s.unpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
@@ -286,12 +331,16 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		"disable_push?", &disablePush,
 		"skips_local_docker?", &skipsLocalDocker,
 		"live_update?", &liveUpdateVal,
+		"fall_back_on_change_count?", &fallBackOnChangeCountVal,
 		"match_in_env_vars?", &matchInEnvVars,
 		"ignore?", &ignoreVal,
 		"entrypoint?", &entrypoint,
 		"container_args?", &containerArgsVal,
 		"command_bat_val", &commandBatVal,
 		"outputs_image_ref_to", &outputsImageRefTo,
+		"sandbox?", &sandbox,
+		"outputs?", &outputs,
+		"outputs_manifest?", &outputsManifest,
 	)
 	if err != nil {
 		return nil, err
@@ -302,6 +351,18 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", dockerRef, err)
 	}
 
+	outputRefs, err := customBuildOutputRefs(ref, outputs.Values, outputsManifest.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if sandbox != "" {
+		_, err := container.ParseNamed(sandbox)
+		if err != nil {
+			return nil, fmt.Errorf("Argument 'sandbox': can't parse image %q: %v", sandbox, err)
+		}
+	}
+
 	if deps == nil || deps.Len() == 0 {
 		return nil, fmt.Errorf("Argument 3 (deps) can't be empty")
 	}
@@ -322,6 +383,10 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 	if err != nil {
 		return nil, errors.Wrap(err, "live_update")
 	}
+	liveUpdate, err = liveUpdateWithFallBackOnChangeCount(liveUpdate, fallBackOnChangeCountVal)
+	if err != nil {
+		return nil, err
+	}
 
 	ignores, err := parseValuesToStrings(ignoreVal, "ignore")
 	if err != nil {
@@ -342,7 +407,13 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		containerArgs = model.OverrideArgs{ShouldOverride: true, Args: args}
 	}
 
-	command, err := value.ValueGroupToCmdHelper(commandVal, commandBatVal)
+	var command model.Cmd
+	if sandbox != "" {
+		// Sandboxes are Linux containers, whatever the host OS.
+		command, err = value.ValueToUnixCmd(commandVal)
+	} else {
+		command, err = value.ValueGroupToCmdHelper(commandVal, commandBatVal)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("Argument 2 (command): %v", err)
 	} else if command.Empty() {
@@ -352,6 +423,9 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 	if tag != "" && outputsImageRefTo.Value != "" {
 		return nil, fmt.Errorf("Cannot specify both tag= and outputs_image_ref_to=")
 	}
+	if outputsManifest.Value != "" && (tag != "" || outputsImageRefTo.Value != "") {
+		return nil, fmt.Errorf("Cannot specify outputs_manifest= with tag= or outputs_image_ref_to=")
+	}
 
 	img := &dockerImage{
 		workDir:           starkit.AbsWorkingDir(thread),
@@ -367,6 +441,9 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		entrypoint:        entrypointCmd,
 		containerArgs:     containerArgs,
 		outputsImageRefTo: outputsImageRefTo.Value,
+		sandbox:           sandbox,
+		outputsManifest:   outputsManifest.Value,
+		outputRefs:        outputRefs,
 	}
 
 	err = s.buildIndex.addImage(img)
@@ -374,9 +451,63 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		return nil, err
 	}
 
+	// The other outputs share the command, but not the container settings.
+	for _, o := range outputRefs {
+		if o == container.FamiliarString(ref) {
+			continue
+		}
+		oRef, _ := container.ParseNamed(o)
+		err = s.buildIndex.addImage(&dockerImage{
+			workDir:          img.workDir,
+			configurationRef: container.NewRefSelector(oRef),
+			customCommand:    command,
+			customDeps:       localDeps,
+			disablePush:      disablePush,
+			skipsLocalDocker: skipsLocalDocker,
+			matchInEnvVars:   matchInEnvVars,
+			ignores:          ignores,
+			sandbox:          sandbox,
+			outputsManifest:  outputsManifest.Value,
+			outputRefs:       outputRefs,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &customBuild{s: s, img: img}, nil
 }
 
+// Returns the refs of every image that the custom_build command builds,
+// starting with the main one.
+func customBuildOutputRefs(ref reference.Named, outputs []string, outputsManifest string) ([]string, error) {
+	if len(outputs) == 0 {
+		if outputsManifest == "" {
+			return nil, nil
+		}
+		return []string{container.FamiliarString(ref)}, nil
+	}
+	if outputsManifest == "" {
+		return nil, fmt.Errorf("Argument 'outputs': the command must report the images it built with outputs_manifest=")
+	}
+
+	result := []string{container.FamiliarString(ref)}
+	seen := map[string]bool{result[0]: true}
+	for _, o := range outputs {
+		oRef, err := container.ParseNamed(o)
+		if err != nil {
+			return nil, fmt.Errorf("Argument 'outputs': can't parse %q: %v", o, err)
+		}
+		name := container.FamiliarString(oRef)
+		if seen[name] {
+			return nil, fmt.Errorf("Argument 'outputs': duplicate image %q", name)
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result, nil
+}
+
 type customBuild struct {
 	s   *tiltfileState
 	img *dockerImage
@@ -509,14 +640,21 @@ func (s *tiltfileState) defaultRegistry(thread *starlark.Thread, fn *starlark.Bu
 		return starlark.None, errors.New("default registry already defined")
 	}
 
-	var host, hostFromCluster, singleName string
+	var host, hostFromCluster, singleName, pullSecret string
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"host", &host,
 		"host_from_cluster?", &hostFromCluster,
-		"single_name?", &singleName); err != nil {
+		"single_name?", &singleName,
+		"pull_secret?", &pullSecret); err != nil {
 		return nil, err
 	}
 
+	if pullSecret != "" {
+		if errs := validation.IsDNS1123Subdomain(pullSecret); len(errs) > 0 {
+			return nil, fmt.Errorf("%s: invalid pull_secret %q: %s", fn.Name(), pullSecret, strings.Join(errs, "; "))
+		}
+	}
+
 	reg, err := container.NewRegistryWithHostFromCluster(host, hostFromCluster)
 	if err != nil {
 		return starlark.None, errors.Wrapf(err, "validating defaultRegistry")
@@ -525,7 +663,34 @@ func (s *tiltfileState) defaultRegistry(thread *starlark.Thread, fn *starlark.Bu
 	reg.SingleName = singleName
 
 	s.defaultReg = reg
+	s.defaultRegPullSecret = pullSecret
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) localRegistryFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if !s.localRegistrySpec.Empty() {
+		return starlark.None, fmt.Errorf("%s: can only be called once", fn.Name())
+	}
+
+	spec := model.LocalRegistrySpec{
+		Name: model.DefaultLocalRegistryName,
+		Port: model.DefaultClusterRegistryPort,
+	}
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name?", &spec.Name,
+		"port?", &spec.Port); err != nil {
+		return nil, err
+	}
+
+	if spec.Name == "" {
+		return nil, fmt.Errorf("%s: name cannot be empty", fn.Name())
+	}
+	if spec.Port <= 0 || spec.Port > 65535 {
+		return nil, fmt.Errorf("%s: port must be between 1 and 65535 (got: %d)", fn.Name(), spec.Port)
+	}
 
+	s.localRegistrySpec = spec
 	return starlark.None, nil
 }
 
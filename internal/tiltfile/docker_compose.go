@@ -14,6 +14,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
@@ -23,6 +24,7 @@ import (
 // dcResourceSet represents a single docker-compose config file and all its associated services
 type dcResourceSet struct {
 	configPaths []string
+	backend     model.DockerComposeBackend
 
 	services     []*dcService
 	tiltfilePath string
@@ -66,7 +68,8 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 	allConfigPaths := append([]string{}, dc.configPaths...)
 	allConfigPaths = append(allConfigPaths, configPaths...)
 
-	services, err := parseDCConfig(s.ctx, s.dcCli, allConfigPaths)
+	proj := model.DockerComposeProject{ConfigPaths: allConfigPaths, Backend: s.dcBackend}
+	services, err := parseDCConfig(s.ctx, s.dcCli, proj)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +88,7 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 
 	s.dc = dcResourceSet{
 		configPaths:  allConfigPaths,
+		backend:      s.dcBackend,
 		services:     services,
 		tiltfilePath: starkit.CurrentExecPath(thread),
 	}
@@ -92,13 +96,40 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 	return starlark.None, nil
 }
 
+// dc_settings() configures how Tilt runs docker-compose projects.
+func (s *tiltfileState) dcSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var backendName string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"backend?", &backendName); err != nil {
+		return nil, err
+	}
+
+	if !s.dc.Empty() {
+		return nil, fmt.Errorf("%s must be called before %s, because docker_compose() runs the compose CLI",
+			fn.Name(), dockerComposeN)
+	}
+
+	backend, err := dockercompose.BackendForName(backendName)
+	if err != nil {
+		return nil, errors.Wrap(err, fn.Name())
+	}
+
+	s.dcBackend = model.DockerComposeBackendAuto
+	if backend != nil {
+		s.dcBackend = backend.Name()
+	}
+	return starlark.None, nil
+}
+
 // DCResource allows you to adjust specific settings on a DC resource that we assume
 // to be defined in a `docker_compose.yml`
 func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var name string
 	var imageVal starlark.Value
 	var triggerMode triggerMode
+	var priority buildPriority
 	var resourceDepsVal starlark.Sequence
+	var buildArgs value.StringStringMap
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"name", &name,
@@ -108,12 +139,17 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 		//  CAN'T do is use the arg to dc_resource.image to OVERRIDE the image named
 		//  in dc.yml, which we should probs be able to do?
 		// (If your dc.yml does NOT specify `Image`, DC will expect an image of name
-		// <directory>_<service>, and Tilt has no way of figuring this out yet, so
-		// can't auto-associate that image, you need to use dc_resource.)
+		// <project>_<service>, which is what we auto-associate with.)
 		"image?", &imageVal,
 
 		"trigger_mode?", &triggerMode,
 		"resource_deps?", &resourceDepsVal,
+
+		// Only applies to services that Tilt builds from the dc.yml `build` section.
+		// Overrides any args of the same name in dc.yml.
+		"build_args?", &buildArgs,
+
+		"priority?", &priority,
 	); err != nil {
 		return nil, err
 	}
@@ -138,6 +174,7 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	}
 
 	svc.TriggerMode = triggerMode
+	svc.Priority = priority
 
 	if imageRefAsStr != nil {
 		normalized, err := container.ParseNamed(*imageRefAsStr)
@@ -153,6 +190,13 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 	}
 	svc.resourceDeps = rds
 
+	if len(buildArgs.AsMap()) > 0 {
+		if svc.DfPath == "" {
+			return nil, fmt.Errorf("%s: build_args: service %q has no build section", fn.Name(), name)
+		}
+		svc.buildArgsFromUser = buildArgs.AsMap()
+	}
+
 	return starlark.None, nil
 }
 
@@ -179,8 +223,18 @@ type dcService struct {
 
 	// RefSelector of the image associated with this service
 	// The user-provided image ref overrides the config-provided image ref
-	imageRefFromConfig reference.Named // from docker-compose.yml `Image` field
-	imageRefFromUser   reference.Named // set via dc_resource
+	imageRefFromConfig  reference.Named // from docker-compose.yml `Image` field
+	imageRefFromUser    reference.Named // set via dc_resource
+	imageRefFromProject reference.Named // the <project>_<service> (or <project>-<service>) name DC builds when there's no `Image` field
+
+	// From the docker-compose.yml `build` section. When there's no docker_build
+	// for this service's image, we use these to build the image ourselves.
+	BuildArgs   model.DockerBuildArgs
+	TargetStage string
+	CacheFrom   []string
+	Network     string
+
+	buildArgsFromUser model.DockerBuildArgs // set via dc_resource
 
 	// Currently just use these to diff against when config files are edited to see if manifest has changed
 	ServiceConfig []byte
@@ -190,6 +244,7 @@ type dcService struct {
 	PublishedPorts []int
 
 	TriggerMode triggerMode
+	Priority    buildPriority
 
 	resourceDeps []string
 }
@@ -198,7 +253,25 @@ func (svc dcService) ImageRef() reference.Named {
 	if svc.imageRefFromUser != nil {
 		return svc.imageRefFromUser
 	}
-	return svc.imageRefFromConfig
+	if svc.imageRefFromConfig != nil {
+		return svc.imageRefFromConfig
+	}
+	return svc.imageRefFromProject
+}
+
+// The build args from docker-compose.yml, with any overrides from dc_resource applied.
+func (svc dcService) buildArgs() model.DockerBuildArgs {
+	if len(svc.BuildArgs) == 0 && len(svc.buildArgsFromUser) == 0 {
+		return nil
+	}
+	result := make(model.DockerBuildArgs, len(svc.BuildArgs)+len(svc.buildArgsFromUser))
+	for k, v := range svc.BuildArgs {
+		result[k] = v
+	}
+	for k, v := range svc.buildArgsFromUser {
+		result[k] = v
+	}
+	return result
 }
 
 func DockerComposeConfigToService(c dockercompose.Config, name string) (dcService, error) {
@@ -239,6 +312,15 @@ func DockerComposeConfigToService(c dockercompose.Config, name string) (dcServic
 		PublishedPorts: publishedPorts,
 	}
 
+	if buildContext != "" {
+		svc.TargetStage = svcConfig.Build.Target
+		svc.CacheFrom = svcConfig.Build.CacheFrom
+		svc.Network = svcConfig.Build.Network
+		if len(svcConfig.Build.Args) > 0 {
+			svc.BuildArgs = model.DockerBuildArgs(svcConfig.Build.Args)
+		}
+	}
+
 	if svcConfig.Image != "" {
 		ref, err := container.ParseNamed(svcConfig.Image)
 		if err != nil {
@@ -260,33 +342,63 @@ func DockerComposeConfigToService(c dockercompose.Config, name string) (dcServic
 	return svc, nil
 }
 
-func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, configPaths []string) ([]*dcService, error) {
+func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, proj model.DockerComposeProject) ([]*dcService, error) {
 
-	config, svcNames, err := dockercompose.ReadConfigAndServiceNames(ctx, dcc, configPaths)
+	config, svcNames, err := dockercompose.ReadConfigAndServiceNames(ctx, dcc, proj)
 	if err != nil {
 		return nil, err
 	}
 
+	backend := dcc.Backend(ctx, proj)
 	var services []*dcService
 	for _, name := range svcNames {
 		svc, err := DockerComposeConfigToService(config, name)
 		if err != nil {
 			return nil, errors.Wrapf(err, "getting service %s", name)
 		}
+
+		if svc.DfPath != "" && svc.imageRefFromConfig == nil {
+			ref, err := container.ParseNamed(dockercompose.DefaultImageName(backend, proj.ConfigPaths, name))
+			if err == nil {
+				svc.imageRefFromProject = ref
+			}
+		}
 		services = append(services, &svc)
 	}
 
 	return services, nil
 }
 
+func (s *tiltfileState) dcImageBuilder(svc *dcService) (*dockerImage, error) {
+	img := &dockerImage{
+		workDir:          s.dc.tiltfilePath,
+		configurationRef: container.NewRefSelector(svc.ImageRef()),
+		dbDockerfilePath: svc.DfPath,
+		dbDockerfile:     dockerfile.Dockerfile(svc.DfContents),
+		dbBuildPath:      svc.BuildContext,
+		dbBuildArgs:      svc.buildArgs(),
+		targetStage:      svc.TargetStage,
+		cacheFrom:        svc.CacheFrom,
+		network:          svc.Network,
+		matched:          true,
+	}
+	err := s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
 func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResourceSet) (model.Manifest, error) {
 	dcInfo := model.DockerComposeTarget{
 		ConfigPaths: dcSet.configPaths,
+		Backend:     dcSet.backend,
 		YAMLRaw:     service.ServiceConfig,
 		DfRaw:       service.DfContents,
 	}.WithDependencyIDs(service.DependencyIDs).
 		WithPublishedPorts(service.PublishedPorts).
-		WithIgnoredLocalDirectories(service.MountedLocalDirs)
+		WithIgnoredLocalDirectories(service.MountedLocalDirs).
+		WithBindMounts(service.MountedLocalDirs)
 
 	um, err := starlarkTriggerModeToModel(s.triggerModeForResource(service.TriggerMode), true)
 	if err != nil {
@@ -302,6 +414,7 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 		Name:                 model.ManifestName(service.Name),
 		TriggerMode:          um,
 		ResourceDependencies: mds,
+		BuildPriority:        model.BuildPriority(service.Priority),
 	}.WithDeployTarget(dcInfo)
 
 	if service.DfPath == "" {
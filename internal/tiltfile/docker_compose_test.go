@@ -2,13 +2,17 @@ package tiltfile
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // ParseConfig must return services topologically sorted wrt dependencies.
@@ -123,6 +127,38 @@ version: '3.0'
 	}
 }
 
+func TestImageNameFromProjectPerBackend(t *testing.T) {
+	for _, testCase := range []struct {
+		backend   model.DockerComposeBackend
+		separator string
+	}{
+		{model.DockerComposeBackendV1, "_"},
+		{model.DockerComposeBackendV2, "-"},
+		{model.DockerComposeBackendPodman, "_"},
+		{model.DockerComposeBackendNerdctl, "-"},
+	} {
+		t.Run(string(testCase.backend), func(t *testing.T) {
+			f := newDCFixture(t)
+
+			services := f.parseBuiltService(testCase.backend)
+			if assert.Len(t, services, 1) {
+				assert.Equal(t, "docker.io/library/myproj"+testCase.separator+"app",
+					services[0].imageRefFromProject.String())
+			}
+		})
+	}
+}
+
+func TestImageNameFromProjectDetectedBackend(t *testing.T) {
+	f := newDCFixture(t)
+	f.dcCli.DetectedBackend = dockercompose.BackendV2
+
+	services := f.parseBuiltService(model.DockerComposeBackendAuto)
+	if assert.Len(t, services, 1) {
+		assert.Equal(t, "docker.io/library/myproj-app", services[0].imageRefFromProject.String())
+	}
+}
+
 type dcFixture struct {
 	t     *testing.T
 	ctx   context.Context
@@ -140,10 +176,34 @@ func newDCFixture(t *testing.T) dcFixture {
 }
 
 func (f dcFixture) parse(configOutput, servicesOutput string) []*dcService {
+	return f.parseProject(model.DockerComposeProject{ConfigPaths: []string{"doesn't-matter.yml"}}, configOutput, servicesOutput)
+}
+
+// Parses a project in a directory named myproj, with one service, app, that
+// compose builds from a Dockerfile.
+func (f dcFixture) parseBuiltService(backend model.DockerComposeBackend) []*dcService {
+	tf := tempdir.NewTempDirFixture(f.t)
+	f.t.Cleanup(tf.TearDown)
+
+	tf.WriteFile(filepath.Join("myproj", "Dockerfile"), "FROM alpine")
+	output := fmt.Sprintf(`services:
+  app:
+    build:
+      context: %s
+version: '3.0'
+`, tf.JoinPath("myproj"))
+	proj := model.DockerComposeProject{
+		ConfigPaths: []string{tf.JoinPath("myproj", "docker-compose.yml")},
+		Backend:     backend,
+	}
+	return f.parseProject(proj, output, "app\n")
+}
+
+func (f dcFixture) parseProject(proj model.DockerComposeProject, configOutput, servicesOutput string) []*dcService {
 	f.dcCli.ConfigOutput = configOutput
 	f.dcCli.ServicesOutput = servicesOutput
 
-	services, err := parseDCConfig(f.ctx, f.dcCli, []string{"doesn't-matter.yml"})
+	services, err := parseDCConfig(f.ctx, f.dcCli, proj)
 	if err != nil {
 		f.t.Fatalf("dcFixture.Parse: %v", err)
 	}
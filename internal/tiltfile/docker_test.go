@@ -141,3 +141,62 @@ custom_build('gcr.io/fe', 'export MY_REF="gcr.io/fe:dev" && docker build -t $MY_
 
 	f.loadErrString("Cannot specify both tag= and outputs_image_ref_to=")
 }
+
+func TestCustomBuildOutputs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.yaml("be.yaml", deployment("be", image("gcr.io/be")))
+	f.file("Tiltfile", `
+k8s_yaml(['fe.yaml', 'be.yaml'])
+custom_build('gcr.io/fe', 'bazel run //:images', ['src'],
+            outputs=['gcr.io/be'],
+            outputs_manifest='images.json')
+`)
+
+	f.load()
+
+	fe := f.assertNextManifest("fe").ImageTargets[0].CustomBuildInfo()
+	be := f.assertNextManifest("be").ImageTargets[0].CustomBuildInfo()
+	assert.Equal(t, f.JoinPath("images.json"), fe.OutputsManifest)
+	assert.Equal(t, []string{"gcr.io/fe", "gcr.io/be"}, fe.OutputRefs)
+	assert.Equal(t, fe, be)
+}
+
+func TestCustomBuildOutputsRequiresManifest(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+custom_build('gcr.io/fe', 'bazel run //:images', ['src'], outputs=['gcr.io/be'])
+`)
+
+	f.loadErrString("the command must report the images it built with outputs_manifest=")
+}
+
+func TestCustomBuildOutputsDuplicate(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+custom_build('gcr.io/fe', 'bazel run //:images', ['src'],
+            outputs=['gcr.io/be', 'gcr.io/fe'],
+            outputs_manifest='images.json')
+`)
+
+	f.loadErrString(`duplicate image "gcr.io/fe"`)
+}
+
+func TestCustomBuildOutputsManifestIncompatibleWithTag(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+custom_build('gcr.io/fe', 'bazel run //:images', ['src'],
+            tag='dev',
+            outputs_manifest='images.json')
+`)
+
+	f.loadErrString("Cannot specify outputs_manifest= with tag= or outputs_image_ref_to=")
+}
@@ -25,6 +25,9 @@ func (e Extension) NewState() interface{} {
 		MaxAge:     model.DockerPruneDefaultMaxAge,
 		Interval:   model.DockerPruneDefaultInterval,
 		KeepRecent: model.DockerPruneDefaultKeepRecent,
+
+		WarnFreeDiskPct: model.DockerPruneDefaultWarnFreeDiskPct,
+		MinFreeDiskPct:  model.DockerPruneDefaultMinFreeDiskPct,
 	}
 }
 
@@ -34,14 +37,16 @@ func (e Extension) OnStart(env *starkit.Environment) error {
 
 func (e Extension) dockerPruneSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var disable bool
-	var keepRecent starlark.Value
+	var keepRecent, warnFreeDiskPct, minFreeDiskPct starlark.Value
 	var intervalHrs, numBuilds, maxAgeMins int
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 		"disable?", &disable,
 		"max_age_mins?", &maxAgeMins,
 		"num_builds?", &numBuilds,
 		"interval_hrs?", &intervalHrs,
-		"keep_recent?", &keepRecent); err != nil {
+		"keep_recent?", &keepRecent,
+		"warn_free_disk_pct?", &warnFreeDiskPct,
+		"min_free_disk_pct?", &minFreeDiskPct); err != nil {
 		return nil, err
 	}
 
@@ -66,12 +71,41 @@ func (e Extension) dockerPruneSettings(thread *starlark.Thread, fn *starlark.Bui
 			}
 			settings.KeepRecent = recent
 		}
+		if warnFreeDiskPct != nil {
+			pct, err := diskPct(fn.Name(), "warn_free_disk_pct", warnFreeDiskPct)
+			if err != nil {
+				return settings, err
+			}
+			settings.WarnFreeDiskPct = pct
+		}
+		if minFreeDiskPct != nil {
+			pct, err := diskPct(fn.Name(), "min_free_disk_pct", minFreeDiskPct)
+			if err != nil {
+				return settings, err
+			}
+			settings.MinFreeDiskPct = pct
+		}
+		if settings.MinFreeDiskPct > settings.WarnFreeDiskPct && settings.WarnFreeDiskPct != 0 {
+			return settings, fmt.Errorf("%s: min_free_disk_pct (%d) must not be greater than warn_free_disk_pct (%d)",
+				fn.Name(), settings.MinFreeDiskPct, settings.WarnFreeDiskPct)
+		}
 		return settings, nil
 	})
 
 	return starlark.None, err
 }
 
+func diskPct(fnName, argName string, v starlark.Value) (int, error) {
+	pct, err := starlark.AsInt32(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: for parameter %s: %v", fnName, argName, err)
+	}
+	if pct < 0 || pct >= 100 {
+		return 0, fmt.Errorf("%s: for parameter %s: must be between 0 and 99, got %d", fnName, argName, pct)
+	}
+	return pct, nil
+}
+
 var _ starkit.StatefulExtension = Extension{}
 
 func MustState(model starkit.Model) model.DockerPruneSettings {
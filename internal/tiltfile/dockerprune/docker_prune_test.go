@@ -45,6 +45,46 @@ docker_prune_settings(keep_recent=5)
 	assert.Equal(t, model.DockerPruneDefaultKeepRecent, MustState(result).KeepRecent)
 }
 
+func TestDockerPruneFreeDiskPct(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(warn_free_disk_pct=20, min_free_disk_pct=8)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, MustState(result).WarnFreeDiskPct)
+	assert.Equal(t, 8, MustState(result).MinFreeDiskPct)
+
+	f.File("Tiltfile.empty", `
+`)
+	result, err = f.ExecFile("Tiltfile.empty")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DockerPruneDefaultWarnFreeDiskPct, MustState(result).WarnFreeDiskPct)
+	assert.Equal(t, model.DockerPruneDefaultMinFreeDiskPct, MustState(result).MinFreeDiskPct)
+}
+
+func TestDockerPruneFreeDiskPctOutOfRange(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(warn_free_disk_pct=100)
+`)
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "warn_free_disk_pct: must be between 0 and 99, got 100")
+	}
+}
+
+func TestDockerPruneMinFreeDiskPctAboveWarn(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(warn_free_disk_pct=5, min_free_disk_pct=10)
+`)
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "min_free_disk_pct (10) must not be greater than warn_free_disk_pct (5)")
+	}
+}
+
 func NewFixture(tb testing.TB) *starkit.Fixture {
 	return starkit.NewFixture(tb, NewExtension())
 }
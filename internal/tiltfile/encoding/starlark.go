@@ -7,6 +7,12 @@ import (
 	"go.starlark.net/starlark"
 )
 
+// Converts data decoded from JSON or YAML (maps, lists, strings, numbers, bools)
+// into Starlark values.
+func ConvertStructuredDataToStarlark(j interface{}) (starlark.Value, error) {
+	return convertStructuredDataToStarlark(j)
+}
+
 func convertStructuredDataToStarlark(j interface{}) (starlark.Value, error) {
 	switch j := j.(type) {
 	case bool:
@@ -158,6 +158,21 @@ func encodeYAMLStream(thread *starlark.Thread, fn *starlark.Builtin, args starla
 		return nil, err
 	}
 
+	ret, err := EncodeYAMLStream(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	return tiltfile_io.NewBlob(ret, "encode_yaml_stream"), nil
+}
+
+// Decodes a YAML stream into a list of starlark values, like decode_yaml_stream().
+func DecodeYAMLStream(s string) (*starlark.List, error) {
+	return yamlStreamToStarlark(s, "")
+}
+
+// Encodes a list of starlark values as a YAML stream, like encode_yaml_stream().
+func EncodeYAMLStream(objs starlark.Iterable) (string, error) {
 	var yamlDocs []string
 
 	it := objs.Iterate()
@@ -166,12 +181,12 @@ func encodeYAMLStream(thread *starlark.Thread, fn *starlark.Builtin, args starla
 	for it.Next(&v) {
 		s, err := starlarkToYAMLString(v)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		yamlDocs = append(yamlDocs, s)
 	}
 
-	return tiltfile_io.NewBlob(strings.Join(yamlDocs, "---\n"), "encode_yaml_stream"), nil
+	return strings.Join(yamlDocs, "---\n"), nil
 }
 
 func starlarkToYAMLString(obj starlark.Value) (string, error) {
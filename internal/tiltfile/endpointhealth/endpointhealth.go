@@ -0,0 +1,88 @@
+package endpointhealth
+
+import (
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Checks can't run more often than this, so that Tilt doesn't
+// hammer a dev server with requests.
+const minInterval = time.Second
+
+// Implements the endpoint_health_checks() builtin, which periodically
+// checks that resources' endpoint links respond.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.DefaultEndpointHealthSettings()
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("endpoint_health_checks", setEndpointHealthSettings)
+}
+
+func setEndpointHealthSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings model.EndpointHealthSettings) (model.EndpointHealthSettings, error) {
+		settings.Enabled = true
+		interval := settings.Interval.String()
+		timeout := settings.Timeout.String()
+		var resources value.StringOrStringList
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"enabled?", &settings.Enabled,
+			"interval?", &interval,
+			"timeout?", &timeout,
+			"resources?", &resources)
+		if err != nil {
+			return model.EndpointHealthSettings{}, err
+		}
+
+		settings.Interval, err = time.ParseDuration(interval)
+		if err != nil {
+			return model.EndpointHealthSettings{}, fmt.Errorf("%s: invalid interval %q: %v", fn.Name(), interval, err)
+		}
+		if settings.Interval < minInterval {
+			return model.EndpointHealthSettings{}, fmt.Errorf("%s: interval must be at least %s (got: %s)", fn.Name(), minInterval, settings.Interval)
+		}
+
+		settings.Timeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			return model.EndpointHealthSettings{}, fmt.Errorf("%s: invalid timeout %q: %v", fn.Name(), timeout, err)
+		}
+		if settings.Timeout <= 0 || settings.Timeout > settings.Interval {
+			return model.EndpointHealthSettings{}, fmt.Errorf("%s: timeout must be positive and no longer than the interval (got: %s)", fn.Name(), settings.Timeout)
+		}
+
+		settings.Resources = nil
+		for _, r := range resources.Values {
+			settings.Resources = append(settings.Resources, model.ManifestName(r))
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.EndpointHealthSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.EndpointHealthSettings, error) {
+	var state model.EndpointHealthSettings
+	err := m.Load(&state)
+	return state, err
+}
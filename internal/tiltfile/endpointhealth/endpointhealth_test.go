@@ -0,0 +1,73 @@
+package endpointhealth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestEndpointHealthDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultEndpointHealthSettings(), MustState(result))
+}
+
+func TestEndpointHealthEnabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "endpoint_health_checks()")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Enabled)
+	assert.Equal(t, 15*time.Second, MustState(result).Interval)
+	assert.Equal(t, 3*time.Second, MustState(result).Timeout)
+	assert.Empty(t, MustState(result).Resources)
+}
+
+func TestEndpointHealthIntervalAndResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "endpoint_health_checks(interval='1m', timeout='10s', resources=['frontend'])")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, MustState(result).Interval)
+	assert.Equal(t, 10*time.Second, MustState(result).Timeout)
+	assert.Equal(t, []model.ManifestName{"frontend"}, MustState(result).Resources)
+}
+
+func TestEndpointHealthIntervalTooShort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "endpoint_health_checks(interval='100ms')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "interval must be at least 1s")
+	}
+}
+
+func TestEndpointHealthTimeoutLongerThanInterval(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "endpoint_health_checks(interval='5s', timeout='10s')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timeout must be positive and no longer than the interval")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
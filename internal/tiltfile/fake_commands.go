@@ -0,0 +1,73 @@
+package tiltfile
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// CommandFaker intercepts the commands a Tiltfile shells out to
+// (local(), helm(), kustomize()), so that Tiltfile tests don't need
+// a cluster or any tools installed.
+type CommandFaker interface {
+	// If handled is false, the command runs for real.
+	FakeCommand(argv []string, dir string) (stdout string, handled bool, err error)
+}
+
+// NewHermeticTiltfileLoader creates a loader that never talks to a cluster,
+// and routes shell commands through the given faker.
+//
+// Used by `tilt alpha tiltfile-test`.
+func NewHermeticTiltfileLoader(
+	analytics *analytics.TiltAnalytics,
+	kubeContext k8s.KubeContext,
+	env k8s.Env,
+	build model.TiltBuild,
+	faker CommandFaker) TiltfileLoader {
+	return tiltfileLoader{
+		analytics:     analytics,
+		kCli:          k8s.NewFakeK8sClient(),
+		k8sContextExt: k8scontext.NewExtension(kubeContext, env),
+		versionExt:    version.NewExtension(build),
+		configExt:     config.NewExtension("alpha tiltfile-test"),
+		dcCli:         dockercompose.NewDockerComposeClient(docker.LocalEnv{}),
+		fDefaults:     feature.MainDefaults,
+		env:           env,
+		commandFaker:  faker,
+	}
+}
+
+func (s *tiltfileState) fakeLocalCmd(c *exec.Cmd) (string, bool, error) {
+	if s.commandFaker == nil {
+		return "", false, nil
+	}
+	stdout, handled, err := s.commandFaker.FakeCommand(c.Args, c.Dir)
+	if err != nil {
+		return "", true, fmt.Errorf("command %q failed.\nerror: %v", c.Args, err)
+	}
+	return stdout, handled, nil
+}
+
+// Detect the helm version, asking the faker first so that helm()
+// works in tests without helm installed.
+func (s *tiltfileState) helmVersion() (helmVersion, error) {
+	if s.commandFaker != nil {
+		out, handled, err := s.fakeLocalCmd(&exec.Cmd{Args: helmVersionArgs})
+		if handled {
+			if err != nil {
+				return unknownHelmVersion, err
+			}
+			return parseVersion(out)
+		}
+	}
+	return getHelmVersion()
+}
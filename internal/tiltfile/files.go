@@ -56,18 +56,35 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 }
 
 func (s *tiltfileState) execLocalCmd(t *starlark.Thread, c *exec.Cmd, logOutput bool) (string, error) {
+	// TODO(nick): Should this also inject any docker.Env overrides?
+	c.Dir = starkit.AbsWorkingDir(t)
+
+	if out, handled, err := s.fakeLocalCmd(c); handled {
+		return out, err
+	}
+
+	l := logger.NewPrefixedLogger(localLogPrefix, s.logger)
+	var out io.Writer
+	if logOutput {
+		out = logger.NewMutexWriter(l.Writer(logger.InfoLvl))
+	}
+	return s.runLocalCmd(c, out, l)
+}
+
+// Runs a command for real, copying its output to out (if not nil).
+//
+// Safe to call off the Starlark thread.
+func (s *tiltfileState) runLocalCmd(c *exec.Cmd, out io.Writer, l logger.Logger) (string, error) {
 	stdout := bytes.NewBuffer(nil)
 	stderr := bytes.NewBuffer(nil)
 
-	// TODO(nick): Should this also inject any docker.Env overrides?
-	c.Dir = starkit.AbsWorkingDir(t)
 	c.Stdout = stdout
 	c.Stderr = stderr
 
+	logOutput := out != nil
 	if logOutput {
-		logOutput := logger.NewMutexWriter(logger.NewPrefixedLogger(localLogPrefix, s.logger).Writer(logger.InfoLvl))
-		c.Stdout = io.MultiWriter(stdout, logOutput)
-		c.Stderr = io.MultiWriter(stderr, logOutput)
+		c.Stdout = io.MultiWriter(stdout, out)
+		c.Stderr = io.MultiWriter(stderr, out)
 	}
 
 	err := c.Run()
@@ -82,7 +99,7 @@ func (s *tiltfileState) execLocalCmd(t *starlark.Thread, c *exec.Cmd, logOutput
 	}
 
 	if stdout.Len() == 0 && stderr.Len() == 0 {
-		s.logger.Infof("%s[no output]", localLogPrefix)
+		l.Infof("[no output]")
 	}
 
 	return stdout.String(), nil
@@ -181,7 +198,7 @@ func (s *tiltfileState) helm(thread *starlark.Thread, fn *starlark.Builtin, args
 		}
 	}
 
-	version, err := getHelmVersion()
+	version, err := s.helmVersion()
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +92,8 @@ func isHelmInstalled() bool {
 	return true
 }
 
+var helmVersionArgs = []string{"helm", "version", "--client", "--short"}
+
 func getHelmVersion() (helmVersion, error) {
 	if !isHelmInstalled() {
 		return unknownHelmVersion, unableToFindHelmErrorMessage()
@@ -102,7 +104,7 @@ func getHelmVersion() (helmVersion, error) {
 	// command to fail, even though Tilt doesn't use the server at all (it just calls
 	// `helm template`).
 	// In Helm v3, it has no effect, not even an unknown flag error.
-	cmd := exec.Command("helm", "version", "--client", "--short")
+	cmd := exec.Command(helmVersionArgs[0], helmVersionArgs[1:]...)
 
 	out, err := cmd.Output()
 	if err != nil {
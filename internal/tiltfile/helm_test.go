@@ -24,6 +24,8 @@ k8s_yaml(yml)
 	f.assertConfigFiles(
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		"helm",
 	)
 }
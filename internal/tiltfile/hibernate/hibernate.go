@@ -0,0 +1,78 @@
+package hibernate
+
+import (
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Resources can't hibernate more often than this, so that a typo
+// doesn't make Tilt scale everything down while you're using it.
+const minIdleTimeout = time.Minute
+
+// Implements the hibernate() builtin, which scales idle Kubernetes
+// resources to zero until they're needed again.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.DefaultHibernateSettings()
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("hibernate", setHibernateSettings)
+}
+
+func setHibernateSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings model.HibernateSettings) (model.HibernateSettings, error) {
+		settings.Enabled = true
+		idleTimeout := settings.IdleTimeout.String()
+		var resources value.StringOrStringList
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"enabled?", &settings.Enabled,
+			"idle_timeout?", &idleTimeout,
+			"resources?", &resources)
+		if err != nil {
+			return model.HibernateSettings{}, err
+		}
+
+		settings.IdleTimeout, err = time.ParseDuration(idleTimeout)
+		if err != nil {
+			return model.HibernateSettings{}, fmt.Errorf("%s: invalid idle_timeout %q: %v", fn.Name(), idleTimeout, err)
+		}
+		if settings.IdleTimeout < minIdleTimeout {
+			return model.HibernateSettings{}, fmt.Errorf("%s: idle_timeout must be at least %s (got: %s)", fn.Name(), minIdleTimeout, settings.IdleTimeout)
+		}
+
+		settings.Resources = nil
+		for _, r := range resources.Values {
+			settings.Resources = append(settings.Resources, model.ManifestName(r))
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.HibernateSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.HibernateSettings, error) {
+	var state model.HibernateSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,81 @@
+package hibernate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHibernateDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultHibernateSettings(), MustState(result))
+}
+
+func TestHibernateEnabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "hibernate()")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Enabled)
+	assert.Equal(t, 20*time.Minute, MustState(result).IdleTimeout)
+	assert.Empty(t, MustState(result).Resources)
+}
+
+func TestHibernateTimeoutAndResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "hibernate(idle_timeout='1h30m', resources=['frontend', 'backend'])")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, MustState(result).IdleTimeout)
+	assert.Equal(t, []model.ManifestName{"frontend", "backend"}, MustState(result).Resources)
+}
+
+func TestHibernateDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "hibernate(enabled=False)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).Enabled)
+}
+
+func TestHibernateBadTimeout(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "hibernate(idle_timeout='soon')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid idle_timeout")
+	}
+}
+
+func TestHibernateTimeoutTooShort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "hibernate(idle_timeout='10s')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "idle_timeout must be at least 1m0s")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
@@ -67,7 +67,12 @@ func readFile(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple
 	// the type of default is Union[None, Str], so that we can distinguish unspecified from the empty string
 	// which means we can't simply lean on Unpack args for type-checking
 	var defaultReturnValue starlark.Value = starlark.None
-	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "paths", &path, "default?", &defaultReturnValue)
+	// Set watch=False to read a file without making it a Tiltfile reload trigger.
+	watch := true
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"paths", &path,
+		"default?", &defaultReturnValue,
+		"watch?", &watch)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +91,12 @@ func readFile(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple
 		return nil, fmt.Errorf("invalid type for paths: %v", err)
 	}
 
-	bs, err := ReadFile(thread, p)
+	var bs []byte
+	if watch {
+		bs, err = ReadFile(thread, p)
+	} else {
+		bs, err = ioutil.ReadFile(p)
+	}
 	if os.IsNotExist(err) && defaultReturnValue != starlark.None {
 		bs = []byte(defaultReturn.GoString())
 	} else if err != nil {
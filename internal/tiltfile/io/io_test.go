@@ -74,6 +74,22 @@ s = read_file('foo.txt', 5)
 	require.Contains(t, err.Error(), "default must be starlark.NoneType or starlark.String. got starlark.Int")
 }
 
+func TestReadFileWatch(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("foo.txt", "foo")
+	f.File("bar.txt", "bar")
+	f.File("Tiltfile", `
+read_file('foo.txt')
+read_file('bar.txt', watch=False)
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, []string{f.JoinPath("Tiltfile"), f.JoinPath("foo.txt")}, MustState(result).Paths)
+}
+
 func TestReadFileMissing(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"go.starlark.net/syntax"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -54,10 +56,33 @@ type k8sResource struct {
 
 	triggerMode triggerMode
 	autoInit    bool
+	priority    buildPriority
 
 	resourceDeps []string
 
 	manuallyGrouped bool
+
+	// if non-nil, overrides the scale of the global dev_resource_profile
+	scaleResources *float64
+
+	orderedPodManagement bool
+	deletePVCs           bool
+	pauseAutoscaling     bool
+	podReplacement       model.PodReplacement
+
+	// if non-nil, changes the objects at deploy time
+	transform *starlark.Function
+
+	seed model.K8sSeed
+
+	links []model.Link
+
+	// if non-empty, copies the namespaced objects into each of these namespaces
+	namespaces []string
+
+	// if non-nil, why we couldn't put this resource together
+	// (only with load_settings(on_error='continue_on_error'))
+	configErr error
 }
 
 const deprecatedResourceAssemblyV1Warning = "This Tiltfile is using k8s resource assembly version 1, which has been " +
@@ -73,11 +98,21 @@ type k8sResourceOptions struct {
 	extraPodSelectors []labels.Selector
 	triggerMode       triggerMode
 	autoInit          bool
+	priority          buildPriority
 	tiltfilePosition  syntax.Position
 	resourceDeps      []string
 	objects           []string
 	manuallyGrouped   bool
 	podReadinessMode  model.PodReadinessMode
+	scaleResources    *float64
+	orderedPods       bool
+	deletePVCs        bool
+	pauseAutoscaling  bool
+	podReplacement    model.PodReplacement
+	transform         *starlark.Function
+	seed              model.K8sSeed
+	links             []model.Link
+	namespaces        []string
 }
 
 func (r *k8sResource) addRefSelector(selector container.RefSelector) {
@@ -118,10 +153,12 @@ func (r k8sResource) refSelectorList() []string {
 func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var yamlValue starlark.Value
 	var allowDuplicates bool
+	manage := true
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"yaml", &yamlValue,
 		"allow_duplicates?", &allowDuplicates,
+		"manage?", &manage,
 	); err != nil {
 		return nil, err
 	}
@@ -149,6 +186,12 @@ func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, a
 
 		s.k8sUnresourced = append(s.k8sUnresourced, entities...)
 
+		if !manage {
+			for _, e := range entities {
+				s.k8sObserved[entityKey(e)] = true
+			}
+		}
+
 	} else {
 		return nil, fmt.Errorf("Empty or Invalid YAML Resource Detected")
 	}
@@ -336,6 +379,9 @@ func (s *tiltfileState) k8sImageLocatorsList() []k8s.ImageLocator {
 	locators := []k8s.ImageLocator{}
 	for _, info := range s.k8sKinds {
 		locators = append(locators, info.ImageLocators...)
+		if info.PodTemplateLocator != nil {
+			locators = append(locators, info.PodTemplateLocator)
+		}
 	}
 	return locators
 }
@@ -405,9 +451,16 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 	var portForwardsVal starlark.Value
 	var extraPodSelectorsVal starlark.Value
 	var triggerMode triggerMode
+	var priority buildPriority
 	var resourceDepsVal starlark.Sequence
 	var objectsVal starlark.Sequence
 	var podReadinessMode tiltfile_k8s.PodReadinessMode
+	var scaleResourcesVal starlark.Value
+	var orderedPods, deletePVCs, pauseAutoscaling, surge bool
+	var drainPeriodVal, gracePeriodVal starlark.Value
+	var transform *starlark.Function
+	var seedVal, linksVal starlark.Value
+	var namespacesVal starlark.Sequence
 	autoInit := true
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
@@ -420,6 +473,18 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		"objects?", &objectsVal,
 		"auto_init?", &autoInit,
 		"pod_readiness?", &podReadinessMode,
+		"scale_resources?", &scaleResourcesVal,
+		"ordered_pod_management?", &orderedPods,
+		"delete_pvcs?", &deletePVCs,
+		"pause_autoscaling?", &pauseAutoscaling,
+		"surge?", &surge,
+		"drain_period_secs?", &drainPeriodVal,
+		"termination_grace_period_secs?", &gracePeriodVal,
+		"priority?", &priority,
+		"transform?", &transform,
+		"seed?", &seedVal,
+		"links?", &linksVal,
+		"namespaces?", &namespacesVal,
 	); err != nil {
 		return nil, err
 	}
@@ -442,6 +507,49 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		return nil, err
 	}
 
+	scaleResources, err := resourceScaleFromStarlarkValue(scaleResourcesVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: scale_resources", fn.Name(), resourceName)
+	}
+
+	drainPeriod, err := durationSecsFromStarlarkValue(drainPeriodVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: drain_period_secs", fn.Name(), resourceName)
+	}
+
+	gracePeriod, err := durationSecsFromStarlarkValue(gracePeriodVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: termination_grace_period_secs", fn.Name(), resourceName)
+	}
+
+	podReplacement := model.PodReplacement{
+		Surge:                  surge,
+		TerminationGracePeriod: gracePeriod,
+	}
+	if drainPeriod != nil {
+		podReplacement.DrainPeriod = *drainPeriod
+	}
+
+	if transform != nil && transform.NumParams() != 1 {
+		return nil, fmt.Errorf("%s %q: transform must take 1 argument. %s takes %d",
+			fn.Name(), resourceName, transform.Name(), transform.NumParams())
+	}
+
+	seed, err := seedFromStarlarkValue(seedVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: seed", fn.Name(), resourceName)
+	}
+
+	links, err := convertLinks(linksVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: links", fn.Name(), resourceName)
+	}
+
+	namespaces, err := fanOutNamespacesFromStarlarkValue(namespacesVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: namespaces", fn.Name(), resourceName)
+	}
+
 	if opts, ok := s.k8sResourceOptions[resourceName]; ok {
 		return nil, fmt.Errorf("%s already called for %s, at %s", fn.Name(), resourceName, opts.tiltfilePosition.String())
 	}
@@ -473,15 +581,178 @@ func (s *tiltfileState) k8sResourceV2(thread *starlark.Thread, fn *starlark.Buil
 		tiltfilePosition:  thread.CallFrame(1).Pos,
 		triggerMode:       triggerMode,
 		autoInit:          autoInit,
+		priority:          priority,
 		resourceDeps:      resourceDeps,
 		objects:           objects,
 		manuallyGrouped:   manuallyGrouped,
 		podReadinessMode:  podReadinessMode.Value,
+		scaleResources:    scaleResources,
+		orderedPods:       orderedPods,
+		deletePVCs:        deletePVCs,
+		pauseAutoscaling:  pauseAutoscaling,
+		podReplacement:    podReplacement,
+		transform:         transform,
+		seed:              seed,
+		links:             links,
+		namespaces:        namespaces,
 	}
 
 	return starlark.None, nil
 }
 
+func fanOutNamespacesFromStarlarkValue(v starlark.Sequence) ([]string, error) {
+	namespaces, err := value.SequenceToStringSlice(v)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid namespace %q: %s", ns, strings.Join(errs, "; "))
+		}
+		if seen[ns] {
+			return nil, fmt.Errorf("namespace %q is listed more than once", ns)
+		}
+		seen[ns] = true
+	}
+	return namespaces, nil
+}
+
+func (s *tiltfileState) devResourceProfileFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var scaleVal starlark.Value
+	removeAntiAffinity := true
+	localOnly := true
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"scale?", &scaleVal,
+		"remove_anti_affinity?", &removeAntiAffinity,
+		"local_only?", &localOnly,
+	); err != nil {
+		return nil, err
+	}
+
+	if s.devResourceProfileCallPosition.IsValid() {
+		return starlark.None, fmt.Errorf("%s can only be called once. It was already called at %s", fn.Name(), s.devResourceProfileCallPosition.String())
+	}
+
+	scale, err := resourceScaleFromStarlarkValue(scaleVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: scale", fn.Name())
+	}
+
+	profile := model.DevResourceProfile{
+		RemoveAntiAffinity: removeAntiAffinity,
+		LocalOnly:          localOnly,
+	}
+	s.devResourceProfile = withResourceScale(profile, scale)
+	s.devResourceProfileCallPosition = thread.CallFrame(1).Pos
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) k8sManagedLabelsFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var prefix string
+	var objLabels, annotations value.StringStringMap
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"labels?", &objLabels,
+		"annotations?", &annotations,
+		"prefix?", &prefix,
+	); err != nil {
+		return nil, err
+	}
+
+	if s.k8sObjectLabelsCallPosition.IsValid() {
+		return starlark.None, fmt.Errorf("%s can only be called once. It was already called at %s", fn.Name(), s.k8sObjectLabelsCallPosition.String())
+	}
+
+	if prefix != "" {
+		if errs := validation.IsDNS1123Subdomain(prefix); len(errs) > 0 {
+			return nil, fmt.Errorf("%s: prefix %q: %s", fn.Name(), prefix, strings.Join(errs, "; "))
+		}
+	}
+
+	labelPairs := sortedLabelPairs(objLabels)
+	for _, lp := range labelPairs {
+		errs := append(validation.IsQualifiedName(lp.Key), validation.IsValidLabelValue(lp.Value)...)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("%s: label %q: %s", fn.Name(), lp.Key, strings.Join(errs, "; "))
+		}
+	}
+
+	annotationPairs := sortedLabelPairs(annotations)
+	for _, ap := range annotationPairs {
+		if errs := validation.IsQualifiedName(ap.Key); len(errs) > 0 {
+			return nil, fmt.Errorf("%s: annotation %q: %s", fn.Name(), ap.Key, strings.Join(errs, "; "))
+		}
+	}
+
+	s.k8sObjectLabels = model.K8sObjectLabels{
+		Prefix:      prefix,
+		Labels:      labelPairs,
+		Annotations: annotationPairs,
+	}
+	s.k8sObjectLabelsCallPosition = thread.CallFrame(1).Pos
+
+	return starlark.None, nil
+}
+
+// Sorted by key, so that the K8sTarget doesn't change between Tiltfile loads.
+func sortedLabelPairs(m value.StringStringMap) []model.LabelPair {
+	pairs := model.ToLabelPairs(m.AsMap())
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key < pairs[j].Key
+	})
+	return pairs
+}
+
+// Returns nil if no duration was specified.
+func durationSecsFromStarlarkValue(v starlark.Value) (*time.Duration, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+
+	// Kubernetes only deals in whole seconds.
+	i, ok := v.(starlark.Int)
+	if !ok {
+		return nil, fmt.Errorf("expected a whole number of seconds, got %s of type %s", v.String(), v.Type())
+	}
+	secs, ok := i.Int64()
+	if !ok {
+		return nil, fmt.Errorf("too large: %s", v.String())
+	}
+	if secs < 0 {
+		return nil, fmt.Errorf("must be >= 0, got %v", secs)
+	}
+	d := time.Duration(secs) * time.Second
+	return &d, nil
+}
+
+// A scale of 0 means "strip resource requirements entirely".
+// Returns nil if no scale was specified.
+func resourceScaleFromStarlarkValue(v starlark.Value) (*float64, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+
+	scale, ok := starlark.AsFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s of type %s", v.String(), v.Type())
+	}
+	if scale < 0 {
+		return nil, fmt.Errorf("must be >= 0, got %v", scale)
+	}
+	return &scale, nil
+}
+
+func withResourceScale(profile model.DevResourceProfile, scale *float64) model.DevResourceProfile {
+	if scale == nil {
+		return profile
+	}
+	profile.StripResources = *scale == 0
+	profile.Scale = *scale
+	return profile
+}
+
 func selectorFromSkylarkDict(d *starlark.Dict) (labels.Selector, error) {
 	ret := make(labels.Set)
 
@@ -592,12 +863,14 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 	var jpLocators tiltfile_k8s.JSONPathImageLocatorListSpec
 	var jpObjectLocator tiltfile_k8s.JSONPathImageObjectLocatorSpec
 	var podReadiness tiltfile_k8s.PodReadinessMode
+	var podTemplatePath string
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"kind", &kind,
 		"image_json_path?", &jpLocators,
 		"api_version?", &apiVersion,
 		"image_object?", &jpObjectLocator,
 		"pod_readiness?", &podReadiness,
+		"pod_template_json_path?", &podTemplatePath,
 	); err != nil {
 		return nil, err
 	}
@@ -636,6 +909,14 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 		kindInfo.PodReadinessMode = podReadiness.Value
 	}
 
+	if podTemplatePath != "" {
+		locator, err := k8s.NewPodTemplateLocator(k, podTemplatePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: pod_template_json_path", fn.Name())
+		}
+		kindInfo.PodTemplateLocator = locator
+	}
+
 	return starlark.None, nil
 }
 
@@ -656,11 +937,26 @@ func (s *tiltfileState) workloadToResourceFunctionFn(thread *starlark.Thread, fn
 	return starlark.None, nil
 }
 
+func (s *tiltfileState) k8sAutoGroupFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var enabled bool
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"enabled", &enabled); err != nil {
+		return nil, err
+	}
+
+	s.k8sAutoGroup = enabled
+
+	return starlark.None, nil
+}
+
 type k8sObjectID struct {
 	name      string
 	kind      string
 	namespace string
 	group     string
+
+	// Not part of the identity, but handy for deciding what resource a workload belongs to.
+	labels *starlark.Dict
 }
 
 func (k k8sObjectID) Attr(name string) (starlark.Value, error) {
@@ -673,13 +969,18 @@ func (k k8sObjectID) Attr(name string) (starlark.Value, error) {
 		return starlark.String(k.namespace), nil
 	case "group":
 		return starlark.String(k.group), nil
+	case "labels":
+		if k.labels == nil {
+			return starlark.NewDict(0), nil
+		}
+		return k.labels, nil
 	default:
 		return starlark.None, fmt.Errorf("%T has no attribute '%s'", k, name)
 	}
 }
 
 func (k k8sObjectID) AttrNames() []string {
-	return []string{"name", "kind", "namespace", "group"}
+	return []string{"name", "kind", "namespace", "group", "labels"}
 }
 
 func (k k8sObjectID) String() string {
@@ -691,6 +992,9 @@ func (k k8sObjectID) Type() string {
 }
 
 func (k k8sObjectID) Freeze() {
+	if k.labels != nil {
+		k.labels.Freeze()
+	}
 }
 
 func (k k8sObjectID) Truth() starlark.Bool {
@@ -704,6 +1008,7 @@ func (k k8sObjectID) Hash() (uint32, error) {
 var _ starlark.Value = k8sObjectID{}
 
 type workloadToResourceFunction struct {
+	// Returns "" if the workload should get its default name.
 	fn  func(thread *starlark.Thread, id k8sObjectID) (string, error)
 	pos syntax.Position
 }
@@ -717,9 +1022,15 @@ func makeWorkloadToResourceFunction(f *starlark.Function) (workloadToResourceFun
 		if err != nil {
 			return "", err
 		}
+		if ret == starlark.None {
+			return "", nil
+		}
 		s, ok := ret.(starlark.String)
 		if !ok {
-			return "", fmt.Errorf("%s: invalid return value. wanted: string. got: %T", f.Name(), ret)
+			return "", fmt.Errorf("%s: invalid return value. wanted: string or None. got: %T", f.Name(), ret)
+		}
+		if s == "" {
+			return "", fmt.Errorf("%s: invalid return value. resource name must not be empty", f.Name())
 		}
 		return string(s), nil
 	}
@@ -785,7 +1096,7 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 			return nil, errors.Wrap(err, "error reading yaml file")
 		}
 
-		entities, err := k8s.ParseYAMLFromString(string(bs))
+		entities, lines, err := k8s.ParseYAMLFromStringWithLines(string(bs))
 		if err != nil {
 			if strings.Contains(err.Error(), "json parse error: ") {
 				return entities, fmt.Errorf("%s is not a valid YAML file: %s", yamlPath, err)
@@ -793,6 +1104,10 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 			return entities, err
 		}
 
+		for i, e := range entities {
+			s.k8sSources[entityKey(e)] = fmt.Sprintf("%s:%d", yamlPath, lines[i])
+		}
+
 		return entities, nil
 	}
 }
@@ -854,14 +1169,44 @@ func convertPortForwards(val starlark.Value) ([]model.PortForward, error) {
 func (s *tiltfileState) portForward(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var local int
 	var container int
+	var service string
+	var host string
+	var protocol string
 
-	if err := s.unpackArgs(fn.Name(), args, kwargs, "local", &local, "container?", &container); err != nil {
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"local", &local,
+		"container?", &container,
+		"service?", &service,
+		"host?", &host,
+		"protocol?", &protocol); err != nil {
 		return nil, err
 	}
 
-	return portForward{
-		model.PortForward{LocalPort: local, ContainerPort: container},
-	}, nil
+	if host != "" && !validHost.MatchString(host) {
+		return nil, fmt.Errorf("%s: host value %q is not a valid hostname or IP address", fn.Name(), host)
+	}
+
+	pf := model.PortForward{LocalPort: local, ContainerPort: container, Service: service, Host: host}
+	var err error
+	pf.Protocol, err = parsePortForwardProtocol(protocol)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	if pf.IsUDP() && service != "" {
+		return nil, fmt.Errorf("%s: UDP port forwards to a service are not supported", fn.Name())
+	}
+
+	return portForward{pf}, nil
+}
+
+func parsePortForwardProtocol(s string) (model.PortForwardProtocol, error) {
+	switch strings.ToLower(s) {
+	case "", "tcp":
+		return model.PortForwardTCP, nil
+	case "udp":
+		return model.PortForwardUDP, nil
+	}
+	return "", fmt.Errorf("protocol must be one of \"tcp\" or \"udp\" (got: %q)", s)
 }
 
 type portForward struct {
@@ -871,7 +1216,17 @@ type portForward struct {
 var _ starlark.Value = portForward{}
 
 func (f portForward) String() string {
-	return fmt.Sprintf("port_forward(%d, %d)", f.LocalPort, f.ContainerPort)
+	args := fmt.Sprintf("%d, %d", f.LocalPort, f.ContainerPort)
+	if f.Service != "" {
+		args += fmt.Sprintf(", service=%q", f.Service)
+	}
+	if f.Host != "" {
+		args += fmt.Sprintf(", host=%q", f.Host)
+	}
+	if f.IsUDP() {
+		args += ", protocol=\"udp\""
+	}
+	return fmt.Sprintf("port_forward(%s)", args)
 }
 
 func (f portForward) Type() string {
@@ -904,8 +1259,20 @@ const hostnameReStr = `^(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*(
 
 var validHost = regexp.MustCompile(ipReStr + "|" + hostnameReStr)
 
+// Parses "[host:]local[:container][/protocol]", like kubectl port-forward.
 func stringToPortForward(s starlark.String) (model.PortForward, error) {
-	parts := strings.SplitN(string(s), ":", 3)
+	spec := string(s)
+	var protocol model.PortForwardProtocol
+	if i := strings.LastIndex(spec, "/"); i != -1 {
+		var err error
+		protocol, err = parsePortForwardProtocol(spec[i+1:])
+		if err != nil {
+			return model.PortForward{}, fmt.Errorf("portForward %q: %v", spec, err)
+		}
+		spec = spec[:i]
+	}
+
+	parts := strings.SplitN(spec, ":", 3)
 
 	var host string
 	var localString string
@@ -932,7 +1299,7 @@ func stringToPortForward(s starlark.String) (model.PortForward, error) {
 			return model.PortForward{}, fmt.Errorf("portForward port value %q is not in the valid range [0-65535]", last)
 		}
 	}
-	return model.PortForward{LocalPort: local, ContainerPort: container, Host: host}, nil
+	return model.PortForward{LocalPort: local, ContainerPort: container, Host: host, Protocol: protocol}, nil
 }
 
 func (s *tiltfileState) k8sResourceAssemblyVersionFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -978,6 +1345,7 @@ func (s *tiltfileState) calculateResourceNames(workloads []k8s.K8sEntity) ([]str
 func (s *tiltfileState) workloadToResourceFunctionNames(workloads []k8s.K8sEntity) ([]string, error) {
 	takenNames := make(map[string]k8s.K8sEntity)
 	ret := make([]string, len(workloads))
+	defaultNames := k8s.UniqueNames(workloads, 1)
 	thread := &starlark.Thread{
 		Print: s.print,
 	}
@@ -987,6 +1355,9 @@ func (s *tiltfileState) workloadToResourceFunctionNames(workloads []k8s.K8sEntit
 		if err != nil {
 			return nil, errors.Wrapf(err, "error determining resource name for '%s'", id.String())
 		}
+		if name == "" {
+			name = defaultNames[i]
+		}
 
 		if conflictingWorkload, ok := takenNames[name]; ok {
 			return nil, fmt.Errorf("both '%s' and '%s' mapped to resource name '%s'", newK8sObjectID(e).String(), newK8sObjectID(conflictingWorkload).String(), name)
@@ -1000,10 +1371,24 @@ func (s *tiltfileState) workloadToResourceFunctionNames(workloads []k8s.K8sEntit
 
 func newK8sObjectID(e k8s.K8sEntity) k8sObjectID {
 	gvk := e.GVK()
+
+	entityLabels := e.Labels()
+	keys := make([]string, 0, len(entityLabels))
+	for key := range entityLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	labels := starlark.NewDict(len(keys))
+	for _, key := range keys {
+		_ = labels.SetKey(starlark.String(key), starlark.String(entityLabels[key]))
+	}
+	labels.Freeze()
+
 	return k8sObjectID{
 		name:      e.Name(),
 		kind:      gvk.Kind,
 		namespace: e.Namespace().String(),
 		group:     gvk.Group,
+		labels:    labels,
 	}
 }
@@ -8,4 +8,8 @@ import (
 type KindInfo struct {
 	ImageLocators    []k8s.ImageLocator
 	PodReadinessMode model.PodReadinessMode
+
+	// Where this kind keeps its pod templates, if it creates pods like a
+	// Deployment does (e.g., an Argo Rollout).
+	PodTemplateLocator *k8s.PodTemplateLocator
 }
@@ -0,0 +1,99 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements seed_sql(), for k8s_resource(seed=...).
+//
+// Tilt pipes the file into cmd in the resource's pod, once the pod is ready.
+// By default, cmd is psql with the settings of the official postgres image.
+func (s *tiltfileState) seedSQL(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	var on string
+	var cmdVal starlark.Value
+	var container string
+
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"path", &path,
+		"on?", &on,
+		"cmd?", &cmdVal,
+		"container?", &container); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("%s: path must not be empty", fn.Name())
+	}
+
+	trigger, err := parseSeedTrigger(on)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	cmd, err := value.ValueToUnixCmd(cmdVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: cmd: %v", fn.Name(), err)
+	}
+	if cmd.Empty() {
+		cmd = model.DefaultSeedSQLCmd
+	}
+
+	return seedSpec{model.K8sSeed{
+		Path:      starkit.AbsPath(thread, path),
+		Cmd:       cmd,
+		Container: container,
+		On:        trigger,
+	}}, nil
+}
+
+func parseSeedTrigger(s string) (model.SeedTrigger, error) {
+	switch model.SeedTrigger(s) {
+	case "", model.SeedOnFirstDeploy:
+		return model.SeedOnFirstDeploy, nil
+	case model.SeedOnEveryDeploy:
+		return model.SeedOnEveryDeploy, nil
+	}
+	return "", fmt.Errorf("on must be one of %q or %q (got: %q)",
+		model.SeedOnFirstDeploy, model.SeedOnEveryDeploy, s)
+}
+
+type seedSpec struct {
+	model.K8sSeed
+}
+
+var _ starlark.Value = seedSpec{}
+
+func (s seedSpec) String() string {
+	return fmt.Sprintf("seed_sql(%q, on=%q)", s.Path, s.On)
+}
+
+func (s seedSpec) Type() string {
+	return "seed"
+}
+
+func (s seedSpec) Freeze() {}
+
+func (s seedSpec) Truth() starlark.Bool {
+	return starlark.Bool(!s.Empty())
+}
+
+func (s seedSpec) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: seed")
+}
+
+func seedFromStarlarkValue(v starlark.Value) (model.K8sSeed, error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return model.K8sSeed{}, nil
+	case seedSpec:
+		return x.K8sSeed, nil
+	}
+	return model.K8sSeed{}, fmt.Errorf("must be a seed_sql(); is a %s", v.Type())
+}
@@ -0,0 +1,94 @@
+package tiltfile
+
+import (
+	"context"
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements k8s_resource(transform=fn).
+//
+// The engine calls fn at deploy time, after it injects images, with the
+// resource's objects decoded as in decode_yaml_stream(). fn returns the
+// changed objects.
+//
+// fn runs after the Tiltfile has finished loading, so it can only use
+// builtins that don't depend on the Tiltfile (e.g., it can't call local()).
+type k8sTransform struct {
+	fn *starlark.Function
+
+	// What fn did to the resource's objects when the Tiltfile loaded.
+	// The function is a new value every time the Tiltfile loads, so we
+	// compare this instead, to avoid redeploying on every reload.
+	fingerprint string
+}
+
+func newK8sTransform(ctx context.Context, fn *starlark.Function, entities []k8s.K8sEntity) (*k8sTransform, error) {
+	t := &k8sTransform{fn: fn}
+
+	// Try the transform on the objects without images, so that a broken
+	// transform is a Tiltfile error rather than a deploy error.
+	result, err := k8s.ApplyTransform(ctx, t, entities)
+	if err != nil {
+		return nil, err
+	}
+	yaml, err := k8s.SerializeSpecYAML(result)
+	if err != nil {
+		return nil, err
+	}
+	t.fingerprint = fmt.Sprintf("%s\n%s", fn.Position().String(), yaml)
+	return t, nil
+}
+
+func (t *k8sTransform) EqualsK8sTransform(other interface{}) bool {
+	o, ok := other.(*k8sTransform)
+	if !ok {
+		return false
+	}
+	return t.fingerprint == o.fingerprint
+}
+
+func (t *k8sTransform) Transform(ctx context.Context, entities []k8s.K8sEntity) ([]k8s.K8sEntity, error) {
+	yaml, err := k8s.SerializeSpecYAML(entities)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := encoding.DecodeYAMLStream(yaml)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{
+		Name: fmt.Sprintf("transform %s", t.fn.Name()),
+		Print: func(_ *starlark.Thread, msg string) {
+			logger.Get(ctx).Infof("%s", msg)
+		},
+	}
+	ret, err := starlark.Call(thread, t.fn, starlark.Tuple{objs}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", t.fn.Name(), err)
+	}
+
+	retList, ok := ret.(*starlark.List)
+	if !ok {
+		return nil, fmt.Errorf("transform %s: invalid return value. wanted: list. got: %s", t.fn.Name(), ret.Type())
+	}
+	newYAML, err := encoding.EncodeYAMLStream(retList)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", t.fn.Name(), err)
+	}
+	result, err := k8s.ParseYAMLFromString(newYAML)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", t.fn.Name(), err)
+	}
+	return result, nil
+}
+
+var _ model.K8sTransform = &k8sTransform{}
+var _ k8s.EntityTransform = &k8sTransform{}
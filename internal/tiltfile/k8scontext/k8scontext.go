@@ -24,6 +24,14 @@ func NewExtension(context k8s.KubeContext, env k8s.Env) Extension {
 	}
 }
 
+func (e Extension) KubeContext() k8s.KubeContext {
+	return e.context
+}
+
+func (e Extension) Env() k8s.Env {
+	return e.env
+}
+
 func (e Extension) NewState() interface{} {
 	return State{context: e.context, env: e.env}
 }
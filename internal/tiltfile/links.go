@@ -0,0 +1,92 @@
+package tiltfile
+
+import (
+	"fmt"
+	"net/url"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const linkN = "link"
+
+// A URL to show on a resource in the web UI, from link(url, name).
+type link struct {
+	model.Link
+}
+
+var _ starlark.Value = link{}
+
+func (l link) String() string {
+	if l.Name == "" {
+		return fmt.Sprintf("link(%q)", l.URL)
+	}
+	return fmt.Sprintf("link(%q, %q)", l.URL, l.Name)
+}
+
+func (l link) Type() string {
+	return "link"
+}
+
+func (l link) Freeze() {}
+
+func (l link) Truth() starlark.Bool {
+	return l.URL != ""
+}
+
+func (l link) Hash() (uint32, error) {
+	return starlark.String(l.URL).Hash()
+}
+
+func (s *tiltfileState) linkFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var u, name string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"url", &u,
+		"name?", &name); err != nil {
+		return nil, err
+	}
+
+	err := validateLinkURL(u)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return link{model.Link{URL: u, Name: name}}, nil
+}
+
+// Browsers can only open absolute http(s) URLs from the web UI.
+func validateLinkURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", u, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url %q must start with http:// or https://", u)
+	}
+	return nil
+}
+
+// Converts the links= argument of a resource, which may be a URL, a link(),
+// or a list of them.
+func convertLinks(val starlark.Value) ([]model.Link, error) {
+	if val == nil || val == starlark.None {
+		return nil, nil
+	}
+
+	var result []model.Link
+	for _, v := range starlarkValueOrSequenceToSlice(val) {
+		switch v := v.(type) {
+		case starlark.String:
+			err := validateLinkURL(v.GoString())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, model.Link{URL: v.GoString()})
+		case link:
+			result = append(result, v.Link)
+		default:
+			return nil, fmt.Errorf("links must be a string, a link, or a list of them; found a %s", v.Type())
+		}
+	}
+	return result, nil
+}
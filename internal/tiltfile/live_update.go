@@ -127,6 +127,26 @@ func (l liveUpdateRestartContainerStep) Hash() (uint32, error)  { return 0, nil
 func (l liveUpdateRestartContainerStep) declarationPos() string { return l.position.String() }
 func (l liveUpdateRestartContainerStep) liveUpdateStep()        {}
 
+type liveUpdateRestartResourceStep struct {
+	resource string
+	position syntax.Position
+}
+
+var _ starlark.Value = liveUpdateRestartResourceStep{}
+var _ liveUpdateStep = liveUpdateRestartResourceStep{}
+
+func (l liveUpdateRestartResourceStep) String() string {
+	return fmt.Sprintf("restart_resource step: '%s'", l.resource)
+}
+func (l liveUpdateRestartResourceStep) Type() string         { return "live_update_restart_resource_step" }
+func (l liveUpdateRestartResourceStep) Freeze()              {}
+func (l liveUpdateRestartResourceStep) Truth() starlark.Bool { return len(l.resource) > 0 }
+func (l liveUpdateRestartResourceStep) Hash() (uint32, error) {
+	return starlark.String(l.resource).Hash()
+}
+func (l liveUpdateRestartResourceStep) declarationPos() string { return l.position.String() }
+func (l liveUpdateRestartResourceStep) liveUpdateStep()        {}
+
 func (s *tiltfileState) recordLiveUpdateStep(step liveUpdateStep) {
 	s.unconsumedLiveUpdateSteps[step.declarationPos()] = step
 }
@@ -213,6 +233,23 @@ func (s *tiltfileState) liveUpdateRestartContainer(thread *starlark.Thread, fn *
 	return ret, nil
 }
 
+func (s *tiltfileState) liveUpdateRestartResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource string
+	if err := s.unpackArgs(fn.Name(), args, kwargs, "resource", &resource); err != nil {
+		return nil, err
+	}
+	if resource == "" {
+		return nil, fmt.Errorf("%s: resource cannot be empty", fn.Name())
+	}
+
+	ret := liveUpdateRestartResourceStep{
+		resource: resource,
+		position: thread.CallFrame(1).Pos,
+	}
+	s.recordLiveUpdateStep(ret)
+	return ret, nil
+}
+
 func (s *tiltfileState) liveUpdateStepToModel(t *starlark.Thread, l liveUpdateStep) (model.LiveUpdateStep, error) {
 	switch x := l.(type) {
 	case liveUpdateFallBackOnStep:
@@ -235,6 +272,8 @@ func (s *tiltfileState) liveUpdateStepToModel(t *starlark.Thread, l liveUpdateSt
 		}, nil
 	case liveUpdateRestartContainerStep:
 		return model.LiveUpdateRestartContainerStep{}, nil
+	case liveUpdateRestartResourceStep:
+		return model.LiveUpdateRestartResourceStep{Resource: model.ManifestName(x.resource)}, nil
 	default:
 		return nil, fmt.Errorf("internal error - unknown liveUpdateStep '%v' of type '%T', declared at %s", l, l, l.declarationPos())
 	}
@@ -262,6 +301,22 @@ func (s *tiltfileState) liveUpdateFromSteps(t *starlark.Thread, maybeSteps starl
 	return model.NewLiveUpdate(modelSteps, starkit.AbsWorkingDir(t))
 }
 
+// Applies the fall_back_on_change_count argument of docker_build and custom_build.
+func liveUpdateWithFallBackOnChangeCount(lu model.LiveUpdate, v starlark.Value) (model.LiveUpdate, error) {
+	if v == nil || v == starlark.None {
+		return lu, nil
+	}
+
+	n, err := starlark.AsInt32(v)
+	if err != nil {
+		return model.LiveUpdate{}, fmt.Errorf("fall_back_on_change_count: %v", err)
+	}
+	if n < 0 {
+		return model.LiveUpdate{}, fmt.Errorf("fall_back_on_change_count must be >= 0 (0 to never fall back); got %d", n)
+	}
+	return lu.WithMaxChangedFiles(n), nil
+}
+
 func (s *tiltfileState) consumeLiveUpdateStep(stepToConsume liveUpdateStep) {
 	delete(s.unconsumedLiveUpdateSteps, stepToConsume.declarationPos())
 }
@@ -169,6 +169,42 @@ func TestLiveUpdateCustomBuild(t *testing.T) {
 	f.assertNextManifest("foo", cb(image("foo"), f.expectedLU))
 }
 
+func TestLiveUpdateFallBackOnChangeCount(t *testing.T) {
+	f := newLiveUpdateFixture(t)
+	defer f.TearDown()
+
+	f.tiltfileCode = "docker_build('foo', 'foo', live_update=%s, fall_back_on_change_count=20)"
+	f.init()
+
+	f.load("foo")
+
+	f.expectedLU = f.expectedLU.WithMaxChangedFiles(20)
+	f.assertNextManifest("foo", db(image("foo"), f.expectedLU))
+}
+
+func TestLiveUpdateFallBackOnChangeCountDisabledCustomBuild(t *testing.T) {
+	f := newLiveUpdateFixture(t)
+	defer f.TearDown()
+
+	f.tiltfileCode = "custom_build('foo', 'docker build -t $TAG foo', ['foo'], live_update=%s, fall_back_on_change_count=0)"
+	f.init()
+
+	f.load("foo")
+
+	f.expectedLU = f.expectedLU.WithMaxChangedFiles(0)
+	f.assertNextManifest("foo", cb(image("foo"), f.expectedLU))
+}
+
+func TestLiveUpdateFallBackOnChangeCountNegative(t *testing.T) {
+	f := newLiveUpdateFixture(t)
+	defer f.TearDown()
+
+	f.tiltfileCode = "docker_build('foo', 'foo', live_update=%s, fall_back_on_change_count=-1)"
+	f.init()
+
+	f.loadErrString("fall_back_on_change_count must be >= 0 (0 to never fall back); got -1")
+}
+
 func TestLiveUpdateSyncFilesOutsideOfDockerBuildContext(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -392,6 +428,64 @@ docker_compose('docker-compose.yml')
 	f.assertNextManifest("foo", db(image("gcr.io/foo")))
 }
 
+func TestLiveUpdateRestartResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+local_resource('cache', serve_cmd='./cache.sh')
+docker_build('gcr.io/foo', 'foo',
+  live_update=[
+    sync('foo', '/baz'),
+    restart_resource('cache'),
+  ]
+)`)
+	f.load()
+
+	lu := model.LiveUpdate{
+		Steps: []model.LiveUpdateStep{
+			model.LiveUpdateSyncStep{Source: f.JoinPath("foo"), Dest: "/baz"},
+			model.LiveUpdateRestartResourceStep{Resource: "cache"},
+		},
+		BaseDir: f.Path(),
+	}
+	f.assertNextManifest("foo", db(image("gcr.io/foo"), lu))
+}
+
+func TestLiveUpdateRestartResourceUnknown(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo',
+  live_update=[
+    sync('foo', '/baz'),
+    restart_resource('cache'),
+  ]
+)`)
+	f.loadErrString("live_update restart_resource() step for unknown resource cache")
+}
+
+func TestLiveUpdateRestartResourceSelf(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo',
+  live_update=[
+    sync('foo', '/baz'),
+    restart_resource('foo'),
+  ]
+)`)
+	f.loadErrString("Use restart_container() instead")
+}
+
 type liveUpdateFixture struct {
 	*fixture
 
@@ -0,0 +1,89 @@
+package loadsettings
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+// What Tilt does when one resource's config is broken, e.g., a k8s_resource
+// with a dependency on a resource that doesn't exist.
+type OnError string
+
+const (
+	// Fail the whole Tiltfile load, and keep running the last good config
+	// of every resource. (The default.)
+	OnErrorFailFast OnError = "fail_fast"
+
+	// Mark only the broken resource with a config error, and load the
+	// rest of the resources as usual.
+	OnErrorContinue OnError = "continue_on_error"
+)
+
+type Settings struct {
+	OnError OnError
+}
+
+func (s Settings) ContinueOnError() bool {
+	return s.OnError == OnErrorContinue
+}
+
+// Implements the load_settings() builtin, which controls what happens
+// when part of the Tiltfile's config is broken.
+//
+// Errors while the Tiltfile executes (like a fail() or a bad argument)
+// always fail the load, because there's no telling what the rest of
+// the Tiltfile would have done.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (Extension) NewState() interface{} {
+	return Settings{OnError: OnErrorFailFast}
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("load_settings", setLoadSettings)
+}
+
+func setLoadSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings Settings) (Settings, error) {
+		var onError string
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"on_error?", &onError)
+		if err != nil {
+			return Settings{}, err
+		}
+
+		switch OnError(onError) {
+		case "":
+		case OnErrorFailFast, OnErrorContinue:
+			settings.OnError = OnError(onError)
+		default:
+			return Settings{}, fmt.Errorf("%s: on_error must be one of %q or %q (got: %q)",
+				fn.Name(), OnErrorFailFast, OnErrorContinue, onError)
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) Settings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (Settings, error) {
+	var state Settings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,59 @@
+package loadsettings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func TestLoadSettingsDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, OnErrorFailFast, MustState(result).OnError)
+	assert.False(t, MustState(result).ContinueOnError())
+}
+
+func TestLoadSettingsContinueOnError(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "load_settings(on_error='continue_on_error')")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).ContinueOnError())
+}
+
+func TestLoadSettingsFailFast(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", `
+load_settings(on_error='continue_on_error')
+load_settings(on_error='fail_fast')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).ContinueOnError())
+}
+
+func TestLoadSettingsInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "load_settings(on_error='shrug')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `on_error must be one of "fail_fast" or "continue_on_error" (got: "shrug")`)
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
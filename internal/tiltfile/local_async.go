@@ -0,0 +1,219 @@
+package tiltfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"go.starlark.net/starlark"
+
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A command started by local_async(), which may still be running.
+type localAsyncHandle struct {
+	label string
+	cmd   model.Cmd
+	done  chan struct{}
+
+	// Set once done is closed.
+	out string
+	err error
+
+	// Whether the Tiltfile has called wait_all() on this handle.
+	// Only touched on the Starlark thread.
+	waited bool
+}
+
+var _ starlark.Value = &localAsyncHandle{}
+
+func (h *localAsyncHandle) String() string {
+	return fmt.Sprintf("<local_async %s: %s>", h.label, h.cmd)
+}
+func (h *localAsyncHandle) Type() string         { return "local_async_handle" }
+func (h *localAsyncHandle) Freeze()              {}
+func (h *localAsyncHandle) Truth() starlark.Bool { return starlark.True }
+func (h *localAsyncHandle) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", h.Type())
+}
+
+func (h *localAsyncHandle) wait() (string, error) {
+	<-h.done
+	return h.out, h.err
+}
+
+func (s *tiltfileState) localAsync(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var commandValue, commandBatValue starlark.Value
+	quiet := false
+	echoOff := false
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"command", &commandValue,
+		"quiet?", &quiet,
+		"command_bat", &commandBatValue,
+		"echo_off", &echoOff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := value.ValueGroupToCmdHelper(commandValue, commandBatValue)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &localAsyncHandle{
+		label: fmt.Sprintf("[%d]", len(s.localAsyncHandles)+1),
+		cmd:   cmd,
+		done:  make(chan struct{}),
+	}
+	s.localAsyncHandles = append(s.localAsyncHandles, h)
+
+	if !echoOff {
+		s.logger.Infof("local_async %s: %s", h.label, cmd)
+	}
+
+	c := exec.Command(cmd.Argv[0], cmd.Argv[1:]...)
+	c.Dir = starkit.AbsWorkingDir(thread)
+
+	// Fakes answer right away, and aren't safe to call off the Starlark thread.
+	if out, handled, err := s.fakeLocalCmd(c); handled {
+		h.out, h.err = out, err
+		close(h.done)
+		return h, nil
+	}
+
+	l := logger.NewPrefixedLogger(fmt.Sprintf("%s%s ", localLogPrefix, h.label), s.logger)
+	var out io.Writer
+	var lw *lineWriter
+	if !quiet {
+		lw = newLineWriter(l.Writer(logger.InfoLvl), &s.localAsyncOutputMu)
+		out = lw
+	}
+
+	go func() {
+		defer close(h.done)
+		h.out, h.err = s.runLocalCmd(c, out, l)
+		if lw != nil {
+			lw.Flush()
+		}
+	}()
+
+	return h, nil
+}
+
+func (s *tiltfileState) waitAll(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var handlesValue starlark.Value
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"handles", &handlesValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	values := starlarkValueOrSequenceToSlice(handlesValue)
+	handles := make([]*localAsyncHandle, 0, len(values))
+	for _, v := range values {
+		h, ok := v.(*localAsyncHandle)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected handles from local_async(), got %s", fn.Name(), v.Type())
+		}
+		handles = append(handles, h)
+	}
+
+	// Wait for all of them, even after one fails, so that nothing
+	// is still running when we report the error.
+	results := make([]starlark.Value, 0, len(handles))
+	var errs []string
+	for _, h := range handles {
+		h.waited = true
+		out, err := h.wait()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("local_async %s: %v", h.label, err))
+			continue
+		}
+		results = append(results, tiltfile_io.NewBlob(out, fmt.Sprintf("local_async: %s", h.cmd)))
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return starlark.NewList(results), nil
+}
+
+// Waits for the commands that the Tiltfile started but never waited on,
+// so that none of them outlive the Tiltfile load.
+//
+// Returns an error if any of them failed, because otherwise nobody would see it.
+func (s *tiltfileState) waitForUnwaitedLocalAsync() error {
+	var errs []string
+	for _, h := range s.localAsyncHandles {
+		if h.waited {
+			continue
+		}
+		h.waited = true
+		_, err := h.wait()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("local_async %s: %v", h.label, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("local_async commands that were never passed to wait_all() failed:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// Buffers output until it has whole lines, so that output from commands
+// running in parallel doesn't interleave in the middle of a line.
+type lineWriter struct {
+	out   io.Writer
+	outMu *sync.Mutex // Shared by all the commands writing to the same log.
+
+	mu  sync.Mutex // Guards buf. stdout and stderr write from different goroutines.
+	buf []byte
+}
+
+func newLineWriter(out io.Writer, outMu *sync.Mutex) *lineWriter {
+	return &lineWriter{out: out, outMu: outMu}
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, b...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i == -1 {
+		return len(b), nil
+	}
+
+	err := w.write(w.buf[:i+1])
+	w.buf = append([]byte(nil), w.buf[i+1:]...)
+	return len(b), err
+}
+
+// Writes out any partial line left at the end of the output.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return
+	}
+	_ = w.write(append(w.buf, '\n'))
+	w.buf = nil
+}
+
+func (w *lineWriter) write(b []byte) error {
+	w.outMu.Lock()
+	defer w.outMu.Unlock()
+	_, err := w.out.Write(b)
+	return err
+}
@@ -0,0 +1,29 @@
+package tiltfile
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := newLineWriter(out, &sync.Mutex{})
+
+	_, _ = w.Write([]byte("hel"))
+	assert.Equal(t, "", out.String())
+
+	_, _ = w.Write([]byte("lo\nwor"))
+	assert.Equal(t, "hello\n", out.String())
+
+	_, _ = w.Write([]byte("ld\nfoo\nba"))
+	assert.Equal(t, "hello\nworld\nfoo\n", out.String())
+
+	w.Flush()
+	assert.Equal(t, "hello\nworld\nfoo\nba\n", out.String())
+
+	w.Flush()
+	assert.Equal(t, "hello\nworld\nfoo\nba\n", out.String())
+}
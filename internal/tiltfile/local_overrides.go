@@ -0,0 +1,215 @@
+package tiltfile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The per-user Tiltfile is named after the main one, e.g., Tiltfile.local.
+//
+// It's meant to be gitignored, so that everyone on a team can tweak
+// their own setup without `if os.path.exists(...): load(...)` guards.
+const localTiltfileSuffix = ".local"
+
+const (
+	overrideDockerBuildN = "override_docker_build"
+	disableResourceN     = "disable_resource"
+	addPortForwardN      = "add_port_forward"
+)
+
+func LocalTiltfilePath(absFilename string) string {
+	return absFilename + localTiltfileSuffix
+}
+
+// Changes that Tiltfile.local asked for, applied once the resources are assembled.
+type localOverrides struct {
+	disabledResources []string
+	portForwards      map[string][]model.PortForward
+}
+
+// Executes the per-user Tiltfile, if there is one, after the main Tiltfile finishes.
+func (s *tiltfileState) OnFinish(t *starlark.Thread, globals starlark.StringDict) error {
+	path := LocalTiltfilePath(starkit.CurrentExecPath(t))
+	_, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	s.execingLocalTiltfile = true
+	defer func() {
+		s.execingLocalTiltfile = false
+	}()
+
+	_, err = t.Load(t, path)
+	return err
+}
+
+func (s *tiltfileState) checkLocalTiltfile(fn *starlark.Builtin) error {
+	if !s.execingLocalTiltfile {
+		return fmt.Errorf("%s can only be called from Tiltfile%s", fn.Name(), localTiltfileSuffix)
+	}
+	return nil
+}
+
+// override_docker_build(ref, build_args={}, target="")
+//
+// Changes a docker_build() from the main Tiltfile. Build args are merged into the existing ones.
+func (s *tiltfileState) overrideDockerBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := s.checkLocalTiltfile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var dockerRef, targetStage string
+	var buildArgs value.StringStringMap
+	err = s.unpackArgs(fn.Name(), args, kwargs,
+		"ref", &dockerRef,
+		"build_args?", &buildArgs,
+		"target?", &targetStage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(dockerRef)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parsing %q: %v", fn.Name(), dockerRef, err)
+	}
+
+	image := s.buildIndex.findBuilderByRef(ref)
+	if image == nil || image.Type() != DockerBuild {
+		return nil, fmt.Errorf("%s: no docker_build() for image %q", fn.Name(), dockerRef)
+	}
+
+	if len(buildArgs.AsMap()) > 0 {
+		merged := model.DockerBuildArgs{}
+		for k, v := range image.dbBuildArgs {
+			merged[k] = v
+		}
+		for k, v := range buildArgs.AsMap() {
+			merged[k] = v
+		}
+		image.dbBuildArgs = merged
+	}
+	if targetStage != "" {
+		image.targetStage = targetStage
+	}
+	return starlark.None, nil
+}
+
+// disable_resource(name)
+func (s *tiltfileState) disableResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := s.checkLocalTiltfile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	err = s.unpackArgs(fn.Name(), args, kwargs, "name", &name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.localOverrides.disabledResources = append(s.localOverrides.disabledResources, name)
+	return starlark.None, nil
+}
+
+// add_port_forward(resource, port_forwards)
+//
+// Adds port forwards to a k8s resource, on top of any from k8s_resource().
+func (s *tiltfileState) addPortForward(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := s.checkLocalTiltfile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	var portForwardsVal starlark.Value
+	err = s.unpackArgs(fn.Name(), args, kwargs,
+		"resource", &name,
+		"port_forwards", &portForwardsVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	portForwards, err := convertPortForwards(portForwardsVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: %v", fn.Name(), name, err)
+	}
+
+	if s.localOverrides.portForwards == nil {
+		s.localOverrides.portForwards = make(map[string][]model.PortForward)
+	}
+	s.localOverrides.portForwards[name] = append(s.localOverrides.portForwards[name], portForwards...)
+	return starlark.None, nil
+}
+
+// Applies the port forwards and disabled resources from Tiltfile.local.
+//
+// Resources that depended on a disabled resource don't wait for it anymore.
+func (s *tiltfileState) applyLocalOverrides(manifests []model.Manifest) ([]model.Manifest, error) {
+	indexes := make(map[string]int)
+	for i, m := range manifests {
+		indexes[m.Name.String()] = i
+	}
+
+	var names []string
+	for name := range s.localOverrides.portForwards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i, ok := indexes[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: no resource named %q", addPortForwardN, name)
+		}
+		m := manifests[i]
+		if !m.IsK8s() {
+			return nil, fmt.Errorf("%s: resource %q isn't a Kubernetes resource", addPortForwardN, name)
+		}
+		kTarget := m.K8sTarget()
+		kTarget.PortForwards = append(append([]model.PortForward{}, kTarget.PortForwards...), s.localOverrides.portForwards[name]...)
+		manifests[i] = m.WithDeployTarget(kTarget)
+	}
+
+	if len(s.localOverrides.disabledResources) == 0 {
+		return manifests, nil
+	}
+
+	disabled := make(map[model.ManifestName]bool)
+	for _, name := range s.localOverrides.disabledResources {
+		if _, ok := indexes[name]; !ok {
+			return nil, fmt.Errorf("%s: no resource named %q", disableResourceN, name)
+		}
+		disabled[model.ManifestName(name)] = true
+	}
+
+	result := make([]model.Manifest, 0, len(manifests))
+	for _, m := range manifests {
+		if disabled[m.Name] {
+			continue
+		}
+
+		var deps []model.ManifestName
+		for _, dep := range m.ResourceDependencies {
+			if !disabled[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		m.ResourceDependencies = deps
+		result = append(result, m)
+	}
+	return result, nil
+}
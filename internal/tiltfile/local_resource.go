@@ -20,19 +20,22 @@ type localResource struct {
 	deps          []string
 	triggerMode   triggerMode
 	autoInit      bool
+	priority      buildPriority
 	repos         []model.LocalGitRepo
 	resourceDeps  []string
 	ignores       []string
 	allowParallel bool
+	links         []model.Link
 }
 
 func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var name string
 	var updateCmdVal, updateCmdBatVal, serveCmdVal, serveCmdBatVal starlark.Value
 	var triggerMode triggerMode
+	var priority buildPriority
 	var deps starlark.Value
 	var resourceDepsVal starlark.Sequence
-	var ignoresVal starlark.Value
+	var ignoresVal, linksVal starlark.Value
 	var allowParallel bool
 	autoInit := true
 
@@ -48,6 +51,8 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		"cmd_bat?", &updateCmdBatVal,
 		"serve_cmd_bat?", &serveCmdBatVal,
 		"allow_parallel?", &allowParallel,
+		"priority?", &priority,
+		"links?", &linksVal,
 	); err != nil {
 		return nil, err
 	}
@@ -83,6 +88,11 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		return nil, err
 	}
 
+	links, err := convertLinks(linksVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: links", fn.Name(), name)
+	}
+
 	if updateCmd.Empty() && serveCmd.Empty() {
 		return nil, fmt.Errorf("local_resource must have a cmd and/or a serve_cmd, but both were empty")
 	}
@@ -95,10 +105,12 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		deps:          depsStrings,
 		triggerMode:   triggerMode,
 		autoInit:      autoInit,
+		priority:      priority,
 		repos:         repos,
 		resourceDeps:  resourceDeps,
 		ignores:       ignores,
 		allowParallel: allowParallel,
+		links:         links,
 	}
 
 	//check for duplicate resources by name and throw error if found
@@ -0,0 +1,66 @@
+package localdns
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements the local_dns() builtin, which turns on a DNS server for
+// the port-forwards of running resources.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.DefaultLocalDNSSettings()
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("local_dns", setLocalDNSSettings)
+}
+
+func setLocalDNSSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings model.LocalDNSSettings) (model.LocalDNSSettings, error) {
+		settings.Enabled = true
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"enabled?", &settings.Enabled,
+			"domain?", &settings.Domain,
+			"port?", &settings.Port)
+		if err != nil {
+			return model.LocalDNSSettings{}, err
+		}
+
+		settings.Domain = strings.Trim(strings.ToLower(settings.Domain), ".")
+		if settings.Domain == "" {
+			return model.LocalDNSSettings{}, fmt.Errorf("%s: domain cannot be empty", fn.Name())
+		}
+		if settings.Port <= 0 || settings.Port > 65535 {
+			return model.LocalDNSSettings{}, fmt.Errorf("%s: port must be between 1 and 65535 (got: %d)", fn.Name(), settings.Port)
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.LocalDNSSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.LocalDNSSettings, error) {
+	var state model.LocalDNSSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,69 @@
+package localdns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLocalDNSDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultLocalDNSSettings(), MustState(result))
+}
+
+func TestLocalDNSEnabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "local_dns()")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Enabled)
+	assert.Equal(t, "tilt.local", MustState(result).Domain)
+	assert.Equal(t, 10353, MustState(result).Port)
+}
+
+func TestLocalDNSDomainAndPort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "local_dns(domain='Dev.Example.', port=5353)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, "dev.example", MustState(result).Domain)
+	assert.Equal(t, 5353, MustState(result).Port)
+}
+
+func TestLocalDNSDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "local_dns(enabled=False)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).Enabled)
+}
+
+func TestLocalDNSBadPort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "local_dns(port=0)")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "port must be between 1 and 65535")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
@@ -0,0 +1,61 @@
+package logsettings
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements the log_settings() builtin, which controls how Tilt
+// renders logs in the terminal, the HUD, and the web UI.
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (e Extension) NewState() interface{} {
+	return model.DefaultLogSettings()
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("log_settings", setLogSettings)
+}
+
+func setLogSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.SetState(thread, func(settings model.LogSettings) (model.LogSettings, error) {
+		err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"colors?", &settings.Colors,
+			"prefix_width?", &settings.PrefixWidth,
+			"timestamps?", &settings.Timestamps,
+			"json?", &settings.JSON)
+		if err != nil {
+			return model.LogSettings{}, err
+		}
+
+		if settings.PrefixWidth < 1 {
+			return model.LogSettings{}, fmt.Errorf("%s: prefix_width must be at least 1 (got: %d)", fn.Name(), settings.PrefixWidth)
+		}
+		return settings, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) model.LogSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.LogSettings, error) {
+	var state model.LogSettings
+	err := m.Load(&state)
+	return state, err
+}
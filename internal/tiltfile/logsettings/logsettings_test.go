@@ -0,0 +1,66 @@
+package logsettings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestLogSettingsDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultLogSettings(), MustState(result))
+}
+
+func TestLogSettingsAll(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "log_settings(colors=True, prefix_width=20, timestamps=True, json=True)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.LogSettings{
+		Colors:      true,
+		PrefixWidth: 20,
+		Timestamps:  true,
+		JSON:        true,
+	}, MustState(result))
+}
+
+func TestLogSettingsKeepsEarlierCalls(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", `
+log_settings(colors=True)
+log_settings(timestamps=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Colors)
+	assert.True(t, MustState(result).Timestamps)
+	assert.Equal(t, model.DefaultLogPrefixWidth, MustState(result).PrefixWidth)
+}
+
+func TestLogSettingsPrefixWidthTooSmall(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "log_settings(prefix_width=0)")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "prefix_width must be at least 1")
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
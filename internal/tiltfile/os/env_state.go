@@ -0,0 +1,196 @@
+package os
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Records the environment variables the Tiltfile reads, and enforces
+// env_settings().
+//
+// os.environ is a Starlark value, so its methods don't get a thread to look
+// up the starkit.Model. Instead, the value and the EnvState share a recorder.
+type envRecorder struct {
+	settings model.EnvSettings
+	reads    model.TiltfileEnv
+	secrets  model.SecretSet
+
+	// Variables the Tiltfile set itself before reading them. These
+	// aren't inputs to the Tiltfile.
+	written map[string]bool
+	cleared bool
+}
+
+func newEnvRecorder() *envRecorder {
+	return &envRecorder{
+		settings: model.DefaultEnvSettings(),
+		reads:    make(model.TiltfileEnv),
+		secrets:  model.SecretSet{},
+		written:  make(map[string]bool),
+	}
+}
+
+func (r *envRecorder) checkAllowed(name string) error {
+	if !r.settings.Allows(name) {
+		return fmt.Errorf("environment variable %s is not allowed by env_settings(allow=%v)", name, r.settings.Allow)
+	}
+	return nil
+}
+
+// Looks up the variable, and records that the Tiltfile read it.
+func (r *envRecorder) lookup(name string) (string, bool, error) {
+	err := r.checkAllowed(name)
+	if err != nil {
+		return "", false, err
+	}
+	val, found := os.LookupEnv(name)
+	r.record(name, val, found)
+	return val, found, nil
+}
+
+// All the variables the Tiltfile can read, as a dictionary.
+func (r *envRecorder) environ() *starlark.Dict {
+	env := os.Environ()
+	result := starlark.NewDict(len(env))
+	for _, e := range env {
+		pair := strings.SplitN(e, "=", 2)
+		if !r.settings.Allows(pair[0]) {
+			continue
+		}
+		r.record(pair[0], pair[1], true)
+		_ = result.SetKey(starlark.String(pair[0]), starlark.String(pair[1]))
+	}
+	return result
+}
+
+func (r *envRecorder) set(name string, val string) {
+	r.written[name] = true
+	os.Setenv(name, val)
+}
+
+func (r *envRecorder) unset(name string) {
+	r.written[name] = true
+	os.Unsetenv(name)
+}
+
+func (r *envRecorder) clear() {
+	r.cleared = true
+	os.Clearenv()
+}
+
+func (r *envRecorder) record(name string, val string, found bool) {
+	// Only record the value from outside the Tiltfile.
+	if r.cleared || r.written[name] {
+		return
+	}
+	if _, ok := r.reads[name]; ok {
+		return
+	}
+	read := model.NewEnvVarRead(name, val, found, r.settings.Denies(name))
+	r.reads[name] = read
+	if read.Denied && read.Set {
+		r.secrets.AddSecret("env", name, []byte(val))
+	}
+}
+
+// Track the environment variables read while loading.
+type EnvState struct {
+	rec *envRecorder
+}
+
+func (s EnvState) Settings() model.EnvSettings {
+	if s.rec == nil {
+		return model.DefaultEnvSettings()
+	}
+	return s.rec.settings
+}
+
+func (s EnvState) Reads() model.TiltfileEnv {
+	result := make(model.TiltfileEnv)
+	if s.rec == nil {
+		return result
+	}
+	for k, v := range s.rec.reads {
+		result[k] = v
+	}
+	return result
+}
+
+// The values of denied variables that the Tiltfile read, so that
+// Tilt can scrub them from logs.
+func (s EnvState) Secrets() model.SecretSet {
+	result := model.SecretSet{}
+	if s.rec != nil {
+		result.AddAll(s.rec.secrets)
+	}
+	return result
+}
+
+func envSettings(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var allow, deny value.StringOrStringList
+	err := starkit.UnpackArgs(t, fn.Name(), args, kwargs,
+		"allow?", &allow,
+		"deny?", &deny,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range append(append([]string{}, allow.Values...), deny.Values...) {
+		err := model.ValidateEnvPattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+	}
+
+	state, err := stateFromThread(t)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := state.rec
+	if len(rec.reads) > 0 {
+		return nil, fmt.Errorf("%s: must be called before the Tiltfile reads any environment variables", fn.Name())
+	}
+	if len(allow.Values) > 0 {
+		rec.settings.Allow = allow.Values
+	}
+	rec.settings.Deny = append(rec.settings.Deny, deny.Values...)
+	return starlark.None, nil
+}
+
+func stateFromThread(t *starlark.Thread) (EnvState, error) {
+	m, err := starkit.ModelFromThread(t)
+	if err != nil {
+		return EnvState{}, err
+	}
+	state, err := GetState(m)
+	if err != nil {
+		return EnvState{}, err
+	}
+	if state.rec == nil {
+		return EnvState{}, fmt.Errorf("internal error: os.environ not initialized")
+	}
+	return state, nil
+}
+
+func MustState(model starkit.Model) EnvState {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (EnvState, error) {
+	var state EnvState
+	err := m.Load(&state)
+	return state, err
+}
@@ -2,8 +2,6 @@ package os
 
 import (
 	"fmt"
-	"os"
-	"strings"
 
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
@@ -12,56 +10,52 @@ import (
 )
 
 // Exposes os.Environ as a Starlark dictionary.
+//
+// Records the variables the Tiltfile reads.
 type Environ struct {
+	rec *envRecorder
 }
 
-func (Environ) Clear() error {
-	os.Clearenv()
+func (e Environ) Clear() error {
+	e.rec.clear()
 	return nil
 }
 
-func (Environ) Delete(k starlark.Value) (v starlark.Value, found bool, err error) {
+func (e Environ) Delete(k starlark.Value) (v starlark.Value, found bool, err error) {
 	str, ok := value.AsString(k)
 	if !ok {
 		return starlark.None, false, nil
 	}
 
-	val, found := os.LookupEnv(string(str))
-	if !found {
-		return starlark.None, false, nil
+	val, found, err := e.rec.lookup(str)
+	if err != nil || !found {
+		return starlark.None, false, err
 	}
 
-	os.Unsetenv(string(str))
+	e.rec.unset(str)
 
 	return starlark.String(val), true, nil
 }
 
-func (Environ) Get(k starlark.Value) (v starlark.Value, found bool, err error) {
+func (e Environ) Get(k starlark.Value) (v starlark.Value, found bool, err error) {
 	str, ok := value.AsString(k)
 	if !ok {
 		return starlark.None, false, nil
 	}
 
-	val, found := os.LookupEnv(string(str))
-	return starlark.String(val), found, nil
-}
-
-func environAsDict() *starlark.Dict {
-	env := os.Environ()
-	result := starlark.NewDict(len(env))
-	for _, e := range env {
-		pair := strings.SplitN(e, "=", 2)
-		_ = result.SetKey(starlark.String(pair[0]), starlark.String(pair[1]))
+	val, found, err := e.rec.lookup(str)
+	if err != nil {
+		return starlark.None, false, err
 	}
-	return result
+	return starlark.String(val), found, nil
 }
 
-func (Environ) Items() []starlark.Tuple    { return environAsDict().Items() }
-func (Environ) Keys() []starlark.Value     { return environAsDict().Keys() }
-func (Environ) Len() int                   { return len(os.Environ()) }
-func (Environ) Iterate() starlark.Iterator { return environAsDict().Iterate() }
+func (e Environ) Items() []starlark.Tuple    { return e.rec.environ().Items() }
+func (e Environ) Keys() []starlark.Value     { return e.rec.environ().Keys() }
+func (e Environ) Len() int                   { return e.rec.environ().Len() }
+func (e Environ) Iterate() starlark.Iterator { return e.rec.environ().Iterate() }
 
-func (Environ) SetKey(k, v starlark.Value) error {
+func (e Environ) SetKey(k, v starlark.Value) error {
 	kStr, ok := value.AsString(k)
 	if !ok {
 		return fmt.Errorf("putenv() key must be a string, not %s", k.Type())
@@ -71,22 +65,22 @@ func (Environ) SetKey(k, v starlark.Value) error {
 		return fmt.Errorf("putenv() value must be a string, not %s", v.Type())
 	}
 
-	os.Setenv(string(kStr), string(vStr))
+	e.rec.set(kStr, vStr)
 	return nil
 }
 
-func (Environ) String() string        { return environAsDict().String() }
-func (Environ) Type() string          { return "environ" }
-func (Environ) Freeze()               {}
-func (Environ) Truth() starlark.Bool  { return len(os.Environ()) > 0 }
-func (Environ) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: environ") }
+func (e Environ) String() string       { return e.rec.environ().String() }
+func (Environ) Type() string           { return "environ" }
+func (Environ) Freeze()                {}
+func (e Environ) Truth() starlark.Bool { return e.Len() > 0 }
+func (Environ) Hash() (uint32, error)  { return 0, fmt.Errorf("unhashable type: environ") }
 
 func (e Environ) Attr(name string) (starlark.Value, error) {
 	return builtinAttr(e, name, environMethods)
 }
 func (Environ) AttrNames() []string { return builtinAttrNames(environMethods) }
-func (Environ) CompareSameType(op syntax.Token, y_ starlark.Value, depth int) (bool, error) {
-	return environAsDict().CompareSameType(op, y_, depth)
+func (e Environ) CompareSameType(op syntax.Token, y_ starlark.Value, depth int) (bool, error) {
+	return e.rec.environ().CompareSameType(op, y_.(Environ).rec.environ(), depth)
 }
 
 var _ starlark.HasSetKey = Environ{}
@@ -19,10 +19,17 @@ import (
 // and Python's OS module
 // https://docs.python.org/3/library/os.html
 type Extension struct {
+	rec *envRecorder
 }
 
 func NewExtension() Extension {
-	return Extension{}
+	return Extension{rec: newEnvRecorder()}
+}
+
+func (e Extension) NewState() interface{} {
+	// Each execution starts with a clean record.
+	*e.rec = *newEnvRecorder()
+	return EnvState{rec: e.rec}
 }
 
 func (e Extension) OnStart(env *starkit.Environment) error {
@@ -56,7 +63,7 @@ func (e Extension) OnStart(env *starkit.Environment) error {
 		return err
 	}
 
-	err = env.AddValue("os.environ", Environ{})
+	err = env.AddValue("os.environ", Environ{rec: e.rec})
 	if err != nil {
 		return err
 	}
@@ -72,10 +79,16 @@ func (e Extension) OnStart(env *starkit.Environment) error {
 	if err != nil {
 		return err
 	}
+	err = env.AddBuiltin("env_settings", envSettings)
+	if err != nil {
+		return err
+	}
 
 	return env.AddValue("os.name", starlark.String(osName()))
 }
 
+var _ starkit.StatefulExtension = Extension{}
+
 // For consistency with
 // https://docs.python.org/3/library/os.html#os.name
 func osName() string {
@@ -96,7 +109,15 @@ func getenv(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwarg
 		return nil, err
 	}
 
-	envVal, found := os.LookupEnv(key.Value)
+	state, err := stateFromThread(t)
+	if err != nil {
+		return nil, err
+	}
+
+	envVal, found, err := state.rec.lookup(key.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
 	if !found {
 		return defaultVal, nil
 	}
@@ -114,7 +135,12 @@ func putenv(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwarg
 		return nil, err
 	}
 
-	os.Setenv(key.Value, v.Value)
+	state, err := stateFromThread(t)
+	if err != nil {
+		return nil, err
+	}
+
+	state.rec.set(key.Value, v.Value)
 	return starlark.None, nil
 }
 
@@ -127,7 +153,12 @@ func unsetenv(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwa
 		return nil, err
 	}
 
-	os.Unsetenv(key.Value)
+	state, err := stateFromThread(t)
+	if err != nil {
+		return nil, err
+	}
+
+	state.rec.unset(key.Value)
 	return starlark.None, nil
 }
 
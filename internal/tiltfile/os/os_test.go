@@ -12,6 +12,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func TestEnviron(t *testing.T) {
@@ -361,6 +362,111 @@ print(os.path.join("foo", "bar", "baz"))
 	assert.Equal(t, fmt.Sprintf("%s\n", filepath.Join("foo", "bar", "baz")), f.PrintOutput())
 }
 
+func TestEnvReadsRecorded(t *testing.T) {
+	f := NewFixture(t)
+	os.Setenv("FAKE_ENV_VARIABLE", "fakeValue")
+	defer os.Unsetenv("FAKE_ENV_VARIABLE")
+
+	f.File("Tiltfile", `
+os.getenv('FAKE_ENV_VARIABLE')
+os.environ.get('FAKE_ENV_VARIABLE_UNSET')
+os.putenv('FAKE_ENV_VARIABLE_WRITTEN', 'x')
+os.getenv('FAKE_ENV_VARIABLE_WRITTEN')
+`)
+	defer os.Unsetenv("FAKE_ENV_VARIABLE_WRITTEN")
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	reads := MustState(result).Reads()
+	assert.Equal(t, model.NewEnvVarRead("FAKE_ENV_VARIABLE", "fakeValue", true, false), reads["FAKE_ENV_VARIABLE"])
+	assert.Equal(t, model.EnvVarRead{Name: "FAKE_ENV_VARIABLE_UNSET"}, reads["FAKE_ENV_VARIABLE_UNSET"])
+
+	// The Tiltfile set this variable itself, so it's not an input.
+	_, ok := reads["FAKE_ENV_VARIABLE_WRITTEN"]
+	assert.False(t, ok)
+}
+
+func TestEnvDeniedNotRecorded(t *testing.T) {
+	f := NewFixture(t)
+	os.Setenv("FAKE_API_TOKEN", "supersecret")
+	os.Setenv("FAKE_CUSTOM", "alsosecret")
+	defer os.Unsetenv("FAKE_API_TOKEN")
+	defer os.Unsetenv("FAKE_CUSTOM")
+
+	f.File("Tiltfile", `
+env_settings(deny='fake_custom')
+print(os.environ['FAKE_API_TOKEN'])
+print(os.getenv('FAKE_CUSTOM'))
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	// The Tiltfile can still read them.
+	assert.Equal(t, "supersecret\nalsosecret\n", f.PrintOutput())
+
+	state := MustState(result)
+	for _, name := range []string{"FAKE_API_TOKEN", "FAKE_CUSTOM"} {
+		read := state.Reads()[name]
+		assert.True(t, read.Denied)
+		assert.True(t, read.Set)
+		assert.Equal(t, "", read.Value)
+		assert.NotEqual(t, "", read.Digest)
+	}
+
+	secrets := state.Secrets()
+	assert.Equal(t, "[redacted secret env:FAKE_API_TOKEN]", string(secrets.Scrub([]byte("supersecret"))))
+}
+
+func TestEnvAllow(t *testing.T) {
+	f := NewFixture(t)
+	os.Setenv("FAKE_ALLOWED", "a")
+	os.Setenv("FAKE_FORBIDDEN", "b")
+	defer os.Unsetenv("FAKE_ALLOWED")
+	defer os.Unsetenv("FAKE_FORBIDDEN")
+
+	f.File("Tiltfile", `
+env_settings(allow=['FAKE_ALLOW*'])
+print(os.getenv('FAKE_ALLOWED'))
+print('FAKE_FORBIDDEN' in os.environ.keys())
+print(os.getenv('FAKE_FORBIDDEN'))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "environment variable FAKE_FORBIDDEN is not allowed by env_settings(allow=[FAKE_ALLOW*])")
+	}
+	assert.Equal(t, "a\nFalse\n", f.PrintOutput())
+}
+
+func TestEnvSettingsAfterRead(t *testing.T) {
+	f := NewFixture(t)
+
+	f.File("Tiltfile", `
+os.getenv('FAKE_ENV_VARIABLE')
+env_settings(allow=['FAKE_*'])
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must be called before the Tiltfile reads any environment variables")
+	}
+}
+
+func TestEnvSettingsInvalidPattern(t *testing.T) {
+	f := NewFixture(t)
+
+	f.File("Tiltfile", `
+env_settings(deny=['[FAKE'])
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid pattern "[FAKE"`)
+	}
+}
+
 func NewFixture(tb testing.TB) *starkit.Fixture {
 	return starkit.NewFixture(tb, NewExtension(), io.NewExtension())
 }
@@ -0,0 +1,62 @@
+package tiltfile
+
+import (
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/overlay"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Adds the resources from the session overlay (see `tilt alpha create`)
+// to the local resources declared in the Tiltfile.
+//
+// The Tiltfile wins on conflicts: if it declares a resource with the same
+// name, we skip the overlay resource and warn.
+func (s *tiltfileState) addOverlayResources(absFilename string, manifests []model.Manifest) error {
+	o, err := overlay.Read(overlay.Path(absFilename))
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, m := range manifests {
+		names[m.Name.String()] = true
+	}
+	for _, r := range s.localResources {
+		names[r.name] = true
+	}
+
+	workdir := filepath.Dir(absFilename)
+	for _, r := range o.LocalResources {
+		err := r.Validate()
+		if err != nil {
+			s.logger.Warnf("Skipping resource from %s: %v", overlay.FileName, err)
+			continue
+		}
+		if names[r.Name] {
+			s.logger.Warnf("Skipping resource %s from %s: the Tiltfile already defines a resource with that name", r.Name, overlay.FileName)
+			continue
+		}
+		names[r.Name] = true
+
+		var deps []string
+		for _, dep := range r.Deps {
+			if !filepath.IsAbs(dep) {
+				dep = filepath.Join(workdir, dep)
+			}
+			deps = append(deps, dep)
+		}
+
+		s.localResources = append(s.localResources, localResource{
+			name:         r.Name,
+			updateCmd:    model.ToHostCmd(r.Cmd),
+			serveCmd:     model.ToHostCmd(r.ServeCmd),
+			workdir:      workdir,
+			deps:         deps,
+			autoInit:     true,
+			repos:        reposForPaths(deps),
+			resourceDeps: r.ResourceDeps,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package overlay reads and writes the session overlay: resources added to a
+// running session with `tilt alpha create`, without editing the Tiltfile.
+//
+// The overlay lives next to the Tiltfile. The Tiltfile loader merges it in
+// on every load, and watches it, so writing it triggers a reload.
+package overlay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const FileName = ".tilt-overlay.json"
+
+type Overlay struct {
+	LocalResources []LocalResource `json:"local_resources,omitempty"`
+}
+
+type LocalResource struct {
+	Name     string `json:"name"`
+	Cmd      string `json:"cmd,omitempty"`
+	ServeCmd string `json:"serve_cmd,omitempty"`
+
+	// Relative to the Tiltfile's directory, unless absolute.
+	Deps []string `json:"deps,omitempty"`
+
+	ResourceDeps []string `json:"resource_deps,omitempty"`
+}
+
+func (r LocalResource) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("local resource must have a name")
+	}
+	if r.Cmd == "" && r.ServeCmd == "" {
+		return fmt.Errorf("local resource %s must have a cmd and/or a serve_cmd, but both were empty", r.Name)
+	}
+	return nil
+}
+
+func Path(tiltfilePath string) string {
+	return filepath.Join(filepath.Dir(tiltfilePath), FileName)
+}
+
+// Reads the overlay at path. A missing overlay is empty.
+func Read(path string) (Overlay, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Overlay{}, nil
+		}
+		return Overlay{}, err
+	}
+
+	var o Overlay
+	err = json.Unmarshal(contents, &o)
+	if err != nil {
+		return Overlay{}, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return o, nil
+}
+
+// Writes the overlay to path. We write to a temp file and rename it, so
+// that a Tiltfile reload never sees a half-written overlay.
+func Write(path string, o Overlay) error {
+	contents, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), FileName+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(append(contents, '\n'))
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Returns a copy of the overlay with the given local resource added.
+func (o Overlay) WithLocalResource(r LocalResource) (Overlay, error) {
+	err := r.Validate()
+	if err != nil {
+		return Overlay{}, err
+	}
+	for _, existing := range o.LocalResources {
+		if existing.Name == r.Name {
+			return Overlay{}, fmt.Errorf("resource %s already exists in %s", r.Name, FileName)
+		}
+	}
+
+	result := Overlay{LocalResources: append([]LocalResource{}, o.LocalResources...)}
+	result.LocalResources = append(result.LocalResources, r)
+	return result, nil
+}
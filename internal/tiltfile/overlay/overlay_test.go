@@ -0,0 +1,64 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestReadMissing(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	o, err := Read(Path(f.JoinPath("Tiltfile")))
+	require.NoError(t, err)
+	assert.Equal(t, Overlay{}, o)
+}
+
+func TestWriteAndRead(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	path := Path(f.JoinPath("Tiltfile"))
+	assert.Equal(t, f.JoinPath(".tilt-overlay.json"), path)
+
+	o, err := Overlay{}.WithLocalResource(LocalResource{Name: "gen", Cmd: "make gen", Deps: []string{"./proto"}})
+	require.NoError(t, err)
+	require.NoError(t, Write(path, o))
+
+	actual, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, o, actual)
+}
+
+func TestReadInvalid(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile(".tilt-overlay.json", "{")
+	_, err := Read(f.JoinPath(".tilt-overlay.json"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "parsing")
+	}
+}
+
+func TestWithLocalResourceDuplicate(t *testing.T) {
+	o, err := Overlay{}.WithLocalResource(LocalResource{Name: "gen", Cmd: "make gen"})
+	require.NoError(t, err)
+
+	_, err = o.WithLocalResource(LocalResource{Name: "gen", Cmd: "make other"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "resource gen already exists")
+	}
+	assert.Equal(t, 1, len(o.LocalResources))
+}
+
+func TestWithLocalResourceNoCmd(t *testing.T) {
+	_, err := Overlay{}.WithLocalResource(LocalResource{Name: "gen"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must have a cmd and/or a serve_cmd")
+	}
+}
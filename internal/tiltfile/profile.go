@@ -0,0 +1,45 @@
+package tiltfile
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// If the Tiltfile takes longer than this to load, print a breakdown of where
+// the time went.
+const slowTiltfileLoadThreshold = 3 * time.Second
+
+func newTiltfileProfile(result starkit.Model, total time.Duration) model.TiltfileProfile {
+	builtins := []model.BuiltinTiming{}
+	builtinIndex := make(map[string]int)
+	for _, call := range result.BuiltinCalls {
+		i, ok := builtinIndex[call.Name]
+		if !ok {
+			i = len(builtins)
+			builtinIndex[call.Name] = i
+			builtins = append(builtins, model.BuiltinTiming{Name: call.Name})
+		}
+		builtins[i].Count++
+		builtins[i].Dur += call.Dur
+	}
+	sort.SliceStable(builtins, func(i, j int) bool {
+		return builtins[i].Dur > builtins[j].Dur
+	})
+
+	loads := make([]model.LoadTiming, 0, len(result.LoadCalls))
+	for _, call := range result.LoadCalls {
+		loads = append(loads, model.LoadTiming{Path: call.Path, Dur: call.Dur})
+	}
+	sort.SliceStable(loads, func(i, j int) bool {
+		return loads[i].Dur > loads[j].Dur
+	})
+
+	return model.TiltfileProfile{
+		Total:    total,
+		Builtins: builtins,
+		Loads:    loads,
+	}
+}
@@ -0,0 +1,47 @@
+package tiltfile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestTiltfileProfile(t *testing.T) {
+	result := starkit.Model{
+		BuiltinCalls: []starkit.BuiltinCall{
+			{Name: "local", Dur: 2 * time.Second},
+			{Name: "k8s_yaml", Dur: 10 * time.Millisecond},
+			{Name: "local", Dur: 3 * time.Second},
+		},
+		LoadCalls: []starkit.LoadCall{
+			{Path: "/src/lib.star", Dur: 3 * time.Second},
+			{Path: "/src/Tiltfile", Dur: 5 * time.Second},
+		},
+	}
+
+	profile := newTiltfileProfile(result, 6*time.Second)
+	assert.Equal(t, model.TiltfileProfile{
+		Total: 6 * time.Second,
+		Builtins: []model.BuiltinTiming{
+			{Name: "local", Count: 2, Dur: 5 * time.Second},
+			{Name: "k8s_yaml", Count: 1, Dur: 10 * time.Millisecond},
+		},
+		Loads: []model.LoadTiming{
+			{Path: "/src/Tiltfile", Dur: 5 * time.Second},
+			{Path: "/src/lib.star", Dur: 3 * time.Second},
+		},
+	}, profile)
+
+	assert.Equal(t, `Tiltfile load took 6s
+Files (including the files they load):
+          5s  /src/Tiltfile
+          3s  /src/lib.star
+Builtins:
+          5s  local (2 calls)
+        10ms  k8s_yaml (1 calls)
+`, profile.String())
+}
@@ -0,0 +1,383 @@
+// Package secrets implements Tiltfile builtins that decrypt dev secrets
+// at load time, so that they don't need to be checked in as plaintext.
+//
+// A secret provider is any command that speaks this protocol: Tilt runs
+// it with a JSON request on stdin,
+//
+//	{"apiVersion": "tilt.dev/v1alpha1", "kind": "SecretRequest", "key": "db/password"}
+//
+// and the command prints a JSON response on stdout with either one value,
+//
+//	{"apiVersion": "tilt.dev/v1alpha1", "kind": "SecretResponse", "value": "hunter2"}
+//
+// or a group of values:
+//
+//	{"apiVersion": "tilt.dev/v1alpha1", "kind": "SecretResponse", "data": {"username": "admin", "password": "hunter2"}}
+//
+// A non-zero exit code means the secret couldn't be read.
+//
+// Every value a Tiltfile reads is scrubbed from logs (unless scrubbing is
+// turned off with secret_settings()).
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	protocolAPIVersion = "tilt.dev/v1alpha1"
+
+	// How long to wait for a provider (or sops) to print a secret.
+	providerTimeout = 30 * time.Second
+
+	sopsInstallURL = "https://github.com/getsops/sops#download"
+)
+
+type request struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Key        string `json:"key"`
+}
+
+type response struct {
+	Value *string           `json:"value,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// The providers the Tiltfile registered, and every secret it read.
+type State struct {
+	Providers map[string]model.Cmd
+	Secrets   model.SecretSet
+}
+
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (Extension) NewState() interface{} {
+	return State{
+		Providers: make(map[string]model.Cmd),
+		Secrets:   model.SecretSet{},
+	}
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	for _, b := range []struct {
+		name string
+		f    starkit.Function
+	}{
+		{"secret_provider", secretProvider},
+		{"read_secret", readSecret},
+		{"read_sops", readSOPS},
+		{"secret_yaml", secretYAML},
+	} {
+		err := env.AddBuiltin(b.name, b.f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func secretProvider(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var cmdVal starlark.Value
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &name,
+		"cmd", &cmdVal)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%s: name must not be empty", fn.Name())
+	}
+
+	cmd, err := value.ValueToHostCmd(cmdVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	if cmd.Empty() {
+		return nil, fmt.Errorf("%s: cmd must not be empty", fn.Name())
+	}
+
+	err = starkit.SetState(thread, func(state State) (State, error) {
+		if _, ok := state.Providers[name]; ok {
+			return state, fmt.Errorf("%s: provider %q is already registered", fn.Name(), name)
+		}
+		providers := make(map[string]model.Cmd, len(state.Providers)+1)
+		for k, v := range state.Providers {
+			providers[k] = v
+		}
+		providers[name] = cmd
+		state.Providers = providers
+		return state, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
+
+func readSecret(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var provider, key string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"provider", &provider,
+		"key", &key)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := starkit.ModelFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+	state, err := GetState(m)
+	if err != nil {
+		return nil, err
+	}
+	cmd, ok := state.Providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%s: no secret provider named %q. Register it with secret_provider() first", fn.Name(), provider)
+	}
+
+	req, err := json.Marshal(request{APIVersion: protocolAPIVersion, Kind: "SecretRequest", Key: key})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := run(thread, cmd.Argv, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: provider %q couldn't read %q: %v", fn.Name(), provider, key, err)
+	}
+
+	var resp response
+	err = json.Unmarshal(out, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: provider %q printed an invalid response: %v", fn.Name(), provider, err)
+	}
+
+	switch {
+	case resp.Value != nil:
+		err = recordSecrets(thread, provider, map[string]string{key: *resp.Value})
+		if err != nil {
+			return nil, err
+		}
+		return starlark.String(*resp.Value), nil
+
+	case resp.Data != nil:
+		err = recordSecrets(thread, provider, prefixKeys(key, resp.Data))
+		if err != nil {
+			return nil, err
+		}
+		data := make(map[string]interface{}, len(resp.Data))
+		for k, v := range resp.Data {
+			data[k] = v
+		}
+		return encoding.ConvertStructuredDataToStarlark(data)
+	}
+	return nil, fmt.Errorf("%s: provider %q printed a response with neither a value nor data", fn.Name(), provider)
+}
+
+func readSOPS(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pathVal starlark.Value
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs, "path", &pathVal)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := value.ValueToAbsPath(thread, pathVal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	// Reload the Tiltfile when the encrypted file changes.
+	err = tiltfile_io.RecordReadPath(thread, tiltfile_io.WatchFileOnly, path)
+	if err != nil {
+		return nil, err
+	}
+
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("%s: sops not found on PATH. Install it from %s", fn.Name(), sopsInstallURL)
+	}
+
+	// sops decrypts YAML, JSON, dotenv and INI files, and can print all of them as JSON.
+	out, err := run(thread, []string{sops, "--decrypt", "--output-type", "json", path}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: couldn't decrypt %s: %v", fn.Name(), path, err)
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(out, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: couldn't parse the decrypted %s: %v", fn.Name(), path, err)
+	}
+
+	values := make(map[string]string)
+	collectStrings("", decoded, values)
+	err = recordSecrets(thread, filepath.Base(path), values)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoding.ConvertStructuredDataToStarlark(decoded)
+}
+
+// Creates a Kubernetes Secret, to pass to k8s_yaml(), so that
+// decrypted values never have to be written to a file.
+func secretYAML(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, namespace, secretType string
+	var data value.StringStringMap
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &name,
+		"data", &data,
+		"namespace?", &namespace,
+		"type?", &secretType)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%s: name must not be empty", fn.Name())
+	}
+	if secretType == "" {
+		secretType = string(v1.SecretTypeOpaque)
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: v1.SecretType(secretType),
+		Data: make(map[string][]byte, len(data)),
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+
+	yaml, err := k8s.SerializeSpecYAML([]k8s.K8sEntity{k8s.NewK8sEntity(secret)})
+	if err != nil {
+		return nil, err
+	}
+	return tiltfile_io.NewBlob(yaml, fmt.Sprintf("%s(%q)", fn.Name(), name)), nil
+}
+
+func run(thread *starlark.Thread, argv []string, stdin []byte) ([]byte, error) {
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = starkit.AbsWorkingDir(thread)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timed out after %s", providerTimeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func prefixKeys(prefix string, data map[string]string) map[string]string {
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		result[prefix+"."+k] = v
+	}
+	return result
+}
+
+// Flattens every string in the decoded document into dotted keys,
+// e.g., {"db": {"password": "x"}} becomes "db.password".
+func collectStrings(key string, v interface{}, result map[string]string) {
+	switch v := v.(type) {
+	case string:
+		result[key] = v
+	case map[string]interface{}:
+		for k, child := range v {
+			if key != "" {
+				k = key + "." + k
+			}
+			collectStrings(k, child, result)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectStrings(fmt.Sprintf("%s[%d]", key, i), child, result)
+		}
+	}
+}
+
+func recordSecrets(thread *starlark.Thread, name string, values map[string]string) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return starkit.SetState(thread, func(state State) State {
+		secrets := model.SecretSet{}
+		secrets.AddAll(state.Secrets)
+		for _, k := range keys {
+			if values[k] == "" {
+				continue
+			}
+			secrets.AddSecret(name, k, []byte(values[k]))
+		}
+		state.Secrets = secrets
+		return state
+	})
+}
+
+var _ starkit.StatefulExtension = Extension{}
+
+func MustState(model starkit.Model) State {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (State, error) {
+	var state State
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,194 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func TestReadSecretValue(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.script("provider.sh", `cat > request.json
+echo '{"apiVersion": "tilt.dev/v1alpha1", "kind": "SecretResponse", "value": "hunter2-hunter2"}'`)
+	f.File("Tiltfile", `
+secret_provider('vault', ['./provider.sh'])
+print(read_secret('vault', 'db/password'))
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Contains(t, f.PrintOutput(), "hunter2-hunter2")
+
+	req, err := ioutil.ReadFile(f.JoinPath("request.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion": "tilt.dev/v1alpha1", "kind": "SecretRequest", "key": "db/password"}`, string(req))
+
+	secret := MustState(result).Secrets["hunter2-hunter2"]
+	assert.Equal(t, "vault", secret.Name)
+	assert.Equal(t, "db/password", secret.Key)
+}
+
+func TestReadSecretData(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.script("provider.sh", `echo '{"data": {"username": "admin", "password": "hunter2-hunter2"}}'`)
+	f.File("Tiltfile", `
+secret_provider('vault', './provider.sh')
+db = read_secret('vault', 'db')
+print(db['username'] + ':' + db['password'])
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Contains(t, f.PrintOutput(), "admin:hunter2-hunter2")
+	assert.Equal(t, "db.password", MustState(result).Secrets["hunter2-hunter2"].Key)
+}
+
+func TestReadSecretUnknownProvider(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.File("Tiltfile", `
+read_secret('vault', 'db/password')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no secret provider named "vault"`)
+}
+
+func TestReadSecretProviderFails(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.script("provider.sh", `echo 'Vault is sealed' >&2
+exit 2`)
+	f.File("Tiltfile", `
+secret_provider('vault', ['./provider.sh'])
+read_secret('vault', 'db/password')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `provider "vault" couldn't read "db/password"`)
+	assert.Contains(t, err.Error(), "Vault is sealed")
+}
+
+func TestSecretProviderRegisteredTwice(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.File("Tiltfile", `
+secret_provider('vault', 'vault-plugin')
+secret_provider('vault', 'other-plugin')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `provider "vault" is already registered`)
+}
+
+func TestReadSOPS(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.tool("sops", `[ "$1 $2 $3" = "--decrypt --output-type json" ] || exit 1
+echo '{"db": {"password": "hunter2-hunter2", "port": 5432}}'`)
+	f.File("secrets.enc.yaml", "db: ENC[AES256_GCM,data:...]")
+	f.File("Tiltfile", `
+s = read_sops('secrets.enc.yaml')
+print(s['db']['password'])
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Contains(t, f.PrintOutput(), "hunter2-hunter2")
+
+	secret := MustState(result).Secrets["hunter2-hunter2"]
+	assert.Equal(t, "secrets.enc.yaml", secret.Name)
+	assert.Equal(t, "db.password", secret.Key)
+
+	readState, err := io.GetState(result)
+	require.NoError(t, err)
+	assert.Contains(t, readState.Paths, f.JoinPath("secrets.enc.yaml"))
+}
+
+func TestReadSOPSNotInstalled(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+	require.NoError(t, os.Setenv("PATH", f.binDir))
+
+	f.File("Tiltfile", `
+read_sops('secrets.enc.yaml')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sops not found on PATH")
+}
+
+func TestSecretYAML(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.File("Tiltfile", `
+print(secret_yaml('db', {'password': 'hunter2'}, namespace='dev'))
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	out := f.PrintOutput()
+	assert.Contains(t, out, "kind: Secret")
+	assert.Contains(t, out, "name: db")
+	assert.Contains(t, out, "namespace: dev")
+	assert.Contains(t, out, "password: aHVudGVyMg==")
+	assert.Contains(t, out, "type: Opaque")
+}
+
+type fixture struct {
+	*starkit.Fixture
+	binDir  string
+	oldPath string
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := starkit.NewFixture(t, NewExtension(), io.NewExtension())
+	f.UseRealFS()
+	binDir := f.JoinPath("bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+
+	oldPath := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", binDir+string(os.PathListSeparator)+oldPath))
+	return &fixture{Fixture: f, binDir: binDir, oldPath: oldPath}
+}
+
+// Writes an executable shell script next to the Tiltfile.
+func (f *fixture) script(name string, body string) {
+	err := ioutil.WriteFile(f.JoinPath(name), []byte("#!/bin/sh\n"+body+"\n"), 0755)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Puts a fake tool on the PATH.
+func (f *fixture) tool(name string, body string) {
+	err := ioutil.WriteFile(filepath.Join(f.binDir, name), []byte("#!/bin/sh\n"+body+"\n"), 0755)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (f *fixture) tearDown() {
+	_ = os.Setenv("PATH", f.oldPath)
+	f.TearDown()
+}
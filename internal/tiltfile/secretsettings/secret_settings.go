@@ -1,6 +1,8 @@
 package secretsettings
 
 import (
+	"fmt"
+
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -25,15 +27,26 @@ func (e Extension) OnStart(env *starkit.Environment) error {
 }
 
 func (e Extension) secretSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var disable bool
-	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
-		"disable_scrub", &disable); err != nil {
-		return nil, err
-	}
+	err := starkit.SetState(thread, func(settings model.SecretSettings) (model.SecretSettings, error) {
+		disable := !settings.ScrubSecrets
+		scanPolicy := string(settings.ScanPolicy)
+		if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+			"disable_scrub?", &disable,
+			"scan_builds?", &settings.ScanBuilds,
+			"scan_policy?", &scanPolicy); err != nil {
+			return model.SecretSettings{}, err
+		}
+
+		switch model.SecretScanPolicy(scanPolicy) {
+		case model.SecretScanPolicyFail, model.SecretScanPolicyWarn:
+		default:
+			return model.SecretSettings{}, fmt.Errorf("%s: scan_policy must be one of %q or %q (got: %q)",
+				fn.Name(), model.SecretScanPolicyFail, model.SecretScanPolicyWarn, scanPolicy)
+		}
 
-	err := starkit.SetState(thread, func(settings model.SecretSettings) model.SecretSettings {
 		settings.ScrubSecrets = !disable
-		return settings
+		settings.ScanPolicy = model.SecretScanPolicy(scanPolicy)
+		return settings, nil
 	})
 
 	return starlark.None, err
@@ -0,0 +1,73 @@
+package secretsettings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSecretSettingsDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultSecretSettings(), MustState(result))
+}
+
+func TestSecretSettingsDisableScrub(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "secret_settings(disable_scrub=True)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).ScrubSecrets)
+	assert.False(t, MustState(result).ScanBuilds)
+}
+
+func TestSecretSettingsScanBuilds(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "secret_settings(scan_builds=True)")
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).ScrubSecrets)
+	assert.True(t, MustState(result).ScanBuilds)
+	assert.Equal(t, model.SecretScanPolicyFail, MustState(result).ScanPolicy)
+}
+
+func TestSecretSettingsScanPolicyWarn(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", `
+secret_settings(scan_builds=True, scan_policy='warn')
+secret_settings(disable_scrub=False)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).ScanBuilds)
+	assert.Equal(t, model.SecretScanPolicyWarn, MustState(result).ScanPolicy)
+}
+
+func TestSecretSettingsBadScanPolicy(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.File("Tiltfile", "secret_settings(scan_builds=True, scan_policy='ignore')")
+	_, err := f.ExecFile("Tiltfile")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `scan_policy must be one of "fail" or "warn"`)
+	}
+}
+
+func newFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewExtension())
+}
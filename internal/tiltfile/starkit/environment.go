@@ -37,6 +37,14 @@ type BuiltinCall struct {
 	Dur  time.Duration
 }
 
+// A file that we executed, either as the entrypoint or with load().
+//
+// Dur includes the time spent executing any files that it loads.
+type LoadCall struct {
+	Path string
+	Dur  time.Duration
+}
+
 // A starlark execution environment.
 type Environment struct {
 	ctx              context.Context
@@ -50,6 +58,7 @@ type Environment struct {
 	startPath        string
 
 	builtinCalls []BuiltinCall
+	loadCalls    []LoadCall
 }
 
 func newEnvironment(extensions ...Extension) *Environment {
@@ -186,8 +195,23 @@ func (e *Environment) start(path string) (Model, error) {
 	t.SetLocal(modelKey, model)
 	t.SetLocal(ctxKey, e.ctx)
 
-	_, err = e.exec(t, path)
+	globals, err := e.exec(t, path)
+	if err == nil {
+		// Functions called from OnFinish resolve paths relative to the entrypoint.
+		t.SetLocal(execingTiltfileKey, path)
+		for _, ext := range e.extensions {
+			onFinishExt, ok := ext.(OnFinishExtension)
+			if !ok {
+				continue
+			}
+			err = onFinishExt.OnFinish(t, globals)
+			if err != nil {
+				break
+			}
+		}
+	}
 	model.BuiltinCalls = e.builtinCalls
+	model.LoadCalls = e.loadCalls
 	return model, err
 }
 
@@ -221,7 +245,12 @@ func (e *Environment) exec(t *starlark.Thread, path string) (starlark.StringDict
 	oldPath := t.Local(execingTiltfileKey)
 	t.SetLocal(execingTiltfileKey, localPath)
 
+	start := time.Now()
 	exports, err := e.doLoad(t, localPath)
+	e.loadCalls = append(e.loadCalls, LoadCall{
+		Path: localPath,
+		Dur:  time.Since(start),
+	})
 
 	t.SetLocal(execingTiltfileKey, oldPath)
 
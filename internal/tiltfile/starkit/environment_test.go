@@ -220,3 +220,38 @@ print_mypath()
 	require.Equal(t, "Tiltfile", filepath.Base(paths[0]))
 	require.Equal(t, "Tiltfile2", filepath.Base(paths[1]))
 }
+
+type finishExtension struct {
+	names []string
+}
+
+func (e *finishExtension) OnStart(env *Environment) error { return nil }
+
+func (e *finishExtension) OnFinish(t *starlark.Thread, globals starlark.StringDict) error {
+	e.names = globals.Keys()
+	return nil
+}
+
+func TestOnFinishReceivesGlobals(t *testing.T) {
+	e := &finishExtension{}
+	f := NewFixture(t, e)
+	f.File("Tiltfile", `
+x = 1
+def test_foo():
+  pass
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test_foo", "x"}, e.names)
+}
+
+func TestOnFinishSkippedOnError(t *testing.T) {
+	e := &finishExtension{}
+	f := NewFixture(t, e)
+	f.File("Tiltfile", `fail("oh no")`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Nil(t, e.names)
+}
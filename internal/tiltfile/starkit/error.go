@@ -1,8 +1,12 @@
 package starkit
 
 import (
+	goerrors "errors"
+
 	"github.com/pkg/errors"
+	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
 )
 
 // Keep unwrapping errors until we find an error with a backtrace.
@@ -29,6 +33,14 @@ func UnpackBacktrace(err error) error {
 	return err
 }
 
+// Whether the error (or one that it wraps, e.g., from a load())
+// came from parsing or resolving Starlark, rather than from running it.
+func IsSyntaxError(err error) bool {
+	var syntaxErr syntax.Error
+	var resolveErrs resolve.ErrorList
+	return goerrors.As(err, &syntaxErr) || goerrors.As(err, &resolveErrs)
+}
+
 // go 1.13 error wrapper
 type wrapper interface {
 	Unwrap() error
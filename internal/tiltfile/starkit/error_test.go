@@ -0,0 +1,44 @@
+package starkit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSyntaxError(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+x = [1, 2
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.True(t, IsSyntaxError(err))
+}
+
+func TestIsSyntaxErrorInLoadedFile(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+load('./foo/Tiltfile', "x")
+`)
+	f.File("foo/Tiltfile", `
+x = undefined_name
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.True(t, IsSyntaxError(err))
+}
+
+func TestRuntimeErrorIsNotSyntaxError(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+x = 1 // 0
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.False(t, IsSyntaxError(err))
+}
@@ -15,6 +15,14 @@ type OnExecExtension interface {
 	OnExec(t *starlark.Thread, path string) error
 }
 
+type OnFinishExtension interface {
+	Extension
+
+	// Called after the entrypoint finishes executing without errors,
+	// with the entrypoint's globals.
+	OnFinish(t *starlark.Thread, globals starlark.StringDict) error
+}
+
 type OnBuiltinCallExtension interface {
 	Extension
 
@@ -12,6 +12,7 @@ type Model struct {
 	state map[reflect.Type]interface{}
 
 	BuiltinCalls []BuiltinCall
+	LoadCalls    []LoadCall
 }
 
 func NewModel() Model {
@@ -27,7 +27,8 @@ func NewExtension(fetcher Fetcher, store Store) *Extension {
 }
 
 type Fetcher interface {
-	Fetch(ctx context.Context, moduleName string) (ModuleContents, error)
+	// Fetch the extension at the given version, or the latest version if empty.
+	Fetch(ctx context.Context, moduleName string, version string) (ModuleContents, error)
 	CleanUp() error
 }
 
@@ -57,7 +58,18 @@ func (e *Extension) LocalPath(t *starlark.Thread, arg string) (string, error) {
 		return localPath, nil
 	}
 
-	contents, err := e.fetcher.Fetch(ctx, moduleName)
+	// If the extension was pinned (e.g., the tilt_modules metadata was checked in,
+	// but the extension itself wasn't), fetch the pinned version.
+	version := ""
+	metadata, err := e.store.ModuleMetadata(ctx, moduleName)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if metadata.Pinned {
+		version = metadata.Version
+	}
+
+	contents, err := e.fetcher.Fetch(ctx, moduleName, version)
 	if err != nil {
 		return "", err
 	}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/tiltfile/include"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
@@ -50,6 +52,38 @@ printFoo()
 	f.assertExecOutput("foo")
 }
 
+func TestFetchPinnedVersion(t *testing.T) {
+	f := newExtensionFixture(t)
+	defer f.tearDown()
+
+	f.tiltfile(`
+load("ext://fetchable", "printFoo")
+printFoo()
+`)
+	f.tmp.WriteFile(filepath.Join("project", "tilt_modules", "extensions.json"), `{
+  "Extensions": [{"Name": "fetchable", "Version": "abc123", "Pinned": true}]
+}`)
+
+	f.assertExecOutput("foo")
+	assert.Equal(t, "abc123", f.fetcher.lastVersion)
+}
+
+func TestFetchUnpinnedFetchesLatest(t *testing.T) {
+	f := newExtensionFixture(t)
+	defer f.tearDown()
+
+	f.tiltfile(`
+load("ext://fetchable", "printFoo")
+printFoo()
+`)
+	f.tmp.WriteFile(filepath.Join("project", "tilt_modules", "extensions.json"), `{
+  "Extensions": [{"Name": "fetchable", "Version": "abc123"}]
+}`)
+
+	f.assertExecOutput("foo")
+	assert.Equal(t, "", f.fetcher.lastVersion)
+}
+
 func TestFetchUnfetchableFails(t *testing.T) {
 	f := newExtensionFixture(t)
 	defer f.tearDown()
@@ -114,24 +148,27 @@ printFoo()
 }
 
 type extensionFixture struct {
-	t   *testing.T
-	skf *starkit.Fixture
-	tmp *tempdir.TempDirFixture
+	t       *testing.T
+	skf     *starkit.Fixture
+	tmp     *tempdir.TempDirFixture
+	fetcher *fakeFetcher
 }
 
 func newExtensionFixture(t *testing.T) *extensionFixture {
 	tmp := tempdir.NewTempDirFixture(t)
+	fetcher := &fakeFetcher{t: t}
 	ext := NewExtension(
-		&fakeFetcher{t: t},
+		fetcher,
 		NewLocalStore(tmp.JoinPath("project")),
 	)
 	skf := starkit.NewFixture(t, ext, include.IncludeFn{})
 	skf.UseRealFS()
 
 	return &extensionFixture{
-		t:   t,
-		skf: skf,
-		tmp: tmp,
+		t:       t,
+		skf:     skf,
+		tmp:     tmp,
+		fetcher: fetcher,
 	}
 }
 
@@ -187,10 +224,12 @@ def printFoo():
 `
 
 type fakeFetcher struct {
-	t *testing.T
+	t           *testing.T
+	lastVersion string
 }
 
-func (f *fakeFetcher) Fetch(ctx context.Context, moduleName string) (ModuleContents, error) {
+func (f *fakeFetcher) Fetch(ctx context.Context, moduleName string, version string) (ModuleContents, error) {
+	f.lastVersion = version
 	if moduleName != "fetchable" {
 		return ModuleContents{}, fmt.Errorf("module %s can't be fetched because... reasons", moduleName)
 	}
@@ -5,12 +5,40 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/tilt-dev/go-get"
 )
 
+// The repo that `load('ext://...')` fetches extensions from,
+// unless overridden with TILT_EXTENSIONS_REGISTRY.
+const DefaultRegistry = "github.com/tilt-dev/tilt-extensions"
+
+const registryEnvVar = "TILT_EXTENSIONS_REGISTRY"
+
+// The registry to fetch new extensions from.
+//
+// Can be any repo that go-get understands, e.g.
+// TILT_EXTENSIONS_REGISTRY=github.com/my-org/tilt-extensions
+func RegistryFromEnv() string {
+	registry := strings.TrimSpace(os.Getenv(registryEnvVar))
+	if registry == "" {
+		return DefaultRegistry
+	}
+	return RegistryImportPath(registry)
+}
+
+// Registries are recorded in the metadata file as URLs, but go-get
+// wants an import path.
+func RegistryImportPath(registry string) string {
+	registry = strings.TrimPrefix(registry, "https://")
+	registry = strings.TrimPrefix(registry, "http://")
+	return strings.TrimSuffix(registry, "/")
+}
+
 type Downloader interface {
 	RootDir() string
 	Download(pkg string) (string, error)
@@ -38,29 +66,55 @@ func (d *TempDirDownloader) Download(pkg string) (string, error) {
 }
 
 type GithubFetcher struct {
-	dlr Downloader
+	dlr      Downloader
+	registry string
 }
 
-func NewGithubFetcher(dlr Downloader) *GithubFetcher {
-	return &GithubFetcher{dlr: dlr}
+func NewGithubFetcher(dlr Downloader, registry string) *GithubFetcher {
+	return &GithubFetcher{dlr: dlr, registry: registry}
 }
 
 func (f *GithubFetcher) CleanUp() error {
 	return os.RemoveAll(f.dlr.RootDir())
 }
 
-func (f *GithubFetcher) Fetch(ctx context.Context, moduleName string) (ModuleContents, error) {
-	dir, err := f.dlr.Download(path.Join("github.com/tilt-dev/tilt-extensions", moduleName))
+func (f *GithubFetcher) Fetch(ctx context.Context, moduleName string, version string) (ModuleContents, error) {
+	dir, err := f.dlr.Download(path.Join(f.registry, moduleName))
+	if err != nil {
+		return ModuleContents{}, fmt.Errorf("Fetching %s: %v", f.registry, err)
+	}
+
+	if version != "" {
+		_, err := runGit(ctx, dir, "checkout", "--quiet", version)
+		if err != nil {
+			return ModuleContents{}, fmt.Errorf("Fetching %s at version %s: %v", moduleName, version, err)
+		}
+	}
+
+	// Registries that aren't git repos don't get versions,
+	// so failing to read the commit isn't fatal.
+	head, err := runGit(ctx, dir, "rev-parse", "HEAD")
 	if err != nil {
-		return ModuleContents{}, fmt.Errorf("Fetching tilt-extensions: %v", err)
+		head = ""
 	}
 
 	return ModuleContents{
 		Name:              moduleName,
 		Dir:               dir,
-		ExtensionRegistry: "https://github.com/tilt-dev/tilt-extensions",
+		ExtensionRegistry: "https://" + f.registry,
+		Version:           head,
 		TimeFetched:       time.Now(),
 	}, nil
 }
 
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 var _ Fetcher = (*GithubFetcher)(nil)
@@ -3,6 +3,7 @@ package tiltextension
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -21,6 +22,11 @@ type Store interface {
 	// Returns ErrNotExist if module doesn't exist
 	ModulePath(ctx context.Context, moduleName string) (string, error)
 	Write(ctx context.Context, contents ModuleContents) (string, error)
+
+	// ModuleMetadata returns what we recorded about the extension the last time
+	// it was written, so that we can re-fetch the same version.
+	// Returns ErrNotExist if we've never written the extension.
+	ModuleMetadata(ctx context.Context, moduleName string) (Metadata, error)
 }
 
 type ModuleContents struct {
@@ -29,13 +35,11 @@ type ModuleContents struct {
 	ExtensionRegistry string
 	TimeFetched       time.Time
 
-	// NOTE(nick): Currently this is missing any kind of integrity hashing or versioning.
-	// This used to have a non-functional versioning stub, which we deleted in
-	// https://github.com/tilt-dev/tilt/pull/3779
+	// The git commit of the registry that the extension was fetched at.
+	// Empty if the registry isn't a git repo.
 	//
-	// If we decide to add versioning semantics, we should try to adapt something from
-	// an existing implementation (e.g., go-get's versioning sematnics) rather
-	// than trying to implement versioning from scratch.
+	// NOTE(nick): Currently this is missing any kind of integrity hashing.
+	Version string
 }
 
 type LocalStore struct {
@@ -46,6 +50,11 @@ type Metadata struct {
 	Name              string
 	ExtensionRegistry string
 	TimeFetched       time.Time
+	Version           string
+
+	// Pinned extensions are always fetched at Version,
+	// and are skipped by `tilt ext update`.
+	Pinned bool
 }
 
 type MetadataFile struct {
@@ -71,11 +80,13 @@ func (s *LocalStore) ModulePath(ctx context.Context, moduleName string) (string,
 
 // TODO(dmiller): handle atomic writes to the metadata file and the modules?
 // Right now if a write to the metadata file fails the module will still be written
-
-// TODO(dmiller): should this error if we try to write an extension with the same name as
-// one that already exists?
 func (s *LocalStore) Write(ctx context.Context, contents ModuleContents) (string, error) {
 	moduleDir := filepath.Join(s.baseDir, contents.Name)
+
+	// Clear out the old copy, so that files deleted upstream don't linger.
+	if err := os.RemoveAll(moduleDir); err != nil {
+		return "", errors.Wrapf(err, "couldn't remove old copy of module %s at path %s", contents.Name, moduleDir)
+	}
 	if err := os.MkdirAll(moduleDir, os.FileMode(0700)); err != nil {
 		return "", errors.Wrapf(err, "couldn't create module directory %s at path %s", contents.Name, moduleDir)
 	}
@@ -85,41 +96,114 @@ func (s *LocalStore) Write(ctx context.Context, contents ModuleContents) (string
 		return "", errors.Wrapf(err, "couldn't store module %s at path %s", contents.Name, moduleDir)
 	}
 
+	metadataFile, err := s.readMetadataFile()
+	if err != nil {
+		return "", err
+	}
+
 	metadata := Metadata{
 		Name:              contents.Name,
 		ExtensionRegistry: contents.ExtensionRegistry,
 		TimeFetched:       contents.TimeFetched,
+		Version:           contents.Version,
+	}
+	if i := metadataFile.index(contents.Name); i != -1 {
+		metadata.Pinned = metadataFile.Extensions[i].Pinned
+		metadataFile.Extensions[i] = metadata
+	} else {
+		metadataFile.Extensions = append(metadataFile.Extensions, metadata)
 	}
 
-	// read file if it exists, append extension, write out the file
+	err = s.writeMetadataFile(metadataFile)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(moduleDir, "Tiltfile"), nil
+}
+
+func (s *LocalStore) ModuleMetadata(ctx context.Context, moduleName string) (Metadata, error) {
+	metadataFile, err := s.readMetadataFile()
+	if err != nil {
+		return Metadata{}, err
+	}
+	i := metadataFile.index(moduleName)
+	if i == -1 {
+		return Metadata{}, os.ErrNotExist
+	}
+	return metadataFile.Extensions[i], nil
+}
+
+// List returns the metadata of every extension in the store, in the order they were first fetched.
+func (s *LocalStore) List(ctx context.Context) ([]Metadata, error) {
+	metadataFile, err := s.readMetadataFile()
+	if err != nil {
+		return nil, err
+	}
+	return metadataFile.Extensions, nil
+}
+
+// SetPinned marks whether an extension should stay at its current version.
+func (s *LocalStore) SetPinned(ctx context.Context, moduleName string, pinned bool) error {
+	metadataFile, err := s.readMetadataFile()
+	if err != nil {
+		return err
+	}
+	i := metadataFile.index(moduleName)
+	if i == -1 {
+		return fmt.Errorf("extension %q not found in %s", moduleName, s.baseDir)
+	}
+	metadataFile.Extensions[i].Pinned = pinned
+	return s.writeMetadataFile(metadataFile)
+}
+
+func (s *LocalStore) metadataFilePath() string {
+	return filepath.Join(s.baseDir, metadataFileName)
+}
+
+// Returns an empty MetadataFile if the file doesn't exist yet.
+func (s *LocalStore) readMetadataFile() (MetadataFile, error) {
 	var metadataFile MetadataFile
-	extensionMetadataFilePath := filepath.Join(s.baseDir, metadataFileName)
+	extensionMetadataFilePath := s.metadataFilePath()
 	b, err := ioutil.ReadFile(extensionMetadataFilePath)
 	if os.IsNotExist(err) {
-		metadataFile = MetadataFile{
-			Extensions: []Metadata{metadata},
-		}
+		return metadataFile, nil
 	} else if err != nil {
-		return "", errors.Wrapf(err, "unable to open extension metadata file at path %s", extensionMetadataFilePath)
-	} else {
-		err = json.Unmarshal(b, &metadataFile)
-		if err != nil {
-			return "", errors.Wrapf(err, "Unable to unmarshal metadata file at path %s", extensionMetadataFilePath)
-		}
-		metadataFile.Extensions = append(metadataFile.Extensions, metadata)
+		return metadataFile, errors.Wrapf(err, "unable to open extension metadata file at path %s", extensionMetadataFilePath)
+	}
+
+	err = json.Unmarshal(b, &metadataFile)
+	if err != nil {
+		return metadataFile, errors.Wrapf(err, "Unable to unmarshal metadata file at path %s", extensionMetadataFilePath)
 	}
+	return metadataFile, nil
+}
 
+func (s *LocalStore) writeMetadataFile(metadataFile MetadataFile) error {
+	extensionMetadataFilePath := s.metadataFilePath()
 	js, err := json.MarshalIndent(metadataFile, "", "  ")
 	if err != nil {
-		return "", errors.Wrap(err, "internal error: unable to marshal metadataFile as JSON")
+		return errors.Wrap(err, "internal error: unable to marshal metadataFile as JSON")
+	}
+
+	if err := os.MkdirAll(s.baseDir, os.FileMode(0700)); err != nil {
+		return errors.Wrapf(err, "couldn't create extension directory %s", s.baseDir)
 	}
 
 	err = ioutil.WriteFile(extensionMetadataFilePath, js, 0600)
 	if err != nil {
-		return "", errors.Wrapf(err, "unable to write extension metadata file at path %s", extensionMetadataFilePath)
+		return errors.Wrapf(err, "unable to write extension metadata file at path %s", extensionMetadataFilePath)
 	}
+	return nil
+}
 
-	return filepath.Join(moduleDir, "Tiltfile"), nil
+func (f MetadataFile) index(moduleName string) int {
+	for i, m := range f.Extensions {
+		if m.Name == moduleName {
+			return i
+		}
+	}
+	return -1
 }
 
 var _ Store = (*LocalStore)(nil)
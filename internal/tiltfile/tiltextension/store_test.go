@@ -72,6 +72,51 @@ func TestTwoExtensions(t *testing.T) {
 	f.assertExtension("test2", "print('hi')", "aaaaaa", "https://github.com/windmill/tilt-extensions")
 }
 
+func TestRewriteReplacesExtensionAndKeepsPin(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.writeModule(ModuleContents{
+		Name:              "test",
+		Dir:               f.dirWithTiltfile("print('hi')"),
+		ExtensionRegistry: "https://github.com/windmill/tilt-extensions",
+		Version:           "v1",
+	})
+	require.NoError(t, f.store.SetPinned(f.ctx, "test", true))
+
+	f.writeModule(ModuleContents{
+		Name:              "test",
+		Dir:               f.dirWithTiltfile("print('bye')"),
+		ExtensionRegistry: "https://github.com/windmill/tilt-extensions",
+		Version:           "v2",
+	})
+
+	f.assertExtension("test", "print('bye')", "aaaaaa", "https://github.com/windmill/tilt-extensions")
+
+	metadata, err := f.store.ModuleMetadata(f.ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", metadata.Version)
+	assert.True(t, metadata.Pinned)
+}
+
+func TestModuleMetadataDoesntExist(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	_, err := f.store.ModuleMetadata(f.ctx, "test")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSetPinnedUnknownExtension(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	err := f.store.SetPinned(f.ctx, "test", true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `extension "test" not found`)
+	}
+}
+
 type fixture struct {
 	t       *testing.T
 	ctx     context.Context
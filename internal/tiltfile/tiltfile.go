@@ -13,20 +13,32 @@ import (
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	clusteradmin "github.com/tilt-dev/tilt/internal/cluster"
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	tiltfileanalytics "github.com/tilt-dev/tilt/internal/tiltfile/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/baseimage"
+	"github.com/tilt-dev/tilt/internal/tiltfile/cluster"
 	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/internal/tiltfile/dockerprune"
+	"github.com/tilt-dev/tilt/internal/tiltfile/endpointhealth"
+	"github.com/tilt-dev/tilt/internal/tiltfile/hibernate"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/localdns"
+	"github.com/tilt-dev/tilt/internal/tiltfile/logsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/metrics"
+	tiltfileos "github.com/tilt-dev/tilt/internal/tiltfile/os"
+	"github.com/tilt-dev/tilt/internal/tiltfile/overlay"
+	"github.com/tilt-dev/tilt/internal/tiltfile/secrets"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tools"
 	"github.com/tilt-dev/tilt/internal/tiltfile/updatesettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
@@ -41,6 +53,9 @@ func init() {
 	resolve.AllowNestedDef = true
 	resolve.AllowGlobalReassign = true
 	resolve.AllowRecursion = true
+
+	// For fractional settings, like dev_resource_profile(scale=0.5).
+	resolve.AllowFloat = true
 }
 
 type TiltfileLoadResult struct {
@@ -49,18 +64,32 @@ type TiltfileLoadResult struct {
 	ConfigFiles         []string
 	FeatureFlags        map[string]bool
 	TeamID              string
+	AdditionalTeamIDs   []string
 	TelemetrySettings   model.TelemetrySettings
 	MetricsSettings     model.MetricsSettings
 	Secrets             model.SecretSet
 	Error               error
 	DockerPruneSettings model.DockerPruneSettings
 	AnalyticsOpt        wmanalytics.Opt
+	AnalyticsConsent    analytics.Consent
 	VersionSettings     model.VersionSettings
 	UpdateSettings      model.UpdateSettings
 	WatchSettings       model.WatchSettings
+	LocalDNSSettings    model.LocalDNSSettings
+	Cluster             model.ClusterSpec
+	HibernateSettings   model.HibernateSettings
+	EndpointHealth      model.EndpointHealthSettings
+	BaseImageChecks     model.BaseImageCheckSettings
+	LogSettings         model.LogSettings
+	SecretSettings      model.SecretSettings
+	Alerts              []model.Alert
+
+	// The environment variables the Tiltfile read.
+	Env model.TiltfileEnv
 
 	// For diagnostic purposes only
 	BuiltinCalls []starkit.BuiltinCall `json:"-"`
+	Profile      model.TiltfileProfile `json:"-"`
 }
 
 func (r TiltfileLoadResult) Orchestrator() model.Orchestrator {
@@ -125,9 +154,15 @@ func ProvideTiltfileLoader(
 		webHost:       webHost,
 		fDefaults:     fDefaults,
 		env:           env,
+		registryAdmin: clusteradmin.NewAdmin(),
 	}
 }
 
+// Sets up the registry from local_registry(). Implemented by cluster.Admin.
+type LocalRegistryAdmin interface {
+	EnsureLocalRegistry(ctx context.Context, spec model.LocalRegistrySpec, env k8s.Env, kubeContext k8s.KubeContext) (container.Registry, error)
+}
+
 type tiltfileLoader struct {
 	analytics *analytics.TiltAnalytics
 	kCli      k8s.Client
@@ -139,6 +174,10 @@ type tiltfileLoader struct {
 	configExt     *config.Extension
 	fDefaults     feature.Defaults
 	env           k8s.Env
+	registryAdmin LocalRegistryAdmin
+
+	// Only set in Tiltfile tests.
+	commandFaker CommandFaker
 }
 
 var _ TiltfileLoader = &tiltfileLoader{}
@@ -163,7 +202,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, userConfigS
 
 	tiltignorePath := watch.TiltignorePath(absFilename)
 	tlr := TiltfileLoadResult{
-		ConfigFiles: []string{absFilename, tiltignorePath},
+		ConfigFiles: []string{absFilename, tiltignorePath, overlay.Path(absFilename), LocalTiltfilePath(absFilename)},
 	}
 
 	tiltignore, err := watch.ReadTiltignore(tiltignorePath)
@@ -179,17 +218,27 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, userConfigS
 	localRegistry := tfl.kCli.LocalRegistry(ctx)
 
 	s := newTiltfileState(ctx, tfl.dcCli, tfl.webHost, tfl.k8sContextExt, tfl.versionExt, tfl.configExt, localRegistry, feature.FromDefaults(tfl.fDefaults))
+	s.commandFaker = tfl.commandFaker
+	s.localRegistryAdmin = tfl.registryAdmin
 
 	manifests, result, err := s.loadManifests(absFilename, userConfigState)
+	if err != nil {
+		err = withMissingTools(result, err)
+	}
+	clusterSpec, _ := cluster.GetState(result)
+	tlr.Cluster = clusterSpec
+	if err == nil && tfl.env == k8s.EnvNone {
+		tfl.warnIfClusterNotConfigured(ctx, s, manifests, clusterSpec)
+	}
 
 	tlr.BuiltinCalls = result.BuiltinCalls
 
 	ws, _ := watch.GetState(result)
 	tlr.WatchSettings = ws
 
-	// NOTE(maia): if/when add secret settings that affect the engine, add them to tlr here
 	ss, _ := secretsettings.GetState(result)
 	s.secretSettings = ss
+	tlr.SecretSettings = ss
 
 	ioState, _ := io.GetState(result)
 
@@ -202,12 +251,23 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, userConfigS
 
 	aSettings, _ := tiltfileanalytics.GetState(result)
 	tlr.AnalyticsOpt = aSettings.Opt
+	tlr.AnalyticsConsent = aSettings.Consent
+
+	envState, _ := tiltfileos.GetState(result)
+	tlr.Env = envState.Reads()
 
 	tlr.Secrets = s.extractSecrets()
+	secretsState, _ := secrets.GetState(result)
+	if ss.ScrubSecrets {
+		tlr.Secrets.AddAll(envState.Secrets())
+		tlr.Secrets.AddAll(secretsState.Secrets)
+	}
 	tlr.FeatureFlags = s.features.ToEnabled()
 	tlr.Error = err
 	tlr.Manifests = manifests
 	tlr.TeamID = s.teamID
+	tlr.AdditionalTeamIDs = s.additionalTeamIDs
+	tlr.Alerts = s.alerts
 
 	vs, _ := version.GetState(result)
 	tlr.VersionSettings = vs
@@ -221,8 +281,26 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, userConfigS
 	us, _ := updatesettings.GetState(result)
 	tlr.UpdateSettings = us
 
+	dnsSettings, _ := localdns.GetState(result)
+	tlr.LocalDNSSettings = dnsSettings
+
+	hibernateSettings, _ := hibernate.GetState(result)
+	tlr.HibernateSettings = hibernateSettings
+
+	endpointHealthSettings, _ := endpointhealth.GetState(result)
+	tlr.EndpointHealth = endpointHealthSettings
+	baseImageCheckSettings, _ := baseimage.GetState(result)
+	tlr.BaseImageChecks = baseImageCheckSettings
+
+	logSettings, _ := logsettings.GetState(result)
+	tlr.LogSettings = logSettings
+
 	duration := time.Since(start)
+	tlr.Profile = newTiltfileProfile(result, duration)
 	s.logger.Infof("Successfully loaded Tiltfile (%s)", duration)
+	if duration > slowTiltfileLoadThreshold {
+		s.logger.Infof("%s", tlr.Profile)
+	}
 	tfl.reportTiltfileLoaded(s.builtinCallCounts, s.builtinArgCounts, duration)
 
 	if len(aSettings.CustomTagsToReport) > 0 {
@@ -232,6 +310,34 @@ func (tfl tiltfileLoader) Load(ctx context.Context, filename string, userConfigS
 	return tlr
 }
 
+// If the Tiltfile failed after a require_tool() came up short, the
+// missing tool is probably why, so lead with it.
+func withMissingTools(result starkit.Model, err error) error {
+	toolState, _ := tools.GetState(result)
+	toolErr := toolState.Error()
+	if toolErr == nil || toolErr.Error() == err.Error() {
+		return err
+	}
+	return fmt.Errorf("%v\n\nThe Tiltfile failed, probably because of the missing tools:\n%v", toolErr, err)
+}
+
+// The Kubernetes client doesn't fail at startup if there's no cluster,
+// so that Tiltfiles that don't need one can still run. If this one does,
+// tell the user up front rather than letting every resource fail to deploy.
+func (tfl *tiltfileLoader) warnIfClusterNotConfigured(ctx context.Context, s *tiltfileState, manifests []model.Manifest, clusterSpec model.ClusterSpec) {
+	for _, m := range manifests {
+		if m.IsK8s() {
+			s.logger.Warnf("This Tiltfile has Kubernetes resources, which will fail to deploy until a cluster is configured.\n%v",
+				tfl.kCli.ConnectedToCluster(ctx))
+			if !clusterSpec.Empty() {
+				s.logger.Warnf("To create the %s cluster %q that this Tiltfile describes, run: tilt cluster create",
+					clusterSpec.Product, clusterSpec.Name)
+			}
+			return
+		}
+	}
+}
+
 func starlarkValueOrSequenceToSlice(v starlark.Value) []starlark.Value {
 	return value.ValueOrSequenceToSlice(v)
 }
@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -95,6 +97,8 @@ func TestDockerComposeManifest(t *testing.T) {
 	expectedConfFiles := []string{
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		".dockerignore",
 		"docker-compose.yml",
 		filepath.Join("foo", "Dockerfile"),
@@ -122,7 +126,7 @@ services:
 		// TODO(maia): assert m.tiltFilename
 	)
 
-	expectedConfFiles := []string{"Tiltfile", ".tiltignore", "docker-compose.yml"}
+	expectedConfFiles := []string{"Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "docker-compose.yml"}
 	f.assertConfigFiles(expectedConfFiles...)
 }
 
@@ -153,7 +157,7 @@ services:
 		// TODO(maia): assert m.tiltFilename
 	)
 
-	expectedConfFiles := []string{"Tiltfile", ".tiltignore", ".dockerignore", "docker-compose.yml", "baz/alternate-Dockerfile", "baz/.dockerignore"}
+	expectedConfFiles := []string{"Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", ".dockerignore", "docker-compose.yml", "baz/alternate-Dockerfile", "baz/.dockerignore"}
 	f.assertConfigFiles(expectedConfFiles...)
 }
 
@@ -185,7 +189,7 @@ services:
 		// TODO(maia): assert m.tiltFilename
 	)
 
-	expectedConfFiles := []string{"Tiltfile", ".tiltignore", "docker-compose.yml", "baz/alternate-Dockerfile", "baz/alternate-Dockerfile.dockerignore"}
+	expectedConfFiles := []string{"Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "docker-compose.yml", "baz/alternate-Dockerfile", "baz/alternate-Dockerfile.dockerignore"}
 	f.assertConfigFiles(expectedConfFiles...)
 }
 
@@ -288,6 +292,8 @@ RUN echo hi`
 	expectedConfFiles := []string{
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		"docker-compose.yml",
 		filepath.Join("foo", "Dockerfile"),
 		".dockerignore",
@@ -330,6 +336,8 @@ services:
 	expectedConfFiles := []string{
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		filepath.Join("foo", "docker-compose.yml"),
 		filepath.Join("foo", "Dockerfile"),
 		".dockerignore",
@@ -466,6 +474,69 @@ dc_resource('foo', 'fooimage')
 	assert.Equal(t, m.DockerComposeTarget().ConfigPaths, []string{configPath})
 }
 
+func TestDockerComposeBuildSectionBuiltByTilt(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	f.load()
+
+	configPath := f.TempDirFixture.JoinPath("docker-compose.yml")
+	backend := dockercompose.NewDockerComposeClient(docker.LocalEnv{}).Backend(f.ctx, model.DockerComposeProject{})
+	expectedImage := dockercompose.DefaultImageName(backend, []string{configPath}, "foo")
+	m := f.assertNextManifest("foo", db(image(expectedImage)))
+
+	build := m.ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, f.JoinPath("foo"), build.BuildPath)
+	assert.Equal(t, simpleDockerfile, build.Dockerfile)
+}
+
+func TestDockerComposeBuildSectionArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", `version: '3.4'
+services:
+  foo:
+    image: gcr.io/foo
+    build:
+      context: ./foo
+      target: dev
+      args:
+        - FOO=from-config
+        - BAR=from-config
+      cache_from:
+        - gcr.io/foo:cache
+    command: sleep 100`)
+	f.file("Tiltfile", `docker_compose('docker-compose.yml')
+dc_resource('foo', build_args={'BAR': 'from-tiltfile'})
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	build := m.ImageTargetAt(0).DockerBuildInfo()
+	assert.Equal(t, model.DockerBuildArgs{"FOO": "from-config", "BAR": "from-tiltfile"}, build.BuildArgs)
+	assert.Equal(t, model.DockerBuildTarget("dev"), build.TargetStage)
+	assert.Equal(t, []string{"gcr.io/foo:cache"}, build.CacheFrom)
+}
+
+func TestDockerComposeBuildArgsWithoutBuildSection(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("docker-compose.yml", barServiceConfig)
+	f.file("Tiltfile", `docker_compose('docker-compose.yml')
+dc_resource('bar', build_args={'BAR': 'from-tiltfile'})
+`)
+
+	f.loadErrString(`service "bar" has no build section`)
+}
+
 func TestMultipleDockerComposeWithDockerBuild(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -598,7 +669,7 @@ fail("deliberate exit")
 
 	// Make sure that even though tiltfile execution failed, we still
 	// loaded config files correctly.
-	f.assertConfigFiles(".tiltignore", "Tiltfile", "docker-compose.yml", "foo/Dockerfile")
+	f.assertConfigFiles(".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "Tiltfile", "docker-compose.yml", "foo/Dockerfile")
 }
 
 func TestDockerComposeDoesntSupportEntrypointOverride(t *testing.T) {
@@ -715,6 +786,47 @@ dc_resource('bar', resource_deps=['foo'])
 	f.assertNextManifest("bar", resourceDeps("foo"))
 }
 
+func TestDCSettingsBackend(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("docker-compose.yml", simpleConfig)
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("Tiltfile", `
+dc_settings(backend='docker-compose')
+docker_compose('docker-compose.yml')
+`)
+
+	f.load("foo")
+	m := f.assertDcManifest("foo")
+	assert.Equal(t, model.DockerComposeBackendV1, m.DockerComposeTarget().Backend)
+}
+
+func TestDCSettingsUnknownBackend(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+dc_settings(backend='podman')
+`)
+
+	f.loadErrString(`unknown docker-compose backend "podman"`, `"podman-compose"`)
+}
+
+func TestDCSettingsAfterDockerCompose(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("docker-compose.yml", simpleConfig)
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_settings(backend='podman-compose')
+`)
+
+	f.loadErrString("dc_settings must be called before docker_compose")
+}
+
 func (f *fixture) assertDcManifest(name model.ManifestName, opts ...interface{}) model.Manifest {
 	m := f.assertNextManifest(name)
 
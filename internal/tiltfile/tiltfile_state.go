@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/docker/distribution/reference"
 	"github.com/looplab/tarjan"
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
@@ -19,23 +21,33 @@ import (
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/baseimage"
+	"github.com/tilt-dev/tilt/internal/tiltfile/cluster"
 	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/internal/tiltfile/dockerprune"
 	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
+	"github.com/tilt-dev/tilt/internal/tiltfile/endpointhealth"
 	"github.com/tilt-dev/tilt/internal/tiltfile/git"
+	"github.com/tilt-dev/tilt/internal/tiltfile/hibernate"
 	"github.com/tilt-dev/tilt/internal/tiltfile/include"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	tiltfile_k8s "github.com/tilt-dev/tilt/internal/tiltfile/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/loadsettings"
+	"github.com/tilt-dev/tilt/internal/tiltfile/localdns"
+	"github.com/tilt-dev/tilt/internal/tiltfile/logsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/metrics"
 	"github.com/tilt-dev/tilt/internal/tiltfile/os"
+	"github.com/tilt-dev/tilt/internal/tiltfile/secrets"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/shlex"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
 	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
+	"github.com/tilt-dev/tilt/internal/tiltfile/tools"
 	"github.com/tilt-dev/tilt/internal/tiltfile/updatesettings"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
 	"github.com/tilt-dev/tilt/internal/tiltfile/watch"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -63,6 +75,7 @@ type tiltfileState struct {
 	configExt     *config.Extension
 	localRegistry container.Registry
 	features      feature.FeatureSet
+	commandFaker  CommandFaker
 
 	// added to during execution
 	buildIndex     *buildIndex
@@ -79,19 +92,43 @@ type tiltfileState struct {
 	k8sByName      map[string]*k8sResource
 	k8sUnresourced []k8s.K8sEntity
 
+	// Objects from k8s_yaml(manage=False), which Tilt shows but never applies.
+	// Keyed by entityKey, since entities get copied as they move between the lists above.
+	k8sObserved map[string]bool
+
+	// Where each object in a YAML file came from, as "path:line".
+	// Keyed by entityKey, like k8sObserved.
+	k8sSources map[string]string
+
 	dc                 dcResourceSet // currently only support one d-c.yml
+	dcBackend          model.DockerComposeBackend
 	k8sResourceOptions map[string]k8sResourceOptions
 	localResources     []localResource
 
+	// Set while Tiltfile.local is executing, which is the only place
+	// its override helpers work.
+	execingLocalTiltfile bool
+	localOverrides       localOverrides
+
 	// ensure that any images are pushed to/pulled from this registry, rewriting names if needed
 	defaultReg container.Registry
 
+	// If set, the name of the Secret that Tilt creates with the credentials for defaultReg.
+	defaultRegPullSecret string
+
+	// A registry container to run and push to, from local_registry().
+	localRegistrySpec  model.LocalRegistrySpec
+	localRegistryAdmin LocalRegistryAdmin
+
 	k8sKinds map[k8s.ObjectSelector]*tiltfile_k8s.KindInfo
 
 	k8sResourceAssemblyVersion       int
 	k8sResourceAssemblyVersionReason k8sResourceAssemblyVersionReason
 	workloadToResourceFunction       workloadToResourceFunction
 
+	// Whether to group ConfigMaps, Secrets, Ingresses, etc. with the workloads they belong to.
+	k8sAutoGroup bool
+
 	// for assembly
 	usedImages map[string]bool
 
@@ -114,10 +151,32 @@ type tiltfileState struct {
 	// for error reporting in case it's called twice
 	triggerModeCallPosition syntax.Position
 
-	teamID string
+	// global dev resource profile -- applied to all k8s manifests
+	// (tho user can override the scale for a specific manifest)
+	devResourceProfile             model.DevResourceProfile
+	devResourceProfileCallPosition syntax.Position
+
+	// global labels and annotations -- applied to every k8s object we deploy
+	k8sObjectLabels             model.K8sObjectLabels
+	k8sObjectLabelsCallPosition syntax.Position
+
+	teamID            string
+	additionalTeamIDs []string
+
+	// Problems that we should surface in the alert center, not just the logs.
+	alerts []model.Alert
+
+	// Commands started with local_async(), in the order they were started.
+	localAsyncHandles []*localAsyncHandle
+
+	// Held while writing a line of local_async() output to the log.
+	localAsyncOutputMu sync.Mutex
 
 	secretSettings model.SecretSettings
 
+	// read after execution, before we put the resources together
+	loadSettings loadsettings.Settings
+
 	logger                           logger.Logger
 	warnedDeprecatedResourceAssembly bool
 
@@ -155,15 +214,19 @@ func newTiltfileState(
 		buildIndex:                 newBuildIndex(),
 		k8sObjectIndex:             tiltfile_k8s.NewState(),
 		k8sByName:                  make(map[string]*k8sResource),
+		k8sObserved:                make(map[string]bool),
+		k8sSources:                 make(map[string]string),
 		usedImages:                 make(map[string]bool),
 		logger:                     logger.Get(ctx),
 		builtinCallCounts:          make(map[string]int),
 		builtinArgCounts:           make(map[string]map[string]int),
 		unconsumedLiveUpdateSteps:  make(map[string]liveUpdateStep),
 		k8sResourceAssemblyVersion: 2,
+		k8sAutoGroup:               true,
 		k8sResourceOptions:         make(map[string]k8sResourceOptions),
 		localResources:             []localResource{},
 		triggerMode:                TriggerModeAuto,
+		devResourceProfile:         model.DevResourceProfile{LocalOnly: true},
 		features:                   features,
 		secretSettings:             model.DefaultSecretSettings(),
 		k8sKinds:                   make(map[k8s.ObjectSelector]*tiltfile_k8s.KindInfo),
@@ -191,7 +254,7 @@ func (s *tiltfileState) loadManifests(absFilename string, userConfigState model.
 	if err != nil {
 		return nil, starkit.Model{}, err
 	}
-	fetcher := tiltextension.NewGithubFetcher(dlr)
+	fetcher := tiltextension.NewGithubFetcher(dlr, tiltextension.RegistryFromEnv())
 
 	result, err := starkit.ExecFile(absFilename,
 		s,
@@ -208,16 +271,36 @@ func (s *tiltfileState) loadManifests(absFilename string, userConfigState model.
 		telemetry.NewExtension(),
 		metrics.NewExtension(),
 		updatesettings.NewExtension(),
+		localdns.NewExtension(),
+		hibernate.NewExtension(),
+		endpointhealth.NewExtension(),
+		baseimage.NewExtension(),
+		logsettings.NewExtension(),
+		loadsettings.NewExtension(),
 		secretsettings.NewExtension(),
+		secrets.NewExtension(),
 		encoding.NewExtension(),
 		shlex.NewExtension(),
 		watch.NewExtension(),
+		tools.NewExtension(),
+		cluster.NewExtension(),
 		tiltextension.NewExtension(fetcher, tiltextension.NewLocalStore(filepath.Dir(absFilename))),
 	)
+
+	// Even if the Tiltfile failed, don't leave its commands running.
+	asyncErr := s.waitForUnwaitedLocalAsync()
+	if err == nil {
+		err = asyncErr
+	}
 	if err != nil {
+		if starkit.IsSyntaxError(err) {
+			return nil, result, model.TiltfileSyntaxError(starkit.UnpackBacktrace(err))
+		}
 		return nil, result, starkit.UnpackBacktrace(err)
 	}
 
+	s.loadSettings, _ = loadsettings.GetState(result)
+
 	resources, unresourced, err := s.assemble()
 	if err != nil {
 		return nil, result, err
@@ -250,7 +333,7 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		}
 	}
 
-	err = s.validateLiveUpdatesForManifests(manifests)
+	manifests, err = s.validateLiveUpdatesForManifests(manifests)
 	if err != nil {
 		return nil, result, err
 	}
@@ -260,12 +343,22 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		return nil, result, err
 	}
 
+	err = s.addOverlayResources(absFilename, manifests)
+	if err != nil {
+		return nil, result, err
+	}
+
 	localManifests, err := s.translateLocal()
 	if err != nil {
 		return nil, result, err
 	}
 	manifests = append(manifests, localManifests...)
 
+	manifests, err = s.applyLocalOverrides(manifests)
+	if err != nil {
+		return nil, result, err
+	}
+
 	configSettings, _ := config.GetState(result)
 	manifests, err = configSettings.EnabledResources(manifests)
 	if err != nil {
@@ -277,18 +370,53 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		if err != nil {
 			return nil, starkit.Model{}, err
 		}
+		yamlManifest = yamlManifest.WithDeployTarget(yamlManifest.K8sTarget().WithObjectLabels(s.k8sObjectLabels))
 
 		manifests = append(manifests, yamlManifest)
 	}
 
-	err = validateResourceDependencies(manifests)
+	manifests, err = s.degradeResources(manifests, validateResourceDependencies(manifests))
 	if err != nil {
 		return nil, starkit.Model{}, err
 	}
 
+	manifests, err = s.degradeResources(manifests, validateLiveUpdateRestartResources(manifests))
+	if err != nil {
+		return nil, starkit.Model{}, err
+	}
+
+	us, _ := updatesettings.GetState(result)
+	manifests = applyBuildSettings(manifests, us)
+
 	return manifests, result, nil
 }
 
+// docker_build(build_output=...) overrides the global update_settings(build_output=...).
+// Resolve them here (along with other global build settings), so that the builder
+// doesn't need to know about the global.
+func applyBuildSettings(manifests []model.Manifest, us model.UpdateSettings) []model.Manifest {
+	for i, m := range manifests {
+		if len(m.ImageTargets) == 0 {
+			continue
+		}
+		iTargets := make([]model.ImageTarget, 0, len(m.ImageTargets))
+		for _, iTarget := range m.ImageTargets {
+			db, ok := iTarget.BuildDetails.(model.DockerBuild)
+			if ok {
+				if db.OutputVerbosity == model.BuildOutputDefault {
+					db.OutputVerbosity = us.BuildOutputVerbosity()
+				}
+				db.ContextWarningSize = us.BuildContextWarningSize()
+				db.AnalyzeLayers = us.ImageLayerAnalysis()
+				iTarget = iTarget.WithBuildDetails(db)
+			}
+			iTargets = append(iTargets, iTarget)
+		}
+		manifests[i] = m.WithImageTargets(iTargets)
+	}
+	return manifests
+}
+
 // Builtin functions
 
 const (
@@ -297,10 +425,12 @@ const (
 	fastBuildN       = "fast_build"
 	customBuildN     = "custom_build"
 	defaultRegistryN = "default_registry"
+	localRegistryN   = "local_registry"
 
 	// docker compose functions
 	dockerComposeN = "docker_compose"
 	dcResourceN    = "dc_resource"
+	dcSettingsN    = "dc_settings"
 
 	// k8s functions
 	k8sResourceAssemblyVersionN = "k8s_resource_assembly_version"
@@ -309,20 +439,27 @@ const (
 	k8sResourceN                = "k8s_resource"
 	localResourceN              = "local_resource"
 	portForwardN                = "port_forward"
+	seedSQLN                    = "seed_sql"
 	k8sKindN                    = "k8s_kind"
 	k8sImageJSONPathN           = "k8s_image_json_path"
 	workloadToResourceFunctionN = "workload_to_resource_function"
+	k8sAutoGroupN               = "k8s_auto_group"
+	devResourceProfileN         = "dev_resource_profile"
+	k8sManagedLabelsN           = "k8s_managed_labels"
 
 	// file functions
-	localN     = "local"
-	kustomizeN = "kustomize"
-	helmN      = "helm"
+	localN      = "local"
+	localAsyncN = "local_async"
+	waitAllN    = "wait_all"
+	kustomizeN  = "kustomize"
+	helmN       = "helm"
 
 	// live update functions
 	fallBackOnN       = "fall_back_on"
 	syncN             = "sync"
 	runN              = "run"
 	restartContainerN = "restart_container"
+	restartResourceN  = "restart_resource"
 
 	// trigger mode
 	triggerModeN       = "trigger_mode"
@@ -477,21 +614,30 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		builtin starkit.Function
 	}{
 		{localN, s.potentiallyK8sUnsafeBuiltin(s.local)},
+		{localAsyncN, s.potentiallyK8sUnsafeBuiltin(s.localAsync)},
+		{waitAllN, s.waitAll},
 		{dockerBuildN, s.dockerBuild},
 		{fastBuildN, s.fastBuild},
 		{customBuildN, s.customBuild},
 		{defaultRegistryN, s.defaultRegistry},
+		{localRegistryN, s.localRegistryFn},
 		{dockerComposeN, s.dockerCompose},
 		{dcResourceN, s.dcResource},
+		{dcSettingsN, s.dcSettings},
 		{k8sResourceAssemblyVersionN, s.k8sResourceAssemblyVersionFn},
 		{k8sYamlN, s.k8sYaml},
 		{filterYamlN, s.filterYaml},
 		{k8sResourceN, s.k8sResource},
 		{localResourceN, s.localResource},
 		{portForwardN, s.portForward},
+		{linkN, s.linkFn},
+		{seedSQLN, s.seedSQL},
 		{k8sKindN, s.k8sKind},
 		{k8sImageJSONPathN, s.k8sImageJsonPath},
 		{workloadToResourceFunctionN, s.workloadToResourceFunctionFn},
+		{k8sAutoGroupN, s.k8sAutoGroupFn},
+		{devResourceProfileN, s.devResourceProfileFn},
+		{k8sManagedLabelsN, s.k8sManagedLabelsFn},
 		{kustomizeN, s.kustomize},
 		{helmN, s.helm},
 		{failN, s.fail},
@@ -500,10 +646,14 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		{syncN, s.liveUpdateSync},
 		{runN, s.liveUpdateRun},
 		{restartContainerN, s.liveUpdateRestartContainer},
+		{restartResourceN, s.liveUpdateRestartResource},
 		{enableFeatureN, s.enableFeature},
 		{disableFeatureN, s.disableFeature},
 		{disableSnapshotsN, s.disableSnapshots},
 		{setTeamN, s.setTeam},
+		{overrideDockerBuildN, s.overrideDockerBuild},
+		{disableResourceN, s.disableResource},
+		{addPortForwardN, s.addPortForward},
 	} {
 		err := e.AddBuiltin(b.name, b.builtin)
 		if err != nil {
@@ -517,6 +667,9 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 	}{
 		{triggerModeAutoN, TriggerModeAuto},
 		{triggerModeManualN, TriggerModeManual},
+		{priorityLowN, buildPriority(model.BuildPriorityLow)},
+		{priorityNormalN, buildPriority(model.BuildPriorityNormal)},
+		{priorityHighN, buildPriority(model.BuildPriorityHigh)},
 	} {
 		err := e.AddValue(v.name, v.value)
 		if err != nil {
@@ -567,14 +720,46 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 	err = s.assertAllImagesMatched()
 	if err != nil {
 		s.logger.Warnf("%s", err.Error())
+		s.alerts = append(s.alerts, model.Alert{
+			ID:       string(model.AlertSourceImageInjection),
+			Source:   model.AlertSourceImageInjection,
+			Severity: model.AlertSeverityWarning,
+			Message:  err.Error(),
+		})
 	}
 
+	s.checkDeprecatedAPIs()
+
 	return resourceSet{
 		dc:  s.dc,
 		k8s: s.k8s,
 	}, s.k8sUnresourced, nil
 }
 
+// Add an alert for every k8s entity that uses a deprecated API version.
+func (s *tiltfileState) checkDeprecatedAPIs() {
+	check := func(mn model.ManifestName, entities []k8s.K8sEntity) {
+		for _, e := range entities {
+			msg, ok := k8s.DeprecatedAPIMessage(e)
+			if !ok {
+				continue
+			}
+			s.alerts = append(s.alerts, model.Alert{
+				ID:           fmt.Sprintf("%s:%s/%s", model.AlertSourceDeprecatedAPI, e.GVK().Kind, e.Name()),
+				Source:       model.AlertSourceDeprecatedAPI,
+				Severity:     model.AlertSeverityWarning,
+				ManifestName: mn,
+				Message:      msg,
+			})
+		}
+	}
+
+	for _, r := range s.k8s {
+		check(model.ManifestName(r.name), r.entities)
+	}
+	check(model.UnresourcedYAMLManifestName, s.k8sUnresourced)
+}
+
 // Emit an error if there are unmatches images.
 //
 // There are 4 mistakes people commonly make if they
@@ -644,11 +829,23 @@ func (s *tiltfileState) assembleDC() error {
 			builder := s.buildIndex.findBuilderForConsumedImage(svc.ImageRef())
 			if builder != nil {
 				svc.DependencyIDs = append(svc.DependencyIDs, builder.ID())
+				continue
 			}
 			// TODO(maia): throw warning if
 			//  a. there is an img ref from config, and img ref from user doesn't match
 			//  b. there is no img ref from config, and img ref from user is not of form .*_<svc_name>
 		}
+
+		if svc.DfPath != "" && svc.ImageRef() != nil {
+			// The service has a build section, but no docker_build. Build it
+			// with our own image builder rather than `docker-compose build`,
+			// so that we get BuildKit, build arg handling, and streaming logs.
+			builder, err := s.dcImageBuilder(svc)
+			if err != nil {
+				return errors.Wrapf(err, "docker-compose %s", svc.Name)
+			}
+			svc.DependencyIDs = append(svc.DependencyIDs, builder.ID())
+		}
 	}
 	return nil
 }
@@ -684,6 +881,11 @@ func (s *tiltfileState) assembleK8sV2() error {
 		return err
 	}
 
+	err = s.assembleK8sRelated()
+	if err != nil {
+		return err
+	}
+
 	resourcedEntities := []k8s.K8sEntity{}
 	for _, r := range s.k8sByName {
 		resourcedEntities = append(resourcedEntities, r.entities...)
@@ -713,14 +915,28 @@ func (s *tiltfileState) assembleK8sV2() error {
 			r.portForwards = opts.portForwards
 			r.triggerMode = opts.triggerMode
 			r.autoInit = opts.autoInit
+			r.priority = opts.priority
 			r.resourceDeps = opts.resourceDeps
+			r.scaleResources = opts.scaleResources
+			r.orderedPodManagement = opts.orderedPods
+			r.deletePVCs = opts.deletePVCs
+			r.pauseAutoscaling = opts.pauseAutoscaling
+			r.podReplacement = opts.podReplacement
+			r.transform = opts.transform
+			r.seed = opts.seed
+			r.links = opts.links
+			r.namespaces = opts.namespaces
 			if opts.newName != "" && opts.newName != r.name {
 				if _, ok := s.k8sByName[opts.newName]; ok {
-					return fmt.Errorf("k8s_resource at %s specified to rename %q to %q, but there already exists a resource with that name", opts.tiltfilePosition.String(), r.name, opts.newName)
+					err := s.k8sResourceConfigError(r, fmt.Errorf("k8s_resource at %s specified to rename %q to %q, but there already exists a resource with that name", opts.tiltfilePosition.String(), r.name, opts.newName))
+					if err != nil {
+						return err
+					}
+				} else {
+					delete(s.k8sByName, r.name)
+					r.name = opts.newName
+					s.k8sByName[r.name] = r
 				}
-				delete(s.k8sByName, r.name)
-				r.name = opts.newName
-				s.k8sByName[r.name] = r
 			}
 
 			selectors := make([]k8s.ObjectSelector, len(opts.objects))
@@ -735,14 +951,22 @@ func (s *tiltfileState) assembleK8sV2() error {
 			for i, o := range opts.objects {
 				entities, ok := fragmentsToEntities[strings.ToLower(o)]
 				if !ok || len(entities) == 0 {
-					return fmt.Errorf("No object identified by the fragment %q could be found. Possible objects are: %s", o, sliceutils.QuotedStringList(fullNames))
+					err := s.k8sResourceConfigError(r, fmt.Errorf("No object identified by the fragment %q could be found. Possible objects are: %s", o, sliceutils.QuotedStringList(fullNames)))
+					if err != nil {
+						return err
+					}
+					continue
 				}
 				if len(entities) > 1 {
 					matchingObjects := make([]string, len(entities))
 					for i, e := range entities {
 						matchingObjects[i] = fullNameFromK8sEntity(e)
 					}
-					return fmt.Errorf("%q is not a unique fragment. Objects that match %q are %s", o, o, sliceutils.QuotedStringList(matchingObjects))
+					err := s.k8sResourceConfigError(r, fmt.Errorf("%q is not a unique fragment. Objects that match %q are %s", o, o, sliceutils.QuotedStringList(matchingObjects)))
+					if err != nil {
+						return err
+					}
+					continue
 				}
 
 				entitiesToRemove := filterEntitiesBySelector(s.k8sUnresourced, selectors[i])
@@ -752,7 +976,11 @@ func (s *tiltfileState) assembleK8sV2() error {
 					for i, entity := range s.k8sUnresourced {
 						remainingUnresourced[i] = fullNameFromK8sEntity(entity)
 					}
-					return fmt.Errorf("No object identified by the fragment %q could be found in remaining YAML. Valid remaining fragments are: %s", o, sliceutils.QuotedStringList(remainingUnresourced))
+					err := s.k8sResourceConfigError(r, fmt.Errorf("No object identified by the fragment %q could be found in remaining YAML. Valid remaining fragments are: %s", o, sliceutils.QuotedStringList(remainingUnresourced)))
+					if err != nil {
+						return err
+					}
+					continue
 				}
 				if len(entitiesToRemove) > 1 {
 					panic(fmt.Sprintf("Fragment %q matches %d resources. Each object fragment must match exactly 1 resource. This should NOT be possible at this point in the code, we should have already checked that this fragment was unique", o, len(entitiesToRemove)))
@@ -766,18 +994,51 @@ func (s *tiltfileState) assembleK8sV2() error {
 			for name := range s.k8sByName {
 				knownResources = append(knownResources, name)
 			}
-			return fmt.Errorf("k8s_resource at %s specified unknown resource %q. known resources: %s\n\nNote: Tilt's resource naming has recently changed. See https://docs.tilt.dev/resource_assembly_migration.html for more info", opts.tiltfilePosition.String(), workload, strings.Join(knownResources, ", "))
+			err := fmt.Errorf("k8s_resource at %s specified unknown resource %q. known resources: %s\n\nNote: Tilt's resource naming has recently changed. See https://docs.tilt.dev/resource_assembly_migration.html for more info", opts.tiltfilePosition.String(), workload, strings.Join(knownResources, ", "))
+			if !s.loadSettings.ContinueOnError() {
+				return err
+			}
+
+			// Show the resource that the user asked for, with the error.
+			name := workload
+			if opts.newName != "" {
+				name = opts.newName
+			}
+			if _, ok := s.k8sByName[name]; ok {
+				return err
+			}
+			r, _ := s.makeK8sResource(name)
+			_ = s.k8sResourceConfigError(r, err)
 		}
 	}
 
 	for _, r := range s.k8s {
+		if r.configErr != nil {
+			continue
+		}
 		if err := s.validateK8s(r); err != nil {
-			return err
+			err = s.k8sResourceConfigError(r, err)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// With load_settings(on_error='continue_on_error'), records the error on the
+// resource and returns nil, so that the rest of the resources can load.
+// Otherwise, returns the error.
+func (s *tiltfileState) k8sResourceConfigError(r *k8sResource, err error) error {
+	if !s.loadSettings.ContinueOnError() {
+		return err
+	}
+	if r.configErr == nil {
+		r.configErr = err
+	}
+	return nil
+}
+
 // NOTE(dmiller): This isn't _technically_ a fullname since it is missing "group" (core, apps, data, etc)
 // A true full name would look like "foo:secret:mynamespace:core"
 // However because we
@@ -844,7 +1105,7 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 		if err != nil {
 			return errors.Wrapf(err, "error making resource for workload %s", newK8sObjectID(workload))
 		}
-		err = res.addEntities([]k8s.K8sEntity{workload}, locators, s.envVarImages())
+		err = s.addEntities(res, []k8s.K8sEntity{workload}, locators)
 		if err != nil {
 			return err
 		}
@@ -856,7 +1117,7 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 			return err
 		}
 
-		err = res.addEntities(match, locators, s.envVarImages())
+		err = s.addEntities(res, match, locators)
 		if err != nil {
 			return err
 		}
@@ -867,6 +1128,74 @@ func (s *tiltfileState) assembleK8sByWorkload() error {
 	return nil
 }
 
+// assembleK8sRelated moves unresourced entities that obviously belong to a
+// workload (e.g., the ConfigMaps it mounts, or the Ingress in front of its Service)
+// into that workload's resource.
+//
+// Entities the user assigned with k8s_resource(objects=...) stay put,
+// so that they're still there when we apply the resource options.
+func (s *tiltfileState) assembleK8sRelated() error {
+	if !s.k8sAutoGroup || len(s.k8s) == 0 {
+		return nil
+	}
+
+	var selectors []k8s.ObjectSelector
+	for _, opts := range s.k8sResourceOptions {
+		for _, o := range opts.objects {
+			sel, err := k8s.SelectorFromString(o)
+			if err != nil {
+				return errors.Wrapf(err, "Error making selector from string %q", o)
+			}
+			selectors = append(selectors, sel)
+		}
+	}
+
+	var candidates []k8s.K8sEntity
+	for _, e := range s.k8sUnresourced {
+		claimed := false
+		for _, sel := range selectors {
+			if sel.Matches(e) {
+				claimed = true
+				break
+			}
+		}
+		if !claimed {
+			candidates = append(candidates, e)
+		}
+	}
+
+	groups := make([][]k8s.K8sEntity, len(s.k8s))
+	for i, r := range s.k8s {
+		groups[i] = r.entities
+	}
+
+	related, _, err := k8s.GroupRelatedEntities(groups, candidates)
+	if err != nil {
+		return err
+	}
+
+	locators := s.k8sImageLocatorsList()
+	grouped := make(map[runtime.Object]bool)
+	for i, r := range s.k8s {
+		err := s.addEntities(r, related[i], locators)
+		if err != nil {
+			return err
+		}
+		for _, e := range related[i] {
+			grouped[e.Obj] = true
+		}
+	}
+
+	var rest []k8s.K8sEntity
+	for _, e := range s.k8sUnresourced {
+		if !grouped[e.Obj] {
+			rest = append(rest, e)
+		}
+	}
+	s.k8sUnresourced = rest
+	return nil
+}
+
 func (s *tiltfileState) envVarImages() []container.RefSelector {
 	var r []container.RefSelector
 	// explicitly don't care about order
@@ -1024,6 +1353,11 @@ func (s *tiltfileState) findUnresourcedImages() ([]reference.Named, error) {
 
 	locators := s.k8sImageLocatorsList()
 	for _, e := range s.k8sUnresourced {
+		// We never deploy observed objects, so never build images for them.
+		if s.isObservedEntity(e) {
+			continue
+		}
+
 		images, err := e.FindImages(locators, s.envVarImages())
 		if err != nil {
 			return nil, err
@@ -1046,6 +1380,12 @@ func (s *tiltfileState) extractEntities(dest *k8sResource, imageRef container.Re
 		return err
 	}
 
+	// Leave observed objects for assembleK8sUnresourced, so that they get
+	// their own resource instead of depending on an image we won't deploy.
+	var observed []k8s.K8sEntity
+	extracted, observed = s.partitionObservedEntities(extracted)
+	remaining = append(remaining, observed...)
+
 	err = dest.addEntities(extracted, locators, s.envVarImages())
 	if err != nil {
 		return err
@@ -1070,9 +1410,10 @@ func (s *tiltfileState) extractEntities(dest *k8sResource, imageRef container.Re
 }
 
 // decideRegistry returns the image registry we should use; if detected, a pre-configured
-// local registry; otherwise, the registry specified by the user via default_registry.
+// local registry; otherwise, the one from local_registry(), if we could set it up;
+// otherwise, the registry specified by the user via default_registry.
 // Otherwise, we'll return the zero value of `s.defaultReg`, which is an empty registry.
-// It has side-effects (a log line) and so should only be called once.
+// It has side-effects (a log line, and starting a registry) and so should only be called once.
 func (s *tiltfileState) decideRegistry() container.Registry {
 	if s.orchestrator() == model.OrchestratorK8s && !s.localRegistry.Empty() {
 		// If we've found a local registry in the cluster at run-time, use that
@@ -1080,6 +1421,16 @@ func (s *tiltfileState) decideRegistry() container.Registry {
 		s.logger.Infof("Auto-detected local registry from environment: %s", s.localRegistry)
 		return s.localRegistry
 	}
+	if s.orchestrator() == model.OrchestratorK8s && !s.localRegistrySpec.Empty() && s.localRegistryAdmin != nil {
+		reg, err := s.localRegistryAdmin.EnsureLocalRegistry(s.ctx, s.localRegistrySpec,
+			s.k8sContextExt.Env(), s.k8sContextExt.KubeContext())
+		if err != nil {
+			s.logger.Warnf("local_registry: %v", err)
+		} else if !reg.Empty() {
+			s.logger.Infof("Pushing images to local registry: %s", reg)
+			return reg
+		}
+	}
 	return s.defaultReg
 }
 
@@ -1147,46 +1498,164 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource) ([]model.Manifest
 	locators := s.k8sImageLocatorsList()
 	registry := s.decideRegistry()
 	for _, r := range resources {
-		mn := model.ManifestName(r.name)
-		tm, err := starlarkTriggerModeToModel(s.triggerModeForResource(r.triggerMode), r.autoInit)
+		err := r.configErr
+		var m model.Manifest
+		if err == nil {
+			m, err = s.translateK8sResource(r, locators, registry)
+		}
 		if err != nil {
-			return nil, errors.Wrapf(err, "error in resource %s options", mn)
+			mn := model.ManifestName(r.name)
+			m, err = s.degradeResource(model.Manifest{Name: mn}.WithDeployTarget(model.K8sTarget{}), err)
+			if err != nil {
+				return nil, err
+			}
 		}
+		result = append(result, m)
+	}
 
-		var mds []model.ManifestName
-		for _, md := range r.resourceDeps {
-			mds = append(mds, model.ManifestName(md))
-		}
-		m := model.Manifest{
-			Name:                 mn,
-			TriggerMode:          tm,
-			ResourceDependencies: mds,
-		}
+	err := maybeRestartContainerDeprecationError(result)
+	if err != nil {
+		return nil, err
+	}
 
-		k8sTarget, err := k8s.NewTarget(mn.TargetName(), r.entities, s.defaultedPortForwards(r.portForwards),
-			r.extraPodSelectors, r.dependencyIDs, r.imageRefMap, s.inferPodReadinessMode(r), locators)
-		if err != nil {
-			return nil, err
-		}
+	return result, nil
+}
 
-		m = m.WithDeployTarget(k8sTarget)
+func (s *tiltfileState) translateK8sResource(r *k8sResource, locators []k8s.ImageLocator, registry container.Registry) (model.Manifest, error) {
+	mn := model.ManifestName(r.name)
+	tm, err := starlarkTriggerModeToModel(s.triggerModeForResource(r.triggerMode), r.autoInit)
+	if err != nil {
+		return model.Manifest{}, errors.Wrapf(err, "error in resource %s options", mn)
+	}
 
-		iTargets, err := s.imgTargetsForDependencyIDs(r.dependencyIDs, registry)
+	var mds []model.ManifestName
+	for _, md := range r.resourceDeps {
+		mds = append(mds, model.ManifestName(md))
+	}
+	m := model.Manifest{
+		Name:                 mn,
+		TriggerMode:          tm,
+		ResourceDependencies: mds,
+		BuildPriority:        model.BuildPriority(r.priority),
+		Links:                r.links,
+	}
+
+	entities := r.entities
+	if len(r.namespaces) > 0 {
+		entities = s.fanOutEntities(r.entities, r.namespaces)
+	}
+
+	k8sTarget, err := k8s.NewTarget(mn.TargetName(), entities, s.defaultedPortForwards(r.portForwards),
+		r.extraPodSelectors, r.dependencyIDs, r.imageRefMap, s.inferPodReadinessMode(r), locators)
+	if err != nil {
+		return model.Manifest{}, err
+	}
+
+	profile := withResourceScale(s.devResourceProfile, r.scaleResources)
+	if !profile.Empty() {
+		k8sTarget = k8sTarget.WithDevResourceProfile(profile)
+	}
+	k8sTarget.OrderedPodManagement = r.orderedPodManagement
+	k8sTarget.DeletePVCs = r.deletePVCs
+	k8sTarget.PauseAutoscaling = r.pauseAutoscaling
+	k8sTarget.PodReplacement = r.podReplacement
+	k8sTarget.Namespaces = r.namespaces
+	k8sTarget = k8sTarget.WithObjectLabels(s.k8sObjectLabels)
+	k8sTarget.Seed = r.seed
+	k8sTarget = k8s.WithObjectSources(k8sTarget, entities, s.entitySource)
+
+	k8sTarget, err = k8s.WithObservedEntities(k8sTarget, entities, s.isObservedEntity)
+	if err != nil {
+		return model.Manifest{}, errors.Wrapf(err, "resource %s", r.name)
+	}
+
+	if r.transform != nil {
+		// Only the objects that Tilt applies go through the transform.
+		managed, err := k8s.ParseYAMLFromString(k8sTarget.YAML)
 		if err != nil {
-			return nil, errors.Wrapf(err, "getting image build info for %s", r.name)
+			return model.Manifest{}, errors.Wrapf(err, "resource %s", r.name)
 		}
+		transform, err := newK8sTransform(s.ctx, r.transform, managed)
+		if err != nil {
+			return model.Manifest{}, errors.Wrapf(err, "k8s_resource %q", r.name)
+		}
+		k8sTarget.Transform = transform
+	}
 
-		m = m.WithImageTargets(iTargets)
-
-		result = append(result, m)
+	// If we're pushing the resource's images to a private default_registry,
+	// its pods need credentials to pull them.
+	if s.defaultRegPullSecret != "" && len(r.dependencyIDs) > 0 && registry.Host == s.defaultReg.Host {
+		k8sTarget.ImagePullSecret = model.ImagePullSecret{Name: s.defaultRegPullSecret, Host: registry.Host}
 	}
+	m = m.WithDeployTarget(k8sTarget)
 
-	err := maybeRestartContainerDeprecationError(result)
+	iTargets, err := s.imgTargetsForDependencyIDs(r.dependencyIDs, registry)
 	if err != nil {
-		return nil, err
+		return model.Manifest{}, errors.Wrapf(err, "getting image build info for %s", r.name)
 	}
 
-	return result, nil
+	m = m.WithImageTargets(iTargets)
+
+	return m, nil
+}
+
+// Copies each of the resource's namespaced objects into every namespace.
+// Cluster-scoped objects, and objects that Tilt only observes, keep one copy.
+func (s *tiltfileState) fanOutEntities(entities []k8s.K8sEntity, namespaces []string) []k8s.K8sEntity {
+	var result []k8s.K8sEntity
+	for _, e := range entities {
+		if e.IsClusterScoped() || s.isObservedEntity(e) {
+			result = append(result, e)
+			continue
+		}
+		for _, ns := range namespaces {
+			copied := e.WithNamespace(ns)
+			s.k8sSources[entityKey(copied)] = s.k8sSources[entityKey(e)]
+			result = append(result, copied)
+		}
+	}
+	return result
+}
+
+func (s *tiltfileState) isObservedEntity(e k8s.K8sEntity) bool {
+	return s.k8sObserved[entityKey(e)]
+}
+
+// Identifies an object by its name, kind, and namespace, so that
+// copies of an entity have the same key.
+func entityKey(e k8s.K8sEntity) string {
+	return newK8sObjectID(e).String()
+}
+
+func (s *tiltfileState) entitySource(e k8s.K8sEntity) string {
+	return s.k8sSources[entityKey(e)]
+}
+
+// Adds entities to the resource. We never deploy observed objects,
+// so the resource doesn't depend on their images.
+func (s *tiltfileState) addEntities(r *k8sResource, entities []k8s.K8sEntity, locators []k8s.ImageLocator) error {
+	for _, e := range entities {
+		if s.isObservedEntity(e) {
+			r.entities = append(r.entities, e)
+			continue
+		}
+		err := r.addEntities([]k8s.K8sEntity{e}, locators, s.envVarImages())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *tiltfileState) partitionObservedEntities(entities []k8s.K8sEntity) (managed, observed []k8s.K8sEntity) {
+	for _, e := range entities {
+		if s.isObservedEntity(e) {
+			observed = append(observed, e)
+		} else {
+			managed = append(managed, e)
+		}
+	}
+	return managed, observed
 }
 
 // Fill in default values in port-forwarding.
@@ -1212,14 +1681,17 @@ func (s *tiltfileState) defaultedPortForwards(pfs []model.PortForward) []model.P
 	return result
 }
 
-func (s *tiltfileState) validateLiveUpdatesForManifests(manifests []model.Manifest) error {
-	for _, m := range manifests {
+func (s *tiltfileState) validateLiveUpdatesForManifests(manifests []model.Manifest) ([]model.Manifest, error) {
+	for i, m := range manifests {
 		err := s.validateLiveUpdatesForManifest(m)
 		if err != nil {
-			return err
+			manifests[i], err = s.degradeResource(m, err)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
-	return nil
+	return manifests, nil
 }
 
 // validateLiveUpdatesForManifest checks any image targets on the
@@ -1384,6 +1856,10 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 				CacheFrom:   image.cacheFrom,
 				PullParent:  image.pullParent,
 				ExtraTags:   image.extraTags,
+
+				OutputVerbosity: image.buildOutput,
+				BuildOn:         image.buildOn,
+				RegistryCache:   image.registryCache,
 			})
 		case CustomBuild:
 			r := model.CustomBuild{
@@ -1394,6 +1870,9 @@ func (s *tiltfileState) imgTargetsForDependencyIDsHelper(ids []model.TargetID, c
 				DisablePush:       image.disablePush,
 				SkipsLocalDocker:  image.skipsLocalDocker,
 				OutputsImageRefTo: image.outputsImageRefTo,
+				Sandbox:           image.sandbox,
+				OutputsManifest:   image.outputsManifest,
+				OutputRefs:        image.outputRefs,
 				LiveUpdate:        lu,
 			}
 			iTarget = iTarget.WithBuildDetails(r).
@@ -1432,30 +1911,41 @@ func (s *tiltfileState) translateDC(dc dcResourceSet) ([]model.Manifest, error)
 	var result []model.Manifest
 
 	for _, svc := range dc.services {
-		m, err := s.dcServiceToManifest(svc, dc)
+		m, err := s.translateDCService(svc, dc)
 		if err != nil {
-			return nil, err
-		}
-
-		iTargets, err := s.imgTargetsForDependencyIDs(svc.DependencyIDs, container.Registry{}) // Registry not relevant to DC
-		if err != nil {
-			return nil, errors.Wrapf(err, "getting image build info for %s", svc.Name)
-		}
-
-		for _, iTarg := range iTargets {
-			if !iTarg.OverrideCmd.Empty() {
-				return nil, fmt.Errorf("docker_build/custom_build.entrypoint not supported for Docker Compose resources")
+			mn := model.ManifestName(svc.Name)
+			m, err = s.degradeResource(model.Manifest{Name: mn}.WithDeployTarget(model.DockerComposeTarget{}), err)
+			if err != nil {
+				return nil, err
 			}
 		}
 
-		m = m.WithImageTargets(iTargets)
-
 		result = append(result, m)
 	}
 
 	return result, nil
 }
 
+func (s *tiltfileState) translateDCService(svc *dcService, dc dcResourceSet) (model.Manifest, error) {
+	m, err := s.dcServiceToManifest(svc, dc)
+	if err != nil {
+		return model.Manifest{}, err
+	}
+
+	iTargets, err := s.imgTargetsForDependencyIDs(svc.DependencyIDs, container.Registry{}) // Registry not relevant to DC
+	if err != nil {
+		return model.Manifest{}, errors.Wrapf(err, "getting image build info for %s", svc.Name)
+	}
+
+	for _, iTarg := range iTargets {
+		if !iTarg.OverrideCmd.Empty() {
+			return model.Manifest{}, fmt.Errorf("docker_build/custom_build.entrypoint not supported for Docker Compose resources")
+		}
+	}
+
+	return m.WithImageTargets(iTargets), nil
+}
+
 func badTypeErr(b *starlark.Builtin, ex interface{}, v starlark.Value) error {
 	return fmt.Errorf("%v expects a %T; got %T (%v)", b.Name(), ex, v, v)
 }
@@ -1487,11 +1977,20 @@ func (s *tiltfileState) triggerModeFn(thread *starlark.Thread, fn *starlark.Buil
 
 func (s *tiltfileState) setTeam(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var teamID string
-	err := s.unpackArgs(fn.Name(), args, kwargs, "team_id", &teamID)
+	var additionalTeamIDsVal starlark.Sequence
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"team_id", &teamID,
+		"additional_team_ids?", &additionalTeamIDsVal,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	additionalTeamIDs, err := value.SequenceToStringSlice(additionalTeamIDsVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: additional_team_ids", fn.Name())
+	}
+
 	if len(teamID) == 0 {
 		return nil, errors.New("team_id cannot be empty")
 	}
@@ -1502,6 +2001,15 @@ func (s *tiltfileState) setTeam(thread *starlark.Thread, fn *starlark.Builtin, a
 
 	s.teamID = teamID
 
+	for _, id := range additionalTeamIDs {
+		if len(id) == 0 {
+			return nil, errors.New("additional_team_ids cannot contain an empty team id")
+		}
+		if id != teamID {
+			s.additionalTeamIDs = sliceutils.AppendWithoutDupes(s.additionalTeamIDs, id)
+		}
+	}
+
 	return starlark.None, nil
 }
 
@@ -1512,7 +2020,13 @@ func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
 		mn := model.ManifestName(r.name)
 		tm, err := starlarkTriggerModeToModel(s.triggerModeForResource(r.triggerMode), r.autoInit)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error in resource %s options", mn)
+			m, err := s.degradeResource(model.Manifest{Name: mn}.WithDeployTarget(model.LocalTarget{Name: mn.TargetName()}),
+				errors.Wrapf(err, "error in resource %s options", mn))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, m)
+			continue
 		}
 
 		paths := append(r.deps, r.workdir)
@@ -1538,6 +2052,8 @@ func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
 			Name:                 mn,
 			TriggerMode:          tm,
 			ResourceDependencies: mds,
+			BuildPriority:        model.BuildPriority(r.priority),
+			Links:                r.links,
 		}.WithDeployTarget(lt)
 
 		result = append(result, m)
@@ -1546,7 +2062,46 @@ func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
 	return result, nil
 }
 
-func validateResourceDependencies(ms []model.Manifest) error {
+// A problem with one resource's config.
+type resourceConfigError struct {
+	name model.ManifestName
+	err  error
+}
+
+// Handles a problem with one resource's config.
+//
+// With load_settings(on_error='continue_on_error'), marks the manifest with
+// a config error, so that the rest of the resources can load. Otherwise,
+// returns the error, which fails the whole load.
+func (s *tiltfileState) degradeResource(m model.Manifest, err error) (model.Manifest, error) {
+	if !s.loadSettings.ContinueOnError() {
+		return model.Manifest{}, err
+	}
+	s.logger.Warnf("Resource %s has a config error, so Tilt won't update it: %v", m.Name, err)
+	return m.WithConfigError(err.Error()), nil
+}
+
+// Like degradeResource, for problems found by checking the manifests together.
+// Fails with the first error, unless we're continuing on errors.
+func (s *tiltfileState) degradeResources(ms []model.Manifest, errs []resourceConfigError) ([]model.Manifest, error) {
+	if len(errs) == 0 {
+		return ms, nil
+	}
+	if !s.loadSettings.ContinueOnError() {
+		return nil, errs[0].err
+	}
+
+	for _, e := range errs {
+		for i, m := range ms {
+			if m.Name == e.name && m.ConfigError == "" {
+				ms[i], _ = s.degradeResource(m, e.err)
+			}
+		}
+	}
+	return ms, nil
+}
+
+func validateResourceDependencies(ms []model.Manifest) []resourceConfigError {
 	// make sure that:
 	// 1. all deps exist
 	// 2. we have a DAG
@@ -1556,15 +2111,19 @@ func validateResourceDependencies(ms []model.Manifest) error {
 		knownResources[m.Name] = true
 	}
 
+	var errs []resourceConfigError
+
 	// construct the graph and make sure all edges are valid
 	edges := make(map[interface{}][]interface{})
 	for _, m := range ms {
 		for _, b := range m.ResourceDependencies {
 			if m.Name == b {
-				return fmt.Errorf("resource %s specified a dependency on itself", m.Name)
+				errs = append(errs, resourceConfigError{m.Name, fmt.Errorf("resource %s specified a dependency on itself", m.Name)})
+				break
 			}
 			if _, ok := knownResources[b]; !ok {
-				return fmt.Errorf("resource %s specified a dependency on unknown resource %s", m.Name, b)
+				errs = append(errs, resourceConfigError{m.Name, fmt.Errorf("resource %s specified a dependency on unknown resource %s", m.Name, b)})
+				break
 			}
 			edges[m.Name] = append(edges[m.Name], b)
 		}
@@ -1579,11 +2138,36 @@ func validateResourceDependencies(ms []model.Manifest) error {
 				nodes = append(nodes, string(g[len(g)-i-1].(model.ManifestName)))
 			}
 			nodes = append(nodes, string(g[len(g)-1].(model.ManifestName)))
-			return fmt.Errorf("cycle detected in resource dependency graph: %s", strings.Join(nodes, " -> "))
+			err := fmt.Errorf("cycle detected in resource dependency graph: %s", strings.Join(nodes, " -> "))
+			for _, n := range g {
+				errs = append(errs, resourceConfigError{n.(model.ManifestName), err})
+			}
 		}
 	}
 
-	return nil
+	return errs
+}
+
+// restart_resource() steps can only name resources that Tilt manages.
+func validateLiveUpdateRestartResources(ms []model.Manifest) []resourceConfigError {
+	knownResources := make(map[model.ManifestName]bool)
+	for _, m := range ms {
+		knownResources[m.Name] = true
+	}
+
+	var errs []resourceConfigError
+	for _, m := range ms {
+		for _, iTarget := range m.ImageTargets {
+			for _, r := range iTarget.LiveUpdateInfo().RestartResources() {
+				if r == m.Name {
+					errs = append(errs, resourceConfigError{m.Name, fmt.Errorf("resource %s has a live_update restart_resource() step for itself. Use restart_container() instead", m.Name)})
+				} else if !knownResources[r] {
+					errs = append(errs, resourceConfigError{m.Name, fmt.Errorf("resource %s has a live_update restart_resource() step for unknown resource %s", m.Name, r)})
+				}
+			}
+		}
+	}
+	return errs
 }
 
 var _ starkit.Extension = &tiltfileState{}
@@ -109,7 +109,7 @@ k8s_resource('foo', 'foo.yaml')
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
 func TestSimple(t *testing.T) {
@@ -128,7 +128,7 @@ k8s_yaml('foo.yaml')
 	m := f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 
 	iTarget := m.ImageTargetAt(0)
 
@@ -156,7 +156,7 @@ k8s_yaml('foo.yaml')
 	f.assertNextManifest("foo",
 		db(image("fooimage")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
 func TestExplicitDockerfileIsConfigFile(t *testing.T) {
@@ -169,7 +169,7 @@ docker_build('gcr.io/foo', 'foo', dockerfile='other/Dockerfile')
 k8s_yaml('foo.yaml')
 `)
 	f.load()
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
 }
 
 func TestExplicitDockerfileAsLocalPath(t *testing.T) {
@@ -183,7 +183,7 @@ docker_build('gcr.io/foo', 'foo', dockerfile=r.paths('other/Dockerfile'))
 k8s_yaml('foo.yaml')
 `)
 	f.load()
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
 }
 
 func TestExplicitDockerfileContents(t *testing.T) {
@@ -195,7 +195,7 @@ docker_build('gcr.io/foo', 'foo', dockerfile_contents='FROM alpine')
 k8s_yaml('foo.yaml')
 `)
 	f.load()
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "foo/.dockerignore")
 	f.assertNextManifest("foo", db(image("gcr.io/foo")))
 }
 
@@ -210,7 +210,7 @@ docker_build('gcr.io/foo', 'foo', dockerfile_contents=df)
 k8s_yaml('foo.yaml')
 `)
 	f.load()
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "other/Dockerfile", "foo/.dockerignore")
 	f.assertNextManifest("foo", db(image("gcr.io/foo")))
 }
 
@@ -359,6 +359,69 @@ func TestLocalArgvCmd(t *testing.T) {
 	assert.Contains(t, f.out.String(), `a"b`)
 }
 
+func TestLocalAsync(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+a = local_async('echo hello')
+b = local_async('cat foo.yaml', quiet=True)
+out = wait_all([a, b])
+if str(out[0]).strip() != 'hello':
+  fail('unexpected output: ' + str(out[0]))
+k8s_yaml(out[1])
+`)
+
+	f.load()
+
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	assert.Contains(t, f.out.String(), "local_async [1]: echo hello")
+	assert.Contains(t, f.out.String(), "local_async [2]: cat foo.yaml")
+	assert.Contains(t, f.out.String(), "[1] hello")
+	assert.NotContains(t, f.out.String(), "kind: Deployment")
+}
+
+func TestLocalAsyncWaitAllFailure(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+a = local_async('exit 1')
+b = local_async('echo ok')
+c = local_async('exit 2')
+wait_all([a, b, c])
+`)
+
+	f.loadErrString("local_async [1]", "local_async [3]")
+}
+
+func TestLocalAsyncUnwaitedFailure(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_async('exit 1')
+`)
+
+	f.loadErrString("never passed to wait_all()", "local_async [1]")
+}
+
+func TestWaitAllNotAHandle(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+wait_all(['echo hi'])
+`)
+
+	f.loadErrString("expected handles from local_async(), got string")
+}
+
 func TestReadFile(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -376,7 +439,7 @@ k8s_yaml(yaml)
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
 func TestKustomize(t *testing.T) {
@@ -396,7 +459,7 @@ k8s_resource("the-deployment", "foo")
 `)
 	f.load()
 	f.assertNextManifest("foo", deployment("the-deployment"), numEntities(2))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "kustomization.yaml", "service.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "kustomization.yaml", "service.yaml")
 }
 
 func TestKustomizeError(t *testing.T) {
@@ -424,7 +487,7 @@ k8s_resource("the-deployment", "foo")
 `)
 	f.load()
 	f.assertNextManifest("foo", deployment("the-deployment"), numEntities(2))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "Kustomization", "service.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "configMap.yaml", "deployment.yaml", "Kustomization", "service.yaml")
 }
 
 func TestDockerBuildTarget(t *testing.T) {
@@ -497,6 +560,165 @@ docker_build("gcr.io/foo", "foo", pull=True)
 	assert.True(t, m.ImageTargets[0].BuildDetails.(model.DockerBuild).PullParent)
 }
 
+func TestDockerBuildOutput(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+docker_build("gcr.io/foo", "foo")
+docker_build("gcr.io/bar", "bar", build_output='full')
+update_settings(build_output='progress')
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, model.BuildOutputProgress, foo.ImageTargets[0].BuildDetails.(model.DockerBuild).OutputVerbosity)
+	bar := f.assertNextManifest("bar")
+	assert.Equal(t, model.BuildOutputFull, bar.ImageTargets[0].BuildDetails.(model.DockerBuild).OutputVerbosity)
+}
+
+func TestDockerBuildOutputInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_output='loud')
+`)
+	f.loadErrString(`invalid build output "loud"`)
+}
+
+func TestDockerBuildOnCluster(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+docker_build("gcr.io/foo", "foo", build_on='cluster')
+docker_build("gcr.io/bar", "bar")
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.True(t, foo.ImageTargets[0].BuildDetails.(model.DockerBuild).BuildsOnCluster())
+	bar := f.assertNextManifest("bar")
+	assert.False(t, bar.ImageTargets[0].BuildDetails.(model.DockerBuild).BuildsOnCluster())
+}
+
+func TestDockerBuildOnInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_on='laptop')
+`)
+	f.loadErrString(`invalid build location "laptop"`)
+}
+
+func TestDockerBuildOnClusterWithSecret(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_on='cluster', secret='id=shibboleth')
+`)
+	f.loadErrString(`"cluster" doesn't support ssh or secret`)
+}
+
+func TestDockerBuildRegistryCache(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+docker_build("gcr.io/foo", "foo", registry_cache=True)
+docker_build("gcr.io/bar", "bar")
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.True(t, foo.ImageTargets[0].BuildDetails.(model.DockerBuild).RegistryCache)
+	bar := f.assertNextManifest("bar")
+	assert.False(t, bar.ImageTargets[0].BuildDetails.(model.DockerBuild).RegistryCache)
+}
+
+func TestDockerBuildOnClusterWithRegistryCache(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_on='cluster', registry_cache=True)
+`)
+	f.loadErrString(`"cluster" doesn't support registry_cache`)
+}
+
+func TestBuildContextWarningSize(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+docker_build("gcr.io/foo", "foo")
+docker_build("gcr.io/bar", "bar")
+update_settings(build_context_warning_mb=0)
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, int64(0), foo.ImageTargets[0].BuildDetails.(model.DockerBuild).ContextWarningSize)
+}
+
+func TestImageLayerAnalysis(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+docker_build("gcr.io/foo", "foo")
+docker_build("gcr.io/bar", "bar")
+update_settings(image_layer_analysis=True)
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.True(t, foo.ImageTargets[0].BuildDetails.(model.DockerBuild).AnalyzeLayers)
+	bar := f.assertNextManifest("bar")
+	assert.True(t, bar.ImageTargets[0].BuildDetails.(model.DockerBuild).AnalyzeLayers)
+}
+
+func TestImageLayerAnalysisNotBool(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+update_settings(image_layer_analysis='yes')
+`)
+	f.loadErrString(`update_settings: for parameter "image_layer_analysis": got starlark.String, want bool`)
+}
+
+func TestBuildContextWarningSizeDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo")
+`)
+	f.load()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, int64(model.DefaultBuildContextWarningSize),
+		foo.ImageTargets[0].BuildDetails.(model.DockerBuild).ContextWarningSize)
+}
+
 func TestDockerBuildCacheFrom(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -653,11 +875,19 @@ func TestPortForward(t *testing.T) {
 		newPortForwardErrorCase("value_string_garbage", "'garbage'", "not in the valid range"),
 		newPortForwardErrorCase("value_string_empty", "''", "not in the valid range"),
 		newPortForwardSuccessCase("value_both", "port_forward(8001, 443)", []model.PortForward{{LocalPort: 8001, ContainerPort: 443}}),
+		newPortForwardSuccessCase("value_service", "port_forward(8001, 443, service='foo')", []model.PortForward{{LocalPort: 8001, ContainerPort: 443, Service: "foo"}}),
 		newPortForwardSuccessCase("list", "[8000, port_forward(8001, 443)]", []model.PortForward{{LocalPort: 8000}, {LocalPort: 8001, ContainerPort: 443}}),
 		newPortForwardSuccessCase("list_string", "['8000', '8001:443']", []model.PortForward{{LocalPort: 8000}, {LocalPort: 8001, ContainerPort: 443}}),
 		newPortForwardErrorCase("value_host_bad", "'bad+host:10000:8000'", "not a valid hostname or IP address"),
 		newPortForwardSuccessCase("value_host_good_ip", "'0.0.0.0:10000:8000'", []model.PortForward{{LocalPort: 10000, ContainerPort: 8000, Host: "0.0.0.0"}}),
 		newPortForwardSuccessCase("value_host_good_domain", "'tilt.dev:10000:8000'", []model.PortForward{{LocalPort: 10000, ContainerPort: 8000, Host: "tilt.dev"}}),
+		newPortForwardSuccessCase("value_host_kwarg", "port_forward(8001, 443, host='0.0.0.0')", []model.PortForward{{LocalPort: 8001, ContainerPort: 443, Host: "0.0.0.0"}}),
+		newPortForwardErrorCase("value_host_kwarg_bad", "port_forward(8001, 443, host='bad+host')", "not a valid hostname or IP address"),
+		newPortForwardSuccessCase("value_udp", "port_forward(5353, 53, protocol='udp')", []model.PortForward{{LocalPort: 5353, ContainerPort: 53, Protocol: model.PortForwardUDP}}),
+		newPortForwardSuccessCase("value_string_udp", "'0.0.0.0:5353:53/udp'", []model.PortForward{{LocalPort: 5353, ContainerPort: 53, Host: "0.0.0.0", Protocol: model.PortForwardUDP}}),
+		newPortForwardSuccessCase("value_string_tcp", "'8000/tcp'", []model.PortForward{{LocalPort: 8000}}),
+		newPortForwardErrorCase("value_protocol_bad", "port_forward(8001, 443, protocol='sctp')", "protocol must be one of"),
+		newPortForwardErrorCase("value_udp_service", "port_forward(5353, 53, service='dns', protocol='udp')", "UDP port forwards to a service are not supported"),
 		portForwardCase{name: "default_web_host", expr: "8000", webHost: "0.0.0.0",
 			expected: []model.PortForward{{LocalPort: 8000, Host: "0.0.0.0"}}},
 		portForwardCase{name: "override_web_host", expr: "'tilt.dev:10000:8000'", webHost: "0.0.0.0",
@@ -710,7 +940,7 @@ docker_build('gcr.io/d', 'd')
 	f.assertNextManifest("c", db(image("gcr.io/c")), deployment("c"))
 	f.assertNextManifest("d", db(image("gcr.io/d")), deployment("d"))
 	f.assertNoMoreManifests() // should be no unresourced yaml remaining
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "all.yaml", "a/Dockerfile", "a/.dockerignore", "b/Dockerfile", "b/.dockerignore", "c/Dockerfile", "c/.dockerignore", "d/Dockerfile", "d/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "all.yaml", "a/Dockerfile", "a/.dockerignore", "b/Dockerfile", "b/.dockerignore", "c/Dockerfile", "c/.dockerignore", "d/Dockerfile", "d/.dockerignore")
 }
 
 func TestExpandUnresourced(t *testing.T) {
@@ -828,6 +1058,85 @@ k8s_yaml('all.yaml')`)
 	f.assertNextManifestUnresourced("someSecret")
 }
 
+const autoGroupYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: gcr.io/foo
+        envFrom:
+        - configMapRef:
+            name: foo-env
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo-env
+data:
+  FOO: bar
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+data:
+  BAR: baz
+`
+
+func TestK8sAutoGroupReferencedObjects(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("all.yaml", autoGroupYAML)
+	f.file("Tiltfile", `k8s_yaml('all.yaml')`)
+	f.load()
+
+	f.assertNextManifest("foo", deployment("foo"), k8sObject("foo-env", "ConfigMap"))
+	f.assertNextManifestUnresourced("unrelated")
+}
+
+func TestK8sAutoGroupDisabled(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("all.yaml", autoGroupYAML)
+	f.file("Tiltfile", `
+k8s_auto_group(False)
+k8s_yaml('all.yaml')
+`)
+	f.load()
+
+	f.assertNextManifest("foo", deployment("foo"))
+	f.assertNextManifestUnresourced("foo-env", "unrelated")
+}
+
+func TestK8sAutoGroupLeavesObjectsForK8sResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("all.yaml", autoGroupYAML)
+	f.file("Tiltfile", `
+k8s_yaml('all.yaml')
+k8s_resource(new_name='config', objects=['foo-env'])
+`)
+	f.load()
+
+	f.assertNextManifest("foo", deployment("foo"))
+	f.assertNextManifest("config", k8sObject("foo-env", "ConfigMap"))
+	f.assertNextManifestUnresourced("unrelated")
+}
+
 func TestK8sGroupedWhenAddedToResource(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -955,7 +1264,7 @@ k8s_yaml('bar.yaml')
 		db(image("gcr.io/foo")),
 		deployment("foo"))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml")
 }
 
 func TestLoadTypoManifest(t *testing.T) {
@@ -1258,7 +1567,7 @@ if True:
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 }
 
 func TestTopLevelForLoop(t *testing.T) {
@@ -1306,6 +1615,8 @@ k8s_yaml(yml)
 	f.assertConfigFiles(
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		"helm",
 	)
 }
@@ -1337,7 +1648,7 @@ k8s_yaml(yml)
 	expectedNames := []string{"rose-quartz-helloworld-chart:service"}
 	assert.ElementsMatch(t, expectedNames, names)
 
-	f.assertConfigFiles("./helm/", "./dev/helm/values-dev.yaml", ".tiltignore", "Tiltfile")
+	f.assertConfigFiles("./helm/", "./dev/helm/values-dev.yaml", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "Tiltfile")
 }
 
 func TestHelmNamespaceFlagDoesNotInsertNSEntityIfNSInChart(t *testing.T) {
@@ -1425,6 +1736,8 @@ k8s_yaml(yml)
 	f.assertConfigFiles(
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		"helm",
 	)
 }
@@ -1567,72 +1880,604 @@ data:
   foo: bar
 `)
 	f.file("Tiltfile", `
-k8s_yaml('config.yaml')
-k8s_resource(new_name='config', objects=['config'])
+k8s_yaml('config.yaml')
+k8s_resource(new_name='config', objects=['config'])
+`)
+
+	f.load("config")
+	f.assertNextManifest("config",
+		podReadiness(model.PodReadinessIgnore),
+	)
+}
+
+func TestPodReadinessOverrideDeployment(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', pod_readiness='ignore')
+`)
+
+	f.load("foo")
+	f.assertNextManifest("foo",
+		deployment("foo"),
+		podReadiness(model.PodReadinessIgnore),
+	)
+}
+
+func TestPodReadinessOverrideConfigMap(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("config.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  foo: "bar"
+`)
+	f.file("Tiltfile", `
+k8s_yaml('config.yaml')
+k8s_resource(new_name='config', objects=['config'], pod_readiness='wait')
+`)
+
+	f.load("config")
+	f.assertNextManifest("config",
+		podReadiness(model.PodReadinessWait),
+	)
+}
+
+func TestPodReadinessInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("config.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  foo: bar
+`)
+	f.file("Tiltfile", `
+k8s_yaml('config.yaml')
+k8s_resource(new_name='config', objects=['config'], pod_readiness='w')
+`)
+
+	f.loadErrString("Invalid value. Allowed: {ignore, wait}. Got: w")
+}
+
+func TestK8sResourceNamespaces(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.yaml("namespace.yaml", namespace("baz"))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(['foo.yaml', 'namespace.yaml'])
+k8s_resource('foo', objects=['baz:namespace'], namespaces=['tenant-a', 'tenant-b'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", podReadiness(model.PodReadinessWait))
+	kt := m.K8sTarget()
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, kt.Namespaces)
+	assert.ElementsMatch(t, []string{"foo:deployment:tenant-a", "foo:deployment:tenant-b", "baz:namespace"},
+		kt.DisplayNames)
+	f.assertNoMoreManifests()
+}
+
+func TestK8sResourceNamespacesInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', namespaces=['Tenant_A'])
+`)
+
+	f.loadErrString(`invalid namespace "Tenant_A"`)
+}
+
+func TestK8sResourceNamespacesDuplicate(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', namespaces=['tenant-a', 'tenant-a'])
+`)
+
+	f.loadErrString(`namespace "tenant-a" is listed more than once`)
+}
+
+func TestDevResourceProfile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.yaml("baz.yaml", deployment("baz", image("gcr.io/baz:stable")))
+	f.file("Tiltfile", `
+dev_resource_profile(scale=0.5)
+k8s_yaml(['foo.yaml', 'bar.yaml', 'baz.yaml'])
+k8s_resource('bar', scale_resources=0.25)
+k8s_resource('baz', scale_resources=0)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, model.DevResourceProfile{Scale: 0.5, RemoveAntiAffinity: true, LocalOnly: true},
+		m.K8sTarget().DevResourceProfile)
+
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.Equal(t, model.DevResourceProfile{Scale: 0.25, RemoveAntiAffinity: true, LocalOnly: true},
+		m.K8sTarget().DevResourceProfile)
+
+	m = f.assertNextManifest("baz", deployment("baz"))
+	assert.Equal(t, model.DevResourceProfile{StripResources: true, RemoveAntiAffinity: true, LocalOnly: true},
+		m.K8sTarget().DevResourceProfile)
+}
+
+func TestFloats(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+print(float('0.5') + 1 / 4)
+`)
+
+	f.load()
+	assert.Contains(t, f.out.String(), "0.75")
+}
+
+func TestScaleResourcesWithoutProfile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', scale_resources=0.25)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, model.DevResourceProfile{Scale: 0.25, LocalOnly: true},
+		m.K8sTarget().DevResourceProfile)
+
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.True(t, m.K8sTarget().DevResourceProfile.Empty())
+}
+
+func TestScaleResourcesNegative(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', scale_resources=-1)
+`)
+
+	f.loadErrString("scale_resources: must be >= 0")
+}
+
+func TestStatefulSetOptions(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', ordered_pod_management=True, delete_pvcs=True)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, m.K8sTarget().OrderedPodManagement)
+	assert.True(t, m.K8sTarget().DeletePVCs)
+
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.False(t, m.K8sTarget().OrderedPodManagement)
+	assert.False(t, m.K8sTarget().DeletePVCs)
+}
+
+func TestK8sResourcePauseAutoscaling(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', pause_autoscaling=True)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, m.K8sTarget().PauseAutoscaling)
+
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.False(t, m.K8sTarget().PauseAutoscaling)
+}
+
+func TestPodReplacementOptions(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', surge=True, drain_period_secs=5, termination_grace_period_secs=3)
+`)
+
+	f.load()
+	grace := 3 * time.Second
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, model.PodReplacement{
+		Surge:                  true,
+		DrainPeriod:            5 * time.Second,
+		TerminationGracePeriod: &grace,
+	}, m.K8sTarget().PodReplacement)
+
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.True(t, m.K8sTarget().PodReplacement.Empty())
+}
+
+func TestPodReplacementNegativeDrain(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', drain_period_secs=-1)
+`)
+
+	f.loadErrString("drain_period_secs: must be >= 0")
+}
+
+func TestPodReplacementFractionalGracePeriod(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', termination_grace_period_secs=2.5)
+`)
+
+	f.loadErrString("termination_grace_period_secs: expected a whole number of seconds, got 2.5")
+}
+
+func TestK8sResourceTransform(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+def add_toleration(objs):
+  for o in objs:
+    if o['kind'] == 'Deployment':
+      o['spec']['template']['spec']['tolerations'] = [{'key': 'dev', 'operator': 'Exists'}]
+  return objs
+
+k8s_yaml('foo.yaml')
+k8s_resource('foo', transform=add_toleration)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	kTarget := m.K8sTarget()
+	require.NotNil(t, kTarget.Transform)
+
+	// The YAML stays as written. The transform runs at deploy time.
+	assert.NotContains(t, kTarget.YAML, "tolerations")
+
+	entities, err := k8s.ParseYAMLFromString(kTarget.YAML)
+	require.NoError(t, err)
+	transformed, err := k8s.ApplyTransform(f.ctx, kTarget.Transform, entities)
+	require.NoError(t, err)
+	yaml, err := k8s.SerializeSpecYAML(transformed)
+	require.NoError(t, err)
+	assert.Contains(t, yaml, "key: dev")
+}
+
+func TestK8sResourceTransformUnchangedOnReload(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+def add_label(objs):
+  for o in objs:
+    o['metadata'].setdefault('labels', {})['dev'] = 'true'
+  return objs
+
+k8s_yaml('foo.yaml')
+k8s_resource('foo', transform=add_label)
+`)
+
+	f.load()
+	first := f.assertNextManifest("foo").K8sTarget().Transform
+
+	f.load()
+	second := f.assertNextManifest("foo").K8sTarget().Transform
+	assert.True(t, first.EqualsK8sTransform(second))
+}
+
+func TestK8sResourceTransformRename(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+def rename(objs):
+  for o in objs:
+    o['metadata']['name'] = 'bar'
+  return objs
+
+k8s_yaml('foo.yaml')
+k8s_resource('foo', transform=rename)
+`)
+
+	f.loadErrString("may change objects, but not rename them")
+}
+
+func TestK8sResourceTransformWrongArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+def transform(objs, extra):
+  return objs
+
+k8s_yaml('foo.yaml')
+k8s_resource('foo', transform=transform)
+`)
+
+	f.loadErrString("transform must take 1 argument")
+}
+
+func TestK8sYAMLObjectSources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	// The Service selects the CronJob's pods, so they're in one resource.
+	f.file("app.yaml", `# The app
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  selector:
+    app: app-cleanup
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: app-cleanup
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          labels:
+            app: app-cleanup
+        spec:
+          containers:
+          - name: cleanup
+            image: busybox
+          restartPolicy: OnFailure
+`)
+	f.file("Tiltfile", `
+k8s_yaml('app.yaml')
+`)
+
+	f.load()
+	assert.Equal(t, map[string]string{
+		"Service/app":         f.JoinPath("app.yaml") + ":2",
+		"CronJob/app-cleanup": f.JoinPath("app.yaml") + ":10",
+	}, f.assertNextManifest("app-cleanup").K8sTarget().ObjectSources)
+}
+
+func TestK8sResourceSeed(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("postgres.yaml", deployment("postgres", image("postgres:13")))
+	f.file("seed.sql", "CREATE TABLE IF NOT EXISTS users (id int);")
+	f.file("Tiltfile", `
+k8s_yaml('postgres.yaml')
+k8s_resource('postgres', seed=seed_sql('./seed.sql'))
+`)
+
+	f.load()
+	seed := f.assertNextManifest("postgres").K8sTarget().Seed
+	assert.Equal(t, model.K8sSeed{
+		Path: f.JoinPath("seed.sql"),
+		Cmd:  model.DefaultSeedSQLCmd,
+		On:   model.SeedOnFirstDeploy,
+	}, seed)
+}
+
+func TestK8sResourceSeedOptions(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("mysql.yaml", deployment("mysql", image("mysql:8")))
+	f.file("seed.sql", "")
+	f.file("Tiltfile", `
+k8s_yaml('mysql.yaml')
+k8s_resource('mysql', seed=seed_sql('seed.sql', on='every-deploy', cmd=['mysql', '-uroot', 'app'], container='db'))
+`)
+
+	f.load()
+	seed := f.assertNextManifest("mysql").K8sTarget().Seed
+	assert.Equal(t, model.SeedOnEveryDeploy, seed.On)
+	assert.Equal(t, []string{"mysql", "-uroot", "app"}, seed.Cmd.Argv)
+	assert.Equal(t, "db", seed.Container)
+}
+
+func TestK8sResourceSeedBadTrigger(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("postgres.yaml", deployment("postgres", image("postgres:13")))
+	f.file("Tiltfile", `
+k8s_yaml('postgres.yaml')
+k8s_resource('postgres', seed=seed_sql('seed.sql', on='always'))
+`)
+
+	f.loadErrString(`seed_sql: on must be one of "first-deploy" or "every-deploy" (got: "always")`)
+}
+
+func TestK8sResourceSeedNotASeed(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("postgres.yaml", deployment("postgres", image("postgres:13")))
+	f.file("Tiltfile", `
+k8s_yaml('postgres.yaml')
+k8s_resource('postgres', seed='seed.sql')
+`)
+
+	f.loadErrString(`k8s_resource "postgres": seed: must be a seed_sql(); is a string`)
+}
+
+func TestK8sYAMLManageFalse(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.dockerfile("Dockerfile")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.yaml("gitops.yaml", deployment("bar", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.')
+k8s_yaml('foo.yaml')
+k8s_yaml('gitops.yaml', manage=False)
+`)
+
+	f.load()
+	f.assertNextManifest("foo", db(image("gcr.io/foo")), deployment("foo"))
+
+	// The observed deployment gets its own resource, and doesn't
+	// depend on the image, because we never deploy it.
+	m := f.assertNextManifest("bar")
+	assert.Empty(t, m.ImageTargets)
+
+	kTarget := m.K8sTarget()
+	assert.Equal(t, "", kTarget.YAML)
+	if assert.Len(t, kTarget.ObservedObjectRefs, 1) {
+		assert.Equal(t, "bar", kTarget.ObservedObjectRefs[0].Name)
+	}
+	assert.Equal(t, kTarget.ObservedObjectRefs, kTarget.ObjectRefs)
+	assert.Empty(t, kTarget.ManagedDisplayNames())
+	if assert.Len(t, kTarget.ExtraPodSelectors, 1) {
+		assert.Equal(t, "app=bar", kTarget.ExtraPodSelectors[0].String())
+	}
+	f.assertNoMoreManifests()
+}
+
+func TestK8sYAMLManageFalseGroupedWithManaged(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.yaml("foo.yaml", deployment("foo"))
+	f.yaml("svc.yaml", service("foo"))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_yaml('svc.yaml', manage=False)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	kTarget := m.K8sTarget()
+	assert.NotContains(t, kTarget.YAML, "kind: Service")
+	assert.Len(t, kTarget.ObjectRefs, 2)
+	assert.Equal(t, []string{"foo:deployment"}, kTarget.ManagedDisplayNames())
+}
+
+func TestDevResourceProfileCalledTwice(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+dev_resource_profile(scale=0.5)
+dev_resource_profile(scale=0.25)
 `)
 
-	f.load("config")
-	f.assertNextManifest("config",
-		podReadiness(model.PodReadinessIgnore),
-	)
+	f.loadErrString("dev_resource_profile can only be called once")
 }
 
-func TestPodReadinessOverrideDeployment(t *testing.T) {
+func TestK8sManagedLabels(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
 	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
 	f.file("Tiltfile", `
+k8s_managed_labels(prefix='example.com',
+                   labels={'team': 'payments', 'example.com/owner': 'alice'},
+                   annotations={'example.com/contact': 'alice@example.com'})
 k8s_yaml('foo.yaml')
-k8s_resource('foo', pod_readiness='ignore')
 `)
 
-	f.load("foo")
-	f.assertNextManifest("foo",
-		deployment("foo"),
-		podReadiness(model.PodReadinessIgnore),
-	)
+	f.load()
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, model.K8sObjectLabels{
+		Prefix: "example.com",
+		Labels: []model.LabelPair{
+			{Key: "example.com/owner", Value: "alice"},
+			{Key: "team", Value: "payments"},
+		},
+		Annotations: []model.LabelPair{{Key: "example.com/contact", Value: "alice@example.com"}},
+	}, m.K8sTarget().ObjectLabels)
 }
 
-func TestPodReadinessOverrideConfigMap(t *testing.T) {
+func TestK8sManagedLabelsCalledTwice(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
-	f.file("config.yaml", `apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: config
-data:
-  foo: "bar"
-`)
 	f.file("Tiltfile", `
-k8s_yaml('config.yaml')
-k8s_resource(new_name='config', objects=['config'], pod_readiness='wait')
+k8s_managed_labels(labels={'team': 'payments'})
+k8s_managed_labels(labels={'team': 'search'})
 `)
 
-	f.load("config")
-	f.assertNextManifest("config",
-		podReadiness(model.PodReadinessWait),
-	)
+	f.loadErrString("k8s_managed_labels can only be called once")
 }
 
-func TestPodReadinessInvalid(t *testing.T) {
+func TestK8sManagedLabelsInvalidPrefix(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
-	f.file("config.yaml", `apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: config
-data:
-  foo: bar
+	f.file("Tiltfile", `
+k8s_managed_labels(prefix='Not A Domain')
 `)
+
+	f.loadErrString(`k8s_managed_labels: prefix "Not A Domain"`)
+}
+
+func TestK8sManagedLabelsInvalidLabelValue(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
 	f.file("Tiltfile", `
-k8s_yaml('config.yaml')
-k8s_resource(new_name='config', objects=['config'], pod_readiness='w')
+k8s_managed_labels(labels={'owner': 'alice@example.com'})
 `)
 
-	f.loadErrString("Invalid value. Allowed: {ignore, wait}. Got: w")
+	f.loadErrString(`k8s_managed_labels: label "owner"`)
 }
 
 func TestDockerBuildMatchingTag(t *testing.T) {
@@ -1666,6 +2511,42 @@ docker_build('gcr.io/foo:stable', '.')
 	f.loadAssertWarnings(unmatchedImageNoConfigsWarning)
 }
 
+func TestDockerBuildButK8sMissingAlert(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.gitInit("")
+	f.file("Dockerfile", "FROM golang:1.10")
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo:stable', '.')
+`)
+
+	f.loadAllowWarnings()
+	if assert.Equal(t, 1, len(f.loadResult.Alerts)) {
+		alert := f.loadResult.Alerts[0]
+		assert.Equal(t, model.AlertSourceImageInjection, alert.Source)
+		assert.Equal(t, unmatchedImageNoConfigsWarning, alert.Message)
+	}
+}
+
+func TestDeprecatedAPIAlert(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("blorg.yaml", testyaml.BlorgBackendYAML)
+	f.file("Tiltfile", `
+k8s_yaml('blorg.yaml')
+`)
+
+	f.load()
+	if assert.Equal(t, 1, len(f.loadResult.Alerts)) {
+		alert := f.loadResult.Alerts[0]
+		assert.Equal(t, model.AlertSourceDeprecatedAPI, alert.Source)
+		assert.Equal(t, "deprecated-api:Deployment/devel-nick-blorg-be", alert.ID)
+		assert.Contains(t, alert.Message, "Use apps/v1 instead")
+	}
+}
+
 func TestDockerBuildButK8sMissingTag(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2038,7 +2919,7 @@ func TestDir(t *testing.T) {
 
 	f.load("foo", "bar")
 	f.assertNumManifests(2)
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "config/foo.yaml", "config/bar.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "config/foo.yaml", "config/bar.yaml")
 }
 
 func TestDirRecursive(t *testing.T) {
@@ -2055,7 +2936,7 @@ for f in files:
 `)
 
 	f.load()
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo", "foo/bar", "foo/baz/qux")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo", "foo/bar", "foo/baz/qux")
 }
 
 func TestCallCounts(t *testing.T) {
@@ -2225,7 +3106,7 @@ custom_build(
 
 	f.load("foo")
 	f.assertNumManifests(1)
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "foo/.dockerignore")
 	m := f.assertNextManifest("foo",
 		cb(
 			image("gcr.io/foo"),
@@ -2254,7 +3135,7 @@ hfb = custom_build(
 
 	f.load("foo")
 	f.assertNumManifests(1)
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "foo/.dockerignore")
 	f.assertNextManifest("foo",
 		cb(
 			image("gcr.io/foo"),
@@ -2291,6 +3172,48 @@ custom_build(
 	assert.True(t, m.ImageTargets[0].CustomBuildInfo().SkipsPush())
 }
 
+func TestCustomBuildSandbox(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	tiltfile := `
+k8s_yaml('foo.yaml')
+custom_build(
+  'gcr.io/foo',
+  'go build ./foo && docker build -t $EXPECTED_REF foo',
+  ['foo'],
+  sandbox='golang:1.21',
+)`
+
+	f.setupFoo()
+	f.file("Tiltfile", tiltfile)
+
+	f.load("foo")
+	m := f.assertNextManifest("foo",
+		cb(
+			image("gcr.io/foo"),
+			cmd("go build ./foo && docker build -t $EXPECTED_REF foo"),
+		),
+		deployment("foo"))
+	cbInfo := m.ImageTargets[0].CustomBuildInfo()
+	assert.Equal(t, "golang:1.21", cbInfo.Sandbox)
+	assert.Equal(t, []string{"sh", "-c", "go build ./foo && docker build -t $EXPECTED_REF foo"}, cbInfo.Command.Argv)
+}
+
+func TestCustomBuildSandboxInvalidImage(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	tiltfile := `
+k8s_yaml('foo.yaml')
+custom_build('gcr.io/foo', 'go build ./foo', ['foo'], sandbox='Not An Image')`
+
+	f.setupFoo()
+	f.file("Tiltfile", tiltfile)
+
+	f.loadErrString("Argument 'sandbox': can't parse image \"Not An Image\"")
+}
+
 func TestImageObjectJSONPath(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2340,6 +3263,54 @@ docker_build('tilt.dev/frontend', '.')
 		m.ImageTargets[0].Refs.LocalRef().String())
 }
 
+func TestK8sKindPodTemplateJSONPath(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.file("rollout.yaml", `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: rollouts-demo
+spec:
+  template:
+    metadata:
+      labels:
+        app: rollouts-demo
+    spec:
+      containers:
+      - name: rollouts-demo
+        image: tilt.dev/frontend`)
+	f.dockerfile("Dockerfile")
+	f.file("Tiltfile", `
+k8s_yaml('rollout.yaml')
+k8s_kind('Rollout', api_version='argoproj.io/v1alpha1', pod_template_json_path='{.spec.template}')
+docker_build('tilt.dev/frontend', '.')
+`)
+
+	f.load()
+	m := f.assertNextManifest("rollouts-demo",
+		podReadiness(model.PodReadinessWait))
+	assert.Equal(t, "tilt.dev/frontend",
+		m.ImageTargets[0].Refs.LocalRef().String())
+
+	locators := k8s.PodTemplateLocators(k8s.ToImageLocators(m.K8sTarget().ImageLocators))
+	require.Equal(t, 1, len(locators))
+	assert.True(t, locators[0].MatchesRef(v1.ObjectReference{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Rollout",
+		Name:       "rollouts-demo",
+	}))
+}
+
+func TestK8sKindPodTemplateJSONPathInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.file("Tiltfile", `
+k8s_kind('Rollout', pod_template_json_path='{.spec.template')
+`)
+
+	f.loadErrString("k8s_kind: pod_template_json_path")
+}
+
 func TestExtraImageLocationOneImage(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2799,7 +3770,60 @@ default_registry('bar.com')
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo").withLocalRef("bar.com/gcr.io_foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+}
+
+func TestDefaultRegistryPullSecret(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.yaml("bar.yaml", deployment("bar", image("busybox")))
+	f.file("Tiltfile", `
+default_registry('gcr.io/my-project', pull_secret='tilt-registry')
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, model.ImagePullSecret{Name: "tilt-registry", Host: "gcr.io/my-project"},
+		m.K8sTarget().ImagePullSecret)
+
+	// bar doesn't use any images that Tilt pushes.
+	m = f.assertNextManifest("bar", deployment("bar"))
+	assert.True(t, m.K8sTarget().ImagePullSecret.Empty())
+}
+
+func TestDefaultRegistryPullSecretWithLocalRegistry(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.kCli.Registry = container.MustNewRegistry("localhost:32000")
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+default_registry('bar.com', pull_secret='tilt-registry')
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, m.K8sTarget().ImagePullSecret.Empty())
+}
+
+func TestDefaultRegistryPullSecretInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+default_registry('bar.com', pull_secret='Tilt_Registry')
+`)
+
+	f.loadErrString(`default_registry: invalid pull_secret "Tilt_Registry"`)
 }
 
 func TestLocalRegistry(t *testing.T) {
@@ -2822,6 +3846,82 @@ k8s_yaml('foo.yaml')
 		deployment("foo"))
 }
 
+func TestLocalRegistryBuiltin(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.k8sEnv = k8s.EnvKIND6
+	f.registryAdmin.registry = container.MustNewRegistry("localhost:5001")
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+default_registry('bar.com')  # local_registry should override this
+local_registry(port=5001)
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo").withLocalRef("localhost:5001/gcr.io_foo")),
+		deployment("foo"))
+	assert.Equal(t, []model.LocalRegistrySpec{{Name: "tilt-registry", Port: 5001}}, f.registryAdmin.calls)
+}
+
+func TestLocalRegistryBuiltinUnsupportedCluster(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+default_registry('bar.com')
+local_registry()
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load()
+
+	// The fake admin doesn't know how to set up this cluster, so we fall back to default_registry.
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo").withLocalRef("bar.com/gcr.io_foo")),
+		deployment("foo"))
+}
+
+func TestLocalRegistryBuiltinTwice(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_registry()
+local_registry(name='other')
+`)
+
+	f.loadErrString("local_registry: can only be called once")
+}
+
+func TestLocalRegistryBuiltinInvalidPort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_registry(port=0)
+`)
+
+	f.loadErrString("local_registry: port must be between 1 and 65535 (got: 0)")
+}
+
+type fakeLocalRegistryAdmin struct {
+	registry container.Registry
+	calls    []model.LocalRegistrySpec
+}
+
+func (a *fakeLocalRegistryAdmin) EnsureLocalRegistry(ctx context.Context, spec model.LocalRegistrySpec, env k8s.Env, kubeContext k8s.KubeContext) (container.Registry, error) {
+	a.calls = append(a.calls, spec)
+	return a.registry, nil
+}
+
 func TestLocalRegistryDockerCompose(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -2875,7 +3975,7 @@ default_registry('example.com')
 	f.assertNextManifest("baz",
 		db(image("gcr.io/foo:baz").withLocalRef("example.com/gcr.io_foo")),
 		deployment("baz"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml", "baz/Dockerfile", "baz/.dockerignore", "baz.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "bar/Dockerfile", "bar/.dockerignore", "bar.yaml", "baz/Dockerfile", "baz/.dockerignore", "baz.yaml")
 }
 
 func TestDefaultRegistrySingleName(t *testing.T) {
@@ -2937,7 +4037,7 @@ k8s_yaml(str(result) + '.yaml')
 		db(image("gcr.io/foo")),
 		deployment("foo"))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "this_file_does_not_exist", "foo.yaml", "foo/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "this_file_does_not_exist", "foo.yaml", "foo/Dockerfile", "foo/.dockerignore")
 }
 
 func TestWatchFile(t *testing.T) {
@@ -2958,7 +4058,7 @@ k8s_yaml('foo.yaml')
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml", "hello")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml", "hello")
 }
 
 func TestK8sResourceAssemblyVersionAfterYAML(t *testing.T) {
@@ -3044,7 +4144,7 @@ k8s_yaml('foo.yaml')
 		db(image("gcr.io/foo")),
 		deployment("foo"))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "foo/Dockerfile", "foo/.dockerignore")
 }
 
 func TestAssemblyVersion2TwoWorkloadsSameImage(t *testing.T) {
@@ -3069,7 +4169,7 @@ k8s_yaml(['foo.yaml', 'bar.yaml'])
 		db(image("gcr.io/foo")),
 		deployment("bar"))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "bar.yaml", "foo/Dockerfile", "foo/.dockerignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo.yaml", "bar.yaml", "foo/Dockerfile", "foo/.dockerignore")
 }
 
 func TestK8sResourceNoMatch(t *testing.T) {
@@ -3228,7 +4328,7 @@ workload_to_resource_function(wtrf)
 k8s_resource('hello-foo', port_forwards=8000)
 `)
 
-	f.loadErrString("'foo:deployment:default:apps'", "invalid return value", "wanted: string. got: starlark.Int", "Tiltfile:5:1", workloadToResourceFunctionN)
+	f.loadErrString("'foo:deployment:default:apps'", "invalid return value", "wanted: string or None. got: starlark.Int", "Tiltfile:5:1", workloadToResourceFunctionN)
 }
 
 func TestWorkloadToResourceFunctionTakesNoArgs(t *testing.T) {
@@ -3263,10 +4363,52 @@ k8s_yaml('foo.yaml')
 def wtrf(a, b):
 	return "hello"
 workload_to_resource_function(wtrf)
-k8s_resource('hello-foo', port_forwards=8000)
+k8s_resource('hello-foo', port_forwards=8000)
+`)
+
+	f.loadErrString("workload_to_resource_function arg must take 1 argument. wtrf takes 2")
+}
+
+func TestWorkloadToResourceFunctionNoneUsesDefaultName(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFooAndBar()
+
+	f.file("Tiltfile", `
+
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+def wtrf(id):
+	if id.name == 'foo':
+		return 'hello-foo'
+	return None
+workload_to_resource_function(wtrf)
+`)
+
+	f.load()
+	f.assertNextManifest("hello-foo", db(image("gcr.io/foo")))
+	f.assertNextManifest("bar", db(image("gcr.io/bar")))
+}
+
+func TestWorkloadToResourceFunctionLabels(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+def wtrf(id):
+	return id.labels['app'] + '-app'
+workload_to_resource_function(wtrf)
 `)
 
-	f.loadErrString("workload_to_resource_function arg must take 1 argument. wtrf takes 2")
+	f.load()
+	f.assertNextManifest("foo-app", db(image("gcr.io/foo")))
 }
 
 func TestMultipleLiveUpdatesOnManifest(t *testing.T) {
@@ -3523,6 +4665,8 @@ k8s_yaml(yml)
 	f.assertConfigFiles(
 		"Tiltfile",
 		".tiltignore",
+		".tilt-overlay.json",
+		"Tiltfile.local",
 		"helm",
 	)
 }
@@ -3574,7 +4718,7 @@ docker_build('gcr.io/foo', 'foo')
 	f.assertNextManifest("foo",
 		db(image("gcr.io/foo")),
 		deployment("foo"))
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local", "foo/Dockerfile", "foo/.dockerignore", "foo.yaml")
 
 }
 
@@ -4163,6 +5307,25 @@ set_team('jets')
 	f.loadErrString("team_id set multiple times", "'sharks'", "'jets'")
 }
 
+func TestSetTeamAdditionalTeamIDs(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "set_team('sharks', additional_team_ids=['jets', 'sharks', 'jets', 'cobras'])")
+	f.load()
+
+	assert.Equal(t, "sharks", f.loadResult.TeamID)
+	assert.Equal(t, []string{"jets", "cobras"}, f.loadResult.AdditionalTeamIDs)
+}
+
+func TestSetTeamAdditionalTeamIDsEmpty(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", "set_team('sharks', additional_team_ids=[''])")
+	f.loadErrString("additional_team_ids cannot contain an empty team id")
+}
+
 func TestK8SContextAcceptance(t *testing.T) {
 	for _, test := range []struct {
 		name                    string
@@ -4252,7 +5415,7 @@ local_resource("test", "echo hi", deps=["foo/bar", "foo/a.txt"])
 	lt := m.LocalTarget()
 	f.assertRepos([]string{f.Path()}, lt.LocalRepos())
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local")
 }
 
 func TestLocalResourceOnlyServeCmd(t *testing.T) {
@@ -4268,7 +5431,7 @@ local_resource("test", serve_cmd="sleep 1000")
 	f.assertNumManifests(1)
 	f.assertNextManifest("test", localTarget(serveCmd("sleep 1000")))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local")
 }
 
 func TestLocalResourceUpdateAndServeCmd(t *testing.T) {
@@ -4284,7 +5447,7 @@ local_resource("test", cmd="echo hi", serve_cmd="sleep 1000")
 	f.assertNumManifests(1)
 	f.assertNextManifest("test", localTarget(updateCmd("echo hi"), serveCmd("sleep 1000")))
 
-	f.assertConfigFiles("Tiltfile", ".tiltignore")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local")
 }
 
 func TestLocalResourceNeitherUpdateOrServeCmd(t *testing.T) {
@@ -4298,6 +5461,46 @@ local_resource("test")
 	f.loadErrString("local_resource must have a cmd and/or a serve_cmd, but both were empty")
 }
 
+func TestLocalResourceLinks(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("test", serve_cmd="sleep 1000",
+               links=["http://localhost:8000", link("http://localhost:9000/swagger", "API docs")])
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("test", localTarget(serveCmd("sleep 1000")))
+	assert.Equal(t, []model.Link{
+		{URL: "http://localhost:8000"},
+		{URL: "http://localhost:9000/swagger", Name: "API docs"},
+	}, m.Links)
+}
+
+func TestLinkInvalidURL(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+link("localhost:9000/swagger", "API docs")
+`)
+
+	f.loadErrString(`link: url "localhost:9000/swagger" must start with http:// or https://`)
+}
+
+func TestLocalResourceLinksWrongType(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("test", serve_cmd="sleep 1000", links=[8000])
+`)
+
+	f.loadErrString(`local_resource "test": links: links must be a string, a link, or a list of them; found a int`)
+}
+
 func TestLocalResourceUpdateCmdArray(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -4631,6 +5834,54 @@ local_resource('baz', 'echo baz', resource_deps=['bar'])
 	f.loadErrString("cycle detected in resource dependency graph", "bar -> foo", "foo -> baz", "baz -> bar")
 }
 
+func TestContinueOnErrorMissingDependency(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+load_settings(on_error='continue_on_error')
+local_resource('foo', 'echo foo')
+local_resource('bar', 'echo bar', resource_deps=['nope'])
+`)
+
+	f.loadAllowWarnings()
+	foo := f.assertNextManifest("foo")
+	assert.Equal(t, "", foo.ConfigError)
+	bar := f.assertNextManifest("bar")
+	assert.Contains(t, bar.ConfigError, "resource bar specified a dependency on unknown resource nope")
+	f.assertWarnings("Resource bar has a config error, so Tilt won't update it: " + bar.ConfigError)
+}
+
+func TestContinueOnErrorCycle(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+load_settings(on_error='continue_on_error')
+local_resource('foo', 'echo foo', resource_deps=['bar'])
+local_resource('bar', 'echo bar', resource_deps=['foo'])
+local_resource('baz', 'echo baz')
+`)
+
+	f.loadAllowWarnings()
+	assert.Contains(t, f.assertNextManifest("foo").ConfigError, "cycle detected in resource dependency graph")
+	assert.Contains(t, f.assertNextManifest("bar").ConfigError, "cycle detected in resource dependency graph")
+	assert.Equal(t, "", f.assertNextManifest("baz").ConfigError)
+}
+
+func TestFailFastMissingDependency(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+load_settings(on_error='fail_fast')
+local_resource('foo', 'echo foo')
+local_resource('bar', 'echo bar', resource_deps=['nope'])
+`)
+
+	f.loadErrString("resource bar specified a dependency on unknown resource nope")
+}
+
 func TestDependsOnPulledInOnPartialLoad(t *testing.T) {
 	for _, tc := range []struct {
 		name            string
@@ -4699,6 +5950,249 @@ local_resource("b", ["echo", "hi"])
 	assert.False(t, b.LocalTarget().AllowParallel)
 }
 
+func TestLocalResourcePriority(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", ["echo", "hi"], priority=PRIORITY_HIGH)
+local_resource("b", ["echo", "hi"], priority=PRIORITY_LOW)
+local_resource("c", ["echo", "hi"])
+`)
+
+	f.load()
+	a := f.assertNextManifest("a")
+	assert.Equal(t, model.BuildPriorityHigh, a.BuildPriority)
+	b := f.assertNextManifest("b")
+	assert.Equal(t, model.BuildPriorityLow, b.BuildPriority)
+	c := f.assertNextManifest("c")
+	assert.Equal(t, model.BuildPriorityNormal, c.BuildPriority)
+}
+
+func TestLocalResourcePriorityInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", ["echo", "hi"], priority="high")
+`)
+
+	f.loadErrString("priority")
+}
+
+func TestK8sResourcePriority(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', priority=PRIORITY_HIGH)
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, model.BuildPriorityHigh, m.BuildPriority)
+}
+
+func TestEnvRecorded(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	os.Setenv("TILT_TEST_DEBUG", "1")
+	os.Setenv("TILT_TEST_API_TOKEN", "supersecret")
+	defer os.Unsetenv("TILT_TEST_DEBUG")
+	defer os.Unsetenv("TILT_TEST_API_TOKEN")
+
+	f.file("Tiltfile", `
+local_resource("a", ["echo", os.getenv("TILT_TEST_DEBUG"), os.getenv("TILT_TEST_API_TOKEN")])
+`)
+
+	f.load()
+	assert.Equal(t, "1", f.loadResult.Env["TILT_TEST_DEBUG"].Value)
+
+	token := f.loadResult.Env["TILT_TEST_API_TOKEN"]
+	assert.True(t, token.Denied)
+	assert.Equal(t, "", token.Value)
+	assert.Equal(t, "[redacted secret env:TILT_TEST_API_TOKEN]", string(f.loadResult.Secrets.Scrub([]byte("supersecret"))))
+}
+
+func TestRequireToolExplainsLaterFailure(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+require_tool('tilt-test-missing-tool', install_url='https://example.com/install')
+local('tilt-test-missing-tool')
+`)
+
+	f.loadErrString(
+		"tilt-test-missing-tool: not found on PATH",
+		"Install: https://example.com/install",
+		"The Tiltfile failed, probably because of the missing tools")
+}
+
+func TestOverlayLocalResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file(".tilt-overlay.json", `{"local_resources": [{"name": "gen", "cmd": "make gen", "deps": ["./proto"], "resource_deps": ["a"]}]}`)
+
+	f.load()
+	f.assertNumManifests(2)
+	f.assertNextManifest("a", localTarget(updateCmd("echo a")))
+	m := f.assertNextManifest("gen", localTarget(updateCmd("make gen"), deps("proto")))
+	assert.Equal(t, []model.ManifestName{"a"}, m.ResourceDependencies)
+	assert.Equal(t, f.Path(), m.LocalTarget().Workdir)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local")
+}
+
+func TestOverlayLocalResourceConflict(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("gen", "echo tiltfile")
+`)
+	f.file(".tilt-overlay.json", `{"local_resources": [{"name": "gen", "cmd": "make gen"}]}`)
+
+	f.loadAssertWarnings("Skipping resource gen from .tilt-overlay.json: the Tiltfile already defines a resource with that name")
+	f.assertNumManifests(1)
+	f.assertNextManifest("gen", localTarget(updateCmd("echo tiltfile")))
+}
+
+func TestOverlayInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file(".tilt-overlay.json", `{`)
+
+	f.loadErrString("parsing")
+}
+
+func TestLocalTiltfileDisableResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+local_resource("b", "echo b", resource_deps=["a"])
+`)
+	f.file("Tiltfile.local", `
+disable_resource("a")
+`)
+
+	f.load()
+	f.assertNumManifests(1)
+	m := f.assertNextManifest("b", localTarget(updateCmd("echo b")))
+	assert.Empty(t, m.ResourceDependencies)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", ".tilt-overlay.json", "Tiltfile.local")
+}
+
+func TestLocalTiltfileAddsResources(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file("Tiltfile.local", `
+local_resource("mine", "echo mine")
+`)
+
+	f.load()
+	f.assertNumManifests(2)
+	f.assertNextManifest("a", localTarget(updateCmd("echo a")))
+	f.assertNextManifest("mine", localTarget(updateCmd("echo mine")))
+}
+
+func TestLocalTiltfileUnknownResource(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file("Tiltfile.local", `
+disable_resource("nope")
+`)
+
+	f.loadErrString(`disable_resource: no resource named "nope"`)
+}
+
+func TestLocalTiltfilePortForwardNotK8s(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file("Tiltfile.local", `
+add_port_forward("a", 8000)
+`)
+
+	f.loadErrString(`add_port_forward: resource "a" isn't a Kubernetes resource`)
+}
+
+func TestLocalTiltfileHelpersOnlyInLocalTiltfile(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+disable_resource("a")
+`)
+
+	f.loadErrString("disable_resource can only be called from Tiltfile.local")
+}
+
+func TestLocalTiltfileOverrideDockerBuild(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', build_args={'A': 'main', 'B': 'main'})
+k8s_yaml('foo.yaml')
+k8s_resource('foo', port_forwards=8000)
+`)
+	f.file("Tiltfile.local", `
+override_docker_build('gcr.io/foo', build_args={'B': 'mine'}, target='debug')
+add_port_forward('foo', ['9000:90'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t,
+		model.DockerBuildArgs{"A": "main", "B": "mine"},
+		m.ImageTargets[0].DockerBuildInfo().BuildArgs)
+	assert.Equal(t, model.DockerBuildTarget("debug"), m.ImageTargets[0].DockerBuildInfo().TargetStage)
+	assert.Equal(t,
+		[]model.PortForward{{LocalPort: 8000}, {LocalPort: 9000, ContainerPort: 90}},
+		m.K8sTarget().PortForwards)
+}
+
+func TestLocalTiltfileOverrideUnknownImage(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.file("Tiltfile", `
+local_resource("a", "echo a")
+`)
+	f.file("Tiltfile.local", `
+override_docker_build('gcr.io/nope', build_args={'B': 'mine'})
+`)
+
+	f.loadErrString(`override_docker_build: no docker_build() for image "gcr.io/nope"`)
+}
+
 func TestMaxParallelUpdates(t *testing.T) {
 	for _, tc := range []struct {
 		name                       string
@@ -4801,6 +6295,46 @@ func TestK8sUpsertTimeout(t *testing.T) {
 	}
 }
 
+func TestBuildHistoryLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		tiltfile            string
+		expectErrorContains string
+		expectedLimit       int
+	}{
+		{
+			name:          "default value if func not called",
+			tiltfile:      "print('hello world')",
+			expectedLimit: model.DefaultBuildHistoryLimit,
+		},
+		{
+			name:          "set build history limit",
+			tiltfile:      "update_settings(build_history_limit=100)",
+			expectedLimit: 100,
+		},
+		{
+			name:                "must be positive int",
+			tiltfile:            "update_settings(build_history_limit=0)",
+			expectErrorContains: "build history limit must be >= 1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			defer f.TearDown()
+
+			f.file("Tiltfile", tc.tiltfile)
+
+			if tc.expectErrorContains != "" {
+				f.loadErrString(tc.expectErrorContains)
+				return
+			}
+
+			f.load()
+			assert.Equal(t, tc.expectedLimit, f.loadResult.UpdateSettings.BuildHistoryLimit())
+		})
+	}
+}
+
 func TestUpdateSettingsCalledTwice(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
@@ -5488,6 +7022,8 @@ type fixture struct {
 	k8sEnv     k8s.Env
 	webHost    model.WebHost
 
+	registryAdmin *fakeLocalRegistryAdmin
+
 	ta *tiltanalytics.TiltAnalytics
 	an *analytics.MemoryAnalytics
 
@@ -5507,7 +7043,9 @@ func (f *fixture) newTiltfileLoader() TiltfileLoader {
 	k8sContextExt := k8scontext.NewExtension(f.k8sContext, f.k8sEnv)
 	versionExt := version.NewExtension(model.TiltBuild{Version: "0.5.0"})
 	configExt := config.NewExtension("up")
-	return ProvideTiltfileLoader(f.ta, f.kCli, k8sContextExt, versionExt, configExt, dcc, f.webHost, features, f.k8sEnv)
+	tfl := ProvideTiltfileLoader(f.ta, f.kCli, k8sContextExt, versionExt, configExt, dcc, f.webHost, features, f.k8sEnv).(tiltfileLoader)
+	tfl.registryAdmin = f.registryAdmin
+	return tfl
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -5527,6 +7065,7 @@ func newFixture(t *testing.T) *fixture {
 		kCli:           kCli,
 		k8sContext:     "fake-context",
 		k8sEnv:         k8s.EnvDockerDesktop,
+		registryAdmin:  &fakeLocalRegistryAdmin{},
 	}
 
 	// Collect the warnings
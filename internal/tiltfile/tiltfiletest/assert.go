@@ -0,0 +1,116 @@
+package tiltfiletest
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+// Prefix a failure with the user's message, if they gave one.
+func assertionError(msg string, format string, a ...interface{}) error {
+	text := fmt.Sprintf(format, a...)
+	if msg != "" {
+		text = fmt.Sprintf("%s: %s", msg, text)
+	}
+	return fmt.Errorf("%s", text)
+}
+
+func assertEquals(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var expected, actual starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"expected", &expected,
+		"actual", &actual,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	eq, err := starlark.Equal(expected, actual)
+	if err != nil {
+		return nil, err
+	}
+	if !eq {
+		return nil, assertionError(msg, "expected %s, got %s", expected, actual)
+	}
+	return starlark.None, nil
+}
+
+func assertTrue(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return assertTruth(thread, fn, args, kwargs, true)
+}
+
+func assertFalse(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return assertTruth(thread, fn, args, kwargs, false)
+}
+
+func assertTruth(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, expected bool) (starlark.Value, error) {
+	var v starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"value", &v,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if bool(v.Truth()) != expected {
+		return nil, assertionError(msg, "expected %s to be %t", v, expected)
+	}
+	return starlark.None, nil
+}
+
+func assertContains(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var container, item starlark.Value
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"container", &container,
+		"item", &item,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same semantics as starlark's `item in container`.
+	found, err := starlark.Binary(syntax.IN, item, container)
+	if err != nil {
+		return nil, err
+	}
+	if !found.Truth() {
+		return nil, assertionError(msg, "expected %s to contain %s", container, item)
+	}
+	return starlark.None, nil
+}
+
+// Calls fn, and passes if it fails. Returns the error message, so that
+// tests can make further assertions about it.
+func assertFails(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var f starlark.Callable
+	var contains string
+	var msg string
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"fn", &f,
+		"contains?", &contains,
+		"msg?", &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, callErr := starlark.Call(thread, f, nil, nil)
+	if callErr == nil {
+		return nil, assertionError(msg, "expected %s to fail", f)
+	}
+
+	errMsg := callErr.Error()
+	if evalErr, ok := callErr.(*starlark.EvalError); ok {
+		errMsg = evalErr.Msg
+	}
+	if !strings.Contains(errMsg, contains) {
+		return nil, assertionError(msg, "expected error containing %q, got %q", contains, errMsg)
+	}
+	return starlark.String(errMsg), nil
+}
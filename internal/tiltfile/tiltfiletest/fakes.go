@@ -0,0 +1,92 @@
+package tiltfiletest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// Tools that need a cluster or a daemon, and so must always be faked.
+var hermeticTools = map[string]bool{
+	"docker":         true,
+	"docker-compose": true,
+	"helm":           true,
+	"kubectl":        true,
+	"kustomize":      true,
+}
+
+type fakeCommand struct {
+	command  string
+	stdout   string
+	exitCode int
+}
+
+// A fake matches a command exactly, or any command that starts with it
+// followed by more args.
+func (f fakeCommand) matches(command string) bool {
+	return command == f.command || strings.HasPrefix(command, f.command+" ")
+}
+
+var _ tiltfile.CommandFaker = &fileRun{}
+
+func (fr *fileRun) fakeLocal(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var command value.StringOrStringList
+	var stdout string
+	var exitCode int
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"command", &command,
+		"stdout?", &stdout,
+		"exit_code?", &exitCode)
+	if err != nil {
+		return nil, err
+	}
+
+	c := strings.Join(command.Values, " ")
+	if c == "" {
+		return nil, fmt.Errorf("%s: command cannot be empty", fn.Name())
+	}
+
+	// Later fakes take precedence, so that a test can override
+	// a fake from the top level of the file.
+	fr.fakes = append([]fakeCommand{{command: c, stdout: stdout, exitCode: exitCode}}, fr.fakes...)
+	return starlark.None, nil
+}
+
+func (fr *fileRun) FakeCommand(argv []string, dir string) (string, bool, error) {
+	command := displayCommand(argv)
+	for _, f := range fr.fakes {
+		if !f.matches(command) {
+			continue
+		}
+		fmt.Fprintf(fr.out, "fake_local: %s\n", command)
+		if f.exitCode != 0 {
+			return "", true, fmt.Errorf("exit status %d", f.exitCode)
+		}
+		return f.stdout, true, nil
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) > 0 && hermeticTools[filepath.Base(fields[0])] {
+		return "", true, fmt.Errorf("%q is not faked. Tiltfile tests can't call %s. Add fake_local(%q, stdout=...)",
+			command, fields[0], command)
+	}
+	return "", false, nil
+}
+
+// The command as the user wrote it in the Tiltfile, without the
+// shell wrapper that local() adds.
+func displayCommand(argv []string) string {
+	if len(argv) == 3 && argv[0] == "sh" && argv[1] == "-c" {
+		return argv[2]
+	}
+	if len(argv) == 4 && argv[0] == "cmd" && argv[1] == "/S" && argv[2] == "/C" {
+		return argv[3]
+	}
+	return strings.Join(argv, " ")
+}
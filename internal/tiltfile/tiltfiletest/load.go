@@ -0,0 +1,141 @@
+package tiltfiletest
+
+import (
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const defaultK8sContext = "docker-desktop"
+
+// Loads a Tiltfile the way `tilt up` would, and returns a struct with
+// the resources it produced, so that tests can make assertions about them.
+//
+// The Tiltfile never talks to a cluster: k8s_context() and the env
+// are derived from the k8s_context argument.
+func (fr *fileRun) loadTiltfile(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	path := tiltfile.FileName
+	var tfArgs value.StringOrStringList
+	kubeContext := defaultK8sContext
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"path?", &path,
+		"args?", &tfArgs,
+		"k8s_context?", &kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []starlark.Value
+	l := logger.NewFuncLogger(false, logger.InfoLvl, func(level logger.Level, fields logger.Fields, b []byte) error {
+		fr.out.Write(b)
+		if level == logger.WarnLvl {
+			warnings = append(warnings, starlark.String(strings.TrimSpace(string(b))))
+		}
+		return nil
+	})
+	ctx := logger.WithLogger(fr.ctx, l)
+
+	env := k8s.ProvideEnv(ctx, k8s.APIConfigOrError{Config: &api.Config{
+		CurrentContext: kubeContext,
+		Contexts: map[string]*api.Context{
+			kubeContext: {Cluster: kubeContext},
+		},
+	}})
+	tfl := tiltfile.NewHermeticTiltfileLoader(fr.runner.analytics, k8s.KubeContext(kubeContext), env, fr.runner.build, fr)
+	tlr := tfl.Load(ctx, starkit.AbsPath(thread, path), model.NewUserConfigState(tfArgs.Values))
+
+	var loadErr starlark.Value = starlark.None
+	if tlr.Error != nil {
+		loadErr = starlark.String(tlr.Error.Error())
+	}
+
+	resources := starlark.NewDict(len(tlr.Manifests))
+	for _, m := range tlr.Manifests {
+		err := resources.SetKey(starlark.String(m.Name), resourceStruct(m))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("tiltfile"), starlark.StringDict{
+		"error":     loadErr,
+		"warnings":  starlark.NewList(warnings),
+		"resources": resources,
+	}), nil
+}
+
+func resourceStruct(m model.Manifest) *starlarkstruct.Struct {
+	var deps []starlark.Value
+	for _, d := range m.ResourceDependencies {
+		deps = append(deps, starlark.String(d))
+	}
+
+	var images []starlark.Value
+	for _, iTarget := range m.ImageTargets {
+		images = append(images, starlark.String(iTarget.Refs.ConfigurationRef.String()))
+	}
+
+	var objects []starlark.Value
+	var portForwards []starlark.Value
+	if m.IsK8s() {
+		kTarget := m.K8sTarget()
+		for _, n := range kTarget.DisplayNames {
+			objects = append(objects, starlark.String(n))
+		}
+		for _, pf := range kTarget.PortForwards {
+			portForwards = append(portForwards, starlarkstruct.FromStringDict(starlark.String("port_forward"), starlark.StringDict{
+				"local_port":     starlark.MakeInt(pf.LocalPort),
+				"container_port": starlark.MakeInt(pf.ContainerPort),
+				"host":           starlark.String(pf.Host),
+			}))
+		}
+	}
+
+	cmd, serveCmd := "", ""
+	if m.IsLocal() {
+		lTarget := m.LocalTarget()
+		cmd = displayCommand(lTarget.UpdateCmd.Argv)
+		serveCmd = displayCommand(lTarget.ServeCmd.Argv)
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("resource"), starlark.StringDict{
+		"name":          starlark.String(m.Name),
+		"type":          starlark.String(resourceType(m)),
+		"trigger_mode":  starlark.String(triggerModeString(m.TriggerMode)),
+		"auto_init":     starlark.Bool(m.TriggerMode.AutoInitial()),
+		"resource_deps": starlark.NewList(deps),
+		"images":        starlark.NewList(images),
+		"k8s_objects":   starlark.NewList(objects),
+		"port_forwards": starlark.NewList(portForwards),
+		"cmd":           starlark.String(cmd),
+		"serve_cmd":     starlark.String(serveCmd),
+	})
+}
+
+func resourceType(m model.Manifest) string {
+	switch {
+	case m.IsK8s():
+		return "k8s"
+	case m.IsDC():
+		return "docker_compose"
+	case m.IsLocal():
+		return "local"
+	}
+	return ""
+}
+
+func triggerModeString(tm model.TriggerMode) string {
+	if tm.AutoOnChange() {
+		return "auto"
+	}
+	return "manual"
+}
@@ -0,0 +1,173 @@
+// Package tiltfiletest runs Tiltfile unit tests.
+//
+// A Tiltfile_test is a starlark file that defines test_* functions.
+// Each test can load_tiltfile() with different args and k8s contexts,
+// then make assertions about the resources it produced. Commands that talk
+// to a cluster or a docker daemon must be faked with fake_local(), so that
+// tests don't depend on the machine they run on.
+package tiltfiletest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const FileName = "Tiltfile_test"
+
+const testPrefix = "test_"
+
+type TestResult struct {
+	Name     string
+	Duration time.Duration
+
+	// nil if the test passed.
+	Err error
+
+	// Everything the test printed, including the logs of any Tiltfiles it loaded.
+	Output string
+}
+
+func (r TestResult) Passed() bool {
+	return r.Err == nil
+}
+
+type Runner struct {
+	analytics *analytics.TiltAnalytics
+	build     model.TiltBuild
+
+	// If set, only run tests whose names match.
+	filter *regexp.Regexp
+}
+
+func NewRunner(a *analytics.TiltAnalytics, build model.TiltBuild, filter *regexp.Regexp) Runner {
+	return Runner{
+		analytics: a,
+		build:     build,
+		filter:    filter,
+	}
+}
+
+// RunFile executes a test file, then calls each of its test functions
+// in alphabetical order.
+//
+// Returns an error only if the file itself couldn't be executed.
+// Test failures are reported in the results.
+func (r Runner) RunFile(ctx context.Context, path string) ([]TestResult, error) {
+	fr := &fileRun{
+		runner: r,
+		ctx:    ctx,
+		out:    &bytes.Buffer{},
+	}
+	_, err := starkit.ExecFile(path, starlarkstruct.NewExtension(), fr)
+	if err != nil {
+		return nil, starkit.UnpackBacktrace(err)
+	}
+	return fr.results, nil
+}
+
+// The state of a single test file, used as its starkit extension.
+//
+// Extensions usually keep their state on the thread, but the test runner
+// owns the thread, so it's simpler to keep it here.
+type fileRun struct {
+	runner Runner
+	ctx    context.Context
+
+	// Output of whatever is currently running.
+	out *bytes.Buffer
+
+	// Fakes registered so far. Fakes registered at the top level of the
+	// file apply to every test; fakes registered in a test only apply to it.
+	fakes []fakeCommand
+
+	results []TestResult
+}
+
+var _ starkit.OnFinishExtension = &fileRun{}
+
+func (fr *fileRun) OnStart(env *starkit.Environment) error {
+	env.SetContext(fr.ctx)
+	env.SetPrint(func(t *starlark.Thread, msg string) {
+		fmt.Fprintln(fr.out, msg)
+	})
+
+	for _, b := range []struct {
+		name string
+		f    starkit.Function
+	}{
+		{"assert.equals", assertEquals},
+		{"assert.true", assertTrue},
+		{"assert.false", assertFalse},
+		{"assert.contains", assertContains},
+		{"assert.fails", assertFails},
+		{"fake_local", fr.fakeLocal},
+		{"load_tiltfile", fr.loadTiltfile},
+	} {
+		err := env.AddBuiltin(b.name, b.f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fr *fileRun) OnFinish(t *starlark.Thread, globals starlark.StringDict) error {
+	// Anything printed at the top level of the file goes straight to the user.
+	if fr.out.Len() > 0 {
+		logger.Get(fr.ctx).Infof("%s", strings.TrimRight(fr.out.String(), "\n"))
+	}
+
+	var names []string
+	for name, v := range globals {
+		if !strings.HasPrefix(name, testPrefix) {
+			continue
+		}
+		if _, ok := v.(starlark.Callable); !ok {
+			continue
+		}
+		if fr.runner.filter != nil && !fr.runner.filter.MatchString(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fr.results = append(fr.results, fr.runTest(t, name, globals[name].(starlark.Callable)))
+	}
+	return nil
+}
+
+func (fr *fileRun) runTest(t *starlark.Thread, name string, fn starlark.Callable) TestResult {
+	fakes := fr.fakes
+	fr.fakes = append([]fakeCommand{}, fakes...)
+	fr.out = &bytes.Buffer{}
+	defer func() {
+		fr.fakes = fakes
+	}()
+
+	start := time.Now()
+	_, err := starlark.Call(t, fn, nil, nil)
+	if err != nil {
+		err = starkit.UnpackBacktrace(err)
+	}
+	return TestResult{
+		Name:     name,
+		Duration: time.Since(start),
+		Err:      err,
+		Output:   fr.out.String(),
+	}
+}
@@ -0,0 +1,190 @@
+package tiltfiletest
+
+import (
+	"context"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPassAndFail(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile(FileName, `
+def test_b_fails():
+  assert.equals(1, 2, msg="numbers")
+
+def test_a_passes():
+  assert.equals("x", "x")
+  assert.true([1])
+  assert.false("")
+  assert.contains(["foo", "bar"], "bar")
+
+def helper():
+  fail("not a test")
+`)
+
+	results := f.run()
+	require.Len(t, results, 2)
+	assert.Equal(t, "test_a_passes", results[0].Name)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "test_b_fails", results[1].Name)
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), "numbers: expected 1, got 2")
+}
+
+func TestFilter(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile(FileName, `
+def test_foo():
+  pass
+
+def test_bar():
+  pass
+`)
+
+	f.filter = regexp.MustCompile("bar")
+	results := f.run()
+	require.Len(t, results, 1)
+	assert.Equal(t, "test_bar", results[0].Name)
+}
+
+func TestAssertFails(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile(FileName, `
+def test_fails():
+  msg = assert.fails(lambda: fail("oh no"), contains="oh no")
+  assert.equals("fail: oh no", msg)
+
+def test_does_not_fail():
+  assert.fails(lambda: None)
+
+def test_wrong_message():
+  assert.fails(lambda: fail("oh no"), contains="oh yes")
+`)
+
+	results := f.run()
+	require.Len(t, results, 3)
+	assert.Contains(t, results[0].Err.Error(), "to fail")
+	assert.NoError(t, results[1].Err)
+	assert.Contains(t, results[2].Err.Error(), `expected error containing "oh yes"`)
+}
+
+func TestLoadTiltfileWithFakeLocal(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("Tiltfile", `
+config.define_bool("prod")
+cfg = config.parse()
+ctx = str(local("kubectl config current-context")).strip()
+if cfg.get("prod", False):
+  local_resource("deploy", "./deploy.sh " + ctx, trigger_mode=TRIGGER_MODE_MANUAL)
+else:
+  local_resource("dev", serve_cmd="./dev.sh")
+`)
+	f.WriteFile(FileName, `
+fake_local("kubectl config current-context", stdout="staging\n")
+
+def test_dev():
+  tf = load_tiltfile()
+  assert.equals(None, tf.error)
+  assert.equals(["dev"], tf.resources.keys())
+  assert.equals("./dev.sh", tf.resources["dev"].serve_cmd)
+  assert.equals("auto", tf.resources["dev"].trigger_mode)
+
+def test_prod():
+  tf = load_tiltfile(args=["--prod"])
+  res = tf.resources["deploy"]
+  assert.equals("local", res.type)
+  assert.equals("./deploy.sh staging", res.cmd)
+  assert.equals("manual", res.trigger_mode)
+
+def test_override_fake():
+  fake_local("kubectl config current-context", stdout="prod")
+  tf = load_tiltfile(args=["--prod"])
+  assert.equals("./deploy.sh prod", tf.resources["deploy"].cmd)
+
+def test_failing_command():
+  fake_local("kubectl", exit_code=1)
+  tf = load_tiltfile()
+  assert.contains(tf.error, "exit status 1")
+`)
+
+	results := f.run()
+	require.Len(t, results, 4)
+	for _, r := range results {
+		assert.NoError(t, r.Err, "%s\n%s", r.Name, r.Output)
+	}
+}
+
+func TestUnfakedCommandFails(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("Tiltfile", `
+local("docker info")
+`)
+	f.WriteFile(FileName, `
+def test_load():
+  tf = load_tiltfile()
+  assert.contains(tf.error, 'Add fake_local("docker info", stdout=...)')
+`)
+
+	results := f.run()
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+}
+
+func TestFileError(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile(FileName, `
+fail("broken")
+`)
+
+	_, err := f.runner().RunFile(f.ctx, f.JoinPath(FileName))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
+
+type fixture struct {
+	*tempdir.TempDirFixture
+	t      *testing.T
+	ctx    context.Context
+	filter *regexp.Regexp
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, ioutil.Discard))
+	return &fixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		t:              t,
+		ctx:            ctx,
+	}
+}
+
+func (f *fixture) runner() Runner {
+	_, ta := analytics.NewMemoryTiltAnalyticsForTest(analytics.DefaultFakeOpter())
+	return NewRunner(ta, model.TiltBuild{Version: "0.10.13"}, f.filter)
+}
+
+func (f *fixture) run() []TestResult {
+	results, err := f.runner().RunFile(f.ctx, f.JoinPath(FileName))
+	require.NoError(f.t, err)
+	return results
+}
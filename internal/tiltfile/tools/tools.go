@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// How long to wait for a tool to print its version.
+const versionTimeout = 10 * time.Second
+
+// Most tools print their version with --version. These don't.
+var defaultVersionArgs = map[string][]string{
+	"go":        {"version"},
+	"helm":      {"version", "--short"},
+	"kind":      {"version"},
+	"kubectl":   {"version", "--client"},
+	"kustomize": {"version"},
+	"minikube":  {"version", "--short"},
+	"skaffold":  {"version"},
+}
+
+var versionRe = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// A command-line tool that the Tiltfile needs.
+type Tool struct {
+	Name       string
+	Constraint string
+	InstallURL string
+
+	// Where we found the tool, and what version it printed.
+	Path    string
+	Version string
+
+	// Why the tool doesn't work for this Tiltfile. Empty if it does.
+	Problem string
+}
+
+// The tools declared with require_tool().
+type State struct {
+	Tools []Tool
+}
+
+func (s State) Missing() []Tool {
+	var result []Tool
+	for _, t := range s.Tools {
+		if t.Problem != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// One error that lists every missing tool, so that the user can
+// install them all at once.
+func (s State) Error() error {
+	missing := s.Missing()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	if len(missing) == 1 {
+		sb.WriteString("The Tiltfile requires a tool that isn't available:\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("The Tiltfile requires %d tools that aren't available:\n", len(missing)))
+	}
+	for _, t := range missing {
+		sb.WriteString(fmt.Sprintf("  - %s: %s\n", t.Name, t.Problem))
+		if t.InstallURL != "" {
+			sb.WriteString(fmt.Sprintf("    Install: %s\n", t.InstallURL))
+		}
+	}
+	sb.WriteString("Install them, then save the Tiltfile to try again.")
+	return fmt.Errorf("%s", sb.String())
+}
+
+// Implements the require_tool() builtin, which checks that a tool is on the
+// PATH (and new enough) when the Tiltfile loads, instead of letting the first
+// local() that uses it fail with "command not found".
+type Extension struct{}
+
+func NewExtension() Extension {
+	return Extension{}
+}
+
+func (Extension) NewState() interface{} {
+	return State{}
+}
+
+func (Extension) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("require_tool", requireTool)
+}
+
+// Report all the missing tools together, after the Tiltfile declared them all.
+func (Extension) OnFinish(t *starlark.Thread, globals starlark.StringDict) error {
+	model, err := starkit.ModelFromThread(t)
+	if err != nil {
+		return err
+	}
+	state, err := GetState(model)
+	if err != nil {
+		return err
+	}
+	return state.Error()
+}
+
+func requireTool(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var tool Tool
+	var versionArgs value.StringOrStringList
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &tool.Name,
+		"version?", &tool.Constraint,
+		"install_url?", &tool.InstallURL,
+		"version_args?", &versionArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if tool.Name == "" {
+		return nil, fmt.Errorf("%s: name must not be empty", fn.Name())
+	}
+
+	var constraint semver.Range
+	if tool.Constraint != "" {
+		constraint, err = parseConstraint(tool.Constraint)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid version %q: %v", fn.Name(), tool.Constraint, err)
+		}
+	}
+
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+
+	tool.Path, err = exec.LookPath(tool.Name)
+	if err != nil {
+		tool.Problem = "not found on PATH"
+	} else if constraint != nil {
+		vArgs := versionArgs.Values
+		if len(vArgs) == 0 {
+			vArgs = versionArgsFor(tool.Name)
+		}
+		tool.Version, tool.Problem = checkVersion(ctx, tool.Path, vArgs, constraint, tool.Constraint)
+	}
+
+	err = starkit.SetState(thread, func(state State) State {
+		state.Tools = append(state.Tools, tool)
+		return state
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tool.Problem != "" {
+		return starlark.None, nil
+	}
+	return starlark.String(tool.Path), nil
+}
+
+func versionArgsFor(name string) []string {
+	if args, ok := defaultVersionArgs[name]; ok {
+		return args
+	}
+	return []string{"--version"}
+}
+
+// Returns the version the tool printed, and the problem with it (if any).
+func checkVersion(ctx context.Context, path string, args []string, constraint semver.Range, constraintStr string) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Sprintf("couldn't get its version with `%s %s`: %v",
+			path, strings.Join(args, " "), err)
+	}
+
+	version, ok := parseVersion(string(out))
+	if !ok {
+		return "", fmt.Sprintf("couldn't find a version in the output of `%s %s`",
+			path, strings.Join(args, " "))
+	}
+
+	if !constraint(version) {
+		return version.String(), fmt.Sprintf("found version %s at %s, but the Tiltfile requires %s",
+			version, path, constraintStr)
+	}
+	return version.String(), ""
+}
+
+// Finds the first version number in a tool's output (e.g., "v3.8.1+g5cb9af4"),
+// ignoring any pre-release or build suffix.
+func parseVersion(out string) (semver.Version, bool) {
+	m := versionRe.FindStringSubmatch(out)
+	if m == nil {
+		return semver.Version{}, false
+	}
+	patch := m[3]
+	if patch == "" {
+		patch = "0"
+	}
+	v, err := semver.Parse(fmt.Sprintf("%s.%s.%s", m[1], m[2], patch))
+	if err != nil {
+		return semver.Version{}, false
+	}
+	return v, true
+}
+
+var constraintPartRe = regexp.MustCompile(`^([<>=!]*)v?(\d+(?:\.\d+){0,2})$`)
+
+// Accepts constraints like ">=3.8" or ">=1.20 <2", and pads
+// the versions to three parts, as the semver library expects.
+func parseConstraint(s string) (semver.Range, error) {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		m := constraintPartRe.FindStringSubmatch(f)
+		if m == nil {
+			continue
+		}
+		version := m[2]
+		for strings.Count(version, ".") < 2 {
+			version += ".0"
+		}
+		fields[i] = m[1] + version
+	}
+	return semver.ParseRange(strings.Join(fields, " "))
+}
+
+var _ starkit.StatefulExtension = Extension{}
+var _ starkit.OnFinishExtension = Extension{}
+
+func MustState(model starkit.Model) State {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (State, error) {
+	var state State
+	err := m.Load(&state)
+	return state, err
+}
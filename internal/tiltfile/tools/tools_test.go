@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func TestToolFound(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.tool("helm", "v3.8.1+g5cb9af4")
+	f.File("Tiltfile", `
+print(require_tool('helm', version='>=3.8', install_url='https://helm.sh/docs/intro/install/'))
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	tools := MustState(result).Tools
+	require.Equal(t, 1, len(tools))
+	assert.Equal(t, "3.8.1", tools[0].Version)
+	assert.Equal(t, "", tools[0].Problem)
+	assert.Contains(t, f.PrintOutput(), filepath.Join(f.binDir, "helm"))
+}
+
+func TestToolNoVersionCheck(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.tool("jq", "garbage")
+	f.File("Tiltfile", `
+require_tool('jq')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+}
+
+func TestToolsMissingReportedTogether(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.tool("kind", "kind v0.9.0 go1.15.2 darwin/amd64")
+	f.File("Tiltfile", `
+require_tool('tilt-test-missing-tool', install_url='https://example.com/install')
+require_tool('kind', version='>=0.11')
+print('still running')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires 2 tools that aren't available")
+	assert.Contains(t, err.Error(), "tilt-test-missing-tool: not found on PATH")
+	assert.Contains(t, err.Error(), "Install: https://example.com/install")
+	assert.Contains(t, err.Error(), "found version 0.9.0")
+	assert.Contains(t, err.Error(), "requires >=0.11")
+
+	// The whole Tiltfile runs first, so that we can report every missing tool.
+	assert.Contains(t, f.PrintOutput(), "still running")
+}
+
+func TestToolVersionArgs(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.tool("mytool", "1.2")
+	f.File("Tiltfile", `
+require_tool('mytool', version='<1.2', version_args=['info'])
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found version 1.2.0")
+}
+
+func TestToolInvalidConstraint(t *testing.T) {
+	f := newFixture(t)
+	defer f.tearDown()
+
+	f.File("Tiltfile", `
+require_tool('helm', version='>=banana')
+`)
+
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid version ">=banana"`)
+}
+
+func TestParseConstraint(t *testing.T) {
+	r, err := parseConstraint(">=1.20 <2")
+	require.NoError(t, err)
+
+	v, ok := parseVersion("Client Version: v1.21.3")
+	require.True(t, ok)
+	assert.True(t, r(v))
+
+	v, ok = parseVersion("2.0")
+	require.True(t, ok)
+	assert.False(t, r(v))
+}
+
+type fixture struct {
+	*starkit.Fixture
+	binDir  string
+	oldPath string
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := starkit.NewFixture(t, NewExtension())
+	binDir := f.JoinPath("bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+
+	oldPath := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", binDir+string(os.PathListSeparator)+oldPath))
+	return &fixture{Fixture: f, binDir: binDir, oldPath: oldPath}
+}
+
+// Puts a fake tool on the PATH that prints the given version.
+func (f *fixture) tool(name string, version string) {
+	script := fmt.Sprintf("#!/bin/sh\necho '%s'\n", version)
+	err := ioutil.WriteFile(filepath.Join(f.binDir, name), []byte(script), 0755)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (f *fixture) tearDown() {
+	_ = os.Setenv("PATH", f.oldPath)
+}
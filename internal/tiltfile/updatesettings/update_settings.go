@@ -28,10 +28,17 @@ func (e Extension) OnStart(env *starkit.Environment) error {
 }
 
 func (e *Extension) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var maxParallelUpdates, k8sUpsertTimeoutSecs starlark.Value
+	var maxParallelUpdates, k8sUpsertTimeoutSecs, buildContextWarningMB, buildHistoryLimit starlark.Value
+	var buildOutput string
+	var imageLayerAnalysis, discoverLinks starlark.Value
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 		"max_parallel_updates?", &maxParallelUpdates,
-		"k8s_upsert_timeout_secs?", &k8sUpsertTimeoutSecs); err != nil {
+		"k8s_upsert_timeout_secs?", &k8sUpsertTimeoutSecs,
+		"build_output?", &buildOutput,
+		"build_context_warning_mb?", &buildContextWarningMB,
+		"build_history_limit?", &buildHistoryLimit,
+		"image_layer_analysis?", &imageLayerAnalysis,
+		"discover_links?", &discoverLinks); err != nil {
 		return nil, err
 	}
 
@@ -53,13 +60,62 @@ func (e *Extension) updateSettings(thread *starlark.Thread, fn *starlark.Builtin
 			k8sUpsertTimeoutSecs)
 	}
 
+	bcwm, bcwmPassed, err := valueToInt(buildContextWarningMB)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"build_context_warning_mb\"")
+	}
+	if bcwmPassed && bcwm < 0 {
+		return nil, fmt.Errorf("build context warning size must be >= 0 (0 to disable); got %d", bcwm)
+	}
+
+	bhl, bhlPassed, err := valueToInt(buildHistoryLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"build_history_limit\"")
+	}
+	if bhlPassed && bhl < 1 {
+		return nil, fmt.Errorf("build history limit must be >= 1; got %d", bhl)
+	}
+
+	ila, ilaPassed, err := valueToBool(imageLayerAnalysis)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"image_layer_analysis\"")
+	}
+
+	dl, dlPassed, err := valueToBool(discoverLinks)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"discover_links\"")
+	}
+
+	var bo model.BuildOutputVerbosity
+	if buildOutput != "" {
+		bo, err = model.ParseBuildOutputVerbosity(buildOutput)
+		if err != nil {
+			return nil, errors.Wrap(err, "update_settings: for parameter \"build_output\"")
+		}
+	}
+
 	err = starkit.SetState(thread, func(settings model.UpdateSettings) model.UpdateSettings {
+		if bo != model.BuildOutputDefault {
+			settings = settings.WithBuildOutputVerbosity(bo)
+		}
 		if mpuPassed {
 			settings = settings.WithMaxParallelUpdates(mpu)
 		}
 		if kutsPassed {
 			settings = settings.WithK8sUpsertTimeout(time.Duration(kuts) * time.Second)
 		}
+		if bcwmPassed {
+			settings = settings.WithBuildContextWarningSize(int64(bcwm) * 1000 * 1000)
+		}
+		if bhlPassed {
+			settings = settings.WithBuildHistoryLimit(bhl)
+		}
+		if ilaPassed {
+			settings = settings.WithImageLayerAnalysis(ila)
+		}
+		if dlPassed {
+			settings = settings.WithLinkDiscovery(dl)
+		}
 		return settings
 	})
 
@@ -78,6 +134,17 @@ func valueToInt(v starlark.Value) (val int, wasPassed bool, err error) {
 	}
 }
 
+func valueToBool(v starlark.Value) (val bool, wasPassed bool, err error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return false, false, nil
+	case starlark.Bool:
+		return bool(x), true, nil
+	default:
+		return false, true, fmt.Errorf("got %T, want bool", x)
+	}
+}
+
 var _ starkit.StatefulExtension = Extension{}
 
 func MustState(model starkit.Model) model.UpdateSettings {
@@ -0,0 +1,122 @@
+package tiltinit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The config files we know how to convert, in the order we look for them.
+var ConvertibleFiles = []string{
+	"skaffold.yaml",
+	"skaffold.yml",
+	"docker-compose.yml",
+	"docker-compose.yaml",
+	"compose.yml",
+	"compose.yaml",
+}
+
+// Find a config file we can convert in dir.
+func FindConvertibleFile(dir string) (string, error) {
+	for _, name := range ConvertibleFiles {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no skaffold.yaml or docker-compose.yml found in %s", dir)
+}
+
+// Convert a skaffold.yaml or docker-compose.yml into a plan for a Tiltfile
+// that lives in tiltfileDir.
+func Convert(srcPath string, tiltfileDir string) (Plan, error) {
+	contents, err := ioutil.ReadFile(srcPath)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	c := converter{srcDir: filepath.Dir(srcPath), tiltfileDir: tiltfileDir}
+	if isSkaffoldConfig(contents) {
+		return c.convertSkaffold(srcPath, contents)
+	}
+
+	var probe struct {
+		Services map[string]interface{} `yaml:"services"`
+	}
+	err = yaml.Unmarshal(contents, &probe)
+	if err == nil && len(probe.Services) > 0 {
+		return c.convertDockerCompose(srcPath, contents)
+	}
+	return Plan{}, fmt.Errorf("%s doesn't look like a skaffold.yaml or a docker-compose.yml", srcPath)
+}
+
+func isSkaffoldConfig(contents []byte) bool {
+	var probe struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(contents)))
+	if err := dec.Decode(&probe); err != nil {
+		return false
+	}
+	return strings.HasPrefix(probe.APIVersion, "skaffold/")
+}
+
+type converter struct {
+	// Paths in the source file are relative to this directory.
+	srcDir string
+
+	// Paths in the generated Tiltfile are relative to this directory.
+	tiltfileDir string
+}
+
+// Convert a path relative to the source file into a path relative to the Tiltfile.
+func (c converter) path(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.ToSlash(p)
+	}
+
+	abs := filepath.Join(c.srcDir, filepath.FromSlash(p))
+	rel, err := filepath.Rel(c.tiltfileDir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	rel = filepath.ToSlash(rel)
+	if strings.HasPrefix(rel, "../") || rel == ".." {
+		return rel
+	}
+	return dotSlash(rel)
+}
+
+// Join a path onto a Tiltfile-relative directory, keeping the ./ prefix.
+func joinPath(dir, p string) string {
+	joined := path.Join(dir, p)
+	if strings.HasPrefix(joined, "/") || strings.HasPrefix(joined, "../") || joined == ".." {
+		return joined
+	}
+	return dotSlash(joined)
+}
+
+// The directory above the first path element with a glob in it,
+// or the whole path if it has no globs.
+func globBase(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.ContainsAny(part, "*?[{") {
+			base := path.Join(parts[:i]...)
+			if base == "" {
+				return "."
+			}
+			return base
+		}
+	}
+	return path.Clean(pattern)
+}
+
+func syncStep(local, remote string) LiveUpdateStep {
+	return LiveUpdateStep(fmt.Sprintf("sync(%s, %s)", starlarkString(local), starlarkString(remote)))
+}
@@ -0,0 +1,96 @@
+package tiltinit
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+)
+
+func (c converter) convertDockerCompose(srcPath string, contents []byte) (Plan, error) {
+	var config dockercompose.Config
+	err := yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return Plan{}, fmt.Errorf("reading %s: %v", srcPath, err)
+	}
+
+	composeFile := c.path(filepath.Base(srcPath))
+	plan := Plan{
+		GeneratedBy:        fmt.Sprintf("`tilt alpha convert` from %s", composeFile),
+		DockerComposeFiles: []string{composeFile},
+	}
+
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	absSrcPath, err := filepath.Abs(srcPath)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	for _, name := range names {
+		svc := config.Services[name]
+		if svc.Build.Context == "" {
+			continue
+		}
+
+		// Tilt matches images to services by the name docker-compose
+		// gives the image. We don't know which compose CLI will run the
+		// project, so assume docker-compose v1, which Tilt tries first.
+		ref := svc.Image
+		if ref == "" {
+			ref = dockercompose.DefaultImageName(dockercompose.BackendV1, []string{absSrcPath}, name)
+		}
+
+		dockerfile := svc.Build.Dockerfile
+		if dockerfile == "" {
+			dockerfile = "Dockerfile"
+		}
+
+		image := ImagePlan{
+			Ref:       ref,
+			Context:   c.path(svc.Build.Context),
+			Target:    svc.Build.Target,
+			BuildArgs: svc.Build.Args,
+		}
+		image.Dockerfile = joinPath(image.Context, dockerfile)
+		image.LiveUpdate = c.volumesToSyncs(image.Context, svc.Volumes)
+		plan.Images = append(plan.Images, image)
+	}
+	return plan, nil
+}
+
+// Turn bind mounts of the build context into syncs, so that Tilt updates
+// the container instead of rebuilding the image when those files change.
+func (c converter) volumesToSyncs(context string, volumes dockercompose.Volumes) []LiveUpdateStep {
+	var steps []LiveUpdateStep
+	for _, v := range volumes {
+		if v.Target == "" || !(strings.HasPrefix(v.Source, ".") || filepath.IsAbs(v.Source)) {
+			// A named volume, not a bind mount.
+			continue
+		}
+
+		local := c.path(v.Source)
+		if !isWithin(local, context) {
+			continue
+		}
+		steps = append(steps, syncStep(local, v.Target))
+	}
+	return steps
+}
+
+func isWithin(p, dir string) bool {
+	p, dir = path.Clean(p), path.Clean(dir)
+	if dir == "." {
+		return !strings.HasPrefix(p, "../") && p != ".." && !path.IsAbs(p)
+	}
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
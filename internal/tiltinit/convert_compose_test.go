@@ -0,0 +1,70 @@
+package tiltinit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestConvertDockerCompose(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("myproj/docker-compose.yml", `version: '3'
+services:
+  web:
+    build: ./web
+    volumes:
+    - ./web/src:/app/src
+    - ./data:/data
+    - cache:/cache
+  api:
+    image: my-api
+    build:
+      context: api
+      dockerfile: Dockerfile.dev
+      target: dev
+  redis:
+    image: redis
+volumes:
+  cache: {}
+`)
+
+	plan, err := Convert(f.JoinPath("myproj", "docker-compose.yml"), f.JoinPath("myproj"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"./docker-compose.yml"}, plan.DockerComposeFiles)
+	assert.Equal(t, []ImagePlan{
+		{
+			Ref:        "my-api",
+			Context:    "./api",
+			Dockerfile: "./api/Dockerfile.dev",
+			Target:     "dev",
+		},
+		{
+			Ref:        "myproj_web",
+			Context:    "./web",
+			Dockerfile: "./web/Dockerfile",
+			LiveUpdate: []LiveUpdateStep{"sync('./web/src', '/app/src')"},
+		},
+	}, plan.Images)
+	assert.Empty(t, plan.Notes)
+}
+
+func TestFindConvertibleFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	_, err := FindConvertibleFile(f.Path())
+	assert.Error(t, err)
+
+	f.WriteFile("docker-compose.yml", "services: {}\n")
+	f.WriteFile("skaffold.yaml", "apiVersion: skaffold/v2beta10\n")
+
+	p, err := FindConvertibleFile(f.Path())
+	require.NoError(t, err)
+	assert.Equal(t, f.JoinPath("skaffold.yaml"), p)
+}
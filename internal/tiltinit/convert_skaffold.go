@@ -0,0 +1,321 @@
+package tiltinit
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Go representations of skaffold.yaml
+// (Only the parts we know how to convert.)
+// https://skaffold.dev/docs/references/yaml/
+type skaffoldConfig struct {
+	APIVersion  string                `yaml:"apiVersion"`
+	Build       skaffoldBuild         `yaml:"build"`
+	Deploy      skaffoldDeploy        `yaml:"deploy"`
+	PortForward []skaffoldPortForward `yaml:"portForward"`
+	Profiles    []struct {
+		Name string `yaml:"name"`
+	} `yaml:"profiles"`
+}
+
+type skaffoldBuild struct {
+	Artifacts []skaffoldArtifact `yaml:"artifacts"`
+}
+
+type skaffoldArtifact struct {
+	Image   string          `yaml:"image"`
+	Context string          `yaml:"context"`
+	Docker  *skaffoldDocker `yaml:"docker"`
+	Custom  *skaffoldCustom `yaml:"custom"`
+	Sync    *skaffoldSync   `yaml:"sync"`
+
+	// Builders we can't translate.
+	Jib        interface{} `yaml:"jib"`
+	Bazel      interface{} `yaml:"bazel"`
+	Buildpacks interface{} `yaml:"buildpacks"`
+	Ko         interface{} `yaml:"ko"`
+}
+
+type skaffoldDocker struct {
+	Dockerfile string             `yaml:"dockerfile"`
+	Target     string             `yaml:"target"`
+	BuildArgs  map[string]*string `yaml:"buildArgs"`
+}
+
+type skaffoldCustom struct {
+	BuildCommand string `yaml:"buildCommand"`
+	Dependencies struct {
+		Paths []string `yaml:"paths"`
+	} `yaml:"dependencies"`
+}
+
+type skaffoldSync struct {
+	Manual []struct {
+		Src   string `yaml:"src"`
+		Dest  string `yaml:"dest"`
+		Strip string `yaml:"strip"`
+	} `yaml:"manual"`
+	Infer []string    `yaml:"infer"`
+	Auto  interface{} `yaml:"auto"`
+}
+
+type skaffoldDeploy struct {
+	Kubectl *struct {
+		Manifests []string `yaml:"manifests"`
+	} `yaml:"kubectl"`
+	Kustomize *struct {
+		Paths []string `yaml:"paths"`
+	} `yaml:"kustomize"`
+	Helm *struct {
+		Releases []struct {
+			Name        string   `yaml:"name"`
+			ChartPath   string   `yaml:"chartPath"`
+			Namespace   string   `yaml:"namespace"`
+			ValuesFiles []string `yaml:"valuesFiles"`
+		} `yaml:"releases"`
+	} `yaml:"helm"`
+}
+
+type skaffoldPortForward struct {
+	ResourceType string `yaml:"resourceType"`
+	ResourceName string `yaml:"resourceName"`
+	Port         int    `yaml:"port"`
+	LocalPort    int    `yaml:"localPort"`
+}
+
+// Kinds that Tilt makes resources out of, so we can put port forwards on them.
+var skaffoldWorkloadTypes = map[string]bool{
+	"pod":         true,
+	"deployment":  true,
+	"statefulset": true,
+	"daemonset":   true,
+	"replicaset":  true,
+	"job":         true,
+}
+
+func (c converter) convertSkaffold(srcPath string, contents []byte) (Plan, error) {
+	plan := Plan{GeneratedBy: fmt.Sprintf("`tilt alpha convert` from %s", c.path(filepath.Base(srcPath)))}
+
+	// skaffold.yaml may hold several configs, separated by ---.
+	dec := yaml.NewDecoder(strings.NewReader(string(contents)))
+	for {
+		var config skaffoldConfig
+		err := dec.Decode(&config)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Plan{}, fmt.Errorf("reading %s: %v", srcPath, err)
+		}
+		err = c.addSkaffoldConfig(&plan, config)
+		if err != nil {
+			return Plan{}, err
+		}
+	}
+	return plan, nil
+}
+
+func (c converter) addSkaffoldConfig(plan *Plan, config skaffoldConfig) error {
+	for _, profile := range config.Profiles {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("skaffold profile %q. Try config.define_string() to switch between setups", profile.Name))
+	}
+
+	for _, a := range config.Build.Artifacts {
+		image, ok := c.convertSkaffoldArtifact(plan, a)
+		if ok {
+			plan.Images = append(plan.Images, image)
+		}
+	}
+
+	err := c.convertSkaffoldDeploy(plan, config.Deploy)
+	if err != nil {
+		return err
+	}
+
+	for _, pf := range config.PortForward {
+		if !skaffoldWorkloadTypes[strings.ToLower(pf.ResourceType)] {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("port forward to %s/%s. Tilt forwards ports from workloads, so add it to the k8s_resource for the workload behind it",
+				pf.ResourceType, pf.ResourceName))
+			continue
+		}
+		plan.PortForwards = append(plan.PortForwards, WorkloadInfo{
+			Name:      pf.ResourceName,
+			Kind:      pf.ResourceType,
+			Port:      pf.Port,
+			LocalPort: pf.LocalPort,
+		})
+	}
+	return nil
+}
+
+func (c converter) convertSkaffoldArtifact(plan *Plan, a skaffoldArtifact) (ImagePlan, bool) {
+	unsupported := []struct {
+		name string
+		val  interface{}
+	}{{"jib", a.Jib}, {"bazel", a.Bazel}, {"buildpacks", a.Buildpacks}, {"ko", a.Ko}}
+	for _, builder := range unsupported {
+		if builder.val != nil {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("image %s is built with %s. Try custom_build() with the command you use to build it", a.Image, builder.name))
+			return ImagePlan{}, false
+		}
+	}
+
+	context := a.Context
+	if context == "" {
+		context = "."
+	}
+	image := ImagePlan{Ref: a.Image, Context: c.path(context)}
+
+	if a.Custom != nil {
+		deps := []string{}
+		for _, dep := range a.Custom.Dependencies.Paths {
+			deps = append(deps, joinPath(image.Context, dep))
+		}
+		if len(deps) == 0 {
+			deps = append(deps, image.Context)
+		}
+
+		// Skaffold runs the command in the build context, and tells it
+		// the image name in $IMAGE.
+		command := "IMAGE=$EXPECTED_REF " + a.Custom.BuildCommand
+		if image.Context != "." {
+			command = fmt.Sprintf("cd %s && %s", image.Context, command)
+		}
+		image.CustomBuild = &CustomBuildPlan{Command: command, Deps: deps}
+	} else {
+		dockerfile := "Dockerfile"
+		if a.Docker != nil {
+			if a.Docker.Dockerfile != "" {
+				dockerfile = a.Docker.Dockerfile
+			}
+			image.Target = a.Docker.Target
+			image.BuildArgs = skaffoldBuildArgs(plan, a)
+		}
+		image.Dockerfile = joinPath(image.Context, dockerfile)
+	}
+
+	if a.Sync != nil {
+		image.LiveUpdate = c.convertSkaffoldSync(plan, image, a)
+	}
+	return image, true
+}
+
+func skaffoldBuildArgs(plan *Plan, a skaffoldArtifact) map[string]string {
+	if len(a.Docker.BuildArgs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(a.Docker.BuildArgs))
+	for k := range a.Docker.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make(map[string]string, len(keys))
+	for _, k := range keys {
+		v := a.Docker.BuildArgs[k]
+		if v == nil {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("build arg %s for image %s is read from the environment. Try os.getenv()", k, a.Image))
+			continue
+		}
+		args[k] = *v
+	}
+	return args
+}
+
+func (c converter) convertSkaffoldSync(plan *Plan, image ImagePlan, a skaffoldArtifact) []LiveUpdateStep {
+	var steps []LiveUpdateStep
+	for _, m := range a.Sync.Manual {
+		// Skaffold copies each file matching src to dest, minus the strip prefix.
+		// Tilt syncs whole directories, so sync the directory the glob is rooted at.
+		base := globBase(m.Src)
+		strip := strings.TrimSuffix(m.Strip, "/")
+		rel := base
+		if strip != "" && (base == strip || strings.HasPrefix(base, strip+"/")) {
+			rel = strings.TrimPrefix(strings.TrimPrefix(base, strip), "/")
+		}
+		steps = append(steps, syncStep(joinPath(image.Context, base), path.Join(m.Dest, rel)))
+	}
+
+	if len(a.Sync.Infer) > 0 {
+		if image.Dockerfile == "" {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("inferred sync for image %s. Add sync() steps to its live_update", a.Image))
+		} else {
+			inferred := SuggestLiveUpdate(c.tiltfileDir, DockerfileInfo{Path: image.Dockerfile, Context: image.Context})
+			if len(inferred) == 0 {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("inferred sync for image %s. Add sync() steps to its live_update", a.Image))
+			}
+			steps = append(steps, inferred...)
+		}
+	}
+
+	if a.Sync.Auto != nil {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("auto sync for image %s. Add sync() steps to its live_update", a.Image))
+	}
+	return steps
+}
+
+func (c converter) convertSkaffoldDeploy(plan *Plan, d skaffoldDeploy) error {
+	if d.Kubectl != nil {
+		manifests := d.Kubectl.Manifests
+		if len(manifests) == 0 {
+			manifests = []string{"k8s/*.yaml"}
+		}
+		for _, m := range manifests {
+			matches, err := filepath.Glob(filepath.Join(c.srcDir, filepath.FromSlash(m)))
+			if err != nil {
+				return fmt.Errorf("reading manifests %q: %v", m, err)
+			}
+			if len(matches) == 0 {
+				if strings.HasPrefix(m, "http://") || strings.HasPrefix(m, "https://") {
+					plan.Notes = append(plan.Notes, fmt.Sprintf("remote manifest %s. Download it, or use local() with kubectl to fetch it", m))
+				} else {
+					plan.Notes = append(plan.Notes, fmt.Sprintf("no files match manifest %s", m))
+				}
+				continue
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				rel, err := filepath.Rel(c.srcDir, match)
+				if err != nil {
+					return err
+				}
+				plan.K8sYAML = append(plan.K8sYAML, c.path(filepath.ToSlash(rel)))
+			}
+		}
+	}
+
+	if d.Kustomize != nil {
+		paths := d.Kustomize.Paths
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		for _, p := range paths {
+			plan.Kustomizations = append(plan.Kustomizations, c.path(p))
+		}
+	}
+
+	if d.Helm != nil {
+		for _, r := range d.Helm.Releases {
+			if r.ChartPath == "" {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("helm release %s installs a remote chart. Try the helm_remote extension", r.Name))
+				continue
+			}
+			release := HelmReleasePlan{
+				Chart:     c.path(r.ChartPath),
+				Name:      r.Name,
+				Namespace: r.Namespace,
+			}
+			for _, v := range r.ValuesFiles {
+				release.Values = append(release.Values, c.path(v))
+			}
+			plan.HelmReleases = append(plan.HelmReleases, release)
+		}
+	}
+	return nil
+}
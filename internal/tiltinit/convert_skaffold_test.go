@@ -0,0 +1,163 @@
+package tiltinit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+const skaffoldYAML = `apiVersion: skaffold/v2beta10
+kind: Config
+build:
+  artifacts:
+  - image: gcr.io/my-project/web
+    context: web
+    docker:
+      dockerfile: Dockerfile.dev
+      target: dev
+      buildArgs:
+        MODE: debug
+    sync:
+      manual:
+      - src: 'src/**/*.js'
+        dest: /app
+        strip: src/
+  - image: api
+    context: api
+    custom:
+      buildCommand: ./build.sh
+      dependencies:
+        paths: [src, build.sh]
+  - image: java-thing
+    jib: {}
+deploy:
+  kubectl:
+    manifests:
+    - k8s/*.yaml
+  helm:
+    releases:
+    - name: db
+      chartPath: charts/db
+      valuesFiles: [charts/db/dev.yaml]
+portForward:
+- resourceType: deployment
+  resourceName: web
+  port: 8080
+  localPort: 9000
+- resourceType: service
+  resourceName: api
+  port: 80
+profiles:
+- name: prod
+`
+
+func TestConvertSkaffold(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("skaffold.yaml", skaffoldYAML)
+	f.WriteFile("k8s/web.yaml", webDeployment)
+	f.WriteFile("k8s/api.yaml", webDeployment)
+
+	plan, err := Convert(f.JoinPath("skaffold.yaml"), f.Path())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"./k8s/api.yaml", "./k8s/web.yaml"}, plan.K8sYAML)
+	assert.Equal(t, []HelmReleasePlan{{
+		Chart:  "./charts/db",
+		Name:   "db",
+		Values: []string{"./charts/db/dev.yaml"},
+	}}, plan.HelmReleases)
+
+	require.Len(t, plan.Images, 2)
+	assert.Equal(t, ImagePlan{
+		Ref:        "gcr.io/my-project/web",
+		Context:    "./web",
+		Dockerfile: "./web/Dockerfile.dev",
+		Target:     "dev",
+		BuildArgs:  map[string]string{"MODE": "debug"},
+		LiveUpdate: []LiveUpdateStep{"sync('./web/src', '/app')"},
+	}, plan.Images[0])
+	assert.Equal(t, ImagePlan{
+		Ref:     "api",
+		Context: "./api",
+		CustomBuild: &CustomBuildPlan{
+			Command: "cd ./api && IMAGE=$EXPECTED_REF ./build.sh",
+			Deps:    []string{"./api/src", "./api/build.sh"},
+		},
+	}, plan.Images[1])
+
+	assert.Equal(t, []WorkloadInfo{{Name: "web", Kind: "deployment", Port: 8080, LocalPort: 9000}}, plan.PortForwards)
+
+	require.Len(t, plan.Notes, 3)
+	assert.Contains(t, plan.Notes[0], `skaffold profile "prod"`)
+	assert.Contains(t, plan.Notes[1], "image java-thing is built with jib")
+	assert.Contains(t, plan.Notes[2], "port forward to service/api")
+}
+
+func TestConvertSkaffoldTiltfileInOtherDir(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("deploy/skaffold.yaml", `apiVersion: skaffold/v2beta10
+kind: Config
+build:
+  artifacts:
+  - image: web
+    context: ../web
+deploy:
+  kustomize: {}
+`)
+
+	plan, err := Convert(f.JoinPath("deploy", "skaffold.yaml"), f.Path())
+	require.NoError(t, err)
+
+	assert.Equal(t, "`tilt alpha convert` from ./deploy/skaffold.yaml", plan.GeneratedBy)
+	assert.Equal(t, []string{"./deploy"}, plan.Kustomizations)
+	require.Len(t, plan.Images, 1)
+	assert.Equal(t, "./web", plan.Images[0].Context)
+	assert.Equal(t, "./web/Dockerfile", plan.Images[0].Dockerfile)
+}
+
+func TestConvertSkaffoldInferSync(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("skaffold.yaml", `apiVersion: skaffold/v2beta10
+kind: Config
+build:
+  artifacts:
+  - image: web
+    sync:
+      infer: ['**/*.py']
+`)
+	f.WriteFile("Dockerfile", "FROM python\nWORKDIR /app\nCOPY . .\n")
+
+	plan, err := Convert(f.JoinPath("skaffold.yaml"), f.Path())
+	require.NoError(t, err)
+
+	require.Len(t, plan.Images, 1)
+	assert.Equal(t, []LiveUpdateStep{"sync('.', '/app')"}, plan.Images[0].LiveUpdate)
+	assert.Empty(t, plan.Notes)
+}
+
+func TestConvertNotAConfig(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("values.yaml", "replicas: 3\n")
+
+	_, err := Convert(f.JoinPath("values.yaml"), f.Path())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "doesn't look like a skaffold.yaml or a docker-compose.yml")
+	}
+}
+
+func TestGlobBase(t *testing.T) {
+	assert.Equal(t, "src", globBase("src/**/*.js"))
+	assert.Equal(t, ".", globBase("*.py"))
+	assert.Equal(t, "src/main.go", globBase("src/main.go"))
+}
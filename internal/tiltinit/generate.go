@@ -0,0 +1,226 @@
+package tiltinit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An image for the Tiltfile to build.
+type ImagePlan struct {
+	Ref        string
+	Context    string
+	Dockerfile string
+	Target     string
+	BuildArgs  map[string]string
+	LiveUpdate []LiveUpdateStep
+
+	// If set, build the image with custom_build instead of docker_build.
+	CustomBuild *CustomBuildPlan
+}
+
+// An image built by a command.
+type CustomBuildPlan struct {
+	// A shell command that builds the image and tags it as $EXPECTED_REF.
+	Command string
+	Deps    []string
+}
+
+// A helm chart to render, with its release settings.
+type HelmReleasePlan struct {
+	Chart     string
+	Name      string
+	Namespace string
+	Values    []string
+}
+
+// What to put in the generated Tiltfile.
+type Plan struct {
+	DockerComposeFiles []string
+	K8sYAML            []string
+	Kustomizations     []string
+	HelmCharts         []string
+	HelmReleases       []HelmReleasePlan
+	Images             []ImagePlan
+
+	// Workloads to give k8s_resource port forwards.
+	PortForwards []WorkloadInfo
+
+	// The command that generated the Tiltfile. Defaults to `tilt init`.
+	GeneratedBy string
+
+	// Things we couldn't translate, left as comments for the user to deal with.
+	Notes []string
+}
+
+func (p Plan) Empty() bool {
+	return len(p.DockerComposeFiles) == 0 && len(p.K8sYAML) == 0 &&
+		len(p.Kustomizations) == 0 && len(p.HelmCharts) == 0 &&
+		len(p.HelmReleases) == 0 && len(p.Images) == 0
+}
+
+// Generate the text of a Tiltfile from a plan.
+func Generate(p Plan) string {
+	generatedBy := p.GeneratedBy
+	if generatedBy == "" {
+		generatedBy = "`tilt init`"
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("# -*- mode: Python -*-\n")
+	fmt.Fprintf(sb, "# Generated by %s. Edit away!\n", generatedBy)
+	sb.WriteString("# For more on Tiltfiles, see https://docs.tilt.dev/api.html\n")
+
+	if len(p.Notes) > 0 {
+		sb.WriteString("#\n# TODO: Some things couldn't be translated automatically:\n")
+		for _, note := range p.Notes {
+			fmt.Fprintf(sb, "#   - %s\n", note)
+		}
+	}
+
+	if len(p.DockerComposeFiles) > 0 {
+		sb.WriteString("\n# Run the services in your docker-compose files.\n")
+		fmt.Fprintf(sb, "docker_compose(%s)\n", starlarkList(p.DockerComposeFiles))
+	}
+
+	if len(p.K8sYAML) > 0 {
+		sb.WriteString("\n# Deploy your Kubernetes YAML.\n")
+		fmt.Fprintf(sb, "k8s_yaml(%s)\n", starlarkList(p.K8sYAML))
+	}
+
+	if len(p.Kustomizations) > 0 {
+		sb.WriteString("\n# Build your kustomizations and deploy the result.\n")
+		for _, k := range p.Kustomizations {
+			fmt.Fprintf(sb, "k8s_yaml(kustomize(%s))\n", starlarkString(k))
+		}
+	}
+
+	if len(p.HelmCharts) > 0 {
+		sb.WriteString("\n# Render your Helm charts and deploy the result.\n")
+		for _, chart := range p.HelmCharts {
+			fmt.Fprintf(sb, "k8s_yaml(helm(%s))\n", starlarkString(chart))
+		}
+	}
+
+	if len(p.HelmReleases) > 0 {
+		sb.WriteString("\n# Render your Helm releases and deploy the result.\n")
+		for _, r := range p.HelmReleases {
+			fmt.Fprintf(sb, "k8s_yaml(helm(%s", starlarkString(r.Chart))
+			if r.Name != "" {
+				fmt.Fprintf(sb, ", name=%s", starlarkString(r.Name))
+			}
+			if r.Namespace != "" {
+				fmt.Fprintf(sb, ", namespace=%s", starlarkString(r.Namespace))
+			}
+			if len(r.Values) > 0 {
+				fmt.Fprintf(sb, ", values=%s", starlarkList(r.Values))
+			}
+			sb.WriteString("))\n")
+		}
+	}
+
+	for _, image := range p.Images {
+		if image.CustomBuild != nil {
+			writeCustomBuild(sb, image)
+		} else {
+			writeDockerBuild(sb, image)
+		}
+	}
+
+	if len(p.PortForwards) > 0 {
+		sb.WriteString("\n# Forward ports so you can reach your services on localhost.\n")
+		for _, w := range p.PortForwards {
+			if w.LocalPort != 0 && w.LocalPort != w.Port {
+				fmt.Fprintf(sb, "k8s_resource(%s, port_forwards='%d:%d')\n", starlarkString(w.Name), w.LocalPort, w.Port)
+			} else {
+				fmt.Fprintf(sb, "k8s_resource(%s, port_forwards=%d)\n", starlarkString(w.Name), w.Port)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func writeDockerBuild(sb *strings.Builder, image ImagePlan) {
+	fmt.Fprintf(sb, "\n# Build %s from %s.\n", image.Ref, image.Dockerfile)
+	writeLiveUpdateComment(sb, image)
+	fmt.Fprintf(sb, "docker_build(%s, %s", starlarkString(image.Ref), starlarkString(image.Context))
+	if image.Dockerfile != defaultDockerfile(image.Context) {
+		fmt.Fprintf(sb, ",\n    dockerfile=%s", starlarkString(image.Dockerfile))
+	}
+	if image.Target != "" {
+		fmt.Fprintf(sb, ",\n    target=%s", starlarkString(image.Target))
+	}
+	if len(image.BuildArgs) > 0 {
+		fmt.Fprintf(sb, ",\n    build_args=%s", starlarkDict(image.BuildArgs))
+	}
+	writeLiveUpdate(sb, image)
+	sb.WriteString(")\n")
+}
+
+func writeCustomBuild(sb *strings.Builder, image ImagePlan) {
+	fmt.Fprintf(sb, "\n# Build %s with a custom command.\n", image.Ref)
+	writeLiveUpdateComment(sb, image)
+	fmt.Fprintf(sb, "custom_build(%s, %s,\n    deps=[%s]",
+		starlarkString(image.Ref), starlarkString(image.CustomBuild.Command),
+		strings.Join(starlarkStrings(image.CustomBuild.Deps), ", "))
+	writeLiveUpdate(sb, image)
+	sb.WriteString(")\n")
+}
+
+func writeLiveUpdateComment(sb *strings.Builder, image ImagePlan) {
+	if len(image.LiveUpdate) > 0 {
+		sb.WriteString("# When files change, sync them into the running container instead of rebuilding.\n")
+	}
+}
+
+func writeLiveUpdate(sb *strings.Builder, image ImagePlan) {
+	if len(image.LiveUpdate) == 0 {
+		return
+	}
+	sb.WriteString(",\n    live_update=[\n")
+	for _, step := range image.LiveUpdate {
+		fmt.Fprintf(sb, "        %s,\n", step)
+	}
+	sb.WriteString("    ]")
+}
+
+func defaultDockerfile(context string) string {
+	if context == "." {
+		return "./Dockerfile"
+	}
+	return context + "/Dockerfile"
+}
+
+func starlarkString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
+
+func starlarkList(items []string) string {
+	if len(items) == 1 {
+		return starlarkString(items[0])
+	}
+	return "[" + strings.Join(starlarkStrings(items), ", ") + "]"
+}
+
+func starlarkStrings(items []string) []string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, starlarkString(item))
+	}
+	return quoted
+}
+
+func starlarkDict(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, starlarkString(k)+": "+starlarkString(m[k]))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
@@ -0,0 +1,83 @@
+package tiltinit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateK8s(t *testing.T) {
+	out := Generate(Plan{
+		K8sYAML:    []string{"./k8s/web.yaml", "./k8s/api.yaml"},
+		HelmCharts: []string{"./charts/db"},
+		Images: []ImagePlan{
+			{Ref: "web", Context: "./web", Dockerfile: "./web/Dockerfile"},
+			{
+				Ref:        "api",
+				Context:    "./api",
+				Dockerfile: "./api/Dockerfile.dev",
+				LiveUpdate: []LiveUpdateStep{"sync('./api', '/app')"},
+			},
+		},
+		PortForwards: []WorkloadInfo{{Name: "web", Port: 8000}},
+	})
+
+	assert.Contains(t, out, "k8s_yaml(['./k8s/web.yaml', './k8s/api.yaml'])\n")
+	assert.Contains(t, out, "k8s_yaml(helm('./charts/db'))\n")
+	assert.Contains(t, out, "docker_build('web', './web')\n")
+	assert.Contains(t, out, `docker_build('api', './api',
+    dockerfile='./api/Dockerfile.dev',
+    live_update=[
+        sync('./api', '/app'),
+    ])
+`)
+	assert.Contains(t, out, "k8s_resource('web', port_forwards=8000)\n")
+	assert.NotContains(t, out, "docker_compose")
+}
+
+func TestGenerateDockerCompose(t *testing.T) {
+	out := Generate(Plan{DockerComposeFiles: []string{"./docker-compose.yml"}})
+	assert.Contains(t, out, "docker_compose('./docker-compose.yml')\n")
+	assert.NotContains(t, out, "k8s_yaml")
+}
+
+func TestGenerateConverted(t *testing.T) {
+	out := Generate(Plan{
+		GeneratedBy:    "`tilt alpha convert` from ./skaffold.yaml",
+		Notes:          []string{"skaffold profile \"prod\""},
+		Kustomizations: []string{"./k8s"},
+		HelmReleases:   []HelmReleasePlan{{Chart: "./charts/db", Name: "db", Values: []string{"./dev.yaml"}}},
+		Images: []ImagePlan{
+			{
+				Ref:        "web",
+				Context:    ".",
+				Dockerfile: "./Dockerfile",
+				Target:     "dev",
+				BuildArgs:  map[string]string{"B": "2", "A": "1"},
+			},
+			{
+				Ref:         "api",
+				Context:     "./api",
+				CustomBuild: &CustomBuildPlan{Command: "cd ./api && IMAGE=$EXPECTED_REF ./build.sh", Deps: []string{"./api"}},
+				LiveUpdate:  []LiveUpdateStep{"sync('./api/src', '/app')"},
+			},
+		},
+		PortForwards: []WorkloadInfo{{Name: "web", Port: 8080, LocalPort: 9000}},
+	})
+
+	assert.Contains(t, out, "# Generated by `tilt alpha convert` from ./skaffold.yaml. Edit away!\n")
+	assert.Contains(t, out, "#   - skaffold profile \"prod\"\n")
+	assert.Contains(t, out, "k8s_yaml(kustomize('./k8s'))\n")
+	assert.Contains(t, out, "k8s_yaml(helm('./charts/db', name='db', values='./dev.yaml'))\n")
+	assert.Contains(t, out, `docker_build('web', '.',
+    target='dev',
+    build_args={'A': '1', 'B': '2'})
+`)
+	assert.Contains(t, out, `custom_build('api', 'cd ./api && IMAGE=$EXPECTED_REF ./build.sh',
+    deps=['./api'],
+    live_update=[
+        sync('./api/src', '/app'),
+    ])
+`)
+	assert.Contains(t, out, "k8s_resource('web', port_forwards='9000:8080')\n")
+}
@@ -0,0 +1,138 @@
+package tiltinit
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+)
+
+// A live_update step, as Tiltfile code.
+type LiveUpdateStep string
+
+// Dependency managers whose install step we know how to re-run
+// when their manifest changes.
+var installCommands = []struct {
+	trigger  string
+	commands []string
+}{
+	{"package.json", []string{"npm install", "npm ci", "yarn install", "yarn"}},
+	{"requirements.txt", []string{"pip install -r requirements.txt", "pip3 install -r requirements.txt"}},
+	{"Gemfile", []string{"bundle install"}},
+}
+
+// Suggest live_update steps for a Dockerfile, based on how it copies
+// the build context into the image.
+//
+// If the final stage copies the whole build context into the image, we can
+// sync it into the running container instead of rebuilding. And if the Dockerfile
+// runs a dependency install, we re-run it when the dependency manifest changes.
+//
+// Returns nil if we can't figure out a sensible sync.
+func SuggestLiveUpdate(repoDir string, df DockerfileInfo) []LiveUpdateStep {
+	contents, err := ioutil.ReadFile(filepath.Join(repoDir, filepath.FromSlash(df.Path)))
+	if err != nil {
+		return nil
+	}
+
+	ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(contents))
+	if err != nil {
+		return nil
+	}
+
+	workdir := "/"
+	syncDest := ""
+	var runs []string
+	_ = ast.Traverse(func(node *parser.Node) error {
+		switch strings.ToLower(node.Value) {
+		case "from":
+			// Only the last stage ends up in the running container.
+			workdir = "/"
+			syncDest = ""
+			runs = nil
+		case "workdir":
+			if node.Next != nil {
+				workdir = resolveContainerPath(workdir, node.Next.Value)
+			}
+		case "copy", "add":
+			if hasFromFlag(node.Flags) {
+				return nil
+			}
+			args := nodeArgs(node)
+			if len(args) < 2 {
+				return nil
+			}
+			for _, src := range args[:len(args)-1] {
+				if src == "." || src == "./" {
+					syncDest = resolveContainerPath(workdir, args[len(args)-1])
+				}
+			}
+		case "run":
+			runs = append(runs, strings.Join(nodeArgs(node), " "))
+		}
+		return nil
+	})
+
+	if syncDest == "" {
+		return nil
+	}
+
+	steps := []LiveUpdateStep{
+		LiveUpdateStep("sync('" + df.Context + "', '" + syncDest + "')"),
+	}
+	for _, ic := range installCommands {
+		if _, err := os.Stat(filepath.Join(repoDir, filepath.FromSlash(df.Context), ic.trigger)); err != nil {
+			continue
+		}
+		cmd := findInstallCommand(runs, ic.commands)
+		if cmd == "" {
+			continue
+		}
+		trigger := path.Join(df.Context, ic.trigger)
+		if !strings.HasPrefix(trigger, ".") {
+			trigger = "./" + trigger
+		}
+		steps = append(steps, LiveUpdateStep("run('cd "+syncDest+" && "+cmd+"', trigger=['"+trigger+"'])"))
+	}
+	return steps
+}
+
+func findInstallCommand(runs []string, commands []string) string {
+	for _, run := range runs {
+		for _, cmd := range commands {
+			if strings.Contains(run, cmd) {
+				return cmd
+			}
+		}
+	}
+	return ""
+}
+
+func nodeArgs(node *parser.Node) []string {
+	args := []string{}
+	for n := node.Next; n != nil; n = n.Next {
+		args = append(args, n.Value)
+	}
+	return args
+}
+
+func hasFromFlag(flags []string) bool {
+	for _, f := range flags {
+		if strings.HasPrefix(f, "--from") {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveContainerPath(workdir, p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	return path.Join(workdir, p)
+}
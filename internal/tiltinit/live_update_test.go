@@ -0,0 +1,60 @@
+package tiltinit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestSuggestLiveUpdateSync(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("web/Dockerfile", `FROM golang:1.14
+WORKDIR /app
+COPY . .
+RUN go build ./...
+`)
+
+	steps := SuggestLiveUpdate(f.Path(), DockerfileInfo{Path: "./web/Dockerfile", Context: "./web"})
+	assert.Equal(t, []LiveUpdateStep{"sync('./web', '/app')"}, steps)
+}
+
+func TestSuggestLiveUpdateInstall(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("package.json", "{}")
+	f.WriteFile("Dockerfile", `FROM node:12
+WORKDIR /src
+COPY package.json ./
+RUN yarn install
+COPY . ./
+`)
+
+	steps := SuggestLiveUpdate(f.Path(), DockerfileInfo{Path: "./Dockerfile", Context: "."})
+	assert.Equal(t, []LiveUpdateStep{
+		"sync('.', '/src')",
+		"run('cd /src && yarn install', trigger=['./package.json'])",
+	}, steps)
+}
+
+func TestSuggestLiveUpdateOnlyLastStage(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	// The final image only gets the compiled binary, so syncing source won't help.
+	f.WriteFile("Dockerfile", `FROM golang:1.14 AS builder
+WORKDIR /app
+COPY . .
+RUN go build -o /server .
+
+FROM alpine
+COPY --from=builder /server /server
+`)
+
+	steps := SuggestLiveUpdate(f.Path(), DockerfileInfo{Path: "./Dockerfile", Context: "."})
+	assert.Nil(t, steps)
+}
@@ -0,0 +1,194 @@
+// Package tiltinit inspects a repo and generates a starter Tiltfile for it.
+package tiltinit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+// Don't bother parsing YAML files bigger than this. They're probably data, not config.
+const maxYAMLSize = 1000 * 1000
+
+// Directories that never contain anything we'd want to deploy.
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"tilt_modules": true,
+}
+
+// A Dockerfile we found in the repo.
+type DockerfileInfo struct {
+	// Relative to the repo root, with forward slashes.
+	Path string
+
+	// The directory containing the Dockerfile, which we assume is its build context.
+	Context string
+}
+
+// A workload we found in k8s YAML.
+type WorkloadInfo struct {
+	Name   string
+	Kind   string
+	Images []string
+
+	// The first container port, or 0 if none.
+	Port int
+
+	// The port to forward to on localhost, if it's different from Port.
+	LocalPort int
+}
+
+// A k8s YAML file we found in the repo.
+type K8sYAMLInfo struct {
+	Path      string
+	Workloads []WorkloadInfo
+}
+
+// Everything we found in the repo that Tilt knows how to deal with.
+type Project struct {
+	Dir                string
+	Dockerfiles        []DockerfileInfo
+	K8sYAML            []K8sYAMLInfo
+	DockerComposeFiles []string
+	HelmCharts         []string
+}
+
+func (p Project) Empty() bool {
+	return len(p.Dockerfiles) == 0 && len(p.K8sYAML) == 0 &&
+		len(p.DockerComposeFiles) == 0 && len(p.HelmCharts) == 0
+}
+
+// Walk the repo at dir, looking for Dockerfiles, k8s YAML, compose files, and helm charts.
+func Scan(dir string) (Project, error) {
+	p := Project{Dir: dir}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if path != dir && (skipDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+
+			// Helm templates aren't valid YAML, so don't look inside charts.
+			if _, err := os.Stat(filepath.Join(path, "Chart.yaml")); err == nil {
+				p.HelmCharts = append(p.HelmCharts, dotSlash(rel))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := info.Name()
+		switch {
+		case isDockerfile(name):
+			p.Dockerfiles = append(p.Dockerfiles, DockerfileInfo{
+				Path:    dotSlash(rel),
+				Context: dotSlash(filepath.ToSlash(filepath.Dir(rel))),
+			})
+		case isDockerComposeFile(name):
+			p.DockerComposeFiles = append(p.DockerComposeFiles, dotSlash(rel))
+		case isYAML(name) && info.Size() <= maxYAMLSize:
+			workloads, ok := scanK8sYAML(path)
+			if ok {
+				p.K8sYAML = append(p.K8sYAML, K8sYAMLInfo{Path: dotSlash(rel), Workloads: workloads})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Project{}, err
+	}
+
+	sort.Slice(p.Dockerfiles, func(i, j int) bool { return p.Dockerfiles[i].Path < p.Dockerfiles[j].Path })
+	return p, nil
+}
+
+func isDockerfile(name string) bool {
+	return name == "Dockerfile" || strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+func isDockerComposeFile(name string) bool {
+	switch name {
+	case "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml":
+		return true
+	}
+	return false
+}
+
+func isYAML(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Returns false if the file isn't k8s YAML.
+func scanK8sYAML(path string) ([]WorkloadInfo, bool) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	entities, err := k8s.ParseYAMLFromString(string(contents))
+	if err != nil || len(entities) == 0 {
+		return nil, false
+	}
+
+	workloads := []WorkloadInfo{}
+	for _, e := range entities {
+		if e.GVK().Kind == "" {
+			return nil, false
+		}
+
+		images, err := e.FindImages(nil, nil)
+		if err != nil || len(images) == 0 {
+			continue
+		}
+
+		w := WorkloadInfo{Name: e.Name(), Kind: e.GVK().Kind}
+		for _, image := range images {
+			w.Images = append(w.Images, reference.FamiliarName(image))
+		}
+
+		pods, err := k8s.ExtractPods(&e)
+		if err == nil {
+			w.Port = firstContainerPort(pods)
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, true
+}
+
+func firstContainerPort(pods []*v1.PodSpec) int {
+	for _, pod := range pods {
+		for _, c := range pod.Containers {
+			for _, port := range c.Ports {
+				if port.ContainerPort > 0 {
+					return int(port.ContainerPort)
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func dotSlash(rel string) string {
+	if rel == "." {
+		return "."
+	}
+	return "./" + rel
+}
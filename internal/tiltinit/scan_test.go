@@ -0,0 +1,74 @@
+package tiltinit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+const webDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: gcr.io/my-project/web
+        ports:
+        - containerPort: 8000
+`
+
+func TestScan(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("web/Dockerfile", "FROM alpine\n")
+	f.WriteFile("api/Dockerfile.dev", "FROM alpine\n")
+	f.WriteFile("k8s/web.yaml", webDeployment)
+	f.WriteFile("k8s/values.yaml", "replicas: 3\n")
+	f.WriteFile("docker-compose.yml", "version: '3'\n")
+	f.WriteFile("charts/db/Chart.yaml", "name: db\n")
+	f.WriteFile("charts/db/templates/deployment.yaml", "{{ .Values.foo }}\n")
+	f.WriteFile("node_modules/foo/Dockerfile", "FROM alpine\n")
+	f.WriteFile(".git/Dockerfile", "FROM alpine\n")
+
+	p, err := Scan(f.Path())
+	require.NoError(t, err)
+
+	assert.Equal(t, []DockerfileInfo{
+		{Path: "./api/Dockerfile.dev", Context: "./api"},
+		{Path: "./web/Dockerfile", Context: "./web"},
+	}, p.Dockerfiles)
+	assert.Equal(t, []string{"./docker-compose.yml"}, p.DockerComposeFiles)
+	assert.Equal(t, []string{"./charts/db"}, p.HelmCharts)
+	assert.Equal(t, []K8sYAMLInfo{
+		{
+			Path: "./k8s/web.yaml",
+			Workloads: []WorkloadInfo{
+				{Name: "web", Kind: "Deployment", Images: []string{"gcr.io/my-project/web"}, Port: 8000},
+			},
+		},
+	}, p.K8sYAML)
+}
+
+func TestScanEmpty(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("README.md", "hi\n")
+
+	p, err := Scan(f.Path())
+	require.NoError(t, err)
+	assert.True(t, p.Empty())
+}
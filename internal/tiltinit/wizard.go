@@ -0,0 +1,271 @@
+package tiltinit
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/hud/prompt"
+)
+
+// Asks the user questions.
+type Asker interface {
+	// Ask a yes/no question.
+	Confirm(question string, dflt bool) (bool, error)
+
+	// Ask the user to pick one of the options. Returns the index of the option.
+	Choose(question string, options []string) (int, error)
+}
+
+// Answers every question with the default, for non-interactive use.
+type DefaultAsker struct{}
+
+func (DefaultAsker) Confirm(question string, dflt bool) (bool, error) { return dflt, nil }
+func (DefaultAsker) Choose(question string, options []string) (int, error) {
+	return 0, nil
+}
+
+// Asks questions on the terminal, one keypress per answer,
+// like the prompt that `tilt up` shows.
+type TerminalAsker struct {
+	readRune func() (rune, error)
+	out      io.Writer
+}
+
+func NewTerminalAsker(in prompt.TerminalInput, out io.Writer) TerminalAsker {
+	return TerminalAsker{readRune: in.ReadRune, out: out}
+}
+
+func (a TerminalAsker) Confirm(question string, dflt bool) (bool, error) {
+	hint := "[Y/n]"
+	if !dflt {
+		hint = "[y/N]"
+	}
+	_, _ = fmt.Fprintf(a.out, "%s %s ", question, hint)
+	for {
+		r, err := a.readRune()
+		if err != nil {
+			return false, err
+		}
+		switch r {
+		case 'y', 'Y':
+			_, _ = fmt.Fprintln(a.out, "y")
+			return true, nil
+		case 'n', 'N':
+			_, _ = fmt.Fprintln(a.out, "n")
+			return false, nil
+		case '\r', '\n':
+			_, _ = fmt.Fprintln(a.out)
+			return dflt, nil
+		}
+	}
+}
+
+func (a TerminalAsker) Choose(question string, options []string) (int, error) {
+	// One keypress per answer, so we can only offer 9 options.
+	if len(options) > 9 {
+		options = options[:9]
+	}
+
+	_, _ = fmt.Fprintln(a.out, question)
+	for i, option := range options {
+		_, _ = fmt.Fprintf(a.out, "  (%d) %s\n", i+1, option)
+	}
+	_, _ = fmt.Fprintf(a.out, "[1-%d, default 1] ", len(options))
+	for {
+		r, err := a.readRune()
+		if err != nil {
+			return 0, err
+		}
+		if r == '\r' || r == '\n' {
+			_, _ = fmt.Fprintln(a.out, "1")
+			return 0, nil
+		}
+		i := int(r - '1')
+		if i >= 0 && i < len(options) {
+			_, _ = fmt.Fprintf(a.out, "%c\n", r)
+			return i, nil
+		}
+	}
+}
+
+// Ask the user how to turn what we found in the repo into a Tiltfile.
+func BuildPlan(p Project, asker Asker) (Plan, error) {
+	plan := Plan{}
+
+	useCompose := len(p.DockerComposeFiles) > 0
+	hasK8s := len(p.K8sYAML) > 0 || len(p.HelmCharts) > 0
+	if useCompose && hasK8s {
+		choice, err := asker.Choose("We found both Kubernetes config and docker-compose files. How do you want to run your app?",
+			[]string{"Kubernetes", "docker-compose"})
+		if err != nil {
+			return Plan{}, err
+		}
+		useCompose = choice == 1
+	}
+
+	// docker-compose builds its own images, so we only need to point Tilt at the files.
+	if useCompose {
+		plan.DockerComposeFiles = p.DockerComposeFiles
+		return plan, nil
+	}
+
+	var workloads []WorkloadInfo
+	for _, y := range p.K8sYAML {
+		ok, err := asker.Confirm(fmt.Sprintf("Deploy %s (%s)?", y.Path, describeWorkloads(y.Workloads)), true)
+		if err != nil {
+			return Plan{}, err
+		}
+		if ok {
+			plan.K8sYAML = append(plan.K8sYAML, y.Path)
+			workloads = append(workloads, y.Workloads...)
+		}
+	}
+
+	for _, chart := range p.HelmCharts {
+		ok, err := asker.Confirm(fmt.Sprintf("Deploy Helm chart %s?", chart), true)
+		if err != nil {
+			return Plan{}, err
+		}
+		if ok {
+			plan.HelmCharts = append(plan.HelmCharts, chart)
+		}
+	}
+
+	images, err := matchImages(p, workloads, asker)
+	if err != nil {
+		return Plan{}, err
+	}
+	plan.Images = images
+
+	var forwards []WorkloadInfo
+	for _, w := range workloads {
+		if w.Port > 0 {
+			forwards = append(forwards, w)
+		}
+	}
+	if len(forwards) > 0 {
+		ok, err := asker.Confirm(fmt.Sprintf("Forward ports to localhost for %s?", describeWorkloads(forwards)), true)
+		if err != nil {
+			return Plan{}, err
+		}
+		if ok {
+			plan.PortForwards = forwards
+		}
+	}
+
+	return plan, nil
+}
+
+// Figure out which image each Dockerfile builds, and how to live-update it.
+func matchImages(p Project, workloads []WorkloadInfo, asker Asker) ([]ImagePlan, error) {
+	var unmatched []string
+	seen := map[string]bool{}
+	for _, w := range workloads {
+		for _, image := range w.Images {
+			if !seen[image] {
+				seen[image] = true
+				unmatched = append(unmatched, image)
+			}
+		}
+	}
+
+	var result []ImagePlan
+	for _, df := range p.Dockerfiles {
+		if len(unmatched) == 0 {
+			break
+		}
+
+		ref := ""
+		if guess := guessImage(p.Dir, df, unmatched); guess != "" {
+			ok, err := asker.Confirm(fmt.Sprintf("Build image %s from %s?", guess, df.Path), true)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				ref = guess
+			}
+		}
+
+		if ref == "" {
+			options := append([]string{"Don't build it"}, unmatched...)
+			choice, err := asker.Choose(fmt.Sprintf("Which image does %s build?", df.Path), options)
+			if err != nil {
+				return nil, err
+			}
+			if choice == 0 {
+				continue
+			}
+			ref = options[choice]
+		}
+		unmatched = removeString(unmatched, ref)
+
+		image := ImagePlan{Ref: ref, Context: df.Context, Dockerfile: df.Path}
+		steps := SuggestLiveUpdate(p.Dir, df)
+		if len(steps) > 0 {
+			ok, err := asker.Confirm(fmt.Sprintf("Live-update %s (%s) instead of rebuilding on every change?", ref, steps[0]), true)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				image.LiveUpdate = steps
+			}
+		}
+		result = append(result, image)
+	}
+	return result, nil
+}
+
+// Guess which image a Dockerfile builds, by matching the image name
+// against the name of the Dockerfile or its directory.
+func guessImage(repoDir string, df DockerfileInfo, images []string) string {
+	candidates := []string{}
+	base := path.Base(df.Path)
+	if strings.HasPrefix(base, "Dockerfile.") {
+		candidates = append(candidates, strings.TrimPrefix(base, "Dockerfile."))
+	} else if strings.HasSuffix(base, ".Dockerfile") {
+		candidates = append(candidates, strings.TrimSuffix(base, ".Dockerfile"))
+	}
+	if df.Context == "." {
+		candidates = append(candidates, filepath.Base(repoDir))
+	} else {
+		candidates = append(candidates, path.Base(df.Context))
+	}
+
+	for _, candidate := range candidates {
+		for _, image := range images {
+			if path.Base(image) == candidate {
+				return image
+			}
+		}
+	}
+
+	// If there's only one Dockerfile and one image, they probably go together.
+	if len(images) == 1 && df.Context == "." {
+		return images[0]
+	}
+	return ""
+}
+
+func describeWorkloads(workloads []WorkloadInfo) string {
+	if len(workloads) == 0 {
+		return "no workloads"
+	}
+	names := make([]string, 0, len(workloads))
+	for _, w := range workloads {
+		names = append(names, w.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
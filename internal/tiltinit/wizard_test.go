@@ -0,0 +1,105 @@
+package tiltinit
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeAsker(answers string) (TerminalAsker, *bytes.Buffer) {
+	runes := []rune(answers)
+	out := &bytes.Buffer{}
+	readRune := func() (rune, error) {
+		if len(runes) == 0 {
+			return 0, io.EOF
+		}
+		r := runes[0]
+		runes = runes[1:]
+		return r, nil
+	}
+	return TerminalAsker{readRune: readRune, out: out}, out
+}
+
+func TestTerminalAskerConfirm(t *testing.T) {
+	// Unrecognized keys are ignored.
+	asker, out := newFakeAsker("xn\r")
+
+	ok, err := asker.Confirm("Deploy?", true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "Deploy? [Y/n] n\n", out.String())
+
+	ok, err = asker.Confirm("Deploy?", true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = asker.Confirm("Deploy?", true)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTerminalAskerChoose(t *testing.T) {
+	asker, out := newFakeAsker("92")
+
+	i, err := asker.Choose("Which one?", []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.Contains(t, out.String(), "  (2) b\n")
+}
+
+func webProject(dir string) Project {
+	return Project{
+		Dir: dir,
+		Dockerfiles: []DockerfileInfo{
+			{Path: "./web/Dockerfile", Context: "./web"},
+			{Path: "./tools/Dockerfile", Context: "./tools"},
+		},
+		K8sYAML: []K8sYAMLInfo{
+			{
+				Path: "./k8s.yaml",
+				Workloads: []WorkloadInfo{
+					{Name: "web", Kind: "Deployment", Images: []string{"gcr.io/my-project/web"}, Port: 8000},
+					{Name: "api", Kind: "Deployment", Images: []string{"api-server"}},
+				},
+			},
+		},
+		DockerComposeFiles: []string{"./docker-compose.yml"},
+	}
+}
+
+func TestBuildPlanDefaults(t *testing.T) {
+	plan, err := BuildPlan(webProject("/nonexistent"), DefaultAsker{})
+	require.NoError(t, err)
+
+	assert.Empty(t, plan.DockerComposeFiles)
+	assert.Equal(t, []string{"./k8s.yaml"}, plan.K8sYAML)
+	assert.Equal(t, []ImagePlan{
+		{Ref: "gcr.io/my-project/web", Context: "./web", Dockerfile: "./web/Dockerfile"},
+	}, plan.Images)
+	assert.Equal(t, []WorkloadInfo{
+		{Name: "web", Kind: "Deployment", Images: []string{"gcr.io/my-project/web"}, Port: 8000},
+	}, plan.PortForwards)
+}
+
+func TestBuildPlanChooseImage(t *testing.T) {
+	// Kubernetes, deploy k8s.yaml, build web, tools builds the second unmatched image, no port forwards.
+	asker, _ := newFakeAsker("1yy2n")
+	plan, err := BuildPlan(webProject("/nonexistent"), asker)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ImagePlan{
+		{Ref: "gcr.io/my-project/web", Context: "./web", Dockerfile: "./web/Dockerfile"},
+		{Ref: "api-server", Context: "./tools", Dockerfile: "./tools/Dockerfile"},
+	}, plan.Images)
+	assert.Empty(t, plan.PortForwards)
+}
+
+func TestBuildPlanDockerCompose(t *testing.T) {
+	asker, _ := newFakeAsker("2")
+	plan, err := BuildPlan(webProject("/nonexistent"), asker)
+	require.NoError(t, err)
+
+	assert.Equal(t, Plan{DockerComposeFiles: []string{"./docker-compose.yml"}}, plan)
+}
@@ -0,0 +1,34 @@
+// +build linux
+
+package watch
+
+import (
+	"syscall"
+)
+
+// Filesystem magic numbers from statfs(2) for filesystems where
+// inotify events for changes made by other hosts (or by the VM host)
+// never arrive.
+//
+// We leave out FUSE: most FUSE mounts (like encrypted home directories and
+// local overlays) deliver events fine. Users on a network FUSE mount,
+// like sshfs, can set TILT_WATCH_POLL=always.
+var networkFilesystemTypes = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x01021997: "9p",
+	0x6a656a63: "virtiofs",
+}
+
+// Returns the name of the network filesystem that the given path lives on,
+// or the empty string if it's a local filesystem (or we can't tell).
+func networkFilesystemType(path string) string {
+	var buf syscall.Statfs_t
+	err := syscall.Statfs(path, &buf)
+	if err != nil {
+		return ""
+	}
+	return networkFilesystemTypes[uint32(buf.Type)]
+}
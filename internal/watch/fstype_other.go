@@ -0,0 +1,9 @@
+// +build !linux
+
+package watch
+
+// FSEvents and ReadDirectoryChangesW report changes on network mounts well enough
+// that we don't try to detect them.
+func networkFilesystemType(path string) string {
+	return ""
+}
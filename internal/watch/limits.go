@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const RaiseLimitsEnvVar = "TILT_WATCH_RAISE_LIMITS"
+
+// Whether Tilt should raise its own soft limit on open files
+// when it looks too low for what we're watching.
+//
+// Defaults to true. We never go above the hard limit.
+func DesiredRaiseLimits() bool {
+	envVar := os.Getenv(RaiseLimitsEnvVar)
+	if envVar != "" {
+		raise, err := strconv.ParseBool(envVar)
+		if err == nil {
+			return raise
+		}
+	}
+	return true
+}
+
+// Open files we expect to need for everything besides file watching:
+// the API server, log streams, port forwards, docker and kubectl clients.
+const baseOpenFiles = 256
+
+// Each watcher holds a few file descriptors of its own
+// (on Linux, an inotify instance plus an epoll and a wakeup pipe).
+const openFilesPerWatcher = 3
+
+// Warn when we expect to use more than this fraction of a limit.
+const limitWarnFraction = 0.8
+
+// We stop counting directories after this many. If we've found this many,
+// we already know whether we're close to the limit.
+const maxCountedDirs = 1 << 20
+
+// Where Linux keeps the inotify limits. Swapped out in tests.
+var inotifyLimitsDir = "/proc/sys/fs/inotify"
+
+// Operating system limits that file watching can run into.
+//
+// Zero means we couldn't read the limit, or it doesn't apply on this platform.
+type Limits struct {
+	OpenFiles    uint64 // The soft limit on open files (RLIMIT_NOFILE).
+	OpenFilesMax uint64 // The hard limit on open files.
+
+	InotifyWatches   int // fs.inotify.max_user_watches
+	InotifyInstances int // fs.inotify.max_user_instances
+}
+
+// Reads the limits for this process.
+func ReadLimits() Limits {
+	limits := readOpenFilesLimits()
+	limits.InotifyWatches = readInotifyLimit("max_user_watches")
+	limits.InotifyInstances = readInotifyLimit("max_user_instances")
+	return limits
+}
+
+func readInotifyLimit(name string) int {
+	contents, err := ioutil.ReadFile(filepath.Join(inotifyLimitsDir, name))
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// How much file watching we expect to do.
+type Usage struct {
+	// Each watcher is a separate inotify instance on Linux.
+	Watchers int
+
+	// Each directory is a separate inotify watch on Linux.
+	Dirs int
+}
+
+// How many open files we expect to need.
+func (u Usage) OpenFiles() uint64 {
+	return uint64(baseOpenFiles + openFilesPerWatcher*u.Watchers)
+}
+
+// The open files limit we'd like to have, with room to grow.
+func (u Usage) SuggestedOpenFiles() uint64 {
+	return uint64(suggestedLimit(int(u.OpenFiles()), 10240))
+}
+
+// Counts the directories that a watcher on paths would watch,
+// skipping directories that are ignored entirely.
+//
+// Stops counting at limit, if limit is positive.
+func CountWatchedDirs(paths []string, ignore PathMatcher, limit int) int {
+	if limit <= 0 {
+		limit = maxCountedDirs
+	}
+
+	count := 0
+	for _, root := range dedupePathsForRecursiveWatcher(paths) {
+		_ = walk(root, DesiredFollowSymlinks(), func(path string, info os.FileInfo, err error) error {
+			if err != nil || count >= limit {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if path != root {
+				skip, err := ignore.MatchesEntireDir(path)
+				if err == nil && skip {
+					return filepath.SkipDir
+				}
+			}
+			count++
+			return nil
+		})
+		if count >= limit {
+			break
+		}
+	}
+	return count
+}
+
+// Compares the limits against what we expect to use. Returns a warning for
+// each limit that's too low, with instructions for how to raise it.
+//
+// goos is the platform to give fix commands for (usually runtime.GOOS).
+func CheckLimits(limits Limits, usage Usage, goos string) []string {
+	var warnings []string
+
+	// Only the Linux watcher uses inotify. In poll mode, nothing does.
+	if goos == "linux" && DesiredPollMode() != PollModeAlways {
+		if nearLimit(usage.Dirs, limits.InotifyWatches) {
+			want := suggestedLimit(usage.Dirs, 524288)
+			warnings = append(warnings, fmt.Sprintf(
+				"Tilt is watching about %d directories, but this machine only allows %d inotify watches. "+
+					"File changes may be missed, or watching may fail with \"no space left on device\". "+
+					"To raise the limit, run:\n"+
+					"  sudo sysctl fs.inotify.max_user_watches=%d\n"+
+					"To keep it after a reboot, add this line to /etc/sysctl.conf:\n"+
+					"  fs.inotify.max_user_watches=%d\n"+
+					"Or add the directories you don't need to .tiltignore.",
+				usage.Dirs, limits.InotifyWatches, want, want))
+		}
+
+		if nearLimit(usage.Watchers, limits.InotifyInstances) {
+			want := suggestedLimit(usage.Watchers, 512)
+			warnings = append(warnings, fmt.Sprintf(
+				"Tilt needs about %d file watchers, but this machine only allows %d inotify instances. "+
+					"Watching may fail with \"too many open files\". "+
+					"To raise the limit, run:\n"+
+					"  sudo sysctl fs.inotify.max_user_instances=%d\n"+
+					"To keep it after a reboot, add this line to /etc/sysctl.conf:\n"+
+					"  fs.inotify.max_user_instances=%d",
+				usage.Watchers, limits.InotifyInstances, want, want))
+		}
+	}
+
+	need := usage.OpenFiles()
+	if limits.OpenFiles != 0 && float64(need) > limitWarnFraction*float64(limits.OpenFiles) {
+		want := usage.SuggestedOpenFiles()
+		msg := fmt.Sprintf(
+			"Tilt may need about %d open files, but its limit is %d. "+
+				"Builds and file watching may start failing with \"too many open files\". ",
+			need, limits.OpenFiles)
+		if limits.OpenFilesMax != 0 && want > limits.OpenFilesMax {
+			msg += openFilesMaxFix(goos, want)
+		} else {
+			msg += fmt.Sprintf("To raise the limit, run this in the shell you start Tilt from:\n"+
+				"  ulimit -n %d", want)
+		}
+		warnings = append(warnings, msg)
+	}
+
+	return warnings
+}
+
+// How to raise the hard limit on open files, which needs root.
+func openFilesMaxFix(goos string, want uint64) string {
+	switch goos {
+	case "darwin":
+		return fmt.Sprintf("To raise the limit, run:\n"+
+			"  sudo launchctl limit maxfiles %d unlimited\n"+
+			"then run `ulimit -n %d` in the shell you start Tilt from.", want, want)
+	case "linux":
+		return fmt.Sprintf("To raise the limit, add these lines to /etc/security/limits.conf:\n"+
+			"  *  soft  nofile  %d\n"+
+			"  *  hard  nofile  %d\n"+
+			"then log in again.", want, want)
+	}
+	return fmt.Sprintf("Ask your administrator to raise the limit on open files to at least %d.", want)
+}
+
+func nearLimit(used, limit int) bool {
+	return limit > 0 && float64(used) > limitWarnFraction*float64(limit)
+}
+
+// Suggests a new limit with room to grow, and no smaller than
+// a commonly recommended value.
+func suggestedLimit(used, recommended int) int {
+	want := recommended
+	for want < 2*used {
+		want *= 2
+	}
+	return want
+}
@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestReadInotifyLimits(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	orig := inotifyLimitsDir
+	defer func() { inotifyLimitsDir = orig }()
+	inotifyLimitsDir = f.Path()
+
+	f.WriteFile("max_user_watches", "8192\n")
+	f.WriteFile("max_user_instances", "garbage")
+	assert.Equal(t, 8192, readInotifyLimit("max_user_watches"))
+	assert.Equal(t, 0, readInotifyLimit("max_user_instances"))
+	assert.Equal(t, 0, readInotifyLimit("missing"))
+}
+
+func TestCountWatchedDirs(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("src/a/main.go", "")
+	f.WriteFile("src/b/main.go", "")
+	f.WriteFile("src/node_modules/left-pad/index.js", "")
+	f.WriteFile("docs/index.md", "")
+
+	ignore, err := dockerignore.NewDockerPatternMatcher(f.Path(), []string{"src/node_modules"})
+	require.NoError(t, err)
+
+	// src, src/a, src/b. src/a is inside src, so we don't count it twice.
+	assert.Equal(t, 3, CountWatchedDirs([]string{f.JoinPath("src"), f.JoinPath("src", "a")}, ignore, 0))
+	assert.Equal(t, 5, CountWatchedDirs([]string{f.JoinPath("src")}, EmptyMatcher{}, 0))
+	assert.Equal(t, 2, CountWatchedDirs([]string{f.JoinPath("src")}, EmptyMatcher{}, 2))
+	assert.Equal(t, 0, CountWatchedDirs([]string{f.JoinPath("missing")}, EmptyMatcher{}, 0))
+}
+
+func TestCheckLimitsOK(t *testing.T) {
+	limits := Limits{OpenFiles: 10240, OpenFilesMax: 10240, InotifyWatches: 524288, InotifyInstances: 512}
+	assert.Empty(t, CheckLimits(limits, Usage{Watchers: 10, Dirs: 1000}, "linux"))
+
+	// Unknown limits are never a problem.
+	assert.Empty(t, CheckLimits(Limits{}, Usage{Watchers: 10, Dirs: 1000}, "linux"))
+}
+
+func TestCheckLimitsInotifyWatches(t *testing.T) {
+	limits := Limits{OpenFiles: 10240, InotifyWatches: 8192, InotifyInstances: 512}
+	warnings := CheckLimits(limits, Usage{Watchers: 2, Dirs: 8000}, "linux")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "about 8000 directories")
+	assert.Contains(t, warnings[0], "sudo sysctl fs.inotify.max_user_watches=524288")
+
+	// Other platforms don't use inotify.
+	assert.Empty(t, CheckLimits(limits, Usage{Watchers: 2, Dirs: 8000}, "darwin"))
+}
+
+func TestCheckLimitsInotifyWatchesPolling(t *testing.T) {
+	orig := os.Getenv(PollModeEnvVar)
+	defer func() { _ = os.Setenv(PollModeEnvVar, orig) }()
+	_ = os.Setenv(PollModeEnvVar, string(PollModeAlways))
+
+	limits := Limits{OpenFiles: 10240, InotifyWatches: 8192, InotifyInstances: 512}
+	assert.Empty(t, CheckLimits(limits, Usage{Watchers: 2, Dirs: 8000}, "linux"))
+}
+
+func TestCheckLimitsInotifyInstances(t *testing.T) {
+	limits := Limits{OpenFiles: 10240, InotifyWatches: 524288, InotifyInstances: 128}
+	warnings := CheckLimits(limits, Usage{Watchers: 300, Dirs: 1000}, "linux")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "sudo sysctl fs.inotify.max_user_instances=1024")
+}
+
+func TestCheckLimitsOpenFilesSoft(t *testing.T) {
+	limits := Limits{OpenFiles: 256, OpenFilesMax: 65536}
+	warnings := CheckLimits(limits, Usage{Watchers: 10}, "darwin")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "limit is 256")
+	assert.Contains(t, warnings[0], "ulimit -n 10240")
+}
+
+func TestCheckLimitsOpenFilesHard(t *testing.T) {
+	limits := Limits{OpenFiles: 256, OpenFilesMax: 256}
+
+	warnings := CheckLimits(limits, Usage{Watchers: 10}, "darwin")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "sudo launchctl limit maxfiles 10240 unlimited")
+
+	warnings = CheckLimits(limits, Usage{Watchers: 10}, "linux")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "/etc/security/limits.conf")
+}
@@ -0,0 +1,50 @@
+// +build !windows
+
+package watch
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// Darwin refuses soft limits above OPEN_MAX, even when the hard limit is unlimited.
+const darwinMaxOpenFiles = 10240
+
+func readOpenFilesLimits() Limits {
+	var rlimit syscall.Rlimit
+	err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+	if err != nil {
+		return Limits{}
+	}
+	return Limits{OpenFiles: uint64(rlimit.Cur), OpenFilesMax: uint64(rlimit.Max)}
+}
+
+// Raises the soft limit on open files to want, or as close to it as the
+// hard limit allows. Returns the new soft limit.
+//
+// Never lowers the limit.
+func RaiseOpenFilesLimit(want uint64) (uint64, error) {
+	var rlimit syscall.Rlimit
+	err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+	if err != nil {
+		return 0, err
+	}
+
+	cur := uint64(rlimit.Cur)
+	if runtime.GOOS == "darwin" && want > darwinMaxOpenFiles {
+		want = darwinMaxOpenFiles
+	}
+	if want > uint64(rlimit.Max) {
+		want = uint64(rlimit.Max)
+	}
+	if want <= cur {
+		return cur, nil
+	}
+
+	rlimit.Cur = want
+	err = syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+	if err != nil {
+		return cur, err
+	}
+	return want, nil
+}
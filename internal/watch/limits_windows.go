@@ -0,0 +1,12 @@
+// +build windows
+
+package watch
+
+// Windows doesn't have a per-process limit on open files that we can see.
+func readOpenFilesLimits() Limits {
+	return Limits{}
+}
+
+func RaiseOpenFilesLimit(want uint64) (uint64, error) {
+	return 0, nil
+}
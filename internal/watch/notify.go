@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
@@ -45,6 +46,15 @@ type Notify interface {
 	Errors() chan error
 }
 
+// Implemented by watchers that can't rely on the OS to deliver file change
+// events, so that we can tell the user why their builds are slow to start.
+type DegradedNotify interface {
+	Notify
+
+	// Explains why the watcher is degraded, or returns the empty string if it isn't.
+	DegradedReason() string
+}
+
 // When we specify directories to watch, we often want to
 // ignore some subset of the files under those directories.
 //
@@ -69,9 +79,94 @@ func (EmptyMatcher) MatchesEntireDir(f string) (bool, error) { return false, nil
 var _ PathMatcher = EmptyMatcher{}
 
 func NewWatcher(paths []string, ignore PathMatcher, l logger.Logger) (Notify, error) {
+	mounts := DesiredRemoteMounts(l)
+	if len(mounts) > 0 {
+		newLocal := func(paths []string) (Notify, error) {
+			return newLocalWatcher(paths, ignore, l)
+		}
+		return newWatcherWithRemotes(paths, ignore, l, mounts, newLocal, startSSHAgent)
+	}
+	return newLocalWatcher(paths, ignore, l)
+}
+
+func newLocalWatcher(paths []string, ignore PathMatcher, l logger.Logger) (Notify, error) {
+	switch DesiredPollMode() {
+	case PollModeAlways:
+		return newPollingWatcher(paths, ignore, l, DesiredPollInterval())
+	case PollModeAuto:
+		path, fsType := detectNetworkFilesystem(paths)
+		if fsType != "" {
+			reason := fmt.Sprintf("%s is on a %s filesystem, which doesn't deliver file change events. "+
+				"Falling back to polling for changes every %s, which is slower and uses more CPU. "+
+				"To disable polling, set %s=%s",
+				path, fsType, DesiredPollInterval(), PollModeEnvVar, PollModeNever)
+			l.Warnf("%s", reason)
+			w, err := newPollingWatcher(paths, ignore, l, DesiredPollInterval())
+			if err != nil {
+				return nil, err
+			}
+			w.degradedReason = reason
+			return w, nil
+		}
+	}
 	return newWatcher(paths, ignore, l)
 }
 
+const FollowSymlinksEnvVar = "TILT_WATCH_FOLLOW_SYMLINKS"
+
+// Whether the watcher should descend into symlinked directories.
+//
+// Defaults to true. Symlinks at the root of a watch are always followed.
+func DesiredFollowSymlinks() bool {
+	envVar := os.Getenv(FollowSymlinksEnvVar)
+	if envVar != "" {
+		follow, err := strconv.ParseBool(envVar)
+		if err == nil {
+			return follow
+		}
+	}
+	return true
+}
+
+type PollMode string
+
+const (
+	// Poll only when the watched paths are on a network filesystem.
+	PollModeAuto PollMode = "auto"
+
+	// Always poll, even on local filesystems.
+	PollModeAlways PollMode = "always"
+
+	// Never poll, even if we think events won't arrive.
+	PollModeNever PollMode = "never"
+)
+
+const PollModeEnvVar = "TILT_WATCH_POLL"
+const PollIntervalEnvVar = "TILT_WATCH_POLL_INTERVAL"
+
+const defaultPollInterval = 2 * time.Second
+
+func DesiredPollMode() PollMode {
+	switch PollMode(strings.ToLower(os.Getenv(PollModeEnvVar))) {
+	case PollModeAlways:
+		return PollModeAlways
+	case PollModeNever:
+		return PollModeNever
+	}
+	return PollModeAuto
+}
+
+func DesiredPollInterval() time.Duration {
+	envVar := os.Getenv(PollIntervalEnvVar)
+	if envVar != "" {
+		interval, err := time.ParseDuration(envVar)
+		if err == nil && interval > 0 {
+			return interval
+		}
+	}
+	return defaultPollInterval
+}
+
 const WindowsBufferSizeEnvVar = "TILT_WATCH_WINDOWS_BUFFER_SIZE"
 
 const defaultBufferSize int = 65536
@@ -380,6 +380,36 @@ func TestMoveAndReplace(t *testing.T) {
 	f.assertEvents(file)
 }
 
+func TestRenameDirectory(t *testing.T) {
+	f := newNotifyFixture(t)
+	defer f.tearDown()
+
+	root := f.paths[0]
+	oldFile := filepath.Join(root, "old", "sub", "a")
+	f.WriteFile(oldFile, "a")
+	f.fsync()
+	f.events = nil
+
+	err := os.Rename(filepath.Join(root, "old"), filepath.Join(root, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.fsync()
+	f.events = nil
+
+	// Changes in the renamed directory should be reported at the new path.
+	newFile := filepath.Join(root, "new", "sub", "b")
+	f.WriteFile(newFile, "b")
+	f.assertEvents(newFile)
+
+	// The watches on the old directories should be gone.
+	expectedWatches := 3
+	if isRecursiveWatcher() {
+		expectedWatches = 1
+	}
+	assert.Equal(t, expectedWatches, int(numberOfWatches.Value()))
+}
+
 func TestWatchBothDirAndFile(t *testing.T) {
 	f := newNotifyFixture(t)
 	defer f.tearDown()
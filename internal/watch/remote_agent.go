@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Watches paths on this machine and reports changes to a remote watcher
+// on the other end of out.
+//
+// Runs until the context is done, the watcher fails, or in is closed
+// (which is how we notice that the remote watcher has gone away).
+func ServeRemoteAgent(ctx context.Context, paths []string, in io.Reader, out io.Writer, l logger.Logger) error {
+	absPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		absPaths = append(absPaths, abs)
+	}
+
+	// The remote watcher applies its own ignores, which refer to paths
+	// on its machine.
+	notify, err := newLocalWatcher(absPaths, EmptyMatcher{}, l)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = notify.Close() }()
+
+	err = notify.Start()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, in)
+		cancel()
+	}()
+
+	w := bufio.NewWriter(out)
+	_, err = fmt.Fprintln(w, remoteAgentReady)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-notify.Errors():
+			if !ok {
+				return nil
+			}
+			return err
+		case e, ok := <-notify.Events():
+			if !ok {
+				return nil
+			}
+			_, err := fmt.Fprintf(w, "%s%s\n", remoteAgentChangePrefix, filepath.ToSlash(e.Path()))
+			if err == nil {
+				err = w.Flush()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
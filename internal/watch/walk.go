@@ -0,0 +1,131 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// walk is like filepath.Walk, but can optionally descend into symlinked directories.
+//
+// When following symlinks, each directory is visited at most once (keyed by
+// its resolved path), so symlink cycles terminate and a directory that's
+// reachable through two different links is only reported once.
+//
+// Paths passed to walkFn are always the un-resolved paths under root,
+// so that file events line up with the paths the user asked us to watch.
+func walk(root string, followSymlinks bool, walkFn filepath.WalkFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+
+	// The root was explicitly requested, so we always resolve it,
+	// even if we're not following symlinks underneath it.
+	if info.Mode()&os.ModeSymlink != 0 {
+		targetInfo, err := os.Stat(root)
+		if err == nil {
+			info = targetInfo
+		}
+	}
+
+	w := &walker{
+		followSymlinks: followSymlinks,
+		visited:        make(map[string]bool),
+		walkFn:         walkFn,
+	}
+	err = w.walk(root, info)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	followSymlinks bool
+	visited        map[string]bool
+	walkFn         filepath.WalkFunc
+}
+
+func (w *walker) walk(path string, info os.FileInfo) error {
+	if w.followSymlinks && info.Mode()&os.ModeSymlink != 0 {
+		// If the link points to a directory, treat it like a directory.
+		// Broken links and links to files are reported as-is.
+		targetInfo, err := os.Stat(path)
+		if err == nil && targetInfo.IsDir() {
+			info = targetInfo
+		}
+	}
+
+	if !info.IsDir() {
+		return w.walkFn(path, info, nil)
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return w.walkFn(path, info, err)
+	}
+	if w.visited[realPath] {
+		// We've already seen this directory through another path.
+		return nil
+	}
+	w.visited[realPath] = true
+
+	err = w.walkFn(path, info, nil)
+	if err != nil {
+		return err
+	}
+
+	names, err := readDirNames(path)
+	if err != nil {
+		return w.walkFn(path, info, err)
+	}
+
+	for _, name := range names {
+		child := filepath.Join(path, name)
+		childInfo, err := os.Lstat(child)
+		if err != nil {
+			err = w.walkFn(child, childInfo, err)
+			if err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+
+		err = w.walk(child, childInfo)
+		if err != nil {
+			if err == filepath.SkipDir {
+				if childInfo.IsDir() || w.isDirLink(child, childInfo) {
+					continue
+				}
+				// Returning SkipDir on a file skips the rest of the parent directory,
+				// same as filepath.Walk.
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) isDirLink(path string, info os.FileInfo) bool {
+	if !w.followSymlinks || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	targetInfo, err := os.Stat(path)
+	return err == nil && targetInfo.IsDir()
+}
+
+func readDirNames(dirname string) ([]string, error) {
+	f, err := os.Open(dirname)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestWalkFollowsSymlinkedDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("target/a.txt", "a")
+	f.WriteFile("root/b.txt", "b")
+	require.NoError(t, os.Symlink(f.JoinPath("target"), f.JoinPath("root", "link")))
+
+	assert.Equal(t, []string{
+		f.JoinPath("root"),
+		f.JoinPath("root", "b.txt"),
+		f.JoinPath("root", "link"),
+		f.JoinPath("root", "link", "a.txt"),
+	}, walkPaths(t, f.JoinPath("root"), true))
+
+	assert.Equal(t, []string{
+		f.JoinPath("root"),
+		f.JoinPath("root", "b.txt"),
+		f.JoinPath("root", "link"),
+	}, walkPaths(t, f.JoinPath("root"), false))
+}
+
+func TestWalkSymlinkCycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("root/a/a.txt", "a")
+	require.NoError(t, os.Symlink(f.JoinPath("root"), f.JoinPath("root", "a", "parent")))
+
+	assert.Equal(t, []string{
+		f.JoinPath("root"),
+		f.JoinPath("root", "a"),
+		f.JoinPath("root", "a", "a.txt"),
+	}, walkPaths(t, f.JoinPath("root"), true))
+}
+
+func TestWalkSymlinkRootAlwaysFollowed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require admin on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("target/a.txt", "a")
+	require.NoError(t, os.Symlink(f.JoinPath("target"), f.JoinPath("link")))
+
+	assert.Equal(t, []string{
+		f.JoinPath("link"),
+		f.JoinPath("link", "a.txt"),
+	}, walkPaths(t, f.JoinPath("link"), false))
+}
+
+func walkPaths(t *testing.T, root string, followSymlinks bool) []string {
+	result := []string{}
+	err := walk(root, followSymlinks, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		result = append(result, path)
+		return nil
+	})
+	require.NoError(t, err)
+	return result
+}
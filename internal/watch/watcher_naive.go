@@ -28,11 +28,15 @@ type naiveNotify struct {
 	log    logger.Logger
 
 	isWatcherRecursive bool
+	followSymlinks     bool
 	watcher            *fsnotify.Watcher
 	events             chan fsnotify.Event
 	wrappedEvents      chan FileEvent
 	errors             chan error
 	numWatches         int64
+
+	// Paths that we've added to the fsnotify watcher.
+	watches map[string]bool
 }
 
 func (d *naiveNotify) Start() error {
@@ -90,7 +94,7 @@ func (d *naiveNotify) watchRecursively(dir string) error {
 		return errors.Wrapf(err, "watcher.Add(%q)", dir)
 	}
 
-	return filepath.Walk(dir, func(path string, mode os.FileInfo, err error) error {
+	return walk(dir, d.followSymlinks, func(path string, mode os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -142,6 +146,10 @@ func (d *naiveNotify) loop() {
 			continue
 		}
 
+		if e.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+			d.removeWatches(e.Name)
+		}
+
 		if e.Op&fsnotify.Create != fsnotify.Create {
 			if d.shouldNotify(e.Name) {
 				d.wrappedEvents <- FileEvent{e.Name}
@@ -156,12 +164,18 @@ func (d *naiveNotify) loop() {
 			continue
 		}
 
+		// If we're not following symlinks, a newly-created link is just a file.
+		if !d.followSymlinks && isSymlink(e.Name) {
+			if d.shouldNotify(e.Name) {
+				d.wrappedEvents <- FileEvent{e.Name}
+			}
+			continue
+		}
+
 		// If the watcher is not recursive, we have to walk the tree
 		// and add watches manually. We fire the event while we're walking the tree.
 		// because it's a bit more elegant that way.
-		//
-		// TODO(dbentley): if there's a delete should we call d.watcher.Remove to prevent leaking?
-		err := filepath.Walk(e.Name, func(path string, mode os.FileInfo, err error) error {
+		err := walk(e.Name, d.followSymlinks, func(path string, mode os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -170,8 +184,6 @@ func (d *naiveNotify) loop() {
 				d.wrappedEvents <- FileEvent{path}
 			}
 
-			// TODO(dmiller): symlinks 😭
-
 			shouldWatch := false
 			if mode.IsDir() {
 				// watch directories unless we can skip them entirely
@@ -249,11 +261,43 @@ func (d *naiveNotify) add(path string) error {
 	if err != nil {
 		return err
 	}
+	if d.watches[path] {
+		return nil
+	}
+	d.watches[path] = true
 	d.numWatches++
 	numberOfWatches.Add(1)
 	return nil
 }
 
+// When a directory is deleted or renamed, stop watching it and everything
+// under it.
+//
+// A renamed directory keeps its watches, but fsnotify reports its events
+// under the old path, so changes in the renamed directory would look like
+// changes to files that no longer exist. If something is created at the
+// new path (or at the old path again), we get a Create event and re-watch it.
+func (d *naiveNotify) removeWatches(root string) {
+	// We only watch a directory's children if we watch the directory,
+	// so there's nothing to do for a file or an unwatched directory.
+	if d.isWatcherRecursive || !d.watches[root] {
+		return
+	}
+
+	for path := range d.watches {
+		if path != root && !ospath.IsChild(root, path) {
+			continue
+		}
+
+		// fsnotify may have already dropped the watch if the
+		// directory was deleted, so ignore the error.
+		_ = d.watcher.Remove(path)
+		delete(d.watches, path)
+		d.numWatches--
+		numberOfWatches.Add(-1)
+	}
+}
+
 func newWatcher(paths []string, ignore PathMatcher, l logger.Logger) (*naiveNotify, error) {
 	if ignore == nil {
 		return nil, fmt.Errorf("newWatcher: ignore is nil")
@@ -290,6 +334,8 @@ func newWatcher(paths []string, ignore PathMatcher, l logger.Logger) (*naiveNoti
 		wrappedEvents:      wrappedEvents,
 		errors:             fsw.Errors,
 		isWatcherRecursive: isWatcherRecursive,
+		followSymlinks:     DesiredFollowSymlinks(),
+		watches:            make(map[string]bool),
 	}
 
 	return wmw, nil
@@ -305,4 +351,9 @@ func isDir(pth string) (bool, error) {
 	return fi.IsDir(), nil
 }
 
+func isSymlink(pth string) bool {
+	fi, err := os.Lstat(pth)
+	return err == nil && fi.Mode()&os.ModeSymlink != 0
+}
+
 var _ Notify = &naiveNotify{}
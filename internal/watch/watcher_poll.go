@@ -0,0 +1,246 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// A degraded file watcher that periodically walks the watched paths
+// and diffs what it sees against the previous walk.
+//
+// Used on filesystems where the OS doesn't deliver change notifications
+// (e.g., NFS, SMB, and some VM file-sharing mounts).
+type pollingNotify struct {
+	paths          []string
+	ignore         PathMatcher
+	log            logger.Logger
+	interval       time.Duration
+	followSymlinks bool
+
+	// Set when we poll because we detected a filesystem that doesn't
+	// deliver events, rather than because the user asked us to.
+	degradedReason string
+
+	events chan FileEvent
+	errors chan error
+	stop   chan struct{}
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+type pollStat struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+func (d *pollingNotify) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.paths) == 0 || d.started {
+		return nil
+	}
+	d.started = true
+
+	numberOfWatches.Add(int64(len(d.paths)))
+
+	snapshot := d.scan()
+	go d.loop(snapshot)
+	return nil
+}
+
+func (d *pollingNotify) loop(prev map[string]pollStat) {
+	defer close(d.done)
+	defer close(d.events)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+
+		next := d.scan()
+		for _, p := range diffSnapshots(prev, next) {
+			select {
+			case d.events <- NewFileEvent(p):
+			case <-d.stop:
+				return
+			}
+		}
+		prev = next
+	}
+}
+
+// Walk all the watched paths and record the stat info of every file we care about.
+func (d *pollingNotify) scan() map[string]pollStat {
+	result := make(map[string]pollStat)
+	for _, root := range d.paths {
+		err := walk(root, d.followSymlinks, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if info.IsDir() && path != root {
+				skip, err := d.ignore.MatchesEntireDir(path)
+				if err != nil {
+					return errors.Wrap(err, "pollingNotify")
+				}
+				if skip {
+					return filepath.SkipDir
+				}
+			}
+
+			ignore, err := d.ignore.Matches(path)
+			if err != nil {
+				d.log.Infof("Error matching path %q: %v", path, err)
+			} else if ignore {
+				return nil
+			}
+
+			result[path] = pollStat{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				mode:    info.Mode(),
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			d.log.Infof("Error polling %s: %v", root, err)
+		}
+	}
+
+	// We don't care when directories change at the root of a watch.
+	for _, root := range d.paths {
+		if st, ok := result[root]; ok && st.mode.IsDir() {
+			delete(result, root)
+		}
+	}
+	return result
+}
+
+// Returns every path that was created, modified, or deleted between two snapshots,
+// in sorted order.
+func diffSnapshots(prev, next map[string]pollStat) []string {
+	changed := []string{}
+	for p, n := range next {
+		o, ok := prev[p]
+		if !ok {
+			changed = append(changed, p)
+			continue
+		}
+
+		// Directory mtimes change whenever a child is added or removed,
+		// but we already report the child itself.
+		if n.mode.IsDir() && o.mode.IsDir() {
+			continue
+		}
+
+		if !n.modTime.Equal(o.modTime) || n.size != o.size || n.mode != o.mode {
+			changed = append(changed, p)
+		}
+	}
+	for p := range prev {
+		if _, ok := next[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func (d *pollingNotify) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	close(d.stop)
+	if d.started {
+		numberOfWatches.Add(int64(-len(d.paths)))
+		<-d.done
+	} else {
+		close(d.events)
+	}
+	close(d.errors)
+	return nil
+}
+
+func (d *pollingNotify) Events() chan FileEvent {
+	return d.events
+}
+
+func (d *pollingNotify) Errors() chan error {
+	return d.errors
+}
+
+func (d *pollingNotify) DegradedReason() string {
+	return d.degradedReason
+}
+
+func newPollingWatcher(paths []string, ignore PathMatcher, l logger.Logger, interval time.Duration) (*pollingNotify, error) {
+	if ignore == nil {
+		return nil, errors.New("newPollingWatcher: ignore is nil")
+	}
+
+	absPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "newPollingWatcher")
+		}
+		absPaths = append(absPaths, path)
+	}
+
+	return &pollingNotify{
+		paths:          dedupePathsForRecursiveWatcher(absPaths),
+		ignore:         ignore,
+		log:            l,
+		interval:       interval,
+		followSymlinks: DesiredFollowSymlinks(),
+		events:         make(chan FileEvent),
+		errors:         make(chan error),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}, nil
+}
+
+// Returns the first path that lives on a network filesystem and the type of that
+// filesystem, or empty strings if they're all on local filesystems.
+func detectNetworkFilesystem(paths []string) (string, string) {
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		ancestor, err := greatestExistingAncestor(abs)
+		if err != nil {
+			continue
+		}
+		fsType := networkFilesystemType(ancestor)
+		if fsType != "" {
+			return ancestor, fsType
+		}
+	}
+	return "", ""
+}
+
+var _ DegradedNotify = &pollingNotify{}
@@ -0,0 +1,112 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestPollMode(t *testing.T) {
+	orig := os.Getenv(PollModeEnvVar)
+	defer os.Setenv(PollModeEnvVar, orig)
+
+	os.Setenv(PollModeEnvVar, "")
+	assert.Equal(t, PollModeAuto, DesiredPollMode())
+
+	os.Setenv(PollModeEnvVar, "Always")
+	assert.Equal(t, PollModeAlways, DesiredPollMode())
+
+	os.Setenv(PollModeEnvVar, "never")
+	assert.Equal(t, PollModeNever, DesiredPollMode())
+
+	os.Setenv(PollModeEnvVar, "bogus")
+	assert.Equal(t, PollModeAuto, DesiredPollMode())
+}
+
+func TestPollInterval(t *testing.T) {
+	orig := os.Getenv(PollIntervalEnvVar)
+	defer os.Setenv(PollIntervalEnvVar, orig)
+
+	os.Setenv(PollIntervalEnvVar, "")
+	assert.Equal(t, defaultPollInterval, DesiredPollInterval())
+
+	os.Setenv(PollIntervalEnvVar, "-1s")
+	assert.Equal(t, defaultPollInterval, DesiredPollInterval())
+
+	os.Setenv(PollIntervalEnvVar, "500ms")
+	assert.Equal(t, 500*time.Millisecond, DesiredPollInterval())
+}
+
+func TestFollowSymlinks(t *testing.T) {
+	orig := os.Getenv(FollowSymlinksEnvVar)
+	defer os.Setenv(FollowSymlinksEnvVar, orig)
+
+	os.Setenv(FollowSymlinksEnvVar, "")
+	assert.True(t, DesiredFollowSymlinks())
+
+	os.Setenv(FollowSymlinksEnvVar, "false")
+	assert.False(t, DesiredFollowSymlinks())
+}
+
+func TestPollingWatcherCreateModifyDelete(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.WriteFile("root/existing.txt", "hello")
+	n := newTestPollingWatcher(t, f, EmptyMatcher{})
+	defer n.Close()
+
+	f.WriteFile("root/new.txt", "new")
+	assertNextPollEvents(t, n, f.JoinPath("root", "new.txt"))
+
+	f.WriteFile("root/existing.txt", "hello world")
+	assertNextPollEvents(t, n, f.JoinPath("root", "existing.txt"))
+
+	f.Rm("root/new.txt")
+	assertNextPollEvents(t, n, f.JoinPath("root", "new.txt"))
+}
+
+func TestPollingWatcherIgnore(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+
+	f.MkdirAll("root")
+	ignore, err := dockerignore.NewDockerPatternMatcher(f.JoinPath("root"), []string{"ignored"})
+	require.NoError(t, err)
+	n := newTestPollingWatcher(t, f, ignore)
+	defer n.Close()
+
+	f.WriteFile("root/ignored/a.txt", "a")
+	f.WriteFile("root/b.txt", "b")
+	assertNextPollEvents(t, n, f.JoinPath("root", "b.txt"))
+}
+
+func newTestPollingWatcher(t *testing.T, f *tempdir.TempDirFixture, ignore PathMatcher) *pollingNotify {
+	l := logger.NewLogger(logger.DebugLvl, bytes.NewBuffer(nil))
+	n, err := newPollingWatcher([]string{f.JoinPath("root")}, ignore, l, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	return n
+}
+
+func assertNextPollEvents(t *testing.T, n *pollingNotify, expected ...string) {
+	actual := []string{}
+	timeout := time.After(time.Second)
+	for len(actual) < len(expected) {
+		select {
+		case e := <-n.Events():
+			actual = append(actual, e.Path())
+		case <-timeout:
+			t.Fatalf("Timed out waiting for events. Expected: %v. Actual: %v", expected, actual)
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
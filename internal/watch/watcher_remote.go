@@ -0,0 +1,557 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Maps local directories to directories on another machine, e.g.,
+//
+//	TILT_WATCH_REMOTE=/mnt/src=me@laptop:/Users/me/src
+//
+// For when Tilt runs on a cloud workstation, but the sources live on your laptop
+// and are mounted with SSHFS (or similar). Edits on the laptop never reach
+// the workstation's inotify, so we ask a watch agent on the laptop instead.
+//
+// Separate multiple mounts with commas.
+const RemoteEnvVar = "TILT_WATCH_REMOTE"
+
+// The command used to reach the remote machine. The host is appended.
+const RemoteSSHEnvVar = "TILT_WATCH_REMOTE_SSH"
+
+// The command that runs the watch agent on the remote machine.
+const RemoteAgentEnvVar = "TILT_WATCH_REMOTE_AGENT"
+
+const defaultRemoteSSH = "ssh"
+const defaultRemoteAgent = "tilt alpha watch-agent"
+
+// If the connection to the agent drops, wait this long before reconnecting.
+const remoteReconnectDelay = 5 * time.Second
+
+// Lines in the watch agent protocol.
+//
+// The agent prints the ready line once it's watching, then one change line
+// for each file that changes.
+const remoteAgentReady = "ready"
+const remoteAgentChangePrefix = "change "
+
+type RemoteMount struct {
+	// An absolute path on this machine.
+	Local string
+
+	// The host to ssh to, e.g., me@laptop
+	Host string
+
+	// An absolute path on the remote machine.
+	Remote string
+}
+
+func (m RemoteMount) String() string {
+	return fmt.Sprintf("%s=%s:%s", m.Local, m.Host, m.Remote)
+}
+
+// Returns the remote path for a local path, and whether the local path is under this mount.
+func (m RemoteMount) toRemote(localPath string) (string, bool) {
+	if !ospathIsChild(m.Local, localPath) {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.Local, localPath)
+	if err != nil {
+		return "", false
+	}
+	return path.Join(m.Remote, filepath.ToSlash(rel)), true
+}
+
+// Returns the local path for a remote path, and whether the remote path is under this mount.
+func (m RemoteMount) toLocal(remotePath string) (string, bool) {
+	remotePath = path.Clean(remotePath)
+	if remotePath != m.Remote && !strings.HasPrefix(remotePath, strings.TrimSuffix(m.Remote, "/")+"/") {
+		return "", false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(remotePath, m.Remote), "/")
+	return filepath.Join(m.Local, filepath.FromSlash(rel)), true
+}
+
+func ospathIsChild(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func ParseRemoteMounts(s string) ([]RemoteMount, error) {
+	var result []RemoteMount
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		eq := strings.Index(part, "=")
+		if eq == -1 {
+			return nil, fmt.Errorf("%q: expected LOCAL_DIR=HOST:REMOTE_DIR", part)
+		}
+		local, remote := part[:eq], part[eq+1:]
+		colon := strings.Index(remote, ":")
+		if colon == -1 {
+			return nil, fmt.Errorf("%q: expected LOCAL_DIR=HOST:REMOTE_DIR", part)
+		}
+		host, remoteDir := remote[:colon], remote[colon+1:]
+
+		if !filepath.IsAbs(local) {
+			return nil, fmt.Errorf("%q: local dir must be an absolute path", part)
+		}
+		if host == "" {
+			return nil, fmt.Errorf("%q: missing host", part)
+		}
+		if !path.IsAbs(remoteDir) {
+			return nil, fmt.Errorf("%q: remote dir must be an absolute path", part)
+		}
+
+		result = append(result, RemoteMount{
+			Local:  filepath.Clean(local),
+			Host:   host,
+			Remote: path.Clean(remoteDir),
+		})
+	}
+	return result, nil
+}
+
+func DesiredRemoteMounts(l logger.Logger) []RemoteMount {
+	mounts, err := ParseRemoteMounts(os.Getenv(RemoteEnvVar))
+	if err != nil {
+		l.Warnf("Ignoring %s: %v", RemoteEnvVar, err)
+		return nil
+	}
+	return mounts
+}
+
+// Starts the watch agent and returns its stdout.
+//
+// The agent must stop (and its stdout must close) when the context is done.
+type remoteAgentStarter func(ctx context.Context, host string, remotePaths []string) (io.ReadCloser, error)
+
+// Starts the watch agent over ssh.
+func startSSHAgent(ctx context.Context, host string, remotePaths []string) (io.ReadCloser, error) {
+	ssh := strings.Fields(os.Getenv(RemoteSSHEnvVar))
+	if len(ssh) == 0 {
+		ssh = []string{defaultRemoteSSH}
+	}
+	agent := strings.Fields(os.Getenv(RemoteAgentEnvVar))
+	if len(agent) == 0 {
+		agent = strings.Fields(defaultRemoteAgent)
+	}
+
+	// ssh hands the command to the remote shell, so quote the paths.
+	args := append(ssh[1:], host)
+	args = append(args, agent...)
+	for _, p := range remotePaths {
+		args = append(args, shellQuote(p))
+	}
+
+	cmd := exec.CommandContext(ctx, ssh[0], args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	// The agent exits when its stdin closes, so that it doesn't outlive us
+	// if the connection drops.
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrapf(err, "running %s", ssh[0])
+	}
+
+	return &agentProcess{ReadCloser: stdout, stdin: stdin, cmd: cmd, stderr: stderr}, nil
+}
+
+type agentProcess struct {
+	io.ReadCloser
+	stdin  io.Closer
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (p *agentProcess) Close() error {
+	_ = p.stdin.Close()
+	_ = p.ReadCloser.Close()
+	err := p.cmd.Wait()
+	if err != nil {
+		msg := strings.TrimSpace(p.stderr.String())
+		if msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+	}
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// A file watcher that streams change events from a watch agent
+// on another machine.
+type remoteNotify struct {
+	mount  RemoteMount
+	paths  []string
+	ignore PathMatcher
+	log    logger.Logger
+	start  remoteAgentStarter
+
+	// The output of the agent we're currently connected to.
+	agentOutput io.ReadCloser
+
+	reconnectDelay time.Duration
+
+	events chan FileEvent
+	errors chan error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+func (d *remoteNotify) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.paths) == 0 || d.started {
+		return nil
+	}
+	d.started = true
+
+	numberOfWatches.Add(int64(len(d.paths)))
+
+	// If we can't reach the agent the first time, it's probably misconfigured,
+	// so fail loudly.
+	agent, err := d.connect()
+	if err != nil {
+		d.cancel()
+		close(d.done)
+		close(d.events)
+		return err
+	}
+
+	go d.loop(agent)
+	return nil
+}
+
+// Starts the agent and waits until it says it's ready.
+func (d *remoteNotify) connect() (*bufio.Scanner, error) {
+	remotePaths := make([]string, 0, len(d.paths))
+	for _, p := range d.paths {
+		remote, _ := d.mount.toRemote(p)
+		remotePaths = append(remotePaths, remote)
+	}
+
+	stdout, err := d.start(d.ctx, d.mount.Host, remotePaths)
+	if err != nil {
+		return nil, errors.Wrapf(err, "watching %s on %s", d.mount.Remote, d.mount.Host)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	if scanner.Scan() && scanner.Text() == remoteAgentReady {
+		d.agentOutput = stdout
+		return scanner, nil
+	}
+
+	err = stdout.Close()
+	if err == nil {
+		err = fmt.Errorf("agent exited before it was ready")
+	}
+	return nil, fmt.Errorf("watching %s on %s: %v\n"+
+		"Make sure Tilt is installed on %s, or set %s to the command that runs the watch agent there",
+		d.mount.Remote, d.mount.Host, err, d.mount.Host, RemoteAgentEnvVar)
+}
+
+func (d *remoteNotify) loop(scanner *bufio.Scanner) {
+	defer close(d.done)
+	defer close(d.events)
+
+	for {
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, remoteAgentChangePrefix) {
+				continue
+			}
+			local, ok := d.mount.toLocal(strings.TrimPrefix(line, remoteAgentChangePrefix))
+			if !ok || !d.shouldNotify(local) {
+				continue
+			}
+			select {
+			case d.events <- NewFileEvent(local):
+			case <-d.ctx.Done():
+				return
+			}
+		}
+
+		err := d.agentOutput.Close()
+		if d.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = scanner.Err()
+		}
+
+		// Connections to laptops drop all the time, so keep trying
+		// instead of bringing down Tilt.
+		for {
+			d.log.Warnf("Lost connection to the watch agent on %s (%v). Reconnecting in %s. "+
+				"Changes made in the meantime may be missed.", d.mount.Host, err, d.reconnectDelay)
+			select {
+			case <-time.After(d.reconnectDelay):
+			case <-d.ctx.Done():
+				return
+			}
+
+			scanner, err = d.connect()
+			if d.ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				d.log.Infof("Reconnected to the watch agent on %s", d.mount.Host)
+				break
+			}
+		}
+	}
+}
+
+func (d *remoteNotify) shouldNotify(p string) bool {
+	watched := false
+	for _, root := range d.paths {
+		if ospathIsChild(root, p) {
+			watched = true
+			break
+		}
+	}
+	if !watched {
+		return false
+	}
+
+	ignore, err := d.ignore.Matches(p)
+	if err != nil {
+		d.log.Infof("Error matching path %q: %v", p, err)
+	} else if ignore {
+		return false
+	}
+	return true
+}
+
+func (d *remoteNotify) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	d.cancel()
+	if d.started {
+		numberOfWatches.Add(int64(-len(d.paths)))
+		<-d.done
+	} else {
+		close(d.events)
+	}
+	close(d.errors)
+	return nil
+}
+
+func (d *remoteNotify) Events() chan FileEvent {
+	return d.events
+}
+
+func (d *remoteNotify) Errors() chan error {
+	return d.errors
+}
+
+func newRemoteWatcher(mount RemoteMount, paths []string, ignore PathMatcher, l logger.Logger, start remoteAgentStarter) (*remoteNotify, error) {
+	if ignore == nil {
+		return nil, errors.New("newRemoteWatcher: ignore is nil")
+	}
+
+	absPaths := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "newRemoteWatcher")
+		}
+		if _, ok := mount.toRemote(p); !ok {
+			return nil, fmt.Errorf("newRemoteWatcher: %s is not under %s", p, mount.Local)
+		}
+		absPaths = append(absPaths, p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &remoteNotify{
+		mount:          mount,
+		paths:          dedupePathsForRecursiveWatcher(absPaths),
+		ignore:         ignore,
+		log:            l,
+		start:          start,
+		reconnectDelay: remoteReconnectDelay,
+		events:         make(chan FileEvent),
+		errors:         make(chan error),
+		ctx:            ctx,
+		cancel:         cancel,
+		done:           make(chan struct{}),
+	}, nil
+}
+
+// Splits the paths between watchers for each remote mount and
+// a watcher for everything on this machine.
+func newWatcherWithRemotes(paths []string, ignore PathMatcher, l logger.Logger, mounts []RemoteMount,
+	newLocal func(paths []string) (Notify, error), start remoteAgentStarter) (Notify, error) {
+	var local []string
+	remote := make([][]string, len(mounts))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "newWatcherWithRemotes")
+		}
+		matched := false
+		for i, m := range mounts {
+			if _, ok := m.toRemote(abs); ok {
+				remote[i] = append(remote[i], abs)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			local = append(local, p)
+		}
+	}
+
+	var watchers []Notify
+	for i, m := range mounts {
+		if len(remote[i]) == 0 {
+			continue
+		}
+		w, err := newRemoteWatcher(m, remote[i], ignore, l, start)
+		if err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+	if len(local) > 0 || len(watchers) == 0 {
+		w, err := newLocal(local)
+		if err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+
+	if len(watchers) == 1 {
+		return watchers[0], nil
+	}
+	return newMultiNotify(watchers), nil
+}
+
+// Merges the events and errors of several watchers.
+type multiNotify struct {
+	watchers []Notify
+	events   chan FileEvent
+	errors   chan error
+	wg       sync.WaitGroup
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func newMultiNotify(watchers []Notify) *multiNotify {
+	m := &multiNotify{
+		watchers: watchers,
+		events:   make(chan FileEvent),
+		errors:   make(chan error),
+		stop:     make(chan struct{}),
+	}
+
+	// After we stop, keep draining the watchers so that they can shut down.
+	for _, w := range watchers {
+		m.wg.Add(2)
+		go func(w Notify) {
+			defer m.wg.Done()
+			for e := range w.Events() {
+				select {
+				case m.events <- e:
+				case <-m.stop:
+				}
+			}
+		}(w)
+		go func(w Notify) {
+			defer m.wg.Done()
+			for e := range w.Errors() {
+				select {
+				case m.errors <- e:
+				case <-m.stop:
+				}
+			}
+		}(w)
+	}
+	go func() {
+		m.wg.Wait()
+		close(m.events)
+		close(m.errors)
+	}()
+	return m
+}
+
+func (m *multiNotify) Start() error {
+	for i, w := range m.watchers {
+		err := w.Start()
+		if err != nil {
+			for _, started := range m.watchers[:i] {
+				_ = started.Close()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiNotify) Close() error {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	var result error
+	for _, w := range m.watchers {
+		err := w.Close()
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+func (m *multiNotify) Events() chan FileEvent {
+	return m.events
+}
+
+func (m *multiNotify) Errors() chan error {
+	return m.errors
+}
+
+var _ Notify = &remoteNotify{}
+var _ Notify = &multiNotify{}
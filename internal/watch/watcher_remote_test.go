@@ -0,0 +1,375 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestParseRemoteMounts(t *testing.T) {
+	mounts, err := ParseRemoteMounts("/mnt/src=me@laptop:/Users/me/src/, /mnt/other=desktop:/home/me")
+	require.NoError(t, err)
+	assert.Equal(t, []RemoteMount{
+		{Local: "/mnt/src", Host: "me@laptop", Remote: "/Users/me/src"},
+		{Local: "/mnt/other", Host: "desktop", Remote: "/home/me"},
+	}, mounts)
+
+	mounts, err = ParseRemoteMounts("")
+	require.NoError(t, err)
+	assert.Empty(t, mounts)
+
+	for _, bad := range []string{
+		"/mnt/src",
+		"/mnt/src=laptop",
+		"mnt/src=laptop:/src",
+		"/mnt/src=:/src",
+		"/mnt/src=laptop:src",
+	} {
+		_, err := ParseRemoteMounts(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRemoteMountPaths(t *testing.T) {
+	m := RemoteMount{Local: "/mnt/src", Host: "laptop", Remote: "/Users/me/src"}
+
+	remote, ok := m.toRemote("/mnt/src/app/main.go")
+	assert.True(t, ok)
+	assert.Equal(t, "/Users/me/src/app/main.go", remote)
+
+	remote, ok = m.toRemote("/mnt/src")
+	assert.True(t, ok)
+	assert.Equal(t, "/Users/me/src", remote)
+
+	_, ok = m.toRemote("/mnt/srcfoo/main.go")
+	assert.False(t, ok)
+
+	local, ok := m.toLocal("/Users/me/src/app/main.go")
+	assert.True(t, ok)
+	assert.Equal(t, "/mnt/src/app/main.go", local)
+
+	_, ok = m.toLocal("/Users/me/srcfoo/main.go")
+	assert.False(t, ok)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/src/my app'`, shellQuote("/src/my app"))
+	assert.Equal(t, `'/src/it'"'"'s'`, shellQuote("/src/it's"))
+}
+
+func TestRemoteWatcherEvents(t *testing.T) {
+	f := newRemoteFixture(t)
+	defer f.TearDown()
+
+	n := f.newWatcher(EmptyMatcher{}, f.local("app"))
+	require.NoError(t, n.Start())
+	defer n.Close()
+
+	assert.Equal(t, []string{"/Users/me/src/app"}, f.agent().paths)
+
+	f.agent().change("/Users/me/src/app/main.go")
+	f.agent().change("/Users/me/src/other/main.go")
+	f.agent().change("/Users/me/src/app/util.go")
+	f.assertEvents(n, f.local("app/main.go"), f.local("app/util.go"))
+}
+
+func TestRemoteWatcherIgnore(t *testing.T) {
+	f := newRemoteFixture(t)
+	defer f.TearDown()
+
+	ignore, err := dockerignore.NewDockerPatternMatcher(f.local(""), []string{"node_modules"})
+	require.NoError(t, err)
+	n := f.newWatcher(ignore, f.local(""))
+	require.NoError(t, n.Start())
+	defer n.Close()
+
+	f.agent().change("/Users/me/src/node_modules/left-pad/index.js")
+	f.agent().change("/Users/me/src/index.js")
+	f.assertEvents(n, f.local("index.js"))
+}
+
+func TestRemoteWatcherAgentNotReady(t *testing.T) {
+	f := newRemoteFixture(t)
+	defer f.TearDown()
+
+	f.failToStart = true
+	n := f.newWatcher(EmptyMatcher{}, f.local(""))
+	err := n.Start()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "tilt: command not found")
+		assert.Contains(t, err.Error(), RemoteAgentEnvVar)
+	}
+	assert.NoError(t, n.Close())
+}
+
+func TestRemoteWatcherReconnects(t *testing.T) {
+	f := newRemoteFixture(t)
+	defer f.TearDown()
+
+	n := f.newWatcher(EmptyMatcher{}, f.local(""))
+	require.NoError(t, n.Start())
+	defer n.Close()
+
+	f.agent().disconnect()
+	f.waitForAgents(2)
+	assert.Contains(t, f.out.String(), "Lost connection to the watch agent on me@laptop")
+
+	f.agent().change("/Users/me/src/main.go")
+	f.assertEvents(n, f.local("main.go"))
+}
+
+func TestNewWatcherWithRemotes(t *testing.T) {
+	f := newRemoteFixture(t)
+	defer f.TearDown()
+
+	localDir := f.tmp.JoinPath("local")
+	f.tmp.MkdirAll("local")
+	localEvents := make(chan FileEvent)
+	newLocal := func(paths []string) (Notify, error) {
+		assert.Equal(t, []string{localDir}, paths)
+		return &fakeNotify{events: localEvents, errors: make(chan error)}, nil
+	}
+
+	n, err := newWatcherWithRemotes([]string{f.local("app"), localDir}, EmptyMatcher{}, f.l,
+		[]RemoteMount{f.mount}, newLocal, f.start)
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	defer n.Close()
+
+	f.agent().change("/Users/me/src/app/main.go")
+	f.assertEvents(n, f.local("app/main.go"))
+
+	go func() { localEvents <- NewFileEvent(localDir + "/a.txt") }()
+	f.assertEvents(n, localDir+"/a.txt")
+}
+
+func TestServeRemoteAgent(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	defer f.TearDown()
+	f.MkdirAll("src")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error)
+	l := logger.NewLogger(logger.InfoLvl, &syncBuffer{})
+	go func() {
+		done <- ServeRemoteAgent(ctx, []string{f.JoinPath("src")}, inR, outW, l)
+	}()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	nextLine := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the agent")
+			return ""
+		}
+	}
+
+	assert.Equal(t, remoteAgentReady, nextLine())
+
+	f.WriteFile("src/main.go", "package main")
+	assert.Equal(t, remoteAgentChangePrefix+filepath.ToSlash(f.JoinPath("src", "main.go")), nextLine())
+
+	// The agent exits when the watcher on the other end goes away.
+	_ = inW.Close()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the agent to exit")
+	}
+}
+
+type remoteFixture struct {
+	t     *testing.T
+	tmp   *tempdir.TempDirFixture
+	mount RemoteMount
+	out   *syncBuffer
+	l     logger.Logger
+
+	failToStart bool
+
+	mu     sync.Mutex
+	agents []*fakeAgent
+}
+
+func newRemoteFixture(t *testing.T) *remoteFixture {
+	tmp := tempdir.NewTempDirFixture(t)
+	tmp.MkdirAll("src")
+	out := &syncBuffer{}
+	return &remoteFixture{
+		t:     t,
+		tmp:   tmp,
+		mount: RemoteMount{Local: tmp.JoinPath("src"), Host: "me@laptop", Remote: "/Users/me/src"},
+		out:   out,
+		l:     logger.NewLogger(logger.InfoLvl, out),
+	}
+}
+
+func (f *remoteFixture) local(p string) string {
+	return f.tmp.JoinPath("src", p)
+}
+
+func (f *remoteFixture) newWatcher(ignore PathMatcher, paths ...string) *remoteNotify {
+	n, err := newRemoteWatcher(f.mount, paths, ignore, f.l, f.start)
+	require.NoError(f.t, err)
+	n.reconnectDelay = 10 * time.Millisecond
+	return n
+}
+
+func (f *remoteFixture) start(ctx context.Context, host string, remotePaths []string) (io.ReadCloser, error) {
+	assert.Equal(f.t, "me@laptop", host)
+	r, w := io.Pipe()
+	if f.failToStart {
+		_ = w.Close()
+		return &fakeAgentOutput{PipeReader: r, w: w, closeErr: fmt.Errorf("exit status 127: bash: tilt: command not found")}, nil
+	}
+
+	agent := newFakeAgent(remotePaths, w)
+	go func() {
+		<-ctx.Done()
+		_ = w.CloseWithError(ctx.Err())
+	}()
+	f.mu.Lock()
+	f.agents = append(f.agents, agent)
+	f.mu.Unlock()
+	agent.lines <- remoteAgentReady
+	return &fakeAgentOutput{PipeReader: r, w: w}, nil
+}
+
+// The agent we're currently connected to.
+func (f *remoteFixture) agent() *fakeAgent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(f.t, f.agents)
+	return f.agents[len(f.agents)-1]
+}
+
+func (f *remoteFixture) waitForAgents(n int) {
+	timeout := time.After(time.Second)
+	for {
+		f.mu.Lock()
+		count := len(f.agents)
+		f.mu.Unlock()
+		if count >= n {
+			return
+		}
+		select {
+		case <-timeout:
+			f.t.Fatalf("Timed out waiting for %d agents. Actual: %d", n, count)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func (f *remoteFixture) assertEvents(n Notify, expected ...string) {
+	actual := []string{}
+	timeout := time.After(time.Second)
+	for len(actual) < len(expected) {
+		select {
+		case e := <-n.Events():
+			actual = append(actual, e.Path())
+		case <-timeout:
+			f.t.Fatalf("Timed out waiting for events. Expected: %v. Actual: %v", expected, actual)
+		}
+	}
+	assert.Equal(f.t, expected, actual)
+}
+
+func (f *remoteFixture) TearDown() {
+	f.tmp.TearDown()
+}
+
+type fakeAgent struct {
+	paths []string
+	lines chan string
+}
+
+// Writes lines to w in order, without blocking the test.
+func newFakeAgent(paths []string, w *io.PipeWriter) *fakeAgent {
+	a := &fakeAgent{paths: paths, lines: make(chan string, 100)}
+	go func() {
+		for line := range a.lines {
+			_, _ = fmt.Fprintln(w, line)
+		}
+		_ = w.Close()
+	}()
+	return a
+}
+
+func (a *fakeAgent) change(p string) {
+	a.lines <- remoteAgentChangePrefix + p
+}
+
+func (a *fakeAgent) disconnect() {
+	close(a.lines)
+}
+
+type fakeAgentOutput struct {
+	*io.PipeReader
+	w        *io.PipeWriter
+	closeErr error
+}
+
+// Unblocks any writes still in flight, like an exiting process.
+func (o *fakeAgentOutput) Close() error {
+	_ = o.w.Close()
+	_ = o.PipeReader.Close()
+	return o.closeErr
+}
+
+type fakeNotify struct {
+	events chan FileEvent
+	errors chan error
+}
+
+func (n *fakeNotify) Start() error { return nil }
+func (n *fakeNotify) Close() error {
+	close(n.events)
+	close(n.errors)
+	return nil
+}
+func (n *fakeNotify) Events() chan FileEvent { return n.events }
+func (n *fakeNotify) Errors() chan error     { return n.errors }
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
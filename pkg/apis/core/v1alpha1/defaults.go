@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TriggerModeAuto                   = "auto"
+	TriggerModeManualAfterInitial     = "manual_after_initial"
+	TriggerModeManualIncludingInitial = "manual_including_initial"
+)
+
+const (
+	UpdateStatusNone       = "none"
+	UpdateStatusPending    = "pending"
+	UpdateStatusInProgress = "in_progress"
+	UpdateStatusOK         = "ok"
+	UpdateStatusError      = "error"
+)
+
+const RuntimeStatusUnknown = "unknown"
+
+// Clients that read objects from an older Tilt may see empty fields that
+// newer Tilts always fill in. Default() fills in those fields the way
+// the server would, so that clients can handle both the same way.
+
+func setTypeMeta(tm *metav1.TypeMeta, kind string) {
+	if tm.Kind == "" {
+		tm.Kind = kind
+	}
+	if tm.APIVersion == "" {
+		tm.APIVersion = APIVersion
+	}
+}
+
+func (obj *Session) Default() {
+	setTypeMeta(&obj.TypeMeta, KindSession)
+}
+
+func (obj *Resource) Default() {
+	setTypeMeta(&obj.TypeMeta, KindResource)
+	if obj.Spec.TriggerMode == "" {
+		obj.Spec.TriggerMode = TriggerModeAuto
+	}
+	if obj.Status.UpdateStatus == "" {
+		obj.Status.UpdateStatus = UpdateStatusNone
+	}
+	if obj.Status.RuntimeStatus == "" {
+		obj.Status.RuntimeStatus = RuntimeStatusUnknown
+	}
+}
+
+func (obj *Build) Default() {
+	setTypeMeta(&obj.TypeMeta, KindBuild)
+}
+
+func (obj *LogSpan) Default() {
+	setTypeMeta(&obj.TypeMeta, KindLogSpan)
+}
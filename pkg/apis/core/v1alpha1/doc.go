@@ -0,0 +1,10 @@
+// Package v1alpha1 contains the versioned objects that the Tilt API serves.
+//
+// The objects follow Kubernetes API conventions: every object has a kind, an
+// apiVersion, and metadata, and splits what the user asked for (spec) from
+// what Tilt observed (status). Clients can list and watch them on the HUD
+// server under /api/v1alpha1/.
+//
+// Within a version, we only add optional fields. Clients should ignore fields
+// they don't recognize, and treat missing fields as their defaults.
+package v1alpha1
@@ -0,0 +1,189 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	GroupName = "tilt.dev"
+	Version   = "v1alpha1"
+)
+
+// The apiVersion of every object in this package.
+var APIVersion = GroupName + "/" + Version
+
+const (
+	KindSession  = "Session"
+	KindResource = "Resource"
+	KindBuild    = "Build"
+	KindLogSpan  = "LogSpan"
+)
+
+// Session is a running Tilt process. There's only ever one, named "Tiltfile".
+type Session struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SessionSpec   `json:"spec,omitempty"`
+	Status SessionStatus `json:"status,omitempty"`
+}
+
+type SessionSpec struct {
+	// The absolute path of the main Tiltfile.
+	TiltfilePath string `json:"tiltfilePath,omitempty"`
+}
+
+type SessionStatus struct {
+	// The version of Tilt that's running.
+	Version string `json:"version,omitempty"`
+
+	// When Tilt started.
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// Set when the session has hit an error it can't recover from.
+	FatalError string `json:"fatalError,omitempty"`
+}
+
+type SessionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Session `json:"items"`
+}
+
+// Resource is a unit of work defined in the Tiltfile, such as a Kubernetes
+// workload, a Docker Compose service, or a local command.
+type Resource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceSpec   `json:"spec,omitempty"`
+	Status ResourceStatus `json:"status,omitempty"`
+}
+
+type ResourceSpec struct {
+	// One of "tiltfile", "k8s", "docker-compose", "local", or "unknown".
+	Type string `json:"type,omitempty"`
+
+	// One of "auto", "manual_after_initial", or "manual_including_initial".
+	//
+	// Defaults to "auto".
+	TriggerMode string `json:"triggerMode,omitempty"`
+
+	// The names of the resources that this resource waits on before it starts.
+	ResourceDeps []string `json:"resourceDeps,omitempty"`
+}
+
+type ResourceStatus struct {
+	// One of "pending", "in_progress", "ok", "error", or "none".
+	//
+	// Defaults to "none".
+	UpdateStatus string `json:"updateStatus,omitempty"`
+
+	// The status of the running workload, from the runtime's point of view.
+	// One of "pending", "ok", "error", "not_applicable", or "unknown".
+	//
+	// Defaults to "unknown".
+	RuntimeStatus string `json:"runtimeStatus,omitempty"`
+
+	// The name of the Build object that's currently running, if any.
+	CurrentBuild string `json:"currentBuild,omitempty"`
+
+	// When the resource was last deployed.
+	LastDeployTime metav1.Time `json:"lastDeployTime,omitempty"`
+
+	// True if files have changed since the last build.
+	HasPendingChanges bool `json:"hasPendingChanges,omitempty"`
+}
+
+type ResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Resource `json:"items"`
+}
+
+// Build is one update of a resource. Builds are named after the log span
+// that holds their output, so the name is also the build's LogSpan.
+type Build struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BuildSpec   `json:"spec,omitempty"`
+	Status BuildStatus `json:"status,omitempty"`
+}
+
+type BuildSpec struct {
+	// The name of the resource that this build updates.
+	Resource string `json:"resource,omitempty"`
+
+	// Why the build started, e.g., "Changed Files" or "Web Trigger".
+	Reason string `json:"reason,omitempty"`
+
+	// The files that changed, if the build was triggered by file changes.
+	Edits []string `json:"edits,omitempty"`
+}
+
+type BuildStatus struct {
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// Unset while the build is still running.
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+
+	// Set if the build failed.
+	Error string `json:"error,omitempty"`
+
+	WarningCount int32 `json:"warningCount,omitempty"`
+}
+
+type BuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Build `json:"items"`
+}
+
+// LogSpan is a group of log lines from a single source, e.g., a build or a pod.
+type LogSpan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LogSpanSpec   `json:"spec,omitempty"`
+	Status LogSpanStatus `json:"status,omitempty"`
+}
+
+type LogSpanSpec struct {
+	// The name of the resource that this log belongs to. Empty for
+	// logs that don't belong to a resource.
+	Resource string `json:"resource,omitempty"`
+}
+
+type LogSpanStatus struct {
+	// The checkpoints of the first and last log segments in this span.
+	// Clients can use these to fetch the span's logs from the view API.
+	FirstCheckpoint int64 `json:"firstCheckpoint,omitempty"`
+	LastCheckpoint  int64 `json:"lastCheckpoint,omitempty"`
+}
+
+type LogSpanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []LogSpan `json:"items"`
+}
+
+type WatchEventType string
+
+const (
+	Added    WatchEventType = "ADDED"
+	Modified WatchEventType = "MODIFIED"
+	Deleted  WatchEventType = "DELETED"
+)
+
+// WatchEvent is one change to an object, streamed to clients that watch a kind.
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+
+	// A Session, Resource, Build, or LogSpan, depending on the kind being watched.
+	Object interface{} `json:"object"`
+}
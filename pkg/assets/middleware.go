@@ -27,6 +27,19 @@ func StripPrefix(prefix string, h http.Handler) http.Handler {
 	})
 }
 
+// Middleware for when a reverse proxy already stripped the prefix.
+// Attaches the prefix to the Request Context, so that we can still
+// build public URLs.
+func WithPublicPathPrefix(prefix string, h http.Handler) http.Handler {
+	if prefix == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, appendPublicPathPrefix(prefix, r))
+	})
+}
+
 // Middleware that injects version information into the request.
 // We rewrite the URL to contain the version.
 //
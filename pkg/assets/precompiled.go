@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime"
 	"net/http"
 	"os"
@@ -65,5 +66,18 @@ func (s precompiledServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}()
 
 	w.Header().Add("Content-Type", mime.TypeByExtension(filepath.Ext(contentPath)))
+
+	// Behind a reverse proxy, asset URLs need to go through the proxy's path.
+	prefix := getPublicPathPrefix(req)
+	if prefix != "" && shouldRewriteContentURLs(contentPath) {
+		content, err := ioutil.ReadAll(f)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(err.Error()))
+			return
+		}
+		_, _ = w.Write(rewriteContentURLs(prefix, content))
+		return
+	}
 	_, _ = io.Copy(w, f)
 }
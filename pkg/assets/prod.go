@@ -96,13 +96,17 @@ func (s prodServer) fetchFromAssetBucket(w http.ResponseWriter, req *http.Reques
 }
 
 func RewriteContentURLs(req *http.Request, content []byte) []byte {
-	path := req.URL.Path
-	shouldRewrite := strings.HasSuffix(path, ".html") || strings.HasSuffix(path, ".css")
-	if !shouldRewrite {
+	if !shouldRewriteContentURLs(req.URL.Path) {
 		return content
 	}
+	return rewriteContentURLs(getPublicPathPrefix(req), content)
+}
+
+func shouldRewriteContentURLs(path string) bool {
+	return strings.HasSuffix(path, ".html") || strings.HasSuffix(path, ".css")
+}
 
-	prefix := getPublicPathPrefix(req)
+func rewriteContentURLs(prefix string, content []byte) []byte {
 	return bytes.ReplaceAll(content, []byte("/static/"), []byte(fmt.Sprintf("%s/static/", prefix)))
 }
 
@@ -14,3 +14,17 @@ const FieldNameBuildEvent = "buildEvent"
 // progressMustPrint="1" indicates that this line must appear in the
 // output - e.g., a line that communicates that the upload finished.
 const FieldNameProgressMustPrint = "progressMustPrint"
+
+// Marks verbose build output (e.g., the output of each step of a docker build)
+// that may be hidden depending on the user's build output settings.
+//
+// buildDetail="1" lines are only shown when the user asks for full build logs.
+// buildDetail="replay" lines re-print hidden output that the user needs to see
+// (e.g., the output of a failed build step), so they're only shown
+// when the full build logs are hidden.
+const FieldNameBuildDetail = "buildDetail"
+
+const (
+	BuildDetailHidden = "1"
+	BuildDetailReplay = "replay"
+)
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+type AlertSeverity string
+
+const (
+	AlertSeverityWarning AlertSeverity = "warning"
+	AlertSeverityError   AlertSeverity = "error"
+)
+
+// Where an alert came from.
+//
+// Each source owns its alerts: when the source sees that the problem is gone,
+// it resolves the alert, and the alert disappears from the alert center.
+type AlertSource string
+
+const (
+	// An image that the Tiltfile builds, but that no deploy config uses.
+	AlertSourceImageInjection AlertSource = "image-injection"
+
+	// A Kubernetes object that uses an API version that's deprecated or removed.
+	AlertSourceDeprecatedAPI AlertSource = "deprecated-api"
+
+	// A container in CrashLoopBackOff.
+	AlertSourceCrashLoop AlertSource = "crash-loop"
+
+	// A port-forward that couldn't connect.
+	AlertSourcePortForward AlertSource = "port-forward"
+
+	// The disk that Docker stores images on is running out of space.
+	AlertSourceDockerDisk AlertSource = "docker-disk"
+
+	// An operating system limit on open files or file watches that
+	// Tilt is likely to run into.
+	AlertSourceWatchLimits AlertSource = "watch-limits"
+
+	// A file watcher that fell back to polling, because the filesystem
+	// doesn't deliver change events.
+	AlertSourceWatchDegraded AlertSource = "watch-degraded"
+
+	// An image in a Dockerfile's FROM line that has a newer version in the registry.
+	AlertSourceBaseImage AlertSource = "base-image"
+)
+
+// A problem worth the user's attention that would otherwise
+// scroll past in the logs.
+type Alert struct {
+	// Identifies the alert across updates. When a source reports the
+	// same problem again, it uses the same ID, so that we count it
+	// as a repeat instead of a new alert.
+	ID string
+
+	Source       AlertSource
+	Severity     AlertSeverity
+	ManifestName ManifestName // Empty if the alert isn't about one resource.
+	Message      string
+
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Count     int
+
+	// The user has seen this alert and doesn't want to be reminded of it.
+	// If the alert's message changes, we un-acknowledge it.
+	Acknowledged bool
+}
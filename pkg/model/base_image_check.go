@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Settings for base image checks, which periodically ask the registry
+// whether the images in a Dockerfile's FROM lines have been updated,
+// so that a long-running session doesn't keep building on a stale base.
+type BaseImageCheckSettings struct {
+	Enabled bool
+
+	// How often to ask the registry about each base image.
+	Interval time.Duration
+}
+
+func DefaultBaseImageCheckSettings() BaseImageCheckSettings {
+	return BaseImageCheckSettings{
+		Enabled:  true,
+		Interval: time.Hour,
+	}
+}
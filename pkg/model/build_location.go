@@ -0,0 +1,26 @@
+package model
+
+import "fmt"
+
+// Where to build an image.
+type BuildLocation string
+
+const (
+	// Build with the local Docker daemon.
+	BuildLocationLocal BuildLocation = "local"
+
+	// Build in a pod in the Kubernetes cluster, and push straight to the registry.
+	BuildLocationCluster BuildLocation = "cluster"
+)
+
+var allBuildLocations = []BuildLocation{BuildLocationLocal, BuildLocationCluster}
+
+func ParseBuildLocation(s string) (BuildLocation, error) {
+	for _, v := range allBuildLocations {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return BuildLocationLocal, fmt.Errorf("invalid build location %q. Must be one of: %q, %q",
+		s, BuildLocationLocal, BuildLocationCluster)
+}
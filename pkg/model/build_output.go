@@ -0,0 +1,35 @@
+package model
+
+import "fmt"
+
+// How much of a docker build's output to show in the logs.
+//
+// Hidden output is still recorded, and can be retrieved with `tilt logs --build`.
+type BuildOutputVerbosity string
+
+const (
+	// Inherit the global setting from update_settings().
+	BuildOutputDefault BuildOutputVerbosity = ""
+
+	// Every line of output from every build step.
+	BuildOutputFull BuildOutputVerbosity = "full"
+
+	// One status line per build step, updated in place.
+	// Output from a step is only shown if that step fails.
+	BuildOutputProgress BuildOutputVerbosity = "progress"
+
+	// Nothing, unless the build fails.
+	BuildOutputQuiet BuildOutputVerbosity = "quiet"
+)
+
+var allBuildOutputVerbosities = []BuildOutputVerbosity{BuildOutputFull, BuildOutputProgress, BuildOutputQuiet}
+
+func ParseBuildOutputVerbosity(s string) (BuildOutputVerbosity, error) {
+	for _, v := range allBuildOutputVerbosities {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return BuildOutputDefault, fmt.Errorf("invalid build output %q. Must be one of: %q, %q, %q",
+		s, BuildOutputFull, BuildOutputProgress, BuildOutputQuiet)
+}
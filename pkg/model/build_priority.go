@@ -0,0 +1,22 @@
+package model
+
+// When several resources are waiting to build, Tilt builds the one with the
+// highest priority first. Resources with the same priority build in the order
+// their changes came in.
+type BuildPriority int
+
+const (
+	BuildPriorityLow    BuildPriority = -1
+	BuildPriorityNormal BuildPriority = 0
+	BuildPriorityHigh   BuildPriority = 1
+)
+
+func (p BuildPriority) String() string {
+	switch p {
+	case BuildPriorityLow:
+		return "low"
+	case BuildPriorityHigh:
+		return "high"
+	}
+	return "normal"
+}
@@ -29,6 +29,14 @@ const (
 	// Building manifestA will mark imageB
 	// with changed dependencies.
 	BuildReasonFlagChangedDeps
+
+	// The files changed because of a branch switch or other
+	// git checkout, and Tilt waited for the checkout to finish.
+	BuildReasonFlagGitCheckout
+
+	// An external process called `tilt env` to change an environment
+	// variable that the Tiltfile read.
+	BuildReasonFlagTiltfileEnv
 )
 
 func (r BuildReason) With(flag BuildReason) BuildReason {
@@ -62,6 +70,8 @@ var translations = map[BuildReason]string{
 	BuildReasonFlagTriggerUnknown: "Unknown Trigger",
 	BuildReasonFlagTiltfileArgs:   "Tilt Args",
 	BuildReasonFlagChangedDeps:    "Dependency Updated",
+	BuildReasonFlagGitCheckout:    "Git Checkout",
+	BuildReasonFlagTiltfileEnv:    "Env Vars Changed",
 }
 
 var triggerBuildReasons = []BuildReason{
@@ -72,6 +82,7 @@ var triggerBuildReasons = []BuildReason{
 
 var allBuildReasons = []BuildReason{
 	BuildReasonFlagInit,
+	BuildReasonFlagGitCheckout,
 	BuildReasonFlagChangedFiles,
 	BuildReasonFlagConfig,
 	BuildReasonFlagCrash,
@@ -80,6 +91,7 @@ var allBuildReasons = []BuildReason{
 	BuildReasonFlagChangedDeps,
 	BuildReasonFlagTriggerUnknown,
 	BuildReasonFlagTiltfileArgs,
+	BuildReasonFlagTiltfileEnv,
 }
 
 func (r BuildReason) String() string {
@@ -101,6 +113,10 @@ func (r BuildReason) String() string {
 	// Use an array to iterate over the translations to ensure the iteration order
 	// is consistent.
 	for _, v := range allBuildReasons {
+		// The git checkout is why the files changed, so don't list both.
+		if v == BuildReasonFlagChangedFiles && r.Has(BuildReasonFlagGitCheckout) {
+			continue
+		}
 		if r.Has(v) {
 			rs = append(rs, translations[v])
 		}
@@ -9,4 +9,5 @@ import (
 func TestBuildReasonString(t *testing.T) {
 	assert.Equal(t, "Changed Files | Config Changed", BuildReasonFlagChangedFiles.With(BuildReasonFlagConfig).String())
 	assert.Equal(t, "Web Trigger", BuildReasonFlagInit.With(BuildReasonFlagTriggerWeb).String())
+	assert.Equal(t, "Git Checkout", BuildReasonFlagChangedFiles.With(BuildReasonFlagGitCheckout).String())
 }
@@ -4,7 +4,10 @@ import (
 	"time"
 )
 
-const BuildHistoryLimit = 2
+// How many of a resource's most recent builds we send to the HUD and web UI
+// with every update. Tilt keeps more builds than this (see
+// UpdateSettings.BuildHistoryLimit), and serves them from /api/build_history.
+const BuildHistoryViewLimit = 2
 
 type BuildType string
 
@@ -23,12 +26,32 @@ type BuildRecord struct {
 
 	BuildTypes []BuildType
 
+	// What kind of failure Error was, so that we can tell the user how to fix it.
+	ErrorCategory ErrorCategory
+
 	// The lookup key for the logs in the logstore.
 	SpanID LogSpanID
 
+	// Where the build's logs were saved on disk, once Tilt has saved them.
+	// The logstore drops the oldest logs when it gets too big,
+	// so this is where to read the logs of older builds.
+	LogPath string
+
 	// We count the warnings by looking up all the logs with Level=WARNING
 	// in the logstore. We store this number separately for ease of use.
 	WarningCount int
+
+	// For local resources, a hash of the contents of the deps that the
+	// command ran against. Empty if the command failed.
+	DepsHash string
+
+	// For k8s resources, true if the rendered YAML was the same as the
+	// last deploy, so we didn't apply it.
+	DeploySkipped bool
+
+	// For images built with update_settings(image_layer_analysis=True),
+	// the size of each image, layer by layer.
+	ImageLayers []ImageLayerAnalysis
 }
 
 func (bs BuildRecord) Empty() bool {
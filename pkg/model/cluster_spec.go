@@ -0,0 +1,75 @@
+package model
+
+import "fmt"
+
+// The tools that `tilt cluster create` knows how to make a local cluster with.
+type ClusterProduct string
+
+const (
+	ClusterProductKIND     ClusterProduct = "kind"
+	ClusterProductK3D      ClusterProduct = "k3d"
+	ClusterProductMinikube ClusterProduct = "minikube"
+)
+
+var AllClusterProducts = []ClusterProduct{ClusterProductKIND, ClusterProductK3D, ClusterProductMinikube}
+
+const DefaultClusterName = "tilt"
+const DefaultClusterRegistryPort = 5000
+
+// A local cluster for `tilt cluster create` to set up, from the cluster() builtin.
+type ClusterSpec struct {
+	// Empty if the Tiltfile doesn't call cluster().
+	Product ClusterProduct
+
+	Name string
+
+	// The number of nodes, including the control plane.
+	Nodes int
+
+	// If true, also run a local image registry on RegistryPort,
+	// and tell the cluster to pull from it.
+	Registry     bool
+	RegistryPort int
+
+	// The Kubernetes version of the nodes, e.g., v1.21.1.
+	// If empty, we use the product's default.
+	KubernetesVersion string
+}
+
+func (s ClusterSpec) Empty() bool {
+	return s.Product == ""
+}
+
+// The kubeconfig context that the product creates for the cluster.
+func (s ClusterSpec) KubeContext() string {
+	switch s.Product {
+	case ClusterProductKIND:
+		return fmt.Sprintf("kind-%s", s.Name)
+	case ClusterProductK3D:
+		return fmt.Sprintf("k3d-%s", s.Name)
+	}
+	return s.Name
+}
+
+const DefaultLocalRegistryName = "tilt-registry"
+
+// A registry container for Tilt to push to, from the local_registry() builtin.
+//
+// Unlike ClusterSpec.Registry, this works with a cluster that already exists:
+// Tilt starts the registry on `tilt up`, and points the cluster at it if it can.
+type LocalRegistrySpec struct {
+	// The name of the registry container.
+	Name string
+
+	// The port the registry listens on, on localhost.
+	Port int
+}
+
+func (s LocalRegistrySpec) Empty() bool {
+	return s.Name == ""
+}
+
+// Where Tilt pushes images, and the cluster pulls them from.
+func (s LocalRegistrySpec) Host() string {
+	return fmt.Sprintf("localhost:%d", s.Port)
+}
@@ -0,0 +1,34 @@
+package model
+
+// How to rewrite the pods of a K8sTarget before deploying them, so that
+// workloads sized for production fit on a small dev cluster.
+//
+// This is applied when Tilt deploys, so the YAML in the K8sTarget stays
+// identical to what you'd deploy to prod.
+type DevResourceProfile struct {
+	// If true, remove container resource requests and limits entirely.
+	// Takes precedence over Scale.
+	StripResources bool
+
+	// If non-zero, multiply container resource requests and limits by this factor.
+	Scale float64
+
+	// If true, remove pod anti-affinity rules, so that replicas can share a single node.
+	RemoveAntiAffinity bool
+
+	// If true, only rewrite pods when deploying to a local dev cluster
+	// (e.g., kind, minikube, Docker for Desktop).
+	LocalOnly bool
+}
+
+func (p DevResourceProfile) Empty() bool {
+	return !p.StripResources && p.Scale == 0 && !p.RemoveAntiAffinity
+}
+
+// Whether the profile should be applied to a cluster.
+func (p DevResourceProfile) AppliesTo(isDevCluster bool) bool {
+	if p.Empty() {
+		return false
+	}
+	return isDevCluster || !p.LocalOnly
+}
@@ -6,9 +6,29 @@ import (
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 )
 
+// The compose CLI that runs a docker-compose project.
+type DockerComposeBackend string
+
+const (
+	// Tilt picks a compose CLI based on what's installed.
+	DockerComposeBackendAuto    DockerComposeBackend = ""
+	DockerComposeBackendV1      DockerComposeBackend = "docker-compose"
+	DockerComposeBackendV2      DockerComposeBackend = "docker compose"
+	DockerComposeBackendPodman  DockerComposeBackend = "podman-compose"
+	DockerComposeBackendNerdctl DockerComposeBackend = "nerdctl compose"
+)
+
+// The config files that make up a docker-compose project,
+// and the compose CLI to run them with.
+type DockerComposeProject struct {
+	ConfigPaths []string
+	Backend     DockerComposeBackend
+}
+
 type DockerComposeTarget struct {
 	Name        TargetName
 	ConfigPaths []string
+	Backend     DockerComposeBackend
 
 	// The docker context, like in DockerBuild
 	buildPath string
@@ -25,6 +45,9 @@ type DockerComposeTarget struct {
 	// These directories and their children will not trigger file change events
 	ignoredLocalDirectories []string
 
+	// The host paths that the service bind-mounts into its container.
+	bindMounts []string
+
 	dependencyIDs []TargetID
 
 	publishedPorts []int
@@ -36,6 +59,10 @@ func (t DockerComposeTarget) ManifestName() ManifestName {
 	return ManifestName(t.Name)
 }
 
+func (t DockerComposeTarget) Project() DockerComposeProject {
+	return DockerComposeProject{ConfigPaths: t.ConfigPaths, Backend: t.Backend}
+}
+
 func (t DockerComposeTarget) Empty() bool { return t.ID().Empty() }
 
 func (t DockerComposeTarget) ID() TargetID {
@@ -111,6 +138,15 @@ func (t DockerComposeTarget) WithIgnoredLocalDirectories(dirs []string) DockerCo
 	return t
 }
 
+func (t DockerComposeTarget) BindMounts() []string {
+	return t.bindMounts
+}
+
+func (t DockerComposeTarget) WithBindMounts(paths []string) DockerComposeTarget {
+	t.bindMounts = paths
+	return t
+}
+
 // TODO(nick): This method should be deleted. We should just de-dupe and sort LocalPaths once
 // when we create it, rather than have a duplicate method that does the "right" thing.
 func (t DockerComposeTarget) Dependencies() []string {
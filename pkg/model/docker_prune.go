@@ -11,12 +11,21 @@ const DockerPruneDefaultInterval = time.Hour
 // Keep the last 2 builds of an image
 const DockerPruneDefaultKeepRecent = 2
 
+// Warn when Docker's disk has less than this percent free
+const DockerPruneDefaultWarnFreeDiskPct = 10
+
+// Prune right away when Docker's disk has less than this percent free
+const DockerPruneDefaultMinFreeDiskPct = 5
+
 type DockerPruneSettings struct {
 	Enabled    bool
 	MaxAge     time.Duration // "prune Docker objects older than X"
 	NumBuilds  int           // "prune every Y builds" (takes precedence over "prune every Z hours")
 	Interval   time.Duration // "prune every Z hours"
 	KeepRecent int           // Keep the most recent N builds of a tag.
+
+	WarnFreeDiskPct int // "warn when Docker's disk is less than W% free" (0 to never warn)
+	MinFreeDiskPct  int // "prune when Docker's disk is less than V% free" (0 to never prune for space)
 }
 
 func DefaultDockerPruneSettings() DockerPruneSettings {
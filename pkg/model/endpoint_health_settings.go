@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Settings for endpoint health checks, which periodically send an HTTP GET
+// to each of a resource's endpoint links and report the result in the UI.
+type EndpointHealthSettings struct {
+	Enabled bool
+
+	// How often to check each endpoint.
+	Interval time.Duration
+
+	// How long to wait for an endpoint to respond before marking it unhealthy.
+	Timeout time.Duration
+
+	// The resources to check. If empty, every resource with endpoints is checked.
+	Resources []ManifestName
+}
+
+func DefaultEndpointHealthSettings() EndpointHealthSettings {
+	return EndpointHealthSettings{
+		Enabled:  false,
+		Interval: 15 * time.Second,
+		Timeout:  3 * time.Second,
+	}
+}
+
+func (s EndpointHealthSettings) Includes(mn ManifestName) bool {
+	if len(s.Resources) == 0 {
+		return true
+	}
+	for _, r := range s.Resources {
+		if r == mn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Variables that usually hold credentials. The Tiltfile can read them,
+// but Tilt never records their values.
+var DefaultEnvDenyPatterns = []string{
+	"*TOKEN*",
+	"*SECRET*",
+	"*PASSWORD*",
+	"*PASSWD*",
+	"*CREDENTIAL*",
+	"*API_KEY*",
+	"*PRIVATE_KEY*",
+}
+
+// Settings for which environment variables the Tiltfile can read.
+//
+// Patterns are globs (e.g., "AWS_*"), and match names case-insensitively.
+type EnvSettings struct {
+	// If set, the Tiltfile can only read variables that match one of these patterns.
+	Allow []string
+
+	// The Tiltfile can read variables that match these patterns, but Tilt
+	// scrubs their values from logs and never records them.
+	Deny []string
+}
+
+func DefaultEnvSettings() EnvSettings {
+	return EnvSettings{
+		Deny: append([]string{}, DefaultEnvDenyPatterns...),
+	}
+}
+
+func (s EnvSettings) Allows(name string) bool {
+	if len(s.Allow) == 0 {
+		return true
+	}
+	return matchesAnyEnvPattern(s.Allow, name)
+}
+
+func (s EnvSettings) Denies(name string) bool {
+	return matchesAnyEnvPattern(s.Deny, name)
+}
+
+func matchesAnyEnvPattern(patterns []string, name string) bool {
+	name = strings.ToUpper(name)
+	for _, p := range patterns {
+		ok, err := path.Match(strings.ToUpper(p), name)
+		if err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateEnvPattern(p string) error {
+	_, err := path.Match(p, "")
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", p, err)
+	}
+	return nil
+}
+
+// An environment variable that the Tiltfile read.
+type EnvVarRead struct {
+	Name string
+
+	// Whether the variable was set when the Tiltfile read it.
+	Set bool
+
+	// The value the Tiltfile saw. Always empty for denied variables.
+	Value string
+
+	// A digest of the value, so that we can tell when a denied variable
+	// changes without recording it.
+	Digest string
+
+	Denied bool
+}
+
+func NewEnvVarRead(name string, value string, set bool, denied bool) EnvVarRead {
+	r := EnvVarRead{Name: name, Set: set, Denied: denied}
+	if !set {
+		return r
+	}
+	r.Digest = EnvValueDigest(value)
+	if !denied {
+		r.Value = value
+	}
+	return r
+}
+
+func EnvValueDigest(value string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(value)))
+}
+
+// The environment variables the Tiltfile read while loading, by name.
+//
+// These are inputs to the Tiltfile, like the files it reads. When a
+// running Tilt changes one of them (with `tilt env`), it reloads the Tiltfile.
+type TiltfileEnv map[string]EnvVarRead
+
+// Whether setting the variable to a value with the given digest (or unsetting
+// it, if set is false) changes what the Tiltfile read.
+func (e TiltfileEnv) Changes(name string, set bool, digest string) bool {
+	read, ok := e[name]
+	if !ok {
+		return false
+	}
+	if read.Set != set {
+		return true
+	}
+	return set && read.Digest != digest
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvSettingsDefaults(t *testing.T) {
+	s := DefaultEnvSettings()
+	assert.True(t, s.Allows("HOME"))
+	assert.False(t, s.Denies("HOME"))
+	assert.True(t, s.Denies("GITHUB_TOKEN"))
+	assert.True(t, s.Denies("db_password"))
+}
+
+func TestEnvSettingsAllow(t *testing.T) {
+	s := EnvSettings{Allow: []string{"AWS_*", "HOME"}}
+	assert.True(t, s.Allows("aws_region"))
+	assert.True(t, s.Allows("HOME"))
+	assert.False(t, s.Allows("PATH"))
+}
+
+func TestTiltfileEnvChanges(t *testing.T) {
+	env := TiltfileEnv{
+		"DEBUG":  NewEnvVarRead("DEBUG", "1", true, false),
+		"UNSET":  NewEnvVarRead("UNSET", "", false, false),
+		"SECRET": NewEnvVarRead("SECRET", "hunter2", true, true),
+	}
+
+	assert.False(t, env.Changes("DEBUG", true, EnvValueDigest("1")))
+	assert.True(t, env.Changes("DEBUG", true, EnvValueDigest("0")))
+	assert.True(t, env.Changes("DEBUG", false, ""))
+	assert.False(t, env.Changes("UNSET", false, ""))
+	assert.True(t, env.Changes("UNSET", true, EnvValueDigest("")))
+	assert.True(t, env.Changes("SECRET", true, EnvValueDigest("hunter3")))
+	assert.False(t, env.Changes("OTHER", true, EnvValueDigest("1")))
+
+	assert.Equal(t, "", env["SECRET"].Value)
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCategory classifies a failure by what the user needs to do to fix it.
+//
+// Build and deploy code tags the errors it can recognize. The tag survives
+// wrapping, so the HUD and web UI can show category-specific guidance, and
+// `tilt ci` can exit with a distinct code for each class of failure.
+type ErrorCategory string
+
+const (
+	ErrorCategoryNone           ErrorCategory = ""
+	ErrorCategoryBuildContext   ErrorCategory = "build-context"
+	ErrorCategoryRegistryAuth   ErrorCategory = "registry-auth"
+	ErrorCategoryClusterConn    ErrorCategory = "cluster-connection"
+	ErrorCategoryTiltfileSyntax ErrorCategory = "tiltfile-syntax"
+)
+
+type errorRemediation struct {
+	hint string
+	link string
+}
+
+var errorRemediations = map[ErrorCategory]errorRemediation{
+	ErrorCategoryBuildContext: {
+		hint: "Check that the files your Dockerfile copies exist under the build context, " +
+			"and aren't excluded by .dockerignore, .tiltignore, or the ignore/only arguments",
+		link: "https://docs.tilt.dev/api.html#api.docker_build",
+	},
+	ErrorCategoryRegistryAuth: {
+		hint: "The image registry rejected your credentials. Run `docker login` for the registry, " +
+			"or push to a registry your cluster can already pull from with default_registry()",
+		link: "https://docs.tilt.dev/api.html#api.default_registry",
+	},
+	ErrorCategoryClusterConn: {
+		hint: "Tilt couldn't reach your Kubernetes cluster. Check that it's running, " +
+			"and that `kubectl config current-context` points at it",
+		link: "https://docs.tilt.dev/choosing_clusters.html",
+	},
+	ErrorCategoryTiltfileSyntax: {
+		hint: "The Tiltfile isn't valid Starlark. Fix the syntax at the position above",
+		link: "https://docs.tilt.dev/api.html",
+	},
+}
+
+// Guidance for fixing errors in this category, with a link to the docs.
+// Empty for uncategorized errors.
+func (c ErrorCategory) Remediation() string {
+	r, ok := errorRemediations[c]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Hint: %s.\nSee: %s", r.hint, r.link)
+}
+
+type CategorizedError struct {
+	error
+	Category ErrorCategory
+}
+
+func (e CategorizedError) Unwrap() error { return e.error }
+
+// Implements the github.com/pkg/errors causer interface,
+// so that errors.Cause() sees through the category.
+func (e CategorizedError) Cause() error { return e.error }
+
+var _ error = CategorizedError{}
+
+func categorize(err error, c ErrorCategory) error {
+	if err == nil {
+		return nil
+	}
+	return CategorizedError{error: err, Category: c}
+}
+
+// The files that a docker build needs are missing from its context,
+// or can't be read.
+func BuildContextError(err error) error {
+	return categorize(err, ErrorCategoryBuildContext)
+}
+
+// An image registry rejected our credentials.
+func RegistryAuthError(err error) error {
+	return categorize(err, ErrorCategoryRegistryAuth)
+}
+
+// We couldn't connect to the Kubernetes cluster.
+func ClusterConnError(err error) error {
+	return categorize(err, ErrorCategoryClusterConn)
+}
+
+// The Tiltfile failed to parse.
+func TiltfileSyntaxError(err error) error {
+	return categorize(err, ErrorCategoryTiltfileSyntax)
+}
+
+// The category of the outermost categorized error in err's chain,
+// or ErrorCategoryNone if there isn't one.
+func ErrorCategoryOf(err error) ErrorCategory {
+	var ce CategorizedError
+	if errors.As(err, &ce) {
+		return ce.Category
+	}
+	return ErrorCategoryNone
+}
+
+// Guidance for fixing err, or the empty string if we don't recognize it.
+func ErrorRemediation(err error) string {
+	return ErrorCategoryOf(err).Remediation()
+}
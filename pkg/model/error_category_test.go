@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCategorySurvivesWrapping(t *testing.T) {
+	err := RegistryAuthError(fmt.Errorf("unauthorized: authentication required"))
+	err = errors.Wrap(err, "pushing image")
+	err = fmt.Errorf("build failed: %w", err)
+
+	assert.Equal(t, ErrorCategoryRegistryAuth, ErrorCategoryOf(err))
+	assert.Contains(t, ErrorRemediation(err), "docker login")
+	assert.Equal(t, "build failed: pushing image: unauthorized: authentication required", err.Error())
+}
+
+func TestErrorCategoryCause(t *testing.T) {
+	cause := fmt.Errorf("connection refused")
+	err := errors.Wrap(ClusterConnError(cause), "kubectl apply")
+	assert.Equal(t, cause, errors.Cause(err))
+}
+
+func TestUncategorizedError(t *testing.T) {
+	err := fmt.Errorf("oops")
+	assert.Equal(t, ErrorCategoryNone, ErrorCategoryOf(err))
+	assert.Equal(t, "", ErrorRemediation(err))
+	assert.Nil(t, BuildContextError(nil))
+}
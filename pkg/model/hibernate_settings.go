@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Settings for dev hibernation, which scales idle Kubernetes resources
+// to zero and wakes them on the next file change or port-forward request.
+type HibernateSettings struct {
+	Enabled bool
+
+	// A resource is idle when nothing has touched it for this long:
+	// no file changes, no builds, and no port-forward connections.
+	IdleTimeout time.Duration
+
+	// The resources that may hibernate. If empty, any Kubernetes resource may.
+	Resources []ManifestName
+}
+
+func DefaultHibernateSettings() HibernateSettings {
+	return HibernateSettings{
+		Enabled:     false,
+		IdleTimeout: 20 * time.Minute,
+	}
+}
+
+func (s HibernateSettings) Includes(mn ManifestName) bool {
+	if len(s.Resources) == 0 {
+		return true
+	}
+	for _, r := range s.Resources {
+		if r == mn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package model
+
+// How many of the biggest files we keep for each layer.
+const ImageLayerLargestFilesLimit = 5
+
+// A breakdown of the size of a built image, layer by layer.
+//
+// Efficiency works like dive's (https://github.com/wagoodman/dive): bytes
+// that a later layer overwrites or deletes still take up space in the image,
+// so they count as wasted.
+type ImageLayerAnalysis struct {
+	// The image, as the user would write it (e.g., "gcr.io/foo:tilt-123").
+	Image string
+
+	// The sum of the files in all the layers, in bytes.
+	Size int64
+
+	// The bytes of files that a later layer overwrites or deletes.
+	WastedBytes int64
+
+	Layers []ImageLayer
+}
+
+// From 0 (every byte is wasted) to 1 (no bytes are wasted).
+func (a ImageLayerAnalysis) Efficiency() float64 {
+	if a.Size == 0 {
+		return 1
+	}
+	return 1 - float64(a.WastedBytes)/float64(a.Size)
+}
+
+type ImageLayer struct {
+	// The Dockerfile instruction that created the layer, from the image history.
+	CreatedBy string
+
+	// The sum of the files that the layer adds or changes, in bytes.
+	Size int64
+
+	// The layer's biggest files, biggest first.
+	LargestFiles []ImageLayerFile
+}
+
+type ImageLayerFile struct {
+	// An absolute path in the image.
+	Path string
+	Size int64
+}
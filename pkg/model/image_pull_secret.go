@@ -0,0 +1,14 @@
+package model
+
+// A Secret with the credentials for a private registry. Tilt keeps it up to
+// date in every namespace that it deploys pods to, and adds it to their
+// imagePullSecrets, so that the cluster can pull the images Tilt pushed.
+type ImagePullSecret struct {
+	// The name of the Secret.
+	Name string
+
+	// The registry that the credentials are for, e.g., "gcr.io/my-project".
+	Host string
+}
+
+func (s ImagePullSecret) Empty() bool { return s.Name == "" }
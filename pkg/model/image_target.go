@@ -270,10 +270,34 @@ type DockerBuild struct {
 	// Named 'tag' for consistency with how it's used throughout the docker API,
 	// even though this is really more like a reference.NamedTagged
 	ExtraTags []string
+
+	// How much of the build output to show. Resolved against
+	// the global update_settings() when the Tiltfile is loaded.
+	OutputVerbosity BuildOutputVerbosity
+
+	// Warn if the build context is bigger than this many bytes (0 to disable).
+	// Copied from the global update_settings() when the Tiltfile is loaded.
+	ContextWarningSize int64
+
+	// After building, break down the image's size by layer.
+	// Copied from the global update_settings() when the Tiltfile is loaded.
+	AnalyzeLayers bool
+
+	// Where to build the image. Empty means the local Docker daemon.
+	BuildOn BuildLocation
+
+	// Before building, check if the registry already has an image built
+	// from the same inputs (tagged with a hash of the inputs), and use it
+	// instead of building. Lets machines share builds through the registry.
+	RegistryCache bool
 }
 
 func (DockerBuild) buildDetails() {}
 
+func (db DockerBuild) BuildsOnCluster() bool {
+	return db.BuildOn == BuildLocationCluster
+}
+
 type DockerBuildTarget string
 
 func (s DockerBuildTarget) String() string { return string(s) }
@@ -297,6 +321,22 @@ type CustomBuild struct {
 	// We expect the custom build script to print the image ref to this file,
 	// so that Tilt can read it out when we're done.
 	OutputsImageRefTo string
+
+	// Optional: an image to run the command in, so that the build doesn't depend
+	// on the tools installed on the host. WorkDir is mounted into the container
+	// at the same path.
+	Sandbox string
+
+	// Optional: a JSON file that the command writes, mapping the ref of each
+	// image it built to the tagged ref of the result, e.g.,
+	// {"gcr.io/foo/api": "gcr.io/foo/api:abc123"}.
+	//
+	// This lets one command (like a bazel target) build several images.
+	OutputsManifest string
+
+	// The refs of all the images that the command builds, when it writes an
+	// OutputsManifest. Each one gets its own image target with the same CustomBuild.
+	OutputRefs []string
 }
 
 func (CustomBuild) buildDetails() {}
@@ -310,4 +350,9 @@ func (cb CustomBuild) SkipsPush() bool {
 	return cb.SkipsLocalDocker || cb.DisablePush
 }
 
+// Whether the command builds several images at once, and reports them in an OutputsManifest.
+func (cb CustomBuild) HasOutputsManifest() bool {
+	return cb.OutputsManifest != ""
+}
+
 var _ TargetSpec = ImageTarget{}
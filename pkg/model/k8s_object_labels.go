@@ -0,0 +1,22 @@
+package model
+
+// The labels and annotations that Tilt adds to every Kubernetes object it applies.
+//
+// Some clusters have admission policies that reject objects without certain
+// labels (e.g., team or owner), or that reserve the tilt.dev/ prefix.
+type K8sObjectLabels struct {
+	// The prefix for the labels and annotations that Tilt uses to track which
+	// session applied an object (e.g., "<prefix>/session"), so that
+	// `tilt gc-cluster` can prune it later. If empty, Tilt uses "tilt.dev".
+	Prefix string
+
+	// Labels to add to every object, and to the pods it creates.
+	Labels []LabelPair
+
+	// Annotations to add to every object.
+	Annotations []LabelPair
+}
+
+func (l K8sObjectLabels) Empty() bool {
+	return l.Prefix == "" && len(l.Labels) == 0 && len(l.Annotations) == 0
+}
@@ -0,0 +1,35 @@
+package model
+
+// When to load a resource's seed data.
+type SeedTrigger string
+
+const (
+	// Once per Tilt session, the first time the resource's pod is ready.
+	SeedOnFirstDeploy SeedTrigger = "first-deploy"
+
+	// Every time Tilt deploys a new pod for the resource, once it's ready.
+	SeedOnEveryDeploy SeedTrigger = "every-deploy"
+)
+
+// The psql command that seed_sql() runs by default, with the connection
+// settings of the official postgres image.
+var DefaultSeedSQLCmd = ToUnixCmd(`psql -v ON_ERROR_STOP=1 -U "${POSTGRES_USER:-postgres}" -d "${POSTGRES_DB:-${POSTGRES_USER:-postgres}}"`)
+
+// Data to load into a resource's pod once it's ready, e.g., to fill a dev database.
+type K8sSeed struct {
+	// A local file. Tilt reads it each time it loads the seed data,
+	// and sends it to Cmd's stdin.
+	Path string
+
+	// Runs in the pod's container.
+	Cmd Cmd
+
+	// The container to run Cmd in. If empty, the pod's first container.
+	Container string
+
+	On SeedTrigger
+}
+
+func (s K8sSeed) Empty() bool {
+	return s.Path == ""
+}
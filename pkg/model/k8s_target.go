@@ -13,6 +13,13 @@ type K8sImageLocator interface {
 	EqualsImageLocator(other interface{}) bool
 }
 
+// Changes a resource's k8s objects at deploy time, after Tilt injects images.
+//
+// Implemented by the Tiltfile's k8s_resource(transform=fn).
+type K8sTransform interface {
+	EqualsK8sTransform(other interface{}) bool
+}
+
 // Whether or not to wait for pods to become ready before
 // marking the k8s resource healthy.
 //
@@ -46,6 +53,53 @@ type K8sTarget struct {
 
 	PodReadinessMode PodReadinessMode
 
+	// How to shrink the pods in the YAML before deploying them to a dev cluster.
+	DevResourceProfile DevResourceProfile
+
+	// By default, Tilt changes StatefulSets to start their pods in parallel.
+	// If true, keep the StatefulSet's own pod management policy
+	// (e.g., for databases that need pods to come up one at a time).
+	OrderedPodManagement bool
+
+	// If true, `tilt down` deletes the PersistentVolumeClaims created by
+	// StatefulSets' volumeClaimTemplates. Kubernetes leaves them behind by default.
+	DeletePVCs bool
+
+	// If true, Tilt pins the workloads to 1 replica, and pauses the
+	// HorizontalPodAutoscalers and KEDA ScaledObjects that scale them
+	// while Tilt is running, so that autoscaling doesn't fight live updates.
+	PauseAutoscaling bool
+
+	// How to replace the pods when Tilt redeploys.
+	PodReplacement PodReplacement
+
+	// If non-empty, the namespaces that the objects were copied into with
+	// k8s_resource(namespaces=). Tilt tracks the rollout in each one.
+	Namespaces []string
+
+	// If non-empty, the pull secret for the private default_registry that
+	// the images are pushed to.
+	ImagePullSecret ImagePullSecret
+
+	// Extra labels and annotations for every object Tilt applies.
+	ObjectLabels K8sObjectLabels
+
+	// Objects that Tilt watches and shows the status of, but never applies or
+	// deletes, because something else (like a GitOps controller) owns them.
+	// They're in ObjectRefs and DisplayNames, but not in the YAML.
+	ObservedObjectRefs []v1.ObjectReference
+
+	// If non-nil, how to change the objects just before Tilt deploys them.
+	// Implements k8s.EntityTransform.
+	Transform K8sTransform
+
+	// If non-empty, data to load into the resource's pod once it's ready.
+	Seed K8sSeed
+
+	// Where each object came from in the user's YAML (e.g., "k8s/app.yaml:12"),
+	// keyed by k8s.ObjectSourceKey. Used to point warnings at the offending YAML.
+	ObjectSources map[string]string
+
 	// Implementations of k8s.ImageLocator
 	//
 	// NOTE(nick): Untangling the circular dependency between k8s and pkg/model is
@@ -87,13 +141,39 @@ func (k8s K8sTarget) Validate() error {
 		return fmt.Errorf("[Validate] K8s resources missing name:\n%s", k8s.YAML)
 	}
 
-	if k8s.YAML == "" {
+	if k8s.YAML == "" && len(k8s.ObservedObjectRefs) == 0 {
 		return fmt.Errorf("[Validate] K8s resources %q missing YAML", k8s.Name)
 	}
 
 	return nil
 }
 
+// The display names of the objects that Tilt applies,
+// i.e., everything but the observed objects.
+func (k8s K8sTarget) ManagedDisplayNames() []string {
+	if len(k8s.ObservedObjectRefs) == 0 {
+		return k8s.DisplayNames
+	}
+
+	result := []string{}
+	for i, name := range k8s.DisplayNames {
+		if i < len(k8s.ObjectRefs) && k8s.IsObserved(k8s.ObjectRefs[i]) {
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+func (k8s K8sTarget) IsObserved(ref v1.ObjectReference) bool {
+	for _, observed := range k8s.ObservedObjectRefs {
+		if observed == ref {
+			return true
+		}
+	}
+	return false
+}
+
 func (k8s K8sTarget) ID() TargetID {
 	return TargetID{
 		Type: TargetTypeK8s,
@@ -106,6 +186,16 @@ func (k8s K8sTarget) WithDependencyIDs(ids []TargetID) K8sTarget {
 	return k8s
 }
 
+func (k8s K8sTarget) WithDevResourceProfile(profile DevResourceProfile) K8sTarget {
+	k8s.DevResourceProfile = profile
+	return k8s
+}
+
+func (k8s K8sTarget) WithObjectLabels(labels K8sObjectLabels) K8sTarget {
+	k8s.ObjectLabels = labels
+	return k8s
+}
+
 func (k8s K8sTarget) WithRefInjectCounts(ric map[string]int) K8sTarget {
 	k8s.refInjectCounts = ric
 	return k8s
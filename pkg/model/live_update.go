@@ -4,14 +4,24 @@ import (
 	"github.com/pkg/errors"
 )
 
+// When more than this many files change at once, fall back to an image build.
+// A change that big is usually a branch switch or a dependency update,
+// where syncing file-by-file is slower than rebuilding.
+const DefaultLiveUpdateFallBackOnChangeCount = 500
+
 // Specifies how to update a running container.
-// 0. If any paths specified in a FallBackOn step have changed, fall back to an image build
-//    (i.e. don't do a LiveUpdate)
+// 0. If any paths specified in a FallBackOn step have changed, or too many files
+//    have changed at once, fall back to an image build (i.e. don't do a LiveUpdate)
 // 1. If there are Sync steps in `Steps`, files will be synced as specified.
 // 2. Any time we sync one or more files, all Run and RestartContainer steps will be evaluated.
+// 3. After a successful update, the containers of any RestartResource resources are restarted.
 type LiveUpdate struct {
 	Steps   []LiveUpdateStep
 	BaseDir string // directory where the LiveUpdate was initialized (we'll use this to eval. any relative paths)
+
+	// Fall back to an image build when more than this many files change at once.
+	// 0 means DefaultLiveUpdateFallBackOnChangeCount, and a negative number means never.
+	FallBackOnChangeCount int
 }
 
 func NewLiveUpdate(steps []LiveUpdateStep, baseDir string) (LiveUpdate, error) {
@@ -34,19 +44,35 @@ func NewLiveUpdate(steps []LiveUpdateStep, baseDir string) (LiveUpdate, error) {
 		}
 	}
 
+	// restart_resource steps may follow restart_container, since they
+	// act on other resources.
+	lastStep := len(steps) - 1
+	for lastStep > 0 {
+		if _, ok := steps[lastStep].(LiveUpdateRestartResourceStep); !ok {
+			break
+		}
+		lastStep--
+	}
+
 	seenRunStep := false
+	seenRestartResourceStep := false
 	for i, step := range steps {
 		switch step.(type) {
 		case LiveUpdateSyncStep:
 			if seenRunStep {
 				return LiveUpdate{}, errors.New("all sync steps must precede all run steps")
 			}
+			if seenRestartResourceStep {
+				return LiveUpdate{}, errors.New("all sync steps must precede all restart_resource steps")
+			}
 		case LiveUpdateRunStep:
 			seenRunStep = true
 		case LiveUpdateRestartContainerStep:
-			if i != len(steps)-1 {
+			if i != lastStep {
 				return LiveUpdate{}, errors.New("restart container is only valid as the last step")
 			}
+		case LiveUpdateRestartResourceStep:
+			seenRestartResourceStep = true
 		}
 	}
 	return LiveUpdate{Steps: steps, BaseDir: baseDir}, nil
@@ -54,6 +80,29 @@ func NewLiveUpdate(steps []LiveUpdateStep, baseDir string) (LiveUpdate, error) {
 
 func (lu LiveUpdate) Empty() bool { return len(lu.Steps) == 0 }
 
+// MaxChangedFiles returns how many files may change at once before we fall back
+// to an image build, or 0 if there's no limit.
+func (lu LiveUpdate) MaxChangedFiles() int {
+	if lu.FallBackOnChangeCount < 0 {
+		return 0
+	}
+	if lu.FallBackOnChangeCount == 0 {
+		return DefaultLiveUpdateFallBackOnChangeCount
+	}
+	return lu.FallBackOnChangeCount
+}
+
+// WithMaxChangedFiles sets the fall back threshold. 0 means never fall back
+// because of the number of changed files.
+func (lu LiveUpdate) WithMaxChangedFiles(n int) LiveUpdate {
+	if n <= 0 {
+		lu.FallBackOnChangeCount = -1
+	} else {
+		lu.FallBackOnChangeCount = n
+	}
+	return lu
+}
+
 type LiveUpdateStep interface {
 	liveUpdateStep()
 }
@@ -98,6 +147,15 @@ type LiveUpdateRestartContainerStep struct{}
 
 func (l LiveUpdateRestartContainerStep) liveUpdateStep() {}
 
+// Specifies that the containers of another resource should be restarted
+// after this resource is successfully live-updated (e.g., because they share
+// a volume or cache the files that were synced).
+type LiveUpdateRestartResourceStep struct {
+	Resource ManifestName
+}
+
+func (l LiveUpdateRestartResourceStep) liveUpdateStep() {}
+
 // FallBackOnFiles returns a PathSet of files which, if any have changed, indicate
 // that we should fall back to an image build.
 func (lu LiveUpdate) FallBackOnFiles() PathSet {
@@ -134,12 +192,34 @@ func (lu LiveUpdate) RunSteps() []Run {
 }
 
 func (lu LiveUpdate) ShouldRestart() bool {
-	if len(lu.Steps) > 0 {
-		// Currently we require that the Restart step, if present, must be the last step.
-		last := lu.Steps[len(lu.Steps)-1]
-		if _, ok := last.(LiveUpdateRestartContainerStep); ok {
+	// Currently we require that the Restart step, if present, must be the last step
+	// (other than restart_resource steps).
+	for i := len(lu.Steps) - 1; i >= 0; i-- {
+		switch lu.Steps[i].(type) {
+		case LiveUpdateRestartResourceStep:
+			continue
+		case LiveUpdateRestartContainerStep:
 			return true
 		}
+		return false
 	}
 	return false
 }
+
+// RestartResources returns the resources to restart after a successful
+// update, without duplicates.
+func (lu LiveUpdate) RestartResources() []ManifestName {
+	var result []ManifestName
+	seen := make(map[ManifestName]bool)
+	for _, step := range lu.Steps {
+		switch step := step.(type) {
+		case LiveUpdateRestartResourceStep:
+			if seen[step.Resource] {
+				continue
+			}
+			seen[step.Resource] = true
+			result = append(result, step.Resource)
+		}
+	}
+	return result
+}
@@ -20,7 +20,7 @@ func TestNewLiveUpdate(t *testing.T) {
 		return
 	}
 
-	assert.Equal(t, LiveUpdate{steps, BaseDir}, lu)
+	assert.Equal(t, LiveUpdate{Steps: steps, BaseDir: BaseDir}, lu)
 }
 
 func TestNewLiveUpdateRestartContainerNotLast(t *testing.T) {
@@ -68,3 +68,35 @@ func TestNewLiveUpdateFallBackOnFiles(t *testing.T) {
 	expectedFallBackFiles := NewPathSet([]string{"a", "b", "c", "d"}, BaseDir)
 	assert.Equal(t, expectedFallBackFiles, lu.FallBackOnFiles())
 }
+
+func TestNewLiveUpdateRestartResourceAfterRestartContainer(t *testing.T) {
+	steps := []LiveUpdateStep{
+		LiveUpdateSyncStep{"foo", "bar"},
+		LiveUpdateRestartContainerStep{},
+		LiveUpdateRestartResourceStep{"cache"},
+		LiveUpdateRestartResourceStep{"sidecar"},
+		LiveUpdateRestartResourceStep{"cache"},
+	}
+	lu, err := NewLiveUpdate(steps, BaseDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, lu.ShouldRestart())
+	assert.Equal(t, []ManifestName{"cache", "sidecar"}, lu.RestartResources())
+}
+
+func TestNewLiveUpdateSyncAfterRestartResource(t *testing.T) {
+	steps := []LiveUpdateStep{LiveUpdateRestartResourceStep{"cache"}, LiveUpdateSyncStep{"foo", "bar"}}
+	_, err := NewLiveUpdate(steps, BaseDir)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "all sync steps must precede all restart_resource steps")
+}
+
+func TestLiveUpdateMaxChangedFiles(t *testing.T) {
+	lu := LiveUpdate{}
+	assert.Equal(t, DefaultLiveUpdateFallBackOnChangeCount, lu.MaxChangedFiles())
+	assert.Equal(t, 20, lu.WithMaxChangedFiles(20).MaxChangedFiles())
+	assert.Equal(t, 0, lu.WithMaxChangedFiles(0).MaxChangedFiles())
+}
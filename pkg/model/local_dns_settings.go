@@ -0,0 +1,23 @@
+package model
+
+// Settings for the local DNS server, which resolves stable hostnames
+// (like api.tilt.local) to the port-forwards of running resources.
+type LocalDNSSettings struct {
+	Enabled bool
+
+	// Tilt answers queries for names under this domain.
+	Domain string
+
+	// The UDP port on 127.0.0.1 that the DNS server listens on.
+	// Point your system resolver at it for Domain, e.g., on macOS,
+	// with a file in /etc/resolver.
+	Port int
+}
+
+func DefaultLocalDNSSettings() LocalDNSSettings {
+	return LocalDNSSettings{
+		Enabled: false,
+		Domain:  "tilt.local",
+		Port:    10353,
+	}
+}
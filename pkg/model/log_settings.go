@@ -0,0 +1,45 @@
+package model
+
+import "hash/fnv"
+
+// The width of the resource name column in front of each log line.
+const DefaultLogPrefixWidth = 13
+
+// The colors we give resources when LogSettings.Colors is on.
+// We leave out red, so that a resource never looks like an error.
+var LogColors = []string{"cyan", "green", "yellow", "blue", "magenta"}
+
+// Settings for how Tilt renders logs in the terminal, the HUD, and the web UI.
+type LogSettings struct {
+	// Give each resource's log prefix its own color.
+	Colors bool
+
+	// The width of the resource name column. Longer names are truncated.
+	PrefixWidth int
+
+	// Show the time of each log line.
+	Timestamps bool
+
+	// Print log lines that are JSON objects as a message followed by its
+	// fields, and use their "level" field as the log level.
+	JSON bool
+}
+
+func DefaultLogSettings() LogSettings {
+	return LogSettings{
+		PrefixWidth: DefaultLogPrefixWidth,
+	}
+}
+
+// The color of the resource's log prefix, or "" if colors are off.
+//
+// Colors only depend on the resource name, so that a resource keeps its color
+// across Tilt sessions.
+func (s LogSettings) ColorFor(mn ManifestName) string {
+	if !s.Colors || mn == "" || mn == TiltfileManifestName {
+		return ""
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(mn))
+	return LogColors[h.Sum32()%uint32(len(LogColors))]
+}
@@ -0,0 +1,111 @@
+package logstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+var jsonMessageKeys = []string{"msg", "message"}
+var jsonLevelKeys = []string{"level", "severity", "lvl"}
+var jsonTimeKeys = []string{"time", "ts", "timestamp"}
+
+// If the segment is a line with a JSON object, rewrite it as
+// "message key=value ...", and use the object's level as the log level.
+//
+// Anything that isn't a complete line with a JSON object is left alone.
+func formatJSONSegment(seg LogSegment) LogSegment {
+	if !seg.IsComplete() {
+		return seg
+	}
+	text := bytes.TrimSpace(seg.Text)
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return seg
+	}
+
+	var obj map[string]interface{}
+	err := json.Unmarshal(text, &obj)
+	if err != nil {
+		return seg
+	}
+
+	msg := popString(obj, jsonMessageKeys)
+	level, hasLevel := jsonLevel(popString(obj, jsonLevelKeys))
+	for _, key := range jsonTimeKeys {
+		delete(obj, key)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	if msg != "" {
+		parts = append(parts, msg)
+	}
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, jsonValueString(obj[key])))
+	}
+
+	seg.Text = []byte(strings.Join(parts, " ") + "\n")
+	if hasLevel {
+		seg.Level = level
+		if level.AsSevereAs(logger.WarnLvl) {
+			seg.Anchor = true
+		}
+	}
+	return seg
+}
+
+func popString(obj map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		v, ok := obj[key]
+		if !ok {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		delete(obj, key)
+		return s
+	}
+	return ""
+}
+
+func jsonLevel(s string) (logger.Level, bool) {
+	switch strings.ToLower(s) {
+	case "debug", "trace":
+		return logger.DebugLvl, true
+	case "info", "notice":
+		return logger.InfoLvl, true
+	case "warn", "warning":
+		return logger.WarnLvl, true
+	case "error", "err", "fatal", "panic", "critical", "crit":
+		return logger.ErrorLvl, true
+	}
+	return logger.NoneLvl, false
+}
+
+func jsonValueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if strings.ContainsAny(v, " \t\"=") || v == "" {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	case nil:
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -63,18 +63,28 @@ func (b *logLineBuilder) build(options logOptions) []LogLine {
 	return result
 }
 
+// Writes the timestamp and the resource name in front of a line.
+//
+// If this line continues a line we've already printed, it already has them.
+func (b *logLineBuilder) writePrefix(sb *strings.Builder, options logOptions, withTime bool) {
+	if options.skipFirstLineManifestPrefix && b.isFirstLine {
+		return
+	}
+	if withTime && options.settings.Timestamps {
+		sb.WriteString(b.segments[0].Time.Format("15:04:05"))
+		sb.WriteString(" ")
+	}
+	if options.showManifestPrefix && b.span.ManifestName != "" {
+		sb.WriteString(SourcePrefixWithSettings(b.span.ManifestName, options.settings))
+	}
+}
+
 func (b *logLineBuilder) buildSpaceLine(options logOptions) LogLine {
 	sb := strings.Builder{}
-	span := b.span
 	segment := b.segments[0]
 	spanID := segment.SpanID
 	time := segment.Time
-	if options.showManifestPrefix && span.ManifestName != "" {
-		shouldSkip := options.skipFirstLineManifestPrefix && b.isFirstLine
-		if !shouldSkip {
-			sb.WriteString(SourcePrefix(span.ManifestName))
-		}
-	}
+	b.writePrefix(&sb, options, false)
 	sb.WriteString("\n")
 
 	return LogLine{
@@ -86,19 +96,13 @@ func (b *logLineBuilder) buildSpaceLine(options logOptions) LogLine {
 
 func (b *logLineBuilder) buildMainLine(options logOptions) LogLine {
 	segment := b.segments[0]
-	span := b.span
 	spanID := segment.SpanID
 	time := segment.Time
 	progressID := segment.Fields[logger.FieldNameProgressID]
 	progressMustPrint := segment.Fields[logger.FieldNameProgressMustPrint] == "1"
 
 	sb := strings.Builder{}
-	if options.showManifestPrefix && span.ManifestName != "" {
-		shouldSkip := options.skipFirstLineManifestPrefix && b.isFirstLine
-		if !shouldSkip {
-			sb.WriteString(SourcePrefix(span.ManifestName))
-		}
-	}
+	b.writePrefix(&sb, options, true)
 
 	if segment.Anchor {
 		// TODO(nick): Add Terminal colors when supported.
@@ -58,7 +58,24 @@ type LogSegment struct {
 
 // Whether these two log segments may be printed on the same line
 func (l LogSegment) CanContinueLine(other LogSegment) bool {
-	return l.SpanID == other.SpanID && l.Level == other.Level
+	return l.SpanID == other.SpanID && l.Level == other.Level &&
+		l.buildDetail() == other.buildDetail()
+}
+
+func (l LogSegment) buildDetail() string {
+	return l.Fields[logger.FieldNameBuildDetail]
+}
+
+// Whether this segment is hidden, given whether the user asked to see the
+// full build output. See logger.FieldNameBuildDetail.
+func (l LogSegment) IsHiddenBuildDetail(includeBuildDetail bool) bool {
+	switch l.buildDetail() {
+	case logger.BuildDetailHidden:
+		return !includeBuildDetail
+	case logger.BuildDetailReplay:
+		return includeBuildDetail
+	}
+	return false
 }
 
 func (l LogSegment) StartsLine() bool {
@@ -243,6 +260,10 @@ func (s *LogStore) ScrubSecretsStartingAt(secrets model.SecretSet, checkpoint Ch
 }
 
 func (s *LogStore) Append(le LogEvent, secrets model.SecretSet) {
+	s.AppendWithOptions(le, secrets, AppendOptions{})
+}
+
+func (s *LogStore) AppendWithOptions(le LogEvent, secrets model.SecretSet, opts AppendOptions) {
 	spanID := le.SpanID()
 	if spanID == "" && le.ManifestName() != "" {
 		spanID = SpanID(fmt.Sprintf("unknown:%s", le.ManifestName()))
@@ -270,10 +291,21 @@ func (s *LogStore) Append(le LogEvent, secrets model.SecretSet) {
 
 	added[0].ContinuesLine = s.computeContinuesLine(added[0], span)
 
+	if opts.FormatJSON {
+		for i, seg := range added {
+			if i == 0 && seg.ContinuesLine {
+				continue
+			}
+			added[i] = formatJSONSegment(seg)
+		}
+	}
+
 	s.segments = append(s.segments, added...)
 	span.LastSegmentIndex = len(s.segments) - 1
 
-	s.len += len(msg)
+	for _, seg := range added {
+		s.len += seg.Len()
+	}
 	s.ensureMaxLength()
 }
 
@@ -283,20 +315,33 @@ func (s *LogStore) Empty() bool {
 
 // Get at most N lines from the tail of the log.
 func (s *LogStore) Tail(n int) string {
-	return s.tailHelper(n, s.spans, true)
+	return s.TailWithSettings(n, model.LogSettings{})
+}
+
+func (s *LogStore) TailWithSettings(n int, settings model.LogSettings) string {
+	return s.tailHelper(n, s.spans, true, settings)
 }
 
 // Get at most N lines from the tail of the span.
 func (s *LogStore) TailSpan(n int, spanID SpanID) string {
+	return s.TailSpanWithSettings(n, spanID, model.LogSettings{})
+}
+
+func (s *LogStore) TailSpanWithSettings(n int, spanID SpanID, settings model.LogSettings) string {
 	spans, ok := s.idToSpanMap(spanID)
 	if !ok {
 		return ""
 	}
-	return s.tailHelper(n, spans, false)
+	return s.tailHelper(n, spans, false, settings)
+}
+
+// Get at most N lines from the tail of the manifest's logs.
+func (s *LogStore) TailManifestWithSettings(n int, mn model.ManifestName, settings model.LogSettings) string {
+	return s.tailHelper(n, s.spansForManifest(mn), false, settings)
 }
 
 // Get at most N lines from the tail of the log.
-func (s *LogStore) tailHelper(n int, spans map[SpanID]*Span, showManifestPrefix bool) string {
+func (s *LogStore) tailHelper(n int, spans map[SpanID]*Span, showManifestPrefix bool, settings model.LogSettings) string {
 	if n <= 0 {
 		return ""
 	}
@@ -315,7 +360,7 @@ func (s *LogStore) tailHelper(n int, spans map[SpanID]*Span, showManifestPrefix
 			continue
 		}
 
-		if segment.StartsLine() {
+		if segment.StartsLine() && !segment.IsHiddenBuildDetail(false) {
 			remaining--
 			if remaining <= 0 {
 				break
@@ -328,6 +373,7 @@ func (s *LogStore) tailHelper(n int, spans map[SpanID]*Span, showManifestPrefix
 		return s.toLogString(logOptions{
 			spans:              spans,
 			showManifestPrefix: showManifestPrefix,
+			settings:           settings,
 		})
 	}
 
@@ -353,9 +399,58 @@ func (s *LogStore) tailHelper(n int, spans map[SpanID]*Span, showManifestPrefix
 	return tempStore.toLogString(logOptions{
 		spans:              tempStore.spans,
 		showManifestPrefix: showManifestPrefix,
+		settings:           settings,
 	})
 }
 
+// The public view of a span: where its logs start and end.
+type SpanInfo struct {
+	ManifestName    model.ManifestName
+	FirstCheckpoint Checkpoint
+	LastCheckpoint  Checkpoint
+}
+
+// Returns every span that still has logs in the store.
+func (s *LogStore) Spans() map[SpanID]SpanInfo {
+	result := make(map[SpanID]SpanInfo, len(s.spans))
+	for spanID, span := range s.spans {
+		result[spanID] = SpanInfo{
+			ManifestName:    span.ManifestName,
+			FirstCheckpoint: s.checkpointFromIndex(span.FirstSegmentIndex),
+			LastCheckpoint:  s.checkpointFromIndex(span.LastSegmentIndex),
+		}
+	}
+	return result
+}
+
+// A log segment, with the resource that logged it.
+type ManifestSegment struct {
+	ManifestName model.ManifestName
+	LogSegment
+}
+
+// Returns the segments logged since the checkpoint, in the order they were logged.
+func (s *LogStore) SegmentsSince(checkpoint Checkpoint) []ManifestSegment {
+	startIndex := s.checkpointToIndex(checkpoint)
+	if startIndex >= len(s.segments) {
+		return nil
+	}
+
+	result := make([]ManifestSegment, 0, len(s.segments)-startIndex)
+	for _, segment := range s.segments[startIndex:] {
+		var mn model.ManifestName
+		span, ok := s.spans[segment.SpanID]
+		if ok {
+			mn = span.ManifestName
+		}
+		result = append(result, ManifestSegment{
+			ManifestName: mn,
+			LogSegment:   segment,
+		})
+	}
+	return result
+}
+
 func (s *LogStore) cloneSpanMap() map[SpanID]*Span {
 	newSpans := make(map[SpanID]*Span, len(s.spans))
 	for spanID, span := range s.spans {
@@ -488,6 +583,8 @@ func (s *LogStore) ContinuingLinesWithOptions(checkpoint Checkpoint, opts LineOp
 		spans:                       spans,
 		showManifestPrefix:          !opts.SuppressPrefix,
 		skipFirstLineManifestPrefix: isSameSpanContinuation,
+		includeBuildDetail:          opts.IncludeBuildDetail,
+		settings:                    opts.Settings,
 	})
 
 	if isSameSpanContinuation {
@@ -658,11 +755,25 @@ type logOptions struct {
 	spans                       map[SpanID]*Span // only print logs for these spans
 	showManifestPrefix          bool
 	skipFirstLineManifestPrefix bool
+	includeBuildDetail          bool
+	settings                    model.LogSettings
 }
 
 type LineOptions struct {
 	ManifestNames  model.ManifestNameSet // only print logs for these manifests
 	SuppressPrefix bool
+
+	// Include the full build output, even the parts that the user's
+	// build output settings hide.
+	IncludeBuildDetail bool
+
+	// The user's log_settings(). The zero value prints plain logs.
+	Settings model.LogSettings
+}
+
+type AppendOptions struct {
+	// Reformat lines that are JSON objects. See model.LogSettings.JSON.
+	FormatJSON bool
 }
 
 func (s *LogStore) toLogString(options logOptions) string {
@@ -712,6 +823,10 @@ func (s *LogStore) toLogLines(options logOptions) []LogLine {
 			continue
 		}
 
+		if segment.IsHiddenBuildDetail(options.includeBuildDetail) {
+			continue
+		}
+
 		// If the last segment never completed, print a newline now, so that the
 		// logs from different sources don't blend together.
 		if lineBuilder != nil {
@@ -281,6 +281,45 @@ func TestManifestLog(t *testing.T) {
 	assert.Equal(t, "a\nb\n", l.ManifestLog("back"))
 }
 
+func TestTailManifest(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newGlobalTestLogEvent("1\n2\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "3\n4\n"), nil)
+	l.Append(newTestLogEvent("back", time.Now(), "a\nb\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "5\n6\n"), nil)
+	assert.Equal(t, "4\n5\n6\n", l.TailManifestWithSettings(3, "fe", model.LogSettings{}))
+	assert.Equal(t, "a\nb\n", l.TailManifestWithSettings(3, "back", model.LogSettings{}))
+	assert.Equal(t, "", l.TailManifestWithSettings(3, "missing", model.LogSettings{}))
+}
+
+func TestSpans(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newGlobalTestLogEvent("1\n2\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "3\n4\n"), nil)
+	l.Append(newGlobalTestLogEvent("5\n6\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "7\n8\n"), nil)
+
+	spans := l.Spans()
+	assert.Equal(t, SpanInfo{ManifestName: "fe", FirstCheckpoint: 2, LastCheckpoint: 7}, spans["fe"])
+}
+
+func TestSegmentsSince(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newGlobalTestLogEvent("1\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "2\n"), nil)
+	l.Append(newTestLogEvent("be", time.Now(), "3\n"), nil)
+
+	segments := l.SegmentsSince(1)
+	if assert.Equal(t, 2, len(segments)) {
+		assert.Equal(t, model.ManifestName("fe"), segments[0].ManifestName)
+		assert.Equal(t, "2\n", string(segments[0].Text))
+		assert.Equal(t, model.ManifestName("be"), segments[1].ManifestName)
+		assert.Equal(t, "3\n", string(segments[1].Text))
+	}
+
+	assert.Empty(t, l.SegmentsSince(l.Checkpoint()))
+}
+
 func TestManifestLogContinuation(t *testing.T) {
 	l := NewLogStore()
 	l.Append(newGlobalTestLogEvent("1\n2\n"), nil)
@@ -522,6 +561,106 @@ func TestBuildEventInit(t *testing.T) {
 	assertSnapshot(t, l.String())
 }
 
+func TestBuildDetail(t *testing.T) {
+	l := NewLogStore()
+
+	now := time.Now()
+	detail := func(msg, val string) testLogEvent {
+		return testLogEvent{
+			name:    "fe",
+			message: msg,
+			ts:      now,
+			level:   logger.InfoLvl,
+			fields:  map[string]string{logger.FieldNameBuildDetail: val},
+		}
+	}
+
+	l.Append(newTestLogEvent("fe", now, "[1/2] RUN npm install\n"), nil)
+	l.Append(detail("  → added 1 package\n", logger.BuildDetailHidden), nil)
+	l.Append(detail("  → npm ERR! oops\n", logger.BuildDetailHidden), nil)
+	l.Append(newTestLogEvent("fe", now, "ERROR IN: [1/2] RUN npm install\n"), nil)
+	l.Append(detail("  → added 1 package\n  → npm ERR! oops\n", logger.BuildDetailReplay), nil)
+
+	assert.Equal(t, "[1/2] RUN npm install\n"+
+		"ERROR IN: [1/2] RUN npm install\n"+
+		"  → added 1 package\n"+
+		"  → npm ERR! oops\n", l.ManifestLog("fe"))
+	assert.Equal(t, "ERROR IN: [1/2] RUN npm install\n"+
+		"  → added 1 package\n"+
+		"  → npm ERR! oops\n", l.TailSpan(3, l.segments[0].SpanID))
+
+	lines := l.ContinuingLinesWithOptions(0, LineOptions{SuppressPrefix: true, IncludeBuildDetail: true})
+	assert.Equal(t, "[1/2] RUN npm install\n"+
+		"  → added 1 package\n"+
+		"  → npm ERR! oops\n"+
+		"ERROR IN: [1/2] RUN npm install\n", linesToString(lines))
+}
+
+func TestLogSettingsPrefixWidth(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newTestLogEvent("frontend", time.Now(), "1\n"), nil)
+
+	settings := model.LogSettings{PrefixWidth: 5}
+	assert.Equal(t, "fron… │ 1\n", l.TailWithSettings(1, settings))
+
+	lines := l.ContinuingLinesWithOptions(0, LineOptions{Settings: model.LogSettings{PrefixWidth: 10}})
+	assert.Equal(t, "  frontend │ 1\n", linesToString(lines))
+}
+
+func TestLogSettingsColors(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newTestLogEvent("fe", time.Now(), "1\n"), nil)
+
+	settings := model.LogSettings{Colors: true, PrefixWidth: 4}
+	color := ansiColors[settings.ColorFor("fe")]
+	assert.NotEqual(t, "", color)
+	assert.Equal(t, "  "+color+"fe"+ansiReset+" │ 1\n", l.TailWithSettings(1, settings))
+
+	// Colors are stable across calls.
+	assert.Equal(t, settings.ColorFor("fe"), settings.ColorFor("fe"))
+	assert.Equal(t, "", settings.ColorFor(model.TiltfileManifestName))
+}
+
+func TestLogSettingsTimestamps(t *testing.T) {
+	l := NewLogStore()
+	ts := time.Date(2020, 1, 2, 15, 4, 5, 0, time.Local)
+	l.Append(newTestLogEvent("fe", ts, "hello "), nil)
+	l.Append(newTestLogEvent("fe", ts.Add(time.Second), "world\n"), nil)
+	l.Append(newGlobalTestLogEvent("global\n"), nil)
+
+	settings := model.LogSettings{Timestamps: true, PrefixWidth: 4}
+	lines := l.ContinuingLinesWithOptions(0, LineOptions{Settings: settings})
+	assert.Equal(t, "15:04:05   fe │ hello world\n", lines[0].Text)
+
+	// A continued line doesn't get a second timestamp.
+	c := l.Checkpoint()
+	l.Append(newTestLogEvent("fe", ts, "hello "), nil)
+	assert.Equal(t, "15:04:05   fe │ hello ", l.ContinuingStringWithOptions(c, LineOptions{Settings: settings}))
+
+	c = l.Checkpoint()
+	l.Append(newTestLogEvent("fe", ts, "again\n"), nil)
+	assert.Equal(t, "again\n", l.ContinuingStringWithOptions(c, LineOptions{Settings: settings}))
+}
+
+func TestAppendJSON(t *testing.T) {
+	l := NewLogStore()
+	opts := AppendOptions{FormatJSON: true}
+	l.AppendWithOptions(newTestLogEvent("fe", time.Now(),
+		`{"level":"info","msg":"listening","port":8080,"ts":"2020-01-02T15:04:05Z"}`+"\n"+
+			`{"level":"warn","message":"slow request","path":"/api users"}`+"\n"+
+			"not json\n"), nil, opts)
+
+	assert.Equal(t, "listening port=8080\n"+
+		"WARNING: slow request path=\"/api users\"\n"+
+		"not json\n", l.ManifestLog("fe"))
+	assert.Equal(t, []string{"slow request path=\"/api users\"\n"}, l.Warnings(l.segments[0].SpanID))
+
+	// Without the option, JSON is left alone.
+	l2 := NewLogStore()
+	l2.Append(newTestLogEvent("fe", time.Now(), `{"msg":"hi"}`+"\n"), nil)
+	assert.Equal(t, `{"msg":"hi"}`+"\n", l2.ManifestLog("fe"))
+}
+
 func assertSnapshot(t *testing.T, output string) {
 	d1 := []byte(output)
 	gmPath := fmt.Sprintf("testdata/%s_master", t.Name())
@@ -7,16 +7,40 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+const ansiReset = "\x1b[0m"
+
+var ansiColors = map[string]string{
+	"cyan":    "\x1b[36m",
+	"green":   "\x1b[32m",
+	"yellow":  "\x1b[33m",
+	"blue":    "\x1b[34m",
+	"magenta": "\x1b[35m",
+}
+
 func SourcePrefix(n model.ManifestName) string {
+	return SourcePrefixWithSettings(n, model.DefaultLogSettings())
+}
+
+func SourcePrefixWithSettings(n model.ManifestName, settings model.LogSettings) string {
 	if n == "" || n == model.TiltfileManifestName {
 		return ""
 	}
-	max := 13
+	max := settings.PrefixWidth
+	if max < 1 {
+		max = model.DefaultLogPrefixWidth
+	}
+
+	name := string(n)
 	spaces := ""
-	if len(n) > max {
-		n = n[:max-1] + "…"
+	if len([]rune(name)) > max {
+		name = string([]rune(name)[:max-1]) + "…"
 	} else {
-		spaces = strings.Repeat(" ", max-len(n))
+		spaces = strings.Repeat(" ", max-len([]rune(name)))
+	}
+
+	color, ok := ansiColors[settings.ColorFor(n)]
+	if ok {
+		name = color + name + ansiReset
 	}
-	return fmt.Sprintf("%s%s │ ", spaces, n)
+	return fmt.Sprintf("%s%s │ ", spaces, name)
 }
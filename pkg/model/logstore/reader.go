@@ -1,6 +1,10 @@
 package logstore
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
 
 // Thread-safe reading a log store, outside of the Store state loop.
 type Reader struct {
@@ -82,6 +86,36 @@ func (r Reader) TailSpan(n int, spanID SpanID) string {
 	return r.store.TailSpan(n, spanID)
 }
 
+func (r Reader) TailWithSettings(n int, settings model.LogSettings) string {
+	if r.store == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.TailWithSettings(n, settings)
+}
+
+func (r Reader) TailSpanWithSettings(n int, spanID SpanID, settings model.LogSettings) string {
+	if r.store == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.TailSpanWithSettings(n, spanID, settings)
+}
+
+func (r Reader) TailManifestWithSettings(n int, mn model.ManifestName, settings model.LogSettings) string {
+	if r.store == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store.TailManifestWithSettings(n, mn, settings)
+}
+
 func (r Reader) Warnings(spanID SpanID) []string {
 	if r.store == nil {
 		return nil
@@ -44,6 +44,18 @@ type Manifest struct {
 	// The resource in this manifest will not be built until all of its dependencies have been
 	// ready at least once.
 	ResourceDependencies []ManifestName
+
+	// Which resources to build first, when several are waiting.
+	BuildPriority BuildPriority
+
+	// Links from the Tiltfile, e.g., k8s_resource(links=[link(...)]),
+	// shown alongside the links Tilt derives from port forwards, etc.
+	Links []Link
+
+	// Why the Tiltfile couldn't load this resource's config, when it runs
+	// with load_settings(on_error='continue_on_error'). Tilt doesn't build
+	// a resource with a config error until the Tiltfile fixes it.
+	ConfigError string
 }
 
 func (m Manifest) ID() TargetID {
@@ -162,6 +174,11 @@ func (m Manifest) WithDeployTarget(t TargetSpec) Manifest {
 	return m
 }
 
+func (m Manifest) WithConfigError(msg string) Manifest {
+	m.ConfigError = msg
+	return m
+}
+
 func (m Manifest) WithTriggerMode(mode TriggerMode) Manifest {
 	m.TriggerMode = mode
 	return m
@@ -443,11 +460,32 @@ type PortForward struct {
 	// Optional name of the port forward; if given, used as text of the URL
 	// displayed in the web UI (e.g. <a href="localhost:8888">Debugger</a>)
 	Name string
+
+	// Optional name of a Service to forward to, instead of the resource's pod.
+	// Connections are load-balanced across the Service's ready endpoints,
+	// and ContainerPort is the port on those endpoints.
+	Service string
+
+	// TCP by default. Kubernetes can only forward TCP, so we forward UDP
+	// through a relay container that we attach to the pod. The relay only
+	// carries one reply per datagram, so it suits request/response protocols.
+	Protocol PortForwardProtocol
+}
+
+type PortForwardProtocol string
+
+const (
+	PortForwardTCP PortForwardProtocol = ""
+	PortForwardUDP PortForwardProtocol = "UDP"
+)
+
+func (pf PortForward) IsUDP() bool {
+	return pf.Protocol == PortForwardUDP
 }
 
 // A link associated with resource; may represent a port forward, an endpoint
 // derived from a Service/Ingress/etc., or a URL manually associated with a
-// resource via the Tiltfile (TK)
+// resource via the Tiltfile with link()
 type Link struct {
 	URL string
 
@@ -494,6 +532,9 @@ var registryAllowUnexported = cmp.AllowUnexported(container.Registry{})
 var ignoreCustomBuildDepsField = cmpopts.IgnoreFields(CustomBuild{}, "Deps")
 var ignoreLocalTargetDepsField = cmpopts.IgnoreFields(LocalTarget{}, "Deps")
 
+// Port-forwards are set up at runtime, and don't change what we deploy.
+var ignoreK8sTargetPortForwardsField = cmpopts.IgnoreFields(K8sTarget{}, "PortForwards")
+
 var dockerRefEqual = cmp.Comparer(func(a, b reference.Named) bool {
 	aNil := a == nil
 	bNil := b == nil
@@ -512,6 +553,13 @@ var imageLocatorEqual = cmp.Comparer(func(a, b K8sImageLocator) bool {
 	return a.EqualsImageLocator(b)
 })
 
+var k8sTransformEqual = cmp.Comparer(func(a, b K8sTransform) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.EqualsK8sTransform(b)
+})
+
 // Determine whether interfaces x and y are equal, excluding fields that don't invalidate a build.
 func equalForBuildInvalidation(x, y interface{}) bool {
 	return cmp.Equal(x, y,
@@ -526,9 +574,13 @@ func equalForBuildInvalidation(x, y interface{}) bool {
 		registryAllowUnexported,
 		dockerRefEqual,
 		imageLocatorEqual,
+		k8sTransformEqual,
 
 		// deps changes don't invalidate a build, so don't compare fields used only for deps
 		ignoreCustomBuildDepsField,
 		ignoreLocalTargetDepsField,
+
+		// the port-forward controller picks up port_forward changes on its own
+		ignoreK8sTargetPortForwardsField,
 	)
 }
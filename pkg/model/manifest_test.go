@@ -68,10 +68,10 @@ var equalitytests = []struct {
 		false,
 	},
 	{
-		"PortForwards unequal",
+		"PortForwards unequal and doesn't invalidate",
 		Manifest{}.WithDeployTarget(K8sTarget{PortForwards: portFwd8000}),
 		Manifest{}.WithDeployTarget(K8sTarget{PortForwards: portFwd8001}),
-		true,
+		false,
 	},
 	{
 		"PortForwards equal",
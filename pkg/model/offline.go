@@ -0,0 +1,9 @@
+package model
+
+// When Tilt is in offline mode, it must not make any outbound network calls
+// of its own: no analytics, no Tilt Cloud lookups or snapshot uploads,
+// no update checks, and no telemetry export.
+//
+// Calls the user explicitly configured (their cluster, their registry)
+// are unaffected.
+type OfflineMode bool
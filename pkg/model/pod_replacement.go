@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// How to replace a resource's pods when Tilt redeploys it.
+//
+// By default, Kubernetes may tear down the old pod as soon as the new one is
+// scheduled, and kills it 30 seconds after SIGTERM. These options make
+// redeploys behave more like a careful production rollout, so that graceful
+// shutdown paths get exercised, and in-flight requests don't fail.
+type PodReplacement struct {
+	// If true, Deployments bring up their new pods and wait for them to be ready
+	// before tearing down the old ones.
+	Surge bool
+
+	// If non-zero, wait this long after a pod is marked for deletion before
+	// sending its containers SIGTERM (with a preStop hook), so that Services
+	// stop routing to it first.
+	//
+	// The hook needs a `sleep` binary in the container, so images without
+	// one (like distroless) get SIGTERM right away.
+	DrainPeriod time.Duration
+
+	// If non-nil, how long to wait after SIGTERM before killing the pod's containers.
+	TerminationGracePeriod *time.Duration
+}
+
+func (p PodReplacement) Empty() bool {
+	return !p.Surge && p.DrainPeriod == 0 && p.TerminationGracePeriod == nil
+}
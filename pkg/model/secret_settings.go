@@ -1,9 +1,25 @@
 package model
 
+// What to do when a build leaks credentials.
+type SecretScanPolicy string
+
+const (
+	SecretScanPolicyFail SecretScanPolicy = "fail"
+	SecretScanPolicyWarn SecretScanPolicy = "warn"
+)
+
 type SecretSettings struct {
 	ScrubSecrets bool // whether to scrub secrets in logs
+
+	// Whether to scan build output and image config for credentials,
+	// and what to do when we find them.
+	ScanBuilds bool
+	ScanPolicy SecretScanPolicy
 }
 
 func DefaultSecretSettings() SecretSettings {
-	return SecretSettings{ScrubSecrets: true}
+	return SecretSettings{
+		ScrubSecrets: true,
+		ScanPolicy:   SecretScanPolicyFail,
+	}
 }
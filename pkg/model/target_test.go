@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/container"
 )
@@ -116,3 +117,23 @@ func newK8sTarget(name string, deps ...string) K8sTarget {
 	}
 	return K8sTarget{Name: TargetName(name)}.WithDependencyIDs(depIDs)
 }
+
+func TestK8sTargetManagedDisplayNames(t *testing.T) {
+	deploy := v1.ObjectReference{Kind: "Deployment", Name: "foo"}
+	svc := v1.ObjectReference{Kind: "Service", Name: "foo"}
+	target := K8sTarget{
+		Name:               "foo",
+		DisplayNames:       []string{"foo:deployment", "foo:service"},
+		ObjectRefs:         []v1.ObjectReference{deploy, svc},
+		ObservedObjectRefs: []v1.ObjectReference{svc},
+	}
+
+	assert.Equal(t, []string{"foo:deployment"}, target.ManagedDisplayNames())
+	assert.True(t, target.IsObserved(svc))
+	assert.False(t, target.IsObserved(deploy))
+
+	// A resource with only observed objects has no YAML to apply.
+	target.ObservedObjectRefs = []v1.ObjectReference{deploy, svc}
+	assert.Empty(t, target.ManagedDisplayNames())
+	assert.NoError(t, target.Validate())
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Where the time went the last time we executed the Tiltfile.
+type TiltfileProfile struct {
+	// How long the whole load took, including assembling manifests.
+	Total time.Duration
+
+	// Time spent in each builtin, slowest first.
+	Builtins []BuiltinTiming
+
+	// Time spent executing each file, slowest first.
+	// Includes the main Tiltfile and every file it load()s.
+	Loads []LoadTiming
+}
+
+type BuiltinTiming struct {
+	Name  string
+	Count int
+	Dur   time.Duration
+}
+
+type LoadTiming struct {
+	Path string
+
+	// Includes the time spent in files that this file loads.
+	Dur time.Duration
+}
+
+func (p TiltfileProfile) Empty() bool {
+	return p.Total == 0 && len(p.Builtins) == 0 && len(p.Loads) == 0
+}
+
+// A human-readable breakdown of the profile.
+func (p TiltfileProfile) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Tiltfile load took %s\n", p.Total))
+
+	if len(p.Loads) > 0 {
+		sb.WriteString("Files (including the files they load):\n")
+		for _, l := range p.Loads {
+			sb.WriteString(fmt.Sprintf("  %10s  %s\n", roundDur(l.Dur), l.Path))
+		}
+	}
+
+	if len(p.Builtins) > 0 {
+		sb.WriteString("Builtins:\n")
+		for _, b := range p.Builtins {
+			sb.WriteString(fmt.Sprintf("  %10s  %s (%d calls)\n", roundDur(b.Dur), b.Name, b.Count))
+		}
+	}
+	return sb.String()
+}
+
+func roundDur(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
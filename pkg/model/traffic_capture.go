@@ -0,0 +1,31 @@
+package model
+
+import "fmt"
+
+// How much of the HTTP traffic through a resource's port forwards to log.
+//
+// When capture is on, Tilt serves a reverse proxy on the port forward's
+// local port, and logs each request to the resource's log.
+type TrafficCaptureMode string
+
+const (
+	// Forward connections directly, without looking at them.
+	TrafficCaptureOff TrafficCaptureMode = ""
+
+	// Log the request line, status, and latency of each request.
+	TrafficCaptureRequests TrafficCaptureMode = "requests"
+
+	// Also log the request and response bodies.
+	TrafficCaptureBodies TrafficCaptureMode = "bodies"
+)
+
+func ParseTrafficCaptureMode(s string) (TrafficCaptureMode, error) {
+	switch mode := TrafficCaptureMode(s); mode {
+	case TrafficCaptureOff, TrafficCaptureRequests, TrafficCaptureBodies:
+		return mode, nil
+	case "off":
+		return TrafficCaptureOff, nil
+	}
+	return TrafficCaptureOff, fmt.Errorf("invalid traffic capture mode %q. Valid values: off, %s, %s",
+		s, TrafficCaptureRequests, TrafficCaptureBodies)
+}
@@ -5,11 +5,25 @@ import "time"
 const (
 	DefaultMaxParallelUpdates = 3
 	DefaultK8sUpsertTimeout   = 30 * time.Second
+	DefaultBuildHistoryLimit  = 20
+
+	// Build contexts bigger than this are usually a mistake (e.g., a forgotten node_modules).
+	DefaultBuildContextWarningSize = 500 * 1000 * 1000
 )
 
 type UpdateSettings struct {
 	maxParallelUpdates int           // max number of updates to run concurrently
 	k8sUpsertTimeout   time.Duration // timeout for k8s upsert operations
+	buildOutput        BuildOutputVerbosity
+
+	// warn when a docker build context is bigger than this many bytes (0 to disable)
+	buildContextWarningSize int64
+
+	buildHistoryLimit int // max number of completed builds to keep per resource
+
+	imageLayerAnalysis bool // break down the size of each built image by layer
+
+	linkDiscovery bool // probe resources' endpoints for well-known paths, like /metrics
 }
 
 func (us UpdateSettings) MaxParallelUpdates() int {
@@ -46,9 +60,69 @@ func (us UpdateSettings) WithK8sUpsertTimeout(timeout time.Duration) UpdateSetti
 	return us
 }
 
+func (us UpdateSettings) BuildOutputVerbosity() BuildOutputVerbosity {
+	if us.buildOutput == BuildOutputDefault {
+		return BuildOutputFull
+	}
+	return us.buildOutput
+}
+
+func (us UpdateSettings) WithBuildOutputVerbosity(v BuildOutputVerbosity) UpdateSettings {
+	us.buildOutput = v
+	return us
+}
+
+func (us UpdateSettings) BuildContextWarningSize() int64 {
+	return us.buildContextWarningSize
+}
+
+func (us UpdateSettings) WithBuildContextWarningSize(bytes int64) UpdateSettings {
+	us.buildContextWarningSize = bytes
+	return us
+}
+
+func (us UpdateSettings) BuildHistoryLimit() int {
+	// Unset means the default. (WithBuildHistoryLimit keeps set values at 1 or
+	// more, so that we always know the last build.)
+	if us.buildHistoryLimit < 1 {
+		return DefaultBuildHistoryLimit
+	}
+	return us.buildHistoryLimit
+}
+
+func (us UpdateSettings) WithBuildHistoryLimit(n int) UpdateSettings {
+	// Min. value is 1
+	if n < 1 {
+		n = 1
+	}
+	us.buildHistoryLimit = n
+	return us
+}
+
+func (us UpdateSettings) ImageLayerAnalysis() bool {
+	return us.imageLayerAnalysis
+}
+
+func (us UpdateSettings) WithImageLayerAnalysis(enabled bool) UpdateSettings {
+	us.imageLayerAnalysis = enabled
+	return us
+}
+
+func (us UpdateSettings) LinkDiscovery() bool {
+	return us.linkDiscovery
+}
+
+func (us UpdateSettings) WithLinkDiscovery(enabled bool) UpdateSettings {
+	us.linkDiscovery = enabled
+	return us
+}
+
 func DefaultUpdateSettings() UpdateSettings {
 	return UpdateSettings{
-		maxParallelUpdates: DefaultMaxParallelUpdates,
-		k8sUpsertTimeout:   DefaultK8sUpsertTimeout,
+		maxParallelUpdates:      DefaultMaxParallelUpdates,
+		k8sUpsertTimeout:        DefaultK8sUpsertTimeout,
+		buildContextWarningSize: DefaultBuildContextWarningSize,
+		buildHistoryLimit:       DefaultBuildHistoryLimit,
+		linkDiscovery:           true,
 	}
 }
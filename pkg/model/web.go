@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/spf13/pflag"
 )
@@ -86,3 +87,42 @@ func (u WebURL) String() string {
 func (u WebURL) Empty() bool {
 	return WebURL{} == u
 }
+
+// The path that the web UI lives under, when a reverse proxy serves it
+// at a subpath (e.g., "/tilt"). Empty when it lives at the root.
+//
+// Always starts with a slash, and never ends with one.
+type WebBasePath string
+
+func NewWebBasePath(p string) (WebBasePath, error) {
+	p = strings.TrimSpace(p)
+	if strings.ContainsAny(p, "?#") {
+		return "", fmt.Errorf("invalid web base path %q: must be a plain path", p)
+	}
+
+	u, err := url.Parse(p)
+	if err != nil || u.Host != "" || u.Scheme != "" {
+		return "", fmt.Errorf("invalid web base path %q: must be a path, not a URL", p)
+	}
+
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "", nil
+	}
+	return WebBasePath("/" + p), nil
+}
+
+func (p WebBasePath) String() string { return string(p) }
+
+// Origins (e.g., "https://ide.example.com") that can make cross-origin
+// requests to the web API. "*" allows any origin.
+type WebCORSOrigins []string
+
+func (o WebCORSOrigins) Allows(origin string) bool {
+	for _, allowed := range o {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebBasePath(t *testing.T) {
+	for input, expected := range map[string]WebBasePath{
+		"":        "",
+		"/":       "",
+		"tilt":    "/tilt",
+		"/tilt":   "/tilt",
+		"/tilt/":  "/tilt",
+		"/a/b/":   "/a/b",
+		" /tilt ": "/tilt",
+	} {
+		actual, err := NewWebBasePath(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, actual, input)
+	}
+
+	for _, input := range []string{"/tilt?x=1", "/tilt#foo", "https://example.com/tilt"} {
+		_, err := NewWebBasePath(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestWebCORSOriginsAllows(t *testing.T) {
+	origins := WebCORSOrigins{"https://ide.example.com/"}
+	assert.True(t, origins.Allows("https://ide.example.com"))
+	assert.True(t, origins.Allows("https://IDE.example.com"))
+	assert.False(t, origins.Allows("https://evil.example.com"))
+	assert.False(t, WebCORSOrigins{}.Allows("https://ide.example.com"))
+	assert.True(t, WebCORSOrigins{"*"}.Allows("https://evil.example.com"))
+}
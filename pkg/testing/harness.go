@@ -0,0 +1,401 @@
+// Package testing runs a Tilt engine against fake Docker and Kubernetes
+// clients and a fake clock, so that tools built around Tiltfiles can run
+// end-to-end scenario tests without a real cluster.
+//
+//	h := testing.NewHarness(t)
+//	defer h.TearDown()
+//
+//	h.WriteFile("Tiltfile", `local_resource("gen", "make gen", deps=["proto"])`)
+//	h.Start()
+//	h.WaitForBuilds("gen", 1)
+//
+//	h.ChangeFile("proto/api.proto")
+//	h.WaitForBuilds("gen", 2)
+package testing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/tilt-dev/wmclient/pkg/analytics"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/cloud"
+	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/engine"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/autoscale"
+	"github.com/tilt-dev/tilt/internal/engine/baseimage"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
+	"github.com/tilt-dev/tilt/internal/engine/buildlogs"
+	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/cronjob"
+	"github.com/tilt-dev/tilt/internal/engine/dcwatch"
+	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/endpointhealth"
+	"github.com/tilt-dev/tilt/internal/engine/exit"
+	"github.com/tilt-dev/tilt/internal/engine/fswatch"
+	"github.com/tilt-dev/tilt/internal/engine/hibernate"
+	"github.com/tilt-dev/tilt/internal/engine/k8scredentials"
+	"github.com/tilt-dev/tilt/internal/engine/k8sheartbeat"
+	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
+	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
+	"github.com/tilt-dev/tilt/internal/engine/linkdiscovery"
+	"github.com/tilt-dev/tilt/internal/engine/local"
+	"github.com/tilt-dev/tilt/internal/engine/localdns"
+	"github.com/tilt-dev/tilt/internal/engine/metrics"
+	"github.com/tilt-dev/tilt/internal/engine/portforward"
+	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
+	"github.com/tilt-dev/tilt/internal/engine/scheduler"
+	"github.com/tilt-dev/tilt/internal/engine/seed"
+	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/watchdog"
+	"github.com/tilt-dev/tilt/internal/engine/workloadstatus"
+	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/hud/prompt"
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/listeners"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/synclet"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
+	"github.com/tilt-dev/tilt/internal/testutils/httptest"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/config"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/internal/tracer"
+	"github.com/tilt-dev/tilt/internal/watch"
+	"github.com/tilt-dev/tilt/pkg/assets"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How long the Wait* helpers wait before failing the test.
+const DefaultTimeout = 10 * time.Second
+
+// Runs a Tilt session in a temp directory.
+//
+// Builds go through the real build and deploy pipeline, but against
+// fake Docker, Kubernetes, and Docker Compose clients. File changes
+// are simulated with ChangeFile, and time only moves with Advance.
+type Harness struct {
+	*tempdir.TempDirFixture
+
+	t      *testing.T
+	ctx    context.Context
+	cancel func()
+
+	clock      clockwork.FakeClock
+	docker     *docker.FakeClient
+	kCli       *k8s.FakeK8sClient
+	dcCli      *dockercompose.FakeDCClient
+	fsWatcher  *fswatch.FakeMultiWatcher
+	fwm        *fswatch.WatchManager
+	store      *store.Store
+	upper      engine.Upper
+	log        *bufsync.ThreadSafeBuffer
+	builds     map[model.ManifestName]int
+	onChangeCh chan bool
+	initResult chan error
+}
+
+func NewHarness(t *testing.T) *Harness {
+	f := tempdir.NewTempDirFixture(t)
+
+	log := bufsync.NewThreadSafeBuffer()
+	opter := tiltanalytics.NewFakeOpter(analytics.OptIn)
+	ctx, _, ta := testutils.ForkedCtxAndAnalyticsWithOpterForTest(log, opter)
+	ctx, cancel := context.WithCancel(ctx)
+
+	clock := clockwork.NewFakeClockAt(time.Now())
+	env := k8s.EnvDockerDesktop
+	ns := k8s.Namespace("default")
+
+	dCli := docker.NewFakeClient()
+	kCli := k8s.NewFakeK8sClient()
+	dcCli := dockercompose.NewFakeDockerComposeClient(t, ctx)
+	fsWatcher := fswatch.NewFakeMultiWatcher()
+	timerMaker := fswatch.MakeFakeTimerMaker(t)
+
+	// BuildHistory only keeps the last few builds, so count them as they complete.
+	// The reducer runs under the state lock, so readers hold RLockState.
+	buildCounts := make(map[model.ManifestName]int)
+	reducer := store.Reducer(func(ctx context.Context, state *store.EngineState, action store.Action) {
+		if a, ok := action.(buildcontrol.BuildCompleteAction); ok {
+			buildCounts[a.ManifestName]++
+		}
+		engine.UpperReducer(ctx, state, action)
+	})
+
+	onChange := onChangeSub{ch: make(chan bool, 1000)}
+	st := store.NewStore(reducer, store.LogActionsFlag(false))
+	st.AddSubscriber(ctx, onChange)
+
+	b, err := engine.ProvideBuildAndDeployerForTests(ctx, dCli, kCli, dcCli,
+		dirs.NewWindmillDirAt(f.JoinPath(".windmill")), env, clock, ta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	of := k8s.ProvideOwnerFetcher(kCli)
+	sched := scheduler.NewScheduler(clock)
+	k8sContextExt := k8scontext.NewExtension("fake-context", env)
+	versionExt := version.NewExtension(model.TiltBuild{Version: "0.0.0-harness"})
+	configExt := config.NewExtension("up")
+	tfl := tiltfile.ProvideTiltfileLoader(ta, kCli, k8sContextExt, versionExt, configExt, dcCli, "localhost", feature.MainDefaults, env)
+	fwm := fswatch.NewWatchManager(fsWatcher.NewSub, timerMaker.Maker(), clock)
+
+	sCli := synclet.NewTestSyncletClient(dCli)
+	sGRPCCli, err := synclet.FakeGRPCWrapper(ctx, sCli)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dp := dockerprune.NewDockerPruner(dCli, clock)
+	dp.DisabledForTesting(true)
+
+	// Time Tiltfile loads with the fake clock too, so that they line up
+	// with the file changes that trigger them.
+	cc := configs.NewConfigsController(tfl, dCli)
+	cc.SetClockForTesting(clock.Now)
+
+	h := hud.NewFakeHud()
+	subs := engine.ProvideSubscribers(
+		h,
+		hud.NewTerminalStream(hud.NewIncrementalPrinter(log), st),
+		hud.NewJSONStream(log, st),
+		prompt.NewTerminalPrompt(ta, prompt.TTYOpen, prompt.BrowserOpen, log, "localhost", model.WebURL{}, prompt.DefaultKeyBindings()),
+		k8swatch.NewPodWatcher(kCli, of, ns),
+		k8swatch.NewServiceWatcher(kCli, of, ns),
+		runtimelog.NewPodLogManager(kCli),
+		portforward.NewController(kCli, ns, listeners.NewRegistry()),
+		fwm,
+		fswatch.NewGitManager(fsWatcher.NewSub, timerMaker.Maker(), clock),
+		fswatch.NewLimitsChecker(),
+		engine.NewBuildController(b, clock),
+		cc,
+		dcwatch.NewEventWatcher(dcCli, dCli),
+		runtimelog.NewDockerComposeLogManager(dcCli),
+		engine.NewProfilerManager(),
+		containerupdate.NewSyncletManagerForTests(kCli, sGRPCCli, sCli),
+		engineanalytics.ProvideAnalyticsReporter(ta, st, kCli, env, sched),
+		server.ProvideHeadsUpServerController("localhost", 0, nil, &server.HeadsUpServer{}, assets.NewFakeServer(), model.WebURL{}, listeners.NewRegistry()),
+		engineanalytics.NewAnalyticsUpdater(ta, engineanalytics.CmdTags{}),
+		k8swatch.NewEventWatchManager(kCli, of, ns),
+		cloud.NewStatusManager(httptest.NewFakeClientEmptyJSON(), clock),
+		dp,
+		dockerprune.NewDiskGovernor(dCli, dp, sched, clock),
+		telemetry.NewController(clock, tracer.NewSpanCollector(ctx), model.OfflineMode(true), dirs.NewWindmillDirAt(f.JoinPath(".windmill"))),
+		local.NewController(local.NewFakeExecer()),
+		k8srollout.NewPodMonitor(),
+		exit.NewController(),
+		metrics.NewController(metrics.NewDeferredExporter(), model.TiltBuild{}, ""),
+		k8sheartbeat.NewController(kCli, sched, clock),
+		k8scredentials.NewController(nil, st, sched),
+		localdns.NewController(localdns.ProvideListenPacket()),
+		hibernate.NewController(kCli, sched, clock, listeners.NewRegistry()),
+		endpointhealth.NewController(sched, clock),
+		baseimage.NewController(sched, dCli),
+		linkdiscovery.NewController(),
+		buildlogs.NewArchiver(),
+		cronjob.NewController(kCli, clock),
+		autoscale.NewController(kCli),
+		workloadstatus.NewController(kCli, sched),
+		seed.NewController(kCli, clock),
+		watchdog.NewWatchdog(st, &server.HeadsUpServer{}, sched, clock),
+		sched,
+	)
+
+	err = os.Mkdir(f.JoinPath(".git"), os.FileMode(0777))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Harness{
+		TempDirFixture: f,
+		t:              t,
+		ctx:            ctx,
+		cancel:         cancel,
+		clock:          clock,
+		docker:         dCli,
+		kCli:           kCli,
+		dcCli:          dcCli,
+		fsWatcher:      fsWatcher,
+		fwm:            fwm,
+		store:          st,
+		upper:          engine.NewUpper(ctx, st, subs),
+		log:            log,
+		builds:         buildCounts,
+		onChangeCh:     onChange.ch,
+	}
+}
+
+// Starts the session with the Tiltfile in the harness directory,
+// and waits for the Tiltfile to load.
+func (h *Harness) Start(args ...string) {
+	h.initResult = make(chan error, 1)
+	go func() {
+		h.initResult <- h.upper.Init(h.ctx, engine.InitAction{
+			EngineMode:   store.EngineModeUp,
+			TiltfilePath: h.JoinPath("Tiltfile"),
+			UserArgs:     args,
+			TerminalMode: store.TerminalModeStream,
+			StartTime:    h.clock.Now(),
+		})
+	}()
+
+	h.waitUntil("Tiltfile loaded", func(state store.EngineState) bool {
+		return !state.TiltfileState.LastBuild().Empty()
+	})
+
+	state := h.store.RLockState()
+	expectedWatchCount := len(fswatch.WatchableTargetsForManifests(state.Manifests()))
+	if len(state.ConfigFiles) > 0 {
+		// The watch manager also watches the config files.
+		expectedWatchCount++
+	}
+	h.store.RUnlockState()
+
+	h.WaitUntil("file watches set up", func() bool {
+		return h.fwm.TargetWatchCount() == expectedWatchCount
+	})
+}
+
+// Returns the error from the last Tiltfile load, if any.
+func (h *Harness) TiltfileError() error {
+	state := h.store.RLockState()
+	defer h.store.RUnlockState()
+	return state.TiltfileState.LastBuild().Error
+}
+
+// The names of the resources in the session, in Tiltfile order.
+func (h *Harness) ManifestNames() []model.ManifestName {
+	state := h.store.RLockState()
+	defer h.store.RUnlockState()
+	return append([]model.ManifestName{}, state.ManifestDefinitionOrder...)
+}
+
+// Completed builds of the given resource, most recent first.
+func (h *Harness) BuildHistory(mn model.ManifestName) []model.BuildRecord {
+	state := h.store.RLockState()
+	defer h.store.RUnlockState()
+	ms, ok := state.ManifestState(mn)
+	if !ok {
+		return nil
+	}
+	return append([]model.BuildRecord{}, ms.BuildHistory...)
+}
+
+// Waits until the given resource has completed at least n builds.
+func (h *Harness) WaitForBuilds(mn model.ManifestName, n int) {
+	h.t.Helper()
+	h.waitUntil(fmt.Sprintf("%s to complete %d builds", mn, n), func(state store.EngineState) bool {
+		ms, ok := state.ManifestState(mn)
+		return ok && h.builds[mn] >= n && !ms.IsBuilding()
+	})
+}
+
+// Triggers an update of the given resource, like `tilt trigger`.
+func (h *Harness) Trigger(mn model.ManifestName) {
+	h.store.Dispatch(server.AppendToTriggerQueueAction{Name: mn, Reason: model.BuildReasonFlagTriggerCLI})
+}
+
+// Simulates a change to the given files, relative to the harness directory.
+func (h *Harness) ChangeFile(paths ...string) {
+	for _, p := range paths {
+		h.fsWatcher.Events <- watch.NewFileEvent(h.JoinPath(p))
+	}
+}
+
+// Moves the fake clock forward, running any periodic work that comes due.
+func (h *Harness) Advance(d time.Duration) {
+	h.clock.Advance(d)
+}
+
+func (h *Harness) Now() time.Time {
+	return h.clock.Now()
+}
+
+// The number of images built with the fake Docker client.
+func (h *Harness) DockerBuildCount() int {
+	return h.docker.BuildCount
+}
+
+// The YAML most recently applied to the fake Kubernetes cluster.
+func (h *Harness) AppliedYAML() string {
+	return h.kCli.Yaml
+}
+
+// Everything the session has logged so far.
+func (h *Harness) Logs() string {
+	return h.log.String()
+}
+
+// Polls until isDone returns true, failing the test after DefaultTimeout.
+func (h *Harness) WaitUntil(msg string, isDone func() bool) {
+	h.t.Helper()
+	h.waitUntil(msg, func(store.EngineState) bool {
+		return isDone()
+	})
+}
+
+func (h *Harness) waitUntil(msg string, isDone func(store.EngineState) bool) {
+	h.t.Helper()
+
+	ctx, cancel := context.WithTimeout(h.ctx, DefaultTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		state := h.store.RLockState()
+		done := isDone(state)
+		h.store.RUnlockState()
+		if done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			h.t.Fatalf("Timed out waiting for: %s\nLogs:\n%s", msg, h.Logs())
+		case <-h.onChangeCh:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Harness) TearDown() {
+	h.cancel()
+	if h.initResult != nil {
+		err := <-h.initResult
+		if err != nil && err != context.Canceled {
+			h.t.Errorf("Tilt exited with error: %v", err)
+		}
+	}
+	h.kCli.TearDown()
+	close(h.fsWatcher.Events)
+	close(h.fsWatcher.Errors)
+	h.TempDirFixture.TearDown()
+}
+
+type onChangeSub struct {
+	ch chan bool
+}
+
+func (s onChangeSub) OnChange(ctx context.Context, st store.RStore) {
+	select {
+	case s.ch <- true:
+	default:
+	}
+}
@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHarnessLocalResource(t *testing.T) {
+	h := NewHarness(t)
+	defer h.TearDown()
+
+	h.WriteFile("src/a.txt", "a")
+	h.WriteFile("Tiltfile", `local_resource("gen", "echo generating", deps=["src"])`)
+	h.Start()
+	require.NoError(t, h.TiltfileError())
+	assert.Equal(t, []model.ManifestName{"gen"}, h.ManifestNames())
+
+	h.WaitForBuilds("gen", 1)
+	assert.NoError(t, h.BuildHistory("gen")[0].Error)
+	assert.Contains(t, h.Logs(), "generating")
+
+	h.WriteFile("src/a.txt", "b")
+	h.ChangeFile("src/a.txt")
+	h.WaitForBuilds("gen", 2)
+	assert.Equal(t, model.BuildReasonFlagChangedFiles, h.BuildHistory("gen")[0].Reason)
+
+	h.Trigger("gen")
+	h.WaitForBuilds("gen", 3)
+}
+
+func TestHarnessFakeClock(t *testing.T) {
+	h := NewHarness(t)
+	defer h.TearDown()
+
+	h.WriteFile("Tiltfile", `local_resource("gen", "echo generating")`)
+	h.Start()
+	h.WaitForBuilds("gen", 1)
+
+	start := h.Now()
+	assert.Equal(t, start, h.BuildHistory("gen")[0].FinishTime)
+
+	h.Advance(time.Hour)
+	h.Trigger("gen")
+	h.WaitForBuilds("gen", 2)
+	assert.Equal(t, start.Add(time.Hour), h.BuildHistory("gen")[0].StartTime)
+}
+
+func TestHarnessTiltfileError(t *testing.T) {
+	h := NewHarness(t)
+	defer h.TearDown()
+
+	h.WriteFile("Tiltfile", `fail("oh no")`)
+	h.Start()
+	if assert.Error(t, h.TiltfileError()) {
+		assert.Contains(t, h.TiltfileError().Error(), "oh no")
+	}
+}
+
+func TestHarnessTiltfileReloadSkipsUnchangedDeploy(t *testing.T) {
+	h := NewHarness(t)
+	defer h.TearDown()
+
+	h.WriteFile("app.yaml", appYAML)
+	h.WriteFile("Tiltfile", `k8s_yaml("app.yaml")`)
+	h.Start()
+	require.NoError(t, h.TiltfileError())
+	h.WaitForBuilds("app", 1)
+	assert.Contains(t, h.AppliedYAML(), "name: app")
+	assert.NotContains(t, h.Logs(), "Deploy skipped")
+
+	// The comment moves the objects down a line, which changes the resource
+	// and resets its build state, but renders the same YAML.
+	h.WriteFile("app.yaml", "# The app\n"+appYAML)
+	h.Advance(time.Second)
+	h.ChangeFile("app.yaml")
+	h.WaitForBuilds("app", 2)
+	assert.Equal(t, model.BuildReasonFlagConfig, h.BuildHistory("app")[0].Reason)
+	assert.True(t, h.BuildHistory("app")[0].DeploySkipped)
+	h.WaitUntil("deploy skipped", func() bool {
+		return strings.Contains(h.Logs(), "Deploy skipped (no changes)")
+	})
+}
+
+const appYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: busybox
+`
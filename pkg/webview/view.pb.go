@@ -164,7 +164,11 @@ type BuildRecord struct {
 	UpdateTypes    []UpdateType         `protobuf:"varint,9,rep,packed,name=update_types,json=updateTypes,proto3,enum=webview.UpdateType" json:"update_types,omitempty"`
 	IsCrashRebuild bool                 `protobuf:"varint,7,opt,name=is_crash_rebuild,json=isCrashRebuild,proto3" json:"is_crash_rebuild,omitempty"`
 	// The span id for this build record's logs in the main logstore.
-	SpanId               string   `protobuf:"bytes,8,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	SpanId string `protobuf:"bytes,8,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	// What kind of failure the error was (e.g., "registry-auth"), and what
+	// to do about it. Empty if we don't know.
+	ErrorCategory        string   `protobuf:"bytes,10,opt,name=error_category,json=errorCategory,proto3" json:"error_category,omitempty"`
+	ErrorRemediation     string   `protobuf:"bytes,11,opt,name=error_remediation,json=errorRemediation,proto3" json:"error_remediation,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -251,6 +255,20 @@ func (m *BuildRecord) GetSpanId() string {
 	return ""
 }
 
+func (m *BuildRecord) GetErrorCategory() string {
+	if m != nil {
+		return m.ErrorCategory
+	}
+	return ""
+}
+
+func (m *BuildRecord) GetErrorRemediation() string {
+	if m != nil {
+		return m.ErrorRemediation
+	}
+	return ""
+}
+
 type K8SResourceInfo struct {
 	PodName            string `protobuf:"bytes,1,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
 	PodCreationTime    string `protobuf:"bytes,2,opt,name=pod_creation_time,json=podCreationTime,proto3" json:"pod_creation_time,omitempty"`
@@ -260,11 +278,16 @@ type K8SResourceInfo struct {
 	AllContainersReady bool   `protobuf:"varint,6,opt,name=all_containers_ready,json=allContainersReady,proto3" json:"all_containers_ready,omitempty"`
 	PodRestarts        int32  `protobuf:"varint,7,opt,name=pod_restarts,json=podRestarts,proto3" json:"pod_restarts,omitempty"`
 	// The span id for this pod's logs in the main logstore
-	SpanId               string   `protobuf:"bytes,9,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
-	DisplayNames         []string `protobuf:"bytes,10,rep,name=display_names,json=displayNames,proto3" json:"display_names,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	SpanId       string   `protobuf:"bytes,9,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	DisplayNames []string `protobuf:"bytes,10,rep,name=display_names,json=displayNames,proto3" json:"display_names,omitempty"`
+	// The containers of the pod, with their restarts and why they last stopped.
+	Containers []*ContainerStatus `protobuf:"bytes,11,rep,name=containers,proto3" json:"containers,omitempty"`
+	// If the resource is deployed to several namespaces with
+	// k8s_resource(namespaces=), the rollout in each one.
+	Namespaces           []*NamespaceStatus `protobuf:"bytes,12,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
 func (m *K8SResourceInfo) Reset()         { *m = K8SResourceInfo{} }
@@ -355,6 +378,20 @@ func (m *K8SResourceInfo) GetDisplayNames() []string {
 	return nil
 }
 
+func (m *K8SResourceInfo) GetContainers() []*ContainerStatus {
+	if m != nil {
+		return m.Containers
+	}
+	return nil
+}
+
+func (m *K8SResourceInfo) GetNamespaces() []*NamespaceStatus {
+	if m != nil {
+		return m.Namespaces
+	}
+	return nil
+}
+
 type DCResourceInfo struct {
 	ConfigPaths     []string             `protobuf:"bytes,1,rep,name=config_paths,json=configPaths,proto3" json:"config_paths,omitempty"`
 	ContainerStatus string               `protobuf:"bytes,2,opt,name=container_status,json=containerStatus,proto3" json:"container_status,omitempty"`
@@ -563,11 +600,13 @@ func (m *Facet) GetSpanId() string {
 }
 
 type Link struct {
-	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Url  string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The most recent health check of this link, if endpoint health checks are enabled.
+	Health               *LinkHealth `protobuf:"bytes,3,opt,name=health,proto3" json:"health,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
 func (m *Link) Reset()         { *m = Link{} }
@@ -609,6 +648,13 @@ func (m *Link) GetName() string {
 	return ""
 }
 
+func (m *Link) GetHealth() *LinkHealth {
+	if m != nil {
+		return m.Health
+	}
+	return nil
+}
+
 type Resource struct {
 	Name               string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	LastDeployTime     *timestamp.Timestamp `protobuf:"bytes,4,opt,name=last_deploy_time,json=lastDeployTime,proto3" json:"last_deploy_time,omitempty"`
@@ -632,9 +678,18 @@ type Resource struct {
 	// Obsoleted by crash_log_span_id.
 	CrashLog string `protobuf:"bytes,22,opt,name=crash_log,json=crashLog,proto3" json:"crash_log,omitempty"`
 	// A span id for the log that crashed.
-	CrashLogSpanId       string   `protobuf:"bytes,26,opt,name=crash_log_span_id,json=crashLogSpanId,proto3" json:"crash_log_span_id,omitempty"`
-	Facets               []*Facet `protobuf:"bytes,24,rep,name=facets,proto3" json:"facets,omitempty"`
-	Queued               bool     `protobuf:"varint,25,opt,name=queued,proto3" json:"queued,omitempty"`
+	CrashLogSpanId string   `protobuf:"bytes,26,opt,name=crash_log_span_id,json=crashLogSpanId,proto3" json:"crash_log_span_id,omitempty"`
+	Facets         []*Facet `protobuf:"bytes,24,rep,name=facets,proto3" json:"facets,omitempty"`
+	Queued         bool     `protobuf:"varint,25,opt,name=queued,proto3" json:"queued,omitempty"`
+	// How much of the HTTP traffic through this resource's port forwards to log:
+	// "" (off), "requests", or "bodies".
+	TrafficCapture string `protobuf:"bytes,29,opt,name=traffic_capture,json=trafficCapture,proto3" json:"traffic_capture,omitempty"`
+	// The color of this resource's log prefix, or "" if log colors are off.
+	LogColor string `protobuf:"bytes,30,opt,name=log_color,json=logColor,proto3" json:"log_color,omitempty"`
+	// Whether this resource deployed a CronJob, which the user can run now.
+	CronJob bool `protobuf:"varint,31,opt,name=cron_job,json=cronJob,proto3" json:"cron_job,omitempty"`
+	// Why the Tiltfile couldn't load this resource's config, if it couldn't.
+	ConfigError          string   `protobuf:"bytes,32,opt,name=config_error,json=configError,proto3" json:"config_error,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -826,6 +881,34 @@ func (m *Resource) GetQueued() bool {
 	return false
 }
 
+func (m *Resource) GetTrafficCapture() string {
+	if m != nil {
+		return m.TrafficCapture
+	}
+	return ""
+}
+
+func (m *Resource) GetLogColor() string {
+	if m != nil {
+		return m.LogColor
+	}
+	return ""
+}
+
+func (m *Resource) GetCronJob() bool {
+	if m != nil {
+		return m.CronJob
+	}
+	return false
+}
+
+func (m *Resource) GetConfigError() string {
+	if m != nil {
+		return m.ConfigError
+	}
+	return ""
+}
+
 type TiltBuild struct {
 	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
 	CommitSHA            string   `protobuf:"bytes,2,opt,name=commitSHA,proto3" json:"commitSHA,omitempty"`
@@ -948,10 +1031,24 @@ type View struct {
 	LogList                   *LogList         `protobuf:"bytes,13,opt,name=log_list,json=logList,proto3" json:"log_list,omitempty"`
 	// Allows us to synchronize on a running Tilt intance,
 	// so we can tell when Tilt restarted.
-	TiltStartTime        *timestamp.Timestamp `protobuf:"bytes,14,opt,name=tilt_start_time,json=tiltStartTime,proto3" json:"tilt_start_time,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	TiltStartTime *timestamp.Timestamp `protobuf:"bytes,14,opt,name=tilt_start_time,json=tiltStartTime,proto3" json:"tilt_start_time,omitempty"`
+	// Increments by one with every view sent on a websocket,
+	// so that the client can acknowledge what it has received.
+	Seq int32 `protobuf:"varint,17,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Set when Tilt Cloud rejected our token, so the user needs to link
+	// Tilt to Tilt Cloud again.
+	TiltCloudTokenExpired bool `protobuf:"varint,18,opt,name=tilt_cloud_token_expired,json=tiltCloudTokenExpired,proto3" json:"tilt_cloud_token_expired,omitempty"`
+	// The primary team, followed by any additional teams from the Tiltfile.
+	TiltCloudTeams []*TiltCloudTeam `protobuf:"bytes,19,rep,name=tilt_cloud_teams,json=tiltCloudTeams,proto3" json:"tilt_cloud_teams,omitempty"`
+	// Alerts the user hasn't acknowledged yet.
+	Alerts []*Alert `protobuf:"bytes,20,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	// Whether to show the time of each log line.
+	LogTimestamps bool `protobuf:"varint,21,opt,name=log_timestamps,json=logTimestamps,proto3" json:"log_timestamps,omitempty"`
+	// The width of the resource name column in front of each log line.
+	LogPrefixWidth       int32    `protobuf:"varint,22,opt,name=log_prefix_width,json=logPrefixWidth,proto3" json:"log_prefix_width,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *View) Reset()         { *m = View{} }
@@ -1091,6 +1188,48 @@ func (m *View) GetTiltStartTime() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *View) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *View) GetTiltCloudTokenExpired() bool {
+	if m != nil {
+		return m.TiltCloudTokenExpired
+	}
+	return false
+}
+
+func (m *View) GetTiltCloudTeams() []*TiltCloudTeam {
+	if m != nil {
+		return m.TiltCloudTeams
+	}
+	return nil
+}
+
+func (m *View) GetAlerts() []*Alert {
+	if m != nil {
+		return m.Alerts
+	}
+	return nil
+}
+
+func (m *View) GetLogTimestamps() bool {
+	if m != nil {
+		return m.LogTimestamps
+	}
+	return false
+}
+
+func (m *View) GetLogPrefixWidth() int32 {
+	if m != nil {
+		return m.LogPrefixWidth
+	}
+	return 0
+}
+
 type GetViewRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -1298,10 +1437,12 @@ type AckWebsocketRequest struct {
 	ToCheckpoint int32 `protobuf:"varint,1,opt,name=to_checkpoint,json=toCheckpoint,proto3" json:"to_checkpoint,omitempty"`
 	// Allows us to synchronize on a running Tilt intance,
 	// so we can tell when we're talking to the same Tilt.
-	TiltStartTime        *timestamp.Timestamp `protobuf:"bytes,2,opt,name=tilt_start_time,json=tiltStartTime,proto3" json:"tilt_start_time,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	TiltStartTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=tilt_start_time,json=tiltStartTime,proto3" json:"tilt_start_time,omitempty"`
+	// The seq of the received View
+	Seq                  int32    `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *AckWebsocketRequest) Reset()         { *m = AckWebsocketRequest{} }
@@ -1343,6 +1484,13 @@ func (m *AckWebsocketRequest) GetTiltStartTime() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *AckWebsocketRequest) GetSeq() int32 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
 type AckWebsocketResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -1374,6 +1522,366 @@ func (m *AckWebsocketResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_AckWebsocketResponse proto.InternalMessageInfo
 
+type TiltCloudTeam struct {
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Whether the current user is a member of this team.
+	Associated           bool     `protobuf:"varint,3,opt,name=associated,proto3" json:"associated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TiltCloudTeam) Reset()         { *m = TiltCloudTeam{} }
+func (m *TiltCloudTeam) String() string { return proto.CompactTextString(m) }
+func (*TiltCloudTeam) ProtoMessage()    {}
+func (*TiltCloudTeam) Descriptor() ([]byte, []int) {
+	return fileDescriptor_961ad0c6909086c3, []int{18}
+}
+
+func (m *TiltCloudTeam) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TiltCloudTeam.Unmarshal(m, b)
+}
+func (m *TiltCloudTeam) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TiltCloudTeam.Marshal(b, m, deterministic)
+}
+func (m *TiltCloudTeam) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TiltCloudTeam.Merge(m, src)
+}
+func (m *TiltCloudTeam) XXX_Size() int {
+	return xxx_messageInfo_TiltCloudTeam.Size(m)
+}
+func (m *TiltCloudTeam) XXX_DiscardUnknown() {
+	xxx_messageInfo_TiltCloudTeam.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TiltCloudTeam proto.InternalMessageInfo
+
+func (m *TiltCloudTeam) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *TiltCloudTeam) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TiltCloudTeam) GetAssociated() bool {
+	if m != nil {
+		return m.Associated
+	}
+	return false
+}
+
+type Alert struct {
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source               string               `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Severity             string               `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"`
+	ManifestName         string               `protobuf:"bytes,4,opt,name=manifest_name,json=manifestName,proto3" json:"manifest_name,omitempty"`
+	Message              string               `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	FirstSeen            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen             *timestamp.Timestamp `protobuf:"bytes,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Count                int32                `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Alert) Reset()         { *m = Alert{} }
+func (m *Alert) String() string { return proto.CompactTextString(m) }
+func (*Alert) ProtoMessage()    {}
+func (*Alert) Descriptor() ([]byte, []int) {
+	return fileDescriptor_961ad0c6909086c3, []int{19}
+}
+
+func (m *Alert) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Alert.Unmarshal(m, b)
+}
+func (m *Alert) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Alert.Marshal(b, m, deterministic)
+}
+func (m *Alert) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Alert.Merge(m, src)
+}
+func (m *Alert) XXX_Size() int {
+	return xxx_messageInfo_Alert.Size(m)
+}
+func (m *Alert) XXX_DiscardUnknown() {
+	xxx_messageInfo_Alert.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Alert proto.InternalMessageInfo
+
+func (m *Alert) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Alert) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Alert) GetSeverity() string {
+	if m != nil {
+		return m.Severity
+	}
+	return ""
+}
+
+func (m *Alert) GetManifestName() string {
+	if m != nil {
+		return m.ManifestName
+	}
+	return ""
+}
+
+func (m *Alert) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Alert) GetFirstSeen() *timestamp.Timestamp {
+	if m != nil {
+		return m.FirstSeen
+	}
+	return nil
+}
+
+func (m *Alert) GetLastSeen() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastSeen
+	}
+	return nil
+}
+
+func (m *Alert) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type LinkHealth struct {
+	// The HTTP status code of the response, or 0 if there was no response.
+	StatusCode           int32                `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	LatencyMs            int64                `protobuf:"varint,2,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Error                string               `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	CheckedAt            *timestamp.Timestamp `protobuf:"bytes,4,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	Healthy              bool                 `protobuf:"varint,5,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *LinkHealth) Reset()         { *m = LinkHealth{} }
+func (m *LinkHealth) String() string { return proto.CompactTextString(m) }
+func (*LinkHealth) ProtoMessage()    {}
+func (*LinkHealth) Descriptor() ([]byte, []int) {
+	return fileDescriptor_961ad0c6909086c3, []int{20}
+}
+
+func (m *LinkHealth) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LinkHealth.Unmarshal(m, b)
+}
+func (m *LinkHealth) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LinkHealth.Marshal(b, m, deterministic)
+}
+func (m *LinkHealth) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LinkHealth.Merge(m, src)
+}
+func (m *LinkHealth) XXX_Size() int {
+	return xxx_messageInfo_LinkHealth.Size(m)
+}
+func (m *LinkHealth) XXX_DiscardUnknown() {
+	xxx_messageInfo_LinkHealth.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LinkHealth proto.InternalMessageInfo
+
+func (m *LinkHealth) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *LinkHealth) GetLatencyMs() int64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *LinkHealth) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *LinkHealth) GetCheckedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CheckedAt
+	}
+	return nil
+}
+
+func (m *LinkHealth) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+type ContainerStatus struct {
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Restarts int32  `protobuf:"varint,2,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	// Why the container last stopped (e.g., "OOMKilled" or "Error"), and its
+	// exit code. Empty if it has never stopped.
+	LastTerminationReason   string   `protobuf:"bytes,3,opt,name=last_termination_reason,json=lastTerminationReason,proto3" json:"last_termination_reason,omitempty"`
+	LastTerminationExitCode int32    `protobuf:"varint,4,opt,name=last_termination_exit_code,json=lastTerminationExitCode,proto3" json:"last_termination_exit_code,omitempty"`
+	OomKilled               bool     `protobuf:"varint,5,opt,name=oom_killed,json=oomKilled,proto3" json:"oom_killed,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
+	XXX_unrecognized        []byte   `json:"-"`
+	XXX_sizecache           int32    `json:"-"`
+}
+
+func (m *ContainerStatus) Reset()         { *m = ContainerStatus{} }
+func (m *ContainerStatus) String() string { return proto.CompactTextString(m) }
+func (*ContainerStatus) ProtoMessage()    {}
+func (*ContainerStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_961ad0c6909086c3, []int{21}
+}
+
+func (m *ContainerStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContainerStatus.Unmarshal(m, b)
+}
+func (m *ContainerStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContainerStatus.Marshal(b, m, deterministic)
+}
+func (m *ContainerStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContainerStatus.Merge(m, src)
+}
+func (m *ContainerStatus) XXX_Size() int {
+	return xxx_messageInfo_ContainerStatus.Size(m)
+}
+func (m *ContainerStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContainerStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContainerStatus proto.InternalMessageInfo
+
+func (m *ContainerStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ContainerStatus) GetRestarts() int32 {
+	if m != nil {
+		return m.Restarts
+	}
+	return 0
+}
+
+func (m *ContainerStatus) GetLastTerminationReason() string {
+	if m != nil {
+		return m.LastTerminationReason
+	}
+	return ""
+}
+
+func (m *ContainerStatus) GetLastTerminationExitCode() int32 {
+	if m != nil {
+		return m.LastTerminationExitCode
+	}
+	return 0
+}
+
+func (m *ContainerStatus) GetOomKilled() bool {
+	if m != nil {
+		return m.OomKilled
+	}
+	return false
+}
+
+type NamespaceStatus struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// The most recent pod in the namespace. Empty if there isn't one yet.
+	PodName              string   `protobuf:"bytes,2,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	PodStatus            string   `protobuf:"bytes,3,opt,name=pod_status,json=podStatus,proto3" json:"pod_status,omitempty"`
+	RuntimeStatus        string   `protobuf:"bytes,4,opt,name=runtime_status,json=runtimeStatus,proto3" json:"runtime_status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NamespaceStatus) Reset()         { *m = NamespaceStatus{} }
+func (m *NamespaceStatus) String() string { return proto.CompactTextString(m) }
+func (*NamespaceStatus) ProtoMessage()    {}
+func (*NamespaceStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_961ad0c6909086c3, []int{22}
+}
+
+func (m *NamespaceStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NamespaceStatus.Unmarshal(m, b)
+}
+func (m *NamespaceStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NamespaceStatus.Marshal(b, m, deterministic)
+}
+func (m *NamespaceStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NamespaceStatus.Merge(m, src)
+}
+func (m *NamespaceStatus) XXX_Size() int {
+	return xxx_messageInfo_NamespaceStatus.Size(m)
+}
+func (m *NamespaceStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_NamespaceStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NamespaceStatus proto.InternalMessageInfo
+
+func (m *NamespaceStatus) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *NamespaceStatus) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+func (m *NamespaceStatus) GetPodStatus() string {
+	if m != nil {
+		return m.PodStatus
+	}
+	return ""
+}
+
+func (m *NamespaceStatus) GetRuntimeStatus() string {
+	if m != nil {
+		return m.RuntimeStatus
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterEnum("webview.UpdateType", UpdateType_name, UpdateType_value)
 	proto.RegisterEnum("webview.TargetType", TargetType_name, TargetType_value)
@@ -1396,150 +1904,191 @@ func init() {
 	proto.RegisterType((*UploadSnapshotResponse)(nil), "webview.UploadSnapshotResponse")
 	proto.RegisterType((*AckWebsocketRequest)(nil), "webview.AckWebsocketRequest")
 	proto.RegisterType((*AckWebsocketResponse)(nil), "webview.AckWebsocketResponse")
+	proto.RegisterType((*TiltCloudTeam)(nil), "webview.TiltCloudTeam")
+	proto.RegisterType((*Alert)(nil), "webview.Alert")
+	proto.RegisterType((*LinkHealth)(nil), "webview.LinkHealth")
+	proto.RegisterType((*ContainerStatus)(nil), "webview.ContainerStatus")
+	proto.RegisterType((*NamespaceStatus)(nil), "webview.NamespaceStatus")
 }
 
 func init() { proto.RegisterFile("pkg/webview/view.proto", fileDescriptor_961ad0c6909086c3) }
 
 var fileDescriptor_961ad0c6909086c3 = []byte{
-	// 2194 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0x4d, 0x73, 0x23, 0x47,
-	0x19, 0x46, 0x1f, 0xb6, 0xa5, 0x57, 0x5f, 0xa3, 0x96, 0xed, 0x9d, 0x75, 0x1c, 0xd6, 0xab, 0x25,
-	0x89, 0x63, 0x82, 0x05, 0x26, 0x15, 0x36, 0xcb, 0x01, 0x1c, 0x49, 0xd9, 0x95, 0xa3, 0xcd, 0xba,
-	0x5a, 0xde, 0xa5, 0xc2, 0x65, 0x6a, 0x3c, 0xd3, 0x1a, 0x75, 0x69, 0x34, 0x3d, 0x99, 0x6e, 0xd9,
-	0x98, 0x03, 0x07, 0xce, 0xdc, 0xf8, 0x0d, 0x1c, 0xf8, 0x05, 0xfc, 0x0c, 0x0e, 0x39, 0x53, 0x5c,
-	0xf8, 0x15, 0x9c, 0xa8, 0xfe, 0x98, 0xd1, 0x48, 0xde, 0xad, 0x90, 0x8b, 0xaa, 0xfb, 0xfd, 0xec,
-	0x7e, 0xfa, 0xe9, 0xb7, 0xdf, 0x11, 0xec, 0xc7, 0xf3, 0xa0, 0x77, 0x4b, 0xae, 0x6f, 0x28, 0xb9,
-	0xed, 0xc9, 0x9f, 0xd3, 0x38, 0x61, 0x82, 0xa1, 0x1d, 0x23, 0x3b, 0x38, 0x0c, 0x18, 0x0b, 0x42,
-	0xd2, 0x73, 0x63, 0xda, 0x73, 0xa3, 0x88, 0x09, 0x57, 0x50, 0x16, 0x71, 0x6d, 0x76, 0xf0, 0xc8,
-	0x68, 0xd5, 0xec, 0x7a, 0x39, 0xed, 0x09, 0xba, 0x20, 0x5c, 0xb8, 0x8b, 0xd8, 0x18, 0xec, 0xe5,
-	0xe3, 0x87, 0x2c, 0xd0, 0xe2, 0xee, 0x02, 0xe0, 0xca, 0x4d, 0x02, 0x22, 0x26, 0x31, 0xf1, 0x50,
-	0x13, 0x8a, 0xd4, 0xb7, 0x0b, 0x47, 0x85, 0xe3, 0x2a, 0x2e, 0x52, 0x1f, 0x7d, 0x04, 0x65, 0x71,
-	0x17, 0x13, 0xbb, 0x78, 0x54, 0x38, 0x6e, 0x9e, 0x75, 0x4e, 0x8d, 0xff, 0xa9, 0x76, 0xb9, 0xba,
-	0x8b, 0x09, 0x56, 0x06, 0xe8, 0x43, 0x68, 0xcd, 0x5c, 0xee, 0x84, 0xf4, 0x86, 0x38, 0xcb, 0xd8,
-	0x77, 0x05, 0xb1, 0x4b, 0x47, 0x85, 0xe3, 0x0a, 0x6e, 0xcc, 0x5c, 0x3e, 0xa6, 0x37, 0xe4, 0xb5,
-	0x12, 0x76, 0xbf, 0x2b, 0x42, 0xed, 0x8b, 0x25, 0x0d, 0x7d, 0x4c, 0x3c, 0x96, 0xf8, 0x68, 0x17,
-	0xb6, 0x88, 0x4f, 0x05, 0xb7, 0x0b, 0x47, 0xa5, 0xe3, 0x2a, 0xd6, 0x13, 0x25, 0x4d, 0x12, 0x96,
-	0xa8, 0xbc, 0x52, 0x2a, 0x27, 0xe8, 0x00, 0x2a, 0xb7, 0x6e, 0x12, 0xd1, 0x28, 0xe0, 0x76, 0x49,
-	0x99, 0x67, 0x73, 0xf4, 0x39, 0x00, 0x17, 0x6e, 0x22, 0x1c, 0xb9, 0x6d, 0xbb, 0x7c, 0x54, 0x38,
-	0xae, 0x9d, 0x1d, 0x9c, 0x6a, 0x4c, 0x4e, 0x53, 0x4c, 0x4e, 0xaf, 0x52, 0x4c, 0x70, 0x55, 0x59,
-	0xcb, 0x39, 0xfa, 0x35, 0xd4, 0xa6, 0x34, 0xa2, 0x7c, 0xa6, 0x7d, 0xb7, 0xbe, 0xd7, 0x17, 0xb4,
-	0xb9, 0x72, 0xfe, 0x0c, 0xea, 0x7a, 0xbb, 0x8e, 0x84, 0x81, 0xdb, 0xd5, 0xa3, 0xd2, 0x1a, 0x50,
-	0x7a, 0xdb, 0x0a, 0xa8, 0xda, 0x32, 0x1b, 0x73, 0x74, 0x0c, 0x16, 0xe5, 0x8e, 0x97, 0xb8, 0x7c,
-	0xe6, 0x24, 0xe4, 0x5a, 0x22, 0x62, 0xef, 0x28, 0xc0, 0x9a, 0x94, 0xf7, 0xa5, 0x18, 0x6b, 0x29,
-	0x7a, 0x00, 0x3b, 0x3c, 0x76, 0x23, 0x87, 0xfa, 0x76, 0x45, 0xa1, 0xb1, 0x2d, 0xa7, 0x23, 0xff,
-	0xa2, 0x5c, 0xd9, 0xb6, 0x76, 0x70, 0x29, 0x64, 0x41, 0xf7, 0xbf, 0x45, 0x68, 0x7d, 0xf5, 0x94,
-	0x63, 0xc2, 0xd9, 0x32, 0xf1, 0xc8, 0x28, 0x9a, 0x32, 0xf4, 0x10, 0x2a, 0x31, 0xf3, 0x9d, 0xc8,
-	0x5d, 0x10, 0x73, 0xa0, 0x3b, 0x31, 0xf3, 0xbf, 0x76, 0x17, 0x04, 0x9d, 0x40, 0x5b, 0xaa, 0xbc,
-	0x84, 0x28, 0x0a, 0xe9, 0x7d, 0x6b, 0xa8, 0x5b, 0x31, 0xf3, 0xfb, 0x46, 0xae, 0x36, 0xf8, 0x0b,
-	0xd8, 0x93, 0xb6, 0x66, 0x93, 0x39, 0x8c, 0x4b, 0xca, 0x1e, 0xc5, 0xcc, 0xd7, 0x7b, 0x9c, 0x64,
-	0x80, 0xbe, 0x0f, 0x20, 0x5d, 0xb8, 0x70, 0xc5, 0x92, 0xab, 0xb3, 0xa8, 0xe2, 0x6a, 0xcc, 0xfc,
-	0x89, 0x12, 0xa0, 0x4f, 0x00, 0xad, 0xd4, 0xce, 0x82, 0x70, 0xee, 0x06, 0x1a, 0xf6, 0x2a, 0xb6,
-	0x32, 0xb3, 0x97, 0x5a, 0x8e, 0x7e, 0x0e, 0xbb, 0x6e, 0x18, 0x3a, 0x1e, 0x8b, 0x84, 0x4b, 0x23,
-	0x92, 0x70, 0x27, 0x21, 0xae, 0x7f, 0x67, 0x6f, 0x2b, 0xb0, 0x90, 0x1b, 0x86, 0xfd, 0x4c, 0x85,
-	0xa5, 0x06, 0x3d, 0x86, 0xba, 0x8c, 0x9f, 0x10, 0xb5, 0x58, 0xae, 0x60, 0xdd, 0xc2, 0xb5, 0x98,
-	0xf9, 0xd8, 0x88, 0xf2, 0x98, 0x56, 0xf3, 0x98, 0xa2, 0x27, 0xd0, 0xf0, 0x29, 0x8f, 0x43, 0xf7,
-	0x4e, 0x01, 0xc7, 0x6d, 0x50, 0x3c, 0xab, 0x1b, 0xa1, 0x44, 0x8f, 0x5f, 0x94, 0x2b, 0x15, 0x4b,
-	0xa3, 0xe9, 0x48, 0xf0, 0xff, 0x5d, 0x80, 0xe6, 0xa0, 0xbf, 0x86, 0xfd, 0x63, 0xa8, 0x7b, 0x2c,
-	0x9a, 0xd2, 0xc0, 0x89, 0x5d, 0x31, 0x4b, 0xc9, 0x5d, 0xd3, 0xb2, 0x4b, 0x29, 0x42, 0x1f, 0x83,
-	0x95, 0xed, 0x29, 0x85, 0xca, 0x1c, 0x41, 0x26, 0x37, 0x80, 0x1d, 0x41, 0x2d, 0x13, 0x8d, 0x06,
-	0x06, 0xf8, 0xbc, 0x68, 0x83, 0xfd, 0x5b, 0x3f, 0x84, 0xfd, 0x39, 0x28, 0xb6, 0x37, 0xe8, 0x55,
-	0xb6, 0xb6, 0x34, 0xbd, 0x7e, 0x05, 0xd6, 0x37, 0xe7, 0x2f, 0xc7, 0x6b, 0x5b, 0x7c, 0x02, 0x8d,
-	0xf9, 0x53, 0x79, 0x18, 0x5a, 0x96, 0xee, 0xb1, 0x3e, 0x5f, 0xd1, 0x90, 0x77, 0x3f, 0x80, 0xf6,
-	0x98, 0x79, 0x6e, 0xb8, 0xe6, 0x69, 0x41, 0x29, 0x36, 0x45, 0xa6, 0x84, 0xe5, 0xb0, 0x7b, 0x01,
-	0x5b, 0x5f, 0xba, 0x1e, 0x11, 0x08, 0x41, 0x39, 0xc7, 0x57, 0x35, 0x96, 0xb5, 0xe0, 0xc6, 0x0d,
-	0x97, 0x29, 0x41, 0xf5, 0x24, 0xbf, 0xec, 0x52, 0x7e, 0xd9, 0xdd, 0x4f, 0xa0, 0x3c, 0xa6, 0xd1,
-	0x5c, 0x66, 0x59, 0x26, 0xa1, 0x89, 0x24, 0x87, 0x59, 0xf0, 0xe2, 0x2a, 0x78, 0xf7, 0x9f, 0x55,
-	0xa8, 0xa4, 0x8b, 0x7b, 0x6b, 0xf6, 0x01, 0x58, 0xa1, 0xcb, 0x85, 0xe3, 0x93, 0x38, 0x64, 0x77,
-	0xff, 0x6f, 0x75, 0x69, 0x4a, 0x9f, 0x81, 0x72, 0x51, 0x20, 0x3f, 0x86, 0xba, 0x48, 0x68, 0x10,
-	0x90, 0xc4, 0x59, 0x30, 0x5f, 0x9f, 0xd0, 0x16, 0xae, 0x19, 0xd9, 0x4b, 0xe6, 0x13, 0xf4, 0x39,
-	0x34, 0xd4, 0x7d, 0x77, 0x66, 0x94, 0x0b, 0x96, 0x48, 0x82, 0x97, 0x8e, 0x6b, 0x67, 0xbb, 0x59,
-	0x25, 0xc9, 0x55, 0x4d, 0x5c, 0x57, 0xa6, 0x2f, 0xb4, 0xa5, 0x74, 0xf5, 0x96, 0x49, 0x42, 0x22,
-	0xe1, 0xac, 0x0a, 0xc9, 0x3b, 0x5d, 0x8d, 0xa9, 0x92, 0xc9, 0xdb, 0x15, 0x93, 0xc8, 0xa7, 0x51,
-	0xa0, 0x5d, 0xe5, 0xe5, 0xe2, 0x2c, 0x52, 0x95, 0x66, 0x0b, 0x23, 0xa3, 0x33, 0xfe, 0x52, 0x83,
-	0x4e, 0xa1, 0xb3, 0xee, 0xa1, 0xcb, 0x77, 0x55, 0x9d, 0x7e, 0x3b, 0xef, 0x30, 0x54, 0xa5, 0xfc,
-	0x62, 0xd3, 0x9e, 0xd3, 0xc8, 0x23, 0x36, 0x7c, 0x2f, 0x86, 0x6b, 0xb1, 0x26, 0xd2, 0x49, 0xe6,
-	0x96, 0x8f, 0x4c, 0x1a, 0xcf, 0x9b, 0xb9, 0x51, 0x40, 0xb8, 0x5d, 0x53, 0xa5, 0xa0, 0x3d, 0x73,
-	0xf9, 0xa5, 0xd6, 0xf4, 0xb5, 0x02, 0x7d, 0x0a, 0x4d, 0x12, 0xf9, 0x31, 0xa3, 0x91, 0x70, 0x42,
-	0x1a, 0xcd, 0xb9, 0x7d, 0xa8, 0x40, 0x6d, 0x64, 0xc8, 0x48, 0xaa, 0xe0, 0x46, 0x6a, 0x24, 0x67,
-	0xea, 0xf1, 0x89, 0x99, 0x3f, 0x1a, 0xd8, 0x0d, 0x4d, 0x38, 0x35, 0x41, 0x03, 0x68, 0xe7, 0xf9,
-	0xee, 0xd0, 0x68, 0xca, 0xec, 0xa6, 0xda, 0x85, 0x9d, 0x85, 0xdb, 0xa8, 0xc1, 0xb8, 0x35, 0xdf,
-	0x28, 0xca, 0xe7, 0x60, 0xf9, 0xde, 0x46, 0x90, 0x96, 0x0a, 0xf2, 0x20, 0x0b, 0xb2, 0x5e, 0x4b,
-	0x70, 0xd3, 0xf7, 0xd6, 0x42, 0x3c, 0x07, 0x74, 0xe7, 0x2e, 0xc2, 0x8d, 0x20, 0x96, 0x0a, 0xf2,
-	0x30, 0x0b, 0xb2, 0x79, 0x5f, 0xb1, 0x25, 0x9d, 0xd6, 0x02, 0x5d, 0x40, 0x27, 0x94, 0x97, 0x73,
-	0x23, 0x52, 0xdb, 0x9c, 0x4c, 0x06, 0xd1, 0xe6, 0x05, 0xc6, 0xed, 0xf0, 0xde, 0x9d, 0xfe, 0x00,
-	0x9a, 0xc9, 0x32, 0x92, 0xb7, 0x23, 0xad, 0x65, 0x48, 0x81, 0xd7, 0x30, 0x52, 0x53, 0xc9, 0x1e,
-	0x41, 0x8d, 0x72, 0x47, 0xd0, 0x50, 0x4c, 0x69, 0x48, 0xec, 0x8e, 0x3a, 0x38, 0xa0, 0xfc, 0xca,
-	0x48, 0xd0, 0xc7, 0xb0, 0xc5, 0x63, 0xe2, 0x71, 0xfb, 0x3d, 0x75, 0x50, 0x9b, 0x0d, 0x87, 0xec,
-	0x51, 0xb0, 0xb6, 0x90, 0x8f, 0x18, 0x9f, 0xb1, 0xdb, 0x94, 0x55, 0x3a, 0xeb, 0xae, 0x8a, 0xd8,
-	0x92, 0x0a, 0xcd, 0x1b, 0x9d, 0xf7, 0x3d, 0xa8, 0xea, 0xa7, 0x36, 0x64, 0x81, 0xbd, 0xaf, 0x56,
-	0x56, 0x51, 0x82, 0x31, 0x0b, 0xd0, 0xc7, 0xd0, 0xce, 0x94, 0x4e, 0x5a, 0x54, 0x0e, 0x94, 0x51,
-	0x33, 0x35, 0x9a, 0xe8, 0xe7, 0xe1, 0x43, 0xd8, 0x9e, 0xca, 0x42, 0xc5, 0x6d, 0x5b, 0xad, 0xaf,
-	0x99, 0xad, 0x4f, 0xd5, 0x2f, 0x6c, 0xb4, 0x68, 0x1f, 0xb6, 0xbf, 0x5d, 0x92, 0x25, 0xf1, 0xed,
-	0x87, 0x6a, 0x41, 0x66, 0x76, 0x51, 0xae, 0x14, 0xad, 0xd2, 0x45, 0xb9, 0x52, 0xb2, 0xca, 0x17,
-	0xe5, 0x4a, 0xdd, 0x6a, 0x5c, 0x94, 0x2b, 0x7b, 0xd6, 0xfe, 0x45, 0xb9, 0xf2, 0xc0, 0xb2, 0x71,
-	0xc7, 0xa7, 0x09, 0xf1, 0x04, 0x4b, 0x28, 0xe1, 0xce, 0xad, 0x2b, 0xbc, 0x19, 0xf1, 0x71, 0x43,
-	0xbd, 0x20, 0xd9, 0xb4, 0x9a, 0x72, 0x95, 0xe3, 0xba, 0xc7, 0x16, 0xd7, 0x34, 0x22, 0xea, 0x15,
-	0xc2, 0xdb, 0x6e, 0x48, 0x12, 0xc1, 0xbb, 0x14, 0xaa, 0x12, 0x4d, 0x7d, 0xbd, 0x6d, 0xd8, 0xb9,
-	0x21, 0x09, 0xa7, 0x2c, 0x4a, 0x5b, 0x00, 0x33, 0x45, 0x87, 0x50, 0xf5, 0xd8, 0x62, 0x41, 0xc5,
-	0xe4, 0xc5, 0xb9, 0xa9, 0x88, 0x2b, 0x81, 0xac, 0x84, 0x59, 0x0b, 0x57, 0xc5, 0x6a, 0x2c, 0x0b,
-	0xaa, 0x4f, 0x6e, 0x54, 0xf1, 0xab, 0x60, 0x39, 0xec, 0x7e, 0x06, 0xad, 0x37, 0x3a, 0xdc, 0x84,
-	0x08, 0xa1, 0xda, 0xb0, 0x27, 0xd0, 0xf0, 0x66, 0xc4, 0x9b, 0x9b, 0x7e, 0x81, 0xab, 0xb4, 0x15,
-	0x5c, 0x57, 0x42, 0xdd, 0x27, 0xf0, 0xee, 0xdf, 0x76, 0xa0, 0xfc, 0x86, 0x92, 0x5b, 0x19, 0x52,
-	0x1e, 0x88, 0xa9, 0xd1, 0x21, 0x0b, 0x50, 0x0f, 0xaa, 0xab, 0x17, 0xa5, 0xa8, 0x30, 0x6e, 0x67,
-	0x18, 0xa7, 0x8c, 0xc3, 0x2b, 0x1b, 0xf4, 0x0c, 0x1e, 0x0e, 0x86, 0x97, 0x78, 0xd8, 0x3f, 0xbf,
-	0x1a, 0x0e, 0xd4, 0x09, 0x66, 0x7d, 0x2f, 0x37, 0x1d, 0xe8, 0x83, 0x95, 0xc1, 0x98, 0x05, 0x59,
-	0x81, 0xe1, 0x68, 0x00, 0x8d, 0x29, 0x71, 0xc5, 0x32, 0x21, 0xce, 0x34, 0x74, 0x03, 0xd9, 0xaa,
-	0xc8, 0x84, 0x8f, 0xb2, 0x84, 0x6f, 0xd4, 0xc9, 0x6a, 0x93, 0x2f, 0xa5, 0xc5, 0x30, 0x12, 0xc9,
-	0x1d, 0xae, 0x4f, 0x73, 0x22, 0x74, 0x06, 0x7b, 0x11, 0x21, 0x3e, 0x77, 0xdc, 0xc8, 0x0d, 0xef,
-	0x04, 0xf5, 0xb8, 0x13, 0x2d, 0x7d, 0xd3, 0xd1, 0x54, 0x70, 0x47, 0x29, 0xcf, 0x53, 0xdd, 0xd7,
-	0x52, 0x85, 0x7e, 0x0b, 0x28, 0x59, 0x46, 0xb2, 0x73, 0x55, 0x97, 0xc1, 0x94, 0xed, 0x6d, 0x75,
-	0xf3, 0xd0, 0x8a, 0xf3, 0xe9, 0x39, 0x62, 0xcb, 0x58, 0xaf, 0x4e, 0x76, 0x02, 0x87, 0xf9, 0x7d,
-	0x4b, 0x5c, 0x45, 0x3e, 0xd6, 0xce, 0x3b, 0x63, 0xe5, 0xf0, 0x1a, 0x2b, 0xb7, 0x55, 0xd0, 0x4f,
-	0x61, 0x9f, 0x2f, 0x83, 0x80, 0x70, 0x41, 0x7c, 0x1d, 0x2c, 0x65, 0x8f, 0xa5, 0x8e, 0x68, 0x37,
-	0xd3, 0x4a, 0x1f, 0x73, 0xf6, 0xa8, 0x0f, 0x96, 0x31, 0x73, 0xb8, 0xe1, 0x81, 0x5d, 0xdf, 0x28,
-	0x8c, 0x1b, 0x3c, 0xc1, 0xad, 0x9b, 0x0d, 0xe2, 0x9c, 0x42, 0x47, 0x25, 0xf4, 0x42, 0xb6, 0xf4,
-	0x9d, 0x25, 0x27, 0x89, 0x7a, 0x8a, 0x75, 0xc7, 0xdb, 0x96, 0xaa, 0xbe, 0xd4, 0xbc, 0x36, 0x0a,
-	0xd4, 0x83, 0xdd, 0x9c, 0xbd, 0x20, 0xee, 0x42, 0x77, 0xba, 0xad, 0x0d, 0x87, 0x2b, 0xe2, 0x2e,
-	0x54, 0xcf, 0x7b, 0x06, 0x7b, 0x39, 0x07, 0xee, 0xcd, 0xc8, 0x82, 0xbc, 0x60, 0x5c, 0x98, 0x06,
-	0xb0, 0x93, 0x79, 0x4c, 0x32, 0x95, 0x2c, 0x31, 0x1b, 0x49, 0x46, 0x03, 0xf5, 0x72, 0x55, 0x71,
-	0x6b, 0x2d, 0xc3, 0x68, 0x20, 0x4b, 0xdb, 0xd4, 0x15, 0x6e, 0xe8, 0xe8, 0x0f, 0x97, 0x9a, 0xb2,
-	0x02, 0x25, 0x1a, 0xaa, 0xaf, 0x97, 0x9f, 0x42, 0x45, 0xd2, 0x33, 0xa4, 0x5c, 0xa8, 0x97, 0xa5,
-	0x76, 0x66, 0xe5, 0x6a, 0x6c, 0x30, 0xa6, 0x5c, 0xe0, 0x9d, 0x50, 0x0f, 0xd0, 0x17, 0xa0, 0x12,
-	0xe4, 0xfb, 0xed, 0xe6, 0xf7, 0xbe, 0x98, 0x0d, 0xe9, 0x92, 0xb5, 0xe1, 0x07, 0xbf, 0x81, 0xf6,
-	0x3d, 0xee, 0xca, 0x2b, 0x37, 0x27, 0x77, 0xe9, 0x95, 0x9b, 0x93, 0xbb, 0xf5, 0xfe, 0xaa, 0x62,
-	0xfa, 0xab, 0x67, 0xc5, 0xa7, 0x85, 0xae, 0x05, 0xcd, 0xe7, 0x44, 0xc8, 0x4b, 0x80, 0xc9, 0xb7,
-	0x4b, 0xc2, 0x45, 0x97, 0x43, 0x7b, 0x12, 0xb9, 0x31, 0x9f, 0x31, 0xf1, 0x82, 0x06, 0xb3, 0x90,
-	0x06, 0x33, 0x81, 0x3e, 0x82, 0xd6, 0x35, 0x09, 0xa8, 0xa6, 0x73, 0xc8, 0x82, 0xd1, 0xc0, 0x84,
-	0x6f, 0x66, 0xe2, 0xb1, 0x94, 0xca, 0x2e, 0xc8, 0xbc, 0xdc, 0xda, 0x4a, 0x97, 0x9d, 0x9a, 0x96,
-	0x69, 0x13, 0x04, 0x65, 0x41, 0xfe, 0x20, 0xd2, 0xc2, 0x23, 0xc7, 0xdd, 0x7f, 0x15, 0xa0, 0x92,
-	0x66, 0x45, 0x8f, 0xa1, 0x2c, 0x11, 0x53, 0x19, 0xf2, 0x0f, 0xb9, 0x5a, 0xa5, 0x52, 0xc9, 0x53,
-	0xa3, 0xdc, 0xe1, 0xd4, 0x27, 0xd7, 0x6e, 0x22, 0xcf, 0x8e, 0x13, 0xdf, 0x6c, 0xae, 0x45, 0xf9,
-	0x44, 0xcb, 0xfb, 0x4a, 0x2c, 0xf3, 0xc9, 0xfa, 0x9a, 0xe6, 0x93, 0x63, 0x34, 0x02, 0xc4, 0x4d,
-	0x3a, 0x67, 0x96, 0xee, 0x32, 0x6b, 0xfa, 0xd2, 0x84, 0xf7, 0x70, 0xc0, 0x6d, 0x7e, 0x0f, 0x9a,
-	0x27, 0xd0, 0xc8, 0x42, 0xc9, 0x06, 0xc4, 0x7c, 0xe5, 0xd4, 0x53, 0xa1, 0x6c, 0x38, 0xba, 0x27,
-	0xb0, 0xff, 0x3a, 0x0e, 0x99, 0xeb, 0xa7, 0x21, 0x31, 0xe1, 0x31, 0x8b, 0x38, 0xb9, 0xdf, 0xc3,
-	0x76, 0xff, 0x04, 0x9d, 0x73, 0x6f, 0xfe, 0x3b, 0x72, 0xcd, 0x99, 0x37, 0x27, 0xc2, 0x9c, 0x8b,
-	0xcc, 0x23, 0x98, 0xa3, 0x8a, 0xac, 0x7a, 0x1c, 0x94, 0xcb, 0x16, 0xae, 0x0b, 0xd6, 0xcf, 0x64,
-	0x6f, 0xe3, 0x54, 0xf1, 0x07, 0x72, 0xaa, 0xbb, 0x0f, 0xbb, 0xeb, 0xf9, 0xf5, 0x4a, 0x4f, 0xfe,
-	0x5e, 0x00, 0x58, 0x7d, 0xea, 0xa2, 0xf7, 0xe0, 0xc1, 0xeb, 0xcb, 0xc1, 0xf9, 0xd5, 0xd0, 0xb9,
-	0xfa, 0xe6, 0x72, 0xe8, 0xbc, 0xfe, 0x7a, 0x72, 0x39, 0xec, 0x8f, 0xbe, 0x1c, 0x0d, 0x07, 0xd6,
-	0x8f, 0xd0, 0x1e, 0xb4, 0xf3, 0xca, 0xd1, 0xcb, 0xf3, 0xe7, 0x43, 0xab, 0xb0, 0xe9, 0x33, 0x1e,
-	0xbd, 0x19, 0x3a, 0x5a, 0x60, 0x15, 0xd1, 0x8f, 0xe1, 0x20, 0xaf, 0x1c, 0xbc, 0xea, 0x7f, 0x35,
-	0xc4, 0x4e, 0xff, 0xd5, 0xcb, 0xcb, 0x57, 0x93, 0xa1, 0x55, 0x42, 0x1d, 0x68, 0xe5, 0xf5, 0x5f,
-	0x3d, 0x9d, 0x58, 0xe5, 0xcd, 0x44, 0xe3, 0x57, 0xfd, 0xf3, 0xb1, 0xb5, 0x75, 0xf2, 0x97, 0x42,
-	0xfa, 0x97, 0x47, 0xba, 0xd6, 0xab, 0x73, 0xfc, 0x7c, 0x78, 0xf5, 0x8e, 0xb5, 0xe6, 0x95, 0xe9,
-	0x5a, 0x3b, 0xd0, 0xca, 0x8b, 0x65, 0x3a, 0xb5, 0xc6, 0xbc, 0xf0, 0xde, 0x1a, 0x37, 0x62, 0xe9,
-	0xe5, 0x94, 0xcf, 0xfe, 0x51, 0x80, 0x9a, 0x64, 0xef, 0x84, 0x24, 0x37, 0xd4, 0x93, 0x5f, 0x1c,
-	0x3b, 0xe6, 0xd6, 0xa1, 0x55, 0x4f, 0xb8, 0x7e, 0x0f, 0x0f, 0xd6, 0x79, 0xdf, 0x6d, 0xff, 0xf9,
-	0xbb, 0xff, 0xfc, 0xb5, 0x58, 0x43, 0x55, 0xf5, 0xdf, 0x90, 0xba, 0x04, 0xd7, 0xd0, 0x5c, 0x27,
-	0x15, 0x6a, 0xdf, 0xa3, 0xee, 0xc1, 0xa3, 0xdc, 0xdf, 0x14, 0x6f, 0x23, 0x60, 0xf7, 0x50, 0x05,
-	0xde, 0xef, 0xb6, 0x55, 0xe0, 0x94, 0xb5, 0xbd, 0x88, 0xdc, 0x3e, 0x2b, 0x9c, 0x9c, 0xfd, 0x11,
-	0xac, 0x8c, 0x09, 0xe9, 0xea, 0xa7, 0x50, 0xcf, 0x13, 0x04, 0x1d, 0x66, 0x29, 0xde, 0xc2, 0xdb,
-	0x83, 0xf7, 0xdf, 0xa1, 0x35, 0xe9, 0x1f, 0xaa, 0xf4, 0x9d, 0x6e, 0xb3, 0x77, 0x9b, 0xea, 0x7a,
-	0xae, 0x37, 0x7f, 0x56, 0x38, 0xf9, 0xe2, 0xc3, 0xdf, 0xff, 0x24, 0xa0, 0x62, 0xb6, 0xbc, 0x3e,
-	0xf5, 0xd8, 0xa2, 0x27, 0x49, 0xfa, 0x33, 0x9f, 0xdc, 0xa8, 0x41, 0x2f, 0xf7, 0x47, 0xd7, 0xf5,
-	0xb6, 0xe2, 0xf4, 0x2f, 0xff, 0x17, 0x00, 0x00, 0xff, 0xff, 0xed, 0x80, 0x0e, 0xcc, 0x5e, 0x13,
-	0x00, 0x00,
+	// 2773 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x59, 0xcd, 0x72, 0x1b, 0xc7,
+	0xb5, 0xbe, 0x20, 0x00, 0x12, 0x38, 0xf8, 0x1b, 0x34, 0x7f, 0x34, 0xa2, 0x25, 0x8b, 0x82, 0xae,
+	0x6d, 0x5a, 0xbe, 0x97, 0xbc, 0x97, 0x71, 0xd9, 0xb2, 0xbd, 0x88, 0x69, 0x00, 0x96, 0x48, 0x51,
+	0x12, 0xab, 0x41, 0xc9, 0xe5, 0x6c, 0xa6, 0x86, 0x33, 0x8d, 0x41, 0x07, 0x83, 0xe9, 0xf1, 0x74,
+	0x83, 0x14, 0xb2, 0xcc, 0x3a, 0x8b, 0x54, 0x25, 0xef, 0x90, 0x4a, 0xe5, 0x01, 0xfc, 0x20, 0xae,
+	0x2c, 0x53, 0xd9, 0x64, 0x9d, 0x67, 0x48, 0xf5, 0xcf, 0x0c, 0x66, 0x40, 0xa9, 0xe4, 0x64, 0x83,
+	0x9a, 0xfe, 0xce, 0x4f, 0x9f, 0x3e, 0xa7, 0xcf, 0x39, 0xdd, 0x0d, 0xd8, 0x89, 0xa7, 0xc1, 0xe1,
+	0x35, 0xb9, 0xbc, 0xa2, 0xe4, 0xfa, 0x50, 0xfe, 0x1c, 0xc4, 0x09, 0x13, 0x0c, 0x6d, 0x18, 0x6c,
+	0xf7, 0x4e, 0xc0, 0x58, 0x10, 0x92, 0x43, 0x37, 0xa6, 0x87, 0x6e, 0x14, 0x31, 0xe1, 0x0a, 0xca,
+	0x22, 0xae, 0xd9, 0x76, 0xef, 0x19, 0xaa, 0x1a, 0x5d, 0xce, 0xc7, 0x87, 0x82, 0xce, 0x08, 0x17,
+	0xee, 0x2c, 0x36, 0x0c, 0xdb, 0x79, 0xfd, 0x21, 0x0b, 0x34, 0xdc, 0x9b, 0x01, 0x5c, 0xb8, 0x49,
+	0x40, 0xc4, 0x28, 0x26, 0x1e, 0x6a, 0xc3, 0x1a, 0xf5, 0xed, 0xd2, 0x5e, 0x69, 0xbf, 0x8e, 0xd7,
+	0xa8, 0x8f, 0x3e, 0x82, 0x8a, 0x58, 0xc4, 0xc4, 0x5e, 0xdb, 0x2b, 0xed, 0xb7, 0x8f, 0x36, 0x0f,
+	0x8c, 0xfc, 0x81, 0x16, 0xb9, 0x58, 0xc4, 0x04, 0x2b, 0x06, 0xf4, 0x21, 0x74, 0x26, 0x2e, 0x77,
+	0x42, 0x7a, 0x45, 0x9c, 0x79, 0xec, 0xbb, 0x82, 0xd8, 0xe5, 0xbd, 0xd2, 0x7e, 0x0d, 0xb7, 0x26,
+	0x2e, 0x3f, 0xa3, 0x57, 0xe4, 0xa5, 0x02, 0x7b, 0x7f, 0x2a, 0x43, 0xe3, 0x9b, 0x39, 0x0d, 0x7d,
+	0x4c, 0x3c, 0x96, 0xf8, 0x68, 0x0b, 0xaa, 0xc4, 0xa7, 0x82, 0xdb, 0xa5, 0xbd, 0xf2, 0x7e, 0x1d,
+	0xeb, 0x81, 0x42, 0x93, 0x84, 0x25, 0x6a, 0xde, 0x3a, 0xd6, 0x03, 0xb4, 0x0b, 0xb5, 0x6b, 0x37,
+	0x89, 0x68, 0x14, 0x70, 0xbb, 0xac, 0xd8, 0xb3, 0x31, 0xfa, 0x02, 0x80, 0x0b, 0x37, 0x11, 0x8e,
+	0x5c, 0xb6, 0x5d, 0xd9, 0x2b, 0xed, 0x37, 0x8e, 0x76, 0x0f, 0xb4, 0x4f, 0x0e, 0x52, 0x9f, 0x1c,
+	0x5c, 0xa4, 0x3e, 0xc1, 0x75, 0xc5, 0x2d, 0xc7, 0xe8, 0x2b, 0x68, 0x8c, 0x69, 0x44, 0xf9, 0x44,
+	0xcb, 0x56, 0xdf, 0x29, 0x0b, 0x9a, 0x5d, 0x09, 0x7f, 0x06, 0x4d, 0xbd, 0x5c, 0x47, 0xba, 0x81,
+	0xdb, 0xf5, 0xbd, 0x72, 0xc1, 0x51, 0x7a, 0xd9, 0xca, 0x51, 0x8d, 0x79, 0xf6, 0xcd, 0xd1, 0x3e,
+	0x58, 0x94, 0x3b, 0x5e, 0xe2, 0xf2, 0x89, 0x93, 0x90, 0x4b, 0xe9, 0x11, 0x7b, 0x43, 0x39, 0xac,
+	0x4d, 0x79, 0x5f, 0xc2, 0x58, 0xa3, 0xe8, 0x16, 0x6c, 0xf0, 0xd8, 0x8d, 0x1c, 0xea, 0xdb, 0x35,
+	0xe5, 0x8d, 0x75, 0x39, 0x3c, 0xf1, 0xd1, 0x07, 0xd0, 0x56, 0x7e, 0x71, 0x3c, 0x57, 0x90, 0x80,
+	0x25, 0x0b, 0x1b, 0x14, 0xbd, 0xa5, 0xd0, 0xbe, 0x01, 0xd1, 0x27, 0xd0, 0xd5, 0x6c, 0x09, 0x99,
+	0x11, 0x9f, 0xaa, 0x4d, 0x63, 0x37, 0x14, 0xa7, 0xa5, 0x08, 0x78, 0x89, 0x9f, 0x56, 0x6a, 0xeb,
+	0xd6, 0x06, 0x2e, 0x87, 0x2c, 0xe8, 0xfd, 0xb3, 0x0c, 0x9d, 0xa7, 0x8f, 0x38, 0x26, 0x9c, 0xcd,
+	0x13, 0x8f, 0x9c, 0x44, 0x63, 0x86, 0x6e, 0x43, 0x2d, 0x66, 0xbe, 0x13, 0xb9, 0x33, 0x62, 0x36,
+	0xc9, 0x46, 0xcc, 0xfc, 0xe7, 0xee, 0x8c, 0xa0, 0x87, 0xd0, 0x95, 0x24, 0x2f, 0x21, 0x4a, 0x93,
+	0xf6, 0xa5, 0x0e, 0x5f, 0x27, 0x66, 0x7e, 0xdf, 0xe0, 0xca, 0x69, 0xff, 0x0f, 0xdb, 0x92, 0xd7,
+	0x38, 0x2e, 0x17, 0xb7, 0xb2, 0xe2, 0x47, 0x31, 0xf3, 0xb5, 0xdf, 0x46, 0x59, 0x90, 0xee, 0x02,
+	0x48, 0x11, 0x2e, 0x5c, 0x31, 0xe7, 0x2a, 0xbe, 0x75, 0x5c, 0x8f, 0x99, 0x3f, 0x52, 0x00, 0xfa,
+	0x1f, 0x40, 0x4b, 0xb2, 0x33, 0x23, 0x9c, 0xbb, 0x81, 0x0e, 0x65, 0x1d, 0x5b, 0x19, 0xdb, 0x33,
+	0x8d, 0xa3, 0xff, 0x83, 0x2d, 0x37, 0x0c, 0x1d, 0x8f, 0x45, 0xc2, 0xa5, 0x11, 0x49, 0xb8, 0x93,
+	0x10, 0xd7, 0x5f, 0xd8, 0xeb, 0x2a, 0x00, 0xc8, 0x0d, 0xc3, 0x7e, 0x46, 0xc2, 0x92, 0x82, 0xee,
+	0x43, 0x53, 0xea, 0x4f, 0x88, 0x32, 0x96, 0xab, 0x50, 0x55, 0x71, 0x23, 0x66, 0x3e, 0x36, 0x50,
+	0x3e, 0x4e, 0xf5, 0x42, 0x9c, 0x1e, 0x40, 0xcb, 0xa7, 0x3c, 0x0e, 0xdd, 0x85, 0x72, 0x1c, 0xb7,
+	0x41, 0xed, 0xdd, 0xa6, 0x01, 0xa5, 0xf7, 0x38, 0x7a, 0x04, 0xb0, 0x34, 0xc7, 0x6e, 0xec, 0x95,
+	0xf7, 0x1b, 0x47, 0x76, 0xb6, 0x8b, 0x32, 0x73, 0xf4, 0x3a, 0x70, 0x8e, 0x57, 0x4a, 0x2a, 0xb5,
+	0xb1, 0xeb, 0x11, 0x6e, 0x37, 0x57, 0x24, 0x9f, 0xa7, 0xa4, 0x54, 0x72, 0xc9, 0x7b, 0x5a, 0xa9,
+	0xd5, 0x2c, 0x1d, 0x41, 0x47, 0x06, 0xfc, 0xef, 0x25, 0x68, 0x0f, 0xfa, 0x85, 0x78, 0xdf, 0x87,
+	0xa6, 0xc7, 0xa2, 0x31, 0x0d, 0x9c, 0xd8, 0x15, 0x93, 0x34, 0x49, 0x1b, 0x1a, 0x3b, 0x97, 0x10,
+	0xfa, 0x18, 0xac, 0xcc, 0x98, 0x34, 0x3c, 0x26, 0xec, 0x5e, 0xd1, 0x6a, 0xb4, 0x07, 0x8d, 0x0c,
+	0x3a, 0x19, 0x98, 0x60, 0xe7, 0xa1, 0x95, 0x2c, 0xae, 0xfe, 0x3b, 0x59, 0x9c, 0x73, 0xff, 0x7a,
+	0xde, 0xfd, 0xa7, 0x95, 0x5a, 0xc5, 0xaa, 0xea, 0x2d, 0xfd, 0x39, 0x58, 0xdf, 0x1f, 0x3f, 0x3b,
+	0x2b, 0x2c, 0xf1, 0x01, 0xb4, 0xa6, 0x8f, 0xe4, 0x06, 0xd0, 0x58, 0xba, 0xc6, 0xe6, 0x74, 0xb9,
+	0xf5, 0x79, 0xef, 0x03, 0xe8, 0x9e, 0x31, 0xcf, 0x0d, 0x0b, 0x92, 0x16, 0x94, 0x63, 0x53, 0x2c,
+	0xcb, 0x58, 0x7e, 0xf6, 0x4e, 0xa1, 0xfa, 0xad, 0xeb, 0x11, 0x81, 0x10, 0x54, 0x72, 0x39, 0xa2,
+	0xbe, 0x65, 0x4d, 0xbb, 0x72, 0xc3, 0x79, 0x9a, 0x14, 0x7a, 0x90, 0x37, 0xbb, 0x9c, 0x37, 0xbb,
+	0xf7, 0x3d, 0x54, 0xce, 0x68, 0x34, 0x95, 0xb3, 0xcc, 0x93, 0xd0, 0x68, 0x92, 0x9f, 0x99, 0xf2,
+	0xb5, 0x9c, 0xf2, 0x4f, 0x60, 0x7d, 0x42, 0xdc, 0x50, 0x4c, 0x94, 0x96, 0x46, 0xae, 0x00, 0x49,
+	0x25, 0x4f, 0x14, 0x09, 0x1b, 0x96, 0xde, 0x4f, 0x00, 0xb5, 0x74, 0x25, 0x6f, 0x34, 0x75, 0x00,
+	0x56, 0xe8, 0x72, 0xe1, 0xf8, 0x24, 0x0e, 0xd9, 0xe2, 0xe7, 0x96, 0xd4, 0xb6, 0x94, 0x19, 0x28,
+	0x11, 0x15, 0x91, 0xfb, 0xd0, 0x14, 0x09, 0x0d, 0x02, 0x92, 0x38, 0x33, 0xe6, 0xeb, 0x70, 0x56,
+	0x71, 0xc3, 0x60, 0xcf, 0x98, 0x4f, 0xd0, 0x17, 0xd0, 0x52, 0x45, 0xce, 0x99, 0x50, 0x2e, 0x64,
+	0x05, 0x5b, 0x57, 0xdb, 0x77, 0x2b, 0xb3, 0x3e, 0xd7, 0x2a, 0x70, 0x53, 0xb1, 0x3e, 0xd1, 0x9c,
+	0x52, 0xd4, 0x9b, 0x27, 0x09, 0x89, 0x84, 0xb3, 0xac, 0x9e, 0x6f, 0x15, 0x35, 0xac, 0x0a, 0x93,
+	0xe9, 0x1f, 0x93, 0xc8, 0xa7, 0x51, 0xa0, 0x45, 0x65, 0xf6, 0x73, 0x16, 0xa9, 0xf2, 0x5a, 0xc5,
+	0xc8, 0xd0, 0x8c, 0xbc, 0xa4, 0xa0, 0x03, 0xd8, 0x2c, 0x4a, 0xe8, 0x9e, 0x55, 0x57, 0x5b, 0xa5,
+	0x9b, 0x17, 0x18, 0x4a, 0x02, 0x3a, 0x5d, 0xe5, 0xe7, 0x34, 0xf2, 0x88, 0x0d, 0xef, 0xf4, 0x61,
+	0x41, 0xd7, 0x48, 0x0a, 0xc9, 0xb9, 0x65, 0x67, 0x4d, 0xf5, 0x79, 0x13, 0x37, 0x0a, 0x08, 0x57,
+	0x15, 0xbc, 0x86, 0xbb, 0x13, 0x97, 0x9f, 0x6b, 0x4a, 0x5f, 0x13, 0xd0, 0xa7, 0xd0, 0x26, 0x91,
+	0x1f, 0x33, 0x1a, 0x09, 0x27, 0xa4, 0xd1, 0x94, 0xdb, 0x77, 0x94, 0x53, 0x5b, 0x85, 0x2d, 0x81,
+	0x5b, 0x29, 0x93, 0x1c, 0xa9, 0x8e, 0x1b, 0x33, 0xff, 0x64, 0x60, 0xb7, 0xf4, 0xee, 0x54, 0x03,
+	0x34, 0x80, 0x6e, 0x3e, 0x39, 0x1c, 0x1a, 0x8d, 0x99, 0xdd, 0xde, 0x2b, 0x15, 0x4a, 0xcc, 0x4a,
+	0x93, 0xc0, 0x9d, 0x69, 0x11, 0x40, 0xc7, 0x60, 0xf9, 0xde, 0x8a, 0x92, 0x8e, 0x52, 0x72, 0x2b,
+	0x53, 0x52, 0x2c, 0x3c, 0xb8, 0xed, 0x7b, 0x05, 0x15, 0x8f, 0x01, 0x2d, 0xdc, 0x59, 0xb8, 0xa2,
+	0xc4, 0x52, 0x4a, 0x6e, 0x67, 0x4a, 0x56, 0x93, 0x1b, 0x5b, 0x52, 0xa8, 0xa0, 0xe8, 0x14, 0x36,
+	0x43, 0x99, 0xc9, 0x2b, 0x9a, 0xba, 0x26, 0x32, 0x99, 0x8b, 0x56, 0xb3, 0x1d, 0x77, 0xc3, 0x55,
+	0x48, 0x36, 0xe0, 0x64, 0x1e, 0xc9, 0xec, 0x48, 0x0b, 0x1f, 0xd2, 0x0d, 0xd8, 0xa0, 0xa6, 0xec,
+	0xdd, 0x83, 0x06, 0xe5, 0x8e, 0xa0, 0xa1, 0x18, 0xd3, 0x90, 0xd8, 0x9b, 0x2a, 0x70, 0x40, 0xf9,
+	0x85, 0x41, 0xd0, 0xc7, 0x50, 0xe5, 0x31, 0xf1, 0xb8, 0xfd, 0xde, 0x5e, 0xb9, 0x90, 0xbb, 0xcb,
+	0x83, 0x19, 0xd6, 0x1c, 0xb2, 0xcb, 0xf2, 0x09, 0xbb, 0x4e, 0x77, 0x95, 0x9e, 0x75, 0x4b, 0x69,
+	0xec, 0x48, 0x82, 0xde, 0x37, 0x7a, 0xde, 0xf7, 0xa0, 0xae, 0xcf, 0x17, 0x21, 0x0b, 0xec, 0x1d,
+	0x65, 0x59, 0x4d, 0x01, 0x67, 0x2c, 0x40, 0x1f, 0x43, 0x37, 0x23, 0x3a, 0x69, 0x05, 0xda, 0x55,
+	0x4c, 0xed, 0x94, 0x69, 0xa4, 0xfb, 0xd7, 0x87, 0xb0, 0x3e, 0x96, 0x55, 0x8d, 0xdb, 0xb6, 0xb2,
+	0xaf, 0x9d, 0xd9, 0xa7, 0x8a, 0x1d, 0x36, 0x54, 0xb4, 0x03, 0xeb, 0x3f, 0xcc, 0xc9, 0x9c, 0xf8,
+	0xf6, 0x6d, 0x65, 0x90, 0x19, 0xa1, 0x8f, 0xa0, 0x23, 0x12, 0x77, 0x3c, 0xa6, 0x9e, 0xe3, 0xb9,
+	0xb1, 0x98, 0x27, 0xc4, 0xbe, 0xab, 0x27, 0x32, 0x70, 0x5f, 0xa3, 0xd2, 0x60, 0x69, 0x8d, 0xc7,
+	0x42, 0x96, 0xd8, 0xef, 0x6b, 0x83, 0x43, 0x16, 0xf4, 0xe5, 0x58, 0x1e, 0x3d, 0xbc, 0x84, 0x45,
+	0xce, 0xaf, 0xd9, 0xa5, 0x7d, 0x4f, 0xe9, 0xdf, 0x90, 0xe3, 0x53, 0x76, 0x99, 0xeb, 0x52, 0xfa,
+	0xd0, 0xb8, 0x97, 0x35, 0x96, 0x31, 0x0d, 0x86, 0x12, 0x3a, 0xad, 0xd4, 0xd6, 0xac, 0xf2, 0x69,
+	0xa5, 0x56, 0xb6, 0x2a, 0xa7, 0x95, 0x5a, 0xd3, 0x6a, 0x9d, 0x56, 0x6a, 0xdb, 0xd6, 0xce, 0x69,
+	0xa5, 0x76, 0xcb, 0xb2, 0xf1, 0xa6, 0x4f, 0x13, 0xe2, 0x09, 0x96, 0x50, 0xc2, 0x9d, 0x6b, 0x57,
+	0x78, 0x13, 0xe2, 0xe3, 0x96, 0x6a, 0x79, 0xd9, 0xb0, 0x9e, 0xe6, 0x0b, 0xc7, 0x4d, 0x8f, 0xcd,
+	0x2e, 0x69, 0x44, 0x54, 0xdb, 0xc4, 0xeb, 0x6e, 0x48, 0x12, 0xc1, 0x7b, 0x14, 0xea, 0x32, 0xa2,
+	0xba, 0xc4, 0xd8, 0xb0, 0x71, 0x45, 0x12, 0x2e, 0x8f, 0x5a, 0xe6, 0x9c, 0x64, 0x86, 0xe8, 0x0e,
+	0xd4, 0x3d, 0x36, 0x9b, 0x51, 0x31, 0x7a, 0x72, 0x6c, 0x4a, 0xf8, 0x12, 0x90, 0xd5, 0x38, 0x3b,
+	0x3b, 0xd7, 0xb1, 0xfa, 0x96, 0x1d, 0xc0, 0x27, 0x57, 0xaa, 0x00, 0xd7, 0xb0, 0xfc, 0xec, 0x7d,
+	0x06, 0x9d, 0x57, 0x5a, 0xdd, 0x88, 0x08, 0xa1, 0xce, 0xbf, 0x0f, 0xa0, 0xe5, 0x4d, 0x88, 0x37,
+	0x35, 0x87, 0x2a, 0xae, 0xa6, 0xad, 0xe1, 0xa6, 0x02, 0xf5, 0x61, 0x8a, 0xf7, 0xfe, 0x52, 0x87,
+	0xca, 0x2b, 0x4a, 0xae, 0xa5, 0x4a, 0xb9, 0x29, 0x4c, 0x53, 0x09, 0x59, 0x80, 0x0e, 0xa1, 0xbe,
+	0x6c, 0x81, 0x6b, 0x2a, 0xce, 0xdd, 0x2c, 0xce, 0xe9, 0xae, 0xc7, 0x4b, 0x1e, 0xf4, 0x25, 0xdc,
+	0x1e, 0x0c, 0xcf, 0xf1, 0xb0, 0x7f, 0x7c, 0x31, 0x1c, 0xa8, 0x5d, 0x94, 0x5d, 0x38, 0xb8, 0x39,
+	0xfa, 0xdf, 0x5a, 0x32, 0x9c, 0xb1, 0x20, 0x2b, 0x72, 0x1c, 0x0d, 0xa0, 0x35, 0x26, 0xae, 0x8c,
+	0xb9, 0x33, 0x0e, 0xdd, 0x40, 0x9e, 0xe7, 0xe4, 0x84, 0xf7, 0xb2, 0x09, 0x5f, 0xa9, 0xdd, 0xa5,
+	0x59, 0xbe, 0x95, 0x1c, 0xc3, 0x48, 0x24, 0x0b, 0xdc, 0x1c, 0xe7, 0x20, 0x74, 0x04, 0xdb, 0x11,
+	0x21, 0x3e, 0x77, 0xdc, 0xc8, 0x0d, 0x17, 0x82, 0x7a, 0xdc, 0x89, 0xe6, 0xbe, 0x39, 0xf6, 0xd5,
+	0xf0, 0xa6, 0x22, 0x1e, 0xa7, 0xb4, 0xe7, 0x92, 0x84, 0xbe, 0x06, 0x94, 0xcc, 0x23, 0x79, 0x65,
+	0x50, 0x09, 0x69, 0x5a, 0xc7, 0xba, 0xca, 0x7e, 0xb4, 0xcc, 0xbb, 0x34, 0x8e, 0xd8, 0x32, 0xdc,
+	0xcb, 0xc8, 0x8e, 0xe0, 0x4e, 0x7e, 0xdd, 0xd2, 0xaf, 0x22, 0xaf, 0x6b, 0xe3, 0xad, 0xba, 0x72,
+	0xfe, 0x3a, 0x53, 0x62, 0x4b, 0xa5, 0x9f, 0xc2, 0x0e, 0x9f, 0x07, 0x01, 0xe1, 0x82, 0xf8, 0x5a,
+	0x59, 0xba, 0x7b, 0x2c, 0x15, 0xa2, 0xad, 0x8c, 0x2a, 0x65, 0x4c, 0xec, 0x51, 0x1f, 0x2c, 0xc3,
+	0xe6, 0x70, 0xb3, 0x0f, 0xec, 0xe6, 0x4a, 0x71, 0x5e, 0xd9, 0x27, 0xb8, 0x73, 0x55, 0x04, 0x64,
+	0x7b, 0x51, 0x13, 0x7a, 0x21, 0x9b, 0xfb, 0xce, 0x9c, 0x93, 0x44, 0x1d, 0x07, 0xf4, 0x55, 0xa3,
+	0x2b, 0x49, 0x7d, 0x49, 0x79, 0x69, 0x08, 0xe8, 0x10, 0xb6, 0x72, 0xfc, 0x82, 0xb8, 0x33, 0x7d,
+	0x1d, 0xe8, 0xac, 0x08, 0x5c, 0x10, 0x77, 0xa6, 0x2e, 0x06, 0x47, 0xb0, 0x9d, 0x13, 0xe0, 0xde,
+	0x84, 0xcc, 0xc8, 0x13, 0xc6, 0x85, 0x39, 0x25, 0x6f, 0x66, 0x12, 0xa3, 0x8c, 0x24, 0xcb, 0xdc,
+	0xca, 0x24, 0x27, 0x03, 0x73, 0xbb, 0xe9, 0x14, 0x66, 0x38, 0x19, 0xc8, 0xf2, 0x3a, 0x76, 0x85,
+	0x1b, 0x9a, 0xe4, 0xd7, 0x37, 0x1b, 0x50, 0x90, 0xca, 0x7d, 0xf4, 0x09, 0xc8, 0x2a, 0xe2, 0x84,
+	0x94, 0x0b, 0xd5, 0xdd, 0x1a, 0x47, 0x56, 0xae, 0xce, 0x07, 0x67, 0x94, 0x0b, 0xbc, 0x11, 0xea,
+	0x0f, 0xf4, 0x0d, 0xa8, 0x09, 0xf2, 0x97, 0x92, 0xf6, 0x3b, 0xbb, 0x76, 0x4b, 0x8a, 0x2c, 0xef,
+	0x2a, 0x16, 0x94, 0x39, 0xf9, 0x41, 0xf5, 0x94, 0x2a, 0x96, 0x9f, 0xe8, 0x73, 0xb0, 0xf3, 0xeb,
+	0x61, 0x53, 0x12, 0x39, 0xe4, 0x75, 0x4c, 0x13, 0xe2, 0xab, 0x9e, 0x51, 0xc3, 0xdb, 0xcb, 0x65,
+	0x49, 0xea, 0x50, 0x13, 0xd1, 0xd7, 0x60, 0xad, 0x38, 0x82, 0xdb, 0x9b, 0x2a, 0x59, 0x76, 0x0a,
+	0x3b, 0x2c, 0x73, 0x08, 0x6e, 0x17, 0xfc, 0xc3, 0x65, 0xf5, 0xd6, 0x05, 0xca, 0xde, 0x5a, 0xa9,
+	0xde, 0xc7, 0x12, 0x4e, 0xcb, 0x97, 0x6c, 0x66, 0x2b, 0x49, 0xbc, 0xad, 0xef, 0xef, 0x61, 0x21,
+	0x75, 0xf7, 0xc1, 0x92, 0x6c, 0x71, 0x42, 0xc6, 0xf4, 0xb5, 0x73, 0x4d, 0x7d, 0x31, 0x51, 0xbd,
+	0xa5, 0x8a, 0xa5, 0xf8, 0xb9, 0x82, 0xbf, 0x93, 0xe8, 0xee, 0x2f, 0xa1, 0x7b, 0x23, 0x83, 0xa5,
+	0x6b, 0xa6, 0x64, 0x91, 0x16, 0x9e, 0x29, 0x59, 0x14, 0x8f, 0xc5, 0x35, 0x73, 0x2c, 0xfe, 0x72,
+	0xed, 0x51, 0xa9, 0x67, 0x41, 0xfb, 0x31, 0x11, 0xb2, 0x14, 0x60, 0xf2, 0xc3, 0x9c, 0x70, 0xd1,
+	0xe3, 0xd0, 0x1d, 0x45, 0x6e, 0xcc, 0x27, 0x4c, 0x3c, 0xa1, 0xc1, 0x24, 0xa4, 0xc1, 0x44, 0xc8,
+	0xf6, 0x72, 0x49, 0x02, 0xaa, 0x93, 0x3a, 0x64, 0xc1, 0xc9, 0xc0, 0xa8, 0x6f, 0x67, 0xf0, 0x99,
+	0x44, 0x65, 0x9b, 0x30, 0x67, 0x28, 0xcd, 0xa5, 0x8b, 0x6f, 0x43, 0x63, 0x9a, 0x05, 0x41, 0x45,
+	0x90, 0xd7, 0x22, 0x2d, 0xbf, 0xf2, 0xbb, 0xf7, 0xb7, 0x12, 0xd4, 0xd2, 0x59, 0xd1, 0x7d, 0xa8,
+	0x48, 0xdf, 0xa9, 0x19, 0xf2, 0x47, 0x2a, 0x65, 0xa5, 0x22, 0xc9, 0xbd, 0x4b, 0xb9, 0xc3, 0xa9,
+	0x4f, 0x2e, 0xdd, 0x44, 0x06, 0x8e, 0x13, 0xdf, 0x2c, 0xae, 0x43, 0xf9, 0x48, 0xe3, 0x7d, 0x05,
+	0xcb, 0xf9, 0x64, 0x97, 0x49, 0xe7, 0x93, 0xdf, 0xe8, 0x04, 0x10, 0x37, 0xd3, 0x39, 0x93, 0x74,
+	0x95, 0xd9, 0xf1, 0x3b, 0x9d, 0xf0, 0x86, 0x1f, 0x70, 0x97, 0xdf, 0x70, 0xcd, 0x03, 0x68, 0x65,
+	0xaa, 0xe4, 0x51, 0xd0, 0x5c, 0x88, 0x9b, 0x29, 0x28, 0x8f, 0x7e, 0xbd, 0x87, 0xb0, 0xf3, 0x32,
+	0x0e, 0x99, 0xeb, 0xa7, 0x2a, 0x31, 0xe1, 0x31, 0x8b, 0x38, 0xb9, 0x79, 0xf5, 0xe8, 0xfd, 0xbe,
+	0x04, 0x9b, 0xc7, 0xde, 0xf4, 0x3b, 0x72, 0xc9, 0x99, 0x37, 0x25, 0xc2, 0x04, 0x46, 0x4e, 0x24,
+	0x98, 0xa3, 0x7a, 0x8d, 0xea, 0x91, 0x4a, 0xa6, 0x8a, 0x9b, 0x82, 0xf5, 0x33, 0xec, 0x4d, 0xa9,
+	0xb5, 0xf6, 0x1f, 0xa6, 0x56, 0x39, 0x4b, 0xad, 0xde, 0x0e, 0x6c, 0x15, 0x2d, 0xd2, 0xc6, 0xf7,
+	0x46, 0xd0, 0x2a, 0x24, 0xc6, 0x8d, 0xa7, 0xad, 0x37, 0x5d, 0xa3, 0xde, 0x07, 0x70, 0x39, 0x67,
+	0x1e, 0x75, 0x05, 0xf1, 0x4d, 0x17, 0xcb, 0x21, 0xbd, 0x3f, 0xae, 0x41, 0x55, 0xa5, 0xcd, 0x0d,
+	0x6d, 0x3b, 0xb0, 0xae, 0x3b, 0xa3, 0xd1, 0x67, 0x46, 0xf2, 0xcd, 0x8a, 0x93, 0x2b, 0x92, 0x50,
+	0xb1, 0x30, 0x51, 0xce, 0xc6, 0xd2, 0x6b, 0x33, 0x37, 0xa2, 0x63, 0xd9, 0x41, 0x94, 0x29, 0xfa,
+	0x59, 0xa3, 0x99, 0x82, 0xaa, 0x7c, 0xda, 0xb0, 0x51, 0x7c, 0xce, 0x48, 0x87, 0xf2, 0xb2, 0x3c,
+	0xa6, 0x09, 0x17, 0x0e, 0x27, 0x24, 0xb2, 0xd7, 0xdf, 0xe9, 0xca, 0xba, 0xe2, 0x1e, 0x11, 0x12,
+	0xa1, 0xcf, 0xa1, 0x1e, 0xba, 0xa9, 0xe4, 0xc6, 0x3b, 0x25, 0x6b, 0xa1, 0x6b, 0x04, 0xb7, 0xa0,
+	0xea, 0xb1, 0x79, 0x24, 0xcc, 0x5d, 0x49, 0x0f, 0x7a, 0x3f, 0x96, 0x00, 0x96, 0xf7, 0x4c, 0x59,
+	0x91, 0xcd, 0x43, 0x8c, 0x27, 0xef, 0x7d, 0x7a, 0x2f, 0x80, 0x86, 0xfa, 0xf2, 0xda, 0x77, 0x17,
+	0x40, 0x36, 0xce, 0xc8, 0x5b, 0x38, 0x33, 0xfd, 0x5a, 0x50, 0xc6, 0x75, 0x83, 0x3c, 0xcb, 0xbd,
+	0xfe, 0x95, 0xf3, 0xaf, 0x7f, 0x5f, 0x00, 0xa8, 0x0d, 0x46, 0x7c, 0xc7, 0x15, 0x3f, 0xe7, 0x85,
+	0xcf, 0x70, 0x1f, 0x0b, 0xe9, 0x43, 0x7d, 0xf5, 0x5d, 0x98, 0xb3, 0x41, 0x3a, 0xec, 0xfd, 0xb5,
+	0x04, 0x9d, 0x95, 0xc7, 0x95, 0x37, 0xde, 0x88, 0x77, 0xa1, 0x96, 0xbd, 0xfd, 0xac, 0xa9, 0xf5,
+	0x64, 0x63, 0xf4, 0x19, 0xdc, 0x52, 0xce, 0x14, 0x24, 0x99, 0xd1, 0x48, 0xbf, 0x7e, 0x99, 0x1b,
+	0xa5, 0x5e, 0xc0, 0xb6, 0x24, 0x5f, 0x2c, 0xa9, 0xe6, 0x52, 0xf9, 0x15, 0xec, 0xde, 0x90, 0x23,
+	0xaf, 0xa9, 0xd0, 0x5e, 0xab, 0xa8, 0x59, 0x6e, 0xad, 0x88, 0x0e, 0x5f, 0x53, 0x91, 0xba, 0x90,
+	0xb1, 0x99, 0x33, 0xa5, 0x61, 0x48, 0x7c, 0xb3, 0xaa, 0x3a, 0x63, 0xb3, 0xa7, 0x0a, 0x90, 0x89,
+	0xda, 0x59, 0x79, 0xfa, 0x91, 0x27, 0xcf, 0xec, 0xf1, 0xc7, 0x2c, 0x6e, 0x09, 0x14, 0x9e, 0xf6,
+	0xd6, 0x8a, 0x4f, 0x7b, 0xc5, 0xb7, 0xb7, 0xf2, 0xea, 0xdb, 0xdb, 0xcd, 0x6b, 0x50, 0xe5, 0x0d,
+	0xd7, 0xa0, 0x87, 0x7f, 0x2e, 0x01, 0x2c, 0x5f, 0x43, 0xd1, 0x7b, 0x70, 0xeb, 0xe5, 0xf9, 0xe0,
+	0xf8, 0x62, 0xe8, 0x5c, 0x7c, 0x7f, 0x3e, 0x74, 0x5e, 0x3e, 0x1f, 0x9d, 0x0f, 0xfb, 0x27, 0xdf,
+	0x9e, 0x0c, 0x07, 0xd6, 0x7f, 0xa1, 0x6d, 0xe8, 0xe6, 0x89, 0x27, 0xcf, 0x8e, 0x1f, 0x0f, 0xad,
+	0xd2, 0xaa, 0xcc, 0xd9, 0xc9, 0xab, 0xa1, 0xa3, 0x01, 0x6b, 0x0d, 0xbd, 0x0f, 0xbb, 0x79, 0xe2,
+	0xe0, 0x45, 0xff, 0xe9, 0x10, 0x3b, 0xfd, 0x17, 0xcf, 0xce, 0x5f, 0x8c, 0x86, 0x56, 0x19, 0x6d,
+	0x42, 0x27, 0x4f, 0x7f, 0xfa, 0x68, 0x64, 0x55, 0x56, 0x27, 0x3a, 0x7b, 0xd1, 0x3f, 0x3e, 0xb3,
+	0xaa, 0x0f, 0x7f, 0x57, 0x4a, 0x5f, 0xc5, 0x53, 0x5b, 0x2f, 0x8e, 0xf1, 0xe3, 0xe1, 0xc5, 0x5b,
+	0x6c, 0xcd, 0x13, 0x53, 0x5b, 0x37, 0xa1, 0x93, 0x87, 0xe5, 0x74, 0xca, 0xc6, 0x3c, 0x78, 0xc3,
+	0xc6, 0x15, 0x5d, 0xda, 0x9c, 0xca, 0xd1, 0x8f, 0x25, 0x68, 0xc8, 0x0e, 0x33, 0x22, 0xc9, 0x15,
+	0xf5, 0xe4, 0xfb, 0xcc, 0x86, 0xe9, 0x8c, 0x68, 0x79, 0x83, 0x2e, 0xf6, 0xca, 0xdd, 0x62, 0x6f,
+	0xea, 0x75, 0x7f, 0xfb, 0xd3, 0x3f, 0xfe, 0xb0, 0xd6, 0x40, 0x75, 0xf5, 0xf7, 0x81, 0xc4, 0xd1,
+	0x25, 0xb4, 0x8b, 0x85, 0x1f, 0x75, 0x6f, 0xb4, 0x97, 0xdd, 0x7b, 0xb9, 0x97, 0xec, 0x37, 0x35,
+	0x89, 0xde, 0x1d, 0xa5, 0x78, 0xe7, 0xcb, 0xd2, 0xc3, 0x5e, 0x57, 0xe9, 0x4e, 0x9b, 0xcb, 0x61,
+	0x44, 0xae, 0x8f, 0x7e, 0x03, 0x56, 0x56, 0x9a, 0x53, 0xeb, 0xc7, 0xd0, 0xcc, 0x57, 0x6c, 0x74,
+	0x67, 0x79, 0x22, 0xb9, 0xd9, 0x5a, 0x76, 0xef, 0xbe, 0x85, 0x6a, 0xa6, 0xbf, 0xad, 0xa6, 0xdf,
+	0x94, 0xd3, 0xb7, 0x0f, 0xaf, 0x53, 0xf2, 0xa1, 0xeb, 0x4d, 0xbf, 0xf9, 0xf0, 0x57, 0xff, 0x1d,
+	0x50, 0x31, 0x99, 0x5f, 0x1e, 0x78, 0x6c, 0x76, 0x28, 0xfb, 0xc8, 0xff, 0xfa, 0xe4, 0x4a, 0x7d,
+	0x1c, 0xe6, 0xfe, 0x0b, 0xb9, 0x5c, 0x57, 0xc5, 0xe3, 0x17, 0xff, 0x1a, 0x00, 0x67, 0x0d, 0x38,
+	0x45, 0x81, 0x19, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,70 @@
+import React from "react"
+import { mount } from "enzyme"
+import AlertCenter, {
+  AlertCenterAckButton,
+  AlertCenterBadge,
+  AlertCenterItem,
+  AlertCenterPanel,
+} from "./AlertCenter"
+import fetchMock from "jest-fetch-mock"
+
+const alerts: Proto.webviewAlert[] = [
+  {
+    id: "crash-loop:foo:foo-pod",
+    source: "crash-loop",
+    severity: "error",
+    manifestName: "foo",
+    message: "Container main on pod foo-pod is crash looping (3 restarts)",
+    count: 3,
+  },
+  {
+    id: "image-injection",
+    source: "image-injection",
+    severity: "warning",
+    message: "Image not used in any deploy config: gcr.io/foo",
+    count: 1,
+  },
+]
+
+beforeEach(() => {
+  fetchMock.resetMocks()
+})
+
+it("renders nothing when there are no alerts", () => {
+  const root = mount(<AlertCenter alerts={[]} isSnapshot={false} />)
+  expect(root.find(AlertCenterBadge)).toHaveLength(0)
+})
+
+it("renders nothing on a Tilt Snapshot", () => {
+  const root = mount(<AlertCenter alerts={alerts} isSnapshot={true} />)
+  expect(root.find(AlertCenterBadge)).toHaveLength(0)
+})
+
+it("opens the panel from the badge", () => {
+  const root = mount(<AlertCenter alerts={alerts} isSnapshot={false} />)
+  expect(root.find(AlertCenterBadge).text()).toContain("2 alerts")
+  expect(root.find(AlertCenterPanel)).toHaveLength(0)
+
+  root.find(AlertCenterBadge).simulate("click")
+  expect(root.find(AlertCenterPanel)).toHaveLength(1)
+  expect(root.find(AlertCenterItem)).toHaveLength(2)
+})
+
+it("acknowledges all alerts", () => {
+  fetchMock.mockResponse("")
+  const root = mount(<AlertCenter alerts={alerts} isSnapshot={false} />)
+  root.find(AlertCenterBadge).simulate("click")
+
+  root
+    .find(AlertCenterPanel)
+    .find(AlertCenterAckButton)
+    .first()
+    .simulate("click")
+
+  expect(fetchMock.mock.calls.length).toEqual(1)
+  expect(fetchMock.mock.calls[0][0]).toEqual("/api/alerts/ack")
+  expect(JSON.parse(fetchMock.mock.calls[0][1]?.body as string)).toEqual({
+    ids: ["crash-loop:foo:foo-pod", "image-injection"],
+  })
+  expect(root.find(AlertCenterPanel)).toHaveLength(0)
+})
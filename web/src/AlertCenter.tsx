@@ -0,0 +1,193 @@
+import React, { useState } from "react"
+import styled from "styled-components"
+import { ReactComponent as WarningSvg } from "./assets/svg/warning.svg"
+import {
+  Color,
+  Font,
+  FontSize,
+  Height,
+  SizeUnit,
+  ZIndex,
+} from "./style-helpers"
+import { apiUrl } from "./basePath"
+
+type Alert = Proto.webviewAlert
+
+type AlertCenterProps = {
+  alerts: Alert[]
+  isSnapshot: boolean
+}
+
+export const AlertCenterBadge = styled.button`
+  position: fixed;
+  right: ${SizeUnit(0.5)};
+  bottom: ${Height.statusbar + 16}px;
+  z-index: ${ZIndex.HUDHeader};
+  display: flex;
+  align-items: center;
+  border: 0;
+  border-radius: ${SizeUnit(0.25)};
+  cursor: pointer;
+  background-color: ${Color.grayDark};
+  color: ${Color.white};
+  font-family: ${Font.monospace};
+  font-size: ${FontSize.small};
+  padding: ${SizeUnit(0.15)} ${SizeUnit(0.35)};
+
+  &.has-error svg {
+    fill: ${Color.red};
+  }
+
+  svg {
+    fill: ${Color.yellow};
+    margin-right: ${SizeUnit(0.2)};
+  }
+`
+
+export const AlertCenterPanel = styled.section`
+  position: fixed;
+  right: ${SizeUnit(0.5)};
+  bottom: ${Height.statusbar + 56}px;
+  z-index: ${ZIndex.HUDHeader};
+  width: ${SizeUnit(14)};
+  max-height: 50vh;
+  overflow-y: auto;
+  background-color: ${Color.white};
+  color: ${Color.text};
+  font-family: ${Font.sansSerif};
+  font-size: ${FontSize.smallest};
+  box-shadow: 0 0 ${SizeUnit(0.5)} ${Color.grayDarkest};
+`
+
+let AlertCenterHeader = styled.header`
+  display: flex;
+  justify-content: space-between;
+  align-items: center;
+  padding: ${SizeUnit(0.25)} ${SizeUnit(0.5)};
+  border-bottom: 1px solid ${Color.offWhite};
+  font-size: ${FontSize.small};
+`
+
+export const AlertCenterItem = styled.div`
+  display: flex;
+  justify-content: space-between;
+  align-items: flex-start;
+  padding: ${SizeUnit(0.25)} ${SizeUnit(0.5)};
+  border-left: 4px solid ${Color.yellow};
+
+  &.is-error {
+    border-left-color: ${Color.red};
+  }
+
+  & + & {
+    border-top: 1px solid ${Color.offWhite};
+  }
+`
+
+let AlertCenterMessage = styled.p`
+  margin: 0;
+  white-space: pre-wrap;
+  word-break: break-word;
+`
+
+let AlertCenterMeta = styled.span`
+  display: block;
+  color: ${Color.grayLight};
+  font-family: ${Font.monospace};
+`
+
+export const AlertCenterAckButton = styled.button`
+  flex-shrink: 0;
+  border: 0;
+  cursor: pointer;
+  background-color: transparent;
+  color: ${Color.blueDark};
+  font-family: ${Font.sansSerif};
+  font-size: ${FontSize.smallest};
+  margin-left: ${SizeUnit(0.25)};
+
+  &:hover {
+    color: ${Color.blue};
+  }
+`
+
+// With no IDs, acknowledges every alert.
+function acknowledgeAlerts(ids: string[]) {
+  fetch(apiUrl("/api/alerts/ack"), {
+    method: "post",
+    body: JSON.stringify({ ids: ids }),
+  }).then(response => {
+    if (!response.ok) {
+      console.log(response)
+    }
+  })
+}
+
+function AlertCenter(props: AlertCenterProps) {
+  const [isOpen, setOpen] = useState(false)
+
+  let alerts = props.alerts
+  if (props.isSnapshot || alerts.length === 0) {
+    return null
+  }
+
+  let hasError = alerts.some(a => a.severity === "error")
+  let badge = (
+    <AlertCenterBadge
+      className={hasError ? "has-error" : ""}
+      onClick={() => setOpen(!isOpen)}
+    >
+      <WarningSvg width="16px" height="16px" />
+      {alerts.length} {alerts.length === 1 ? "alert" : "alerts"}
+    </AlertCenterBadge>
+  )
+
+  if (!isOpen) {
+    return badge
+  }
+
+  let items = alerts.map(a => {
+    let meta = a.manifestName || ""
+    if (a.count && a.count > 1) {
+      meta += ` ×${a.count}`
+    }
+    return (
+      <AlertCenterItem
+        key={a.id}
+        className={a.severity === "error" ? "is-error" : ""}
+      >
+        <AlertCenterMessage>
+          {meta ? <AlertCenterMeta>{meta}</AlertCenterMeta> : null}
+          {a.message}
+        </AlertCenterMessage>
+        <AlertCenterAckButton
+          onClick={() => acknowledgeAlerts([a.id || ""])}
+        >
+          Dismiss
+        </AlertCenterAckButton>
+      </AlertCenterItem>
+    )
+  })
+
+  let ackAll = () => {
+    acknowledgeAlerts(alerts.map(a => a.id || ""))
+    setOpen(false)
+  }
+
+  return (
+    <>
+      {badge}
+      <AlertCenterPanel>
+        <AlertCenterHeader>
+          Alerts
+          <AlertCenterAckButton onClick={ackAll}>
+            Dismiss all
+          </AlertCenterAckButton>
+        </AlertCenterHeader>
+        {items}
+      </AlertCenterPanel>
+    </>
+  )
+}
+
+export default AlertCenter
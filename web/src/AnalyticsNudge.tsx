@@ -1,5 +1,6 @@
 import React, { Component } from "react"
 import "./AnalyticsNudge.scss"
+import { apiUrl } from "./basePath"
 
 const nudgeTimeoutMs = 15000
 const nudgeElem = (): JSX.Element => {
@@ -87,7 +88,7 @@ class AnalyticsNudge extends Component<
   }
 
   analyticsOpt(optIn: boolean) {
-    let url = `//${window.location.host}/api/analytics_opt`
+    let url = apiUrl("/api/analytics_opt")
 
     let payload = { opt: optIn ? "opt-in" : "opt-out" }
 
@@ -69,4 +69,43 @@ describe("AppController", () => {
       snapshotHighlight: snapshotHighlight,
     })
   })
+
+  it("acks views with seq numbers and log checkpoints", () => {
+    let pb = new PathBuilder("localhost:10350", "/")
+    let ac = new AppController(pb, HUD)
+
+    expect(ac.ackFor({ tiltStartTime: "2020-01-01T00:00:00Z" })).toBeNull()
+    expect(
+      ac.ackFor({ tiltStartTime: "2020-01-01T00:00:00Z", seq: 2 })
+    ).toStrictEqual({ tiltStartTime: "2020-01-01T00:00:00Z", seq: 2 })
+    expect(
+      ac.ackFor({
+        tiltStartTime: "2020-01-01T00:00:00Z",
+        seq: 3,
+        logList: { fromCheckpoint: 0, toCheckpoint: 10 },
+      })
+    ).toStrictEqual({
+      tiltStartTime: "2020-01-01T00:00:00Z",
+      seq: 3,
+      toCheckpoint: 10,
+    })
+  })
+
+  it("resumes from the last checkpoint on reconnect", () => {
+    let pb = new PathBuilder("localhost:10350", "/")
+    let ac = new AppController(pb, HUD)
+    expect(ac.socketUrl()).toBe("ws://localhost:10350/ws/view")
+
+    ac.ackFor({
+      tiltStartTime: "2020-01-01T00:00:00Z",
+      logList: { fromCheckpoint: 0, toCheckpoint: 10 },
+    })
+    expect(ac.socketUrl()).toBe(
+      "ws://localhost:10350/ws/view?checkpoint=10&tiltStartTime=2020-01-01T00%3A00%3A00Z"
+    )
+
+    // If Tilt restarts, start over.
+    ac.ackFor({ tiltStartTime: "2020-01-02T00:00:00Z" })
+    expect(ac.socketUrl()).toBe("ws://localhost:10350/ws/view")
+  })
 })
@@ -18,6 +18,11 @@ class AppController {
   disposed: boolean = false
   pb: PathBuilder
 
+  // The last log checkpoint we received, and the Tilt process it came from.
+  // When we reconnect, we send these so the server only sends the logs we missed.
+  checkpoint: number = 0
+  tiltStartTime: string = ""
+
   /**
    * @param pathBuilder a PathBuilder
    * @param component The top-level component for the app.
@@ -39,7 +44,7 @@ class AppController {
 
   createNewSocket() {
     this.tryConnectCount++
-    this.socket = new WebSocket(this.url)
+    this.socket = new WebSocket(this.socketUrl())
     let socket = this.socket
 
     this.socket.addEventListener("close", this.onSocketClose.bind(this))
@@ -51,14 +56,9 @@ class AppController {
       this.tryConnectCount = 0
 
       let data: Proto.webviewView = JSON.parse(event.data)
-      let toCheckpoint = data.logList?.toCheckpoint
-      if (toCheckpoint && toCheckpoint > 0) {
-        let tiltStartTime = data.tiltStartTime
-        let response: Proto.webviewAckWebsocketRequest = {
-          toCheckpoint,
-          tiltStartTime,
-        }
-        socket.send(JSON.stringify(response))
+      let ack = this.ackFor(data)
+      if (ack) {
+        socket.send(JSON.stringify(ack))
       }
 
       // @ts-ignore
@@ -69,6 +69,48 @@ class AppController {
     })
   }
 
+  // The websocket URL, including where to resume from if we've
+  // already received logs on a previous connection.
+  socketUrl(): string {
+    if (this.checkpoint <= 0 || !this.tiltStartTime) {
+      return this.url
+    }
+    let params = new URLSearchParams({
+      checkpoint: String(this.checkpoint),
+      tiltStartTime: this.tiltStartTime,
+    })
+    return `${this.url}?${params.toString()}`
+  }
+
+  // Records what we've received, and returns the message that tells
+  // the server about it (or null if there's nothing to acknowledge).
+  ackFor(data: Proto.webviewView): Proto.webviewAckWebsocketRequest | null {
+    let tiltStartTime = data.tiltStartTime
+    if (tiltStartTime !== this.tiltStartTime) {
+      this.tiltStartTime = tiltStartTime ?? ""
+      this.checkpoint = 0
+    }
+
+    let toCheckpoint = data.logList?.toCheckpoint ?? 0
+    if (toCheckpoint > 0) {
+      this.checkpoint = toCheckpoint
+    }
+
+    let seq = data.seq ?? 0
+    if (toCheckpoint <= 0 && seq <= 0) {
+      return null
+    }
+
+    let response: Proto.webviewAckWebsocketRequest = { tiltStartTime }
+    if (toCheckpoint > 0) {
+      response.toCheckpoint = toCheckpoint
+    }
+    if (seq > 0) {
+      response.seq = seq
+    }
+    return response
+  }
+
   dispose() {
     this.disposed = true
     if (this.socket) {
@@ -29,6 +29,7 @@ import { logLinesFromString } from "./logs"
 import HudState from "./HudState"
 import AlertPane from "./AlertPane"
 import AnalyticsNudge from "./AnalyticsNudge"
+import AlertCenter from "./AlertCenter"
 import NotFound from "./NotFound"
 import { numberOfAlerts } from "./alerts"
 import Features from "./feature"
@@ -40,6 +41,7 @@ import HUDLayout from "./HUDLayout"
 import LogStore from "./LogStore"
 import { traceNav } from "./trace"
 import ErrorModal from "./ErrorModal"
+import { apiUrl, basePath } from "./basePath"
 
 type HudProps = {
   history: History
@@ -61,7 +63,9 @@ class HUD extends Component<HudProps, HudState> {
 
     this.pathBuilder = new PathBuilder(
       window.location.host,
-      window.location.pathname
+      window.location.pathname,
+      basePath(),
+      window.location.protocol
     )
     this.controller = new AppController(this.pathBuilder, this)
     this.history = props.history
@@ -174,7 +178,7 @@ class HUD extends Component<HudProps, HudState> {
   }
 
   sendSnapshot(snapshot: Proto.webviewSnapshot) {
-    let url = `//${window.location.host}/api/snapshot/new`
+    let url = apiUrl("/api/snapshot/new")
 
     if (!snapshot.view) {
       return
@@ -183,10 +187,8 @@ class HUD extends Component<HudProps, HudState> {
     let body = JSON.stringify(snapshot)
 
     // TODO(dmiller): we need to figure out a way to get human readable error messages from the server
-    fetch(url, {
-      method: "post",
-      body: body,
-    })
+    gzipRequestBody(body)
+      .then(req => fetch(url, { method: "post", ...req }))
       .then(res => {
         res
           .json()
@@ -278,6 +280,10 @@ class HUD extends Component<HudProps, HudState> {
     return (
       <div className={hudClasses.join(" ")}>
         <AnalyticsNudge needsNudge={needsNudge} />
+        <AlertCenter
+          alerts={view?.alerts ?? []}
+          isSnapshot={this.pathBuilder.isSnapshot()}
+        />
         <SocketBar state={this.state.socketState} />
         {fatalErrorModal}
         {errorModal}
@@ -331,7 +337,10 @@ class HUD extends Component<HudProps, HudState> {
 
     return (
       <ResourceInfo
+        resourceName={name}
         endpoints={endpoints}
+        trafficCapture={selectedResource?.trafficCapture ?? ""}
+        cronJob={selectedResource?.cronJob ?? false}
         podID={podID}
         podStatus={podStatus}
         showSnapshotButton={showSnapshot}
@@ -422,6 +431,8 @@ class HUD extends Component<HudProps, HudState> {
     let tiltCloudSchemeHost = view?.tiltCloudSchemeHost || ""
     let tiltCloudTeamID = view?.tiltCloudTeamID || null
     let tiltCloudTeamName = view?.tiltCloudTeamName || null
+    let tiltCloudTokenExpired = !!view?.tiltCloudTokenExpired
+    let tiltCloudTeams = view?.tiltCloudTeams || []
     let isSnapshot = this.pathBuilder.isSnapshot()
     let sidebarRoute = (t: ResourceView, props: RouteComponentProps<any>) => {
       let name = props.match.params.name
@@ -432,6 +443,8 @@ class HUD extends Component<HudProps, HudState> {
             tiltCloudSchemeHost={tiltCloudSchemeHost}
             tiltCloudTeamID={tiltCloudTeamID}
             tiltCloudTeamName={tiltCloudTeamName}
+            tiltCloudTokenExpired={tiltCloudTokenExpired}
+            tiltCloudTeams={tiltCloudTeams}
             isSnapshot={isSnapshot}
           />
           <SidebarResources
@@ -473,6 +486,18 @@ class HUD extends Component<HudProps, HudState> {
     let snapshotHighlight = this.state.snapshotHighlight || null
     let isSnapshot = this.pathBuilder.isSnapshot()
 
+    let prefixColors: { [manifestName: string]: string } = {}
+    resources.forEach(r => {
+      if (r.name && r.logColor) {
+        prefixColors[r.name] = r.logColor
+      }
+    })
+    let logSettings = {
+      showTimestamps: view?.logTimestamps ?? false,
+      prefixWidth: view?.logPrefixWidth || undefined,
+      prefixColors: prefixColors,
+    }
+
     let traceRoute = (props: RouteComponentProps<any>) => {
       let name = props.match.params?.name ?? ""
       let span = props.match.params?.span ?? ""
@@ -496,6 +521,7 @@ class HUD extends Component<HudProps, HudState> {
           handleClearHighlight={this.handleClearHighlight}
           highlight={snapshotHighlight}
           isSnapshot={isSnapshot}
+          {...logSettings}
         />
       )
     }
@@ -521,6 +547,7 @@ class HUD extends Component<HudProps, HudState> {
           handleClearHighlight={this.handleClearHighlight}
           highlight={snapshotHighlight}
           isSnapshot={isSnapshot}
+          {...logSettings}
         />
       )
     }
@@ -565,6 +592,7 @@ class HUD extends Component<HudProps, HudState> {
           handleClearHighlight={this.handleClearHighlight}
           highlight={this.state.snapshotHighlight}
           isSnapshot={isSnapshot}
+          {...logSettings}
         />
       )
     }
@@ -658,4 +686,20 @@ class HUD extends Component<HudProps, HudState> {
   }
 }
 
+// Snapshots are multi-MB of JSON, so compress them if the browser can.
+function gzipRequestBody(body: string): Promise<RequestInit> {
+  let CompressionStream = (window as any).CompressionStream
+  if (!CompressionStream) {
+    return Promise.resolve({ body })
+  }
+
+  let stream = (new Blob([body]) as any)
+    .stream()
+    .pipeThrough(new CompressionStream("gzip"))
+  return new Response(stream).blob().then(compressed => ({
+    body: compressed,
+    headers: { "Content-Encoding": "gzip" },
+  }))
+}
+
 export default HUD
@@ -18,6 +18,11 @@ type LogPaneProps = {
   handleClearHighlight: () => void
   highlight: SnapshotHighlight | null | undefined
   isSnapshot: boolean
+
+  // From the Tiltfile's log_settings().
+  showTimestamps?: boolean
+  prefixWidth?: number
+  prefixColors?: { [manifestName: string]: string }
 }
 
 type LogPaneState = {
@@ -369,6 +374,10 @@ class LogPane extends Component<LogPaneProps, LogPaneState> {
           lineId={i}
           showManifestPrefix={this.props.showManifestPrefix}
           shouldHighlight={shouldHighlight}
+          time={l.time}
+          showTimestamp={this.props.showTimestamps}
+          prefixColor={this.props.prefixColors?.[l.manifestName]}
+          prefixWidth={this.props.prefixWidth}
         />
       )
       logLineEls.push(el)
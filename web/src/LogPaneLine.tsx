@@ -11,19 +11,39 @@ type LogPaneProps = {
   shouldHighlight: boolean
   showManifestPrefix: boolean
   isContextChange: boolean
+  time?: string
+  showTimestamp?: boolean
+  prefixColor?: string
+  prefixWidth?: number
 }
 
-let LogLinePrefix = React.memo((props: { name: string }) => {
-  let name = props.name
-  if (!name) {
-    name = "(global)"
+let LogLinePrefix = React.memo(
+  (props: { name: string; color?: string; width?: number }) => {
+    let name = props.name
+    if (!name) {
+      name = "(global)"
+    }
+    let className = "logLinePrefix"
+    if (props.color) {
+      className += ` is-color-${props.color}`
+    }
+    // Leave room for the padding around the name.
+    let style = props.width ? { width: `${props.width + 2}ch` } : undefined
+    return (
+      <span className={className} title={name} style={style}>
+        {name}
+      </span>
+    )
   }
-  return (
-    <span className="logLinePrefix" title={name}>
-      {name}
-    </span>
-  )
-})
+)
+
+function formatLogTime(time: string): string {
+  let date = new Date(time)
+  if (isNaN(date.getTime())) {
+    return ""
+  }
+  return date.toLocaleTimeString("en-US", { hour12: false })
+}
 
 class LogPaneLine extends PureComponent<LogPaneProps> {
   private ref: React.RefObject<HTMLSpanElement> = React.createRef()
@@ -39,7 +59,19 @@ class LogPaneLine extends PureComponent<LogPaneProps> {
     let prefix = null
     let text = props.text
     if (props.showManifestPrefix) {
-      prefix = <LogLinePrefix name={props.manifestName} />
+      prefix = (
+        <LogLinePrefix
+          name={props.manifestName}
+          color={props.prefixColor}
+          width={props.prefixWidth}
+        />
+      )
+    }
+    let timestamp = null
+    if (props.showTimestamp && props.time) {
+      timestamp = (
+        <span className="logLineTime">{formatLogTime(props.time)}</span>
+      )
     }
     let classes = ["LogPaneLine"]
     if (props.shouldHighlight) {
@@ -66,6 +98,7 @@ class LogPaneLine extends PureComponent<LogPaneProps> {
         data-lineid={props.lineId}
         className={classes.join(" ")}
       >
+        {timestamp}
         {prefix}
         <AnsiLine className="LogPaneLine-content" line={text} />
       </span>
@@ -22,6 +22,38 @@ describe("LogStore", () => {
     return { spanId: name, text: text, time: now() }
   }
 
+  it("hides build detail unless asked", () => {
+    let detail = (text: string, value: string): Proto.webviewLogSegment => {
+      return {
+        spanId: "fe",
+        text,
+        time: now(),
+        fields: { buildDetail: value },
+      }
+    }
+
+    let logs = new LogStore()
+    logs.append({
+      spans: { fe: { manifestName: "fe" } },
+      segments: [
+        newManifestSegment("fe", "RUN npm install\n"),
+        detail("  → npm ERR! oops\n", "1"),
+        newManifestSegment("fe", "ERROR IN: RUN npm install\n"),
+        detail("  → npm ERR! oops\n", "replay"),
+      ],
+    })
+
+    expect(logLinesToString(logs.manifestLog("fe"), false)).toEqual(
+      "RUN npm install\nERROR IN: RUN npm install\n  → npm ERR! oops"
+    )
+
+    logs.showBuildDetail = true
+    logs.lineCache = {}
+    expect(logLinesToString(logs.manifestLog("fe"), false)).toEqual(
+      "RUN npm install\n  → npm ERR! oops\nERROR IN: RUN npm install"
+    )
+  })
+
   it("handles simple printing", () => {
     let logs = new LogStore()
     logs.append({
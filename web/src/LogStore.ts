@@ -12,6 +12,11 @@ import { isBuildSpanId } from "./logs"
 const defaultSpanId = "_"
 const fieldNameProgressId = "progressID"
 
+// See pkg/logger/fields.go
+const fieldNameBuildDetail = "buildDetail"
+const buildDetailHidden = "1"
+const buildDetailReplay = "replay"
+
 type LogSpan = {
   spanId: string
   manifestName: string
@@ -54,7 +59,22 @@ class StoredLine {
   }
 
   canContinueLine(other: StoredLine) {
-    return this.level === other.level && this.spanId === other.spanId
+    return (
+      this.level === other.level &&
+      this.spanId === other.spanId &&
+      this.field(fieldNameBuildDetail) === other.field(fieldNameBuildDetail)
+    )
+  }
+
+  // Whether this line is hidden by the user's build_output settings.
+  isHiddenBuildDetail(showBuildDetail: boolean) {
+    switch (this.field(fieldNameBuildDetail)) {
+      case buildDetailHidden:
+        return !showBuildDetail
+      case buildDetailReplay:
+        return showBuildDetail
+    }
+    return false
   }
 }
 
@@ -78,7 +98,12 @@ class LogStore {
   // We index all the warnings up-front by span id.
   warningIndex: { [key: string]: LogWarning[] }
 
+  // If true, show the full build output, even the parts
+  // that the Tiltfile's build_output settings hide.
+  showBuildDetail: boolean
+
   constructor() {
+    this.showBuildDetail = false
     this.spans = {}
     this.segments = []
     this.lines = []
@@ -391,6 +416,10 @@ class LogStore {
         continue
       }
 
+      if (storedLine.isHiddenBuildDetail(this.showBuildDetail)) {
+        continue
+      }
+
       let line = this.lineCache[i]
       if (!line) {
         let text = storedLine.text
@@ -404,6 +433,7 @@ class LogStore {
           manifestName: span.manifestName,
           buildEvent: storedLine.fields?.buildEvent,
           spanId: spanId,
+          time: storedLine.time,
         }
 
         this.lineCache[i] = line
@@ -23,4 +23,25 @@ describe("PathBuilder", () => {
     let pb = new PathBuilder("10.205.131.189:10350", "/r/fe")
     expect(pb.getDataUrl()).toEqual("ws://10.205.131.189:10350/ws/view")
   })
+
+  it("handles a base path behind a reverse proxy", () => {
+    let pb = new PathBuilder(
+      "ide.example.com",
+      "/tilt/r/fe",
+      "/tilt",
+      "https:"
+    )
+    expect(pb.getDataUrl()).toEqual("wss://ide.example.com/tilt/ws/view")
+    expect(pb.path("/foo")).toEqual("/foo")
+  })
+
+  it("handles snapshots behind a reverse proxy", () => {
+    let pb = new PathBuilder(
+      "ide.example.com",
+      "/tilt/snapshot/aaaaaa",
+      "/tilt"
+    )
+    expect(pb.getDataUrl()).toEqual("/tilt/api/snapshot/aaaaaa")
+    expect(pb.path("/foo")).toEqual("/snapshot/aaaaaa/foo")
+  })
 })
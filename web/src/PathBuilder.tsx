@@ -2,10 +2,24 @@
 
 class PathBuilder {
   private host: string
+  private basePath: string
+  private wsScheme: string
   private snapId: string = ""
 
-  constructor(host: string, pathname: string) {
+  // basePath is where the app lives, when a reverse proxy serves it at a subpath.
+  constructor(
+    host: string,
+    pathname: string,
+    basePath: string = "",
+    protocol: string = "http:"
+  ) {
     this.host = host
+    this.basePath = basePath
+    this.wsScheme = protocol === "https:" ? "wss" : "ws"
+
+    if (basePath && pathname.indexOf(basePath) === 0) {
+      pathname = pathname.substring(basePath.length)
+    }
 
     const snapshotRe = new RegExp("^/snapshot/([^/]+)")
     let snapMatch = snapshotRe.exec(pathname)
@@ -18,7 +32,7 @@ class PathBuilder {
     if (this.isSnapshot()) {
       return this.snapshotDataUrl()
     }
-    return `ws://${this.host}/ws/view`
+    return `${this.wsScheme}://${this.host}${this.basePath}/ws/view`
   }
 
   isSnapshot(): boolean {
@@ -26,7 +40,7 @@ class PathBuilder {
   }
 
   private snapshotDataUrl(): string {
-    return `/api/snapshot/${this.snapId}`
+    return `${this.basePath}/api/snapshot/${this.snapId}`
   }
 
   private snapshotPathBase(): string {
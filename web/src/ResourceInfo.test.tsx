@@ -93,3 +93,83 @@ it("displays mixed named/unnamed endpoints", () => {
   expect(links.at(1).prop("href")).toEqual(namedEndpointLink.url)
   expect(links.at(1).text()).toEqual(namedEndpointLink.name)
 })
+
+it("toggles traffic capture on the resource's port-forwards", () => {
+  fetchMock.resetMocks()
+  fetchMock.mockResponse(JSON.stringify({}))
+
+  const root = mount(
+    <ResourceInfo
+      showSnapshotButton={false}
+      handleOpenModal={fakeHandleOpenModal}
+      highlight={null}
+      resourceName="fe"
+      podID="fe-pod"
+      endpoints={[unnamedEndpointLink]}
+      trafficCapture="requests"
+    />
+  )
+
+  let button = root.find("button.trafficCaptureButton")
+  expect(button.text()).toEqual("Capturing requests")
+  button.simulate("click")
+
+  expect(fetchMock.mock.calls.length).toEqual(1)
+  expect(fetchMock.mock.calls[0][0]).toEqual("/api/action")
+  expect(JSON.parse(fetchMock.mock.calls[0][1]?.body as string)).toEqual({
+    type: "SetTrafficCapture",
+    manifest_name: "fe",
+    traffic_capture: "bodies",
+  })
+})
+
+it("doesn't offer traffic capture without a pod", () => {
+  const root = mount(
+    <ResourceInfo
+      showSnapshotButton={false}
+      handleOpenModal={fakeHandleOpenModal}
+      highlight={null}
+      resourceName="fe"
+      endpoints={[unnamedEndpointLink]}
+    />
+  )
+
+  expect(root.find("button.trafficCaptureButton")).toHaveLength(0)
+})
+
+it("runs a CronJob now", () => {
+  fetchMock.resetMocks()
+  fetchMock.mockResponse(JSON.stringify({}))
+
+  const root = mount(
+    <ResourceInfo
+      showSnapshotButton={false}
+      handleOpenModal={fakeHandleOpenModal}
+      highlight={null}
+      resourceName="report"
+      cronJob={true}
+    />
+  )
+
+  root.find("button.runNowButton").simulate("click")
+
+  expect(fetchMock.mock.calls.length).toEqual(1)
+  expect(fetchMock.mock.calls[0][0]).toEqual("/api/action")
+  expect(JSON.parse(fetchMock.mock.calls[0][1]?.body as string)).toEqual({
+    type: "CronJobRunNow",
+    manifest_name: "report",
+  })
+})
+
+it("doesn't offer to run resources without a CronJob", () => {
+  const root = mount(
+    <ResourceInfo
+      showSnapshotButton={false}
+      handleOpenModal={fakeHandleOpenModal}
+      highlight={null}
+      resourceName="fe"
+    />
+  )
+
+  expect(root.find("button.runNowButton")).toHaveLength(0)
+})
@@ -3,12 +3,16 @@ import styled from "styled-components"
 import * as s from "./style-helpers"
 import { SnapshotHighlight } from "./types"
 import { ReactComponent as SnapshotSvg } from "./assets/svg/snapshot.svg"
+import { apiUrl } from "./basePath"
 
 type Link = Proto.webviewLink
 
 type HUDHeaderProps = {
+  resourceName?: string
   podID?: string
   endpoints?: Link[]
+  trafficCapture?: string
+  cronJob?: boolean
   podStatus?: string
   showSnapshotButton: boolean
   highlight: SnapshotHighlight | null
@@ -59,6 +63,99 @@ let ResourceLink = styled.a`
   }
 `
 
+let EndpointHealth = styled.span`
+  font-size: ${s.FontSize.smallest};
+  margin-left: ${s.SizeUnit(0.15)};
+  color: ${s.Color.red};
+
+  &.isHealthy {
+    color: ${s.Color.green};
+  }
+`
+
+function endpointHealthText(health: Proto.webviewLinkHealth): string {
+  if (health.error) {
+    return "down"
+  }
+  return `${health.statusCode ?? 0} · ${health.latencyMs ?? 0}ms`
+}
+
+// Traffic capture cycles off → requests → bodies → off.
+const nextTrafficCapture: { [mode: string]: string } = {
+  "": "requests",
+  requests: "bodies",
+  bodies: "",
+}
+
+const trafficCaptureLabel: { [mode: string]: string } = {
+  "": "Capture traffic",
+  requests: "Capturing requests",
+  bodies: "Capturing bodies",
+}
+
+function setTrafficCapture(resourceName: string, mode: string) {
+  fetch(apiUrl("/api/action"), {
+    method: "POST",
+    body: JSON.stringify({
+      type: "SetTrafficCapture",
+      manifest_name: resourceName,
+      traffic_capture: mode,
+    }),
+    headers: {
+      "Content-Type": "application/json",
+    },
+  }).then(response => {
+    if (!response.ok) {
+      console.error(response)
+    }
+  })
+}
+
+let TrafficCaptureButton = styled.button`
+  border: 1px solid ${s.Color.grayLight};
+  border-radius: 2px;
+  font-family: ${s.Font.sansSerif};
+  font-size: ${s.FontSize.smallest};
+  background-color: transparent;
+  color: ${s.Color.grayLight};
+  margin-left: ${s.SizeUnit(0.5)};
+  cursor: pointer;
+
+  &:hover {
+    border-color: ${s.Color.blue};
+  }
+
+  &.isCapturing {
+    color: ${s.Color.blue};
+    border-color: ${s.Color.blue};
+  }
+
+  ${s.mixinHideOnSmallScreen}
+`
+
+function runCronJobNow(resourceName: string) {
+  fetch(apiUrl("/api/action"), {
+    method: "POST",
+    body: JSON.stringify({
+      type: "CronJobRunNow",
+      manifest_name: resourceName,
+    }),
+    headers: {
+      "Content-Type": "application/json",
+    },
+  }).then(response => {
+    if (!response.ok) {
+      console.error(response)
+    }
+  })
+}
+
+let RunNowButton = styled(TrafficCaptureButton)`
+  &:hover {
+    color: ${s.Color.blue};
+  }
+`
+
 let SnapshotButton = styled.button`
   border: 1px solid transparent;
   font-family: ${s.Font.sansSerif};
@@ -110,6 +207,42 @@ class ResourceInfo extends PureComponent<HUDHeaderProps> {
       )
   }
 
+  renderTrafficCaptureButton() {
+    let resourceName = this.props.resourceName
+    if (!resourceName || !this.props.podID) {
+      return null
+    }
+
+    let mode = this.props.trafficCapture ?? ""
+    let next = nextTrafficCapture[mode] ?? ""
+    return (
+      <TrafficCaptureButton
+        className={`trafficCaptureButton ${mode ? "isCapturing" : ""}`}
+        title="Log the HTTP requests through these port-forwards to this resource's log. Click again to log bodies too."
+        onClick={() => setTrafficCapture(resourceName as string, next)}
+      >
+        {trafficCaptureLabel[mode] ?? trafficCaptureLabel[""]}
+      </TrafficCaptureButton>
+    )
+  }
+
+  renderRunNowButton() {
+    let resourceName = this.props.resourceName
+    if (!resourceName || !this.props.cronJob) {
+      return null
+    }
+
+    return (
+      <RunNowButton
+        className="runNowButton"
+        title="Create a Job from this resource's CronJob, instead of waiting for its schedule. Tilt suspends the schedule while it runs."
+        onClick={() => runCronJobNow(resourceName as string)}
+      >
+        Run now
+      </RunNowButton>
+    )
+  }
+
   render() {
     let podStatus = this.props.podStatus
     let podID = this.props.podID
@@ -129,8 +262,17 @@ class ResourceInfo extends PureComponent<HUDHeaderProps> {
             key={ep.url}
           >
             {ep.name || ep.url}
+            {ep.health && (
+              <EndpointHealth
+                className={ep.health.healthy ? "isHealthy" : ""}
+                title={ep.health.error || `HTTP ${ep.health.statusCode}`}
+              >
+                {endpointHealthText(ep.health)}
+              </EndpointHealth>
+            )}
           </ResourceLink>
         ))}
+        {this.renderTrafficCaptureButton()}
       </PortForward>
     )
 
@@ -140,6 +282,7 @@ class ResourceInfo extends PureComponent<HUDHeaderProps> {
           <PodStatus>{podStatus}</PodStatus>
           {podID && <PodId>{podID}</PodId>}
           {endpointsEl}
+          {this.renderRunNowButton()}
         </ResourceInfoStyle>
         {this.renderSnapshotButton()}
       </Root>
@@ -4,6 +4,7 @@ import "./ShareSnapshotModal.scss"
 import cookies from "js-cookie"
 import intro from "./assets/png/share-snapshot-intro.png"
 import { ReactComponent as ArrowSvg } from "./assets/svg/arrow.svg"
+import { apiUrl } from "./basePath"
 
 type props = {
   handleSendSnapshot: () => void
@@ -205,7 +206,7 @@ export default class ShareSnapshotModal extends PureComponent<props> {
   }
 
   static notifyTiltOfRegistration() {
-    let url = `${window.location.protocol}//${window.location.host}/api/user_started_tilt_cloud_registration`
+    let url = apiUrl("/api/user_started_tilt_cloud_registration")
     fetch(url, {
       method: "POST",
       headers: {
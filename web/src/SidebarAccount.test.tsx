@@ -3,6 +3,7 @@ import SidebarAccount, {
   SidebarAccountRoot,
   MenuContentButtonSignUp,
   MenuContentButtonTiltCloud,
+  MenuContentTokenExpired,
 } from "./SidebarAccount"
 import { MemoryRouter } from "react-router-dom"
 import { mount } from "enzyme"
@@ -54,3 +55,21 @@ it("renders TiltCloud button when user is signed in", () => {
 
   expect(root.find(MenuContentButtonTiltCloud)).toHaveLength(1)
 })
+
+it("renders Link button again when the token has expired", () => {
+  const root = mount(
+    <MemoryRouter initialEntries={["/"]}>
+      <SidebarAccount
+        tiltCloudUsername=""
+        tiltCloudSchemeHost="http://cloud.tilt.dev"
+        tiltCloudTeamID="cactus inc"
+        tiltCloudTeamName=""
+        tiltCloudTokenExpired={true}
+        isSnapshot={false}
+      />
+    </MemoryRouter>
+  )
+
+  expect(root.find(MenuContentTokenExpired)).toHaveLength(1)
+  expect(root.find(MenuContentButtonSignUp)).toHaveLength(1)
+})
@@ -14,6 +14,7 @@ import { ReactComponent as TiltCloudLogoSvg } from "./assets/svg/logo-Tilt-Cloud
 import ButtonLink from "./ButtonLink"
 import ButtonInput from "./ButtonInput"
 import ReactOutlineManager from "react-outline-manager"
+import { apiUrl } from "./basePath"
 
 export const SidebarAccountRoot = styled.div`
   position: relative; // Anchor SidebarAccountMenu
@@ -157,6 +158,9 @@ let MenuContentTeamInTiltfile = styled.small`
     opacity: 1;
   }
 `
+export const MenuContentTokenExpired = styled.p`
+  color: ${Color.text};
+`
 export const MenuContentButtonTiltCloud = styled(ButtonLink)`
   margin-top: ${SizeUnit(0.3)};
 `
@@ -171,10 +175,12 @@ type SidebarAccountProps = {
   tiltCloudSchemeHost: string
   tiltCloudTeamID: string | null
   tiltCloudTeamName: string | null
+  tiltCloudTokenExpired?: boolean
+  tiltCloudTeams?: Proto.webviewTiltCloudTeam[]
 }
 
 function notifyTiltOfRegistration() {
-  let url = `/api/user_started_tilt_cloud_registration`
+  let url = apiUrl("/api/user_started_tilt_cloud_registration")
   fetch(url, {
     method: "POST",
     headers: {
@@ -200,8 +206,17 @@ function SidebarAccount(props: SidebarAccountProps) {
     )
   }
 
+  let teams = props.tiltCloudTeams ?? []
   let teamContent = null
-  if (props.tiltCloudTeamID) {
+  if (teams.length > 1) {
+    teamContent = teams.map(team => (
+      <MenuContentTeam key={team.id}>
+        On team{" "}
+        <MenuContentTeamName>{team.name || team.id}</MenuContentTeamName>
+        {team.associated ? null : <small> (not linked)</small>}
+      </MenuContentTeam>
+    ))
+  } else if (props.tiltCloudTeamID) {
     teamContent = (
       <MenuContentTeam>
         On team{" "}
@@ -232,12 +247,24 @@ function SidebarAccount(props: SidebarAccountProps) {
     </SidebarAccountMenuContent>
   )
 
+  let signedOutIntro = (
+    <p>
+      Tilt Cloud is a platform for making all kinds of data from Tilt available
+      to your team — and making your team’s data available to you.
+    </p>
+  )
+  if (props.tiltCloudTokenExpired) {
+    signedOutIntro = (
+      <MenuContentTokenExpired>
+        Your Tilt Cloud token has expired. Link Tilt to Tilt Cloud again to
+        keep sharing data with your team.
+      </MenuContentTokenExpired>
+    )
+  }
+
   let signedOutMenuContent = (
     <SidebarAccountMenuContent>
-      <p>
-        Tilt Cloud is a platform for making all kinds of data from Tilt
-        available to your team — and making your team’s data available to you.
-      </p>
+      {signedOutIntro}
       <form
         action={props.tiltCloudSchemeHost + "/start_register_token"}
         target="_blank"
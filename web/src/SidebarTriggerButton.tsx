@@ -3,6 +3,7 @@ import { TriggerMode } from "./types"
 import "./SidebarTriggerButton.scss"
 import styled from "styled-components"
 import { Height, Width } from "./style-helpers"
+import { apiUrl } from "./basePath"
 
 let SidebarTriggerButtonStyle = styled.button`
   background-position: center center;
@@ -39,7 +40,7 @@ type SidebarTriggerButtonProps = {
 }
 
 const triggerUpdate = (name: string): void => {
-  let url = `//${window.location.host}/api/trigger`
+  let url = apiUrl("/api/trigger")
 
   fetch(url, {
     method: "post",
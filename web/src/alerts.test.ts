@@ -61,6 +61,27 @@ describe("getResourceAlerts", () => {
     expect(actual).toEqual(expectedAlerts)
   })
 
+  it("K8s Resource: pod restart alert says which container was OOMKilled", () => {
+    let r: Resource = k8sResource()
+    let rInfo = r.k8sResourceInfo
+    if (!rInfo) throw new Error("missing k8s info")
+    rInfo.podRestarts = 2
+    rInfo.containers = [
+      { name: "sidecar", restarts: 0 },
+      {
+        name: "main",
+        restarts: 2,
+        lastTerminationReason: "OOMKilled",
+        lastTerminationExitCode: 137,
+        oomKilled: true,
+      },
+    ]
+    let actual = getResourceAlerts(r, logStore)
+    expect(actual[0].header).toEqual(
+      "Restarts: 2 · main was OOMKilled (exit code 137)"
+    )
+  })
+
   it("K8s Resource should show the first build alert", () => {
     let r: Resource = k8sResource()
     r.buildHistory = [
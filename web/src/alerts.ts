@@ -1,6 +1,7 @@
 import { podStatusIsError, podStatusIsCrash } from "./constants"
 import { logLinesToString } from "./logs"
 import LogStore from "./LogStore"
+import { apiUrl } from "./basePath"
 
 type Resource = Proto.webviewResource
 type K8sResourceInfo = Proto.webviewK8sResourceInfo
@@ -108,9 +109,15 @@ function podRestartAlert(r: Resource): Alert {
   let rInfo = r.k8sResourceInfo as K8sResourceInfo
   let msg = r.crashLog || ""
   let header = `Restarts: ${Number(rInfo.podRestarts).toString()}`
+  let oomKilled = (rInfo.containers ?? []).find(c => c.oomKilled)
+  if (oomKilled) {
+    header += ` · ${oomKilled.name} was OOMKilled (exit code ${Number(
+      oomKilled.lastTerminationExitCode
+    )})`
+  }
 
   let dismissHandler = () => {
-    let url = "/api/action"
+    let url = apiUrl("/api/action")
     let payload = {
       type: "PodResetRestarts",
       manifest_name: r.name,
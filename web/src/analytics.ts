@@ -1,8 +1,10 @@
+import { apiUrl } from "./basePath"
+
 type Tags = { [key: string]: string }
 
 // Fire and forget all analytics events
 const incr = (name: string, tags: Tags = {}): void => {
-  let url = `//${window.location.host}/api/analytics`
+  let url = apiUrl("/api/analytics")
 
   fetch(url, {
     method: "post",
@@ -0,0 +1,16 @@
+import cookies from "js-cookie"
+
+// The path that the web UI lives under, when a reverse proxy serves it
+// at a subpath (tilt up --web-base-path). Empty when it lives at the root.
+export function basePath(): string {
+  let path = cookies.get("Tilt-Base-Path") || ""
+  return path.replace(/\/+$/, "")
+}
+
+// The URL of an API endpoint, relative to the page's origin.
+export function apiUrl(path: string): string {
+  if (path[0] !== "/") {
+    throw new Error('path should start with "/", actual:' + path)
+  }
+  return basePath() + path
+}
@@ -5,10 +5,11 @@ import HUD from "./HUD"
 import { Router } from "react-router-dom"
 import { createBrowserHistory } from "history"
 import ReactModal from "react-modal"
+import { basePath } from "./basePath"
 
 ReactModal.setAppElement(document.body)
 
-let history = createBrowserHistory()
+let history = createBrowserHistory({ basename: basePath() })
 let app = (
   <Router history={history}>
     <HUD history={history} />
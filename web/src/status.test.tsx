@@ -27,6 +27,14 @@ describe("combinedStatus", () => {
     expect(combinedStatus(res)).toBe(ResourceStatus.Building)
   })
 
+  it("unhealthy when config error", () => {
+    const ts = Date.now().toLocaleString()
+    let res = emptyResource()
+    res.currentBuild = { startTime: ts }
+    res.configError = "resource bar specified a dependency on unknown resource foo"
+    expect(combinedStatus(res)).toBe(ResourceStatus.Unhealthy)
+  })
+
   it("healthy when runtime ok", () => {
     const ts = Date.now().toLocaleString()
     let res = emptyResource()
@@ -78,6 +86,23 @@ describe("combinedStatus", () => {
     expect(warnings(res)).toEqual(["Container restarted"])
   })
 
+  it("warning says when a container was OOMKilled", () => {
+    let res = emptyResource()
+    res.runtimeStatus = RuntimeStatus.Ok
+    if (!res.k8sResourceInfo) throw new Error("missing k8s info")
+    res.k8sResourceInfo.podRestarts = 2
+    res.k8sResourceInfo.containers = [
+      {
+        name: "main",
+        restarts: 2,
+        lastTerminationReason: "OOMKilled",
+        lastTerminationExitCode: 137,
+        oomKilled: true,
+      },
+    ]
+    expect(warnings(res)).toEqual(["Container restarted (OOMKilled)"])
+  })
+
   it("none when n/a runtime status and no builds", () => {
     let res = emptyResource()
     res.runtimeStatus = RuntimeStatus.NotApplicable
@@ -4,6 +4,7 @@ import { ResourceStatus, RuntimeStatus, TriggerMode } from "./types"
 type Resource = Proto.webviewResource
 
 // A combination of runtime status and build status over a resource view.
+// 0) If the Tiltfile couldn't load the resource's config, this is "error".
 // 1) If there's a current or pending build, this is "pending".
 // 2) Otherwise, if there's a build error or runtime error, this is "error".
 // 3) Otherwise, we fallback to runtime status.
@@ -22,7 +23,9 @@ function combinedStatus(res: Resource): ResourceStatus {
   let lastBuildError = lastBuild ? lastBuild.error : ""
   let hasWarnings = warnings(res).length > 0
 
-  if (hasCurrentBuild) {
+  if (res.configError) {
+    return ResourceStatus.Unhealthy
+  } else if (hasCurrentBuild) {
     return ResourceStatus.Building
   } else if (hasPendingBuild) {
     return ResourceStatus.Pending
@@ -60,7 +63,12 @@ function warnings(res: any): string[] {
   warnings = Array.from(warnings)
 
   if (res.k8sResourceInfo && res.k8sResourceInfo.podRestarts > 0) {
-    warnings.push("Container restarted")
+    let containers = res.k8sResourceInfo.containers || []
+    if (containers.some((c: any) => c.oomKilled)) {
+      warnings.push("Container restarted (OOMKilled)")
+    } else {
+      warnings.push("Container restarted")
+    }
   }
 
   return warnings
@@ -72,6 +72,7 @@ export type LogLine = {
   level: string
   buildEvent?: string
   spanId: string
+  time?: string
 }
 
 // Display data about the current log trace.
@@ -27,6 +27,32 @@ declare namespace Proto {
      * so we can tell when Tilt restarted.
      */
     tiltStartTime?: string
+    /**
+     * Increments by one with every view sent on a websocket,
+     * so that the client can acknowledge what it has received.
+     */
+    seq?: number
+    /**
+     * Set when Tilt Cloud rejected our token, so the user needs to link
+     * Tilt to Tilt Cloud again.
+     */
+    tiltCloudTokenExpired?: boolean
+    /**
+     * The primary team, followed by any additional teams from the Tiltfile.
+     */
+    tiltCloudTeams?: webviewTiltCloudTeam[]
+    /**
+     * Alerts the user hasn't acknowledged yet.
+     */
+    alerts?: webviewAlert[]
+    /**
+     * Whether to show the time of each log line.
+     */
+    logTimestamps?: boolean
+    /**
+     * The width of the resource name column in front of each log line.
+     */
+    logPrefixWidth?: number
   }
   export interface webviewVersionSettings {
     checkUpdates?: boolean
@@ -34,6 +60,24 @@ declare namespace Proto {
   export interface webviewUploadSnapshotResponse {
     url?: string
   }
+  export interface webviewAlert {
+    id?: string
+    source?: string
+    severity?: string
+    manifestName?: string
+    message?: string
+    firstSeen?: string
+    lastSeen?: string
+    count?: number
+  }
+  export interface webviewTiltCloudTeam {
+    id?: string
+    name?: string
+    /**
+     * Whether the current user is a member of this team.
+     */
+    associated?: boolean
+  }
   export interface webviewTiltBuild {
     version?: string
     commitSHA?: string
@@ -87,6 +131,23 @@ declare namespace Proto {
     crashLogSpanId?: string
     facets?: webviewFacet[]
     queued?: boolean
+    /**
+     * How much of the HTTP traffic through this resource's port forwards to log:
+     * "" (off), "requests", or "bodies".
+     */
+    trafficCapture?: string
+    /**
+     * The color of this resource's log prefix, or "" if log colors are off.
+     */
+    logColor?: string
+    /**
+     * Whether this resource deployed a CronJob, which the user can run now.
+     */
+    cronJob?: boolean
+    /**
+     * Why the Tiltfile couldn't load this resource's config, if it couldn't.
+     */
+    configError?: string
   }
   export interface webviewLogSpan {
     manifestName?: string
@@ -133,6 +194,14 @@ declare namespace Proto {
   export interface webviewLink {
     url?: string
     name?: string
+    health?: webviewLinkHealth
+  }
+  export interface webviewLinkHealth {
+    statusCode?: number
+    latencyMs?: string
+    error?: string
+    checkedAt?: string
+    healthy?: boolean
   }
   export interface webviewK8sResourceInfo {
     podName?: string
@@ -144,6 +213,35 @@ declare namespace Proto {
     podRestarts?: number
     spanId?: string
     displayNames?: string[]
+    /**
+     * The containers of the pod, with their restarts and why they last stopped.
+     */
+    containers?: webviewContainerStatus[]
+    /**
+     * If the resource is deployed to several namespaces with
+     * k8s_resource(namespaces=), the rollout in each one.
+     */
+    namespaces?: webviewNamespaceStatus[]
+  }
+  export interface webviewContainerStatus {
+    name?: string
+    restarts?: number
+    /**
+     * Why the container last stopped (e.g., "OOMKilled" or "Error"), and its
+     * exit code. Empty if it has never stopped.
+     */
+    lastTerminationReason?: string
+    lastTerminationExitCode?: number
+    oomKilled?: boolean
+  }
+  export interface webviewNamespaceStatus {
+    namespace?: string
+    /**
+     * The most recent pod in the namespace. Empty if there isn't one yet.
+     */
+    podName?: string
+    podStatus?: string
+    runtimeStatus?: string
   }
   export interface webviewFacet {
     name?: string
@@ -173,6 +271,12 @@ declare namespace Proto {
      * The span id for this build record's logs in the main logstore.
      */
     spanId?: string
+    /**
+     * What kind of failure the error was (e.g., "registry-auth"), and what
+     * to do about it. Empty if we don't know.
+     */
+    errorCategory?: string
+    errorRemediation?: string
   }
   export interface webviewAckWebsocketResponse {}
   export interface webviewAckWebsocketRequest {
@@ -182,5 +286,6 @@ declare namespace Proto {
      * so we can tell when we're talking to the same Tilt.
      */
     tiltStartTime?: string
+    seq?: number
   }
 }